@@ -0,0 +1,119 @@
+// Command reconcile checks the payments ledger against trips and prints a
+// JSON report of every inconsistency: ENDED trips without a payment, trips
+// with several, SUCCESS payments without a finished trip, payments that do
+// not match their trip's fare, and duplicate idempotency keys.
+//
+// It exits 0 when the ledger is consistent, 1 when anomalies were found and
+// 2 when the check could not run, so it can be scheduled from cron.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"ride/internal/app"
+	"ride/internal/config"
+	"ride/internal/domain"
+	"ride/internal/repository/postgres"
+	"ride/internal/service"
+)
+
+const (
+	exitConsistent = 0
+	exitAnomalies  = 1
+	exitError      = 2
+)
+
+// ledgerReportJSON is the machine-readable report written to stdout.
+type ledgerReportJSON struct {
+	CheckedAt  string                           `json:"checked_at"`
+	Since      string                           `json:"since,omitempty"`
+	Consistent bool                             `json:"consistent"`
+	Counts     map[domain.LedgerAnomalyKind]int `json:"counts"`
+	Anomalies  []ledgerAnomalyJSON              `json:"anomalies"`
+}
+
+type ledgerAnomalyJSON struct {
+	Kind           domain.LedgerAnomalyKind `json:"kind"`
+	TripID         string                   `json:"trip_id,omitempty"`
+	PaymentID      string                   `json:"payment_id,omitempty"`
+	IdempotencyKey string                   `json:"idempotency_key,omitempty"`
+	Fare           float64                  `json:"fare,omitempty"`
+	NetAmount      float64                  `json:"net_amount,omitempty"`
+	Detail         string                   `json:"detail,omitempty"`
+}
+
+func main() {
+	since := flag.Duration("since", 0, "only check trips and payments from this far back, e.g. 48h; 0 checks the whole ledger")
+	timeout := flag.Duration("timeout", 5*time.Minute, "give up after this long")
+	flag.Parse()
+	log.SetOutput(os.Stderr)
+
+	os.Exit(run(*since, *timeout))
+}
+
+func run(since, timeout time.Duration) int {
+	cfg := config.Load()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	db, err := app.NewDatabase(ctx, cfg.Database, nil, nil)
+	if err != nil {
+		log.Printf("[RECONCILE] failed to connect to database: %v", err)
+		return exitError
+	}
+	defer db.Close()
+
+	var from time.Time
+	if since > 0 {
+		from = time.Now().Add(-since)
+	}
+	report, err := service.NewReportService(postgres.NewReportRepository(db)).LedgerCheck(ctx, from)
+	if err != nil {
+		log.Printf("[RECONCILE] ledger check failed: %v", err)
+		return exitError
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(newLedgerReportJSON(report)); err != nil {
+		log.Printf("[RECONCILE] failed to write report: %v", err)
+		return exitError
+	}
+
+	if !report.Consistent {
+		log.Printf("[RECONCILE] %d ledger anomalies found", len(report.Anomalies))
+		return exitAnomalies
+	}
+	return exitConsistent
+}
+
+func newLedgerReportJSON(report *domain.LedgerReport) ledgerReportJSON {
+	out := ledgerReportJSON{
+		CheckedAt:  report.CheckedAt.UTC().Format(time.RFC3339),
+		Consistent: report.Consistent,
+		Counts:     make(map[domain.LedgerAnomalyKind]int),
+		Anomalies:  make([]ledgerAnomalyJSON, 0, len(report.Anomalies)),
+	}
+	if !report.Since.IsZero() {
+		out.Since = report.Since.UTC().Format(time.RFC3339)
+	}
+	for _, a := range report.Anomalies {
+		out.Counts[a.Kind]++
+		out.Anomalies = append(out.Anomalies, ledgerAnomalyJSON{
+			Kind:           a.Kind,
+			TripID:         a.TripID,
+			PaymentID:      a.PaymentID,
+			IdempotencyKey: a.IdempotencyKey,
+			Fare:           a.Fare,
+			NetAmount:      a.NetAmount,
+			Detail:         a.Detail,
+		})
+	}
+	return out
+}
@@ -3,19 +3,30 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/newrelic/go-agent/v3/newrelic"
 	"github.com/redis/go-redis/v9"
+	"google.golang.org/grpc"
 
 	"ride/internal/app"
 	"ride/internal/config"
+	"ride/internal/domain"
+	"ride/internal/faults"
+	internalGRPC "ride/internal/grpc"
 	"ride/internal/handler"
+	"ride/internal/middleware"
+	"ride/internal/money"
 	internalRedis "ride/internal/redis"
 	"ride/internal/repository/postgres"
 	"ride/internal/service"
@@ -24,37 +35,43 @@ import (
 func main() {
 	// Load configuration.
 	cfg := config.Load()
+	if err := cfg.Validate(); err != nil {
+		var invalid *config.ValidationError
+		if !errors.As(err, &invalid) {
+			log.Fatalf("refusing to start: %v", err)
+		}
+		for _, problem := range invalid.Problems {
+			log.Printf("config: %s", problem)
+		}
+		log.Fatalf("refusing to start: %d configuration problems", len(invalid.Problems))
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	// Initialize New Relic FIRST (before database so we can instrument DB).
-	var nrApp *newrelic.Application
-	var err error
-	if cfg.NewRelic.Enabled && cfg.NewRelic.LicenseKey != "" {
-		nrApp, err = newrelic.NewApplication(
-			newrelic.ConfigAppName(cfg.NewRelic.AppName),
-			newrelic.ConfigLicense(cfg.NewRelic.LicenseKey),
-			newrelic.ConfigDistributedTracerEnabled(true),
-			newrelic.ConfigAppLogForwardingEnabled(true),
-		)
-		if err != nil {
-			log.Printf("failed to initialize New Relic: %v", err)
-		} else {
-			log.Printf("New Relic enabled: app=%s (with DB instrumentation)", cfg.NewRelic.AppName)
-		}
-	}
+	// A nil app means instrumentation is off.
+	nrApp := app.NewNewRelicApp(cfg.NewRelic)
+
+	// QA fault injection wraps both stores; nil outside QA environments.
+	injector := newFaultInjector(cfg)
 
 	// Initialize database with New Relic instrumentation.
-	db, err := app.NewDatabase(ctx, cfg.Database, nrApp)
+	db, err := app.NewDatabase(ctx, cfg.Database, nrApp, injector)
 	if err != nil {
 		log.Fatalf("failed to connect to database: %v", err)
 	}
 	defer db.Close()
 	log.Println("Connected to PostgreSQL")
 
+	if cfg.Database.SchemaCheck {
+		if err := app.SchemaCheck(ctx, db, postgres.ExpectedSchema()); err != nil {
+			log.Fatalf("refusing to start: %v", err)
+		}
+	}
+
 	// Initialize Redis with New Relic instrumentation.
-	redisClient, err := app.NewRedisClient(ctx, cfg.Redis, nrApp)
+	redisClient, err := app.NewRedisClient(ctx, cfg.Redis, nrApp, injector)
 	if err != nil {
 		log.Fatalf("failed to connect to redis: %v", err)
 	}
@@ -62,15 +79,27 @@ func main() {
 	log.Println("Connected to Redis")
 
 	// Wire dependencies.
-	server := wireServer(db, redisClient, nrApp, cfg)
+	server, grpcServer, closeWorkers := wireServer(db, redisClient, nrApp, injector, cfg)
 
-	// Start server in goroutine.
+	// Start servers in goroutines.
 	go func() {
 		log.Printf("Starting server on port %s", cfg.Server.Port)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			log.Fatalf("server error: %v", err)
 		}
 	}()
+	if grpcServer != nil {
+		listener, err := net.Listen("tcp", ":"+cfg.Server.GRPCPort)
+		if err != nil {
+			log.Fatalf("failed to listen for gRPC: %v", err)
+		}
+		go func() {
+			log.Printf("Starting gRPC server on port %s", cfg.Server.GRPCPort)
+			if err := grpcServer.Serve(listener); err != nil {
+				log.Fatalf("gRPC server error: %v", err)
+			}
+		}()
+	}
 
 	// Graceful shutdown.
 	quit := make(chan os.Signal, 1)
@@ -81,19 +110,55 @@ func main() {
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer shutdownCancel()
 
+	stopGRPC(shutdownCtx, grpcServer)
 	if err := server.Shutdown(shutdownCtx); err != nil {
 		log.Fatalf("server forced to shutdown: %v", err)
 	}
+	closeWorkers()
 
 	log.Println("Server exited")
 }
 
-// wireServer wires all dependencies and returns the HTTP server.
-func wireServer(db *sql.DB, redisClient *redis.Client, nrApp *newrelic.Application, cfg *config.Config) *http.Server {
+// stopGRPC lets in-flight gRPC calls finish until ctx is done, then closes
+// whatever is left, like http.Server.Shutdown. A nil server is a no-op.
+func stopGRPC(ctx context.Context, server *grpc.Server) {
+	if server == nil {
+		return
+	}
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		log.Printf("gRPC server forced to shutdown: %v", ctx.Err())
+		server.Stop()
+		<-stopped
+	}
+}
+
+// wireServer wires all dependencies and returns the HTTP server and the gRPC
+// server (nil when GRPC_PORT is 0), plus a func that stops background
+// workers once both have shut down.
+func wireServer(db *sql.DB, redisClient *redis.Client, nrApp *newrelic.Application, injector *faults.Injector, cfg *config.Config) (*http.Server, *grpc.Server, func()) {
 	// Initialize Redis stores.
-	locationStore := internalRedis.NewLocationStore(redisClient)
-	lockStore := internalRedis.NewLockStore(redisClient)
-	cacheStore := internalRedis.NewCacheStore(redisClient)
+	locationStore := internalRedis.NewLocationStore(redisClient, cfg.Redis.KeyPrefix, geoRegions(cfg.Matching))
+	lockStore := internalRedis.NewLockStore(redisClient, cfg.Redis.KeyPrefix)
+	cacheStore := internalRedis.NewCacheStore(redisClient, cfg.Redis.KeyPrefix)
+	notificationBroker := internalRedis.NewNotificationBroker(redisClient, cfg.Redis.KeyPrefix)
+	deviationStore := internalRedis.NewDeviationStore(redisClient, cfg.Redis.KeyPrefix)
+	emailTokenStore := internalRedis.NewEmailTokenStore(redisClient, cfg.Redis.KeyPrefix)
+	quoteStore := internalRedis.NewQuoteStore(redisClient, cfg.Redis.KeyPrefix)
+	surgeStore := internalRedis.NewSurgeStore(redisClient, cfg.Redis.KeyPrefix)
+	exclusionStore := internalRedis.NewExclusionStore(redisClient, cfg.Redis.KeyPrefix)
+	arrivalStore := internalRedis.NewArrivalStore(redisClient, cfg.Redis.KeyPrefix)
+	notificationThrottleStore := internalRedis.NewNotificationThrottleStore(redisClient, cfg.Redis.KeyPrefix)
+	destinationStore := internalRedis.NewDestinationStore(redisClient, cfg.Redis.KeyPrefix)
+	locationGuardStore := internalRedis.NewLocationGuardStore(redisClient, cfg.Redis.KeyPrefix)
+	trackerNonceStore := internalRedis.NewTrackerNonceStore(redisClient, cfg.Redis.KeyPrefix)
+	tripETAStore := internalRedis.NewTripETAStore(redisClient, cfg.Redis.KeyPrefix)
 
 	// Initialize repositories.
 	userRepo := postgres.NewUserRepository(db)
@@ -101,41 +166,424 @@ func wireServer(db *sql.DB, redisClient *redis.Client, nrApp *newrelic.Applicati
 	rideRepo := postgres.NewRideRepository(db)
 	tripRepo := postgres.NewTripRepository(db)
 	paymentRepo := postgres.NewPaymentRepository(db)
+	paymentInstrumentRepo := postgres.NewPaymentInstrumentRepository(db)
+	reportRepo := postgres.NewReportRepository(db)
+	notificationRepo := postgres.NewNotificationRepository(db)
+	notificationPreferenceRepo := postgres.NewNotificationPreferenceRepository(db)
+	campaignRepo := postgres.NewCampaignRepository(db)
+	earningsRepo := postgres.NewEarningsRepository(db)
+	summaryRepo := postgres.NewDriverSummaryRepository(db)
+	deviationAlertRepo := postgres.NewDeviationAlertRepository(db)
+	matchAttemptRepo := service.NewAsyncMatchAttemptWriter(postgres.NewMatchAttemptRepository(db))
+	locationHistoryRepo := postgres.NewLocationHistoryRepository(db)
+	featureFlagRepo := postgres.NewFeatureFlagRepository(db)
+	tripAttachmentRepo := postgres.NewTripAttachmentRepository(db)
+	fareSplitRepo := postgres.NewFareSplitRepository(db)
+	fareAdjustmentRepo := postgres.NewFareAdjustmentRepository(db)
+	trackerKeyRepo := postgres.NewTrackerKeyRepository(db)
+	notificationDeliveryRepo := postgres.NewNotificationDeliveryRepository(db)
+
+	catalog, err := loadCatalog(cfg)
+	if err != nil {
+		log.Fatalf("invalid payment method and tier catalog: %v", err)
+	}
+
+	blobStore, err := service.NewBlobStore(cfg.Attachment.Store, cfg.Attachment.Dir)
+	if err != nil {
+		log.Fatalf("failed to create blob store: %v", err)
+	}
+
+	surgeFloors, surgeClock, err := surgeSchedule(cfg.Surge)
+	if err != nil {
+		log.Fatalf("invalid surge floors: %v", err)
+	}
+
+	moneyFormatter, err := money.NewFormatter(cfg.Fare.Currency, cfg.Fare.Locale)
+	if err != nil {
+		log.Fatalf("invalid fare currency: %v", err)
+	}
 
 	// Initialize services.
-	notificationService := service.NewNotificationService()
-	receiptService := service.NewReceiptService(notificationService)
-	matchingService := service.NewMatchingService(db, locationStore, lockStore, cacheStore, driverRepo, rideRepo)
-	surgeService := service.NewSurgeService(locationStore, rideRepo)
-	rideService := service.NewRideService(rideRepo, matchingService, surgeService, notificationService)
-	driverService := service.NewDriverService(locationStore, cacheStore, driverRepo)
-	psp := service.NewMockPSP()
-	paymentService := service.NewPaymentService(paymentRepo, psp)
-	tripService := service.NewTripService(db, tripRepo, rideRepo, driverRepo, paymentService, notificationService, receiptService)
+	emailSender := service.NewLogEmailSender()
+	var notificationChannels []service.NotificationChannelSender
+	if cfg.Notification.EmailEnabled {
+		notificationChannels = append(notificationChannels, service.NewEmailNotificationChannel(userRepo, driverRepo, emailSender))
+	}
+	notificationService := service.NewNotificationService(notificationRepo, notificationBroker, service.DeepLinks{
+		Receipt:    cfg.Notification.ReceiptLinkTemplate,
+		RateDriver: cfg.Notification.RateDriverLinkTemplate,
+	}, notificationPreferenceRepo, notificationChannels, moneyFormatter, notificationDeliveryRepo)
+	eventPublisher := service.NewLogEventPublisher() // Swap for a Kafka or NATS publisher to feed downstream systems
+	emailService := service.NewEmailVerificationService(userRepo, emailTokenStore, emailSender, cfg.Email.VerificationTTL, cfg.Email.ResendCooldown)
+	fareSchedule := service.NewFareSchedule(catalog, cfg.Fare.MinFare)
+	receiptService := service.NewReceiptService(notificationService, userRepo, emailSender, moneyFormatter, fareSchedule)
+	featureFlagService := service.NewFeatureFlagService(featureFlagRepo, cfg.Flags.City, cfg.Flags.CacheTTL)
+	matchingService := service.NewMatchingService(service.MatchingServiceDeps{
+		DB:                   db,
+		LocationStore:        locationStore,
+		LockStore:            lockStore,
+		CacheStore:           cacheStore,
+		DriverRepo:           driverRepo,
+		RideRepo:             rideRepo,
+		AttemptRepo:          matchAttemptRepo,
+		MaxCandidates:        cfg.Matching.MaxCandidates,
+		DegradedFallback:     cfg.Matching.DegradedFallback,
+		TierRadiusKm:         tierRadii(catalog),
+		ExclusionStore:       exclusionStore,
+		ExclusionTTL:         cfg.Matching.ExclusionTTL,
+		Flags:                featureFlagService,
+		DestinationStore:     destinationStore,
+		DestinationAngleDeg:  cfg.Matching.DestinationAngleDeg,
+		MaxConcurrentPerArea: cfg.Matching.MaxConcurrentPerArea,
+		TripRepo:             tripRepo,
+	})
+	surgeService := service.NewSurgeService(locationStore, rideRepo, surgeStore, cfg.Surge.Smoothing, cfg.Surge.SmoothingTTL, surgeFloors, surgeClock)
+	surchargeService := service.NewSurchargeService(surchargeZones(cfg.Surcharge))
+	quoteService := service.NewQuoteService(quoteStore, cfg.Quote.SigningKey, cfg.Quote.TTL)
+	rideQueueService := service.NewRideQueueService(internalRedis.NewRideQueueStore(redisClient, cfg.Redis.KeyPrefix), cfg.Matching.QueueWindow, nil)
+	paymentInstrumentService := service.NewPaymentInstrumentService(paymentInstrumentRepo, catalog)
+	rideBroadcastService := service.NewRideBroadcastService(locationStore, driverRepo, notificationThrottleStore, notificationService, cfg.Notification.RideRequestedDrivers, cfg.Matching.BasicRadiusKm, cfg.Notification.RideRequestedCooldown)
+	estimatorService := service.NewEstimatorService(tripRepo, cfg.Estimator.RadiusKm, cfg.Estimator.Lookback, cfg.Estimator.MinTrips, cfg.Estimator.MaxTrips, nil)
+	rideService := service.NewRideService(service.RideServiceDeps{
+		RideRepo:            rideRepo,
+		MatchingService:     matchingService,
+		SurgeService:        surgeService,
+		SurchargeService:    surchargeService,
+		QuoteService:        quoteService,
+		NotificationService: notificationService,
+		InstrumentService:   paymentInstrumentService,
+		Catalog:             catalog,
+		Publisher:           eventPublisher,
+		Flags:               featureFlagService,
+		Broadcast:           rideBroadcastService,
+		Queue:               rideQueueService,
+		Fares:               fareSchedule,
+		Estimator:           estimatorService,
+	})
+	deviationService := service.NewDeviationService(tripRepo, rideRepo, deviationAlertRepo, deviationStore, notificationService, cfg.Deviation.ThresholdKm, cfg.Deviation.ConsecutivePings)
+	locationHistoryService := service.NewLocationHistoryService(locationHistoryRepo, cfg.History.BatchSize, cfg.History.FlushInterval, cfg.History.Retention)
+	psp := service.NewResilientPSP(service.NewMockPSP(), cfg.PSP.Timeout, cfg.PSP.MaxRetries, cfg.PSP.RetryBackoff, cfg.PSP.BreakerThreshold, cfg.PSP.BreakerCooldown)
+	paymentService := service.NewPaymentService(paymentRepo, psp, paymentInstrumentRepo, eventPublisher, processingFees(cfg.Payment), cfg.Payment.AuthBufferPercent)
+	campaignService := service.NewCampaignService(campaignRepo, driverRepo, earningsRepo, locationHistoryRepo, notificationService, catalog)
+	tripService := service.NewTripService(service.TripServiceDeps{
+		DB:                  db,
+		TripRepo:            tripRepo,
+		RideRepo:            rideRepo,
+		DriverRepo:          driverRepo,
+		LocationStore:       locationStore,
+		PaymentService:      paymentService,
+		NotificationService: notificationService,
+		ReceiptService:      receiptService,
+		CampaignService:     campaignService,
+		MinFare:             cfg.Fare.MinFare,
+		MaxFare:             cfg.Fare.MaxFare,
+		PickupGeofenceKm:    cfg.Trip.PickupGeofenceKm,
+		DriverAbortFare:     service.AbortFarePolicy(cfg.Trip.DriverAbortFare),
+		Catalog:             catalog,
+		Publisher:           eventPublisher,
+		ArrivalStore:        arrivalStore,
+		ArrivalRadiusKm:     cfg.Trip.ArrivalRadiusKm,
+		ArrivalPings:        cfg.Trip.ArrivalPings,
+		AttachmentRepo:      tripAttachmentRepo,
+		Estimator:           estimatorService,
+		FareSplits:          fareSplitRepo,
+		FareAdjustments:     fareAdjustmentRepo,
+		ConfirmationWindow:  cfg.Trip.ConfirmationWindow,
+	})
+	locationGuardService := service.NewLocationGuardService(locationGuardStore, cfg.SpeedGuard.MaxSpeedKmh, cfg.SpeedGuard.MaxAnomalies, nil)
+	driverService := service.NewDriverService(locationStore, cacheStore, driverRepo, deviationService, locationHistoryService, tripService, destinationStore, cfg.Matching.DestinationTTL, locationGuardService)
+	earningsService := service.NewEarningsService(tripRepo, rideRepo, earningsRepo, paymentService, cfg.Fare.CommissionPercent)
+	summaryService := service.NewDriverSummaryService(summaryRepo, notificationService, cfg.Flags.City, cfg.Fare.CommissionPercent)
+	attachmentService := service.NewTripAttachmentService(tripRepo, rideRepo, tripAttachmentRepo, blobStore, cfg.Attachment.MaxBytes, cfg.Attachment.SigningKey, cfg.Attachment.URLTTL)
+	tripSweeper := service.NewTripSweeper(tripService, cfg.Trip.MaxDuration, cfg.Trip.SweepInterval)
+	locationSweeper := service.NewLocationSweeper(driverService, cfg.Redis.LocationMaxAge, cfg.Redis.LocationSweepInterval)
+	summaryJob := service.NewWeeklySummaryJob(summaryService, cfg.Summary.Interval)
+	campaignSweeper := service.NewCampaignSweeper(campaignService, cfg.Campaign.SweepInterval)
+	rematchWorker := service.NewRematchWorker(rideService, cfg.Matching.RematchInterval, cfg.Matching.RematchBatchSize)
+	notificationDispatcher := service.NewNotificationDispatcher(notificationDeliveryRepo, notificationChannels, notificationService, cfg.Notification.MaxAttempts, cfg.Notification.RetryBackoff, cfg.Notification.RetryMaxBackoff, cfg.Notification.DeadAlertThreshold, nil)
+	dispatchWorker := service.NewNotificationDispatchWorker(notificationDispatcher, cfg.Notification.DispatchInterval, cfg.Notification.DispatchBatchSize, nrApp)
+	stopCacheInvalidation := startCacheInvalidation(cfg.Database, cacheStore)
+	reportService := service.NewReportService(reportRepo)
+	exportService := service.NewExportService(db, tripRepo, rideRepo, cfg.Export.MaxRows)
+	etaService := service.NewETAService(rideRepo, locationStore, notificationService, tripETAStore, cfg.Trip.ETASpeedKmh)
+	driverImportService := service.NewDriverImportService(db, driverRepo, cfg.Phone.DefaultRegion, catalog, cfg.DriverImport.MaxRows)
+	trackerKeyService := service.NewTrackerKeyService(trackerKeyRepo, driverRepo, cfg.TrackerAuth.EncryptionKey)
+	opsMapService := service.NewOpsMapService(locationStore, cacheStore, driverRepo, rideRepo, cfg.OpsMap.MaxPoints, cfg.OpsMap.FetchLimit)
 
 	// Initialize handlers.
-	userHandler := handler.NewUserHandler(userRepo)
-	rideHandler := handler.NewRideHandler(rideService, rideRepo)
-	driverHandler := handler.NewDriverHandler(driverService, tripService, driverRepo)
-	tripHandler := handler.NewTripHandler(tripService)
-	paymentHandler := handler.NewPaymentHandler(paymentService)
+	userHandler := handler.NewUserHandler(userRepo, emailService, cfg.Phone.DefaultRegion)
+	rideHandler := handler.NewRideHandler(rideService, etaService, rideRepo, catalog)
+	driverHandler := handler.NewDriverHandler(driverService, tripService, driverRepo, cfg.Phone.DefaultRegion, catalog, trackerKeyService)
+	tripHandler := handler.NewTripHandler(tripService, etaService)
+	attachmentHandler := handler.NewTripAttachmentHandler(attachmentService, tripService)
+	paymentHandler := handler.NewPaymentHandler(paymentService, tripService)
+	reportHandler := handler.NewReportHandler(reportService)
+	eventsHandler := handler.NewEventsHandler(notificationService, cfg.Server.SSEHeartbeat)
+	campaignHandler := handler.NewCampaignHandler(campaignService)
+	earningsHandler := handler.NewEarningsHandler(earningsService, tripService)
+	summaryHandler := handler.NewDriverSummaryHandler(summaryService)
+	matchAttemptHandler := handler.NewMatchAttemptHandler(matchingService)
+	driverLockHandler := handler.NewDriverLockHandler(matchingService)
+	trackerKeyHandler := handler.NewTrackerKeyHandler(trackerKeyService)
+	driverTrackHandler := handler.NewDriverTrackHandler(locationHistoryService)
+	speedGuardHandler := handler.NewSpeedGuardHandler(locationGuardService)
+	exportHandler := handler.NewExportHandler(exportService)
+	instrumentHandler := handler.NewPaymentInstrumentHandler(paymentInstrumentService)
+	opsMapHandler := handler.NewOpsMapHandler(opsMapService)
+	driverImportHandler := handler.NewDriverImportHandler(driverImportService)
+	featureFlagHandler := handler.NewFeatureFlagHandler(featureFlagService)
+	notificationPreferenceHandler := handler.NewNotificationPreferenceHandler(notificationService)
+	notificationDeliveryHandler := handler.NewNotificationDeliveryHandler(notificationDispatcher)
+	redisKeysHandler := handler.NewRedisKeysHandler(internalRedis.NewKeyAuditor(redisClient, cfg.Redis.KeyPrefix))
+	var faultsHandler *handler.FaultsHandler
+	if injector != nil {
+		faultsHandler = handler.NewFaultsHandler(injector)
+	}
 
 	// Create router.
-	router := app.NewRouter(app.RouterDeps{
-		UserHandler:    userHandler,
-		RideHandler:    rideHandler,
-		DriverHandler:  driverHandler,
-		TripHandler:    tripHandler,
-		PaymentHandler: paymentHandler,
+	router, err := app.NewRouter(app.RouterDeps{
+		UserHandler:         userHandler,
+		RideHandler:         rideHandler,
+		DriverHandler:       driverHandler,
+		TripHandler:         tripHandler,
+		AttachmentHandler:   attachmentHandler,
+		PaymentHandler:      paymentHandler,
+		ReportHandler:       reportHandler,
+		EventsHandler:       eventsHandler,
+		CampaignHandler:     campaignHandler,
+		EarningsHandler:     earningsHandler,
+		SummaryHandler:      summaryHandler,
+		MatchAttemptHandler: matchAttemptHandler,
+		DriverLockHandler:   driverLockHandler,
+		DriverTrackHandler:  driverTrackHandler,
+		SpeedGuardHandler:   speedGuardHandler,
+		ExportHandler:       exportHandler,
+		InstrumentHandler:   instrumentHandler,
+		OpsMapHandler:       opsMapHandler,
+		DriverImportHandler: driverImportHandler,
+		FeatureFlagHandler:  featureFlagHandler,
+		PreferenceHandler:   notificationPreferenceHandler,
+		DeliveryHandler:     notificationDeliveryHandler,
+		FaultsHandler:       faultsHandler,
+		RedisKeysHandler:    redisKeysHandler,
+		TrackerKeyHandler:   trackerKeyHandler,
+		TrackerAuth: middleware.TrackerAuthConfig{
+			Keys:    trackerKeyService,
+			Nonces:  trackerNonceStore,
+			MaxSkew: cfg.TrackerAuth.MaxSkew,
+		},
 		RedisClient:    redisClient,
+		RedisKeyPrefix: cfg.Redis.KeyPrefix,
 		NewRelicApp:    nrApp,
+		MaxBodyBytes:   cfg.Server.MaxBodyBytes,
+		RequestTimeout: cfg.Server.RequestTimeout,
+		AdminToken:     cfg.Server.AdminToken,
+		GinMode:        cfg.Server.GinMode,
+		TrustedProxies: cfg.Server.TrustedProxies,
+		AccessLog: middleware.AccessLogConfig{
+			SkipPaths:  cfg.Server.AccessLogSkipPaths,
+			SampleRate: cfg.Server.AccessLogSampleRate,
+		},
+		SlowRequest: cfg.Server.SlowRequest,
 	})
+	if err != nil {
+		log.Fatalf("failed to create router: %v", err)
+	}
 
 	// Create HTTP server.
-	return &http.Server{
-		Addr:         ":" + cfg.Server.Port,
-		Handler:      router,
-		ReadTimeout:  cfg.Server.ReadTimeout,
-		WriteTimeout: cfg.Server.WriteTimeout,
+	// ReadTimeout bounds slow bodies; the body limit middleware bounds large ones.
+	server := &http.Server{
+		Addr:              ":" + cfg.Server.Port,
+		Handler:           router,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+	}
+
+	// The gRPC server shares the HTTP handlers' services.
+	var grpcServer *grpc.Server
+	if cfg.Server.GRPCPort != "0" {
+		grpcServer = internalGRPC.NewGRPCServer(
+			internalGRPC.NewServer(rideService, driverService, tripService, catalog, cfg.Server.AdminToken),
+			nrApp, nil)
+	}
+
+	return server, grpcServer, func() {
+		tripSweeper.Close()
+		locationSweeper.Close()
+		summaryJob.Close()
+		campaignSweeper.Close()
+		rematchWorker.Close()
+		dispatchWorker.Close()
+		stopCacheInvalidation()
+		locationHistoryService.Close()
+		matchAttemptRepo.Close()
+		_ = driverRepo.Close()
+		_ = rideRepo.Close()
+	}
+}
+
+// startCacheInvalidation listens for driver and ride writes from every
+// instance and drops them from cacheStore, returning a func that stops it.
+// Without the listener, cached entries expire by TTL only.
+func startCacheInvalidation(cfg config.DatabaseConfig, cacheStore *internalRedis.CacheStore) func() {
+	if !cfg.CacheInvalidation {
+		return func() {}
+	}
+
+	listener, err := postgres.NewCacheInvalidationListener(app.DatabaseDSN(cfg), time.Second, time.Minute)
+	if err != nil {
+		log.Printf("[CACHE] Failed to start invalidation listener, cached entries expire by TTL only: %v", err)
+		return func() {}
+	}
+	invalidation := service.NewCacheInvalidationService(listener.Invalidations(), cacheStore)
+	return func() {
+		_ = listener.Close()
+		invalidation.Wait()
+	}
+}
+
+// newFaultInjector returns the QA fault injector when FAULTS_ENABLED is set,
+// or nil. Release mode never gets one, so production runs unwrapped stores
+// and has no fault routes.
+func newFaultInjector(cfg *config.Config) *faults.Injector {
+	if !cfg.Faults.Enabled {
+		return nil
+	}
+	if cfg.Server.GinMode == gin.ReleaseMode {
+		log.Printf("[FAULTS] FAULTS_ENABLED is ignored in release mode")
+		return nil
+	}
+	log.Printf("[FAULTS] Fault injection enabled; control it with /v1/admin/faults")
+	return faults.NewInjector()
+}
+
+// loadCatalog builds the payment method and tier catalog: the built-in one,
+// with MATCHING_* radii and default tier, overridden by the catalog file.
+func loadCatalog(cfg *config.Config) (*domain.Catalog, error) {
+	catalog := domain.DefaultCatalog()
+	catalog.Tiers = []domain.TierSpec{
+		{Name: domain.DriverTierBasic, RadiusKm: cfg.Matching.BasicRadiusKm},
+		{Name: domain.DriverTierPremium, RadiusKm: cfg.Matching.PremiumRadiusKm, MinFare: cfg.Fare.PremiumMinFare},
+	}
+	catalog.DefaultTier = domain.DriverTier(strings.ToUpper(strings.TrimSpace(cfg.Matching.DefaultTier)))
+
+	if cfg.Catalog.File != "" {
+		file, err := config.LoadCatalogFile(cfg.Catalog.File)
+		if err != nil {
+			return nil, err
+		}
+		if len(file.PaymentMethods) > 0 {
+			catalog.PaymentMethods = nil
+			for _, method := range file.PaymentMethods {
+				catalog.PaymentMethods = append(catalog.PaymentMethods, domain.PaymentMethod(strings.ToUpper(method)))
+			}
+		}
+		if file.DefaultPaymentMethod != "" {
+			catalog.DefaultPaymentMethod = domain.PaymentMethod(strings.ToUpper(file.DefaultPaymentMethod))
+		}
+		if len(file.Tiers) > 0 {
+			catalog.Tiers = nil
+			for _, tier := range file.Tiers {
+				catalog.Tiers = append(catalog.Tiers, domain.TierSpec{
+					Name:           domain.DriverTier(strings.ToUpper(tier.Name)),
+					RadiusKm:       tier.RadiusKm,
+					FareMultiplier: tier.FareMultiplier,
+					MaxSurge:       tier.MaxSurge,
+					MinFare:        tier.MinFare,
+				})
+			}
+		}
+		if file.DefaultTier != "" {
+			catalog.DefaultTier = domain.DriverTier(strings.ToUpper(file.DefaultTier))
+		}
+	}
+
+	if err := catalog.Validate(); err != nil {
+		return nil, err
+	}
+	return catalog, nil
+}
+
+// tierRadii returns the catalog's per-tier default matching radii.
+func tierRadii(catalog *domain.Catalog) map[domain.DriverTier]float64 {
+	radii := make(map[domain.DriverTier]float64, len(catalog.Tiers))
+	for _, tier := range catalog.Tiers {
+		radii[tier.Name] = tier.RadiusKm
+	}
+	return radii
+}
+
+// geoRegions converts configured service regions to the location store's.
+func geoRegions(cfg config.MatchingConfig) []internalRedis.GeoRegion {
+	regions := make([]internalRedis.GeoRegion, 0, len(cfg.Regions))
+	for _, r := range cfg.Regions {
+		regions = append(regions, internalRedis.GeoRegion{
+			Name:   r.Name,
+			MinLat: r.MinLat,
+			MinLng: r.MinLng,
+			MaxLat: r.MaxLat,
+			MaxLng: r.MaxLng,
+		})
+	}
+	return regions
+}
+
+// surchargeZones converts configured surcharge zones to domain zones.
+func surchargeZones(cfg config.SurchargeConfig) []domain.SurchargeZone {
+	zones := make([]domain.SurchargeZone, 0, len(cfg.Zones))
+	for _, z := range cfg.Zones {
+		zones = append(zones, domain.SurchargeZone{
+			Label:  z.Label,
+			Amount: z.Amount,
+			MinLat: z.MinLat,
+			MinLng: z.MinLng,
+			MaxLat: z.MaxLat,
+			MaxLng: z.MaxLng,
+		})
+	}
+	return zones
+}
+
+// surgeSchedule converts configured surge floors to domain floors, with a
+// clock reading the time in the floors' time zone.
+func surgeSchedule(cfg config.SurgeConfig) ([]domain.SurgeFloor, func() time.Time, error) {
+	loc := time.Local
+	if cfg.FloorTimezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(cfg.FloorTimezone); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	floors := make([]domain.SurgeFloor, 0, len(cfg.Floors))
+	for _, f := range cfg.Floors {
+		start, err := time.Parse("15:04", f.Start)
+		if err != nil {
+			return nil, nil, fmt.Errorf("start %q: %w", f.Start, err)
+		}
+		end, err := time.Parse("15:04", f.End)
+		if err != nil {
+			return nil, nil, fmt.Errorf("end %q: %w", f.End, err)
+		}
+		floors = append(floors, domain.SurgeFloor{
+			Start:      time.Duration(start.Hour())*time.Hour + time.Duration(start.Minute())*time.Minute,
+			End:        time.Duration(end.Hour())*time.Hour + time.Duration(end.Minute())*time.Minute,
+			Multiplier: f.Multiplier,
+		})
+	}
+	return floors, func() time.Time { return time.Now().In(loc) }, nil
+}
+
+// processingFees converts configured processing fees to domain fees keyed by
+// upper-case payment method.
+func processingFees(cfg config.PaymentConfig) map[domain.PaymentMethod]domain.ProcessingFee {
+	fees := make(map[domain.PaymentMethod]domain.ProcessingFee, len(cfg.Fees))
+	for method, f := range cfg.Fees {
+		fees[domain.PaymentMethod(strings.ToUpper(strings.TrimSpace(method)))] = domain.ProcessingFee{
+			Percent: f.Percent,
+			Flat:    f.Flat,
+		}
 	}
+	return fees
 }
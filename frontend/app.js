@@ -398,7 +398,7 @@ function refreshDriversList() {
         fetch(API_BASE + '/v1/rides').then(function(r) { return r.json(); })
     ])
     .then(function(results) {
-        var drivers = results[0] || [];
+        var drivers = (results[0] && results[0].data) || [];
         var rides = results[1] || [];
         
         renderDriverCards(drivers, rides);
@@ -743,7 +743,7 @@ function fetchDriversTable() {
         fetch(API_BASE + '/v1/trips').then(function(r) { return r.json(); })
     ])
     .then(function(results) {
-        var drivers = results[0] || [];
+        var drivers = (results[0] && results[0].data) || [];
         var trips = results[1] || [];
         
         var tbody = document.getElementById('driversTableBody');
@@ -3,6 +3,7 @@ package app
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"time"
 
@@ -11,31 +12,31 @@ import (
 	"github.com/newrelic/go-agent/v3/newrelic"
 
 	"ride/internal/config"
+	"ride/internal/faults"
 )
 
+// databaseDriverName picks the SQL driver. The "nrpostgres" driver is
+// registered by the nrpq import and is only used when New Relic is running.
+func databaseDriverName(nrApp *newrelic.Application) string {
+	if nrApp == nil {
+		return "postgres"
+	}
+	return "nrpostgres"
+}
+
 // NewDatabase creates a new PostgreSQL connection with optimized settings.
 // If nrApp is provided, it uses New Relic instrumented driver for automatic SQL tracing.
-func NewDatabase(ctx context.Context, cfg config.DatabaseConfig, nrApp *newrelic.Application) (*sql.DB, error) {
-	dsn := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DBName, cfg.SSLMode,
-	)
-
-	var db *sql.DB
-	var err error
+// If injector is provided, every connection injects its Postgres faults.
+// With a positive cfg.SlowQueryThreshold, statements slower than it are logged.
+// With a positive cfg.StatementTimeout, Postgres cancels statements running
+// longer than it on every connection.
+func NewDatabase(ctx context.Context, cfg config.DatabaseConfig, nrApp *newrelic.Application, injector *faults.Injector) (*sql.DB, error) {
+	dsn := DatabaseDSN(cfg)
 
-	// Use New Relic instrumented driver if New Relic is enabled
-	// The "nrpostgres" driver is automatically registered by the nrpq import
-	if nrApp != nil {
-		db, err = sql.Open("nrpostgres", dsn)
-		if err != nil {
-			return nil, fmt.Errorf("failed to open database with nrpq: %w", err)
-		}
-	} else {
-		db, err = sql.Open("postgres", dsn)
-		if err != nil {
-			return nil, fmt.Errorf("failed to open database: %w", err)
-		}
+	driverName := databaseDriverName(nrApp)
+	db, err := openDatabase(driverName, dsn, injector, cfg.SlowQueryThreshold)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database with %s: %w", driverName, err)
 	}
 
 	// ============================================
@@ -67,8 +68,62 @@ func NewDatabase(ctx context.Context, cfg config.DatabaseConfig, nrApp *newrelic
 
 	// Verify connection.
 	if err := db.PingContext(ctx); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
 	return db, nil
 }
+
+// DatabaseDSN returns the lib/pq connection string for cfg.
+func DatabaseDSN(cfg config.DatabaseConfig) string {
+	dsn := fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DBName, cfg.SSLMode,
+	)
+
+	// lib/pq sends keys it does not know to the server as run-time
+	// parameters, so each connection starts with the timeout set, in
+	// milliseconds, before it joins the pool.
+	if cfg.StatementTimeout > 0 {
+		dsn += fmt.Sprintf(" statement_timeout=%d", max(cfg.StatementTimeout.Milliseconds(), 1))
+	}
+	return dsn
+}
+
+// openDatabase opens dsn with the named driver, routing its connections
+// through injector when one is given and timing their statements when
+// slowQuery is positive.
+func openDatabase(driverName, dsn string, injector *faults.Injector, slowQuery time.Duration) (*sql.DB, error) {
+	db, err := sql.Open(driverName, dsn)
+	if err != nil || (injector == nil && slowQuery <= 0) {
+		return db, err
+	}
+
+	// sql.Open has not connected yet; reopen over a connector we can wrap.
+	drv := db.Driver()
+	_ = db.Close()
+	var connector driver.Connector = dsnConnector{driver: drv, dsn: dsn}
+	if opener, ok := drv.(driver.DriverContext); ok {
+		if connector, err = opener.OpenConnector(dsn); err != nil {
+			return nil, err
+		}
+	}
+	if injector != nil {
+		connector = injector.Connector(connector)
+	}
+	if slowQuery > 0 {
+		connector = slowQueryConnector(connector, slowQuery)
+	}
+	return sql.OpenDB(connector), nil
+}
+
+// dsnConnector is a driver.Connector for drivers that only open by DSN.
+type dsnConnector struct {
+	driver driver.Driver
+	dsn    string
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) { return c.driver.Open(c.dsn) }
+
+func (c dsnConnector) Driver() driver.Driver { return c.driver }
@@ -0,0 +1,32 @@
+package app
+
+import (
+	"log"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+
+	"ride/internal/config"
+)
+
+// NewNewRelicApp initializes New Relic. It returns nil when New Relic is
+// disabled or fails to initialize; every instrumentation path treats a nil
+// application as "instrumentation off", so a bad license never stops the app.
+func NewNewRelicApp(cfg config.NewRelicConfig) *newrelic.Application {
+	if !cfg.Enabled || cfg.LicenseKey == "" {
+		return nil
+	}
+
+	nrApp, err := newrelic.NewApplication(
+		newrelic.ConfigAppName(cfg.AppName),
+		newrelic.ConfigLicense(cfg.LicenseKey),
+		newrelic.ConfigDistributedTracerEnabled(true),
+		newrelic.ConfigAppLogForwardingEnabled(true),
+	)
+	if err != nil {
+		log.Printf("failed to initialize New Relic, continuing without instrumentation: %v", err)
+		return nil
+	}
+
+	log.Printf("New Relic enabled: app=%s (with DB instrumentation)", cfg.AppName)
+	return nrApp
+}
@@ -8,10 +8,12 @@ import (
 	"github.com/redis/go-redis/v9"
 
 	"ride/internal/config"
+	"ride/internal/faults"
 )
 
-// NewRedisClient creates a new Redis client with optional New Relic instrumentation.
-func NewRedisClient(ctx context.Context, cfg config.RedisConfig, nrApp *newrelic.Application) (*redis.Client, error) {
+// NewRedisClient creates a new Redis client with optional New Relic
+// instrumentation. If injector is provided, commands inject its Redis faults.
+func NewRedisClient(ctx context.Context, cfg config.RedisConfig, nrApp *newrelic.Application, injector *faults.Injector) (*redis.Client, error) {
 	client := redis.NewClient(&redis.Options{
 		Addr:     cfg.Addr,
 		Password: cfg.Password,
@@ -20,7 +22,10 @@ func NewRedisClient(ctx context.Context, cfg config.RedisConfig, nrApp *newrelic
 
 	// Add New Relic hook for Redis instrumentation if enabled
 	if nrApp != nil {
-		client.AddHook(&nrRedisHook{app: nrApp})
+		client.AddHook(NewRedisHook(nrApp))
+	}
+	if injector != nil {
+		client.AddHook(injector.RedisHook())
 	}
 
 	// Verify connection.
@@ -36,12 +41,21 @@ type nrRedisHook struct {
 	app *newrelic.Application
 }
 
+// NewRedisHook returns a redis.Hook that records New Relic datastore
+// segments. With a nil app it passes commands straight through.
+func NewRedisHook(nrApp *newrelic.Application) redis.Hook {
+	return &nrRedisHook{app: nrApp}
+}
+
 func (h *nrRedisHook) DialHook(next redis.DialHook) redis.DialHook {
 	return next
 }
 
 func (h *nrRedisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
 	return func(ctx context.Context, cmd redis.Cmder) error {
+		if h.app == nil {
+			return next(ctx, cmd)
+		}
 		txn := newrelic.FromContext(ctx)
 		if txn != nil {
 			segment := newrelic.DatastoreSegment{
@@ -58,6 +72,9 @@ func (h *nrRedisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
 
 func (h *nrRedisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
 	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if h.app == nil {
+			return next(ctx, cmds)
+		}
 		txn := newrelic.FromContext(ctx)
 		if txn != nil {
 			segment := newrelic.DatastoreSegment{
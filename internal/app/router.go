@@ -1,6 +1,9 @@
 package app
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"github.com/newrelic/go-agent/v3/integrations/nrgin"
 	"github.com/newrelic/go-agent/v3/newrelic"
@@ -12,22 +15,68 @@ import (
 
 // RouterDeps contains all dependencies needed for the router.
 type RouterDeps struct {
-	RideHandler    *handler.RideHandler
-	DriverHandler  *handler.DriverHandler
-	TripHandler    *handler.TripHandler
-	UserHandler    *handler.UserHandler
-	PaymentHandler *handler.PaymentHandler
-	RedisClient    *redis.Client
-	NewRelicApp    *newrelic.Application
+	RideHandler         *handler.RideHandler
+	DriverHandler       *handler.DriverHandler
+	TripHandler         *handler.TripHandler
+	AttachmentHandler   *handler.TripAttachmentHandler
+	UserHandler         *handler.UserHandler
+	PaymentHandler      *handler.PaymentHandler
+	ReportHandler       *handler.ReportHandler
+	EventsHandler       *handler.EventsHandler
+	CampaignHandler     *handler.CampaignHandler
+	EarningsHandler     *handler.EarningsHandler
+	SummaryHandler      *handler.DriverSummaryHandler
+	MatchAttemptHandler *handler.MatchAttemptHandler
+	DriverLockHandler   *handler.DriverLockHandler
+	DriverTrackHandler  *handler.DriverTrackHandler
+	SpeedGuardHandler   *handler.SpeedGuardHandler
+	ExportHandler       *handler.ExportHandler
+	InstrumentHandler   *handler.PaymentInstrumentHandler
+	OpsMapHandler       *handler.OpsMapHandler
+	DriverImportHandler *handler.DriverImportHandler
+	FeatureFlagHandler  *handler.FeatureFlagHandler
+	PreferenceHandler   *handler.NotificationPreferenceHandler
+	DeliveryHandler     *handler.NotificationDeliveryHandler
+	FaultsHandler       *handler.FaultsHandler // Nil unless fault injection is enabled
+	RedisKeysHandler    *handler.RedisKeysHandler
+	TrackerKeyHandler   *handler.TrackerKeyHandler
+	TrackerAuth         middleware.TrackerAuthConfig // Authenticates signed location updates from embedded trackers
+	RedisClient         *redis.Client
+	RedisKeyPrefix      string // Prepended to idempotency keys
+	NewRelicApp         *newrelic.Application
+	MaxBodyBytes        int64         // Request body limit; 0 disables
+	RequestTimeout      time.Duration // Per-request deadline; 0 disables
+	AdminToken          string        // Bearer token for admin routes; empty rejects all
+	GinMode             string        // debug, release or test; empty leaves gin's mode unchanged
+	TrustedProxies      []string
+	AccessLog           middleware.AccessLogConfig
+	SlowRequest         time.Duration // Requests slower than this are logged; 0 disables
 }
 
-// NewRouter creates a new Gin router with all routes registered.
-func NewRouter(deps RouterDeps) *gin.Engine {
+// NewRouter creates a new Gin router with all routes registered. Only
+// TrustedProxies may supply the client IP through forwarding headers; with
+// none configured the connection's remote address is always used.
+func NewRouter(deps RouterDeps) (*gin.Engine, error) {
+	if deps.GinMode != "" {
+		switch deps.GinMode {
+		case gin.DebugMode, gin.ReleaseMode, gin.TestMode:
+			gin.SetMode(deps.GinMode)
+		default:
+			return nil, fmt.Errorf("invalid gin mode %q", deps.GinMode)
+		}
+	}
+
 	router := gin.New()
+	if err := router.SetTrustedProxies(deps.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid trusted proxies: %w", err)
+	}
 
 	// Global middleware.
-	router.Use(gin.Recovery())
-	router.Use(gin.Logger())
+	router.Use(middleware.RequestIDMiddleware())
+	router.Use(middleware.AccessLogMiddleware(deps.AccessLog))
+	router.Use(middleware.SlowRequestMiddleware(deps.SlowRequest, deps.AccessLog.Output))
+	// Inside the logs, so a recovered panic is logged as a 500.
+	router.Use(middleware.RecoveryMiddleware(deps.AccessLog.Output))
 	router.Use(middleware.CORSMiddleware())
 
 	// Add New Relic middleware if enabled.
@@ -35,7 +84,15 @@ func NewRouter(deps RouterDeps) *gin.Engine {
 		router.Use(nrgin.Middleware(deps.NewRelicApp))
 	}
 
-	router.Use(middleware.IdempotencyMiddleware(deps.RedisClient))
+	// Bound request bodies before anything (idempotency, JSON binding) reads them.
+	router.Use(middleware.BodyLimitMiddleware(deps.MaxBodyBytes))
+	router.Use(middleware.IdempotencyMiddleware(deps.RedisClient, deps.RedisKeyPrefix))
+	router.Use(middleware.IdentityMiddleware(deps.AdminToken))
+	router.Use(middleware.TimeoutMiddleware(deps.RequestTimeout,
+		"/v1/users/:id/events",
+		"/v1/admin/export/trips",
+		"/v1/admin/export/rides",
+	))
 
 	// Health check.
 	router.GET("/health", func(c *gin.Context) {
@@ -50,15 +107,28 @@ func NewRouter(deps RouterDeps) *gin.Engine {
 		{
 			users.POST("/register", deps.UserHandler.Register)
 			users.GET("", deps.UserHandler.GetAll)
+			users.POST("/:id/email", deps.UserHandler.RequestEmailVerification)
+			users.GET("/verify-email", deps.UserHandler.VerifyEmail)
+			users.GET("/:id/events", deps.EventsHandler.StreamUserEvents)
+			users.POST("/:id/payment-methods", deps.InstrumentHandler.Create)
+			users.GET("/:id/payment-methods", deps.InstrumentHandler.GetAll)
+			users.GET("/:id/payment-methods/:instrument_id", deps.InstrumentHandler.Get)
+			users.PUT("/:id/payment-methods/:instrument_id", deps.InstrumentHandler.Update)
+			users.DELETE("/:id/payment-methods/:instrument_id", deps.InstrumentHandler.Delete)
+			users.GET("/:id/notification-preferences", deps.PreferenceHandler.Get)
+			users.PUT("/:id/notification-preferences", deps.PreferenceHandler.Update)
 		}
 
 		// Ride routes.
 		rides := v1.Group("/rides")
 		{
 			rides.POST("", deps.RideHandler.CreateRide)
+			rides.POST("/estimate", deps.RideHandler.EstimateRide)
 			rides.GET("", deps.RideHandler.GetAll)
 			rides.GET("/:id", deps.RideHandler.GetRide)
+			rides.GET("/:id/driver-eta", deps.RideHandler.GetDriverETA)
 			rides.POST("/:id/cancel", deps.RideHandler.CancelRide)
+			rides.POST("/:id/rebook", deps.RideHandler.RebookRide)
 		}
 
 		// Driver routes.
@@ -66,8 +136,21 @@ func NewRouter(deps RouterDeps) *gin.Engine {
 		{
 			drivers.POST("/register", deps.DriverHandler.Register)
 			drivers.GET("", deps.DriverHandler.GetAll)
-			drivers.POST("/:id/location", deps.DriverHandler.UpdateLocation)
+			drivers.GET("/nearby", deps.DriverHandler.GetNearby)
+			drivers.GET("/leaderboard", deps.SummaryHandler.GetLeaderboard)
+			drivers.POST("/:id/location", middleware.TrackerAuthMiddleware(deps.TrackerAuth), deps.DriverHandler.UpdateLocation)
+			drivers.POST("/:id/break", deps.DriverHandler.StartBreak)
+			drivers.POST("/:id/resume", deps.DriverHandler.EndBreak)
+			drivers.PUT("/:id/destination", deps.DriverHandler.SetDestination)
+			drivers.DELETE("/:id/destination", deps.DriverHandler.ClearDestination)
 			drivers.POST("/:id/accept", deps.DriverHandler.AcceptRide)
+			drivers.GET("/:id/current", deps.DriverHandler.GetCurrent)
+			drivers.GET("/:id/campaigns", deps.CampaignHandler.GetDriverCampaigns)
+			drivers.GET("/:id/collections", deps.ReportHandler.GetDriverCollections)
+			drivers.GET("/:id/trips/:tripId/earnings", deps.EarningsHandler.GetTripEarnings)
+			drivers.GET("/:id/summary", deps.SummaryHandler.GetSummary)
+			drivers.GET("/:id/notification-preferences", deps.PreferenceHandler.Get)
+			drivers.PUT("/:id/notification-preferences", deps.PreferenceHandler.Update)
 		}
 
 		// Trip routes.
@@ -78,15 +161,63 @@ func NewRouter(deps RouterDeps) *gin.Engine {
 			trips.POST("/:id/pause", deps.TripHandler.PauseTrip)
 			trips.POST("/:id/resume", deps.TripHandler.ResumeTrip)
 			trips.POST("/:id/end", deps.TripHandler.EndTrip)
+			trips.POST("/:id/abort", deps.TripHandler.AbortTrip)
+			trips.POST("/:id/confirm", deps.TripHandler.ConfirmTrip)
+			trips.POST("/:id/dispute", deps.TripHandler.DisputeTrip)
+			trips.POST("/:id/confirm-cash", deps.TripHandler.ConfirmCash)
+			trips.POST("/:id/split", deps.TripHandler.SplitFare)
+			trips.POST("/:id/tip", deps.EarningsHandler.TipTrip)
+			trips.POST("/:id/attachments", deps.AttachmentHandler.Upload)
+			trips.GET("/:id/attachments", deps.AttachmentHandler.List)
 		}
 
+		// Attachment content, authorized by the URL's signature.
+		v1.GET("/attachments/:id/content", deps.AttachmentHandler.Content)
+
 		// Payment routes.
 		payments := v1.Group("/payments")
 		{
 			payments.POST("", deps.PaymentHandler.ProcessPayment)
 			payments.GET("/:id", deps.PaymentHandler.GetPayment)
 		}
+
+		// Admin routes.
+		admin := v1.Group("/admin", middleware.AdminAuthMiddleware(deps.AdminToken))
+		{
+			admin.GET("/reports/daily", deps.ReportHandler.GetDailyReport)
+			admin.GET("/reports/estimate-accuracy", deps.ReportHandler.GetEstimateAccuracy)
+			admin.POST("/campaigns", deps.CampaignHandler.Create)
+			admin.GET("/campaigns", deps.CampaignHandler.GetAll)
+			admin.GET("/campaigns/:id", deps.CampaignHandler.Get)
+			admin.PUT("/campaigns/:id", deps.CampaignHandler.Update)
+			admin.DELETE("/campaigns/:id", deps.CampaignHandler.Delete)
+			admin.POST("/rides/:id/assign", deps.RideHandler.AssignDriver)
+			admin.POST("/trips/:id/approve-fare", deps.TripHandler.ApproveFare)
+			admin.POST("/trips/:id/resolve-dispute", deps.TripHandler.ResolveDispute)
+			admin.POST("/trips/:id/adjust-fare", deps.TripHandler.AdjustFare)
+			admin.GET("/match-attempts", deps.MatchAttemptHandler.GetAll)
+			admin.POST("/drivers/import", deps.DriverImportHandler.Import)
+			admin.POST("/drivers/:id/unlock", deps.DriverLockHandler.Unlock)
+			admin.POST("/drivers/:id/tracker-key", deps.TrackerKeyHandler.Rotate)
+			admin.POST("/drivers/:id/tier", deps.DriverHandler.ChangeTier)
+			admin.GET("/drivers/:id/track", deps.DriverTrackHandler.GetTrack)
+			admin.POST("/drivers/:id/clear-speed-review", deps.SpeedGuardHandler.ClearReview)
+			admin.GET("/reports/speed-anomalies", deps.SpeedGuardHandler.GetAnomalies)
+			admin.GET("/map", deps.OpsMapHandler.GetMap)
+			admin.GET("/export/trips", deps.ExportHandler.ExportTrips)
+			admin.GET("/export/rides", deps.ExportHandler.ExportRides)
+			admin.GET("/flags", deps.FeatureFlagHandler.GetAll)
+			admin.PUT("/flags/:name", deps.FeatureFlagHandler.Set)
+			admin.GET("/redis/keys", deps.RedisKeysHandler.GetKeys)
+			admin.GET("/notifications/dead", deps.DeliveryHandler.ListDead)
+			admin.POST("/notifications/:id/requeue", deps.DeliveryHandler.Requeue)
+			if deps.FaultsHandler != nil {
+				admin.POST("/faults", deps.FaultsHandler.Set)
+				admin.GET("/faults", deps.FaultsHandler.GetAll)
+				admin.DELETE("/faults", deps.FaultsHandler.Clear)
+			}
+		}
 	}
 
-	return router
+	return router, nil
 }
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"ride/internal/repository/postgres"
+)
+
+// schemaColumnsQuery lists the columns of every table in the connection's
+// schema. information_schema is cheap to read and needs no extra grants.
+const schemaColumnsQuery = `
+	SELECT table_name, column_name, data_type
+	FROM information_schema.columns
+	WHERE table_schema = current_schema()
+`
+
+// SchemaCheck compares the database against the tables and columns the
+// repositories need, so schema drift stops a deploy at startup instead of
+// failing requests with "column does not exist". Each problem is logged;
+// a missing table or column, or one whose type the repositories cannot
+// scan, fails the check.
+func SchemaCheck(ctx context.Context, q postgres.Querier, expected []postgres.Table) error {
+	rows, err := q.QueryContext(ctx, schemaColumnsQuery)
+	if err != nil {
+		return fmt.Errorf("schema check: failed to read information_schema: %w", err)
+	}
+	defer rows.Close()
+
+	actual := make(map[string]map[string]string)
+	for rows.Next() {
+		var table, column, dataType string
+		if err := rows.Scan(&table, &column, &dataType); err != nil {
+			return fmt.Errorf("schema check: failed to read information_schema: %w", err)
+		}
+		if actual[table] == nil {
+			actual[table] = make(map[string]string)
+		}
+		actual[table][column] = dataType
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("schema check: failed to read information_schema: %w", err)
+	}
+
+	problems := schemaDiff(expected, actual)
+	if len(problems) == 0 {
+		log.Printf("[SCHEMA] Schema check passed for %d tables", len(expected))
+		return nil
+	}
+	for _, problem := range problems {
+		log.Printf("[SCHEMA] %s", problem)
+	}
+	return fmt.Errorf("schema check failed: %s", strings.Join(problems, "; "))
+}
+
+// schemaDiff describes each way actual, the columns' data types by table,
+// falls short of expected.
+func schemaDiff(expected []postgres.Table, actual map[string]map[string]string) []string {
+	var problems []string
+	for _, table := range expected {
+		columns, ok := actual[table.Name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("missing table %s", table.Name))
+			continue
+		}
+		for _, col := range table.Columns {
+			dataType, ok := columns[col.Name]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("missing column %s.%s (%s)", table.Name, col.Name, col.Type))
+				continue
+			}
+			if got := postgres.ColumnTypeOf(dataType); got != col.Type {
+				problems = append(problems, fmt.Sprintf("column %s.%s is %s, expected %s", table.Name, col.Name, dataType, col.Type))
+			}
+		}
+	}
+	return problems
+}
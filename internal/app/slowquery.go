@@ -0,0 +1,160 @@
+package app
+
+import (
+	"context"
+	"database/sql/driver"
+	"log"
+	"strings"
+	"time"
+)
+
+// slowQueryConnector wraps base so every connection it opens logs statements
+// that run longer than threshold. Only the statement text is logged, never
+// its arguments, which may hold personal data.
+func slowQueryConnector(base driver.Connector, threshold time.Duration) driver.Connector {
+	return &slowConnector{base: base, threshold: threshold}
+}
+
+type slowConnector struct {
+	base      driver.Connector
+	threshold time.Duration
+}
+
+func (c *slowConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.base.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &slowConn{Conn: conn, threshold: c.threshold}, nil
+}
+
+func (c *slowConnector) Driver() driver.Driver { return c.base.Driver() }
+
+// slowConn times the wrapped connection's queries and statements. Optional
+// driver interfaces the wrapped connection lacks are reported with
+// driver.ErrSkip so database/sql falls back as usual.
+type slowConn struct {
+	driver.Conn
+	threshold time.Duration
+}
+
+// logIfSlow logs query if it started longer than the threshold ago.
+func (c *slowConn) logIfSlow(query string, start time.Time) {
+	if elapsed := time.Since(start); elapsed > c.threshold {
+		log.Printf("[DB] Slow query (%v): %s", elapsed, strings.Join(strings.Fields(query), " "))
+	}
+}
+
+func (c *slowConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *slowConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c *slowConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *slowConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &slowStmt{Stmt: stmt, conn: c, query: query}, nil
+}
+
+func (c *slowConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip // database/sql prepares instead; the statement is timed
+	}
+	defer c.logIfSlow(query, time.Now())
+	return execer.ExecContext(ctx, query, args)
+}
+
+func (c *slowConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip // database/sql prepares instead; the statement is timed
+	}
+	defer c.logIfSlow(query, time.Now())
+	return queryer.QueryContext(ctx, query, args)
+}
+
+func (c *slowConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *slowConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *slowConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+func (c *slowConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// slowStmt times a prepared statement's executions.
+type slowStmt struct {
+	driver.Stmt
+	conn  *slowConn
+	query string
+}
+
+func (s *slowStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	defer s.conn.logIfSlow(s.query, time.Now())
+	if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+		return execer.ExecContext(ctx, args)
+	}
+	return s.Stmt.Exec(namedValues(args))
+}
+
+func (s *slowStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	defer s.conn.logIfSlow(s.query, time.Now())
+	if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		return queryer.QueryContext(ctx, args)
+	}
+	return s.Stmt.Query(namedValues(args))
+}
+
+func (s *slowStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// namedValues drops the names from positional arguments.
+func namedValues(args []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	return values
+}
@@ -1,24 +1,65 @@
 package config
 
 import (
+	"encoding/json"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
 // Config holds all configuration for the application.
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Redis    RedisConfig
-	NewRelic NewRelicConfig
+	Server       ServerConfig
+	Database     DatabaseConfig
+	Redis        RedisConfig
+	Matching     MatchingConfig
+	Deviation    DeviationConfig
+	History      LocationHistoryConfig
+	SpeedGuard   SpeedGuardConfig
+	TrackerAuth  TrackerAuthConfig
+	Export       ExportConfig
+	DriverImport DriverImportConfig
+	OpsMap       OpsMapConfig
+	Fare         FareConfig
+	Trip         TripConfig
+	PSP          PSPConfig
+	Catalog      CatalogConfig
+	Surge        SurgeConfig
+	Surcharge    SurchargeConfig
+	Payment      PaymentConfig
+	Email        EmailConfig
+	Quote        QuoteConfig
+	Estimator    EstimatorConfig
+	Phone        PhoneConfig
+	Notification NotificationConfig
+	NewRelic     NewRelicConfig
+	Faults       FaultsConfig
+	Flags        FeatureFlagConfig
+	Attachment   AttachmentConfig
+	Summary      SummaryConfig
+	Campaign     CampaignConfig
+
+	problems []string // Malformed values and file errors found by Load, reported by Validate
 }
 
 // ServerConfig holds HTTP server configuration.
 type ServerConfig struct {
-	Port         string
-	ReadTimeout  time.Duration
-	WriteTimeout time.Duration
+	Port                string
+	GRPCPort            string // Internal gRPC API; "0" disables it
+	Env                 string // development, staging or production
+	ReadTimeout         time.Duration
+	ReadHeaderTimeout   time.Duration
+	WriteTimeout        time.Duration
+	RequestTimeout      time.Duration // Deadline for each request's work; event streams and exports are exempt
+	MaxBodyBytes        int64         // Maximum request body size; larger bodies get 413
+	SSEHeartbeat        time.Duration // Interval between keep-alive comments on event streams
+	AdminToken          string        // Bearer token required on /v1/admin routes
+	GinMode             string        // debug, release or test; defaults to debug in development, release elsewhere
+	TrustedProxies      []string      // CIDRs or IPs allowed to set X-Forwarded-For; empty trusts none
+	AccessLogSkipPaths  []string      // Paths never access-logged
+	AccessLogSampleRate int           // Log 1 in N successful requests; errors are always logged
+	SlowRequest         time.Duration // Requests slower than this are logged with their route; 0 disables
 }
 
 // DatabaseConfig holds PostgreSQL configuration.
@@ -29,13 +70,261 @@ type DatabaseConfig struct {
 	Password string
 	DBName   string
 	SSLMode  string
+
+	// SchemaCheck compares the schema with the repositories' expectations at
+	// startup and refuses to start on missing or incompatible columns.
+	SchemaCheck bool
+
+	// SlowQueryThreshold logs statements that run longer than it; 0
+	// disables the log.
+	SlowQueryThreshold time.Duration
+
+	// StatementTimeout has Postgres cancel any statement running longer
+	// than it, so a runaway query cannot hold a pooled connection; 0
+	// disables it. The streaming CSV exports lift it for their own
+	// transaction.
+	StatementTimeout time.Duration
+
+	// CacheInvalidation listens for driver and ride writes made through any
+	// instance and drops this instance's cached copies. Off, or while the
+	// listener is disconnected, cached entries expire by TTL only.
+	CacheInvalidation bool
 }
 
 // RedisConfig holds Redis configuration.
 type RedisConfig struct {
-	Addr     string
-	Password string
-	DB       int
+	Addr      string
+	Password  string
+	DB        int
+	KeyPrefix string // Prepended to every key so deployments can share an instance
+
+	LocationMaxAge        time.Duration // Drivers without a location ping for this long are dropped from the geo indexes
+	LocationSweepInterval time.Duration // How often to look for drivers past LocationMaxAge
+}
+
+// MatchingConfig holds driver matching configuration.
+type MatchingConfig struct {
+	MaxCandidates        int               // Closest drivers attempted per match before giving up
+	DegradedFallback     bool              // Match ONLINE drivers near their last recorded position from the database when Redis is unreachable
+	BasicRadiusKm        float64           // Default search radius for BASIC ride requests
+	PremiumRadiusKm      float64           // Default search radius for PREMIUM ride requests
+	DefaultTier          string            // Tier for ride requests and driver registrations that give none, unless the catalog file sets one
+	ExclusionTTL         time.Duration     // How long a ride's excluded drivers are remembered; cover the longest a ride stays unmatched
+	DestinationAngleDeg  float64           // How far a ride's heading may stray from a destination-mode driver's direction home
+	DestinationTTL       time.Duration     // How long destination mode lasts without a match
+	MaxConcurrentPerArea int               // Assignment transactions run at once per ~5km pickup area; others queue. 0 disables
+	Regions              []GeoRegionConfig // Service regions; drivers are only matched to pickups in their region
+	RematchInterval      time.Duration     // How often rides still waiting for a driver are matched again
+	RematchBatchSize     int               // Waiting rides retried per interval, priority rides first
+	QueueWindow          time.Duration     // Recent matches of waiting rides an area's estimated wait is based on
+}
+
+// GeoRegionConfig is a bounding box, such as a city, whose drivers are kept
+// in their own geo index.
+type GeoRegionConfig struct {
+	Name   string  `json:"name"`
+	MinLat float64 `json:"min_lat"`
+	MinLng float64 `json:"min_lng"`
+	MaxLat float64 `json:"max_lat"`
+	MaxLng float64 `json:"max_lng"`
+}
+
+// DeviationConfig holds trip route deviation alert configuration.
+type DeviationConfig struct {
+	ThresholdKm      float64 // Distance from the pickup-destination line that counts as off route
+	ConsecutivePings int     // Off-route pings in a row before an alert is raised
+}
+
+// LocationHistoryConfig holds driver location history configuration.
+type LocationHistoryConfig struct {
+	BatchSize     int           // Points written per INSERT
+	FlushInterval time.Duration // Longest a queued point waits before being written
+	Retention     time.Duration // Points older than this are pruned
+}
+
+// SpeedGuardConfig holds driver location spoofing guard configuration.
+type SpeedGuardConfig struct {
+	MaxSpeedKmh  float64 // Average speed since the last update above which an update is rejected
+	MaxAnomalies int     // Rejected updates before a driver is held out of matching for review
+}
+
+// TrackerAuthConfig holds signed tracker location update configuration.
+type TrackerAuthConfig struct {
+	MaxSkew       time.Duration // How far a signed request's timestamp may be from the server's clock
+	EncryptionKey string        // Key tracker secrets are stored encrypted under; must match across instances
+}
+
+// ExportConfig holds admin CSV export configuration.
+type ExportConfig struct {
+	MaxRows int // Exports with more rows than this are refused
+}
+
+// DriverImportConfig holds admin bulk driver import configuration.
+type DriverImportConfig struct {
+	MaxRows int // Imports with more rows than this are refused
+}
+
+// OpsMapConfig holds admin live ops map configuration.
+type OpsMapConfig struct {
+	MaxPoints  int // Drivers or requests returned per layer; more are down-sampled
+	FetchLimit int // Drivers or requests read per layer before down-sampling
+}
+
+// FareConfig holds trip fare limits and the platform's commission.
+type FareConfig struct {
+	MinFare           float64 // Floor applied to every computed base fare, unless the tier sets its own
+	PremiumMinFare    float64 // Floor for the built-in PREMIUM tier; 0 uses MinFare
+	MaxFare           float64 // Fares above this are capped and held for admin review
+	CommissionPercent float64 // Platform's share of each fare, excluding surcharges and tips
+	Currency          string  // ISO 4217 code fares are charged and displayed in
+	Locale            string  // Locale amounts are formatted for, e.g. en-IN
+}
+
+// TripConfig holds trip arrival, start, abort, auto-end and rider confirmation configuration.
+type TripConfig struct {
+	PickupGeofenceKm   float64       // Furthest a driver may be from the pickup point when starting a trip
+	ArrivalRadiusKm    float64       // Distance from the pickup point at which the driver counts as arrived
+	ArrivalPings       int           // In-radius pings in a row before arrival is marked
+	DriverAbortFare    string        // What the rider pays when the driver aborts: NONE or ELAPSED
+	MaxDuration        time.Duration // STARTED trips running longer than this are auto-ended
+	SweepInterval      time.Duration // How often to look for trips past MaxDuration or ConfirmationWindow
+	ConfirmationWindow time.Duration // How long an ended CARD trip's fare is held for the rider to confirm; 0 captures at once
+	ETASpeedKmh        float64       // Average speed the ETA to the destination assumes
+}
+
+// PSPConfig holds payment provider timeout, retry and circuit breaker configuration.
+type PSPConfig struct {
+	Timeout          time.Duration // Bound on each charge attempt
+	MaxRetries       int           // Retries after a transient error; 0 disables
+	RetryBackoff     time.Duration // Wait before the first retry, growing linearly
+	BreakerThreshold int           // Consecutive failed charges that open the breaker
+	BreakerCooldown  time.Duration // How long an open breaker fails charges fast
+}
+
+// CatalogConfig holds the payment method and tier catalog configuration.
+type CatalogConfig struct {
+	File string // JSON catalog for the market; empty uses the built-in catalog
+}
+
+// CatalogFile is the JSON catalog a market is configured with, e.g.
+//
+//	{"payment_methods": ["UPI", "CASH", "CARD"], "default_payment_method": "UPI",
+//	 "tiers": [{"name": "AUTO", "radius_km": 3, "fare_multiplier": 0.6, "min_fare": 2.5}, {"name": "BASIC"}],
+//	 "default_tier": "AUTO"}
+//
+// Omitted fields keep the built-in catalog's values.
+type CatalogFile struct {
+	PaymentMethods       []string          `json:"payment_methods"`
+	DefaultPaymentMethod string            `json:"default_payment_method"`
+	Tiers                []CatalogTierFile `json:"tiers"`
+	DefaultTier          string            `json:"default_tier"`
+}
+
+// CatalogTierFile is one tier in a CatalogFile.
+type CatalogTierFile struct {
+	Name           string  `json:"name"`
+	RadiusKm       float64 `json:"radius_km"`       // Default matching radius; 0 uses the matching default
+	FareMultiplier float64 `json:"fare_multiplier"` // Applied to the metered fare; 0 means 1.0
+	MaxSurge       float64 `json:"max_surge"`       // Caps the surge multiplier; 0 means uncapped
+	MinFare        float64 `json:"min_fare"`        // Floor on the fare before surge; 0 uses FARE_MIN scaled by fare_multiplier
+}
+
+// LoadCatalogFile reads and parses the catalog file at path.
+func LoadCatalogFile(path string) (*CatalogFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file CatalogFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// SurgeConfig holds surge smoothing and scheduled floor configuration.
+type SurgeConfig struct {
+	Smoothing     float64       // Weight of a new surge reading, in (0, 1]; 1 disables smoothing
+	SmoothingTTL  time.Duration // Idle time after which a cell's smoothed surge resets to 1.0
+	Floors        []SurgeFloorConfig
+	FloorTimezone string // IANA zone the floor windows are in, e.g. Asia/Kolkata; empty uses the server's
+}
+
+// SurgeFloorConfig is a daily window, in "15:04" times of day, during which
+// surge is at least Multiplier. End before Start wraps past midnight.
+type SurgeFloorConfig struct {
+	Start      string  `json:"start"`
+	End        string  `json:"end"`
+	Multiplier float64 `json:"multiplier"`
+}
+
+// SurchargeConfig holds zone surcharge configuration.
+type SurchargeConfig struct {
+	Zones []SurchargeZoneConfig
+}
+
+// SurchargeZoneConfig is a bounding box, such as an airport, whose rides pay
+// a flat surcharge.
+type SurchargeZoneConfig struct {
+	Label  string  `json:"label"`
+	Amount float64 `json:"amount"`
+	MinLat float64 `json:"min_lat"`
+	MinLng float64 `json:"min_lng"`
+	MaxLat float64 `json:"max_lat"`
+	MaxLng float64 `json:"max_lng"`
+}
+
+// PaymentConfig holds payment configuration.
+type PaymentConfig struct {
+	Fees              map[string]PaymentFeeConfig // Processing fee passed on per payment method, e.g. "CARD"
+	AuthBufferPercent float64                     // Held on a CARD ride's card above its estimated fare when the trip starts
+}
+
+// PaymentFeeConfig is a processing fee: a percentage of the fare plus a flat
+// amount.
+type PaymentFeeConfig struct {
+	Percent float64 `json:"percent"`
+	Flat    float64 `json:"flat"`
+}
+
+// EmailConfig holds email verification configuration.
+type EmailConfig struct {
+	VerificationTTL time.Duration // How long a verification token stays valid
+	ResendCooldown  time.Duration // Minimum gap between verification emails per user
+}
+
+// QuoteConfig holds ride quote configuration.
+type QuoteConfig struct {
+	TTL        time.Duration // How long an estimate's surge is honored
+	SigningKey string        // HMAC key for quote IDs; must match across instances
+}
+
+// EstimatorConfig holds trip duration estimation configuration.
+type EstimatorConfig struct {
+	RadiusKm float64       // How far a past trip's pickup and destination may each be from the route's
+	Lookback time.Duration // How old a past trip may be
+	MinTrips int           // Past trips needed to trust their median over the average-speed model
+	MaxTrips int           // Most recent past trips considered
+}
+
+// PhoneConfig holds phone number handling configuration.
+type PhoneConfig struct {
+	DefaultRegion string // ISO 3166 region assumed for numbers without a country code, e.g. "US"
+}
+
+// NotificationConfig holds notification content configuration.
+type NotificationConfig struct {
+	ReceiptLinkTemplate    string        // App link to a receipt; "{receipt_id}" is replaced
+	RateDriverLinkTemplate string        // App link to rate a trip's driver; "{trip_id}" is replaced
+	EmailEnabled           bool          // Also email notifications to verified addresses, per each recipient's preferences
+	RideRequestedDrivers   int           // Nearest drivers, besides the assigned one, told about each new ride request
+	RideRequestedCooldown  time.Duration // Minimum gap between ride request notifications to one driver
+	DispatchInterval       time.Duration // How often queued channel deliveries are sent
+	DispatchBatchSize      int           // Most deliveries sent per pass
+	MaxAttempts            int           // Attempts before a delivery is dead-lettered
+	RetryBackoff           time.Duration // Wait after the first failed attempt, doubled after each further one
+	RetryMaxBackoff        time.Duration // Longest wait between attempts
+	DeadAlertThreshold     int           // Dead deliveries that alert the operations team; 0 disables the alert
 }
 
 // NewRelicConfig holds New Relic configuration.
@@ -45,65 +334,352 @@ type NewRelicConfig struct {
 	Enabled    bool
 }
 
-// Load loads configuration from environment variables.
+// FaultsConfig holds QA fault injection configuration.
+type FaultsConfig struct {
+	Enabled bool // Wrap Postgres and Redis with a runtime fault injector; ignored in release mode
+}
+
+// FeatureFlagConfig holds feature flag evaluation configuration.
+type FeatureFlagConfig struct {
+	City     string        // The city this deployment serves, matched against flag city allowlists
+	CacheTTL time.Duration // How long flags are cached before being reloaded from the database
+}
+
+// SummaryConfig holds driver weekly summary configuration.
+type SummaryConfig struct {
+	Interval time.Duration // How often to check whether last week's summaries are due
+}
+
+// CampaignConfig holds driver incentive campaign configuration.
+type CampaignConfig struct {
+	SweepInterval time.Duration // How often ONLINE_HOURS campaigns are checked for drivers who met the target
+}
+
+// AttachmentConfig holds trip photo configuration.
+type AttachmentConfig struct {
+	Store      string        // Blob store kind; only "filesystem" so far
+	Dir        string        // Directory the filesystem store keeps photos in
+	MaxBytes   int64         // Largest accepted upload; keep below SERVER_MAX_BODY_BYTES
+	SigningKey string        // HMAC key for attachment URLs; must match across instances
+	URLTTL     time.Duration // How long a signed attachment URL stays valid
+}
+
+// defaultGinMode returns the gin mode for an APP_ENV: debug, with its route
+// dump and verbose warnings, only in development.
+func defaultGinMode(env string) string {
+	if env == "development" {
+		return "debug"
+	}
+	return "release"
+}
+
+// Load loads configuration from environment variables and, when CONFIG_FILE
+// names one, a YAML or JSON file of the same settings; the environment takes
+// precedence. Malformed values fall back to their defaults and are reported
+// by Validate.
 func Load() *Config {
-	return &Config{
+	src := newSource(os.LookupEnv)
+	env := src.getEnv("APP_ENV", "production")
+	cfg := &Config{
 		Server: ServerConfig{
-			Port:         getEnv("SERVER_PORT", "8080"),
-			ReadTimeout:  getDurationEnv("SERVER_READ_TIMEOUT", 10*time.Second),
-			WriteTimeout: getDurationEnv("SERVER_WRITE_TIMEOUT", 10*time.Second),
+			Port:                src.getEnv("SERVER_PORT", "8080"),
+			GRPCPort:            src.getEnv("GRPC_PORT", "9090"),
+			Env:                 env,
+			ReadTimeout:         src.getDurationEnv("SERVER_READ_TIMEOUT", 10*time.Second),
+			ReadHeaderTimeout:   src.getDurationEnv("SERVER_READ_HEADER_TIMEOUT", 5*time.Second),
+			WriteTimeout:        src.getDurationEnv("SERVER_WRITE_TIMEOUT", 10*time.Second),
+			RequestTimeout:      src.getDurationEnv("SERVER_REQUEST_TIMEOUT", 8*time.Second),
+			MaxBodyBytes:        int64(src.getIntEnv("SERVER_MAX_BODY_BYTES", 1<<20)), // 1 MiB
+			SSEHeartbeat:        src.getDurationEnv("SERVER_SSE_HEARTBEAT", 15*time.Second),
+			AdminToken:          src.getEnv("ADMIN_API_TOKEN", ""),
+			GinMode:             src.getEnv("GIN_MODE", defaultGinMode(env)),
+			TrustedProxies:      src.getListEnv("SERVER_TRUSTED_PROXIES", nil),
+			AccessLogSkipPaths:  src.getListEnv("SERVER_ACCESS_LOG_SKIP_PATHS", []string{"/health", "/metrics"}),
+			AccessLogSampleRate: src.getIntEnv("SERVER_ACCESS_LOG_SAMPLE_RATE", 1),
+			SlowRequest:         src.getDurationEnv("SERVER_SLOW_REQUEST_THRESHOLD", 3*time.Second),
 		},
 		Database: DatabaseConfig{
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnv("DB_PORT", "5432"),
-			User:     getEnv("DB_USER", "postgres"),
-			Password: getEnv("DB_PASSWORD", "postgres"),
-			DBName:   getEnv("DB_NAME", "ride_hailing"),
-			SSLMode:  getEnv("DB_SSLMODE", "disable"),
+			Host:     src.getEnv("DB_HOST", "localhost"),
+			Port:     src.getEnv("DB_PORT", "5432"),
+			User:     src.getEnv("DB_USER", "postgres"),
+			Password: src.getEnv("DB_PASSWORD", "postgres"),
+			DBName:   src.getEnv("DB_NAME", "ride_hailing"),
+			SSLMode:  src.getEnv("DB_SSLMODE", "disable"),
+
+			SchemaCheck: src.getBoolEnv("DB_SCHEMA_CHECK", false),
+
+			SlowQueryThreshold: src.getDurationEnv("DB_SLOW_QUERY_THRESHOLD", time.Second),
+			StatementTimeout:   src.getDurationEnv("DB_STATEMENT_TIMEOUT", 30*time.Second),
+
+			CacheInvalidation: src.getBoolEnv("DB_CACHE_INVALIDATION", true),
 		},
 		Redis: RedisConfig{
-			Addr:     getEnv("REDIS_ADDR", "localhost:6379"),
-			Password: getEnv("REDIS_PASSWORD", ""),
-			DB:       getIntEnv("REDIS_DB", 0),
+			Addr:      src.getEnv("REDIS_ADDR", "localhost:6379"),
+			Password:  src.getEnv("REDIS_PASSWORD", ""),
+			DB:        src.getIntEnv("REDIS_DB", 0),
+			KeyPrefix: src.getEnv("REDIS_KEY_PREFIX", "ride:"),
+
+			LocationMaxAge:        src.getDurationEnv("REDIS_LOCATION_MAX_AGE", 10*time.Minute),
+			LocationSweepInterval: src.getDurationEnv("REDIS_LOCATION_SWEEP_INTERVAL", time.Minute),
+		},
+		Matching: MatchingConfig{
+			MaxCandidates:        src.getIntEnv("MATCHING_MAX_CANDIDATES", 25),
+			DegradedFallback:     src.getBoolEnv("MATCHING_DEGRADED_FALLBACK", false),
+			BasicRadiusKm:        src.getFloatEnv("MATCHING_RADIUS_KM_BASIC", 5.0),
+			PremiumRadiusKm:      src.getFloatEnv("MATCHING_RADIUS_KM_PREMIUM", 5.0),
+			DefaultTier:          src.getEnv("MATCHING_DEFAULT_TIER", "BASIC"),
+			ExclusionTTL:         src.getDurationEnv("MATCHING_EXCLUSION_TTL", 24*time.Hour),
+			DestinationAngleDeg:  src.getFloatEnv("MATCHING_DESTINATION_ANGLE_DEG", 45.0),
+			DestinationTTL:       src.getDurationEnv("MATCHING_DESTINATION_TTL", 2*time.Hour),
+			MaxConcurrentPerArea: src.getIntEnv("MATCHING_MAX_CONCURRENT_PER_AREA", 10),
+			Regions:              src.getGeoRegionsEnv("GEO_REGIONS"),
+			RematchInterval:      src.getDurationEnv("MATCHING_REMATCH_INTERVAL", 30*time.Second),
+			RematchBatchSize:     src.getIntEnv("MATCHING_REMATCH_BATCH_SIZE", 50),
+			QueueWindow:          src.getDurationEnv("MATCHING_QUEUE_THROUGHPUT_WINDOW", 15*time.Minute),
+		},
+		Deviation: DeviationConfig{
+			ThresholdKm:      src.getFloatEnv("ROUTE_DEVIATION_THRESHOLD_KM", 1.0),
+			ConsecutivePings: src.getIntEnv("ROUTE_DEVIATION_CONSECUTIVE_PINGS", 3),
+		},
+		History: LocationHistoryConfig{
+			BatchSize:     src.getIntEnv("LOCATION_HISTORY_BATCH_SIZE", 100),
+			FlushInterval: src.getDurationEnv("LOCATION_HISTORY_FLUSH_INTERVAL", 5*time.Second),
+			Retention:     src.getDurationEnv("LOCATION_HISTORY_RETENTION", 30*24*time.Hour),
+		},
+		SpeedGuard: SpeedGuardConfig{
+			MaxSpeedKmh:  src.getFloatEnv("LOCATION_MAX_SPEED_KMH", 200.0),
+			MaxAnomalies: src.getIntEnv("LOCATION_SPEED_ANOMALY_LIMIT", 5),
+		},
+		TrackerAuth: TrackerAuthConfig{
+			MaxSkew:       src.getDurationEnv("TRACKER_AUTH_MAX_SKEW", 5*time.Minute),
+			EncryptionKey: src.getEnv("TRACKER_KEY_ENCRYPTION_KEY", ""),
+		},
+		Export: ExportConfig{
+			MaxRows: src.getIntEnv("EXPORT_MAX_ROWS", 100000),
+		},
+		DriverImport: DriverImportConfig{
+			MaxRows: src.getIntEnv("DRIVER_IMPORT_MAX_ROWS", 500),
+		},
+		OpsMap: OpsMapConfig{
+			MaxPoints:  src.getIntEnv("OPS_MAP_MAX_POINTS", 500),
+			FetchLimit: src.getIntEnv("OPS_MAP_FETCH_LIMIT", 5000),
+		},
+		Fare: FareConfig{
+			MinFare:           src.getFloatEnv("FARE_MIN", 5.0),
+			PremiumMinFare:    src.getFloatEnv("FARE_MIN_PREMIUM", 8.0),
+			MaxFare:           src.getFloatEnv("FARE_MAX", 200.0),
+			CommissionPercent: src.getFloatEnv("FARE_COMMISSION_PERCENT", 20.0),
+			Currency:          src.getEnv("FARE_CURRENCY", "USD"),
+			Locale:            src.getEnv("FARE_LOCALE", "en-US"),
+		},
+		Trip: TripConfig{
+			PickupGeofenceKm:   src.getFloatEnv("TRIP_PICKUP_GEOFENCE_KM", 0.5),
+			ArrivalRadiusKm:    src.getFloatEnv("TRIP_ARRIVAL_RADIUS_KM", 0.075),
+			ArrivalPings:       src.getIntEnv("TRIP_ARRIVAL_CONSECUTIVE_PINGS", 2),
+			DriverAbortFare:    src.getEnv("TRIP_DRIVER_ABORT_FARE", "NONE"),
+			MaxDuration:        src.getDurationEnv("TRIP_MAX_DURATION", 6*time.Hour),
+			SweepInterval:      src.getDurationEnv("TRIP_SWEEP_INTERVAL", 5*time.Minute),
+			ConfirmationWindow: src.getDurationEnv("TRIP_CONFIRMATION_WINDOW", 0),
+			ETASpeedKmh:        src.getFloatEnv("TRIP_ETA_SPEED_KMH", 25.0),
+		},
+		PSP: PSPConfig{
+			Timeout:          src.getDurationEnv("PSP_TIMEOUT", 5*time.Second),
+			MaxRetries:       src.getIntEnv("PSP_MAX_RETRIES", 2),
+			RetryBackoff:     src.getDurationEnv("PSP_RETRY_BACKOFF", 200*time.Millisecond),
+			BreakerThreshold: src.getIntEnv("PSP_BREAKER_THRESHOLD", 5),
+			BreakerCooldown:  src.getDurationEnv("PSP_BREAKER_COOLDOWN", 30*time.Second),
+		},
+		Catalog: CatalogConfig{
+			File: src.getEnv("CATALOG_FILE", ""),
+		},
+		Surge: SurgeConfig{
+			Smoothing:    src.getFloatEnv("SURGE_SMOOTHING_FACTOR", 0.5),
+			SmoothingTTL: src.getDurationEnv("SURGE_SMOOTHING_TTL", 10*time.Minute),
+
+			Floors:        src.getSurgeFloorsEnv("SURGE_FLOORS"),
+			FloorTimezone: src.getEnv("SURGE_FLOOR_TIMEZONE", ""),
+		},
+		Surcharge: SurchargeConfig{
+			Zones: src.getSurchargeZonesEnv("SURCHARGE_ZONES"),
+		},
+		Payment: PaymentConfig{
+			Fees:              src.getPaymentFeesEnv("PAYMENT_FEES"),
+			AuthBufferPercent: src.getFloatEnv("PAYMENT_AUTH_BUFFER_PERCENT", 20),
+		},
+		Email: EmailConfig{
+			VerificationTTL: src.getDurationEnv("EMAIL_VERIFICATION_TTL", 24*time.Hour),
+			ResendCooldown:  src.getDurationEnv("EMAIL_RESEND_COOLDOWN", time.Minute),
+		},
+		Quote: QuoteConfig{
+			TTL:        src.getDurationEnv("RIDE_QUOTE_TTL", 2*time.Minute),
+			SigningKey: src.getEnv("RIDE_QUOTE_SIGNING_KEY", ""),
+		},
+		Estimator: EstimatorConfig{
+			RadiusKm: src.getFloatEnv("DURATION_ESTIMATE_RADIUS_KM", 0.5),
+			Lookback: src.getDurationEnv("DURATION_ESTIMATE_LOOKBACK", 30*24*time.Hour),
+			MinTrips: src.getIntEnv("DURATION_ESTIMATE_MIN_TRIPS", 5),
+			MaxTrips: src.getIntEnv("DURATION_ESTIMATE_MAX_TRIPS", 50),
+		},
+		Phone: PhoneConfig{
+			DefaultRegion: src.getEnv("PHONE_DEFAULT_REGION", "US"),
+		},
+		Notification: NotificationConfig{
+			ReceiptLinkTemplate:    src.getEnv("NOTIFICATION_RECEIPT_LINK", "ride://receipts/{receipt_id}"),
+			RateDriverLinkTemplate: src.getEnv("NOTIFICATION_RATE_DRIVER_LINK", "ride://trips/{trip_id}/rate"),
+			EmailEnabled:           src.getBoolEnv("NOTIFICATION_EMAIL_ENABLED", false),
+			RideRequestedDrivers:   src.getIntEnv("NOTIFICATION_RIDE_REQUESTED_DRIVERS", 5),
+			RideRequestedCooldown:  src.getDurationEnv("NOTIFICATION_RIDE_REQUESTED_COOLDOWN", time.Minute),
+			DispatchInterval:       src.getDurationEnv("NOTIFICATION_DISPATCH_INTERVAL", 5*time.Second),
+			DispatchBatchSize:      src.getIntEnv("NOTIFICATION_DISPATCH_BATCH_SIZE", 100),
+			MaxAttempts:            src.getIntEnv("NOTIFICATION_MAX_ATTEMPTS", 5),
+			RetryBackoff:           src.getDurationEnv("NOTIFICATION_RETRY_BACKOFF", 30*time.Second),
+			RetryMaxBackoff:        src.getDurationEnv("NOTIFICATION_RETRY_MAX_BACKOFF", time.Hour),
+			DeadAlertThreshold:     src.getIntEnv("NOTIFICATION_DEAD_ALERT_THRESHOLD", 10),
 		},
 		NewRelic: NewRelicConfig{
-			AppName:    getEnv("NEW_RELIC_APP_NAME", "ride-hailing-service"),
-			LicenseKey: getEnv("NEW_RELIC_LICENSE_KEY", ""),
-			Enabled:    getBoolEnv("NEW_RELIC_ENABLED", false),
+			AppName:    src.getEnv("NEW_RELIC_APP_NAME", "ride-hailing-service"),
+			LicenseKey: src.getEnv("NEW_RELIC_LICENSE_KEY", ""),
+			Enabled:    src.getBoolEnv("NEW_RELIC_ENABLED", false),
+		},
+		Faults: FaultsConfig{
+			Enabled: src.getBoolEnv("FAULTS_ENABLED", false),
+		},
+		Flags: FeatureFlagConfig{
+			City:     src.getEnv("FEATURE_FLAGS_CITY", ""),
+			CacheTTL: src.getDurationEnv("FEATURE_FLAGS_CACHE_TTL", 30*time.Second),
+		},
+		Attachment: AttachmentConfig{
+			Store:      src.getEnv("BLOB_STORE", "filesystem"),
+			Dir:        src.getEnv("BLOB_DIR", "./data/blobs"),
+			MaxBytes:   int64(src.getIntEnv("ATTACHMENT_MAX_BYTES", 512<<10)),
+			SigningKey: src.getEnv("ATTACHMENT_SIGNING_KEY", ""),
+			URLTTL:     src.getDurationEnv("ATTACHMENT_URL_TTL", 15*time.Minute),
+		},
+		Summary: SummaryConfig{
+			Interval: src.getDurationEnv("SUMMARY_INTERVAL", time.Hour),
+		},
+		Campaign: CampaignConfig{
+			SweepInterval: src.getDurationEnv("CAMPAIGN_SWEEP_INTERVAL", 5*time.Minute),
 		},
 	}
+	cfg.problems = src.finish()
+	return cfg
 }
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
+func (s *source) getEnv(key, defaultValue string) string {
+	if value, ok := s.lookup(key); ok && value != "" {
 		return value
 	}
 	return defaultValue
 }
 
-func getIntEnv(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		if intVal, err := strconv.Atoi(value); err == nil {
+func (s *source) getIntEnv(key string, defaultValue int) int {
+	if value, ok := s.lookup(key); ok && value != "" {
+		intVal, err := strconv.Atoi(value)
+		if err == nil {
 			return intVal
 		}
+		s.problemf("%s: %q is not an integer", key, value)
 	}
 	return defaultValue
 }
 
-func getBoolEnv(key string, defaultValue bool) bool {
-	if value := os.Getenv(key); value != "" {
-		if boolVal, err := strconv.ParseBool(value); err == nil {
+func (s *source) getFloatEnv(key string, defaultValue float64) float64 {
+	if value, ok := s.lookup(key); ok && value != "" {
+		floatVal, err := strconv.ParseFloat(value, 64)
+		if err == nil {
+			return floatVal
+		}
+		s.problemf("%s: %q is not a number", key, value)
+	}
+	return defaultValue
+}
+
+func (s *source) getBoolEnv(key string, defaultValue bool) bool {
+	if value, ok := s.lookup(key); ok && value != "" {
+		boolVal, err := strconv.ParseBool(value)
+		if err == nil {
 			return boolVal
 		}
+		s.problemf("%s: %q is not a boolean", key, value)
 	}
 	return defaultValue
 }
 
-func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
-	if value := os.Getenv(key); value != "" {
-		if duration, err := time.ParseDuration(value); err == nil {
+func (s *source) getDurationEnv(key string, defaultValue time.Duration) time.Duration {
+	if value, ok := s.lookup(key); ok && value != "" {
+		duration, err := time.ParseDuration(value)
+		if err == nil {
 			return duration
 		}
+		s.problemf("%s: %q is not a duration, e.g. 30s or 5m", key, value)
 	}
 	return defaultValue
 }
+
+// getListEnv parses a comma-separated list, dropping empty entries. Set the
+// variable to "," to configure an explicitly empty list.
+func (s *source) getListEnv(key string, defaultValue []string) []string {
+	value, ok := s.lookup(key)
+	if !ok || value == "" {
+		return defaultValue
+	}
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// getJSONEnv parses a JSON value into v, reporting false if it is malformed.
+func (s *source) getJSONEnv(key string, v any) bool {
+	if value, ok := s.lookup(key); ok && value != "" {
+		if err := json.Unmarshal([]byte(value), v); err != nil {
+			s.problemf("%s: invalid JSON: %v", key, err)
+			return false
+		}
+	}
+	return true
+}
+
+// getSurchargeZonesEnv parses a JSON array of surcharge zones. Unset or
+// malformed values configure no zones.
+func (s *source) getSurchargeZonesEnv(key string) []SurchargeZoneConfig {
+	var zones []SurchargeZoneConfig
+	if !s.getJSONEnv(key, &zones) {
+		return nil
+	}
+	return zones
+}
+
+// getSurgeFloorsEnv parses a JSON array of surge floors. Unset or malformed
+// values configure no floors.
+func (s *source) getSurgeFloorsEnv(key string) []SurgeFloorConfig {
+	var floors []SurgeFloorConfig
+	if !s.getJSONEnv(key, &floors) {
+		return nil
+	}
+	return floors
+}
+
+// getGeoRegionsEnv parses a JSON array of geo regions. Unset or malformed
+// values configure no regions.
+func (s *source) getGeoRegionsEnv(key string) []GeoRegionConfig {
+	var regions []GeoRegionConfig
+	if !s.getJSONEnv(key, &regions) {
+		return nil
+	}
+	return regions
+}
+
+// getPaymentFeesEnv parses a JSON object of processing fees keyed by payment
+// method. Unset or malformed values configure no fees.
+func (s *source) getPaymentFeesEnv(key string) map[string]PaymentFeeConfig {
+	var fees map[string]PaymentFeeConfig
+	if !s.getJSONEnv(key, &fees) {
+		return nil
+	}
+	return fees
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// source resolves settings by their environment variable names: from the
+// environment first, then from the CONFIG_FILE file, if any. It collects
+// every problem it meets instead of stopping at the first.
+type source struct {
+	env      func(key string) (string, bool)
+	file     map[string]string
+	used     map[string]bool // Settings Load asked for, to spot unknown file keys
+	problems []string
+}
+
+// newSource returns a source reading env and the file CONFIG_FILE names.
+func newSource(env func(key string) (string, bool)) *source {
+	s := &source{env: env, used: make(map[string]bool)}
+	if path, ok := env("CONFIG_FILE"); ok && path != "" {
+		file, err := readConfigFile(path)
+		if err != nil {
+			s.problemf("CONFIG_FILE: %v", err)
+		}
+		s.file = file
+	}
+	return s
+}
+
+// lookup returns the raw value of a setting, the environment taking
+// precedence over the file.
+func (s *source) lookup(key string) (string, bool) {
+	s.used[key] = true
+	if value, ok := s.env(key); ok {
+		return value, true
+	}
+	value, ok := s.file[key]
+	return value, ok
+}
+
+func (s *source) problemf(format string, args ...any) {
+	s.problems = append(s.problems, fmt.Sprintf(format, args...))
+}
+
+// finish reports file settings Load never asked for, most likely typos, and
+// returns every problem found.
+func (s *source) finish() []string {
+	var unknown []string
+	for key := range s.file {
+		if !s.used[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		s.problemf("CONFIG_FILE: unknown setting %s", key)
+	}
+	return s.problems
+}
+
+// readConfigFile reads a YAML (.yaml, .yml) or JSON (.json) file mapping
+// setting names, as in the environment, to values, e.g.
+//
+//	DB_PORT: 5432
+//	SERVER_READ_TIMEOUT: 10s
+//	SURGE_FLOORS: [{start: "08:00", end: "09:00", multiplier: 1.25}]
+//
+// Scalars are read as their text; lists and objects, for the settings that
+// take JSON, are re-encoded as JSON.
+func readConfigFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]any
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".json":
+		err = json.Unmarshal(data, &raw)
+	default:
+		return nil, fmt.Errorf("%s: unsupported format %q, want .yaml, .yml or .json", path, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	file := make(map[string]string, len(raw))
+	for key, value := range raw {
+		text, err := settingText(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", path, key, err)
+		}
+		file[key] = text
+	}
+	return file, nil
+}
+
+// settingText renders a decoded file value the way it would be written in
+// the environment.
+func settingText(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	default:
+		data, err := json.Marshal(v)
+		return string(data), err
+	}
+}
@@ -0,0 +1,267 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"ride/internal/money"
+)
+
+// ValidationError lists every problem found in a configuration.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration: " + strings.Join(e.Problems, "; ")
+}
+
+// Validate checks the configuration for malformed values, missing required
+// settings, out-of-range numbers and inconsistent combinations, returning a
+// *ValidationError listing all of them, or nil. Settings are named by their
+// environment variables.
+func (c *Config) Validate() error {
+	v := &validator{problems: append([]string(nil), c.problems...)}
+
+	// Required settings.
+	v.required("SERVER_PORT", c.Server.Port)
+	v.required("DB_HOST", c.Database.Host)
+	v.required("DB_PORT", c.Database.Port)
+	v.required("DB_USER", c.Database.User)
+	v.required("DB_NAME", c.Database.DBName)
+	v.required("REDIS_ADDR", c.Redis.Addr)
+
+	// Ports and addresses.
+	v.port("SERVER_PORT", c.Server.Port)
+	if c.Server.GRPCPort != "0" {
+		v.port("GRPC_PORT", c.Server.GRPCPort)
+		if c.Server.GRPCPort == c.Server.Port {
+			v.problemf("GRPC_PORT: %q is also SERVER_PORT", c.Server.GRPCPort)
+		}
+	}
+	v.port("DB_PORT", c.Database.Port)
+	if c.Redis.Addr != "" {
+		if _, port, err := net.SplitHostPort(c.Redis.Addr); err != nil {
+			v.problemf("REDIS_ADDR: %q is not host:port", c.Redis.Addr)
+		} else {
+			v.port("REDIS_ADDR", port)
+		}
+	}
+	v.oneOf("APP_ENV", c.Server.Env, "development", "staging", "production")
+	v.oneOf("GIN_MODE", c.Server.GinMode, "debug", "release", "test")
+	v.oneOf("DB_SSLMODE", c.Database.SSLMode, "disable", "allow", "prefer", "require", "verify-ca", "verify-full")
+	v.oneOf("TRIP_DRIVER_ABORT_FARE", c.Trip.DriverAbortFare, "NONE", "ELAPSED")
+	v.oneOf("BLOB_STORE", c.Attachment.Store, "filesystem")
+
+	// Timeouts, TTLs and intervals; settings where 0 disables a feature
+	// are only checked for being negative.
+	for _, d := range []struct {
+		key   string
+		value time.Duration
+	}{
+		{"SERVER_READ_TIMEOUT", c.Server.ReadTimeout},
+		{"SERVER_READ_HEADER_TIMEOUT", c.Server.ReadHeaderTimeout},
+		{"SERVER_WRITE_TIMEOUT", c.Server.WriteTimeout},
+		{"SERVER_SSE_HEARTBEAT", c.Server.SSEHeartbeat},
+		{"REDIS_LOCATION_MAX_AGE", c.Redis.LocationMaxAge},
+		{"REDIS_LOCATION_SWEEP_INTERVAL", c.Redis.LocationSweepInterval},
+		{"MATCHING_EXCLUSION_TTL", c.Matching.ExclusionTTL},
+		{"MATCHING_DESTINATION_TTL", c.Matching.DestinationTTL},
+		{"MATCHING_REMATCH_INTERVAL", c.Matching.RematchInterval},
+		{"MATCHING_QUEUE_THROUGHPUT_WINDOW", c.Matching.QueueWindow},
+		{"LOCATION_HISTORY_FLUSH_INTERVAL", c.History.FlushInterval},
+		{"LOCATION_HISTORY_RETENTION", c.History.Retention},
+		{"TRACKER_AUTH_MAX_SKEW", c.TrackerAuth.MaxSkew},
+		{"TRIP_MAX_DURATION", c.Trip.MaxDuration},
+		{"TRIP_SWEEP_INTERVAL", c.Trip.SweepInterval},
+		{"PSP_TIMEOUT", c.PSP.Timeout},
+		{"PSP_BREAKER_COOLDOWN", c.PSP.BreakerCooldown},
+		{"SURGE_SMOOTHING_TTL", c.Surge.SmoothingTTL},
+		{"EMAIL_VERIFICATION_TTL", c.Email.VerificationTTL},
+		{"RIDE_QUOTE_TTL", c.Quote.TTL},
+		{"DURATION_ESTIMATE_LOOKBACK", c.Estimator.Lookback},
+		{"FEATURE_FLAGS_CACHE_TTL", c.Flags.CacheTTL},
+		{"ATTACHMENT_URL_TTL", c.Attachment.URLTTL},
+		{"SUMMARY_INTERVAL", c.Summary.Interval},
+		{"CAMPAIGN_SWEEP_INTERVAL", c.Campaign.SweepInterval},
+		{"NOTIFICATION_DISPATCH_INTERVAL", c.Notification.DispatchInterval},
+		{"NOTIFICATION_RETRY_BACKOFF", c.Notification.RetryBackoff},
+	} {
+		if d.value <= 0 {
+			v.problemf("%s: must be positive, got %s", d.key, d.value)
+		}
+	}
+	for _, d := range []struct {
+		key   string
+		value time.Duration
+	}{
+		{"SERVER_REQUEST_TIMEOUT", c.Server.RequestTimeout},
+		{"SERVER_SLOW_REQUEST_THRESHOLD", c.Server.SlowRequest},
+		{"DB_SLOW_QUERY_THRESHOLD", c.Database.SlowQueryThreshold},
+		{"DB_STATEMENT_TIMEOUT", c.Database.StatementTimeout},
+		{"PSP_RETRY_BACKOFF", c.PSP.RetryBackoff},
+		{"TRIP_CONFIRMATION_WINDOW", c.Trip.ConfirmationWindow},
+		{"EMAIL_RESEND_COOLDOWN", c.Email.ResendCooldown},
+		{"NOTIFICATION_RIDE_REQUESTED_COOLDOWN", c.Notification.RideRequestedCooldown},
+	} {
+		if d.value < 0 {
+			v.problemf("%s: must not be negative, got %s", d.key, d.value)
+		}
+	}
+
+	// Sizes and counts.
+	v.atLeast("REDIS_DB", c.Redis.DB, 0)
+	v.atLeast("SERVER_ACCESS_LOG_SAMPLE_RATE", c.Server.AccessLogSampleRate, 1)
+	v.atLeast("MATCHING_MAX_CANDIDATES", c.Matching.MaxCandidates, 1)
+	v.atLeast("MATCHING_MAX_CONCURRENT_PER_AREA", c.Matching.MaxConcurrentPerArea, 0)
+	v.atLeast("MATCHING_REMATCH_BATCH_SIZE", c.Matching.RematchBatchSize, 1)
+	v.atLeast("ROUTE_DEVIATION_CONSECUTIVE_PINGS", c.Deviation.ConsecutivePings, 1)
+	v.atLeast("LOCATION_HISTORY_BATCH_SIZE", c.History.BatchSize, 1)
+	v.atLeast("LOCATION_SPEED_ANOMALY_LIMIT", c.SpeedGuard.MaxAnomalies, 1)
+	v.atLeast("EXPORT_MAX_ROWS", c.Export.MaxRows, 1)
+	v.atLeast("DRIVER_IMPORT_MAX_ROWS", c.DriverImport.MaxRows, 1)
+	v.atLeast("OPS_MAP_MAX_POINTS", c.OpsMap.MaxPoints, 1)
+	v.atLeast("OPS_MAP_FETCH_LIMIT", c.OpsMap.FetchLimit, c.OpsMap.MaxPoints)
+	v.atLeast("TRIP_ARRIVAL_CONSECUTIVE_PINGS", c.Trip.ArrivalPings, 1)
+	v.atLeast("PSP_MAX_RETRIES", c.PSP.MaxRetries, 0)
+	v.atLeast("DURATION_ESTIMATE_MIN_TRIPS", c.Estimator.MinTrips, 1)
+	v.atLeast("DURATION_ESTIMATE_MAX_TRIPS", c.Estimator.MaxTrips, c.Estimator.MinTrips)
+	v.atLeast("PSP_BREAKER_THRESHOLD", c.PSP.BreakerThreshold, 1)
+	v.atLeast("NOTIFICATION_RIDE_REQUESTED_DRIVERS", c.Notification.RideRequestedDrivers, 0)
+	v.atLeast("NOTIFICATION_DISPATCH_BATCH_SIZE", c.Notification.DispatchBatchSize, 1)
+	v.atLeast("NOTIFICATION_MAX_ATTEMPTS", c.Notification.MaxAttempts, 1)
+	v.atLeast("NOTIFICATION_DEAD_ALERT_THRESHOLD", c.Notification.DeadAlertThreshold, 0)
+	if c.Server.MaxBodyBytes < 0 {
+		v.problemf("SERVER_MAX_BODY_BYTES: must not be negative, got %d", c.Server.MaxBodyBytes)
+	}
+	if c.Attachment.MaxBytes <= 0 {
+		v.problemf("ATTACHMENT_MAX_BYTES: must be positive, got %d", c.Attachment.MaxBytes)
+	} else if c.Server.MaxBodyBytes > 0 && c.Attachment.MaxBytes > c.Server.MaxBodyBytes {
+		v.problemf("ATTACHMENT_MAX_BYTES: %d exceeds SERVER_MAX_BODY_BYTES %d, so uploads that size are refused first", c.Attachment.MaxBytes, c.Server.MaxBodyBytes)
+	}
+
+	// Distances, fares and percentages.
+	v.positive("MATCHING_RADIUS_KM_BASIC", c.Matching.BasicRadiusKm)
+	v.positive("MATCHING_RADIUS_KM_PREMIUM", c.Matching.PremiumRadiusKm)
+	v.positive("ROUTE_DEVIATION_THRESHOLD_KM", c.Deviation.ThresholdKm)
+	v.positive("LOCATION_MAX_SPEED_KMH", c.SpeedGuard.MaxSpeedKmh)
+	v.positive("TRIP_PICKUP_GEOFENCE_KM", c.Trip.PickupGeofenceKm)
+	v.positive("TRIP_ARRIVAL_RADIUS_KM", c.Trip.ArrivalRadiusKm)
+	v.positive("TRIP_ETA_SPEED_KMH", c.Trip.ETASpeedKmh)
+	v.positive("DURATION_ESTIMATE_RADIUS_KM", c.Estimator.RadiusKm)
+	v.between("MATCHING_DESTINATION_ANGLE_DEG", c.Matching.DestinationAngleDeg, 0, 180)
+	v.between("FARE_COMMISSION_PERCENT", c.Fare.CommissionPercent, 0, 100)
+	v.between("SURGE_SMOOTHING_FACTOR", c.Surge.Smoothing, 0, 1)
+	if c.Surge.Smoothing == 0 {
+		v.problemf("SURGE_SMOOTHING_FACTOR: must be above 0")
+	}
+	if c.Fare.MinFare < 0 {
+		v.problemf("FARE_MIN: must not be negative, got %g", c.Fare.MinFare)
+	}
+	if c.Fare.PremiumMinFare < 0 {
+		v.problemf("FARE_MIN_PREMIUM: must not be negative, got %g", c.Fare.PremiumMinFare)
+	}
+	if c.Fare.MaxFare < c.Fare.MinFare {
+		v.problemf("FARE_MAX: %g is below FARE_MIN %g", c.Fare.MaxFare, c.Fare.MinFare)
+	} else if c.Fare.MaxFare < c.Fare.PremiumMinFare {
+		v.problemf("FARE_MAX: %g is below FARE_MIN_PREMIUM %g", c.Fare.MaxFare, c.Fare.PremiumMinFare)
+	}
+	if c.Payment.AuthBufferPercent < 0 {
+		v.problemf("PAYMENT_AUTH_BUFFER_PERCENT: must not be negative, got %g", c.Payment.AuthBufferPercent)
+	}
+
+	if _, err := money.NewFormatter(c.Fare.Currency, c.Fare.Locale); err != nil {
+		v.problemf("FARE_CURRENCY, FARE_LOCALE: %v", err)
+	}
+
+	// Surge floors.
+	if c.Surge.FloorTimezone != "" {
+		if _, err := time.LoadLocation(c.Surge.FloorTimezone); err != nil {
+			v.problemf("SURGE_FLOOR_TIMEZONE: unknown time zone %q", c.Surge.FloorTimezone)
+		}
+	}
+	for i, f := range c.Surge.Floors {
+		for _, t := range []string{f.Start, f.End} {
+			if _, err := time.Parse("15:04", t); err != nil {
+				v.problemf("SURGE_FLOORS[%d]: %q is not a 15:04 time of day", i, t)
+			}
+		}
+		if f.Multiplier < 1 {
+			v.problemf("SURGE_FLOORS[%d]: multiplier %g is below 1", i, f.Multiplier)
+		}
+	}
+
+	// Settings that depend on each other.
+	if c.NewRelic.Enabled && c.NewRelic.LicenseKey == "" {
+		v.problemf("NEW_RELIC_LICENSE_KEY: required when NEW_RELIC_ENABLED is true")
+	}
+	if c.Server.Env == "production" && c.Server.GinMode == "debug" {
+		v.problemf("GIN_MODE: debug is not allowed when APP_ENV is production")
+	}
+	if c.Notification.RetryMaxBackoff < c.Notification.RetryBackoff {
+		v.problemf("NOTIFICATION_RETRY_MAX_BACKOFF: %s is below NOTIFICATION_RETRY_BACKOFF %s", c.Notification.RetryMaxBackoff, c.Notification.RetryBackoff)
+	}
+	if c.Notification.EmailEnabled && c.Email.VerificationTTL <= 0 {
+		v.problemf("NOTIFICATION_EMAIL_ENABLED: requires a positive EMAIL_VERIFICATION_TTL")
+	}
+
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: v.problems}
+}
+
+// validator collects problems found by Validate.
+type validator struct {
+	problems []string
+}
+
+func (v *validator) problemf(format string, args ...any) {
+	v.problems = append(v.problems, fmt.Sprintf(format, args...))
+}
+
+func (v *validator) required(key, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.problemf("%s: required", key)
+	}
+}
+
+// port checks a TCP port number, skipping empty values reported as missing.
+func (v *validator) port(key, value string) {
+	if value == "" {
+		return
+	}
+	if n, err := strconv.Atoi(value); err != nil || n < 1 || n > 65535 {
+		v.problemf("%s: %q is not a port number between 1 and 65535", key, value)
+	}
+}
+
+func (v *validator) oneOf(key, value string, allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	v.problemf("%s: %q is not one of %s", key, value, strings.Join(allowed, ", "))
+}
+
+func (v *validator) atLeast(key string, value, min int) {
+	if value < min {
+		v.problemf("%s: must be at least %d, got %d", key, min, value)
+	}
+}
+
+func (v *validator) positive(key string, value float64) {
+	if value <= 0 {
+		v.problemf("%s: must be positive, got %g", key, value)
+	}
+}
+
+func (v *validator) between(key string, value, min, max float64) {
+	if value < min || value > max {
+		v.problemf("%s: must be between %g and %g, got %g", key, min, max, value)
+	}
+}
@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// TripAttachment is a photo a driver attached to a trip, e.g. proof of
+// delivery for a package ride. The image itself lives in the blob store
+// under BlobKey.
+type TripAttachment struct {
+	ID          string
+	TripID      string
+	UploadedBy  string // Driver who attached it
+	BlobKey     string
+	ContentType string // e.g. "image/jpeg"
+	SizeBytes   int64
+	CreatedAt   time.Time
+}
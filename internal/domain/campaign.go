@@ -0,0 +1,114 @@
+package domain
+
+import "time"
+
+// CampaignCriteria is the measure a driver incentive campaign counts.
+type CampaignCriteria string
+
+const (
+	// CampaignCriteriaTripCount counts trips ended within the window.
+	CampaignCriteriaTripCount CampaignCriteria = "TRIP_COUNT"
+	// CampaignCriteriaTripHours counts hours on trip (excluding pauses) within
+	// the window.
+	CampaignCriteriaTripHours CampaignCriteria = "TRIP_HOURS"
+	// CampaignCriteriaOnlineHours counts hours online within the window, on
+	// trip or not, from the driver's location history: every 5-minute slot
+	// with at least one location update counts.
+	CampaignCriteriaOnlineHours CampaignCriteria = "ONLINE_HOURS"
+)
+
+// IsValid reports whether the criteria is a known campaign criteria.
+func (c CampaignCriteria) IsValid() bool {
+	return c == CampaignCriteriaTripCount || c == CampaignCriteriaTripHours || c == CampaignCriteriaOnlineHours
+}
+
+// CountsTrips reports whether progress is counted as trips end. Online hours
+// are measured from location history instead.
+func (c CampaignCriteria) CountsTrips() bool {
+	return c == CampaignCriteriaTripCount || c == CampaignCriteriaTripHours
+}
+
+// Campaign is a driver incentive such as "complete 10 trips today, earn a $20 bonus".
+type Campaign struct {
+	ID           string
+	Name         string
+	Criteria     CampaignCriteria
+	Target       float64 // Trips or hours needed to earn the reward
+	RewardAmount float64
+	Tier         DriverTier // Empty means every tier is eligible
+	City         string     // Normalized, see NormalizeCity; empty means every city is eligible
+	StartsAt     time.Time
+	EndsAt       time.Time
+	CreatedAt    time.Time
+}
+
+// IsActiveAt reports whether t falls within the campaign window [StartsAt, EndsAt).
+func (c *Campaign) IsActiveAt(t time.Time) bool {
+	return !t.Before(c.StartsAt) && t.Before(c.EndsAt)
+}
+
+// IsEligible reports whether the driver's tier and city let them take part.
+// A driver without a city is only eligible for campaigns open to every city.
+func (c *Campaign) IsEligible(driver *Driver) bool {
+	return (c.Tier == "" || c.Tier == driver.Tier) && (c.City == "" || c.City == driver.City)
+}
+
+// ProgressFor returns how much a trip contributes towards the campaign target.
+func (c *Campaign) ProgressFor(trip *Trip) float64 {
+	if c.Criteria == CampaignCriteriaTripHours {
+		return (trip.EndedAt.Sub(trip.StartedAt) - trip.TotalPaused).Hours()
+	}
+	return 1
+}
+
+// Validate checks the campaign's invariants before it is persisted.
+func (c *Campaign) Validate() error {
+	if c.Name == "" {
+		return ErrInvalidCampaignName
+	}
+	if !c.Criteria.IsValid() {
+		return ErrInvalidCampaignCriteria
+	}
+	if c.Target <= 0 {
+		return ErrInvalidCampaignTarget
+	}
+	if c.RewardAmount <= 0 {
+		return ErrInvalidCampaignReward
+	}
+	if c.StartsAt.IsZero() || !c.EndsAt.After(c.StartsAt) {
+		return ErrInvalidCampaignWindow
+	}
+	return nil
+}
+
+// CampaignProgress is a driver's progress towards one campaign.
+type CampaignProgress struct {
+	CampaignID  string
+	DriverID    string
+	Progress    float64
+	CompletedAt time.Time // Zero until the reward has been credited
+}
+
+// IsCompleted reports whether the reward has been credited.
+func (p *CampaignProgress) IsCompleted() bool {
+	return !p.CompletedAt.IsZero()
+}
+
+// EarningsKind classifies a driver earnings ledger entry.
+type EarningsKind string
+
+const (
+	EarningsKindCampaignBonus EarningsKind = "CAMPAIGN_BONUS"
+	EarningsKindTip           EarningsKind = "TIP" // A rider's tip on a trip, kept by the driver in full
+)
+
+// EarningsEntry is a credit in a driver's earnings ledger. Reference is unique
+// per entry, so crediting the same reference twice records it once.
+type EarningsEntry struct {
+	ID        string
+	DriverID  string
+	Kind      EarningsKind
+	Amount    float64
+	Reference string
+	CreatedAt time.Time
+}
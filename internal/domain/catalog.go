@@ -0,0 +1,117 @@
+package domain
+
+import "strings"
+
+// TierSpec is a service tier offered in a market, such as BASIC or AUTO.
+type TierSpec struct {
+	Name           DriverTier
+	RadiusKm       float64 // Default matching radius; 0 uses the matching default
+	FareMultiplier float64 // Applied to the metered fare; 0 means 1.0
+	MaxSurge       float64 // Caps the surge multiplier; 0 means uncapped
+	MinFare        float64 // Floor on the fare before surge; 0 uses FARE_MIN scaled by FareMultiplier
+}
+
+// Catalog is the set of payment methods and service tiers a market offers.
+// Names are stored upper-case and matched case-insensitively.
+type Catalog struct {
+	PaymentMethods       []PaymentMethod
+	DefaultPaymentMethod PaymentMethod // Used when a ride names no method
+	Tiers                []TierSpec
+	DefaultTier          DriverTier // Used when a ride or registration names no tier
+}
+
+// DefaultCatalog returns the catalog used when none is configured: every
+// built-in payment method with CASH as the default, and BASIC and PREMIUM
+// tiers with BASIC as the default.
+func DefaultCatalog() *Catalog {
+	return &Catalog{
+		PaymentMethods:       []PaymentMethod{PaymentMethodCash, PaymentMethodCard, PaymentMethodWallet, PaymentMethodUPI},
+		DefaultPaymentMethod: PaymentMethodCash,
+		Tiers:                []TierSpec{{Name: DriverTierBasic}, {Name: DriverTierPremium}},
+		DefaultTier:          DriverTierBasic,
+	}
+}
+
+// Validate checks that the catalog offers at least one payment method and
+// tier, that names are unique, and that both defaults are offered.
+func (c *Catalog) Validate() error {
+	if len(c.PaymentMethods) == 0 || len(c.Tiers) == 0 {
+		return ErrInvalidCatalog
+	}
+
+	methods := make(map[PaymentMethod]bool, len(c.PaymentMethods))
+	for _, method := range c.PaymentMethods {
+		if method == "" || method != PaymentMethod(strings.ToUpper(string(method))) || methods[method] {
+			return ErrInvalidCatalog
+		}
+		methods[method] = true
+	}
+	if !methods[c.DefaultPaymentMethod] {
+		return ErrInvalidCatalog
+	}
+
+	tiers := make(map[DriverTier]bool, len(c.Tiers))
+	for _, tier := range c.Tiers {
+		if tier.Name == "" || tier.Name != DriverTier(strings.ToUpper(string(tier.Name))) || tiers[tier.Name] {
+			return ErrInvalidCatalog
+		}
+		if tier.RadiusKm < 0 || tier.FareMultiplier < 0 || tier.MinFare < 0 || (tier.MaxSurge != 0 && tier.MaxSurge < 1.0) {
+			return ErrInvalidCatalog
+		}
+		tiers[tier.Name] = true
+	}
+	if !tiers[c.DefaultTier] {
+		return ErrInvalidCatalog
+	}
+	return nil
+}
+
+// PaymentMethod returns the offered payment method named by s, any case.
+// Empty s means the default method.
+func (c *Catalog) PaymentMethod(s string) (PaymentMethod, bool) {
+	method := PaymentMethod(strings.ToUpper(strings.TrimSpace(s)))
+	if method == "" {
+		return c.DefaultPaymentMethod, true
+	}
+	for _, offered := range c.PaymentMethods {
+		if offered == method {
+			return method, true
+		}
+	}
+	return "", false
+}
+
+// Tier returns the offered tier named by s, any case. Empty s means the
+// default tier.
+func (c *Catalog) Tier(s string) (TierSpec, bool) {
+	name := DriverTier(strings.ToUpper(strings.TrimSpace(s)))
+	if name == "" {
+		name = c.DefaultTier
+	}
+	for _, tier := range c.Tiers {
+		if tier.Name == name {
+			return tier, true
+		}
+	}
+	return TierSpec{}, false
+}
+
+// HasPaymentMethod reports whether method is offered exactly as given.
+func (c *Catalog) HasPaymentMethod(method PaymentMethod) bool {
+	for _, offered := range c.PaymentMethods {
+		if offered == method {
+			return true
+		}
+	}
+	return false
+}
+
+// HasTier reports whether tier is offered exactly as given.
+func (c *Catalog) HasTier(tier DriverTier) bool {
+	for _, offered := range c.Tiers {
+		if offered.Name == tier {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+// RouteDeviationAlert records a driver straying from the straight-line
+// pickup-to-destination corridor for several consecutive location pings.
+type RouteDeviationAlert struct {
+	ID               string
+	TripID           string
+	RideID           string
+	DriverID         string
+	Lat              float64
+	Lng              float64
+	DistanceKm       float64 // Distance from the route at the triggering ping
+	ConsecutivePings int
+	CreatedAt        time.Time
+}
@@ -1,5 +1,11 @@
 package domain
 
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
 // DriverStatus represents the current status of a driver.
 type DriverStatus string
 
@@ -7,6 +13,7 @@ const (
 	DriverStatusOnline  DriverStatus = "ONLINE"
 	DriverStatusOffline DriverStatus = "OFFLINE"
 	DriverStatusOnTrip  DriverStatus = "ON_TRIP"
+	DriverStatusBreak   DriverStatus = "BREAK" // On the map but receiving no offers
 )
 
 // DriverTier represents the service tier of a driver.
@@ -19,9 +26,89 @@ const (
 
 // Driver represents a driver in the system.
 type Driver struct {
-	ID     string
-	Name   string
-	Phone  string
-	Status DriverStatus
-	Tier   DriverTier
+	ID            string
+	Name          string
+	Phone         string
+	Email         string // Optional; empty when not provided
+	EmailVerified bool
+	Status        DriverStatus
+	Tier          DriverTier
+	VehiclePlate  string    // Optional; empty when not provided
+	City          string    // Optional: the city the driver works in, normalized, see NormalizeCity
+	Capabilities  []string  // Vehicle capabilities, e.g. WAV; normalized, see NormalizeCapabilities
+	CreatedAt     time.Time // Set when the driver is stored
+	UpdatedAt     time.Time // Set on every stored change
+}
+
+// DriverTierChange records an admin moving a driver to another tier.
+type DriverTierChange struct {
+	ID           string
+	DriverID     string
+	PreviousTier DriverTier
+	Tier         DriverTier
+	ChangedAt    time.Time
+}
+
+// CapabilityWAV marks a wheelchair-accessible vehicle.
+const CapabilityWAV = "WAV"
+
+// knownCapabilities maps each vehicle capability to whether it serves an
+// accessibility need. Rides requiring an accessibility capability are
+// matched with priority.
+var knownCapabilities = map[string]bool{
+	CapabilityWAV: true,
+}
+
+// NormalizeCity trims and lowercases a city name, so "Bengaluru " and
+// "bengaluru" name the same city.
+func NormalizeCity(city string) string {
+	return strings.ToLower(strings.TrimSpace(city))
+}
+
+// NormalizeCapabilities uppercases, deduplicates and sorts a capability set.
+// Empty input yields nil.
+func NormalizeCapabilities(capabilities []string) ([]string, error) {
+	seen := make(map[string]bool, len(capabilities))
+	var normalized []string
+	for _, c := range capabilities {
+		c = strings.ToUpper(strings.TrimSpace(c))
+		if _, ok := knownCapabilities[c]; !ok {
+			return nil, ErrInvalidCapability
+		}
+		if seen[c] {
+			continue
+		}
+		seen[c] = true
+		normalized = append(normalized, c)
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+// HasCapabilities reports whether capabilities include every required one.
+func HasCapabilities(capabilities, required []string) bool {
+	for _, r := range required {
+		found := false
+		for _, c := range capabilities {
+			if c == r {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// NeedsAccessibility reports whether any of the required capabilities
+// serves an accessibility need.
+func NeedsAccessibility(required []string) bool {
+	for _, r := range required {
+		if knownCapabilities[r] {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,69 @@
+package domain
+
+import "math"
+
+// TripEarnings breaks down what a driver made on an ended or aborted trip.
+// The platform's commission is taken from the fare excluding surcharges,
+// which pass through to the driver, and never from tips.
+type TripEarnings struct {
+	TripID            string
+	DriverID          string
+	PaymentMethod     PaymentMethod
+	GrossFare         float64 // The trip's fare, before any processing fee
+	SurgeMultiplier   float64 // As persisted on the ride
+	SurgeAmount       float64 // Part of GrossFare due to surge
+	SurchargeAmount   float64 // Part of GrossFare from zone surcharges, e.g. tolls
+	CommissionPercent float64
+	Commission        float64
+	Tip               float64
+	NetEarnings       float64 // GrossFare - Commission + Tip
+
+	// CashCollected is the fare a CASH trip's driver took from the rider;
+	// 0 for other methods, whose fare the platform collects.
+	CashCollected float64
+
+	// Payout is what the platform owes the driver for the trip, NetEarnings
+	// less CashCollected. It is negative when the driver holds cash and owes
+	// the commission.
+	Payout float64
+}
+
+// NewTripEarnings computes a trip's earnings with the given commission
+// percentage and tip.
+func NewTripEarnings(trip *Trip, ride *Ride, commissionPercent, tip float64) TripEarnings {
+	surgeMultiplier := ride.SurgeMultiplier
+	if surgeMultiplier < 1.0 {
+		surgeMultiplier = 1.0
+	}
+
+	// An aborted trip never reaches the surcharge zone.
+	surcharge := ride.SurchargeAmount
+	if trip.Status == TripStatusAborted || surcharge > trip.Fare {
+		surcharge = 0
+	}
+	fare := trip.Fare - surcharge
+
+	earnings := TripEarnings{
+		TripID:            trip.ID,
+		DriverID:          trip.DriverID,
+		PaymentMethod:     ride.PaymentMethod,
+		GrossFare:         roundCents(trip.Fare),
+		SurgeMultiplier:   surgeMultiplier,
+		SurgeAmount:       roundCents(fare - fare/surgeMultiplier),
+		SurchargeAmount:   roundCents(surcharge),
+		CommissionPercent: commissionPercent,
+		Commission:        roundCents(fare * commissionPercent / 100),
+		Tip:               roundCents(tip),
+	}
+	earnings.NetEarnings = roundCents(earnings.GrossFare - earnings.Commission + earnings.Tip)
+	if ride.PaymentMethod == PaymentMethodCash {
+		earnings.CashCollected = earnings.GrossFare
+	}
+	earnings.Payout = roundCents(earnings.NetEarnings - earnings.CashCollected)
+	return earnings
+}
+
+// roundCents rounds an amount to the cent.
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
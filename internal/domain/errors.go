@@ -0,0 +1,95 @@
+package domain
+
+import "errors"
+
+var (
+	// ErrInvalidRiderID is returned when a ride has no rider.
+	ErrInvalidRiderID = errors.New("invalid rider id")
+
+	// ErrInvalidRideID is returned when an entity is missing its ride reference.
+	ErrInvalidRideID = errors.New("invalid ride id")
+
+	// ErrInvalidDriverID is returned when an entity is missing its driver reference.
+	ErrInvalidDriverID = errors.New("invalid driver id")
+
+	// ErrInvalidTripID is returned when a trip has no ID.
+	ErrInvalidTripID = errors.New("invalid trip id")
+
+	// ErrInvalidPickupLocation is returned when pickup coordinates are invalid.
+	ErrInvalidPickupLocation = errors.New("invalid pickup location")
+
+	// ErrInvalidDestinationLocation is returned when destination coordinates are invalid.
+	ErrInvalidDestinationLocation = errors.New("invalid destination location")
+
+	// ErrInvalidRideStatus is returned when a ride carries an unknown status.
+	ErrInvalidRideStatus = errors.New("invalid ride status")
+
+	// ErrInvalidRideType is returned when a ride is neither PASSENGER nor PACKAGE.
+	ErrInvalidRideType = errors.New("invalid ride type")
+
+	// ErrInvalidCapability is returned for an unknown vehicle capability.
+	ErrInvalidCapability = errors.New("invalid vehicle capability")
+
+	// ErrInvalidTripStatus is returned when a trip carries an unknown status.
+	ErrInvalidTripStatus = errors.New("invalid trip status")
+
+	// ErrInvalidPauseReason is returned when a trip carries an unknown pause reason.
+	ErrInvalidPauseReason = errors.New("invalid pause reason")
+
+	// ErrInvalidTripConfirmation is returned when a trip carries an unknown
+	// confirmation state, or one while not ENDED.
+	ErrInvalidTripConfirmation = errors.New("invalid trip confirmation")
+
+	// ErrInvalidAbortParty is returned when an aborted trip names neither the rider nor the driver.
+	ErrInvalidAbortParty = errors.New("invalid abort party")
+
+	// ErrInvalidSurgeMultiplier is returned when a surge multiplier is below 1.0.
+	ErrInvalidSurgeMultiplier = errors.New("invalid surge multiplier")
+
+	// ErrInvalidSurcharge is returned when a surcharge amount is negative.
+	ErrInvalidSurcharge = errors.New("invalid surcharge")
+
+	// ErrInvalidTripTimestamps is returned when trip timestamps are inconsistent.
+	ErrInvalidTripTimestamps = errors.New("invalid trip timestamps")
+
+	// ErrInvalidFare is returned when a fare is negative.
+	ErrInvalidFare = errors.New("invalid fare")
+
+	// ErrInvalidEmail is returned when an email address is malformed.
+	ErrInvalidEmail = errors.New("invalid email")
+
+	// ErrInvalidPaymentInstrument is returned when an instrument is for cash
+	// or an unknown type, or lacks its token or masked details.
+	ErrInvalidPaymentInstrument = errors.New("invalid payment instrument")
+
+	// ErrInvalidCampaignName is returned when a campaign has no name.
+	ErrInvalidCampaignName = errors.New("invalid campaign name")
+
+	// ErrInvalidCampaignCriteria is returned when a campaign carries an unknown criteria.
+	ErrInvalidCampaignCriteria = errors.New("invalid campaign criteria")
+
+	// ErrInvalidCampaignTarget is returned when a campaign target is not positive.
+	ErrInvalidCampaignTarget = errors.New("invalid campaign target")
+
+	// ErrInvalidCampaignReward is returned when a campaign reward is not positive.
+	ErrInvalidCampaignReward = errors.New("invalid campaign reward")
+
+	// ErrInvalidCampaignTier is returned when a campaign is limited to an unknown tier.
+	ErrInvalidCampaignTier = errors.New("invalid campaign tier")
+
+	// ErrInvalidCatalog is returned when a payment method and tier catalog is unusable.
+	ErrInvalidCatalog = errors.New("invalid catalog")
+
+	// ErrInvalidCampaignWindow is returned when a campaign window is missing or ends before it starts.
+	ErrInvalidCampaignWindow = errors.New("invalid campaign window")
+
+	// ErrInvalidFeatureFlagName is returned when a feature flag has no name.
+	ErrInvalidFeatureFlagName = errors.New("invalid feature flag name")
+
+	// ErrInvalidFeatureFlagPercentage is returned when a rollout percentage is outside 0-100.
+	ErrInvalidFeatureFlagPercentage = errors.New("invalid feature flag percentage")
+
+	// ErrInvalidFareSplit is returned when a fare split has fewer than two
+	// riders, repeats or leaves out a rider, or mixes weighted and equal shares.
+	ErrInvalidFareSplit = errors.New("invalid fare split")
+)
@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+// EventType names a domain event published to downstream systems.
+type EventType string
+
+const (
+	EventRideCreated      EventType = "ride.created"
+	EventRideAssigned     EventType = "ride.assigned"
+	EventTripStarted      EventType = "trip.started"
+	EventTripEnded        EventType = "trip.ended"
+	EventPaymentSucceeded EventType = "payment.succeeded"
+)
+
+// Event is a state transition published to downstream systems such as
+// analytics, fraud and accounting. IDs are unique per event, so consumers
+// can drop duplicates on redelivery.
+type Event struct {
+	ID          string
+	Type        EventType
+	AggregateID string // ID of the ride, trip or payment the event is about
+	OccurredAt  time.Time
+	Data        map[string]any
+}
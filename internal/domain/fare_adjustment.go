@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// FareAdjustment records an admin's correction of an ended trip's fare, e.g.
+// after a rider disputes a wrong route. The difference is charged to or
+// refunded on the ride's payment method.
+type FareAdjustment struct {
+	ID           string
+	TripID       string
+	PreviousFare float64
+	Fare         float64 // The corrected fare
+	Reason       string
+	PaymentID    string    // The extra charge or refund; empty if it could not be made
+	CreatedAt    time.Time // Set when the adjustment is stored
+}
+
+// Difference returns what the adjustment moves, rounded to the cent:
+// positive to charge the rider, negative to refund them.
+func (a *FareAdjustment) Difference() float64 {
+	return math.Round((a.Fare-a.PreviousFare)*100) / 100
+}
@@ -0,0 +1,135 @@
+package domain
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// FareSplit divides a trip's fare between the accounts of riders travelling
+// together. The ride's rider owns the split: their share is charged like an
+// unsplit fare, and any other share that fails to pay is added to it.
+type FareSplit struct {
+	TripID    string
+	OwnerID   string // The ride's rider
+	Shares    []FareShare
+	CreatedAt time.Time // Set when the split is stored
+	UpdatedAt time.Time // Set on every stored change
+}
+
+// FareShare is one rider's part of a split fare.
+type FareShare struct {
+	RiderID   string
+	Weight    float64 // Relative to the other shares; 0 on every share splits the fare equally
+	Amount    float64 // The rider's part of the fare, set when it is settled
+	PaymentID string  // Payment charging the share, set when it is settled
+
+	// Absorbed is set when the share failed to pay and the owner was
+	// charged for it instead; the rider still owes it and needs following up.
+	Absorbed bool
+}
+
+// Validate checks that the split names at least two distinct riders,
+// including its owner, and that either every share is weighted or none is.
+func (s *FareSplit) Validate() error {
+	if s.TripID == "" {
+		return ErrInvalidTripID
+	}
+	if s.OwnerID == "" {
+		return ErrInvalidRiderID
+	}
+	if len(s.Shares) < 2 {
+		return ErrInvalidFareSplit
+	}
+
+	seen := make(map[string]bool, len(s.Shares))
+	weighted := s.Shares[0].Weight > 0
+	for _, share := range s.Shares {
+		if share.RiderID == "" || seen[share.RiderID] {
+			return ErrInvalidFareSplit
+		}
+		seen[share.RiderID] = true
+		if share.Weight < 0 || math.IsNaN(share.Weight) || math.IsInf(share.Weight, 0) || (share.Weight > 0) != weighted {
+			return ErrInvalidFareSplit
+		}
+	}
+	if !seen[s.OwnerID] {
+		return ErrInvalidFareSplit
+	}
+	return nil
+}
+
+// Share returns the rider's share, or nil if the split does not include them.
+func (s *FareSplit) Share(riderID string) *FareShare {
+	for i := range s.Shares {
+		if s.Shares[i].RiderID == riderID {
+			return &s.Shares[i]
+		}
+	}
+	return nil
+}
+
+// Apportion divides the fare between the shares in proportion to their
+// weights, or equally when none is weighted, and sets each share's Amount.
+// Amounts are whole cents that sum exactly to the fare: every share gets its
+// proportion rounded down, and the cents left over go one each to the
+// shares that lost the most in rounding, earlier shares first on a tie.
+func (s *FareSplit) Apportion(fare float64) {
+	weights := make([]float64, len(s.Shares))
+	for i, share := range s.Shares {
+		weights[i] = share.Weight
+	}
+	for i, amount := range SplitAmount(fare, weights) {
+		s.Shares[i].Amount = amount
+	}
+}
+
+// SplitAmount divides total into whole-cent parts in proportion to weights,
+// equally if the weights are all zero, by the largest remainder method. The
+// parts always sum to total rounded to the cent.
+func SplitAmount(total float64, weights []float64) []float64 {
+	parts := make([]float64, len(weights))
+	if len(weights) == 0 {
+		return parts
+	}
+
+	var sum float64
+	for _, w := range weights {
+		sum += w
+	}
+	equal := sum <= 0
+	if equal {
+		sum = float64(len(weights))
+	}
+
+	cents := int64(math.Round(total * 100))
+	floors := make([]int64, len(weights))
+	remainders := make([]float64, len(weights))
+	left := cents
+	for i, w := range weights {
+		if equal {
+			w = 1
+		}
+		exact := float64(cents) * w / sum
+		floors[i] = int64(math.Floor(exact))
+		remainders[i] = exact - float64(floors[i])
+		left -= floors[i]
+	}
+
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return remainders[order[a]] > remainders[order[b]]
+	})
+	for i := 0; left > 0; i = (i + 1) % len(order) {
+		floors[order[i]]++
+		left--
+	}
+
+	for i, c := range floors {
+		parts[i] = float64(c) / 100
+	}
+	return parts
+}
@@ -0,0 +1,129 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// earthRadiusKm is the mean Earth radius used for great-circle distances.
+const earthRadiusKm = 6371.0
+
+// IsValidLatitude reports whether lat is within [-90, 90].
+func IsValidLatitude(lat float64) bool {
+	return lat >= -90 && lat <= 90
+}
+
+// IsValidLongitude reports whether lng is within [-180, 180].
+func IsValidLongitude(lng float64) bool {
+	return lng >= -180 && lng <= 180
+}
+
+// IsValidHeading reports whether heading is a compass bearing within [0, 360] degrees.
+func IsValidHeading(heading float64) bool {
+	return heading >= 0 && heading <= 360
+}
+
+// IsValidCoordinate reports whether the lat/lng pair is a valid position.
+func IsValidCoordinate(lat, lng float64) bool {
+	return IsValidLatitude(lat) && IsValidLongitude(lng)
+}
+
+// HaversineKm returns the great-circle distance between two points in kilometers.
+func HaversineKm(lat1, lng1, lat2, lng2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRad(lat2 - lat1)
+	dLng := toRad(lng2 - lng1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
+
+// SpeedKmh returns the average speed, in km/h, of covering distanceKm in
+// elapsed. Any distance covered in no time at all is infinitely fast.
+func SpeedKmh(distanceKm float64, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		if distanceKm > 0 {
+			return math.Inf(1)
+		}
+		return 0
+	}
+	return distanceKm / elapsed.Hours()
+}
+
+// BearingDeg returns the initial compass bearing, in degrees within [0, 360),
+// of the great-circle path from the first point to the second.
+func BearingDeg(lat1, lng1, lat2, lng2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLng := toRad(lng2 - lng1)
+	y := math.Sin(dLng) * math.Cos(toRad(lat2))
+	x := math.Cos(toRad(lat1))*math.Sin(toRad(lat2)) - math.Sin(toRad(lat1))*math.Cos(toRad(lat2))*math.Cos(dLng)
+	return math.Mod(math.Atan2(y, x)*180/math.Pi+360, 360)
+}
+
+// BearingDiffDeg returns the angle between two compass bearings, in degrees
+// within [0, 180].
+func BearingDiffDeg(a, b float64) float64 {
+	diff := math.Mod(math.Abs(a-b), 360)
+	if diff > 180 {
+		diff = 360 - diff
+	}
+	return diff
+}
+
+// DistanceToSegmentKm returns the distance in kilometers from a point to the
+// straight segment between A and B. Over route-scale distances an
+// equirectangular projection around the point is accurate enough.
+func DistanceToSegmentKm(lat, lng, aLat, aLng, bLat, bLng float64) float64 {
+	kmPerDegLat := earthRadiusKm * math.Pi / 180
+	kmPerDegLng := kmPerDegLat * math.Cos(lat*math.Pi/180)
+
+	// Planar coordinates relative to the point, in kilometers.
+	ax, ay := (aLng-lng)*kmPerDegLng, (aLat-lat)*kmPerDegLat
+	bx, by := (bLng-lng)*kmPerDegLng, (bLat-lat)*kmPerDegLat
+
+	dx, dy := bx-ax, by-ay
+	lengthSq := dx*dx + dy*dy
+	if lengthSq == 0 {
+		return math.Hypot(ax, ay)
+	}
+
+	// Project the point (the origin) onto AB, clamped to the segment.
+	t := math.Max(0, math.Min(1, -(ax*dx+ay*dy)/lengthSq))
+	return math.Hypot(ax+t*dx, ay+t*dy)
+}
+
+// BoundingBox is a latitude/longitude rectangle, edges included. Boxes that
+// cross the antimeridian are not supported.
+type BoundingBox struct {
+	MinLat float64
+	MinLng float64
+	MaxLat float64
+	MaxLng float64
+}
+
+// IsValid reports whether both corners are valid positions and the minimum
+// corner lies south-west of the maximum.
+func (b BoundingBox) IsValid() bool {
+	return IsValidCoordinate(b.MinLat, b.MinLng) && IsValidCoordinate(b.MaxLat, b.MaxLng) &&
+		b.MinLat < b.MaxLat && b.MinLng < b.MaxLng
+}
+
+// Contains reports whether the point lies inside the box, edges included.
+func (b BoundingBox) Contains(lat, lng float64) bool {
+	return lat >= b.MinLat && lat <= b.MaxLat && lng >= b.MinLng && lng <= b.MaxLng
+}
+
+// BoxAround returns the smallest box holding every point within radiusKm of
+// the given one, for narrowing a search before measuring distances.
+func BoxAround(lat, lng, radiusKm float64) BoundingBox {
+	dLat := radiusKm / earthRadiusKm * 180 / math.Pi
+	dLng := dLat / math.Max(math.Cos(lat*math.Pi/180), 0.01)
+	return BoundingBox{
+		MinLat: math.Max(lat-dLat, -90),
+		MinLng: math.Max(lng-dLng, -180),
+		MaxLat: math.Min(lat+dLat, 90),
+		MaxLng: math.Min(lng+dLng, 180),
+	}
+}
@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+// LocationPoint is one recorded driver position, kept for track replay and
+// analytics. Unlike the live geo index it is append-only.
+type LocationPoint struct {
+	DriverID   string
+	Lat        float64
+	Lng        float64
+	Heading    float64
+	RecordedAt time.Time
+}
+
+// PathDistanceKm returns the length of the path through points, in order.
+func PathDistanceKm(points []*LocationPoint) float64 {
+	var km float64
+	for i := 1; i < len(points); i++ {
+		km += HaversineKm(points[i-1].Lat, points[i-1].Lng, points[i].Lat, points[i].Lng)
+	}
+	return km
+}
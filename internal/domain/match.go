@@ -0,0 +1,39 @@
+package domain
+
+import "time"
+
+// MatchOutcome is how a matching call ended.
+type MatchOutcome string
+
+const (
+	MatchOutcomeMatched  MatchOutcome = "MATCHED"
+	MatchOutcomeNoDriver MatchOutcome = "NO_DRIVER"
+	MatchOutcomeFailed   MatchOutcome = "FAILED" // Error before or during assignment
+
+	// MatchOutcomeCandidatesExhausted means the candidate cap was reached
+	// without an assignment; drivers beyond the cap were not tried.
+	MatchOutcomeCandidatesExhausted MatchOutcome = "CANDIDATES_EXHAUSTED"
+)
+
+// MatchAttempt summarizes one matching call, successful or not, so that a
+// failed match can be explained after the fact.
+type MatchAttempt struct {
+	ID                string
+	RideID            string
+	Tier              DriverTier // Requested tier; empty means any
+	RadiusKm          float64
+	CandidatesFound   int // Drivers returned by the location search, or the database when degraded
+	SkippedOffline    int // Not ONLINE: offline, on a trip or on a break
+	SkippedTier       int
+	SkippedLocked     int  // Held by another matching call
+	SkippedStale      int  // Location entry with no loadable driver, or status changed since cached
+	SkippedExcluded   int  // Excluded from the ride, e.g. blocked by the rider
+	SkippedDirection  int  // In destination mode, and the ride heads away from the driver's destination
+	SkippedCapability int  // Vehicle lacks a capability the ride requires
+	Degraded          bool // Redis was unreachable; candidates came from the database
+	Outcome           MatchOutcome
+	AssignedDriverID  string
+	Error             string
+	Duration          time.Duration
+	CreatedAt         time.Time
+}
@@ -0,0 +1,74 @@
+package domain
+
+import "time"
+
+// NotificationEvent is a notification as recorded in the outbox and streamed
+// to clients. IDs increase monotonically, so they double as SSE event IDs.
+type NotificationEvent struct {
+	ID          int64
+	RecipientID string
+	Type        string
+	Title       string
+	Message     string
+	Data        map[string]any
+	CreatedAt   time.Time
+}
+
+// NotificationChannel is a way a notification reaches its recipient.
+type NotificationChannel string
+
+const (
+	NotificationChannelPush  NotificationChannel = "PUSH"  // The app's notification feed and live stream
+	NotificationChannelEmail NotificationChannel = "EMAIL" // The recipient's verified email address
+)
+
+// NotificationChannels lists every channel, in display order.
+var NotificationChannels = []NotificationChannel{NotificationChannelPush, NotificationChannelEmail}
+
+// IsValid reports whether the channel is a known notification channel.
+func (c NotificationChannel) IsValid() bool {
+	for _, channel := range NotificationChannels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// NotificationPreference turns one notification type on or off on one
+// channel for a recipient. Types and channels without a preference are on.
+type NotificationPreference struct {
+	RecipientID string
+	Type        string
+	Channel     NotificationChannel
+	Enabled     bool
+	UpdatedAt   time.Time
+}
+
+// NotificationDeliveryStatus is where a queued delivery is in its retries.
+type NotificationDeliveryStatus string
+
+const (
+	NotificationDeliveryPending NotificationDeliveryStatus = "PENDING" // Waiting for its next attempt
+	NotificationDeliverySent    NotificationDeliveryStatus = "SENT"
+	NotificationDeliveryDead    NotificationDeliveryStatus = "DEAD" // Out of attempts; only requeued by an admin
+)
+
+// NotificationDelivery is a notification queued for delivery over one
+// channel besides push, retried with backoff until it is sent or runs out
+// of attempts.
+type NotificationDelivery struct {
+	ID            int64
+	Channel       NotificationChannel
+	RecipientID   string
+	Type          string
+	Title         string
+	Message       string
+	Data          map[string]any
+	Status        NotificationDeliveryStatus
+	Attempts      int       // Failed attempts since it was queued or last requeued
+	NextAttemptAt time.Time // When a PENDING delivery is next tried
+	LastError     string    // Why the last attempt failed
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
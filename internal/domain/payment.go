@@ -1,19 +1,61 @@
 package domain
 
+import (
+	"math"
+	"time"
+)
+
 // PaymentStatus represents the current status of a payment.
 type PaymentStatus string
 
 const (
-	PaymentStatusPending PaymentStatus = "PENDING"
-	PaymentStatusSuccess PaymentStatus = "SUCCESS"
-	PaymentStatusFailed  PaymentStatus = "FAILED"
+	PaymentStatusPending  PaymentStatus = "PENDING"
+	PaymentStatusSuccess  PaymentStatus = "SUCCESS"
+	PaymentStatusFailed   PaymentStatus = "FAILED"
+	PaymentStatusRefunded PaymentStatus = "REFUNDED"
+	PaymentStatusCashDue  PaymentStatus = "CASH_DUE" // Cash the driver has yet to confirm collecting
+
+	// Card payments hold an estimate while the trip runs: AUTHORIZED until
+	// the final fare is captured (SUCCESS) or the hold is released (VOIDED).
+	PaymentStatusAuthorized PaymentStatus = "AUTHORIZED"
+	PaymentStatusVoided     PaymentStatus = "VOIDED"
+)
+
+// Failure reasons of FAILED payments the PSP gave no reason for.
+const (
+	PaymentFailureDeclined      = "payment declined"
+	PaymentFailureProviderError = "payment provider unavailable" // The charge did not get an answer; the instrument is not at fault
 )
 
 // Payment represents a payment for a trip.
 type Payment struct {
 	ID             string
 	TripID         string
-	Amount         float64
+	Amount         float64 // Total charged, including Fee; the amount held while AUTHORIZED
+	Fee            float64 // Processing fee passed on for the payment method; 0 for cash
 	Status         PaymentStatus
 	IdempotencyKey string
+	InstrumentID   string    // Instrument charged; empty for cash
+	FailureReason  string    // Why a FAILED payment failed: the PSP's decline reason or PaymentFailureProviderError
+	AuthID         string    // PSP reference of the hold on the card; empty unless authorized
+	CreatedAt      time.Time // Set when the payment is stored
+	UpdatedAt      time.Time // Set on every stored change
+}
+
+// Declined reports whether the payment failed because the PSP refused the
+// charge, so retrying needs a different or updated payment method.
+func (p *Payment) Declined() bool {
+	return p.Status == PaymentStatusFailed && p.FailureReason != PaymentFailureProviderError
+}
+
+// ProcessingFee is the processor fee passed on to riders paying with a
+// method: a percentage of the fare plus a flat amount.
+type ProcessingFee struct {
+	Percent float64 // Percentage of the fare, e.g. 2.9 for 2.9%
+	Flat    float64
+}
+
+// Amount returns the fee on fare, rounded to the cent.
+func (f ProcessingFee) Amount(fare float64) float64 {
+	return math.Round((fare*f.Percent/100+f.Flat)*100) / 100
 }
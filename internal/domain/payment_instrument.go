@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// PaymentInstrument is a card, wallet or UPI account a user has on file.
+// Cash needs no instrument.
+type PaymentInstrument struct {
+	ID            string
+	UserID        string
+	Type          PaymentMethod // CARD, WALLET or UPI
+	Token         string        // PSP token charged for this instrument; never returned by the API
+	MaskedDetails string        // Display form, e.g. "VISA •••• 4242"
+	IsDefault     bool          // Charged for rides paid by this type unless another is chosen
+	CreatedAt     time.Time
+	UpdatedAt     time.Time // Set on every stored change
+}
+
+// Validate checks the instrument's required fields.
+func (i *PaymentInstrument) Validate() error {
+	if i.UserID == "" {
+		return ErrInvalidRiderID
+	}
+	if i.Type == "" || i.Type == PaymentMethodCash {
+		return ErrInvalidPaymentInstrument
+	}
+	if strings.TrimSpace(i.Token) == "" || strings.TrimSpace(i.MaskedDetails) == "" {
+		return ErrInvalidPaymentInstrument
+	}
+	return nil
+}
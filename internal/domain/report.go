@@ -0,0 +1,96 @@
+package domain
+
+import "time"
+
+// AmountSummary is a count and total amount for a class of payments.
+type AmountSummary struct {
+	Count  int
+	Amount float64
+}
+
+// DailyTotals holds the raw aggregates for trips ended within one day.
+type DailyTotals struct {
+	TripsEnded         int
+	GrossFares         float64
+	SuccessfulPayments AmountSummary
+	FailedPayments     AmountSummary
+	PendingPayments    AmountSummary
+	CashAwaiting       AmountSummary // CASH_DUE payments not yet confirmed collected
+	Refunds            AmountSummary
+}
+
+// FareDiscrepancy is a trip whose fare is not matched by its payments.
+type FareDiscrepancy struct {
+	TripID          string
+	Fare            float64
+	AccountedAmount float64 // Sum of SUCCESS, FAILED, PENDING and CASH_DUE payments, less REFUNDED ones
+}
+
+// DailyReport is the end-of-day reconciliation report.
+type DailyReport struct {
+	Date            time.Time
+	Totals          DailyTotals
+	AccountedAmount float64 // successful + failed + pending + cash awaiting - refunds
+	Difference      float64 // GrossFares - AccountedAmount
+	Balanced        bool
+	Discrepancies   []FareDiscrepancy
+}
+
+// EstimateAccuracy compares the fare range riders were shown when requesting
+// with the fares their trips ended with, to calibrate the estimate.
+type EstimateAccuracy struct {
+	Date        time.Time
+	Trips       int // ENDED trips whose ride carried an estimate
+	WithinRange int
+	BelowRange  int     // Fare under the low end
+	AboveRange  int     // Fare over the high end
+	ErrorP50    float64 // Percentiles of the range midpoint's distance from the fare, as a percentage of the fare
+	ErrorP90    float64
+	ErrorP99    float64
+}
+
+// OutstandingCollection is a cash payment a driver has yet to confirm collecting.
+type OutstandingCollection struct {
+	PaymentID string
+	TripID    string
+	Amount    float64
+	EndedAt   time.Time
+}
+
+// CollectionsReport lists a driver's outstanding cash collections.
+type CollectionsReport struct {
+	DriverID    string
+	Total       AmountSummary
+	Collections []OutstandingCollection
+}
+
+// LedgerAnomalyKind classifies a ledger consistency problem.
+type LedgerAnomalyKind string
+
+const (
+	LedgerTripWithoutPayment      LedgerAnomalyKind = "TRIP_WITHOUT_PAYMENT"      // ENDED trip with a fare and no payment
+	LedgerTripMultiplePayments    LedgerAnomalyKind = "TRIP_MULTIPLE_PAYMENTS"    // Trip with more than one payment
+	LedgerPaymentWithoutTrip      LedgerAnomalyKind = "PAYMENT_WITHOUT_TRIP"      // SUCCESS payment whose trip is missing or not finished
+	LedgerAmountMismatch          LedgerAnomalyKind = "AMOUNT_MISMATCH"           // Payment, net of fee, differs from the trip fare
+	LedgerDuplicateIdempotencyKey LedgerAnomalyKind = "DUPLICATE_IDEMPOTENCY_KEY" // Several payments share an idempotency key
+)
+
+// LedgerAnomaly is one inconsistency between trips and payments. Fields that
+// do not apply to its kind are left empty.
+type LedgerAnomaly struct {
+	Kind           LedgerAnomalyKind
+	TripID         string
+	PaymentID      string
+	IdempotencyKey string
+	Fare           float64 // Trip fare, for amount mismatches
+	NetAmount      float64 // Payment amount less its fee, for amount mismatches
+	Detail         string  // e.g. "trip status STARTED" or "3 payments"
+}
+
+// LedgerReport is the result of the ledger consistency check.
+type LedgerReport struct {
+	CheckedAt  time.Time
+	Since      time.Time // Zero when the whole ledger was checked
+	Anomalies  []LedgerAnomaly
+	Consistent bool
+}
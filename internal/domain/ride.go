@@ -23,6 +23,27 @@ const (
 	PaymentMethodUPI    PaymentMethod = "UPI"
 )
 
+// RideType distinguishes carrying a rider from carrying a package.
+type RideType string
+
+const (
+	RideTypePassenger RideType = "PASSENGER"
+	RideTypePackage   RideType = "PACKAGE"
+)
+
+// IsValid reports whether the type is a known ride type.
+func (t RideType) IsValid() bool {
+	return t == RideTypePassenger || t == RideTypePackage
+}
+
+// RideAssigner records how a ride's driver was chosen.
+type RideAssigner string
+
+const (
+	RideAssignedByMatching RideAssigner = "MATCHING" // Nearest eligible driver, found by matching
+	RideAssignedByAdmin    RideAssigner = "ADMIN"    // Chosen by a dispatcher
+)
+
 // Ride represents a ride request in the system.
 type Ride struct {
 	ID               string
@@ -33,9 +54,102 @@ type Ride struct {
 	DestinationLng   float64
 	Status           RideStatus
 	AssignedDriverID string
+	AssignedBy       RideAssigner  // Empty until assigned, and on rides assigned before it was recorded
 	SurgeMultiplier  float64       // 1.0 = no surge, 1.5 = 50% surge, 2.0 = 100% surge
 	PaymentMethod    PaymentMethod // Payment method for this ride
+	Tier             DriverTier    // Requested tier; empty means any
+	Type             RideType      // Empty means PASSENGER
+	Capabilities     []string      // Vehicle capabilities the driver must have, e.g. WAV
+	Priority         bool          // Matched before other waiting rides; set for accessibility needs
+	InstrumentID     string        // Instrument charged for a non-CASH ride; empty for cash
+	SurchargeLabel   string        // e.g. "Airport fee"; empty when no surcharge applies
+	SurchargeAmount  float64       // Flat amount added to the fare, e.g. tolls
+	EstimateLow      float64       // Estimated fare range quoted when the ride was requested
+	EstimateHigh     float64       // Zero on rides requested before estimates were recorded
+	QuoteID          string        // Quote whose surge priced the ride; empty when priced live
+	RebookedFrom     string        // Ride this one repeats; empty unless rebooked
 	CreatedAt        time.Time
+	UpdatedAt        time.Time // Set on every stored change
+	RequestedAt      time.Time // Lifecycle timestamps; zero until the transition happens
+	AssignedAt       time.Time
+	ArrivedAt        time.Time // Driver reached the pickup point
+	CompletedAt      time.Time
 	CancelledAt      time.Time
 	CancelReason     string
+	Version          int // Optimistic concurrency token; bumped on every update
+}
+
+// rideTransitions encodes the ride state machine: each status maps to the
+// set of statuses it may move to. Terminal statuses have no entries. An
+// IN_TRIP ride is only cancelled by aborting its trip.
+var rideTransitions = map[RideStatus][]RideStatus{
+	RideStatusRequested: {RideStatusAssigned, RideStatusCancelled},
+	RideStatusAssigned:  {RideStatusInTrip, RideStatusCancelled},
+	RideStatusInTrip:    {RideStatusCompleted, RideStatusCancelled},
+	RideStatusCompleted: {},
+	RideStatusCancelled: {},
+}
+
+// IsValid reports whether the status is a known ride status.
+func (s RideStatus) IsValid() bool {
+	_, ok := rideTransitions[s]
+	return ok
+}
+
+// IsTerminal reports whether a ride in this status is over, COMPLETED or
+// CANCELLED, and can move no further.
+func (s RideStatus) IsTerminal() bool {
+	allowed, ok := rideTransitions[s]
+	return ok && len(allowed) == 0
+}
+
+// CanTransitionTo reports whether the ride may move from its current status to next.
+func (r *Ride) CanTransitionTo(next RideStatus) bool {
+	for _, allowed := range rideTransitions[r.Status] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the ride's invariants before it is persisted.
+func (r *Ride) Validate() error {
+	if r.ID == "" {
+		return ErrInvalidRideID
+	}
+	if r.RiderID == "" {
+		return ErrInvalidRiderID
+	}
+	if !IsValidCoordinate(r.PickupLat, r.PickupLng) {
+		return ErrInvalidPickupLocation
+	}
+	if !IsValidCoordinate(r.DestinationLat, r.DestinationLng) {
+		return ErrInvalidDestinationLocation
+	}
+	if !r.Status.IsValid() {
+		return ErrInvalidRideStatus
+	}
+	// Zero means "not set" and is defaulted to 1.0 by the repository.
+	if r.SurgeMultiplier != 0 && r.SurgeMultiplier < 1.0 {
+		return ErrInvalidSurgeMultiplier
+	}
+	if r.SurchargeAmount < 0 {
+		return ErrInvalidSurcharge
+	}
+	if r.Type != "" && !r.Type.IsValid() {
+		return ErrInvalidRideType
+	}
+	if _, err := NormalizeCapabilities(r.Capabilities); err != nil {
+		return err
+	}
+	if r.Status == RideStatusAssigned && r.AssignedDriverID == "" {
+		return ErrInvalidDriverID
+	}
+	return nil
+}
+
+// IsPackage reports whether the ride carries a package rather than a rider.
+func (r *Ride) IsPackage() bool {
+	return r.Type == RideTypePackage
 }
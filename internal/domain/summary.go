@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// DriverWeeklySummary is a driver's activity over one week, Monday to
+// Monday UTC, and their rank among the drivers active that week in the city.
+type DriverWeeklySummary struct {
+	DriverID      string
+	WeekStart     time.Time // Monday 00:00 UTC
+	City          string    // The driver's city, or the deployment's for drivers registered without one
+	Trips         int       // Trips ended in the week
+	Earnings      float64   // Fares net of commission, plus bonuses and tips credited in the week
+	OnlineHours   float64   // Estimated from location updates, in 5-minute slots
+	Rank          int       // 1 is best; drivers tied on trips and earnings share a rank
+	RankedDrivers int       // Drivers ranked that week
+	GeneratedAt   time.Time
+	NotifiedAt    time.Time // Zero until the driver was sent the summary
+}
+
+// WeekStart returns the start of the week t falls in: the Monday before or
+// on t, at 00:00 UTC.
+func WeekStart(t time.Time) time.Time {
+	t = t.UTC()
+	daysSinceMonday := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-daysSinceMonday, 0, 0, 0, 0, time.UTC)
+}
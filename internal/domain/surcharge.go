@@ -0,0 +1,17 @@
+package domain
+
+// SurchargeZone is a bounding box, such as an airport, that adds a flat
+// surcharge to rides picking up or dropping off inside it.
+type SurchargeZone struct {
+	Label  string  // Shown on the receipt, e.g. "Airport fee"
+	Amount float64 // Flat amount added to the fare
+	MinLat float64
+	MinLng float64
+	MaxLat float64
+	MaxLng float64
+}
+
+// Contains reports whether the point lies inside the zone, edges included.
+func (z SurchargeZone) Contains(lat, lng float64) bool {
+	return lat >= z.MinLat && lat <= z.MaxLat && lng >= z.MinLng && lng <= z.MaxLng
+}
@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// SurgeFloor is a daily window, such as the morning peak, during which the
+// surge multiplier never drops below Multiplier whatever the demand.
+type SurgeFloor struct {
+	Start      time.Duration // Time of day the window opens, e.g. 8h
+	End        time.Duration // Time of day it closes, exclusive; before Start for a window past midnight
+	Multiplier float64
+}
+
+// Covers reports whether t's time of day, in t's location, falls in the
+// window.
+func (f SurgeFloor) Covers(t time.Time) bool {
+	timeOfDay := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	if f.Start <= f.End {
+		return timeOfDay >= f.Start && timeOfDay < f.End
+	}
+	return timeOfDay >= f.Start || timeOfDay < f.End
+}
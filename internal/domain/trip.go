@@ -9,8 +9,41 @@ const (
 	TripStatusStarted TripStatus = "STARTED"
 	TripStatusPaused  TripStatus = "PAUSED"
 	TripStatusEnded   TripStatus = "ENDED"
+	TripStatusAborted TripStatus = "ABORTED" // Ended before the destination, e.g. a breakdown
 )
 
+// PauseReason explains to the rider why a trip was paused.
+type PauseReason string
+
+const (
+	PauseReasonTraffic      PauseReason = "TRAFFIC"
+	PauseReasonFuel         PauseReason = "FUEL"
+	PauseReasonRiderRequest PauseReason = "RIDER_REQUEST"
+	PauseReasonOther        PauseReason = "OTHER"
+)
+
+// IsValid reports whether the reason is a known pause reason.
+func (r PauseReason) IsValid() bool {
+	switch r {
+	case PauseReasonTraffic, PauseReasonFuel, PauseReasonRiderRequest, PauseReasonOther:
+		return true
+	}
+	return false
+}
+
+// AbortParty identifies who aborted a trip in progress.
+type AbortParty string
+
+const (
+	AbortPartyRider  AbortParty = "RIDER"
+	AbortPartyDriver AbortParty = "DRIVER"
+)
+
+// IsValid reports whether the party is a known abort party.
+func (p AbortParty) IsValid() bool {
+	return p == AbortPartyRider || p == AbortPartyDriver
+}
+
 // Trip represents an active or completed trip in the system.
 type Trip struct {
 	ID          string
@@ -21,7 +54,31 @@ type Trip struct {
 	StartedAt   time.Time
 	EndedAt     time.Time
 	PausedAt    time.Time     // When trip was paused
+	PauseReason PauseReason   // Why the trip is paused; empty when not given or not paused
 	TotalPaused time.Duration // Total time paused (for fare calculation)
+	Version     int           // Optimistic concurrency token; bumped on every update
+
+	// NeedsReview holds the trip's payment until an admin approves the fare.
+	// It is set when the computed fare exceeds the maximum fare cap, in which
+	// case Fare holds the capped amount and UncappedFare the computed one.
+	NeedsReview  bool
+	UncappedFare float64
+
+	AbortedBy   AbortParty // Who aborted the trip; empty unless ABORTED
+	AbortReason string
+
+	// AutoEnded marks a trip ended by the safeguard sweeper after it ran past
+	// the maximum trip duration. Its fare covers only that duration.
+	AutoEnded bool
+
+	// Confirmation holds an ended trip's payment, authorized but not
+	// captured, while it waits for the rider to confirm the trip happened or
+	// for an admin to resolve the rider's dispute. Empty unless it waited.
+	Confirmation  TripConfirmation
+	DisputeReason string // The rider's reason; set once disputed
+
+	CreatedAt time.Time // Set when the trip is stored
+	UpdatedAt time.Time // Set on every stored change
 }
 
 // Receipt represents a trip receipt.
@@ -38,12 +95,117 @@ type Receipt struct {
 	BaseFare      float64
 	SurgeMultiplier float64
 	SurgeAmount   float64
+	SurchargeLabel  string
+	SurchargeAmount float64 // Zone surcharges, e.g. tolls or airport fees
+	ProcessingFee float64 // Passed on for the payment method; included in TotalFare
 	TotalFare     float64
 	PaymentMethod PaymentMethod
 	PaymentStatus PaymentStatus
+	SplitRiders   int     // Riders the fare was split between; 0 if it was not
+	ShareAmount   float64 // This rider's share of a split fare
+	AbsorbedAmount float64 // Other riders' shares this rider paid because theirs failed
+	ShareAbsorbed bool    // This rider's share failed and was charged to the ride's rider
+	AdjustedFrom  float64 // The fare before an admin adjusted it; 0 if it was not adjusted
+	AdjustmentReason string
 	Duration      time.Duration
 	Distance      float64 // In kilometers (estimated)
 	StartedAt     time.Time
 	EndedAt       time.Time
+	AbortedBy     AbortParty // Set when the trip was aborted before the destination
+	AbortReason   string
 	CreatedAt     time.Time
 }
+
+// TripConfirmation is where an ended trip stands in the rider's confirmation
+// step. Empty means the trip did not wait for the rider.
+type TripConfirmation string
+
+const (
+	TripConfirmationPending   TripConfirmation = "PENDING"   // Payment held until the rider confirms or disputes
+	TripConfirmationConfirmed TripConfirmation = "CONFIRMED" // By the rider, or by the window lapsing; payment captured
+	TripConfirmationDisputed  TripConfirmation = "DISPUTED"  // Payment held until an admin resolves the dispute
+	TripConfirmationCharged   TripConfirmation = "CHARGED"   // Dispute resolved against the rider; payment captured
+	TripConfirmationVoided    TripConfirmation = "VOIDED"    // Dispute resolved for the rider; hold released, nothing charged
+)
+
+// IsValid reports whether the confirmation is a known confirmation state.
+func (c TripConfirmation) IsValid() bool {
+	switch c {
+	case TripConfirmationPending, TripConfirmationConfirmed, TripConfirmationDisputed,
+		TripConfirmationCharged, TripConfirmationVoided:
+		return true
+	}
+	return false
+}
+
+// tripTransitions encodes the trip state machine: each status maps to the
+// set of statuses it may move to. ENDED and ABORTED are terminal.
+var tripTransitions = map[TripStatus][]TripStatus{
+	TripStatusStarted: {TripStatusPaused, TripStatusEnded, TripStatusAborted},
+	TripStatusPaused:  {TripStatusStarted, TripStatusEnded, TripStatusAborted},
+	TripStatusEnded:   {},
+	TripStatusAborted: {},
+}
+
+// IsValid reports whether the status is a known trip status.
+func (s TripStatus) IsValid() bool {
+	_, ok := tripTransitions[s]
+	return ok
+}
+
+// CanTransitionTo reports whether the trip may move from its current status to next.
+func (t *Trip) CanTransitionTo(next TripStatus) bool {
+	for _, allowed := range tripTransitions[t.Status] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// PaymentHeld reports whether the trip's payment is held for the rider's
+// confirmation or for a dispute to be resolved.
+func (t *Trip) PaymentHeld() bool {
+	return t.Confirmation == TripConfirmationPending || t.Confirmation == TripConfirmationDisputed
+}
+
+// Validate checks the trip's invariants before it is persisted.
+func (t *Trip) Validate() error {
+	if t.ID == "" {
+		return ErrInvalidTripID
+	}
+	if t.RideID == "" {
+		return ErrInvalidRideID
+	}
+	if t.DriverID == "" {
+		return ErrInvalidDriverID
+	}
+	if !t.Status.IsValid() {
+		return ErrInvalidTripStatus
+	}
+	if t.Fare < 0 {
+		return ErrInvalidFare
+	}
+	if t.StartedAt.IsZero() || t.TotalPaused < 0 {
+		return ErrInvalidTripTimestamps
+	}
+	if !t.EndedAt.IsZero() && t.EndedAt.Before(t.StartedAt) {
+		return ErrInvalidTripTimestamps
+	}
+	if (t.Status == TripStatusEnded || t.Status == TripStatusAborted) && t.EndedAt.IsZero() {
+		return ErrInvalidTripTimestamps
+	}
+	if t.Status == TripStatusAborted && !t.AbortedBy.IsValid() {
+		return ErrInvalidAbortParty
+	}
+	if t.PauseReason != "" && !t.PauseReason.IsValid() {
+		return ErrInvalidPauseReason
+	}
+	if t.Status == TripStatusPaused && t.PausedAt.IsZero() {
+		return ErrInvalidTripTimestamps
+	}
+	if t.Confirmation != "" && (!t.Confirmation.IsValid() || t.Status != TripStatusEnded) {
+		return ErrInvalidTripConfirmation
+	}
+	return nil
+}
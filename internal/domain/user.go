@@ -1,11 +1,36 @@
 package domain
 
-import "time"
+import (
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// maxEmailLength is the longest address SMTP allows.
+const maxEmailLength = 254
 
 // User represents a rider in the system.
 type User struct {
-	ID        string
-	Name      string
-	Phone     string
-	CreatedAt time.Time
+	ID            string
+	Name          string
+	Phone         string
+	Email         string // Optional; empty when not provided
+	EmailVerified bool   // Only verified addresses receive email, e.g. receipts
+	CreatedAt     time.Time
+}
+
+// NormalizeEmail trims and lower-cases an email address so that uniqueness
+// checks are case-insensitive.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// IsValidEmail reports whether email is a bare address such as
+// "rider@example.com", without a display name or angle brackets.
+func IsValidEmail(email string) bool {
+	if email == "" || len(email) > maxEmailLength {
+		return false
+	}
+	addr, err := mail.ParseAddress(email)
+	return err == nil && addr.Address == email
 }
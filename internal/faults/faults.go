@@ -0,0 +1,146 @@
+package faults
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Operation names. Every Redis command is also its own operation, "redis."
+// followed by the lower-case command name (e.g. "redis.geoadd"). A fault set
+// on "postgres" or "redis" applies to every operation of that store that has
+// no fault of its own.
+const (
+	OpPostgres        = "postgres"
+	OpPostgresConnect = "postgres.connect"
+	OpPostgresBegin   = "postgres.begin"
+	OpPostgresQuery   = "postgres.query"
+	OpPostgresExec    = "postgres.exec"
+	OpRedis           = "redis"
+	OpRedisDial       = "redis.dial"
+	OpRedisPipeline   = "redis.pipeline"
+)
+
+var (
+	// ErrInjected is returned by an operation failed by an error-rate fault.
+	ErrInjected = errors.New("injected fault")
+
+	// ErrInvalidFault is returned when a fault names an unknown store or has
+	// a negative latency or an error rate outside [0, 1].
+	ErrInvalidFault = errors.New("invalid fault")
+)
+
+// Fault is the misbehaviour injected into an operation. Latency is added
+// first; then the operation is refused or failed at random.
+type Fault struct {
+	Latency           time.Duration // Added before the operation runs
+	ErrorRate         float64       // Fraction of operations failed with ErrInjected, 0 to 1
+	ConnectionRefused bool          // Fail as if the server refused the connection
+}
+
+// OperationFault is a fault and the operation it is set on.
+type OperationFault struct {
+	Operation string
+	Fault
+}
+
+// Injector holds the faults injected into wrapped Postgres connections and
+// Redis clients, changeable at runtime. It is for QA environments only: the
+// server builds one solely when FAULTS_ENABLED is set outside release mode,
+// and without one nothing is wrapped.
+type Injector struct {
+	mu     sync.RWMutex
+	faults map[string]Fault
+	rand   func() float64
+}
+
+// NewInjector creates an Injector with no faults set.
+func NewInjector() *Injector {
+	return &Injector{faults: make(map[string]Fault), rand: rand.Float64}
+}
+
+// Set injects f into operation, replacing any fault already set on it. The
+// zero Fault clears the operation.
+func (i *Injector) Set(operation string, f Fault) error {
+	operation = strings.ToLower(strings.TrimSpace(operation))
+	if !isKnownOperation(operation) || f.Latency < 0 || f.ErrorRate < 0 || f.ErrorRate > 1 {
+		return ErrInvalidFault
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if f == (Fault{}) {
+		delete(i.faults, operation)
+		return nil
+	}
+	i.faults[operation] = f
+	return nil
+}
+
+// Clear removes every fault.
+func (i *Injector) Clear() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.faults = make(map[string]Fault)
+}
+
+// Faults returns the faults currently set, sorted by operation.
+func (i *Injector) Faults() []OperationFault {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	faults := make([]OperationFault, 0, len(i.faults))
+	for operation, f := range i.faults {
+		faults = append(faults, OperationFault{Operation: operation, Fault: f})
+	}
+	sort.Slice(faults, func(a, b int) bool { return faults[a].Operation < faults[b].Operation })
+	return faults
+}
+
+// inject applies the fault set on operation, or else on its store. It
+// reports whether the operation was refused, with the error to fail it with.
+func (i *Injector) inject(ctx context.Context, operation string) (refused bool, err error) {
+	i.mu.RLock()
+	f, ok := i.faults[operation]
+	if !ok {
+		store, _, _ := strings.Cut(operation, ".")
+		f, ok = i.faults[store]
+	}
+	i.mu.RUnlock()
+	if !ok {
+		return false, nil
+	}
+
+	if f.Latency > 0 {
+		timer := time.NewTimer(f.Latency)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+	}
+	if f.ConnectionRefused {
+		return true, &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+	}
+	if f.ErrorRate > 0 && i.rand() < f.ErrorRate {
+		return false, fmt.Errorf("%w: %s", ErrInjected, operation)
+	}
+	return false, nil
+}
+
+// isKnownOperation reports whether operation is a store or one of its
+// operations.
+func isKnownOperation(operation string) bool {
+	store, name, dotted := strings.Cut(operation, ".")
+	if store != OpPostgres && store != OpRedis {
+		return false
+	}
+	return !dotted || name != ""
+}
@@ -0,0 +1,51 @@
+package faults
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisHook returns a go-redis hook that injects the Redis faults into
+// dials, commands and pipelines. A refused command fails with the same
+// dial error go-redis returns when the server is down.
+func (i *Injector) RedisHook() redis.Hook {
+	return &redisHook{injector: i}
+}
+
+type redisHook struct {
+	injector *Injector
+}
+
+func (h *redisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if _, err := h.injector.inject(ctx, OpRedisDial); err != nil {
+			return nil, err
+		}
+		return next(ctx, network, addr)
+	}
+}
+
+func (h *redisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if _, err := h.injector.inject(ctx, OpRedis+"."+strings.ToLower(cmd.Name())); err != nil {
+			cmd.SetErr(err)
+			return err
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (h *redisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if _, err := h.injector.inject(ctx, OpRedisPipeline); err != nil {
+			for _, cmd := range cmds {
+				cmd.SetErr(err)
+			}
+			return err
+		}
+		return next(ctx, cmds)
+	}
+}
@@ -0,0 +1,176 @@
+package faults
+
+import (
+	"context"
+	"database/sql/driver"
+)
+
+// Connector wraps base so every connection it opens injects the Postgres
+// faults. A refused connection fails Connect with the refusal and makes an
+// open connection report driver.ErrBadConn, so database/sql discards it and
+// dials again, as it would after the server dropped it.
+func (i *Injector) Connector(base driver.Connector) driver.Connector {
+	return &faultConnector{base: base, injector: i}
+}
+
+type faultConnector struct {
+	base     driver.Connector
+	injector *Injector
+}
+
+func (c *faultConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	if _, err := c.injector.inject(ctx, OpPostgresConnect); err != nil {
+		return nil, err
+	}
+	conn, err := c.base.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &faultConn{Conn: conn, injector: c.injector}, nil
+}
+
+func (c *faultConnector) Driver() driver.Driver { return c.base.Driver() }
+
+// faultConn injects faults ahead of the wrapped connection's transactions,
+// queries and statements. Optional driver interfaces the wrapped connection
+// lacks are reported with driver.ErrSkip so database/sql falls back as usual.
+type faultConn struct {
+	driver.Conn
+	injector *Injector
+}
+
+// connInject applies the fault on operation to a connection.
+func (c *faultConn) connInject(ctx context.Context, operation string) error {
+	refused, err := c.injector.inject(ctx, operation)
+	if refused {
+		return driver.ErrBadConn
+	}
+	return err
+}
+
+func (c *faultConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *faultConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if err := c.connInject(ctx, OpPostgresBegin); err != nil {
+		return nil, err
+	}
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c *faultConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *faultConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &faultStmt{Stmt: stmt, conn: c}, nil
+}
+
+func (c *faultConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip // database/sql prepares instead; the statement injects
+	}
+	if err := c.connInject(ctx, OpPostgresExec); err != nil {
+		return nil, err
+	}
+	return execer.ExecContext(ctx, query, args)
+}
+
+func (c *faultConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip // database/sql prepares instead; the statement injects
+	}
+	if err := c.connInject(ctx, OpPostgresQuery); err != nil {
+		return nil, err
+	}
+	return queryer.QueryContext(ctx, query, args)
+}
+
+func (c *faultConn) Ping(ctx context.Context) error {
+	if err := c.connInject(ctx, OpPostgresConnect); err != nil {
+		return err
+	}
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *faultConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *faultConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+func (c *faultConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// faultStmt injects faults ahead of a prepared statement's executions.
+type faultStmt struct {
+	driver.Stmt
+	conn *faultConn
+}
+
+func (s *faultStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if err := s.conn.connInject(ctx, OpPostgresExec); err != nil {
+		return nil, err
+	}
+	if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+		return execer.ExecContext(ctx, args)
+	}
+	return s.Stmt.Exec(namedValues(args))
+}
+
+func (s *faultStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	if err := s.conn.connInject(ctx, OpPostgresQuery); err != nil {
+		return nil, err
+	}
+	if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		return queryer.QueryContext(ctx, args)
+	}
+	return s.Stmt.Query(namedValues(args))
+}
+
+func (s *faultStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// namedValues drops the names from positional arguments.
+func namedValues(args []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	return values
+}
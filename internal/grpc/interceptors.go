@@ -0,0 +1,98 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"runtime/debug"
+	"time"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+	gogrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// NewRelicInterceptor instruments each call as a New Relic transaction
+// named after its method, noticing any error it returns. Downstream code
+// (datastore segments, custom metrics) finds the transaction through the
+// context. Calls pass through uninstrumented when app is nil.
+func NewRelicInterceptor(app *newrelic.Application) gogrpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *gogrpc.UnaryServerInfo, next gogrpc.UnaryHandler) (any, error) {
+		if app == nil {
+			return next(ctx, req)
+		}
+
+		txn := app.StartTransaction(info.FullMethod)
+		if txn == nil {
+			return next(ctx, req)
+		}
+		defer txn.End()
+
+		resp, err := next(newrelic.NewContext(ctx, txn), req)
+		txn.AddAttribute("grpcStatusCode", status.Code(err).String())
+		if err != nil {
+			txn.NoticeError(err)
+		}
+		return resp, err
+	}
+}
+
+// LoggingInterceptor logs every call to out (os.Stdout when nil) with its
+// status code, latency, peer and, for failed calls, the error.
+func LoggingInterceptor(out io.Writer) gogrpc.UnaryServerInterceptor {
+	if out == nil {
+		out = os.Stdout
+	}
+
+	return func(ctx context.Context, req any, info *gogrpc.UnaryServerInfo, next gogrpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := next(ctx, req)
+
+		addr := "-"
+		if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+			addr = p.Addr.String()
+		}
+		message := ""
+		if err != nil {
+			message = status.Convert(err).Message()
+		}
+		fmt.Fprintf(out, "[GRPC] %s | %-18s | %13v | %21s | %s %s\n",
+			start.Format("2006/01/02 - 15:04:05"),
+			status.Code(err),
+			time.Since(start),
+			addr,
+			info.FullMethod,
+			message,
+		)
+		return resp, err
+	}
+}
+
+// RecoveryInterceptor turns a panicking call into an INTERNAL error. The
+// panic is logged with its stack to out (os.Stdout when nil); the caller
+// never sees the panic value or stack.
+func RecoveryInterceptor(out io.Writer) gogrpc.UnaryServerInterceptor {
+	if out == nil {
+		out = os.Stdout
+	}
+
+	return func(ctx context.Context, req any, info *gogrpc.UnaryServerInfo, next gogrpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			fmt.Fprintf(out, "[PANIC] %s | %s | %v\n%s",
+				time.Now().Format("2006/01/02 - 15:04:05"),
+				info.FullMethod,
+				recovered,
+				debug.Stack(),
+			)
+			resp, err = nil, status.Error(codes.Internal, "internal server error")
+		}()
+		return next(ctx, req)
+	}
+}
@@ -0,0 +1,1198 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v5.29.3
+// source: ride.proto
+
+package ridepb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type CreateRideRequest struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	RiderId              string                 `protobuf:"bytes,1,opt,name=rider_id,json=riderId,proto3" json:"rider_id,omitempty"`
+	PickupLat            float64                `protobuf:"fixed64,2,opt,name=pickup_lat,json=pickupLat,proto3" json:"pickup_lat,omitempty"`
+	PickupLng            float64                `protobuf:"fixed64,3,opt,name=pickup_lng,json=pickupLng,proto3" json:"pickup_lng,omitempty"`
+	DestinationLat       float64                `protobuf:"fixed64,4,opt,name=destination_lat,json=destinationLat,proto3" json:"destination_lat,omitempty"`
+	DestinationLng       float64                `protobuf:"fixed64,5,opt,name=destination_lng,json=destinationLng,proto3" json:"destination_lng,omitempty"`
+	Tier                 string                 `protobuf:"bytes,6,opt,name=tier,proto3" json:"tier,omitempty"`                                        // A catalog tier, any case; defaults to the catalog's default
+	PaymentMethod        string                 `protobuf:"bytes,7,opt,name=payment_method,json=paymentMethod,proto3" json:"payment_method,omitempty"` // A catalog method, any case; defaults to the catalog's default
+	QuoteId              string                 `protobuf:"bytes,8,opt,name=quote_id,json=quoteId,proto3" json:"quote_id,omitempty"`
+	RideType             string                 `protobuf:"bytes,9,opt,name=ride_type,json=rideType,proto3" json:"ride_type,omitempty"` // PASSENGER or PACKAGE, any case; defaults to PASSENGER
+	RequiredCapabilities []string               `protobuf:"bytes,10,rep,name=required_capabilities,json=requiredCapabilities,proto3" json:"required_capabilities,omitempty"`
+	PaymentInstrumentId  string                 `protobuf:"bytes,11,opt,name=payment_instrument_id,json=paymentInstrumentId,proto3" json:"payment_instrument_id,omitempty"`
+	ExcludeDriverIds     []string               `protobuf:"bytes,12,rep,name=exclude_driver_ids,json=excludeDriverIds,proto3" json:"exclude_driver_ids,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *CreateRideRequest) Reset() {
+	*x = CreateRideRequest{}
+	mi := &file_ride_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateRideRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateRideRequest) ProtoMessage() {}
+
+func (x *CreateRideRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ride_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateRideRequest.ProtoReflect.Descriptor instead.
+func (*CreateRideRequest) Descriptor() ([]byte, []int) {
+	return file_ride_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CreateRideRequest) GetRiderId() string {
+	if x != nil {
+		return x.RiderId
+	}
+	return ""
+}
+
+func (x *CreateRideRequest) GetPickupLat() float64 {
+	if x != nil {
+		return x.PickupLat
+	}
+	return 0
+}
+
+func (x *CreateRideRequest) GetPickupLng() float64 {
+	if x != nil {
+		return x.PickupLng
+	}
+	return 0
+}
+
+func (x *CreateRideRequest) GetDestinationLat() float64 {
+	if x != nil {
+		return x.DestinationLat
+	}
+	return 0
+}
+
+func (x *CreateRideRequest) GetDestinationLng() float64 {
+	if x != nil {
+		return x.DestinationLng
+	}
+	return 0
+}
+
+func (x *CreateRideRequest) GetTier() string {
+	if x != nil {
+		return x.Tier
+	}
+	return ""
+}
+
+func (x *CreateRideRequest) GetPaymentMethod() string {
+	if x != nil {
+		return x.PaymentMethod
+	}
+	return ""
+}
+
+func (x *CreateRideRequest) GetQuoteId() string {
+	if x != nil {
+		return x.QuoteId
+	}
+	return ""
+}
+
+func (x *CreateRideRequest) GetRideType() string {
+	if x != nil {
+		return x.RideType
+	}
+	return ""
+}
+
+func (x *CreateRideRequest) GetRequiredCapabilities() []string {
+	if x != nil {
+		return x.RequiredCapabilities
+	}
+	return nil
+}
+
+func (x *CreateRideRequest) GetPaymentInstrumentId() string {
+	if x != nil {
+		return x.PaymentInstrumentId
+	}
+	return ""
+}
+
+func (x *CreateRideRequest) GetExcludeDriverIds() []string {
+	if x != nil {
+		return x.ExcludeDriverIds
+	}
+	return nil
+}
+
+type CreateRideResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Ride           *Ride                  `protobuf:"bytes,1,opt,name=ride,proto3" json:"ride,omitempty"`
+	DriverAssigned bool                   `protobuf:"varint,2,opt,name=driver_assigned,json=driverAssigned,proto3" json:"driver_assigned,omitempty"`
+	QuoteRejected  bool                   `protobuf:"varint,3,opt,name=quote_rejected,json=quoteRejected,proto3" json:"quote_rejected,omitempty"` // The quote was expired or invalid; surge was priced live
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *CreateRideResponse) Reset() {
+	*x = CreateRideResponse{}
+	mi := &file_ride_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateRideResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateRideResponse) ProtoMessage() {}
+
+func (x *CreateRideResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ride_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateRideResponse.ProtoReflect.Descriptor instead.
+func (*CreateRideResponse) Descriptor() ([]byte, []int) {
+	return file_ride_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateRideResponse) GetRide() *Ride {
+	if x != nil {
+		return x.Ride
+	}
+	return nil
+}
+
+func (x *CreateRideResponse) GetDriverAssigned() bool {
+	if x != nil {
+		return x.DriverAssigned
+	}
+	return false
+}
+
+func (x *CreateRideResponse) GetQuoteRejected() bool {
+	if x != nil {
+		return x.QuoteRejected
+	}
+	return false
+}
+
+type GetRideRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RideId        string                 `protobuf:"bytes,1,opt,name=ride_id,json=rideId,proto3" json:"ride_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRideRequest) Reset() {
+	*x = GetRideRequest{}
+	mi := &file_ride_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRideRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRideRequest) ProtoMessage() {}
+
+func (x *GetRideRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ride_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRideRequest.ProtoReflect.Descriptor instead.
+func (*GetRideRequest) Descriptor() ([]byte, []int) {
+	return file_ride_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetRideRequest) GetRideId() string {
+	if x != nil {
+		return x.RideId
+	}
+	return ""
+}
+
+type Ride struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	Id                   string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	RiderId              string                 `protobuf:"bytes,2,opt,name=rider_id,json=riderId,proto3" json:"rider_id,omitempty"`
+	PickupLat            float64                `protobuf:"fixed64,3,opt,name=pickup_lat,json=pickupLat,proto3" json:"pickup_lat,omitempty"`
+	PickupLng            float64                `protobuf:"fixed64,4,opt,name=pickup_lng,json=pickupLng,proto3" json:"pickup_lng,omitempty"`
+	DestinationLat       float64                `protobuf:"fixed64,5,opt,name=destination_lat,json=destinationLat,proto3" json:"destination_lat,omitempty"`
+	DestinationLng       float64                `protobuf:"fixed64,6,opt,name=destination_lng,json=destinationLng,proto3" json:"destination_lng,omitempty"`
+	Status               string                 `protobuf:"bytes,7,opt,name=status,proto3" json:"status,omitempty"`
+	AssignedDriverId     string                 `protobuf:"bytes,8,opt,name=assigned_driver_id,json=assignedDriverId,proto3" json:"assigned_driver_id,omitempty"`
+	SurgeMultiplier      float64                `protobuf:"fixed64,9,opt,name=surge_multiplier,json=surgeMultiplier,proto3" json:"surge_multiplier,omitempty"`
+	PaymentMethod        string                 `protobuf:"bytes,10,opt,name=payment_method,json=paymentMethod,proto3" json:"payment_method,omitempty"`
+	Tier                 string                 `protobuf:"bytes,11,opt,name=tier,proto3" json:"tier,omitempty"`
+	RideType             string                 `protobuf:"bytes,12,opt,name=ride_type,json=rideType,proto3" json:"ride_type,omitempty"`
+	RequiredCapabilities []string               `protobuf:"bytes,13,rep,name=required_capabilities,json=requiredCapabilities,proto3" json:"required_capabilities,omitempty"`
+	QuoteId              string                 `protobuf:"bytes,14,opt,name=quote_id,json=quoteId,proto3" json:"quote_id,omitempty"`
+	CreatedAt            string                 `protobuf:"bytes,15,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"` // RFC 3339
+	UpdatedAt            string                 `protobuf:"bytes,16,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"` // RFC 3339
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *Ride) Reset() {
+	*x = Ride{}
+	mi := &file_ride_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Ride) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Ride) ProtoMessage() {}
+
+func (x *Ride) ProtoReflect() protoreflect.Message {
+	mi := &file_ride_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Ride.ProtoReflect.Descriptor instead.
+func (*Ride) Descriptor() ([]byte, []int) {
+	return file_ride_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Ride) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Ride) GetRiderId() string {
+	if x != nil {
+		return x.RiderId
+	}
+	return ""
+}
+
+func (x *Ride) GetPickupLat() float64 {
+	if x != nil {
+		return x.PickupLat
+	}
+	return 0
+}
+
+func (x *Ride) GetPickupLng() float64 {
+	if x != nil {
+		return x.PickupLng
+	}
+	return 0
+}
+
+func (x *Ride) GetDestinationLat() float64 {
+	if x != nil {
+		return x.DestinationLat
+	}
+	return 0
+}
+
+func (x *Ride) GetDestinationLng() float64 {
+	if x != nil {
+		return x.DestinationLng
+	}
+	return 0
+}
+
+func (x *Ride) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Ride) GetAssignedDriverId() string {
+	if x != nil {
+		return x.AssignedDriverId
+	}
+	return ""
+}
+
+func (x *Ride) GetSurgeMultiplier() float64 {
+	if x != nil {
+		return x.SurgeMultiplier
+	}
+	return 0
+}
+
+func (x *Ride) GetPaymentMethod() string {
+	if x != nil {
+		return x.PaymentMethod
+	}
+	return ""
+}
+
+func (x *Ride) GetTier() string {
+	if x != nil {
+		return x.Tier
+	}
+	return ""
+}
+
+func (x *Ride) GetRideType() string {
+	if x != nil {
+		return x.RideType
+	}
+	return ""
+}
+
+func (x *Ride) GetRequiredCapabilities() []string {
+	if x != nil {
+		return x.RequiredCapabilities
+	}
+	return nil
+}
+
+func (x *Ride) GetQuoteId() string {
+	if x != nil {
+		return x.QuoteId
+	}
+	return ""
+}
+
+func (x *Ride) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *Ride) GetUpdatedAt() string {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return ""
+}
+
+type UpdateDriverLocationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DriverId      string                 `protobuf:"bytes,1,opt,name=driver_id,json=driverId,proto3" json:"driver_id,omitempty"`
+	Lat           float64                `protobuf:"fixed64,2,opt,name=lat,proto3" json:"lat,omitempty"`
+	Lng           float64                `protobuf:"fixed64,3,opt,name=lng,proto3" json:"lng,omitempty"`
+	Heading       float64                `protobuf:"fixed64,4,opt,name=heading,proto3" json:"heading,omitempty"`                       // Degrees clockwise from north
+	RecordedAt    string                 `protobuf:"bytes,5,opt,name=recorded_at,json=recordedAt,proto3" json:"recorded_at,omitempty"` // RFC 3339; optional, defaults to when the update arrives
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateDriverLocationRequest) Reset() {
+	*x = UpdateDriverLocationRequest{}
+	mi := &file_ride_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateDriverLocationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateDriverLocationRequest) ProtoMessage() {}
+
+func (x *UpdateDriverLocationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ride_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateDriverLocationRequest.ProtoReflect.Descriptor instead.
+func (*UpdateDriverLocationRequest) Descriptor() ([]byte, []int) {
+	return file_ride_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *UpdateDriverLocationRequest) GetDriverId() string {
+	if x != nil {
+		return x.DriverId
+	}
+	return ""
+}
+
+func (x *UpdateDriverLocationRequest) GetLat() float64 {
+	if x != nil {
+		return x.Lat
+	}
+	return 0
+}
+
+func (x *UpdateDriverLocationRequest) GetLng() float64 {
+	if x != nil {
+		return x.Lng
+	}
+	return 0
+}
+
+func (x *UpdateDriverLocationRequest) GetHeading() float64 {
+	if x != nil {
+		return x.Heading
+	}
+	return 0
+}
+
+func (x *UpdateDriverLocationRequest) GetRecordedAt() string {
+	if x != nil {
+		return x.RecordedAt
+	}
+	return ""
+}
+
+type UpdateDriverLocationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateDriverLocationResponse) Reset() {
+	*x = UpdateDriverLocationResponse{}
+	mi := &file_ride_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateDriverLocationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateDriverLocationResponse) ProtoMessage() {}
+
+func (x *UpdateDriverLocationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ride_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateDriverLocationResponse.ProtoReflect.Descriptor instead.
+func (*UpdateDriverLocationResponse) Descriptor() ([]byte, []int) {
+	return file_ride_proto_rawDescGZIP(), []int{5}
+}
+
+type AcceptRideRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	DriverId         string                 `protobuf:"bytes,1,opt,name=driver_id,json=driverId,proto3" json:"driver_id,omitempty"`
+	RideId           string                 `protobuf:"bytes,2,opt,name=ride_id,json=rideId,proto3" json:"ride_id,omitempty"`
+	OverrideGeofence bool                   `protobuf:"varint,3,opt,name=override_geofence,json=overrideGeofence,proto3" json:"override_geofence,omitempty"` // Start away from the pickup point
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *AcceptRideRequest) Reset() {
+	*x = AcceptRideRequest{}
+	mi := &file_ride_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AcceptRideRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AcceptRideRequest) ProtoMessage() {}
+
+func (x *AcceptRideRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ride_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AcceptRideRequest.ProtoReflect.Descriptor instead.
+func (*AcceptRideRequest) Descriptor() ([]byte, []int) {
+	return file_ride_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *AcceptRideRequest) GetDriverId() string {
+	if x != nil {
+		return x.DriverId
+	}
+	return ""
+}
+
+func (x *AcceptRideRequest) GetRideId() string {
+	if x != nil {
+		return x.RideId
+	}
+	return ""
+}
+
+func (x *AcceptRideRequest) GetOverrideGeofence() bool {
+	if x != nil {
+		return x.OverrideGeofence
+	}
+	return false
+}
+
+type EndTripRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TripId        string                 `protobuf:"bytes,1,opt,name=trip_id,json=tripId,proto3" json:"trip_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EndTripRequest) Reset() {
+	*x = EndTripRequest{}
+	mi := &file_ride_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EndTripRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EndTripRequest) ProtoMessage() {}
+
+func (x *EndTripRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ride_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EndTripRequest.ProtoReflect.Descriptor instead.
+func (*EndTripRequest) Descriptor() ([]byte, []int) {
+	return file_ride_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *EndTripRequest) GetTripId() string {
+	if x != nil {
+		return x.TripId
+	}
+	return ""
+}
+
+type Trip struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Id                 string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	RideId             string                 `protobuf:"bytes,2,opt,name=ride_id,json=rideId,proto3" json:"ride_id,omitempty"`
+	DriverId           string                 `protobuf:"bytes,3,opt,name=driver_id,json=driverId,proto3" json:"driver_id,omitempty"`
+	Status             string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	Fare               float64                `protobuf:"fixed64,5,opt,name=fare,proto3" json:"fare,omitempty"`
+	StartedAt          string                 `protobuf:"bytes,6,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"` // RFC 3339
+	EndedAt            string                 `protobuf:"bytes,7,opt,name=ended_at,json=endedAt,proto3" json:"ended_at,omitempty"`       // RFC 3339
+	TotalPausedSeconds int64                  `protobuf:"varint,8,opt,name=total_paused_seconds,json=totalPausedSeconds,proto3" json:"total_paused_seconds,omitempty"`
+	NeedsReview        bool                   `protobuf:"varint,9,opt,name=needs_review,json=needsReview,proto3" json:"needs_review,omitempty"`
+	UncappedFare       float64                `protobuf:"fixed64,10,opt,name=uncapped_fare,json=uncappedFare,proto3" json:"uncapped_fare,omitempty"` // Computed fare of a trip held for review
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *Trip) Reset() {
+	*x = Trip{}
+	mi := &file_ride_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Trip) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Trip) ProtoMessage() {}
+
+func (x *Trip) ProtoReflect() protoreflect.Message {
+	mi := &file_ride_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Trip.ProtoReflect.Descriptor instead.
+func (*Trip) Descriptor() ([]byte, []int) {
+	return file_ride_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Trip) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Trip) GetRideId() string {
+	if x != nil {
+		return x.RideId
+	}
+	return ""
+}
+
+func (x *Trip) GetDriverId() string {
+	if x != nil {
+		return x.DriverId
+	}
+	return ""
+}
+
+func (x *Trip) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Trip) GetFare() float64 {
+	if x != nil {
+		return x.Fare
+	}
+	return 0
+}
+
+func (x *Trip) GetStartedAt() string {
+	if x != nil {
+		return x.StartedAt
+	}
+	return ""
+}
+
+func (x *Trip) GetEndedAt() string {
+	if x != nil {
+		return x.EndedAt
+	}
+	return ""
+}
+
+func (x *Trip) GetTotalPausedSeconds() int64 {
+	if x != nil {
+		return x.TotalPausedSeconds
+	}
+	return 0
+}
+
+func (x *Trip) GetNeedsReview() bool {
+	if x != nil {
+		return x.NeedsReview
+	}
+	return false
+}
+
+func (x *Trip) GetUncappedFare() float64 {
+	if x != nil {
+		return x.UncappedFare
+	}
+	return 0
+}
+
+type Payment struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Amount        float64                `protobuf:"fixed64,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	Fee           float64                `protobuf:"fixed64,3,opt,name=fee,proto3" json:"fee,omitempty"`
+	Status        string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	FailureReason string                 `protobuf:"bytes,5,opt,name=failure_reason,json=failureReason,proto3" json:"failure_reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Payment) Reset() {
+	*x = Payment{}
+	mi := &file_ride_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Payment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Payment) ProtoMessage() {}
+
+func (x *Payment) ProtoReflect() protoreflect.Message {
+	mi := &file_ride_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Payment.ProtoReflect.Descriptor instead.
+func (*Payment) Descriptor() ([]byte, []int) {
+	return file_ride_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Payment) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Payment) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *Payment) GetFee() float64 {
+	if x != nil {
+		return x.Fee
+	}
+	return 0
+}
+
+func (x *Payment) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Payment) GetFailureReason() string {
+	if x != nil {
+		return x.FailureReason
+	}
+	return ""
+}
+
+type Receipt struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	BaseFare        float64                `protobuf:"fixed64,2,opt,name=base_fare,json=baseFare,proto3" json:"base_fare,omitempty"`
+	SurgeMultiplier float64                `protobuf:"fixed64,3,opt,name=surge_multiplier,json=surgeMultiplier,proto3" json:"surge_multiplier,omitempty"`
+	SurgeAmount     float64                `protobuf:"fixed64,4,opt,name=surge_amount,json=surgeAmount,proto3" json:"surge_amount,omitempty"`
+	SurchargeAmount float64                `protobuf:"fixed64,5,opt,name=surcharge_amount,json=surchargeAmount,proto3" json:"surcharge_amount,omitempty"`
+	ProcessingFee   float64                `protobuf:"fixed64,6,opt,name=processing_fee,json=processingFee,proto3" json:"processing_fee,omitempty"`
+	TotalFare       float64                `protobuf:"fixed64,7,opt,name=total_fare,json=totalFare,proto3" json:"total_fare,omitempty"`
+	PaymentMethod   string                 `protobuf:"bytes,8,opt,name=payment_method,json=paymentMethod,proto3" json:"payment_method,omitempty"`
+	PaymentStatus   string                 `protobuf:"bytes,9,opt,name=payment_status,json=paymentStatus,proto3" json:"payment_status,omitempty"`
+	DurationMinutes float64                `protobuf:"fixed64,10,opt,name=duration_minutes,json=durationMinutes,proto3" json:"duration_minutes,omitempty"`
+	DistanceKm      float64                `protobuf:"fixed64,11,opt,name=distance_km,json=distanceKm,proto3" json:"distance_km,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Receipt) Reset() {
+	*x = Receipt{}
+	mi := &file_ride_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Receipt) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Receipt) ProtoMessage() {}
+
+func (x *Receipt) ProtoReflect() protoreflect.Message {
+	mi := &file_ride_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Receipt.ProtoReflect.Descriptor instead.
+func (*Receipt) Descriptor() ([]byte, []int) {
+	return file_ride_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Receipt) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Receipt) GetBaseFare() float64 {
+	if x != nil {
+		return x.BaseFare
+	}
+	return 0
+}
+
+func (x *Receipt) GetSurgeMultiplier() float64 {
+	if x != nil {
+		return x.SurgeMultiplier
+	}
+	return 0
+}
+
+func (x *Receipt) GetSurgeAmount() float64 {
+	if x != nil {
+		return x.SurgeAmount
+	}
+	return 0
+}
+
+func (x *Receipt) GetSurchargeAmount() float64 {
+	if x != nil {
+		return x.SurchargeAmount
+	}
+	return 0
+}
+
+func (x *Receipt) GetProcessingFee() float64 {
+	if x != nil {
+		return x.ProcessingFee
+	}
+	return 0
+}
+
+func (x *Receipt) GetTotalFare() float64 {
+	if x != nil {
+		return x.TotalFare
+	}
+	return 0
+}
+
+func (x *Receipt) GetPaymentMethod() string {
+	if x != nil {
+		return x.PaymentMethod
+	}
+	return ""
+}
+
+func (x *Receipt) GetPaymentStatus() string {
+	if x != nil {
+		return x.PaymentStatus
+	}
+	return ""
+}
+
+func (x *Receipt) GetDurationMinutes() float64 {
+	if x != nil {
+		return x.DurationMinutes
+	}
+	return 0
+}
+
+func (x *Receipt) GetDistanceKm() float64 {
+	if x != nil {
+		return x.DistanceKm
+	}
+	return 0
+}
+
+type EndTripResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Trip          *Trip                  `protobuf:"bytes,1,opt,name=trip,proto3" json:"trip,omitempty"`
+	Payment       *Payment               `protobuf:"bytes,2,opt,name=payment,proto3" json:"payment,omitempty"` // Unset when nothing was charged
+	Receipt       *Receipt               `protobuf:"bytes,3,opt,name=receipt,proto3" json:"receipt,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EndTripResponse) Reset() {
+	*x = EndTripResponse{}
+	mi := &file_ride_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EndTripResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EndTripResponse) ProtoMessage() {}
+
+func (x *EndTripResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ride_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EndTripResponse.ProtoReflect.Descriptor instead.
+func (*EndTripResponse) Descriptor() ([]byte, []int) {
+	return file_ride_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *EndTripResponse) GetTrip() *Trip {
+	if x != nil {
+		return x.Trip
+	}
+	return nil
+}
+
+func (x *EndTripResponse) GetPayment() *Payment {
+	if x != nil {
+		return x.Payment
+	}
+	return nil
+}
+
+func (x *EndTripResponse) GetReceipt() *Receipt {
+	if x != nil {
+		return x.Receipt
+	}
+	return nil
+}
+
+var File_ride_proto protoreflect.FileDescriptor
+
+const file_ride_proto_rawDesc = "" +
+	"\n" +
+	"\n" +
+	"ride.proto\x12\aride.v1\"\xc8\x03\n" +
+	"\x11CreateRideRequest\x12\x19\n" +
+	"\brider_id\x18\x01 \x01(\tR\ariderId\x12\x1d\n" +
+	"\n" +
+	"pickup_lat\x18\x02 \x01(\x01R\tpickupLat\x12\x1d\n" +
+	"\n" +
+	"pickup_lng\x18\x03 \x01(\x01R\tpickupLng\x12'\n" +
+	"\x0fdestination_lat\x18\x04 \x01(\x01R\x0edestinationLat\x12'\n" +
+	"\x0fdestination_lng\x18\x05 \x01(\x01R\x0edestinationLng\x12\x12\n" +
+	"\x04tier\x18\x06 \x01(\tR\x04tier\x12%\n" +
+	"\x0epayment_method\x18\a \x01(\tR\rpaymentMethod\x12\x19\n" +
+	"\bquote_id\x18\b \x01(\tR\aquoteId\x12\x1b\n" +
+	"\tride_type\x18\t \x01(\tR\brideType\x123\n" +
+	"\x15required_capabilities\x18\n" +
+	" \x03(\tR\x14requiredCapabilities\x122\n" +
+	"\x15payment_instrument_id\x18\v \x01(\tR\x13paymentInstrumentId\x12,\n" +
+	"\x12exclude_driver_ids\x18\f \x03(\tR\x10excludeDriverIds\"\x87\x01\n" +
+	"\x12CreateRideResponse\x12!\n" +
+	"\x04ride\x18\x01 \x01(\v2\r.ride.v1.RideR\x04ride\x12'\n" +
+	"\x0fdriver_assigned\x18\x02 \x01(\bR\x0edriverAssigned\x12%\n" +
+	"\x0equote_rejected\x18\x03 \x01(\bR\rquoteRejected\")\n" +
+	"\x0eGetRideRequest\x12\x17\n" +
+	"\aride_id\x18\x01 \x01(\tR\x06rideId\"\x98\x04\n" +
+	"\x04Ride\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x19\n" +
+	"\brider_id\x18\x02 \x01(\tR\ariderId\x12\x1d\n" +
+	"\n" +
+	"pickup_lat\x18\x03 \x01(\x01R\tpickupLat\x12\x1d\n" +
+	"\n" +
+	"pickup_lng\x18\x04 \x01(\x01R\tpickupLng\x12'\n" +
+	"\x0fdestination_lat\x18\x05 \x01(\x01R\x0edestinationLat\x12'\n" +
+	"\x0fdestination_lng\x18\x06 \x01(\x01R\x0edestinationLng\x12\x16\n" +
+	"\x06status\x18\a \x01(\tR\x06status\x12,\n" +
+	"\x12assigned_driver_id\x18\b \x01(\tR\x10assignedDriverId\x12)\n" +
+	"\x10surge_multiplier\x18\t \x01(\x01R\x0fsurgeMultiplier\x12%\n" +
+	"\x0epayment_method\x18\n" +
+	" \x01(\tR\rpaymentMethod\x12\x12\n" +
+	"\x04tier\x18\v \x01(\tR\x04tier\x12\x1b\n" +
+	"\tride_type\x18\f \x01(\tR\brideType\x123\n" +
+	"\x15required_capabilities\x18\r \x03(\tR\x14requiredCapabilities\x12\x19\n" +
+	"\bquote_id\x18\x0e \x01(\tR\aquoteId\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x0f \x01(\tR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\x10 \x01(\tR\tupdatedAt\"\x99\x01\n" +
+	"\x1bUpdateDriverLocationRequest\x12\x1b\n" +
+	"\tdriver_id\x18\x01 \x01(\tR\bdriverId\x12\x10\n" +
+	"\x03lat\x18\x02 \x01(\x01R\x03lat\x12\x10\n" +
+	"\x03lng\x18\x03 \x01(\x01R\x03lng\x12\x18\n" +
+	"\aheading\x18\x04 \x01(\x01R\aheading\x12\x1f\n" +
+	"\vrecorded_at\x18\x05 \x01(\tR\n" +
+	"recordedAt\"\x1e\n" +
+	"\x1cUpdateDriverLocationResponse\"v\n" +
+	"\x11AcceptRideRequest\x12\x1b\n" +
+	"\tdriver_id\x18\x01 \x01(\tR\bdriverId\x12\x17\n" +
+	"\aride_id\x18\x02 \x01(\tR\x06rideId\x12+\n" +
+	"\x11override_geofence\x18\x03 \x01(\bR\x10overrideGeofence\")\n" +
+	"\x0eEndTripRequest\x12\x17\n" +
+	"\atrip_id\x18\x01 \x01(\tR\x06tripId\"\xac\x02\n" +
+	"\x04Trip\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
+	"\aride_id\x18\x02 \x01(\tR\x06rideId\x12\x1b\n" +
+	"\tdriver_id\x18\x03 \x01(\tR\bdriverId\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12\x12\n" +
+	"\x04fare\x18\x05 \x01(\x01R\x04fare\x12\x1d\n" +
+	"\n" +
+	"started_at\x18\x06 \x01(\tR\tstartedAt\x12\x19\n" +
+	"\bended_at\x18\a \x01(\tR\aendedAt\x120\n" +
+	"\x14total_paused_seconds\x18\b \x01(\x03R\x12totalPausedSeconds\x12!\n" +
+	"\fneeds_review\x18\t \x01(\bR\vneedsReview\x12#\n" +
+	"\runcapped_fare\x18\n" +
+	" \x01(\x01R\funcappedFare\"\x82\x01\n" +
+	"\aPayment\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06amount\x18\x02 \x01(\x01R\x06amount\x12\x10\n" +
+	"\x03fee\x18\x03 \x01(\x01R\x03fee\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12%\n" +
+	"\x0efailure_reason\x18\x05 \x01(\tR\rfailureReason\"\x8f\x03\n" +
+	"\aReceipt\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
+	"\tbase_fare\x18\x02 \x01(\x01R\bbaseFare\x12)\n" +
+	"\x10surge_multiplier\x18\x03 \x01(\x01R\x0fsurgeMultiplier\x12!\n" +
+	"\fsurge_amount\x18\x04 \x01(\x01R\vsurgeAmount\x12)\n" +
+	"\x10surcharge_amount\x18\x05 \x01(\x01R\x0fsurchargeAmount\x12%\n" +
+	"\x0eprocessing_fee\x18\x06 \x01(\x01R\rprocessingFee\x12\x1d\n" +
+	"\n" +
+	"total_fare\x18\a \x01(\x01R\ttotalFare\x12%\n" +
+	"\x0epayment_method\x18\b \x01(\tR\rpaymentMethod\x12%\n" +
+	"\x0epayment_status\x18\t \x01(\tR\rpaymentStatus\x12)\n" +
+	"\x10duration_minutes\x18\n" +
+	" \x01(\x01R\x0fdurationMinutes\x12\x1f\n" +
+	"\vdistance_km\x18\v \x01(\x01R\n" +
+	"distanceKm\"\x8c\x01\n" +
+	"\x0fEndTripResponse\x12!\n" +
+	"\x04trip\x18\x01 \x01(\v2\r.ride.v1.TripR\x04trip\x12*\n" +
+	"\apayment\x18\x02 \x01(\v2\x10.ride.v1.PaymentR\apayment\x12*\n" +
+	"\areceipt\x18\x03 \x01(\v2\x10.ride.v1.ReceiptR\areceipt2\xe3\x02\n" +
+	"\vRideService\x12E\n" +
+	"\n" +
+	"CreateRide\x12\x1a.ride.v1.CreateRideRequest\x1a\x1b.ride.v1.CreateRideResponse\x121\n" +
+	"\aGetRide\x12\x17.ride.v1.GetRideRequest\x1a\r.ride.v1.Ride\x12c\n" +
+	"\x14UpdateDriverLocation\x12$.ride.v1.UpdateDriverLocationRequest\x1a%.ride.v1.UpdateDriverLocationResponse\x127\n" +
+	"\n" +
+	"AcceptRide\x12\x1a.ride.v1.AcceptRideRequest\x1a\r.ride.v1.Trip\x12<\n" +
+	"\aEndTrip\x12\x17.ride.v1.EndTripRequest\x1a\x18.ride.v1.EndTripResponseB\x1bZ\x19ride/internal/grpc/ridepbb\x06proto3"
+
+var (
+	file_ride_proto_rawDescOnce sync.Once
+	file_ride_proto_rawDescData []byte
+)
+
+func file_ride_proto_rawDescGZIP() []byte {
+	file_ride_proto_rawDescOnce.Do(func() {
+		file_ride_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_ride_proto_rawDesc), len(file_ride_proto_rawDesc)))
+	})
+	return file_ride_proto_rawDescData
+}
+
+var file_ride_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_ride_proto_goTypes = []any{
+	(*CreateRideRequest)(nil),            // 0: ride.v1.CreateRideRequest
+	(*CreateRideResponse)(nil),           // 1: ride.v1.CreateRideResponse
+	(*GetRideRequest)(nil),               // 2: ride.v1.GetRideRequest
+	(*Ride)(nil),                         // 3: ride.v1.Ride
+	(*UpdateDriverLocationRequest)(nil),  // 4: ride.v1.UpdateDriverLocationRequest
+	(*UpdateDriverLocationResponse)(nil), // 5: ride.v1.UpdateDriverLocationResponse
+	(*AcceptRideRequest)(nil),            // 6: ride.v1.AcceptRideRequest
+	(*EndTripRequest)(nil),               // 7: ride.v1.EndTripRequest
+	(*Trip)(nil),                         // 8: ride.v1.Trip
+	(*Payment)(nil),                      // 9: ride.v1.Payment
+	(*Receipt)(nil),                      // 10: ride.v1.Receipt
+	(*EndTripResponse)(nil),              // 11: ride.v1.EndTripResponse
+}
+var file_ride_proto_depIdxs = []int32{
+	3,  // 0: ride.v1.CreateRideResponse.ride:type_name -> ride.v1.Ride
+	8,  // 1: ride.v1.EndTripResponse.trip:type_name -> ride.v1.Trip
+	9,  // 2: ride.v1.EndTripResponse.payment:type_name -> ride.v1.Payment
+	10, // 3: ride.v1.EndTripResponse.receipt:type_name -> ride.v1.Receipt
+	0,  // 4: ride.v1.RideService.CreateRide:input_type -> ride.v1.CreateRideRequest
+	2,  // 5: ride.v1.RideService.GetRide:input_type -> ride.v1.GetRideRequest
+	4,  // 6: ride.v1.RideService.UpdateDriverLocation:input_type -> ride.v1.UpdateDriverLocationRequest
+	6,  // 7: ride.v1.RideService.AcceptRide:input_type -> ride.v1.AcceptRideRequest
+	7,  // 8: ride.v1.RideService.EndTrip:input_type -> ride.v1.EndTripRequest
+	1,  // 9: ride.v1.RideService.CreateRide:output_type -> ride.v1.CreateRideResponse
+	3,  // 10: ride.v1.RideService.GetRide:output_type -> ride.v1.Ride
+	5,  // 11: ride.v1.RideService.UpdateDriverLocation:output_type -> ride.v1.UpdateDriverLocationResponse
+	8,  // 12: ride.v1.RideService.AcceptRide:output_type -> ride.v1.Trip
+	11, // 13: ride.v1.RideService.EndTrip:output_type -> ride.v1.EndTripResponse
+	9,  // [9:14] is the sub-list for method output_type
+	4,  // [4:9] is the sub-list for method input_type
+	4,  // [4:4] is the sub-list for extension type_name
+	4,  // [4:4] is the sub-list for extension extendee
+	0,  // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_ride_proto_init() }
+func file_ride_proto_init() {
+	if File_ride_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_ride_proto_rawDesc), len(file_ride_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_ride_proto_goTypes,
+		DependencyIndexes: file_ride_proto_depIdxs,
+		MessageInfos:      file_ride_proto_msgTypes,
+	}.Build()
+	File_ride_proto = out.File
+	file_ride_proto_goTypes = nil
+	file_ride_proto_depIdxs = nil
+}
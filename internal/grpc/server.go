@@ -0,0 +1,247 @@
+// Package grpc serves the internal gRPC API defined in ridepb/ride.proto.
+// It shares the REST handlers' service layer, request validation and error
+// classification, so a request behaves the same over either API.
+package grpc
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+	gogrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"ride/internal/domain"
+	"ride/internal/grpc/ridepb"
+	"ride/internal/handler"
+	"ride/internal/middleware"
+	"ride/internal/repository"
+	"ride/internal/service"
+)
+
+// Server implements ridepb.RideServiceServer.
+type Server struct {
+	ridepb.UnimplementedRideServiceServer
+
+	rideService   *service.RideService
+	driverService *service.DriverService
+	tripService   *service.TripService
+	catalog       *domain.Catalog // Payment methods and tiers a ride may ask for
+	adminToken    string          // Recognizes admin callers; see callerFrom
+}
+
+// NewServer creates a new Server. A nil catalog means the default catalog.
+func NewServer(rideService *service.RideService, driverService *service.DriverService, tripService *service.TripService, catalog *domain.Catalog, adminToken string) *Server {
+	if catalog == nil {
+		catalog = domain.DefaultCatalog()
+	}
+	return &Server{
+		rideService:   rideService,
+		driverService: driverService,
+		tripService:   tripService,
+		catalog:       catalog,
+		adminToken:    adminToken,
+	}
+}
+
+// NewGRPCServer returns a gRPC server for srv, with interceptors that
+// instrument each call with New Relic (off when nrApp is nil), log it to
+// logOut (os.Stdout when nil) and recover panics into INTERNAL errors.
+func NewGRPCServer(srv ridepb.RideServiceServer, nrApp *newrelic.Application, logOut io.Writer) *gogrpc.Server {
+	server := gogrpc.NewServer(gogrpc.ChainUnaryInterceptor(
+		NewRelicInterceptor(nrApp),
+		LoggingInterceptor(logOut),
+		RecoveryInterceptor(logOut),
+	))
+	ridepb.RegisterRideServiceServer(server, srv)
+	return server
+}
+
+// CreateRide requests a ride, like POST /v1/rides.
+func (s *Server) CreateRide(ctx context.Context, req *ridepb.CreateRideRequest) (*ridepb.CreateRideResponse, error) {
+	createReq, err := handler.NewCreateRideRequest(handler.CreateRideRequest{
+		RiderID:              req.GetRiderId(),
+		PickupLat:            req.GetPickupLat(),
+		PickupLng:            req.GetPickupLng(),
+		DestinationLat:       req.GetDestinationLat(),
+		DestinationLng:       req.GetDestinationLng(),
+		Tier:                 req.GetTier(),
+		PaymentMethod:        req.GetPaymentMethod(),
+		QuoteID:              req.GetQuoteId(),
+		RideType:             req.GetRideType(),
+		RequiredCapabilities: req.GetRequiredCapabilities(),
+		PaymentInstrumentID:  req.GetPaymentInstrumentId(),
+		ExcludeDriverIDs:     req.GetExcludeDriverIds(),
+	}, s.catalog)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	result, err := s.rideService.CreateRide(ctx, createReq)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	ride := newRide(result.Ride)
+	ride.AssignedDriverId = result.DriverID
+	ride.SurgeMultiplier = result.SurgeMultiplier
+	return &ridepb.CreateRideResponse{
+		Ride:           ride,
+		DriverAssigned: result.DriverAssigned,
+		QuoteRejected:  result.QuoteRejected,
+	}, nil
+}
+
+// GetRide returns a ride, like GET /v1/rides/:id. Callers identify
+// themselves with x-user-id and authorization metadata, as REST callers do
+// with headers.
+func (s *Server) GetRide(ctx context.Context, req *ridepb.GetRideRequest) (*ridepb.Ride, error) {
+	ride, err := s.rideService.GetRideStatus(ctx, req.GetRideId())
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	caller := s.callerFrom(ctx)
+	if !caller.Admin && (caller.UserID == "" || (caller.UserID != ride.RiderID && caller.UserID != ride.AssignedDriverID)) {
+		return nil, statusError(repository.ErrNotFound)
+	}
+	return newRide(ride), nil
+}
+
+// UpdateDriverLocation records a driver's position, like
+// POST /v1/drivers/:id/location. Only the driver may call it.
+func (s *Server) UpdateDriverLocation(ctx context.Context, req *ridepb.UpdateDriverLocationRequest) (*ridepb.UpdateDriverLocationResponse, error) {
+	if s.callerFrom(ctx).UserID != req.GetDriverId() {
+		return nil, statusError(service.ErrNotTheDriver)
+	}
+
+	var recordedAt time.Time
+	if req.GetRecordedAt() != "" {
+		t, err := time.Parse(time.RFC3339, req.GetRecordedAt())
+		if err != nil {
+			return nil, statusError(service.ErrInvalidRecordedAt)
+		}
+		recordedAt = t
+	}
+
+	err := s.driverService.UpdateLocation(ctx, service.UpdateLocationRequest{
+		DriverID:   req.GetDriverId(),
+		Lat:        req.GetLat(),
+		Lng:        req.GetLng(),
+		Heading:    req.GetHeading(),
+		RecordedAt: recordedAt,
+	})
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &ridepb.UpdateDriverLocationResponse{}, nil
+}
+
+// AcceptRide starts the trip of a driver's assigned ride, like
+// POST /v1/drivers/:id/accept.
+func (s *Server) AcceptRide(ctx context.Context, req *ridepb.AcceptRideRequest) (*ridepb.Trip, error) {
+	trip, err := s.tripService.StartTrip(ctx, service.StartTripRequest{
+		RideID:   req.GetRideId(),
+		DriverID: req.GetDriverId(),
+		Override: req.GetOverrideGeofence(),
+	})
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return newTrip(trip), nil
+}
+
+// EndTrip ends a trip and settles its fare, like POST /v1/trips/:id/end.
+func (s *Server) EndTrip(ctx context.Context, req *ridepb.EndTripRequest) (*ridepb.EndTripResponse, error) {
+	result, err := s.tripService.EndTrip(ctx, service.EndTripRequest{
+		TripID: req.GetTripId(),
+	})
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	response := &ridepb.EndTripResponse{Trip: newTrip(result.Trip)}
+	if p := result.Payment; p != nil {
+		response.Payment = &ridepb.Payment{
+			Id:            p.ID,
+			Amount:        p.Amount,
+			Fee:           p.Fee,
+			Status:        string(p.Status),
+			FailureReason: p.FailureReason,
+		}
+	}
+	if r := result.Receipt; r != nil {
+		response.Receipt = &ridepb.Receipt{
+			Id:              r.ID,
+			BaseFare:        r.BaseFare,
+			SurgeMultiplier: r.SurgeMultiplier,
+			SurgeAmount:     r.SurgeAmount,
+			SurchargeAmount: r.SurchargeAmount,
+			ProcessingFee:   r.ProcessingFee,
+			TotalFare:       r.TotalFare,
+			PaymentMethod:   string(r.PaymentMethod),
+			PaymentStatus:   string(r.PaymentStatus),
+			DurationMinutes: r.Duration.Minutes(),
+			DistanceKm:      r.Distance,
+		}
+	}
+	return response, nil
+}
+
+// callerFrom returns who a call is made on behalf of, from its x-user-id and
+// authorization metadata.
+func (s *Server) callerFrom(ctx context.Context) middleware.Caller {
+	md, _ := metadata.FromIncomingContext(ctx)
+	first := func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+	return middleware.NewCaller(first("x-user-id"), first("authorization"), s.adminToken)
+}
+
+func newRide(ride *domain.Ride) *ridepb.Ride {
+	return &ridepb.Ride{
+		Id:                   ride.ID,
+		RiderId:              ride.RiderID,
+		PickupLat:            ride.PickupLat,
+		PickupLng:            ride.PickupLng,
+		DestinationLat:       ride.DestinationLat,
+		DestinationLng:       ride.DestinationLng,
+		Status:               string(ride.Status),
+		AssignedDriverId:     ride.AssignedDriverID,
+		SurgeMultiplier:      ride.SurgeMultiplier,
+		PaymentMethod:        string(ride.PaymentMethod),
+		Tier:                 string(ride.Tier),
+		RideType:             string(ride.Type),
+		RequiredCapabilities: ride.Capabilities,
+		QuoteId:              ride.QuoteID,
+		CreatedAt:            formatTimestamp(ride.CreatedAt),
+		UpdatedAt:            formatTimestamp(ride.UpdatedAt),
+	}
+}
+
+func newTrip(trip *domain.Trip) *ridepb.Trip {
+	return &ridepb.Trip{
+		Id:                 trip.ID,
+		RideId:             trip.RideID,
+		DriverId:           trip.DriverID,
+		Status:             string(trip.Status),
+		Fare:               trip.Fare,
+		StartedAt:          formatTimestamp(trip.StartedAt),
+		EndedAt:            formatTimestamp(trip.EndedAt),
+		TotalPausedSeconds: int64(trip.TotalPaused.Seconds()),
+		NeedsReview:        trip.NeedsReview,
+		UncappedFare:       trip.UncappedFare,
+	}
+}
+
+// formatTimestamp formats t as RFC 3339, or "" when t is zero.
+func formatTimestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
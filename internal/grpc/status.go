@@ -0,0 +1,72 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"ride/internal/handler"
+	"ride/internal/repository"
+	"ride/internal/service"
+)
+
+// statusError converts a service or repository error to a gRPC status
+// error. The code follows the HTTP status REST reports the error with, so
+// both APIs classify it alike. A service.ValidationError is INVALID_ARGUMENT
+// with a BadRequest detail listing every field problem.
+func statusError(err error) error {
+	var validationErr *service.ValidationError
+	if errors.As(err, &validationErr) {
+		st := status.New(codes.InvalidArgument, "invalid request")
+		violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(validationErr.Fields))
+		for _, f := range validationErr.Fields {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{Field: f.Field, Description: f.Err.Error()})
+		}
+		if detailed, detailErr := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations}); detailErr == nil {
+			st = detailed
+		}
+		return st.Err()
+	}
+
+	return status.Error(codeFor(err), err.Error())
+}
+
+// codeFor returns the gRPC code for err. Where one HTTP status covers errors
+// gRPC tells apart, the error itself decides.
+func codeFor(err error) codes.Code {
+	switch {
+	case errors.Is(err, repository.ErrVersionConflict):
+		return codes.Aborted
+	case errors.Is(err, repository.ErrDuplicate):
+		return codes.AlreadyExists
+	case errors.Is(err, context.Canceled):
+		return codes.Canceled
+	}
+
+	switch handler.HTTPStatus(err) {
+	case http.StatusBadRequest,
+		http.StatusRequestEntityTooLarge,
+		http.StatusUnsupportedMediaType:
+		return codes.InvalidArgument
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusConflict,
+		http.StatusUnprocessableEntity,
+		http.StatusPaymentRequired:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	default:
+		return codes.Internal
+	}
+}
@@ -0,0 +1,26 @@
+package handler
+
+import "ride/pkg/api"
+
+// Bodies shared with pkg/rideclient are defined in pkg/api.
+type (
+	ErrorResponse           = api.ErrorResponse
+	ValidationErrorResponse = api.ValidationErrorResponse
+	FieldError              = api.FieldError
+	CreateRideRequest       = api.CreateRideRequest
+	CreateRideResponse      = api.CreateRideResponse
+	GetRideResponse         = api.GetRideResponse
+	AssignedDriverResponse  = api.AssignedDriverResponse
+	UpdateLocationRequest   = api.UpdateLocationRequest
+	AcceptRideRequest       = api.AcceptRideRequest
+	AcceptRideResponse      = api.AcceptRideResponse
+	TripResponse            = api.TripResponse
+	PaymentInfo             = api.PaymentInfo
+	ReceiptInfo             = api.ReceiptInfo
+	FareSplitInfo           = api.FareSplitInfo
+	TripETAInfo             = api.TripETAInfo
+	FareShareInfo           = api.FareShareInfo
+	ProcessPaymentRequest   = api.ProcessPaymentRequest
+	PaymentResponse         = api.PaymentResponse
+	PaymentFailedResponse   = api.PaymentFailedResponse
+)
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/domain"
+	"ride/internal/middleware"
+	"ride/internal/service"
+)
+
+// TripAttachmentHandler handles HTTP requests for photos attached to trips.
+type TripAttachmentHandler struct {
+	attachmentService *service.TripAttachmentService
+	tripService       *service.TripService
+}
+
+// NewTripAttachmentHandler creates a new TripAttachmentHandler.
+func NewTripAttachmentHandler(attachmentService *service.TripAttachmentService, tripService *service.TripService) *TripAttachmentHandler {
+	return &TripAttachmentHandler{attachmentService: attachmentService, tripService: tripService}
+}
+
+// TripAttachmentResponse describes a photo attached to a trip. URL is a
+// signed path, relative to the API's base URL, that serves the image to
+// anyone holding it until ExpiresAt.
+type TripAttachmentResponse struct {
+	ID          string `json:"id"`
+	TripID      string `json:"trip_id"`
+	ContentType string `json:"content_type"`
+	SizeBytes   int64  `json:"size_bytes"`
+	URL         string `json:"url"`
+	ExpiresAt   string `json:"expires_at"`
+	CreatedAt   string `json:"created_at"`
+}
+
+// newTripAttachmentResponse maps an attachment with a freshly signed URL.
+func (h *TripAttachmentHandler) newTripAttachmentResponse(attachment *domain.TripAttachment) TripAttachmentResponse {
+	url, expiresAt := h.attachmentService.SignedURL(attachment, time.Now())
+	return TripAttachmentResponse{
+		ID:          attachment.ID,
+		TripID:      attachment.TripID,
+		ContentType: attachment.ContentType,
+		SizeBytes:   attachment.SizeBytes,
+		URL:         url,
+		ExpiresAt:   formatTimestamp(expiresAt),
+		CreatedAt:   formatTimestamp(attachment.CreatedAt),
+	}
+}
+
+// Upload handles POST /v1/trips/:id/attachments
+// The trip's driver (X-User-ID) attaches a JPEG, PNG or WebP photo, sent as
+// the "file" field of a multipart form, to a PACKAGE ride's trip before
+// ending it. 409 for other rides, 413 above ATTACHMENT_MAX_BYTES and 415 for
+// other file types.
+func (h *TripAttachmentHandler) Upload(c *gin.Context) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "file is required"})
+		return
+	}
+	if file.Size > h.attachmentService.MaxBytes() {
+		respondError(c, service.ErrAttachmentTooLarge)
+		return
+	}
+	f, err := file.Open()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, h.attachmentService.MaxBytes()+1))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	attachment, err := h.attachmentService.Attach(c.Request.Context(), service.AttachRequest{
+		TripID:   c.Param("id"),
+		DriverID: middleware.CallerFrom(c).UserID,
+		Data:     data,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusCreated, h.newTripAttachmentResponse(attachment))
+}
+
+// List handles GET /v1/trips/:id/attachments
+// Returns the trip's photos, oldest first, each with a newly signed URL.
+// Visible to the trip's rider and driver.
+func (h *TripAttachmentHandler) List(c *gin.Context) {
+	trip, err := h.tripService.GetTrip(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if err := authorizeRead(c, func() (string, string, error) {
+		riderID, err := h.tripService.GetTripRiderID(c.Request.Context(), trip)
+		return riderID, trip.DriverID, err
+	}); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	attachments, err := h.attachmentService.List(c.Request.Context(), trip.ID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response := make([]TripAttachmentResponse, 0, len(attachments))
+	for _, attachment := range attachments {
+		response = append(response, h.newTripAttachmentResponse(attachment))
+	}
+	respondJSON(c, http.StatusOK, response)
+}
+
+// Content handles GET /v1/attachments/:id/content?expires=&signature=
+// Serves the image behind a URL from Upload or List. The signature is the
+// authorization, so the URL can be used directly as an image source; 403
+// once it has expired or if it was altered.
+func (h *TripAttachmentHandler) Content(c *gin.Context) {
+	attachment, data, err := h.attachmentService.Open(c.Request.Context(), c.Param("id"), c.Query("expires"), c.Query("signature"), time.Now())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "private")
+	c.Data(http.StatusOK, attachment.ContentType, data)
+}
@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/middleware"
+	"ride/internal/repository"
+)
+
+// authorizeRead checks that the caller may see a ride-related record: admins
+// see everything, riders their own rides and drivers the rides assigned to
+// them. participants is only called for non-admins. Anyone else gets
+// repository.ErrNotFound, so the record's existence is not leaked.
+func authorizeRead(c *gin.Context, participants func() (riderID, driverID string, err error)) error {
+	caller := middleware.CallerFrom(c)
+	if caller.Admin {
+		return nil
+	}
+	if caller.UserID == "" {
+		return repository.ErrNotFound
+	}
+
+	riderID, driverID, err := participants()
+	if err != nil {
+		return err
+	}
+	if caller.UserID != riderID && caller.UserID != driverID {
+		return repository.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/domain"
+	"ride/internal/service"
+)
+
+// CampaignHandler handles HTTP requests for driver incentive campaigns.
+type CampaignHandler struct {
+	campaignService *service.CampaignService
+}
+
+// NewCampaignHandler creates a new CampaignHandler.
+func NewCampaignHandler(campaignService *service.CampaignService) *CampaignHandler {
+	return &CampaignHandler{campaignService: campaignService}
+}
+
+// CampaignRequest is the HTTP request body for creating or updating a campaign.
+type CampaignRequest struct {
+	Name         string    `json:"name"`
+	Criteria     string    `json:"criteria"`
+	Target       float64   `json:"target"`
+	RewardAmount float64   `json:"reward_amount"`
+	Tier         string    `json:"tier"`
+	City         string    `json:"city"` // Optional: limits the campaign to drivers in the city
+	StartsAt     time.Time `json:"starts_at"`
+	EndsAt       time.Time `json:"ends_at"`
+}
+
+// CampaignResponse is the HTTP response for campaign data.
+type CampaignResponse struct {
+	ID           string  `json:"id"`
+	Name         string  `json:"name"`
+	Criteria     string  `json:"criteria"`
+	Target       float64 `json:"target"`
+	RewardAmount float64 `json:"reward_amount"`
+	Tier         string  `json:"tier,omitempty"`
+	City         string  `json:"city,omitempty"`
+	StartsAt     string  `json:"starts_at"`
+	EndsAt       string  `json:"ends_at"`
+}
+
+// DriverCampaignResponse is a driver's live progress in one campaign.
+type DriverCampaignResponse struct {
+	Campaign    CampaignResponse `json:"campaign"`
+	Progress    float64          `json:"progress"`
+	Remaining   float64          `json:"remaining"`
+	Completed   bool             `json:"completed"`
+	CompletedAt string           `json:"completed_at,omitempty"`
+}
+
+// Create handles POST /v1/admin/campaigns
+func (h *CampaignHandler) Create(c *gin.Context) {
+	var req CampaignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	campaign, err := h.campaignService.CreateCampaign(c.Request.Context(), req.toServiceRequest())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusCreated, newCampaignResponse(campaign))
+}
+
+// GetAll handles GET /v1/admin/campaigns
+func (h *CampaignHandler) GetAll(c *gin.Context) {
+	campaigns, err := h.campaignService.ListCampaigns(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response := make([]CampaignResponse, 0, len(campaigns))
+	for _, campaign := range campaigns {
+		response = append(response, newCampaignResponse(campaign))
+	}
+
+	respondJSON(c, http.StatusOK, response)
+}
+
+// Get handles GET /v1/admin/campaigns/:id
+func (h *CampaignHandler) Get(c *gin.Context) {
+	campaign, err := h.campaignService.GetCampaign(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, newCampaignResponse(campaign))
+}
+
+// Update handles PUT /v1/admin/campaigns/:id
+func (h *CampaignHandler) Update(c *gin.Context) {
+	var req CampaignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	campaign, err := h.campaignService.UpdateCampaign(c.Request.Context(), c.Param("id"), req.toServiceRequest())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, newCampaignResponse(campaign))
+}
+
+// Delete handles DELETE /v1/admin/campaigns/:id
+func (h *CampaignHandler) Delete(c *gin.Context) {
+	if err := h.campaignService.DeleteCampaign(c.Request.Context(), c.Param("id")); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetDriverCampaigns handles GET /v1/drivers/:id/campaigns
+func (h *CampaignHandler) GetDriverCampaigns(c *gin.Context) {
+	standings, err := h.campaignService.DriverCampaigns(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response := make([]DriverCampaignResponse, 0, len(standings))
+	for _, s := range standings {
+		item := DriverCampaignResponse{
+			Campaign:  newCampaignResponse(s.Campaign),
+			Progress:  s.Progress.Progress,
+			Remaining: max(s.Campaign.Target-s.Progress.Progress, 0),
+			Completed: s.Progress.IsCompleted(),
+		}
+		if s.Progress.IsCompleted() {
+			item.CompletedAt = s.Progress.CompletedAt.Format(time.RFC3339)
+		}
+		response = append(response, item)
+	}
+
+	respondJSON(c, http.StatusOK, response)
+}
+
+func (req CampaignRequest) toServiceRequest() service.CampaignRequest {
+	return service.CampaignRequest{
+		Name:         req.Name,
+		Criteria:     domain.CampaignCriteria(req.Criteria),
+		Target:       req.Target,
+		RewardAmount: req.RewardAmount,
+		Tier:         domain.DriverTier(req.Tier),
+		City:         req.City,
+		StartsAt:     req.StartsAt,
+		EndsAt:       req.EndsAt,
+	}
+}
+
+func newCampaignResponse(campaign *domain.Campaign) CampaignResponse {
+	return CampaignResponse{
+		ID:           campaign.ID,
+		Name:         campaign.Name,
+		Criteria:     string(campaign.Criteria),
+		Target:       campaign.Target,
+		RewardAmount: campaign.RewardAmount,
+		Tier:         string(campaign.Tier),
+		City:         campaign.City,
+		StartsAt:     campaign.StartsAt.Format(time.RFC3339),
+		EndsAt:       campaign.EndsAt.Format(time.RFC3339),
+	}
+}
@@ -2,12 +2,15 @@ package handler
 
 import (
 	"errors"
+	"log"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 
 	"ride/internal/domain"
+	"ride/internal/middleware"
 	"ride/internal/repository"
 	"ride/internal/service"
 )
@@ -17,51 +20,81 @@ type DriverHandler struct {
 	driverService *service.DriverService
 	tripService   *service.TripService
 	driverRepo    repository.DriverRepository
+	phoneRegion   string                     // Region assumed for phone numbers without a country code
+	catalog       *domain.Catalog            // Tiers a driver may register for
+	trackerKeys   *service.TrackerKeyService // Optional: nil issues no tracker secret at registration
 }
 
-// NewDriverHandler creates a new DriverHandler.
-func NewDriverHandler(driverService *service.DriverService, tripService *service.TripService, driverRepo repository.DriverRepository) *DriverHandler {
+// NewDriverHandler creates a new DriverHandler. A nil catalog means the default catalog.
+func NewDriverHandler(driverService *service.DriverService, tripService *service.TripService, driverRepo repository.DriverRepository, phoneRegion string, catalog *domain.Catalog, trackerKeys *service.TrackerKeyService) *DriverHandler {
+	if catalog == nil {
+		catalog = domain.DefaultCatalog()
+	}
 	return &DriverHandler{
 		driverService: driverService,
 		tripService:   tripService,
 		driverRepo:    driverRepo,
+		phoneRegion:   phoneRegion,
+		catalog:       catalog,
+		trackerKeys:   trackerKeys,
 	}
 }
 
-// UpdateLocationRequest is the HTTP request body for updating driver location.
-type UpdateLocationRequest struct {
-	Lat float64 `json:"lat"`
-	Lng float64 `json:"lng"`
-}
-
-// AcceptRideRequest is the HTTP request body for accepting a ride.
-type AcceptRideRequest struct {
-	RideID string `json:"ride_id"`
+func toDriverResponse(d *domain.Driver) DriverResponse {
+	return DriverResponse{
+		ID:           d.ID,
+		Name:         d.Name,
+		Phone:        d.Phone,
+		Status:       string(d.Status),
+		Tier:         string(d.Tier),
+		Email:        d.Email,
+		VehiclePlate: d.VehiclePlate,
+		City:         d.City,
+		Capabilities: d.Capabilities,
+		CreatedAt:    formatTimestamp(d.CreatedAt),
+		UpdatedAt:    formatTimestamp(d.UpdatedAt),
+	}
 }
 
-// AcceptRideResponse is the HTTP response for accepting a ride.
-type AcceptRideResponse struct {
-	TripID    string `json:"trip_id"`
-	RideID    string `json:"ride_id"`
-	DriverID  string `json:"driver_id"`
-	Status    string `json:"status"`
-	StartedAt string `json:"started_at"`
+// NearbyDriverResponse is a driver position for the rider map.
+type NearbyDriverResponse struct {
+	DriverID string  `json:"driver_id"`
+	Lat      float64 `json:"lat"`
+	Lng      float64 `json:"lng"`
+	Heading  float64 `json:"heading"`
 }
 
 // RegisterDriverRequest is the HTTP request body for driver registration.
 type RegisterDriverRequest struct {
-	Name  string `json:"name"`
-	Phone string `json:"phone"`
-	Tier  string `json:"tier"`
+	Name         string   `json:"name"`
+	Phone        string   `json:"phone"`
+	Tier         string   `json:"tier"`
+	Email        string   `json:"email"`         // Optional
+	VehiclePlate string   `json:"vehicle_plate"` // Optional
+	City         string   `json:"city"`          // Optional: the city the driver works in, for city-limited campaigns
+	Capabilities []string `json:"capabilities"`  // Optional: vehicle capabilities, any case, e.g. WAV
 }
 
 // DriverResponse is the HTTP response for driver data.
 type DriverResponse struct {
-	ID     string `json:"id"`
-	Name   string `json:"name"`
-	Phone  string `json:"phone"`
-	Status string `json:"status"`
-	Tier   string `json:"tier"`
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	Phone        string   `json:"phone"`
+	Status       string   `json:"status"`
+	Tier         string   `json:"tier"`
+	Email        string   `json:"email,omitempty"`
+	VehiclePlate string   `json:"vehicle_plate,omitempty"`
+	City         string   `json:"city,omitempty"`
+	Capabilities []string `json:"capabilities,omitempty"`
+	CreatedAt    string   `json:"created_at,omitempty"`
+	UpdatedAt    string   `json:"updated_at,omitempty"`
+}
+
+// RegisterDriverResponse is the HTTP response for a registered driver. The
+// tracker secret is shown only once; rotating it issues a new one.
+type RegisterDriverResponse struct {
+	DriverResponse
+	TrackerSecret string `json:"tracker_secret,omitempty"`
 }
 
 // Register handles POST /v1/drivers/register
@@ -72,18 +105,22 @@ func (h *DriverHandler) Register(c *gin.Context) {
 		return
 	}
 
-	if req.Name == "" || req.Phone == "" {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "name and phone are required"})
+	driver, err := service.NewRegisteredDriver(service.DriverRegistration{
+		Name:         req.Name,
+		Phone:        req.Phone,
+		Tier:         req.Tier,
+		Email:        req.Email,
+		VehiclePlate: req.VehiclePlate,
+		City:         req.City,
+		Capabilities: req.Capabilities,
+	}, h.phoneRegion, h.catalog)
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 
-	tier := domain.DriverTierBasic
-	if req.Tier == "PREMIUM" {
-		tier = domain.DriverTierPremium
-	}
-
 	// Check if driver already exists
-	existing, err := h.driverRepo.GetByPhone(c.Request.Context(), req.Phone)
+	existing, err := h.driverRepo.GetByPhone(c.Request.Context(), driver.Phone)
 	if err != nil && !errors.Is(err, repository.ErrNotFound) {
 		respondError(c, err)
 		return
@@ -92,59 +129,116 @@ func (h *DriverHandler) Register(c *gin.Context) {
 	if existing != nil {
 		c.JSON(http.StatusConflict, gin.H{
 			"message": "Driver already registered",
-			"driver":  DriverResponse{ID: existing.ID, Name: existing.Name, Phone: existing.Phone, Status: string(existing.Status), Tier: string(existing.Tier)},
+			"driver":  toDriverResponse(existing),
 		})
 		return
 	}
 
-	// Create new driver
-	driver := &domain.Driver{
-		ID:     uuid.New().String(),
-		Name:   req.Name,
-		Phone:  req.Phone,
-		Status: domain.DriverStatusOffline,
-		Tier:   tier,
+	if driver.Email != "" {
+		if _, err := h.driverRepo.GetByEmail(c.Request.Context(), driver.Email); err == nil {
+			respondError(c, service.ErrEmailTaken)
+			return
+		} else if !errors.Is(err, repository.ErrNotFound) {
+			respondError(c, err)
+			return
+		}
 	}
 
+	// Create new driver
 	if err := h.driverRepo.Create(c.Request.Context(), driver); err != nil {
 		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, DriverResponse{
-		ID:     driver.ID,
-		Name:   driver.Name,
-		Phone:  driver.Phone,
-		Status: string(driver.Status),
-		Tier:   string(driver.Tier),
-	})
+	resp := RegisterDriverResponse{DriverResponse: toDriverResponse(driver)}
+	if h.trackerKeys != nil {
+		// The driver is registered either way; an admin can issue the
+		// secret later by rotating it.
+		secret, err := h.trackerKeys.Issue(c.Request.Context(), driver.ID)
+		if err != nil {
+			log.Printf("[DRIVER] Failed to issue a tracker secret to driver %s: %v", driver.ID, err)
+		}
+		resp.TrackerSecret = secret
+	}
+
+	c.JSON(http.StatusCreated, resp)
 }
 
+// Driver listing page size bounds.
+const (
+	defaultDriverListLimit = 50
+	maxDriverListLimit     = 200
+)
+
 // GetAll handles GET /v1/drivers
+// Supports ?status=, ?tier=, ?phone= (prefix), ?name= (substring), ?limit= and ?offset=.
 func (h *DriverHandler) GetAll(c *gin.Context) {
-	drivers, err := h.driverRepo.GetAll(c.Request.Context())
+	filter := repository.DriverFilter{
+		Status:      domain.DriverStatus(c.Query("status")),
+		Tier:        domain.DriverTier(c.Query("tier")),
+		PhonePrefix: c.Query("phone"),
+		Name:        c.Query("name"),
+		Limit:       defaultDriverListLimit,
+	}
+
+	switch filter.Status {
+	case "", domain.DriverStatusOnline, domain.DriverStatusOffline, domain.DriverStatusOnTrip, domain.DriverStatusBreak:
+	default:
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid status filter"})
+		return
+	}
+
+	if filter.Tier != "" && !h.catalog.HasTier(filter.Tier) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid tier filter"})
+		return
+	}
+
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxDriverListLimit {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "limit must be between 1 and 200"})
+			return
+		}
+		filter.Limit = limit
+	}
+
+	if v := c.Query("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "offset must be a non-negative integer"})
+			return
+		}
+		filter.Offset = offset
+	}
+
+	drivers, total, err := h.driverRepo.List(c.Request.Context(), filter)
 	if err != nil {
 		respondError(c, err)
 		return
 	}
 
-	var response []DriverResponse
+	response := make([]DriverResponse, 0, len(drivers))
 	for _, d := range drivers {
-		response = append(response, DriverResponse{
-			ID:     d.ID,
-			Name:   d.Name,
-			Phone:  d.Phone,
-			Status: string(d.Status),
-			Tier:   string(d.Tier),
-		})
+		response = append(response, toDriverResponse(d))
 	}
 
-	c.JSON(http.StatusOK, response)
+	c.JSON(http.StatusOK, ListResponse{
+		Data:   response,
+		Total:  total,
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	})
 }
 
 // UpdateLocation handles POST /v1/drivers/:id/location
+// Only the driver may report their location: the app identifies them with
+// X-User-ID, a tracker with its signature.
 func (h *DriverHandler) UpdateLocation(c *gin.Context) {
 	driverID := c.Param("id")
+	if middleware.CallerFrom(c).UserID != driverID {
+		respondError(c, service.ErrNotTheDriver)
+		return
+	}
 
 	var req UpdateLocationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -152,10 +246,22 @@ func (h *DriverHandler) UpdateLocation(c *gin.Context) {
 		return
 	}
 
+	var recordedAt time.Time
+	if req.RecordedAt != "" {
+		t, err := time.Parse(time.RFC3339, req.RecordedAt)
+		if err != nil {
+			respondError(c, service.ErrInvalidRecordedAt)
+			return
+		}
+		recordedAt = t
+	}
+
 	err := h.driverService.UpdateLocation(c.Request.Context(), service.UpdateLocationRequest{
-		DriverID: driverID,
-		Lat:      req.Lat,
-		Lng:      req.Lng,
+		DriverID:   driverID,
+		Lat:        req.Lat,
+		Lng:        req.Lng,
+		Heading:    req.Heading,
+		RecordedAt: recordedAt,
 	})
 	if err != nil {
 		respondError(c, err)
@@ -165,6 +271,211 @@ func (h *DriverHandler) UpdateLocation(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+// ChangeTierRequest is the HTTP request body for changing a driver's tier.
+type ChangeTierRequest struct {
+	Tier string `json:"tier" binding:"required"`
+}
+
+// TierChangeResponse is the HTTP response for a changed driver tier.
+type TierChangeResponse struct {
+	DriverID     string `json:"driver_id"`
+	PreviousTier string `json:"previous_tier"`
+	Tier         string `json:"tier"`
+	ChangedAt    string `json:"changed_at"`
+}
+
+// ChangeTier handles POST /v1/admin/drivers/:id/tier
+func (h *DriverHandler) ChangeTier(c *gin.Context) {
+	var req ChangeTierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	tier, err := service.ValidateTier(req.Tier, h.catalog)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	change, err := h.driverService.ChangeTier(c.Request.Context(), c.Param("id"), tier)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, TierChangeResponse{
+		DriverID:     change.DriverID,
+		PreviousTier: string(change.PreviousTier),
+		Tier:         string(change.Tier),
+		ChangedAt:    formatTimestamp(change.ChangedAt),
+	})
+}
+
+// StartBreak handles POST /v1/drivers/:id/break
+func (h *DriverHandler) StartBreak(c *gin.Context) {
+	driver, err := h.driverService.StartBreak(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toDriverResponse(driver))
+}
+
+// EndBreak handles POST /v1/drivers/:id/resume
+func (h *DriverHandler) EndBreak(c *gin.Context) {
+	driver, err := h.driverService.EndBreak(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toDriverResponse(driver))
+}
+
+// SetDestinationRequest is the HTTP request body for entering destination mode.
+type SetDestinationRequest struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+// DestinationResponse is the HTTP response for a driver's destination.
+type DestinationResponse struct {
+	DriverID  string  `json:"driver_id"`
+	Lat       float64 `json:"lat"`
+	Lng       float64 `json:"lng"`
+	ExpiresAt string  `json:"expires_at"`
+}
+
+// SetDestination handles PUT /v1/drivers/:id/destination
+func (h *DriverHandler) SetDestination(c *gin.Context) {
+	driverID := c.Param("id")
+
+	var req SetDestinationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	destination, err := h.driverService.SetDestination(c.Request.Context(), driverID, req.Lat, req.Lng)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, DestinationResponse{
+		DriverID:  driverID,
+		Lat:       destination.Lat,
+		Lng:       destination.Lng,
+		ExpiresAt: destination.ExpiresAt.Format(time.RFC3339),
+	})
+}
+
+// ClearDestination handles DELETE /v1/drivers/:id/destination
+func (h *DriverHandler) ClearDestination(c *gin.Context) {
+	if err := h.driverService.ClearDestination(c.Request.Context(), c.Param("id")); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetNearby handles GET /v1/drivers/nearby?lat=&lng=&radius_km=
+func (h *DriverHandler) GetNearby(c *gin.Context) {
+	lat, latErr := strconv.ParseFloat(c.Query("lat"), 64)
+	lng, lngErr := strconv.ParseFloat(c.Query("lng"), 64)
+	if latErr != nil || lngErr != nil {
+		respondError(c, service.ErrInvalidLocation)
+		return
+	}
+
+	var radiusKm float64
+	if raw := c.Query("radius_km"); raw != "" {
+		r, err := strconv.ParseFloat(raw, 64)
+		if err != nil || r <= 0 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid radius_km"})
+			return
+		}
+		radiusKm = r
+	}
+
+	locations, err := h.driverService.NearbyDrivers(c.Request.Context(), lat, lng, radiusKm)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response := make([]NearbyDriverResponse, 0, len(locations))
+	for _, loc := range locations {
+		response = append(response, NearbyDriverResponse{
+			DriverID: loc.DriverID,
+			Lat:      loc.Lat,
+			Lng:      loc.Lng,
+			Heading:  loc.Heading,
+		})
+	}
+
+	respondJSON(c, http.StatusOK, response)
+}
+
+// PendingRideResponse is a ride assigned to a driver that they have not
+// accepted yet. Offers do not expire, so there is no time left to accept.
+type PendingRideResponse struct {
+	RideID         string  `json:"ride_id"`
+	PickupLat      float64 `json:"pickup_lat"`
+	PickupLng      float64 `json:"pickup_lng"`
+	DestinationLat float64 `json:"destination_lat"`
+	DestinationLng float64 `json:"destination_lng"`
+	PaymentMethod  string  `json:"payment_method"`
+	AssignedAt     string  `json:"assigned_at,omitempty"`
+	ArrivedAt      string  `json:"arrived_at,omitempty"`
+}
+
+// DriverCurrentResponse is the HTTP response for what a driver is working
+// on; both fields are null for an idle driver.
+type DriverCurrentResponse struct {
+	Trip         *TripResponse        `json:"trip"`
+	PendingOffer *PendingRideResponse `json:"pending_offer"`
+}
+
+// GetCurrent handles GET /v1/drivers/:id/current
+// Returns the driver's active trip and any ride assigned to them but not yet
+// accepted, for a driver app recovering after a restart.
+func (h *DriverHandler) GetCurrent(c *gin.Context) {
+	driverID := c.Param("id")
+	if err := authorizeRead(c, func() (string, string, error) { return "", driverID, nil }); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	current, err := h.tripService.GetDriverCurrent(c.Request.Context(), driverID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	var response DriverCurrentResponse
+	if current.Trip != nil {
+		trip := newTripResponse(current.Trip)
+		response.Trip = &trip
+	}
+	if ride := current.PendingRide; ride != nil {
+		response.PendingOffer = &PendingRideResponse{
+			RideID:         ride.ID,
+			PickupLat:      ride.PickupLat,
+			PickupLng:      ride.PickupLng,
+			DestinationLat: ride.DestinationLat,
+			DestinationLng: ride.DestinationLng,
+			PaymentMethod:  string(ride.PaymentMethod),
+			AssignedAt:     formatTimestamp(ride.AssignedAt),
+			ArrivedAt:      formatTimestamp(ride.ArrivedAt),
+		}
+	}
+	respondJSON(c, http.StatusOK, response)
+}
+
 // AcceptRide handles POST /v1/drivers/:id/accept
 func (h *DriverHandler) AcceptRide(c *gin.Context) {
 	driverID := c.Param("id")
@@ -178,6 +489,7 @@ func (h *DriverHandler) AcceptRide(c *gin.Context) {
 	trip, err := h.tripService.StartTrip(c.Request.Context(), service.StartTripRequest{
 		RideID:   req.RideID,
 		DriverID: driverID,
+		Override: req.OverrideGeofence,
 	})
 	if err != nil {
 		respondError(c, err)
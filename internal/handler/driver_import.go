@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/service"
+)
+
+// DriverImportHandler handles admin HTTP requests for bulk driver onboarding.
+type DriverImportHandler struct {
+	importService *service.DriverImportService
+}
+
+// NewDriverImportHandler creates a new DriverImportHandler.
+func NewDriverImportHandler(importService *service.DriverImportService) *DriverImportHandler {
+	return &DriverImportHandler{importService: importService}
+}
+
+// DriverImportRowResponse is the outcome of one imported row.
+type DriverImportRowResponse struct {
+	Row      int    `json:"row"`
+	Status   string `json:"status"` // IMPORTED or FAILED
+	DriverID string `json:"driver_id,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// DriverImportResponse is the HTTP response for a driver import.
+type DriverImportResponse struct {
+	Imported int                       `json:"imported"`
+	Failed   int                       `json:"failed"`
+	Rows     []DriverImportRowResponse `json:"rows"`
+}
+
+// errInvalidImportCSV is returned for CSV that cannot be read as driver rows.
+var errInvalidImportCSV = errors.New("invalid csv: expected a header row with name and phone columns")
+
+// Import handles POST /v1/admin/drivers/import
+// The body is a JSON array of driver registrations, or CSV with a header row
+// naming the name, phone, tier, email, vehicle_plate, city and capabilities
+// (space-separated) columns, sent as text/csv or as the "file" field of a multipart form. Rows are numbered
+// from 1, not counting the CSV header.
+func (h *DriverImportHandler) Import(c *gin.Context) {
+	var regs []service.DriverRegistration
+	switch c.ContentType() {
+	case "text/csv":
+		var err error
+		if regs, err = parseDriverImportCSV(c.Request.Body); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+	case "multipart/form-data":
+		file, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "file is required"})
+			return
+		}
+		f, err := file.Open()
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		defer f.Close()
+		if regs, err = parseDriverImportCSV(f); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+	default:
+		var req []RegisterDriverRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+			return
+		}
+		for _, r := range req {
+			regs = append(regs, service.DriverRegistration{
+				Name:         r.Name,
+				Phone:        r.Phone,
+				Tier:         r.Tier,
+				Email:        r.Email,
+				VehiclePlate: r.VehiclePlate,
+				City:         r.City,
+				Capabilities: r.Capabilities,
+			})
+		}
+	}
+
+	result, err := h.importService.Import(c.Request.Context(), regs)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	resp := DriverImportResponse{
+		Imported: result.Imported,
+		Failed:   result.Failed,
+		Rows:     make([]DriverImportRowResponse, 0, len(result.Rows)),
+	}
+	for _, row := range result.Rows {
+		rowResp := DriverImportRowResponse{Row: row.Row, Status: "IMPORTED", DriverID: row.DriverID}
+		if row.Err != nil {
+			rowResp.Status = "FAILED"
+			rowResp.Error = row.Err.Error()
+		}
+		resp.Rows = append(resp.Rows, rowResp)
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// parseDriverImportCSV reads driver registrations from CSV with a header
+// row. Columns are matched by name, case-insensitively; unknown columns are
+// ignored.
+func parseDriverImportCSV(r io.Reader) ([]service.DriverRegistration, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errInvalidImportCSV
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["name"]; !ok {
+		return nil, errInvalidImportCSV
+	}
+	if _, ok := columns["phone"]; !ok {
+		return nil, errInvalidImportCSV
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var regs []service.DriverRegistration
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return regs, nil
+		}
+		if err != nil {
+			return nil, errInvalidImportCSV
+		}
+		regs = append(regs, service.DriverRegistration{
+			Name:         field(record, "name"),
+			Phone:        field(record, "phone"),
+			Tier:         field(record, "tier"),
+			Email:        field(record, "email"),
+			VehiclePlate: field(record, "vehicle_plate"),
+			City:         field(record, "city"),
+			Capabilities: strings.Fields(field(record, "capabilities")),
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/service"
+)
+
+// DriverLockHandler handles admin HTTP requests for driver matching locks.
+type DriverLockHandler struct {
+	matchingService *service.MatchingService
+}
+
+// NewDriverLockHandler creates a new DriverLockHandler.
+func NewDriverLockHandler(matchingService *service.MatchingService) *DriverLockHandler {
+	return &DriverLockHandler{matchingService: matchingService}
+}
+
+// UnlockDriverResponse is the HTTP response for a forced driver unlock.
+type UnlockDriverResponse struct {
+	DriverID  string `json:"driver_id"`
+	WasLocked bool   `json:"was_locked"`
+}
+
+// Unlock handles POST /v1/admin/drivers/:id/unlock
+func (h *DriverLockHandler) Unlock(c *gin.Context) {
+	driverID := c.Param("id")
+
+	wasLocked, err := h.matchingService.ReleaseDriverLock(c.Request.Context(), driverID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, UnlockDriverResponse{DriverID: driverID, WasLocked: wasLocked})
+}
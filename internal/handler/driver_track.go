@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/service"
+)
+
+// DriverTrackHandler handles admin HTTP requests for driver location history.
+type DriverTrackHandler struct {
+	historyService *service.LocationHistoryService
+}
+
+// NewDriverTrackHandler creates a new DriverTrackHandler.
+func NewDriverTrackHandler(historyService *service.LocationHistoryService) *DriverTrackHandler {
+	return &DriverTrackHandler{historyService: historyService}
+}
+
+// TrackPointResponse is one recorded driver position.
+type TrackPointResponse struct {
+	Lat        float64 `json:"lat"`
+	Lng        float64 `json:"lng"`
+	Heading    float64 `json:"heading"`
+	RecordedAt string  `json:"recorded_at"`
+}
+
+// DriverTrackResponse is the HTTP response for a driver's recorded path.
+type DriverTrackResponse struct {
+	DriverID string               `json:"driver_id"`
+	Points   []TrackPointResponse `json:"points"`
+}
+
+// GetTrack handles GET /v1/admin/drivers/:id/track?from=&to=
+// from and to are RFC 3339 timestamps; both are optional.
+func (h *DriverTrackHandler) GetTrack(c *gin.Context) {
+	from, err := parseTrackTime(c.Query("from"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	to, err := parseTrackTime(c.Query("to"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	driverID := c.Param("id")
+	points, err := h.historyService.Track(c.Request.Context(), driverID, from, to)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response := DriverTrackResponse{DriverID: driverID, Points: make([]TrackPointResponse, 0, len(points))}
+	for _, p := range points {
+		response.Points = append(response.Points, TrackPointResponse{
+			Lat:        p.Lat,
+			Lng:        p.Lng,
+			Heading:    p.Heading,
+			RecordedAt: p.RecordedAt.Format(time.RFC3339Nano),
+		})
+	}
+
+	respondJSON(c, http.StatusOK, response)
+}
+
+// parseTrackTime parses an optional RFC 3339 query timestamp.
+func parseTrackTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, service.ErrInvalidTrackWindow
+	}
+	return t, nil
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/middleware"
+	"ride/internal/service"
+)
+
+// EarningsHandler handles HTTP requests for drivers' per-trip earnings.
+type EarningsHandler struct {
+	earningsService *service.EarningsService
+	tripService     *service.TripService
+}
+
+// NewEarningsHandler creates a new EarningsHandler.
+func NewEarningsHandler(earningsService *service.EarningsService, tripService *service.TripService) *EarningsHandler {
+	return &EarningsHandler{earningsService: earningsService, tripService: tripService}
+}
+
+// EarningsInfo breaks down what the driver made on a trip.
+type EarningsInfo struct {
+	GrossFare         float64 `json:"gross_fare"`
+	SurgeMultiplier   float64 `json:"surge_multiplier"`
+	SurgeAmount       float64 `json:"surge_amount"`     // Part of gross_fare due to surge
+	SurchargeAmount   float64 `json:"surcharge_amount"` // Part of gross_fare from zone surcharges, free of commission
+	CommissionPercent float64 `json:"commission_percent"`
+	Commission        float64 `json:"commission"`
+	Tip               float64 `json:"tip"`
+	NetEarnings       float64 `json:"net_earnings"` // gross_fare - commission + tip
+	PaymentMethod     string  `json:"payment_method"`
+	CashCollected     float64 `json:"cash_collected"` // Fare the driver took in cash
+	Payout            float64 `json:"payout"`         // net_earnings - cash_collected; negative when the driver owes commission
+}
+
+// TripEarningsResponse is a driver's view of a trip with its earnings.
+type TripEarningsResponse struct {
+	TripResponse
+	Earnings EarningsInfo `json:"earnings"`
+}
+
+// GetTripEarnings handles GET /v1/drivers/:id/trips/:tripId/earnings
+// Returns an ended or aborted trip of the driver's with its earnings
+// breakdown to the driver or an admin; 404 for another driver's trip or
+// another caller, and 409 while it is in progress.
+func (h *EarningsHandler) GetTripEarnings(c *gin.Context) {
+	driverID := c.Param("id")
+	if err := authorizeRead(c, func() (string, string, error) { return "", driverID, nil }); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	earnings, err := h.earningsService.TripEarnings(c.Request.Context(), driverID, c.Param("tripId"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	trip, err := h.tripService.GetTrip(c.Request.Context(), earnings.TripID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, TripEarningsResponse{
+		TripResponse: newTripResponse(trip),
+		Earnings: EarningsInfo{
+			GrossFare:         earnings.GrossFare,
+			SurgeMultiplier:   earnings.SurgeMultiplier,
+			SurgeAmount:       earnings.SurgeAmount,
+			SurchargeAmount:   earnings.SurchargeAmount,
+			CommissionPercent: earnings.CommissionPercent,
+			Commission:        earnings.Commission,
+			Tip:               earnings.Tip,
+			NetEarnings:       earnings.NetEarnings,
+			PaymentMethod:     string(earnings.PaymentMethod),
+			CashCollected:     earnings.CashCollected,
+			Payout:            earnings.Payout,
+		},
+	})
+}
+
+// TipRequest is the body of a tip on a trip.
+type TipRequest struct {
+	Amount float64 `json:"amount"`
+}
+
+// TipTrip handles POST /v1/trips/:id/tip
+// Charges the calling rider a tip on their ended trip, credited to the
+// driver in full. A trip is tipped once: repeating the request returns the
+// first tip, or retries it if its charge failed. 404 for another rider's
+// trip, 409 while it is in progress and 400 on a cash ride.
+func (h *EarningsHandler) TipTrip(c *gin.Context) {
+	var req TipRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	if req.Amount <= 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "amount must be positive"})
+		return
+	}
+
+	payment, err := h.earningsService.TipTrip(c.Request.Context(), middleware.CallerFrom(c).UserID, c.Param("id"), req.Amount)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondCharged(c, payment)
+}
@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/domain"
+	"ride/internal/middleware"
+	"ride/internal/repository"
+	"ride/internal/service"
+)
+
+// EventsHandler streams notifications to web clients as server-sent events.
+type EventsHandler struct {
+	notificationService *service.NotificationService
+	heartbeat           time.Duration
+}
+
+// NewEventsHandler creates a new EventsHandler. A heartbeat comment is sent
+// every heartbeat interval to keep idle connections open through proxies.
+func NewEventsHandler(notificationService *service.NotificationService, heartbeat time.Duration) *EventsHandler {
+	return &EventsHandler{
+		notificationService: notificationService,
+		heartbeat:           heartbeat,
+	}
+}
+
+// NotificationEventData is the JSON payload of a notification event.
+type NotificationEventData struct {
+	ID        int64          `json:"id"`
+	Type      string         `json:"type"`
+	Title     string         `json:"title"`
+	Message   string         `json:"message"`
+	Data      map[string]any `json:"data,omitempty"`
+	CreatedAt string         `json:"created_at"`
+}
+
+// StreamUserEvents handles GET /v1/users/:id/events
+// Streams the user's notifications as text/event-stream. Reconnecting clients
+// send Last-Event-ID (or ?last_event_id=) to replay events they missed.
+// Only the user and admins may subscribe; the ops team's stream is for
+// admins alone.
+func (h *EventsHandler) StreamUserEvents(c *gin.Context) {
+	recipientID := c.Param("id")
+	if recipientID == service.OpsRecipientID && !middleware.CallerFrom(c).Admin {
+		respondError(c, repository.ErrNotFound)
+		return
+	}
+	if _, ok := authorizeOwner(c); !ok {
+		return
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+
+	var afterID int64
+	if lastEventID != "" {
+		id, err := strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil || id < 0 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid Last-Event-ID"})
+			return
+		}
+		afterID = id
+	}
+
+	ctx := c.Request.Context()
+	sub, err := h.notificationService.Subscribe(ctx, recipientID, afterID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	defer sub.Close()
+
+	// Streams outlive the server's WriteTimeout; lift it for this response.
+	// If the writer doesn't support it, the client reconnects and replays.
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	for _, event := range sub.Replay {
+		if err := writeNotificationEvent(c.Writer, event); err != nil {
+			return
+		}
+	}
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Client disconnected.
+			return
+		case event, ok := <-sub.Events:
+			if !ok {
+				return
+			}
+			if err := writeNotificationEvent(c.Writer, event); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case <-ticker.C:
+			if _, err := io.WriteString(c.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+// writeNotificationEvent writes one SSE frame: id, event type and JSON data.
+func writeNotificationEvent(w io.Writer, event *domain.NotificationEvent) error {
+	data, err := json.Marshal(NotificationEventData{
+		ID:        event.ID,
+		Type:      event.Type,
+		Title:     event.Title,
+		Message:   event.Message,
+		Data:      event.Data,
+		CreatedAt: event.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	})
+	if err != nil {
+		return err
+	}
+
+	if event.ID > 0 {
+		if _, err := fmt.Fprintf(w, "id: %d\n", event.ID); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+	return err
+}
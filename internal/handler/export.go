@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"encoding/csv"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/domain"
+	"ride/internal/service"
+)
+
+// ExportHandler handles admin HTTP requests for CSV exports.
+type ExportHandler struct {
+	exportService *service.ExportService
+}
+
+// NewExportHandler creates a new ExportHandler.
+func NewExportHandler(exportService *service.ExportService) *ExportHandler {
+	return &ExportHandler{exportService: exportService}
+}
+
+var (
+	tripExportHeader = []string{
+		"id", "ride_id", "driver_id", "status", "fare", "started_at", "ended_at", "paused_seconds",
+		"needs_review", "aborted_by", "abort_reason", "created_at", "updated_at",
+	}
+	rideExportHeader = []string{
+		"id", "rider_id", "status", "assigned_driver_id", "pickup_lat", "pickup_lng", "destination_lat", "destination_lng",
+		"surge_multiplier", "payment_method", "surcharge_label", "surcharge_amount", "cancel_reason",
+		"requested_at", "assigned_at", "completed_at", "cancelled_at", "created_at", "updated_at",
+	}
+)
+
+// ExportTrips handles GET /v1/admin/export/trips?from=YYYY-MM-DD&to=YYYY-MM-DD&format=csv
+// Trips started on any day from from to to, inclusive, are streamed oldest first.
+func (h *ExportHandler) ExportTrips(c *gin.Context) {
+	from, to, ok := parseExportQuery(c)
+	if !ok {
+		return
+	}
+
+	stream := newCSVStream(c, "trips", from, to, tripExportHeader)
+	err := h.exportService.ExportTrips(c.Request.Context(), from, to, func(trip *domain.Trip) error {
+		return stream.write([]string{
+			trip.ID,
+			trip.RideID,
+			trip.DriverID,
+			string(trip.Status),
+			formatMoney(trip.Fare),
+			formatTimestamp(trip.StartedAt),
+			formatTimestamp(trip.EndedAt),
+			strconv.FormatInt(int64(trip.TotalPaused/time.Second), 10),
+			strconv.FormatBool(trip.NeedsReview),
+			string(trip.AbortedBy),
+			trip.AbortReason,
+			formatTimestamp(trip.CreatedAt),
+			formatTimestamp(trip.UpdatedAt),
+		})
+	})
+	stream.finish(err)
+}
+
+// ExportRides handles GET /v1/admin/export/rides?from=YYYY-MM-DD&to=YYYY-MM-DD&format=csv
+// Rides created on any day from from to to, inclusive, are streamed oldest first.
+func (h *ExportHandler) ExportRides(c *gin.Context) {
+	from, to, ok := parseExportQuery(c)
+	if !ok {
+		return
+	}
+
+	coord := func(f float64) string { return strconv.FormatFloat(f, 'f', -1, 64) }
+
+	stream := newCSVStream(c, "rides", from, to, rideExportHeader)
+	err := h.exportService.ExportRides(c.Request.Context(), from, to, func(ride *domain.Ride) error {
+		return stream.write([]string{
+			ride.ID,
+			ride.RiderID,
+			string(ride.Status),
+			ride.AssignedDriverID,
+			coord(ride.PickupLat),
+			coord(ride.PickupLng),
+			coord(ride.DestinationLat),
+			coord(ride.DestinationLng),
+			strconv.FormatFloat(ride.SurgeMultiplier, 'f', -1, 64),
+			string(ride.PaymentMethod),
+			ride.SurchargeLabel,
+			formatMoney(ride.SurchargeAmount),
+			ride.CancelReason,
+			formatTimestamp(ride.RequestedAt),
+			formatTimestamp(ride.AssignedAt),
+			formatTimestamp(ride.CompletedAt),
+			formatTimestamp(ride.CancelledAt),
+			formatTimestamp(ride.CreatedAt),
+			formatTimestamp(ride.UpdatedAt),
+		})
+	})
+	stream.finish(err)
+}
+
+// parseExportQuery reads the from, to and format query parameters,
+// responding with an error and returning false if any is invalid.
+func parseExportQuery(c *gin.Context) (time.Time, time.Time, bool) {
+	if format := c.Query("format"); format != "" && format != "csv" {
+		respondError(c, service.ErrUnsupportedExportFormat)
+		return time.Time{}, time.Time{}, false
+	}
+
+	from, err := time.Parse("2006-01-02", c.Query("from"))
+	if err != nil {
+		respondError(c, service.ErrInvalidExportRange)
+		return time.Time{}, time.Time{}, false
+	}
+	to, err := time.Parse("2006-01-02", c.Query("to"))
+	if err != nil {
+		respondError(c, service.ErrInvalidExportRange)
+		return time.Time{}, time.Time{}, false
+	}
+	return from, to, true
+}
+
+// csvStream writes CSV rows to the response as they are produced. The
+// headers are sent with the first row, so errors raised before any row is
+// written, such as the row cap, still get a JSON error response.
+type csvStream struct {
+	c        *gin.Context
+	w        *csv.Writer
+	filename string
+	header   []string
+	started  bool
+}
+
+func newCSVStream(c *gin.Context, name string, from, to time.Time, header []string) *csvStream {
+	return &csvStream{
+		c:        c,
+		w:        csv.NewWriter(c.Writer),
+		filename: name + "-" + from.Format("2006-01-02") + "-to-" + to.Format("2006-01-02") + ".csv",
+		header:   header,
+	}
+}
+
+// write sends one row and flushes it to the client.
+func (s *csvStream) write(row []string) error {
+	if !s.started {
+		if err := s.start(); err != nil {
+			return err
+		}
+	}
+	if err := s.w.Write(row); err != nil {
+		return err
+	}
+	s.w.Flush()
+	if err := s.w.Error(); err != nil {
+		return err
+	}
+	s.c.Writer.Flush()
+	return nil
+}
+
+// finish completes the stream. An error before the first row becomes an
+// error response; once rows are sent the status cannot change, so a later
+// error is logged and the export ends short.
+func (s *csvStream) finish(err error) {
+	if err != nil {
+		if !s.started {
+			respondError(s.c, err)
+			return
+		}
+		log.Printf("[EXPORT] %s ended early: %v", s.filename, err)
+		return
+	}
+
+	if !s.started {
+		// An empty range still gets the header row.
+		_ = s.start()
+	}
+	s.w.Flush()
+}
+
+// start sends the response headers and the CSV header row.
+func (s *csvStream) start() error {
+	s.started = true
+	s.c.Header("Content-Type", "text/csv; charset=utf-8")
+	s.c.Header("Content-Disposition", `attachment; filename="`+s.filename+`"`)
+	s.c.Status(http.StatusOK)
+	return s.w.Write(s.header)
+}
+
+// formatMoney formats an amount with two decimal places.
+func formatMoney(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/faults"
+)
+
+// FaultsHandler handles admin HTTP requests controlling QA fault injection.
+type FaultsHandler struct {
+	injector *faults.Injector
+}
+
+// NewFaultsHandler creates a new FaultsHandler.
+func NewFaultsHandler(injector *faults.Injector) *FaultsHandler {
+	return &FaultsHandler{injector: injector}
+}
+
+// SetFaultRequest is the HTTP request body for injecting a fault. All-zero
+// values clear the operation's fault.
+type SetFaultRequest struct {
+	Operation         string  `json:"operation" binding:"required"`
+	LatencyMs         int64   `json:"latency_ms"`
+	ErrorRate         float64 `json:"error_rate"`
+	ConnectionRefused bool    `json:"connection_refused"`
+}
+
+// FaultResponse is a fault currently injected into an operation.
+type FaultResponse struct {
+	Operation         string  `json:"operation"`
+	LatencyMs         int64   `json:"latency_ms"`
+	ErrorRate         float64 `json:"error_rate"`
+	ConnectionRefused bool    `json:"connection_refused"`
+}
+
+// FaultsResponse is the HTTP response listing the injected faults.
+type FaultsResponse struct {
+	Faults []FaultResponse `json:"faults"`
+}
+
+// Set handles POST /v1/admin/faults
+func (h *FaultsHandler) Set(c *gin.Context) {
+	var req SetFaultRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondJSON(c, http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	err := h.injector.Set(req.Operation, faults.Fault{
+		Latency:           time.Duration(req.LatencyMs) * time.Millisecond,
+		ErrorRate:         req.ErrorRate,
+		ConnectionRefused: req.ConnectionRefused,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, h.faultsResponse())
+}
+
+// GetAll handles GET /v1/admin/faults
+func (h *FaultsHandler) GetAll(c *gin.Context) {
+	respondJSON(c, http.StatusOK, h.faultsResponse())
+}
+
+// Clear handles DELETE /v1/admin/faults
+func (h *FaultsHandler) Clear(c *gin.Context) {
+	h.injector.Clear()
+	c.Status(http.StatusNoContent)
+}
+
+func (h *FaultsHandler) faultsResponse() FaultsResponse {
+	current := h.injector.Faults()
+	response := FaultsResponse{Faults: make([]FaultResponse, 0, len(current))}
+	for _, f := range current {
+		response.Faults = append(response.Faults, FaultResponse{
+			Operation:         f.Operation,
+			LatencyMs:         f.Latency.Milliseconds(),
+			ErrorRate:         f.ErrorRate,
+			ConnectionRefused: f.ConnectionRefused,
+		})
+	}
+	return response
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/domain"
+	"ride/internal/service"
+)
+
+// FeatureFlagHandler handles HTTP requests for feature flags.
+type FeatureFlagHandler struct {
+	flagService *service.FeatureFlagService
+}
+
+// NewFeatureFlagHandler creates a new FeatureFlagHandler.
+func NewFeatureFlagHandler(flagService *service.FeatureFlagService) *FeatureFlagHandler {
+	return &FeatureFlagHandler{flagService: flagService}
+}
+
+// FeatureFlagRequest is the HTTP request body for setting a flag's rules.
+type FeatureFlagRequest struct {
+	Enabled    bool     `json:"enabled"`
+	Percentage int      `json:"percentage"`
+	Cities     []string `json:"cities"`
+}
+
+// FeatureFlagResponse is the HTTP response for feature flag data.
+type FeatureFlagResponse struct {
+	Name       string   `json:"name"`
+	Enabled    bool     `json:"enabled"`
+	Percentage int      `json:"percentage"`
+	Cities     []string `json:"cities"`
+	UpdatedAt  string   `json:"updated_at"`
+}
+
+// Set handles PUT /v1/admin/flags/:name
+func (h *FeatureFlagHandler) Set(c *gin.Context) {
+	var req FeatureFlagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	flag, err := h.flagService.SetFlag(c.Request.Context(), c.Param("name"), service.FeatureFlagRequest{
+		Enabled:    req.Enabled,
+		Percentage: req.Percentage,
+		Cities:     req.Cities,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, newFeatureFlagResponse(flag))
+}
+
+// GetAll handles GET /v1/admin/flags
+func (h *FeatureFlagHandler) GetAll(c *gin.Context) {
+	flags, err := h.flagService.ListFlags(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response := make([]FeatureFlagResponse, 0, len(flags))
+	for _, flag := range flags {
+		response = append(response, newFeatureFlagResponse(flag))
+	}
+
+	respondJSON(c, http.StatusOK, response)
+}
+
+func newFeatureFlagResponse(flag *domain.FeatureFlag) FeatureFlagResponse {
+	cities := flag.Cities
+	if cities == nil {
+		cities = []string{}
+	}
+	return FeatureFlagResponse{
+		Name:       flag.Name,
+		Enabled:    flag.Enabled,
+		Percentage: flag.Percentage,
+		Cities:     cities,
+		UpdatedAt:  flag.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/service"
+)
+
+// MatchAttemptHandler handles HTTP requests for matching diagnostics.
+type MatchAttemptHandler struct {
+	matchingService *service.MatchingService
+}
+
+// NewMatchAttemptHandler creates a new MatchAttemptHandler.
+func NewMatchAttemptHandler(matchingService *service.MatchingService) *MatchAttemptHandler {
+	return &MatchAttemptHandler{matchingService: matchingService}
+}
+
+// MatchAttemptResponse is the HTTP response for a recorded match attempt.
+type MatchAttemptResponse struct {
+	ID                string  `json:"id"`
+	RideID            string  `json:"ride_id"`
+	Tier              string  `json:"tier,omitempty"`
+	RadiusKm          float64 `json:"radius_km"`
+	CandidatesFound   int     `json:"candidates_found"`
+	SkippedOffline    int     `json:"skipped_offline"`
+	SkippedTier       int     `json:"skipped_tier"`
+	SkippedLocked     int     `json:"skipped_locked"`
+	SkippedStale      int     `json:"skipped_stale"`
+	SkippedExcluded   int     `json:"skipped_excluded"`
+	SkippedDirection  int     `json:"skipped_direction"`
+	SkippedCapability int     `json:"skipped_capability"`
+	Degraded          bool    `json:"degraded"`
+	Outcome           string  `json:"outcome"`
+	AssignedDriverID  string  `json:"assigned_driver_id,omitempty"`
+	Error             string  `json:"error,omitempty"`
+	DurationMs        int64   `json:"duration_ms"`
+	CreatedAt         string  `json:"created_at"`
+}
+
+// GetAll handles GET /v1/admin/match-attempts?ride_id=
+func (h *MatchAttemptHandler) GetAll(c *gin.Context) {
+	attempts, err := h.matchingService.ListAttempts(c.Request.Context(), c.Query("ride_id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response := make([]MatchAttemptResponse, 0, len(attempts))
+	for _, a := range attempts {
+		response = append(response, MatchAttemptResponse{
+			ID:                a.ID,
+			RideID:            a.RideID,
+			Tier:              string(a.Tier),
+			RadiusKm:          a.RadiusKm,
+			CandidatesFound:   a.CandidatesFound,
+			SkippedOffline:    a.SkippedOffline,
+			SkippedTier:       a.SkippedTier,
+			SkippedLocked:     a.SkippedLocked,
+			SkippedStale:      a.SkippedStale,
+			SkippedExcluded:   a.SkippedExcluded,
+			SkippedDirection:  a.SkippedDirection,
+			SkippedCapability: a.SkippedCapability,
+			Degraded:          a.Degraded,
+			Outcome:           string(a.Outcome),
+			AssignedDriverID:  a.AssignedDriverID,
+			Error:             a.Error,
+			DurationMs:        a.Duration.Milliseconds(),
+			CreatedAt:         a.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	respondJSON(c, http.StatusOK, response)
+}
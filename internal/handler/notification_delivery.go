@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/domain"
+	"ride/internal/service"
+)
+
+// NotificationDeliveryHandler handles HTTP requests for the notification
+// dead-letter queue.
+type NotificationDeliveryHandler struct {
+	dispatcher *service.NotificationDispatcher
+}
+
+// NewNotificationDeliveryHandler creates a new NotificationDeliveryHandler.
+func NewNotificationDeliveryHandler(dispatcher *service.NotificationDispatcher) *NotificationDeliveryHandler {
+	return &NotificationDeliveryHandler{dispatcher: dispatcher}
+}
+
+// NotificationDeliveryResponse is the HTTP response for a queued notification
+// delivery.
+type NotificationDeliveryResponse struct {
+	ID            int64  `json:"id"`
+	Channel       string `json:"channel"`
+	RecipientID   string `json:"recipient_id"`
+	Type          string `json:"type"`
+	Title         string `json:"title"`
+	Status        string `json:"status"`
+	Attempts      int    `json:"attempts"`
+	NextAttemptAt string `json:"next_attempt_at,omitempty"`
+	LastError     string `json:"last_error,omitempty"`
+	CreatedAt     string `json:"created_at"`
+	UpdatedAt     string `json:"updated_at"`
+}
+
+func toNotificationDeliveryResponse(d *domain.NotificationDelivery) NotificationDeliveryResponse {
+	response := NotificationDeliveryResponse{
+		ID:          d.ID,
+		Channel:     string(d.Channel),
+		RecipientID: d.RecipientID,
+		Type:        d.Type,
+		Title:       d.Title,
+		Status:      string(d.Status),
+		Attempts:    d.Attempts,
+		LastError:   d.LastError,
+		CreatedAt:   formatTimestamp(d.CreatedAt),
+		UpdatedAt:   formatTimestamp(d.UpdatedAt),
+	}
+	if d.Status == domain.NotificationDeliveryPending {
+		response.NextAttemptAt = formatTimestamp(d.NextAttemptAt)
+	}
+	return response
+}
+
+// ListDead handles GET /v1/admin/notifications/dead
+func (h *NotificationDeliveryHandler) ListDead(c *gin.Context) {
+	deliveries, err := h.dispatcher.ListDead(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response := make([]NotificationDeliveryResponse, 0, len(deliveries))
+	for _, d := range deliveries {
+		response = append(response, toNotificationDeliveryResponse(d))
+	}
+	respondJSON(c, http.StatusOK, response)
+}
+
+// Requeue handles POST /v1/admin/notifications/:id/requeue
+func (h *NotificationDeliveryHandler) Requeue(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid notification id"})
+		return
+	}
+
+	delivery, err := h.dispatcher.Requeue(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondJSON(c, http.StatusOK, toNotificationDeliveryResponse(delivery))
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/domain"
+	"ride/internal/service"
+)
+
+// NotificationPreferenceHandler handles HTTP requests for the notification
+// types riders and drivers receive on each channel.
+type NotificationPreferenceHandler struct {
+	notificationService *service.NotificationService
+}
+
+// NewNotificationPreferenceHandler creates a new NotificationPreferenceHandler.
+func NewNotificationPreferenceHandler(notificationService *service.NotificationService) *NotificationPreferenceHandler {
+	return &NotificationPreferenceHandler{notificationService: notificationService}
+}
+
+// NotificationPreferencesRequest is the HTTP request body for updating
+// preferences. Types and channels left out keep their current setting.
+type NotificationPreferencesRequest struct {
+	Preferences map[string]map[string]bool `json:"preferences"` // e.g. {"PAYMENT_SUCCESS": {"PUSH": false}}
+}
+
+// NotificationPreferencesResponse is whether each notification type is sent
+// on each channel.
+type NotificationPreferencesResponse struct {
+	RecipientID string                     `json:"recipient_id"`
+	Preferences map[string]map[string]bool `json:"preferences"`
+}
+
+// Get handles GET /v1/users/:id/notification-preferences and
+// GET /v1/drivers/:id/notification-preferences
+func (h *NotificationPreferenceHandler) Get(c *gin.Context) {
+	recipientID, ok := authorizeOwner(c)
+	if !ok {
+		return
+	}
+
+	preferences, err := h.notificationService.GetPreferences(c.Request.Context(), recipientID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, newNotificationPreferencesResponse(recipientID, preferences))
+}
+
+// Update handles PUT /v1/users/:id/notification-preferences and
+// PUT /v1/drivers/:id/notification-preferences
+func (h *NotificationPreferenceHandler) Update(c *gin.Context) {
+	recipientID, ok := authorizeOwner(c)
+	if !ok {
+		return
+	}
+
+	var req NotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	changes := make(service.NotificationPreferences, len(req.Preferences))
+	for t, channels := range req.Preferences {
+		changes[service.NotificationType(t)] = make(map[domain.NotificationChannel]bool, len(channels))
+		for channel, enabled := range channels {
+			changes[service.NotificationType(t)][domain.NotificationChannel(channel)] = enabled
+		}
+	}
+
+	preferences, err := h.notificationService.UpdatePreferences(c.Request.Context(), recipientID, changes)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, newNotificationPreferencesResponse(recipientID, preferences))
+}
+
+func newNotificationPreferencesResponse(recipientID string, preferences service.NotificationPreferences) NotificationPreferencesResponse {
+	response := NotificationPreferencesResponse{
+		RecipientID: recipientID,
+		Preferences: make(map[string]map[string]bool, len(preferences)),
+	}
+	for t, channels := range preferences {
+		response.Preferences[string(t)] = make(map[string]bool, len(channels))
+		for channel, enabled := range channels {
+			response.Preferences[string(t)][string(channel)] = enabled
+		}
+	}
+	return response
+}
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/domain"
+	"ride/internal/service"
+)
+
+// OpsMapHandler handles admin HTTP requests for the live ops map.
+type OpsMapHandler struct {
+	opsMapService *service.OpsMapService
+}
+
+// NewOpsMapHandler creates a new OpsMapHandler.
+func NewOpsMapHandler(opsMapService *service.OpsMapService) *OpsMapHandler {
+	return &OpsMapHandler{opsMapService: opsMapService}
+}
+
+// MapDriverResponse is a driver on the ops map.
+type MapDriverResponse struct {
+	DriverID string  `json:"driver_id"`
+	Lat      float64 `json:"lat"`
+	Lng      float64 `json:"lng"`
+	Heading  float64 `json:"heading"`
+	Status   string  `json:"status"`
+	Tier     string  `json:"tier"`
+}
+
+// MapRequestResponse is an open ride request on the ops map.
+type MapRequestResponse struct {
+	RideID    string  `json:"ride_id"`
+	Lat       float64 `json:"lat"`
+	Lng       float64 `json:"lng"`
+	Status    string  `json:"status"`
+	Tier      string  `json:"tier"`
+	CreatedAt string  `json:"created_at"`
+}
+
+// OpsMapResponse is the HTTP response for the live ops map. The counts are
+// what was found in the box; when a layer was down-sampled its list is an
+// evenly spread subset and the sampled flag is set.
+type OpsMapResponse struct {
+	Drivers         []MapDriverResponse  `json:"drivers"`
+	DriverCount     int                  `json:"driver_count"`
+	DriversSampled  bool                 `json:"drivers_sampled"`
+	Requests        []MapRequestResponse `json:"requests"`
+	RequestCount    int                  `json:"request_count"`
+	RequestsSampled bool                 `json:"requests_sampled"`
+}
+
+// GetMap handles GET /v1/admin/map?min_lat=&min_lng=&max_lat=&max_lng=
+func (h *OpsMapHandler) GetMap(c *gin.Context) {
+	var box domain.BoundingBox
+	for _, param := range []struct {
+		name string
+		dest *float64
+	}{
+		{"min_lat", &box.MinLat},
+		{"min_lng", &box.MinLng},
+		{"max_lat", &box.MaxLat},
+		{"max_lng", &box.MaxLng},
+	} {
+		value, err := strconv.ParseFloat(c.Query(param.name), 64)
+		if err != nil {
+			respondError(c, service.ErrInvalidBoundingBox)
+			return
+		}
+		*param.dest = value
+	}
+
+	opsMap, err := h.opsMapService.Map(c.Request.Context(), box)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response := OpsMapResponse{
+		Drivers:         make([]MapDriverResponse, 0, len(opsMap.Drivers)),
+		DriverCount:     opsMap.DriverCount,
+		DriversSampled:  opsMap.DriversSampled,
+		Requests:        make([]MapRequestResponse, 0, len(opsMap.Requests)),
+		RequestCount:    opsMap.RequestCount,
+		RequestsSampled: opsMap.RequestsSampled,
+	}
+	for _, d := range opsMap.Drivers {
+		response.Drivers = append(response.Drivers, MapDriverResponse{
+			DriverID: d.DriverID,
+			Lat:      d.Lat,
+			Lng:      d.Lng,
+			Heading:  d.Heading,
+			Status:   string(d.Status),
+			Tier:     string(d.Tier),
+		})
+	}
+	for _, r := range opsMap.Requests {
+		response.Requests = append(response.Requests, MapRequestResponse{
+			RideID:    r.RideID,
+			Lat:       r.Lat,
+			Lng:       r.Lng,
+			Status:    string(r.Status),
+			Tier:      string(r.Tier),
+			CreatedAt: r.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	respondJSON(c, http.StatusOK, response)
+}
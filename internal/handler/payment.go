@@ -5,35 +5,53 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"ride/internal/domain"
 	"ride/internal/service"
 )
 
 // PaymentHandler handles HTTP requests for payments.
 type PaymentHandler struct {
 	paymentService *service.PaymentService
+	tripService    *service.TripService // Resolves who may read a payment
 }
 
 // NewPaymentHandler creates a new PaymentHandler.
-func NewPaymentHandler(paymentService *service.PaymentService) *PaymentHandler {
-	return &PaymentHandler{paymentService: paymentService}
+func NewPaymentHandler(paymentService *service.PaymentService, tripService *service.TripService) *PaymentHandler {
+	return &PaymentHandler{paymentService: paymentService, tripService: tripService}
 }
 
-// ProcessPaymentRequest is the HTTP request body for processing a payment.
-type ProcessPaymentRequest struct {
-	TripID string  `json:"trip_id"`
-	Amount float64 `json:"amount"`
+func toPaymentResponse(p *domain.Payment) PaymentResponse {
+	return PaymentResponse{
+		ID:             p.ID,
+		TripID:         p.TripID,
+		Amount:         p.Amount,
+		Fee:            p.Fee,
+		Status:         string(p.Status),
+		IdempotencyKey: p.IdempotencyKey,
+		InstrumentID:   p.InstrumentID,
+		FailureReason:  p.FailureReason,
+		RetryHint:      paymentRetryHint(p),
+		CreatedAt:      formatTimestamp(p.CreatedAt),
+		UpdatedAt:      formatTimestamp(p.UpdatedAt),
+	}
 }
 
-// PaymentResponse is the HTTP response for payment operations.
-type PaymentResponse struct {
-	ID             string  `json:"id"`
-	TripID         string  `json:"trip_id"`
-	Amount         float64 `json:"amount"`
-	Status         string  `json:"status"`
-	IdempotencyKey string  `json:"idempotency_key"`
+// paymentRetryHint tells the client how to recover a FAILED payment. An
+// Idempotency-Key replays the failure, so a retry needs a new one.
+func paymentRetryHint(p *domain.Payment) string {
+	switch {
+	case p.Status != domain.PaymentStatusFailed:
+		return ""
+	case p.Declined():
+		return "Resolve the decline with the card issuer, then retry with POST /v1/payments and a new Idempotency-Key"
+	default:
+		return "The payment provider could not be reached; retry with POST /v1/payments shortly"
+	}
 }
 
-// ProcessPayment handles POST /v1/payments
+// ProcessPayment handles POST /v1/payments. Processing a FAILED payment
+// charges it again; failing again is a 402 when declined and a 503 when the
+// payment provider could not be reached, with the reason and a retry hint.
 func (h *PaymentHandler) ProcessPayment(c *gin.Context) {
 	var req ProcessPaymentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -52,21 +70,37 @@ func (h *PaymentHandler) ProcessPayment(c *gin.Context) {
 	}
 
 	payment, err := h.paymentService.ProcessPayment(c.Request.Context(), service.ProcessPaymentRequest{
-		TripID: req.TripID,
-		Amount: req.Amount,
+		TripID:         req.TripID,
+		Amount:         req.Amount,
+		IdempotencyKey: req.IdempotencyKey,
 	})
 	if err != nil {
 		respondError(c, err)
 		return
 	}
 
-	respondJSON(c, http.StatusCreated, PaymentResponse{
-		ID:             payment.ID,
-		TripID:         payment.TripID,
-		Amount:         payment.Amount,
-		Status:         string(payment.Status),
-		IdempotencyKey: payment.IdempotencyKey,
-	})
+	respondCharged(c, payment)
+}
+
+// respondCharged responds with a charged payment: 201, or for a FAILED one a
+// 402 when declined and a 503 when the payment provider could not be
+// reached.
+func respondCharged(c *gin.Context, payment *domain.Payment) {
+	if payment.Status == domain.PaymentStatusFailed {
+		status, message := http.StatusPaymentRequired, domain.PaymentFailureDeclined
+		if !payment.Declined() {
+			status, message = http.StatusServiceUnavailable, domain.PaymentFailureProviderError
+		}
+		respondJSON(c, status, PaymentFailedResponse{
+			Error:         message,
+			FailureReason: payment.FailureReason,
+			RetryHint:     paymentRetryHint(payment),
+			Payment:       toPaymentResponse(payment),
+		})
+		return
+	}
+
+	respondJSON(c, http.StatusCreated, toPaymentResponse(payment))
 }
 
 // GetPayment handles GET /v1/payments/:id
@@ -79,11 +113,17 @@ func (h *PaymentHandler) GetPayment(c *gin.Context) {
 		return
 	}
 
-	respondJSON(c, http.StatusOK, PaymentResponse{
-		ID:             payment.ID,
-		TripID:         payment.TripID,
-		Amount:         payment.Amount,
-		Status:         string(payment.Status),
-		IdempotencyKey: payment.IdempotencyKey,
-	})
+	if err := authorizeRead(c, func() (string, string, error) {
+		trip, err := h.tripService.GetTrip(c.Request.Context(), payment.TripID)
+		if err != nil {
+			return "", "", err
+		}
+		riderID, err := h.tripService.GetTripRiderID(c.Request.Context(), trip)
+		return riderID, trip.DriverID, err
+	}); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, toPaymentResponse(payment))
 }
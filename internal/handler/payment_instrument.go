@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/domain"
+	"ride/internal/service"
+)
+
+// PaymentInstrumentHandler handles HTTP requests for the payment methods
+// users keep on file.
+type PaymentInstrumentHandler struct {
+	instrumentService *service.PaymentInstrumentService
+}
+
+// NewPaymentInstrumentHandler creates a new PaymentInstrumentHandler.
+func NewPaymentInstrumentHandler(instrumentService *service.PaymentInstrumentService) *PaymentInstrumentHandler {
+	return &PaymentInstrumentHandler{instrumentService: instrumentService}
+}
+
+// AddPaymentInstrumentRequest is the HTTP request body for adding a payment method.
+type AddPaymentInstrumentRequest struct {
+	Type          string `json:"type"`           // CARD, WALLET or UPI
+	Token         string `json:"token"`          // PSP token from client-side tokenization
+	MaskedDetails string `json:"masked_details"` // e.g. "VISA •••• 4242"
+	IsDefault     bool   `json:"is_default,omitempty"`
+}
+
+// UpdatePaymentInstrumentRequest is the HTTP request body for updating a payment method.
+type UpdatePaymentInstrumentRequest struct {
+	IsDefault bool `json:"is_default"` // Only true is accepted; pick another default to move it
+}
+
+// PaymentInstrumentResponse is the HTTP response for a payment method. The
+// PSP token is never returned.
+type PaymentInstrumentResponse struct {
+	ID            string `json:"id"`
+	UserID        string `json:"user_id"`
+	Type          string `json:"type"`
+	MaskedDetails string `json:"masked_details"`
+	IsDefault     bool   `json:"is_default"`
+	CreatedAt     string `json:"created_at,omitempty"`
+	UpdatedAt     string `json:"updated_at,omitempty"`
+}
+
+func toPaymentInstrumentResponse(i *domain.PaymentInstrument) PaymentInstrumentResponse {
+	return PaymentInstrumentResponse{
+		ID:            i.ID,
+		UserID:        i.UserID,
+		Type:          string(i.Type),
+		MaskedDetails: i.MaskedDetails,
+		IsDefault:     i.IsDefault,
+		CreatedAt:     formatTimestamp(i.CreatedAt),
+		UpdatedAt:     formatTimestamp(i.UpdatedAt),
+	}
+}
+
+// Create handles POST /v1/users/:id/payment-methods
+func (h *PaymentInstrumentHandler) Create(c *gin.Context) {
+	userID, ok := authorizeOwner(c)
+	if !ok {
+		return
+	}
+
+	var req AddPaymentInstrumentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	instrument, err := h.instrumentService.Add(c.Request.Context(), service.AddPaymentInstrumentRequest{
+		UserID:        userID,
+		Type:          domain.PaymentMethod(req.Type),
+		Token:         req.Token,
+		MaskedDetails: req.MaskedDetails,
+		IsDefault:     req.IsDefault,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusCreated, toPaymentInstrumentResponse(instrument))
+}
+
+// GetAll handles GET /v1/users/:id/payment-methods
+func (h *PaymentInstrumentHandler) GetAll(c *gin.Context) {
+	userID, ok := authorizeOwner(c)
+	if !ok {
+		return
+	}
+
+	instruments, err := h.instrumentService.List(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response := make([]PaymentInstrumentResponse, 0, len(instruments))
+	for _, instrument := range instruments {
+		response = append(response, toPaymentInstrumentResponse(instrument))
+	}
+	respondJSON(c, http.StatusOK, response)
+}
+
+// Get handles GET /v1/users/:id/payment-methods/:instrument_id
+func (h *PaymentInstrumentHandler) Get(c *gin.Context) {
+	userID, ok := authorizeOwner(c)
+	if !ok {
+		return
+	}
+
+	instrument, err := h.instrumentService.Get(c.Request.Context(), userID, c.Param("instrument_id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, toPaymentInstrumentResponse(instrument))
+}
+
+// Update handles PUT /v1/users/:id/payment-methods/:instrument_id
+func (h *PaymentInstrumentHandler) Update(c *gin.Context) {
+	userID, ok := authorizeOwner(c)
+	if !ok {
+		return
+	}
+
+	var req UpdatePaymentInstrumentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+	if !req.IsDefault {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "is_default must be true"})
+		return
+	}
+
+	instrument, err := h.instrumentService.SetDefault(c.Request.Context(), userID, c.Param("instrument_id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, toPaymentInstrumentResponse(instrument))
+}
+
+// Delete handles DELETE /v1/users/:id/payment-methods/:instrument_id
+func (h *PaymentInstrumentHandler) Delete(c *gin.Context) {
+	userID, ok := authorizeOwner(c)
+	if !ok {
+		return
+	}
+
+	if err := h.instrumentService.Delete(c.Request.Context(), userID, c.Param("instrument_id")); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// authorizeOwner returns the :id user when the caller is that user or an
+// admin, and otherwise responds not found.
+func authorizeOwner(c *gin.Context) (string, bool) {
+	userID := c.Param("id")
+	if err := authorizeRead(c, func() (string, string, error) { return userID, "", nil }); err != nil {
+		respondError(c, err)
+		return "", false
+	}
+	return userID, true
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/redis"
+)
+
+// RedisKeysHandler handles admin HTTP requests auditing Redis memory use.
+type RedisKeysHandler struct {
+	auditor *redis.KeyAuditor
+}
+
+// NewRedisKeysHandler creates a new RedisKeysHandler.
+func NewRedisKeysHandler(auditor *redis.KeyAuditor) *RedisKeysHandler {
+	return &RedisKeysHandler{auditor: auditor}
+}
+
+// NamespaceUsageResponse is the number of keys in one namespace.
+type NamespaceUsageResponse struct {
+	Namespace  string `json:"namespace"`
+	Keys       int64  `json:"keys"`
+	WithoutTTL int64  `json:"without_ttl"`
+	Cleanup    string `json:"cleanup"`
+}
+
+// RedisKeysResponse is the HTTP response for a Redis key audit.
+type RedisKeysResponse struct {
+	Prefix        string                   `json:"prefix"`
+	Scanned       int64                    `json:"scanned"`
+	Namespaces    []NamespaceUsageResponse `json:"namespaces"`
+	Unknown       int64                    `json:"unknown"`
+	UnknownSample []string                 `json:"unknown_sample"`
+}
+
+// GetKeys handles GET /v1/admin/redis/keys
+func (h *RedisKeysHandler) GetKeys(c *gin.Context) {
+	audit, err := h.auditor.Audit(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response := RedisKeysResponse{
+		Prefix:        audit.Prefix,
+		Scanned:       audit.Scanned,
+		Namespaces:    make([]NamespaceUsageResponse, 0, len(audit.Namespaces)),
+		Unknown:       audit.Unknown,
+		UnknownSample: append([]string{}, audit.UnknownSample...),
+	}
+	for _, ns := range audit.Namespaces {
+		response.Namespaces = append(response.Namespaces, NamespaceUsageResponse{
+			Namespace:  ns.Namespace,
+			Keys:       ns.Keys,
+			WithoutTTL: ns.WithoutTTL,
+			Cleanup:    ns.Cleanup,
+		})
+	}
+	respondJSON(c, http.StatusOK, response)
+}
@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/domain"
+	"ride/internal/service"
+)
+
+// ReportHandler handles HTTP requests for admin reports.
+type ReportHandler struct {
+	reportService *service.ReportService
+}
+
+// NewReportHandler creates a new ReportHandler.
+func NewReportHandler(reportService *service.ReportService) *ReportHandler {
+	return &ReportHandler{reportService: reportService}
+}
+
+// AmountSummaryResponse is a count and total amount.
+type AmountSummaryResponse struct {
+	Count  int     `json:"count"`
+	Amount float64 `json:"amount"`
+}
+
+// DiscrepancyResponse is a trip whose payments do not match its fare.
+type DiscrepancyResponse struct {
+	TripID          string  `json:"trip_id"`
+	Fare            float64 `json:"fare"`
+	AccountedAmount float64 `json:"accounted_amount"`
+}
+
+// DailyReportResponse is the HTTP response for the daily reconciliation report.
+type DailyReportResponse struct {
+	Date               string                `json:"date"`
+	TripsEnded         int                   `json:"trips_ended"`
+	GrossFares         float64               `json:"gross_fares"`
+	SuccessfulPayments AmountSummaryResponse `json:"successful_payments"`
+	FailedPayments     AmountSummaryResponse `json:"failed_payments"`
+	PendingPayments    AmountSummaryResponse `json:"pending_payments"`
+	CashAwaiting       AmountSummaryResponse `json:"cash_awaiting_collection"`
+	Refunds            AmountSummaryResponse `json:"refunds"`
+	AccountedAmount    float64               `json:"accounted_amount"`
+	Difference         float64               `json:"difference"`
+	Balanced           bool                  `json:"balanced"`
+	Discrepancies      []DiscrepancyResponse `json:"discrepancies"`
+}
+
+// EstimateAccuracyResponse is the HTTP response for the estimate accuracy report.
+type EstimateAccuracyResponse struct {
+	Date        string  `json:"date"`
+	Trips       int     `json:"trips"`
+	WithinRange int     `json:"within_range"`
+	BelowRange  int     `json:"below_range"`
+	AboveRange  int     `json:"above_range"`
+	ErrorP50    float64 `json:"error_pct_p50"`
+	ErrorP90    float64 `json:"error_pct_p90"`
+	ErrorP99    float64 `json:"error_pct_p99"`
+}
+
+// OutstandingCollectionResponse is a cash payment awaiting the driver's confirmation.
+type OutstandingCollectionResponse struct {
+	PaymentID string  `json:"payment_id"`
+	TripID    string  `json:"trip_id"`
+	Amount    float64 `json:"amount"`
+	EndedAt   string  `json:"ended_at"`
+}
+
+// CollectionsReportResponse is the HTTP response for a driver's outstanding cash collections.
+type CollectionsReportResponse struct {
+	DriverID    string                          `json:"driver_id"`
+	Total       AmountSummaryResponse           `json:"total"`
+	Collections []OutstandingCollectionResponse `json:"collections"`
+}
+
+// GetDailyReport handles GET /v1/admin/reports/daily?date=YYYY-MM-DD&format=csv
+func (h *ReportHandler) GetDailyReport(c *gin.Context) {
+	date, err := time.Parse("2006-01-02", c.Query("date"))
+	if err != nil {
+		respondError(c, service.ErrInvalidReportDate)
+		return
+	}
+
+	report, err := h.reportService.DailyReport(c.Request.Context(), date)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if c.Query("format") == "csv" {
+		writeDailyReportCSV(c, report)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, toDailyReportResponse(report))
+}
+
+// GetEstimateAccuracy handles GET /v1/admin/reports/estimate-accuracy?date=YYYY-MM-DD
+func (h *ReportHandler) GetEstimateAccuracy(c *gin.Context) {
+	date, err := time.Parse("2006-01-02", c.Query("date"))
+	if err != nil {
+		respondError(c, service.ErrInvalidReportDate)
+		return
+	}
+
+	accuracy, err := h.reportService.EstimateAccuracy(c.Request.Context(), date)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, EstimateAccuracyResponse{
+		Date:        accuracy.Date.Format("2006-01-02"),
+		Trips:       accuracy.Trips,
+		WithinRange: accuracy.WithinRange,
+		BelowRange:  accuracy.BelowRange,
+		AboveRange:  accuracy.AboveRange,
+		ErrorP50:    accuracy.ErrorP50,
+		ErrorP90:    accuracy.ErrorP90,
+		ErrorP99:    accuracy.ErrorP99,
+	})
+}
+
+// GetDriverCollections handles GET /v1/drivers/:id/collections
+// Only the driver or an admin may see what the driver owes; 404 for anyone
+// else.
+func (h *ReportHandler) GetDriverCollections(c *gin.Context) {
+	driverID := c.Param("id")
+	if err := authorizeRead(c, func() (string, string, error) { return "", driverID, nil }); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	report, err := h.reportService.OutstandingCollections(c.Request.Context(), driverID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	collections := make([]OutstandingCollectionResponse, 0, len(report.Collections))
+	for _, col := range report.Collections {
+		collections = append(collections, OutstandingCollectionResponse{
+			PaymentID: col.PaymentID,
+			TripID:    col.TripID,
+			Amount:    col.Amount,
+			EndedAt:   col.EndedAt.Format(time.RFC3339),
+		})
+	}
+
+	respondJSON(c, http.StatusOK, CollectionsReportResponse{
+		DriverID:    report.DriverID,
+		Total:       AmountSummaryResponse{Count: report.Total.Count, Amount: report.Total.Amount},
+		Collections: collections,
+	})
+}
+
+func toDailyReportResponse(report *domain.DailyReport) DailyReportResponse {
+	summary := func(s domain.AmountSummary) AmountSummaryResponse {
+		return AmountSummaryResponse{Count: s.Count, Amount: s.Amount}
+	}
+
+	discrepancies := make([]DiscrepancyResponse, 0, len(report.Discrepancies))
+	for _, d := range report.Discrepancies {
+		discrepancies = append(discrepancies, DiscrepancyResponse{
+			TripID:          d.TripID,
+			Fare:            d.Fare,
+			AccountedAmount: d.AccountedAmount,
+		})
+	}
+
+	return DailyReportResponse{
+		Date:               report.Date.Format("2006-01-02"),
+		TripsEnded:         report.Totals.TripsEnded,
+		GrossFares:         report.Totals.GrossFares,
+		SuccessfulPayments: summary(report.Totals.SuccessfulPayments),
+		FailedPayments:     summary(report.Totals.FailedPayments),
+		PendingPayments:    summary(report.Totals.PendingPayments),
+		CashAwaiting:       summary(report.Totals.CashAwaiting),
+		Refunds:            summary(report.Totals.Refunds),
+		AccountedAmount:    report.AccountedAmount,
+		Difference:         report.Difference,
+		Balanced:           report.Balanced,
+		Discrepancies:      discrepancies,
+	}
+}
+
+// writeDailyReportCSV writes the summary rows followed by one row per discrepancy.
+func writeDailyReportCSV(c *gin.Context, report *domain.DailyReport) {
+	date := report.Date.Format("2006-01-02")
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="daily-report-`+date+`.csv"`)
+	c.Status(http.StatusOK)
+
+	money := func(f float64) string { return strconv.FormatFloat(f, 'f', 2, 64) }
+	count := strconv.Itoa
+
+	w := csv.NewWriter(c.Writer)
+	_ = w.WriteAll([][]string{
+		{"metric", "count", "amount"},
+		{"trips_ended", count(report.Totals.TripsEnded), money(report.Totals.GrossFares)},
+		{"successful_payments", count(report.Totals.SuccessfulPayments.Count), money(report.Totals.SuccessfulPayments.Amount)},
+		{"failed_payments", count(report.Totals.FailedPayments.Count), money(report.Totals.FailedPayments.Amount)},
+		{"pending_payments", count(report.Totals.PendingPayments.Count), money(report.Totals.PendingPayments.Amount)},
+		{"cash_awaiting_collection", count(report.Totals.CashAwaiting.Count), money(report.Totals.CashAwaiting.Amount)},
+		{"refunds", count(report.Totals.Refunds.Count), money(report.Totals.Refunds.Amount)},
+		{"difference", "", money(report.Difference)},
+		{"balanced", strconv.FormatBool(report.Balanced), ""},
+	})
+
+	_ = w.Write([]string{})
+	_ = w.Write([]string{"discrepancy_trip_id", "fare", "accounted_amount"})
+	for _, d := range report.Discrepancies {
+		_ = w.Write([]string{d.TripID, money(d.Fare), money(d.AccountedAmount)})
+	}
+	w.Flush()
+}
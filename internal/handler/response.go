@@ -1,71 +1,213 @@
 package handler
 
 import (
+	"context"
+	"database/sql/driver"
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"ride/internal/faults"
+	"ride/internal/redis"
 	"ride/internal/repository"
 	"ride/internal/service"
 )
 
-// ErrorResponse represents an error response.
-type ErrorResponse struct {
-	Error string `json:"error"`
+// ListResponse is the envelope for paginated list endpoints.
+type ListResponse struct {
+	Data   any `json:"data"`
+	Total  int `json:"total"`
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
 }
 
 // respondError sends an error response with the appropriate HTTP status code.
+// A service.ValidationError is sent as a 422 listing every field problem.
 func respondError(c *gin.Context, err error) {
+	var validationErr *service.ValidationError
+	if errors.As(err, &validationErr) {
+		response := ValidationErrorResponse{Error: "invalid request", Fields: make([]FieldError, 0, len(validationErr.Fields))}
+		for _, f := range validationErr.Fields {
+			response.Fields = append(response.Fields, FieldError{Field: f.Field, Error: f.Err.Error()})
+		}
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
 	code := mapErrorToHTTPStatus(err)
 	c.JSON(code, ErrorResponse{Error: err.Error()})
 }
 
+// formatTimestamp formats t as RFC 3339, or "" when t is zero.
+func formatTimestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
 // respondJSON sends a JSON response with the given status code.
 func respondJSON(c *gin.Context, code int, data any) {
 	c.JSON(code, data)
 }
 
+// HTTPStatus returns the HTTP status code err is reported with. The gRPC
+// server derives its status codes from it, so both APIs classify errors alike.
+func HTTPStatus(err error) int {
+	return mapErrorToHTTPStatus(err)
+}
+
 // mapErrorToHTTPStatus maps service/repository errors to HTTP status codes.
 func mapErrorToHTTPStatus(err error) int {
+	var validationErr *service.ValidationError
 	switch {
+	// Request fields failed validation; see respondError
+	case errors.As(err, &validationErr):
+		return http.StatusUnprocessableEntity
+
 	// Not found errors
 	case errors.Is(err, repository.ErrNotFound):
 		return http.StatusNotFound
 
 	// Validation errors - Bad Request
-	case errors.Is(err, service.ErrInvalidRiderID),
+	case errors.Is(err, repository.ErrForeignKeyViolation),
+		errors.Is(err, service.ErrInvalidRiderID),
 		errors.Is(err, service.ErrInvalidRideID),
 		errors.Is(err, service.ErrInvalidDriverID),
 		errors.Is(err, service.ErrInvalidTripID),
 		errors.Is(err, service.ErrInvalidPickupLocation),
 		errors.Is(err, service.ErrInvalidDestinationLocation),
 		errors.Is(err, service.ErrInvalidLocation),
+		errors.Is(err, service.ErrInvalidHeading),
+		errors.Is(err, service.ErrInvalidRecordedAt),
+		errors.Is(err, service.ErrInvalidFare),
+		errors.Is(err, service.ErrInvalidPauseReason),
+		errors.Is(err, service.ErrInvalidAbortParty),
+		errors.Is(err, service.ErrAbortReasonRequired),
+		errors.Is(err, service.ErrAdjustmentReasonRequired),
+		errors.Is(err, service.ErrDisputeReasonRequired),
+		errors.Is(err, service.ErrInvalidDisputeOutcome),
 		errors.Is(err, service.ErrInvalidPaymentAmount),
 		errors.Is(err, service.ErrInvalidPaymentID),
-		errors.Is(err, service.ErrInvalidPaymentMethod):
+		errors.Is(err, service.ErrInvalidIdempotencyKey),
+		errors.Is(err, service.ErrInvalidFareSplit),
+		errors.Is(err, service.ErrInvalidPaymentMethod),
+		errors.Is(err, service.ErrInvalidTier),
+		errors.Is(err, service.ErrInvalidRideType),
+		errors.Is(err, service.ErrInvalidCapability),
+		errors.Is(err, service.ErrInvalidAttachment),
+		errors.Is(err, service.ErrInvalidReportDate),
+		errors.Is(err, service.ErrInvalidWeek),
+		errors.Is(err, service.ErrInvalidTrackWindow),
+		errors.Is(err, service.ErrInvalidBoundingBox),
+		errors.Is(err, service.ErrInvalidExportRange),
+		errors.Is(err, service.ErrUnsupportedExportFormat),
+		errors.Is(err, service.ErrExportTooLarge),
+		errors.Is(err, service.ErrFieldRequired),
+		errors.Is(err, service.ErrEmptyImport),
+		errors.Is(err, service.ErrImportTooLarge),
+		errors.Is(err, service.ErrInvalidCampaignName),
+		errors.Is(err, service.ErrInvalidCampaignCriteria),
+		errors.Is(err, service.ErrInvalidCampaignTarget),
+		errors.Is(err, service.ErrInvalidCampaignReward),
+		errors.Is(err, service.ErrInvalidCampaignTier),
+		errors.Is(err, service.ErrInvalidCampaignWindow),
+		errors.Is(err, service.ErrInvalidFeatureFlagName),
+		errors.Is(err, service.ErrInvalidFeatureFlagPercentage),
+		errors.Is(err, service.ErrInvalidNotificationPreference),
+		errors.Is(err, service.ErrInvalidEmail),
+		errors.Is(err, service.ErrInvalidPhone),
+		errors.Is(err, service.ErrInvalidPaymentInstrument),
+		errors.Is(err, service.ErrInvalidVerificationToken),
+		errors.Is(err, faults.ErrInvalidFault):
 		return http.StatusBadRequest
 
 	// Conflict errors
-	case errors.Is(err, service.ErrDriverHasActiveTrip),
+	case errors.Is(err, repository.ErrVersionConflict),
+		errors.Is(err, repository.ErrDuplicate),
+		errors.Is(err, service.ErrDriverHasActiveTrip),
+		errors.Is(err, service.ErrDriverNotOnline),
+		errors.Is(err, service.ErrDriverNotOnBreak),
+		errors.Is(err, service.ErrDriverNotEligible),
 		errors.Is(err, service.ErrTripAlreadyEnded),
 		errors.Is(err, service.ErrTripNotStarted),
 		errors.Is(err, service.ErrTripNotPaused),
+		errors.Is(err, service.ErrTripNotUnderReview),
+		errors.Is(err, service.ErrTripUnderReview),
+		errors.Is(err, service.ErrTripNotAwaitingConfirmation),
+		errors.Is(err, service.ErrTripNotDisputed),
+		errors.Is(err, service.ErrTripPaymentOnHold),
+		errors.Is(err, service.ErrFareUnchanged),
+		errors.Is(err, service.ErrDriverTierUnchanged),
+		errors.Is(err, service.ErrPaymentNotCashDue),
 		errors.Is(err, service.ErrRideNotInRequestedState),
 		errors.Is(err, service.ErrRideAlreadyCancelled),
 		errors.Is(err, service.ErrRideCannotBeCancelled),
-		errors.Is(err, service.ErrTripInProgress):
+		errors.Is(err, service.ErrRideStillActive),
+		errors.Is(err, service.ErrTripInProgress),
+		errors.Is(err, service.ErrPickupETANotApplicable),
+		errors.Is(err, service.ErrEmailTaken),
+		errors.Is(err, service.ErrEmailAlreadyVerified),
+		errors.Is(err, service.ErrDriverAlreadyRegistered),
+		errors.Is(err, service.ErrProofOfDeliveryRequired),
+		errors.Is(err, service.ErrAttachmentNotAllowed),
+		errors.Is(err, service.ErrFareSplitNotAllowed),
+		errors.Is(err, service.ErrTripNotEnded):
 		return http.StatusConflict
 
 	// Forbidden/Business rule errors
 	case errors.Is(err, service.ErrRideNotAssigned),
-		errors.Is(err, service.ErrDriverNotAssignedToRide):
+		errors.Is(err, service.ErrDriverNotAssignedToRide),
+		errors.Is(err, service.ErrNotTheDriver),
+		errors.Is(err, service.ErrDriverTooFarFromPickup),
+		errors.Is(err, service.ErrInvalidAttachmentURL):
 		return http.StatusForbidden
 
+	// Uploads the attachment endpoint refuses
+	case errors.Is(err, service.ErrAttachmentTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, service.ErrUnsupportedAttachmentType):
+		return http.StatusUnsupportedMediaType
+
+	// Unprocessable: well-formed, but the rider cannot pay this way, or
+	// the driver cannot have got there
+	case errors.Is(err, service.ErrNoPaymentInstrument),
+		errors.Is(err, service.ErrImplausibleSpeed):
+		return http.StatusUnprocessableEntity
+
+	// Payment required: the card would not cover the trip
+	case errors.Is(err, service.ErrPaymentAuthorizationDeclined):
+		return http.StatusPaymentRequired
+
+	// Rate limited
+	case errors.Is(err, service.ErrEmailResendTooSoon):
+		return http.StatusTooManyRequests
+
 	// Service unavailable
-	case errors.Is(err, service.ErrNoDriverAvailable):
+	case errors.Is(err, service.ErrNoDriverAvailable),
+		errors.Is(err, service.ErrMatchingCandidatesExhausted),
+		errors.Is(err, service.ErrDriverLocationUnavailable),
+		errors.Is(err, service.ErrNotificationStreamUnavailable),
+		errors.Is(err, service.ErrNotificationPreferencesUnavailable),
+		errors.Is(err, service.ErrDestinationModeUnavailable),
+		errors.Is(err, service.ErrFareAdjustmentUnavailable),
+		errors.Is(err, service.ErrTrackerKeysUnavailable),
+		errors.Is(err, service.ErrPSPUnavailable),
+		errors.Is(err, service.ErrPSPTransient):
 		return http.StatusServiceUnavailable
 
+	// Service unavailable: Redis or Postgres could not be reached
+	case redis.IsUnavailable(err),
+		errors.Is(err, driver.ErrBadConn):
+		return http.StatusServiceUnavailable
+
+	// Gateway timeout: the request ran past its deadline, or the client left
+	case errors.Is(err, context.DeadlineExceeded),
+		errors.Is(err, context.Canceled):
+		return http.StatusGatewayTimeout
+
 	// Default to internal server error
 	default:
 		return http.StatusInternalServerError
@@ -1,11 +1,13 @@
 package handler
 
 import (
+	"math"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 
 	"ride/internal/domain"
+	"ride/internal/middleware"
 	"ride/internal/repository"
 	"ride/internal/service"
 )
@@ -13,26 +15,40 @@ import (
 // RideHandler handles HTTP requests for rides.
 type RideHandler struct {
 	rideService *service.RideService
+	etaService  *service.ETAService
 	rideRepo    repository.RideRepository
+	catalog     *domain.Catalog // Payment methods and tiers a ride may ask for
 }
 
-// NewRideHandler creates a new RideHandler.
-func NewRideHandler(rideService *service.RideService, rideRepo repository.RideRepository) *RideHandler {
+// NewRideHandler creates a new RideHandler. A nil catalog means the default catalog.
+func NewRideHandler(rideService *service.RideService, etaService *service.ETAService, rideRepo repository.RideRepository, catalog *domain.Catalog) *RideHandler {
+	if catalog == nil {
+		catalog = domain.DefaultCatalog()
+	}
 	return &RideHandler{
 		rideService: rideService,
+		etaService:  etaService,
 		rideRepo:    rideRepo,
+		catalog:     catalog,
 	}
 }
 
-// CreateRideRequest is the HTTP request body for creating a ride.
-type CreateRideRequest struct {
-	RiderID        string  `json:"rider_id"`
+// EstimateRideRequest is the HTTP request body for estimating a ride.
+type EstimateRideRequest struct {
 	PickupLat      float64 `json:"pickup_lat"`
 	PickupLng      float64 `json:"pickup_lng"`
 	DestinationLat float64 `json:"destination_lat"`
 	DestinationLng float64 `json:"destination_lng"`
-	Tier           string  `json:"tier,omitempty"`
-	PaymentMethod  string  `json:"payment_method,omitempty"` // CASH, CARD, WALLET, UPI
+}
+
+// EstimateRideResponse is the HTTP response for a ride estimate.
+type EstimateRideResponse struct {
+	QuoteID         string  `json:"quote_id,omitempty"`
+	SurgeMultiplier float64 `json:"surge_multiplier"`
+	SurgeActive     bool    `json:"surge_active"`
+	SurchargeLabel  string  `json:"surcharge_label,omitempty"`
+	SurchargeAmount float64 `json:"surcharge_amount,omitempty"`
+	ExpiresAt       string  `json:"expires_at,omitempty"`
 }
 
 // CancelRideRequest is the HTTP request body for cancelling a ride.
@@ -41,37 +57,90 @@ type CancelRideRequest struct {
 	Reason      string `json:"reason,omitempty"`
 }
 
-// CreateRideResponse is the HTTP response for creating a ride.
-type CreateRideResponse struct {
-	ID               string  `json:"id"`
-	RiderID          string  `json:"rider_id"`
-	PickupLat        float64 `json:"pickup_lat"`
-	PickupLng        float64 `json:"pickup_lng"`
-	DestinationLat   float64 `json:"destination_lat"`
-	DestinationLng   float64 `json:"destination_lng"`
-	Status           string  `json:"status"`
-	AssignedDriverID string  `json:"assigned_driver_id,omitempty"`
-	DriverAssigned   bool    `json:"driver_assigned"`
-	SurgeMultiplier  float64 `json:"surge_multiplier"`
-	SurgeActive      bool    `json:"surge_active"`
-	PaymentMethod    string  `json:"payment_method"`
-}
-
-// GetRideResponse is the HTTP response for getting a ride.
-type GetRideResponse struct {
-	ID               string  `json:"id"`
-	RiderID          string  `json:"rider_id"`
-	PickupLat        float64 `json:"pickup_lat"`
-	PickupLng        float64 `json:"pickup_lng"`
-	DestinationLat   float64 `json:"destination_lat"`
-	DestinationLng   float64 `json:"destination_lng"`
-	Status           string  `json:"status"`
-	AssignedDriverID string  `json:"assigned_driver_id,omitempty"`
-	SurgeMultiplier  float64 `json:"surge_multiplier"`
-	SurgeActive      bool    `json:"surge_active"`
-	PaymentMethod    string  `json:"payment_method"`
-	CancelledAt      string  `json:"cancelled_at,omitempty"`
-	CancelReason     string  `json:"cancel_reason,omitempty"`
+// assignedDriver builds the driver details for an ASSIGNED ride, or nil when
+// there is no assigned driver to show.
+func (h *RideHandler) assignedDriver(c *gin.Context, ride *domain.Ride) *AssignedDriverResponse {
+	driver := h.rideService.AssignedDriver(c.Request.Context(), ride)
+	if driver == nil {
+		return nil
+	}
+
+	response := &AssignedDriverResponse{
+		ID:           driver.ID,
+		Name:         driver.Name,
+		Tier:         string(driver.Tier),
+		VehiclePlate: driver.VehiclePlate,
+	}
+	if h.etaService != nil {
+		if eta, err := h.etaService.DriverETA(c.Request.Context(), ride.ID, false); err == nil {
+			response.ETASeconds = eta.ETASeconds
+			response.ETAMinutes = eta.ETAMinutes()
+		}
+	}
+	return response
+}
+
+// driverDistance returns the live distance of the ride's driver, or nil when
+// no driver is assigned or their location is unknown.
+func (h *RideHandler) driverDistance(c *gin.Context, ride *domain.Ride) *float64 {
+	if h.etaService == nil || ride.AssignedDriverID == "" {
+		return nil
+	}
+	distanceKm, err := h.etaService.DriverDistanceKm(c.Request.Context(), ride)
+	if err != nil {
+		return nil
+	}
+	return &distanceKm
+}
+
+// newGetRideResponse maps a ride to its response shape. Lifecycle timestamps
+// are present once their transition has happened; cancellation fields only
+// on cancelled rides.
+func newGetRideResponse(ride *domain.Ride) GetRideResponse {
+	response := GetRideResponse{
+		ID:                ride.ID,
+		RiderID:           ride.RiderID,
+		PickupLat:         ride.PickupLat,
+		PickupLng:         ride.PickupLng,
+		DestinationLat:    ride.DestinationLat,
+		DestinationLng:    ride.DestinationLng,
+		Status:            string(ride.Status),
+		AssignedDriverID:  ride.AssignedDriverID,
+		SurgeMultiplier:   ride.SurgeMultiplier,
+		SurgeActive:       ride.SurgeMultiplier > 1.0,
+		SurchargeLabel:    ride.SurchargeLabel,
+		SurchargeAmount:   ride.SurchargeAmount,
+		EstimatedFareLow:  ride.EstimateLow,
+		EstimatedFareHigh: ride.EstimateHigh,
+		PaymentMethod:     string(ride.PaymentMethod),
+		RideType:          string(ride.Type),
+		Capabilities:      ride.Capabilities,
+		Priority:          ride.Priority,
+		QuoteID:           ride.QuoteID,
+		RebookedFrom:      ride.RebookedFrom,
+		CreatedAt:         formatTimestamp(ride.CreatedAt),
+		UpdatedAt:         formatTimestamp(ride.UpdatedAt),
+	}
+
+	if !ride.RequestedAt.IsZero() {
+		response.RequestedAt = ride.RequestedAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+	if !ride.AssignedAt.IsZero() {
+		response.AssignedAt = ride.AssignedAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+	if !ride.ArrivedAt.IsZero() {
+		response.ArrivedAt = ride.ArrivedAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+	if !ride.CompletedAt.IsZero() {
+		response.CompletedAt = ride.CompletedAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+
+	if !ride.CancelledAt.IsZero() {
+		response.CancelledAt = ride.CancelledAt.Format("2006-01-02T15:04:05Z07:00")
+		response.CancelReason = ride.CancelReason
+	}
+
+	return response
 }
 
 // CreateRide handles POST /v1/rides
@@ -82,41 +151,144 @@ func (h *RideHandler) CreateRide(c *gin.Context) {
 		return
 	}
 
-	// Validate payment method
-	paymentMethod, err := service.ValidatePaymentMethod(req.PaymentMethod)
+	createReq, err := NewCreateRideRequest(req, h.catalog)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		respondError(c, err)
+		return
+	}
+
+	result, err := h.rideService.CreateRide(c.Request.Context(), createReq)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusCreated, h.newCreateRideResponse(c, result))
+}
+
+// NewCreateRideRequest validates a create ride body against the catalog and
+// converts it for the ride service. Every field is checked before failing,
+// so the client sees all problems at once, as a service.ValidationError.
+// The gRPC server shares it, so both APIs accept the same rides.
+func NewCreateRideRequest(req CreateRideRequest, catalog *domain.Catalog) (service.CreateRideRequest, error) {
+	var problems service.ValidationError
+	if req.RiderID == "" {
+		problems.Add("rider_id", service.ErrFieldRequired)
+	}
+	if !domain.IsValidLatitude(req.PickupLat) {
+		problems.Add("pickup_lat", service.ErrInvalidPickupLocation)
+	}
+	if !domain.IsValidLongitude(req.PickupLng) {
+		problems.Add("pickup_lng", service.ErrInvalidPickupLocation)
+	}
+	if !domain.IsValidLatitude(req.DestinationLat) {
+		problems.Add("destination_lat", service.ErrInvalidDestinationLocation)
+	}
+	if !domain.IsValidLongitude(req.DestinationLng) {
+		problems.Add("destination_lng", service.ErrInvalidDestinationLocation)
+	}
+	paymentMethod, err := service.ValidatePaymentMethod(req.PaymentMethod, catalog)
+	problems.Add("payment_method", err)
+	tier, err := service.ValidateTier(req.Tier, catalog)
+	problems.Add("tier", err)
+	rideType, err := service.ValidateRideType(req.RideType)
+	problems.Add("ride_type", err)
+	capabilities, err := service.ValidateCapabilities(req.RequiredCapabilities)
+	problems.Add("required_capabilities", err)
+	if err := problems.Err(); err != nil {
+		return service.CreateRideRequest{}, err
+	}
+
+	return service.CreateRideRequest{
+		RiderID:          req.RiderID,
+		PickupLat:        req.PickupLat,
+		PickupLng:        req.PickupLng,
+		DestinationLat:   req.DestinationLat,
+		DestinationLng:   req.DestinationLng,
+		Tier:             tier,
+		PaymentMethod:    paymentMethod,
+		Type:             rideType,
+		Capabilities:     capabilities,
+		QuoteID:          req.QuoteID,
+		InstrumentID:     req.PaymentInstrumentID,
+		ExcludeDriverIDs: req.ExcludeDriverIDs,
+	}, nil
+}
+
+// RebookRide handles POST /v1/rides/:id/rebook
+// Requests a new ride like a completed or cancelled one of the caller's
+// (X-User-ID), at the current surge. Responds like CreateRide; 409 if the
+// ride is still active, 404 if it is not the caller's.
+func (h *RideHandler) RebookRide(c *gin.Context) {
+	result, err := h.rideService.Rebook(c.Request.Context(), c.Param("id"), middleware.CallerFrom(c).UserID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusCreated, h.newCreateRideResponse(c, result))
+}
+
+// newCreateRideResponse builds the HTTP response for a newly requested ride.
+func (h *RideHandler) newCreateRideResponse(c *gin.Context, result *service.CreateRideResponse) CreateRideResponse {
+	return CreateRideResponse{
+		ID:                result.Ride.ID,
+		RiderID:           result.Ride.RiderID,
+		PickupLat:         result.Ride.PickupLat,
+		PickupLng:         result.Ride.PickupLng,
+		DestinationLat:    result.Ride.DestinationLat,
+		DestinationLng:    result.Ride.DestinationLng,
+		Status:            string(result.Ride.Status),
+		AssignedDriverID:  result.DriverID,
+		DriverAssigned:    result.DriverAssigned,
+		SurgeMultiplier:   result.SurgeMultiplier,
+		SurgeActive:       result.SurgeMultiplier > 1.0,
+		SurchargeLabel:    result.Ride.SurchargeLabel,
+		SurchargeAmount:   result.Ride.SurchargeAmount,
+		EstimatedFareLow:  result.Ride.EstimateLow,
+		EstimatedFareHigh: result.Ride.EstimateHigh,
+		PaymentMethod:     string(result.Ride.PaymentMethod),
+		RideType:          string(result.Ride.Type),
+		Capabilities:      result.Ride.Capabilities,
+		Priority:          result.Ride.Priority,
+		QuoteID:           result.Ride.QuoteID,
+		QuoteRejected:     result.QuoteRejected,
+		RebookedFrom:      result.Ride.RebookedFrom,
+		Driver:            h.assignedDriver(c, result.Ride),
+	}
+}
+
+// EstimateRide handles POST /v1/rides/estimate
+func (h *RideHandler) EstimateRide(c *gin.Context) {
+	var req EstimateRideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
 		return
 	}
 
-	result, err := h.rideService.CreateRide(c.Request.Context(), service.CreateRideRequest{
-		RiderID:        req.RiderID,
+	estimate, err := h.rideService.EstimateRide(c.Request.Context(), service.EstimateRideRequest{
 		PickupLat:      req.PickupLat,
 		PickupLng:      req.PickupLng,
 		DestinationLat: req.DestinationLat,
 		DestinationLng: req.DestinationLng,
-		Tier:           domain.DriverTier(req.Tier),
-		PaymentMethod:  paymentMethod,
 	})
 	if err != nil {
 		respondError(c, err)
 		return
 	}
 
-	respondJSON(c, http.StatusCreated, CreateRideResponse{
-		ID:               result.Ride.ID,
-		RiderID:          result.Ride.RiderID,
-		PickupLat:        result.Ride.PickupLat,
-		PickupLng:        result.Ride.PickupLng,
-		DestinationLat:   result.Ride.DestinationLat,
-		DestinationLng:   result.Ride.DestinationLng,
-		Status:           string(result.Ride.Status),
-		AssignedDriverID: result.DriverID,
-		DriverAssigned:   result.DriverAssigned,
-		SurgeMultiplier:  result.SurgeMultiplier,
-		SurgeActive:      result.SurgeMultiplier > 1.0,
-		PaymentMethod:    string(result.Ride.PaymentMethod),
-	})
+	response := EstimateRideResponse{
+		QuoteID:         estimate.QuoteID,
+		SurgeMultiplier: estimate.SurgeMultiplier,
+		SurgeActive:     estimate.SurgeMultiplier > 1.0,
+		SurchargeLabel:  estimate.SurchargeLabel,
+		SurchargeAmount: estimate.SurchargeAmount,
+	}
+	if !estimate.ExpiresAt.IsZero() {
+		response.ExpiresAt = estimate.ExpiresAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+
+	respondJSON(c, http.StatusOK, response)
 }
 
 // GetRide handles GET /v1/rides/:id
@@ -129,28 +301,71 @@ func (h *RideHandler) GetRide(c *gin.Context) {
 		return
 	}
 
-	response := GetRideResponse{
-		ID:               ride.ID,
-		RiderID:          ride.RiderID,
-		PickupLat:        ride.PickupLat,
-		PickupLng:        ride.PickupLng,
-		DestinationLat:   ride.DestinationLat,
-		DestinationLng:   ride.DestinationLng,
-		Status:           string(ride.Status),
-		AssignedDriverID: ride.AssignedDriverID,
-		SurgeMultiplier:  ride.SurgeMultiplier,
-		SurgeActive:      ride.SurgeMultiplier > 1.0,
-		PaymentMethod:    string(ride.PaymentMethod),
+	if err := authorizeRead(c, func() (string, string, error) {
+		return ride.RiderID, ride.AssignedDriverID, nil
+	}); err != nil {
+		respondError(c, err)
+		return
 	}
 
-	if !ride.CancelledAt.IsZero() {
-		response.CancelledAt = ride.CancelledAt.Format("2006-01-02T15:04:05Z07:00")
-		response.CancelReason = ride.CancelReason
+	response := newGetRideResponse(ride)
+	response.Driver = h.assignedDriver(c, ride)
+	response.DriverDistanceKm = h.driverDistance(c, ride)
+	if queue := h.rideService.QueuePosition(c.Request.Context(), ride); queue != nil {
+		response.QueuePosition = &queue.Position
+		if queue.EstimatedWait > 0 {
+			seconds := int(math.Ceil(queue.EstimatedWait.Seconds()))
+			response.EstimatedWaitSeconds = &seconds
+		}
 	}
-
 	respondJSON(c, http.StatusOK, response)
 }
 
+// DriverETAResponse is the HTTP response for the assigned driver's pickup ETA.
+type DriverETAResponse struct {
+	RideID     string  `json:"ride_id"`
+	DriverID   string  `json:"driver_id"`
+	DriverLat  float64 `json:"driver_lat"`
+	DriverLng  float64 `json:"driver_lng"`
+	DistanceKm float64 `json:"distance_km"`
+	ETASeconds int     `json:"eta_seconds"`
+	ETAMinutes int     `json:"eta_minutes"`
+}
+
+// GetDriverETA handles GET /v1/rides/:id/driver-eta
+// Recomputes the ETA from the driver's latest location on every call.
+// Pass ?notify=true to also push the update to the rider. Visible, like the
+// ride, to its rider and driver.
+func (h *RideHandler) GetDriverETA(c *gin.Context) {
+	rideID := c.Param("id")
+	if err := authorizeRead(c, func() (string, string, error) {
+		ride, err := h.rideService.GetRideStatus(c.Request.Context(), rideID)
+		if err != nil {
+			return "", "", err
+		}
+		return ride.RiderID, ride.AssignedDriverID, nil
+	}); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	eta, err := h.etaService.DriverETA(c.Request.Context(), rideID, c.Query("notify") == "true")
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, DriverETAResponse{
+		RideID:     eta.RideID,
+		DriverID:   eta.DriverID,
+		DriverLat:  eta.DriverLat,
+		DriverLng:  eta.DriverLng,
+		DistanceKm: eta.DistanceKm,
+		ETASeconds: eta.ETASeconds,
+		ETAMinutes: eta.ETAMinutes(),
+	})
+}
+
 // CancelRide handles POST /v1/rides/:id/cancel
 func (h *RideHandler) CancelRide(c *gin.Context) {
 	rideID := c.Param("id")
@@ -183,6 +398,7 @@ func (h *RideHandler) CancelRide(c *gin.Context) {
 		SurgeMultiplier:  ride.SurgeMultiplier,
 		SurgeActive:      ride.SurgeMultiplier > 1.0,
 		PaymentMethod:    string(ride.PaymentMethod),
+		RideType:         string(ride.Type),
 		CancelledAt:      ride.CancelledAt.Format("2006-01-02T15:04:05Z07:00"),
 		CancelReason:     ride.CancelReason,
 	}
@@ -190,6 +406,43 @@ func (h *RideHandler) CancelRide(c *gin.Context) {
 	respondJSON(c, http.StatusOK, response)
 }
 
+// AssignRideRequest is the HTTP request body for assigning a driver by hand.
+type AssignRideRequest struct {
+	DriverID string `json:"driver_id" binding:"required"`
+}
+
+// AssignRideResponse is the HTTP response for a ride assigned by hand.
+type AssignRideResponse struct {
+	RideID           string `json:"ride_id"`
+	Status           string `json:"status"`
+	AssignedDriverID string `json:"assigned_driver_id"`
+	AssignedBy       string `json:"assigned_by"`
+	AssignedAt       string `json:"assigned_at"`
+}
+
+// AssignDriver handles POST /v1/admin/rides/:id/assign
+func (h *RideHandler) AssignDriver(c *gin.Context) {
+	var req AssignRideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	result, err := h.rideService.AssignDriver(c.Request.Context(), c.Param("id"), req.DriverID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, AssignRideResponse{
+		RideID:           result.Ride.ID,
+		Status:           string(result.Ride.Status),
+		AssignedDriverID: result.DriverID,
+		AssignedBy:       string(result.Ride.AssignedBy),
+		AssignedAt:       formatTimestamp(result.Ride.AssignedAt),
+	})
+}
+
 // GetAll handles GET /v1/rides
 func (h *RideHandler) GetAll(c *gin.Context) {
 	rides, err := h.rideRepo.GetAll(c.Request.Context())
@@ -198,20 +451,9 @@ func (h *RideHandler) GetAll(c *gin.Context) {
 		return
 	}
 
-	var response []GetRideResponse
+	response := make([]GetRideResponse, 0, len(rides))
 	for _, r := range rides {
-		response = append(response, GetRideResponse{
-			ID:               r.ID,
-			RiderID:          r.RiderID,
-			PickupLat:        r.PickupLat,
-			PickupLng:        r.PickupLng,
-			DestinationLat:   r.DestinationLat,
-			DestinationLng:   r.DestinationLng,
-			Status:           string(r.Status),
-			AssignedDriverID: r.AssignedDriverID,
-			SurgeMultiplier:  r.SurgeMultiplier,
-			SurgeActive:      r.SurgeMultiplier > 1.0,
-		})
+		response = append(response, newGetRideResponse(r))
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/service"
+)
+
+// SpeedGuardHandler handles admin HTTP requests for drivers whose location
+// updates were rejected for implausible speed.
+type SpeedGuardHandler struct {
+	guardService *service.LocationGuardService
+}
+
+// NewSpeedGuardHandler creates a new SpeedGuardHandler.
+func NewSpeedGuardHandler(guardService *service.LocationGuardService) *SpeedGuardHandler {
+	return &SpeedGuardHandler{guardService: guardService}
+}
+
+// SpeedAnomalyResponse is a driver's count of location updates rejected
+// for implausible speed.
+type SpeedAnomalyResponse struct {
+	DriverID      string `json:"driver_id"`
+	Rejected      int64  `json:"rejected"`
+	HeldForReview bool   `json:"held_for_review"`
+}
+
+// ClearSpeedReviewResponse is the HTTP response for clearing a driver's
+// speed review.
+type ClearSpeedReviewResponse struct {
+	DriverID     string `json:"driver_id"`
+	HadAnomalies bool   `json:"had_anomalies"`
+}
+
+// GetAnomalies handles GET /v1/admin/reports/speed-anomalies
+func (h *SpeedGuardHandler) GetAnomalies(c *gin.Context) {
+	summaries, err := h.guardService.SpeedAnomalies(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response := make([]SpeedAnomalyResponse, 0, len(summaries))
+	for _, s := range summaries {
+		response = append(response, SpeedAnomalyResponse{
+			DriverID:      s.DriverID,
+			Rejected:      s.Rejected,
+			HeldForReview: s.HeldForReview,
+		})
+	}
+	respondJSON(c, http.StatusOK, response)
+}
+
+// ClearReview handles POST /v1/admin/drivers/:id/clear-speed-review
+func (h *SpeedGuardHandler) ClearReview(c *gin.Context) {
+	driverID := c.Param("id")
+
+	hadAnomalies, err := h.guardService.ClearReview(c.Request.Context(), driverID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, ClearSpeedReviewResponse{DriverID: driverID, HadAnomalies: hadAnomalies})
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/domain"
+	"ride/internal/service"
+)
+
+// maxLeaderboardLimit caps the limit query parameter of the leaderboard.
+const maxLeaderboardLimit = 500
+
+// DriverSummaryHandler handles HTTP requests for drivers' weekly summaries.
+type DriverSummaryHandler struct {
+	summaryService *service.DriverSummaryService
+}
+
+// NewDriverSummaryHandler creates a new DriverSummaryHandler.
+func NewDriverSummaryHandler(summaryService *service.DriverSummaryService) *DriverSummaryHandler {
+	return &DriverSummaryHandler{summaryService: summaryService}
+}
+
+// DriverSummaryResponse is a driver's activity over a week.
+type DriverSummaryResponse struct {
+	DriverID      string  `json:"driver_id"`
+	WeekStart     string  `json:"week_start"` // Monday, YYYY-MM-DD
+	City          string  `json:"city,omitempty"`
+	Trips         int     `json:"trips"`
+	Earnings      float64 `json:"earnings"`
+	OnlineHours   float64 `json:"online_hours"`
+	Rank          int     `json:"rank"`
+	RankedDrivers int     `json:"ranked_drivers"`
+	GeneratedAt   string  `json:"generated_at"`
+}
+
+// LeaderboardResponse is the HTTP response for a week's leaderboard.
+type LeaderboardResponse struct {
+	WeekStart string                  `json:"week_start"`
+	City      string                  `json:"city,omitempty"`
+	Drivers   []DriverSummaryResponse `json:"drivers"`
+}
+
+func newDriverSummaryResponse(summary *domain.DriverWeeklySummary) DriverSummaryResponse {
+	return DriverSummaryResponse{
+		DriverID:      summary.DriverID,
+		WeekStart:     summary.WeekStart.Format("2006-01-02"),
+		City:          summary.City,
+		Trips:         summary.Trips,
+		Earnings:      summary.Earnings,
+		OnlineHours:   summary.OnlineHours,
+		Rank:          summary.Rank,
+		RankedDrivers: summary.RankedDrivers,
+		GeneratedAt:   formatTimestamp(summary.GeneratedAt),
+	}
+}
+
+// GetSummary handles GET /v1/drivers/:id/summary?week=YYYY-MM-DD
+// week is any day of the week; the last complete week when omitted. Only
+// the driver or an admin may read it, 404 for anyone else, and 404 until the
+// week's summaries have been generated.
+func (h *DriverSummaryHandler) GetSummary(c *gin.Context) {
+	driverID := c.Param("id")
+	if err := authorizeRead(c, func() (string, string, error) { return "", driverID, nil }); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	week, err := service.ParseWeek(c.Query("week"), time.Now())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	summary, err := h.summaryService.Summary(c.Request.Context(), driverID, week)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, newDriverSummaryResponse(summary))
+}
+
+// GetLeaderboard handles GET /v1/drivers/leaderboard?week=YYYY-MM-DD&city=&limit=
+// Drivers ranked on trips, then earnings, for a week and city (this
+// deployment's by default). Drivers tied on both share a rank.
+func (h *DriverSummaryHandler) GetLeaderboard(c *gin.Context) {
+	week, err := service.ParseWeek(c.Query("week"), time.Now())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	limit := 0
+	if v := c.Query("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxLeaderboardLimit {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "limit must be between 1 and 500"})
+			return
+		}
+	}
+
+	city := domain.NormalizeCity(c.Query("city"))
+	if city == "" {
+		city = h.summaryService.City()
+	}
+
+	summaries, err := h.summaryService.Leaderboard(c.Request.Context(), week, city, limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response := LeaderboardResponse{
+		WeekStart: week.Format("2006-01-02"),
+		City:      city,
+		Drivers:   make([]DriverSummaryResponse, 0, len(summaries)),
+	}
+	for _, summary := range summaries {
+		response.Drivers = append(response.Drivers, newDriverSummaryResponse(summary))
+	}
+	respondJSON(c, http.StatusOK, response)
+}
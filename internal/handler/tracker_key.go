@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/service"
+)
+
+// TrackerKeyHandler handles admin HTTP requests for drivers' tracker keys.
+type TrackerKeyHandler struct {
+	trackerKeys *service.TrackerKeyService
+}
+
+// NewTrackerKeyHandler creates a new TrackerKeyHandler.
+func NewTrackerKeyHandler(trackerKeys *service.TrackerKeyService) *TrackerKeyHandler {
+	return &TrackerKeyHandler{trackerKeys: trackerKeys}
+}
+
+// TrackerKeyResponse is the HTTP response for a rotated tracker key. The
+// secret is shown only once.
+type TrackerKeyResponse struct {
+	DriverID      string `json:"driver_id"`
+	TrackerSecret string `json:"tracker_secret"`
+}
+
+// Rotate handles POST /v1/admin/drivers/:id/tracker-key
+func (h *TrackerKeyHandler) Rotate(c *gin.Context) {
+	driverID := c.Param("id")
+
+	secret, err := h.trackerKeys.Issue(c.Request.Context(), driverID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, TrackerKeyResponse{DriverID: driverID, TrackerSecret: secret})
+}
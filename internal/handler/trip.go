@@ -1,56 +1,125 @@
 package handler
 
 import (
+	"errors"
+	"io"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 
+	"ride/internal/domain"
+	"ride/internal/middleware"
 	"ride/internal/service"
 )
 
 // TripHandler handles HTTP requests for trips.
 type TripHandler struct {
 	tripService *service.TripService
+	etaService  *service.ETAService // Optional: nil leaves trips without progress
 }
 
 // NewTripHandler creates a new TripHandler.
-func NewTripHandler(tripService *service.TripService) *TripHandler {
-	return &TripHandler{tripService: tripService}
-}
-
-// TripResponse is the HTTP response for trip operations.
-type TripResponse struct {
-	TripID      string       `json:"trip_id"`
-	RideID      string       `json:"ride_id"`
-	DriverID    string       `json:"driver_id"`
-	Status      string       `json:"status"`
-	Fare        float64      `json:"fare"`
-	StartedAt   string       `json:"started_at"`
-	EndedAt     string       `json:"ended_at,omitempty"`
-	PausedAt    string       `json:"paused_at,omitempty"`
-	TotalPaused int64        `json:"total_paused_seconds,omitempty"`
-	Payment     *PaymentInfo `json:"payment,omitempty"`
-	Receipt     *ReceiptInfo `json:"receipt,omitempty"`
-}
-
-// PaymentInfo contains payment details in the response.
-type PaymentInfo struct {
-	ID     string  `json:"id"`
-	Amount float64 `json:"amount"`
-	Status string  `json:"status"`
-}
-
-// ReceiptInfo contains receipt details in the response.
-type ReceiptInfo struct {
-	ID              string  `json:"id"`
-	BaseFare        float64 `json:"base_fare"`
-	SurgeMultiplier float64 `json:"surge_multiplier"`
-	SurgeAmount     float64 `json:"surge_amount"`
-	TotalFare       float64 `json:"total_fare"`
-	PaymentMethod   string  `json:"payment_method"`
-	PaymentStatus   string  `json:"payment_status"`
-	DurationMinutes float64 `json:"duration_minutes"`
-	DistanceKm      float64 `json:"distance_km"`
+func NewTripHandler(tripService *service.TripService, etaService *service.ETAService) *TripHandler {
+	return &TripHandler{tripService: tripService, etaService: etaService}
+}
+
+// PauseTripRequest is the optional HTTP request body for pausing a trip.
+type PauseTripRequest struct {
+	Reason string `json:"reason"` // TRAFFIC, FUEL, RIDER_REQUEST or OTHER
+}
+
+// AbortTripRequest is the HTTP request body for aborting a trip in progress.
+type AbortTripRequest struct {
+	AbortedBy string `json:"aborted_by"` // RIDER or DRIVER
+	Reason    string `json:"reason"`
+}
+
+// SplitFareRequest is the HTTP request body for splitting a trip's fare.
+type SplitFareRequest struct {
+	Riders []struct {
+		RiderID string  `json:"rider_id"`
+		Share   float64 `json:"share"` // Relative weight; omit on every rider to split equally
+	} `json:"riders"`
+}
+
+// DisputeTripRequest is the HTTP request body for the rider disputing a trip.
+type DisputeTripRequest struct {
+	Reason string `json:"reason"`
+}
+
+// ResolveDisputeRequest is the HTTP request body for resolving a disputed trip.
+type ResolveDisputeRequest struct {
+	Outcome string `json:"outcome"` // CHARGE or VOID
+}
+
+// ApproveFareRequest is the HTTP request body for approving a held fare.
+type ApproveFareRequest struct {
+	Fare float64 `json:"fare"`
+}
+
+// AdjustFareRequest is the HTTP request body for correcting an ended trip's fare.
+type AdjustFareRequest struct {
+	Fare   float64 `json:"fare"`
+	Reason string  `json:"reason"`
+}
+
+// FareAdjustmentResponse is the HTTP response for a fare adjustment: the trip
+// with its corrected fare and new receipt, the payment charging or refunding
+// the difference, and the adjustment as recorded.
+type FareAdjustmentResponse struct {
+	TripResponse
+	Adjustment FareAdjustmentInfo `json:"adjustment"`
+}
+
+// FareAdjustmentInfo is a recorded fare adjustment.
+type FareAdjustmentInfo struct {
+	ID           string  `json:"id"`
+	PreviousFare float64 `json:"previous_fare"`
+	Fare         float64 `json:"fare"`
+	Difference   float64 `json:"difference"` // Positive when charged, negative when refunded
+	Reason       string  `json:"reason"`
+	PaymentID    string  `json:"payment_id,omitempty"`
+}
+
+// newTripResponse maps a trip to its response shape. total_paused_seconds is
+// always reported (0 if never paused); ended_at and paused_at only when set.
+func newTripResponse(trip *domain.Trip) TripResponse {
+	response := TripResponse{
+		TripID:      trip.ID,
+		RideID:      trip.RideID,
+		DriverID:    trip.DriverID,
+		Status:      string(trip.Status),
+		Fare:        trip.Fare,
+		StartedAt:   trip.StartedAt.Format("2006-01-02T15:04:05Z07:00"),
+		TotalPaused: int64(trip.TotalPaused.Seconds()),
+		AutoEnded:   trip.AutoEnded,
+		CreatedAt:   formatTimestamp(trip.CreatedAt),
+		UpdatedAt:   formatTimestamp(trip.UpdatedAt),
+	}
+
+	if !trip.EndedAt.IsZero() {
+		response.EndedAt = trip.EndedAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+
+	if !trip.PausedAt.IsZero() {
+		response.PausedAt = trip.PausedAt.Format("2006-01-02T15:04:05Z07:00")
+		response.PauseReason = string(trip.PauseReason)
+	}
+
+	if trip.NeedsReview {
+		response.NeedsReview = true
+		response.UncappedFare = trip.UncappedFare
+	}
+
+	if trip.Status == domain.TripStatusAborted {
+		response.AbortedBy = string(trip.AbortedBy)
+		response.AbortReason = trip.AbortReason
+	}
+
+	response.Confirmation = string(trip.Confirmation)
+	response.DisputeReason = trip.DisputeReason
+
+	return response
 }
 
 // EndTrip handles POST /v1/trips/:id/end
@@ -65,65 +134,285 @@ func (h *TripHandler) EndTrip(c *gin.Context) {
 		return
 	}
 
-	response := TripResponse{
-		TripID:      result.Trip.ID,
-		RideID:      result.Trip.RideID,
-		DriverID:    result.Trip.DriverID,
-		Status:      string(result.Trip.Status),
-		Fare:        result.Trip.Fare,
-		StartedAt:   result.Trip.StartedAt.Format("2006-01-02T15:04:05Z07:00"),
-		EndedAt:     result.Trip.EndedAt.Format("2006-01-02T15:04:05Z07:00"),
-		TotalPaused: int64(result.Trip.TotalPaused.Seconds()),
+	respondJSON(c, http.StatusOK, newSettledTripResponse(result))
+}
+
+// AbortTrip handles POST /v1/trips/:id/abort
+func (h *TripHandler) AbortTrip(c *gin.Context) {
+	var req AbortTripRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	result, err := h.tripService.AbortTrip(c.Request.Context(), service.AbortTripRequest{
+		TripID:    c.Param("id"),
+		AbortedBy: domain.AbortParty(req.AbortedBy),
+		Reason:    req.Reason,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, newSettledTripResponse(result))
+}
+
+// SplitFare handles POST /v1/trips/:id/split
+// Splits the fare between the riders listed, who must include the caller
+// (X-User-ID), the ride's rider. Each is charged their share when the trip
+// ends; 409 once it has, 404 if the ride is not the caller's.
+func (h *TripHandler) SplitFare(c *gin.Context) {
+	var req SplitFareRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	participants := make([]service.SplitParticipant, len(req.Riders))
+	for i, r := range req.Riders {
+		participants[i] = service.SplitParticipant{RiderID: r.RiderID, Share: r.Share}
+	}
+
+	split, err := h.tripService.SplitFare(c.Request.Context(), service.SplitFareRequest{
+		TripID:       c.Param("id"),
+		RiderID:      middleware.CallerFrom(c).UserID,
+		Participants: participants,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, newFareSplitInfo(split))
+}
+
+// newFareSplitInfo maps a fare split to its response shape.
+func newFareSplitInfo(split *domain.FareSplit) *FareSplitInfo {
+	info := &FareSplitInfo{
+		TripID:  split.TripID,
+		OwnerID: split.OwnerID,
+		Shares:  make([]FareShareInfo, len(split.Shares)),
+	}
+	for i, share := range split.Shares {
+		info.Shares[i] = FareShareInfo{
+			RiderID:   share.RiderID,
+			Share:     share.Weight,
+			Amount:    share.Amount,
+			PaymentID: share.PaymentID,
+			Absorbed:  share.Absorbed,
+		}
+	}
+	return info
+}
+
+// ApproveFare handles POST /v1/admin/trips/:id/approve-fare
+func (h *TripHandler) ApproveFare(c *gin.Context) {
+	var req ApproveFareRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	result, err := h.tripService.ApproveFare(c.Request.Context(), service.ApproveFareRequest{
+		TripID: c.Param("id"),
+		Fare:   req.Fare,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, newSettledTripResponse(result))
+}
+
+// ConfirmTrip handles POST /v1/trips/:id/confirm
+// The ride's rider confirms a trip waiting for them happened, capturing its
+// fare. 404 if it is not the caller's, 409 if it is not waiting for them.
+func (h *TripHandler) ConfirmTrip(c *gin.Context) {
+	result, err := h.tripService.ConfirmTrip(c.Request.Context(), service.TripConfirmationRequest{
+		TripID:  c.Param("id"),
+		RiderID: middleware.CallerFrom(c).UserID,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, newSettledTripResponse(result))
+}
+
+// DisputeTrip handles POST /v1/trips/:id/dispute
+// The ride's rider disputes a trip waiting for them; its fare stays held
+// until an admin resolves the dispute. 404 if it is not the caller's, 409 if
+// it is not waiting for them.
+func (h *TripHandler) DisputeTrip(c *gin.Context) {
+	var req DisputeTripRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	trip, err := h.tripService.DisputeTrip(c.Request.Context(), service.TripConfirmationRequest{
+		TripID:  c.Param("id"),
+		RiderID: middleware.CallerFrom(c).UserID,
+		Reason:  req.Reason,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, newTripResponse(trip))
+}
+
+// ResolveDispute handles POST /v1/admin/trips/:id/resolve-dispute
+// CHARGE captures a disputed trip's fare; VOID releases the hold and
+// charges nothing.
+func (h *TripHandler) ResolveDispute(c *gin.Context) {
+	var req ResolveDisputeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	result, err := h.tripService.ResolveDispute(c.Request.Context(), service.ResolveDisputeRequest{
+		TripID:  c.Param("id"),
+		Outcome: service.DisputeOutcome(req.Outcome),
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, newSettledTripResponse(result))
+}
+
+// AdjustFare handles POST /v1/admin/trips/:id/adjust-fare
+// Corrects an ended trip's fare, charging or refunding the difference, and
+// regenerates its receipt.
+func (h *TripHandler) AdjustFare(c *gin.Context) {
+	var req AdjustFareRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	result, err := h.tripService.AdjustFare(c.Request.Context(), service.AdjustFareRequest{
+		TripID: c.Param("id"),
+		Fare:   req.Fare,
+		Reason: req.Reason,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	adjustment := result.Adjustment
+	respondJSON(c, http.StatusOK, FareAdjustmentResponse{
+		TripResponse: newSettledTripResponse(&service.EndTripResponse{
+			Trip:    result.Trip,
+			Payment: result.Payment,
+			Receipt: result.Receipt,
+		}),
+		Adjustment: FareAdjustmentInfo{
+			ID:           adjustment.ID,
+			PreviousFare: adjustment.PreviousFare,
+			Fare:         adjustment.Fare,
+			Difference:   adjustment.Difference(),
+			Reason:       adjustment.Reason,
+			PaymentID:    adjustment.PaymentID,
+		},
+	})
+}
+
+// ConfirmCash handles POST /v1/trips/:id/confirm-cash
+// The caller must be the trip's driver; 403 otherwise.
+func (h *TripHandler) ConfirmCash(c *gin.Context) {
+	payment, err := h.tripService.ConfirmCash(c.Request.Context(), service.ConfirmCashRequest{
+		TripID:   c.Param("id"),
+		DriverID: middleware.CallerFrom(c).UserID,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
 	}
 
+	respondJSON(c, http.StatusOK, PaymentInfo{
+		ID:     payment.ID,
+		Amount: payment.Amount,
+		Fee:    payment.Fee,
+		Status: string(payment.Status),
+	})
+}
+
+// newSettledTripResponse maps an ended or aborted trip with its payment and
+// receipt.
+func newSettledTripResponse(result *service.EndTripResponse) TripResponse {
+	response := newTripResponse(result.Trip)
+
 	if result.Payment != nil {
 		response.Payment = &PaymentInfo{
-			ID:     result.Payment.ID,
-			Amount: result.Payment.Amount,
-			Status: string(result.Payment.Status),
+			ID:            result.Payment.ID,
+			Amount:        result.Payment.Amount,
+			Fee:           result.Payment.Fee,
+			Status:        string(result.Payment.Status),
+			FailureReason: result.Payment.FailureReason,
+			RetryHint:     paymentRetryHint(result.Payment),
 		}
 	}
 
 	if result.Receipt != nil {
 		response.Receipt = &ReceiptInfo{
-			ID:              result.Receipt.ID,
-			BaseFare:        result.Receipt.BaseFare,
-			SurgeMultiplier: result.Receipt.SurgeMultiplier,
-			SurgeAmount:     result.Receipt.SurgeAmount,
-			TotalFare:       result.Receipt.TotalFare,
-			PaymentMethod:   string(result.Receipt.PaymentMethod),
-			PaymentStatus:   string(result.Receipt.PaymentStatus),
-			DurationMinutes: result.Receipt.Duration.Minutes(),
-			DistanceKm:      result.Receipt.Distance,
+			ID:               result.Receipt.ID,
+			BaseFare:         result.Receipt.BaseFare,
+			SurgeMultiplier:  result.Receipt.SurgeMultiplier,
+			SurgeAmount:      result.Receipt.SurgeAmount,
+			SurchargeLabel:   result.Receipt.SurchargeLabel,
+			SurchargeAmount:  result.Receipt.SurchargeAmount,
+			ProcessingFee:    result.Receipt.ProcessingFee,
+			TotalFare:        result.Receipt.TotalFare,
+			PaymentMethod:    string(result.Receipt.PaymentMethod),
+			PaymentStatus:    string(result.Receipt.PaymentStatus),
+			DurationMinutes:  result.Receipt.Duration.Minutes(),
+			DistanceKm:       result.Receipt.Distance,
+			SplitRiders:      result.Receipt.SplitRiders,
+			ShareAmount:      result.Receipt.ShareAmount,
+			AbsorbedAmount:   result.Receipt.AbsorbedAmount,
+			ShareAbsorbed:    result.Receipt.ShareAbsorbed,
+			AdjustedFrom:     result.Receipt.AdjustedFrom,
+			AdjustmentReason: result.Receipt.AdjustmentReason,
 		}
 	}
 
-	respondJSON(c, http.StatusOK, response)
+	if result.Split != nil {
+		response.Split = newFareSplitInfo(result.Split)
+	}
+
+	return response
 }
 
 // PauseTrip handles POST /v1/trips/:id/pause
 func (h *TripHandler) PauseTrip(c *gin.Context) {
 	tripID := c.Param("id")
 
+	// The body is optional: a pause without one carries no reason.
+	var req PauseTripRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
 	trip, err := h.tripService.PauseTrip(c.Request.Context(), service.PauseTripRequest{
 		TripID: tripID,
+		Reason: domain.PauseReason(req.Reason),
 	})
 	if err != nil {
 		respondError(c, err)
 		return
 	}
 
-	response := TripResponse{
-		TripID:    trip.ID,
-		RideID:    trip.RideID,
-		DriverID:  trip.DriverID,
-		Status:    string(trip.Status),
-		Fare:      trip.Fare,
-		StartedAt: trip.StartedAt.Format("2006-01-02T15:04:05Z07:00"),
-		PausedAt:  trip.PausedAt.Format("2006-01-02T15:04:05Z07:00"),
-	}
-
-	respondJSON(c, http.StatusOK, response)
+	respondJSON(c, http.StatusOK, newTripResponse(trip))
 }
 
 // ResumeTrip handles POST /v1/trips/:id/resume
@@ -138,17 +427,7 @@ func (h *TripHandler) ResumeTrip(c *gin.Context) {
 		return
 	}
 
-	response := TripResponse{
-		TripID:      trip.ID,
-		RideID:      trip.RideID,
-		DriverID:    trip.DriverID,
-		Status:      string(trip.Status),
-		Fare:        trip.Fare,
-		StartedAt:   trip.StartedAt.Format("2006-01-02T15:04:05Z07:00"),
-		TotalPaused: int64(trip.TotalPaused.Seconds()),
-	}
-
-	respondJSON(c, http.StatusOK, response)
+	respondJSON(c, http.StatusOK, newTripResponse(trip))
 }
 
 // GetTrip handles GET /v1/trips/:id
@@ -161,22 +440,27 @@ func (h *TripHandler) GetTrip(c *gin.Context) {
 		return
 	}
 
-	response := TripResponse{
-		TripID:      trip.ID,
-		RideID:      trip.RideID,
-		DriverID:    trip.DriverID,
-		Status:      string(trip.Status),
-		Fare:        trip.Fare,
-		StartedAt:   trip.StartedAt.Format("2006-01-02T15:04:05Z07:00"),
-		TotalPaused: int64(trip.TotalPaused.Seconds()),
-	}
-
-	if !trip.EndedAt.IsZero() {
-		response.EndedAt = trip.EndedAt.Format("2006-01-02T15:04:05Z07:00")
+	if err := authorizeRead(c, func() (string, string, error) {
+		riderID, err := h.tripService.GetTripRiderID(c.Request.Context(), trip)
+		return riderID, trip.DriverID, err
+	}); err != nil {
+		respondError(c, err)
+		return
 	}
 
-	if !trip.PausedAt.IsZero() {
-		response.PausedAt = trip.PausedAt.Format("2006-01-02T15:04:05Z07:00")
+	response := newTripResponse(trip)
+	// Progress is left out, rather than failing the request, when the
+	// driver's location is unknown.
+	if h.etaService != nil {
+		if progress, err := h.etaService.TripProgress(c.Request.Context(), trip); err == nil && progress != nil {
+			response.Progress = &TripETAInfo{
+				RemainingKm:     progress.RemainingKm,
+				ETASeconds:      progress.ETASeconds,
+				ETAMinutes:      progress.ETAMinutes(),
+				ProgressPercent: progress.ProgressPercent,
+				Paused:          progress.Paused,
+			}
+		}
 	}
 
 	respondJSON(c, http.StatusOK, response)
@@ -190,21 +474,9 @@ func (h *TripHandler) GetAll(c *gin.Context) {
 		return
 	}
 
-	var response []TripResponse
+	response := make([]TripResponse, 0, len(trips))
 	for _, trip := range trips {
-		tr := TripResponse{
-			TripID:      trip.ID,
-			RideID:      trip.RideID,
-			DriverID:    trip.DriverID,
-			Status:      string(trip.Status),
-			Fare:        trip.Fare,
-			StartedAt:   trip.StartedAt.Format("2006-01-02T15:04:05Z07:00"),
-			TotalPaused: int64(trip.TotalPaused.Seconds()),
-		}
-		if !trip.EndedAt.IsZero() {
-			tr.EndedAt = trip.EndedAt.Format("2006-01-02T15:04:05Z07:00")
-		}
-		response = append(response, tr)
+		response = append(response, newTripResponse(trip))
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -9,29 +9,51 @@ import (
 
 	"ride/internal/domain"
 	"ride/internal/repository"
+	"ride/internal/service"
 )
 
 // UserHandler handles HTTP requests for users.
 type UserHandler struct {
-	userRepo repository.UserRepository
+	userRepo     repository.UserRepository
+	emailService *service.EmailVerificationService
+	phoneRegion  string // Region assumed for phone numbers without a country code
 }
 
 // NewUserHandler creates a new UserHandler.
-func NewUserHandler(userRepo repository.UserRepository) *UserHandler {
-	return &UserHandler{userRepo: userRepo}
+func NewUserHandler(userRepo repository.UserRepository, emailService *service.EmailVerificationService, phoneRegion string) *UserHandler {
+	return &UserHandler{userRepo: userRepo, emailService: emailService, phoneRegion: phoneRegion}
 }
 
 // RegisterRequest is the HTTP request body for user registration.
 type RegisterRequest struct {
 	Name  string `json:"name"`
 	Phone string `json:"phone"`
+	Email string `json:"email"` // Optional; unverified until confirmed
+}
+
+// RequestEmailVerificationRequest is the HTTP request body for sending a
+// verification email.
+type RequestEmailVerificationRequest struct {
+	Email string `json:"email"`
 }
 
 // UserResponse is the HTTP response for user data.
 type UserResponse struct {
-	ID    string `json:"id"`
-	Name  string `json:"name"`
-	Phone string `json:"phone"`
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	Phone         string `json:"phone"`
+	Email         string `json:"email,omitempty"`
+	EmailVerified bool   `json:"email_verified,omitempty"`
+}
+
+func toUserResponse(u *domain.User) UserResponse {
+	return UserResponse{
+		ID:            u.ID,
+		Name:          u.Name,
+		Phone:         u.Phone,
+		Email:         u.Email,
+		EmailVerified: u.EmailVerified,
+	}
 }
 
 // Register handles POST /v1/users/register
@@ -42,13 +64,30 @@ func (h *UserHandler) Register(c *gin.Context) {
 		return
 	}
 
-	if req.Name == "" || req.Phone == "" {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "name and phone are required"})
+	// Check every field before failing, so the client sees all problems at once.
+	var problems service.ValidationError
+	if req.Name == "" {
+		problems.Add("name", service.ErrFieldRequired)
+	}
+	var phone string
+	if req.Phone == "" {
+		problems.Add("phone", service.ErrFieldRequired)
+	} else {
+		var err error
+		phone, err = service.NormalizePhone(req.Phone, h.phoneRegion)
+		problems.Add("phone", err)
+	}
+	email := domain.NormalizeEmail(req.Email)
+	if email != "" && !domain.IsValidEmail(email) {
+		problems.Add("email", service.ErrInvalidEmail)
+	}
+	if err := problems.Err(); err != nil {
+		respondError(c, err)
 		return
 	}
 
 	// Check if user already exists
-	existing, err := h.userRepo.GetByPhone(c.Request.Context(), req.Phone)
+	existing, err := h.userRepo.GetByPhone(c.Request.Context(), phone)
 	if err != nil && !errors.Is(err, repository.ErrNotFound) {
 		respondError(c, err)
 		return
@@ -57,16 +96,27 @@ func (h *UserHandler) Register(c *gin.Context) {
 	if existing != nil {
 		c.JSON(http.StatusConflict, gin.H{
 			"message": "User already registered",
-			"user":    UserResponse{ID: existing.ID, Name: existing.Name, Phone: existing.Phone},
+			"user":    toUserResponse(existing),
 		})
 		return
 	}
 
+	if email != "" {
+		if _, err := h.userRepo.GetByEmail(c.Request.Context(), email); err == nil {
+			respondError(c, service.ErrEmailTaken)
+			return
+		} else if !errors.Is(err, repository.ErrNotFound) {
+			respondError(c, err)
+			return
+		}
+	}
+
 	// Create new user
 	user := &domain.User{
 		ID:    uuid.New().String(),
 		Name:  req.Name,
-		Phone: req.Phone,
+		Phone: phone,
+		Email: email,
 	}
 
 	if err := h.userRepo.Create(c.Request.Context(), user); err != nil {
@@ -74,11 +124,7 @@ func (h *UserHandler) Register(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, UserResponse{
-		ID:    user.ID,
-		Name:  user.Name,
-		Phone: user.Phone,
-	})
+	c.JSON(http.StatusCreated, toUserResponse(user))
 }
 
 // GetAll handles GET /v1/users
@@ -89,14 +135,37 @@ func (h *UserHandler) GetAll(c *gin.Context) {
 		return
 	}
 
-	var response []UserResponse
+	response := make([]UserResponse, 0, len(users))
 	for _, u := range users {
-		response = append(response, UserResponse{
-			ID:    u.ID,
-			Name:  u.Name,
-			Phone: u.Phone,
-		})
+		response = append(response, toUserResponse(u))
 	}
 
 	c.JSON(http.StatusOK, response)
 }
+
+// RequestEmailVerification handles POST /v1/users/:id/email
+func (h *UserHandler) RequestEmailVerification(c *gin.Context) {
+	var req RequestEmailVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	if err := h.emailService.RequestVerification(c.Request.Context(), c.Param("id"), req.Email); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Verification email sent"})
+}
+
+// VerifyEmail handles GET /v1/users/verify-email?token=
+func (h *UserHandler) VerifyEmail(c *gin.Context) {
+	user, err := h.emailService.VerifyEmail(c.Request.Context(), c.Query("token"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toUserResponse(user))
+}
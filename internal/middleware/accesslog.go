@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AccessLogConfig configures AccessLogMiddleware.
+type AccessLogConfig struct {
+	SkipPaths  []string  // Paths never logged, e.g. health checks
+	SampleRate int       // Log 1 in N successful requests; values <= 1 log all
+	Output     io.Writer // Defaults to gin.DefaultWriter
+}
+
+// AccessLogMiddleware replaces gin.Logger. Requests to SkipPaths are never
+// logged, successful requests are sampled at 1 in SampleRate, and requests
+// that end in an error status are always logged. Client IPs come from
+// c.ClientIP, so forwarding headers are only honored from trusted proxies.
+// Each line carries the request ID set by RequestIDMiddleware.
+func AccessLogMiddleware(cfg AccessLogConfig) gin.HandlerFunc {
+	skip := make(map[string]struct{}, len(cfg.SkipPaths))
+	for _, path := range cfg.SkipPaths {
+		skip[path] = struct{}{}
+	}
+	out := cfg.Output
+	if out == nil {
+		out = gin.DefaultWriter
+	}
+	var successes atomic.Uint64
+
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		if _, ok := skip[path]; ok {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		if status < http.StatusBadRequest && len(c.Errors) == 0 && cfg.SampleRate > 1 {
+			// Log the first success in every window of SampleRate.
+			if (successes.Add(1)-1)%uint64(cfg.SampleRate) != 0 {
+				return
+			}
+		}
+
+		if c.Request.URL.RawQuery != "" {
+			path += "?" + c.Request.URL.RawQuery
+		}
+		fmt.Fprintf(out, "[ACCESS] %s | %3d | %13v | %15s | %s | %-7s %q %s\n",
+			start.Format("2006/01/02 - 15:04:05"),
+			status,
+			time.Since(start),
+			c.ClientIP(),
+			RequestID(c),
+			c.Request.Method,
+			path,
+			c.Errors.ByType(gin.ErrorTypePrivate).String(),
+		)
+	}
+}
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminAuthMiddleware requires an "Authorization: Bearer <token>" header
+// matching the configured admin token. With no token configured every
+// request is rejected, so admin routes are never left open by accident.
+func AdminAuthMiddleware(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !hasAdminToken(c.GetHeader("Authorization"), token) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "admin authorization required"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -9,6 +9,8 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
+
+	"ride/internal/redis/keyspace"
 )
 
 const (
@@ -35,7 +37,8 @@ func (w *responseWriter) Write(b []byte) (int, error) {
 }
 
 // IdempotencyMiddleware returns middleware that handles idempotent requests.
-func IdempotencyMiddleware(redisClient *redis.Client) gin.HandlerFunc {
+// keyPrefix is prepended to the cached response keys.
+func IdempotencyMiddleware(redisClient *redis.Client, keyPrefix string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Only apply to mutating methods.
 		if c.Request.Method != http.MethodPost && c.Request.Method != http.MethodPut && c.Request.Method != http.MethodPatch {
@@ -52,7 +55,7 @@ func IdempotencyMiddleware(redisClient *redis.Client) gin.HandlerFunc {
 		}
 
 		ctx := c.Request.Context()
-		cacheKey := "idempotency:" + key
+		cacheKey := keyspace.New(keyPrefix).Idempotency(key)
 
 		// Check for cached response.
 		cached, err := getCachedResponse(ctx, redisClient, cacheKey)
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// userIDHeader identifies the rider or driver making a request until real
+// authentication exists.
+const userIDHeader = "X-User-ID"
+
+const callerKey = "caller"
+
+// Caller is who a request is made on behalf of.
+type Caller struct {
+	UserID string // Rider or driver ID; empty when not given
+	Admin  bool   // Presented the admin token
+}
+
+// IdentityMiddleware records the request's Caller for handlers to authorize
+// against. Admins are recognized by the admin bearer token.
+func IdentityMiddleware(adminToken string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(callerKey, NewCaller(c.GetHeader(userIDHeader), c.GetHeader("Authorization"), adminToken))
+		c.Next()
+	}
+}
+
+// NewCaller returns the Caller identified by a request's X-User-ID and
+// Authorization values. The gRPC server reads the same values from metadata.
+func NewCaller(userID, authorization, adminToken string) Caller {
+	return Caller{
+		UserID: strings.TrimSpace(userID),
+		Admin:  hasAdminToken(authorization, adminToken),
+	}
+}
+
+// CallerFrom returns the request's Caller; the zero Caller if none was recorded.
+func CallerFrom(c *gin.Context) Caller {
+	value, _ := c.Get(callerKey)
+	caller, _ := value.(Caller)
+	return caller
+}
+
+// hasAdminToken reports whether authorization carries the admin bearer token.
+// No request does when the token is not configured.
+func hasAdminToken(authorization, adminToken string) bool {
+	provided, ok := strings.CutPrefix(authorization, "Bearer ")
+	return adminToken != "" && ok && subtle.ConstantTimeCompare([]byte(provided), []byte(adminToken)) == 1
+}
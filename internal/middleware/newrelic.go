@@ -14,10 +14,17 @@ func NewRelicMiddleware(app *newrelic.Application) gin.HandlerFunc {
 		}
 
 		txn := app.StartTransaction(c.Request.Method + " " + c.FullPath())
+		if txn == nil {
+			c.Next()
+			return
+		}
 		defer txn.End()
 
 		txn.SetWebRequestHTTP(c.Request)
 		c.Set("newRelicTransaction", txn)
+		// Downstream code (datastore segments, custom metrics) finds the
+		// transaction through the request context.
+		c.Request = newrelic.RequestWithTransactionContext(c.Request, txn)
 
 		writer := txn.SetWebResponse(c.Writer)
 		c.Writer = &wrappedResponseWriter{
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RecoveryMiddleware replaces gin.Recovery. A panicking handler is logged,
+// with its request ID and stack, to out (gin.DefaultErrorWriter when nil),
+// and the client gets a plain JSON 500 carrying only the request ID, never
+// the panic value or stack. http.ErrAbortHandler is re-panicked so the
+// server still aborts the connection.
+func RecoveryMiddleware(out io.Writer) gin.HandlerFunc {
+	if out == nil {
+		out = gin.DefaultErrorWriter
+	}
+
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if err, ok := recovered.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(recovered)
+			}
+
+			id := RequestID(c)
+			fmt.Fprintf(out, "[PANIC] %s | %s | %-7s %s | %v\n%s",
+				time.Now().Format("2006/01/02 - 15:04:05"),
+				id,
+				c.Request.Method,
+				c.Request.URL.Path,
+				recovered,
+				debug.Stack(),
+			)
+
+			if c.Writer.Written() {
+				// Part of the response is already out; all that is left is
+				// to stop.
+				c.Abort()
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"error":      "internal server error",
+				"request_id": id,
+			})
+		}()
+		c.Next()
+	}
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader carries a request's ID in both directions.
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDKey is the gin context key of the request ID.
+const requestIDKey = "request_id"
+
+// maxRequestIDLength bounds an ID accepted from the client.
+const maxRequestIDLength = 64
+
+// RequestIDMiddleware gives every request an ID, echoed in the X-Request-ID
+// response header and written to the access and panic logs so a client's
+// report can be matched to the server's. An ID sent by the client, e.g. from
+// a gateway, is kept when it is short and plain; otherwise a new one is made.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+		c.Set(requestIDKey, id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// RequestID returns the request's ID, or "" before RequestIDMiddleware ran.
+func RequestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+// validRequestID reports whether id is safe to log and echo: letters,
+// digits, '.', '_' and '-' only.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
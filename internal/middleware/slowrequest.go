@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SlowRequestMiddleware logs every request that takes longer than threshold,
+// with its route pattern and status, to surface latency outliers without
+// full tracing. Unlike the access log it is never sampled. Output defaults to
+// gin.DefaultWriter; a non-positive threshold disables the log.
+func SlowRequestMiddleware(threshold time.Duration, out io.Writer) gin.HandlerFunc {
+	if out == nil {
+		out = gin.DefaultWriter
+	}
+
+	return func(c *gin.Context) {
+		if threshold <= 0 {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		c.Next()
+
+		elapsed := time.Since(start)
+		if elapsed <= threshold {
+			return
+		}
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path // No route matched
+		}
+		fmt.Fprintf(out, "[SLOW] %s | %3d | %13v | %-7s %s | over %v\n",
+			start.Format("2006/01/02 - 15:04:05"),
+			c.Writer.Status(),
+			elapsed,
+			c.Request.Method,
+			route,
+			threshold,
+		)
+	}
+}
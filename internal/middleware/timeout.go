@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TimeoutMiddleware bounds each request's context to timeout, so services
+// stop work for a request that has run too long; the handler reports the
+// resulting context.DeadlineExceeded as 504. Routes in skipRoutes, given as
+// registered patterns such as "/v1/users/:id/events", keep the
+// unbounded context, for streams that are meant to stay open. A non-positive
+// timeout disables the limit.
+func TimeoutMiddleware(timeout time.Duration, skipRoutes ...string) gin.HandlerFunc {
+	skip := make(map[string]struct{}, len(skipRoutes))
+	for _, route := range skipRoutes {
+		skip[route] = struct{}{}
+	}
+
+	return func(c *gin.Context) {
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+		if _, ok := skip[c.FullPath()]; ok {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -0,0 +1,165 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Headers of a signed tracker request.
+const (
+	TrackerTimestampHeader = "X-Tracker-Timestamp" // Unix seconds
+	TrackerNonceHeader     = "X-Tracker-Nonce"
+	TrackerSignatureHeader = "X-Tracker-Signature"
+)
+
+const (
+	defaultTrackerMaxSkew = 5 * time.Minute // Used when the configured skew is not positive
+	maxTrackerNonceLength = 128
+)
+
+// TrackerKeys looks up the key a driver's tracker signs with; "" if none
+// was issued.
+type TrackerKeys interface {
+	SigningKey(ctx context.Context, driverID string) (string, error)
+}
+
+// TrackerNonces remembers the nonces of accepted tracker requests.
+type TrackerNonces interface {
+	ClaimNonce(ctx context.Context, driverID, nonce string, ttl time.Duration) (bool, error)
+}
+
+// TrackerAuthConfig configures TrackerAuthMiddleware.
+type TrackerAuthConfig struct {
+	Keys    TrackerKeys
+	Nonces  TrackerNonces
+	MaxSkew time.Duration    // How far a request's timestamp may be from now
+	Now     func() time.Time // Optional: defaults to time.Now
+}
+
+// TrackerAuthMiddleware authenticates location updates from embedded
+// trackers that cannot carry the app's credentials. A tracker signs each
+// request with TrackerSignature over its timestamp, a fresh nonce and the
+// body, keyed by the driver in the :id path parameter's signing key. A
+// request is rejected with 401 if the signature does not match, its
+// timestamp is more than MaxSkew from now or its nonce was seen before;
+// otherwise its caller is the driver. Once a driver has a key, their
+// requests must be signed; until then unsigned requests pass through
+// unchanged, as the app sends them. Without Keys or Nonces signed requests
+// get 503.
+func TrackerAuthMiddleware(cfg TrackerAuthConfig) gin.HandlerFunc {
+	if cfg.MaxSkew <= 0 {
+		cfg.MaxSkew = defaultTrackerMaxSkew
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		driverID := c.Param("id")
+		signature := c.GetHeader(TrackerSignatureHeader)
+		if signature == "" {
+			if cfg.Keys == nil {
+				c.Next()
+				return
+			}
+			key, err := cfg.Keys.SigningKey(ctx, driverID)
+			if err != nil {
+				log.Printf("[TRACKER] Failed to load the signing key of driver %s: %v", driverID, err)
+				c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "tracker authentication unavailable"})
+				return
+			}
+			if key != "" {
+				abortTrackerUnauthorized(c, "tracker signature required")
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if cfg.Keys == nil || cfg.Nonces == nil {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "tracker authentication unavailable"})
+			return
+		}
+
+		nonce := c.GetHeader(TrackerNonceHeader)
+		if nonce == "" || len(nonce) > maxTrackerNonceLength {
+			abortTrackerUnauthorized(c, "invalid tracker nonce")
+			return
+		}
+
+		timestamp := c.GetHeader(TrackerTimestampHeader)
+		unix, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			abortTrackerUnauthorized(c, "invalid tracker timestamp")
+			return
+		}
+		if skew := cfg.Now().Sub(time.Unix(unix, 0)); skew > cfg.MaxSkew || skew < -cfg.MaxSkew {
+			abortTrackerUnauthorized(c, "tracker request expired")
+			return
+		}
+
+		key, err := cfg.Keys.SigningKey(ctx, driverID)
+		if err != nil {
+			log.Printf("[TRACKER] Failed to load the signing key of driver %s: %v", driverID, err)
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "tracker authentication unavailable"})
+			return
+		}
+
+		var body []byte
+		if c.Request.Body != nil {
+			body, err = io.ReadAll(c.Request.Body)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "failed to read request body"})
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		// Compared in constant time, and against a key even when the
+		// driver has none, so timing reveals neither.
+		expected := TrackerSignature(key, timestamp, nonce, body)
+		if !hmac.Equal([]byte(signature), []byte(expected)) || key == "" {
+			abortTrackerUnauthorized(c, "invalid tracker signature")
+			return
+		}
+
+		// Remembered for as long as the timestamp could still be accepted.
+		fresh, err := cfg.Nonces.ClaimNonce(ctx, driverID, nonce, 2*cfg.MaxSkew)
+		if err != nil {
+			log.Printf("[TRACKER] Failed to claim a nonce of driver %s: %v", driverID, err)
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "tracker authentication unavailable"})
+			return
+		}
+		if !fresh {
+			abortTrackerUnauthorized(c, "tracker request replayed")
+			return
+		}
+
+		c.Set(callerKey, Caller{UserID: driverID})
+		c.Next()
+	}
+}
+
+// TrackerSignature returns the hex HMAC-SHA256, keyed by signingKey, of a
+// request's timestamp, nonce and body, each separated by a newline.
+func TrackerSignature(signingKey, timestamp, nonce string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(signingKey))
+	mac.Write([]byte(timestamp + "\n" + nonce + "\n"))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func abortTrackerUnauthorized(c *gin.Context, message string) {
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": message})
+}
@@ -0,0 +1,130 @@
+// Package money formats amounts for display in a currency and locale.
+package money
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// currency is how a currency is written.
+type currency struct {
+	code     string
+	symbol   string
+	decimals int // Minor-unit digits, e.g. 2 for cents
+}
+
+var currencies = map[string]currency{
+	"USD": {code: "USD", symbol: "$", decimals: 2},
+	"EUR": {code: "EUR", symbol: "€", decimals: 2},
+	"GBP": {code: "GBP", symbol: "£", decimals: 2},
+	"INR": {code: "INR", symbol: "₹", decimals: 2},
+	"JPY": {code: "JPY", symbol: "¥", decimals: 0},
+}
+
+// locale is how a locale writes numbers and places the currency symbol.
+type locale struct {
+	group       string // Thousands separator
+	decimal     string // Decimal separator
+	indian      bool   // Groups by two digits after the first three, as in 1,00,000
+	symbolAfter bool   // 12,50 € rather than €12.50
+}
+
+var locales = map[string]locale{
+	"en-US": {group: ",", decimal: "."},
+	"en-GB": {group: ",", decimal: "."},
+	"en-IN": {group: ",", decimal: ".", indian: true},
+	"de-DE": {group: ".", decimal: ",", symbolAfter: true},
+	"fr-FR": {group: "\u202f", decimal: ",", symbolAfter: true},
+}
+
+const (
+	DefaultCurrency = "USD"
+	DefaultLocale   = "en-US"
+)
+
+// Formatter formats amounts in one currency for one locale. A nil
+// *Formatter formats US dollars for en-US.
+type Formatter struct {
+	currency currency
+	locale   locale
+}
+
+// NewFormatter creates a Formatter for an ISO 4217 currency code and a
+// locale tag such as en-IN. Empty values mean DefaultCurrency and
+// DefaultLocale.
+func NewFormatter(currencyCode, localeTag string) (*Formatter, error) {
+	if currencyCode == "" {
+		currencyCode = DefaultCurrency
+	}
+	if localeTag == "" {
+		localeTag = DefaultLocale
+	}
+	c, ok := currencies[currencyCode]
+	if !ok {
+		return nil, fmt.Errorf("unsupported currency %q", currencyCode)
+	}
+	l, ok := locales[localeTag]
+	if !ok {
+		return nil, fmt.Errorf("unsupported locale %q", localeTag)
+	}
+	return &Formatter{currency: c, locale: l}, nil
+}
+
+// Currency returns the ISO 4217 code of the formatter's currency.
+func (f *Formatter) Currency() string {
+	if f == nil {
+		return DefaultCurrency
+	}
+	return f.currency.code
+}
+
+// Format writes an amount with the currency symbol, thousands separators
+// and the currency's minor-unit digits, e.g. $1,234.50, ₹1,04,000.00 or
+// 1.234,50 €.
+func (f *Formatter) Format(amount float64) string {
+	c, l := currencies[DefaultCurrency], locales[DefaultLocale]
+	if f != nil {
+		c, l = f.currency, f.locale
+	}
+
+	scale := math.Pow10(c.decimals)
+	minor := int64(math.Round(math.Abs(amount) * scale))
+	whole := fmt.Sprintf("%d", minor/int64(scale))
+
+	var b strings.Builder
+	if minor != 0 && amount < 0 {
+		b.WriteString("-")
+	}
+	if !l.symbolAfter {
+		b.WriteString(c.symbol)
+	}
+	b.WriteString(group(whole, l))
+	if c.decimals > 0 {
+		b.WriteString(l.decimal)
+		fmt.Fprintf(&b, "%0*d", c.decimals, minor%int64(scale))
+	}
+	if l.symbolAfter {
+		b.WriteString("\u00a0" + c.symbol) // A no-break space keeps the symbol with the number
+	}
+	return b.String()
+}
+
+// group inserts the locale's thousands separators into a run of digits.
+func group(digits string, l locale) string {
+	if len(digits) <= 3 {
+		return digits
+	}
+	head, tail := digits[:len(digits)-3], digits[len(digits)-3:]
+	size := 3
+	if l.indian {
+		size = 2
+	}
+	var parts []string
+	for len(head) > size {
+		parts = append([]string{head[len(head)-size:]}, parts...)
+		head = head[:len(head)-size]
+	}
+	parts = append([]string{head}, parts...)
+	return strings.Join(append(parts, tail), l.group)
+}
@@ -0,0 +1,47 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"ride/internal/redis/keyspace"
+)
+
+// arrivalCounterTTL bounds how long an abandoned pickup's counter lingers.
+const arrivalCounterTTL = time.Hour
+
+// ArrivalStore counts consecutive location pings per ride that place the
+// assigned driver inside the pickup geofence.
+type ArrivalStore struct {
+	client *redis.Client
+	keys   keyspace.Keyspace
+}
+
+// NewArrivalStore creates a new ArrivalStore.
+func NewArrivalStore(client *redis.Client, prefix string) *ArrivalStore {
+	return &ArrivalStore{client: client, keys: keyspace.New(prefix)}
+}
+
+// IncrementInFence records an in-fence ping and returns the number of
+// consecutive in-fence pings for the ride, including this one.
+func (s *ArrivalStore) IncrementInFence(ctx context.Context, rideID string) (int64, error) {
+	key := s.keys.RideArrival(rideID)
+
+	pipe := s.client.TxPipeline()
+	incr := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, arrivalCounterTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+
+	return incr.Val(), nil
+}
+
+// ResetInFence clears the ride's consecutive in-fence count.
+func (s *ArrivalStore) ResetInFence(ctx context.Context, rideID string) error {
+	key := s.keys.RideArrival(rideID)
+
+	return s.client.Del(ctx, key).Err()
+}
@@ -3,20 +3,22 @@ package redis
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"time"
 
 	"github.com/redis/go-redis/v9"
+
+	"ride/internal/redis/keyspace"
 )
 
 // CacheStore handles entity caching in Redis.
 type CacheStore struct {
 	client *redis.Client
+	keys   keyspace.Keyspace
 }
 
 // NewCacheStore creates a new CacheStore.
-func NewCacheStore(client *redis.Client) *CacheStore {
-	return &CacheStore{client: client}
+func NewCacheStore(client *redis.Client, prefix string) *CacheStore {
+	return &CacheStore{client: client, keys: keyspace.New(prefix)}
 }
 
 // Cache TTL constants
@@ -26,20 +28,15 @@ const (
 	TripCacheTTL   = 60 * time.Second  // Trip changes less frequently
 )
 
-// Key prefixes
-const (
-	driverCachePrefix = "cache:driver:"
-	rideCachePrefix   = "cache:ride:"
-	tripCachePrefix   = "cache:trip:"
-)
-
 // CachedDriver represents a cached driver entity.
 type CachedDriver struct {
-	ID     string `json:"id"`
-	Name   string `json:"name"`
-	Phone  string `json:"phone"`
-	Status string `json:"status"`
-	Tier   string `json:"tier"`
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Phone        string `json:"phone"`
+	Status       string `json:"status"`
+	Tier         string `json:"tier"`
+	VehiclePlate string `json:"vehicle_plate,omitempty"`
+	Capabilities []string `json:"capabilities,omitempty"` // Vehicle capabilities, e.g. WAV, for filtering without a database read
 }
 
 // CachedRide represents a cached ride entity.
@@ -53,7 +50,7 @@ type CachedRide struct {
 
 // GetDriver retrieves a driver from cache.
 func (s *CacheStore) GetDriver(ctx context.Context, driverID string) (*CachedDriver, error) {
-	key := driverCachePrefix + driverID
+	key := s.keys.DriverCache(driverID)
 	data, err := s.client.Get(ctx, key).Bytes()
 	if err != nil {
 		if err == redis.Nil {
@@ -71,7 +68,7 @@ func (s *CacheStore) GetDriver(ctx context.Context, driverID string) (*CachedDri
 
 // SetDriver stores a driver in cache.
 func (s *CacheStore) SetDriver(ctx context.Context, driver *CachedDriver) error {
-	key := driverCachePrefix + driver.ID
+	key := s.keys.DriverCache(driver.ID)
 	data, err := json.Marshal(driver)
 	if err != nil {
 		return err
@@ -81,13 +78,13 @@ func (s *CacheStore) SetDriver(ctx context.Context, driver *CachedDriver) error
 
 // InvalidateDriver removes a driver from cache.
 func (s *CacheStore) InvalidateDriver(ctx context.Context, driverID string) error {
-	key := driverCachePrefix + driverID
+	key := s.keys.DriverCache(driverID)
 	return s.client.Del(ctx, key).Err()
 }
 
 // GetRide retrieves a ride from cache.
 func (s *CacheStore) GetRide(ctx context.Context, rideID string) (*CachedRide, error) {
-	key := rideCachePrefix + rideID
+	key := s.keys.RideCache(rideID)
 	data, err := s.client.Get(ctx, key).Bytes()
 	if err != nil {
 		if err == redis.Nil {
@@ -105,7 +102,7 @@ func (s *CacheStore) GetRide(ctx context.Context, rideID string) (*CachedRide, e
 
 // SetRide stores a ride in cache.
 func (s *CacheStore) SetRide(ctx context.Context, ride *CachedRide) error {
-	key := rideCachePrefix + ride.ID
+	key := s.keys.RideCache(ride.ID)
 	data, err := json.Marshal(ride)
 	if err != nil {
 		return err
@@ -115,7 +112,7 @@ func (s *CacheStore) SetRide(ctx context.Context, ride *CachedRide) error {
 
 // InvalidateRide removes a ride from cache.
 func (s *CacheStore) InvalidateRide(ctx context.Context, rideID string) error {
-	key := rideCachePrefix + rideID
+	key := s.keys.RideCache(rideID)
 	return s.client.Del(ctx, key).Err()
 }
 
@@ -131,7 +128,7 @@ func (s *CacheStore) GetDriversBatch(ctx context.Context, driverIDs []string) (m
 	cmds := make(map[string]*redis.StringCmd, len(driverIDs))
 
 	for _, id := range driverIDs {
-		key := driverCachePrefix + id
+		key := s.keys.DriverCache(id)
 		cmds[id] = pipe.Get(ctx, key)
 	}
 
@@ -176,7 +173,7 @@ func (s *CacheStore) SetDriversBatch(ctx context.Context, drivers []*CachedDrive
 	pipe := s.client.Pipeline()
 
 	for _, driver := range drivers {
-		key := driverCachePrefix + driver.ID
+		key := s.keys.DriverCache(driver.ID)
 		data, err := json.Marshal(driver)
 		if err != nil {
 			continue // Skip invalid entries
@@ -191,7 +188,7 @@ func (s *CacheStore) SetDriversBatch(ctx context.Context, drivers []*CachedDrive
 // AcquireRideLock attempts to acquire a lock for ride assignment.
 // This prevents multiple matching attempts on the same ride.
 func (s *CacheStore) AcquireRideLock(ctx context.Context, rideID string, ttl time.Duration) (bool, error) {
-	key := fmt.Sprintf("lock:ride:%s", rideID)
+	key := s.keys.RideLock(rideID)
 	ok, err := s.client.SetNX(ctx, key, "1", ttl).Result()
 	if err != nil {
 		return false, err
@@ -201,27 +198,27 @@ func (s *CacheStore) AcquireRideLock(ctx context.Context, rideID string, ttl tim
 
 // ReleaseRideLock releases the lock for a ride.
 func (s *CacheStore) ReleaseRideLock(ctx context.Context, rideID string) error {
-	key := fmt.Sprintf("lock:ride:%s", rideID)
+	key := s.keys.RideLock(rideID)
 	return s.client.Del(ctx, key).Err()
 }
 
 // TrackDriverStatus stores driver availability status for fast lookup.
 // This is separate from the main cache - it's a set of available driver IDs.
 func (s *CacheStore) AddAvailableDriver(ctx context.Context, driverID string) error {
-	return s.client.SAdd(ctx, "available_drivers", driverID).Err()
+	return s.client.SAdd(ctx, s.keys.AvailableDrivers(), driverID).Err()
 }
 
 // RemoveAvailableDriver removes a driver from the available set.
 func (s *CacheStore) RemoveAvailableDriver(ctx context.Context, driverID string) error {
-	return s.client.SRem(ctx, "available_drivers", driverID).Err()
+	return s.client.SRem(ctx, s.keys.AvailableDrivers(), driverID).Err()
 }
 
 // IsDriverAvailable checks if a driver is in the available set.
 func (s *CacheStore) IsDriverAvailable(ctx context.Context, driverID string) (bool, error) {
-	return s.client.SIsMember(ctx, "available_drivers", driverID).Result()
+	return s.client.SIsMember(ctx, s.keys.AvailableDrivers(), driverID).Result()
 }
 
 // GetAvailableDrivers returns all available driver IDs.
 func (s *CacheStore) GetAvailableDrivers(ctx context.Context) ([]string, error) {
-	return s.client.SMembers(ctx, "available_drivers").Result()
+	return s.client.SMembers(ctx, s.keys.AvailableDrivers()).Result()
 }
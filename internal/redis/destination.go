@@ -0,0 +1,70 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"ride/internal/redis/keyspace"
+)
+
+// DriverDestination is where a driver in destination mode is heading, e.g.
+// home at the end of a shift.
+type DriverDestination struct {
+	Lat       float64   `json:"lat"`
+	Lng       float64   `json:"lng"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// DestinationStore holds drivers' destinations until they expire or the
+// driver is matched.
+type DestinationStore struct {
+	client *redis.Client
+	keys   keyspace.Keyspace
+}
+
+// NewDestinationStore creates a new DestinationStore.
+func NewDestinationStore(client *redis.Client, prefix string) *DestinationStore {
+	return &DestinationStore{client: client, keys: keyspace.New(prefix)}
+}
+
+// SetDestination stores the driver's destination until its ExpiresAt.
+func (s *DestinationStore) SetDestination(ctx context.Context, driverID string, d DriverDestination) error {
+	key := s.keys.DriverDestination(driverID)
+
+	data, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+
+	return s.client.Set(ctx, key, data, time.Until(d.ExpiresAt)).Err()
+}
+
+// GetDestination returns the driver's destination, or nil if the driver is
+// not in destination mode.
+func (s *DestinationStore) GetDestination(ctx context.Context, driverID string) (*DriverDestination, error) {
+	key := s.keys.DriverDestination(driverID)
+
+	data, err := s.client.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var d DriverDestination
+	if err := json.Unmarshal(data, &d); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// ClearDestination takes the driver out of destination mode.
+func (s *DestinationStore) ClearDestination(ctx context.Context, driverID string) error {
+	key := s.keys.DriverDestination(driverID)
+
+	return s.client.Del(ctx, key).Err()
+}
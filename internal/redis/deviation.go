@@ -0,0 +1,46 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"ride/internal/redis/keyspace"
+)
+
+// deviationCounterTTL bounds how long an abandoned trip's counter lingers.
+const deviationCounterTTL = time.Hour
+
+// DeviationStore counts consecutive off-route location pings per trip.
+type DeviationStore struct {
+	client *redis.Client
+	keys   keyspace.Keyspace
+}
+
+// NewDeviationStore creates a new DeviationStore.
+func NewDeviationStore(client *redis.Client, prefix string) *DeviationStore {
+	return &DeviationStore{client: client, keys: keyspace.New(prefix)}
+}
+
+// IncrementOffRoute records an off-route ping and returns the number of
+// consecutive off-route pings for the trip, including this one.
+func (s *DeviationStore) IncrementOffRoute(ctx context.Context, tripID string) (int64, error) {
+	key := s.keys.TripDeviation(tripID)
+
+	pipe := s.client.TxPipeline()
+	incr := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, deviationCounterTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+
+	return incr.Val(), nil
+}
+
+// ResetOffRoute clears the trip's consecutive off-route count.
+func (s *DeviationStore) ResetOffRoute(ctx context.Context, tripID string) error {
+	key := s.keys.TripDeviation(tripID)
+
+	return s.client.Del(ctx, key).Err()
+}
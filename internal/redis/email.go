@@ -0,0 +1,69 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"ride/internal/redis/keyspace"
+)
+
+// EmailVerification is an email address awaiting confirmation by its owner.
+type EmailVerification struct {
+	UserID string `json:"user_id"`
+	Email  string `json:"email"`
+}
+
+// EmailTokenStore holds single-use email verification tokens and the resend
+// cooldown per user.
+type EmailTokenStore struct {
+	client *redis.Client
+	keys   keyspace.Keyspace
+}
+
+// NewEmailTokenStore creates a new EmailTokenStore.
+func NewEmailTokenStore(client *redis.Client, prefix string) *EmailTokenStore {
+	return &EmailTokenStore{client: client, keys: keyspace.New(prefix)}
+}
+
+// SaveVerification stores a verification token that expires after ttl.
+func (s *EmailTokenStore) SaveVerification(ctx context.Context, token string, v EmailVerification, ttl time.Duration) error {
+	key := s.keys.EmailVerification(token)
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return s.client.Set(ctx, key, data, ttl).Err()
+}
+
+// ConsumeVerification returns and deletes the verification for a token, so
+// each token works once. Returns nil if the token is unknown, used or expired.
+func (s *EmailTokenStore) ConsumeVerification(ctx context.Context, token string) (*EmailVerification, error) {
+	key := s.keys.EmailVerification(token)
+
+	data, err := s.client.GetDel(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var v EmailVerification
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// AcquireResendSlot reports whether a verification email may be sent to the
+// user now, starting a cooldown if so.
+func (s *EmailTokenStore) AcquireResendSlot(ctx context.Context, userID string, cooldown time.Duration) (bool, error) {
+	key := s.keys.EmailResend(userID)
+
+	return s.client.SetNX(ctx, key, "1", cooldown).Result()
+}
@@ -0,0 +1,28 @@
+package redis
+
+import (
+	"errors"
+	"io"
+	"net"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// errPoolTimeout matches go-redis's unexported connection pool timeout error.
+const errPoolTimeout = "redis: connection pool timeout"
+
+// IsUnavailable reports whether err means Redis could not be reached, as
+// opposed to Redis rejecting a command.
+func IsUnavailable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, redis.ErrClosed) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	if err.Error() == errPoolTimeout {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -0,0 +1,45 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"ride/internal/redis/keyspace"
+)
+
+// ExclusionStore keeps, per ride, the drivers it must never be matched to.
+type ExclusionStore struct {
+	client *redis.Client
+	keys   keyspace.Keyspace
+}
+
+// NewExclusionStore creates a new ExclusionStore.
+func NewExclusionStore(client *redis.Client, prefix string) *ExclusionStore {
+	return &ExclusionStore{client: client, keys: keyspace.New(prefix)}
+}
+
+// AddExcludedDrivers adds driverIDs to the ride's exclusion set and keeps
+// the whole set for ttl from now.
+func (s *ExclusionStore) AddExcludedDrivers(ctx context.Context, rideID string, driverIDs []string, ttl time.Duration) error {
+	if len(driverIDs) == 0 {
+		return nil
+	}
+	members := make([]any, len(driverIDs))
+	for i, id := range driverIDs {
+		members[i] = id
+	}
+
+	key := s.keys.RideExclusion(rideID)
+	pipe := s.client.TxPipeline()
+	pipe.SAdd(ctx, key, members...)
+	pipe.Expire(ctx, key, ttl)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// ExcludedDrivers returns the drivers the ride must not be matched to.
+func (s *ExclusionStore) ExcludedDrivers(ctx context.Context, rideID string) ([]string, error) {
+	return s.client.SMembers(ctx, s.keys.RideExclusion(rideID)).Result()
+}
@@ -7,19 +7,121 @@ import (
 
 // LocationStoreInterface defines the interface for driver location operations.
 type LocationStoreInterface interface {
-	UpdateLocation(ctx context.Context, driverID string, lat, lng float64) error
-	FindNearbyDrivers(ctx context.Context, lat, lng, radiusKm float64) ([]DriverLocation, error)
+	UpdateLocation(ctx context.Context, driverID string, lat, lng, heading float64) error
+	FindNearbyDrivers(ctx context.Context, lat, lng, radiusKm float64, limit int) ([]DriverLocation, error)
+	FindInBox(ctx context.Context, minLat, minLng, maxLat, maxLng float64, limit int) ([]DriverLocation, error)
+	GetLocation(ctx context.Context, driverID string) (*DriverLocation, error)
 	RemoveLocation(ctx context.Context, driverID string) error
+	RemoveStale(ctx context.Context, cutoff time.Time) ([]string, error)
 }
 
 // LockStoreInterface defines the interface for distributed locking.
 type LockStoreInterface interface {
 	AcquireDriverLock(ctx context.Context, driverID string, ttl time.Duration) (bool, error)
 	ReleaseDriverLock(ctx context.Context, driverID string) error
+	IsDriverLocked(ctx context.Context, driverID string) (bool, error)
+}
+
+// DeviationStoreInterface defines the interface for route deviation debouncing.
+type DeviationStoreInterface interface {
+	IncrementOffRoute(ctx context.Context, tripID string) (int64, error)
+	ResetOffRoute(ctx context.Context, tripID string) error
+}
+
+// ArrivalStoreInterface defines the interface for driver arrival debouncing.
+type ArrivalStoreInterface interface {
+	IncrementInFence(ctx context.Context, rideID string) (int64, error)
+	ResetInFence(ctx context.Context, rideID string) error
+}
+
+// EmailTokenStoreInterface defines the interface for email verification tokens.
+type EmailTokenStoreInterface interface {
+	SaveVerification(ctx context.Context, token string, v EmailVerification, ttl time.Duration) error
+	ConsumeVerification(ctx context.Context, token string) (*EmailVerification, error)
+	AcquireResendSlot(ctx context.Context, userID string, cooldown time.Duration) (bool, error)
+}
+
+// QuoteStoreInterface defines the interface for ride quote storage.
+type QuoteStoreInterface interface {
+	SaveQuote(ctx context.Context, id string, q RideQuote, ttl time.Duration) error
+	ConsumeQuote(ctx context.Context, id string) (*RideQuote, error)
+}
+
+// SurgeStoreInterface defines the interface for smoothed surge storage.
+type SurgeStoreInterface interface {
+	GetMultiplier(ctx context.Context, cell string) (float64, bool, error)
+	SetMultiplier(ctx context.Context, cell string, multiplier float64, ttl time.Duration) error
+}
+
+// ExclusionStoreInterface defines the interface for per-ride driver exclusions.
+type ExclusionStoreInterface interface {
+	AddExcludedDrivers(ctx context.Context, rideID string, driverIDs []string, ttl time.Duration) error
+	ExcludedDrivers(ctx context.Context, rideID string) ([]string, error)
+}
+
+// NotificationBrokerInterface defines the interface for live notification fan-out.
+type NotificationBrokerInterface interface {
+	Publish(ctx context.Context, recipientID string, payload []byte) error
+	Subscribe(ctx context.Context, recipientID string) (<-chan []byte, func(), error)
+}
+
+// NotificationThrottleStoreInterface defines the interface for per-recipient notification cooldowns.
+type NotificationThrottleStoreInterface interface {
+	AcquireNotifySlot(ctx context.Context, recipientID, notificationType string, cooldown time.Duration) (bool, error)
+}
+
+// DestinationStoreInterface defines the interface for driver destination mode.
+type DestinationStoreInterface interface {
+	SetDestination(ctx context.Context, driverID string, d DriverDestination) error
+	GetDestination(ctx context.Context, driverID string) (*DriverDestination, error)
+	ClearDestination(ctx context.Context, driverID string) error
+}
+
+// LocationGuardStoreInterface defines the interface for the driver location speed guard.
+type LocationGuardStoreInterface interface {
+	LastFix(ctx context.Context, driverID string) (*LocationFix, error)
+	SetLastFix(ctx context.Context, driverID string, fix LocationFix) error
+	IncrementSpeedAnomalies(ctx context.Context, driverID string) (int64, error)
+	SpeedAnomalies(ctx context.Context, driverID string) (int64, error)
+	ListSpeedAnomalies(ctx context.Context, limit int) ([]SpeedAnomalyCount, error)
+	ClearSpeedAnomalies(ctx context.Context, driverID string) (bool, error)
+}
+
+// TrackerNonceStoreInterface defines the interface for signed tracker request replay protection.
+type TrackerNonceStoreInterface interface {
+	ClaimNonce(ctx context.Context, driverID, nonce string, ttl time.Duration) (bool, error)
+}
+
+// TripETAStoreInterface defines the interface for freezing paused trips' ETAs.
+type TripETAStoreInterface interface {
+	FrozenRemaining(ctx context.Context, tripID string, pausedAt time.Time) (float64, bool, error)
+	FreezeRemaining(ctx context.Context, tripID string, pausedAt time.Time, remainingKm float64, ttl time.Duration) error
+}
+
+// RideQueueStoreInterface defines the interface for the per-area queues of rides waiting for a driver.
+type RideQueueStoreInterface interface {
+	Enqueue(ctx context.Context, area, rideID string, score float64) error
+	Remove(ctx context.Context, rideID string) (string, error)
+	Position(ctx context.Context, rideID string) (string, int64, bool, error)
+	RecordMatch(ctx context.Context, area, rideID string, at time.Time, window time.Duration) error
+	MatchesSince(ctx context.Context, area string, since time.Time) (int64, error)
 }
 
 // Ensure concrete types implement interfaces.
 var (
-	_ LocationStoreInterface = (*LocationStore)(nil)
-	_ LockStoreInterface     = (*LockStore)(nil)
+	_ LocationStoreInterface             = (*LocationStore)(nil)
+	_ LockStoreInterface                 = (*LockStore)(nil)
+	_ NotificationBrokerInterface        = (*NotificationBroker)(nil)
+	_ DeviationStoreInterface            = (*DeviationStore)(nil)
+	_ ArrivalStoreInterface              = (*ArrivalStore)(nil)
+	_ EmailTokenStoreInterface           = (*EmailTokenStore)(nil)
+	_ QuoteStoreInterface                = (*QuoteStore)(nil)
+	_ SurgeStoreInterface                = (*SurgeStore)(nil)
+	_ ExclusionStoreInterface            = (*ExclusionStore)(nil)
+	_ NotificationThrottleStoreInterface = (*NotificationThrottleStore)(nil)
+	_ DestinationStoreInterface          = (*DestinationStore)(nil)
+	_ RideQueueStoreInterface            = (*RideQueueStore)(nil)
+	_ LocationGuardStoreInterface        = (*LocationGuardStore)(nil)
+	_ TrackerNonceStoreInterface         = (*TrackerNonceStore)(nil)
+	_ TripETAStoreInterface              = (*TripETAStore)(nil)
 )
@@ -0,0 +1,116 @@
+package redis
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"ride/internal/redis/keyspace"
+)
+
+// auditScanCount is the COUNT hint of each SCAN the audit issues.
+const auditScanCount = 1000
+
+// auditUnknownSample caps how many unknown keys an audit lists.
+const auditUnknownSample = 20
+
+// noExpiry is the TTL go-redis reports for a key without an expiry; a key
+// deleted since the scan reads as -2.
+const noExpiry = time.Duration(-1)
+
+// KeyAuditor counts the keys under the app prefix by namespace, to spot key
+// families that grow without bound.
+type KeyAuditor struct {
+	client *redis.Client
+	keys   keyspace.Keyspace
+}
+
+// NewKeyAuditor creates a new KeyAuditor.
+func NewKeyAuditor(client *redis.Client, prefix string) *KeyAuditor {
+	return &KeyAuditor{client: client, keys: keyspace.New(prefix)}
+}
+
+// NamespaceUsage is the number of keys in one namespace.
+type NamespaceUsage struct {
+	Namespace  string
+	Keys       int64
+	WithoutTTL int64  // Keys that never expire; only expected where Cleanup is not a TTL
+	Cleanup    string // How the namespace's keys leave Redis
+}
+
+// KeyAudit is the result of an audit.
+type KeyAudit struct {
+	Prefix        string
+	Scanned       int64
+	Namespaces    []NamespaceUsage // In keyspace.Namespaces order, empty ones included
+	Unknown       int64            // Keys under the prefix in no known namespace
+	UnknownSample []string
+}
+
+// Audit walks every key under the prefix with SCAN, so it never blocks the
+// server the way KEYS would, reading each batch's TTLs in one pipeline.
+func (a *KeyAuditor) Audit(ctx context.Context) (*KeyAudit, error) {
+	audit := &KeyAudit{Prefix: a.keys.Prefix()}
+	index := make(map[string]int, len(keyspace.Namespaces))
+	for i, ns := range keyspace.Namespaces {
+		audit.Namespaces = append(audit.Namespaces, NamespaceUsage{Namespace: ns.Name, Cleanup: ns.Cleanup})
+		index[ns.Name] = i
+	}
+
+	var cursor uint64
+	for {
+		batch, next, err := a.client.Scan(ctx, cursor, escapeGlob(a.keys.Prefix())+"*", auditScanCount).Result()
+		if err != nil {
+			return nil, err
+		}
+
+		ttls := make([]*redis.DurationCmd, len(batch))
+		if len(batch) > 0 {
+			if _, err := a.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+				for i, key := range batch {
+					ttls[i] = pipe.TTL(ctx, key)
+				}
+				return nil
+			}); err != nil {
+				return nil, err
+			}
+		}
+
+		for i, key := range batch {
+			audit.Scanned++
+			name, ok := a.keys.Namespace(key)
+			if !ok {
+				audit.Unknown++
+				if len(audit.UnknownSample) < auditUnknownSample {
+					audit.UnknownSample = append(audit.UnknownSample, key)
+				}
+				continue
+			}
+			usage := &audit.Namespaces[index[name]]
+			usage.Keys++
+			if ttls[i].Val() == noExpiry {
+				usage.WithoutTTL++
+			}
+		}
+
+		cursor = next
+		if cursor == 0 {
+			return audit, nil
+		}
+	}
+}
+
+// escapeGlob escapes the characters SCAN MATCH treats as a pattern.
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,186 @@
+// Package keyspace builds every Redis key the service reads or writes, so
+// that all of them share one app-level prefix and belong to a known
+// namespace. Stores and middleware must not assemble keys themselves.
+package keyspace
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Namespace is a family of keys, e.g. every cached driver.
+type Namespace struct {
+	Name    string // Key prefix after the app prefix, e.g. "cache:driver:"
+	Cleanup string // How its keys leave Redis: a TTL, or what removes them
+}
+
+// Namespaces lists every key family, so the audit can tell the service's
+// keys apart from strays. Each write either sets a TTL or has the cleanup
+// path given here. Notification channels are pub/sub, not keys, and are
+// not listed.
+var Namespaces = []Namespace{
+	{Name: "drivers:locations", Cleanup: "location sweeper removes drivers without a recent ping; going offline removes it"},
+	{Name: "drivers:headings", Cleanup: "location sweeper; going offline"},
+	{Name: "drivers:regions", Cleanup: "location sweeper; going offline"},
+	{Name: "drivers:heartbeats", Cleanup: "location sweeper; going offline"},
+	{Name: "drivers:available", Cleanup: "location sweeper; going offline, on a break or matched"},
+	{Name: "drivers:speed_anomalies", Cleanup: "an admin clearing a driver's location review removes them"},
+	{Name: "driver:destination:", Cleanup: "TTL: destination mode expiry"},
+	{Name: "driver:lastfix:", Cleanup: "TTL: 24h"},
+	{Name: "lock:driver:", Cleanup: "TTL: lock timeout"},
+	{Name: "lock:ride:", Cleanup: "TTL: lock timeout"},
+	{Name: "cache:driver:", Cleanup: "TTL: 30s"},
+	{Name: "cache:ride:", Cleanup: "TTL: 10s"},
+	{Name: "cache:trip:", Cleanup: "TTL: 60s"},
+	{Name: "ride:arrival:", Cleanup: "TTL: 1h"},
+	{Name: "ride:excluded:", Cleanup: "TTL: MATCHING_EXCLUSION_TTL"},
+	{Name: "ride:queue:", Cleanup: "rides leave when matched or cancelled; an empty queue is deleted"},
+	{Name: "ride:queued", Cleanup: "rides leave when matched or cancelled"},
+	{Name: "ride:matched:", Cleanup: "TTL: MATCHING_QUEUE_THROUGHPUT_WINDOW"},
+	{Name: "trip:deviation:", Cleanup: "TTL: 1h"},
+	{Name: "email:verify:", Cleanup: "TTL: EMAIL_VERIFICATION_TTL"},
+	{Name: "email:resend:", Cleanup: "TTL: EMAIL_RESEND_COOLDOWN"},
+	{Name: "notify:throttle:", Cleanup: "TTL: notification cooldown"},
+	{Name: "quote:", Cleanup: "TTL: RIDE_QUOTE_TTL"},
+	{Name: "tracker:nonce:", Cleanup: "TTL: twice TRACKER_AUTH_MAX_SKEW"},
+	{Name: "trip:eta:", Cleanup: "TTL: 24h"},
+	{Name: "surge:cell:", Cleanup: "TTL: SURGE_SMOOTHING_TTL"},
+	{Name: "idempotency:", Cleanup: "TTL: 24h"},
+}
+
+// Keyspace builds keys under an app-level prefix, e.g. "ride:" or
+// "ride:staging:" when deployments share an instance.
+type Keyspace struct {
+	prefix string
+}
+
+// New returns a Keyspace that prepends prefix to every key.
+func New(prefix string) Keyspace {
+	return Keyspace{prefix: prefix}
+}
+
+// Prefix returns the app-level prefix.
+func (k Keyspace) Prefix() string {
+	return k.prefix
+}
+
+// Namespace returns the namespace a key of this keyspace belongs to, and
+// false for keys under another prefix or in no known namespace.
+func (k Keyspace) Namespace(key string) (string, bool) {
+	rest, ok := strings.CutPrefix(key, k.prefix)
+	if !ok {
+		return "", false
+	}
+	for _, ns := range Namespaces {
+		if rest == ns.Name || strings.HasPrefix(rest, ns.Name) {
+			return ns.Name, true
+		}
+	}
+	return "", false
+}
+
+// DriverLocations is the geo index of drivers in a region, "" meaning
+// drivers outside every region.
+func (k Keyspace) DriverLocations(region string) string {
+	if region == "" {
+		return k.prefix + "drivers:locations"
+	}
+	return k.prefix + "drivers:locations:" + region
+}
+
+// DriverHeadings is the hash of driver ID to heading.
+func (k Keyspace) DriverHeadings() string { return k.prefix + "drivers:headings" }
+
+// DriverRegions is the hash of driver ID to the region whose index holds them.
+func (k Keyspace) DriverRegions() string { return k.prefix + "drivers:regions" }
+
+// DriverHeartbeats is the sorted set of driver ID by the Unix time of their
+// latest location ping.
+func (k Keyspace) DriverHeartbeats() string { return k.prefix + "drivers:heartbeats" }
+
+// AvailableDrivers is the set of drivers available for offers.
+func (k Keyspace) AvailableDrivers() string { return k.prefix + "drivers:available" }
+
+// DriverDestination is a driver's destination-mode target.
+func (k Keyspace) DriverDestination(driverID string) string {
+	return k.prefix + "driver:destination:" + driverID
+}
+
+// DriverLastFix is the last location a driver's speed was checked from.
+func (k Keyspace) DriverLastFix(driverID string) string {
+	return k.prefix + "driver:lastfix:" + driverID
+}
+
+// DriverSpeedAnomalies is the sorted set of drivers by their count of
+// location updates rejected for implausible speed.
+func (k Keyspace) DriverSpeedAnomalies() string { return k.prefix + "drivers:speed_anomalies" }
+
+// DriverLock is the assignment lock of a driver.
+func (k Keyspace) DriverLock(driverID string) string { return k.prefix + "lock:driver:" + driverID }
+
+// RideLock is the matching lock of a ride.
+func (k Keyspace) RideLock(rideID string) string { return k.prefix + "lock:ride:" + rideID }
+
+// DriverCache is a cached driver.
+func (k Keyspace) DriverCache(driverID string) string { return k.prefix + "cache:driver:" + driverID }
+
+// RideCache is a cached ride.
+func (k Keyspace) RideCache(rideID string) string { return k.prefix + "cache:ride:" + rideID }
+
+// TripCache is a cached trip.
+func (k Keyspace) TripCache(tripID string) string { return k.prefix + "cache:trip:" + tripID }
+
+// RideArrival counts a ride's driver's in-fence pings.
+func (k Keyspace) RideArrival(rideID string) string { return k.prefix + "ride:arrival:" + rideID }
+
+// RideExclusion is the set of drivers a ride must not be matched to.
+func (k Keyspace) RideExclusion(rideID string) string { return k.prefix + "ride:excluded:" + rideID }
+
+// RideQueue is an area's sorted set of rides waiting for a driver, in the
+// order they will be matched.
+func (k Keyspace) RideQueue(area string) string { return k.prefix + "ride:queue:" + area }
+
+// RideQueueAreas is the hash of queued ride ID to the area it is queued in.
+func (k Keyspace) RideQueueAreas() string { return k.prefix + "ride:queued" }
+
+// RideQueueMatches is an area's sorted set of recently matched queued rides
+// by match time.
+func (k Keyspace) RideQueueMatches(area string) string { return k.prefix + "ride:matched:" + area }
+
+// TripDeviation counts a trip's off-route pings.
+func (k Keyspace) TripDeviation(tripID string) string { return k.prefix + "trip:deviation:" + tripID }
+
+// EmailVerification is a pending email verification token.
+func (k Keyspace) EmailVerification(token string) string { return k.prefix + "email:verify:" + token }
+
+// EmailResend is a user's verification resend cooldown.
+func (k Keyspace) EmailResend(userID string) string { return k.prefix + "email:resend:" + userID }
+
+// NotifyThrottle is a recipient's cooldown for one notification type.
+func (k Keyspace) NotifyThrottle(notificationType, recipientID string) string {
+	return k.prefix + fmt.Sprintf("notify:throttle:%s:%s", notificationType, recipientID)
+}
+
+// Quote is an issued ride quote.
+func (k Keyspace) Quote(id string) string { return k.prefix + "quote:" + id }
+
+// TrackerNonce marks a nonce a driver's tracker has signed a request with.
+func (k Keyspace) TrackerNonce(driverID, nonce string) string {
+	return k.prefix + "tracker:nonce:" + driverID + ":" + nonce
+}
+
+// TripETA is a paused trip's remaining distance, frozen for one pause.
+func (k Keyspace) TripETA(tripID string, pausedAt int64) string {
+	return k.prefix + fmt.Sprintf("trip:eta:%s:%d", tripID, pausedAt)
+}
+
+// SurgeCell is a cell's smoothed surge multiplier.
+func (k Keyspace) SurgeCell(cell string) string { return k.prefix + "surge:cell:" + cell }
+
+// Idempotency is a cached response to an idempotent request.
+func (k Keyspace) Idempotency(key string) string { return k.prefix + "idempotency:" + key }
+
+// Notifications is the pub/sub channel of a recipient's live notifications.
+func (k Keyspace) Notifications(recipientID string) string {
+	return k.prefix + "notifications:" + recipientID
+}
@@ -2,44 +2,118 @@ package redis
 
 import (
 	"context"
+	"math"
+	"sort"
+	"strconv"
+	"time"
 
 	"github.com/redis/go-redis/v9"
+
+	"ride/internal/redis/keyspace"
 )
 
-const driverLocationKey = "drivers:locations"
+// kmPerDegreeLat is the length of one degree of latitude on the mean Earth sphere.
+const kmPerDegreeLat = 6371.0 * math.Pi / 180
+
+// GeoRegion is a service region, such as a city. Drivers inside it are kept
+// in the region's own geo index, so searches from a point in the region
+// only ever see the region's drivers.
+type GeoRegion struct {
+	Name   string
+	MinLat float64
+	MinLng float64
+	MaxLat float64
+	MaxLng float64
+}
+
+// Contains reports whether the point lies inside the region, edges included.
+func (r GeoRegion) Contains(lat, lng float64) bool {
+	return lat >= r.MinLat && lat <= r.MaxLat && lng >= r.MinLng && lng <= r.MaxLng
+}
+
+// overlaps reports whether the region and the box share any point.
+func (r GeoRegion) overlaps(minLat, minLng, maxLat, maxLng float64) bool {
+	return r.MinLat <= maxLat && minLat <= r.MaxLat && r.MinLng <= maxLng && minLng <= r.MaxLng
+}
 
 // DriverLocation represents a driver's position.
 type DriverLocation struct {
 	DriverID string
 	Lat      float64
 	Lng      float64
+	Heading  float64 // Compass bearing in degrees, 0 = north
 }
 
 // LocationStore handles driver location operations in Redis.
 type LocationStore struct {
-	client *redis.Client
+	client  *redis.Client
+	keys    keyspace.Keyspace
+	regions []GeoRegion // Checked in order; the first containing a point is its region
+}
+
+// NewLocationStore creates a new LocationStore. Without regions every driver
+// shares one geo index.
+func NewLocationStore(client *redis.Client, prefix string, regions []GeoRegion) *LocationStore {
+	return &LocationStore{client: client, keys: keyspace.New(prefix), regions: regions}
+}
+
+// regionOf returns the name of the region containing the point, or "" if
+// it lies outside every region.
+func (s *LocationStore) regionOf(lat, lng float64) string {
+	for _, r := range s.regions {
+		if r.Contains(lat, lng) {
+			return r.Name
+		}
+	}
+	return ""
 }
 
-// NewLocationStore creates a new LocationStore.
-func NewLocationStore(client *redis.Client) *LocationStore {
-	return &LocationStore{client: client}
+// locationKey returns the geo index key for a region, "" meaning drivers
+// outside every region.
+func (s *LocationStore) locationKey(region string) string {
+	return s.keys.DriverLocations(region)
 }
 
-// UpdateLocation stores a driver's location using GEOADD.
-func (s *LocationStore) UpdateLocation(ctx context.Context, driverID string, lat, lng float64) error {
-	return s.client.GeoAdd(ctx, driverLocationKey, &redis.GeoLocation{
-		Name:      driverID,
-		Longitude: lng,
-		Latitude:  lat,
-	}).Err()
+// UpdateLocation stores a driver's location using GEOADD in their region's
+// index, their heading in the companion hash and the time of the ping in
+// the heartbeat set, atomically. A driver who crossed into another region
+// is removed from the other indexes.
+func (s *LocationStore) UpdateLocation(ctx context.Context, driverID string, lat, lng, heading float64) error {
+	region := s.regionOf(lat, lng)
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.GeoAdd(ctx, s.locationKey(region), &redis.GeoLocation{
+			Name:      driverID,
+			Longitude: lng,
+			Latitude:  lat,
+		})
+		pipe.HSet(ctx, s.keys.DriverHeadings(), driverID, heading)
+		pipe.ZAdd(ctx, s.keys.DriverHeartbeats(), redis.Z{Score: float64(time.Now().Unix()), Member: driverID})
+		if len(s.regions) > 0 {
+			if region != "" {
+				pipe.ZRem(ctx, s.locationKey(""), driverID)
+			}
+			for _, r := range s.regions {
+				if r.Name != region {
+					pipe.ZRem(ctx, s.locationKey(r.Name), driverID)
+				}
+			}
+			pipe.HSet(ctx, s.keys.DriverRegions(), driverID, region)
+		}
+		return nil
+	})
+	return err
 }
 
-// FindNearbyDrivers returns driver IDs within the given radius (in kilometers).
-func (s *LocationStore) FindNearbyDrivers(ctx context.Context, lat, lng, radiusKm float64) ([]DriverLocation, error) {
-	results, err := s.client.GeoRadius(ctx, driverLocationKey, lng, lat, &redis.GeoRadiusQuery{
+// FindNearbyDrivers returns drivers within the given radius (in kilometers),
+// nearest first. Only the index of the point's region is searched, so
+// drivers in other regions are never returned. A positive limit caps the
+// result server-side with COUNT.
+func (s *LocationStore) FindNearbyDrivers(ctx context.Context, lat, lng, radiusKm float64, limit int) ([]DriverLocation, error) {
+	results, err := s.client.GeoRadius(ctx, s.locationKey(s.regionOf(lat, lng)), lng, lat, &redis.GeoRadiusQuery{
 		Radius:    radiusKm,
 		Unit:      "km",
 		WithCoord: true,
+		Count:     limit,
 		Sort:      "ASC",
 	}).Result()
 	if err != nil {
@@ -47,18 +121,204 @@ func (s *LocationStore) FindNearbyDrivers(ctx context.Context, lat, lng, radiusK
 	}
 
 	locations := make([]DriverLocation, 0, len(results))
+	driverIDs := make([]string, 0, len(results))
 	for _, r := range results {
 		locations = append(locations, DriverLocation{
 			DriverID: r.Name,
 			Lat:      r.Latitude,
 			Lng:      r.Longitude,
 		})
+		driverIDs = append(driverIDs, r.Name)
+	}
+
+	if len(driverIDs) == 0 {
+		return locations, nil
+	}
+
+	headings, err := s.client.HMGet(ctx, s.keys.DriverHeadings(), driverIDs...).Result()
+	if err != nil {
+		return nil, err
+	}
+	for i, h := range headings {
+		locations[i].Heading = parseHeading(h)
 	}
 
 	return locations, nil
 }
 
-// RemoveLocation removes a driver's location from the geo index.
+// FindInBox returns drivers inside the latitude/longitude box, nearest to its
+// centre first, from every region the box overlaps. A positive limit caps
+// the result.
+//
+// GEOSEARCH BYBOX measures the box in kilometres around its centre, so the
+// search box is sized to cover the requested one at the latitude nearest the
+// equator and the results are then trimmed to the exact bounds.
+func (s *LocationStore) FindInBox(ctx context.Context, minLat, minLng, maxLat, maxLng float64, limit int) ([]DriverLocation, error) {
+	widestLat := math.Min(math.Abs(minLat), math.Abs(maxLat))
+	if minLat < 0 && maxLat > 0 {
+		widestLat = 0
+	}
+	widthKm := (maxLng - minLng) * kmPerDegreeLat * math.Cos(widestLat*math.Pi/180)
+	heightKm := (maxLat - minLat) * kmPerDegreeLat
+
+	keys := []string{s.locationKey("")}
+	for _, r := range s.regions {
+		if r.overlaps(minLat, minLng, maxLat, maxLng) {
+			keys = append(keys, s.locationKey(r.Name))
+		}
+	}
+
+	// One round trip for every region's search.
+	cmds := make([]*redis.GeoSearchLocationCmd, len(keys))
+	if _, err := s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pipe.GeoSearchLocation(ctx, key, &redis.GeoSearchLocationQuery{
+				GeoSearchQuery: redis.GeoSearchQuery{
+					Longitude: (minLng + maxLng) / 2,
+					Latitude:  (minLat + maxLat) / 2,
+					BoxWidth:  widthKm,
+					BoxHeight: heightKm,
+					BoxUnit:   "km",
+					Sort:      "ASC",
+					Count:     limit,
+				},
+				WithCoord: true,
+				WithDist:  true,
+			})
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	var results []redis.GeoLocation
+	for _, cmd := range cmds {
+		results = append(results, cmd.Val()...)
+	}
+	if len(keys) > 1 {
+		sort.SliceStable(results, func(i, j int) bool { return results[i].Dist < results[j].Dist })
+		if limit > 0 && len(results) > limit {
+			results = results[:limit]
+		}
+	}
+
+	locations := make([]DriverLocation, 0, len(results))
+	driverIDs := make([]string, 0, len(results))
+	for _, r := range results {
+		if r.Latitude < minLat || r.Latitude > maxLat || r.Longitude < minLng || r.Longitude > maxLng {
+			continue
+		}
+		locations = append(locations, DriverLocation{
+			DriverID: r.Name,
+			Lat:      r.Latitude,
+			Lng:      r.Longitude,
+		})
+		driverIDs = append(driverIDs, r.Name)
+	}
+
+	if len(driverIDs) == 0 {
+		return locations, nil
+	}
+
+	headings, err := s.client.HMGet(ctx, s.keys.DriverHeadings(), driverIDs...).Result()
+	if err != nil {
+		return nil, err
+	}
+	for i, h := range headings {
+		locations[i].Heading = parseHeading(h)
+	}
+
+	return locations, nil
+}
+
+// GetLocation returns a driver's last known position, or nil if the driver
+// is not in the geo index.
+func (s *LocationStore) GetLocation(ctx context.Context, driverID string) (*DriverLocation, error) {
+	var region string
+	if len(s.regions) > 0 {
+		var err error
+		region, err = s.client.HGet(ctx, s.keys.DriverRegions(), driverID).Result()
+		if err != nil && err != redis.Nil {
+			return nil, err
+		}
+	}
+
+	positions, err := s.client.GeoPos(ctx, s.locationKey(region), driverID).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(positions) == 0 || positions[0] == nil {
+		return nil, nil
+	}
+
+	heading, err := s.client.HGet(ctx, s.keys.DriverHeadings(), driverID).Result()
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	return &DriverLocation{
+		DriverID: driverID,
+		Lat:      positions[0].Latitude,
+		Lng:      positions[0].Longitude,
+		Heading:  parseHeading(heading),
+	}, nil
+}
+
+// RemoveLocation removes a driver's location from the geo indexes.
 func (s *LocationStore) RemoveLocation(ctx context.Context, driverID string) error {
-	return s.client.ZRem(ctx, driverLocationKey, driverID).Err()
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		s.remove(ctx, pipe, driverID)
+		return nil
+	})
+	return err
+}
+
+// RemoveStale removes every driver whose last location ping is older than
+// cutoff, so drivers who vanished without going offline do not linger in
+// the geo indexes, and returns their IDs.
+func (s *LocationStore) RemoveStale(ctx context.Context, cutoff time.Time) ([]string, error) {
+	driverIDs, err := s.client.ZRangeByScore(ctx, s.keys.DriverHeartbeats(), &redis.ZRangeBy{
+		Min: "-inf",
+		Max: "(" + strconv.FormatInt(cutoff.Unix(), 10),
+	}).Result()
+	if err != nil || len(driverIDs) == 0 {
+		return nil, err
+	}
+
+	if _, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, driverID := range driverIDs {
+			s.remove(ctx, pipe, driverID)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return driverIDs, nil
+}
+
+// remove queues the removal of a driver from every location key.
+func (s *LocationStore) remove(ctx context.Context, pipe redis.Pipeliner, driverID string) {
+	pipe.ZRem(ctx, s.locationKey(""), driverID)
+	pipe.HDel(ctx, s.keys.DriverHeadings(), driverID)
+	pipe.ZRem(ctx, s.keys.DriverHeartbeats(), driverID)
+	if len(s.regions) > 0 {
+		for _, r := range s.regions {
+			pipe.ZRem(ctx, s.locationKey(r.Name), driverID)
+		}
+		pipe.HDel(ctx, s.keys.DriverRegions(), driverID)
+	}
+}
+
+// parseHeading converts a stored heading hash value; missing or malformed
+// values read as 0.
+func parseHeading(value any) float64 {
+	s, ok := value.(string)
+	if !ok {
+		return 0
+	}
+	heading, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return heading
 }
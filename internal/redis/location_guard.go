@@ -0,0 +1,121 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"ride/internal/redis/keyspace"
+)
+
+// lastFixTTL bounds how long a silent driver's last fix lingers. A fix this
+// old implies no meaningful speed, so nothing is lost when it expires.
+const lastFixTTL = 24 * time.Hour
+
+// LocationFix is a driver position and when it was received.
+type LocationFix struct {
+	Lat float64   `json:"lat"`
+	Lng float64   `json:"lng"`
+	At  time.Time `json:"at"`
+}
+
+// SpeedAnomalyCount is a driver's count of location updates rejected for
+// implausible speed.
+type SpeedAnomalyCount struct {
+	DriverID string
+	Count    int64
+}
+
+// LocationGuardStore holds each driver's last accepted location fix and
+// counts their location updates rejected for implausible speed.
+type LocationGuardStore struct {
+	client *redis.Client
+	keys   keyspace.Keyspace
+}
+
+// NewLocationGuardStore creates a new LocationGuardStore.
+func NewLocationGuardStore(client *redis.Client, prefix string) *LocationGuardStore {
+	return &LocationGuardStore{client: client, keys: keyspace.New(prefix)}
+}
+
+// LastFix returns the driver's last accepted fix, or nil if there is none.
+func (s *LocationGuardStore) LastFix(ctx context.Context, driverID string) (*LocationFix, error) {
+	data, err := s.client.Get(ctx, s.keys.DriverLastFix(driverID)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var fix LocationFix
+	if err := json.Unmarshal(data, &fix); err != nil {
+		return nil, err
+	}
+	return &fix, nil
+}
+
+// SetLastFix replaces the driver's last accepted fix.
+func (s *LocationGuardStore) SetLastFix(ctx context.Context, driverID string, fix LocationFix) error {
+	data, err := json.Marshal(fix)
+	if err != nil {
+		return err
+	}
+
+	return s.client.Set(ctx, s.keys.DriverLastFix(driverID), data, lastFixTTL).Err()
+}
+
+// IncrementSpeedAnomalies records a rejected update and returns the
+// driver's count, including this one.
+func (s *LocationGuardStore) IncrementSpeedAnomalies(ctx context.Context, driverID string) (int64, error) {
+	count, err := s.client.ZIncrBy(ctx, s.keys.DriverSpeedAnomalies(), 1, driverID).Result()
+	if err != nil {
+		return 0, err
+	}
+	return int64(count), nil
+}
+
+// SpeedAnomalies returns the driver's count of rejected updates.
+func (s *LocationGuardStore) SpeedAnomalies(ctx context.Context, driverID string) (int64, error) {
+	count, err := s.client.ZScore(ctx, s.keys.DriverSpeedAnomalies(), driverID).Result()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return int64(count), nil
+}
+
+// ListSpeedAnomalies returns drivers with rejected updates, most first. A
+// positive limit caps the result.
+func (s *LocationGuardStore) ListSpeedAnomalies(ctx context.Context, limit int) ([]SpeedAnomalyCount, error) {
+	stop := int64(-1)
+	if limit > 0 {
+		stop = int64(limit) - 1
+	}
+
+	entries, err := s.client.ZRevRangeWithScores(ctx, s.keys.DriverSpeedAnomalies(), 0, stop).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]SpeedAnomalyCount, 0, len(entries))
+	for _, e := range entries {
+		driverID, _ := e.Member.(string)
+		counts = append(counts, SpeedAnomalyCount{DriverID: driverID, Count: int64(e.Score)})
+	}
+	return counts, nil
+}
+
+// ClearSpeedAnomalies resets the driver's count and reports whether they
+// had any.
+func (s *LocationGuardStore) ClearSpeedAnomalies(ctx context.Context, driverID string) (bool, error) {
+	removed, err := s.client.ZRem(ctx, s.keys.DriverSpeedAnomalies(), driverID).Result()
+	if err != nil {
+		return false, err
+	}
+	return removed > 0, nil
+}
@@ -2,26 +2,28 @@ package redis
 
 import (
 	"context"
-	"fmt"
 	"time"
 
 	"github.com/redis/go-redis/v9"
+
+	"ride/internal/redis/keyspace"
 )
 
 // LockStore handles distributed locking in Redis.
 type LockStore struct {
 	client *redis.Client
+	keys   keyspace.Keyspace
 }
 
 // NewLockStore creates a new LockStore.
-func NewLockStore(client *redis.Client) *LockStore {
-	return &LockStore{client: client}
+func NewLockStore(client *redis.Client, prefix string) *LockStore {
+	return &LockStore{client: client, keys: keyspace.New(prefix)}
 }
 
 // AcquireDriverLock attempts to acquire a lock for the given driver.
 // Returns true if the lock was acquired, false if already held.
 func (s *LockStore) AcquireDriverLock(ctx context.Context, driverID string, ttl time.Duration) (bool, error) {
-	key := fmt.Sprintf("lock:driver:%s", driverID)
+	key := s.keys.DriverLock(driverID)
 
 	ok, err := s.client.SetNX(ctx, key, "1", ttl).Result()
 	if err != nil {
@@ -33,7 +35,19 @@ func (s *LockStore) AcquireDriverLock(ctx context.Context, driverID string, ttl
 
 // ReleaseDriverLock releases the lock for the given driver.
 func (s *LockStore) ReleaseDriverLock(ctx context.Context, driverID string) error {
-	key := fmt.Sprintf("lock:driver:%s", driverID)
+	key := s.keys.DriverLock(driverID)
 
 	return s.client.Del(ctx, key).Err()
 }
+
+// IsDriverLocked reports whether a lock is currently held for the given driver.
+func (s *LockStore) IsDriverLocked(ctx context.Context, driverID string) (bool, error) {
+	key := s.keys.DriverLock(driverID)
+
+	n, err := s.client.Exists(ctx, key).Result()
+	if err != nil {
+		return false, err
+	}
+
+	return n > 0, nil
+}
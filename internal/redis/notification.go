@@ -0,0 +1,57 @@
+package redis
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+
+	"ride/internal/redis/keyspace"
+)
+
+// NotificationBroker fans notifications out to live subscribers using one
+// Redis pub/sub channel per recipient. Delivery is best-effort; the outbox
+// table is the durable record.
+type NotificationBroker struct {
+	client *redis.Client
+	keys   keyspace.Keyspace
+}
+
+// NewNotificationBroker creates a new NotificationBroker.
+func NewNotificationBroker(client *redis.Client, prefix string) *NotificationBroker {
+	return &NotificationBroker{client: client, keys: keyspace.New(prefix)}
+}
+
+func (b *NotificationBroker) channel(recipientID string) string {
+	return b.keys.Notifications(recipientID)
+}
+
+// Publish sends a payload to the recipient's channel.
+func (b *NotificationBroker) Publish(ctx context.Context, recipientID string, payload []byte) error {
+	return b.client.Publish(ctx, b.channel(recipientID), payload).Err()
+}
+
+// Subscribe listens on the recipient's channel until ctx is done or the
+// returned cancel func is called. The subscription is active on return.
+func (b *NotificationBroker) Subscribe(ctx context.Context, recipientID string) (<-chan []byte, func(), error) {
+	pubsub := b.client.Subscribe(ctx, b.channel(recipientID))
+
+	// Wait for the subscription confirmation so no publish is missed.
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return nil, nil, err
+	}
+
+	payloads := make(chan []byte)
+	go func() {
+		defer close(payloads)
+		for msg := range pubsub.Channel() {
+			select {
+			case payloads <- []byte(msg.Payload):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return payloads, func() { _ = pubsub.Close() }, nil
+}
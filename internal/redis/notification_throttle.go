@@ -0,0 +1,30 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"ride/internal/redis/keyspace"
+)
+
+// NotificationThrottleStore rate-limits notifications of one type to a
+// recipient.
+type NotificationThrottleStore struct {
+	client *redis.Client
+	keys   keyspace.Keyspace
+}
+
+// NewNotificationThrottleStore creates a new NotificationThrottleStore.
+func NewNotificationThrottleStore(client *redis.Client, prefix string) *NotificationThrottleStore {
+	return &NotificationThrottleStore{client: client, keys: keyspace.New(prefix)}
+}
+
+// AcquireNotifySlot reports whether a notification of the given type may be
+// sent to the recipient now, starting a cooldown if so.
+func (s *NotificationThrottleStore) AcquireNotifySlot(ctx context.Context, recipientID, notificationType string, cooldown time.Duration) (bool, error) {
+	key := s.keys.NotifyThrottle(notificationType, recipientID)
+
+	return s.client.SetNX(ctx, key, "1", cooldown).Result()
+}
@@ -0,0 +1,65 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"ride/internal/redis/keyspace"
+)
+
+// RideQuote is the pricing shown to a rider for a trip, held so that a ride
+// requested shortly afterwards is charged the same surge.
+type RideQuote struct {
+	PickupLat       float64   `json:"pickup_lat"`
+	PickupLng       float64   `json:"pickup_lng"`
+	DestinationLat  float64   `json:"destination_lat"`
+	DestinationLng  float64   `json:"destination_lng"`
+	SurgeMultiplier float64   `json:"surge_multiplier"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// QuoteStore holds ride quotes until they expire or are redeemed.
+type QuoteStore struct {
+	client *redis.Client
+	keys   keyspace.Keyspace
+}
+
+// NewQuoteStore creates a new QuoteStore.
+func NewQuoteStore(client *redis.Client, prefix string) *QuoteStore {
+	return &QuoteStore{client: client, keys: keyspace.New(prefix)}
+}
+
+// SaveQuote stores a quote that expires after ttl.
+func (s *QuoteStore) SaveQuote(ctx context.Context, id string, q RideQuote, ttl time.Duration) error {
+	key := s.keys.Quote(id)
+
+	data, err := json.Marshal(q)
+	if err != nil {
+		return err
+	}
+
+	return s.client.Set(ctx, key, data, ttl).Err()
+}
+
+// ConsumeQuote returns and deletes a quote, so each quote prices one ride.
+// Returns nil if the quote is unknown, used or expired.
+func (s *QuoteStore) ConsumeQuote(ctx context.Context, id string) (*RideQuote, error) {
+	key := s.keys.Quote(id)
+
+	data, err := s.client.GetDel(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var q RideQuote
+	if err := json.Unmarshal(data, &q); err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
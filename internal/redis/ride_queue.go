@@ -0,0 +1,90 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"ride/internal/redis/keyspace"
+)
+
+// RideQueueStore keeps, per pickup area, the rides waiting for a driver in
+// the order they will be matched, plus each area's recent matches of
+// waiting rides for estimating how fast its queue drains.
+type RideQueueStore struct {
+	client *redis.Client
+	keys   keyspace.Keyspace
+}
+
+// NewRideQueueStore creates a new RideQueueStore.
+func NewRideQueueStore(client *redis.Client, prefix string) *RideQueueStore {
+	return &RideQueueStore{client: client, keys: keyspace.New(prefix)}
+}
+
+// Enqueue adds a ride to the area's queue, lower scores first. A ride
+// already queued keeps its place.
+func (s *RideQueueStore) Enqueue(ctx context.Context, area, rideID string, score float64) error {
+	pipe := s.client.TxPipeline()
+	pipe.ZAddNX(ctx, s.keys.RideQueue(area), redis.Z{Score: score, Member: rideID})
+	pipe.HSetNX(ctx, s.keys.RideQueueAreas(), rideID, area)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Remove takes a ride out of its queue and returns the area it was queued
+// in, or "" when it was not queued.
+func (s *RideQueueStore) Remove(ctx context.Context, rideID string) (string, error) {
+	area, err := s.client.HGet(ctx, s.keys.RideQueueAreas(), rideID).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	pipe := s.client.TxPipeline()
+	pipe.ZRem(ctx, s.keys.RideQueue(area), rideID)
+	pipe.HDel(ctx, s.keys.RideQueueAreas(), rideID)
+	_, err = pipe.Exec(ctx)
+	return area, err
+}
+
+// Position returns the area a ride is queued in and how many rides are
+// ahead of it. The bool is false when the ride is not queued.
+func (s *RideQueueStore) Position(ctx context.Context, rideID string) (string, int64, bool, error) {
+	area, err := s.client.HGet(ctx, s.keys.RideQueueAreas(), rideID).Result()
+	if err == redis.Nil {
+		return "", 0, false, nil
+	}
+	if err != nil {
+		return "", 0, false, err
+	}
+
+	ahead, err := s.client.ZRank(ctx, s.keys.RideQueue(area), rideID).Result()
+	if err == redis.Nil {
+		return "", 0, false, nil
+	}
+	if err != nil {
+		return "", 0, false, err
+	}
+	return area, ahead, true, nil
+}
+
+// RecordMatch notes that a queued ride in the area was matched at at,
+// forgetting matches older than window.
+func (s *RideQueueStore) RecordMatch(ctx context.Context, area, rideID string, at time.Time, window time.Duration) error {
+	key := s.keys.RideQueueMatches(area)
+	pipe := s.client.TxPipeline()
+	pipe.ZAdd(ctx, key, redis.Z{Score: float64(at.UnixMilli()), Member: rideID})
+	pipe.ZRemRangeByScore(ctx, key, "-inf", "("+strconv.FormatInt(at.Add(-window).UnixMilli(), 10))
+	pipe.Expire(ctx, key, window)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// MatchesSince counts the area's queued rides matched at or after since.
+func (s *RideQueueStore) MatchesSince(ctx context.Context, area string, since time.Time) (int64, error) {
+	return s.client.ZCount(ctx, s.keys.RideQueueMatches(area), strconv.FormatInt(since.UnixMilli(), 10), "+inf").Result()
+}
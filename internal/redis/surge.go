@@ -0,0 +1,40 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"ride/internal/redis/keyspace"
+)
+
+// SurgeStore keeps the last smoothed surge multiplier per geo cell.
+type SurgeStore struct {
+	client *redis.Client
+	keys   keyspace.Keyspace
+}
+
+// NewSurgeStore creates a new SurgeStore.
+func NewSurgeStore(client *redis.Client, prefix string) *SurgeStore {
+	return &SurgeStore{client: client, keys: keyspace.New(prefix)}
+}
+
+// GetMultiplier returns the cell's last multiplier. The bool is false when
+// none is stored or it has expired.
+func (s *SurgeStore) GetMultiplier(ctx context.Context, cell string) (float64, bool, error) {
+	multiplier, err := s.client.Get(ctx, s.keys.SurgeCell(cell)).Float64()
+	if err == redis.Nil {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return multiplier, true, nil
+}
+
+// SetMultiplier stores the cell's multiplier until ttl passes without
+// another evaluation.
+func (s *SurgeStore) SetMultiplier(ctx context.Context, cell string, multiplier float64, ttl time.Duration) error {
+	return s.client.Set(ctx, s.keys.SurgeCell(cell), multiplier, ttl).Err()
+}
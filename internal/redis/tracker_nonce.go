@@ -0,0 +1,30 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"ride/internal/redis/keyspace"
+)
+
+// TrackerNonceStore remembers the nonces of signed tracker requests so that
+// each signature is accepted once.
+type TrackerNonceStore struct {
+	client *redis.Client
+	keys   keyspace.Keyspace
+}
+
+// NewTrackerNonceStore creates a new TrackerNonceStore.
+func NewTrackerNonceStore(client *redis.Client, prefix string) *TrackerNonceStore {
+	return &TrackerNonceStore{client: client, keys: keyspace.New(prefix)}
+}
+
+// ClaimNonce reports whether the nonce is unused for the driver, marking it
+// used for ttl if so.
+func (s *TrackerNonceStore) ClaimNonce(ctx context.Context, driverID, nonce string, ttl time.Duration) (bool, error) {
+	key := s.keys.TrackerNonce(driverID, nonce)
+
+	return s.client.SetNX(ctx, key, "1", ttl).Result()
+}
@@ -0,0 +1,51 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"ride/internal/redis/keyspace"
+)
+
+// TripETAStore holds the remaining distance of paused trips, so a paused
+// trip's ETA stays where it was when the trip was paused.
+type TripETAStore struct {
+	client *redis.Client
+	keys   keyspace.Keyspace
+}
+
+// NewTripETAStore creates a new TripETAStore.
+func NewTripETAStore(client *redis.Client, prefix string) *TripETAStore {
+	return &TripETAStore{client: client, keys: keyspace.New(prefix)}
+}
+
+// FrozenRemaining returns the remaining distance frozen for the trip's pause
+// that began at pausedAt, and false if none was frozen yet.
+func (s *TripETAStore) FrozenRemaining(ctx context.Context, tripID string, pausedAt time.Time) (float64, bool, error) {
+	key := s.keys.TripETA(tripID, pausedAt.UnixNano())
+
+	val, err := s.client.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	remainingKm, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return 0, false, err
+	}
+	return remainingKm, true, nil
+}
+
+// FreezeRemaining freezes the remaining distance for the trip's pause that
+// began at pausedAt, for ttl. The first value frozen for a pause is kept.
+func (s *TripETAStore) FreezeRemaining(ctx context.Context, tripID string, pausedAt time.Time, remainingKm float64, ttl time.Duration) error {
+	key := s.keys.TripETA(tripID, pausedAt.UnixNano())
+
+	return s.client.SetNX(ctx, key, strconv.FormatFloat(remainingKm, 'f', -1, 64), ttl).Err()
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+
+	"ride/internal/domain"
+)
+
+// TripAttachmentRepository defines the persistence operations for photos
+// attached to trips.
+type TripAttachmentRepository interface {
+	// Create persists a new attachment.
+	Create(ctx context.Context, attachment *domain.TripAttachment) error
+
+	// GetByID retrieves an attachment by ID.
+	GetByID(ctx context.Context, id string) (*domain.TripAttachment, error)
+
+	// ListByTrip retrieves a trip's attachments, oldest first.
+	ListByTrip(ctx context.Context, tripID string) ([]*domain.TripAttachment, error)
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"ride/internal/domain"
+)
+
+// CampaignRepository defines the persistence operations for driver incentive
+// campaigns and per-driver progress.
+type CampaignRepository interface {
+	// Create persists a new campaign.
+	Create(ctx context.Context, campaign *domain.Campaign) error
+
+	// GetByID retrieves a campaign by ID.
+	GetByID(ctx context.Context, id string) (*domain.Campaign, error)
+
+	// GetAll retrieves all campaigns, newest window first.
+	GetAll(ctx context.Context) ([]*domain.Campaign, error)
+
+	// Update replaces a campaign's definition.
+	Update(ctx context.Context, campaign *domain.Campaign) error
+
+	// Delete removes a campaign and its progress.
+	Delete(ctx context.Context, id string) error
+
+	// ListActive retrieves the campaigns whose window contains at.
+	ListActive(ctx context.Context, at time.Time) ([]*domain.Campaign, error)
+
+	// ListOverlapping retrieves the campaigns whose window overlaps [from, to].
+	ListOverlapping(ctx context.Context, from, to time.Time) ([]*domain.Campaign, error)
+
+	// AddTripProgress adds amount to the driver's progress unless the trip has
+	// already been counted for the campaign. It returns the resulting progress
+	// and whether this call applied the trip.
+	AddTripProgress(ctx context.Context, campaignID, driverID, tripID string, amount float64) (*domain.CampaignProgress, bool, error)
+
+	// SetProgress raises the driver's progress to progress, creating the row
+	// if needed; progress already recorded is never lowered. It returns the
+	// resulting progress.
+	SetProgress(ctx context.Context, campaignID, driverID string, progress float64) (*domain.CampaignProgress, error)
+
+	// MarkCompleted sets the completion time if it is not already set.
+	// It reports whether this call completed the progress row.
+	MarkCompleted(ctx context.Context, campaignID, driverID string, at time.Time) (bool, error)
+
+	// GetProgressByDriver retrieves all of a driver's progress rows.
+	GetProgressByDriver(ctx context.Context, driverID string) ([]*domain.CampaignProgress, error)
+}
+
+// EarningsRepository defines the persistence operations for the driver
+// earnings ledger.
+type EarningsRepository interface {
+	// Credit records an entry unless one with the same reference exists.
+	// It reports whether the entry was recorded.
+	Credit(ctx context.Context, entry *domain.EarningsEntry) (bool, error)
+
+	// ListByDriver retrieves a driver's ledger entries, oldest first.
+	ListByDriver(ctx context.Context, driverID string) ([]*domain.EarningsEntry, error)
+
+	// GetByReference retrieves the entry with the given reference.
+	GetByReference(ctx context.Context, reference string) (*domain.EarningsEntry, error)
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"ride/internal/domain"
+)
+
+// DeviationAlertRepository defines the persistence operations for trip route
+// deviation alerts.
+type DeviationAlertRepository interface {
+	// Create persists a new alert.
+	Create(ctx context.Context, alert *domain.RouteDeviationAlert) error
+
+	// ListByTrip retrieves a trip's alerts, oldest first.
+	ListByTrip(ctx context.Context, tripID string) ([]*domain.RouteDeviationAlert, error)
+}
@@ -17,9 +17,45 @@ type DriverRepository interface {
 	// GetByPhone retrieves a driver by phone number.
 	GetByPhone(ctx context.Context, phone string) (*domain.Driver, error)
 
+	// GetByEmail retrieves a driver by email address.
+	GetByEmail(ctx context.Context, email string) (*domain.Driver, error)
+
 	// GetAll retrieves all drivers.
 	GetAll(ctx context.Context) ([]*domain.Driver, error)
 
 	// UpdateStatus updates the status of a driver.
 	UpdateStatus(ctx context.Context, id string, status domain.DriverStatus) error
+
+	// TransitionStatus moves a driver from one status to another. It reports
+	// false, without error, if the driver was not in the from status.
+	TransitionStatus(ctx context.Context, id string, from, to domain.DriverStatus) (bool, error)
+
+	// ChangeTier moves a driver to change.Tier and records the change,
+	// filling in PreviousTier and ChangedAt. Returns ErrNotFound if the
+	// driver does not exist.
+	ChangeTier(ctx context.Context, change *domain.DriverTierChange) error
+
+	// List retrieves a page of drivers matching the filter, along with the
+	// total number of matching drivers.
+	List(ctx context.Context, filter DriverFilter) ([]*domain.Driver, int, error)
+}
+
+// DriverFilter narrows a driver listing. Zero values mean "no filter".
+type DriverFilter struct {
+	Status      domain.DriverStatus
+	Tier        domain.DriverTier
+	PhonePrefix string // Matches phones starting with this value
+	Name        string // Case-insensitive substring match
+	Near        *NearFilter
+	Limit       int
+	Offset      int
+}
+
+// NearFilter keeps only drivers whose last recorded position lies within
+// RadiusKm of (Lat, Lng), and orders them nearest first. Drivers with no
+// recorded position are left out.
+type NearFilter struct {
+	Lat      float64
+	Lng      float64
+	RadiusKm float64
 }
@@ -5,4 +5,16 @@ import "errors"
 var (
 	// ErrNotFound is returned when a requested entity does not exist.
 	ErrNotFound = errors.New("entity not found")
+
+	// ErrVersionConflict is returned when an update's version no longer matches
+	// the stored row, i.e. someone else updated it first.
+	ErrVersionConflict = errors.New("entity was modified concurrently")
+
+	// ErrDuplicate is returned when a write would break a uniqueness
+	// constraint, e.g. an email address already in use.
+	ErrDuplicate = errors.New("entity already exists")
+
+	// ErrForeignKeyViolation is returned when a write references a row that
+	// does not exist, e.g. a payment for an unknown trip.
+	ErrForeignKeyViolation = errors.New("referenced entity does not exist")
 )
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+
+	"ride/internal/domain"
+)
+
+// FareAdjustmentRepository defines the persistence operations for the audit
+// trail of fare adjustments.
+type FareAdjustmentRepository interface {
+	// Create records an adjustment.
+	Create(ctx context.Context, adjustment *domain.FareAdjustment) error
+
+	// SetPaymentID records the payment that settled an adjustment.
+	SetPaymentID(ctx context.Context, id, paymentID string) error
+
+	// ListByTripID returns a trip's adjustments, oldest first.
+	ListByTripID(ctx context.Context, tripID string) ([]*domain.FareAdjustment, error)
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+
+	"ride/internal/domain"
+)
+
+// FareSplitRepository defines the persistence operations for trips' fare
+// splits.
+type FareSplitRepository interface {
+	// Save stores a trip's split, replacing any it already has.
+	Save(ctx context.Context, split *domain.FareSplit) error
+
+	// GetByTripID retrieves a trip's split. Returns nil if the fare is not
+	// split.
+	GetByTripID(ctx context.Context, tripID string) (*domain.FareSplit, error)
+}
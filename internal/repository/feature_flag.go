@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"ride/internal/domain"
+)
+
+// FeatureFlagRepository defines the persistence operations for feature flags.
+type FeatureFlagRepository interface {
+	// Upsert creates the flag, or replaces the rules of an existing one.
+	Upsert(ctx context.Context, flag *domain.FeatureFlag) error
+
+	// GetAll retrieves every flag, by name.
+	GetAll(ctx context.Context) ([]*domain.FeatureFlag, error)
+}
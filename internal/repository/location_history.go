@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"ride/internal/domain"
+)
+
+// LocationHistoryRepository defines the persistence operations for driver
+// location history.
+type LocationHistoryRepository interface {
+	// CreateBatch appends a batch of location points.
+	CreateBatch(ctx context.Context, points []*domain.LocationPoint) error
+
+	// ListByDriver retrieves a driver's points recorded in [from, to], oldest first.
+	ListByDriver(ctx context.Context, driverID string, from, to time.Time) ([]*domain.LocationPoint, error)
+
+	// OnlineHours returns the driver's hours online in [from, to): every
+	// 5-minute slot with at least one point counts.
+	OnlineHours(ctx context.Context, driverID string, from, to time.Time) (float64, error)
+
+	// ListOnlineHours returns the hours online in [from, to) of every driver
+	// online for at least minHours, by driver ID.
+	ListOnlineHours(ctx context.Context, from, to time.Time, minHours float64) (map[string]float64, error)
+
+	// DeleteBefore removes points recorded before cutoff and returns how many were removed.
+	DeleteBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"ride/internal/domain"
+)
+
+// MatchAttemptRepository defines the persistence operations for matching
+// diagnostics.
+type MatchAttemptRepository interface {
+	// Create persists a match attempt.
+	Create(ctx context.Context, attempt *domain.MatchAttempt) error
+
+	// ListByRide retrieves a ride's match attempts, oldest first.
+	ListByRide(ctx context.Context, rideID string) ([]*domain.MatchAttempt, error)
+}
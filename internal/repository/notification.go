@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+
+	"ride/internal/domain"
+)
+
+// NotificationRepository defines the persistence operations for the
+// notification outbox.
+type NotificationRepository interface {
+	// Append records a notification and sets its ID.
+	Append(ctx context.Context, event *domain.NotificationEvent) error
+
+	// ListSince returns up to limit notifications for the recipient with an
+	// ID greater than afterID, oldest first.
+	ListSince(ctx context.Context, recipientID string, afterID int64, limit int) ([]*domain.NotificationEvent, error)
+}
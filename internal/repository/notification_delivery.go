@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"ride/internal/domain"
+)
+
+// NotificationDeliveryRepository defines the persistence operations for the
+// queue of channel deliveries.
+type NotificationDeliveryRepository interface {
+	// Create queues a delivery and sets its ID.
+	Create(ctx context.Context, delivery *domain.NotificationDelivery) error
+
+	// ClaimDue returns up to limit PENDING deliveries due at now, oldest due
+	// first, pushing their next attempt to now+lease so that no other
+	// dispatcher picks them up while they are being sent.
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*domain.NotificationDelivery, error)
+
+	// Update stores a delivery's status, attempts, next attempt and last
+	// error. Returns ErrNotFound if it does not exist.
+	Update(ctx context.Context, delivery *domain.NotificationDelivery) error
+
+	// ListByStatus returns up to limit deliveries with the status, most
+	// recently updated first.
+	ListByStatus(ctx context.Context, status domain.NotificationDeliveryStatus, limit int) ([]*domain.NotificationDelivery, error)
+
+	// CountByStatus returns how many deliveries have the status.
+	CountByStatus(ctx context.Context, status domain.NotificationDeliveryStatus) (int, error)
+
+	// Requeue moves a DEAD delivery back to PENDING with no attempts, due at
+	// now, and returns it. A delivery that is not DEAD is returned as is.
+	// Returns ErrNotFound if it does not exist.
+	Requeue(ctx context.Context, id int64, now time.Time) (*domain.NotificationDelivery, error)
+}
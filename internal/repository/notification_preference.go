@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+
+	"ride/internal/domain"
+)
+
+// NotificationPreferenceRepository defines the persistence operations for
+// the notification types and channels riders and drivers have turned on or off.
+type NotificationPreferenceRepository interface {
+	// Upsert records each preference, replacing any earlier setting for the
+	// same recipient, type and channel.
+	Upsert(ctx context.Context, preferences []*domain.NotificationPreference) error
+
+	// ListByRecipient retrieves a recipient's preferences by type, then channel.
+	ListByRecipient(ctx context.Context, recipientID string) ([]*domain.NotificationPreference, error)
+}
@@ -18,6 +18,18 @@ type PaymentRepository interface {
 	// Returns nil if no payment exists with the given key.
 	GetByIdempotencyKey(ctx context.Context, key string) (*domain.Payment, error)
 
-	// UpdateStatus updates the status of a payment.
+	// UpdateStatus updates the status of a payment, clearing any failure
+	// reason.
 	UpdateStatus(ctx context.Context, id string, status domain.PaymentStatus) error
+
+	// MarkFailed sets a payment FAILED with the reason it failed.
+	MarkFailed(ctx context.Context, id string, reason string) error
+
+	// TransitionStatus moves a payment from one status to another. It reports
+	// false, without error, if the payment was not in the from status.
+	TransitionStatus(ctx context.Context, id string, from, to domain.PaymentStatus) (bool, error)
+
+	// UpdateCharge sets the amount and fee a payment charges and the hold it
+	// is captured against.
+	UpdateCharge(ctx context.Context, id string, amount, fee float64, authID string) error
 }
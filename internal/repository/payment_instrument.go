@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+
+	"ride/internal/domain"
+)
+
+// PaymentInstrumentRepository defines the persistence operations for the
+// payment instruments users keep on file.
+type PaymentInstrumentRepository interface {
+	// Create persists a new instrument.
+	Create(ctx context.Context, instrument *domain.PaymentInstrument) error
+
+	// GetByID retrieves an instrument by ID.
+	GetByID(ctx context.Context, id string) (*domain.PaymentInstrument, error)
+
+	// ListByUser retrieves a user's instruments, oldest first.
+	ListByUser(ctx context.Context, userID string) ([]*domain.PaymentInstrument, error)
+
+	// GetDefault retrieves the user's default instrument of the given type,
+	// falling back to the most recently added one when none is marked
+	// default. Returns nil if the user has no instrument of that type.
+	GetDefault(ctx context.Context, userID string, instrumentType domain.PaymentMethod) (*domain.PaymentInstrument, error)
+
+	// SetDefault marks the instrument as the user's default for its type,
+	// clearing the flag on the user's other instruments of that type.
+	SetDefault(ctx context.Context, userID, id string) error
+
+	// Delete removes one of the user's instruments.
+	Delete(ctx context.Context, userID, id string) error
+}
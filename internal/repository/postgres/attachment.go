@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/repository"
+)
+
+// TripAttachmentRepository is a PostgreSQL implementation of
+// repository.TripAttachmentRepository.
+type TripAttachmentRepository struct {
+	q Querier
+}
+
+// NewTripAttachmentRepository creates a new PostgreSQL trip attachment repository.
+func NewTripAttachmentRepository(db *sql.DB) *TripAttachmentRepository {
+	return &TripAttachmentRepository{q: db}
+}
+
+// tripAttachmentSchema is the part of the schema TripAttachmentRepository reads and writes.
+var tripAttachmentSchema = []Table{
+	{Name: "trip_attachments", Columns: []Column{
+		{"id", ColumnText}, {"trip_id", ColumnText}, {"uploaded_by", ColumnText}, {"blob_key", ColumnText},
+		{"content_type", ColumnText}, {"size_bytes", ColumnInteger}, {"created_at", ColumnTimestamp},
+	}},
+}
+
+const tripAttachmentColumns = `id, trip_id, uploaded_by, blob_key, content_type, size_bytes, created_at`
+
+// Create persists a new attachment.
+func (r *TripAttachmentRepository) Create(ctx context.Context, attachment *domain.TripAttachment) error {
+	query := `
+		INSERT INTO trip_attachments (` + tripAttachmentColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	if attachment.CreatedAt.IsZero() {
+		attachment.CreatedAt = time.Now()
+	}
+
+	_, err := r.q.ExecContext(ctx, query,
+		attachment.ID,
+		attachment.TripID,
+		attachment.UploadedBy,
+		attachment.BlobKey,
+		attachment.ContentType,
+		attachment.SizeBytes,
+		attachment.CreatedAt,
+	)
+	return translateConstraintViolation(err)
+}
+
+// GetByID retrieves an attachment by ID.
+func (r *TripAttachmentRepository) GetByID(ctx context.Context, id string) (*domain.TripAttachment, error) {
+	query := `SELECT ` + tripAttachmentColumns + ` FROM trip_attachments WHERE id = $1`
+
+	attachment, err := scanTripAttachment(r.q.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// ListByTrip retrieves a trip's attachments, oldest first.
+func (r *TripAttachmentRepository) ListByTrip(ctx context.Context, tripID string) ([]*domain.TripAttachment, error) {
+	query := `SELECT ` + tripAttachmentColumns + ` FROM trip_attachments WHERE trip_id = $1 ORDER BY created_at, id`
+
+	rows, err := r.q.QueryContext(ctx, query, tripID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attachments []*domain.TripAttachment
+	for rows.Next() {
+		attachment, err := scanTripAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, attachment)
+	}
+	return attachments, rows.Err()
+}
+
+// Ensure TripAttachmentRepository implements repository.TripAttachmentRepository.
+var _ repository.TripAttachmentRepository = (*TripAttachmentRepository)(nil)
+
+func scanTripAttachment(row rowScanner) (*domain.TripAttachment, error) {
+	var attachment domain.TripAttachment
+	err := row.Scan(
+		&attachment.ID,
+		&attachment.TripID,
+		&attachment.UploadedBy,
+		&attachment.BlobKey,
+		&attachment.ContentType,
+		&attachment.SizeBytes,
+		&attachment.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// CacheInvalidationChannel is the channel the drivers and rides triggers in
+// scripts/schema.sql notify on, with "<table>:<id>" payloads, whenever a row
+// is updated or deleted.
+const CacheInvalidationChannel = "cache_invalidation"
+
+// CacheInvalidation names a row that changed.
+type CacheInvalidation struct {
+	Table string // "drivers" or "rides"
+	ID    string
+}
+
+// ParseCacheInvalidation parses a notification payload. It reports false for
+// payloads not in the "<table>:<id>" form.
+func ParseCacheInvalidation(payload string) (CacheInvalidation, bool) {
+	table, id, ok := strings.Cut(payload, ":")
+	if !ok || table == "" || id == "" {
+		return CacheInvalidation{}, false
+	}
+	return CacheInvalidation{Table: table, ID: id}, true
+}
+
+// CacheInvalidationListener receives cache invalidations over a dedicated
+// connection, separate from the pool. When the connection drops it
+// reconnects with backoff; notifications sent meanwhile are lost, so cached
+// entries fall back to expiring by TTL until it is back.
+type CacheInvalidationListener struct {
+	listener      *pq.Listener
+	invalidations chan CacheInvalidation
+}
+
+// NewCacheInvalidationListener connects to the database at dsn and listens
+// on CacheInvalidationChannel. Call Close on shutdown.
+func NewCacheInvalidationListener(dsn string, minReconnect, maxReconnect time.Duration) (*CacheInvalidationListener, error) {
+	listener := pq.NewListener(dsn, minReconnect, maxReconnect, func(event pq.ListenerEventType, err error) {
+		switch event {
+		case pq.ListenerEventDisconnected:
+			log.Printf("[CACHE] Invalidation listener disconnected, cached entries expire by TTL until it reconnects: %v", err)
+		case pq.ListenerEventReconnected:
+			log.Println("[CACHE] Invalidation listener reconnected")
+		case pq.ListenerEventConnectionAttemptFailed:
+			log.Printf("[CACHE] Invalidation listener failed to reconnect: %v", err)
+		}
+	})
+	if err := listener.Listen(CacheInvalidationChannel); err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+
+	l := &CacheInvalidationListener{
+		listener:      listener,
+		invalidations: make(chan CacheInvalidation, 256),
+	}
+	go l.run()
+	return l, nil
+}
+
+// Invalidations returns the rows changed by any instance. It is closed
+// after Close.
+func (l *CacheInvalidationListener) Invalidations() <-chan CacheInvalidation {
+	return l.invalidations
+}
+
+// Close disconnects the listener.
+func (l *CacheInvalidationListener) Close() error {
+	return l.listener.Close()
+}
+
+// run forwards notifications until the listener is closed.
+func (l *CacheInvalidationListener) run() {
+	defer close(l.invalidations)
+
+	for n := range l.listener.Notify {
+		// A nil notification follows a reconnect; whatever was sent while
+		// disconnected is gone.
+		if n == nil {
+			continue
+		}
+		invalidation, ok := ParseCacheInvalidation(n.Extra)
+		if !ok {
+			log.Printf("[CACHE] Ignoring malformed invalidation %q", n.Extra)
+			continue
+		}
+		l.invalidations <- invalidation
+	}
+}
@@ -0,0 +1,360 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/repository"
+)
+
+// CampaignRepository is a PostgreSQL implementation of repository.CampaignRepository.
+type CampaignRepository struct {
+	q Querier
+}
+
+// NewCampaignRepository creates a new PostgreSQL campaign repository.
+func NewCampaignRepository(db *sql.DB) *CampaignRepository {
+	return &CampaignRepository{q: db}
+}
+
+// NewCampaignRepositoryWithTx creates a campaign repository using a transaction.
+func NewCampaignRepositoryWithTx(tx *sql.Tx) *CampaignRepository {
+	return &CampaignRepository{q: tx}
+}
+
+// campaignSchema is the part of the schema CampaignRepository reads and writes.
+var campaignSchema = []Table{
+	{Name: "campaigns", Columns: []Column{
+		{"id", ColumnText}, {"name", ColumnText}, {"criteria", ColumnText}, {"target", ColumnFloat},
+		{"reward_amount", ColumnFloat}, {"tier", ColumnText}, {"city", ColumnText}, {"starts_at", ColumnTimestamp},
+		{"ends_at", ColumnTimestamp}, {"created_at", ColumnTimestamp},
+	}},
+	{Name: "campaign_progress", Columns: []Column{
+		{"campaign_id", ColumnText}, {"driver_id", ColumnText}, {"progress", ColumnFloat},
+		{"completed_at", ColumnTimestamp}, {"updated_at", ColumnTimestamp},
+	}},
+	{Name: "campaign_trip_credits", Columns: []Column{
+		{"campaign_id", ColumnText}, {"trip_id", ColumnText}, {"driver_id", ColumnText},
+	}},
+}
+
+const campaignColumns = `id, name, criteria, target, reward_amount, tier, city, starts_at, ends_at, created_at`
+
+// Create persists a new campaign.
+func (r *CampaignRepository) Create(ctx context.Context, campaign *domain.Campaign) error {
+	query := `
+		INSERT INTO campaigns (` + campaignColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	_, err := r.q.ExecContext(ctx, query,
+		campaign.ID,
+		campaign.Name,
+		campaign.Criteria,
+		campaign.Target,
+		campaign.RewardAmount,
+		nullTier(campaign.Tier),
+		campaign.City,
+		campaign.StartsAt,
+		campaign.EndsAt,
+		campaign.CreatedAt,
+	)
+
+	return translateConstraintViolation(err)
+}
+
+// GetByID retrieves a campaign by ID.
+func (r *CampaignRepository) GetByID(ctx context.Context, id string) (*domain.Campaign, error) {
+	query := `SELECT ` + campaignColumns + ` FROM campaigns WHERE id = $1`
+
+	campaign, err := scanCampaign(r.q.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return campaign, nil
+}
+
+// GetAll retrieves all campaigns, newest window first.
+func (r *CampaignRepository) GetAll(ctx context.Context) ([]*domain.Campaign, error) {
+	query := `SELECT ` + campaignColumns + ` FROM campaigns ORDER BY starts_at DESC, id`
+
+	return r.queryCampaigns(ctx, query)
+}
+
+// Update replaces a campaign's definition.
+func (r *CampaignRepository) Update(ctx context.Context, campaign *domain.Campaign) error {
+	query := `
+		UPDATE campaigns
+		SET name = $1, criteria = $2, target = $3, reward_amount = $4, tier = $5, city = $6, starts_at = $7, ends_at = $8
+		WHERE id = $9
+	`
+
+	result, err := r.q.ExecContext(ctx, query,
+		campaign.Name,
+		campaign.Criteria,
+		campaign.Target,
+		campaign.RewardAmount,
+		nullTier(campaign.Tier),
+		campaign.City,
+		campaign.StartsAt,
+		campaign.EndsAt,
+		campaign.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return requireRowsAffected(result)
+}
+
+// Delete removes a campaign; progress and trip credits cascade.
+func (r *CampaignRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.q.ExecContext(ctx, `DELETE FROM campaigns WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	return requireRowsAffected(result)
+}
+
+// ListActive retrieves the campaigns whose window contains at.
+func (r *CampaignRepository) ListActive(ctx context.Context, at time.Time) ([]*domain.Campaign, error) {
+	query := `
+		SELECT ` + campaignColumns + `
+		FROM campaigns
+		WHERE starts_at <= $1 AND ends_at > $1
+		ORDER BY starts_at, id
+	`
+
+	return r.queryCampaigns(ctx, query, at)
+}
+
+// ListOverlapping retrieves the campaigns whose window overlaps [from, to].
+func (r *CampaignRepository) ListOverlapping(ctx context.Context, from, to time.Time) ([]*domain.Campaign, error) {
+	query := `
+		SELECT ` + campaignColumns + `
+		FROM campaigns
+		WHERE starts_at <= $2 AND ends_at > $1
+		ORDER BY starts_at, id
+	`
+
+	return r.queryCampaigns(ctx, query, from, to)
+}
+
+// AddTripProgress adds amount to the driver's progress unless the trip has
+// already been counted for the campaign. The trip credit and the progress
+// increment happen in one statement, so a retry cannot count a trip twice.
+func (r *CampaignRepository) AddTripProgress(ctx context.Context, campaignID, driverID, tripID string, amount float64) (*domain.CampaignProgress, bool, error) {
+	query := `
+		WITH credited AS (
+			INSERT INTO campaign_trip_credits (campaign_id, trip_id, driver_id)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (campaign_id, trip_id) DO NOTHING
+			RETURNING campaign_id
+		)
+		INSERT INTO campaign_progress (campaign_id, driver_id, progress, updated_at)
+		SELECT campaign_id, $3, $4, NOW() FROM credited
+		ON CONFLICT (campaign_id, driver_id)
+		DO UPDATE SET progress = campaign_progress.progress + EXCLUDED.progress, updated_at = NOW()
+		RETURNING progress, completed_at
+	`
+
+	progress := &domain.CampaignProgress{CampaignID: campaignID, DriverID: driverID}
+	var completedAt sql.NullTime
+
+	err := r.q.QueryRowContext(ctx, query, campaignID, tripID, driverID, amount).Scan(&progress.Progress, &completedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Trip already counted; report the current progress unchanged.
+		current, err := r.getProgress(ctx, campaignID, driverID)
+		return current, false, err
+	}
+	if err != nil {
+		return nil, false, translateConstraintViolation(err)
+	}
+
+	if completedAt.Valid {
+		progress.CompletedAt = completedAt.Time
+	}
+
+	return progress, true, nil
+}
+
+// SetProgress raises the driver's progress to progress; progress already
+// recorded is never lowered.
+func (r *CampaignRepository) SetProgress(ctx context.Context, campaignID, driverID string, progress float64) (*domain.CampaignProgress, error) {
+	query := `
+		INSERT INTO campaign_progress (campaign_id, driver_id, progress, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (campaign_id, driver_id)
+		DO UPDATE SET progress = GREATEST(campaign_progress.progress, EXCLUDED.progress), updated_at = NOW()
+		RETURNING campaign_id, driver_id, progress, completed_at
+	`
+
+	p, err := scanCampaignProgress(r.q.QueryRowContext(ctx, query, campaignID, driverID, progress))
+	if err != nil {
+		return nil, translateConstraintViolation(err)
+	}
+
+	return p, nil
+}
+
+// MarkCompleted sets the completion time if it is not already set.
+func (r *CampaignRepository) MarkCompleted(ctx context.Context, campaignID, driverID string, at time.Time) (bool, error) {
+	query := `
+		UPDATE campaign_progress
+		SET completed_at = $1, updated_at = NOW()
+		WHERE campaign_id = $2 AND driver_id = $3 AND completed_at IS NULL
+	`
+
+	result, err := r.q.ExecContext(ctx, query, at, campaignID, driverID)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// GetProgressByDriver retrieves all of a driver's progress rows.
+func (r *CampaignRepository) GetProgressByDriver(ctx context.Context, driverID string) ([]*domain.CampaignProgress, error) {
+	query := `
+		SELECT campaign_id, driver_id, progress, completed_at
+		FROM campaign_progress WHERE driver_id = $1
+	`
+
+	rows, err := r.q.QueryContext(ctx, query, driverID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var progress []*domain.CampaignProgress
+	for rows.Next() {
+		p, err := scanCampaignProgress(rows)
+		if err != nil {
+			return nil, err
+		}
+		progress = append(progress, p)
+	}
+
+	return progress, rows.Err()
+}
+
+func (r *CampaignRepository) getProgress(ctx context.Context, campaignID, driverID string) (*domain.CampaignProgress, error) {
+	query := `
+		SELECT campaign_id, driver_id, progress, completed_at
+		FROM campaign_progress WHERE campaign_id = $1 AND driver_id = $2
+	`
+
+	progress, err := scanCampaignProgress(r.q.QueryRowContext(ctx, query, campaignID, driverID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return progress, nil
+}
+
+func (r *CampaignRepository) queryCampaigns(ctx context.Context, query string, args ...any) ([]*domain.Campaign, error) {
+	rows, err := r.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var campaigns []*domain.Campaign
+	for rows.Next() {
+		campaign, err := scanCampaign(rows)
+		if err != nil {
+			return nil, err
+		}
+		campaigns = append(campaigns, campaign)
+	}
+
+	return campaigns, rows.Err()
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanCampaign(row rowScanner) (*domain.Campaign, error) {
+	var campaign domain.Campaign
+	var tier sql.NullString
+
+	err := row.Scan(
+		&campaign.ID,
+		&campaign.Name,
+		&campaign.Criteria,
+		&campaign.Target,
+		&campaign.RewardAmount,
+		&tier,
+		&campaign.City,
+		&campaign.StartsAt,
+		&campaign.EndsAt,
+		&campaign.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if tier.Valid {
+		campaign.Tier = domain.DriverTier(tier.String)
+	}
+
+	return &campaign, nil
+}
+
+func scanCampaignProgress(row rowScanner) (*domain.CampaignProgress, error) {
+	var progress domain.CampaignProgress
+	var completedAt sql.NullTime
+
+	if err := row.Scan(&progress.CampaignID, &progress.DriverID, &progress.Progress, &completedAt); err != nil {
+		return nil, err
+	}
+
+	if completedAt.Valid {
+		progress.CompletedAt = completedAt.Time
+	}
+
+	return &progress, nil
+}
+
+// nullTier stores an empty tier ("every tier") as NULL.
+func nullTier(tier domain.DriverTier) sql.NullString {
+	if tier == "" {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: string(tier), Valid: true}
+}
+
+// requireRowsAffected maps a zero-row UPDATE or DELETE to repository.ErrNotFound.
+func requireRowsAffected(result sql.Result) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+// Ensure CampaignRepository implements repository.CampaignRepository.
+var _ repository.CampaignRepository = (*CampaignRepository)(nil)
@@ -3,6 +3,13 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+
+	"ride/internal/repository"
 )
 
 // Querier is an interface satisfied by both *sql.DB and *sql.Tx.
@@ -16,4 +23,115 @@ type Querier interface {
 var (
 	_ Querier = (*sql.DB)(nil)
 	_ Querier = (*sql.Tx)(nil)
+	_ Querier = (*preparedQuerier)(nil)
+)
+
+// LiftStatementTimeout exempts the rest of tx from DB_STATEMENT_TIMEOUT, for
+// the few reads, such as exports, that are expected to run long. Other
+// statements on the connection keep the timeout once tx ends.
+func LiftStatementTimeout(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `SET LOCAL statement_timeout = 0`)
+	return err
+}
+
+// preparedQuerier is a Querier over a *sql.DB that runs a fixed set of hot
+// queries through statements prepared once, so Postgres does not re-parse
+// and re-plan them on every call. Any other query runs directly on the db.
+type preparedQuerier struct {
+	*sql.DB
+	stmts map[string]*sql.Stmt
+}
+
+// prepareQueries returns a Querier for db with queries prepared. A query
+// that fails to prepare is logged and run directly instead, so a repository
+// still works against a database that cannot prepare statements.
+func prepareQueries(db *sql.DB, queries ...string) Querier {
+	prepared := &preparedQuerier{DB: db, stmts: make(map[string]*sql.Stmt, len(queries))}
+	for _, query := range queries {
+		stmt, err := db.Prepare(query)
+		if err != nil {
+			log.Printf("[DB] Running query unprepared: %v", err)
+			continue
+		}
+		prepared.stmts[query] = stmt
+	}
+	return prepared
+}
+
+func (q *preparedQuerier) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	if stmt, ok := q.stmts[query]; ok {
+		return stmt.ExecContext(ctx, args...)
+	}
+	return q.DB.ExecContext(ctx, query, args...)
+}
+
+func (q *preparedQuerier) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	if stmt, ok := q.stmts[query]; ok {
+		return stmt.QueryRowContext(ctx, args...)
+	}
+	return q.DB.QueryRowContext(ctx, query, args...)
+}
+
+func (q *preparedQuerier) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	if stmt, ok := q.stmts[query]; ok {
+		return stmt.QueryContext(ctx, args...)
+	}
+	return q.DB.QueryContext(ctx, query, args...)
+}
+
+// closeStatements closes the statements prepared for q, if any. It does not
+// close the underlying db.
+func closeStatements(q Querier) error {
+	prepared, ok := q.(*preparedQuerier)
+	if !ok {
+		return nil
+	}
+	var errs []error
+	for _, stmt := range prepared.stmts {
+		errs = append(errs, stmt.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// nullTime stores a zero time as NULL.
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+// versionMismatchError explains why a versioned UPDATE matched no rows:
+// the row is either gone or was updated by someone else first.
+// table must be a trusted constant.
+func versionMismatchError(ctx context.Context, q Querier, table, id string) error {
+	var exists bool
+	err := q.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM `+table+` WHERE id = $1)`, id).Scan(&exists)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if !exists {
+		return repository.ErrNotFound
+	}
+	return repository.ErrVersionConflict
+}
+
+// PostgreSQL error codes for constraint violations.
+const (
+	foreignKeyViolation = "23503"
+	uniqueViolation     = "23505"
 )
+
+// translateConstraintViolation maps unique and foreign key constraint
+// violations to repository.ErrDuplicate and repository.ErrForeignKeyViolation
+// and returns other errors unchanged.
+func translateConstraintViolation(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+	switch pqErr.Code {
+	case uniqueViolation:
+		return repository.ErrDuplicate
+	case foreignKeyViolation:
+		return repository.ErrForeignKeyViolation
+	}
+	return err
+}
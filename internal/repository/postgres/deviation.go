@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"ride/internal/domain"
+	"ride/internal/repository"
+)
+
+// DeviationAlertRepository is a PostgreSQL implementation of repository.DeviationAlertRepository.
+type DeviationAlertRepository struct {
+	q Querier
+}
+
+// NewDeviationAlertRepository creates a new PostgreSQL deviation alert repository.
+func NewDeviationAlertRepository(db *sql.DB) *DeviationAlertRepository {
+	return &DeviationAlertRepository{q: db}
+}
+
+// NewDeviationAlertRepositoryWithTx creates a deviation alert repository using a transaction.
+func NewDeviationAlertRepositoryWithTx(tx *sql.Tx) *DeviationAlertRepository {
+	return &DeviationAlertRepository{q: tx}
+}
+
+// deviationSchema is the part of the schema DeviationAlertRepository reads and writes.
+var deviationSchema = []Table{
+	{Name: "route_deviation_alerts", Columns: []Column{
+		{"id", ColumnText}, {"trip_id", ColumnText}, {"ride_id", ColumnText}, {"driver_id", ColumnText},
+		{"lat", ColumnFloat}, {"lng", ColumnFloat}, {"distance_km", ColumnFloat},
+		{"consecutive_pings", ColumnInteger}, {"created_at", ColumnTimestamp},
+	}},
+}
+
+// Create persists a new alert.
+func (r *DeviationAlertRepository) Create(ctx context.Context, alert *domain.RouteDeviationAlert) error {
+	query := `
+		INSERT INTO route_deviation_alerts (id, trip_id, ride_id, driver_id, lat, lng, distance_km, consecutive_pings, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	_, err := r.q.ExecContext(ctx, query,
+		alert.ID,
+		alert.TripID,
+		alert.RideID,
+		alert.DriverID,
+		alert.Lat,
+		alert.Lng,
+		alert.DistanceKm,
+		alert.ConsecutivePings,
+		alert.CreatedAt,
+	)
+	return translateConstraintViolation(err)
+}
+
+// ListByTrip retrieves a trip's alerts, oldest first.
+func (r *DeviationAlertRepository) ListByTrip(ctx context.Context, tripID string) ([]*domain.RouteDeviationAlert, error) {
+	query := `
+		SELECT id, trip_id, ride_id, driver_id, lat, lng, distance_km, consecutive_pings, created_at
+		FROM route_deviation_alerts WHERE trip_id = $1
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.q.QueryContext(ctx, query, tripID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var alerts []*domain.RouteDeviationAlert
+	for rows.Next() {
+		var alert domain.RouteDeviationAlert
+		if err := rows.Scan(
+			&alert.ID,
+			&alert.TripID,
+			&alert.RideID,
+			&alert.DriverID,
+			&alert.Lat,
+			&alert.Lng,
+			&alert.DistanceKm,
+			&alert.ConsecutivePings,
+			&alert.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, &alert)
+	}
+
+	return alerts, rows.Err()
+}
+
+// Ensure DeviationAlertRepository implements repository.DeviationAlertRepository.
+var _ repository.DeviationAlertRepository = (*DeviationAlertRepository)(nil)
@@ -3,7 +3,11 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"ride/internal/domain"
 	"ride/internal/repository"
@@ -14,35 +18,67 @@ type DriverRepository struct {
 	q Querier
 }
 
-// NewDriverRepository creates a new PostgreSQL driver repository.
+// driverColumns is the column list scanDriver expects.
+const driverColumns = `id, COALESCE(name, ''), COALESCE(phone, ''), status, tier, email, email_verified, vehicle_plate, city, capabilities, created_at, updated_at`
+
+// Hot queries run on every match and location update; they are prepared
+// once per repository.
+const (
+	driverGetByIDQuery      = `SELECT ` + driverColumns + ` FROM drivers WHERE id = $1`
+	driverUpdateStatusQuery = `UPDATE drivers SET status = $1, updated_at = NOW() WHERE id = $2`
+)
+
+// NewDriverRepository creates a new PostgreSQL driver repository. Call Close
+// to release its prepared statements.
 func NewDriverRepository(db *sql.DB) *DriverRepository {
-	return &DriverRepository{q: db}
+	return &DriverRepository{q: prepareQueries(db, driverGetByIDQuery, driverUpdateStatusQuery)}
 }
 
 // NewDriverRepositoryWithTx creates a driver repository using a transaction.
+// Its queries run unprepared.
 func NewDriverRepositoryWithTx(tx *sql.Tx) *DriverRepository {
 	return &DriverRepository{q: tx}
 }
 
+// driverSchema is the part of the schema DriverRepository reads and writes.
+var driverSchema = []Table{
+	{Name: "drivers", Columns: []Column{
+		{"id", ColumnText}, {"name", ColumnText}, {"phone", ColumnText}, {"status", ColumnText},
+		{"tier", ColumnText}, {"email", ColumnText}, {"email_verified", ColumnBool},
+		{"vehicle_plate", ColumnText}, {"city", ColumnText}, {"capabilities", ColumnJSON},
+		{"created_at", ColumnTimestamp}, {"updated_at", ColumnTimestamp},
+	}},
+	{Name: "driver_tier_changes", Columns: []Column{
+		{"id", ColumnText}, {"driver_id", ColumnText}, {"previous_tier", ColumnText}, {"tier", ColumnText},
+		{"changed_at", ColumnTimestamp},
+	}},
+}
+
+// Close releases the repository's prepared statements.
+func (r *DriverRepository) Close() error {
+	return closeStatements(r.q)
+}
+
 // Create adds a new driver.
 func (r *DriverRepository) Create(ctx context.Context, driver *domain.Driver) error {
-	query := `INSERT INTO drivers (id, name, phone, status, tier) VALUES ($1, $2, $3, $4, $5)`
-	_, err := r.q.ExecContext(ctx, query, driver.ID, driver.Name, driver.Phone, driver.Status, driver.Tier)
-	return err
+	if driver.CreatedAt.IsZero() {
+		driver.CreatedAt = time.Now()
+	}
+	driver.UpdatedAt = driver.CreatedAt
+
+	capabilities, err := marshalCapabilities(driver.Capabilities)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO drivers (id, name, phone, status, tier, email, email_verified, vehicle_plate, city, capabilities, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+	_, err = r.q.ExecContext(ctx, query, driver.ID, driver.Name, driver.Phone, driver.Status, driver.Tier, driver.Email, driver.EmailVerified, driver.VehiclePlate, driver.City, capabilities, driver.CreatedAt, driver.UpdatedAt)
+	return translateConstraintViolation(err)
 }
 
 // GetByID retrieves a driver by ID.
 func (r *DriverRepository) GetByID(ctx context.Context, id string) (*domain.Driver, error) {
-	query := `SELECT id, COALESCE(name, ''), COALESCE(phone, ''), status, tier FROM drivers WHERE id = $1`
-
-	var driver domain.Driver
-	err := r.q.QueryRowContext(ctx, query, id).Scan(
-		&driver.ID,
-		&driver.Name,
-		&driver.Phone,
-		&driver.Status,
-		&driver.Tier,
-	)
+	driver, err := scanDriver(r.q.QueryRowContext(ctx, driverGetByIDQuery, id))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, repository.ErrNotFound
@@ -50,21 +86,14 @@ func (r *DriverRepository) GetByID(ctx context.Context, id string) (*domain.Driv
 		return nil, err
 	}
 
-	return &driver, nil
+	return driver, nil
 }
 
 // GetByPhone retrieves a driver by phone number.
 func (r *DriverRepository) GetByPhone(ctx context.Context, phone string) (*domain.Driver, error) {
-	query := `SELECT id, name, phone, status, tier FROM drivers WHERE phone = $1`
+	query := `SELECT ` + driverColumns + ` FROM drivers WHERE phone = $1`
 
-	var driver domain.Driver
-	err := r.q.QueryRowContext(ctx, query, phone).Scan(
-		&driver.ID,
-		&driver.Name,
-		&driver.Phone,
-		&driver.Status,
-		&driver.Tier,
-	)
+	driver, err := scanDriver(r.q.QueryRowContext(ctx, query, phone))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, repository.ErrNotFound
@@ -72,12 +101,27 @@ func (r *DriverRepository) GetByPhone(ctx context.Context, phone string) (*domai
 		return nil, err
 	}
 
-	return &driver, nil
+	return driver, nil
+}
+
+// GetByEmail retrieves a driver by email address.
+func (r *DriverRepository) GetByEmail(ctx context.Context, email string) (*domain.Driver, error) {
+	query := `SELECT ` + driverColumns + ` FROM drivers WHERE email = $1`
+
+	driver, err := scanDriver(r.q.QueryRowContext(ctx, query, email))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return driver, nil
 }
 
 // GetAll retrieves all drivers.
 func (r *DriverRepository) GetAll(ctx context.Context) ([]*domain.Driver, error) {
-	query := `SELECT id, COALESCE(name, ''), COALESCE(phone, ''), status, tier FROM drivers ORDER BY id`
+	query := `SELECT ` + driverColumns + ` FROM drivers ORDER BY id`
 	rows, err := r.q.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
@@ -86,20 +130,18 @@ func (r *DriverRepository) GetAll(ctx context.Context) ([]*domain.Driver, error)
 
 	var drivers []*domain.Driver
 	for rows.Next() {
-		var driver domain.Driver
-		if err := rows.Scan(&driver.ID, &driver.Name, &driver.Phone, &driver.Status, &driver.Tier); err != nil {
+		driver, err := scanDriver(rows)
+		if err != nil {
 			return nil, err
 		}
-		drivers = append(drivers, &driver)
+		drivers = append(drivers, driver)
 	}
 	return drivers, rows.Err()
 }
 
 // UpdateStatus updates the status of a driver.
 func (r *DriverRepository) UpdateStatus(ctx context.Context, id string, status domain.DriverStatus) error {
-	query := `UPDATE drivers SET status = $1 WHERE id = $2`
-
-	result, err := r.q.ExecContext(ctx, query, status, id)
+	result, err := r.q.ExecContext(ctx, driverUpdateStatusQuery, status, id)
 	if err != nil {
 		return err
 	}
@@ -115,3 +157,183 @@ func (r *DriverRepository) UpdateStatus(ctx context.Context, id string, status d
 
 	return nil
 }
+
+// TransitionStatus moves a driver from one status to another. The status
+// check and update are a single statement, so concurrent callers cannot both
+// succeed.
+func (r *DriverRepository) TransitionStatus(ctx context.Context, id string, from, to domain.DriverStatus) (bool, error) {
+	query := `UPDATE drivers SET status = $1, updated_at = NOW() WHERE id = $2 AND status = $3`
+
+	result, err := r.q.ExecContext(ctx, query, to, id, from)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// ChangeTier moves a driver to change.Tier and records the change. The row
+// is locked while it is read, so concurrent changes each record the tier
+// they replaced.
+func (r *DriverRepository) ChangeTier(ctx context.Context, change *domain.DriverTierChange) error {
+	query := `
+		WITH previous AS (
+			SELECT id, tier FROM drivers WHERE id = $2 FOR UPDATE
+		), updated AS (
+			UPDATE drivers d SET tier = $3, updated_at = NOW()
+			FROM previous
+			WHERE d.id = previous.id
+			RETURNING d.id, previous.tier AS previous_tier
+		)
+		INSERT INTO driver_tier_changes (id, driver_id, previous_tier, tier)
+		SELECT $1, id, previous_tier, $3 FROM updated
+		RETURNING previous_tier, changed_at
+	`
+
+	err := r.q.QueryRowContext(ctx, query, change.ID, change.DriverID, change.Tier).Scan(&change.PreviousTier, &change.ChangedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repository.ErrNotFound
+	}
+	return err
+}
+
+// lastPositionDistanceKm is the haversine distance, in km, from the last
+// position joined by a Near filter to the point bound at the two given
+// parameter indexes.
+const lastPositionDistanceKm = `(2 * 6371.0 * ASIN(SQRT(
+	POWER(SIN(RADIANS(last_position.lat - $%[1]d) / 2), 2) +
+	COS(RADIANS($%[1]d)) * COS(RADIANS(last_position.lat)) * POWER(SIN(RADIANS(last_position.lng - $%[2]d) / 2), 2))))`
+
+// List retrieves a page of drivers matching the filter, along with the total
+// number of matching drivers. All filter values are bound as parameters. A
+// Near filter reads each driver's last position from
+// driver_location_history.
+func (r *DriverRepository) List(ctx context.Context, filter repository.DriverFilter) ([]*domain.Driver, int, error) {
+	var conditions []string
+	var args []any
+
+	from := "drivers"
+	order := "id"
+	if near := filter.Near; near != nil {
+		from += ` JOIN LATERAL (
+			SELECT lat, lng FROM driver_location_history h
+			WHERE h.driver_id = drivers.id
+			ORDER BY recorded_at DESC LIMIT 1
+		) last_position ON TRUE`
+		args = append(args, near.Lat, near.Lng, near.RadiusKm)
+		distance := fmt.Sprintf(lastPositionDistanceKm, len(args)-2, len(args)-1)
+		conditions = append(conditions, fmt.Sprintf("%s <= $%d", distance, len(args)))
+		order = distance + ", id"
+	}
+
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+	if filter.Tier != "" {
+		args = append(args, filter.Tier)
+		conditions = append(conditions, fmt.Sprintf("tier = $%d", len(args)))
+	}
+	if filter.PhonePrefix != "" {
+		// Left-anchored LIKE so idx_drivers_phone_prefix (varchar_pattern_ops) is usable.
+		args = append(args, escapeLike(filter.PhonePrefix)+"%")
+		conditions = append(conditions, fmt.Sprintf("phone LIKE $%d", len(args)))
+	}
+	if filter.Name != "" {
+		args = append(args, "%"+escapeLike(filter.Name)+"%")
+		conditions = append(conditions, fmt.Sprintf("name ILIKE $%d", len(args)))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := r.q.QueryRowContext(ctx, `SELECT COUNT(*) FROM `+from+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	query := fmt.Sprintf(
+		`SELECT `+driverColumns+` FROM %s%s ORDER BY %s LIMIT $%d OFFSET $%d`,
+		from, where, order, len(args)-1, len(args),
+	)
+
+	rows, err := r.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var drivers []*domain.Driver
+	for rows.Next() {
+		driver, err := scanDriver(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		drivers = append(drivers, driver)
+	}
+	return drivers, total, rows.Err()
+}
+
+// scanDriver scans a row selected with driverColumns.
+func scanDriver(row rowScanner) (*domain.Driver, error) {
+	var driver domain.Driver
+	var capabilities []byte
+	err := row.Scan(
+		&driver.ID,
+		&driver.Name,
+		&driver.Phone,
+		&driver.Status,
+		&driver.Tier,
+		&driver.Email,
+		&driver.EmailVerified,
+		&driver.VehiclePlate,
+		&driver.City,
+		&capabilities,
+		&driver.CreatedAt,
+		&driver.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if driver.Capabilities, err = unmarshalCapabilities(capabilities); err != nil {
+		return nil, err
+	}
+	return &driver, nil
+}
+
+// marshalCapabilities encodes a capability set for a JSONB column; nil is
+// stored as an empty array.
+func marshalCapabilities(capabilities []string) ([]byte, error) {
+	if capabilities == nil {
+		capabilities = []string{}
+	}
+	return json.Marshal(capabilities)
+}
+
+// unmarshalCapabilities decodes a capability set read from a JSONB column.
+// An empty set decodes to nil.
+func unmarshalCapabilities(data []byte) ([]string, error) {
+	var capabilities []string
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &capabilities); err != nil {
+			return nil, err
+		}
+	}
+	if len(capabilities) == 0 {
+		return nil, nil
+	}
+	return capabilities, nil
+}
+
+// escapeLike escapes LIKE wildcards so user input matches literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,123 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"ride/internal/domain"
+	"ride/internal/repository"
+)
+
+// EarningsRepository is a PostgreSQL implementation of repository.EarningsRepository.
+type EarningsRepository struct {
+	q Querier
+}
+
+// NewEarningsRepository creates a new PostgreSQL earnings repository.
+func NewEarningsRepository(db *sql.DB) *EarningsRepository {
+	return &EarningsRepository{q: db}
+}
+
+// NewEarningsRepositoryWithTx creates an earnings repository using a transaction.
+func NewEarningsRepositoryWithTx(tx *sql.Tx) *EarningsRepository {
+	return &EarningsRepository{q: tx}
+}
+
+// earningsSchema is the part of the schema EarningsRepository reads and writes.
+var earningsSchema = []Table{
+	{Name: "driver_earnings", Columns: []Column{
+		{"id", ColumnText}, {"driver_id", ColumnText}, {"kind", ColumnText}, {"amount", ColumnFloat},
+		{"reference", ColumnText}, {"created_at", ColumnTimestamp},
+	}},
+}
+
+// Credit records an entry unless one with the same reference exists.
+func (r *EarningsRepository) Credit(ctx context.Context, entry *domain.EarningsEntry) (bool, error) {
+	query := `
+		INSERT INTO driver_earnings (id, driver_id, kind, amount, reference, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (reference) DO NOTHING
+	`
+
+	result, err := r.q.ExecContext(ctx, query,
+		entry.ID,
+		entry.DriverID,
+		entry.Kind,
+		entry.Amount,
+		entry.Reference,
+		entry.CreatedAt,
+	)
+	if err != nil {
+		return false, translateConstraintViolation(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// ListByDriver retrieves a driver's ledger entries, oldest first.
+func (r *EarningsRepository) ListByDriver(ctx context.Context, driverID string) ([]*domain.EarningsEntry, error) {
+	query := `
+		SELECT id, driver_id, kind, amount, reference, created_at
+		FROM driver_earnings WHERE driver_id = $1
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.q.QueryContext(ctx, query, driverID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*domain.EarningsEntry
+	for rows.Next() {
+		var entry domain.EarningsEntry
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.DriverID,
+			&entry.Kind,
+			&entry.Amount,
+			&entry.Reference,
+			&entry.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// GetByReference retrieves the entry with the given reference.
+func (r *EarningsRepository) GetByReference(ctx context.Context, reference string) (*domain.EarningsEntry, error) {
+	query := `
+		SELECT id, driver_id, kind, amount, reference, created_at
+		FROM driver_earnings WHERE reference = $1
+	`
+
+	var entry domain.EarningsEntry
+	err := r.q.QueryRowContext(ctx, query, reference).Scan(
+		&entry.ID,
+		&entry.DriverID,
+		&entry.Kind,
+		&entry.Amount,
+		&entry.Reference,
+		&entry.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// Ensure EarningsRepository implements repository.EarningsRepository.
+var _ repository.EarningsRepository = (*EarningsRepository)(nil)
@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"ride/internal/domain"
+	"ride/internal/repository"
+)
+
+// FareAdjustmentRepository is a PostgreSQL implementation of
+// repository.FareAdjustmentRepository.
+type FareAdjustmentRepository struct {
+	q Querier
+}
+
+// NewFareAdjustmentRepository creates a new PostgreSQL fare adjustment repository.
+func NewFareAdjustmentRepository(db *sql.DB) *FareAdjustmentRepository {
+	return &FareAdjustmentRepository{q: db}
+}
+
+// NewFareAdjustmentRepositoryWithTx creates a fare adjustment repository using a transaction.
+func NewFareAdjustmentRepositoryWithTx(tx *sql.Tx) *FareAdjustmentRepository {
+	return &FareAdjustmentRepository{q: tx}
+}
+
+// fareAdjustmentSchema is the part of the schema FareAdjustmentRepository reads and writes.
+var fareAdjustmentSchema = []Table{
+	{Name: "fare_adjustments", Columns: []Column{
+		{"id", ColumnText}, {"trip_id", ColumnText}, {"previous_fare", ColumnFloat}, {"fare", ColumnFloat},
+		{"reason", ColumnText}, {"payment_id", ColumnText}, {"created_at", ColumnTimestamp},
+	}},
+}
+
+// Create records an adjustment.
+func (r *FareAdjustmentRepository) Create(ctx context.Context, adjustment *domain.FareAdjustment) error {
+	query := `
+		INSERT INTO fare_adjustments (id, trip_id, previous_fare, fare, reason, payment_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING created_at
+	`
+
+	err := r.q.QueryRowContext(ctx, query,
+		adjustment.ID,
+		adjustment.TripID,
+		adjustment.PreviousFare,
+		adjustment.Fare,
+		adjustment.Reason,
+		adjustment.PaymentID,
+	).Scan(&adjustment.CreatedAt)
+	return translateConstraintViolation(err)
+}
+
+// SetPaymentID records the payment that settled an adjustment.
+func (r *FareAdjustmentRepository) SetPaymentID(ctx context.Context, id, paymentID string) error {
+	query := `UPDATE fare_adjustments SET payment_id = $1 WHERE id = $2`
+
+	result, err := r.q.ExecContext(ctx, query, paymentID, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+// ListByTripID returns a trip's adjustments, oldest first.
+func (r *FareAdjustmentRepository) ListByTripID(ctx context.Context, tripID string) ([]*domain.FareAdjustment, error) {
+	query := `
+		SELECT id, trip_id, previous_fare, fare, reason, payment_id, created_at
+		FROM fare_adjustments
+		WHERE trip_id = $1
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.q.QueryContext(ctx, query, tripID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var adjustments []*domain.FareAdjustment
+	for rows.Next() {
+		var a domain.FareAdjustment
+		if err := rows.Scan(&a.ID, &a.TripID, &a.PreviousFare, &a.Fare, &a.Reason, &a.PaymentID, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		adjustments = append(adjustments, &a)
+	}
+	return adjustments, rows.Err()
+}
+
+// Ensure FareAdjustmentRepository implements repository.FareAdjustmentRepository.
+var _ repository.FareAdjustmentRepository = (*FareAdjustmentRepository)(nil)
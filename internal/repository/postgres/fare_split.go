@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/repository"
+)
+
+// FareSplitRepository is a PostgreSQL implementation of
+// repository.FareSplitRepository.
+type FareSplitRepository struct {
+	q Querier
+}
+
+// NewFareSplitRepository creates a new PostgreSQL fare split repository.
+func NewFareSplitRepository(db *sql.DB) *FareSplitRepository {
+	return &FareSplitRepository{q: db}
+}
+
+// fareSplitSchema is the part of the schema FareSplitRepository reads and writes.
+var fareSplitSchema = []Table{
+	{Name: "trip_fare_splits", Columns: []Column{
+		{"trip_id", ColumnText}, {"owner_id", ColumnText}, {"shares", ColumnJSON},
+		{"created_at", ColumnTimestamp}, {"updated_at", ColumnTimestamp},
+	}},
+}
+
+// fareShareRow is a FareShare as stored in the shares column.
+type fareShareRow struct {
+	RiderID   string  `json:"rider_id"`
+	Weight    float64 `json:"weight,omitempty"`
+	Amount    float64 `json:"amount,omitempty"`
+	PaymentID string  `json:"payment_id,omitempty"`
+	Absorbed  bool    `json:"absorbed,omitempty"`
+}
+
+// Save stores a trip's split, replacing any it already has.
+func (r *FareSplitRepository) Save(ctx context.Context, split *domain.FareSplit) error {
+	query := `
+		INSERT INTO trip_fare_splits (trip_id, owner_id, shares, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $4)
+		ON CONFLICT (trip_id) DO UPDATE
+		SET owner_id = EXCLUDED.owner_id, shares = EXCLUDED.shares, updated_at = EXCLUDED.updated_at
+		RETURNING created_at
+	`
+
+	rows := make([]fareShareRow, len(split.Shares))
+	for i, share := range split.Shares {
+		rows[i] = fareShareRow(share)
+	}
+	shares, err := json.Marshal(rows)
+	if err != nil {
+		return err
+	}
+
+	split.UpdatedAt = time.Now()
+	err = r.q.QueryRowContext(ctx, query, split.TripID, split.OwnerID, shares, split.UpdatedAt).Scan(&split.CreatedAt)
+	return translateConstraintViolation(err)
+}
+
+// GetByTripID retrieves a trip's split, or nil if its fare is not split.
+func (r *FareSplitRepository) GetByTripID(ctx context.Context, tripID string) (*domain.FareSplit, error) {
+	query := `
+		SELECT trip_id, owner_id, shares, created_at, updated_at
+		FROM trip_fare_splits
+		WHERE trip_id = $1
+	`
+
+	var split domain.FareSplit
+	var shares []byte
+	err := r.q.QueryRowContext(ctx, query, tripID).Scan(
+		&split.TripID,
+		&split.OwnerID,
+		&shares,
+		&split.CreatedAt,
+		&split.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []fareShareRow
+	if err := json.Unmarshal(shares, &rows); err != nil {
+		return nil, err
+	}
+	split.Shares = make([]domain.FareShare, len(rows))
+	for i, row := range rows {
+		split.Shares[i] = domain.FareShare(row)
+	}
+	return &split, nil
+}
+
+// Ensure FareSplitRepository implements repository.FareSplitRepository.
+var _ repository.FareSplitRepository = (*FareSplitRepository)(nil)
@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"ride/internal/domain"
+	"ride/internal/repository"
+)
+
+// FeatureFlagRepository is a PostgreSQL implementation of repository.FeatureFlagRepository.
+type FeatureFlagRepository struct {
+	q Querier
+}
+
+// NewFeatureFlagRepository creates a new PostgreSQL feature flag repository.
+func NewFeatureFlagRepository(db *sql.DB) *FeatureFlagRepository {
+	return &FeatureFlagRepository{q: db}
+}
+
+// NewFeatureFlagRepositoryWithTx creates a feature flag repository using a transaction.
+func NewFeatureFlagRepositoryWithTx(tx *sql.Tx) *FeatureFlagRepository {
+	return &FeatureFlagRepository{q: tx}
+}
+
+// featureFlagSchema is the part of the schema FeatureFlagRepository reads and writes.
+var featureFlagSchema = []Table{
+	{Name: "feature_flags", Columns: []Column{
+		{"name", ColumnText}, {"enabled", ColumnBool}, {"percentage", ColumnInteger},
+		{"cities", ColumnJSON}, {"updated_at", ColumnTimestamp},
+	}},
+}
+
+// Upsert creates the flag, or replaces the rules of an existing one.
+func (r *FeatureFlagRepository) Upsert(ctx context.Context, flag *domain.FeatureFlag) error {
+	query := `
+		INSERT INTO feature_flags (name, enabled, percentage, cities, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (name) DO UPDATE
+		SET enabled = EXCLUDED.enabled, percentage = EXCLUDED.percentage, cities = EXCLUDED.cities, updated_at = EXCLUDED.updated_at
+	`
+
+	cities := flag.Cities
+	if cities == nil {
+		cities = []string{}
+	}
+	data, err := json.Marshal(cities)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.q.ExecContext(ctx, query, flag.Name, flag.Enabled, flag.Percentage, data, flag.UpdatedAt)
+	return err
+}
+
+// GetAll retrieves every flag, by name.
+func (r *FeatureFlagRepository) GetAll(ctx context.Context) ([]*domain.FeatureFlag, error) {
+	query := `SELECT name, enabled, percentage, cities, updated_at FROM feature_flags ORDER BY name`
+
+	rows, err := r.q.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var flags []*domain.FeatureFlag
+	for rows.Next() {
+		var flag domain.FeatureFlag
+		var cities []byte
+		if err := rows.Scan(&flag.Name, &flag.Enabled, &flag.Percentage, &cities, &flag.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if len(cities) > 0 {
+			if err := json.Unmarshal(cities, &flag.Cities); err != nil {
+				return nil, err
+			}
+		}
+		flags = append(flags, &flag)
+	}
+
+	return flags, rows.Err()
+}
+
+// Ensure FeatureFlagRepository implements repository.FeatureFlagRepository.
+var _ repository.FeatureFlagRepository = (*FeatureFlagRepository)(nil)
@@ -0,0 +1,137 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/repository"
+)
+
+// LocationHistoryRepository is a PostgreSQL implementation of repository.LocationHistoryRepository.
+type LocationHistoryRepository struct {
+	q Querier
+}
+
+// NewLocationHistoryRepository creates a new PostgreSQL location history repository.
+func NewLocationHistoryRepository(db *sql.DB) *LocationHistoryRepository {
+	return &LocationHistoryRepository{q: db}
+}
+
+// locationHistorySchema is the part of the schema LocationHistoryRepository reads and writes.
+var locationHistorySchema = []Table{
+	{Name: "driver_location_history", Columns: []Column{
+		{"id", ColumnInteger}, {"driver_id", ColumnText}, {"lat", ColumnFloat}, {"lng", ColumnFloat},
+		{"heading", ColumnFloat}, {"recorded_at", ColumnTimestamp},
+	}},
+}
+
+// onlineHoursSQL aggregates location points into hours online: every
+// 5-minute slot with at least one point counts.
+const onlineHoursSQL = `COUNT(DISTINCT floor(extract(epoch FROM recorded_at) / 300)) / 12.0`
+
+// CreateBatch appends a batch of location points in a single INSERT.
+func (r *LocationHistoryRepository) CreateBatch(ctx context.Context, points []*domain.LocationPoint) error {
+	if len(points) == 0 {
+		return nil
+	}
+
+	const columns = 5
+	values := make([]string, 0, len(points))
+	args := make([]any, 0, len(points)*columns)
+	for i, p := range points {
+		n := i * columns
+		values = append(values, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5))
+		args = append(args, p.DriverID, p.Lat, p.Lng, p.Heading, p.RecordedAt)
+	}
+
+	query := `INSERT INTO driver_location_history (driver_id, lat, lng, heading, recorded_at) VALUES ` +
+		strings.Join(values, ", ")
+	_, err := r.q.ExecContext(ctx, query, args...)
+	return err
+}
+
+// ListByDriver retrieves a driver's points recorded in [from, to], oldest first.
+func (r *LocationHistoryRepository) ListByDriver(ctx context.Context, driverID string, from, to time.Time) ([]*domain.LocationPoint, error) {
+	query := `
+		SELECT driver_id, lat, lng, heading, recorded_at
+		FROM driver_location_history
+		WHERE driver_id = $1 AND recorded_at BETWEEN $2 AND $3
+		ORDER BY recorded_at, id
+	`
+
+	rows, err := r.q.QueryContext(ctx, query, driverID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var points []*domain.LocationPoint
+	for rows.Next() {
+		var p domain.LocationPoint
+		if err := rows.Scan(&p.DriverID, &p.Lat, &p.Lng, &p.Heading, &p.RecordedAt); err != nil {
+			return nil, err
+		}
+		points = append(points, &p)
+	}
+
+	return points, rows.Err()
+}
+
+// OnlineHours returns the driver's hours online in [from, to).
+func (r *LocationHistoryRepository) OnlineHours(ctx context.Context, driverID string, from, to time.Time) (float64, error) {
+	query := `
+		SELECT ` + onlineHoursSQL + `
+		FROM driver_location_history
+		WHERE driver_id = $1 AND recorded_at >= $2 AND recorded_at < $3
+	`
+
+	var hours float64
+	err := r.q.QueryRowContext(ctx, query, driverID, from, to).Scan(&hours)
+	return hours, err
+}
+
+// ListOnlineHours returns the hours online in [from, to) of every driver
+// online for at least minHours, by driver ID.
+func (r *LocationHistoryRepository) ListOnlineHours(ctx context.Context, from, to time.Time, minHours float64) (map[string]float64, error) {
+	query := `
+		SELECT driver_id, ` + onlineHoursSQL + ` AS hours
+		FROM driver_location_history
+		WHERE recorded_at >= $1 AND recorded_at < $2
+		GROUP BY driver_id
+		HAVING ` + onlineHoursSQL + ` >= $3
+	`
+
+	rows, err := r.q.QueryContext(ctx, query, from, to, minHours)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hours := make(map[string]float64)
+	for rows.Next() {
+		var driverID string
+		var h float64
+		if err := rows.Scan(&driverID, &h); err != nil {
+			return nil, err
+		}
+		hours[driverID] = h
+	}
+
+	return hours, rows.Err()
+}
+
+// DeleteBefore removes points recorded before cutoff.
+func (r *LocationHistoryRepository) DeleteBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	result, err := r.q.ExecContext(ctx, `DELETE FROM driver_location_history WHERE recorded_at < $1`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// Ensure LocationHistoryRepository implements repository.LocationHistoryRepository.
+var _ repository.LocationHistoryRepository = (*LocationHistoryRepository)(nil)
@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/repository"
+)
+
+// MatchAttemptRepository is a PostgreSQL implementation of repository.MatchAttemptRepository.
+type MatchAttemptRepository struct {
+	q Querier
+}
+
+// NewMatchAttemptRepository creates a new PostgreSQL match attempt repository.
+func NewMatchAttemptRepository(db *sql.DB) *MatchAttemptRepository {
+	return &MatchAttemptRepository{q: db}
+}
+
+// matchAttemptSchema is the part of the schema MatchAttemptRepository reads and writes.
+var matchAttemptSchema = []Table{
+	{Name: "match_attempts", Columns: []Column{
+		{"id", ColumnText}, {"ride_id", ColumnText}, {"tier", ColumnText}, {"radius_km", ColumnFloat},
+		{"candidates_found", ColumnInteger}, {"skipped_offline", ColumnInteger},
+		{"skipped_tier", ColumnInteger}, {"skipped_locked", ColumnInteger},
+		{"skipped_stale", ColumnInteger}, {"outcome", ColumnText}, {"assigned_driver_id", ColumnText},
+		{"error", ColumnText}, {"duration_ms", ColumnInteger}, {"created_at", ColumnTimestamp},
+		{"degraded", ColumnBool}, {"skipped_excluded", ColumnInteger},
+		{"skipped_direction", ColumnInteger}, {"skipped_capability", ColumnInteger},
+	}},
+}
+
+// Create persists a match attempt.
+func (r *MatchAttemptRepository) Create(ctx context.Context, attempt *domain.MatchAttempt) error {
+	query := `
+		INSERT INTO match_attempts (id, ride_id, tier, radius_km, candidates_found, skipped_offline, skipped_tier,
+			skipped_locked, skipped_stale, outcome, assigned_driver_id, error, duration_ms, created_at, degraded,
+			skipped_excluded, skipped_direction, skipped_capability)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+	`
+
+	_, err := r.q.ExecContext(ctx, query,
+		attempt.ID,
+		attempt.RideID,
+		attempt.Tier,
+		attempt.RadiusKm,
+		attempt.CandidatesFound,
+		attempt.SkippedOffline,
+		attempt.SkippedTier,
+		attempt.SkippedLocked,
+		attempt.SkippedStale,
+		attempt.Outcome,
+		attempt.AssignedDriverID,
+		attempt.Error,
+		attempt.Duration.Milliseconds(),
+		attempt.CreatedAt,
+		attempt.Degraded,
+		attempt.SkippedExcluded,
+		attempt.SkippedDirection,
+		attempt.SkippedCapability,
+	)
+	return translateConstraintViolation(err)
+}
+
+// ListByRide retrieves a ride's match attempts, oldest first.
+func (r *MatchAttemptRepository) ListByRide(ctx context.Context, rideID string) ([]*domain.MatchAttempt, error) {
+	query := `
+		SELECT id, ride_id, tier, radius_km, candidates_found, skipped_offline, skipped_tier,
+			skipped_locked, skipped_stale, outcome, assigned_driver_id, error, duration_ms, created_at, degraded,
+			skipped_excluded, skipped_direction, skipped_capability
+		FROM match_attempts WHERE ride_id = $1
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.q.QueryContext(ctx, query, rideID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attempts []*domain.MatchAttempt
+	for rows.Next() {
+		var attempt domain.MatchAttempt
+		var durationMs int64
+		if err := rows.Scan(
+			&attempt.ID,
+			&attempt.RideID,
+			&attempt.Tier,
+			&attempt.RadiusKm,
+			&attempt.CandidatesFound,
+			&attempt.SkippedOffline,
+			&attempt.SkippedTier,
+			&attempt.SkippedLocked,
+			&attempt.SkippedStale,
+			&attempt.Outcome,
+			&attempt.AssignedDriverID,
+			&attempt.Error,
+			&durationMs,
+			&attempt.CreatedAt,
+			&attempt.Degraded,
+			&attempt.SkippedExcluded,
+			&attempt.SkippedDirection,
+			&attempt.SkippedCapability,
+		); err != nil {
+			return nil, err
+		}
+		attempt.Duration = time.Duration(durationMs) * time.Millisecond
+		attempts = append(attempts, &attempt)
+	}
+
+	return attempts, rows.Err()
+}
+
+// Ensure MatchAttemptRepository implements repository.MatchAttemptRepository.
+var _ repository.MatchAttemptRepository = (*MatchAttemptRepository)(nil)
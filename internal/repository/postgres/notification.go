@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"ride/internal/domain"
+	"ride/internal/repository"
+)
+
+// NotificationRepository is a PostgreSQL implementation of repository.NotificationRepository.
+type NotificationRepository struct {
+	q Querier
+}
+
+// NewNotificationRepository creates a new PostgreSQL notification repository.
+func NewNotificationRepository(db *sql.DB) *NotificationRepository {
+	return &NotificationRepository{q: db}
+}
+
+// NewNotificationRepositoryWithTx creates a notification repository using a transaction.
+func NewNotificationRepositoryWithTx(tx *sql.Tx) *NotificationRepository {
+	return &NotificationRepository{q: tx}
+}
+
+// notificationSchema is the part of the schema NotificationRepository reads and writes.
+var notificationSchema = []Table{
+	{Name: "notification_outbox", Columns: []Column{
+		{"id", ColumnInteger}, {"recipient_id", ColumnText}, {"type", ColumnText}, {"title", ColumnText},
+		{"message", ColumnText}, {"data", ColumnJSON}, {"created_at", ColumnTimestamp},
+	}},
+}
+
+// Append records a notification in the outbox and sets its ID.
+func (r *NotificationRepository) Append(ctx context.Context, event *domain.NotificationEvent) error {
+	query := `
+		INSERT INTO notification_outbox (recipient_id, type, title, message, data, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+
+	return r.q.QueryRowContext(ctx, query,
+		event.RecipientID,
+		event.Type,
+		event.Title,
+		event.Message,
+		data,
+		event.CreatedAt,
+	).Scan(&event.ID)
+}
+
+// ListSince returns up to limit notifications for the recipient after afterID, oldest first.
+func (r *NotificationRepository) ListSince(ctx context.Context, recipientID string, afterID int64, limit int) ([]*domain.NotificationEvent, error) {
+	query := `
+		SELECT id, recipient_id, type, title, message, data, created_at
+		FROM notification_outbox
+		WHERE recipient_id = $1 AND id > $2
+		ORDER BY id
+		LIMIT $3
+	`
+
+	rows, err := r.q.QueryContext(ctx, query, recipientID, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*domain.NotificationEvent
+	for rows.Next() {
+		var event domain.NotificationEvent
+		var data []byte
+		if err := rows.Scan(
+			&event.ID,
+			&event.RecipientID,
+			&event.Type,
+			&event.Title,
+			&event.Message,
+			&data,
+			&event.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, &event.Data); err != nil {
+				return nil, err
+			}
+		}
+		events = append(events, &event)
+	}
+
+	return events, rows.Err()
+}
+
+// Ensure NotificationRepository implements repository.NotificationRepository.
+var _ repository.NotificationRepository = (*NotificationRepository)(nil)
@@ -0,0 +1,220 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/repository"
+)
+
+// NotificationDeliveryRepository is a PostgreSQL implementation of
+// repository.NotificationDeliveryRepository.
+type NotificationDeliveryRepository struct {
+	q Querier
+}
+
+// NewNotificationDeliveryRepository creates a new PostgreSQL notification delivery repository.
+func NewNotificationDeliveryRepository(db *sql.DB) *NotificationDeliveryRepository {
+	return &NotificationDeliveryRepository{q: db}
+}
+
+// NewNotificationDeliveryRepositoryWithTx creates a notification delivery repository using a transaction.
+func NewNotificationDeliveryRepositoryWithTx(tx *sql.Tx) *NotificationDeliveryRepository {
+	return &NotificationDeliveryRepository{q: tx}
+}
+
+// notificationDeliverySchema is the part of the schema NotificationDeliveryRepository reads and writes.
+var notificationDeliverySchema = []Table{
+	{Name: "notification_deliveries", Columns: []Column{
+		{"id", ColumnInteger}, {"channel", ColumnText}, {"recipient_id", ColumnText}, {"type", ColumnText},
+		{"title", ColumnText}, {"message", ColumnText}, {"data", ColumnJSON}, {"status", ColumnText},
+		{"attempts", ColumnInteger}, {"next_attempt_at", ColumnTimestamp}, {"last_error", ColumnText},
+		{"created_at", ColumnTimestamp}, {"updated_at", ColumnTimestamp},
+	}},
+}
+
+const notificationDeliveryColumns = `id, channel, recipient_id, type, title, message, data, status,
+	attempts, next_attempt_at, last_error, created_at, updated_at`
+
+// Create queues a delivery and sets its ID.
+func (r *NotificationDeliveryRepository) Create(ctx context.Context, delivery *domain.NotificationDelivery) error {
+	query := `
+		INSERT INTO notification_deliveries (channel, recipient_id, type, title, message, data, status,
+			attempts, next_attempt_at, last_error, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $11)
+		RETURNING id
+	`
+
+	data, err := json.Marshal(delivery.Data)
+	if err != nil {
+		return err
+	}
+
+	delivery.UpdatedAt = delivery.CreatedAt
+	return r.q.QueryRowContext(ctx, query,
+		delivery.Channel,
+		delivery.RecipientID,
+		delivery.Type,
+		delivery.Title,
+		delivery.Message,
+		data,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.NextAttemptAt,
+		delivery.LastError,
+		delivery.CreatedAt,
+	).Scan(&delivery.ID)
+}
+
+// ClaimDue returns up to limit due PENDING deliveries, oldest due first,
+// leasing them in the same statement. Rows another dispatcher has locked are
+// skipped rather than waited for.
+func (r *NotificationDeliveryRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*domain.NotificationDelivery, error) {
+	query := `
+		UPDATE notification_deliveries
+		SET next_attempt_at = $2
+		WHERE id IN (
+			SELECT id FROM notification_deliveries
+			WHERE status = 'PENDING' AND next_attempt_at <= $1
+			ORDER BY next_attempt_at, id
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + notificationDeliveryColumns
+
+	rows, err := r.q.QueryContext(ctx, query, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanNotificationDeliveries(rows)
+}
+
+// Update stores a delivery's status, attempts, next attempt and last error.
+func (r *NotificationDeliveryRepository) Update(ctx context.Context, delivery *domain.NotificationDelivery) error {
+	query := `
+		UPDATE notification_deliveries
+		SET status = $2, attempts = $3, next_attempt_at = $4, last_error = $5, updated_at = $6
+		WHERE id = $1
+	`
+
+	result, err := r.q.ExecContext(ctx, query,
+		delivery.ID,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.NextAttemptAt,
+		delivery.LastError,
+		delivery.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// ListByStatus returns up to limit deliveries with the status, most recently
+// updated first.
+func (r *NotificationDeliveryRepository) ListByStatus(ctx context.Context, status domain.NotificationDeliveryStatus, limit int) ([]*domain.NotificationDelivery, error) {
+	query := `
+		SELECT ` + notificationDeliveryColumns + `
+		FROM notification_deliveries
+		WHERE status = $1
+		ORDER BY updated_at DESC, id DESC
+		LIMIT $2
+	`
+
+	rows, err := r.q.QueryContext(ctx, query, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanNotificationDeliveries(rows)
+}
+
+// CountByStatus returns how many deliveries have the status.
+func (r *NotificationDeliveryRepository) CountByStatus(ctx context.Context, status domain.NotificationDeliveryStatus) (int, error) {
+	var count int
+	err := r.q.QueryRowContext(ctx, `SELECT COUNT(*) FROM notification_deliveries WHERE status = $1`, status).Scan(&count)
+	return count, err
+}
+
+// Requeue moves a DEAD delivery back to PENDING, or returns the delivery
+// unchanged if it is not DEAD.
+func (r *NotificationDeliveryRepository) Requeue(ctx context.Context, id int64, now time.Time) (*domain.NotificationDelivery, error) {
+	query := `
+		UPDATE notification_deliveries
+		SET status = 'PENDING', attempts = 0, next_attempt_at = $2, updated_at = $2
+		WHERE id = $1 AND status = 'DEAD'
+		RETURNING ` + notificationDeliveryColumns
+
+	rows, err := r.q.QueryContext(ctx, query, id, now)
+	if err != nil {
+		return nil, err
+	}
+	deliveries, err := scanNotificationDeliveries(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(deliveries) == 1 {
+		return deliveries[0], nil
+	}
+
+	rows, err = r.q.QueryContext(ctx, `SELECT `+notificationDeliveryColumns+` FROM notification_deliveries WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	if deliveries, err = scanNotificationDeliveries(rows); err != nil {
+		return nil, err
+	}
+	if len(deliveries) == 0 {
+		return nil, repository.ErrNotFound
+	}
+	return deliveries[0], nil
+}
+
+// scanNotificationDeliveries reads and closes rows of notificationDeliveryColumns.
+func scanNotificationDeliveries(rows *sql.Rows) ([]*domain.NotificationDelivery, error) {
+	defer rows.Close()
+
+	var deliveries []*domain.NotificationDelivery
+	for rows.Next() {
+		var d domain.NotificationDelivery
+		var data []byte
+		if err := rows.Scan(
+			&d.ID,
+			&d.Channel,
+			&d.RecipientID,
+			&d.Type,
+			&d.Title,
+			&d.Message,
+			&data,
+			&d.Status,
+			&d.Attempts,
+			&d.NextAttemptAt,
+			&d.LastError,
+			&d.CreatedAt,
+			&d.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, &d.Data); err != nil {
+				return nil, err
+			}
+		}
+		deliveries = append(deliveries, &d)
+	}
+	return deliveries, rows.Err()
+}
+
+// Ensure NotificationDeliveryRepository implements repository.NotificationDeliveryRepository.
+var _ repository.NotificationDeliveryRepository = (*NotificationDeliveryRepository)(nil)
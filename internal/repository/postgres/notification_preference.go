@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"ride/internal/domain"
+	"ride/internal/repository"
+)
+
+// NotificationPreferenceRepository is a PostgreSQL implementation of
+// repository.NotificationPreferenceRepository.
+type NotificationPreferenceRepository struct {
+	q Querier
+}
+
+// NewNotificationPreferenceRepository creates a new PostgreSQL notification preference repository.
+func NewNotificationPreferenceRepository(db *sql.DB) *NotificationPreferenceRepository {
+	return &NotificationPreferenceRepository{q: db}
+}
+
+// NewNotificationPreferenceRepositoryWithTx creates a notification preference repository using a transaction.
+func NewNotificationPreferenceRepositoryWithTx(tx *sql.Tx) *NotificationPreferenceRepository {
+	return &NotificationPreferenceRepository{q: tx}
+}
+
+// notificationPreferenceSchema is the part of the schema NotificationPreferenceRepository reads and writes.
+var notificationPreferenceSchema = []Table{
+	{Name: "notification_preferences", Columns: []Column{
+		{"recipient_id", ColumnText}, {"type", ColumnText}, {"channel", ColumnText},
+		{"enabled", ColumnBool}, {"updated_at", ColumnTimestamp},
+	}},
+}
+
+// Upsert records each preference in a single statement, replacing any
+// earlier setting for the same recipient, type and channel.
+func (r *NotificationPreferenceRepository) Upsert(ctx context.Context, preferences []*domain.NotificationPreference) error {
+	if len(preferences) == 0 {
+		return nil
+	}
+
+	rows := make([]string, 0, len(preferences))
+	args := make([]any, 0, len(preferences)*5)
+	for i, p := range preferences {
+		n := i * 5
+		rows = append(rows, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5))
+		args = append(args, p.RecipientID, p.Type, p.Channel, p.Enabled, p.UpdatedAt)
+	}
+
+	query := `
+		INSERT INTO notification_preferences (recipient_id, type, channel, enabled, updated_at)
+		VALUES ` + strings.Join(rows, ", ") + `
+		ON CONFLICT (recipient_id, type, channel) DO UPDATE
+		SET enabled = EXCLUDED.enabled, updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := r.q.ExecContext(ctx, query, args...)
+	return err
+}
+
+// ListByRecipient retrieves a recipient's preferences by type, then channel.
+func (r *NotificationPreferenceRepository) ListByRecipient(ctx context.Context, recipientID string) ([]*domain.NotificationPreference, error) {
+	query := `
+		SELECT recipient_id, type, channel, enabled, updated_at
+		FROM notification_preferences
+		WHERE recipient_id = $1
+		ORDER BY type, channel
+	`
+
+	rows, err := r.q.QueryContext(ctx, query, recipientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var preferences []*domain.NotificationPreference
+	for rows.Next() {
+		var p domain.NotificationPreference
+		if err := rows.Scan(&p.RecipientID, &p.Type, &p.Channel, &p.Enabled, &p.UpdatedAt); err != nil {
+			return nil, err
+		}
+		preferences = append(preferences, &p)
+	}
+
+	return preferences, rows.Err()
+}
+
+// Ensure NotificationPreferenceRepository implements repository.NotificationPreferenceRepository.
+var _ repository.NotificationPreferenceRepository = (*NotificationPreferenceRepository)(nil)
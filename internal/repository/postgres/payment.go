@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
 	"ride/internal/domain"
 	"ride/internal/repository"
@@ -24,28 +25,48 @@ func NewPaymentRepositoryWithTx(tx *sql.Tx) *PaymentRepository {
 	return &PaymentRepository{q: tx}
 }
 
+// paymentSchema is the part of the schema PaymentRepository reads and writes.
+var paymentSchema = []Table{
+	{Name: "payments", Columns: []Column{
+		{"id", ColumnText}, {"trip_id", ColumnText}, {"amount", ColumnFloat}, {"status", ColumnText},
+		{"idempotency_key", ColumnText}, {"created_at", ColumnTimestamp},
+		{"updated_at", ColumnTimestamp}, {"instrument_id", ColumnText}, {"fee", ColumnFloat},
+		{"failure_reason", ColumnText}, {"auth_id", ColumnText},
+	}},
+}
+
 // Create persists a new payment.
 func (r *PaymentRepository) Create(ctx context.Context, payment *domain.Payment) error {
 	query := `
-		INSERT INTO payments (id, trip_id, amount, status, idempotency_key)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO payments (id, trip_id, amount, status, idempotency_key, created_at, updated_at, instrument_id, fee, auth_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	`
 
+	if payment.CreatedAt.IsZero() {
+		payment.CreatedAt = time.Now()
+	}
+	payment.UpdatedAt = payment.CreatedAt
+
 	_, err := r.q.ExecContext(ctx, query,
 		payment.ID,
 		payment.TripID,
 		payment.Amount,
 		payment.Status,
 		payment.IdempotencyKey,
+		payment.CreatedAt,
+		payment.UpdatedAt,
+		payment.InstrumentID,
+		payment.Fee,
+		payment.AuthID,
 	)
 
-	return err
+	return translateConstraintViolation(err)
 }
 
 // GetByID retrieves a payment by ID.
 func (r *PaymentRepository) GetByID(ctx context.Context, id string) (*domain.Payment, error) {
 	query := `
-		SELECT id, trip_id, amount, status, idempotency_key
+		SELECT id, trip_id, amount, status, idempotency_key, created_at, updated_at, instrument_id, fee, failure_reason, auth_id
 		FROM payments WHERE id = $1
 	`
 
@@ -56,6 +77,12 @@ func (r *PaymentRepository) GetByID(ctx context.Context, id string) (*domain.Pay
 		&payment.Amount,
 		&payment.Status,
 		&payment.IdempotencyKey,
+		&payment.CreatedAt,
+		&payment.UpdatedAt,
+		&payment.InstrumentID,
+		&payment.Fee,
+		&payment.FailureReason,
+		&payment.AuthID,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -71,7 +98,7 @@ func (r *PaymentRepository) GetByID(ctx context.Context, id string) (*domain.Pay
 // Returns nil if no payment exists with the given key.
 func (r *PaymentRepository) GetByIdempotencyKey(ctx context.Context, key string) (*domain.Payment, error) {
 	query := `
-		SELECT id, trip_id, amount, status, idempotency_key
+		SELECT id, trip_id, amount, status, idempotency_key, created_at, updated_at, instrument_id, fee, failure_reason, auth_id
 		FROM payments WHERE idempotency_key = $1
 	`
 
@@ -82,6 +109,12 @@ func (r *PaymentRepository) GetByIdempotencyKey(ctx context.Context, key string)
 		&payment.Amount,
 		&payment.Status,
 		&payment.IdempotencyKey,
+		&payment.CreatedAt,
+		&payment.UpdatedAt,
+		&payment.InstrumentID,
+		&payment.Fee,
+		&payment.FailureReason,
+		&payment.AuthID,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -95,7 +128,7 @@ func (r *PaymentRepository) GetByIdempotencyKey(ctx context.Context, key string)
 
 // UpdateStatus updates the status of a payment.
 func (r *PaymentRepository) UpdateStatus(ctx context.Context, id string, status domain.PaymentStatus) error {
-	query := `UPDATE payments SET status = $1 WHERE id = $2`
+	query := `UPDATE payments SET status = $1, failure_reason = '', updated_at = NOW() WHERE id = $2`
 
 	result, err := r.q.ExecContext(ctx, query, status, id)
 	if err != nil {
@@ -113,3 +146,64 @@ func (r *PaymentRepository) UpdateStatus(ctx context.Context, id string, status
 
 	return nil
 }
+
+// MarkFailed sets a payment FAILED with the reason it failed.
+func (r *PaymentRepository) MarkFailed(ctx context.Context, id string, reason string) error {
+	query := `UPDATE payments SET status = $1, failure_reason = $2, updated_at = NOW() WHERE id = $3`
+
+	result, err := r.q.ExecContext(ctx, query, domain.PaymentStatusFailed, reason, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+// TransitionStatus moves a payment from one status to another. It reports
+// false, without error, if the payment was not in the from status.
+func (r *PaymentRepository) TransitionStatus(ctx context.Context, id string, from, to domain.PaymentStatus) (bool, error) {
+	query := `UPDATE payments SET status = $1, updated_at = NOW() WHERE id = $2 AND status = $3`
+
+	result, err := r.q.ExecContext(ctx, query, to, id, from)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// UpdateCharge sets the amount and fee a payment charges and the hold it is
+// captured against.
+func (r *PaymentRepository) UpdateCharge(ctx context.Context, id string, amount, fee float64, authID string) error {
+	query := `UPDATE payments SET amount = $1, fee = $2, auth_id = $3, updated_at = NOW() WHERE id = $4`
+
+	result, err := r.q.ExecContext(ctx, query, amount, fee, authID, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,177 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/repository"
+)
+
+// PaymentInstrumentRepository is a PostgreSQL implementation of
+// repository.PaymentInstrumentRepository.
+type PaymentInstrumentRepository struct {
+	q Querier
+}
+
+// NewPaymentInstrumentRepository creates a new PostgreSQL payment instrument repository.
+func NewPaymentInstrumentRepository(db *sql.DB) *PaymentInstrumentRepository {
+	return &PaymentInstrumentRepository{q: db}
+}
+
+// paymentInstrumentSchema is the part of the schema PaymentInstrumentRepository reads and writes.
+var paymentInstrumentSchema = []Table{
+	{Name: "payment_instruments", Columns: []Column{
+		{"id", ColumnText}, {"user_id", ColumnText}, {"type", ColumnText}, {"token", ColumnText},
+		{"masked_details", ColumnText}, {"is_default", ColumnBool}, {"created_at", ColumnTimestamp},
+		{"updated_at", ColumnTimestamp},
+	}},
+}
+
+const paymentInstrumentColumns = `id, user_id, type, token, masked_details, is_default, created_at, updated_at`
+
+// Create persists a new instrument.
+func (r *PaymentInstrumentRepository) Create(ctx context.Context, instrument *domain.PaymentInstrument) error {
+	query := `
+		INSERT INTO payment_instruments (` + paymentInstrumentColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	if instrument.CreatedAt.IsZero() {
+		instrument.CreatedAt = time.Now()
+	}
+	instrument.UpdatedAt = instrument.CreatedAt
+
+	_, err := r.q.ExecContext(ctx, query,
+		instrument.ID,
+		instrument.UserID,
+		instrument.Type,
+		instrument.Token,
+		instrument.MaskedDetails,
+		instrument.IsDefault,
+		instrument.CreatedAt,
+		instrument.UpdatedAt,
+	)
+	return translateConstraintViolation(err)
+}
+
+// GetByID retrieves an instrument by ID.
+func (r *PaymentInstrumentRepository) GetByID(ctx context.Context, id string) (*domain.PaymentInstrument, error) {
+	query := `SELECT ` + paymentInstrumentColumns + ` FROM payment_instruments WHERE id = $1`
+
+	instrument, err := scanPaymentInstrument(r.q.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+	return instrument, nil
+}
+
+// ListByUser retrieves a user's instruments, oldest first.
+func (r *PaymentInstrumentRepository) ListByUser(ctx context.Context, userID string) ([]*domain.PaymentInstrument, error) {
+	query := `SELECT ` + paymentInstrumentColumns + ` FROM payment_instruments WHERE user_id = $1 ORDER BY created_at, id`
+
+	rows, err := r.q.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var instruments []*domain.PaymentInstrument
+	for rows.Next() {
+		instrument, err := scanPaymentInstrument(rows)
+		if err != nil {
+			return nil, err
+		}
+		instruments = append(instruments, instrument)
+	}
+	return instruments, rows.Err()
+}
+
+// GetDefault retrieves the user's default instrument of the given type,
+// falling back to the most recently added one. Returns nil if the user has
+// no instrument of that type.
+func (r *PaymentInstrumentRepository) GetDefault(ctx context.Context, userID string, instrumentType domain.PaymentMethod) (*domain.PaymentInstrument, error) {
+	query := `
+		SELECT ` + paymentInstrumentColumns + `
+		FROM payment_instruments
+		WHERE user_id = $1 AND type = $2
+		ORDER BY is_default DESC, created_at DESC
+		LIMIT 1
+	`
+
+	instrument, err := scanPaymentInstrument(r.q.QueryRowContext(ctx, query, userID, instrumentType))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return instrument, nil
+}
+
+// SetDefault marks the instrument as the user's default for its type and
+// clears the flag on the user's other instruments of that type, in one
+// statement so there is never more than one default.
+func (r *PaymentInstrumentRepository) SetDefault(ctx context.Context, userID, id string) error {
+	query := `
+		UPDATE payment_instruments
+		SET is_default = (id = $2), updated_at = NOW()
+		WHERE user_id = $1
+		  AND type = (SELECT type FROM payment_instruments WHERE id = $2 AND user_id = $1)
+	`
+
+	result, err := r.q.ExecContext(ctx, query, userID, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// Delete removes one of the user's instruments.
+func (r *PaymentInstrumentRepository) Delete(ctx context.Context, userID, id string) error {
+	result, err := r.q.ExecContext(ctx, `DELETE FROM payment_instruments WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// scanPaymentInstrument scans a row selected with paymentInstrumentColumns.
+func scanPaymentInstrument(row rowScanner) (*domain.PaymentInstrument, error) {
+	var instrument domain.PaymentInstrument
+	err := row.Scan(
+		&instrument.ID,
+		&instrument.UserID,
+		&instrument.Type,
+		&instrument.Token,
+		&instrument.MaskedDetails,
+		&instrument.IsDefault,
+		&instrument.CreatedAt,
+		&instrument.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &instrument, nil
+}
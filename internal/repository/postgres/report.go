@@ -0,0 +1,358 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/repository"
+)
+
+// ReportRepository is a PostgreSQL implementation of repository.ReportRepository.
+type ReportRepository struct {
+	q Querier
+}
+
+// NewReportRepository creates a new PostgreSQL report repository.
+func NewReportRepository(db *sql.DB) *ReportRepository {
+	return &ReportRepository{q: db}
+}
+
+// NewReportRepositoryWithTx creates a report repository using a transaction.
+func NewReportRepositoryWithTx(tx *sql.Tx) *ReportRepository {
+	return &ReportRepository{q: tx}
+}
+
+// reportSchema is the part of the schema ReportRepository reads and writes.
+var reportSchema = []Table{
+	{Name: "trips", Columns: []Column{
+		{"id", ColumnText}, {"ride_id", ColumnText}, {"status", ColumnText}, {"fare", ColumnFloat},
+		{"ended_at", ColumnTimestamp}, {"needs_review", ColumnBool},
+	}},
+	{Name: "rides", Columns: []Column{
+		{"id", ColumnText}, {"estimate_low", ColumnFloat}, {"estimate_high", ColumnFloat},
+	}},
+	{Name: "payments", Columns: []Column{
+		{"id", ColumnText}, {"trip_id", ColumnText}, {"amount", ColumnFloat}, {"status", ColumnText},
+		{"fee", ColumnFloat}, {"idempotency_key", ColumnText}, {"created_at", ColumnTimestamp},
+	}},
+	{Name: "trip_fare_splits", Columns: []Column{{"trip_id", ColumnText}}},
+	{Name: "fare_adjustments", Columns: []Column{{"trip_id", ColumnText}}},
+}
+
+// GetDailyTotals aggregates trips ended or aborted in [from, to) and their
+// payments.
+func (r *ReportRepository) GetDailyTotals(ctx context.Context, from, to time.Time) (*domain.DailyTotals, error) {
+	var totals domain.DailyTotals
+
+	tripQuery := `
+		SELECT COUNT(*), COALESCE(SUM(fare), 0)
+		FROM trips
+		WHERE status IN ('ENDED', 'ABORTED') AND ended_at >= $1 AND ended_at < $2
+	`
+	if err := r.q.QueryRowContext(ctx, tripQuery, from, to).Scan(&totals.TripsEnded, &totals.GrossFares); err != nil {
+		return nil, err
+	}
+
+	paymentQuery := `
+		SELECT
+			COUNT(*) FILTER (WHERE p.status = 'SUCCESS'),
+			COALESCE(SUM(p.amount) FILTER (WHERE p.status = 'SUCCESS'), 0),
+			COUNT(*) FILTER (WHERE p.status = 'FAILED'),
+			COALESCE(SUM(p.amount) FILTER (WHERE p.status = 'FAILED'), 0),
+			COUNT(*) FILTER (WHERE p.status = 'PENDING'),
+			COALESCE(SUM(p.amount) FILTER (WHERE p.status = 'PENDING'), 0),
+			COUNT(*) FILTER (WHERE p.status = 'CASH_DUE'),
+			COALESCE(SUM(p.amount) FILTER (WHERE p.status = 'CASH_DUE'), 0),
+			COUNT(*) FILTER (WHERE p.status = 'REFUNDED'),
+			COALESCE(SUM(p.amount) FILTER (WHERE p.status = 'REFUNDED'), 0)
+		FROM payments p
+		JOIN trips t ON t.id = p.trip_id
+		WHERE t.status IN ('ENDED', 'ABORTED') AND t.ended_at >= $1 AND t.ended_at < $2
+	`
+	err := r.q.QueryRowContext(ctx, paymentQuery, from, to).Scan(
+		&totals.SuccessfulPayments.Count,
+		&totals.SuccessfulPayments.Amount,
+		&totals.FailedPayments.Count,
+		&totals.FailedPayments.Amount,
+		&totals.PendingPayments.Count,
+		&totals.PendingPayments.Amount,
+		&totals.CashAwaiting.Count,
+		&totals.CashAwaiting.Amount,
+		&totals.Refunds.Count,
+		&totals.Refunds.Amount,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &totals, nil
+}
+
+// GetFareDiscrepancies returns trips ended or aborted in [from, to) whose fare differs
+// from the sum of their payments, less refunds, by more than tolerance.
+func (r *ReportRepository) GetFareDiscrepancies(ctx context.Context, from, to time.Time, tolerance float64) ([]domain.FareDiscrepancy, error) {
+	query := `
+		SELECT t.id, t.fare,
+			COALESCE(SUM(p.amount) FILTER (WHERE p.status IN ('SUCCESS', 'FAILED', 'PENDING', 'CASH_DUE')), 0)
+				- COALESCE(SUM(p.amount) FILTER (WHERE p.status = 'REFUNDED'), 0) AS accounted
+		FROM trips t
+		LEFT JOIN payments p ON p.trip_id = t.id
+		WHERE t.status IN ('ENDED', 'ABORTED') AND t.ended_at >= $1 AND t.ended_at < $2
+		GROUP BY t.id, t.fare
+		HAVING ABS(t.fare - COALESCE(SUM(p.amount) FILTER (WHERE p.status IN ('SUCCESS', 'FAILED', 'PENDING', 'CASH_DUE')), 0)
+			+ COALESCE(SUM(p.amount) FILTER (WHERE p.status = 'REFUNDED'), 0)) > $3
+		ORDER BY t.id
+	`
+
+	rows, err := r.q.QueryContext(ctx, query, from, to, tolerance)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var discrepancies []domain.FareDiscrepancy
+	for rows.Next() {
+		var d domain.FareDiscrepancy
+		if err := rows.Scan(&d.TripID, &d.Fare, &d.AccountedAmount); err != nil {
+			return nil, err
+		}
+		discrepancies = append(discrepancies, d)
+	}
+	return discrepancies, rows.Err()
+}
+
+// GetEstimateAccuracy compares the estimated fare range with the fare of
+// ENDED trips ended in [from, to) whose ride carried an estimate.
+func (r *ReportRepository) GetEstimateAccuracy(ctx context.Context, from, to time.Time) (*domain.EstimateAccuracy, error) {
+	query := `
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE t.fare BETWEEN r.estimate_low AND r.estimate_high),
+			COUNT(*) FILTER (WHERE t.fare < r.estimate_low),
+			COUNT(*) FILTER (WHERE t.fare > r.estimate_high),
+			COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY e.error), 0),
+			COALESCE(percentile_cont(0.9) WITHIN GROUP (ORDER BY e.error), 0),
+			COALESCE(percentile_cont(0.99) WITHIN GROUP (ORDER BY e.error), 0)
+		FROM trips t
+		JOIN rides r ON r.id = t.ride_id
+		CROSS JOIN LATERAL (
+			SELECT ABS((r.estimate_low + r.estimate_high) / 2 - t.fare) / t.fare * 100 AS error
+		) e
+		WHERE t.status = 'ENDED' AND t.ended_at >= $1 AND t.ended_at < $2
+			AND NOT t.needs_review AND t.fare > 0 AND r.estimate_high > 0
+	`
+
+	var accuracy domain.EstimateAccuracy
+	err := r.q.QueryRowContext(ctx, query, from, to).Scan(
+		&accuracy.Trips,
+		&accuracy.WithinRange,
+		&accuracy.BelowRange,
+		&accuracy.AboveRange,
+		&accuracy.ErrorP50,
+		&accuracy.ErrorP90,
+		&accuracy.ErrorP99,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &accuracy, nil
+}
+
+// GetOutstandingCollections returns the driver's CASH_DUE payments, oldest trip first.
+func (r *ReportRepository) GetOutstandingCollections(ctx context.Context, driverID string) ([]domain.OutstandingCollection, error) {
+	query := `
+		SELECT p.id, p.trip_id, p.amount, t.ended_at
+		FROM payments p
+		JOIN trips t ON t.id = p.trip_id
+		WHERE t.driver_id = $1 AND p.status = 'CASH_DUE'
+		ORDER BY t.ended_at, p.id
+	`
+
+	rows, err := r.q.QueryContext(ctx, query, driverID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var collections []domain.OutstandingCollection
+	for rows.Next() {
+		var c domain.OutstandingCollection
+		if err := rows.Scan(&c.PaymentID, &c.TripID, &c.Amount, &c.EndedAt); err != nil {
+			return nil, err
+		}
+		collections = append(collections, c)
+	}
+	return collections, rows.Err()
+}
+
+// GetLedgerAnomalies cross-checks trips finished and payments created at or
+// after since, or the whole ledger when since is zero:
+//
+//   - an ENDED trip with a fare owes exactly one fare payment, unless its
+//     fare is held for review; no finished trip has more than one,
+//   - a SUCCESS payment belongs to an ENDED or ABORTED trip,
+//   - a fare payment, less its processing fee, matches its trip's fare
+//     within tolerance, unless the fare is split between riders or was
+//     adjusted after the trip ended,
+//   - no two payments share an idempotency key.
+//
+// A trip's fare payment is the one keyed by the trip alone; further charges,
+// such as tips, the other riders' shares of a split fare or adjustments, are
+// not counted.
+// Anomalies are returned in that order, then by ID.
+func (r *ReportRepository) GetLedgerAnomalies(ctx context.Context, since time.Time, tolerance float64) ([]domain.LedgerAnomaly, error) {
+	var anomalies []domain.LedgerAnomaly
+	checks := []func(context.Context, sql.NullTime, float64) ([]domain.LedgerAnomaly, error){
+		r.tripPaymentCountAnomalies,
+		r.orphanPaymentAnomalies,
+		r.amountMismatchAnomalies,
+		r.duplicateKeyAnomalies,
+	}
+	for _, check := range checks {
+		found, err := check(ctx, nullTime(since), tolerance)
+		if err != nil {
+			return nil, err
+		}
+		anomalies = append(anomalies, found...)
+	}
+	return anomalies, nil
+}
+
+// tripPaymentCountAnomalies finds ENDED trips owed a fare payment that have
+// none and finished trips with more than one.
+func (r *ReportRepository) tripPaymentCountAnomalies(ctx context.Context, since sql.NullTime, _ float64) ([]domain.LedgerAnomaly, error) {
+	query := `
+		SELECT t.id, COUNT(p.id)
+		FROM trips t
+		LEFT JOIN payments p ON p.trip_id = t.id AND p.idempotency_key = 'payment:' || t.id
+		WHERE t.status IN ('ENDED', 'ABORTED') AND ($1::timestamp IS NULL OR t.ended_at >= $1)
+		GROUP BY t.id, t.status, t.fare, t.needs_review
+		HAVING COUNT(p.id) > 1
+			OR (COUNT(p.id) = 0 AND t.status = 'ENDED' AND t.fare > 0 AND NOT t.needs_review)
+		ORDER BY t.id
+	`
+
+	rows, err := r.q.QueryContext(ctx, query, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var anomalies []domain.LedgerAnomaly
+	for rows.Next() {
+		a := domain.LedgerAnomaly{Kind: domain.LedgerTripWithoutPayment}
+		var payments int
+		if err := rows.Scan(&a.TripID, &payments); err != nil {
+			return nil, err
+		}
+		if payments > 1 {
+			a.Kind = domain.LedgerTripMultiplePayments
+			a.Detail = fmt.Sprintf("%d payments", payments)
+		}
+		anomalies = append(anomalies, a)
+	}
+	return anomalies, rows.Err()
+}
+
+// orphanPaymentAnomalies finds SUCCESS payments whose trip is missing or
+// has not finished.
+func (r *ReportRepository) orphanPaymentAnomalies(ctx context.Context, since sql.NullTime, _ float64) ([]domain.LedgerAnomaly, error) {
+	query := `
+		SELECT p.id, p.trip_id, t.status
+		FROM payments p
+		LEFT JOIN trips t ON t.id = p.trip_id
+		WHERE p.status = 'SUCCESS' AND ($1::timestamp IS NULL OR p.created_at >= $1)
+		  AND (t.id IS NULL OR t.status NOT IN ('ENDED', 'ABORTED'))
+		ORDER BY p.id
+	`
+
+	rows, err := r.q.QueryContext(ctx, query, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var anomalies []domain.LedgerAnomaly
+	for rows.Next() {
+		a := domain.LedgerAnomaly{Kind: domain.LedgerPaymentWithoutTrip}
+		var tripStatus sql.NullString
+		if err := rows.Scan(&a.PaymentID, &a.TripID, &tripStatus); err != nil {
+			return nil, err
+		}
+		a.Detail = "trip not found"
+		if tripStatus.Valid {
+			a.Detail = "trip status " + tripStatus.String
+		}
+		anomalies = append(anomalies, a)
+	}
+	return anomalies, rows.Err()
+}
+
+// amountMismatchAnomalies finds fare payments whose amount, less their fee,
+// differs from their finished trip's unsplit, unadjusted fare by more than
+// tolerance.
+func (r *ReportRepository) amountMismatchAnomalies(ctx context.Context, since sql.NullTime, tolerance float64) ([]domain.LedgerAnomaly, error) {
+	query := `
+		SELECT p.id, t.id, t.fare, p.amount - p.fee
+		FROM payments p
+		JOIN trips t ON t.id = p.trip_id
+		WHERE t.status IN ('ENDED', 'ABORTED') AND ($1::timestamp IS NULL OR t.ended_at >= $1)
+		  AND p.idempotency_key = 'payment:' || t.id
+		  AND NOT EXISTS (SELECT 1 FROM trip_fare_splits s WHERE s.trip_id = t.id)
+		  AND NOT EXISTS (SELECT 1 FROM fare_adjustments a WHERE a.trip_id = t.id)
+		  AND ABS(t.fare - (p.amount - p.fee)) > $2
+		ORDER BY p.id
+	`
+
+	rows, err := r.q.QueryContext(ctx, query, since, tolerance)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var anomalies []domain.LedgerAnomaly
+	for rows.Next() {
+		a := domain.LedgerAnomaly{Kind: domain.LedgerAmountMismatch}
+		if err := rows.Scan(&a.PaymentID, &a.TripID, &a.Fare, &a.NetAmount); err != nil {
+			return nil, err
+		}
+		anomalies = append(anomalies, a)
+	}
+	return anomalies, rows.Err()
+}
+
+// duplicateKeyAnomalies finds idempotency keys shared by several payments,
+// at least one of them created at or after since.
+func (r *ReportRepository) duplicateKeyAnomalies(ctx context.Context, since sql.NullTime, _ float64) ([]domain.LedgerAnomaly, error) {
+	query := `
+		SELECT idempotency_key, COUNT(*)
+		FROM payments
+		GROUP BY idempotency_key
+		HAVING COUNT(*) > 1 AND ($1::timestamp IS NULL OR MAX(created_at) >= $1)
+		ORDER BY idempotency_key
+	`
+
+	rows, err := r.q.QueryContext(ctx, query, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var anomalies []domain.LedgerAnomaly
+	for rows.Next() {
+		a := domain.LedgerAnomaly{Kind: domain.LedgerDuplicateIdempotencyKey}
+		var payments int
+		if err := rows.Scan(&a.IdempotencyKey, &payments); err != nil {
+			return nil, err
+		}
+		a.Detail = fmt.Sprintf("%d payments", payments)
+		anomalies = append(anomalies, a)
+	}
+	return anomalies, rows.Err()
+}
+
+// Ensure ReportRepository implements repository.ReportRepository.
+var _ repository.ReportRepository = (*ReportRepository)(nil)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
 	"ride/internal/domain"
 	"ride/internal/repository"
@@ -14,21 +15,52 @@ type RideRepository struct {
 	q Querier
 }
 
-// NewRideRepository creates a new PostgreSQL ride repository.
+// rideGetByIDQuery runs on every match and trip transition; it is prepared
+// once per repository.
+const rideGetByIDQuery = `
+		SELECT id, rider_id, pickup_lat, pickup_lng, destination_lat, destination_lng, status, assigned_driver_id, surge_multiplier, payment_method, cancelled_at, cancel_reason, created_at, version, requested_at, assigned_at, completed_at, surcharge_label, surcharge_amount, quote_id, updated_at, instrument_id, tier, rebooked_from, arrived_at, ride_type, capabilities, priority, estimate_low, estimate_high, assigned_by
+		FROM rides WHERE id = $1
+	`
+
+// NewRideRepository creates a new PostgreSQL ride repository. Call Close to
+// release its prepared statements.
 func NewRideRepository(db *sql.DB) *RideRepository {
-	return &RideRepository{q: db}
+	return &RideRepository{q: prepareQueries(db, rideGetByIDQuery)}
 }
 
-// NewRideRepositoryWithTx creates a ride repository using a transaction.
+// NewRideRepositoryWithTx creates a ride repository using a transaction. Its
+// queries run unprepared.
 func NewRideRepositoryWithTx(tx *sql.Tx) *RideRepository {
 	return &RideRepository{q: tx}
 }
 
+// rideSchema is the part of the schema RideRepository reads and writes.
+var rideSchema = []Table{
+	{Name: "rides", Columns: []Column{
+		{"id", ColumnText}, {"rider_id", ColumnText}, {"pickup_lat", ColumnFloat},
+		{"pickup_lng", ColumnFloat}, {"destination_lat", ColumnFloat}, {"destination_lng", ColumnFloat},
+		{"status", ColumnText}, {"assigned_driver_id", ColumnText}, {"surge_multiplier", ColumnFloat},
+		{"payment_method", ColumnText}, {"cancelled_at", ColumnTimestamp}, {"cancel_reason", ColumnText},
+		{"created_at", ColumnTimestamp}, {"version", ColumnInteger}, {"requested_at", ColumnTimestamp},
+		{"assigned_at", ColumnTimestamp}, {"completed_at", ColumnTimestamp},
+		{"surcharge_label", ColumnText}, {"surcharge_amount", ColumnFloat}, {"quote_id", ColumnText},
+		{"updated_at", ColumnTimestamp}, {"instrument_id", ColumnText}, {"tier", ColumnText},
+		{"rebooked_from", ColumnText}, {"arrived_at", ColumnTimestamp}, {"ride_type", ColumnText},
+		{"capabilities", ColumnJSON}, {"priority", ColumnBool},
+		{"estimate_low", ColumnFloat}, {"estimate_high", ColumnFloat}, {"assigned_by", ColumnText},
+	}},
+}
+
+// Close releases the repository's prepared statements.
+func (r *RideRepository) Close() error {
+	return closeStatements(r.q)
+}
+
 // Create persists a new ride.
 func (r *RideRepository) Create(ctx context.Context, ride *domain.Ride) error {
 	query := `
-		INSERT INTO rides (id, rider_id, pickup_lat, pickup_lng, destination_lat, destination_lng, status, assigned_driver_id, surge_multiplier, payment_method, cancelled_at, cancel_reason, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		INSERT INTO rides (id, rider_id, pickup_lat, pickup_lng, destination_lat, destination_lng, status, assigned_driver_id, surge_multiplier, payment_method, cancelled_at, cancel_reason, created_at, version, requested_at, assigned_at, completed_at, surcharge_label, surcharge_amount, quote_id, updated_at, instrument_id, tier, rebooked_from, arrived_at, ride_type, capabilities, priority, estimate_low, estimate_high, assigned_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31)
 	`
 
 	var assignedDriverID sql.NullString
@@ -48,6 +80,15 @@ func (r *RideRepository) Create(ctx context.Context, ride *domain.Ride) error {
 		paymentMethod = "CASH"
 	}
 
+	if ride.Type == "" {
+		ride.Type = domain.RideTypePassenger
+	}
+
+	capabilities, err := marshalCapabilities(ride.Capabilities)
+	if err != nil {
+		return err
+	}
+
 	var cancelledAt sql.NullTime
 	if !ride.CancelledAt.IsZero() {
 		cancelledAt = sql.NullTime{Time: ride.CancelledAt, Valid: true}
@@ -58,7 +99,15 @@ func (r *RideRepository) Create(ctx context.Context, ride *domain.Ride) error {
 		cancelReason = sql.NullString{String: ride.CancelReason, Valid: true}
 	}
 
-	_, err := r.q.ExecContext(ctx, query,
+	if ride.Version == 0 {
+		ride.Version = 1
+	}
+	if ride.CreatedAt.IsZero() {
+		ride.CreatedAt = time.Now()
+	}
+	ride.UpdatedAt = ride.CreatedAt
+
+	_, err = r.q.ExecContext(ctx, query,
 		ride.ID,
 		ride.RiderID,
 		ride.PickupLat,
@@ -72,38 +121,32 @@ func (r *RideRepository) Create(ctx context.Context, ride *domain.Ride) error {
 		cancelledAt,
 		cancelReason,
 		ride.CreatedAt,
+		ride.Version,
+		nullTime(ride.RequestedAt),
+		nullTime(ride.AssignedAt),
+		nullTime(ride.CompletedAt),
+		ride.SurchargeLabel,
+		ride.SurchargeAmount,
+		ride.QuoteID,
+		ride.UpdatedAt,
+		ride.InstrumentID,
+		ride.Tier,
+		ride.RebookedFrom,
+		nullTime(ride.ArrivedAt),
+		ride.Type,
+		capabilities,
+		ride.Priority,
+		ride.EstimateLow,
+		ride.EstimateHigh,
+		ride.AssignedBy,
 	)
 
-	return err
+	return translateConstraintViolation(err)
 }
 
 // GetByID retrieves a ride by ID.
 func (r *RideRepository) GetByID(ctx context.Context, id string) (*domain.Ride, error) {
-	query := `
-		SELECT id, rider_id, pickup_lat, pickup_lng, destination_lat, destination_lng, status, assigned_driver_id, surge_multiplier, payment_method, cancelled_at, cancel_reason, created_at
-		FROM rides WHERE id = $1
-	`
-
-	var ride domain.Ride
-	var assignedDriverID sql.NullString
-	var cancelledAt sql.NullTime
-	var cancelReason sql.NullString
-
-	err := r.q.QueryRowContext(ctx, query, id).Scan(
-		&ride.ID,
-		&ride.RiderID,
-		&ride.PickupLat,
-		&ride.PickupLng,
-		&ride.DestinationLat,
-		&ride.DestinationLng,
-		&ride.Status,
-		&assignedDriverID,
-		&ride.SurgeMultiplier,
-		&ride.PaymentMethod,
-		&cancelledAt,
-		&cancelReason,
-		&ride.CreatedAt,
-	)
+	ride, err := scanRide(r.q.QueryRowContext(ctx, rideGetByIDQuery, id))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, repository.ErrNotFound
@@ -111,23 +154,13 @@ func (r *RideRepository) GetByID(ctx context.Context, id string) (*domain.Ride,
 		return nil, err
 	}
 
-	if assignedDriverID.Valid {
-		ride.AssignedDriverID = assignedDriverID.String
-	}
-	if cancelledAt.Valid {
-		ride.CancelledAt = cancelledAt.Time
-	}
-	if cancelReason.Valid {
-		ride.CancelReason = cancelReason.String
-	}
-
-	return &ride, nil
+	return ride, nil
 }
 
 // GetAll retrieves all rides.
 func (r *RideRepository) GetAll(ctx context.Context) ([]*domain.Ride, error) {
 	query := `
-		SELECT id, rider_id, pickup_lat, pickup_lng, destination_lat, destination_lng, status, assigned_driver_id, surge_multiplier, payment_method, cancelled_at, cancel_reason, created_at
+		SELECT id, rider_id, pickup_lat, pickup_lng, destination_lat, destination_lng, status, assigned_driver_id, surge_multiplier, payment_method, cancelled_at, cancel_reason, created_at, version, requested_at, assigned_at, completed_at, surcharge_label, surcharge_amount, quote_id, updated_at, instrument_id, tier, rebooked_from, arrived_at, ride_type, capabilities, priority, estimate_low, estimate_high, assigned_by
 		FROM rides ORDER BY created_at DESC LIMIT 100
 	`
 
@@ -139,47 +172,23 @@ func (r *RideRepository) GetAll(ctx context.Context) ([]*domain.Ride, error) {
 
 	var rides []*domain.Ride
 	for rows.Next() {
-		var ride domain.Ride
-		var assignedDriverID sql.NullString
-		var cancelledAt sql.NullTime
-		var cancelReason sql.NullString
-		if err := rows.Scan(
-			&ride.ID,
-			&ride.RiderID,
-			&ride.PickupLat,
-			&ride.PickupLng,
-			&ride.DestinationLat,
-			&ride.DestinationLng,
-			&ride.Status,
-			&assignedDriverID,
-			&ride.SurgeMultiplier,
-			&ride.PaymentMethod,
-			&cancelledAt,
-			&cancelReason,
-			&ride.CreatedAt,
-		); err != nil {
+		ride, err := scanRide(rows)
+		if err != nil {
 			return nil, err
 		}
-		if assignedDriverID.Valid {
-			ride.AssignedDriverID = assignedDriverID.String
-		}
-		if cancelledAt.Valid {
-			ride.CancelledAt = cancelledAt.Time
-		}
-		if cancelReason.Valid {
-			ride.CancelReason = cancelReason.String
-		}
-		rides = append(rides, &ride)
+		rides = append(rides, ride)
 	}
 	return rides, rows.Err()
 }
 
-// Update updates an existing ride.
+// Update updates an existing ride if its version still matches the stored
+// row, bumping ride.Version and setting ride.UpdatedAt on success. Returns repository.ErrVersionConflict
+// if the ride was modified since it was read.
 func (r *RideRepository) Update(ctx context.Context, ride *domain.Ride) error {
 	query := `
 		UPDATE rides
-		SET rider_id = $1, pickup_lat = $2, pickup_lng = $3, destination_lat = $4, destination_lng = $5, status = $6, assigned_driver_id = $7, surge_multiplier = $8, payment_method = $9, cancelled_at = $10, cancel_reason = $11
-		WHERE id = $12
+		SET rider_id = $1, pickup_lat = $2, pickup_lng = $3, destination_lat = $4, destination_lng = $5, status = $6, assigned_driver_id = $7, surge_multiplier = $8, payment_method = $9, cancelled_at = $10, cancel_reason = $11, assigned_at = $14, completed_at = $15, updated_at = $16, arrived_at = $17, ride_type = $18, assigned_by = $19, version = version + 1
+		WHERE id = $12 AND version = $13
 	`
 
 	var assignedDriverID sql.NullString
@@ -199,6 +208,10 @@ func (r *RideRepository) Update(ctx context.Context, ride *domain.Ride) error {
 		paymentMethod = "CASH"
 	}
 
+	if ride.Type == "" {
+		ride.Type = domain.RideTypePassenger
+	}
+
 	var cancelledAt sql.NullTime
 	if !ride.CancelledAt.IsZero() {
 		cancelledAt = sql.NullTime{Time: ride.CancelledAt, Valid: true}
@@ -209,6 +222,7 @@ func (r *RideRepository) Update(ctx context.Context, ride *domain.Ride) error {
 		cancelReason = sql.NullString{String: ride.CancelReason, Valid: true}
 	}
 
+	now := time.Now()
 	result, err := r.q.ExecContext(ctx, query,
 		ride.RiderID,
 		ride.PickupLat,
@@ -222,6 +236,13 @@ func (r *RideRepository) Update(ctx context.Context, ride *domain.Ride) error {
 		cancelledAt,
 		cancelReason,
 		ride.ID,
+		ride.Version,
+		nullTime(ride.AssignedAt),
+		nullTime(ride.CompletedAt),
+		now,
+		nullTime(ride.ArrivedAt),
+		ride.Type,
+		ride.AssignedBy,
 	)
 	if err != nil {
 		return err
@@ -233,8 +254,230 @@ func (r *RideRepository) Update(ctx context.Context, ride *domain.Ride) error {
 	}
 
 	if rowsAffected == 0 {
-		return repository.ErrNotFound
+		return versionMismatchError(ctx, r.q, "rides", ride.ID)
 	}
 
+	ride.Version++
+	ride.UpdatedAt = now
 	return nil
 }
+
+// StartIfAssigned moves the ride to IN_TRIP in a single conditional UPDATE,
+// so of two drivers racing to start the same ride only the one it is still
+// assigned to wins. On success it sets ride.Status, bumps ride.Version and
+// sets ride.UpdatedAt. Returns false if the ride is no longer ASSIGNED to
+// driverID.
+func (r *RideRepository) StartIfAssigned(ctx context.Context, ride *domain.Ride, driverID string) (bool, error) {
+	query := `
+		UPDATE rides
+		SET status = $1, updated_at = $2, version = version + 1
+		WHERE id = $3 AND status = $4 AND assigned_driver_id = $5
+	`
+
+	now := time.Now()
+	result, err := r.q.ExecContext(ctx, query, domain.RideStatusInTrip, now, ride.ID, domain.RideStatusAssigned, driverID)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	if rowsAffected == 0 {
+		return false, nil
+	}
+
+	ride.Status = domain.RideStatusInTrip
+	ride.Version++
+	ride.UpdatedAt = now
+	return true, nil
+}
+
+// GetAssignedByDriverID retrieves the ride currently assigned to a driver,
+// which they are on their way to pick up. Returns nil if there is none.
+func (r *RideRepository) GetAssignedByDriverID(ctx context.Context, driverID string) (*domain.Ride, error) {
+	query := `
+		SELECT id, rider_id, pickup_lat, pickup_lng, destination_lat, destination_lng, status, assigned_driver_id, surge_multiplier, payment_method, cancelled_at, cancel_reason, created_at, version, requested_at, assigned_at, completed_at, surcharge_label, surcharge_amount, quote_id, updated_at, instrument_id, tier, rebooked_from, arrived_at, ride_type, capabilities, priority, estimate_low, estimate_high, assigned_by
+		FROM rides
+		WHERE assigned_driver_id = $1 AND status = $2
+		ORDER BY assigned_at DESC
+		LIMIT 1
+	`
+
+	ride, err := scanRide(r.q.QueryRowContext(ctx, query, driverID, domain.RideStatusAssigned))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return ride, nil
+}
+
+// CountInRange counts rides created in [from, to).
+func (r *RideRepository) CountInRange(ctx context.Context, from, to time.Time) (int, error) {
+	var count int
+	err := r.q.QueryRowContext(ctx, `SELECT COUNT(*) FROM rides WHERE created_at >= $1 AND created_at < $2`, from, to).Scan(&count)
+	return count, err
+}
+
+// ListOpenInBox returns REQUESTED and ASSIGNED rides whose pickup lies inside
+// box, oldest first. A positive limit caps the result.
+func (r *RideRepository) ListOpenInBox(ctx context.Context, box domain.BoundingBox, limit int) ([]*domain.Ride, error) {
+	query := `
+		SELECT id, rider_id, pickup_lat, pickup_lng, destination_lat, destination_lng, status, assigned_driver_id, surge_multiplier, payment_method, cancelled_at, cancel_reason, created_at, version, requested_at, assigned_at, completed_at, surcharge_label, surcharge_amount, quote_id, updated_at, instrument_id, tier, rebooked_from, arrived_at, ride_type, capabilities, priority, estimate_low, estimate_high, assigned_by
+		FROM rides
+		WHERE status IN ('REQUESTED', 'ASSIGNED')
+		  AND pickup_lat BETWEEN $1 AND $2
+		  AND pickup_lng BETWEEN $3 AND $4
+		ORDER BY created_at, id
+	`
+	args := []any{box.MinLat, box.MaxLat, box.MinLng, box.MaxLng}
+	if limit > 0 {
+		query += ` LIMIT $5`
+		args = append(args, limit)
+	}
+
+	rows, err := r.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rides []*domain.Ride
+	for rows.Next() {
+		ride, err := scanRide(rows)
+		if err != nil {
+			return nil, err
+		}
+		rides = append(rides, ride)
+	}
+	return rides, rows.Err()
+}
+
+// ListRequested returns up to limit rides waiting for a driver, priority
+// rides first and oldest first within each.
+func (r *RideRepository) ListRequested(ctx context.Context, limit int) ([]*domain.Ride, error) {
+	query := `
+		SELECT id, rider_id, pickup_lat, pickup_lng, destination_lat, destination_lng, status, assigned_driver_id, surge_multiplier, payment_method, cancelled_at, cancel_reason, created_at, version, requested_at, assigned_at, completed_at, surcharge_label, surcharge_amount, quote_id, updated_at, instrument_id, tier, rebooked_from, arrived_at, ride_type, capabilities, priority, estimate_low, estimate_high, assigned_by
+		FROM rides
+		WHERE status = 'REQUESTED'
+		ORDER BY priority DESC, created_at, id
+		LIMIT $1
+	`
+
+	rows, err := r.q.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rides []*domain.Ride
+	for rows.Next() {
+		ride, err := scanRide(rows)
+		if err != nil {
+			return nil, err
+		}
+		rides = append(rides, ride)
+	}
+	return rides, rows.Err()
+}
+
+// ListIterator calls fn for each ride created in [from, to), oldest first.
+// Rows are scanned from the cursor one at a time, so a large range is never
+// held in memory. Iteration stops at the first error from fn, which is
+// returned.
+func (r *RideRepository) ListIterator(ctx context.Context, from, to time.Time, fn func(*domain.Ride) error) error {
+	query := `
+		SELECT id, rider_id, pickup_lat, pickup_lng, destination_lat, destination_lng, status, assigned_driver_id, surge_multiplier, payment_method, cancelled_at, cancel_reason, created_at, version, requested_at, assigned_at, completed_at, surcharge_label, surcharge_amount, quote_id, updated_at, instrument_id, tier, rebooked_from, arrived_at, ride_type, capabilities, priority, estimate_low, estimate_high, assigned_by
+		FROM rides
+		WHERE created_at >= $1 AND created_at < $2
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.q.QueryContext(ctx, query, from, to)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		ride, err := scanRide(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(ride); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// scanRide scans a row selected with the column list used by GetByID.
+func scanRide(row rowScanner) (*domain.Ride, error) {
+	var ride domain.Ride
+	var assignedDriverID sql.NullString
+	var cancelledAt sql.NullTime
+	var cancelReason sql.NullString
+	var requestedAt, assignedAt, arrivedAt, completedAt sql.NullTime
+	var capabilities []byte
+
+	err := row.Scan(
+		&ride.ID,
+		&ride.RiderID,
+		&ride.PickupLat,
+		&ride.PickupLng,
+		&ride.DestinationLat,
+		&ride.DestinationLng,
+		&ride.Status,
+		&assignedDriverID,
+		&ride.SurgeMultiplier,
+		&ride.PaymentMethod,
+		&cancelledAt,
+		&cancelReason,
+		&ride.CreatedAt,
+		&ride.Version,
+		&requestedAt,
+		&assignedAt,
+		&completedAt,
+		&ride.SurchargeLabel,
+		&ride.SurchargeAmount,
+		&ride.QuoteID,
+		&ride.UpdatedAt,
+		&ride.InstrumentID,
+		&ride.Tier,
+		&ride.RebookedFrom,
+		&arrivedAt,
+		&ride.Type,
+		&capabilities,
+		&ride.Priority,
+		&ride.EstimateLow,
+		&ride.EstimateHigh,
+		&ride.AssignedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if ride.Capabilities, err = unmarshalCapabilities(capabilities); err != nil {
+		return nil, err
+	}
+
+	if assignedDriverID.Valid {
+		ride.AssignedDriverID = assignedDriverID.String
+	}
+	if cancelledAt.Valid {
+		ride.CancelledAt = cancelledAt.Time
+	}
+	if cancelReason.Valid {
+		ride.CancelReason = cancelReason.String
+	}
+	ride.RequestedAt = requestedAt.Time
+	ride.AssignedAt = assignedAt.Time
+	ride.ArrivedAt = arrivedAt.Time
+	ride.CompletedAt = completedAt.Time
+
+	return &ride, nil
+}
@@ -0,0 +1,105 @@
+package postgres
+
+// ColumnType is the family of database types a column may have and still
+// scan into, and accept, the Go values a repository uses for it.
+type ColumnType string
+
+const (
+	ColumnText      ColumnType = "text"
+	ColumnInteger   ColumnType = "integer"
+	ColumnFloat     ColumnType = "float"
+	ColumnBool      ColumnType = "bool"
+	ColumnTimestamp ColumnType = "timestamp"
+	ColumnJSON      ColumnType = "json"
+)
+
+// Column is a column a repository's queries read or write.
+type Column struct {
+	Name string
+	Type ColumnType
+}
+
+// Table is the part of a table a repository depends on. Each repository
+// declares its tables next to its queries; keep them in step when a query
+// starts using a new column.
+type Table struct {
+	Name    string
+	Columns []Column
+}
+
+// ExpectedSchema returns every table and column the repositories need, one
+// entry per table, in a stable order.
+func ExpectedSchema() []Table {
+	declared := [][]Table{
+		userSchema,
+		driverSchema,
+		rideSchema,
+		tripSchema,
+		paymentSchema,
+		paymentInstrumentSchema,
+		notificationSchema,
+		notificationPreferenceSchema,
+		notificationDeliverySchema,
+		campaignSchema,
+		earningsSchema,
+		driverSummarySchema,
+		deviationSchema,
+		matchAttemptSchema,
+		locationHistorySchema,
+		reportSchema,
+		featureFlagSchema,
+		tripAttachmentSchema,
+		fareSplitSchema,
+		fareAdjustmentSchema,
+		trackerKeySchema,
+	}
+
+	var tables []Table
+	index := make(map[string]int)
+	for _, schema := range declared {
+		for _, table := range schema {
+			i, ok := index[table.Name]
+			if !ok {
+				index[table.Name] = len(tables)
+				tables = append(tables, Table{Name: table.Name, Columns: append([]Column(nil), table.Columns...)})
+				continue
+			}
+			for _, col := range table.Columns {
+				if !hasColumn(tables[i].Columns, col.Name) {
+					tables[i].Columns = append(tables[i].Columns, col)
+				}
+			}
+		}
+	}
+	return tables
+}
+
+// ColumnTypeOf maps an information_schema data_type to its ColumnType. It
+// returns "" for types no repository uses.
+func ColumnTypeOf(dataType string) ColumnType {
+	switch dataType {
+	case "character varying", "character", "text":
+		return ColumnText
+	case "smallint", "integer", "bigint":
+		return ColumnInteger
+	case "double precision", "real", "numeric":
+		return ColumnFloat
+	case "boolean":
+		return ColumnBool
+	case "timestamp without time zone", "timestamp with time zone":
+		return ColumnTimestamp
+	case "json", "jsonb":
+		return ColumnJSON
+	default:
+		return ""
+	}
+}
+
+func hasColumn(columns []Column, name string) bool {
+	for _, col := range columns {
+		if col.Name == name {
+			return true
+		}
+	}
+	return false
+}
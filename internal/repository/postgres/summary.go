@@ -0,0 +1,184 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/repository"
+)
+
+// DriverSummaryRepository is a PostgreSQL implementation of repository.DriverSummaryRepository.
+type DriverSummaryRepository struct {
+	q Querier
+}
+
+// NewDriverSummaryRepository creates a new PostgreSQL driver summary repository.
+func NewDriverSummaryRepository(db *sql.DB) *DriverSummaryRepository {
+	return &DriverSummaryRepository{q: db}
+}
+
+// driverSummarySchema is the part of the schema DriverSummaryRepository reads and writes.
+var driverSummarySchema = []Table{
+	{Name: "driver_weekly_summaries", Columns: []Column{
+		{"driver_id", ColumnText}, {"week_start", ColumnTimestamp}, {"city", ColumnText},
+		{"trips", ColumnInteger}, {"earnings", ColumnFloat}, {"online_hours", ColumnFloat},
+		{"rank", ColumnInteger}, {"ranked_drivers", ColumnInteger},
+		{"generated_at", ColumnTimestamp}, {"notified_at", ColumnTimestamp},
+	}},
+}
+
+const driverSummaryColumns = `driver_id, week_start, city, trips, earnings, online_hours, rank, ranked_drivers, generated_at, notified_at`
+
+// GenerateWeek computes, ranks and stores the week's summaries in one
+// statement. Drivers are ranked within their city on trips, then earnings;
+// RANK gives drivers tied on both the same rank and skips the ranks after
+// them. Online hours count the 5-minute slots with at least one location
+// update.
+func (r *DriverSummaryRepository) GenerateWeek(ctx context.Context, weekStart time.Time, defaultCity string, commissionPercent float64, now time.Time) ([]*domain.DriverWeeklySummary, error) {
+	query := `
+		WITH trip_stats AS (
+			SELECT t.driver_id, COUNT(*) AS trips,
+				SUM(t.fare - (t.fare - CASE WHEN r.surcharge_amount > t.fare THEN 0 ELSE r.surcharge_amount END)
+					* $3::double precision / 100) AS net_fares
+			FROM trips t
+			JOIN rides r ON r.id = t.ride_id
+			WHERE t.status = 'ENDED' AND t.ended_at >= $1 AND t.ended_at < $2
+			GROUP BY t.driver_id
+		), ledger_stats AS (
+			SELECT driver_id, SUM(amount) AS credits
+			FROM driver_earnings
+			WHERE created_at >= $1 AND created_at < $2
+			GROUP BY driver_id
+		), online_stats AS (
+			SELECT driver_id, ` + onlineHoursSQL + ` AS hours
+			FROM driver_location_history
+			WHERE recorded_at >= $1 AND recorded_at < $2
+			GROUP BY driver_id
+		), totals AS (
+			SELECT d.id AS driver_id,
+				COALESCE(NULLIF(d.city, ''), $4) AS city,
+				COALESCE(ts.trips, 0) AS trips,
+				ROUND((COALESCE(ts.net_fares, 0) + COALESCE(ls.credits, 0))::numeric, 2)::double precision AS earnings,
+				ROUND(COALESCE(os.hours, 0)::numeric, 2)::double precision AS online_hours
+			FROM drivers d
+			LEFT JOIN trip_stats ts ON ts.driver_id = d.id
+			LEFT JOIN ledger_stats ls ON ls.driver_id = d.id
+			LEFT JOIN online_stats os ON os.driver_id = d.id
+			WHERE ts.driver_id IS NOT NULL OR ls.driver_id IS NOT NULL OR os.driver_id IS NOT NULL
+		)
+		INSERT INTO driver_weekly_summaries (driver_id, week_start, city, trips, earnings, online_hours, rank, ranked_drivers, generated_at)
+		SELECT driver_id, $1, city, trips, earnings, online_hours,
+			RANK() OVER (PARTITION BY city ORDER BY trips DESC, earnings DESC),
+			COUNT(*) OVER (PARTITION BY city),
+			$5
+		FROM totals
+		ON CONFLICT (driver_id, week_start) DO UPDATE SET
+			city = EXCLUDED.city,
+			trips = EXCLUDED.trips,
+			earnings = EXCLUDED.earnings,
+			online_hours = EXCLUDED.online_hours,
+			rank = EXCLUDED.rank,
+			ranked_drivers = EXCLUDED.ranked_drivers,
+			generated_at = EXCLUDED.generated_at
+		RETURNING ` + driverSummaryColumns
+
+	return r.query(ctx, query, weekStart, weekStart.AddDate(0, 0, 7), commissionPercent, defaultCity, now)
+}
+
+// Get retrieves a driver's summary for the week starting at weekStart.
+func (r *DriverSummaryRepository) Get(ctx context.Context, driverID string, weekStart time.Time) (*domain.DriverWeeklySummary, error) {
+	query := `SELECT ` + driverSummaryColumns + ` FROM driver_weekly_summaries WHERE driver_id = $1 AND week_start = $2`
+
+	summary, err := scanDriverSummary(r.q.QueryRowContext(ctx, query, driverID, weekStart))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+	return summary, nil
+}
+
+// Leaderboard retrieves up to limit summaries for a week and city, best
+// rank first and by driver ID within a rank.
+func (r *DriverSummaryRepository) Leaderboard(ctx context.Context, weekStart time.Time, city string, limit int) ([]*domain.DriverWeeklySummary, error) {
+	query := `
+		SELECT ` + driverSummaryColumns + `
+		FROM driver_weekly_summaries
+		WHERE week_start = $1 AND city = $2
+		ORDER BY rank, driver_id
+		LIMIT $3
+	`
+
+	return r.query(ctx, query, weekStart, city, limit)
+}
+
+// MarkNotified records that a driver was sent their summary.
+func (r *DriverSummaryRepository) MarkNotified(ctx context.Context, driverID string, weekStart time.Time, at time.Time) (bool, error) {
+	query := `
+		UPDATE driver_weekly_summaries SET notified_at = $3
+		WHERE driver_id = $1 AND week_start = $2 AND notified_at IS NULL
+	`
+
+	result, err := r.q.ExecContext(ctx, query, driverID, weekStart, at)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// query runs a query that returns summary rows.
+func (r *DriverSummaryRepository) query(ctx context.Context, query string, args ...any) ([]*domain.DriverWeeklySummary, error) {
+	rows, err := r.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var summaries []*domain.DriverWeeklySummary
+	for rows.Next() {
+		summary, err := scanDriverSummary(rows)
+		if err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, summary)
+	}
+
+	return summaries, rows.Err()
+}
+
+// scanDriverSummary scans a row selected with driverSummaryColumns.
+func scanDriverSummary(row rowScanner) (*domain.DriverWeeklySummary, error) {
+	var summary domain.DriverWeeklySummary
+	var notifiedAt sql.NullTime
+	if err := row.Scan(
+		&summary.DriverID,
+		&summary.WeekStart,
+		&summary.City,
+		&summary.Trips,
+		&summary.Earnings,
+		&summary.OnlineHours,
+		&summary.Rank,
+		&summary.RankedDrivers,
+		&summary.GeneratedAt,
+		&notifiedAt,
+	); err != nil {
+		return nil, err
+	}
+	if notifiedAt.Valid {
+		summary.NotifiedAt = notifiedAt.Time
+	}
+	return &summary, nil
+}
+
+// Ensure DriverSummaryRepository implements repository.DriverSummaryRepository.
+var _ repository.DriverSummaryRepository = (*DriverSummaryRepository)(nil)
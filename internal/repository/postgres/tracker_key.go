@@ -0,0 +1,57 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"ride/internal/repository"
+)
+
+// TrackerKeyRepository is a PostgreSQL implementation of
+// repository.TrackerKeyRepository.
+type TrackerKeyRepository struct {
+	q Querier
+}
+
+// NewTrackerKeyRepository creates a new PostgreSQL tracker key repository.
+func NewTrackerKeyRepository(db *sql.DB) *TrackerKeyRepository {
+	return &TrackerKeyRepository{q: db}
+}
+
+// trackerKeySchema is the part of the schema TrackerKeyRepository reads and writes.
+var trackerKeySchema = []Table{
+	{Name: "driver_tracker_keys", Columns: []Column{
+		{"driver_id", ColumnText}, {"encrypted_secret", ColumnText}, {"rotated_at", ColumnTimestamp},
+	}},
+}
+
+// Set stores a driver's encrypted secret, replacing any earlier key.
+func (r *TrackerKeyRepository) Set(ctx context.Context, driverID, encryptedSecret string) error {
+	query := `
+		INSERT INTO driver_tracker_keys (driver_id, encrypted_secret, rotated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (driver_id) DO UPDATE
+		SET encrypted_secret = EXCLUDED.encrypted_secret, rotated_at = EXCLUDED.rotated_at
+	`
+
+	_, err := r.q.ExecContext(ctx, query, driverID, encryptedSecret)
+	return translateConstraintViolation(err)
+}
+
+// GetSecret returns the driver's encrypted secret.
+func (r *TrackerKeyRepository) GetSecret(ctx context.Context, driverID string) (string, error) {
+	query := `SELECT encrypted_secret FROM driver_tracker_keys WHERE driver_id = $1`
+
+	var encryptedSecret string
+	if err := r.q.QueryRowContext(ctx, query, driverID).Scan(&encryptedSecret); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", repository.ErrNotFound
+		}
+		return "", err
+	}
+	return encryptedSecret, nil
+}
+
+// Ensure TrackerKeyRepository implements repository.TrackerKeyRepository.
+var _ repository.TrackerKeyRepository = (*TrackerKeyRepository)(nil)
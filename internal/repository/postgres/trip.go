@@ -25,11 +25,28 @@ func NewTripRepositoryWithTx(tx *sql.Tx) *TripRepository {
 	return &TripRepository{q: tx}
 }
 
+// tripSchema is the part of the schema TripRepository reads and writes.
+var tripSchema = []Table{
+	{Name: "trips", Columns: []Column{
+		{"id", ColumnText}, {"ride_id", ColumnText}, {"driver_id", ColumnText}, {"status", ColumnText},
+		{"fare", ColumnFloat}, {"started_at", ColumnTimestamp}, {"ended_at", ColumnTimestamp},
+		{"paused_at", ColumnTimestamp}, {"total_paused_seconds", ColumnInteger},
+		{"version", ColumnInteger}, {"needs_review", ColumnBool}, {"uncapped_fare", ColumnFloat},
+		{"pause_reason", ColumnText}, {"aborted_by", ColumnText}, {"abort_reason", ColumnText},
+		{"created_at", ColumnTimestamp}, {"updated_at", ColumnTimestamp}, {"auto_ended", ColumnBool},
+		{"confirmation", ColumnText}, {"dispute_reason", ColumnText},
+	}},
+	{Name: "rides", Columns: []Column{
+		{"id", ColumnText}, {"pickup_lat", ColumnFloat}, {"pickup_lng", ColumnFloat},
+		{"destination_lat", ColumnFloat}, {"destination_lng", ColumnFloat},
+	}},
+}
+
 // Create persists a new trip.
 func (r *TripRepository) Create(ctx context.Context, trip *domain.Trip) error {
 	query := `
-		INSERT INTO trips (id, ride_id, driver_id, status, fare, started_at, ended_at, paused_at, total_paused_seconds)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO trips (id, ride_id, driver_id, status, fare, started_at, ended_at, paused_at, total_paused_seconds, version, needs_review, uncapped_fare, pause_reason, aborted_by, abort_reason, created_at, updated_at, auto_ended, confirmation, dispute_reason)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
 	`
 
 	var endedAt sql.NullTime
@@ -44,6 +61,14 @@ func (r *TripRepository) Create(ctx context.Context, trip *domain.Trip) error {
 
 	totalPausedSeconds := int64(trip.TotalPaused.Seconds())
 
+	if trip.Version == 0 {
+		trip.Version = 1
+	}
+	if trip.CreatedAt.IsZero() {
+		trip.CreatedAt = time.Now()
+	}
+	trip.UpdatedAt = trip.CreatedAt
+
 	_, err := r.q.ExecContext(ctx, query,
 		trip.ID,
 		trip.RideID,
@@ -54,34 +79,30 @@ func (r *TripRepository) Create(ctx context.Context, trip *domain.Trip) error {
 		endedAt,
 		pausedAt,
 		totalPausedSeconds,
+		trip.Version,
+		trip.NeedsReview,
+		trip.UncappedFare,
+		trip.PauseReason,
+		trip.AbortedBy,
+		trip.AbortReason,
+		trip.CreatedAt,
+		trip.UpdatedAt,
+		trip.AutoEnded,
+		trip.Confirmation,
+		trip.DisputeReason,
 	)
 
-	return err
+	return translateConstraintViolation(err)
 }
 
 // GetByID retrieves a trip by ID.
 func (r *TripRepository) GetByID(ctx context.Context, id string) (*domain.Trip, error) {
 	query := `
-		SELECT id, ride_id, driver_id, status, fare, started_at, ended_at, paused_at, total_paused_seconds
+		SELECT id, ride_id, driver_id, status, fare, started_at, ended_at, paused_at, total_paused_seconds, version, needs_review, uncapped_fare, pause_reason, aborted_by, abort_reason, created_at, updated_at, auto_ended, confirmation, dispute_reason
 		FROM trips WHERE id = $1
 	`
 
-	var trip domain.Trip
-	var endedAt sql.NullTime
-	var pausedAt sql.NullTime
-	var totalPausedSeconds int64
-
-	err := r.q.QueryRowContext(ctx, query, id).Scan(
-		&trip.ID,
-		&trip.RideID,
-		&trip.DriverID,
-		&trip.Status,
-		&trip.Fare,
-		&trip.StartedAt,
-		&endedAt,
-		&pausedAt,
-		&totalPausedSeconds,
-	)
+	trip, err := scanTrip(r.q.QueryRowContext(ctx, query, id))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, repository.ErrNotFound
@@ -89,21 +110,13 @@ func (r *TripRepository) GetByID(ctx context.Context, id string) (*domain.Trip,
 		return nil, err
 	}
 
-	if endedAt.Valid {
-		trip.EndedAt = endedAt.Time
-	}
-	if pausedAt.Valid {
-		trip.PausedAt = pausedAt.Time
-	}
-	trip.TotalPaused = time.Duration(totalPausedSeconds) * time.Second
-
-	return &trip, nil
+	return trip, nil
 }
 
 // GetAll retrieves all trips.
 func (r *TripRepository) GetAll(ctx context.Context) ([]*domain.Trip, error) {
 	query := `
-		SELECT id, ride_id, driver_id, status, fare, started_at, ended_at, paused_at, total_paused_seconds
+		SELECT id, ride_id, driver_id, status, fare, started_at, ended_at, paused_at, total_paused_seconds, version, needs_review, uncapped_fare, pause_reason, aborted_by, abort_reason, created_at, updated_at, auto_ended, confirmation, dispute_reason
 		FROM trips ORDER BY started_at DESC LIMIT 100
 	`
 
@@ -115,45 +128,24 @@ func (r *TripRepository) GetAll(ctx context.Context) ([]*domain.Trip, error) {
 
 	var trips []*domain.Trip
 	for rows.Next() {
-		var trip domain.Trip
-		var endedAt sql.NullTime
-		var pausedAt sql.NullTime
-		var totalPausedSeconds int64
-
-		if err := rows.Scan(
-			&trip.ID,
-			&trip.RideID,
-			&trip.DriverID,
-			&trip.Status,
-			&trip.Fare,
-			&trip.StartedAt,
-			&endedAt,
-			&pausedAt,
-			&totalPausedSeconds,
-		); err != nil {
+		trip, err := scanTrip(rows)
+		if err != nil {
 			return nil, err
 		}
-
-		if endedAt.Valid {
-			trip.EndedAt = endedAt.Time
-		}
-		if pausedAt.Valid {
-			trip.PausedAt = pausedAt.Time
-		}
-		trip.TotalPaused = time.Duration(totalPausedSeconds) * time.Second
-
-		trips = append(trips, &trip)
+		trips = append(trips, trip)
 	}
 
 	return trips, rows.Err()
 }
 
-// Update updates an existing trip.
+// Update updates an existing trip if its version still matches the stored
+// row, bumping trip.Version and setting trip.UpdatedAt on success. Returns repository.ErrVersionConflict
+// if the trip was modified since it was read.
 func (r *TripRepository) Update(ctx context.Context, trip *domain.Trip) error {
 	query := `
 		UPDATE trips
-		SET ride_id = $1, driver_id = $2, status = $3, fare = $4, started_at = $5, ended_at = $6, paused_at = $7, total_paused_seconds = $8
-		WHERE id = $9
+		SET ride_id = $1, driver_id = $2, status = $3, fare = $4, started_at = $5, ended_at = $6, paused_at = $7, total_paused_seconds = $8, version = version + 1, needs_review = $11, uncapped_fare = $12, pause_reason = $13, updated_at = $14, aborted_by = $15, abort_reason = $16, auto_ended = $17, confirmation = $18, dispute_reason = $19
+		WHERE id = $9 AND version = $10
 	`
 
 	var endedAt sql.NullTime
@@ -167,6 +159,7 @@ func (r *TripRepository) Update(ctx context.Context, trip *domain.Trip) error {
 	}
 
 	totalPausedSeconds := int64(trip.TotalPaused.Seconds())
+	now := time.Now()
 
 	result, err := r.q.ExecContext(ctx, query,
 		trip.RideID,
@@ -178,6 +171,16 @@ func (r *TripRepository) Update(ctx context.Context, trip *domain.Trip) error {
 		pausedAt,
 		totalPausedSeconds,
 		trip.ID,
+		trip.Version,
+		trip.NeedsReview,
+		trip.UncappedFare,
+		trip.PauseReason,
+		now,
+		trip.AbortedBy,
+		trip.AbortReason,
+		trip.AutoEnded,
+		trip.Confirmation,
+		trip.DisputeReason,
 	)
 	if err != nil {
 		return err
@@ -189,9 +192,11 @@ func (r *TripRepository) Update(ctx context.Context, trip *domain.Trip) error {
 	}
 
 	if rowsAffected == 0 {
-		return repository.ErrNotFound
+		return versionMismatchError(ctx, r.q, "trips", trip.ID)
 	}
 
+	trip.Version++
+	trip.UpdatedAt = now
 	return nil
 }
 
@@ -199,18 +204,166 @@ func (r *TripRepository) Update(ctx context.Context, trip *domain.Trip) error {
 // Returns nil if no active trip exists.
 func (r *TripRepository) GetActiveByDriverID(ctx context.Context, driverID string) (*domain.Trip, error) {
 	query := `
-		SELECT id, ride_id, driver_id, status, fare, started_at, ended_at, paused_at, total_paused_seconds
+		SELECT id, ride_id, driver_id, status, fare, started_at, ended_at, paused_at, total_paused_seconds, version, needs_review, uncapped_fare, pause_reason, aborted_by, abort_reason, created_at, updated_at, auto_ended, confirmation, dispute_reason
 		FROM trips
-		WHERE driver_id = $1 AND status != $2
+		WHERE driver_id = $1 AND status NOT IN ($2, $3)
 		LIMIT 1
 	`
 
+	trip, err := scanTrip(r.q.QueryRowContext(ctx, query, driverID, domain.TripStatusEnded, domain.TripStatusAborted))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return trip, nil
+}
+
+// Ensure TripRepository implements repository.TripRepository.
+var _ repository.TripRepository = (*TripRepository)(nil)
+
+// GetStartedBefore retrieves STARTED trips that started before the given
+// time, oldest first, up to limit.
+func (r *TripRepository) GetStartedBefore(ctx context.Context, before time.Time, limit int) ([]*domain.Trip, error) {
+	query := `
+		SELECT id, ride_id, driver_id, status, fare, started_at, ended_at, paused_at, total_paused_seconds, version, needs_review, uncapped_fare, pause_reason, aborted_by, abort_reason, created_at, updated_at, auto_ended, confirmation, dispute_reason
+		FROM trips
+		WHERE status = $1 AND started_at < $2
+		ORDER BY started_at
+		LIMIT $3
+	`
+
+	rows, err := r.q.QueryContext(ctx, query, domain.TripStatusStarted, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var trips []*domain.Trip
+	for rows.Next() {
+		trip, err := scanTrip(rows)
+		if err != nil {
+			return nil, err
+		}
+		trips = append(trips, trip)
+	}
+
+	return trips, rows.Err()
+}
+
+// GetAwaitingConfirmationBefore retrieves trips ended before the given time
+// that are still waiting for the rider's confirmation, oldest first, up to
+// limit.
+func (r *TripRepository) GetAwaitingConfirmationBefore(ctx context.Context, before time.Time, limit int) ([]*domain.Trip, error) {
+	query := `
+		SELECT id, ride_id, driver_id, status, fare, started_at, ended_at, paused_at, total_paused_seconds, version, needs_review, uncapped_fare, pause_reason, aborted_by, abort_reason, created_at, updated_at, auto_ended, confirmation, dispute_reason
+		FROM trips
+		WHERE confirmation = $1 AND ended_at < $2
+		ORDER BY ended_at
+		LIMIT $3
+	`
+
+	rows, err := r.q.QueryContext(ctx, query, domain.TripConfirmationPending, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var trips []*domain.Trip
+	for rows.Next() {
+		trip, err := scanTrip(rows)
+		if err != nil {
+			return nil, err
+		}
+		trips = append(trips, trip)
+	}
+
+	return trips, rows.Err()
+}
+
+// CountInRange counts trips started in [from, to).
+func (r *TripRepository) CountInRange(ctx context.Context, from, to time.Time) (int, error) {
+	var count int
+	err := r.q.QueryRowContext(ctx, `SELECT COUNT(*) FROM trips WHERE started_at >= $1 AND started_at < $2`, from, to).Scan(&count)
+	return count, err
+}
+
+// CompletedDurations returns the driving time, pauses excluded, of up to
+// limit trips ended since since, newest first, whose ride's pickup and
+// destination lie in the given boxes. Aborted and auto-ended trips, whose
+// durations say nothing about the route, are left out.
+func (r *TripRepository) CompletedDurations(ctx context.Context, pickup, destination domain.BoundingBox, since time.Time, limit int) ([]time.Duration, error) {
+	query := `
+		SELECT EXTRACT(EPOCH FROM (t.ended_at - t.started_at)) - t.total_paused_seconds
+		FROM trips t
+		JOIN rides r ON r.id = t.ride_id
+		WHERE t.status = $1 AND NOT t.auto_ended AND t.ended_at >= $2
+			AND r.pickup_lat BETWEEN $3 AND $4 AND r.pickup_lng BETWEEN $5 AND $6
+			AND r.destination_lat BETWEEN $7 AND $8 AND r.destination_lng BETWEEN $9 AND $10
+		ORDER BY t.ended_at DESC
+		LIMIT $11
+	`
+
+	rows, err := r.q.QueryContext(ctx, query, domain.TripStatusEnded, since,
+		pickup.MinLat, pickup.MaxLat, pickup.MinLng, pickup.MaxLng,
+		destination.MinLat, destination.MaxLat, destination.MinLng, destination.MaxLng,
+		limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var durations []time.Duration
+	for rows.Next() {
+		var seconds float64
+		if err := rows.Scan(&seconds); err != nil {
+			return nil, err
+		}
+		durations = append(durations, time.Duration(seconds*float64(time.Second)))
+	}
+	return durations, rows.Err()
+}
+
+// ListIterator calls fn for each trip started in [from, to), oldest first.
+// Rows are scanned from the cursor one at a time, so a large range is never
+// held in memory. Iteration stops at the first error from fn, which is
+// returned.
+func (r *TripRepository) ListIterator(ctx context.Context, from, to time.Time, fn func(*domain.Trip) error) error {
+	query := `
+		SELECT id, ride_id, driver_id, status, fare, started_at, ended_at, paused_at, total_paused_seconds, version, needs_review, uncapped_fare, pause_reason, aborted_by, abort_reason, created_at, updated_at, auto_ended, confirmation, dispute_reason
+		FROM trips
+		WHERE started_at >= $1 AND started_at < $2
+		ORDER BY started_at, id
+	`
+
+	rows, err := r.q.QueryContext(ctx, query, from, to)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		trip, err := scanTrip(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(trip); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// scanTrip scans a row selected with the column list used by GetByID.
+func scanTrip(row rowScanner) (*domain.Trip, error) {
 	var trip domain.Trip
 	var endedAt sql.NullTime
 	var pausedAt sql.NullTime
 	var totalPausedSeconds int64
 
-	err := r.q.QueryRowContext(ctx, query, driverID, domain.TripStatusEnded).Scan(
+	err := row.Scan(
 		&trip.ID,
 		&trip.RideID,
 		&trip.DriverID,
@@ -220,11 +373,19 @@ func (r *TripRepository) GetActiveByDriverID(ctx context.Context, driverID strin
 		&endedAt,
 		&pausedAt,
 		&totalPausedSeconds,
+		&trip.Version,
+		&trip.NeedsReview,
+		&trip.UncappedFare,
+		&trip.PauseReason,
+		&trip.AbortedBy,
+		&trip.AbortReason,
+		&trip.CreatedAt,
+		&trip.UpdatedAt,
+		&trip.AutoEnded,
+		&trip.Confirmation,
+		&trip.DisputeReason,
 	)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, nil
-		}
 		return nil, err
 	}
 
@@ -238,6 +399,3 @@ func (r *TripRepository) GetActiveByDriverID(ctx context.Context, driverID strin
 
 	return &trip, nil
 }
-
-// Ensure TripRepository implements repository.TripRepository.
-var _ repository.TripRepository = (*TripRepository)(nil)
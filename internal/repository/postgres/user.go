@@ -18,36 +18,45 @@ func NewUserRepository(db *sql.DB) *UserRepository {
 	return &UserRepository{db: db}
 }
 
+// userSchema is the part of the schema UserRepository reads and writes.
+var userSchema = []Table{
+	{Name: "users", Columns: []Column{
+		{"id", ColumnText}, {"name", ColumnText}, {"phone", ColumnText}, {"email", ColumnText},
+		{"email_verified", ColumnBool}, {"created_at", ColumnTimestamp},
+	}},
+}
+
 // Create adds a new user.
 func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
-	query := `INSERT INTO users (id, name, phone) VALUES ($1, $2, $3)`
-	_, err := r.db.ExecContext(ctx, query, user.ID, user.Name, user.Phone)
-	return err
+	query := `INSERT INTO users (id, name, phone, email, email_verified) VALUES ($1, $2, $3, $4, $5)`
+	_, err := r.db.ExecContext(ctx, query, user.ID, user.Name, user.Phone, user.Email, user.EmailVerified)
+	return translateConstraintViolation(err)
 }
 
 // GetByID retrieves a user by ID.
 func (r *UserRepository) GetByID(ctx context.Context, id string) (*domain.User, error) {
-	query := `SELECT id, name, phone, created_at FROM users WHERE id = $1`
-	row := r.db.QueryRowContext(ctx, query, id)
-
-	var user domain.User
-	err := row.Scan(&user.ID, &user.Name, &user.Phone, &user.CreatedAt)
-	if err == sql.ErrNoRows {
-		return nil, repository.ErrNotFound
-	}
-	if err != nil {
-		return nil, err
-	}
-	return &user, nil
+	query := `SELECT id, name, phone, email, email_verified, created_at FROM users WHERE id = $1`
+	return r.getOne(ctx, query, id)
 }
 
 // GetByPhone retrieves a user by phone number.
 func (r *UserRepository) GetByPhone(ctx context.Context, phone string) (*domain.User, error) {
-	query := `SELECT id, name, phone, created_at FROM users WHERE phone = $1`
-	row := r.db.QueryRowContext(ctx, query, phone)
+	query := `SELECT id, name, phone, email, email_verified, created_at FROM users WHERE phone = $1`
+	return r.getOne(ctx, query, phone)
+}
+
+// GetByEmail retrieves a user by email address.
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	query := `SELECT id, name, phone, email, email_verified, created_at FROM users WHERE email = $1`
+	return r.getOne(ctx, query, email)
+}
+
+// getOne runs a single-user query.
+func (r *UserRepository) getOne(ctx context.Context, query string, arg any) (*domain.User, error) {
+	row := r.db.QueryRowContext(ctx, query, arg)
 
 	var user domain.User
-	err := row.Scan(&user.ID, &user.Name, &user.Phone, &user.CreatedAt)
+	err := row.Scan(&user.ID, &user.Name, &user.Phone, &user.Email, &user.EmailVerified, &user.CreatedAt)
 	if err == sql.ErrNoRows {
 		return nil, repository.ErrNotFound
 	}
@@ -59,7 +68,7 @@ func (r *UserRepository) GetByPhone(ctx context.Context, phone string) (*domain.
 
 // GetAll retrieves all users.
 func (r *UserRepository) GetAll(ctx context.Context) ([]*domain.User, error) {
-	query := `SELECT id, name, phone, created_at FROM users ORDER BY created_at DESC`
+	query := `SELECT id, name, phone, email, email_verified, created_at FROM users ORDER BY created_at DESC`
 	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
@@ -69,10 +78,33 @@ func (r *UserRepository) GetAll(ctx context.Context) ([]*domain.User, error) {
 	var users []*domain.User
 	for rows.Next() {
 		var user domain.User
-		if err := rows.Scan(&user.ID, &user.Name, &user.Phone, &user.CreatedAt); err != nil {
+		if err := rows.Scan(&user.ID, &user.Name, &user.Phone, &user.Email, &user.EmailVerified, &user.CreatedAt); err != nil {
 			return nil, err
 		}
 		users = append(users, &user)
 	}
 	return users, rows.Err()
 }
+
+// UpdateEmail sets a user's email address and whether it is verified.
+// Returns repository.ErrDuplicate if another user has the address.
+func (r *UserRepository) UpdateEmail(ctx context.Context, id, email string, verified bool) error {
+	query := `UPDATE users SET email = $1, email_verified = $2 WHERE id = $3`
+
+	result, err := r.db.ExecContext(ctx, query, email, verified, id)
+	if err != nil {
+		return translateConstraintViolation(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// Ensure UserRepository implements repository.UserRepository.
+var _ repository.UserRepository = (*UserRepository)(nil)
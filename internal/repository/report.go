@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"ride/internal/domain"
+)
+
+// ReportRepository defines read-only reporting queries across trips and payments.
+type ReportRepository interface {
+	// GetDailyTotals aggregates trips ended in [from, to) and their payments.
+	GetDailyTotals(ctx context.Context, from, to time.Time) (*domain.DailyTotals, error)
+
+	// GetFareDiscrepancies returns trips ended in [from, to) whose fare differs
+	// from the sum of their payments, less refunds, by more than tolerance.
+	GetFareDiscrepancies(ctx context.Context, from, to time.Time, tolerance float64) ([]domain.FareDiscrepancy, error)
+
+	// GetEstimateAccuracy compares the estimated fare range with the fare of
+	// ENDED trips ended in [from, to) whose ride carried an estimate. Fares
+	// held for review are left out.
+	GetEstimateAccuracy(ctx context.Context, from, to time.Time) (*domain.EstimateAccuracy, error)
+
+	// GetOutstandingCollections returns the driver's CASH_DUE payments,
+	// oldest trip first.
+	GetOutstandingCollections(ctx context.Context, driverID string) ([]domain.OutstandingCollection, error)
+
+	// GetLedgerAnomalies cross-checks trips finished and payments created at
+	// or after since, or the whole ledger when since is zero. Fare and
+	// payment amounts within tolerance of each other match.
+	GetLedgerAnomalies(ctx context.Context, since time.Time, tolerance float64) ([]domain.LedgerAnomaly, error)
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"ride/internal/domain"
 )
@@ -19,4 +20,28 @@ type RideRepository interface {
 
 	// Update updates an existing ride.
 	Update(ctx context.Context, ride *domain.Ride) error
+
+	// StartIfAssigned moves the ride to IN_TRIP only if it is still ASSIGNED
+	// to driverID, reporting whether it did.
+	StartIfAssigned(ctx context.Context, ride *domain.Ride, driverID string) (bool, error)
+
+	// GetAssignedByDriverID retrieves the ride currently assigned to a
+	// driver. Returns nil if there is none.
+	GetAssignedByDriverID(ctx context.Context, driverID string) (*domain.Ride, error)
+
+	// CountInRange counts rides created in [from, to).
+	CountInRange(ctx context.Context, from, to time.Time) (int, error)
+
+	// ListOpenInBox returns REQUESTED and ASSIGNED rides whose pickup lies
+	// inside box, oldest first. A positive limit caps the result.
+	ListOpenInBox(ctx context.Context, box domain.BoundingBox, limit int) ([]*domain.Ride, error)
+
+	// ListRequested returns up to limit rides waiting for a driver, priority
+	// rides first and oldest first within each.
+	ListRequested(ctx context.Context, limit int) ([]*domain.Ride, error)
+
+	// ListIterator calls fn for each ride created in [from, to), oldest
+	// first, without loading the range into memory. Iteration stops at the
+	// first error from fn, which is returned.
+	ListIterator(ctx context.Context, from, to time.Time, fn func(*domain.Ride) error) error
 }
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"ride/internal/domain"
+)
+
+// DriverSummaryRepository defines the persistence operations for drivers'
+// weekly summaries.
+type DriverSummaryRepository interface {
+	// GenerateWeek computes the summary of every driver active in the week
+	// starting at weekStart from their trips, earnings and location history,
+	// ranks them among the drivers of their city, and stores them, replacing
+	// any earlier generation for the week. Drivers without a city are ranked
+	// in defaultCity. Fares count net of commissionPercent. NotifiedAt is
+	// kept across generations.
+	GenerateWeek(ctx context.Context, weekStart time.Time, defaultCity string, commissionPercent float64, now time.Time) ([]*domain.DriverWeeklySummary, error)
+
+	// Get retrieves a driver's summary for the week starting at weekStart.
+	Get(ctx context.Context, driverID string, weekStart time.Time) (*domain.DriverWeeklySummary, error)
+
+	// Leaderboard retrieves up to limit summaries for a week and city, best
+	// rank first and by driver ID within a rank.
+	Leaderboard(ctx context.Context, weekStart time.Time, city string, limit int) ([]*domain.DriverWeeklySummary, error)
+
+	// MarkNotified records that a driver was sent their summary. It reports
+	// false if they already had been.
+	MarkNotified(ctx context.Context, driverID string, weekStart time.Time, at time.Time) (bool, error)
+}
@@ -0,0 +1,15 @@
+package repository
+
+import "context"
+
+// TrackerKeyRepository defines the persistence operations for the keys that
+// drivers' location trackers sign their requests with. Each secret is stored
+// encrypted; the repository never sees it in the clear.
+type TrackerKeyRepository interface {
+	// Set stores a driver's encrypted secret, replacing any earlier key.
+	Set(ctx context.Context, driverID, encryptedSecret string) error
+
+	// GetSecret returns the driver's encrypted secret, or ErrNotFound if none
+	// was issued.
+	GetSecret(ctx context.Context, driverID string) (string, error)
+}
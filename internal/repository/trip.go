@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"ride/internal/domain"
 )
@@ -23,4 +24,27 @@ type TripRepository interface {
 	// GetActiveByDriverID retrieves the active trip for a driver.
 	// Returns nil if no active trip exists.
 	GetActiveByDriverID(ctx context.Context, driverID string) (*domain.Trip, error)
+
+	// GetStartedBefore retrieves STARTED trips that started before the
+	// given time, oldest first, up to limit.
+	GetStartedBefore(ctx context.Context, before time.Time, limit int) ([]*domain.Trip, error)
+
+	// GetAwaitingConfirmationBefore retrieves trips ended before the given
+	// time that are still waiting for the rider's confirmation, oldest
+	// first, up to limit.
+	GetAwaitingConfirmationBefore(ctx context.Context, before time.Time, limit int) ([]*domain.Trip, error)
+
+	// CountInRange counts trips started in [from, to).
+	CountInRange(ctx context.Context, from, to time.Time) (int, error)
+
+	// CompletedDurations returns the driving time, pauses excluded, of up
+	// to limit trips ended since since, newest first, whose ride's pickup
+	// and destination lie in the given boxes. Trips that were aborted or
+	// auto-ended are left out.
+	CompletedDurations(ctx context.Context, pickup, destination domain.BoundingBox, since time.Time, limit int) ([]time.Duration, error)
+
+	// ListIterator calls fn for each trip started in [from, to), oldest
+	// first, without loading the range into memory. Iteration stops at the
+	// first error from fn, which is returned.
+	ListIterator(ctx context.Context, from, to time.Time, fn func(*domain.Trip) error) error
 }
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// matchAreaCellDegrees is the side of the grid cell matches are limited
+// per, about 5km.
+const matchAreaCellDegrees = 0.05
+
+// areaLimiter bounds how many assignment transactions run at once per
+// pickup area. Excess matches queue for a slot rather than fail, so a
+// demand spike in one area is spread over time instead of hitting the
+// database at once.
+type areaLimiter struct {
+	limit int // Concurrent transactions per area; not positive disables the limit
+
+	mu    sync.Mutex
+	areas map[string]*areaSlots
+}
+
+// areaSlots are one area's transaction slots. The area is forgotten once no
+// match holds or waits for a slot.
+type areaSlots struct {
+	slots chan struct{}
+	users int
+}
+
+func newAreaLimiter(limit int) *areaLimiter {
+	return &areaLimiter{limit: limit, areas: make(map[string]*areaSlots)}
+}
+
+// acquire waits for a slot in the area containing the point, until ctx is
+// done. The returned release must be called once the transaction ends.
+func (l *areaLimiter) acquire(ctx context.Context, lat, lng float64) (release func(), err error) {
+	if l == nil || l.limit <= 0 {
+		return func() {}, nil
+	}
+
+	area := matchAreaCell(lat, lng)
+	l.mu.Lock()
+	a := l.areas[area]
+	if a == nil {
+		a = &areaSlots{slots: make(chan struct{}, l.limit)}
+		l.areas[area] = a
+	}
+	a.users++
+	l.mu.Unlock()
+
+	select {
+	case a.slots <- struct{}{}:
+	default:
+		start := time.Now()
+		select {
+		case a.slots <- struct{}{}:
+			recordMetric(ctx, metricMatchingAreaQueued, time.Since(start).Seconds())
+		case <-ctx.Done():
+			l.leave(area, a)
+			return nil, ctx.Err()
+		}
+	}
+
+	return func() {
+		<-a.slots
+		l.leave(area, a)
+	}, nil
+}
+
+func (l *areaLimiter) leave(area string, a *areaSlots) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	a.users--
+	if a.users == 0 {
+		delete(l.areas, area)
+	}
+}
+
+// matchAreaCell names the grid cell containing the point.
+func matchAreaCell(lat, lng float64) string {
+	return fmt.Sprintf("%d:%d", int(math.Floor(lat/matchAreaCellDegrees)), int(math.Floor(lng/matchAreaCellDegrees)))
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"ride/internal/domain"
+	"ride/internal/repository"
+)
+
+const (
+	defaultAttachmentMaxBytes = 512 << 10        // Used when the configured limit is not positive
+	defaultAttachmentURLTTL   = 15 * time.Minute // Used when the configured TTL is not positive
+)
+
+// attachmentExtensions lists the image types accepted as attachments, by
+// sniffed content type, with the extension their blobs are stored under.
+var attachmentExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+// TripAttachmentService stores photos drivers attach to PACKAGE rides'
+// trips as proof of delivery, and serves them back through signed URLs that
+// expire, so they can be shown to the rider without exposing the blob store.
+type TripAttachmentService struct {
+	tripRepo       repository.TripRepository
+	rideRepo       repository.RideRepository
+	attachmentRepo repository.TripAttachmentRepository
+	blobs          BlobStore
+	maxBytes       int64 // Largest accepted upload
+	signingKey     []byte
+	urlTTL         time.Duration // How long a signed URL stays valid
+}
+
+// NewTripAttachmentService creates a new TripAttachmentService. Without a
+// signing key a random one is generated, so URLs are only honored by the
+// instance that signed them.
+func NewTripAttachmentService(
+	tripRepo repository.TripRepository,
+	rideRepo repository.RideRepository,
+	attachmentRepo repository.TripAttachmentRepository,
+	blobs BlobStore,
+	maxBytes int64,
+	signingKey string,
+	urlTTL time.Duration,
+) *TripAttachmentService {
+	if maxBytes <= 0 {
+		maxBytes = defaultAttachmentMaxBytes
+	}
+	if urlTTL <= 0 {
+		urlTTL = defaultAttachmentURLTTL
+	}
+
+	key := []byte(signingKey)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			panic(err)
+		}
+		log.Printf("[ATTACHMENT] no signing key configured; attachment URLs are only valid on this instance")
+	}
+
+	return &TripAttachmentService{
+		tripRepo:       tripRepo,
+		rideRepo:       rideRepo,
+		attachmentRepo: attachmentRepo,
+		blobs:          blobs,
+		maxBytes:       maxBytes,
+		signingKey:     key,
+		urlTTL:         urlTTL,
+	}
+}
+
+// MaxBytes returns the largest upload Attach accepts.
+func (s *TripAttachmentService) MaxBytes() int64 {
+	return s.maxBytes
+}
+
+// AttachRequest contains the parameters for attaching a photo to a trip.
+type AttachRequest struct {
+	TripID   string
+	DriverID string // Must be the trip's driver
+	Data     []byte
+}
+
+// Attach stores a photo for a trip in progress. Only the trip's driver may
+// attach, and only to a PACKAGE ride's trip.
+func (s *TripAttachmentService) Attach(ctx context.Context, req AttachRequest) (*domain.TripAttachment, error) {
+	if req.TripID == "" {
+		return nil, ErrInvalidTripID
+	}
+	if req.DriverID == "" {
+		return nil, ErrInvalidDriverID
+	}
+	if len(req.Data) == 0 {
+		return nil, ErrInvalidAttachment
+	}
+	if int64(len(req.Data)) > s.maxBytes {
+		return nil, ErrAttachmentTooLarge
+	}
+	contentType := http.DetectContentType(req.Data)
+	ext, ok := attachmentExtensions[contentType]
+	if !ok {
+		return nil, ErrUnsupportedAttachmentType
+	}
+
+	trip, err := s.tripRepo.GetByID(ctx, req.TripID)
+	if err != nil {
+		return nil, err
+	}
+	if trip.DriverID != req.DriverID {
+		return nil, ErrDriverNotAssignedToRide
+	}
+	if !trip.CanTransitionTo(domain.TripStatusEnded) {
+		return nil, ErrTripAlreadyEnded
+	}
+	ride, err := s.rideRepo.GetByID(ctx, trip.RideID)
+	if err != nil {
+		return nil, err
+	}
+	if !ride.IsPackage() {
+		return nil, ErrAttachmentNotAllowed
+	}
+
+	id := uuid.New().String()
+	attachment := &domain.TripAttachment{
+		ID:          id,
+		TripID:      trip.ID,
+		UploadedBy:  req.DriverID,
+		BlobKey:     "trips/" + trip.ID + "/" + id + ext,
+		ContentType: contentType,
+		SizeBytes:   int64(len(req.Data)),
+		CreatedAt:   time.Now(),
+	}
+	if err := s.blobs.Put(ctx, attachment.BlobKey, req.Data); err != nil {
+		return nil, err
+	}
+	if err := s.attachmentRepo.Create(ctx, attachment); err != nil {
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// List returns a trip's attachments, oldest first.
+func (s *TripAttachmentService) List(ctx context.Context, tripID string) ([]*domain.TripAttachment, error) {
+	if tripID == "" {
+		return nil, ErrInvalidTripID
+	}
+	return s.attachmentRepo.ListByTrip(ctx, tripID)
+}
+
+// SignedURL returns the path the attachment's image can be fetched from
+// without further authorization, and when that stops working.
+func (s *TripAttachmentService) SignedURL(attachment *domain.TripAttachment, now time.Time) (string, time.Time) {
+	expiresAt := now.Add(s.urlTTL).Truncate(time.Second)
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("signature", s.sign(attachment.ID, expires))
+	return "/v1/attachments/" + attachment.ID + "/content?" + query.Encode(), expiresAt
+}
+
+// Open returns an attachment and its image for a URL from SignedURL.
+// Returns ErrInvalidAttachmentURL if the URL was tampered with or has
+// expired.
+func (s *TripAttachmentService) Open(ctx context.Context, id, expires, signature string, now time.Time) (*domain.TripAttachment, []byte, error) {
+	if !hmac.Equal([]byte(signature), []byte(s.sign(id, expires))) {
+		return nil, nil, ErrInvalidAttachmentURL
+	}
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || now.Unix() >= expiresAt {
+		return nil, nil, ErrInvalidAttachmentURL
+	}
+
+	attachment, err := s.attachmentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := s.blobs.Get(ctx, attachment.BlobKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return attachment, data, nil
+}
+
+// sign returns the hex HMAC-SHA256 of an attachment ID and expiry.
+func (s *TripAttachmentService) sign(id, expires string) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(id + "." + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
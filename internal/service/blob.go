@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// BlobStore is the interface for storing uploaded files, e.g. trip photos,
+// by key. Keys are slash-separated relative paths.
+type BlobStore interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+}
+
+// Blob store kinds for BLOB_STORE.
+const (
+	BlobStoreFilesystem = "filesystem"
+)
+
+// NewBlobStore returns the blob store of the given kind. Filesystem stores
+// keep blobs under dir.
+func NewBlobStore(kind, dir string) (BlobStore, error) {
+	switch kind {
+	case "", BlobStoreFilesystem:
+		return NewFilesystemBlobStore(dir)
+	default:
+		return nil, fmt.Errorf("unknown blob store %q", kind)
+	}
+}
+
+// FilesystemBlobStore is a BlobStore keeping each blob as a file under a
+// directory. Blobs are only visible to instances sharing the directory.
+type FilesystemBlobStore struct {
+	dir string
+}
+
+// NewFilesystemBlobStore creates a FilesystemBlobStore, creating dir if needed.
+func NewFilesystemBlobStore(dir string) (*FilesystemBlobStore, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("blob store directory not set")
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	return &FilesystemBlobStore{dir: dir}, nil
+}
+
+// Put writes the blob, replacing any existing one with the same key. The
+// file is written in full before it becomes visible under the key.
+func (s *FilesystemBlobStore) Put(ctx context.Context, key string, data []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Get reads the blob.
+func (s *FilesystemBlobStore) Get(ctx context.Context, key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(path)
+}
+
+// path maps a key to its file, refusing keys that would escape the directory.
+func (s *FilesystemBlobStore) path(key string) (string, error) {
+	local := filepath.FromSlash(key)
+	if !filepath.IsLocal(local) {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(s.dir, local), nil
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	"ride/internal/repository/postgres"
+)
+
+// CacheInvalidator drops cached drivers and rides. *redis.CacheStore
+// implements it.
+type CacheInvalidator interface {
+	InvalidateDriver(ctx context.Context, driverID string) error
+	InvalidateRide(ctx context.Context, rideID string) error
+}
+
+// CacheInvalidationService drops this instance's cached copy of every driver
+// or ride written by any instance, so e.g. a driver deactivated through
+// another instance's admin API is not served stale until the cache TTL.
+type CacheInvalidationService struct {
+	cache CacheInvalidator
+	done  chan struct{}
+}
+
+// NewCacheInvalidationService starts applying invalidations until the
+// channel is closed.
+func NewCacheInvalidationService(invalidations <-chan postgres.CacheInvalidation, cache CacheInvalidator) *CacheInvalidationService {
+	s := &CacheInvalidationService{cache: cache, done: make(chan struct{})}
+	go s.run(invalidations)
+	return s
+}
+
+// Wait blocks until the invalidations channel is closed and drained.
+func (s *CacheInvalidationService) Wait() {
+	<-s.done
+}
+
+func (s *CacheInvalidationService) run(invalidations <-chan postgres.CacheInvalidation) {
+	defer close(s.done)
+
+	for invalidation := range invalidations {
+		var err error
+		switch invalidation.Table {
+		case "drivers":
+			err = s.cache.InvalidateDriver(context.Background(), invalidation.ID)
+		case "rides":
+			err = s.cache.InvalidateRide(context.Background(), invalidation.ID)
+		default:
+			continue
+		}
+		// The entry expires by TTL regardless.
+		if err != nil {
+			log.Printf("[CACHE] Failed to invalidate %s %s: %v", invalidation.Table, invalidation.ID, err)
+		}
+	}
+}
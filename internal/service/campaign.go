@@ -0,0 +1,402 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"ride/internal/domain"
+	"ride/internal/repository"
+)
+
+// CampaignService manages driver incentive campaigns and credits their bonuses.
+type CampaignService struct {
+	campaignRepo        repository.CampaignRepository
+	driverRepo          repository.DriverRepository
+	earningsRepo        repository.EarningsRepository
+	historyRepo         repository.LocationHistoryRepository // Online hours; nil leaves ONLINE_HOURS campaigns without progress
+	notificationService *NotificationService
+	catalog             *domain.Catalog // Tiers a campaign may be limited to
+}
+
+// NewCampaignService creates a new CampaignService.
+func NewCampaignService(
+	campaignRepo repository.CampaignRepository,
+	driverRepo repository.DriverRepository,
+	earningsRepo repository.EarningsRepository,
+	historyRepo repository.LocationHistoryRepository,
+	notificationService *NotificationService,
+	catalog *domain.Catalog,
+) *CampaignService {
+	if catalog == nil {
+		catalog = domain.DefaultCatalog()
+	}
+	return &CampaignService{
+		campaignRepo:        campaignRepo,
+		driverRepo:          driverRepo,
+		earningsRepo:        earningsRepo,
+		historyRepo:         historyRepo,
+		notificationService: notificationService,
+		catalog:             catalog,
+	}
+}
+
+// CampaignRequest contains the parameters for creating or updating a campaign.
+type CampaignRequest struct {
+	Name         string
+	Criteria     domain.CampaignCriteria
+	Target       float64
+	RewardAmount float64
+	Tier         domain.DriverTier
+	City         string
+	StartsAt     time.Time
+	EndsAt       time.Time
+}
+
+// CreateCampaign creates a new campaign.
+func (s *CampaignService) CreateCampaign(ctx context.Context, req CampaignRequest) (*domain.Campaign, error) {
+	campaign := &domain.Campaign{
+		ID:        uuid.New().String(),
+		CreatedAt: time.Now(),
+	}
+	req.apply(campaign)
+
+	if err := s.validate(campaign); err != nil {
+		return nil, err
+	}
+
+	if err := s.campaignRepo.Create(ctx, campaign); err != nil {
+		return nil, err
+	}
+
+	return campaign, nil
+}
+
+// UpdateCampaign replaces a campaign's definition. Progress already recorded is kept.
+func (s *CampaignService) UpdateCampaign(ctx context.Context, id string, req CampaignRequest) (*domain.Campaign, error) {
+	campaign, err := s.campaignRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	req.apply(campaign)
+
+	if err := s.validate(campaign); err != nil {
+		return nil, err
+	}
+
+	if err := s.campaignRepo.Update(ctx, campaign); err != nil {
+		return nil, err
+	}
+
+	return campaign, nil
+}
+
+// GetCampaign retrieves a campaign by ID.
+func (s *CampaignService) GetCampaign(ctx context.Context, id string) (*domain.Campaign, error) {
+	return s.campaignRepo.GetByID(ctx, id)
+}
+
+// ListCampaigns retrieves all campaigns.
+func (s *CampaignService) ListCampaigns(ctx context.Context) ([]*domain.Campaign, error) {
+	return s.campaignRepo.GetAll(ctx)
+}
+
+// DeleteCampaign removes a campaign and its progress.
+func (s *CampaignService) DeleteCampaign(ctx context.Context, id string) error {
+	return s.campaignRepo.Delete(ctx, id)
+}
+
+// validate checks the campaign and that its tier, if any, is offered.
+func (s *CampaignService) validate(campaign *domain.Campaign) error {
+	if err := campaign.Validate(); err != nil {
+		return err
+	}
+	if campaign.Tier != "" && !s.catalog.HasTier(campaign.Tier) {
+		return ErrInvalidCampaignTier
+	}
+	return nil
+}
+
+func (req CampaignRequest) apply(campaign *domain.Campaign) {
+	campaign.Name = req.Name
+	campaign.Criteria = req.Criteria
+	campaign.Target = req.Target
+	campaign.RewardAmount = req.RewardAmount
+	campaign.Tier = req.Tier
+	campaign.City = domain.NormalizeCity(req.City)
+	campaign.StartsAt = req.StartsAt
+	campaign.EndsAt = req.EndsAt
+}
+
+// DriverCampaign is a driver's live standing in one active campaign.
+type DriverCampaign struct {
+	Campaign *domain.Campaign
+	Progress *domain.CampaignProgress
+}
+
+// DriverCampaigns returns the active campaigns the driver is eligible for,
+// with their progress so far. Online hours are measured live, so they run
+// ahead of the progress recorded by the last sweep.
+func (s *CampaignService) DriverCampaigns(ctx context.Context, driverID string) ([]*DriverCampaign, error) {
+	if driverID == "" {
+		return nil, ErrInvalidDriverID
+	}
+
+	driver, err := s.driverRepo.GetByID(ctx, driverID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	campaigns, err := s.campaignRepo.ListActive(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	progressRows, err := s.campaignRepo.GetProgressByDriver(ctx, driverID)
+	if err != nil {
+		return nil, err
+	}
+
+	progressByCampaign := make(map[string]*domain.CampaignProgress, len(progressRows))
+	for _, p := range progressRows {
+		progressByCampaign[p.CampaignID] = p
+	}
+
+	result := make([]*DriverCampaign, 0, len(campaigns))
+	for _, campaign := range campaigns {
+		if !campaign.IsEligible(driver) {
+			continue
+		}
+
+		progress := progressByCampaign[campaign.ID]
+		if progress == nil {
+			progress = &domain.CampaignProgress{CampaignID: campaign.ID, DriverID: driverID}
+		}
+
+		if campaign.Criteria == domain.CampaignCriteriaOnlineHours && s.historyRepo != nil {
+			hours, err := s.historyRepo.OnlineHours(ctx, driverID, campaign.StartsAt, now)
+			if err != nil {
+				return nil, err
+			}
+			progress.Progress = max(progress.Progress, hours)
+		}
+
+		result = append(result, &DriverCampaign{Campaign: campaign, Progress: progress})
+	}
+
+	return result, nil
+}
+
+// OnTripEnded counts an ended trip towards every active trip campaign its
+// driver is eligible for and credits the bonus of any campaign whose target
+// is met. ONLINE_HOURS campaigns are left to SweepOnlineHours.
+//
+// It is safe to call more than once for the same trip: each trip is counted
+// once per campaign, and each bonus is credited once per driver. A call that
+// finds a met target without a recorded completion (say, a previous attempt
+// failed between counting and crediting) finishes the completion.
+func (s *CampaignService) OnTripEnded(ctx context.Context, trip *domain.Trip) error {
+	if trip.Status != domain.TripStatusEnded {
+		return nil
+	}
+
+	driver, err := s.driverRepo.GetByID(ctx, trip.DriverID)
+	if err != nil {
+		return err
+	}
+
+	campaigns, err := s.campaignRepo.ListActive(ctx, trip.EndedAt)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, campaign := range campaigns {
+		if !campaign.Criteria.CountsTrips() || !campaign.IsEligible(driver) {
+			continue
+		}
+
+		progress, _, err := s.campaignRepo.AddTripProgress(ctx, campaign.ID, trip.DriverID, trip.ID, campaign.ProgressFor(trip))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if progress.IsCompleted() || progress.Progress < campaign.Target {
+			continue
+		}
+
+		if err := s.completeCampaign(ctx, campaign, trip.DriverID, trip.EndedAt); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// SweepOnlineHours records the progress of the drivers who met the target
+// of an ONLINE_HOURS campaign whose window overlaps [since, now], and
+// credits their bonus. Hours are counted from the campaign start to now, or
+// to its end once it is over, so the first sweep after a campaign ends still
+// completes it. Like OnTripEnded it is safe to repeat. It returns how many
+// campaigns were completed.
+func (s *CampaignService) SweepOnlineHours(ctx context.Context, since, now time.Time) (int, error) {
+	if s.historyRepo == nil {
+		return 0, nil
+	}
+
+	campaigns, err := s.campaignRepo.ListOverlapping(ctx, since, now)
+	if err != nil {
+		return 0, err
+	}
+
+	completed := 0
+	var errs []error
+	for _, campaign := range campaigns {
+		if campaign.Criteria != domain.CampaignCriteriaOnlineHours {
+			continue
+		}
+
+		until := now
+		if campaign.EndsAt.Before(until) {
+			until = campaign.EndsAt
+		}
+
+		hours, err := s.historyRepo.ListOnlineHours(ctx, campaign.StartsAt, until, campaign.Target)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		for driverID, h := range hours {
+			driver, err := s.driverRepo.GetByID(ctx, driverID)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if !campaign.IsEligible(driver) {
+				continue
+			}
+
+			progress, err := s.campaignRepo.SetProgress(ctx, campaign.ID, driverID, h)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if progress.IsCompleted() {
+				continue
+			}
+
+			if err := s.completeCampaign(ctx, campaign, driverID, until); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			completed++
+		}
+	}
+
+	return completed, errors.Join(errs...)
+}
+
+// completeCampaign credits the bonus and then records the completion. The
+// ledger reference is per campaign and driver, so the credit happens once
+// however many times this runs.
+func (s *CampaignService) completeCampaign(ctx context.Context, campaign *domain.Campaign, driverID string, at time.Time) error {
+	credited, err := s.earningsRepo.Credit(ctx, &domain.EarningsEntry{
+		ID:        uuid.New().String(),
+		DriverID:  driverID,
+		Kind:      domain.EarningsKindCampaignBonus,
+		Amount:    campaign.RewardAmount,
+		Reference: campaignBonusReference(campaign.ID, driverID),
+		CreatedAt: at,
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.campaignRepo.MarkCompleted(ctx, campaign.ID, driverID, at); err != nil {
+		return err
+	}
+
+	if credited && s.notificationService != nil {
+		_ = s.notificationService.NotifyCampaignBonus(ctx, campaign, driverID)
+	}
+
+	return nil
+}
+
+// campaignBonusReference is the earnings ledger reference for a campaign bonus.
+func campaignBonusReference(campaignID, driverID string) string {
+	return "campaign:" + campaignID + ":" + driverID
+}
+
+const defaultCampaignSweepInterval = 5 * time.Minute // Used when the configured interval is not positive
+
+// CampaignSweeper periodically completes ONLINE_HOURS campaigns. Online
+// hours accrue without any trip ending, so nothing else would notice a
+// driver reaching the target.
+type CampaignSweeper struct {
+	campaignService *CampaignService
+	interval        time.Duration
+	lastSweep       time.Time // When the last sweep ran; only touched by run
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewCampaignSweeper creates a CampaignSweeper and starts its background
+// loop. Call Close on shutdown to stop it.
+func NewCampaignSweeper(campaignService *CampaignService, interval time.Duration) *CampaignSweeper {
+	if interval <= 0 {
+		interval = defaultCampaignSweepInterval
+	}
+
+	s := &CampaignSweeper{
+		campaignService: campaignService,
+		interval:        interval,
+		lastSweep:       time.Now().Add(-interval),
+		stop:            make(chan struct{}),
+		done:            make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// Close stops the background loop, waiting for a sweep in progress.
+func (s *CampaignSweeper) Close() {
+	s.closeOnce.Do(func() { close(s.stop) })
+	<-s.done
+}
+
+// run sweeps the campaigns running since the last sweep on every interval.
+// A failed sweep is retried over the same span.
+func (s *CampaignSweeper) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			now := time.Now()
+			completed, err := s.campaignService.SweepOnlineHours(context.Background(), s.lastSweep, now)
+			if err != nil {
+				log.Printf("[CAMPAIGN] Failed to sweep online hours campaigns: %v", err)
+				continue
+			}
+			s.lastSweep = now
+			if completed > 0 {
+				log.Printf("[CAMPAIGN] Completed %d online hours campaigns", completed)
+			}
+		case <-s.stop:
+			return
+		}
+	}
+}
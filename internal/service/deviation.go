@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"ride/internal/domain"
+	"ride/internal/redis"
+	"ride/internal/repository"
+)
+
+const (
+	defaultDeviationThresholdKm      = 1.0 // Used when the configured threshold is not positive
+	defaultDeviationConsecutivePings = 3   // Used when the configured ping count is not positive
+)
+
+// DeviationService raises an alert when a driver on a trip strays from the
+// straight-line pickup-to-destination route for several pings in a row.
+type DeviationService struct {
+	tripRepo            repository.TripRepository
+	rideRepo            repository.RideRepository
+	alertRepo           repository.DeviationAlertRepository
+	deviationStore      redis.DeviationStoreInterface
+	notificationService *NotificationService
+	thresholdKm         float64 // Distance from the route that counts as off route
+	consecutivePings    int     // Off-route pings in a row before alerting
+}
+
+// NewDeviationService creates a new DeviationService.
+func NewDeviationService(
+	tripRepo repository.TripRepository,
+	rideRepo repository.RideRepository,
+	alertRepo repository.DeviationAlertRepository,
+	deviationStore redis.DeviationStoreInterface,
+	notificationService *NotificationService,
+	thresholdKm float64,
+	consecutivePings int,
+) *DeviationService {
+	if thresholdKm <= 0 {
+		thresholdKm = defaultDeviationThresholdKm
+	}
+	if consecutivePings <= 0 {
+		consecutivePings = defaultDeviationConsecutivePings
+	}
+
+	return &DeviationService{
+		tripRepo:            tripRepo,
+		rideRepo:            rideRepo,
+		alertRepo:           alertRepo,
+		deviationStore:      deviationStore,
+		notificationService: notificationService,
+		thresholdKm:         thresholdKm,
+		consecutivePings:    consecutivePings,
+	}
+}
+
+// CheckLocation checks a driver's location ping against the route of their
+// active trip. It returns the alert raised by this ping, or nil.
+//
+// An alert is raised on the ping that completes the run of consecutive
+// off-route pings; later off-route pings in the same run do not raise another.
+// An on-route ping resets the run.
+func (s *DeviationService) CheckLocation(ctx context.Context, driverID string, lat, lng float64) (*domain.RouteDeviationAlert, error) {
+	trip, err := s.tripRepo.GetActiveByDriverID(ctx, driverID)
+	if err != nil {
+		return nil, err
+	}
+	if trip == nil {
+		return nil, nil
+	}
+
+	ride, err := s.rideRepo.GetByID(ctx, trip.RideID)
+	if err != nil {
+		return nil, err
+	}
+
+	distanceKm := domain.DistanceToSegmentKm(lat, lng,
+		ride.PickupLat, ride.PickupLng, ride.DestinationLat, ride.DestinationLng)
+
+	if distanceKm <= s.thresholdKm {
+		return nil, s.deviationStore.ResetOffRoute(ctx, trip.ID)
+	}
+
+	count, err := s.deviationStore.IncrementOffRoute(ctx, trip.ID)
+	if err != nil {
+		return nil, err
+	}
+	if count != int64(s.consecutivePings) {
+		return nil, nil
+	}
+
+	alert := &domain.RouteDeviationAlert{
+		ID:               uuid.New().String(),
+		TripID:           trip.ID,
+		RideID:           ride.ID,
+		DriverID:         driverID,
+		Lat:              lat,
+		Lng:              lng,
+		DistanceKm:       distanceKm,
+		ConsecutivePings: s.consecutivePings,
+		CreatedAt:        time.Now(),
+	}
+	if err := s.alertRepo.Create(ctx, alert); err != nil {
+		return nil, err
+	}
+
+	if s.notificationService != nil {
+		_ = s.notificationService.NotifyRouteDeviation(ctx, alert, ride.RiderID)
+	}
+
+	return alert, nil
+}
@@ -2,17 +2,32 @@ package service
 
 import (
 	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
 
 	"ride/internal/domain"
 	"ride/internal/redis"
 	"ride/internal/repository"
 )
 
+// defaultDestinationTTL is how long destination mode lasts when the
+// configured TTL is not positive.
+const defaultDestinationTTL = 2 * time.Hour
+
 // DriverService handles driver operations.
 type DriverService struct {
-	locationStore redis.LocationStoreInterface
-	cacheStore    *redis.CacheStore
-	driverRepo    repository.DriverRepository
+	locationStore  redis.LocationStoreInterface
+	cacheStore     *redis.CacheStore
+	driverRepo     repository.DriverRepository
+	deviation      *DeviationService               // Optional: nil disables route deviation checks
+	history        *LocationHistoryService         // Optional: nil records no location history
+	arrivals       *TripService                    // Optional: nil disables driver arrival detection
+	destinations   redis.DestinationStoreInterface // Optional: nil disables destination mode
+	destinationTTL time.Duration                   // How long destination mode lasts without a match
+	guard          *LocationGuardService           // Optional: nil accepts updates at any speed
 }
 
 // NewDriverService creates a new DriverService.
@@ -20,11 +35,26 @@ func NewDriverService(
 	locationStore redis.LocationStoreInterface,
 	cacheStore *redis.CacheStore,
 	driverRepo repository.DriverRepository,
+	deviation *DeviationService,
+	history *LocationHistoryService,
+	arrivals *TripService,
+	destinations redis.DestinationStoreInterface,
+	destinationTTL time.Duration,
+	guard *LocationGuardService,
 ) *DriverService {
+	if destinationTTL <= 0 {
+		destinationTTL = defaultDestinationTTL
+	}
 	return &DriverService{
-		locationStore: locationStore,
-		cacheStore:    cacheStore,
-		driverRepo:    driverRepo,
+		locationStore:  locationStore,
+		cacheStore:     cacheStore,
+		driverRepo:     driverRepo,
+		deviation:      deviation,
+		history:        history,
+		arrivals:       arrivals,
+		destinations:   destinations,
+		destinationTTL: destinationTTL,
+		guard:          guard,
 	}
 }
 
@@ -33,51 +63,288 @@ type UpdateLocationRequest struct {
 	DriverID string
 	Lat      float64
 	Lng      float64
+	Heading  float64 // Optional compass bearing in degrees
+
+	// RecordedAt is when the device took the fix; zero means now.
+	RecordedAt time.Time
 }
 
-// UpdateLocation updates a driver's location in Redis and sets them ONLINE.
+// UpdateLocation updates a driver's location in Redis and sets them ONLINE,
+// unless they are on a break.
 // Optimized with cache invalidation and available driver tracking.
+//
+// An update implying an implausible speed is rejected with
+// ErrImplausibleSpeed; the driver keeps their last position and status. A
+// driver held for review after repeated rejections stays out of the
+// available set.
 func (s *DriverService) UpdateLocation(ctx context.Context, req UpdateLocationRequest) error {
 	if req.DriverID == "" {
 		return ErrInvalidDriverID
 	}
 
-	if !isValidLatitude(req.Lat) || !isValidLongitude(req.Lng) {
+	if !domain.IsValidCoordinate(req.Lat, req.Lng) {
 		return ErrInvalidLocation
 	}
 
+	if !domain.IsValidHeading(req.Heading) {
+		return ErrInvalidHeading
+	}
+
+	held, err := s.checkSpeed(ctx, req)
+	if err != nil {
+		if held && s.cacheStore != nil {
+			_ = s.cacheStore.RemoveAvailableDriver(ctx, req.DriverID)
+		}
+		return err
+	}
+
 	// Update location in Redis (primary real-time data store)
-	if err := s.locationStore.UpdateLocation(ctx, req.DriverID, req.Lat, req.Lng); err != nil {
+	if err := s.locationStore.UpdateLocation(ctx, req.DriverID, req.Lat, req.Lng, req.Heading); err != nil {
 		return err
 	}
 
+	if s.history != nil {
+		s.history.Record(req.DriverID, req.Lat, req.Lng, req.Heading, req.RecordedAt)
+	}
+
 	// Set driver status to ONLINE when they update location
-	err := s.driverRepo.UpdateStatus(ctx, req.DriverID, domain.DriverStatusOnline)
-	if err != nil && err != repository.ErrNotFound {
+	available, err := s.markOnline(ctx, req.DriverID)
+	if err != nil {
 		return err
 	}
 
 	if s.cacheStore != nil {
 		// Add to available drivers set for fast lookup
-		_ = s.cacheStore.AddAvailableDriver(ctx, req.DriverID)
+		if held {
+			_ = s.cacheStore.RemoveAvailableDriver(ctx, req.DriverID)
+		} else if available {
+			_ = s.cacheStore.AddAvailableDriver(ctx, req.DriverID)
+		}
 
 		// Update driver cache with new status
 		driver, err := s.driverRepo.GetByID(ctx, req.DriverID)
 		if err == nil {
 			cached := &redis.CachedDriver{
-				ID:     driver.ID,
-				Name:   driver.Name,
-				Phone:  driver.Phone,
-				Status: string(driver.Status),
-				Tier:   string(driver.Tier),
+				ID:           driver.ID,
+				Name:         driver.Name,
+				Phone:        driver.Phone,
+				Status:       string(driver.Status),
+				Tier:         string(driver.Tier),
+				VehiclePlate: driver.VehiclePlate,
+				Capabilities: driver.Capabilities,
 			}
 			_ = s.cacheStore.SetDriver(ctx, cached)
 		}
 	}
 
+	s.checkRouteDeviation(ctx, req)
+	s.detectArrival(ctx, req)
+
 	return nil
 }
 
+// markOnline sets the driver ONLINE after a location update and reports
+// whether they are now available for offers. A driver on a break stays on
+// it; the status change is conditional on the status just read, so a break
+// started concurrently is not overwritten.
+func (s *DriverService) markOnline(ctx context.Context, driverID string) (bool, error) {
+	driver, err := s.driverRepo.GetByID(ctx, driverID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if driver.Status == domain.DriverStatusBreak {
+		return false, nil
+	}
+
+	return s.driverRepo.TransitionStatus(ctx, driverID, driver.Status, domain.DriverStatusOnline)
+}
+
+// ChangeTier moves a driver to another tier, e.g. after a vehicle upgrade,
+// and records the change. The driver's cached entry is dropped so matching
+// sees the new tier on its next search. tier must already be validated
+// against the catalog.
+func (s *DriverService) ChangeTier(ctx context.Context, driverID string, tier domain.DriverTier) (*domain.DriverTierChange, error) {
+	if driverID == "" {
+		return nil, ErrInvalidDriverID
+	}
+	if tier == "" {
+		return nil, ErrInvalidTier
+	}
+
+	driver, err := s.driverRepo.GetByID(ctx, driverID)
+	if err != nil {
+		return nil, err
+	}
+	if driver.Tier == tier {
+		return nil, ErrDriverTierUnchanged
+	}
+
+	change := &domain.DriverTierChange{
+		ID:       uuid.New().String(),
+		DriverID: driverID,
+		Tier:     tier,
+	}
+	if err := s.driverRepo.ChangeTier(ctx, change); err != nil {
+		return nil, err
+	}
+
+	if s.cacheStore != nil {
+		_ = s.cacheStore.InvalidateDriver(ctx, driverID)
+	}
+
+	log.Printf("[DRIVER] Driver %s moved from tier %s to %s", driverID, change.PreviousTier, change.Tier)
+	return change, nil
+}
+
+// StartBreak puts an ONLINE driver on a break: they keep their position on
+// the map but receive no offers until they resume. Starting a break twice is
+// not an error.
+func (s *DriverService) StartBreak(ctx context.Context, driverID string) (*domain.Driver, error) {
+	if driverID == "" {
+		return nil, ErrInvalidDriverID
+	}
+
+	moved, err := s.driverRepo.TransitionStatus(ctx, driverID, domain.DriverStatusOnline, domain.DriverStatusBreak)
+	if err != nil {
+		return nil, err
+	}
+
+	driver, err := s.driverRepo.GetByID(ctx, driverID)
+	if err != nil {
+		return nil, err
+	}
+	if !moved && driver.Status != domain.DriverStatusBreak {
+		return nil, ErrDriverNotOnline
+	}
+
+	if s.cacheStore != nil {
+		_ = s.cacheStore.RemoveAvailableDriver(ctx, driverID)
+		_ = s.cacheStore.InvalidateDriver(ctx, driverID)
+	}
+
+	return driver, nil
+}
+
+// SetDestination puts the driver in destination mode: until they are matched
+// or the mode expires, they are only offered rides heading roughly toward
+// the given point. Setting it again replaces the destination and restarts
+// the expiry.
+func (s *DriverService) SetDestination(ctx context.Context, driverID string, lat, lng float64) (*redis.DriverDestination, error) {
+	if driverID == "" {
+		return nil, ErrInvalidDriverID
+	}
+	if !domain.IsValidCoordinate(lat, lng) {
+		return nil, ErrInvalidLocation
+	}
+	if s.destinations == nil {
+		return nil, ErrDestinationModeUnavailable
+	}
+
+	if _, err := s.driverRepo.GetByID(ctx, driverID); err != nil {
+		return nil, err
+	}
+
+	destination := redis.DriverDestination{Lat: lat, Lng: lng, ExpiresAt: time.Now().Add(s.destinationTTL)}
+	if err := s.destinations.SetDestination(ctx, driverID, destination); err != nil {
+		return nil, err
+	}
+	return &destination, nil
+}
+
+// ClearDestination takes the driver out of destination mode. Clearing it
+// when not set is not an error.
+func (s *DriverService) ClearDestination(ctx context.Context, driverID string) error {
+	if driverID == "" {
+		return ErrInvalidDriverID
+	}
+	if s.destinations == nil {
+		return ErrDestinationModeUnavailable
+	}
+	return s.destinations.ClearDestination(ctx, driverID)
+}
+
+// EndBreak returns a driver on a break to ONLINE, making them available for
+// offers again. Resuming a driver who is already ONLINE is not an error.
+func (s *DriverService) EndBreak(ctx context.Context, driverID string) (*domain.Driver, error) {
+	if driverID == "" {
+		return nil, ErrInvalidDriverID
+	}
+
+	moved, err := s.driverRepo.TransitionStatus(ctx, driverID, domain.DriverStatusBreak, domain.DriverStatusOnline)
+	if err != nil {
+		return nil, err
+	}
+
+	driver, err := s.driverRepo.GetByID(ctx, driverID)
+	if err != nil {
+		return nil, err
+	}
+	if !moved && driver.Status != domain.DriverStatusOnline {
+		return nil, ErrDriverNotOnBreak
+	}
+
+	if s.cacheStore != nil {
+		_ = s.cacheStore.AddAvailableDriver(ctx, driverID)
+		_ = s.cacheStore.InvalidateDriver(ctx, driverID)
+	}
+
+	return driver, nil
+}
+
+// checkSpeed runs the update past the speed guard, if any, and reports
+// whether the driver is held for review.
+func (s *DriverService) checkSpeed(ctx context.Context, req UpdateLocationRequest) (bool, error) {
+	if s.guard == nil {
+		return false, nil
+	}
+	return s.guard.CheckLocation(ctx, req.DriverID, req.Lat, req.Lng)
+}
+
+// checkRouteDeviation checks the ping against the driver's active trip route.
+// Failures are logged rather than returned: the location update itself has
+// already succeeded.
+func (s *DriverService) checkRouteDeviation(ctx context.Context, req UpdateLocationRequest) {
+	if s.deviation == nil {
+		return
+	}
+	if _, err := s.deviation.CheckLocation(ctx, req.DriverID, req.Lat, req.Lng); err != nil {
+		log.Printf("[DEVIATION] failed to check route for driver %s: %v", req.DriverID, err)
+	}
+}
+
+// detectArrival checks the ping against the pickup point of the driver's
+// assigned ride. Like checkRouteDeviation, failures are only logged.
+func (s *DriverService) detectArrival(ctx context.Context, req UpdateLocationRequest) {
+	if s.arrivals == nil {
+		return
+	}
+	if _, err := s.arrivals.DetectArrival(ctx, req.DriverID, req.Lat, req.Lng); err != nil {
+		log.Printf("[ARRIVAL] failed to check arrival for driver %s: %v", req.DriverID, err)
+	}
+}
+
+// nearbyDriversLimit caps how many drivers the rider map shows.
+const nearbyDriversLimit = 50
+
+// NearbyDrivers returns the positions and headings of drivers within
+// radiusKm of the given point, nearest first. A zero radius uses the
+// matching search radius.
+func (s *DriverService) NearbyDrivers(ctx context.Context, lat, lng, radiusKm float64) ([]redis.DriverLocation, error) {
+	if !domain.IsValidCoordinate(lat, lng) {
+		return nil, ErrInvalidLocation
+	}
+
+	if radiusKm <= 0 {
+		radiusKm = defaultSearchRadiusKm
+	}
+
+	return s.locationStore.FindNearbyDrivers(ctx, lat, lng, radiusKm, nearbyDriversLimit)
+}
+
 // SetDriverOffline sets a driver as offline and updates cache.
 func (s *DriverService) SetDriverOffline(ctx context.Context, driverID string) error {
 	if driverID == "" {
@@ -101,3 +368,80 @@ func (s *DriverService) SetDriverOffline(ctx context.Context, driverID string) e
 
 	return nil
 }
+
+// SweepStaleLocations removes drivers whose last location ping is older
+// than maxAge from the geo indexes and the available set, so a driver whose
+// app died without going offline stops being offered rides. Their status is
+// left alone: the next ping puts them back. It returns how many were removed.
+func (s *DriverService) SweepStaleLocations(ctx context.Context, now time.Time, maxAge time.Duration) (int, error) {
+	driverIDs, err := s.locationStore.RemoveStale(ctx, now.Add(-maxAge))
+	if err != nil {
+		return 0, err
+	}
+
+	if s.cacheStore != nil {
+		for _, driverID := range driverIDs {
+			_ = s.cacheStore.RemoveAvailableDriver(ctx, driverID)
+		}
+	}
+	return len(driverIDs), nil
+}
+
+// DriverRegistration contains the details a driver registers with.
+type DriverRegistration struct {
+	Name         string
+	Phone        string
+	Tier         string
+	Email        string   // Optional
+	VehiclePlate string   // Optional
+	City         string   // Optional: the city the driver works in
+	Capabilities []string // Optional: vehicle capabilities, any case, e.g. WAV
+}
+
+// NewRegisteredDriver validates reg and returns the OFFLINE driver it
+// registers, with the phone in E.164 form (numbers without a country code
+// are read as phoneRegion) and the email, plate, city and capabilities
+// normalized. Every invalid field is reported in one ValidationError. It
+// does not check that the phone or email is free.
+func NewRegisteredDriver(reg DriverRegistration, phoneRegion string, catalog *domain.Catalog) (*domain.Driver, error) {
+	var problems ValidationError
+
+	if reg.Name == "" {
+		problems.Add("name", ErrFieldRequired)
+	}
+
+	tier, err := ValidateTier(reg.Tier, catalog)
+	problems.Add("tier", err)
+
+	var phone string
+	if reg.Phone == "" {
+		problems.Add("phone", ErrFieldRequired)
+	} else {
+		phone, err = NormalizePhone(reg.Phone, phoneRegion)
+		problems.Add("phone", err)
+	}
+
+	email := domain.NormalizeEmail(reg.Email)
+	if email != "" && !domain.IsValidEmail(email) {
+		problems.Add("email", ErrInvalidEmail)
+	}
+
+	capabilities, err := ValidateCapabilities(reg.Capabilities)
+	problems.Add("capabilities", err)
+
+	if err := problems.Err(); err != nil {
+		return nil, err
+	}
+
+	return &domain.Driver{
+		ID:           uuid.New().String(),
+		Name:         reg.Name,
+		Phone:        phone,
+		Status:       domain.DriverStatusOffline,
+		Tier:         tier,
+		Email:        email,
+		VehiclePlate: strings.ToUpper(strings.TrimSpace(reg.VehiclePlate)),
+		City:         domain.NormalizeCity(reg.City),
+		Capabilities: capabilities,
+	}, nil
+}
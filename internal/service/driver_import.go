@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"ride/internal/domain"
+	"ride/internal/repository"
+	"ride/internal/repository/postgres"
+)
+
+const defaultDriverImportMaxRows = 500 // Used when the configured row cap is not positive
+
+// DriverImportService onboards batches of drivers for fleet operators. Each
+// row is validated as a driver registration and written in its own
+// transaction, so one bad row does not hold back the rest of the batch.
+type DriverImportService struct {
+	db          *sql.DB // Optional: nil writes each row through driverRepo without a transaction
+	driverRepo  repository.DriverRepository
+	phoneRegion string          // Region assumed for phone numbers without a country code
+	catalog     *domain.Catalog // Tiers a driver may register for
+	maxRows     int
+}
+
+// NewDriverImportService creates a new DriverImportService. A nil catalog
+// means the default catalog.
+func NewDriverImportService(db *sql.DB, driverRepo repository.DriverRepository, phoneRegion string, catalog *domain.Catalog, maxRows int) *DriverImportService {
+	if catalog == nil {
+		catalog = domain.DefaultCatalog()
+	}
+	if maxRows <= 0 {
+		maxRows = defaultDriverImportMaxRows
+	}
+	return &DriverImportService{
+		db:          db,
+		driverRepo:  driverRepo,
+		phoneRegion: phoneRegion,
+		catalog:     catalog,
+		maxRows:     maxRows,
+	}
+}
+
+// DriverImportRow is the outcome of one row of an import.
+type DriverImportRow struct {
+	Row      int    // 1-based position in the batch
+	DriverID string // Set when the driver was created
+	Err      error  // Set when the row was rejected
+}
+
+// DriverImportResult summarizes an import, with every row in batch order.
+type DriverImportResult struct {
+	Imported int
+	Failed   int
+	Rows     []DriverImportRow
+}
+
+// Import registers each driver in regs. Rows that fail validation or whose
+// phone or email is already registered, including by an earlier row, are
+// reported and skipped. It returns ErrEmptyImport or ErrImportTooLarge
+// without writing anything if the batch is empty or over the row cap.
+func (s *DriverImportService) Import(ctx context.Context, regs []DriverRegistration) (*DriverImportResult, error) {
+	if len(regs) == 0 {
+		return nil, ErrEmptyImport
+	}
+	if len(regs) > s.maxRows {
+		return nil, fmt.Errorf("%w: %d rows, limit is %d", ErrImportTooLarge, len(regs), s.maxRows)
+	}
+
+	result := &DriverImportResult{Rows: make([]DriverImportRow, 0, len(regs))}
+	for i, reg := range regs {
+		row := DriverImportRow{Row: i + 1}
+		driver, err := s.importRow(ctx, reg)
+		if err != nil {
+			row.Err = err
+			result.Failed++
+		} else {
+			row.DriverID = driver.ID
+			result.Imported++
+		}
+		result.Rows = append(result.Rows, row)
+	}
+
+	log.Printf("[IMPORT] Imported %d of %d drivers, %d failed", result.Imported, len(regs), result.Failed)
+	return result, nil
+}
+
+// importRow validates and creates one driver in its own transaction.
+func (s *DriverImportService) importRow(ctx context.Context, reg DriverRegistration) (driver *domain.Driver, err error) {
+	driver, err = NewRegisteredDriver(reg, s.phoneRegion, s.catalog)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.db == nil {
+		return driver, s.createDriver(ctx, s.driverRepo, driver)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if err = s.createDriver(ctx, postgres.NewDriverRepositoryWithTx(tx), driver); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return driver, nil
+}
+
+// createDriver creates driver unless its phone or email is already
+// registered. The unique constraints catch a registration racing the checks.
+func (s *DriverImportService) createDriver(ctx context.Context, driverRepo repository.DriverRepository, driver *domain.Driver) error {
+	if _, err := driverRepo.GetByPhone(ctx, driver.Phone); err == nil {
+		return ErrDriverAlreadyRegistered
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+
+	if driver.Email != "" {
+		if _, err := driverRepo.GetByEmail(ctx, driver.Email); err == nil {
+			return ErrEmailTaken
+		} else if !errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+	}
+
+	if err := driverRepo.Create(ctx, driver); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return ErrDriverAlreadyRegistered
+		}
+		return err
+	}
+	return nil
+}
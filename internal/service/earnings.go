@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"ride/internal/domain"
+	"ride/internal/repository"
+)
+
+const defaultCommissionPercent = 20.0 // Used when the configured commission is outside 0-100
+
+// EarningsService breaks down drivers' earnings per trip: the fare, its
+// surge portion, the platform's commission and any tip.
+type EarningsService struct {
+	tripRepo          repository.TripRepository
+	rideRepo          repository.RideRepository
+	earningsRepo      repository.EarningsRepository
+	paymentService    *PaymentService // Charges riders' tips
+	commissionPercent float64         // Platform's share of each fare, excluding surcharges
+}
+
+// NewEarningsService creates a new EarningsService.
+func NewEarningsService(
+	tripRepo repository.TripRepository,
+	rideRepo repository.RideRepository,
+	earningsRepo repository.EarningsRepository,
+	paymentService *PaymentService,
+	commissionPercent float64,
+) *EarningsService {
+	if commissionPercent < 0 || commissionPercent > 100 {
+		commissionPercent = defaultCommissionPercent
+	}
+
+	return &EarningsService{
+		tripRepo:          tripRepo,
+		rideRepo:          rideRepo,
+		earningsRepo:      earningsRepo,
+		paymentService:    paymentService,
+		commissionPercent: commissionPercent,
+	}
+}
+
+// TripEarnings returns the driver's earnings on one of their trips. A trip
+// that is not the driver's is reported as not found; one still in progress
+// has no earnings yet.
+func (s *EarningsService) TripEarnings(ctx context.Context, driverID, tripID string) (*domain.TripEarnings, error) {
+	if driverID == "" {
+		return nil, ErrInvalidDriverID
+	}
+	if tripID == "" {
+		return nil, ErrInvalidTripID
+	}
+
+	trip, err := s.tripRepo.GetByID(ctx, tripID)
+	if err != nil {
+		return nil, err
+	}
+	if trip.DriverID != driverID {
+		return nil, repository.ErrNotFound
+	}
+	if trip.Status != domain.TripStatusEnded && trip.Status != domain.TripStatusAborted {
+		return nil, ErrTripNotEnded
+	}
+
+	ride, err := s.rideRepo.GetByID(ctx, trip.RideID)
+	if err != nil {
+		return nil, err
+	}
+
+	var tip float64
+	entry, err := s.earningsRepo.GetByReference(ctx, TipReference(trip.ID))
+	switch {
+	case err == nil:
+		tip = entry.Amount
+	case !errors.Is(err, repository.ErrNotFound):
+		return nil, err
+	}
+
+	earnings := domain.NewTripEarnings(trip, ride, s.commissionPercent, tip)
+	return &earnings, nil
+}
+
+// tipIdempotencyKey identifies the tip among a trip's charges, so a trip is
+// tipped once however many times the rider asks.
+const tipIdempotencyKey = "tip"
+
+// TipTrip charges the rider a tip on an ended trip and, once the charge
+// succeeds, credits it to the driver's ledger in full. A trip that is not the
+// rider's is reported as not found. Tips on cash rides are handed over in
+// cash, so they are not charged here. A FAILED charge is returned as is and
+// tipping again retries it.
+func (s *EarningsService) TipTrip(ctx context.Context, riderID, tripID string, amount float64) (*domain.Payment, error) {
+	if tripID == "" {
+		return nil, ErrInvalidTripID
+	}
+	if amount <= 0 {
+		return nil, ErrInvalidPaymentAmount
+	}
+
+	trip, err := s.tripRepo.GetByID(ctx, tripID)
+	if err != nil {
+		return nil, err
+	}
+	ride, err := s.rideRepo.GetByID(ctx, trip.RideID)
+	if err != nil {
+		return nil, err
+	}
+	if riderID == "" || ride.RiderID != riderID {
+		return nil, repository.ErrNotFound
+	}
+	if trip.Status != domain.TripStatusEnded {
+		return nil, ErrTripNotEnded
+	}
+	if ride.PaymentMethod == domain.PaymentMethodCash {
+		return nil, ErrInvalidPaymentMethod
+	}
+
+	payment, err := s.paymentService.ProcessPayment(ctx, ProcessPaymentRequest{
+		TripID: trip.ID,
+		Amount: amount,
+		Method: ride.PaymentMethod,
+
+		InstrumentID:   ride.InstrumentID,
+		IdempotencyKey: tipIdempotencyKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status != domain.PaymentStatusSuccess {
+		return payment, nil
+	}
+
+	// Credit what the tip payment charged, less its processing fee: a repeat
+	// request returns the first tip, whatever amount it asked for.
+	if _, err := s.earningsRepo.Credit(ctx, &domain.EarningsEntry{
+		ID:        uuid.New().String(),
+		DriverID:  trip.DriverID,
+		Kind:      domain.EarningsKindTip,
+		Amount:    roundCents(payment.Amount - payment.Fee),
+		Reference: TipReference(trip.ID),
+		CreatedAt: time.Now(),
+	}); err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+// TipReference is the earnings ledger reference for the tip on a trip.
+func TipReference(tripID string) string {
+	return "tip:" + tripID
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/redis"
+	"ride/internal/repository"
+)
+
+const (
+	defaultEmailVerificationTTL = 24 * time.Hour // Used when the configured TTL is not positive
+	defaultEmailResendCooldown  = time.Minute    // Used when the configured cooldown is not positive
+)
+
+// EmailSender is the interface for delivering email.
+type EmailSender interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+// LogEmailSender is an EmailSender that writes messages to the log instead of
+// delivering them.
+type LogEmailSender struct{}
+
+// NewLogEmailSender creates a new LogEmailSender.
+func NewLogEmailSender() *LogEmailSender {
+	return &LogEmailSender{}
+}
+
+// Send logs the message. Always succeeds.
+func (s *LogEmailSender) Send(ctx context.Context, to, subject, body string) error {
+	log.Printf("[EMAIL] to=%s subject=%q\n%s", to, subject, body)
+	return nil
+}
+
+// EmailVerificationService confirms that users own the email addresses they
+// give us. The address is only stored on the user once verified; until then
+// it lives with the token.
+type EmailVerificationService struct {
+	userRepo       repository.UserRepository
+	tokenStore     redis.EmailTokenStoreInterface
+	sender         EmailSender
+	tokenTTL       time.Duration // How long a verification token stays valid
+	resendCooldown time.Duration // Minimum gap between verification emails per user
+}
+
+// NewEmailVerificationService creates a new EmailVerificationService.
+func NewEmailVerificationService(
+	userRepo repository.UserRepository,
+	tokenStore redis.EmailTokenStoreInterface,
+	sender EmailSender,
+	tokenTTL time.Duration,
+	resendCooldown time.Duration,
+) *EmailVerificationService {
+	if tokenTTL <= 0 {
+		tokenTTL = defaultEmailVerificationTTL
+	}
+	if resendCooldown <= 0 {
+		resendCooldown = defaultEmailResendCooldown
+	}
+
+	return &EmailVerificationService{
+		userRepo:       userRepo,
+		tokenStore:     tokenStore,
+		sender:         sender,
+		tokenTTL:       tokenTTL,
+		resendCooldown: resendCooldown,
+	}
+}
+
+// RequestVerification sends a verification token for email to the user.
+// Sending is rate limited per user.
+func (s *EmailVerificationService) RequestVerification(ctx context.Context, userID, email string) error {
+	if userID == "" {
+		return ErrInvalidRiderID
+	}
+
+	email = domain.NormalizeEmail(email)
+	if !domain.IsValidEmail(email) {
+		return ErrInvalidEmail
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.Email == email && user.EmailVerified {
+		return ErrEmailAlreadyVerified
+	}
+	if err := s.checkEmailAvailable(ctx, userID, email); err != nil {
+		return err
+	}
+
+	ok, err := s.tokenStore.AcquireResendSlot(ctx, userID, s.resendCooldown)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrEmailResendTooSoon
+	}
+
+	token, err := newVerificationToken()
+	if err != nil {
+		return err
+	}
+	verification := redis.EmailVerification{UserID: userID, Email: email}
+	if err := s.tokenStore.SaveVerification(ctx, token, verification, s.tokenTTL); err != nil {
+		return err
+	}
+
+	body := "Use this token to verify your email address: " + token +
+		"\nIt expires in " + s.tokenTTL.String() + "."
+	return s.sender.Send(ctx, email, "Verify your email address", body)
+}
+
+// VerifyEmail consumes a verification token and marks its address verified
+// on the user. Each token works once.
+func (s *EmailVerificationService) VerifyEmail(ctx context.Context, token string) (*domain.User, error) {
+	if token == "" {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	verification, err := s.tokenStore.ConsumeVerification(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if verification == nil {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	user, err := s.userRepo.GetByID(ctx, verification.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Another user may have verified the address since the token was sent.
+	if err := s.checkEmailAvailable(ctx, user.ID, verification.Email); err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.UpdateEmail(ctx, user.ID, verification.Email, true); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, ErrEmailTaken
+		}
+		return nil, err
+	}
+
+	user.Email = verification.Email
+	user.EmailVerified = true
+	return user, nil
+}
+
+// checkEmailAvailable returns ErrEmailTaken if email belongs to a user other
+// than userID.
+func (s *EmailVerificationService) checkEmailAvailable(ctx context.Context, userID, email string) error {
+	owner, err := s.userRepo.GetByEmail(ctx, email)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if owner.ID != userID {
+		return ErrEmailTaken
+	}
+	return nil
+}
+
+// newVerificationToken returns a random, URL-safe verification token.
+func newVerificationToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -1,31 +1,40 @@
 package service
 
-import "errors"
+import (
+	"errors"
+
+	"ride/internal/domain"
+)
 
 var (
 	// ErrNoDriverAvailable is returned when no driver can be matched.
 	ErrNoDriverAvailable = errors.New("no driver available")
 
+	// ErrMatchingCandidatesExhausted is returned when every candidate up to
+	// the per-match cap was tried without an assignment. More drivers may be
+	// in range, so the match is worth retrying soon at the same radius.
+	ErrMatchingCandidatesExhausted = errors.New("matching candidates exhausted")
+
 	// ErrRideNotInRequestedState is returned when trying to match a ride not in REQUESTED state.
 	ErrRideNotInRequestedState = errors.New("ride not in requested state")
 
 	// ErrInvalidRiderID is returned when rider ID is empty.
-	ErrInvalidRiderID = errors.New("invalid rider id")
+	ErrInvalidRiderID = domain.ErrInvalidRiderID
 
 	// ErrInvalidRideID is returned when ride ID is empty.
-	ErrInvalidRideID = errors.New("invalid ride id")
+	ErrInvalidRideID = domain.ErrInvalidRideID
 
 	// ErrInvalidPickupLocation is returned when pickup coordinates are invalid.
-	ErrInvalidPickupLocation = errors.New("invalid pickup location")
+	ErrInvalidPickupLocation = domain.ErrInvalidPickupLocation
 
 	// ErrInvalidDestinationLocation is returned when destination coordinates are invalid.
-	ErrInvalidDestinationLocation = errors.New("invalid destination location")
+	ErrInvalidDestinationLocation = domain.ErrInvalidDestinationLocation
 
 	// ErrInvalidDriverID is returned when driver ID is empty.
-	ErrInvalidDriverID = errors.New("invalid driver id")
+	ErrInvalidDriverID = domain.ErrInvalidDriverID
 
 	// ErrInvalidTripID is returned when trip ID is empty.
-	ErrInvalidTripID = errors.New("invalid trip id")
+	ErrInvalidTripID = domain.ErrInvalidTripID
 
 	// ErrDriverHasActiveTrip is returned when driver already has an active trip.
 	ErrDriverHasActiveTrip = errors.New("driver already has an active trip")
@@ -33,9 +42,27 @@ var (
 	// ErrRideNotAssigned is returned when ride is not in ASSIGNED state.
 	ErrRideNotAssigned = errors.New("ride not assigned")
 
+	// ErrDriverNotOnline is returned when a driver who is not ONLINE starts a break.
+	ErrDriverNotOnline = errors.New("driver not online")
+
+	// ErrDriverNotOnBreak is returned when a driver who is not on a break resumes.
+	ErrDriverNotOnBreak = errors.New("driver not on break")
+
 	// ErrDriverNotAssignedToRide is returned when driver is not assigned to the ride.
 	ErrDriverNotAssignedToRide = errors.New("driver not assigned to this ride")
 
+	// ErrNotTheDriver is returned when a caller acts as a driver they are
+	// not, e.g. reporting another driver's location.
+	ErrNotTheDriver = errors.New("caller is not the driver")
+
+	// ErrDriverNotEligible is returned when assigning a ride to a driver a
+	// match would have skipped: one excluded from the ride, e.g. blocked by
+	// the rider, or without the ride's tier or vehicle capabilities.
+	ErrDriverNotEligible = errors.New("driver not eligible for this ride")
+
+	// ErrDriverTooFarFromPickup is returned when a driver starts a trip away from the pickup point.
+	ErrDriverTooFarFromPickup = errors.New("driver too far from pickup")
+
 	// ErrTripAlreadyEnded is returned when trying to end an already ended trip.
 	ErrTripAlreadyEnded = errors.New("trip already ended")
 
@@ -45,24 +72,269 @@ var (
 	// ErrTripNotPaused is returned when trying to resume a trip that isn't paused.
 	ErrTripNotPaused = errors.New("trip not paused")
 
+	// ErrTripNotUnderReview is returned when approving the fare of a trip that is not held for review.
+	ErrTripNotUnderReview = errors.New("trip fare not under review")
+
+	// ErrTripUnderReview is returned when adjusting the fare of a trip that is
+	// still held for review; approve it instead.
+	ErrTripUnderReview = errors.New("trip fare under review")
+
+	// ErrTripNotAwaitingConfirmation is returned when the rider confirms or
+	// disputes a trip that is not waiting for them.
+	ErrTripNotAwaitingConfirmation = errors.New("trip not awaiting confirmation")
+
+	// ErrTripNotDisputed is returned when resolving a dispute on a trip that is not disputed.
+	ErrTripNotDisputed = errors.New("trip not disputed")
+
+	// ErrTripPaymentOnHold is returned when adjusting the fare of a trip whose
+	// payment is held for the rider's confirmation or a dispute.
+	ErrTripPaymentOnHold = errors.New("trip payment on hold")
+
+	// ErrDisputeReasonRequired is returned when a trip is disputed without a reason.
+	ErrDisputeReasonRequired = errors.New("dispute reason required")
+
+	// ErrInvalidDisputeOutcome is returned when a dispute is resolved other than by CHARGE or VOID.
+	ErrInvalidDisputeOutcome = errors.New("invalid dispute outcome")
+
+	// ErrFareUnchanged is returned when adjusting a trip's fare to what it
+	// already is.
+	ErrFareUnchanged = errors.New("fare unchanged")
+
+	// ErrAdjustmentReasonRequired is returned when a fare is adjusted without a reason.
+	ErrAdjustmentReasonRequired = errors.New("adjustment reason required")
+
+	// ErrInvalidFare is returned when a fare is not positive.
+	ErrInvalidFare = domain.ErrInvalidFare
+
+	// ErrInvalidPauseReason is returned when a pause reason is not one of the known reasons.
+	ErrInvalidPauseReason = domain.ErrInvalidPauseReason
+
+	// ErrInvalidAbortParty is returned when a trip abort names neither the rider nor the driver.
+	ErrInvalidAbortParty = domain.ErrInvalidAbortParty
+
+	// ErrAbortReasonRequired is returned when a trip is aborted without a reason.
+	ErrAbortReasonRequired = errors.New("abort reason required")
+
 	// ErrInvalidPaymentAmount is returned when payment amount is invalid.
 	ErrInvalidPaymentAmount = errors.New("invalid payment amount")
 
 	// ErrInvalidPaymentID is returned when payment ID is empty.
 	ErrInvalidPaymentID = errors.New("invalid payment id")
 
+	// ErrInvalidIdempotencyKey is returned when a client idempotency key is too long.
+	ErrInvalidIdempotencyKey = errors.New("invalid idempotency key")
+
 	// ErrInvalidLocation is returned when location coordinates are invalid.
 	ErrInvalidLocation = errors.New("invalid location")
 
+	// ErrInvalidHeading is returned when a heading is outside 0-360 degrees.
+	ErrInvalidHeading = errors.New("invalid heading")
+
+	// ErrImplausibleSpeed is returned when a driver location update implies
+	// moving faster than the speed guard allows since the last one.
+	ErrImplausibleSpeed = errors.New("location implies implausible speed")
+
+	// ErrInvalidRecordedAt is returned when a location's recorded_at is not an RFC 3339 timestamp.
+	ErrInvalidRecordedAt = errors.New("invalid recorded_at")
+
 	// ErrRideAlreadyCancelled is returned when trying to cancel an already cancelled ride.
 	ErrRideAlreadyCancelled = errors.New("ride already cancelled")
 
 	// ErrRideCannotBeCancelled is returned when ride is in a state that cannot be cancelled.
 	ErrRideCannotBeCancelled = errors.New("ride cannot be cancelled in current state")
 
+	// ErrRideStillActive is returned when rebooking a ride that is not yet
+	// completed or cancelled.
+	ErrRideStillActive = errors.New("ride is still active")
+
 	// ErrTripInProgress is returned when trying to cancel a ride with an active trip.
 	ErrTripInProgress = errors.New("cannot cancel ride with trip in progress")
 
+	// ErrPaymentNotCashDue is returned when confirming cash for a payment not awaiting collection.
+	ErrPaymentNotCashDue = errors.New("payment not awaiting cash collection")
+
+	// ErrPaymentNotOwed is returned when reducing a payment that is no longer
+	// FAILED or CASH_DUE, e.g. because it was collected meanwhile.
+	ErrPaymentNotOwed = errors.New("payment not owed")
+
 	// ErrInvalidPaymentMethod is returned when payment method is invalid.
 	ErrInvalidPaymentMethod = errors.New("invalid payment method")
+
+	// ErrInvalidTier is returned when a tier is not in the catalog.
+	ErrInvalidTier = errors.New("invalid tier")
+
+	// ErrDriverTierUnchanged is returned when moving a driver to the tier
+	// they are already in.
+	ErrDriverTierUnchanged = errors.New("driver tier unchanged")
+
+	// ErrInvalidRideType is returned when a ride type is neither PASSENGER nor PACKAGE.
+	ErrInvalidRideType = domain.ErrInvalidRideType
+
+	// ErrInvalidCapability is returned for an unknown vehicle capability.
+	ErrInvalidCapability = domain.ErrInvalidCapability
+
+	// ErrPickupETANotApplicable is returned when the trip has already started.
+	ErrPickupETANotApplicable = errors.New("trip already started; pickup eta no longer applicable")
+
+	// ErrDriverLocationUnavailable is returned when the driver has no known location.
+	ErrDriverLocationUnavailable = errors.New("driver location unavailable")
+
+	// ErrNotificationStreamUnavailable is returned when live notifications are not configured.
+	ErrNotificationStreamUnavailable = errors.New("notification stream unavailable")
+
+	// ErrNotificationPreferencesUnavailable is returned when notification preferences are not configured.
+	ErrNotificationPreferencesUnavailable = errors.New("notification preferences unavailable")
+
+	// ErrDestinationModeUnavailable is returned when driver destination mode is not configured.
+	ErrDestinationModeUnavailable = errors.New("destination mode unavailable")
+
+	// ErrFareAdjustmentUnavailable is returned when fare adjustments have
+	// nowhere to be recorded.
+	ErrFareAdjustmentUnavailable = errors.New("fare adjustments unavailable")
+
+	// ErrTrackerKeysUnavailable is returned when no encryption key is
+	// configured to seal tracker secrets with.
+	ErrTrackerKeysUnavailable = errors.New("tracker keys unavailable")
+
+	// ErrTrackerSecretUnreadable is returned when a stored tracker secret
+	// cannot be decrypted, e.g. after the encryption key changed.
+	ErrTrackerSecretUnreadable = errors.New("tracker secret unreadable")
+
+	// ErrInvalidNotificationPreference is returned when a preference names an unknown type or channel.
+	ErrInvalidNotificationPreference = errors.New("invalid notification preference")
+
+	// ErrInvalidReportDate is returned when a report date is missing or malformed.
+	ErrInvalidReportDate = errors.New("invalid report date")
+
+	// ErrInvalidWeek is returned when a summary week is not a YYYY-MM-DD date.
+	ErrInvalidWeek = errors.New("invalid week: expected a YYYY-MM-DD date")
+
+	// ErrInvalidTrackWindow is returned when a location track window is malformed or inverted.
+	ErrInvalidTrackWindow = errors.New("invalid track window")
+
+	// ErrInvalidBoundingBox is returned when a map bounding box is missing, malformed or inverted.
+	ErrInvalidBoundingBox = errors.New("invalid bounding box")
+
+	// ErrInvalidExportRange is returned when an export date range is missing, malformed or inverted.
+	ErrInvalidExportRange = errors.New("invalid export date range")
+
+	// ErrUnsupportedExportFormat is returned when an export format other than CSV is requested.
+	ErrUnsupportedExportFormat = errors.New("unsupported export format")
+
+	// ErrExportTooLarge is returned when an export range holds more rows than the configured cap.
+	ErrExportTooLarge = errors.New("export exceeds the row limit")
+
+	// ErrFieldRequired is returned for a required request field left empty.
+	ErrFieldRequired = errors.New("required")
+
+	// ErrDriverAlreadyRegistered is returned when a driver registers with a phone already on file.
+	ErrDriverAlreadyRegistered = errors.New("driver already registered")
+
+	// ErrEmptyImport is returned when a driver import holds no rows.
+	ErrEmptyImport = errors.New("import has no rows")
+
+	// ErrImportTooLarge is returned when a driver import holds more rows than the configured cap.
+	ErrImportTooLarge = errors.New("import exceeds the row limit")
+
+	// ErrInvalidCampaignName is returned when a campaign has no name.
+	ErrInvalidCampaignName = domain.ErrInvalidCampaignName
+
+	// ErrInvalidCampaignCriteria is returned when a campaign criteria is unknown.
+	ErrInvalidCampaignCriteria = domain.ErrInvalidCampaignCriteria
+
+	// ErrInvalidCampaignTarget is returned when a campaign target is not positive.
+	ErrInvalidCampaignTarget = domain.ErrInvalidCampaignTarget
+
+	// ErrInvalidCampaignReward is returned when a campaign reward is not positive.
+	ErrInvalidCampaignReward = domain.ErrInvalidCampaignReward
+
+	// ErrInvalidCampaignTier is returned when a campaign tier is unknown.
+	ErrInvalidCampaignTier = domain.ErrInvalidCampaignTier
+
+	// ErrInvalidCampaignWindow is returned when a campaign window is missing or inverted.
+	ErrInvalidCampaignWindow = domain.ErrInvalidCampaignWindow
+
+	// ErrInvalidFeatureFlagName is returned when a feature flag has no name.
+	ErrInvalidFeatureFlagName = domain.ErrInvalidFeatureFlagName
+
+	// ErrInvalidFeatureFlagPercentage is returned when a rollout percentage is outside 0-100.
+	ErrInvalidFeatureFlagPercentage = domain.ErrInvalidFeatureFlagPercentage
+
+	// ErrInvalidEmail is returned when an email address is malformed.
+	ErrInvalidEmail = domain.ErrInvalidEmail
+
+	// ErrEmailTaken is returned when an email address belongs to another user.
+	ErrEmailTaken = errors.New("email already in use")
+
+	// ErrEmailAlreadyVerified is returned when the user has already verified the address.
+	ErrEmailAlreadyVerified = errors.New("email already verified")
+
+	// ErrEmailResendTooSoon is returned when a verification email was sent too recently.
+	ErrEmailResendTooSoon = errors.New("verification email sent too recently")
+
+	// ErrInvalidVerificationToken is returned when a token is unknown, used or expired.
+	ErrInvalidVerificationToken = errors.New("invalid or expired verification token")
+
+	// ErrInvalidQuote is returned when a ride quote is tampered with, expired,
+	// already used or quoted for other coordinates.
+	ErrInvalidQuote = errors.New("invalid or expired quote")
+
+	// ErrInvalidPhone is returned when a phone number is not a valid number.
+	ErrInvalidPhone = errors.New("invalid phone number")
+
+	// ErrInvalidPaymentInstrument is returned when an instrument is for cash or
+	// an unknown type, or lacks its token or masked details.
+	ErrInvalidPaymentInstrument = domain.ErrInvalidPaymentInstrument
+
+	// ErrNoPaymentInstrument is returned when a non-CASH ride is requested
+	// without a matching instrument on file.
+	ErrNoPaymentInstrument = errors.New("no payment instrument on file for this payment method")
+
+	// ErrPSPTransient is wrapped by PSP implementations for errors where the
+	// charge was certainly not made, e.g. a refused connection, so it is
+	// safe to retry.
+	ErrPSPTransient = errors.New("transient payment provider error")
+
+	// ErrPSPUnavailable is returned without calling the PSP while its circuit
+	// breaker is open after repeated failures.
+	ErrPSPUnavailable = errors.New("payment provider unavailable")
+
+	// ErrPaymentAuthorizationDeclined is returned when starting a CARD
+	// ride's trip and the PSP refuses to hold the estimated fare on the card.
+	ErrPaymentAuthorizationDeclined = errors.New("payment authorization declined: update the card before starting the trip")
+
+	// ErrProofOfDeliveryRequired is returned when a PACKAGE ride's trip is
+	// ended before the driver attached a photo.
+	ErrProofOfDeliveryRequired = errors.New("proof of delivery required: attach a photo before ending the trip")
+
+	// ErrAttachmentNotAllowed is returned when a photo is attached to the
+	// trip of a ride that is not a PACKAGE ride.
+	ErrAttachmentNotAllowed = errors.New("attachments are only accepted on package rides")
+
+	// ErrInvalidAttachment is returned when an upload has no file or an empty one.
+	ErrInvalidAttachment = errors.New("invalid attachment")
+
+	// ErrAttachmentTooLarge is returned when an upload exceeds ATTACHMENT_MAX_BYTES.
+	ErrAttachmentTooLarge = errors.New("attachment too large")
+
+	// ErrUnsupportedAttachmentType is returned when an upload is not a JPEG,
+	// PNG or WebP image.
+	ErrUnsupportedAttachmentType = errors.New("unsupported attachment type")
+
+	// ErrInvalidAttachmentURL is returned when an attachment URL's signature
+	// does not match or it has expired.
+	ErrInvalidAttachmentURL = errors.New("invalid or expired attachment url")
+
+	// ErrTripNotEnded is returned when asking for the earnings on a trip
+	// that is still in progress.
+	ErrTripNotEnded = errors.New("trip has not ended")
+
+	// ErrInvalidFareSplit is returned when a fare split has fewer than two
+	// riders, repeats or leaves out the ride's rider, or mixes weighted and
+	// equal shares.
+	ErrInvalidFareSplit = domain.ErrInvalidFareSplit
+
+	// ErrFareSplitNotAllowed is returned when splitting the fare of a CASH
+	// ride, which the driver collects from one rider.
+	ErrFareSplitNotAllowed = errors.New("only fares paid by card, wallet or UPI can be split")
 )
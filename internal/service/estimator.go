@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/repository"
+)
+
+const (
+	defaultEstimatorRadiusKm = 0.5                 // Used when the configured radius is not positive
+	defaultEstimatorLookback = 30 * 24 * time.Hour // Used when the configured lookback is not positive
+	defaultEstimatorMinTrips = 5                   // Used when the configured minimum is not positive
+	defaultEstimatorMaxTrips = 50                  // Used when the configured maximum is below the minimum
+)
+
+// EstimatorService estimates how long a trip between two points will take
+// from recent trips on a similar route: ones whose pickup and destination
+// were each within a radius of the route's. With too few of those it falls
+// back to the straight-line distance at the average city speed.
+type EstimatorService struct {
+	tripRepo repository.TripRepository
+	radiusKm float64
+	lookback time.Duration
+	minTrips int
+	maxTrips int
+	now      func() time.Time
+}
+
+// NewEstimatorService creates a new EstimatorService. A nil now means
+// time.Now.
+func NewEstimatorService(tripRepo repository.TripRepository, radiusKm float64, lookback time.Duration, minTrips, maxTrips int, now func() time.Time) *EstimatorService {
+	if radiusKm <= 0 {
+		radiusKm = defaultEstimatorRadiusKm
+	}
+	if lookback <= 0 {
+		lookback = defaultEstimatorLookback
+	}
+	if minTrips <= 0 {
+		minTrips = defaultEstimatorMinTrips
+	}
+	if maxTrips < minTrips {
+		maxTrips = max(defaultEstimatorMaxTrips, minTrips)
+	}
+	if now == nil {
+		now = time.Now
+	}
+	return &EstimatorService{
+		tripRepo: tripRepo,
+		radiusKm: radiusKm,
+		lookback: lookback,
+		minTrips: minTrips,
+		maxTrips: maxTrips,
+		now:      now,
+	}
+}
+
+// DurationEstimate is an estimated trip duration.
+type DurationEstimate struct {
+	Duration time.Duration
+	Trips    int // Past trips whose median it is; 0 when from the average speed
+}
+
+// EstimateDuration estimates the driving time from pickup to destination:
+// the median of the matching recent trips, or the average-speed estimate
+// when there are fewer than the minimum or they cannot be read. A nil
+// service always uses the average speed.
+func (s *EstimatorService) EstimateDuration(ctx context.Context, pickupLat, pickupLng, destinationLat, destinationLng float64) DurationEstimate {
+	fallback := DurationEstimate{Duration: averageSpeedDuration(pickupLat, pickupLng, destinationLat, destinationLng)}
+	if s == nil {
+		return fallback
+	}
+
+	durations, err := s.tripRepo.CompletedDurations(ctx,
+		domain.BoxAround(pickupLat, pickupLng, s.radiusKm),
+		domain.BoxAround(destinationLat, destinationLng, s.radiusKm),
+		s.now().Add(-s.lookback), s.maxTrips)
+	if err != nil {
+		log.Printf("[ESTIMATE] Failed to read past trip durations: %v", err)
+		return fallback
+	}
+	if len(durations) < s.minTrips {
+		return fallback
+	}
+	return DurationEstimate{Duration: medianDuration(durations), Trips: len(durations)}
+}
+
+// averageSpeedDuration is the straight-line distance at the average city
+// speed.
+func averageSpeedDuration(pickupLat, pickupLng, destinationLat, destinationLng float64) time.Duration {
+	distanceKm := domain.HaversineKm(pickupLat, pickupLng, destinationLat, destinationLng)
+	return time.Duration(distanceKm / averageCitySpeedKmh * float64(time.Hour))
+}
+
+// medianDuration returns the median of a non-empty slice, sorting it.
+func medianDuration(durations []time.Duration) time.Duration {
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	mid := len(durations) / 2
+	if len(durations)%2 == 1 {
+		return durations[mid]
+	}
+	return (durations[mid-1] + durations[mid]) / 2
+}
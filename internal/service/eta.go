@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/redis"
+	"ride/internal/repository"
+)
+
+// averageCitySpeedKmh is the assumed city driving speed used for pickup ETAs
+// and trip fare estimates. In production, use route durations from a Maps API.
+const averageCitySpeedKmh = 25.0
+
+// frozenTripETATTL is how long a paused trip's remaining distance is kept;
+// a pause outlasting it is frozen again from the driver's location.
+const frozenTripETATTL = 24 * time.Hour
+
+// ETAService computes live driver-to-pickup and trip-to-destination ETAs.
+type ETAService struct {
+	rideRepo            repository.RideRepository
+	locationStore       redis.LocationStoreInterface
+	notificationService *NotificationService
+	tripETAs            redis.TripETAStoreInterface // Optional: nil leaves paused trips' ETAs live
+	tripSpeedKmh        float64                     // Average speed the ETA to the destination assumes
+}
+
+// NewETAService creates a new ETAService.
+func NewETAService(
+	rideRepo repository.RideRepository,
+	locationStore redis.LocationStoreInterface,
+	notificationService *NotificationService,
+	tripETAs redis.TripETAStoreInterface,
+	tripSpeedKmh float64,
+) *ETAService {
+	if tripSpeedKmh <= 0 {
+		tripSpeedKmh = averageCitySpeedKmh
+	}
+
+	return &ETAService{
+		rideRepo:            rideRepo,
+		locationStore:       locationStore,
+		notificationService: notificationService,
+		tripETAs:            tripETAs,
+		tripSpeedKmh:        tripSpeedKmh,
+	}
+}
+
+// DriverETA is the assigned driver's current distance and ETA to pickup.
+type DriverETA struct {
+	RideID     string
+	DriverID   string
+	DriverLat  float64
+	DriverLng  float64
+	DistanceKm float64
+	ETASeconds int
+}
+
+// ETAMinutes returns the ETA rounded up to whole minutes.
+func (e *DriverETA) ETAMinutes() int {
+	return int(math.Ceil(float64(e.ETASeconds) / 60))
+}
+
+// DriverETA recomputes the pickup ETA from the driver's latest location.
+// If notify is set, the update is also pushed to the rider.
+func (s *ETAService) DriverETA(ctx context.Context, rideID string, notify bool) (*DriverETA, error) {
+	if rideID == "" {
+		return nil, ErrInvalidRideID
+	}
+
+	ride, err := s.rideRepo.GetByID(ctx, rideID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch ride.Status {
+	case domain.RideStatusAssigned:
+	case domain.RideStatusInTrip, domain.RideStatusCompleted:
+		return nil, ErrPickupETANotApplicable
+	default:
+		return nil, ErrRideNotAssigned
+	}
+
+	loc, err := s.locationStore.GetLocation(ctx, ride.AssignedDriverID)
+	if err != nil {
+		return nil, err
+	}
+	if loc == nil {
+		return nil, ErrDriverLocationUnavailable
+	}
+
+	distanceKm := domain.HaversineKm(loc.Lat, loc.Lng, ride.PickupLat, ride.PickupLng)
+	eta := &DriverETA{
+		RideID:     ride.ID,
+		DriverID:   ride.AssignedDriverID,
+		DriverLat:  loc.Lat,
+		DriverLng:  loc.Lng,
+		DistanceKm: math.Round(distanceKm*100) / 100,
+		ETASeconds: int(math.Ceil(distanceKm / averageCitySpeedKmh * 3600)),
+	}
+
+	if notify && s.notificationService != nil {
+		_ = s.notificationService.NotifyDriverETA(ctx, ride, eta)
+	}
+
+	return eta, nil
+}
+
+// DriverDistanceKm returns how far the ride's driver currently is from where
+// they are heading: the pickup point while the ride is ASSIGNED, the
+// destination once it is IN_TRIP. It returns ErrRideNotAssigned for rides in
+// other statuses and ErrDriverLocationUnavailable when the driver's position
+// is unknown.
+func (s *ETAService) DriverDistanceKm(ctx context.Context, ride *domain.Ride) (float64, error) {
+	var lat, lng float64
+	switch ride.Status {
+	case domain.RideStatusAssigned:
+		lat, lng = ride.PickupLat, ride.PickupLng
+	case domain.RideStatusInTrip:
+		lat, lng = ride.DestinationLat, ride.DestinationLng
+	default:
+		return 0, ErrRideNotAssigned
+	}
+
+	loc, err := s.locationStore.GetLocation(ctx, ride.AssignedDriverID)
+	if err != nil {
+		return 0, err
+	}
+	if loc == nil {
+		return 0, ErrDriverLocationUnavailable
+	}
+
+	return math.Round(domain.HaversineKm(loc.Lat, loc.Lng, lat, lng)*100) / 100, nil
+}
+
+// TripProgress is an in-progress trip's distance and ETA to its destination.
+type TripProgress struct {
+	TripID          string
+	RemainingKm     float64
+	ETASeconds      int
+	ProgressPercent int  // Share of the pickup-to-destination distance covered, 0-100
+	Paused          bool // The trip is PAUSED and the figures are frozen
+}
+
+// ETAMinutes returns the ETA rounded up to whole minutes.
+func (p *TripProgress) ETAMinutes() int {
+	return int(math.Ceil(float64(p.ETASeconds) / 60))
+}
+
+// TripProgress computes how far a STARTED or PAUSED trip's driver is from the
+// ride's destination, at the configured average speed. Progress is the
+// distance covered as a share of the straight line from pickup to
+// destination. While the trip is PAUSED the remaining distance is the one
+// first computed during the pause, so the ETA stands still until the trip
+// resumes. It returns nil for trips in other statuses and
+// ErrDriverLocationUnavailable when the driver has no recent location.
+func (s *ETAService) TripProgress(ctx context.Context, trip *domain.Trip) (*TripProgress, error) {
+	if trip.Status != domain.TripStatusStarted && trip.Status != domain.TripStatusPaused {
+		return nil, nil
+	}
+	paused := trip.Status == domain.TripStatusPaused
+
+	ride, err := s.rideRepo.GetByID(ctx, trip.RideID)
+	if err != nil {
+		return nil, err
+	}
+
+	remainingKm, frozen := 0.0, false
+	if paused && s.tripETAs != nil {
+		remainingKm, frozen, err = s.tripETAs.FrozenRemaining(ctx, trip.ID, trip.PausedAt)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !frozen {
+		loc, err := s.locationStore.GetLocation(ctx, trip.DriverID)
+		if err != nil {
+			return nil, err
+		}
+		if loc == nil {
+			return nil, ErrDriverLocationUnavailable
+		}
+		remainingKm = math.Round(domain.HaversineKm(loc.Lat, loc.Lng, ride.DestinationLat, ride.DestinationLng)*100) / 100
+
+		if paused && s.tripETAs != nil {
+			if err := s.tripETAs.FreezeRemaining(ctx, trip.ID, trip.PausedAt, remainingKm, frozenTripETATTL); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	progress := 100
+	if totalKm := domain.HaversineKm(ride.PickupLat, ride.PickupLng, ride.DestinationLat, ride.DestinationLng); totalKm > 0 {
+		covered := math.Max(0, math.Min(1, 1-remainingKm/totalKm))
+		progress = int(math.Round(covered * 100))
+	}
+
+	return &TripProgress{
+		TripID:          trip.ID,
+		RemainingKm:     remainingKm,
+		ETASeconds:      int(math.Ceil(remainingKm / s.tripSpeedKmh * 3600)),
+		ProgressPercent: progress,
+		Paused:          paused,
+	}, nil
+}
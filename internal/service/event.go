@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"ride/internal/domain"
+)
+
+// EventPublisher is the interface for publishing domain events to a message
+// bus such as Kafka or NATS.
+type EventPublisher interface {
+	Publish(ctx context.Context, event *domain.Event) error
+}
+
+// NoopEventPublisher is an EventPublisher that drops every event. Services
+// given no publisher use it.
+type NoopEventPublisher struct{}
+
+// Publish discards the event. Always succeeds.
+func (NoopEventPublisher) Publish(ctx context.Context, event *domain.Event) error {
+	return nil
+}
+
+// LogEventPublisher is an EventPublisher that writes events to the log
+// instead of a message bus.
+type LogEventPublisher struct{}
+
+// NewLogEventPublisher creates a new LogEventPublisher.
+func NewLogEventPublisher() *LogEventPublisher {
+	return &LogEventPublisher{}
+}
+
+// Publish logs the event. Always succeeds.
+func (p *LogEventPublisher) Publish(ctx context.Context, event *domain.Event) error {
+	data, _ := json.Marshal(event.Data)
+	log.Printf("[EVENT] type=%s id=%s aggregate=%s data=%s", event.Type, event.ID, event.AggregateID, data)
+	return nil
+}
+
+// publishEvent publishes an event about aggregateID. Publishing is
+// best-effort: failures are logged and never fail the transition, which has
+// already been committed.
+func publishEvent(ctx context.Context, publisher EventPublisher, eventType domain.EventType, aggregateID string, data map[string]any) {
+	event := &domain.Event{
+		ID:          uuid.New().String(),
+		Type:        eventType,
+		AggregateID: aggregateID,
+		OccurredAt:  time.Now(),
+		Data:        data,
+	}
+	if err := publisher.Publish(ctx, event); err != nil {
+		log.Printf("[EVENT] failed to publish %s for %s: %v", eventType, aggregateID, err)
+	}
+}
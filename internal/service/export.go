@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/repository"
+	"ride/internal/repository/postgres"
+)
+
+const defaultExportMaxRows = 100000 // Used when the configured row cap is not positive
+
+// ExportService streams trips and rides for admin exports. Rows are handed
+// to the caller one at a time as they are read, so exports never hold a
+// whole range in memory. Ranges with more rows than the cap are refused up
+// front rather than truncated. An export runs in its own transaction with
+// the statement timeout lifted, as streaming a large range to a slow client
+// can outlast it.
+type ExportService struct {
+	db       *sql.DB // Optional: nil reads through the repositories, under the statement timeout
+	tripRepo repository.TripRepository
+	rideRepo repository.RideRepository
+	maxRows  int
+}
+
+// NewExportService creates a new ExportService.
+func NewExportService(db *sql.DB, tripRepo repository.TripRepository, rideRepo repository.RideRepository, maxRows int) *ExportService {
+	if maxRows <= 0 {
+		maxRows = defaultExportMaxRows
+	}
+	return &ExportService{db: db, tripRepo: tripRepo, rideRepo: rideRepo, maxRows: maxRows}
+}
+
+// ExportTrips calls fn for each trip started between the UTC days from and
+// to, inclusive, oldest first. It returns ErrExportTooLarge before calling
+// fn if the range holds more rows than the cap.
+func (s *ExportService) ExportTrips(ctx context.Context, from, to time.Time, fn func(*domain.Trip) error) error {
+	start, end, err := exportWindow(from, to)
+	if err != nil {
+		return err
+	}
+
+	return s.withoutStatementTimeout(ctx, func(tripRepo repository.TripRepository, _ repository.RideRepository) error {
+		count, err := tripRepo.CountInRange(ctx, start, end)
+		if err != nil {
+			return err
+		}
+		if err := s.checkRowCap(count); err != nil {
+			return err
+		}
+
+		rows := 0
+		return tripRepo.ListIterator(ctx, start, end, func(trip *domain.Trip) error {
+			// Trips started after the count can push the range over the cap.
+			if rows++; rows > s.maxRows {
+				return s.checkRowCap(rows)
+			}
+			return fn(trip)
+		})
+	})
+}
+
+// ExportRides calls fn for each ride created between the UTC days from and
+// to, inclusive, oldest first. It returns ErrExportTooLarge before calling
+// fn if the range holds more rows than the cap.
+func (s *ExportService) ExportRides(ctx context.Context, from, to time.Time, fn func(*domain.Ride) error) error {
+	start, end, err := exportWindow(from, to)
+	if err != nil {
+		return err
+	}
+
+	return s.withoutStatementTimeout(ctx, func(_ repository.TripRepository, rideRepo repository.RideRepository) error {
+		count, err := rideRepo.CountInRange(ctx, start, end)
+		if err != nil {
+			return err
+		}
+		if err := s.checkRowCap(count); err != nil {
+			return err
+		}
+
+		rows := 0
+		return rideRepo.ListIterator(ctx, start, end, func(ride *domain.Ride) error {
+			if rows++; rows > s.maxRows {
+				return s.checkRowCap(rows)
+			}
+			return fn(ride)
+		})
+	})
+}
+
+// withoutStatementTimeout runs fn with repositories reading in a transaction
+// exempt from the statement timeout. Without a db, fn gets the service's
+// repositories.
+func (s *ExportService) withoutStatementTimeout(ctx context.Context, fn func(repository.TripRepository, repository.RideRepository) error) (err error) {
+	if s.db == nil {
+		return fn(s.tripRepo, s.rideRepo)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if err = postgres.LiftStatementTimeout(ctx, tx); err != nil {
+		return err
+	}
+	if err = fn(postgres.NewTripRepositoryWithTx(tx), postgres.NewRideRepositoryWithTx(tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// checkRowCap returns ErrExportTooLarge, with the counts, if rows exceeds the cap.
+func (s *ExportService) checkRowCap(rows int) error {
+	if rows > s.maxRows {
+		return fmt.Errorf("%w: %d rows, limit is %d; narrow the date range", ErrExportTooLarge, rows, s.maxRows)
+	}
+	return nil
+}
+
+// exportWindow converts an inclusive range of UTC days into a half-open
+// [start, end) window.
+func exportWindow(from, to time.Time) (time.Time, time.Time, error) {
+	if from.IsZero() || to.IsZero() {
+		return time.Time{}, time.Time{}, ErrInvalidExportRange
+	}
+
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	end := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+	if !end.After(start) {
+		return time.Time{}, time.Time{}, ErrInvalidExportRange
+	}
+	return start, end, nil
+}
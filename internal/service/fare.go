@@ -0,0 +1,97 @@
+package service
+
+import (
+	"time"
+
+	"ride/internal/domain"
+)
+
+// Metered fare: a flag fall plus a rate per minute of riding.
+const (
+	meteredFlagFall  = 2.0
+	meteredPerMinute = 0.5
+)
+
+// A ride's estimated fare range is priced for trips this much shorter and
+// longer than its estimated duration, to allow for traffic and the route
+// taken. Calibrate them against the estimate-accuracy report.
+const (
+	estimateLowFactor  = 0.85
+	estimateHighFactor = 1.25
+)
+
+// FareSchedule prices the time-based part of a trip for each tier. Trip
+// fares, estimates and receipts all price through it, so they agree.
+type FareSchedule struct {
+	catalog *domain.Catalog
+	minFare float64 // Floor for tiers without their own, before the tier's multiplier
+}
+
+// NewFareSchedule creates a FareSchedule. A nil catalog means the built-in
+// one, and a minFare that is not positive means defaultMinFare.
+func NewFareSchedule(catalog *domain.Catalog, minFare float64) *FareSchedule {
+	if catalog == nil {
+		catalog = domain.DefaultCatalog()
+	}
+	if minFare <= 0 {
+		minFare = defaultMinFare
+	}
+	return &FareSchedule{catalog: catalog, minFare: minFare}
+}
+
+// tier returns the tier's spec. Rides without a tier, and tiers the catalog
+// no longer offers, get an empty spec.
+func (f *FareSchedule) tier(tier domain.DriverTier) domain.TierSpec {
+	if tier == "" {
+		return domain.TierSpec{}
+	}
+	spec, _ := f.catalog.Tier(string(tier))
+	return spec
+}
+
+// Multiplier returns the tier's fare multiplier, or 1.0 for rides without a
+// tier and tiers without a multiplier.
+func (f *FareSchedule) Multiplier(tier domain.DriverTier) float64 {
+	if m := f.tier(tier).FareMultiplier; m > 0 {
+		return m
+	}
+	return 1.0
+}
+
+// MinFare returns the floor on the tier's fare before surge: the tier's
+// own minimum, or else the schedule's scaled by the tier's multiplier.
+func (f *FareSchedule) MinFare(tier domain.DriverTier) float64 {
+	if m := f.tier(tier).MinFare; m > 0 {
+		return m
+	}
+	return f.minFare * f.Multiplier(tier)
+}
+
+// BaseFare returns the fare for riding for duration in the tier, before
+// surge and surcharges: the metered fare times the tier's multiplier, but
+// no less than the tier's minimum.
+func (f *FareSchedule) BaseFare(tier domain.DriverTier, duration time.Duration) float64 {
+	fare := (meteredFlagFall + duration.Minutes()*meteredPerMinute) * f.Multiplier(tier)
+	if floor := f.MinFare(tier); fare < floor {
+		return floor
+	}
+	return fare
+}
+
+// Fare returns a trip's fare: the base fare for riding for duration in the
+// tier, with surge applied, plus the ride's zone surcharge. A multiplier
+// below 1.0 means no surge.
+func (f *FareSchedule) Fare(tier domain.DriverTier, duration time.Duration, surgeMultiplier, surcharge float64) float64 {
+	if surgeMultiplier < 1.0 {
+		surgeMultiplier = 1.0
+	}
+	return f.BaseFare(tier, duration)*surgeMultiplier + surcharge
+}
+
+// EstimateRange returns the fare range to quote for a ride expected to take
+// duration, priced like its fare will be, rounded to the cent.
+func (f *FareSchedule) EstimateRange(tier domain.DriverTier, duration time.Duration, surgeMultiplier, surcharge float64) (low, high float64) {
+	low = f.Fare(tier, time.Duration(float64(duration)*estimateLowFactor), surgeMultiplier, surcharge)
+	high = f.Fare(tier, time.Duration(float64(duration)*estimateHighFactor), surgeMultiplier, surcharge)
+	return roundCents(low), roundCents(high)
+}
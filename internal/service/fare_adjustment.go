@@ -0,0 +1,371 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"ride/internal/domain"
+	"ride/internal/repository/postgres"
+)
+
+// AdjustFareRequest contains the parameters for correcting an ended trip's
+// fare.
+type AdjustFareRequest struct {
+	TripID string
+	Fare   float64 // The corrected fare
+	Reason string  // Recorded with the adjustment and shown on the receipt
+}
+
+// AdjustFareResponse contains the result of a fare adjustment.
+type AdjustFareResponse struct {
+	Trip       *domain.Trip
+	Adjustment *domain.FareAdjustment
+	Payment    *domain.Payment // The extra charge, the refund or the reduced fare payment; nil if none could be made
+	Receipt    *domain.Receipt
+}
+
+// AdjustFare corrects the fare of an ENDED trip, e.g. after a rider disputes
+// a wrong route. The ride's rider is charged the difference on the ride's
+// payment method if the fare went up. If it went down the difference is
+// refunded, split between the riders of a split fare; a fare not yet
+// collected is reduced rather than refunded. The adjustment is recorded with its reason, with the new fare and
+// before any money moves, and the rider gets a fresh receipt. A trip held for
+// review is approved instead.
+func (s *TripService) AdjustFare(ctx context.Context, req AdjustFareRequest) (*AdjustFareResponse, error) {
+	if req.TripID == "" {
+		return nil, ErrInvalidTripID
+	}
+
+	if req.Fare <= 0 {
+		return nil, ErrInvalidFare
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, ErrAdjustmentReasonRequired
+	}
+
+	if s.fareAdjustments == nil {
+		return nil, ErrFareAdjustmentUnavailable
+	}
+
+	trip, err := s.tripRepo.GetByID(ctx, req.TripID)
+	if err != nil {
+		return nil, err
+	}
+
+	if trip.Status != domain.TripStatusEnded {
+		return nil, ErrTripNotEnded
+	}
+	if trip.NeedsReview {
+		return nil, ErrTripUnderReview
+	}
+	if trip.PaymentHeld() {
+		return nil, ErrTripPaymentOnHold
+	}
+
+	adjustment := &domain.FareAdjustment{
+		ID:           uuid.New().String(),
+		TripID:       trip.ID,
+		PreviousFare: trip.Fare,
+		Fare:         roundCents(req.Fare),
+		Reason:       reason,
+	}
+	if adjustment.Difference() == 0 {
+		return nil, ErrFareUnchanged
+	}
+
+	ride, err := s.rideRepo.GetByID(ctx, trip.RideID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The versioned update lets only one concurrent adjustment through.
+	trip.Fare = adjustment.Fare
+
+	if err := trip.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.recordAdjustment(ctx, trip, adjustment); err != nil {
+		return nil, err
+	}
+
+	// The fare has changed; from here on failures are logged rather than
+	// returned, as when an ended trip's fare is settled.
+	ctx = context.WithoutCancel(ctx)
+
+	payment := s.settleAdjustment(ctx, trip, ride, adjustment)
+	if payment != nil {
+		adjustment.PaymentID = payment.ID
+		if err := s.fareAdjustments.SetPaymentID(ctx, adjustment.ID, payment.ID); err != nil {
+			log.Printf("[FARE] Failed to record payment %s against adjustment %s of trip %s: %v",
+				payment.ID, adjustment.ID, trip.ID, err)
+		}
+	}
+
+	var receipt *domain.Receipt
+	if s.receiptService != nil {
+		farePayment, err := s.paymentService.FarePayment(ctx, trip.ID)
+		if err != nil {
+			log.Printf("[FARE] Failed to load the fare payment of trip %s for its receipt: %v", trip.ID, err)
+		}
+		receipt, _ = s.receiptService.GenerateReceipt(ctx, GenerateReceiptRequest{
+			Trip:       trip,
+			Ride:       ride,
+			Payment:    farePayment,
+			Adjustment: adjustment,
+		})
+	}
+
+	return &AdjustFareResponse{
+		Trip:       trip,
+		Adjustment: adjustment,
+		Payment:    payment,
+		Receipt:    receipt,
+	}, nil
+}
+
+// recordAdjustment stores the corrected fare and the adjustment's audit row
+// in one transaction, so no money moves for an adjustment left unrecorded.
+func (s *TripService) recordAdjustment(ctx context.Context, trip *domain.Trip, adjustment *domain.FareAdjustment) (err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if err = postgres.NewTripRepositoryWithTx(tx).Update(ctx, trip); err != nil {
+		return err
+	}
+
+	if err = postgres.NewFareAdjustmentRepositoryWithTx(tx).Create(ctx, adjustment); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// settleAdjustment charges or refunds the difference an adjustment makes,
+// under a key of its own so it never collides with the fare payment. Only a
+// collected fare is refunded; a fare still owed is reduced instead. It
+// returns nil if the money could not be moved at all.
+func (s *TripService) settleAdjustment(ctx context.Context, trip *domain.Trip, ride *domain.Ride, adjustment *domain.FareAdjustment) *domain.Payment {
+	key := "adjustment:" + adjustment.ID
+	difference := adjustment.Difference()
+
+	var payment *domain.Payment
+	var err error
+	if difference > 0 {
+		payment, err = s.paymentService.ProcessPayment(ctx, ProcessPaymentRequest{
+			TripID: adjustment.TripID,
+			Amount: difference,
+			Method: ride.PaymentMethod,
+
+			InstrumentID:   ride.InstrumentID,
+			IdempotencyKey: key,
+		})
+	} else {
+		payment, err = s.returnDifference(ctx, trip, ride, adjustment, -difference, key)
+	}
+	if err != nil {
+		log.Printf("[FARE] Failed to settle adjustment %s of trip %s (%+.2f): %v", adjustment.ID, adjustment.TripID, difference, err)
+		return nil
+	}
+
+	if s.notificationService != nil && difference > 0 {
+		if payment.Status == domain.PaymentStatusSuccess {
+			_ = s.notificationService.NotifyPaymentSuccess(ctx, payment, ride.RiderID)
+		} else if payment.Status == domain.PaymentStatusFailed {
+			_ = s.notificationService.NotifyPaymentFailed(ctx, payment, ride.RiderID)
+		}
+	}
+
+	return payment
+}
+
+// returnDifference gives back amount of a trip's fare: refunded if the fare
+// was collected, taken off what is owed if it failed or is cash still due. A
+// fare collected while it was being reduced is refunded after all. A split
+// fare's riders each get back their part of amount, in proportion to the
+// split, from the payment that collected their share; the owner's part
+// includes the shares they absorbed. No payment is refunded more than it
+// collected, less earlier refunds; whatever another rider's payment cannot
+// cover is returned to the owner, who paid any extra charges. It returns the
+// owner's payment, or another rider's refund if the owner had no part.
+func (s *TripService) returnDifference(ctx context.Context, trip *domain.Trip, ride *domain.Ride, adjustment *domain.FareAdjustment, amount float64, key string) (*domain.Payment, error) {
+	split := s.fareSplit(ctx, trip)
+	collected, err := s.collectedByRider(ctx, trip.ID, ride.RiderID, split, adjustment.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var shareRefund *domain.Payment
+	if split != nil {
+		weights := make([]float64, len(split.Shares))
+		for i, share := range split.Shares {
+			weights[i] = share.Weight
+		}
+		ownerPart := 0.0
+		for i, part := range domain.SplitAmount(amount, weights) {
+			share := split.Shares[i]
+			if share.RiderID == split.OwnerID || share.Absorbed || share.PaymentID == "" {
+				ownerPart = roundCents(ownerPart + part)
+				continue
+			}
+			refund := math.Min(part, collected[share.RiderID])
+			ownerPart = roundCents(ownerPart + part - refund)
+			if refund <= 0 {
+				continue
+			}
+			payment, err := s.refundShare(ctx, trip.ID, ride, share, refund, key)
+			if err != nil {
+				log.Printf("[FARE] Failed to refund %s's part %.2f of adjustment %s of trip %s: %v",
+					share.RiderID, refund, adjustment.ID, trip.ID, err)
+				continue
+			}
+			if shareRefund == nil {
+				shareRefund = payment
+			}
+		}
+		if ownerPart <= 0 {
+			return shareRefund, nil
+		}
+		amount = ownerPart
+	}
+
+	fare, err := s.paymentService.FarePayment(ctx, trip.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if fare != nil && fare.Status != domain.PaymentStatusSuccess {
+		reduced, err := s.paymentService.ReduceOwed(ctx, fare, amount, ride.PaymentMethod)
+		if !errors.Is(err, ErrPaymentNotOwed) {
+			return reduced, err
+		}
+		if fare, err = s.paymentService.FarePayment(ctx, trip.ID); err != nil {
+			return nil, err
+		}
+		if fare != nil && fare.Status == domain.PaymentStatusSuccess {
+			collected[ride.RiderID] = roundCents(collected[ride.RiderID] + fare.Amount)
+		}
+	}
+
+	if fare == nil || fare.Status != domain.PaymentStatusSuccess {
+		return nil, ErrPaymentNotOwed
+	}
+
+	amount = math.Min(amount, collected[ride.RiderID])
+	if amount <= 0 {
+		return nil, ErrPaymentNotOwed
+	}
+
+	return s.paymentService.Refund(ctx, RefundRequest{
+		TripID: trip.ID,
+		Amount: amount,
+		Method: ride.PaymentMethod,
+
+		InstrumentID:   ride.InstrumentID,
+		IdempotencyKey: key,
+	})
+}
+
+// refundShare refunds amount of a split fare to a rider other than the owner,
+// on the instrument their share was charged to.
+func (s *TripService) refundShare(ctx context.Context, tripID string, ride *domain.Ride, share domain.FareShare, amount float64, key string) (*domain.Payment, error) {
+	payment, err := s.paymentService.GetPayment(ctx, share.PaymentID)
+	if err != nil {
+		return nil, err
+	}
+	return s.paymentService.Refund(ctx, RefundRequest{
+		TripID: tripID,
+		Amount: amount,
+		Method: ride.PaymentMethod,
+
+		InstrumentID:   payment.InstrumentID,
+		IdempotencyKey: shareRefundKey(key, share.RiderID),
+	})
+}
+
+// collectedByRider returns how much of a trip's fare each rider has paid and
+// not had back: the owner's fare payment and the extra charges of earlier
+// adjustments, each other rider's share payment, less what earlier
+// adjustments refunded them. The adjustment being settled is left out.
+func (s *TripService) collectedByRider(ctx context.Context, tripID, ownerID string, split *domain.FareSplit, adjustmentID string) (map[string]float64, error) {
+	collected := make(map[string]float64)
+	add := func(riderID string, payment *domain.Payment, status domain.PaymentStatus, sign float64) {
+		if payment != nil && payment.Status == status {
+			collected[riderID] = roundCents(collected[riderID] + sign*payment.Amount)
+		}
+	}
+
+	fare, err := s.paymentService.FarePayment(ctx, tripID)
+	if err != nil {
+		return nil, err
+	}
+	add(ownerID, fare, domain.PaymentStatusSuccess, 1)
+
+	var others []string
+	if split != nil {
+		for _, share := range split.Shares {
+			if share.RiderID == split.OwnerID || share.Absorbed || share.PaymentID == "" {
+				continue
+			}
+			payment, err := s.paymentService.GetPayment(ctx, share.PaymentID)
+			if err != nil {
+				return nil, err
+			}
+			add(share.RiderID, payment, domain.PaymentStatusSuccess, 1)
+			others = append(others, share.RiderID)
+		}
+	}
+
+	earlier, err := s.fareAdjustments.ListByTripID(ctx, tripID)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range earlier {
+		if a.ID == adjustmentID {
+			continue
+		}
+		key := "adjustment:" + a.ID
+		if a.Difference() > 0 {
+			payment, err := s.paymentService.PaymentByKey(ctx, tripID, key)
+			if err != nil {
+				return nil, err
+			}
+			add(ownerID, payment, domain.PaymentStatusSuccess, 1)
+			continue
+		}
+		payment, err := s.paymentService.PaymentByKey(ctx, tripID, key)
+		if err != nil {
+			return nil, err
+		}
+		add(ownerID, payment, domain.PaymentStatusRefunded, -1)
+		for _, riderID := range others {
+			payment, err := s.paymentService.PaymentByKey(ctx, tripID, shareRefundKey(key, riderID))
+			if err != nil {
+				return nil, err
+			}
+			add(riderID, payment, domain.PaymentStatusRefunded, -1)
+		}
+	}
+	return collected, nil
+}
+
+// shareRefundKey is the client idempotency key of a rider's part of a split
+// fare's refund, other than the owner's, which uses the adjustment's key.
+func shareRefundKey(key, riderID string) string {
+	return key + ":" + riderID
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	"ride/internal/domain"
+	"ride/internal/repository"
+)
+
+// SplitParticipant is one rider in a fare split request.
+type SplitParticipant struct {
+	RiderID string
+	Share   float64 // Relative weight; leave 0 on every rider to split equally
+}
+
+// SplitFareRequest contains the parameters for splitting a trip's fare.
+type SplitFareRequest struct {
+	TripID       string
+	RiderID      string // The caller, who must be the ride's rider
+	Participants []SplitParticipant
+}
+
+// SplitFare divides a trip's fare between the riders travelling together,
+// including the ride's rider, who owns the split. It may be set, and
+// replaced, until the trip ends; when it does, each rider is charged their
+// share. A rider who is not the ride's rider gets repository.ErrNotFound.
+func (s *TripService) SplitFare(ctx context.Context, req SplitFareRequest) (*domain.FareSplit, error) {
+	if req.TripID == "" {
+		return nil, ErrInvalidTripID
+	}
+
+	trip, err := s.tripRepo.GetByID(ctx, req.TripID)
+	if err != nil {
+		return nil, err
+	}
+	ride, err := s.rideRepo.GetByID(ctx, trip.RideID)
+	if err != nil {
+		return nil, err
+	}
+	if req.RiderID == "" || ride.RiderID != req.RiderID {
+		return nil, repository.ErrNotFound
+	}
+
+	if trip.Status != domain.TripStatusStarted && trip.Status != domain.TripStatusPaused {
+		return nil, ErrTripAlreadyEnded
+	}
+	if s.fareSplits == nil || ride.PaymentMethod == domain.PaymentMethodCash {
+		return nil, ErrFareSplitNotAllowed
+	}
+
+	split := &domain.FareSplit{
+		TripID:  trip.ID,
+		OwnerID: ride.RiderID,
+		Shares:  make([]domain.FareShare, len(req.Participants)),
+	}
+	for i, p := range req.Participants {
+		split.Shares[i] = domain.FareShare{RiderID: p.RiderID, Weight: p.Share}
+	}
+	if err := split.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Everyone else pays with their default instrument for the ride's
+	// method, so they need one on file.
+	for _, share := range split.Shares {
+		if share.RiderID == split.OwnerID {
+			continue
+		}
+		if _, err := s.shareInstrument(ctx, share.RiderID, ride.PaymentMethod); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.fareSplits.Save(ctx, split); err != nil {
+		return nil, err
+	}
+	return split, nil
+}
+
+// shareInstrument returns the instrument a rider's share is charged to.
+// Returns ErrNoPaymentInstrument if they have none for method; without an
+// instrument store every share is charged with no instrument.
+func (s *TripService) shareInstrument(ctx context.Context, riderID string, method domain.PaymentMethod) (string, error) {
+	instrumentID, err := s.paymentService.DefaultInstrumentID(ctx, riderID, method)
+	if err != nil {
+		return "", err
+	}
+	if instrumentID == "" && s.paymentService.instrumentRepo != nil {
+		return "", ErrNoPaymentInstrument
+	}
+	return instrumentID, nil
+}
+
+// fareSplit returns the trip's fare split, or nil if its fare is not split.
+// A split that cannot be loaded is logged, and the ride's rider pays the
+// whole fare.
+func (s *TripService) fareSplit(ctx context.Context, trip *domain.Trip) *domain.FareSplit {
+	if s.fareSplits == nil {
+		return nil
+	}
+	split, err := s.fareSplits.GetByTripID(ctx, trip.ID)
+	if err != nil {
+		log.Printf("[PAYMENT] Failed to load fare split of trip %s; charging the ride's rider: %v", trip.ID, err)
+		return nil
+	}
+	return split
+}
+
+// settleSplitFare charges each rider their share of the trip's fare and
+// generates each a receipt for it. The other riders are charged first: a
+// share that fails to pay is added to the owner's and flagged for follow-up,
+// so one rider's card cannot hold up the rest. It returns the owner's
+// payment and receipt.
+func (s *TripService) settleSplitFare(ctx context.Context, trip *domain.Trip, ride *domain.Ride, split *domain.FareSplit) (*domain.Payment, *domain.Receipt) {
+	split.Apportion(trip.Fare)
+
+	payments := make(map[string]*domain.Payment, len(split.Shares))
+	var absorbed float64
+	for i := range split.Shares {
+		share := &split.Shares[i]
+		if share.RiderID == split.OwnerID || share.Amount <= 0 {
+			continue
+		}
+
+		payment := s.chargeShare(ctx, trip, ride, share)
+		if payment != nil {
+			share.PaymentID = payment.ID
+			payments[share.RiderID] = payment
+		}
+		if payment == nil || payment.Status != domain.PaymentStatusSuccess {
+			share.Absorbed = true
+			absorbed = roundCents(absorbed + share.Amount)
+			log.Printf("[PAYMENT] trip %s: %s's share of %.2f did not pay; charged to %s and flagged for follow-up",
+				trip.ID, share.RiderID, share.Amount, split.OwnerID)
+			recordMetric(ctx, metricFareSharesAbsorbed, 1)
+			continue
+		}
+		if s.notificationService != nil {
+			_ = s.notificationService.NotifyPaymentSuccess(ctx, payment, share.RiderID)
+		}
+	}
+
+	// The owner's share, with what the others could not pay, is the trip's
+	// fare payment, captured against any hold on their card.
+	owner := split.Share(split.OwnerID)
+	var payment *domain.Payment
+	if total := roundCents(owner.Amount + absorbed); total > 0 {
+		payment, _ = s.paymentService.ProcessPayment(ctx, ProcessPaymentRequest{
+			TripID: trip.ID,
+			Amount: total,
+			Method: ride.PaymentMethod,
+
+			InstrumentID: ride.InstrumentID,
+		})
+	} else {
+		payment, _ = s.paymentService.VoidAuthorization(ctx, trip.ID)
+	}
+	if payment != nil {
+		owner.PaymentID = payment.ID
+		payments[owner.RiderID] = payment
+	}
+
+	if s.notificationService != nil && payment != nil {
+		if payment.Status == domain.PaymentStatusSuccess {
+			_ = s.notificationService.NotifyPaymentSuccess(ctx, payment, ride.RiderID)
+		} else if payment.Status == domain.PaymentStatusFailed {
+			_ = s.notificationService.NotifyPaymentFailed(ctx, payment, ride.RiderID)
+		}
+	}
+
+	if err := s.fareSplits.Save(ctx, split); err != nil {
+		log.Printf("[PAYMENT] Failed to record the settled fare split of trip %s: %v", trip.ID, err)
+	}
+
+	var receipt *domain.Receipt
+	if s.receiptService != nil {
+		for _, share := range split.Shares {
+			r, _ := s.receiptService.GenerateReceipt(ctx, GenerateReceiptRequest{
+				Trip:    trip,
+				Ride:    ride,
+				Payment: payments[share.RiderID],
+				Split:   split,
+				RiderID: share.RiderID,
+			})
+			if share.RiderID == split.OwnerID {
+				receipt = r
+			}
+		}
+	}
+
+	return payment, receipt
+}
+
+// chargeShare charges a rider other than the owner their share of a split
+// fare, under a key of its own so a retried settlement charges it once. It
+// returns nil if the share could not be charged at all.
+func (s *TripService) chargeShare(ctx context.Context, trip *domain.Trip, ride *domain.Ride, share *domain.FareShare) *domain.Payment {
+	instrumentID, err := s.shareInstrument(ctx, share.RiderID, ride.PaymentMethod)
+	if err != nil {
+		log.Printf("[PAYMENT] trip %s: no instrument to charge %s's share: %v", trip.ID, share.RiderID, err)
+		return nil
+	}
+
+	payment, err := s.paymentService.ProcessPayment(ctx, ProcessPaymentRequest{
+		TripID:         trip.ID,
+		Amount:         share.Amount,
+		Method:         ride.PaymentMethod,
+		InstrumentID:   instrumentID,
+		IdempotencyKey: splitShareKey(share.RiderID),
+	})
+	if err != nil {
+		log.Printf("[PAYMENT] trip %s: failed to charge %s's share: %v", trip.ID, share.RiderID, err)
+		return nil
+	}
+	return payment
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/repository"
+)
+
+// Feature flags consulted at decision points. While a flag is undefined the
+// decision point keeps its configured behavior.
+const (
+	// FlagDegradedMatching lets a rider's match fall back to the database
+	// when Redis is unreachable (MATCHING_DEGRADED_FALLBACK).
+	FlagDegradedMatching = "degraded_matching"
+
+	// FlagRideQuotes honors the surge quoted by EstimateRide when a rider
+	// requests the ride. Off prices every ride live.
+	FlagRideQuotes = "ride_quotes"
+)
+
+const defaultFeatureFlagCacheTTL = 30 * time.Second // Used when the configured TTL is not positive
+
+// FeatureFlagService evaluates feature flags for soft launches. Flags are
+// cached in memory and reloaded once the cache is older than its TTL, so
+// evaluating a flag on a hot path rarely touches the database.
+type FeatureFlagService struct {
+	flagRepo repository.FeatureFlagRepository
+	city     string        // The city this deployment serves, matched against flag allowlists
+	cacheTTL time.Duration // How long loaded flags are used before reloading
+
+	mu       sync.Mutex
+	flags    map[string]*domain.FeatureFlag // Nil until first loaded
+	loadedAt time.Time
+}
+
+// NewFeatureFlagService creates a new FeatureFlagService.
+func NewFeatureFlagService(flagRepo repository.FeatureFlagRepository, city string, cacheTTL time.Duration) *FeatureFlagService {
+	if cacheTTL <= 0 {
+		cacheTTL = defaultFeatureFlagCacheTTL
+	}
+	return &FeatureFlagService{
+		flagRepo: flagRepo,
+		city:     city,
+		cacheTTL: cacheTTL,
+	}
+}
+
+// Enabled reports whether the named flag is on for a user. An undefined flag
+// returns fallback, as does every flag on a nil service. If reloading the
+// flags fails, the previously loaded ones stay in use until the next reload.
+func (s *FeatureFlagService) Enabled(ctx context.Context, name, userID string, fallback bool) bool {
+	if s == nil {
+		return fallback
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.flags == nil || time.Since(s.loadedAt) >= s.cacheTTL {
+		s.reload(ctx)
+	}
+	flag, ok := s.flags[name]
+	if !ok {
+		return fallback
+	}
+	return flag.IsOnFor(userID, s.city)
+}
+
+// reload replaces the cached flags with the stored ones. The caller must
+// hold s.mu.
+func (s *FeatureFlagService) reload(ctx context.Context) {
+	// A failed reload is not retried until the TTL passes again, so an
+	// unavailable database is not queried on every evaluation.
+	s.loadedAt = time.Now()
+
+	flags, err := s.flagRepo.GetAll(ctx)
+	if err != nil {
+		log.Printf("[FLAGS] Failed to reload feature flags, keeping %d cached: %v", len(s.flags), err)
+		return
+	}
+
+	s.flags = make(map[string]*domain.FeatureFlag, len(flags))
+	for _, flag := range flags {
+		s.flags[flag.Name] = flag
+	}
+}
+
+// FeatureFlagRequest contains a flag's rollout rules.
+type FeatureFlagRequest struct {
+	Enabled    bool
+	Percentage int
+	Cities     []string
+}
+
+// SetFlag creates or replaces the rules of a flag. The change applies to this
+// instance at once and to others when their cache next reloads.
+func (s *FeatureFlagService) SetFlag(ctx context.Context, name string, req FeatureFlagRequest) (*domain.FeatureFlag, error) {
+	flag := &domain.FeatureFlag{
+		Name:       name,
+		Enabled:    req.Enabled,
+		Percentage: req.Percentage,
+		Cities:     req.Cities,
+		UpdatedAt:  time.Now(),
+	}
+	if err := flag.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.flagRepo.Upsert(ctx, flag); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	if s.flags != nil {
+		cached := *flag
+		s.flags[name] = &cached
+	}
+	s.mu.Unlock()
+
+	log.Printf("[FLAGS] %s set: enabled=%t percentage=%d cities=%v", name, flag.Enabled, flag.Percentage, flag.Cities)
+	return flag, nil
+}
+
+// ListFlags retrieves every flag, by name, as currently stored.
+func (s *FeatureFlagService) ListFlags(ctx context.Context) ([]*domain.FeatureFlag, error) {
+	return s.flagRepo.GetAll(ctx)
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/redis"
+)
+
+const (
+	defaultMaxSpeedKmh       = 200.0 // Used when the configured speed is not positive
+	defaultMaxSpeedAnomalies = 5     // Used when the configured count is not positive
+	speedGuardMinJumpKm      = 0.5   // Shorter moves are GPS jitter, whatever speed they imply
+	speedAnomalyReportLimit  = 500   // Drivers listed in the anomaly report
+)
+
+// LocationGuardService rejects driver location updates that imply the
+// driver moved faster than any car could, as when a driver spoofs GPS to
+// appear in a surge zone. Each update is compared with the driver's last
+// accepted fix, timed by the server clock rather than the device's. A
+// driver whose rejected updates reach the anomaly limit is held out of the
+// available set until an admin clears their review.
+type LocationGuardService struct {
+	store        redis.LocationGuardStoreInterface
+	maxSpeedKmh  float64 // Average speed since the last fix above which an update is rejected
+	maxAnomalies int64   // Rejected updates before the driver is held for review
+	now          func() time.Time
+}
+
+// NewLocationGuardService creates a new LocationGuardService. A nil now
+// means time.Now.
+func NewLocationGuardService(store redis.LocationGuardStoreInterface, maxSpeedKmh float64, maxAnomalies int, now func() time.Time) *LocationGuardService {
+	if maxSpeedKmh <= 0 {
+		maxSpeedKmh = defaultMaxSpeedKmh
+	}
+	if maxAnomalies <= 0 {
+		maxAnomalies = defaultMaxSpeedAnomalies
+	}
+	if now == nil {
+		now = time.Now
+	}
+	return &LocationGuardService{store: store, maxSpeedKmh: maxSpeedKmh, maxAnomalies: int64(maxAnomalies), now: now}
+}
+
+// SpeedAnomalySummary is a driver's record of location updates rejected
+// for implausible speed.
+type SpeedAnomalySummary struct {
+	DriverID      string
+	Rejected      int64
+	HeldForReview bool // Kept out of the available set until cleared
+}
+
+// CheckLocation checks a location update against the driver's last
+// accepted fix and reports whether the driver is held for review. It
+// returns ErrImplausibleSpeed, and counts an anomaly, if getting here from
+// that fix implies a speed above the limit; a rejected update leaves the
+// fix alone, so the next one is still measured from where the driver
+// really was.
+//
+// Moves shorter than speedGuardMinJumpKm are not measured and do not
+// replace the fix either, so creeping in small steps still adds up. Store
+// failures are logged and the update let through: the guard must not stop
+// drivers reporting their location.
+func (s *LocationGuardService) CheckLocation(ctx context.Context, driverID string, lat, lng float64) (bool, error) {
+	now := s.now()
+
+	last, err := s.store.LastFix(ctx, driverID)
+	if err != nil {
+		log.Printf("[SPEED GUARD] Failed to load last fix of driver %s: %v", driverID, err)
+		return false, nil
+	}
+
+	if last != nil {
+		distanceKm := domain.HaversineKm(last.Lat, last.Lng, lat, lng)
+		if distanceKm <= speedGuardMinJumpKm {
+			return s.held(ctx, driverID), nil
+		}
+
+		elapsed := now.Sub(last.At)
+		if speed := domain.SpeedKmh(distanceKm, elapsed); speed > s.maxSpeedKmh {
+			count, err := s.store.IncrementSpeedAnomalies(ctx, driverID)
+			if err != nil {
+				log.Printf("[SPEED GUARD] Failed to count anomaly of driver %s: %v", driverID, err)
+			}
+			log.Printf("[SPEED GUARD] Rejected location of driver %s: %.1f km in %s implies %.0f km/h (%d rejected)",
+				driverID, distanceKm, elapsed, speed, count)
+			recordMetric(ctx, metricLocationSpeedRejected, 1)
+			return count >= s.maxAnomalies, ErrImplausibleSpeed
+		}
+	}
+
+	if err := s.store.SetLastFix(ctx, driverID, redis.LocationFix{Lat: lat, Lng: lng, At: now}); err != nil {
+		log.Printf("[SPEED GUARD] Failed to store last fix of driver %s: %v", driverID, err)
+	}
+	return s.held(ctx, driverID), nil
+}
+
+// held reports whether the driver's rejected updates have reached the
+// anomaly limit. A count that cannot be read does not hold them.
+func (s *LocationGuardService) held(ctx context.Context, driverID string) bool {
+	count, err := s.store.SpeedAnomalies(ctx, driverID)
+	if err != nil {
+		log.Printf("[SPEED GUARD] Failed to load anomalies of driver %s: %v", driverID, err)
+		return false
+	}
+	return count >= s.maxAnomalies
+}
+
+// SpeedAnomalies returns the drivers with the most location updates
+// rejected for implausible speed, most first.
+func (s *LocationGuardService) SpeedAnomalies(ctx context.Context) ([]SpeedAnomalySummary, error) {
+	counts, err := s.store.ListSpeedAnomalies(ctx, speedAnomalyReportLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]SpeedAnomalySummary, 0, len(counts))
+	for _, c := range counts {
+		summaries = append(summaries, SpeedAnomalySummary{
+			DriverID:      c.DriverID,
+			Rejected:      c.Count,
+			HeldForReview: c.Count >= s.maxAnomalies,
+		})
+	}
+	return summaries, nil
+}
+
+// ClearReview resets a driver's rejected updates after review, releasing
+// them if held; their next location update makes them available again.
+// It reports whether they had any.
+func (s *LocationGuardService) ClearReview(ctx context.Context, driverID string) (bool, error) {
+	if driverID == "" {
+		return false, ErrInvalidDriverID
+	}
+	return s.store.ClearSpeedAnomalies(ctx, driverID)
+}
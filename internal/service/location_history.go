@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/repository"
+)
+
+const (
+	defaultLocationHistoryBatchSize     = 100                 // Used when the configured batch size is not positive
+	defaultLocationHistoryFlushInterval = 5 * time.Second     // Used when the configured flush interval is not positive
+	defaultLocationHistoryRetention     = 30 * 24 * time.Hour // Used when the configured retention is not positive
+	locationHistoryQueueBatches         = 10                  // Queue capacity, in batches, before points are dropped
+	locationHistoryPruneInterval        = time.Hour
+	defaultTrackWindow                  = 24 * time.Hour // Track window when no start is given
+)
+
+// LocationHistoryService records driver positions for replay and analytics.
+// Points are queued and written in batches by a background writer, so
+// location updates never wait on the database; when the queue is full,
+// points are dropped rather than slowing updates down. Points older than
+// the retention period are pruned periodically.
+//
+// Mobile clients often resend a fix or deliver fixes out of order, so a
+// point extends a driver's path only if it was recorded after the last
+// point accepted for them; anything else is ignored. This is tracked per
+// instance.
+type LocationHistoryService struct {
+	repo          repository.LocationHistoryRepository
+	batchSize     int
+	flushInterval time.Duration
+	retention     time.Duration
+
+	mu   sync.Mutex
+	last map[string]time.Time // Driver ID -> RecordedAt of their last accepted point
+
+	points    chan *domain.LocationPoint
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewLocationHistoryService creates a LocationHistoryService and starts its
+// background writer. Call Close on shutdown to flush queued points.
+func NewLocationHistoryService(
+	repo repository.LocationHistoryRepository,
+	batchSize int,
+	flushInterval time.Duration,
+	retention time.Duration,
+) *LocationHistoryService {
+	if batchSize <= 0 {
+		batchSize = defaultLocationHistoryBatchSize
+	}
+	if flushInterval <= 0 {
+		flushInterval = defaultLocationHistoryFlushInterval
+	}
+	if retention <= 0 {
+		retention = defaultLocationHistoryRetention
+	}
+
+	s := &LocationHistoryService{
+		repo:          repo,
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		retention:     retention,
+		last:          make(map[string]time.Time),
+		points:        make(chan *domain.LocationPoint, batchSize*locationHistoryQueueBatches),
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// Record queues a driver position for the history. It never blocks. recordedAt is when the device took the
+// fix: zero means now, and a time in the future is taken as now. A point
+// not recorded after the driver's last accepted one is a duplicate or
+// arrived out of order, and is ignored.
+func (s *LocationHistoryService) Record(driverID string, lat, lng, heading float64, recordedAt time.Time) {
+	now := time.Now()
+	if recordedAt.IsZero() || recordedAt.After(now) {
+		recordedAt = now
+	}
+
+	s.mu.Lock()
+	if !recordedAt.After(s.last[driverID]) {
+		s.mu.Unlock()
+		return
+	}
+	s.last[driverID] = recordedAt
+	s.mu.Unlock()
+
+	point := &domain.LocationPoint{
+		DriverID:   driverID,
+		Lat:        lat,
+		Lng:        lng,
+		Heading:    heading,
+		RecordedAt: recordedAt,
+	}
+
+	select {
+	case <-s.stop:
+	case s.points <- point:
+	default:
+		log.Printf("[HISTORY] Queue full, dropping location point for driver %s", driverID)
+	}
+}
+
+// Close stops the background writer after flushing queued points.
+func (s *LocationHistoryService) Close() {
+	s.closeOnce.Do(func() { close(s.stop) })
+	<-s.done
+}
+
+// Track returns a driver's recorded path in [from, to], oldest first. A zero
+// to means now; a zero from means defaultTrackWindow before to.
+func (s *LocationHistoryService) Track(ctx context.Context, driverID string, from, to time.Time) ([]*domain.LocationPoint, error) {
+	if driverID == "" {
+		return nil, ErrInvalidDriverID
+	}
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if from.IsZero() {
+		from = to.Add(-defaultTrackWindow)
+	}
+	if from.After(to) {
+		return nil, ErrInvalidTrackWindow
+	}
+
+	return s.repo.ListByDriver(ctx, driverID, from, to)
+}
+
+// Prune removes points older than the retention period, and forgets the
+// last point of drivers with none newer.
+func (s *LocationHistoryService) Prune(ctx context.Context) (int64, error) {
+	cutoff := time.Now().Add(-s.retention)
+
+	s.mu.Lock()
+	for driverID, last := range s.last {
+		if last.Before(cutoff) {
+			delete(s.last, driverID)
+		}
+	}
+	s.mu.Unlock()
+
+	return s.repo.DeleteBefore(ctx, cutoff)
+}
+
+// run batches queued points, writing a batch when it is full or when the
+// flush interval passes, and prunes old points on its own interval.
+func (s *LocationHistoryService) run() {
+	defer close(s.done)
+
+	flushTicker := time.NewTicker(s.flushInterval)
+	defer flushTicker.Stop()
+	pruneTicker := time.NewTicker(locationHistoryPruneInterval)
+	defer pruneTicker.Stop()
+
+	batch := make([]*domain.LocationPoint, 0, s.batchSize)
+	for {
+		select {
+		case point := <-s.points:
+			batch = append(batch, point)
+			if len(batch) >= s.batchSize {
+				batch = s.flush(batch)
+			}
+		case <-flushTicker.C:
+			batch = s.flush(batch)
+		case <-pruneTicker.C:
+			if removed, err := s.Prune(context.Background()); err != nil {
+				log.Printf("[HISTORY] Failed to prune location history: %v", err)
+			} else if removed > 0 {
+				log.Printf("[HISTORY] Pruned %d location points", removed)
+			}
+		case <-s.stop:
+			for {
+				select {
+				case point := <-s.points:
+					batch = append(batch, point)
+					if len(batch) >= s.batchSize {
+						batch = s.flush(batch)
+					}
+				default:
+					s.flush(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+// flush writes the batch and returns it emptied for reuse. A failed batch
+// is logged and dropped; history is best effort.
+func (s *LocationHistoryService) flush(batch []*domain.LocationPoint) []*domain.LocationPoint {
+	if len(batch) == 0 {
+		return batch
+	}
+	if err := s.repo.CreateBatch(context.Background(), batch); err != nil {
+		log.Printf("[HISTORY] Failed to write %d location points: %v", len(batch), err)
+	}
+	return batch[:0]
+}
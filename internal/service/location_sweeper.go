@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	defaultLocationMaxAge        = 10 * time.Minute // Used when the configured maximum age is not positive
+	defaultLocationSweepInterval = time.Minute      // Used when the configured interval is not positive
+)
+
+// LocationSweeper periodically removes drivers who stopped sending location
+// pings from Redis. The geo indexes have no per-member TTL, so without it a
+// driver who vanished without going offline would stay matchable.
+type LocationSweeper struct {
+	driverService *DriverService
+	maxAge        time.Duration
+	interval      time.Duration
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewLocationSweeper creates a LocationSweeper and starts its background
+// loop. Call Close on shutdown to stop it.
+func NewLocationSweeper(driverService *DriverService, maxAge time.Duration, interval time.Duration) *LocationSweeper {
+	if maxAge <= 0 {
+		maxAge = defaultLocationMaxAge
+	}
+	if interval <= 0 {
+		interval = defaultLocationSweepInterval
+	}
+
+	s := &LocationSweeper{
+		driverService: driverService,
+		maxAge:        maxAge,
+		interval:      interval,
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// Close stops the background loop, waiting for a sweep in progress.
+func (s *LocationSweeper) Close() {
+	s.closeOnce.Do(func() { close(s.stop) })
+	<-s.done
+}
+
+// run sweeps for stale driver locations on every interval.
+func (s *LocationSweeper) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if removed, err := s.driverService.SweepStaleLocations(context.Background(), time.Now(), s.maxAge); err != nil {
+				log.Printf("[LOCATION] Failed to sweep stale driver locations: %v", err)
+			} else if removed > 0 {
+				log.Printf("[LOCATION] Removed %d drivers without a recent location ping", removed)
+			}
+		case <-s.stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"ride/internal/domain"
+	"ride/internal/repository"
+)
+
+// matchAttemptQueueSize is how many attempts may wait to be written before
+// new ones are dropped.
+const matchAttemptQueueSize = 1000
+
+// AsyncMatchAttemptWriter is a repository.MatchAttemptRepository that queues
+// attempts and writes them from a background goroutine, so matching never
+// waits on the database to record its diagnostics. When the queue is full,
+// attempts are dropped rather than slowing matching down. Reads go straight
+// to the underlying repository and may miss attempts still queued.
+type AsyncMatchAttemptWriter struct {
+	repo repository.MatchAttemptRepository
+
+	attempts  chan *domain.MatchAttempt
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewAsyncMatchAttemptWriter creates an AsyncMatchAttemptWriter and starts
+// its background writer. Call Close on shutdown to write queued attempts.
+func NewAsyncMatchAttemptWriter(repo repository.MatchAttemptRepository) *AsyncMatchAttemptWriter {
+	w := &AsyncMatchAttemptWriter{
+		repo:     repo,
+		attempts: make(chan *domain.MatchAttempt, matchAttemptQueueSize),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Create queues a copy of the attempt for writing. It never blocks and
+// never fails.
+func (w *AsyncMatchAttemptWriter) Create(ctx context.Context, attempt *domain.MatchAttempt) error {
+	queued := *attempt
+	select {
+	case <-w.stop:
+	case w.attempts <- &queued:
+	default:
+		log.Printf("[MATCH] Attempt queue full, dropping match attempt for ride %s", attempt.RideID)
+	}
+	return nil
+}
+
+// ListByRide retrieves a ride's written match attempts, oldest first.
+func (w *AsyncMatchAttemptWriter) ListByRide(ctx context.Context, rideID string) ([]*domain.MatchAttempt, error) {
+	return w.repo.ListByRide(ctx, rideID)
+}
+
+// Close stops the background writer after writing queued attempts.
+func (w *AsyncMatchAttemptWriter) Close() {
+	w.closeOnce.Do(func() { close(w.stop) })
+	<-w.done
+}
+
+// run writes queued attempts until stopped, then drains the queue.
+func (w *AsyncMatchAttemptWriter) run() {
+	defer close(w.done)
+
+	for {
+		select {
+		case attempt := <-w.attempts:
+			w.write(attempt)
+		case <-w.stop:
+			for {
+				select {
+				case attempt := <-w.attempts:
+					w.write(attempt)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (w *AsyncMatchAttemptWriter) write(attempt *domain.MatchAttempt) {
+	if err := w.repo.Create(context.Background(), attempt); err != nil {
+		log.Printf("[MATCH] Failed to record match attempt for ride %s: %v", attempt.RideID, err)
+	}
+}
+
+// Ensure AsyncMatchAttemptWriter implements repository.MatchAttemptRepository.
+var _ repository.MatchAttemptRepository = (*AsyncMatchAttemptWriter)(nil)
@@ -3,8 +3,14 @@ package service
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
 	"time"
 
+	"github.com/google/uuid"
+
 	"ride/internal/domain"
 	"ride/internal/redis"
 	"ride/internal/repository"
@@ -13,36 +19,93 @@ import (
 
 const (
 	defaultSearchRadiusKm = 5.0
+	defaultMaxCandidates  = 25 // Used when the configured cap is not positive
 	driverLockTTL         = 10 * time.Second
 	rideLockTTL           = 30 * time.Second // Lock ride during matching
+	defaultExclusionTTL   = 24 * time.Hour   // Used when the configured exclusion TTL is not positive
+
+	// defaultDestinationAngleDeg is how far, in degrees, a ride's direction may
+	// stray from a destination-mode driver's direction home, when the
+	// configured angle is not positive.
+	defaultDestinationAngleDeg = 45.0
 )
 
+// errDriverTaken is returned by a conditional assignment when the driver is no
+// longer ONLINE, i.e. another match claimed them first.
+var errDriverTaken = errors.New("driver no longer online")
+
 // MatchingService handles driver-rider matching.
 type MatchingService struct {
-	db            *sql.DB
-	locationStore redis.LocationStoreInterface
-	lockStore     redis.LockStoreInterface
-	cacheStore    *redis.CacheStore
-	driverRepo    repository.DriverRepository
-	rideRepo      repository.RideRepository
+	db               *sql.DB
+	locationStore    redis.LocationStoreInterface
+	lockStore        redis.LockStoreInterface
+	cacheStore       *redis.CacheStore
+	driverRepo       repository.DriverRepository
+	rideRepo         repository.RideRepository
+	attemptRepo      repository.MatchAttemptRepository // Optional: nil disables match diagnostics
+	maxCandidates    int                               // Closest drivers attempted per match
+	degradedFallback bool                              // Match from the database when Redis is unreachable
+	tierRadiusKm     map[domain.DriverTier]float64     // Default search radius per tier
+	exclusionStore   redis.ExclusionStoreInterface     // Optional: nil honors only each request's own exclusions
+	exclusionTTL     time.Duration                     // How long a ride's excluded drivers are remembered
+	flags            *FeatureFlagService               // Optional: nil uses the configured degraded fallback for every rider
+	destinationStore redis.DestinationStoreInterface   // Optional: nil ignores destination mode
+	destinationAngle float64                           // Widest angle between a ride and a destination-mode driver's heading
+	areaLimiter      *areaLimiter                      // Bounds concurrent assignment transactions per pickup area
+	tripRepo         repository.TripRepository         // Optional: nil skips the active-trip check of manual assignments
+}
+
+// MatchingServiceDeps holds what a MatchingService is built from.
+type MatchingServiceDeps struct {
+	DB                   *sql.DB
+	LocationStore        redis.LocationStoreInterface
+	LockStore            redis.LockStoreInterface
+	CacheStore           *redis.CacheStore
+	DriverRepo           repository.DriverRepository
+	RideRepo             repository.RideRepository
+	AttemptRepo          repository.MatchAttemptRepository // Optional: nil disables match diagnostics
+	MaxCandidates        int                               // Closest drivers attempted per match; 0 uses the default
+	DegradedFallback     bool                              // Match from the database when Redis is unreachable
+	TierRadiusKm         map[domain.DriverTier]float64     // Default search radius per tier
+	ExclusionStore       redis.ExclusionStoreInterface     // Optional: nil honors only each request's own exclusions
+	ExclusionTTL         time.Duration                     // How long a ride's excluded drivers are remembered; 0 uses the default
+	Flags                *FeatureFlagService               // Optional: nil uses DegradedFallback for every rider
+	DestinationStore     redis.DestinationStoreInterface   // Optional: nil ignores destination mode
+	DestinationAngleDeg  float64                           // Widest angle between a ride and a destination-mode driver's heading; 0 uses the default
+	MaxConcurrentPerArea int                               // Concurrent assignment transactions per pickup area; 0 is unlimited
+	TripRepo             repository.TripRepository         // Optional: nil skips the active-trip check of manual assignments
 }
 
 // NewMatchingService creates a new MatchingService.
-func NewMatchingService(
-	db *sql.DB,
-	locationStore redis.LocationStoreInterface,
-	lockStore redis.LockStoreInterface,
-	cacheStore *redis.CacheStore,
-	driverRepo repository.DriverRepository,
-	rideRepo repository.RideRepository,
-) *MatchingService {
+func NewMatchingService(deps MatchingServiceDeps) *MatchingService {
+	if deps.MaxCandidates <= 0 {
+		deps.MaxCandidates = defaultMaxCandidates
+	}
+	if deps.DestinationAngleDeg <= 0 {
+		deps.DestinationAngleDeg = defaultDestinationAngleDeg
+	}
+	if deps.ExclusionTTL <= 0 {
+		deps.ExclusionTTL = defaultExclusionTTL
+	}
+
 	return &MatchingService{
-		db:            db,
-		locationStore: locationStore,
-		lockStore:     lockStore,
-		cacheStore:    cacheStore,
-		driverRepo:    driverRepo,
-		rideRepo:      rideRepo,
+		db:               deps.DB,
+		locationStore:    deps.LocationStore,
+		lockStore:        deps.LockStore,
+		cacheStore:       deps.CacheStore,
+		driverRepo:       deps.DriverRepo,
+		rideRepo:         deps.RideRepo,
+		attemptRepo:      deps.AttemptRepo,
+		maxCandidates:    deps.MaxCandidates,
+		degradedFallback: deps.DegradedFallback,
+		tierRadiusKm:     deps.TierRadiusKm,
+		exclusionStore:   deps.ExclusionStore,
+		exclusionTTL:     deps.ExclusionTTL,
+		flags:            deps.Flags,
+		destinationStore: deps.DestinationStore,
+		destinationAngle: deps.DestinationAngleDeg,
+		areaLimiter:      newAreaLimiter(deps.MaxConcurrentPerArea),
+		tripRepo:         deps.TripRepo,
 	}
 }
 
@@ -52,7 +115,13 @@ type MatchRequest struct {
 	Lat      float64
 	Lng      float64
 	Tier     domain.DriverTier // Optional: empty means any tier
-	RadiusKm float64           // Optional: 0 uses default
+	RadiusKm float64           // Optional: 0 uses the tier's default radius
+	RiderID  string            // Optional: the rider feature flags are evaluated for
+
+	// ExcludeDriverIDs are drivers never to assign to the ride, e.g. ones the
+	// rider blocked or who cancelled it. They are remembered for the ride, so
+	// later matches exclude them too without being told again.
+	ExcludeDriverIDs []string
 }
 
 // MatchResult contains the result of a successful match.
@@ -66,24 +135,129 @@ type MatchResult struct {
 // - Ride locking to prevent double assignment
 // - Batch driver lookup from cache
 // - Cache invalidation on assignment
+//
+// Every call, successful or not, is recorded as a MatchAttempt.
+//
+// With the degraded fallback enabled, an unreachable Redis does not fail the
+// match: candidates come from the database instead (see matchFromDatabase).
 func (s *MatchingService) Match(ctx context.Context, req MatchRequest) (*MatchResult, error) {
+	start := time.Now()
+
 	// Set default radius if not specified.
 	radiusKm := req.RadiusKm
 	if radiusKm <= 0 {
-		radiusKm = defaultSearchRadiusKm
+		radiusKm = s.defaultRadiusKm(req.Tier)
+	}
+
+	attempt := &domain.MatchAttempt{
+		ID:        uuid.New().String(),
+		RideID:    req.RideID,
+		Tier:      req.Tier,
+		RadiusKm:  radiusKm,
+		CreatedAt: start,
+	}
+
+	result, err := s.match(ctx, req, attempt)
+	switch {
+	case errors.Is(err, ErrNoDriverAvailable):
+		recordMetric(ctx, metricMatchingNoDriver, 1)
+	case errors.Is(err, ErrMatchingCandidatesExhausted):
+		recordMetric(ctx, metricMatchingCandidatesExhausted, 1)
+	}
+	// Record the attempt even if the caller has gone away.
+	s.recordAttempt(context.WithoutCancel(ctx), attempt, start, result, err)
+	return result, err
+}
+
+// defaultRadiusKm returns the configured search radius for a tier, falling
+// back to defaultSearchRadiusKm for requests with no tier or no configured
+// radius.
+func (s *MatchingService) defaultRadiusKm(tier domain.DriverTier) float64 {
+	if radiusKm := s.tierRadiusKm[tier]; radiusKm > 0 {
+		return radiusKm
+	}
+	return defaultSearchRadiusKm
+}
+
+// ListAttempts returns the recorded match attempts for a ride, oldest first.
+func (s *MatchingService) ListAttempts(ctx context.Context, rideID string) ([]*domain.MatchAttempt, error) {
+	if rideID == "" {
+		return nil, ErrInvalidRideID
+	}
+	if s.attemptRepo == nil {
+		return nil, nil
+	}
+	return s.attemptRepo.ListByRide(ctx, rideID)
+}
+
+// GetDriver loads a driver for display, from the cache when present and
+// otherwise from the database, caching the result.
+func (s *MatchingService) GetDriver(ctx context.Context, driverID string) (*domain.Driver, error) {
+	if driverID == "" {
+		return nil, ErrInvalidDriverID
+	}
+
+	if s.cacheStore != nil {
+		if cached, err := s.cacheStore.GetDriver(ctx, driverID); err == nil && cached != nil {
+			return s.cachedToDriver(cached), nil
+		}
+	}
+
+	driver, err := s.driverRepo.GetByID(ctx, driverID)
+	if err != nil {
+		return nil, err
+	}
+	s.cacheDriverAsync(ctx, driver)
+	return driver, nil
+}
+
+// ReleaseDriverLock force-releases a driver's matching lock, for operators
+// clearing a lock left behind by a crash. The driver is put back in the
+// available set only if they are ONLINE. Reports whether a lock was held.
+func (s *MatchingService) ReleaseDriverLock(ctx context.Context, driverID string) (bool, error) {
+	if driverID == "" {
+		return false, ErrInvalidDriverID
+	}
+
+	driver, err := s.driverRepo.GetByID(ctx, driverID)
+	if err != nil {
+		return false, err
 	}
 
+	locked, err := s.lockStore.IsDriverLocked(ctx, driverID)
+	if err != nil {
+		return false, err
+	}
+	if err := s.lockStore.ReleaseDriverLock(ctx, driverID); err != nil {
+		return false, err
+	}
+
+	s.invalidateDriverCache(ctx, driverID)
+	if s.cacheStore != nil && driver.Status == domain.DriverStatusOnline {
+		_ = s.cacheStore.AddAvailableDriver(ctx, driverID)
+	}
+
+	return locked, nil
+}
+
+// match runs the matching algorithm, tallying skipped candidates on attempt.
+func (s *MatchingService) match(ctx context.Context, req MatchRequest, attempt *domain.MatchAttempt) (*MatchResult, error) {
+
 	// OPTIMIZATION 1: Acquire ride lock to prevent concurrent matching
 	if s.cacheStore != nil {
 		locked, err := s.cacheStore.AcquireRideLock(ctx, req.RideID, rideLockTTL)
-		if err != nil {
+		switch {
+		case s.canDegrade(ctx, req, err):
+			// The ride's version check still prevents double assignment.
+			s.markDegraded(ctx, attempt, err)
+		case err != nil:
 			return nil, err
-		}
-		if !locked {
+		case !locked:
 			// Another matching process is handling this ride
 			return nil, ErrRideNotInRequestedState
+		default:
+			defer s.cacheStore.ReleaseRideLock(context.WithoutCancel(ctx), req.RideID)
 		}
-		defer s.cacheStore.ReleaseRideLock(ctx, req.RideID)
 	}
 
 	// Get ride and verify it's in REQUESTED state.
@@ -92,20 +266,48 @@ func (s *MatchingService) Match(ctx context.Context, req MatchRequest) (*MatchRe
 		return nil, err
 	}
 
-	if ride.Status != domain.RideStatusRequested {
+	if !ride.CanTransitionTo(domain.RideStatusAssigned) {
 		return nil, ErrRideNotInRequestedState
 	}
 
-	// Find nearby drivers from Redis (sorted by distance).
-	nearbyDrivers, err := s.locationStore.FindNearbyDrivers(ctx, req.Lat, req.Lng, radiusKm)
+	excluded, err := s.excludedDrivers(ctx, req, attempt)
 	if err != nil {
 		return nil, err
 	}
 
+	// Find the closest drivers from Redis (sorted by distance). Only the
+	// nearest maxCandidates are attempted, bounding work in dense areas.
+	if attempt.Degraded {
+		return s.matchFromDatabase(ctx, req, ride, excluded, attempt)
+	}
+	nearbyDrivers, err := s.locationStore.FindNearbyDrivers(ctx, req.Lat, req.Lng, attempt.RadiusKm, s.maxCandidates)
+	if err != nil {
+		if !s.canDegrade(ctx, req, err) {
+			return nil, err
+		}
+		s.markDegraded(ctx, attempt, err)
+		return s.matchFromDatabase(ctx, req, ride, excluded, attempt)
+	}
+	if len(nearbyDrivers) > s.maxCandidates {
+		nearbyDrivers = nearbyDrivers[:s.maxCandidates]
+	}
+	attempt.CandidatesFound = len(nearbyDrivers)
+
 	if len(nearbyDrivers) == 0 {
 		return nil, ErrNoDriverAvailable
 	}
 
+	// Drop excluded drivers before any cache, database or lock work.
+	candidates := nearbyDrivers[:0]
+	for _, loc := range nearbyDrivers {
+		if excluded[loc.DriverID] {
+			attempt.SkippedExcluded++
+			continue
+		}
+		candidates = append(candidates, loc)
+	}
+	nearbyDrivers = candidates
+
 	// OPTIMIZATION 2: Batch fetch driver data from cache
 	driverIDs := make([]string, len(nearbyDrivers))
 	for i, loc := range nearbyDrivers {
@@ -119,6 +321,9 @@ func (s *MatchingService) Match(ctx context.Context, req MatchRequest) (*MatchRe
 	// For now, fall back to individual queries for missing drivers
 	dbDrivers := make(map[string]*domain.Driver)
 	for _, id := range missingIDs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		driver, err := s.driverRepo.GetByID(ctx, id)
 		if err != nil {
 			if err == repository.ErrNotFound {
@@ -131,8 +336,12 @@ func (s *MatchingService) Match(ctx context.Context, req MatchRequest) (*MatchRe
 		s.cacheDriverAsync(ctx, driver)
 	}
 
-	// Try each driver in order of proximity.
+	// Try each driver in order of proximity, stopping as soon as the
+	// caller cancels or the request's deadline passes.
 	for _, loc := range nearbyDrivers {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		driverID := loc.DriverID
 
 		// OPTIMIZATION 3: Check cache first, then DB
@@ -140,9 +349,15 @@ func (s *MatchingService) Match(ctx context.Context, req MatchRequest) (*MatchRe
 		if cached, ok := cachedDrivers[driverID]; ok {
 			// Use cached data for quick filtering
 			if cached.Status != string(domain.DriverStatusOnline) {
+				attempt.SkippedOffline++
 				continue
 			}
 			if req.Tier != "" && cached.Tier != string(req.Tier) {
+				attempt.SkippedTier++
+				continue
+			}
+			if !domain.HasCapabilities(cached.Capabilities, ride.Capabilities) {
+				attempt.SkippedCapability++
 				continue
 			}
 			// Cache hit - still need full driver for assignment
@@ -150,17 +365,32 @@ func (s *MatchingService) Match(ctx context.Context, req MatchRequest) (*MatchRe
 		} else if dbDriver, ok := dbDrivers[driverID]; ok {
 			driver = dbDriver
 		} else {
-			// Driver not found in cache or DB
+			// Driver not found in cache or DB: a stale location entry
+			attempt.SkippedStale++
 			continue
 		}
 
 		// Filter by status (double-check for DB drivers).
 		if driver.Status != domain.DriverStatusOnline {
+			attempt.SkippedOffline++
 			continue
 		}
 
 		// Filter by tier if specified.
 		if req.Tier != "" && driver.Tier != req.Tier {
+			attempt.SkippedTier++
+			continue
+		}
+
+		// Skip vehicles lacking a capability the ride requires, e.g. WAV.
+		if !domain.HasCapabilities(driver.Capabilities, ride.Capabilities) {
+			attempt.SkippedCapability++
+			continue
+		}
+
+		// Drivers heading to a destination only take rides going their way.
+		if !s.headsTowardDestination(ctx, loc, ride) {
+			attempt.SkippedDirection++
 			continue
 		}
 
@@ -172,6 +402,7 @@ func (s *MatchingService) Match(ctx context.Context, req MatchRequest) (*MatchRe
 
 		if !locked {
 			// Driver is being assigned to another ride.
+			attempt.SkippedLocked++
 			continue
 		}
 
@@ -179,37 +410,316 @@ func (s *MatchingService) Match(ctx context.Context, req MatchRequest) (*MatchRe
 		// This handles the case where cached status is stale
 		freshDriver, err := s.driverRepo.GetByID(ctx, driverID)
 		if err != nil {
-			_ = s.lockStore.ReleaseDriverLock(ctx, driverID)
+			_ = s.lockStore.ReleaseDriverLock(context.WithoutCancel(ctx), driverID)
 			if err == repository.ErrNotFound {
+				attempt.SkippedStale++
 				continue
 			}
 			return nil, err
 		}
 
 		if freshDriver.Status != domain.DriverStatusOnline {
-			_ = s.lockStore.ReleaseDriverLock(ctx, driverID)
+			attempt.SkippedStale++
+			_ = s.lockStore.ReleaseDriverLock(context.WithoutCancel(ctx), driverID)
 			// Invalidate stale cache
 			s.invalidateDriverCache(ctx, driverID)
 			continue
 		}
 
 		// Attempt atomic assignment.
-		result, err := s.assignDriver(ctx, ride, freshDriver)
+		result, err := s.assignDriver(ctx, ride, freshDriver, false, domain.RideAssignedByMatching)
 		if err != nil {
 			// Release lock on failure.
-			_ = s.lockStore.ReleaseDriverLock(ctx, driverID)
+			_ = s.lockStore.ReleaseDriverLock(context.WithoutCancel(ctx), driverID)
 			return nil, err
 		}
 
 		// OPTIMIZATION 5: Invalidate caches after assignment
 		s.invalidateDriverCache(ctx, driverID)
 		s.invalidateRideCache(ctx, ride.ID)
+		s.clearDestination(ctx, driverID)
 
 		// Success - driver lock will expire via TTL.
 		return result, nil
 	}
 
-	return nil, ErrNoDriverAvailable
+	return nil, s.unmatched(attempt)
+}
+
+// AssignDriver assigns a dispatcher's chosen driver to a ride, skipping the
+// proximity search but none of the safeguards of a match: the ride must be
+// REQUESTED and is locked against concurrent matching, and the driver must
+// be ONLINE, without an active trip, and not locked by another assignment.
+// Like a match, it never assigns a driver excluded from the ride, e.g. one
+// the rider blocked, or one without the ride's tier or vehicle capabilities.
+// The assignment goes through the same transaction as a match, claiming the
+// driver only if still ONLINE, and is recorded as made by ADMIN. A busy
+// driver gets ErrDriverHasActiveTrip, one otherwise unavailable
+// ErrDriverNotOnline, one the ride cannot have ErrDriverNotEligible, and a
+// ride no longer waiting ErrRideNotInRequestedState.
+func (s *MatchingService) AssignDriver(ctx context.Context, rideID, driverID string) (*MatchResult, error) {
+	if rideID == "" {
+		return nil, ErrInvalidRideID
+	}
+	if driverID == "" {
+		return nil, ErrInvalidDriverID
+	}
+
+	if s.cacheStore != nil {
+		locked, err := s.cacheStore.AcquireRideLock(ctx, rideID, rideLockTTL)
+		if err != nil {
+			return nil, err
+		}
+		if !locked {
+			return nil, ErrRideNotInRequestedState
+		}
+		defer s.cacheStore.ReleaseRideLock(context.WithoutCancel(ctx), rideID)
+	}
+
+	ride, err := s.rideRepo.GetByID(ctx, rideID)
+	if err != nil {
+		return nil, err
+	}
+	if !ride.CanTransitionTo(domain.RideStatusAssigned) {
+		return nil, ErrRideNotInRequestedState
+	}
+
+	if s.exclusionStore != nil {
+		excluded, err := s.exclusionStore.ExcludedDrivers(ctx, ride.ID)
+		if err != nil {
+			return nil, err
+		}
+		if slices.Contains(excluded, driverID) {
+			return nil, fmt.Errorf("%w: driver %s is excluded from ride %s", ErrDriverNotEligible, driverID, ride.ID)
+		}
+	}
+
+	locked, err := s.lockStore.AcquireDriverLock(ctx, driverID, driverLockTTL)
+	if err != nil {
+		return nil, err
+	}
+	if !locked {
+		// Another match is assigning the driver.
+		return nil, ErrDriverHasActiveTrip
+	}
+
+	result, err := s.assignLockedDriver(ctx, ride, driverID)
+	if err != nil {
+		_ = s.lockStore.ReleaseDriverLock(context.WithoutCancel(ctx), driverID)
+		return nil, err
+	}
+
+	s.invalidateDriverCache(ctx, driverID)
+	s.invalidateRideCache(ctx, ride.ID)
+	s.clearDestination(ctx, driverID)
+
+	// Like a match's, the driver lock expires via TTL.
+	return result, nil
+}
+
+// assignLockedDriver checks, from the database, that a driver whose lock is
+// held can take the ride, and assigns them.
+func (s *MatchingService) assignLockedDriver(ctx context.Context, ride *domain.Ride, driverID string) (*MatchResult, error) {
+	driver, err := s.driverRepo.GetByID(ctx, driverID)
+	if err != nil {
+		return nil, err
+	}
+	switch driver.Status {
+	case domain.DriverStatusOnline:
+	case domain.DriverStatusOnTrip:
+		return nil, ErrDriverHasActiveTrip
+	default:
+		return nil, ErrDriverNotOnline
+	}
+
+	if ride.Tier != "" && driver.Tier != ride.Tier {
+		return nil, fmt.Errorf("%w: driver %s is %s, ride %s needs %s", ErrDriverNotEligible, driver.ID, driver.Tier, ride.ID, ride.Tier)
+	}
+	if !domain.HasCapabilities(driver.Capabilities, ride.Capabilities) {
+		return nil, fmt.Errorf("%w: driver %s lacks capabilities %v of ride %s", ErrDriverNotEligible, driver.ID, ride.Capabilities, ride.ID)
+	}
+
+	if s.tripRepo != nil {
+		trip, err := s.tripRepo.GetActiveByDriverID(ctx, driverID)
+		if err != nil {
+			return nil, err
+		}
+		if trip != nil {
+			return nil, ErrDriverHasActiveTrip
+		}
+	}
+
+	result, err := s.assignDriver(ctx, ride, driver, true, domain.RideAssignedByAdmin)
+	if errors.Is(err, errDriverTaken) {
+		// The status read above was stale.
+		return nil, ErrDriverHasActiveTrip
+	}
+	return result, err
+}
+
+// matchFromDatabase matches a ride without Redis. ONLINE drivers whose last
+// recorded position lies within the search radius are read from the
+// database, nearest first, and a conditional status update stands in for
+// the driver lock. The recorded position can trail the driver by the
+// location history's flush interval.
+func (s *MatchingService) matchFromDatabase(ctx context.Context, req MatchRequest, ride *domain.Ride, excluded map[string]bool, attempt *domain.MatchAttempt) (*MatchResult, error) {
+	drivers, _, err := s.driverRepo.List(ctx, repository.DriverFilter{
+		Status: domain.DriverStatusOnline,
+		Tier:   req.Tier,
+		Near:   &repository.NearFilter{Lat: req.Lat, Lng: req.Lng, RadiusKm: attempt.RadiusKm},
+		Limit:  s.maxCandidates,
+	})
+	if err != nil {
+		return nil, err
+	}
+	attempt.CandidatesFound = len(drivers)
+
+	for _, driver := range drivers {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if excluded[driver.ID] {
+			attempt.SkippedExcluded++
+			continue
+		}
+		if !domain.HasCapabilities(driver.Capabilities, ride.Capabilities) {
+			attempt.SkippedCapability++
+			continue
+		}
+		result, err := s.assignDriver(ctx, ride, driver, true, domain.RideAssignedByMatching)
+		if errors.Is(err, errDriverTaken) {
+			attempt.SkippedLocked++
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	return nil, s.unmatched(attempt)
+}
+
+// headsTowardDestination reports whether the driver at loc may be offered
+// the ride: always, unless the driver is in destination mode, in which case
+// the ride's bearing from pickup to destination must be within the
+// configured angle of the driver's bearing to their own destination. If the
+// destination cannot be read the driver is treated as not in the mode.
+func (s *MatchingService) headsTowardDestination(ctx context.Context, loc redis.DriverLocation, ride *domain.Ride) bool {
+	if s.destinationStore == nil {
+		return true
+	}
+	destination, err := s.destinationStore.GetDestination(ctx, loc.DriverID)
+	if err != nil {
+		log.Printf("[MATCH] Failed to read destination of driver %s: %v", loc.DriverID, err)
+		return true
+	}
+	if destination == nil {
+		return true
+	}
+
+	rideBearing := domain.BearingDeg(ride.PickupLat, ride.PickupLng, ride.DestinationLat, ride.DestinationLng)
+	driverBearing := domain.BearingDeg(loc.Lat, loc.Lng, destination.Lat, destination.Lng)
+	return domain.BearingDiffDeg(rideBearing, driverBearing) <= s.destinationAngle
+}
+
+// clearDestination takes a newly matched driver out of destination mode.
+func (s *MatchingService) clearDestination(ctx context.Context, driverID string) {
+	if s.destinationStore == nil {
+		return
+	}
+	if err := s.destinationStore.ClearDestination(context.WithoutCancel(ctx), driverID); err != nil {
+		log.Printf("[MATCH] Failed to clear destination of driver %s: %v", driverID, err)
+	}
+}
+
+// excludedDrivers returns the set of drivers the ride must not be matched
+// to: those in the request, which are added to the ride's stored set, and
+// those stored by earlier matches. With Redis unreachable and the degraded
+// fallback enabled, only the request's own are known.
+func (s *MatchingService) excludedDrivers(ctx context.Context, req MatchRequest, attempt *domain.MatchAttempt) (map[string]bool, error) {
+	excluded := make(map[string]bool, len(req.ExcludeDriverIDs))
+	for _, id := range req.ExcludeDriverIDs {
+		excluded[id] = true
+	}
+	if s.exclusionStore == nil || attempt.Degraded {
+		return excluded, nil
+	}
+
+	if err := s.exclusionStore.AddExcludedDrivers(ctx, req.RideID, req.ExcludeDriverIDs, s.exclusionTTL); err != nil {
+		if !s.canDegrade(ctx, req, err) {
+			return nil, err
+		}
+		s.markDegraded(ctx, attempt, err)
+		return excluded, nil
+	}
+
+	stored, err := s.exclusionStore.ExcludedDrivers(ctx, req.RideID)
+	if err != nil {
+		if !s.canDegrade(ctx, req, err) {
+			return nil, err
+		}
+		s.markDegraded(ctx, attempt, err)
+		return excluded, nil
+	}
+	for _, id := range stored {
+		excluded[id] = true
+	}
+	return excluded, nil
+}
+
+// unmatched returns the error for a match that tried every candidate without
+// an assignment. If the search was cut off at the cap, drivers beyond it were
+// never tried, so the match is reported as exhausted rather than as having no
+// driver in range.
+func (s *MatchingService) unmatched(attempt *domain.MatchAttempt) error {
+	if attempt.CandidatesFound >= s.maxCandidates {
+		return ErrMatchingCandidatesExhausted
+	}
+	return ErrNoDriverAvailable
+}
+
+// canDegrade reports whether err allows falling back to database-only
+// matching. The fallback is rolled out per rider by FlagDegradedMatching and
+// follows the configured setting while that flag is undefined.
+func (s *MatchingService) canDegrade(ctx context.Context, req MatchRequest, err error) bool {
+	return redis.IsUnavailable(err) && s.flags.Enabled(ctx, FlagDegradedMatching, req.RiderID, s.degradedFallback)
+}
+
+// markDegraded flags the attempt as running without Redis.
+func (s *MatchingService) markDegraded(ctx context.Context, attempt *domain.MatchAttempt, err error) {
+	if attempt.Degraded {
+		return
+	}
+	attempt.Degraded = true
+	log.Printf("[MATCH] Redis unavailable, matching ride %s from the database: %v", attempt.RideID, err)
+	recordMetric(ctx, metricMatchingDegraded, 1)
+}
+
+// recordAttempt completes and persists a match attempt. Persistence failures
+// are logged; they never affect the match itself.
+func (s *MatchingService) recordAttempt(ctx context.Context, attempt *domain.MatchAttempt, start time.Time, result *MatchResult, err error) {
+	if s.attemptRepo == nil {
+		return
+	}
+
+	attempt.Duration = time.Since(start)
+	switch {
+	case err == nil:
+		attempt.Outcome = domain.MatchOutcomeMatched
+		attempt.AssignedDriverID = result.DriverID
+	case errors.Is(err, ErrNoDriverAvailable):
+		attempt.Outcome = domain.MatchOutcomeNoDriver
+	case errors.Is(err, ErrMatchingCandidatesExhausted):
+		attempt.Outcome = domain.MatchOutcomeCandidatesExhausted
+	default:
+		attempt.Outcome = domain.MatchOutcomeFailed
+		attempt.Error = err.Error()
+	}
+
+	if err := s.attemptRepo.Create(ctx, attempt); err != nil {
+		log.Printf("[MATCH] Failed to record match attempt for ride %s: %v", attempt.RideID, err)
+	}
 }
 
 // getDriversBatchOptimized fetches drivers from cache using batch operation.
@@ -227,11 +737,13 @@ func (s *MatchingService) cacheDriverAsync(ctx context.Context, driver *domain.D
 	}
 	go func() {
 		cached := &redis.CachedDriver{
-			ID:     driver.ID,
-			Name:   driver.Name,
-			Phone:  driver.Phone,
-			Status: string(driver.Status),
-			Tier:   string(driver.Tier),
+			ID:           driver.ID,
+			Name:         driver.Name,
+			Phone:        driver.Phone,
+			Status:       string(driver.Status),
+			Tier:         string(driver.Tier),
+			VehiclePlate: driver.VehiclePlate,
+			Capabilities: driver.Capabilities,
 		}
 		_ = s.cacheStore.SetDriver(context.Background(), cached)
 	}()
@@ -240,11 +752,13 @@ func (s *MatchingService) cacheDriverAsync(ctx context.Context, driver *domain.D
 // cachedToDriver converts a cached driver to domain driver.
 func (s *MatchingService) cachedToDriver(cached *redis.CachedDriver) *domain.Driver {
 	return &domain.Driver{
-		ID:     cached.ID,
-		Name:   cached.Name,
-		Phone:  cached.Phone,
-		Status: domain.DriverStatus(cached.Status),
-		Tier:   domain.DriverTier(cached.Tier),
+		ID:           cached.ID,
+		Name:         cached.Name,
+		Phone:        cached.Phone,
+		Status:       domain.DriverStatus(cached.Status),
+		Tier:         domain.DriverTier(cached.Tier),
+		VehiclePlate: cached.VehiclePlate,
+		Capabilities: cached.Capabilities,
 	}
 }
 
@@ -266,8 +780,18 @@ func (s *MatchingService) invalidateRideCache(ctx context.Context, rideID string
 	_ = s.cacheStore.InvalidateRide(ctx, rideID)
 }
 
-// assignDriver atomically assigns a driver to a ride using a transaction.
-func (s *MatchingService) assignDriver(ctx context.Context, ride *domain.Ride, driver *domain.Driver) (*MatchResult, error) {
+// assignDriver atomically assigns a driver to a ride using a transaction,
+// recording who chose the driver. When conditional, the driver is claimed
+// only if still ONLINE, returning errDriverTaken otherwise; callers holding
+// the driver lock need not check. With a per-area limit configured, it
+// first waits for a slot in the ride's pickup area.
+func (s *MatchingService) assignDriver(ctx context.Context, ride *domain.Ride, driver *domain.Driver, conditional bool, by domain.RideAssigner) (*MatchResult, error) {
+	release, err := s.areaLimiter.acquire(ctx, ride.PickupLat, ride.PickupLng)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
@@ -283,17 +807,40 @@ func (s *MatchingService) assignDriver(ctx context.Context, ride *domain.Ride, d
 	txRideRepo := postgres.NewRideRepositoryWithTx(tx)
 	txDriverRepo := postgres.NewDriverRepositoryWithTx(tx)
 
+	// Claim the driver before touching the ride, so a lost race leaves the
+	// ride unchanged for the next candidate.
+	if conditional {
+		var claimed bool
+		if claimed, err = txDriverRepo.TransitionStatus(ctx, driver.ID, domain.DriverStatusOnline, domain.DriverStatusOnTrip); err != nil {
+			return nil, err
+		}
+		if !claimed {
+			err = errDriverTaken
+			return nil, err
+		}
+	}
+
 	// Update ride status and assign driver.
 	ride.Status = domain.RideStatusAssigned
 	ride.AssignedDriverID = driver.ID
+	ride.AssignedBy = by
+	ride.AssignedAt = time.Now()
 
+	if err = ride.Validate(); err != nil {
+		return nil, err
+	}
+
+	// A version conflict means the ride changed (e.g. was cancelled) since it
+	// was read; surface it rather than overwrite that change.
 	if err = txRideRepo.Update(ctx, ride); err != nil {
 		return nil, err
 	}
 
 	// Update driver status to ON_TRIP.
-	if err = txDriverRepo.UpdateStatus(ctx, driver.ID, domain.DriverStatusOnTrip); err != nil {
-		return nil, err
+	if !conditional {
+		if err = txDriverRepo.UpdateStatus(ctx, driver.ID, domain.DriverStatusOnTrip); err != nil {
+			return nil, err
+		}
 	}
 
 	if err = tx.Commit(); err != nil {
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+// Custom metric names reported to New Relic.
+const (
+	metricTripsFlaggedForReview       = "Custom/Trips/FlaggedForReview"
+	metricMatchingDegraded            = "Custom/Matching/Degraded"
+	metricMatchingNoDriver            = "Custom/Matching/NoDriver"
+	metricMatchingCandidatesExhausted = "Custom/Matching/CandidatesExhausted"
+	metricMatchingAreaQueued          = "Custom/Matching/AreaQueued"         // Seconds a match waited for its area's limit
+	metricNotificationsDead           = "Custom/Notifications/Dead"          // Deliveries that ran out of attempts
+	metricFareSharesAbsorbed          = "Custom/Payments/FareSharesAbsorbed" // Split fare shares the ride's rider paid for
+	metricLocationSpeedRejected       = "Custom/Drivers/SpeedRejected"       // Location updates implying an implausible speed
+)
+
+// recordMetric records a custom metric against the New Relic application of
+// the transaction in ctx. It does nothing when the request is not instrumented.
+func recordMetric(ctx context.Context, name string, value float64) {
+	txn := newrelic.FromContext(ctx)
+	if txn == nil {
+		return
+	}
+	if app := txn.Application(); app != nil {
+		app.RecordCustomMetric(name, value)
+	}
+}
@@ -2,11 +2,16 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"ride/internal/domain"
+	"ride/internal/money"
+	"ride/internal/redis"
+	"ride/internal/repository"
 )
 
 // NotificationType represents the type of notification.
@@ -20,12 +25,39 @@ const (
 	NotificationTripPaused      NotificationType = "TRIP_PAUSED"
 	NotificationTripResumed     NotificationType = "TRIP_RESUMED"
 	NotificationTripEnded       NotificationType = "TRIP_ENDED"
+	NotificationTripAutoEnded   NotificationType = "TRIP_AUTO_ENDED"
 	NotificationPaymentSuccess  NotificationType = "PAYMENT_SUCCESS"
 	NotificationPaymentFailed   NotificationType = "PAYMENT_FAILED"
 	NotificationRideCancelled   NotificationType = "RIDE_CANCELLED"
 	NotificationReceiptReady    NotificationType = "RECEIPT_READY"
+	NotificationDriverETA       NotificationType = "DRIVER_ETA_UPDATED"
+	NotificationCampaignBonus   NotificationType = "CAMPAIGN_BONUS_EARNED"
+	NotificationRouteDeviation  NotificationType = "ROUTE_DEVIATION"
+	NotificationFareReview      NotificationType = "FARE_REVIEW_REQUIRED"
+	NotificationTripDisputed    NotificationType = "TRIP_DISPUTED"
+	NotificationWeeklySummary   NotificationType = "WEEKLY_SUMMARY"
+	NotificationDeadLetters     NotificationType = "NOTIFICATIONS_DEAD_LETTERED"
 )
 
+// notificationTypes lists every notification type, in display order.
+var notificationTypes = []NotificationType{
+	NotificationRideRequested, NotificationDriverAssigned, NotificationDriverArrived, NotificationDriverETA,
+	NotificationTripStarted, NotificationTripPaused, NotificationTripResumed, NotificationTripEnded,
+	NotificationTripAutoEnded, NotificationRouteDeviation, NotificationFareReview, NotificationTripDisputed,
+	NotificationPaymentSuccess, NotificationPaymentFailed, NotificationReceiptReady, NotificationRideCancelled,
+	NotificationCampaignBonus, NotificationWeeklySummary, NotificationDeadLetters,
+}
+
+// isValid reports whether t is a known notification type.
+func (t NotificationType) isValid() bool {
+	for _, known := range notificationTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
 // Notification represents a notification to be sent.
 type Notification struct {
 	ID          string
@@ -37,18 +69,57 @@ type Notification struct {
 	CreatedAt   time.Time
 }
 
+// Deep-link templates used when none are configured.
+const (
+	defaultReceiptLink    = "ride://receipts/{receipt_id}"
+	defaultRateDriverLink = "ride://trips/{trip_id}/rate"
+)
+
+// DeepLinks holds the app URL templates embedded in notifications.
+// "{trip_id}" and "{receipt_id}" are replaced with the notification's IDs.
+type DeepLinks struct {
+	Receipt    string
+	RateDriver string
+}
+
+// notificationReplayLimit caps how many missed events are replayed on reconnect.
+const notificationReplayLimit = 500
+
 // NotificationService handles notification delivery.
 type NotificationService struct {
-	// In a real system, this would have:
+	// In a real system, this would also have:
 	// - Push notification client (FCM, APNS)
 	// - SMS client (Twilio)
 	// - Email client (SendGrid)
-	// - WebSocket connections for real-time
+	outbox      repository.NotificationRepository           // Optional: durable record for replay
+	broker      redis.NotificationBrokerInterface           // Optional: live fan-out to SSE streams
+	links       DeepLinks
+	preferences repository.NotificationPreferenceRepository // Optional: nil sends every type on every channel
+	channels    []NotificationChannelSender                 // Channels besides push, e.g. email
+	money       *money.Formatter                            // Nil formats US dollars
+	deliveries  repository.NotificationDeliveryRepository   // Optional: queues channel sends for the NotificationDispatcher
 }
 
 // NewNotificationService creates a new NotificationService.
-func NewNotificationService() *NotificationService {
-	return &NotificationService{}
+// outbox and broker may be nil, in which case notifications are only logged.
+// Empty deep-link templates fall back to the ride:// defaults.
+func NewNotificationService(outbox repository.NotificationRepository, broker redis.NotificationBrokerInterface, links DeepLinks, preferences repository.NotificationPreferenceRepository, channels []NotificationChannelSender, formatter *money.Formatter, deliveries repository.NotificationDeliveryRepository) *NotificationService {
+	if links.Receipt == "" {
+		links.Receipt = defaultReceiptLink
+	}
+	if links.RateDriver == "" {
+		links.RateDriver = defaultRateDriverLink
+	}
+
+	return &NotificationService{
+		outbox:      outbox,
+		broker:      broker,
+		links:       links,
+		preferences: preferences,
+		channels:    channels,
+		money:       formatter,
+		deliveries:  deliveries,
+	}
 }
 
 // NotifyRideRequested notifies nearby drivers about a new ride request.
@@ -90,6 +161,176 @@ func (s *NotificationService) NotifyDriverAssigned(ctx context.Context, ride *do
 	return s.send(ctx, notification)
 }
 
+// NotifyDriverETA pushes the driver's refreshed pickup ETA to the rider.
+func (s *NotificationService) NotifyDriverETA(ctx context.Context, ride *domain.Ride, eta *DriverETA) error {
+	notification := Notification{
+		Type:        NotificationDriverETA,
+		RecipientID: ride.RiderID,
+		Title:       "Driver On The Way",
+		Message:     fmt.Sprintf("Your driver is %.1f km away (about %d min)", eta.DistanceKm, eta.ETAMinutes()),
+		Data: map[string]interface{}{
+			"ride_id":     ride.ID,
+			"driver_id":   eta.DriverID,
+			"distance_km": eta.DistanceKm,
+			"eta_seconds": eta.ETASeconds,
+		},
+		CreatedAt: time.Now(),
+	}
+	return s.send(ctx, notification)
+}
+
+// NotifyDriverArrived tells the rider their driver is waiting at pickup.
+func (s *NotificationService) NotifyDriverArrived(ctx context.Context, ride *domain.Ride) error {
+	notification := Notification{
+		Type:        NotificationDriverArrived,
+		RecipientID: ride.RiderID,
+		Title:       "Driver Arrived",
+		Message:     "Your driver has arrived at the pickup point",
+		Data: map[string]interface{}{
+			"ride_id":    ride.ID,
+			"driver_id":  ride.AssignedDriverID,
+			"arrived_at": ride.ArrivedAt,
+		},
+		CreatedAt: time.Now(),
+	}
+	return s.send(ctx, notification)
+}
+
+// NotifyCampaignBonus notifies the driver that a campaign bonus was credited.
+func (s *NotificationService) NotifyCampaignBonus(ctx context.Context, campaign *domain.Campaign, driverID string) error {
+	notification := Notification{
+		Type:        NotificationCampaignBonus,
+		RecipientID: driverID,
+		Title:       "Bonus Earned",
+		Message:     fmt.Sprintf("You completed %s and earned a %s bonus", campaign.Name, s.money.Format(campaign.RewardAmount)),
+		Data: map[string]interface{}{
+			"campaign_id":   campaign.ID,
+			"reward_amount": campaign.RewardAmount,
+		},
+		CreatedAt: time.Now(),
+	}
+	return s.send(ctx, notification)
+}
+
+// NotifyWeeklySummary sends the driver their summary of the week.
+func (s *NotificationService) NotifyWeeklySummary(ctx context.Context, summary *domain.DriverWeeklySummary) error {
+	message := fmt.Sprintf("You completed %d trips and earned %s", summary.Trips, s.money.Format(summary.Earnings))
+	if summary.City != "" {
+		message += fmt.Sprintf(", ranked #%d in %s", summary.Rank, summary.City)
+	} else {
+		message += fmt.Sprintf(", ranked #%d of %d drivers", summary.Rank, summary.RankedDrivers)
+	}
+	notification := Notification{
+		Type:        NotificationWeeklySummary,
+		RecipientID: summary.DriverID,
+		Title:       "Your Week",
+		Message:     message,
+		Data: map[string]interface{}{
+			"week_start":     summary.WeekStart.Format("2006-01-02"),
+			"trips":          summary.Trips,
+			"earnings":       summary.Earnings,
+			"online_hours":   summary.OnlineHours,
+			"rank":           summary.Rank,
+			"ranked_drivers": summary.RankedDrivers,
+		},
+		CreatedAt: time.Now(),
+	}
+	return s.send(ctx, notification)
+}
+
+// OpsRecipientID is the recipient ID the safety operations team subscribes to.
+const OpsRecipientID = "ops"
+
+// NotifyRouteDeviation alerts the rider and the safety operations team that
+// the driver has left the expected route.
+func (s *NotificationService) NotifyRouteDeviation(ctx context.Context, alert *domain.RouteDeviationAlert, riderID string) error {
+	data := map[string]interface{}{
+		"alert_id":    alert.ID,
+		"trip_id":     alert.TripID,
+		"ride_id":     alert.RideID,
+		"driver_id":   alert.DriverID,
+		"lat":         alert.Lat,
+		"lng":         alert.Lng,
+		"distance_km": alert.DistanceKm,
+	}
+
+	riderNotification := Notification{
+		Type:        NotificationRouteDeviation,
+		RecipientID: riderID,
+		Title:       "Route Check",
+		Message:     "Your driver appears to have left the expected route. Tap if you need help.",
+		Data:        data,
+		CreatedAt:   time.Now(),
+	}
+	if err := s.send(ctx, riderNotification); err != nil {
+		return err
+	}
+
+	opsNotification := Notification{
+		Type:        NotificationRouteDeviation,
+		RecipientID: OpsRecipientID,
+		Title:       "Route Deviation",
+		Message:     fmt.Sprintf("Trip %s is %.1f km off route", alert.TripID, alert.DistanceKm),
+		Data:        data,
+		CreatedAt:   time.Now(),
+	}
+	return s.send(ctx, opsNotification)
+}
+
+// NotifyDeadLetters tells the operations team that count notification
+// deliveries have run out of attempts and are waiting to be requeued.
+func (s *NotificationService) NotifyDeadLetters(ctx context.Context, count int) error {
+	notification := Notification{
+		Type:        NotificationDeadLetters,
+		RecipientID: OpsRecipientID,
+		Title:       "Notifications Failing",
+		Message:     fmt.Sprintf("%d notifications could not be delivered and are waiting to be requeued", count),
+		Data: map[string]interface{}{
+			"dead_count": count,
+		},
+		CreatedAt: time.Now(),
+	}
+	return s.send(ctx, notification)
+}
+
+// NotifyFareReview asks the operations team to review a trip whose fare was
+// capped and whose payment is on hold.
+func (s *NotificationService) NotifyFareReview(ctx context.Context, trip *domain.Trip) error {
+	notification := Notification{
+		Type:        NotificationFareReview,
+		RecipientID: OpsRecipientID,
+		Title:       "Fare Review Required",
+		Message:     fmt.Sprintf("Trip %s computed a %s fare, capped at %s; payment is on hold", trip.ID, s.money.Format(trip.UncappedFare), s.money.Format(trip.Fare)),
+		Data: map[string]interface{}{
+			"trip_id":       trip.ID,
+			"driver_id":     trip.DriverID,
+			"fare":          trip.Fare,
+			"uncapped_fare": trip.UncappedFare,
+		},
+		CreatedAt: time.Now(),
+	}
+	return s.send(ctx, notification)
+}
+
+// NotifyTripDisputed asks the operations team to resolve a trip its rider
+// disputed, whose payment is on hold until they do.
+func (s *NotificationService) NotifyTripDisputed(ctx context.Context, trip *domain.Trip) error {
+	notification := Notification{
+		Type:        NotificationTripDisputed,
+		RecipientID: OpsRecipientID,
+		Title:       "Trip Disputed",
+		Message:     fmt.Sprintf("The rider disputes trip %s (%s): %s; payment is on hold", trip.ID, s.money.Format(trip.Fare), trip.DisputeReason),
+		Data: map[string]interface{}{
+			"trip_id":        trip.ID,
+			"driver_id":      trip.DriverID,
+			"fare":           trip.Fare,
+			"dispute_reason": trip.DisputeReason,
+		},
+		CreatedAt: time.Now(),
+	}
+	return s.send(ctx, notification)
+}
+
 // NotifyTripStarted notifies the rider that the trip has started.
 func (s *NotificationService) NotifyTripStarted(ctx context.Context, trip *domain.Trip, riderID string) error {
 	notification := Notification{
@@ -106,22 +347,37 @@ func (s *NotificationService) NotifyTripStarted(ctx context.Context, trip *domai
 	return s.send(ctx, notification)
 }
 
-// NotifyTripPaused notifies the rider that the trip has been paused.
+// NotifyTripPaused notifies the rider that the trip has been paused, with the
+// driver's reason when one was given.
 func (s *NotificationService) NotifyTripPaused(ctx context.Context, trip *domain.Trip, riderID string) error {
+	message := "Your trip has been paused by the driver."
+	if text, ok := pauseReasonText[trip.PauseReason]; ok {
+		message = fmt.Sprintf("Your trip has been paused by the driver (%s).", text)
+	}
+
 	notification := Notification{
 		Type:        NotificationTripPaused,
 		RecipientID: riderID,
 		Title:       "Trip Paused",
-		Message:     "Your trip has been paused by the driver.",
+		Message:     message,
 		Data: map[string]interface{}{
 			"trip_id":   trip.ID,
 			"paused_at": trip.PausedAt,
+			"reason":    trip.PauseReason,
 		},
 		CreatedAt: time.Now(),
 	}
 	return s.send(ctx, notification)
 }
 
+// pauseReasonText is the rider-facing wording for each pause reason.
+var pauseReasonText = map[domain.PauseReason]string{
+	domain.PauseReasonTraffic:      "traffic",
+	domain.PauseReasonFuel:         "refuelling",
+	domain.PauseReasonRiderRequest: "at your request",
+	domain.PauseReasonOther:        "other reason",
+}
+
 // NotifyTripResumed notifies the rider that the trip has resumed.
 func (s *NotificationService) NotifyTripResumed(ctx context.Context, trip *domain.Trip, riderID string) error {
 	notification := Notification{
@@ -137,30 +393,99 @@ func (s *NotificationService) NotifyTripResumed(ctx context.Context, trip *domai
 	return s.send(ctx, notification)
 }
 
-// NotifyTripEnded notifies the rider that the trip has ended.
-func (s *NotificationService) NotifyTripEnded(ctx context.Context, trip *domain.Trip, riderID string, fare float64) error {
+// TripSummary is the completion summary sent to the rider when a trip ends.
+// Payment and receipt fields are empty when the fare is held for review or
+// could not be settled.
+type TripSummary struct {
+	TripID          string
+	RideID          string
+	RiderID         string
+	DriverID        string
+	Duration        time.Duration // Excludes paused time
+	DistanceKm      float64       // Straight-line estimate from pickup to destination
+	Fare            float64
+	SurgeMultiplier float64
+	PaymentMethod   domain.PaymentMethod
+	PaymentStatus   domain.PaymentStatus // Empty without a payment
+	ReceiptID       string               // Empty without a receipt
+	EndedAt         time.Time
+}
+
+// NotifyTripEnded sends the rider the trip's completion summary, with deep
+// links to the receipt and to rate the driver.
+func (s *NotificationService) NotifyTripEnded(ctx context.Context, summary TripSummary) error {
 	notification := Notification{
 		Type:        NotificationTripEnded,
-		RecipientID: riderID,
+		RecipientID: summary.RiderID,
 		Title:       "Trip Completed",
-		Message:     fmt.Sprintf("Your trip has ended. Total fare: $%.2f", fare),
-		Data: map[string]interface{}{
-			"trip_id":  trip.ID,
-			"fare":     fare,
-			"ended_at": trip.EndedAt,
-		},
-		CreatedAt: time.Now(),
+		Message:     fmt.Sprintf("Your trip has ended. Total fare: %s. How was your driver?", s.money.Format(summary.Fare)),
+		Data:        s.tripSummaryData(summary),
+		CreatedAt:   time.Now(),
 	}
 	return s.send(ctx, notification)
 }
 
+// NotifyTripAutoEnded tells the rider and the driver that a trip left
+// running past the maximum duration was ended automatically, and that the
+// fare only covers that duration.
+func (s *NotificationService) NotifyTripAutoEnded(ctx context.Context, trip *domain.Trip, riderID string, maxDuration time.Duration) error {
+	message := fmt.Sprintf("Your trip ran longer than %s and was ended automatically. The fare of %s covers the first %s only.", maxDuration, s.money.Format(trip.Fare), maxDuration)
+	data := map[string]interface{}{
+		"trip_id":  trip.ID,
+		"ended_at": trip.EndedAt,
+		"fare":     trip.Fare,
+	}
+
+	for _, recipientID := range []string{riderID, trip.DriverID} {
+		notification := Notification{
+			Type:        NotificationTripAutoEnded,
+			RecipientID: recipientID,
+			Title:       "Trip Ended Automatically",
+			Message:     message,
+			Data:        data,
+			CreatedAt:   time.Now(),
+		}
+		s.send(ctx, notification)
+	}
+	return nil
+}
+
+// tripSummaryData builds the TRIP_ENDED payload. Payment and receipt keys are
+// left out when the summary has none.
+func (s *NotificationService) tripSummaryData(summary TripSummary) map[string]interface{} {
+	data := map[string]interface{}{
+		"trip_id":          summary.TripID,
+		"ride_id":          summary.RideID,
+		"driver_id":        summary.DriverID,
+		"fare":             summary.Fare,
+		"ended_at":         summary.EndedAt,
+		"duration_seconds": int64(summary.Duration.Seconds()),
+		"distance_km":      summary.DistanceKm,
+		"surge_multiplier": summary.SurgeMultiplier,
+		"payment_method":   summary.PaymentMethod,
+		"rate_driver_url":  s.renderLink(s.links.RateDriver, summary),
+	}
+	if summary.PaymentStatus != "" {
+		data["payment_status"] = summary.PaymentStatus
+	}
+	if summary.ReceiptID != "" {
+		data["receipt_id"] = summary.ReceiptID
+		data["receipt_url"] = s.renderLink(s.links.Receipt, summary)
+	}
+	return data
+}
+
+func (s *NotificationService) renderLink(template string, summary TripSummary) string {
+	return strings.NewReplacer("{trip_id}", summary.TripID, "{receipt_id}", summary.ReceiptID).Replace(template)
+}
+
 // NotifyPaymentSuccess notifies the rider of successful payment.
 func (s *NotificationService) NotifyPaymentSuccess(ctx context.Context, payment *domain.Payment, riderID string) error {
 	notification := Notification{
 		Type:        NotificationPaymentSuccess,
 		RecipientID: riderID,
 		Title:       "Payment Successful",
-		Message:     fmt.Sprintf("Payment of $%.2f was successful", payment.Amount),
+		Message:     fmt.Sprintf("Payment of %s was successful", s.money.Format(payment.Amount)),
 		Data: map[string]interface{}{
 			"payment_id": payment.ID,
 			"amount":     payment.Amount,
@@ -176,10 +501,11 @@ func (s *NotificationService) NotifyPaymentFailed(ctx context.Context, payment *
 		Type:        NotificationPaymentFailed,
 		RecipientID: riderID,
 		Title:       "Payment Failed",
-		Message:     fmt.Sprintf("Payment of $%.2f failed. Please try again.", payment.Amount),
+		Message:     fmt.Sprintf("Payment of %s failed. Please try again.", s.money.Format(payment.Amount)),
 		Data: map[string]interface{}{
-			"payment_id": payment.ID,
-			"amount":     payment.Amount,
+			"payment_id":     payment.ID,
+			"amount":         payment.Amount,
+			"failure_reason": payment.FailureReason,
 		},
 		CreatedAt: time.Now(),
 	}
@@ -225,7 +551,7 @@ func (s *NotificationService) NotifyReceiptReady(ctx context.Context, receipt *d
 		Type:        NotificationReceiptReady,
 		RecipientID: receipt.RiderID,
 		Title:       "Receipt Ready",
-		Message:     fmt.Sprintf("Your receipt for $%.2f is ready", receipt.TotalFare),
+		Message:     fmt.Sprintf("Your receipt for %s is ready", s.money.Format(receipt.TotalFare)),
 		Data: map[string]interface{}{
 			"receipt_id": receipt.ID,
 			"trip_id":    receipt.TripID,
@@ -236,17 +562,232 @@ func (s *NotificationService) NotifyReceiptReady(ctx context.Context, receipt *d
 	return s.send(ctx, notification)
 }
 
-// send delivers a notification (mock implementation).
+// send delivers a notification: it is logged, then, on each channel the
+// recipient has not turned off for its type, recorded in the outbox and
+// published to their live stream (push) and handed to the other channels,
+// or queued for the NotificationDispatcher to send when deliveries are
+// configured. Delivery failures are logged and never fail the calling flow.
 func (s *NotificationService) send(ctx context.Context, notification Notification) error {
-	// In a real implementation, this would:
-	// 1. Store notification in database
-	// 2. Send push notification via FCM/APNS
-	// 3. Send SMS if enabled
-	// 4. Send email if enabled
-	// 5. Broadcast via WebSocket for real-time updates
-
 	log.Printf("[NOTIFICATION] Type=%s, Recipient=%s, Title=%s, Message=%s",
 		notification.Type, notification.RecipientID, notification.Title, notification.Message)
 
+	muted := s.mutedChannels(ctx, notification)
+	for _, channel := range s.channels {
+		if muted[channel.Channel()] {
+			continue
+		}
+		if s.deliveries != nil {
+			s.queueDelivery(ctx, channel.Channel(), notification)
+			continue
+		}
+		if err := channel.Send(ctx, notification); err != nil {
+			log.Printf("[NOTIFICATION] failed to send %s over %s: %v", notification.Type, channel.Channel(), err)
+		}
+	}
+	if muted[domain.NotificationChannelPush] {
+		return nil
+	}
+
+	event := &domain.NotificationEvent{
+		RecipientID: notification.RecipientID,
+		Type:        string(notification.Type),
+		Title:       notification.Title,
+		Message:     notification.Message,
+		Data:        notification.Data,
+		CreatedAt:   notification.CreatedAt,
+	}
+
+	if s.outbox != nil {
+		if err := s.outbox.Append(ctx, event); err != nil {
+			log.Printf("[NOTIFICATION] failed to record outbox entry: %v", err)
+		}
+	}
+
+	if s.broker != nil {
+		payload, err := json.Marshal(event)
+		if err == nil {
+			err = s.broker.Publish(ctx, event.RecipientID, payload)
+		}
+		if err != nil {
+			log.Printf("[NOTIFICATION] failed to publish to stream: %v", err)
+		}
+	}
+
 	return nil
 }
+
+// queueDelivery queues the notification for the dispatcher to send over
+// the channel, due now.
+func (s *NotificationService) queueDelivery(ctx context.Context, channel domain.NotificationChannel, notification Notification) {
+	now := time.Now()
+	delivery := &domain.NotificationDelivery{
+		Channel:       channel,
+		RecipientID:   notification.RecipientID,
+		Type:          string(notification.Type),
+		Title:         notification.Title,
+		Message:       notification.Message,
+		Data:          notification.Data,
+		Status:        domain.NotificationDeliveryPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+	if err := s.deliveries.Create(ctx, delivery); err != nil {
+		log.Printf("[NOTIFICATION] failed to queue %s over %s: %v", notification.Type, channel, err)
+	}
+}
+
+// mutedChannels returns the channels the recipient turned off for the
+// notification's type. If preferences cannot be read, nothing is muted.
+func (s *NotificationService) mutedChannels(ctx context.Context, notification Notification) map[domain.NotificationChannel]bool {
+	if s.preferences == nil {
+		return nil
+	}
+
+	preferences, err := s.preferences.ListByRecipient(ctx, notification.RecipientID)
+	if err != nil {
+		log.Printf("[NOTIFICATION] failed to load preferences for %s, sending on every channel: %v", notification.RecipientID, err)
+		return nil
+	}
+
+	muted := make(map[domain.NotificationChannel]bool)
+	for _, p := range preferences {
+		if p.Type == string(notification.Type) && !p.Enabled {
+			muted[p.Channel] = true
+		}
+	}
+	return muted
+}
+
+// NotificationPreferences maps each notification type to whether it is sent
+// on each channel.
+type NotificationPreferences map[NotificationType]map[domain.NotificationChannel]bool
+
+// GetPreferences returns whether each notification type is sent to the
+// recipient on each channel. Everything is on until turned off.
+func (s *NotificationService) GetPreferences(ctx context.Context, recipientID string) (NotificationPreferences, error) {
+	if recipientID == "" {
+		return nil, ErrInvalidRiderID
+	}
+	if s.preferences == nil {
+		return nil, ErrNotificationPreferencesUnavailable
+	}
+
+	stored, err := s.preferences.ListByRecipient(ctx, recipientID)
+	if err != nil {
+		return nil, err
+	}
+
+	preferences := make(NotificationPreferences, len(notificationTypes))
+	for _, t := range notificationTypes {
+		preferences[t] = make(map[domain.NotificationChannel]bool, len(domain.NotificationChannels))
+		for _, channel := range domain.NotificationChannels {
+			preferences[t][channel] = true
+		}
+	}
+	for _, p := range stored {
+		if channels, ok := preferences[NotificationType(p.Type)]; ok && p.Channel.IsValid() {
+			channels[p.Channel] = p.Enabled
+		}
+	}
+	return preferences, nil
+}
+
+// UpdatePreferences turns the given types on or off on the given channels for
+// the recipient, leaving the rest as they were, and returns the result.
+func (s *NotificationService) UpdatePreferences(ctx context.Context, recipientID string, changes NotificationPreferences) (NotificationPreferences, error) {
+	if recipientID == "" {
+		return nil, ErrInvalidRiderID
+	}
+	if s.preferences == nil {
+		return nil, ErrNotificationPreferencesUnavailable
+	}
+
+	now := time.Now()
+	var updates []*domain.NotificationPreference
+	for t, channels := range changes {
+		if !t.isValid() {
+			return nil, fmt.Errorf("%w: unknown type %q", ErrInvalidNotificationPreference, t)
+		}
+		for channel, enabled := range channels {
+			if !channel.IsValid() {
+				return nil, fmt.Errorf("%w: unknown channel %q", ErrInvalidNotificationPreference, channel)
+			}
+			updates = append(updates, &domain.NotificationPreference{
+				RecipientID: recipientID,
+				Type:        string(t),
+				Channel:     channel,
+				Enabled:     enabled,
+				UpdatedAt:   now,
+			})
+		}
+	}
+
+	if err := s.preferences.Upsert(ctx, updates); err != nil {
+		return nil, err
+	}
+	return s.GetPreferences(ctx, recipientID)
+}
+
+// NotificationSubscription is a recipient's live notification stream.
+type NotificationSubscription struct {
+	Replay []*domain.NotificationEvent      // Missed events since the requested ID, oldest first
+	Events <-chan *domain.NotificationEvent // Live events; closed when the subscription ends
+	Close  func()
+}
+
+// Subscribe opens a live stream for the recipient. If lastEventID is positive,
+// events recorded after it are returned for replay. Live events already covered
+// by the replay are filtered out.
+func (s *NotificationService) Subscribe(ctx context.Context, recipientID string, lastEventID int64) (*NotificationSubscription, error) {
+	if recipientID == "" {
+		return nil, ErrInvalidRiderID
+	}
+	if s.broker == nil {
+		return nil, ErrNotificationStreamUnavailable
+	}
+
+	// Subscribe before reading the outbox so nothing falls between the two.
+	payloads, cancel, err := s.broker.Subscribe(ctx, recipientID)
+	if err != nil {
+		return nil, err
+	}
+
+	var replay []*domain.NotificationEvent
+	if lastEventID > 0 && s.outbox != nil {
+		replay, err = s.outbox.ListSince(ctx, recipientID, lastEventID, notificationReplayLimit)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+	}
+
+	seen := lastEventID
+	if n := len(replay); n > 0 {
+		seen = replay[n-1].ID
+	}
+
+	events := make(chan *domain.NotificationEvent)
+	go func() {
+		defer close(events)
+		for payload := range payloads {
+			var event domain.NotificationEvent
+			if err := json.Unmarshal(payload, &event); err != nil {
+				continue
+			}
+			if event.ID != 0 && event.ID <= seen {
+				continue
+			}
+			select {
+			case events <- &event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return &NotificationSubscription{
+		Replay: replay,
+		Events: events,
+		Close:  cancel,
+	}, nil
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"ride/internal/domain"
+	"ride/internal/repository"
+)
+
+// NotificationChannelSender delivers notifications over a channel besides
+// push, such as email or SMS.
+type NotificationChannelSender interface {
+	// Channel returns the channel the sender delivers over.
+	Channel() domain.NotificationChannel
+
+	// Send delivers the notification to its recipient.
+	Send(ctx context.Context, notification Notification) error
+}
+
+// EmailNotificationChannel emails notifications to the recipient, rider or
+// driver, at their address on file. Recipients without a verified address
+// are skipped.
+type EmailNotificationChannel struct {
+	userRepo   repository.UserRepository
+	driverRepo repository.DriverRepository
+	sender     EmailSender
+}
+
+// NewEmailNotificationChannel creates a new EmailNotificationChannel.
+func NewEmailNotificationChannel(userRepo repository.UserRepository, driverRepo repository.DriverRepository, sender EmailSender) *EmailNotificationChannel {
+	return &EmailNotificationChannel{
+		userRepo:   userRepo,
+		driverRepo: driverRepo,
+		sender:     sender,
+	}
+}
+
+// Channel returns domain.NotificationChannelEmail.
+func (c *EmailNotificationChannel) Channel() domain.NotificationChannel {
+	return domain.NotificationChannelEmail
+}
+
+// Send emails the notification's title and message to the recipient.
+func (c *EmailNotificationChannel) Send(ctx context.Context, notification Notification) error {
+	email, err := c.verifiedEmail(ctx, notification.RecipientID)
+	if err != nil || email == "" {
+		return err
+	}
+	return c.sender.Send(ctx, email, notification.Title, notification.Message)
+}
+
+// verifiedEmail returns the recipient's verified email address, looking them
+// up as a rider, then as a driver. It returns "" if they have none.
+func (c *EmailNotificationChannel) verifiedEmail(ctx context.Context, recipientID string) (string, error) {
+	user, err := c.userRepo.GetByID(ctx, recipientID)
+	if err == nil {
+		if user.EmailVerified {
+			return user.Email, nil
+		}
+		return "", nil
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		return "", err
+	}
+
+	driver, err := c.driverRepo.GetByID(ctx, recipientID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if driver.EmailVerified {
+		return driver.Email, nil
+	}
+	return "", nil
+}
+
+// Ensure EmailNotificationChannel implements NotificationChannelSender.
+var _ NotificationChannelSender = (*EmailNotificationChannel)(nil)
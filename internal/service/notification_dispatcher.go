@@ -0,0 +1,273 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+
+	"ride/internal/domain"
+	"ride/internal/repository"
+)
+
+const (
+	defaultNotificationMaxAttempts    = 5                // Used when the configured maximum is not positive
+	defaultNotificationRetryBackoff   = 30 * time.Second // Used when the configured backoff is not positive
+	defaultNotificationMaxBackoff     = time.Hour        // Used when the configured cap is below the backoff
+	defaultNotificationDispatchPeriod = 5 * time.Second  // Used when the configured interval is not positive
+	defaultNotificationDispatchBatch  = 100              // Used when the configured batch size is not positive
+
+	// notificationDispatchLease hides claimed deliveries from other
+	// dispatchers while they are sent; one that crashes mid-send is retried
+	// after it.
+	notificationDispatchLease = 5 * time.Minute
+
+	// deadNotificationListLimit caps how many dead deliveries are listed.
+	deadNotificationListLimit = 200
+)
+
+// NotificationDispatcher sends queued notification deliveries over their
+// channels. A failed delivery is retried with exponential backoff; after the
+// maximum attempts it is DEAD until an admin requeues it, and the operations
+// team is told once the number of dead deliveries reaches a threshold.
+type NotificationDispatcher struct {
+	deliveries     repository.NotificationDeliveryRepository
+	channels       map[domain.NotificationChannel]NotificationChannelSender
+	notifications  *NotificationService // Optional: without it no alert is sent
+	maxAttempts    int
+	retryBackoff   time.Duration
+	maxBackoff     time.Duration
+	alertThreshold int // Dead deliveries that trigger an alert; 0 disables it
+	now            func() time.Time
+
+	mu      sync.Mutex
+	alerted bool // Set while the dead count is at or above the threshold
+}
+
+// NewNotificationDispatcher creates a new NotificationDispatcher. A nil now
+// means time.Now.
+func NewNotificationDispatcher(deliveries repository.NotificationDeliveryRepository, channels []NotificationChannelSender, notifications *NotificationService, maxAttempts int, retryBackoff, maxBackoff time.Duration, alertThreshold int, now func() time.Time) *NotificationDispatcher {
+	if maxAttempts <= 0 {
+		maxAttempts = defaultNotificationMaxAttempts
+	}
+	if retryBackoff <= 0 {
+		retryBackoff = defaultNotificationRetryBackoff
+	}
+	if maxBackoff < retryBackoff {
+		maxBackoff = max(defaultNotificationMaxBackoff, retryBackoff)
+	}
+	if now == nil {
+		now = time.Now
+	}
+
+	byChannel := make(map[domain.NotificationChannel]NotificationChannelSender, len(channels))
+	for _, c := range channels {
+		byChannel[c.Channel()] = c
+	}
+	return &NotificationDispatcher{
+		deliveries:     deliveries,
+		channels:       byChannel,
+		notifications:  notifications,
+		maxAttempts:    maxAttempts,
+		retryBackoff:   retryBackoff,
+		maxBackoff:     maxBackoff,
+		alertThreshold: alertThreshold,
+		now:            now,
+	}
+}
+
+// DispatchResult counts what happened to the deliveries of one pass.
+type DispatchResult struct {
+	Sent    int
+	Retried int // Failed and scheduled for another attempt
+	Dead    int // Failed for the last time
+}
+
+// DispatchDue sends up to limit deliveries that are due.
+func (d *NotificationDispatcher) DispatchDue(ctx context.Context, limit int) (DispatchResult, error) {
+	var result DispatchResult
+
+	due, err := d.deliveries.ClaimDue(ctx, d.now(), notificationDispatchLease, limit)
+	if err != nil {
+		return result, err
+	}
+
+	for _, delivery := range due {
+		err := d.deliver(ctx, delivery)
+		now := d.now()
+		delivery.UpdatedAt = now
+		switch {
+		case err == nil:
+			delivery.Status = domain.NotificationDeliverySent
+			result.Sent++
+		case delivery.Attempts+1 >= d.maxAttempts:
+			delivery.Attempts++
+			delivery.Status = domain.NotificationDeliveryDead
+			delivery.LastError = err.Error()
+			result.Dead++
+			log.Printf("[NOTIFICATION] Delivery %d of %s over %s is dead after %d attempts: %v",
+				delivery.ID, delivery.Type, delivery.Channel, delivery.Attempts, err)
+		default:
+			delivery.Attempts++
+			delivery.NextAttemptAt = now.Add(d.backoff(delivery.Attempts))
+			delivery.LastError = err.Error()
+			result.Retried++
+		}
+		if err := d.deliveries.Update(ctx, delivery); err != nil {
+			log.Printf("[NOTIFICATION] Failed to record delivery %d: %v", delivery.ID, err)
+		}
+	}
+
+	if result.Dead > 0 {
+		recordMetric(ctx, metricNotificationsDead, float64(result.Dead))
+		d.checkDeadLetters(ctx)
+	}
+	return result, nil
+}
+
+// deliver sends a delivery over its channel.
+func (d *NotificationDispatcher) deliver(ctx context.Context, delivery *domain.NotificationDelivery) error {
+	sender, ok := d.channels[delivery.Channel]
+	if !ok {
+		return fmt.Errorf("no sender for channel %s", delivery.Channel)
+	}
+	return sender.Send(ctx, Notification{
+		ID:          fmt.Sprintf("%d", delivery.ID),
+		Type:        NotificationType(delivery.Type),
+		RecipientID: delivery.RecipientID,
+		Title:       delivery.Title,
+		Message:     delivery.Message,
+		Data:        delivery.Data,
+		CreatedAt:   delivery.CreatedAt,
+	})
+}
+
+// backoff returns the wait after the given number of failed attempts: the
+// retry backoff, doubled for each attempt after the first, up to the cap.
+func (d *NotificationDispatcher) backoff(attempts int) time.Duration {
+	wait := d.retryBackoff
+	for i := 1; i < attempts && wait < d.maxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, d.maxBackoff)
+}
+
+// checkDeadLetters alerts the operations team when the number of dead
+// deliveries has reached the threshold since the last alert, and re-arms the
+// alert once requeues bring it back under.
+func (d *NotificationDispatcher) checkDeadLetters(ctx context.Context) {
+	if d.alertThreshold <= 0 {
+		return
+	}
+
+	count, err := d.deliveries.CountByStatus(ctx, domain.NotificationDeliveryDead)
+	if err != nil {
+		log.Printf("[NOTIFICATION] Failed to count dead deliveries: %v", err)
+		return
+	}
+
+	d.mu.Lock()
+	crossed := count >= d.alertThreshold && !d.alerted
+	d.alerted = count >= d.alertThreshold
+	d.mu.Unlock()
+
+	if crossed && d.notifications != nil {
+		if err := d.notifications.NotifyDeadLetters(ctx, count); err != nil {
+			log.Printf("[NOTIFICATION] Failed to alert on dead deliveries: %v", err)
+		}
+	}
+}
+
+// ListDead returns the most recently failed dead deliveries.
+func (d *NotificationDispatcher) ListDead(ctx context.Context) ([]*domain.NotificationDelivery, error) {
+	return d.deliveries.ListByStatus(ctx, domain.NotificationDeliveryDead, deadNotificationListLimit)
+}
+
+// Requeue moves a dead delivery back to the queue with a fresh set of
+// attempts, due now. Requeuing a delivery that is already queued or sent
+// changes nothing and returns it as is. Returns repository.ErrNotFound for
+// an unknown delivery.
+func (d *NotificationDispatcher) Requeue(ctx context.Context, id int64) (*domain.NotificationDelivery, error) {
+	delivery, err := d.deliveries.Requeue(ctx, id, d.now())
+	if err != nil {
+		return nil, err
+	}
+	d.checkDeadLetters(ctx)
+	return delivery, nil
+}
+
+// NotificationDispatchWorker runs a NotificationDispatcher on an interval.
+type NotificationDispatchWorker struct {
+	dispatcher *NotificationDispatcher
+	interval   time.Duration
+	batchSize  int
+	nrApp      *newrelic.Application // Optional: passes are reported as background transactions
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewNotificationDispatchWorker creates a NotificationDispatchWorker and
+// starts its background loop. Call Close on shutdown to stop it.
+func NewNotificationDispatchWorker(dispatcher *NotificationDispatcher, interval time.Duration, batchSize int, nrApp *newrelic.Application) *NotificationDispatchWorker {
+	if interval <= 0 {
+		interval = defaultNotificationDispatchPeriod
+	}
+	if batchSize <= 0 {
+		batchSize = defaultNotificationDispatchBatch
+	}
+
+	w := &NotificationDispatchWorker{
+		dispatcher: dispatcher,
+		interval:   interval,
+		batchSize:  batchSize,
+		nrApp:      nrApp,
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Close stops the background loop, waiting for a pass in progress.
+func (w *NotificationDispatchWorker) Close() {
+	w.closeOnce.Do(func() { close(w.stop) })
+	<-w.done
+}
+
+// run dispatches due deliveries on every interval.
+func (w *NotificationDispatchWorker) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.dispatch()
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+// dispatch runs one pass inside a New Relic background transaction, so its
+// metrics are recorded.
+func (w *NotificationDispatchWorker) dispatch() {
+	txn := w.nrApp.StartTransaction("NotificationDispatch")
+	defer txn.End()
+
+	result, err := w.dispatcher.DispatchDue(newrelic.NewContext(context.Background(), txn), w.batchSize)
+	if err != nil {
+		log.Printf("[NOTIFICATION] Failed to dispatch deliveries: %v", err)
+		return
+	}
+	if result.Sent+result.Retried+result.Dead > 0 {
+		log.Printf("[NOTIFICATION] Dispatched deliveries: %d sent, %d to retry, %d dead", result.Sent, result.Retried, result.Dead)
+	}
+}
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/redis"
+	"ride/internal/repository"
+)
+
+const (
+	defaultOpsMapMaxPoints  = 500  // Used when the configured cap is not positive
+	defaultOpsMapFetchLimit = 5000 // Used when the configured limit is not positive
+)
+
+// MapDriver is a driver shown on the ops map.
+type MapDriver struct {
+	DriverID string
+	Lat      float64
+	Lng      float64
+	Heading  float64
+	Status   domain.DriverStatus
+	Tier     domain.DriverTier
+}
+
+// MapRequest is an open ride request shown on the ops map.
+type MapRequest struct {
+	RideID    string
+	Lat       float64
+	Lng       float64
+	Status    domain.RideStatus
+	Tier      domain.DriverTier
+	CreatedAt time.Time
+}
+
+// OpsMap is the live view of drivers and open requests inside a bounding box.
+// Each layer holds at most the configured number of points; when more were
+// found the layer is down-sampled evenly and flagged.
+type OpsMap struct {
+	Drivers         []MapDriver
+	DriverCount     int  // Drivers found in the box, up to the fetch limit
+	DriversSampled  bool // Drivers were down-sampled to the point cap
+	Requests        []MapRequest
+	RequestCount    int  // Open requests found in the box, up to the fetch limit
+	RequestsSampled bool // Requests were down-sampled to the point cap
+}
+
+// OpsMapService builds the admin live ops map.
+type OpsMapService struct {
+	locationStore redis.LocationStoreInterface
+	cacheStore    *redis.CacheStore
+	driverRepo    repository.DriverRepository
+	rideRepo      repository.RideRepository
+	maxPoints     int // Points returned per layer
+	fetchLimit    int // Points read per layer before down-sampling
+}
+
+// NewOpsMapService creates a new OpsMapService. Non-positive maxPoints and
+// fetchLimit use the defaults, and a fetchLimit below the cap is raised to it.
+func NewOpsMapService(
+	locationStore redis.LocationStoreInterface,
+	cacheStore *redis.CacheStore,
+	driverRepo repository.DriverRepository,
+	rideRepo repository.RideRepository,
+	maxPoints int,
+	fetchLimit int,
+) *OpsMapService {
+	if maxPoints <= 0 {
+		maxPoints = defaultOpsMapMaxPoints
+	}
+	if fetchLimit <= 0 {
+		fetchLimit = defaultOpsMapFetchLimit
+	}
+	fetchLimit = max(fetchLimit, maxPoints)
+
+	return &OpsMapService{
+		locationStore: locationStore,
+		cacheStore:    cacheStore,
+		driverRepo:    driverRepo,
+		rideRepo:      rideRepo,
+		maxPoints:     maxPoints,
+		fetchLimit:    fetchLimit,
+	}
+}
+
+// Map returns the drivers and open ride requests inside box. Drivers come from
+// the GEO index, nearest the box centre first, and are enriched with status
+// and tier from the driver cache; offline drivers still in the index are
+// left out. Requests are REQUESTED and ASSIGNED rides by pickup, oldest first.
+func (s *OpsMapService) Map(ctx context.Context, box domain.BoundingBox) (*OpsMap, error) {
+	if !box.IsValid() {
+		return nil, ErrInvalidBoundingBox
+	}
+
+	locations, err := s.locationStore.FindInBox(ctx, box.MinLat, box.MinLng, box.MaxLat, box.MaxLng, s.fetchLimit)
+	if err != nil {
+		return nil, err
+	}
+	rides, err := s.rideRepo.ListOpenInBox(ctx, box, s.fetchLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	opsMap := &OpsMap{
+		DriverCount:     len(locations),
+		DriversSampled:  len(locations) > s.maxPoints,
+		RequestCount:    len(rides),
+		RequestsSampled: len(rides) > s.maxPoints,
+	}
+
+	// Sample before enriching so the cache is only read for points we return.
+	sampled := make([]redis.DriverLocation, 0, min(len(locations), s.maxPoints))
+	for _, i := range sampleIndexes(len(locations), s.maxPoints) {
+		sampled = append(sampled, locations[i])
+	}
+	opsMap.Drivers, err = s.enrichDrivers(ctx, sampled)
+	if err != nil {
+		return nil, err
+	}
+
+	opsMap.Requests = make([]MapRequest, 0, min(len(rides), s.maxPoints))
+	for _, i := range sampleIndexes(len(rides), s.maxPoints) {
+		ride := rides[i]
+		opsMap.Requests = append(opsMap.Requests, MapRequest{
+			RideID:    ride.ID,
+			Lat:       ride.PickupLat,
+			Lng:       ride.PickupLng,
+			Status:    ride.Status,
+			Tier:      ride.Tier,
+			CreatedAt: ride.CreatedAt,
+		})
+	}
+
+	return opsMap, nil
+}
+
+// enrichDrivers attaches status and tier to each location, reading the
+// driver cache in one batch and the database for cache misses. Drivers that
+// are offline or no longer exist are dropped.
+func (s *OpsMapService) enrichDrivers(ctx context.Context, locations []redis.DriverLocation) ([]MapDriver, error) {
+	cached := make(map[string]*redis.CachedDriver)
+	if s.cacheStore != nil && len(locations) > 0 {
+		ids := make([]string, len(locations))
+		for i, loc := range locations {
+			ids[i] = loc.DriverID
+		}
+		batch, _, err := s.cacheStore.GetDriversBatch(ctx, ids)
+		if err != nil {
+			log.Printf("[OPS_MAP] driver cache read failed, falling back to database: %v", err)
+		} else {
+			cached = batch
+		}
+	}
+
+	drivers := make([]MapDriver, 0, len(locations))
+	for _, loc := range locations {
+		var status domain.DriverStatus
+		var tier domain.DriverTier
+		if c, ok := cached[loc.DriverID]; ok {
+			status, tier = domain.DriverStatus(c.Status), domain.DriverTier(c.Tier)
+		} else {
+			driver, err := s.driverRepo.GetByID(ctx, loc.DriverID)
+			if errors.Is(err, repository.ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			status, tier = driver.Status, driver.Tier
+		}
+		if status == domain.DriverStatusOffline {
+			continue
+		}
+
+		drivers = append(drivers, MapDriver{
+			DriverID: loc.DriverID,
+			Lat:      loc.Lat,
+			Lng:      loc.Lng,
+			Heading:  loc.Heading,
+			Status:   status,
+			Tier:     tier,
+		})
+	}
+	return drivers, nil
+}
+
+// sampleIndexes returns the indexes of at most limit items out of n, spread
+// evenly across [0, n) so a down-sampled layer keeps the shape of the full
+// one rather than just its head. With n <= limit every index is returned.
+func sampleIndexes(n, limit int) []int {
+	if n <= limit {
+		indexes := make([]int, n)
+		for i := range indexes {
+			indexes[i] = i
+		}
+		return indexes
+	}
+
+	indexes := make([]int, limit)
+	for i := range indexes {
+		indexes[i] = i * n / limit
+	}
+	return indexes
+}
@@ -2,7 +2,9 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
 
 	"github.com/google/uuid"
 
@@ -12,7 +14,33 @@ import (
 
 // PSP is the interface for a Payment Service Provider.
 type PSP interface {
-	Charge(ctx context.Context, amount float64) (bool, error)
+	// Charge charges amount to the instrument identified by token.
+	Charge(ctx context.Context, token string, amount float64) (bool, error)
+
+	// Authorize holds amount on the instrument identified by token without
+	// charging it, returning the hold's reference. A decline without a
+	// reason is ("", nil).
+	Authorize(ctx context.Context, token string, amount float64) (string, error)
+
+	// Capture charges amount, at most the amount held, against a hold.
+	Capture(ctx context.Context, authID string, amount float64) error
+
+	// Void releases a hold without charging it.
+	Void(ctx context.Context, authID string) error
+
+	// Refund returns amount to the instrument identified by token.
+	Refund(ctx context.Context, token string, amount float64) error
+}
+
+// PaymentDeclinedError is returned by a PSP that declined a charge,
+// authorization or capture and gave a reason, e.g. "insufficient funds". A
+// charge declined without a reason is (false, nil).
+type PaymentDeclinedError struct {
+	Reason string
+}
+
+func (e *PaymentDeclinedError) Error() string {
+	return "payment declined: " + e.Reason
 }
 
 // MockPSP is a mock implementation of PSP for testing.
@@ -24,31 +52,85 @@ func NewMockPSP() *MockPSP {
 }
 
 // Charge simulates a payment charge. Always succeeds.
-func (p *MockPSP) Charge(ctx context.Context, amount float64) (bool, error) {
+func (p *MockPSP) Charge(ctx context.Context, token string, amount float64) (bool, error) {
 	// Mock implementation: always succeeds.
 	return true, nil
 }
 
+// Authorize simulates a hold. Always succeeds.
+func (p *MockPSP) Authorize(ctx context.Context, token string, amount float64) (string, error) {
+	return "auth-" + uuid.New().String(), nil
+}
+
+// Capture simulates capturing against a hold. Always succeeds.
+func (p *MockPSP) Capture(ctx context.Context, authID string, amount float64) error {
+	return nil
+}
+
+// Void simulates releasing a hold. Always succeeds.
+func (p *MockPSP) Void(ctx context.Context, authID string) error {
+	return nil
+}
+
+// Refund simulates a refund. Always succeeds.
+func (p *MockPSP) Refund(ctx context.Context, token string, amount float64) error {
+	return nil
+}
+
 // PaymentService handles payment operations.
 type PaymentService struct {
-	paymentRepo repository.PaymentRepository
-	psp         PSP
+	paymentRepo    repository.PaymentRepository
+	psp            PSP
+	instrumentRepo repository.PaymentInstrumentRepository // Optional: nil charges with no instrument token
+	publisher      EventPublisher
+	fees           map[domain.PaymentMethod]domain.ProcessingFee // Passed on per method; cash never pays one
+	authBuffer     float64                                       // Percentage held on top of a card ride's estimated fare
 }
 
-// NewPaymentService creates a new PaymentService.
-func NewPaymentService(paymentRepo repository.PaymentRepository, psp PSP) *PaymentService {
+// NewPaymentService creates a new PaymentService. A nil publisher publishes
+// no events. Fees with a negative part, or none at all, are ignored. A
+// negative authBuffer means no buffer.
+func NewPaymentService(paymentRepo repository.PaymentRepository, psp PSP, instrumentRepo repository.PaymentInstrumentRepository, publisher EventPublisher, fees map[domain.PaymentMethod]domain.ProcessingFee, authBuffer float64) *PaymentService {
+	if publisher == nil {
+		publisher = NoopEventPublisher{}
+	}
+	if authBuffer < 0 {
+		authBuffer = 0
+	}
+	active := make(map[domain.PaymentMethod]domain.ProcessingFee, len(fees))
+	for method, fee := range fees {
+		if fee.Percent >= 0 && fee.Flat >= 0 && fee != (domain.ProcessingFee{}) {
+			active[method] = fee
+		}
+	}
 	return &PaymentService{
-		paymentRepo: paymentRepo,
-		psp:         psp,
+		paymentRepo:    paymentRepo,
+		psp:            psp,
+		instrumentRepo: instrumentRepo,
+		publisher:      publisher,
+		fees:           active,
+		authBuffer:     authBuffer,
 	}
 }
 
 // ProcessPaymentRequest contains the parameters for processing a payment.
 type ProcessPaymentRequest struct {
 	TripID string
-	Amount float64
+	Amount float64              // Fare, before the method's processing fee
+	Method domain.PaymentMethod // CASH is collected by the driver, not charged
+
+	InstrumentID string // Instrument to charge; recorded on the payment
+
+	// IdempotencyKey, when set, identifies a charge for the trip other
+	// than its fare, such as a tip or an adjustment: requests with the same
+	// key are one charge, requests with different keys are separate ones.
+	// Empty means the trip's fare, deduplicated per trip.
+	IdempotencyKey string
 }
 
+// maxIdempotencyKeyLength bounds client-supplied idempotency keys.
+const maxIdempotencyKeyLength = 128
+
 // ProcessPayment processes a payment for a trip with idempotency support.
 func (s *PaymentService) ProcessPayment(ctx context.Context, req ProcessPaymentRequest) (*domain.Payment, error) {
 	if req.TripID == "" {
@@ -59,8 +141,14 @@ func (s *PaymentService) ProcessPayment(ctx context.Context, req ProcessPaymentR
 		return nil, ErrInvalidPaymentAmount
 	}
 
-	// Generate idempotency key based on trip ID.
-	idempotencyKey := fmt.Sprintf("payment:%s", req.TripID)
+	// The fare's key is derived from the trip; other charges bring their own.
+	idempotencyKey := paymentIdempotencyKey(req.TripID)
+	if req.IdempotencyKey != "" {
+		if len(req.IdempotencyKey) > maxIdempotencyKeyLength {
+			return nil, ErrInvalidIdempotencyKey
+		}
+		idempotencyKey = chargeIdempotencyKey(req.TripID, req.IdempotencyKey)
+	}
 
 	// Check for existing payment (idempotency).
 	existingPayment, err := s.paymentRepo.GetByIdempotencyKey(ctx, idempotencyKey)
@@ -69,11 +157,18 @@ func (s *PaymentService) ProcessPayment(ctx context.Context, req ProcessPaymentR
 	}
 
 	if existingPayment != nil {
+		switch existingPayment.Status {
+		case domain.PaymentStatusFailed:
+			return s.retryFailed(ctx, existingPayment, req.Method)
+		case domain.PaymentStatusAuthorized:
+			return s.capture(ctx, existingPayment, req)
+		}
 		// Payment already exists - return it (idempotent).
 		return existingPayment, nil
 	}
 
-	// Create payment in PENDING state.
+	// Create payment in PENDING state, or CASH_DUE until the driver confirms
+	// collecting cash. Other methods add their processing fee to the total.
 	payment := &domain.Payment{
 		ID:             uuid.New().String(),
 		TripID:         req.TripID,
@@ -81,17 +176,76 @@ func (s *PaymentService) ProcessPayment(ctx context.Context, req ProcessPaymentR
 		Status:         domain.PaymentStatusPending,
 		IdempotencyKey: idempotencyKey,
 	}
+	if req.Method == domain.PaymentMethodCash {
+		payment.Status = domain.PaymentStatusCashDue
+	} else {
+		payment.InstrumentID = req.InstrumentID
+		if fee, ok := s.fees[req.Method]; ok {
+			payment.Fee = fee.Amount(req.Amount)
+			payment.Amount = roundCents(req.Amount + payment.Fee)
+		}
+	}
+
+	// Don't start a payment for a caller that has already gone; a retry
+	// would find it PENDING and never charge it.
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	if err := s.paymentRepo.Create(ctx, payment); err != nil {
 		return nil, err
 	}
 
-	// Call PSP (mocked).
-	success, err := s.psp.Charge(ctx, req.Amount)
+	if payment.Status == domain.PaymentStatusCashDue {
+		return payment, nil
+	}
+
+	return s.chargePayment(ctx, payment, req.Method)
+}
+
+// retryFailed charges a FAILED payment again, e.g. once the rider has
+// sorted out a declined card. The payment is claimed first, so concurrent
+// retries charge it once; a retry that loses the claim returns the payment
+// as it stands.
+func (s *PaymentService) retryFailed(ctx context.Context, payment *domain.Payment, method domain.PaymentMethod) (*domain.Payment, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	claimed, err := s.paymentRepo.TransitionStatus(ctx, payment.ID, domain.PaymentStatusFailed, domain.PaymentStatusPending)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return s.paymentRepo.GetByID(ctx, payment.ID)
+	}
+
+	payment.Status = domain.PaymentStatusPending
+	payment.FailureReason = ""
+	return s.chargePayment(ctx, payment, method)
+}
+
+// chargePayment charges a PENDING payment through the PSP. Once a charge has
+// been attempted its outcome is recorded, even if the caller cancels
+// meanwhile. A failed charge returns the FAILED payment, not an error.
+func (s *PaymentService) chargePayment(ctx context.Context, payment *domain.Payment, method domain.PaymentMethod) (*domain.Payment, error) {
+	success, err := s.charge(ctx, payment.InstrumentID, payment.Amount)
+	ctx = context.WithoutCancel(ctx)
+
+	reason := domain.PaymentFailureDeclined
+	var declined *PaymentDeclinedError
+	if errors.As(err, &declined) {
+		success, err = false, nil
+		if declined.Reason != "" {
+			reason = declined.Reason
+		}
+	}
+
 	if err != nil {
 		// PSP error - mark as failed.
-		_ = s.paymentRepo.UpdateStatus(ctx, payment.ID, domain.PaymentStatusFailed)
+		_ = s.paymentRepo.MarkFailed(ctx, payment.ID, domain.PaymentFailureProviderError)
 		payment.Status = domain.PaymentStatusFailed
+		payment.FailureReason = domain.PaymentFailureProviderError
 		return payment, nil
 	}
 
@@ -101,16 +255,465 @@ func (s *PaymentService) ProcessPayment(ctx context.Context, req ProcessPaymentR
 			return nil, err
 		}
 		payment.Status = domain.PaymentStatusSuccess
+		s.publishSucceeded(ctx, payment, method)
 	} else {
-		if err := s.paymentRepo.UpdateStatus(ctx, payment.ID, domain.PaymentStatusFailed); err != nil {
+		if err := s.paymentRepo.MarkFailed(ctx, payment.ID, reason); err != nil {
 			return nil, err
 		}
 		payment.Status = domain.PaymentStatusFailed
+		payment.FailureReason = reason
 	}
 
 	return payment, nil
 }
 
+// AuthorizeRequest contains the parameters for authorizing a trip's payment.
+type AuthorizeRequest struct {
+	TripID       string
+	Estimate     float64 // Estimated fare; the hold adds the buffer and the method's processing fee
+	Method       domain.PaymentMethod
+	InstrumentID string
+}
+
+// Authorize holds a trip's estimated fare plus the buffer on the rider's
+// instrument before the trip starts, so a card that cannot pay is found
+// before the ride rather than after. It returns the AUTHORIZED payment
+// unsaved, for the caller to store with the trip; if the trip does not
+// start the caller must release the hold with VoidHold.
+//
+// A decline returns an error wrapping ErrPaymentAuthorizationDeclined with
+// the PSP's reason.
+func (s *PaymentService) Authorize(ctx context.Context, req AuthorizeRequest) (*domain.Payment, error) {
+	if req.TripID == "" {
+		return nil, ErrInvalidTripID
+	}
+
+	if req.Estimate <= 0 {
+		return nil, ErrInvalidPaymentAmount
+	}
+
+	hold := roundCents(req.Estimate * (1 + s.authBuffer/100))
+	payment := &domain.Payment{
+		ID:             uuid.New().String(),
+		TripID:         req.TripID,
+		Amount:         hold,
+		Status:         domain.PaymentStatusAuthorized,
+		IdempotencyKey: paymentIdempotencyKey(req.TripID),
+		InstrumentID:   req.InstrumentID,
+	}
+	if fee, ok := s.fees[req.Method]; ok {
+		payment.Fee = fee.Amount(hold)
+		payment.Amount = roundCents(hold + payment.Fee)
+	}
+
+	authID, err := s.authorize(ctx, payment.InstrumentID, payment.Amount)
+	var declined *PaymentDeclinedError
+	if errors.As(err, &declined) {
+		return nil, fmt.Errorf("%w: %s", ErrPaymentAuthorizationDeclined, declined.Reason)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	payment.AuthID = authID
+	return payment, nil
+}
+
+// capture charges an AUTHORIZED payment's final fare against its hold. A
+// fare above the hold is first re-authorized for the full amount, and the
+// original hold released. The payment is claimed first, like a retry, so
+// concurrent endings capture it once. A failed capture returns the FAILED
+// payment, not an error; retrying it charges the card outright.
+func (s *PaymentService) capture(ctx context.Context, payment *domain.Payment, req ProcessPaymentRequest) (*domain.Payment, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	claimed, err := s.paymentRepo.TransitionStatus(ctx, payment.ID, domain.PaymentStatusAuthorized, domain.PaymentStatusPending)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return s.paymentRepo.GetByID(ctx, payment.ID)
+	}
+
+	held := payment.Amount
+	payment.Status = domain.PaymentStatusPending
+	payment.Amount = req.Amount
+	payment.Fee = 0
+	if fee, ok := s.fees[req.Method]; ok {
+		payment.Fee = fee.Amount(req.Amount)
+		payment.Amount = roundCents(req.Amount + payment.Fee)
+	}
+
+	if payment.Amount > held {
+		authID, err := s.authorize(ctx, payment.InstrumentID, payment.Amount)
+		if err != nil {
+			// The original hold cannot cover the fare either.
+			s.VoidHold(ctx, payment.AuthID)
+			return s.failCapture(ctx, payment, err)
+		}
+		s.VoidHold(ctx, payment.AuthID)
+		payment.AuthID = authID
+	}
+
+	// Store what is being captured, so a failed capture is retried for the
+	// final fare rather than the hold.
+	if err := s.paymentRepo.UpdateCharge(ctx, payment.ID, payment.Amount, payment.Fee, payment.AuthID); err != nil {
+		return nil, err
+	}
+
+	err = s.psp.Capture(ctx, payment.AuthID, payment.Amount)
+	ctx = context.WithoutCancel(ctx)
+	if err != nil {
+		var declined *PaymentDeclinedError
+		if errors.As(err, &declined) {
+			s.VoidHold(ctx, payment.AuthID)
+		}
+		return s.failCapture(ctx, payment, err)
+	}
+
+	if err := s.paymentRepo.UpdateStatus(ctx, payment.ID, domain.PaymentStatusSuccess); err != nil {
+		return nil, err
+	}
+	payment.Status = domain.PaymentStatusSuccess
+	s.publishSucceeded(ctx, payment, req.Method)
+	return payment, nil
+}
+
+// failCapture marks a payment whose capture or re-authorization failed
+// FAILED, with the PSP's decline reason or PaymentFailureProviderError.
+func (s *PaymentService) failCapture(ctx context.Context, payment *domain.Payment, err error) (*domain.Payment, error) {
+	ctx = context.WithoutCancel(ctx)
+
+	reason := domain.PaymentFailureProviderError
+	var declined *PaymentDeclinedError
+	if errors.As(err, &declined) {
+		reason = declined.Reason
+	}
+
+	if err := s.paymentRepo.MarkFailed(ctx, payment.ID, reason); err != nil {
+		return nil, err
+	}
+	payment.Status = domain.PaymentStatusFailed
+	payment.FailureReason = reason
+	return payment, nil
+}
+
+// VoidAuthorization releases the hold on a trip's AUTHORIZED payment, e.g.
+// when the trip is aborted with nothing to pay, and returns the VOIDED
+// payment. A trip without a hold returns its payment, if any, unchanged.
+func (s *PaymentService) VoidAuthorization(ctx context.Context, tripID string) (*domain.Payment, error) {
+	if tripID == "" {
+		return nil, ErrInvalidTripID
+	}
+
+	payment, err := s.paymentRepo.GetByIdempotencyKey(ctx, paymentIdempotencyKey(tripID))
+	if err != nil || payment == nil || payment.Status != domain.PaymentStatusAuthorized {
+		return payment, err
+	}
+
+	claimed, err := s.paymentRepo.TransitionStatus(ctx, payment.ID, domain.PaymentStatusAuthorized, domain.PaymentStatusVoided)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return s.paymentRepo.GetByID(ctx, payment.ID)
+	}
+
+	s.VoidHold(ctx, payment.AuthID)
+	payment.Status = domain.PaymentStatusVoided
+	return payment, nil
+}
+
+// FarePayment returns a trip's fare payment, or nil if it has none.
+func (s *PaymentService) FarePayment(ctx context.Context, tripID string) (*domain.Payment, error) {
+	if tripID == "" {
+		return nil, ErrInvalidTripID
+	}
+	return s.paymentRepo.GetByIdempotencyKey(ctx, paymentIdempotencyKey(tripID))
+}
+
+// PaymentByKey returns a trip's further charge or refund made under a client
+// idempotency key, or nil if there is none.
+func (s *PaymentService) PaymentByKey(ctx context.Context, tripID, key string) (*domain.Payment, error) {
+	if tripID == "" {
+		return nil, ErrInvalidTripID
+	}
+	return s.paymentRepo.GetByIdempotencyKey(ctx, chargeIdempotencyKey(tripID, key))
+}
+
+// VoidHold releases a hold with the PSP. A hold that cannot be released is
+// logged and left to expire with the card issuer.
+func (s *PaymentService) VoidHold(ctx context.Context, authID string) {
+	if err := s.psp.Void(context.WithoutCancel(ctx), authID); err != nil {
+		log.Printf("[PAYMENT] Failed to void authorization %s: %v", authID, err)
+	}
+}
+
+// ConfirmCash marks a trip's cash payment as collected. It reports whether
+// this call collected it; confirming an already collected payment returns it
+// unchanged.
+func (s *PaymentService) ConfirmCash(ctx context.Context, tripID string) (*domain.Payment, bool, error) {
+	if tripID == "" {
+		return nil, false, ErrInvalidTripID
+	}
+
+	payment, err := s.paymentRepo.GetByIdempotencyKey(ctx, paymentIdempotencyKey(tripID))
+	if err != nil {
+		return nil, false, err
+	}
+
+	if payment == nil {
+		return nil, false, repository.ErrNotFound
+	}
+
+	switch payment.Status {
+	case domain.PaymentStatusSuccess:
+		return payment, false, nil
+	case domain.PaymentStatusCashDue:
+	default:
+		return nil, false, ErrPaymentNotCashDue
+	}
+
+	if err := s.paymentRepo.UpdateStatus(ctx, payment.ID, domain.PaymentStatusSuccess); err != nil {
+		return nil, false, err
+	}
+	payment.Status = domain.PaymentStatusSuccess
+	s.publishSucceeded(ctx, payment, domain.PaymentMethodCash)
+
+	return payment, true, nil
+}
+
+// RefundRequest contains the parameters for refunding part of a trip's fare.
+type RefundRequest struct {
+	TripID string
+	Amount float64
+	Method domain.PaymentMethod // CASH is paid back by support, not the PSP
+
+	InstrumentID string // Instrument to refund to; recorded on the payment
+
+	// IdempotencyKey identifies the refund among the trip's payments, like
+	// ProcessPaymentRequest's: requests with the same key are one refund.
+	IdempotencyKey string
+}
+
+// Refund returns amount to the rider, recorded as a REFUNDED payment the
+// daily report nets off the trip's fare. A refund the PSP declines or
+// cannot make returns the FAILED payment, not an error. A CASH refund is
+// recorded without a PSP call.
+func (s *PaymentService) Refund(ctx context.Context, req RefundRequest) (*domain.Payment, error) {
+	if req.TripID == "" {
+		return nil, ErrInvalidTripID
+	}
+
+	if req.Amount <= 0 {
+		return nil, ErrInvalidPaymentAmount
+	}
+
+	if req.IdempotencyKey == "" || len(req.IdempotencyKey) > maxIdempotencyKeyLength {
+		return nil, ErrInvalidIdempotencyKey
+	}
+	idempotencyKey := chargeIdempotencyKey(req.TripID, req.IdempotencyKey)
+
+	existingPayment, err := s.paymentRepo.GetByIdempotencyKey(ctx, idempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+	if existingPayment != nil {
+		return existingPayment, nil
+	}
+
+	payment := &domain.Payment{
+		ID:             uuid.New().String(),
+		TripID:         req.TripID,
+		Amount:         roundCents(req.Amount),
+		Status:         domain.PaymentStatusPending,
+		IdempotencyKey: idempotencyKey,
+	}
+	if req.Method != domain.PaymentMethodCash {
+		payment.InstrumentID = req.InstrumentID
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := s.paymentRepo.Create(ctx, payment); err != nil {
+		return nil, err
+	}
+
+	if req.Method == domain.PaymentMethodCash {
+		log.Printf("[PAYMENT] Cash refund %s of %.2f for trip %s to be paid out by support", payment.ID, payment.Amount, payment.TripID)
+	} else {
+		err = s.refund(ctx, payment.InstrumentID, payment.Amount)
+	}
+	ctx = context.WithoutCancel(ctx)
+
+	if err != nil {
+		reason := domain.PaymentFailureProviderError
+		var declined *PaymentDeclinedError
+		if errors.As(err, &declined) {
+			reason = domain.PaymentFailureDeclined
+			if declined.Reason != "" {
+				reason = declined.Reason
+			}
+		}
+		if err := s.paymentRepo.MarkFailed(ctx, payment.ID, reason); err != nil {
+			return nil, err
+		}
+		payment.Status = domain.PaymentStatusFailed
+		payment.FailureReason = reason
+		return payment, nil
+	}
+
+	if err := s.paymentRepo.UpdateStatus(ctx, payment.ID, domain.PaymentStatusRefunded); err != nil {
+		return nil, err
+	}
+	payment.Status = domain.PaymentStatusRefunded
+	return payment, nil
+}
+
+// ReduceOwed lowers a FAILED or CASH_DUE payment, which has not been
+// collected, by amount and recomputes its processing fee, so a later retry
+// or cash collection takes the reduced amount. The payment is claimed first,
+// like a retry; one that is no longer owed returns ErrPaymentNotOwed.
+func (s *PaymentService) ReduceOwed(ctx context.Context, payment *domain.Payment, amount float64, method domain.PaymentMethod) (*domain.Payment, error) {
+	status := payment.Status
+	if status != domain.PaymentStatusFailed && status != domain.PaymentStatusCashDue {
+		return nil, ErrPaymentNotOwed
+	}
+
+	fare := roundCents(payment.Amount - payment.Fee - amount)
+	if amount <= 0 || fare <= 0 {
+		return nil, ErrInvalidPaymentAmount
+	}
+
+	claimed, err := s.paymentRepo.TransitionStatus(ctx, payment.ID, status, domain.PaymentStatusPending)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, ErrPaymentNotOwed
+	}
+	ctx = context.WithoutCancel(ctx)
+
+	reduced := *payment
+	reduced.Amount = fare
+	reduced.Fee = 0
+	if fee, ok := s.fees[method]; ok && method != domain.PaymentMethodCash {
+		reduced.Fee = fee.Amount(fare)
+		reduced.Amount = roundCents(fare + reduced.Fee)
+	}
+
+	// The claim is released whether or not the amount could be stored.
+	err = s.paymentRepo.UpdateCharge(ctx, reduced.ID, reduced.Amount, reduced.Fee, reduced.AuthID)
+	if _, releaseErr := s.paymentRepo.TransitionStatus(ctx, reduced.ID, domain.PaymentStatusPending, status); err == nil {
+		err = releaseErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &reduced, nil
+}
+
+// publishSucceeded publishes payment.succeeded for a payment that was just
+// charged or collected.
+func (s *PaymentService) publishSucceeded(ctx context.Context, payment *domain.Payment, method domain.PaymentMethod) {
+	publishEvent(ctx, s.publisher, domain.EventPaymentSucceeded, payment.ID, map[string]any{
+		"trip_id":        payment.TripID,
+		"amount":         payment.Amount,
+		"payment_method": method,
+		"instrument_id":  payment.InstrumentID,
+	})
+}
+
+// charge charges amount to the instrument through the PSP.
+func (s *PaymentService) charge(ctx context.Context, instrumentID string, amount float64) (bool, error) {
+	token, err := s.instrumentToken(ctx, instrumentID)
+	if err != nil {
+		return false, err
+	}
+	return s.psp.Charge(ctx, token, amount)
+}
+
+// refund refunds amount to the instrument through the PSP.
+func (s *PaymentService) refund(ctx context.Context, instrumentID string, amount float64) error {
+	token, err := s.instrumentToken(ctx, instrumentID)
+	if err != nil {
+		return err
+	}
+	return s.psp.Refund(ctx, token, amount)
+}
+
+// authorize holds amount on the instrument through the PSP. Every decline
+// is returned as a PaymentDeclinedError, with PaymentFailureDeclined as the
+// reason when the PSP gave none.
+func (s *PaymentService) authorize(ctx context.Context, instrumentID string, amount float64) (string, error) {
+	token, err := s.instrumentToken(ctx, instrumentID)
+	if err != nil {
+		return "", err
+	}
+
+	authID, err := s.psp.Authorize(ctx, token, amount)
+	var declined *PaymentDeclinedError
+	switch {
+	case errors.As(err, &declined) && declined.Reason == "",
+		err == nil && authID == "":
+		return "", &PaymentDeclinedError{Reason: domain.PaymentFailureDeclined}
+	case err != nil:
+		return "", err
+	}
+	return authID, nil
+}
+
+// DefaultInstrumentID returns the ID of the rider's default instrument for
+// method, or "" if they have none or instruments are not kept.
+func (s *PaymentService) DefaultInstrumentID(ctx context.Context, riderID string, method domain.PaymentMethod) (string, error) {
+	if s.instrumentRepo == nil {
+		return "", nil
+	}
+	instrument, err := s.instrumentRepo.GetDefault(ctx, riderID, method)
+	if err != nil || instrument == nil {
+		return "", err
+	}
+	return instrument.ID, nil
+}
+
+// instrumentToken returns the PSP token of an instrument. An instrument
+// removed since the ride was requested has no token, which the PSP declines
+// like any other bad card.
+func (s *PaymentService) instrumentToken(ctx context.Context, instrumentID string) (string, error) {
+	if instrumentID == "" || s.instrumentRepo == nil {
+		return "", nil
+	}
+	instrument, err := s.instrumentRepo.GetByID(ctx, instrumentID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return "", err
+	}
+	if instrument == nil {
+		return "", nil
+	}
+	return instrument.Token, nil
+}
+
+// paymentIdempotencyKey is the idempotency key of a trip's payment.
+func paymentIdempotencyKey(tripID string) string {
+	return fmt.Sprintf("payment:%s", tripID)
+}
+
+// chargeIdempotencyKey is the idempotency key of a further charge for a
+// trip, scoped to the trip so that clients cannot collide across trips.
+func chargeIdempotencyKey(tripID, clientKey string) string {
+	return fmt.Sprintf("payment:%s:%s", tripID, clientKey)
+}
+
+// splitShareKey is the client idempotency key of a rider's share of a split
+// fare, other than the owner's, which is the trip's fare payment.
+func splitShareKey(riderID string) string {
+	return "split:" + riderID
+}
+
 // GetPayment retrieves a payment by ID.
 func (s *PaymentService) GetPayment(ctx context.Context, paymentID string) (*domain.Payment, error) {
 	if paymentID == "" {
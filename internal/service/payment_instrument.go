@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"ride/internal/domain"
+	"ride/internal/repository"
+)
+
+// PaymentInstrumentService manages the cards, wallets and UPI accounts users
+// keep on file, and picks the one a ride is charged to.
+type PaymentInstrumentService struct {
+	instrumentRepo repository.PaymentInstrumentRepository
+	catalog        *domain.Catalog // Payment methods an instrument may be for
+}
+
+// NewPaymentInstrumentService creates a new PaymentInstrumentService. A nil
+// catalog means the default catalog.
+func NewPaymentInstrumentService(instrumentRepo repository.PaymentInstrumentRepository, catalog *domain.Catalog) *PaymentInstrumentService {
+	if catalog == nil {
+		catalog = domain.DefaultCatalog()
+	}
+	return &PaymentInstrumentService{instrumentRepo: instrumentRepo, catalog: catalog}
+}
+
+// AddPaymentInstrumentRequest contains the parameters for adding an instrument.
+type AddPaymentInstrumentRequest struct {
+	UserID        string
+	Type          domain.PaymentMethod
+	Token         string
+	MaskedDetails string
+	IsDefault     bool // The user's first instrument of a type becomes its default regardless
+}
+
+// Add stores a new instrument for the user.
+func (s *PaymentInstrumentService) Add(ctx context.Context, req AddPaymentInstrumentRequest) (*domain.PaymentInstrument, error) {
+	instrument := &domain.PaymentInstrument{
+		ID:            uuid.New().String(),
+		UserID:        req.UserID,
+		Type:          req.Type,
+		Token:         req.Token,
+		MaskedDetails: req.MaskedDetails,
+		CreatedAt:     time.Now(),
+	}
+	if err := instrument.Validate(); err != nil {
+		return nil, err
+	}
+	if !s.catalog.HasPaymentMethod(req.Type) {
+		return nil, ErrInvalidPaymentInstrument
+	}
+
+	existing, err := s.instrumentRepo.GetDefault(ctx, req.UserID, req.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.instrumentRepo.Create(ctx, instrument); err != nil {
+		return nil, err
+	}
+
+	if req.IsDefault || existing == nil {
+		if err := s.instrumentRepo.SetDefault(ctx, req.UserID, instrument.ID); err != nil {
+			return nil, err
+		}
+		instrument.IsDefault = true
+	}
+	return instrument, nil
+}
+
+// List returns the user's instruments, oldest first.
+func (s *PaymentInstrumentService) List(ctx context.Context, userID string) ([]*domain.PaymentInstrument, error) {
+	return s.instrumentRepo.ListByUser(ctx, userID)
+}
+
+// Get returns one of the user's instruments. Another user's instrument is
+// reported as not found.
+func (s *PaymentInstrumentService) Get(ctx context.Context, userID, id string) (*domain.PaymentInstrument, error) {
+	instrument, err := s.instrumentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if instrument.UserID != userID {
+		return nil, repository.ErrNotFound
+	}
+	return instrument, nil
+}
+
+// SetDefault makes the instrument the user's default for its type.
+func (s *PaymentInstrumentService) SetDefault(ctx context.Context, userID, id string) (*domain.PaymentInstrument, error) {
+	if err := s.instrumentRepo.SetDefault(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, userID, id)
+}
+
+// Delete removes one of the user's instruments. Rides already charged to it
+// keep the instrument ID for their records.
+func (s *PaymentInstrumentService) Delete(ctx context.Context, userID, id string) error {
+	return s.instrumentRepo.Delete(ctx, userID, id)
+}
+
+// ResolveForRide returns the instrument a ride paid by method is charged to:
+// the one the rider chose, or their default for that method. CASH rides need
+// no instrument and get nil. Returns ErrNoPaymentInstrument if the rider has
+// no matching instrument on file.
+func (s *PaymentInstrumentService) ResolveForRide(ctx context.Context, riderID string, method domain.PaymentMethod, instrumentID string) (*domain.PaymentInstrument, error) {
+	if method == domain.PaymentMethodCash {
+		return nil, nil
+	}
+
+	if instrumentID != "" {
+		instrument, err := s.Get(ctx, riderID, instrumentID)
+		if err == repository.ErrNotFound || (err == nil && instrument.Type != method) {
+			return nil, ErrNoPaymentInstrument
+		}
+		return instrument, err
+	}
+
+	instrument, err := s.instrumentRepo.GetDefault(ctx, riderID, method)
+	if err != nil {
+		return nil, err
+	}
+	if instrument == nil {
+		return nil, ErrNoPaymentInstrument
+	}
+	return instrument, nil
+}
@@ -0,0 +1,28 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/nyaruka/phonenumbers"
+)
+
+// DefaultPhoneRegion is the region assumed for numbers given without a
+// country code when none is configured.
+const DefaultPhoneRegion = "US"
+
+// NormalizePhone converts a phone number to E.164 (e.g. "+15551234567") so
+// that differently formatted copies of the same number compare equal.
+// Numbers without a country code are read as belonging to defaultRegion.
+// Returns ErrInvalidPhone for numbers that cannot be valid.
+func NormalizePhone(phone, defaultRegion string) (string, error) {
+	if defaultRegion == "" {
+		defaultRegion = DefaultPhoneRegion
+	}
+
+	number, err := phonenumbers.Parse(strings.TrimSpace(phone), strings.ToUpper(defaultRegion))
+	if err != nil || !phonenumbers.IsValidNumber(number) {
+		return "", ErrInvalidPhone
+	}
+
+	return phonenumbers.Format(number, phonenumbers.E164), nil
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	defaultPSPTimeout          = 5 * time.Second        // Used when the configured timeout is not positive
+	defaultPSPRetryBackoff     = 200 * time.Millisecond // Used when the configured backoff is not positive
+	defaultPSPBreakerThreshold = 5                      // Used when the configured threshold is not positive
+	defaultPSPBreakerCooldown  = 30 * time.Second       // Used when the configured cooldown is not positive
+)
+
+// ResilientPSP wraps a PSP with a per-attempt timeout, bounded retries and a
+// circuit breaker, so a slow or failing provider cannot stall trip endings.
+//
+// Only errors wrapping ErrPSPTransient are retried: the provider reported
+// that the charge was not made. A timeout is not retried, since the charge
+// may have gone through; the payment fails and can be retried later.
+//
+// After breakerThreshold consecutive failed calls the breaker opens and
+// calls fail fast with ErrPSPUnavailable for breakerCooldown. Then a single
+// trial call is let through: success closes the breaker, failure reopens it.
+// Declines, with or without a PaymentDeclinedError, are answers, not
+// failures, and do not count. Charges, authorizations, captures and voids
+// share the breaker, since they all fail together when the provider is down.
+type ResilientPSP struct {
+	psp              PSP
+	timeout          time.Duration
+	maxRetries       int
+	retryBackoff     time.Duration
+	breakerThreshold int
+	breakerCooldown  time.Duration
+
+	mu        sync.Mutex
+	failures  int       // Consecutive failed calls
+	openUntil time.Time // Zero while closed
+	probing   bool      // A trial call is in flight after the cooldown
+}
+
+var _ PSP = (*ResilientPSP)(nil)
+
+// NewResilientPSP wraps psp. A negative maxRetries means no retries.
+func NewResilientPSP(psp PSP, timeout time.Duration, maxRetries int, retryBackoff time.Duration, breakerThreshold int, breakerCooldown time.Duration) *ResilientPSP {
+	if timeout <= 0 {
+		timeout = defaultPSPTimeout
+	}
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+	if retryBackoff <= 0 {
+		retryBackoff = defaultPSPRetryBackoff
+	}
+	if breakerThreshold <= 0 {
+		breakerThreshold = defaultPSPBreakerThreshold
+	}
+	if breakerCooldown <= 0 {
+		breakerCooldown = defaultPSPBreakerCooldown
+	}
+
+	return &ResilientPSP{
+		psp:              psp,
+		timeout:          timeout,
+		maxRetries:       maxRetries,
+		retryBackoff:     retryBackoff,
+		breakerThreshold: breakerThreshold,
+		breakerCooldown:  breakerCooldown,
+	}
+}
+
+// Charge charges amount through the wrapped PSP. It returns
+// ErrPSPUnavailable without calling the PSP while the breaker is open.
+func (p *ResilientPSP) Charge(ctx context.Context, token string, amount float64) (bool, error) {
+	return callPSP(ctx, p, "charge", func(ctx context.Context) (bool, error) {
+		return p.psp.Charge(ctx, token, amount)
+	})
+}
+
+// Authorize places a hold through the wrapped PSP, like Charge.
+func (p *ResilientPSP) Authorize(ctx context.Context, token string, amount float64) (string, error) {
+	return callPSP(ctx, p, "authorize", func(ctx context.Context) (string, error) {
+		return p.psp.Authorize(ctx, token, amount)
+	})
+}
+
+// Capture captures against a hold through the wrapped PSP, like Charge.
+func (p *ResilientPSP) Capture(ctx context.Context, authID string, amount float64) error {
+	_, err := callPSP(ctx, p, "capture", func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, p.psp.Capture(ctx, authID, amount)
+	})
+	return err
+}
+
+// Void releases a hold through the wrapped PSP, like Charge.
+func (p *ResilientPSP) Void(ctx context.Context, authID string) error {
+	_, err := callPSP(ctx, p, "void", func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, p.psp.Void(ctx, authID)
+	})
+	return err
+}
+
+// Refund refunds through the wrapped PSP, like Charge.
+func (p *ResilientPSP) Refund(ctx context.Context, token string, amount float64) error {
+	_, err := callPSP(ctx, p, "refund", func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, p.psp.Refund(ctx, token, amount)
+	})
+	return err
+}
+
+// callPSP makes a PSP call through the breaker. op names the call in
+// timeout errors.
+func callPSP[T any](ctx context.Context, p *ResilientPSP, op string, call func(context.Context) (T, error)) (T, error) {
+	var zero T
+	if !p.allow() {
+		return zero, ErrPSPUnavailable
+	}
+
+	result, err := retryPSP(ctx, p, op, call)
+	// A call abandoned by the caller says nothing about the PSP.
+	p.record(err, ctx.Err() != nil)
+	return result, err
+}
+
+// retryPSP calls the PSP, retrying transient errors with a linear backoff.
+func retryPSP[T any](ctx context.Context, p *ResilientPSP, op string, call func(context.Context) (T, error)) (T, error) {
+	for attempt := 0; ; attempt++ {
+		result, err := callPSPOnce(ctx, p, op, call)
+		if err == nil || !errors.Is(err, ErrPSPTransient) || attempt >= p.maxRetries {
+			return result, err
+		}
+
+		select {
+		case <-time.After(time.Duration(attempt+1) * p.retryBackoff):
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		}
+	}
+}
+
+// callPSPOnce makes one PSP call bounded by the timeout.
+func callPSPOnce[T any](ctx context.Context, p *ResilientPSP, op string, call func(context.Context) (T, error)) (T, error) {
+	attemptCtx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	type outcome struct {
+		result T
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		result, err := call(attemptCtx)
+		done <- outcome{result, err}
+	}()
+
+	// A PSP that ignores its context is abandoned at the deadline.
+	var zero T
+	select {
+	case o := <-done:
+		return o.result, o.err
+	case <-attemptCtx.Done():
+		if ctx.Err() != nil {
+			return zero, ctx.Err()
+		}
+		return zero, fmt.Errorf("psp %s timed out after %s: %w", op, p.timeout, attemptCtx.Err())
+	}
+}
+
+// allow reports whether a call may go to the PSP, claiming the trial call
+// when the cooldown has passed.
+func (p *ResilientPSP) allow() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.openUntil.IsZero() {
+		return true
+	}
+	if time.Now().Before(p.openUntil) || p.probing {
+		return false
+	}
+	p.probing = true
+	return true
+}
+
+// record updates the breaker with a call's outcome. An abandoned call only
+// gives up the trial slot.
+func (p *ResilientPSP) record(err error, abandoned bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.probing = false
+	if abandoned {
+		return
+	}
+	var declined *PaymentDeclinedError
+	if err == nil || errors.As(err, &declined) {
+		p.failures = 0
+		p.openUntil = time.Time{}
+		return
+	}
+
+	p.failures++
+	if p.failures >= p.breakerThreshold {
+		if p.openUntil.IsZero() {
+			log.Printf("[PSP] Circuit open after %d consecutive failures: %v", p.failures, err)
+		}
+		p.openUntil = time.Now().Add(p.breakerCooldown)
+	}
+}
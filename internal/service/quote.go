@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"ride/internal/domain"
+	"ride/internal/redis"
+)
+
+const (
+	defaultQuoteTTL = 2 * time.Minute // Used when the configured TTL is not positive
+
+	// quoteMatchToleranceKm is how far a ride's pickup or destination may be
+	// from the quoted one and still be priced by the quote.
+	quoteMatchToleranceKm = 0.05
+)
+
+// QuoteService issues and redeems signed ride quotes. A quote holds the surge
+// multiplier shown with an estimate so the ride requested from it is priced
+// the same, even if surge changes in between.
+type QuoteService struct {
+	quoteStore redis.QuoteStoreInterface
+	signingKey []byte
+	ttl        time.Duration // How long a quote can be redeemed
+}
+
+// NewQuoteService creates a new QuoteService. Without a signing key a random
+// one is generated, so quotes are only honored by the instance that issued them.
+func NewQuoteService(quoteStore redis.QuoteStoreInterface, signingKey string, ttl time.Duration) *QuoteService {
+	if ttl <= 0 {
+		ttl = defaultQuoteTTL
+	}
+
+	key := []byte(signingKey)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			panic(err)
+		}
+		log.Printf("[QUOTE] no signing key configured; quotes are only valid on this instance")
+	}
+
+	return &QuoteService{
+		quoteStore: quoteStore,
+		signingKey: key,
+		ttl:        ttl,
+	}
+}
+
+// Issue stores a quote and returns its signed ID and expiry.
+func (s *QuoteService) Issue(ctx context.Context, quote redis.RideQuote) (string, time.Time, error) {
+	id := uuid.New().String()
+	if err := s.quoteStore.SaveQuote(ctx, id, quote, s.ttl); err != nil {
+		return "", time.Time{}, err
+	}
+	return id + "." + s.sign(id), quote.CreatedAt.Add(s.ttl), nil
+}
+
+// Redeem returns the quote for a signed ID if it can price a ride from
+// pickup to destination. Each quote is redeemed at most once. Returns
+// ErrInvalidQuote if the ID was tampered with, has expired or been used, or
+// was quoted for different coordinates.
+func (s *QuoteService) Redeem(ctx context.Context, quoteID string, pickupLat, pickupLng, destinationLat, destinationLng float64) (*redis.RideQuote, error) {
+	id, signature, ok := strings.Cut(quoteID, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(s.sign(id))) {
+		return nil, ErrInvalidQuote
+	}
+
+	quote, err := s.quoteStore.ConsumeQuote(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if quote == nil {
+		return nil, ErrInvalidQuote
+	}
+
+	if domain.HaversineKm(quote.PickupLat, quote.PickupLng, pickupLat, pickupLng) > quoteMatchToleranceKm ||
+		domain.HaversineKm(quote.DestinationLat, quote.DestinationLng, destinationLat, destinationLng) > quoteMatchToleranceKm {
+		return nil, ErrInvalidQuote
+	}
+
+	return quote, nil
+}
+
+// sign returns the hex HMAC-SHA256 of a quote ID.
+func (s *QuoteService) sign(id string) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(id))
+	return hex.EncodeToString(mac.Sum(nil))
+}
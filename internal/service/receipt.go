@@ -3,22 +3,38 @@ package service
 import (
 	"context"
 	"fmt"
+	"log"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
 
 	"ride/internal/domain"
+	"ride/internal/money"
+	"ride/internal/repository"
 )
 
 // ReceiptService handles receipt generation.
 type ReceiptService struct {
 	notificationService *NotificationService
+	userRepo            repository.UserRepository
+	sender              EmailSender      // Optional; receipts are not emailed without one
+	money               *money.Formatter // Nil formats US dollars
+	fares               *FareSchedule    // Prices the base fare the same way the trip was
 }
 
-// NewReceiptService creates a new ReceiptService.
-func NewReceiptService(notificationService *NotificationService) *ReceiptService {
+// NewReceiptService creates a new ReceiptService. A nil fare schedule means
+// the built-in catalog with the default minimum fare.
+func NewReceiptService(notificationService *NotificationService, userRepo repository.UserRepository, sender EmailSender, formatter *money.Formatter, fares *FareSchedule) *ReceiptService {
+	if fares == nil {
+		fares = NewFareSchedule(nil, 0)
+	}
 	return &ReceiptService{
 		notificationService: notificationService,
+		userRepo:            userRepo,
+		sender:              sender,
+		money:               formatter,
+		fares:               fares,
 	}
 }
 
@@ -27,6 +43,15 @@ type GenerateReceiptRequest struct {
 	Trip    *domain.Trip
 	Ride    *domain.Ride
 	Payment *domain.Payment
+
+	// Split and RiderID make the receipt one rider's part of a split fare;
+	// Payment is then the one charging their share.
+	Split   *domain.FareSplit
+	RiderID string
+
+	// Adjustment is set when the receipt is regenerated after an admin
+	// corrected the fare; the receipt notes the fare it replaced and why.
+	Adjustment *domain.FareAdjustment
 }
 
 // GenerateReceipt generates a receipt for a completed trip.
@@ -36,7 +61,7 @@ func (s *ReceiptService) GenerateReceipt(ctx context.Context, req GenerateReceip
 	}
 
 	// Calculate fare components
-	baseFare := s.calculateBaseFare(req.Trip)
+	baseFare := s.fares.BaseFare(req.Ride.Tier, req.Trip.EndedAt.Sub(req.Trip.StartedAt)-req.Trip.TotalPaused)
 	surgeMultiplier := req.Ride.SurgeMultiplier
 	if surgeMultiplier < 1.0 {
 		surgeMultiplier = 1.0
@@ -44,6 +69,17 @@ func (s *ReceiptService) GenerateReceipt(ctx context.Context, req GenerateReceip
 	surgeAmount := baseFare * (surgeMultiplier - 1.0)
 	totalFare := req.Trip.Fare
 
+	// An aborted trip never reaches the surcharge zone, and one the rider
+	// is not charged for has no fare at all.
+	surchargeLabel, surchargeAmount := req.Ride.SurchargeLabel, req.Ride.SurchargeAmount
+	aborted := req.Trip.Status == domain.TripStatusAborted
+	if aborted {
+		surchargeLabel, surchargeAmount = "", 0
+		if totalFare == 0 {
+			baseFare, surgeAmount = 0, 0
+		}
+	}
+
 	// Calculate duration (excluding paused time)
 	duration := req.Trip.EndedAt.Sub(req.Trip.StartedAt) - req.Trip.TotalPaused
 
@@ -53,10 +89,37 @@ func (s *ReceiptService) GenerateReceipt(ctx context.Context, req GenerateReceip
 		req.Ride.DestinationLat, req.Ride.DestinationLng,
 	)
 
-	// Determine payment status
+	// A rider sharing the fare is billed their share, plus any shares the
+	// ride's rider covered for others.
+	riderID := req.Ride.RiderID
+	var share *domain.FareShare
+	var absorbed float64
+	if req.Split != nil {
+		share = req.Split.Share(req.RiderID)
+	}
+	if share != nil {
+		riderID = share.RiderID
+		if riderID == req.Split.OwnerID {
+			for _, other := range req.Split.Shares {
+				if other.Absorbed {
+					absorbed = roundCents(absorbed + other.Amount)
+				}
+			}
+		}
+		totalFare = roundCents(share.Amount + absorbed)
+	}
+
+	// Determine payment status; nothing is pending when nothing is owed.
+	// The total is what the rider was charged, processing fee included.
 	paymentStatus := domain.PaymentStatusPending
+	var processingFee float64
 	if req.Payment != nil {
 		paymentStatus = req.Payment.Status
+		if processingFee = req.Payment.Fee; processingFee > 0 {
+			totalFare = roundCents(totalFare + processingFee)
+		}
+	} else if aborted && totalFare == 0 {
+		paymentStatus = ""
 	}
 
 	receipt := &domain.Receipt{
@@ -64,7 +127,7 @@ func (s *ReceiptService) GenerateReceipt(ctx context.Context, req GenerateReceip
 		TripID:          req.Trip.ID,
 		RideID:          req.Ride.ID,
 		DriverID:        req.Trip.DriverID,
-		RiderID:         req.Ride.RiderID,
+		RiderID:         riderID,
 		PickupLat:       req.Ride.PickupLat,
 		PickupLng:       req.Ride.PickupLng,
 		DestinationLat:  req.Ride.DestinationLat,
@@ -72,6 +135,9 @@ func (s *ReceiptService) GenerateReceipt(ctx context.Context, req GenerateReceip
 		BaseFare:        baseFare,
 		SurgeMultiplier: surgeMultiplier,
 		SurgeAmount:     surgeAmount,
+		SurchargeLabel:  surchargeLabel,
+		SurchargeAmount: surchargeAmount,
+		ProcessingFee:   processingFee,
 		TotalFare:       totalFare,
 		PaymentMethod:   req.Ride.PaymentMethod,
 		PaymentStatus:   paymentStatus,
@@ -79,34 +145,50 @@ func (s *ReceiptService) GenerateReceipt(ctx context.Context, req GenerateReceip
 		Distance:        distance,
 		StartedAt:       req.Trip.StartedAt,
 		EndedAt:         req.Trip.EndedAt,
+		AbortedBy:       req.Trip.AbortedBy,
+		AbortReason:     req.Trip.AbortReason,
 		CreatedAt:       time.Now(),
 	}
+	if share != nil {
+		receipt.SplitRiders = len(req.Split.Shares)
+		receipt.ShareAmount = share.Amount
+		receipt.AbsorbedAmount = absorbed
+		receipt.ShareAbsorbed = share.Absorbed
+	}
+	if req.Adjustment != nil {
+		receipt.AdjustedFrom = req.Adjustment.PreviousFare
+		receipt.AdjustmentReason = req.Adjustment.Reason
+	}
 
 	// Notify rider that receipt is ready
 	if s.notificationService != nil {
 		_ = s.notificationService.NotifyReceiptReady(ctx, receipt)
 	}
 
+	s.emailReceipt(ctx, receipt)
+
 	return receipt, nil
 }
 
-// calculateBaseFare calculates the base fare before surge.
-func (s *ReceiptService) calculateBaseFare(trip *domain.Trip) float64 {
-	const (
-		baseFare      = 2.0
-		perMinuteRate = 0.5
-		minimumFare   = 5.0
-	)
-
-	duration := trip.EndedAt.Sub(trip.StartedAt) - trip.TotalPaused
-	minutes := duration.Minutes()
+// emailReceipt sends the receipt to the rider's email address, but only once
+// the rider has verified it. Failures are logged; the receipt stands.
+func (s *ReceiptService) emailReceipt(ctx context.Context, receipt *domain.Receipt) {
+	if s.sender == nil || s.userRepo == nil {
+		return
+	}
 
-	fare := baseFare + (minutes * perMinuteRate)
-	if fare < minimumFare {
-		return minimumFare
+	rider, err := s.userRepo.GetByID(ctx, receipt.RiderID)
+	if err != nil {
+		log.Printf("[RECEIPT] failed to load rider %s for receipt %s: %v", receipt.RiderID, receipt.ID, err)
+		return
+	}
+	if rider.Email == "" || !rider.EmailVerified {
+		return
 	}
 
-	return fare
+	if err := s.sender.Send(ctx, rider.Email, "Your ride receipt", s.FormatReceipt(receipt)); err != nil {
+		log.Printf("[RECEIPT] failed to email receipt %s: %v", receipt.ID, err)
+	}
 }
 
 // estimateDistance estimates distance using Haversine formula.
@@ -141,18 +223,18 @@ Pickup:      (` + formatFloat(receipt.PickupLat) + `, ` + formatFloat(receipt.Pi
 Destination: (` + formatFloat(receipt.DestinationLat) + `, ` + formatFloat(receipt.DestinationLng) + `)
 Duration:    ` + formatDuration(receipt.Duration) + `
 Distance:    ` + formatFloat(receipt.Distance) + ` km
-
+` + formatAbort(receipt) + `
 FARE BREAKDOWN
 -------------------------------------
-Base Fare:        $` + formatFloat(receipt.BaseFare) + `
-Surge (` + formatFloat(receipt.SurgeMultiplier) + `x):   $` + formatFloat(receipt.SurgeAmount) + `
--------------------------------------
-TOTAL:            $` + formatFloat(receipt.TotalFare) + `
+Base Fare:        ` + s.money.Format(receipt.BaseFare) + `
+Surge (` + formatFloat(receipt.SurgeMultiplier) + `x):   ` + s.money.Format(receipt.SurgeAmount) + `
+` + s.formatSurcharge(receipt) + s.formatSplit(receipt) + s.formatProcessingFee(receipt) + s.formatAdjustment(receipt) + `-------------------------------------
+TOTAL:            ` + s.money.Format(receipt.TotalFare) + `
 
 PAYMENT
 -------------------------------------
 Method: ` + string(receipt.PaymentMethod) + `
-Status: ` + string(receipt.PaymentStatus) + `
+Status: ` + formatPaymentStatus(receipt) + `
 
 =====================================
      Thank you for riding with us!
@@ -160,6 +242,68 @@ Status: ` + string(receipt.PaymentStatus) + `
 `
 }
 
+// formatSurcharge returns the receipt's surcharge line, or nothing without one.
+func (s *ReceiptService) formatSurcharge(receipt *domain.Receipt) string {
+	if receipt.SurchargeAmount <= 0 {
+		return ""
+	}
+	return receipt.SurchargeLabel + `:  ` + s.money.Format(receipt.SurchargeAmount) + "\n"
+}
+
+// formatSplit returns the receipt's split fare lines, or nothing when the
+// fare was not split.
+func (s *ReceiptService) formatSplit(receipt *domain.Receipt) string {
+	if receipt.SplitRiders == 0 {
+		return ""
+	}
+	lines := `Your share (` + strconv.Itoa(receipt.SplitRiders) + ` riders): ` + s.money.Format(receipt.ShareAmount) + "\n"
+	if receipt.AbsorbedAmount > 0 {
+		lines += `Covered for others: ` + s.money.Format(receipt.AbsorbedAmount) + "\n"
+	}
+	if receipt.ShareAbsorbed {
+		lines += "Your payment failed; your share was charged to the ride's rider\n"
+	}
+	return lines
+}
+
+// formatProcessingFee returns the receipt's processing fee line, or nothing
+// for methods without a fee.
+func (s *ReceiptService) formatProcessingFee(receipt *domain.Receipt) string {
+	if receipt.ProcessingFee <= 0 {
+		return ""
+	}
+	return `Processing fee:   ` + s.money.Format(receipt.ProcessingFee) + "\n"
+}
+
+// formatAdjustment returns the receipt's fare adjustment line, or nothing
+// when the fare was not adjusted.
+func (s *ReceiptService) formatAdjustment(receipt *domain.Receipt) string {
+	if receipt.AdjustedFrom <= 0 {
+		return ""
+	}
+	return `Adjusted from ` + s.money.Format(receipt.AdjustedFrom) + `: ` + receipt.AdjustmentReason + "\n"
+}
+
+// formatAbort returns the receipt's abort line, or nothing for a completed trip.
+func formatAbort(receipt *domain.Receipt) string {
+	switch receipt.AbortedBy {
+	case domain.AbortPartyRider:
+		return "Aborted by rider: " + receipt.AbortReason + "\n"
+	case domain.AbortPartyDriver:
+		return "Aborted by driver: " + receipt.AbortReason + "\n"
+	}
+	return ""
+}
+
+// formatPaymentStatus returns the receipt's payment status, or "No charge"
+// for an aborted trip the rider was not charged for.
+func formatPaymentStatus(receipt *domain.Receipt) string {
+	if receipt.PaymentStatus == "" {
+		return "No charge"
+	}
+	return string(receipt.PaymentStatus)
+}
+
 func formatFloat(f float64) string {
 	return fmt.Sprintf("%.2f", f)
 }
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRematchInterval  = 30 * time.Second // Used when the configured interval is not positive
+	defaultRematchBatchSize = 50               // Used when the configured batch size is not positive
+)
+
+// RematchWorker periodically retries matching rides still waiting for a
+// driver, priority rides first. A ride that found no driver when requested
+// otherwise stays REQUESTED until the rider cancels.
+type RematchWorker struct {
+	rideService *RideService
+	interval    time.Duration
+	batchSize   int
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewRematchWorker creates a RematchWorker and starts its background loop.
+// Call Close on shutdown to stop it.
+func NewRematchWorker(rideService *RideService, interval time.Duration, batchSize int) *RematchWorker {
+	if interval <= 0 {
+		interval = defaultRematchInterval
+	}
+	if batchSize <= 0 {
+		batchSize = defaultRematchBatchSize
+	}
+
+	w := &RematchWorker{
+		rideService: rideService,
+		interval:    interval,
+		batchSize:   batchSize,
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Close stops the background loop, waiting for a retry in progress.
+func (w *RematchWorker) Close() {
+	w.closeOnce.Do(func() { close(w.stop) })
+	<-w.done
+}
+
+// run retries waiting rides on every interval.
+func (w *RematchWorker) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if matched, err := w.rideService.RetryUnmatched(context.Background(), w.batchSize); err != nil {
+				log.Printf("[RIDE] Failed to retry unmatched rides: %v", err)
+			} else if matched > 0 {
+				log.Printf("[RIDE] Matched %d waiting rides", matched)
+			}
+		case <-w.stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/repository"
+)
+
+// reconciliationTolerance absorbs floating point rounding in fare sums.
+const reconciliationTolerance = 0.01
+
+// ReportService builds finance reports.
+type ReportService struct {
+	reportRepo repository.ReportRepository
+}
+
+// NewReportService creates a new ReportService.
+func NewReportService(reportRepo repository.ReportRepository) *ReportService {
+	return &ReportService{reportRepo: reportRepo}
+}
+
+// DailyReport builds the reconciliation report for the UTC day containing date.
+// It cross-checks that successful + failed + pending payments and cash awaiting
+// collection, less refunds, add up to the gross fares of trips ended that day
+// and lists any trips that do not.
+func (s *ReportService) DailyReport(ctx context.Context, date time.Time) (*domain.DailyReport, error) {
+	if date.IsZero() {
+		return nil, ErrInvalidReportDate
+	}
+
+	from := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 1)
+
+	totals, err := s.reportRepo.GetDailyTotals(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	discrepancies, err := s.reportRepo.GetFareDiscrepancies(ctx, from, to, reconciliationTolerance)
+	if err != nil {
+		return nil, err
+	}
+
+	accounted := totals.SuccessfulPayments.Amount + totals.FailedPayments.Amount +
+		totals.PendingPayments.Amount + totals.CashAwaiting.Amount - totals.Refunds.Amount
+	difference := roundCents(totals.GrossFares - accounted)
+
+	return &domain.DailyReport{
+		Date:            from,
+		Totals:          *totals,
+		AccountedAmount: roundCents(accounted),
+		Difference:      difference,
+		Balanced:        math.Abs(difference) <= reconciliationTolerance && len(discrepancies) == 0,
+		Discrepancies:   discrepancies,
+	}, nil
+}
+
+// EstimateAccuracy reports, for the UTC day containing date, how the fare
+// range estimated at ride creation compared with the fares trips ended with.
+func (s *ReportService) EstimateAccuracy(ctx context.Context, date time.Time) (*domain.EstimateAccuracy, error) {
+	if date.IsZero() {
+		return nil, ErrInvalidReportDate
+	}
+
+	from := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 1)
+
+	accuracy, err := s.reportRepo.GetEstimateAccuracy(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	accuracy.Date = from
+	accuracy.ErrorP50 = roundCents(accuracy.ErrorP50)
+	accuracy.ErrorP90 = roundCents(accuracy.ErrorP90)
+	accuracy.ErrorP99 = roundCents(accuracy.ErrorP99)
+	return accuracy, nil
+}
+
+// OutstandingCollections lists the cash payments the driver has not yet
+// confirmed collecting.
+func (s *ReportService) OutstandingCollections(ctx context.Context, driverID string) (*domain.CollectionsReport, error) {
+	if driverID == "" {
+		return nil, ErrInvalidDriverID
+	}
+
+	collections, err := s.reportRepo.GetOutstandingCollections(ctx, driverID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.CollectionsReport{DriverID: driverID, Collections: collections}
+	for _, c := range collections {
+		report.Total.Count++
+		report.Total.Amount += c.Amount
+	}
+	report.Total.Amount = roundCents(report.Total.Amount)
+
+	return report, nil
+}
+
+// LedgerCheck cross-checks trips and payments finished or created at or
+// after since, or the whole ledger when since is zero, and reports every
+// inconsistency found. Fares held for review and zero fares are documented
+// exceptions to the one-payment-per-trip rule.
+func (s *ReportService) LedgerCheck(ctx context.Context, since time.Time) (*domain.LedgerReport, error) {
+	anomalies, err := s.reportRepo.GetLedgerAnomalies(ctx, since, reconciliationTolerance)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.LedgerReport{
+		CheckedAt:  time.Now(),
+		Since:      since,
+		Anomalies:  anomalies,
+		Consistent: len(anomalies) == 0,
+	}, nil
+}
+
+// roundCents rounds an amount to two decimal places.
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -2,11 +2,15 @@ package service
 
 import (
 	"context"
+	"errors"
+	"log"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
 	"ride/internal/domain"
+	"ride/internal/redis"
 	"ride/internal/repository"
 )
 
@@ -14,6 +18,8 @@ import (
 // This interface allows for testing with mock implementations.
 type MatchingServiceInterface interface {
 	Match(ctx context.Context, req MatchRequest) (*MatchResult, error)
+	AssignDriver(ctx context.Context, rideID, driverID string) (*MatchResult, error)
+	GetDriver(ctx context.Context, driverID string) (*domain.Driver, error)
 }
 
 // Ensure MatchingService implements MatchingServiceInterface.
@@ -24,22 +30,131 @@ type RideService struct {
 	rideRepo            repository.RideRepository
 	matchingService     MatchingServiceInterface
 	surgeService        *SurgeService
+	surchargeService    *SurchargeService // Optional: nil applies no zone surcharges
+	quoteService        *QuoteService     // Optional: nil issues no quotes and prices every ride live
 	notificationService *NotificationService
+	instrumentService   *PaymentInstrumentService // Optional: nil skips the instrument-on-file check
+	catalog             *domain.Catalog           // Default payment method and per-tier surge caps
+	publisher           EventPublisher
+	flags               *FeatureFlagService   // Optional: nil honors quotes for every rider
+	broadcast           *RideBroadcastService // Optional: nil tells no other drivers about new requests
+	queue               *RideQueueService     // Optional: nil shows riders no queue position
+	fares               *FareSchedule         // Prices the estimated fare range like the trip's fare
+	estimator           *EstimatorService     // Optional: nil estimates durations at the average speed
+}
+
+// RideServiceDeps holds what a RideService is built from.
+type RideServiceDeps struct {
+	RideRepo            repository.RideRepository
+	MatchingService     MatchingServiceInterface
+	SurgeService        *SurgeService
+	SurchargeService    *SurchargeService // Optional: nil applies no zone surcharges
+	QuoteService        *QuoteService     // Optional: nil issues no quotes and prices every ride live
+	NotificationService *NotificationService
+	InstrumentService   *PaymentInstrumentService // Optional: nil skips the instrument-on-file check
+	Catalog             *domain.Catalog           // Optional: nil uses the default catalog
+	Publisher           EventPublisher            // Optional: nil publishes nothing
+	Flags               *FeatureFlagService       // Optional: nil honors quotes for every rider
+	Broadcast           *RideBroadcastService     // Optional: nil tells no other drivers about new requests
+	Queue               *RideQueueService         // Optional: nil shows riders no queue position
+	Fares               *FareSchedule             // Optional: nil uses the catalog's tiers with the default minimum fare
+	Estimator           *EstimatorService         // Optional: nil estimates durations at the average speed
 }
 
 // NewRideService creates a new RideService.
-func NewRideService(
-	rideRepo repository.RideRepository,
-	matchingService MatchingServiceInterface,
-	surgeService *SurgeService,
-	notificationService *NotificationService,
-) *RideService {
+func NewRideService(deps RideServiceDeps) *RideService {
+	if deps.Catalog == nil {
+		deps.Catalog = domain.DefaultCatalog()
+	}
+	if deps.Fares == nil {
+		deps.Fares = NewFareSchedule(deps.Catalog, 0)
+	}
+	if deps.Publisher == nil {
+		deps.Publisher = NoopEventPublisher{}
+	}
 	return &RideService{
-		rideRepo:            rideRepo,
-		matchingService:     matchingService,
-		surgeService:        surgeService,
-		notificationService: notificationService,
+		rideRepo:            deps.RideRepo,
+		matchingService:     deps.MatchingService,
+		surgeService:        deps.SurgeService,
+		surchargeService:    deps.SurchargeService,
+		quoteService:        deps.QuoteService,
+		notificationService: deps.NotificationService,
+		instrumentService:   deps.InstrumentService,
+		catalog:             deps.Catalog,
+		publisher:           deps.Publisher,
+		flags:               deps.Flags,
+		broadcast:           deps.Broadcast,
+		queue:               deps.Queue,
+		fares:               deps.Fares,
+		estimator:           deps.Estimator,
+	}
+}
+
+// EstimateRideRequest contains the parameters for estimating a ride.
+type EstimateRideRequest struct {
+	PickupLat      float64
+	PickupLng      float64
+	DestinationLat float64
+	DestinationLng float64
+}
+
+// RideEstimate is the pricing a ride would get if requested now.
+type RideEstimate struct {
+	QuoteID         string // Empty when quotes are not available
+	SurgeMultiplier float64
+	SurchargeLabel  string
+	SurchargeAmount float64
+	ExpiresAt       time.Time // When the quote stops being honored
+}
+
+// EstimateRide prices a ride without requesting it. The returned quote ID
+// can be passed to CreateRide to keep the estimated surge for a short window.
+func (s *RideService) EstimateRide(ctx context.Context, req EstimateRideRequest) (*RideEstimate, error) {
+	if !domain.IsValidCoordinate(req.PickupLat, req.PickupLng) {
+		return nil, ErrInvalidPickupLocation
+	}
+	if !domain.IsValidCoordinate(req.DestinationLat, req.DestinationLng) {
+		return nil, ErrInvalidDestinationLocation
+	}
+
+	surgeMultiplier := 1.0
+	if s.surgeService != nil {
+		surgeMultiplier = s.surgeService.GetMultiplier(ctx, req.PickupLat, req.PickupLng)
+	}
+
+	ride := &domain.Ride{
+		PickupLat:      req.PickupLat,
+		PickupLng:      req.PickupLng,
+		DestinationLat: req.DestinationLat,
+		DestinationLng: req.DestinationLng,
+	}
+	if s.surchargeService != nil {
+		s.surchargeService.Apply(ride)
+	}
+
+	estimate := &RideEstimate{
+		SurgeMultiplier: surgeMultiplier,
+		SurchargeLabel:  ride.SurchargeLabel,
+		SurchargeAmount: ride.SurchargeAmount,
+	}
+
+	if s.quoteService != nil {
+		quoteID, expiresAt, err := s.quoteService.Issue(ctx, redis.RideQuote{
+			PickupLat:       req.PickupLat,
+			PickupLng:       req.PickupLng,
+			DestinationLat:  req.DestinationLat,
+			DestinationLng:  req.DestinationLng,
+			SurgeMultiplier: surgeMultiplier,
+			CreatedAt:       time.Now(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		estimate.QuoteID = quoteID
+		estimate.ExpiresAt = expiresAt
 	}
+
+	return estimate, nil
 }
 
 // CreateRideRequest contains the parameters for creating a ride.
@@ -50,7 +165,17 @@ type CreateRideRequest struct {
 	DestinationLat float64
 	DestinationLng float64
 	Tier           domain.DriverTier    // Optional: empty means any tier
-	PaymentMethod  domain.PaymentMethod // Optional: defaults to CASH
+	PaymentMethod  domain.PaymentMethod // Optional: defaults to the catalog's default method
+	Type           domain.RideType      // Optional: defaults to PASSENGER
+	Capabilities   []string             // Optional: vehicle capabilities the driver must have, e.g. WAV
+	QuoteID        string               // Optional: quote from EstimateRide whose surge to honor
+	InstrumentID   string               // Optional: instrument to charge; defaults to the rider's default for PaymentMethod
+
+	// ExcludeDriverIDs are drivers the ride must never be matched to, e.g.
+	// ones the rider blocked. Optional.
+	ExcludeDriverIDs []string
+
+	RebookedFrom string // Optional: the ride this one repeats
 }
 
 // CreateRideResponse contains the result of creating a ride.
@@ -59,73 +184,240 @@ type CreateRideResponse struct {
 	DriverAssigned  bool
 	DriverID        string
 	SurgeMultiplier float64
+	QuoteRejected   bool // A quote was given but could not be honored; surge was priced live
 }
 
 // CreateRide creates a new ride and triggers matching.
 func (s *RideService) CreateRide(ctx context.Context, req CreateRideRequest) (*CreateRideResponse, error) {
-	// Validate input.
-	if err := s.validateCreateRequest(req); err != nil {
+	// Set default payment method if not specified
+	paymentMethod := req.PaymentMethod
+	if paymentMethod == "" {
+		paymentMethod = s.catalog.DefaultPaymentMethod
+	}
+
+	rideType := req.Type
+	if rideType == "" {
+		rideType = domain.RideTypePassenger
+	}
+
+	// Create ride in REQUESTED state.
+	now := time.Now()
+	ride := &domain.Ride{
+		ID:             uuid.New().String(),
+		RiderID:        req.RiderID,
+		PickupLat:      req.PickupLat,
+		PickupLng:      req.PickupLng,
+		DestinationLat: req.DestinationLat,
+		DestinationLng: req.DestinationLng,
+		Status:         domain.RideStatusRequested,
+		PaymentMethod:  paymentMethod,
+		Tier:           req.Tier,
+		Type:           rideType,
+		Capabilities:   req.Capabilities,
+		Priority:       domain.NeedsAccessibility(req.Capabilities),
+		RebookedFrom:   req.RebookedFrom,
+		CreatedAt:      now,
+		RequestedAt:    now,
+	}
+
+	// Validate input before doing any surge work.
+	if err := ride.Validate(); err != nil {
 		return nil, err
 	}
 
-	// Calculate surge multiplier based on supply/demand at pickup location.
+	// Card, wallet and UPI rides need an instrument on file to charge.
+	if s.instrumentService != nil {
+		instrument, err := s.instrumentService.ResolveForRide(ctx, req.RiderID, paymentMethod, req.InstrumentID)
+		if err != nil {
+			return nil, err
+		}
+		if instrument != nil {
+			ride.InstrumentID = instrument.ID
+		}
+	}
+
+	// Honor a quoted surge; otherwise calculate it from supply/demand at the pickup location.
+	quote, quoteRejected := s.redeemQuote(ctx, req)
 	surgeMultiplier := 1.0
-	if s.surgeService != nil {
+	if quote != nil {
+		surgeMultiplier = quote.SurgeMultiplier
+		ride.QuoteID = req.QuoteID
+	} else if s.surgeService != nil {
 		surgeMultiplier = s.surgeService.GetMultiplier(ctx, req.PickupLat, req.PickupLng)
 	}
+	surgeMultiplier = s.capSurge(req.Tier, surgeMultiplier)
+	ride.SurgeMultiplier = surgeMultiplier
 
-	// Set default payment method if not specified
-	paymentMethod := req.PaymentMethod
-	if paymentMethod == "" {
-		paymentMethod = domain.PaymentMethodCash
+	if s.surchargeService != nil {
+		s.surchargeService.Apply(ride)
 	}
 
-	// Create ride in REQUESTED state with surge.
-	ride := &domain.Ride{
-		ID:              uuid.New().String(),
-		RiderID:         req.RiderID,
-		PickupLat:       req.PickupLat,
-		PickupLng:       req.PickupLng,
-		DestinationLat:  req.DestinationLat,
-		DestinationLng:  req.DestinationLng,
-		Status:          domain.RideStatusRequested,
-		SurgeMultiplier: surgeMultiplier,
-		PaymentMethod:   paymentMethod,
-		CreatedAt:       time.Now(),
-	}
+	// The fare range the rider is quoted, kept to compare with the final fare.
+	estimate := s.estimator.EstimateDuration(ctx, ride.PickupLat, ride.PickupLng, ride.DestinationLat, ride.DestinationLng)
+	ride.EstimateLow, ride.EstimateHigh = s.fares.EstimateRange(ride.Tier, estimate.Duration, ride.SurgeMultiplier, ride.SurchargeAmount)
 
 	if err := s.rideRepo.Create(ctx, ride); err != nil {
 		return nil, err
 	}
+	publishEvent(ctx, s.publisher, domain.EventRideCreated, ride.ID, map[string]any{
+		"rider_id":         ride.RiderID,
+		"tier":             ride.Tier,
+		"payment_method":   ride.PaymentMethod,
+		"ride_type":        ride.Type,
+		"capabilities":     ride.Capabilities,
+		"surge_multiplier": ride.SurgeMultiplier,
+		"pickup_lat":       ride.PickupLat,
+		"pickup_lng":       ride.PickupLng,
+		"destination_lat":  ride.DestinationLat,
+		"destination_lng":  ride.DestinationLng,
+	})
 
 	// Trigger matching synchronously.
 	matchResult, err := s.matchingService.Match(ctx, MatchRequest{
-		RideID: ride.ID,
-		Lat:    req.PickupLat,
-		Lng:    req.PickupLng,
-		Tier:   req.Tier,
+		RideID:           ride.ID,
+		Lat:              req.PickupLat,
+		Lng:              req.PickupLng,
+		Tier:             req.Tier,
+		RiderID:          req.RiderID,
+		ExcludeDriverIDs: req.ExcludeDriverIDs,
 	})
 
 	// If matching fails, still return the ride (in REQUESTED state).
 	if err != nil {
-		if err == ErrNoDriverAvailable {
+		if errors.Is(err, ErrNoDriverAvailable) || errors.Is(err, ErrMatchingCandidatesExhausted) {
+			s.queue.Enqueue(ctx, ride)
+			s.broadcastRequest(ctx, ride, "")
 			return &CreateRideResponse{
 				Ride:            ride,
 				DriverAssigned:  false,
 				SurgeMultiplier: surgeMultiplier,
+				QuoteRejected:   quoteRejected,
 			}, nil
 		}
 		return nil, err
 	}
+	publishEvent(ctx, s.publisher, domain.EventRideAssigned, ride.ID, map[string]any{
+		"rider_id":  ride.RiderID,
+		"driver_id": matchResult.DriverID,
+	})
+	s.broadcastRequest(ctx, ride, matchResult.DriverID)
 
 	return &CreateRideResponse{
 		Ride:            matchResult.Ride,
 		DriverAssigned:  true,
 		DriverID:        matchResult.DriverID,
 		SurgeMultiplier: surgeMultiplier,
+		QuoteRejected:   quoteRejected,
 	}, nil
 }
 
+// RetryUnmatched retries matching up to limit rides still waiting for a
+// driver, priority rides first, so riders with accessibility needs get the
+// first pick of drivers freed since they requested, then oldest first, the
+// order of the ride queues. It returns how many rides were matched.
+func (s *RideService) RetryUnmatched(ctx context.Context, limit int) (int, error) {
+	rides, err := s.rideRepo.ListRequested(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	matched := 0
+	for _, ride := range rides {
+		if err := ctx.Err(); err != nil {
+			return matched, err
+		}
+		result, err := s.matchingService.Match(ctx, MatchRequest{
+			RideID:  ride.ID,
+			Lat:     ride.PickupLat,
+			Lng:     ride.PickupLng,
+			Tier:    ride.Tier,
+			RiderID: ride.RiderID,
+		})
+		switch {
+		case errors.Is(err, ErrNoDriverAvailable),
+			errors.Is(err, ErrMatchingCandidatesExhausted):
+			// Still waiting; queued again in case its entry was lost.
+			s.queue.Enqueue(ctx, ride)
+			continue
+		case errors.Is(err, ErrRideNotInRequestedState):
+			// Matched or cancelled meanwhile.
+			s.queue.Left(ctx, ride.ID)
+			continue
+		case err != nil:
+			log.Printf("[RIDE] Failed to re-match ride %s: %v", ride.ID, err)
+			continue
+		}
+		matched++
+		s.queue.Matched(ctx, ride.ID)
+		publishEvent(ctx, s.publisher, domain.EventRideAssigned, ride.ID, map[string]any{
+			"rider_id":  ride.RiderID,
+			"driver_id": result.DriverID,
+		})
+	}
+
+	return matched, nil
+}
+
+// AssignDriver assigns a dispatcher's chosen driver to a waiting ride, for
+// bookings matching cannot place, such as corporate accounts or airport
+// queues. The assignment is held to the same safeguards as a match (see
+// MatchingService.AssignDriver).
+func (s *RideService) AssignDriver(ctx context.Context, rideID, driverID string) (*MatchResult, error) {
+	result, err := s.matchingService.AssignDriver(ctx, rideID, driverID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.queue.Matched(ctx, rideID)
+	publishEvent(ctx, s.publisher, domain.EventRideAssigned, rideID, map[string]any{
+		"rider_id":    result.Ride.RiderID,
+		"driver_id":   result.DriverID,
+		"assigned_by": result.Ride.AssignedBy,
+	})
+
+	return result, nil
+}
+
+// broadcastRequest tells nearby drivers, other than the assigned one, that
+// the ride was requested.
+func (s *RideService) broadcastRequest(ctx context.Context, ride *domain.Ride, assignedDriverID string) {
+	if s.broadcast == nil {
+		return
+	}
+	s.broadcast.Broadcast(ctx, ride, assignedDriverID)
+}
+
+// capSurge limits surge to the tier's cap, if the tier has one.
+func (s *RideService) capSurge(tier domain.DriverTier, surgeMultiplier float64) float64 {
+	if tier == "" {
+		return surgeMultiplier
+	}
+	if spec, ok := s.catalog.Tier(string(tier)); ok && spec.MaxSurge > 0 && surgeMultiplier > spec.MaxSurge {
+		return spec.MaxSurge
+	}
+	return surgeMultiplier
+}
+
+// redeemQuote returns the quote the ride asked to be priced by, if it can be
+// honored. The bool reports a quote that was given but rejected.
+func (s *RideService) redeemQuote(ctx context.Context, req CreateRideRequest) (*redis.RideQuote, bool) {
+	if req.QuoteID == "" {
+		return nil, false
+	}
+	if s.quoteService == nil || !s.flags.Enabled(ctx, FlagRideQuotes, req.RiderID, true) {
+		return nil, true
+	}
+
+	quote, err := s.quoteService.Redeem(ctx, req.QuoteID, req.PickupLat, req.PickupLng, req.DestinationLat, req.DestinationLng)
+	if err != nil {
+		if !errors.Is(err, ErrInvalidQuote) {
+			log.Printf("[QUOTE] failed to redeem quote for rider %s: %v", req.RiderID, err)
+		}
+		return nil, true
+	}
+	return quote, false
+}
+
 // GetRideStatus retrieves the current status of a ride.
 func (s *RideService) GetRideStatus(ctx context.Context, rideID string) (*domain.Ride, error) {
 	if rideID == "" {
@@ -135,37 +427,66 @@ func (s *RideService) GetRideStatus(ctx context.Context, rideID string) (*domain
 	return s.rideRepo.GetByID(ctx, rideID)
 }
 
-// validateCreateRequest validates the create ride request.
-func (s *RideService) validateCreateRequest(req CreateRideRequest) error {
-	if req.RiderID == "" {
-		return ErrInvalidRiderID
+// QueuePosition returns a waiting ride's place in its area's queue, or nil
+// when it is not waiting or queues are not tracked.
+func (s *RideService) QueuePosition(ctx context.Context, ride *domain.Ride) *QueuePosition {
+	position, err := s.queue.Position(ctx, ride)
+	if err != nil {
+		log.Printf("[QUEUE] Failed to read queue position of ride %s: %v", ride.ID, err)
+		return nil
 	}
+	return position
+}
 
-	if !isValidLatitude(req.PickupLat) {
-		return ErrInvalidPickupLocation
+// Rebook requests a new ride for riderID with the pickup, destination, tier
+// and payment method of a completed or cancelled ride, priced at the current
+// surge. The original ride's quote is not reused, and its payment method is
+// charged to the rider's current default instrument. A ride that is not
+// riderID's is reported as repository.ErrNotFound; one still in progress
+// returns ErrRideStillActive.
+func (s *RideService) Rebook(ctx context.Context, rideID, riderID string) (*CreateRideResponse, error) {
+	if rideID == "" {
+		return nil, ErrInvalidRideID
 	}
 
-	if !isValidLongitude(req.PickupLng) {
-		return ErrInvalidPickupLocation
+	original, err := s.rideRepo.GetByID(ctx, rideID)
+	if err != nil {
+		return nil, err
 	}
-
-	if !isValidLatitude(req.DestinationLat) {
-		return ErrInvalidDestinationLocation
+	if riderID == "" || original.RiderID != riderID {
+		return nil, repository.ErrNotFound
 	}
-
-	if !isValidLongitude(req.DestinationLng) {
-		return ErrInvalidDestinationLocation
+	if !original.Status.IsTerminal() {
+		return nil, ErrRideStillActive
 	}
 
-	return nil
-}
-
-func isValidLatitude(lat float64) bool {
-	return lat >= -90 && lat <= 90
+	return s.CreateRide(ctx, CreateRideRequest{
+		RiderID:        original.RiderID,
+		PickupLat:      original.PickupLat,
+		PickupLng:      original.PickupLng,
+		DestinationLat: original.DestinationLat,
+		DestinationLng: original.DestinationLng,
+		Tier:           original.Tier,
+		PaymentMethod:  original.PaymentMethod,
+		Type:           original.Type,
+		Capabilities:   original.Capabilities,
+		RebookedFrom:   original.ID,
+	})
 }
 
-func isValidLongitude(lng float64) bool {
-	return lng >= -180 && lng <= 180
+// AssignedDriver returns the driver assigned to a ride, for showing to the
+// rider. It is nil when the ride is not currently ASSIGNED or the driver
+// cannot be loaded; the ride itself is still valid in that case.
+func (s *RideService) AssignedDriver(ctx context.Context, ride *domain.Ride) *domain.Driver {
+	if ride.Status != domain.RideStatusAssigned || ride.AssignedDriverID == "" {
+		return nil
+	}
+	driver, err := s.matchingService.GetDriver(ctx, ride.AssignedDriverID)
+	if err != nil {
+		log.Printf("[RIDE] Failed to load assigned driver %s for ride %s: %v", ride.AssignedDriverID, ride.ID, err)
+		return nil
+	}
+	return driver
 }
 
 // CancelRideRequest contains the parameters for cancelling a ride.
@@ -175,54 +496,102 @@ type CancelRideRequest struct {
 	Reason      string
 }
 
+// cancelRideMaxAttempts bounds reload-and-retry when CancelRide loses a
+// version race with another update to the same ride.
+const cancelRideMaxAttempts = 3
+
 // CancelRide cancels a ride request.
+// If the ride is updated concurrently (e.g. assigned), it is reloaded and the
+// cancellation re-evaluated against the fresh state.
 func (s *RideService) CancelRide(ctx context.Context, req CancelRideRequest) (*domain.Ride, error) {
 	if req.RideID == "" {
 		return nil, ErrInvalidRideID
 	}
 
-	ride, err := s.rideRepo.GetByID(ctx, req.RideID)
-	if err != nil {
-		return nil, err
-	}
+	for attempt := 1; ; attempt++ {
+		ride, err := s.rideRepo.GetByID(ctx, req.RideID)
+		if err != nil {
+			return nil, err
+		}
 
-	// Check if ride can be cancelled
-	if ride.Status == domain.RideStatusCancelled {
-		return nil, ErrRideAlreadyCancelled
-	}
+		// Check if ride can be cancelled
+		if ride.Status == domain.RideStatusCancelled {
+			return nil, ErrRideAlreadyCancelled
+		}
 
-	// Only REQUESTED and ASSIGNED rides can be cancelled
-	// If there's an active trip, it cannot be cancelled
-	if ride.Status != domain.RideStatusRequested && ride.Status != domain.RideStatusAssigned {
-		return nil, ErrRideCannotBeCancelled
-	}
+		// Only REQUESTED and ASSIGNED rides can be cancelled. A ride with an
+		// active trip is cancelled by aborting the trip instead.
+		if ride.Status == domain.RideStatusInTrip || !ride.CanTransitionTo(domain.RideStatusCancelled) {
+			return nil, ErrRideCannotBeCancelled
+		}
 
-	// Update ride status
-	ride.Status = domain.RideStatusCancelled
-	ride.CancelledAt = time.Now()
-	ride.CancelReason = req.Reason
+		// Update ride status
+		ride.Status = domain.RideStatusCancelled
+		ride.CancelledAt = time.Now()
+		ride.CancelReason = req.Reason
 
-	if err := s.rideRepo.Update(ctx, ride); err != nil {
-		return nil, err
+		if err := ride.Validate(); err != nil {
+			return nil, err
+		}
+
+		err = s.rideRepo.Update(ctx, ride)
+		if errors.Is(err, repository.ErrVersionConflict) && attempt < cancelRideMaxAttempts {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		s.queue.Left(ctx, ride.ID)
+
+		// Send notification to affected party
+		if s.notificationService != nil {
+			_ = s.notificationService.NotifyRideCancelled(ctx, ride, req.CancelledBy, req.Reason)
+		}
+
+		return ride, nil
 	}
+}
 
-	// Send notification to affected party
-	if s.notificationService != nil {
-		_ = s.notificationService.NotifyRideCancelled(ctx, ride, req.CancelledBy, req.Reason)
+// ValidatePaymentMethod parses a payment method the catalog offers, any
+// case. Empty means the catalog's default. A nil catalog means the default
+// catalog.
+func ValidatePaymentMethod(method string, catalog *domain.Catalog) (domain.PaymentMethod, error) {
+	if catalog == nil {
+		catalog = domain.DefaultCatalog()
+	}
+	m, ok := catalog.PaymentMethod(method)
+	if !ok {
+		return "", ErrInvalidPaymentMethod
 	}
+	return m, nil
+}
 
-	return ride, nil
+// ValidateTier parses a tier the catalog offers, any case. Empty means the
+// catalog's default. A nil catalog means the default catalog.
+func ValidateTier(tier string, catalog *domain.Catalog) (domain.DriverTier, error) {
+	if catalog == nil {
+		catalog = domain.DefaultCatalog()
+	}
+	spec, ok := catalog.Tier(tier)
+	if !ok {
+		return "", ErrInvalidTier
+	}
+	return spec.Name, nil
 }
 
-// ValidatePaymentMethod validates a payment method string.
-func ValidatePaymentMethod(method string) (domain.PaymentMethod, error) {
-	switch domain.PaymentMethod(method) {
-	case domain.PaymentMethodCash, domain.PaymentMethodCard,
-		domain.PaymentMethodWallet, domain.PaymentMethodUPI:
-		return domain.PaymentMethod(method), nil
-	case "":
-		return domain.PaymentMethodCash, nil // Default to cash
-	default:
-		return "", ErrInvalidPaymentMethod
+// ValidateRideType parses a ride type, any case. Empty means PASSENGER.
+func ValidateRideType(rideType string) (domain.RideType, error) {
+	if strings.TrimSpace(rideType) == "" {
+		return domain.RideTypePassenger, nil
+	}
+	t := domain.RideType(strings.ToUpper(strings.TrimSpace(rideType)))
+	if !t.IsValid() {
+		return "", ErrInvalidRideType
 	}
+	return t, nil
+}
+
+// ValidateCapabilities parses a set of vehicle capabilities, any case.
+func ValidateCapabilities(capabilities []string) ([]string, error) {
+	return domain.NormalizeCapabilities(capabilities)
 }
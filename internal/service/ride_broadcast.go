@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/redis"
+	"ride/internal/repository"
+)
+
+const (
+	defaultBroadcastDrivers  = 5
+	defaultBroadcastCooldown = time.Minute
+
+	// broadcastSearchLimit caps the drivers looked up around the pickup.
+	broadcastSearchLimit = 50
+)
+
+// RideBroadcastService tells nearby drivers that a ride was requested, so
+// drivers who were not offered it (locked, on break, or simply further
+// away) know demand is high around them.
+type RideBroadcastService struct {
+	locationStore       redis.LocationStoreInterface
+	driverRepo          repository.DriverRepository
+	throttle            redis.NotificationThrottleStoreInterface
+	notificationService *NotificationService
+	maxDrivers          int           // Nearest drivers told about each request
+	radiusKm            float64       // Search radius around the pickup
+	cooldown            time.Duration // Minimum gap between broadcasts to one driver
+}
+
+// NewRideBroadcastService creates a new RideBroadcastService. Non-positive
+// values use the defaults: 5 drivers within the matching radius, at most one
+// broadcast per driver per minute.
+func NewRideBroadcastService(
+	locationStore redis.LocationStoreInterface,
+	driverRepo repository.DriverRepository,
+	throttle redis.NotificationThrottleStoreInterface,
+	notificationService *NotificationService,
+	maxDrivers int,
+	radiusKm float64,
+	cooldown time.Duration,
+) *RideBroadcastService {
+	if maxDrivers <= 0 {
+		maxDrivers = defaultBroadcastDrivers
+	}
+	if radiusKm <= 0 {
+		radiusKm = defaultSearchRadiusKm
+	}
+	if cooldown <= 0 {
+		cooldown = defaultBroadcastCooldown
+	}
+	return &RideBroadcastService{
+		locationStore:       locationStore,
+		driverRepo:          driverRepo,
+		throttle:            throttle,
+		notificationService: notificationService,
+		maxDrivers:          maxDrivers,
+		radiusKm:            radiusKm,
+		cooldown:            cooldown,
+	}
+}
+
+// Broadcast notifies the nearest ONLINE or BREAK drivers to the ride's
+// pickup, other than assignedDriverID, that it was requested. Drivers
+// notified within the cooldown are skipped rather than replaced by the next
+// nearest. Failures are logged: a missed broadcast never fails the ride.
+func (s *RideBroadcastService) Broadcast(ctx context.Context, ride *domain.Ride, assignedDriverID string) {
+	nearby, err := s.locationStore.FindNearbyDrivers(ctx, ride.PickupLat, ride.PickupLng, s.radiusKm, broadcastSearchLimit)
+	if err != nil {
+		log.Printf("[BROADCAST] ride=%s: failed to find nearby drivers: %v", ride.ID, err)
+		return
+	}
+
+	var recipients []string
+	picked := 0
+	for _, loc := range nearby {
+		if picked == s.maxDrivers {
+			break
+		}
+		if loc.DriverID == assignedDriverID {
+			continue
+		}
+		driver, err := s.driverRepo.GetByID(ctx, loc.DriverID)
+		if err != nil {
+			continue // Stale location entry
+		}
+		if driver.Status != domain.DriverStatusOnline && driver.Status != domain.DriverStatusBreak {
+			continue
+		}
+		picked++
+
+		ok, err := s.throttle.AcquireNotifySlot(ctx, driver.ID, string(NotificationRideRequested), s.cooldown)
+		if err != nil {
+			log.Printf("[BROADCAST] ride=%s driver=%s: failed to check cooldown: %v", ride.ID, driver.ID, err)
+			continue
+		}
+		if ok {
+			recipients = append(recipients, driver.ID)
+		}
+	}
+
+	if len(recipients) > 0 {
+		_ = s.notificationService.NotifyRideRequested(ctx, ride, recipients)
+	}
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/redis"
+)
+
+// defaultQueueThroughputWindow is used when the configured window is not
+// positive.
+const defaultQueueThroughputWindow = 15 * time.Minute
+
+// queuePriorityOffset is subtracted from a priority ride's queue score so it
+// sorts ahead of every other ride, as RetryUnmatched matches them first.
+// Scores are Unix milliseconds, well below it.
+const queuePriorityOffset = 1e13
+
+// RideQueueService tracks rides waiting for a driver in per-area FIFO
+// queues, the areas being the grid cells matches are limited per, so a
+// rider can be shown their place and an estimated wait. The queues follow
+// the order RetryUnmatched retries rides in: priority rides first, then by
+// creation time.
+type RideQueueService struct {
+	store  redis.RideQueueStoreInterface
+	window time.Duration // Matches this recent set an area's throughput
+	now    func() time.Time
+}
+
+// NewRideQueueService creates a new RideQueueService. A nil now means
+// time.Now.
+func NewRideQueueService(store redis.RideQueueStoreInterface, window time.Duration, now func() time.Time) *RideQueueService {
+	if window <= 0 {
+		window = defaultQueueThroughputWindow
+	}
+	if now == nil {
+		now = time.Now
+	}
+	return &RideQueueService{store: store, window: window, now: now}
+}
+
+// QueuePosition is a waiting ride's place in its area's queue.
+type QueuePosition struct {
+	Position      int           // 1 for the next ride to be matched
+	EstimatedWait time.Duration // Zero when the area had no recent matches to estimate from
+}
+
+// Enqueue adds a ride that found no driver to its area's queue. A ride
+// already queued keeps its place. Failures are logged: the ride is still
+// retried, only its position is unknown.
+func (s *RideQueueService) Enqueue(ctx context.Context, ride *domain.Ride) {
+	if s == nil {
+		return
+	}
+	score := float64(ride.CreatedAt.UnixMilli())
+	if ride.Priority {
+		score -= queuePriorityOffset
+	}
+	if err := s.store.Enqueue(ctx, matchAreaCell(ride.PickupLat, ride.PickupLng), ride.ID, score); err != nil {
+		log.Printf("[QUEUE] Failed to queue ride %s: %v", ride.ID, err)
+	}
+}
+
+// Matched takes a matched ride out of its queue and counts it towards the
+// area's throughput.
+func (s *RideQueueService) Matched(ctx context.Context, rideID string) {
+	if s == nil {
+		return
+	}
+	area, err := s.store.Remove(ctx, rideID)
+	if err != nil {
+		log.Printf("[QUEUE] Failed to dequeue matched ride %s: %v", rideID, err)
+		return
+	}
+	if area == "" {
+		return
+	}
+	if err := s.store.RecordMatch(ctx, area, rideID, s.now(), s.window); err != nil {
+		log.Printf("[QUEUE] Failed to record match of ride %s: %v", rideID, err)
+	}
+}
+
+// Left takes a ride that stopped waiting without a match, e.g. cancelled,
+// out of its queue, so the rides behind it move up.
+func (s *RideQueueService) Left(ctx context.Context, rideID string) {
+	if s == nil {
+		return
+	}
+	if _, err := s.store.Remove(ctx, rideID); err != nil {
+		log.Printf("[QUEUE] Failed to dequeue ride %s: %v", rideID, err)
+	}
+}
+
+// Position returns a ride's place in its queue, or nil when it is not
+// waiting in one. The wait is the position divided by the rate at which
+// the area's queued rides were matched over the window.
+func (s *RideQueueService) Position(ctx context.Context, ride *domain.Ride) (*QueuePosition, error) {
+	if s == nil || ride.Status != domain.RideStatusRequested {
+		return nil, nil
+	}
+	area, ahead, ok, err := s.store.Position(ctx, ride.ID)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	position := &QueuePosition{Position: int(ahead) + 1}
+	matches, err := s.store.MatchesSince(ctx, area, s.now().Add(-s.window))
+	if err != nil {
+		return nil, err
+	}
+	if matches > 0 {
+		position.EstimatedWait = time.Duration(int64(position.Position) * int64(s.window) / matches)
+	}
+	return position, nil
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/repository"
+)
+
+const (
+	defaultSummaryInterval  = time.Hour // Used when the configured interval is not positive
+	defaultLeaderboardLimit = 50        // Leaderboard entries returned when no limit is given
+)
+
+// DriverSummaryService generates drivers' weekly summaries, sends each
+// driver theirs, and serves them and the city leaderboards. Drivers are
+// ranked within the city they registered with.
+type DriverSummaryService struct {
+	summaryRepo         repository.DriverSummaryRepository
+	notificationService *NotificationService // Optional: nil skips notifying drivers
+	city                string               // The city this deployment serves; drivers without a city are ranked in it
+	commissionPercent   float64              // Deducted from fares when totalling earnings
+}
+
+// NewDriverSummaryService creates a new DriverSummaryService.
+func NewDriverSummaryService(
+	summaryRepo repository.DriverSummaryRepository,
+	notificationService *NotificationService,
+	city string,
+	commissionPercent float64,
+) *DriverSummaryService {
+	if commissionPercent < 0 || commissionPercent > 100 {
+		commissionPercent = defaultCommissionPercent
+	}
+
+	return &DriverSummaryService{
+		summaryRepo:         summaryRepo,
+		notificationService: notificationService,
+		city:                domain.NormalizeCity(city),
+		commissionPercent:   commissionPercent,
+	}
+}
+
+// City returns the city this deployment serves.
+func (s *DriverSummaryService) City() string {
+	return s.city
+}
+
+// ParseWeek returns the start of the week containing a YYYY-MM-DD date. An
+// empty value means the last complete week before now.
+func ParseWeek(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return domain.WeekStart(now).AddDate(0, 0, -7), nil
+	}
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, ErrInvalidWeek
+	}
+	return domain.WeekStart(date), nil
+}
+
+// GenerateWeek computes the summaries for the week containing weekStart and
+// notifies each driver of theirs. Regenerating a week refreshes its
+// summaries; drivers already notified are not notified again.
+func (s *DriverSummaryService) GenerateWeek(ctx context.Context, weekStart time.Time) ([]*domain.DriverWeeklySummary, error) {
+	if weekStart.IsZero() {
+		return nil, ErrInvalidWeek
+	}
+	weekStart = domain.WeekStart(weekStart)
+
+	now := time.Now()
+	summaries, err := s.summaryRepo.GenerateWeek(ctx, weekStart, s.city, s.commissionPercent, now)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, summary := range summaries {
+		if !summary.NotifiedAt.IsZero() || s.notificationService == nil {
+			continue
+		}
+		// Claim the notification first, so concurrent generations send it once.
+		claimed, err := s.summaryRepo.MarkNotified(ctx, summary.DriverID, weekStart, now)
+		if err != nil {
+			log.Printf("[SUMMARY] Failed to mark driver %s notified: %v", summary.DriverID, err)
+			continue
+		}
+		if claimed {
+			summary.NotifiedAt = now
+			_ = s.notificationService.NotifyWeeklySummary(ctx, summary)
+		}
+	}
+
+	return summaries, nil
+}
+
+// Summary returns a driver's summary for the week containing weekStart.
+func (s *DriverSummaryService) Summary(ctx context.Context, driverID string, weekStart time.Time) (*domain.DriverWeeklySummary, error) {
+	if driverID == "" {
+		return nil, ErrInvalidDriverID
+	}
+	return s.summaryRepo.Get(ctx, driverID, domain.WeekStart(weekStart))
+}
+
+// Leaderboard returns the best-ranked summaries for the week containing
+// weekStart in a city, this deployment's when empty. Drivers sharing a rank
+// are ordered by ID.
+func (s *DriverSummaryService) Leaderboard(ctx context.Context, weekStart time.Time, city string, limit int) ([]*domain.DriverWeeklySummary, error) {
+	city = domain.NormalizeCity(city)
+	if city == "" {
+		city = s.city
+	}
+	if limit <= 0 {
+		limit = defaultLeaderboardLimit
+	}
+
+	return s.summaryRepo.Leaderboard(ctx, domain.WeekStart(weekStart), city, limit)
+}
+
+// WeeklySummaryJob periodically generates the summaries for the last
+// complete week, once per week per instance. Generation is idempotent, so
+// instances racing on the same week are harmless.
+type WeeklySummaryJob struct {
+	summaryService *DriverSummaryService
+	interval       time.Duration
+	lastWeek       time.Time // Last week generated; only touched by run
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewWeeklySummaryJob creates a WeeklySummaryJob and starts its background
+// loop. Call Close on shutdown to stop it.
+func NewWeeklySummaryJob(summaryService *DriverSummaryService, interval time.Duration) *WeeklySummaryJob {
+	if interval <= 0 {
+		interval = defaultSummaryInterval
+	}
+
+	j := &WeeklySummaryJob{
+		summaryService: summaryService,
+		interval:       interval,
+		stop:           make(chan struct{}),
+		done:           make(chan struct{}),
+	}
+	go j.run()
+	return j
+}
+
+// Close stops the background loop, waiting for a generation in progress.
+func (j *WeeklySummaryJob) Close() {
+	j.closeOnce.Do(func() { close(j.stop) })
+	<-j.done
+}
+
+// run generates the last complete week's summaries on the first interval
+// after it ends.
+func (j *WeeklySummaryJob) run() {
+	defer close(j.done)
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			week := domain.WeekStart(time.Now()).AddDate(0, 0, -7)
+			if week.Equal(j.lastWeek) {
+				continue
+			}
+			summaries, err := j.summaryService.GenerateWeek(context.Background(), week)
+			if err != nil {
+				log.Printf("[SUMMARY] Failed to generate summaries for week of %s: %v", week.Format("2006-01-02"), err)
+				continue
+			}
+			j.lastWeek = week
+			log.Printf("[SUMMARY] Generated %d summaries for week of %s", len(summaries), week.Format("2006-01-02"))
+		case <-j.stop:
+			return
+		}
+	}
+}
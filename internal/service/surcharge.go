@@ -0,0 +1,41 @@
+package service
+
+import (
+	"strings"
+
+	"ride/internal/domain"
+)
+
+// SurchargeService adds zone surcharges, such as airport fees, to rides.
+type SurchargeService struct {
+	zones []domain.SurchargeZone
+}
+
+// NewSurchargeService creates a new SurchargeService. Zones without a
+// positive amount are ignored.
+func NewSurchargeService(zones []domain.SurchargeZone) *SurchargeService {
+	active := make([]domain.SurchargeZone, 0, len(zones))
+	for _, zone := range zones {
+		if zone.Amount > 0 {
+			active = append(active, zone)
+		}
+	}
+	return &SurchargeService{zones: active}
+}
+
+// Apply sets the ride's surcharge from the zones its pickup or destination
+// falls in. Each zone applies at most once, so a ride within a single zone
+// pays once; when several zones match, amounts add up and labels are joined.
+func (s *SurchargeService) Apply(ride *domain.Ride) {
+	var labels []string
+	var amount float64
+	for _, zone := range s.zones {
+		if zone.Contains(ride.PickupLat, ride.PickupLng) || zone.Contains(ride.DestinationLat, ride.DestinationLng) {
+			labels = append(labels, zone.Label)
+			amount += zone.Amount
+		}
+	}
+
+	ride.SurchargeLabel = strings.Join(labels, ", ")
+	ride.SurchargeAmount = amount
+}
@@ -2,25 +2,65 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"log"
+	"math"
+	"time"
 
+	"ride/internal/domain"
 	"ride/internal/redis"
 	"ride/internal/repository"
 )
 
+const (
+	defaultSurgeSmoothing    = 0.5              // Used when the configured factor is outside (0, 1]
+	defaultSurgeSmoothingTTL = 10 * time.Minute // Used when the configured TTL is not positive
+	surgeCellDegrees         = 0.01             // Side of a smoothing cell, about 1km
+	surgeSnapEpsilon         = 0.01             // Smoothed values this close to the target snap to it
+)
+
 // SurgeService calculates surge pricing based on supply and demand.
 type SurgeService struct {
 	locationStore redis.LocationStoreInterface
 	rideRepo      repository.RideRepository
+	surgeStore    redis.SurgeStoreInterface // Nil disables smoothing
+	smoothing     float64                   // Weight of the new multiplier when blending, in (0, 1]
+	smoothingTTL  time.Duration             // How long a cell's smoothed multiplier is remembered
+	floors        []domain.SurgeFloor       // Scheduled minimum multipliers, e.g. during the morning peak
+	now           func() time.Time          // Clock the floors are read against, in their time zone
 }
 
-// NewSurgeService creates a new SurgeService.
+// NewSurgeService creates a new SurgeService. With a surgeStore, multipliers
+// are smoothed per geo cell (see GetMultiplier); a smoothing factor outside
+// (0, 1] and a non-positive TTL use the defaults. Floors are read against
+// now, which defaults to time.Now in the server's time zone when nil.
 func NewSurgeService(
 	locationStore redis.LocationStoreInterface,
 	rideRepo repository.RideRepository,
+	surgeStore redis.SurgeStoreInterface,
+	smoothing float64,
+	smoothingTTL time.Duration,
+	floors []domain.SurgeFloor,
+	now func() time.Time,
 ) *SurgeService {
+	if smoothing <= 0 || smoothing > 1 {
+		smoothing = defaultSurgeSmoothing
+	}
+	if smoothingTTL <= 0 {
+		smoothingTTL = defaultSurgeSmoothingTTL
+	}
+	if now == nil {
+		now = time.Now
+	}
+
 	return &SurgeService{
 		locationStore: locationStore,
 		rideRepo:      rideRepo,
+		surgeStore:    surgeStore,
+		smoothing:     smoothing,
+		smoothingTTL:  smoothingTTL,
+		floors:        floors,
+		now:           now,
 	}
 }
 
@@ -46,31 +86,97 @@ func DefaultSurgeConfig() SurgeConfig {
 
 // GetMultiplier calculates the surge multiplier for a given location.
 // Returns 1.0 if no surge, up to MaxSurge (default 2.0) if high demand.
+// Fails open to 1.0 when supply cannot be counted. With smoothing, the
+// result moves only part of the way from the location's previous multiplier
+// towards the computed one, so surge ramps instead of jumping. Inside a
+// scheduled floor's window the result is at least the floor.
 func (s *SurgeService) GetMultiplier(ctx context.Context, lat, lng float64) float64 {
+	return math.Max(s.demandMultiplier(ctx, lat, lng), s.floor(s.now()))
+}
+
+// floor returns the highest scheduled floor covering t, or 1.0 outside
+// every window. The floor is applied after smoothing and never stored, so
+// surge returns to the demand-based value as soon as a window closes.
+func (s *SurgeService) floor(t time.Time) float64 {
+	floor := 1.0
+	for _, f := range s.floors {
+		if f.Covers(t) && f.Multiplier > floor {
+			floor = f.Multiplier
+		}
+	}
+	return floor
+}
+
+// demandMultiplier is the smoothed multiplier from supply and demand alone.
+func (s *SurgeService) demandMultiplier(ctx context.Context, lat, lng float64) float64 {
 	config := DefaultSurgeConfig()
 
 	// Get supply: count online drivers in the area
-	supply := s.countDriversInArea(ctx, lat, lng, config.RadiusKm)
+	supply, err := s.countDriversInArea(ctx, lat, lng, config.RadiusKm)
+	if err != nil {
+		return 1.0
+	}
 
 	// Get demand: count active ride requests in the area
 	demand := s.countActiveRequestsInArea(ctx, lat, lng, config.RadiusKm)
 
+	// A cancelled scan counted only part of the demand; don't let it move
+	// the cell's smoothed multiplier.
+	if ctx.Err() != nil {
+		return 1.0
+	}
+
 	// Calculate surge based on demand/supply ratio
-	return s.calculateSurgeMultiplier(supply, demand, config)
+	multiplier := s.calculateSurgeMultiplier(supply, demand, config)
+	return s.smooth(ctx, lat, lng, multiplier)
+}
+
+// smooth blends multiplier with the previous smoothed multiplier of the
+// location's geo cell by exponential smoothing and stores the result. A cell
+// with no multiplier within the TTL starts from 1.0. Without a store, or if
+// Redis fails, multiplier is returned as is.
+func (s *SurgeService) smooth(ctx context.Context, lat, lng, multiplier float64) float64 {
+	if s.surgeStore == nil {
+		return multiplier
+	}
+
+	cell := surgeCell(lat, lng)
+	previous, ok, err := s.surgeStore.GetMultiplier(ctx, cell)
+	if err != nil {
+		log.Printf("[SURGE] Smoothing skipped for cell %s: %v", cell, err)
+		return multiplier
+	}
+	if !ok {
+		previous = 1.0
+	}
+
+	smoothed := s.smoothing*multiplier + (1-s.smoothing)*previous
+	if math.Abs(smoothed-multiplier) < surgeSnapEpsilon {
+		smoothed = multiplier
+	}
+	if err := s.surgeStore.SetMultiplier(ctx, cell, smoothed, s.smoothingTTL); err != nil {
+		log.Printf("[SURGE] Failed to store smoothed multiplier for cell %s: %v", cell, err)
+	}
+	return smoothed
+}
+
+// surgeCell names the grid cell containing the point.
+func surgeCell(lat, lng float64) string {
+	return fmt.Sprintf("%d:%d", int(math.Floor(lat/surgeCellDegrees)), int(math.Floor(lng/surgeCellDegrees)))
 }
 
 // countDriversInArea returns the number of online drivers within radius.
-func (s *SurgeService) countDriversInArea(ctx context.Context, lat, lng, radiusKm float64) int {
-	drivers, err := s.locationStore.FindNearbyDrivers(ctx, lat, lng, radiusKm)
+func (s *SurgeService) countDriversInArea(ctx context.Context, lat, lng, radiusKm float64) (int, error) {
+	drivers, err := s.locationStore.FindNearbyDrivers(ctx, lat, lng, radiusKm, 0)
 	if err != nil {
-		// On error, assume no surge (fail open)
-		return 10 // Return a reasonable default to avoid false surge
+		return 0, err
 	}
-	return len(drivers)
+	return len(drivers), nil
 }
 
 // countActiveRequestsInArea returns the number of active ride requests in area.
 // This is a simplified implementation - in production, you'd use spatial indexing.
+// The scan stops early, with a partial count, once ctx is done.
 func (s *SurgeService) countActiveRequestsInArea(ctx context.Context, lat, lng, radiusKm float64) int {
 	rides, err := s.rideRepo.GetAll(ctx)
 	if err != nil {
@@ -79,6 +185,10 @@ func (s *SurgeService) countActiveRequestsInArea(ctx context.Context, lat, lng,
 
 	count := 0
 	for _, ride := range rides {
+		if ctx.Err() != nil {
+			break
+		}
+
 		// Only count REQUESTED or ASSIGNED rides (active)
 		if ride.Status == "CANCELLED" {
 			continue
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+
+	"ride/internal/repository"
+)
+
+// TrackerKeyService issues the secrets that drivers' embedded location
+// trackers sign their updates with, and serves the signing keys the tracker
+// authentication middleware verifies them by. A tracker's signing key is
+// HashTrackerSecret of its secret. Secrets are stored sealed with AES-GCM
+// under the configured encryption key and bound to their driver, so the
+// stored value is useless for signing without that key.
+type TrackerKeyService struct {
+	keys       repository.TrackerKeyRepository
+	driverRepo repository.DriverRepository
+	aead       cipher.AEAD // Nil when no encryption key is configured
+}
+
+// NewTrackerKeyService creates a new TrackerKeyService. The AES-256 key is
+// the SHA-256 of encryptionKey, which must match across instances. Without
+// one, issuing secrets and verifying signed requests return
+// ErrTrackerKeysUnavailable.
+func NewTrackerKeyService(keys repository.TrackerKeyRepository, driverRepo repository.DriverRepository, encryptionKey string) *TrackerKeyService {
+	s := &TrackerKeyService{keys: keys, driverRepo: driverRepo}
+	if encryptionKey == "" {
+		return s
+	}
+
+	key := sha256.Sum256([]byte(encryptionKey))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		panic(err)
+	}
+	if s.aead, err = cipher.NewGCM(block); err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// Issue generates a new tracker secret for the driver, replacing any earlier
+// one, and returns it. It cannot be retrieved again.
+func (s *TrackerKeyService) Issue(ctx context.Context, driverID string) (string, error) {
+	if driverID == "" {
+		return "", ErrInvalidDriverID
+	}
+
+	if s.aead == nil {
+		return "", ErrTrackerKeysUnavailable
+	}
+
+	if _, err := s.driverRepo.GetByID(ctx, driverID); err != nil {
+		return "", err
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	secret := hex.EncodeToString(raw)
+
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := s.aead.Seal(nonce, nonce, []byte(secret), []byte(driverID))
+
+	if err := s.keys.Set(ctx, driverID, hex.EncodeToString(sealed)); err != nil {
+		return "", err
+	}
+	return secret, nil
+}
+
+// SigningKey returns the key the driver's tracker signs with, or "" if none
+// was issued. Drivers without a key are told so even when no encryption key
+// is configured, so their unsigned updates keep working.
+func (s *TrackerKeyService) SigningKey(ctx context.Context, driverID string) (string, error) {
+	stored, err := s.keys.GetSecret(ctx, driverID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if s.aead == nil {
+		return "", ErrTrackerKeysUnavailable
+	}
+
+	sealed, err := hex.DecodeString(stored)
+	if err != nil || len(sealed) < s.aead.NonceSize() {
+		return "", ErrTrackerSecretUnreadable
+	}
+	nonce, ciphertext := sealed[:s.aead.NonceSize()], sealed[s.aead.NonceSize():]
+	secret, err := s.aead.Open(nil, nonce, ciphertext, []byte(driverID))
+	if err != nil {
+		return "", ErrTrackerSecretUnreadable
+	}
+	return HashTrackerSecret(string(secret)), nil
+}
+
+// HashTrackerSecret returns the hex SHA-256 of a tracker secret, the key the
+// tracker signs with.
+func HashTrackerSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
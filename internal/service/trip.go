@@ -3,44 +3,134 @@ package service
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"log"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
 	"ride/internal/domain"
+	"ride/internal/redis"
 	"ride/internal/repository"
 	"ride/internal/repository/postgres"
 )
 
+const (
+	defaultMinFare = 5.0   // Used when the configured minimum is not positive
+	defaultMaxFare = 200.0 // Used when the configured cap is not positive
+
+	defaultPickupGeofenceKm = 0.5 // Used when the configured geofence is not positive
+
+	defaultArrivalRadiusKm = 0.075 // Used when the configured arrival radius is not positive
+	defaultArrivalPings    = 2     // Used when the configured ping count is not positive
+)
+
+// AbortFarePolicy decides what the rider pays when the driver aborts a trip.
+type AbortFarePolicy string
+
+const (
+	AbortFareNone    AbortFarePolicy = "NONE"    // Nothing is charged
+	AbortFareElapsed AbortFarePolicy = "ELAPSED" // The elapsed-time fare is charged, as for a rider abort
+)
+
 // TripService handles trip operations.
 type TripService struct {
 	db                  *sql.DB
 	tripRepo            repository.TripRepository
 	rideRepo            repository.RideRepository
 	driverRepo          repository.DriverRepository
+	locationStore       redis.LocationStoreInterface // Optional: nil disables the pickup geofence
 	paymentService      *PaymentService
 	notificationService *NotificationService
 	receiptService      *ReceiptService
+	campaignService     *CampaignService
+	fares               *FareSchedule // Per-tier metered rates and minimums
+	maxFare             float64       // Fares above this are capped and held for review
+	pickupGeofenceKm    float64       // Furthest a driver may be from pickup when starting a trip
+	driverAbortFare     AbortFarePolicy
+	publisher           EventPublisher
+	arrivalStore        redis.ArrivalStoreInterface         // Optional: nil disables arrival detection
+	arrivalRadiusKm     float64                             // Distance from pickup that counts as arrived
+	arrivalPings        int                                 // In-fence pings in a row before marking arrival
+	attachmentRepo      repository.TripAttachmentRepository // Optional: nil skips the proof-of-delivery check
+	estimator           *EstimatorService                   // Optional: nil estimates durations at the average speed
+	fareSplits          repository.FareSplitRepository      // Optional: nil disables fare splitting
+	fareAdjustments     repository.FareAdjustmentRepository // Optional: nil disables fare adjustments
+	confirmationWindow  time.Duration                       // How long an ended CARD trip waits for the rider; 0 settles at once
+}
+
+// TripServiceDeps holds what a TripService is built from.
+type TripServiceDeps struct {
+	DB                  *sql.DB
+	TripRepo            repository.TripRepository
+	RideRepo            repository.RideRepository
+	DriverRepo          repository.DriverRepository
+	LocationStore       redis.LocationStoreInterface // Optional: nil disables the pickup geofence
+	PaymentService      *PaymentService
+	NotificationService *NotificationService
+	ReceiptService      *ReceiptService
+	CampaignService     *CampaignService
+	MinFare             float64 // Minimum fare of tiers without their own
+	MaxFare             float64 // Fares above this are capped and held for review; 0 uses the default
+	PickupGeofenceKm    float64 // Furthest a driver may be from pickup when starting a trip; 0 uses the default
+	DriverAbortFare     AbortFarePolicy
+	Catalog             *domain.Catalog
+	Publisher           EventPublisher                      // Optional: nil publishes nothing
+	ArrivalStore        redis.ArrivalStoreInterface         // Optional: nil disables arrival detection
+	ArrivalRadiusKm     float64                             // Distance from pickup that counts as arrived; 0 uses the default
+	ArrivalPings        int                                 // In-fence pings in a row before marking arrival; 0 uses the default
+	AttachmentRepo      repository.TripAttachmentRepository // Optional: nil skips the proof-of-delivery check
+	Estimator           *EstimatorService                   // Optional: nil estimates durations at the average speed
+	FareSplits          repository.FareSplitRepository      // Optional: nil disables fare splitting
+	FareAdjustments     repository.FareAdjustmentRepository // Optional: nil disables fare adjustments
+	ConfirmationWindow  time.Duration                       // How long an ended CARD trip waits for the rider to confirm; 0 settles at once
 }
 
 // NewTripService creates a new TripService.
-func NewTripService(
-	db *sql.DB,
-	tripRepo repository.TripRepository,
-	rideRepo repository.RideRepository,
-	driverRepo repository.DriverRepository,
-	paymentService *PaymentService,
-	notificationService *NotificationService,
-	receiptService *ReceiptService,
-) *TripService {
+func NewTripService(deps TripServiceDeps) *TripService {
+	if deps.MaxFare <= 0 {
+		deps.MaxFare = defaultMaxFare
+	}
+	if deps.PickupGeofenceKm <= 0 {
+		deps.PickupGeofenceKm = defaultPickupGeofenceKm
+	}
+	if deps.DriverAbortFare != AbortFareElapsed {
+		deps.DriverAbortFare = AbortFareNone
+	}
+	if deps.Publisher == nil {
+		deps.Publisher = NoopEventPublisher{}
+	}
+	if deps.ArrivalRadiusKm <= 0 {
+		deps.ArrivalRadiusKm = defaultArrivalRadiusKm
+	}
+	if deps.ArrivalPings <= 0 {
+		deps.ArrivalPings = defaultArrivalPings
+	}
+
 	return &TripService{
-		db:                  db,
-		tripRepo:            tripRepo,
-		rideRepo:            rideRepo,
-		driverRepo:          driverRepo,
-		paymentService:      paymentService,
-		notificationService: notificationService,
-		receiptService:      receiptService,
+		db:                  deps.DB,
+		tripRepo:            deps.TripRepo,
+		rideRepo:            deps.RideRepo,
+		driverRepo:          deps.DriverRepo,
+		locationStore:       deps.LocationStore,
+		paymentService:      deps.PaymentService,
+		notificationService: deps.NotificationService,
+		receiptService:      deps.ReceiptService,
+		campaignService:     deps.CampaignService,
+		fares:               NewFareSchedule(deps.Catalog, deps.MinFare),
+		maxFare:             deps.MaxFare,
+		pickupGeofenceKm:    deps.PickupGeofenceKm,
+		driverAbortFare:     deps.DriverAbortFare,
+		publisher:           deps.Publisher,
+		arrivalStore:        deps.ArrivalStore,
+		arrivalRadiusKm:     deps.ArrivalRadiusKm,
+		arrivalPings:        deps.ArrivalPings,
+		attachmentRepo:      deps.AttachmentRepo,
+		estimator:           deps.Estimator,
+		fareSplits:          deps.FareSplits,
+		fareAdjustments:     deps.FareAdjustments,
+		confirmationWindow:  deps.ConfirmationWindow,
 	}
 }
 
@@ -48,9 +138,13 @@ func NewTripService(
 type StartTripRequest struct {
 	RideID   string
 	DriverID string
+	Override bool // Skip the pickup geofence, e.g. when GPS is unreliable at the pickup
 }
 
-// StartTrip creates a new trip when a driver accepts a ride.
+// StartTrip creates a new trip when a driver accepts a ride. The driver must
+// be within the pickup geofence unless the request overrides it. A CARD
+// ride's estimated fare is held on the card first; a decline returns an
+// error wrapping ErrPaymentAuthorizationDeclined and the trip does not start.
 func (s *TripService) StartTrip(ctx context.Context, req StartTripRequest) (*domain.Trip, error) {
 	if req.RideID == "" {
 		return nil, ErrInvalidRideID
@@ -76,7 +170,7 @@ func (s *TripService) StartTrip(ctx context.Context, req StartTripRequest) (*dom
 		return nil, err
 	}
 
-	if ride.Status != domain.RideStatusAssigned {
+	if !ride.CanTransitionTo(domain.RideStatusInTrip) {
 		return nil, ErrRideNotAssigned
 	}
 
@@ -84,6 +178,39 @@ func (s *TripService) StartTrip(ctx context.Context, req StartTripRequest) (*dom
 		return nil, ErrDriverNotAssignedToRide
 	}
 
+	if req.Override {
+		log.Printf("[GEOFENCE] Driver %s started ride %s with the pickup geofence overridden", req.DriverID, req.RideID)
+	} else if err = s.checkPickupGeofence(ctx, req.DriverID, ride); err != nil {
+		return nil, err
+	}
+
+	// Create trip in STARTED state.
+	trip := &domain.Trip{
+		ID:        uuid.New().String(),
+		RideID:    req.RideID,
+		DriverID:  req.DriverID,
+		Status:    domain.TripStatusStarted,
+		Fare:      0,
+		StartedAt: time.Now(),
+	}
+
+	if err = trip.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Hold the estimated fare on a card before the rider gets in; a card
+	// that cannot pay keeps the trip from starting.
+	hold, err := s.authorizeFare(ctx, trip, ride)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil && hold != nil {
+			s.paymentService.VoidHold(ctx, hold.AuthID)
+		}
+	}()
+
 	// Use transaction to create trip and update ride status.
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -101,23 +228,24 @@ func (s *TripService) StartTrip(ctx context.Context, req StartTripRequest) (*dom
 	txRideRepo := postgres.NewRideRepositoryWithTx(tx)
 	txDriverRepo := postgres.NewDriverRepositoryWithTx(tx)
 
-	// Create trip in STARTED state.
-	trip := &domain.Trip{
-		ID:        uuid.New().String(),
-		RideID:    req.RideID,
-		DriverID:  req.DriverID,
-		Status:    domain.TripStatusStarted,
-		Fare:      0,
-		StartedAt: time.Now(),
-	}
-
 	if err = txTripRepo.Create(ctx, trip); err != nil {
 		return nil, err
 	}
 
-	// Update ride status to IN_TRIP.
-	ride.Status = domain.RideStatusInTrip
-	if err = txRideRepo.Update(ctx, ride); err != nil {
+	if hold != nil {
+		if err = postgres.NewPaymentRepositoryWithTx(tx).Create(ctx, hold); err != nil {
+			return nil, err
+		}
+	}
+
+	// Move the ride to IN_TRIP only if it is still assigned to this driver,
+	// so a second driver accepting the same ride loses cleanly.
+	started, err := txRideRepo.StartIfAssigned(ctx, ride, req.DriverID)
+	if err != nil {
+		return nil, err
+	}
+	if !started {
+		err = ErrRideNotAssigned
 		return nil, err
 	}
 
@@ -129,10 +257,109 @@ func (s *TripService) StartTrip(ctx context.Context, req StartTripRequest) (*dom
 	if err = tx.Commit(); err != nil {
 		return nil, err
 	}
+	publishEvent(ctx, s.publisher, domain.EventTripStarted, trip.ID, map[string]any{
+		"ride_id":   trip.RideID,
+		"driver_id": trip.DriverID,
+		"rider_id":  ride.RiderID,
+	})
 
 	return trip, nil
 }
 
+// authorizeFare holds the estimated fare of a CARD ride's trip on the
+// rider's card, returning the AUTHORIZED payment to store with the trip.
+// Other payment methods hold nothing and return nil.
+func (s *TripService) authorizeFare(ctx context.Context, trip *domain.Trip, ride *domain.Ride) (*domain.Payment, error) {
+	if ride.PaymentMethod != domain.PaymentMethodCard || s.paymentService == nil {
+		return nil, nil
+	}
+	return s.paymentService.Authorize(ctx, AuthorizeRequest{
+		TripID:       trip.ID,
+		Estimate:     s.estimateFare(ctx, trip, ride),
+		Method:       ride.PaymentMethod,
+		InstrumentID: ride.InstrumentID,
+	})
+}
+
+// estimateFare estimates a trip's fare from its estimated duration.
+func (s *TripService) estimateFare(ctx context.Context, trip *domain.Trip, ride *domain.Ride) float64 {
+	estimate := s.estimator.EstimateDuration(ctx, ride.PickupLat, ride.PickupLng, ride.DestinationLat, ride.DestinationLng)
+	return s.tripFare(trip, ride, trip.StartedAt.Add(estimate.Duration))
+}
+
+// checkPickupGeofence verifies that the driver's latest location is within
+// the pickup geofence of the ride.
+func (s *TripService) checkPickupGeofence(ctx context.Context, driverID string, ride *domain.Ride) error {
+	if s.locationStore == nil {
+		return nil
+	}
+
+	loc, err := s.locationStore.GetLocation(ctx, driverID)
+	if err != nil {
+		return err
+	}
+	if loc == nil {
+		return ErrDriverLocationUnavailable
+	}
+
+	if domain.HaversineKm(loc.Lat, loc.Lng, ride.PickupLat, ride.PickupLng) > s.pickupGeofenceKm {
+		return ErrDriverTooFarFromPickup
+	}
+	return nil
+}
+
+// DetectArrival checks a driver's location ping against the pickup point of
+// the ride they are assigned to. It returns the ride if this ping marked the
+// driver as arrived, or nil.
+//
+// Arrival is marked on the ping that completes the run of consecutive pings
+// within the arrival radius, and only once per ride: a driver whose GPS
+// jitters out of the fence and back in is not announced again. A ping
+// outside the fence resets the run. Rides whose trip has started are no
+// longer ASSIGNED and are never marked.
+func (s *TripService) DetectArrival(ctx context.Context, driverID string, lat, lng float64) (*domain.Ride, error) {
+	if s.arrivalStore == nil {
+		return nil, nil
+	}
+
+	ride, err := s.rideRepo.GetAssignedByDriverID(ctx, driverID)
+	if err != nil {
+		return nil, err
+	}
+	if ride == nil || !ride.ArrivedAt.IsZero() {
+		return nil, nil
+	}
+
+	if domain.HaversineKm(lat, lng, ride.PickupLat, ride.PickupLng) > s.arrivalRadiusKm {
+		return nil, s.arrivalStore.ResetInFence(ctx, ride.ID)
+	}
+
+	count, err := s.arrivalStore.IncrementInFence(ctx, ride.ID)
+	if err != nil {
+		return nil, err
+	}
+	if count < int64(s.arrivalPings) {
+		return nil, nil
+	}
+
+	ride.ArrivedAt = time.Now()
+	if err := s.rideRepo.Update(ctx, ride); err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			// The ride moved on, e.g. the trip started or the rider
+			// cancelled; the next ping sees its new state.
+			return nil, nil
+		}
+		return nil, err
+	}
+	_ = s.arrivalStore.ResetInFence(ctx, ride.ID)
+
+	if s.notificationService != nil {
+		_ = s.notificationService.NotifyDriverArrived(ctx, ride)
+	}
+
+	return ride, nil
+}
+
 // EndTripRequest contains the parameters for ending a trip.
 type EndTripRequest struct {
 	TripID string
@@ -143,6 +370,7 @@ type EndTripResponse struct {
 	Trip    *domain.Trip
 	Payment *domain.Payment
 	Receipt *domain.Receipt
+	Split   *domain.FareSplit // Set when the fare was split between riders; Payment and Receipt are then the ride's rider's
 }
 
 // EndTrip ends a trip, calculates fare, and triggers payment.
@@ -157,7 +385,12 @@ func (s *TripService) EndTrip(ctx context.Context, req EndTripRequest) (*EndTrip
 		return nil, err
 	}
 
-	if trip.Status == domain.TripStatusEnded {
+	if !trip.CanTransitionTo(domain.TripStatusEnded) {
+		// A retried end re-drives campaign progress, which is idempotent, in
+		// case the first attempt failed after the trip was committed.
+		if trip.Status == domain.TripStatusEnded {
+			s.evaluateCampaigns(ctx, trip)
+		}
 		return nil, ErrTripAlreadyEnded
 	}
 
@@ -166,21 +399,44 @@ func (s *TripService) EndTrip(ctx context.Context, req EndTripRequest) (*EndTrip
 		trip.TotalPaused += time.Since(trip.PausedAt)
 	}
 
-	// Get ride to retrieve surge multiplier.
+	// Get ride to retrieve surge multiplier and surcharge.
 	ride, err := s.rideRepo.GetByID(ctx, trip.RideID)
 	if err != nil {
 		return nil, err
 	}
+	if err := s.checkProofOfDelivery(ctx, trip, ride); err != nil {
+		return nil, err
+	}
 
-	// Calculate fare with surge applied.
 	endTime := time.Now()
-	baseFare := s.calculateFare(trip.StartedAt, endTime, trip.TotalPaused)
-	surgeMultiplier := ride.SurgeMultiplier
-	if surgeMultiplier < 1.0 {
-		surgeMultiplier = 1.0 // Default to no surge if not set
+	return s.completeTrip(ctx, trip, ride, endTime, s.tripFare(trip, ride, endTime))
+}
+
+// checkProofOfDelivery returns ErrProofOfDeliveryRequired if the ride is a
+// PACKAGE ride and its driver has not attached a photo to the trip.
+func (s *TripService) checkProofOfDelivery(ctx context.Context, trip *domain.Trip, ride *domain.Ride) error {
+	if !ride.IsPackage() || s.attachmentRepo == nil {
+		return nil
+	}
+	attachments, err := s.attachmentRepo.ListByTrip(ctx, trip.ID)
+	if err != nil {
+		return err
+	}
+	if len(attachments) == 0 {
+		return ErrProofOfDeliveryRequired
 	}
-	fare := baseFare * surgeMultiplier
+	return nil
+}
 
+// tripFare computes the fare for a trip charged up to end: the time-based
+// fare with the tier's multiplier and surge applied, plus any zone surcharge.
+func (s *TripService) tripFare(trip *domain.Trip, ride *domain.Ride, end time.Time) float64 {
+	return s.fares.Fare(ride.Tier, end.Sub(trip.StartedAt)-trip.TotalPaused, ride.SurgeMultiplier, ride.SurchargeAmount)
+}
+
+// completeTrip ends the trip at endTime with the given fare, completes the
+// ride and frees the driver in one transaction, then settles the fare.
+func (s *TripService) completeTrip(ctx context.Context, trip *domain.Trip, ride *domain.Ride, endTime time.Time, fare float64) (*EndTripResponse, error) {
 	// Use transaction to end trip, update ride status, and reset driver status.
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -200,8 +456,15 @@ func (s *TripService) EndTrip(ctx context.Context, req EndTripRequest) (*EndTrip
 
 	// Update trip.
 	trip.Status = domain.TripStatusEnded
-	trip.Fare = fare
 	trip.EndedAt = endTime
+	s.setFare(trip, fare)
+	if !trip.NeedsReview && s.awaitsConfirmation(ride) {
+		trip.Confirmation = domain.TripConfirmationPending
+	}
+
+	if err = trip.Validate(); err != nil {
+		return nil, err
+	}
 
 	if err = txTripRepo.Update(ctx, trip); err != nil {
 		return nil, err
@@ -209,6 +472,10 @@ func (s *TripService) EndTrip(ctx context.Context, req EndTripRequest) (*EndTrip
 
 	// Update ride status to COMPLETED.
 	ride.Status = domain.RideStatusCompleted
+	ride.CompletedAt = endTime
+	if err = ride.Validate(); err != nil {
+		return nil, err
+	}
 	if err = txRideRepo.Update(ctx, ride); err != nil {
 		return nil, err
 	}
@@ -221,32 +488,285 @@ func (s *TripService) EndTrip(ctx context.Context, req EndTripRequest) (*EndTrip
 	if err = tx.Commit(); err != nil {
 		return nil, err
 	}
+	publishEvent(ctx, s.publisher, domain.EventTripEnded, trip.ID, map[string]any{
+		"ride_id":      trip.RideID,
+		"driver_id":    trip.DriverID,
+		"rider_id":     ride.RiderID,
+		"fare":         trip.Fare,
+		"needs_review": trip.NeedsReview,
+		"auto_ended":   trip.AutoEnded,
+	})
 
-	// Trigger payment (after transaction commits).
+	// Trigger payment (after transaction commits), unless the fare is held.
 	var payment *domain.Payment
-	payment, err = s.paymentService.ProcessPayment(ctx, ProcessPaymentRequest{
+	var receipt *domain.Receipt
+	var split *domain.FareSplit
+	switch {
+	case trip.NeedsReview:
+		s.holdForReview(ctx, trip)
+	case trip.Confirmation == domain.TripConfirmationPending:
+		// The hold placed at the start stays on the card for the rider.
+		payment, _ = s.paymentService.FarePayment(ctx, trip.ID)
+	default:
+		payment, receipt, split = s.settleFare(ctx, trip, ride)
+	}
+
+	if s.notificationService != nil {
+		_ = s.notificationService.NotifyTripEnded(ctx, newTripSummary(trip, ride, payment, receipt))
+	}
+
+	// Count the trip towards driver incentive campaigns.
+	s.evaluateCampaigns(ctx, trip)
+
+	return &EndTripResponse{
+		Trip:    trip,
+		Payment: payment,
+		Receipt: receipt,
+		Split:   split,
+	}, nil
+}
+
+// autoEndBatchSize caps how many overdue trips one sweep ends.
+const autoEndBatchSize = 100
+
+// AutoEndOverdue ends trips still STARTED more than maxDuration before now,
+// so a forgotten trip stops running up its fare and frees its driver. The
+// fare is charged only up to maxDuration, the trip is marked AutoEnded, and
+// both parties are notified. A trip that fails to end is logged and picked
+// up again by the next sweep. It returns how many trips were ended.
+func (s *TripService) AutoEndOverdue(ctx context.Context, now time.Time, maxDuration time.Duration) (int, error) {
+	trips, err := s.tripRepo.GetStartedBefore(ctx, now.Add(-maxDuration), autoEndBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	ended := 0
+	for _, trip := range trips {
+		if err := s.autoEnd(ctx, trip, now, maxDuration); err != nil {
+			log.Printf("[TRIP] Failed to auto-end trip %s: %v", trip.ID, err)
+			continue
+		}
+		ended++
+	}
+	return ended, nil
+}
+
+// autoEnd ends one overdue trip at now, charging only up to maxDuration.
+// A trip ended or paused since it was listed fails the versioned update.
+func (s *TripService) autoEnd(ctx context.Context, trip *domain.Trip, now time.Time, maxDuration time.Duration) error {
+	ride, err := s.rideRepo.GetByID(ctx, trip.RideID)
+	if err != nil {
+		return err
+	}
+
+	trip.AutoEnded = true
+	fare := s.tripFare(trip, ride, trip.StartedAt.Add(maxDuration))
+	resp, err := s.completeTrip(ctx, trip, ride, now, fare)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[TRIP] Auto-ended trip %s after %s; fare %.2f", trip.ID, now.Sub(trip.StartedAt).Round(time.Second), resp.Trip.Fare)
+	if s.notificationService != nil {
+		_ = s.notificationService.NotifyTripAutoEnded(ctx, resp.Trip, ride.RiderID, maxDuration)
+	}
+	return nil
+}
+
+// AbortTripRequest contains the parameters for aborting a trip in progress.
+type AbortTripRequest struct {
+	TripID    string
+	AbortedBy domain.AbortParty
+	Reason    string // e.g. "vehicle breakdown"
+}
+
+// AbortTrip ends a trip before the destination, e.g. after a breakdown. The
+// ride is cancelled with the reason and the driver goes back ONLINE. A rider
+// abort is charged the elapsed-time fare; a driver abort is charged per the
+// configured policy. Zone surcharges are never charged, and when nothing is
+// owed no payment is made. The receipt records the abort either way.
+func (s *TripService) AbortTrip(ctx context.Context, req AbortTripRequest) (*EndTripResponse, error) {
+	if req.TripID == "" {
+		return nil, ErrInvalidTripID
+	}
+
+	if !req.AbortedBy.IsValid() {
+		return nil, ErrInvalidAbortParty
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, ErrAbortReasonRequired
+	}
+
+	trip, err := s.tripRepo.GetByID(ctx, req.TripID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !trip.CanTransitionTo(domain.TripStatusAborted) {
+		return nil, ErrTripAlreadyEnded
+	}
+
+	if trip.Status == domain.TripStatusPaused && !trip.PausedAt.IsZero() {
+		trip.TotalPaused += time.Since(trip.PausedAt)
+	}
+
+	ride, err := s.rideRepo.GetByID(ctx, trip.RideID)
+	if err != nil {
+		return nil, err
+	}
+
+	endTime := time.Now()
+	fare := 0.0
+	if req.AbortedBy == domain.AbortPartyRider || s.driverAbortFare == AbortFareElapsed {
+		surgeMultiplier := ride.SurgeMultiplier
+		if surgeMultiplier < 1.0 {
+			surgeMultiplier = 1.0
+		}
+		fare = s.fares.BaseFare(ride.Tier, endTime.Sub(trip.StartedAt)-trip.TotalPaused) * surgeMultiplier
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	txTripRepo := postgres.NewTripRepositoryWithTx(tx)
+	txDriverRepo := postgres.NewDriverRepositoryWithTx(tx)
+	txRideRepo := postgres.NewRideRepositoryWithTx(tx)
+
+	trip.Status = domain.TripStatusAborted
+	trip.EndedAt = endTime
+	trip.PausedAt = time.Time{}
+	trip.PauseReason = ""
+	trip.AbortedBy = req.AbortedBy
+	trip.AbortReason = reason
+	s.setFare(trip, fare)
+
+	if err = trip.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err = txTripRepo.Update(ctx, trip); err != nil {
+		return nil, err
+	}
+
+	ride.Status = domain.RideStatusCancelled
+	ride.CancelledAt = endTime
+	ride.CancelReason = reason
+	if err = ride.Validate(); err != nil {
+		return nil, err
+	}
+	if err = txRideRepo.Update(ctx, ride); err != nil {
+		return nil, err
+	}
+
+	if err = txDriverRepo.UpdateStatus(ctx, trip.DriverID, domain.DriverStatusOnline); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	var payment *domain.Payment
+	var receipt *domain.Receipt
+	var split *domain.FareSplit
+	switch {
+	case trip.NeedsReview:
+		s.holdForReview(ctx, trip)
+	case trip.Fare > 0:
+		payment, receipt, split = s.settleFare(ctx, trip, ride)
+	default:
+		// Nothing is owed: release any hold placed when the trip started.
+		if s.paymentService != nil {
+			payment, _ = s.paymentService.VoidAuthorization(ctx, trip.ID)
+		}
+		if s.receiptService != nil {
+			receipt, _ = s.receiptService.GenerateReceipt(ctx, GenerateReceiptRequest{Trip: trip, Ride: ride})
+		}
+	}
+
+	if s.notificationService != nil {
+		abortedBy := ride.RiderID
+		if req.AbortedBy == domain.AbortPartyDriver {
+			abortedBy = trip.DriverID
+		}
+		_ = s.notificationService.NotifyRideCancelled(ctx, ride, abortedBy, reason)
+	}
+
+	return &EndTripResponse{
+		Trip:    trip,
+		Payment: payment,
+		Receipt: receipt,
+		Split:   split,
+	}, nil
+}
+
+// newTripSummary assembles the rider's completion summary. payment and
+// receipt may be nil.
+func newTripSummary(trip *domain.Trip, ride *domain.Ride, payment *domain.Payment, receipt *domain.Receipt) TripSummary {
+	surgeMultiplier := ride.SurgeMultiplier
+	if surgeMultiplier < 1.0 {
+		surgeMultiplier = 1.0
+	}
+
+	summary := TripSummary{
+		TripID:          trip.ID,
+		RideID:          ride.ID,
+		RiderID:         ride.RiderID,
+		DriverID:        trip.DriverID,
+		Duration:        trip.EndedAt.Sub(trip.StartedAt) - trip.TotalPaused,
+		DistanceKm:      domain.HaversineKm(ride.PickupLat, ride.PickupLng, ride.DestinationLat, ride.DestinationLng),
+		Fare:            trip.Fare,
+		SurgeMultiplier: surgeMultiplier,
+		PaymentMethod:   ride.PaymentMethod,
+		EndedAt:         trip.EndedAt,
+	}
+	if payment != nil {
+		summary.PaymentStatus = payment.Status
+	}
+	if receipt != nil {
+		summary.ReceiptID = receipt.ID
+	}
+	return summary
+}
+
+// settleFare charges the trip's fare and generates its receipt, or settles
+// each rider's share of a split fare, which it returns. A payment failure is
+// not returned: the trip is ended and payment can be retried.
+func (s *TripService) settleFare(ctx context.Context, trip *domain.Trip, ride *domain.Ride) (*domain.Payment, *domain.Receipt, *domain.FareSplit) {
+	if split := s.fareSplit(ctx, trip); split != nil {
+		payment, receipt := s.settleSplitFare(ctx, trip, ride, split)
+		return payment, receipt, split
+	}
+
+	payment, err := s.paymentService.ProcessPayment(ctx, ProcessPaymentRequest{
 		TripID: trip.ID,
-		Amount: fare,
+		Amount: trip.Fare,
+		Method: ride.PaymentMethod,
+
+		InstrumentID: ride.InstrumentID,
 	})
 	if err != nil {
-		// Log error but don't fail - trip is ended.
-		// Payment can be retried later.
 		payment = nil
 	}
 
-	// Send notifications
-	if s.notificationService != nil {
-		_ = s.notificationService.NotifyTripEnded(ctx, trip, ride.RiderID, fare)
-		if payment != nil {
-			if payment.Status == domain.PaymentStatusSuccess {
-				_ = s.notificationService.NotifyPaymentSuccess(ctx, payment, ride.RiderID)
-			} else if payment.Status == domain.PaymentStatusFailed {
-				_ = s.notificationService.NotifyPaymentFailed(ctx, payment, ride.RiderID)
-			}
+	if s.notificationService != nil && payment != nil {
+		if payment.Status == domain.PaymentStatusSuccess {
+			_ = s.notificationService.NotifyPaymentSuccess(ctx, payment, ride.RiderID)
+		} else if payment.Status == domain.PaymentStatusFailed {
+			_ = s.notificationService.NotifyPaymentFailed(ctx, payment, ride.RiderID)
 		}
 	}
 
-	// Generate receipt
 	var receipt *domain.Receipt
 	if s.receiptService != nil {
 		receipt, _ = s.receiptService.GenerateReceipt(ctx, GenerateReceiptRequest{
@@ -256,13 +776,134 @@ func (s *TripService) EndTrip(ctx context.Context, req EndTripRequest) (*EndTrip
 		})
 	}
 
+	return payment, receipt, nil
+}
+
+// setFare records the trip's fare. A fare over the cap is usually a clock or
+// stuck-trip bug: it is capped and the payment held for admin review.
+func (s *TripService) setFare(trip *domain.Trip, fare float64) {
+	trip.Fare = fare
+	if fare > s.maxFare {
+		trip.Fare = s.maxFare
+		trip.NeedsReview = true
+		trip.UncappedFare = fare
+	}
+}
+
+// holdForReview reports a trip whose fare was capped to ops.
+func (s *TripService) holdForReview(ctx context.Context, trip *domain.Trip) {
+	log.Printf("[FARE] trip %s fare %.2f exceeds cap %.2f; held for review", trip.ID, trip.UncappedFare, s.maxFare)
+	recordMetric(ctx, metricTripsFlaggedForReview, 1)
+
+	if s.notificationService != nil {
+		_ = s.notificationService.NotifyFareReview(ctx, trip)
+	}
+}
+
+// ApproveFareRequest contains the parameters for approving a held fare.
+type ApproveFareRequest struct {
+	TripID string
+	Fare   float64 // The reviewed amount to charge
+}
+
+// ApproveFare releases a trip held for fare review, charging the reviewed fare.
+func (s *TripService) ApproveFare(ctx context.Context, req ApproveFareRequest) (*EndTripResponse, error) {
+	if req.TripID == "" {
+		return nil, ErrInvalidTripID
+	}
+
+	if req.Fare <= 0 {
+		return nil, ErrInvalidFare
+	}
+
+	trip, err := s.tripRepo.GetByID(ctx, req.TripID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !trip.NeedsReview {
+		return nil, ErrTripNotUnderReview
+	}
+
+	ride, err := s.rideRepo.GetByID(ctx, trip.RideID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The versioned update lets only one concurrent approval through.
+	trip.Fare = req.Fare
+	trip.NeedsReview = false
+
+	if err := trip.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.tripRepo.Update(ctx, trip); err != nil {
+		return nil, err
+	}
+
+	payment, receipt, split := s.settleFare(ctx, trip, ride)
+
 	return &EndTripResponse{
 		Trip:    trip,
 		Payment: payment,
 		Receipt: receipt,
+		Split:   split,
 	}, nil
 }
 
+// ConfirmCashRequest contains the parameters for confirming cash collection.
+type ConfirmCashRequest struct {
+	TripID   string
+	DriverID string // Caller, who must be the trip's driver
+}
+
+// ConfirmCash records that the trip's driver collected the cash fare.
+func (s *TripService) ConfirmCash(ctx context.Context, req ConfirmCashRequest) (*domain.Payment, error) {
+	if req.TripID == "" {
+		return nil, ErrInvalidTripID
+	}
+
+	if req.DriverID == "" {
+		return nil, ErrInvalidDriverID
+	}
+
+	trip, err := s.tripRepo.GetByID(ctx, req.TripID)
+	if err != nil {
+		return nil, err
+	}
+
+	if trip.DriverID != req.DriverID {
+		return nil, ErrNotTheDriver
+	}
+
+	payment, collected, err := s.paymentService.ConfirmCash(ctx, trip.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if collected && s.notificationService != nil {
+		ride, _ := s.rideRepo.GetByID(ctx, trip.RideID)
+		if ride != nil {
+			_ = s.notificationService.NotifyPaymentSuccess(ctx, payment, ride.RiderID)
+		}
+	}
+
+	return payment, nil
+}
+
+// evaluateCampaigns counts an ended trip towards the driver's campaigns.
+// Failures are logged rather than returned: the trip has already ended, and
+// retrying EndTrip re-drives the evaluation.
+func (s *TripService) evaluateCampaigns(ctx context.Context, trip *domain.Trip) {
+	if s.campaignService == nil {
+		return
+	}
+	if err := s.campaignService.OnTripEnded(ctx, trip); err != nil {
+		log.Printf("[CAMPAIGN] failed to evaluate campaigns for trip %s: %v", trip.ID, err)
+	}
+}
+
 // GetTrip retrieves a trip by ID.
 func (s *TripService) GetTrip(ctx context.Context, tripID string) (*domain.Trip, error) {
 	if tripID == "" {
@@ -272,6 +913,41 @@ func (s *TripService) GetTrip(ctx context.Context, tripID string) (*domain.Trip,
 	return s.tripRepo.GetByID(ctx, tripID)
 }
 
+// GetTripRiderID returns the ID of the rider on a trip.
+func (s *TripService) GetTripRiderID(ctx context.Context, trip *domain.Trip) (string, error) {
+	ride, err := s.rideRepo.GetByID(ctx, trip.RideID)
+	if err != nil {
+		return "", err
+	}
+	return ride.RiderID, nil
+}
+
+// DriverCurrent is what a driver is working on: a trip in progress, or a
+// ride assigned to them that they have not accepted yet. Both are nil for
+// an idle driver.
+type DriverCurrent struct {
+	Trip        *domain.Trip
+	PendingRide *domain.Ride // ASSIGNED to the driver, with no trip started
+}
+
+// GetDriverCurrent returns the driver's active trip and pending ride, so a
+// driver app that restarted mid-offer or mid-trip can recover its state.
+func (s *TripService) GetDriverCurrent(ctx context.Context, driverID string) (*DriverCurrent, error) {
+	if driverID == "" {
+		return nil, ErrInvalidDriverID
+	}
+
+	trip, err := s.tripRepo.GetActiveByDriverID(ctx, driverID)
+	if err != nil {
+		return nil, err
+	}
+	ride, err := s.rideRepo.GetAssignedByDriverID(ctx, driverID)
+	if err != nil {
+		return nil, err
+	}
+	return &DriverCurrent{Trip: trip, PendingRide: ride}, nil
+}
+
 // GetAllTrips retrieves all trips.
 func (s *TripService) GetAllTrips(ctx context.Context) ([]*domain.Trip, error) {
 	return s.tripRepo.GetAll(ctx)
@@ -280,6 +956,7 @@ func (s *TripService) GetAllTrips(ctx context.Context) ([]*domain.Trip, error) {
 // PauseTripRequest contains the parameters for pausing a trip.
 type PauseTripRequest struct {
 	TripID string
+	Reason domain.PauseReason // Optional
 }
 
 // PauseTrip pauses an active trip.
@@ -288,18 +965,27 @@ func (s *TripService) PauseTrip(ctx context.Context, req PauseTripRequest) (*dom
 		return nil, ErrInvalidTripID
 	}
 
+	if req.Reason != "" && !req.Reason.IsValid() {
+		return nil, ErrInvalidPauseReason
+	}
+
 	trip, err := s.tripRepo.GetByID(ctx, req.TripID)
 	if err != nil {
 		return nil, err
 	}
 
-	if trip.Status != domain.TripStatusStarted {
+	if !trip.CanTransitionTo(domain.TripStatusPaused) {
 		return nil, ErrTripNotStarted
 	}
 
 	// Update trip status to paused
 	trip.Status = domain.TripStatusPaused
 	trip.PausedAt = time.Now()
+	trip.PauseReason = req.Reason
+
+	if err := trip.Validate(); err != nil {
+		return nil, err
+	}
 
 	if err := s.tripRepo.Update(ctx, trip); err != nil {
 		return nil, err
@@ -332,7 +1018,7 @@ func (s *TripService) ResumeTrip(ctx context.Context, req ResumeTripRequest) (*d
 		return nil, err
 	}
 
-	if trip.Status != domain.TripStatusPaused {
+	if !trip.CanTransitionTo(domain.TripStatusStarted) {
 		return nil, ErrTripNotPaused
 	}
 
@@ -343,6 +1029,11 @@ func (s *TripService) ResumeTrip(ctx context.Context, req ResumeTripRequest) (*d
 	// Update trip status to started
 	trip.Status = domain.TripStatusStarted
 	trip.PausedAt = time.Time{} // Reset paused time
+	trip.PauseReason = ""
+
+	if err := trip.Validate(); err != nil {
+		return nil, err
+	}
 
 	if err := s.tripRepo.Update(ctx, trip); err != nil {
 		return nil, err
@@ -358,25 +1049,3 @@ func (s *TripService) ResumeTrip(ctx context.Context, req ResumeTripRequest) (*d
 
 	return trip, nil
 }
-
-// calculateFare calculates the fare based on trip duration.
-// Simple implementation: $2 base + $0.50 per minute.
-func (s *TripService) calculateFare(startTime, endTime time.Time, totalPaused time.Duration) float64 {
-	const (
-		baseFare      = 2.0
-		perMinuteRate = 0.5
-		minimumFare   = 5.0
-	)
-
-	// Subtract paused time from total duration
-	duration := endTime.Sub(startTime) - totalPaused
-	minutes := duration.Minutes()
-
-	fare := baseFare + (minutes * perMinuteRate)
-
-	if fare < minimumFare {
-		return minimumFare
-	}
-
-	return fare
-}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/repository"
+)
+
+// confirmBatchSize caps how many lapsed trips one sweep confirms.
+const confirmBatchSize = 100
+
+// awaitsConfirmation reports whether a ride's trip waits for the rider to
+// confirm it before its fare is captured. Only CARD rides hold their fare,
+// so only they can wait.
+func (s *TripService) awaitsConfirmation(ride *domain.Ride) bool {
+	return s.confirmationWindow > 0 && s.paymentService != nil && ride.PaymentMethod == domain.PaymentMethodCard
+}
+
+// TripConfirmationRequest contains the parameters for the rider confirming
+// or disputing a trip.
+type TripConfirmationRequest struct {
+	TripID  string
+	RiderID string // Caller, who must be the ride's rider
+	Reason  string // Why the trip is disputed; required to dispute
+}
+
+// ConfirmTrip records the rider's confirmation that a trip waiting for them
+// happened, and captures its fare.
+func (s *TripService) ConfirmTrip(ctx context.Context, req TripConfirmationRequest) (*EndTripResponse, error) {
+	trip, ride, err := s.awaitingRider(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return s.settleConfirmation(ctx, trip, ride, domain.TripConfirmationConfirmed)
+}
+
+// DisputeTrip records the rider's dispute of a trip waiting for them. The
+// fare stays authorized, not captured, until an admin resolves the dispute.
+func (s *TripService) DisputeTrip(ctx context.Context, req TripConfirmationRequest) (*domain.Trip, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, ErrDisputeReasonRequired
+	}
+
+	trip, _, err := s.awaitingRider(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// The versioned update lets only one of a confirmation and a dispute through.
+	trip.Confirmation = domain.TripConfirmationDisputed
+	trip.DisputeReason = reason
+	if err := s.tripRepo.Update(ctx, trip); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[TRIP] trip %s disputed by rider %s: %s", trip.ID, req.RiderID, reason)
+	if s.notificationService != nil {
+		_ = s.notificationService.NotifyTripDisputed(ctx, trip)
+	}
+	return trip, nil
+}
+
+// awaitingRider returns a trip waiting for the rider's confirmation with its
+// ride. A trip that is not the rider's is reported as not found.
+func (s *TripService) awaitingRider(ctx context.Context, req TripConfirmationRequest) (*domain.Trip, *domain.Ride, error) {
+	if req.TripID == "" {
+		return nil, nil, ErrInvalidTripID
+	}
+
+	trip, err := s.tripRepo.GetByID(ctx, req.TripID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ride, err := s.rideRepo.GetByID(ctx, trip.RideID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if req.RiderID == "" || ride.RiderID != req.RiderID {
+		return nil, nil, repository.ErrNotFound
+	}
+
+	if trip.Confirmation != domain.TripConfirmationPending {
+		return nil, nil, ErrTripNotAwaitingConfirmation
+	}
+	return trip, ride, nil
+}
+
+// DisputeOutcome is how an admin resolves a disputed trip.
+type DisputeOutcome string
+
+const (
+	DisputeOutcomeCharge DisputeOutcome = "CHARGE" // The trip happened: capture the fare
+	DisputeOutcomeVoid   DisputeOutcome = "VOID"   // It did not: release the hold and charge nothing
+)
+
+// ResolveDisputeRequest contains the parameters for resolving a dispute.
+type ResolveDisputeRequest struct {
+	TripID  string
+	Outcome DisputeOutcome
+}
+
+// ResolveDispute releases a disputed trip's held payment: CHARGE captures
+// the fare, VOID releases the hold and zeroes the fare.
+func (s *TripService) ResolveDispute(ctx context.Context, req ResolveDisputeRequest) (*EndTripResponse, error) {
+	if req.TripID == "" {
+		return nil, ErrInvalidTripID
+	}
+
+	if req.Outcome != DisputeOutcomeCharge && req.Outcome != DisputeOutcomeVoid {
+		return nil, ErrInvalidDisputeOutcome
+	}
+
+	trip, err := s.tripRepo.GetByID(ctx, req.TripID)
+	if err != nil {
+		return nil, err
+	}
+
+	if trip.Confirmation != domain.TripConfirmationDisputed {
+		return nil, ErrTripNotDisputed
+	}
+
+	ride, err := s.rideRepo.GetByID(ctx, trip.RideID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Outcome == DisputeOutcomeCharge {
+		return s.settleConfirmation(ctx, trip, ride, domain.TripConfirmationCharged)
+	}
+
+	trip.Confirmation = domain.TripConfirmationVoided
+	trip.Fare = 0
+	if err := s.tripRepo.Update(ctx, trip); err != nil {
+		return nil, err
+	}
+
+	var payment *domain.Payment
+	var receipt *domain.Receipt
+	if s.paymentService != nil {
+		payment, _ = s.paymentService.VoidAuthorization(ctx, trip.ID)
+	}
+	if s.receiptService != nil {
+		receipt, _ = s.receiptService.GenerateReceipt(ctx, GenerateReceiptRequest{Trip: trip, Ride: ride})
+	}
+
+	return &EndTripResponse{
+		Trip:    trip,
+		Payment: payment,
+		Receipt: receipt,
+	}, nil
+}
+
+// ConfirmLapsed confirms trips the rider left unanswered for longer than the
+// confirmation window before now, capturing their fares. A trip that fails
+// to confirm, e.g. because the rider answered meanwhile, is logged and, if
+// still waiting, picked up again by the next sweep. It returns how many
+// trips were confirmed.
+func (s *TripService) ConfirmLapsed(ctx context.Context, now time.Time) (int, error) {
+	if s.confirmationWindow <= 0 {
+		return 0, nil
+	}
+
+	trips, err := s.tripRepo.GetAwaitingConfirmationBefore(ctx, now.Add(-s.confirmationWindow), confirmBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	confirmed := 0
+	for _, trip := range trips {
+		ride, err := s.rideRepo.GetByID(ctx, trip.RideID)
+		if err == nil {
+			_, err = s.settleConfirmation(ctx, trip, ride, domain.TripConfirmationConfirmed)
+		}
+		if err != nil {
+			log.Printf("[TRIP] Failed to confirm lapsed trip %s: %v", trip.ID, err)
+			continue
+		}
+		confirmed++
+	}
+	return confirmed, nil
+}
+
+// settleConfirmation records how a held trip was confirmed and captures its
+// fare. The versioned update lets only one concurrent settlement through.
+func (s *TripService) settleConfirmation(ctx context.Context, trip *domain.Trip, ride *domain.Ride, confirmation domain.TripConfirmation) (*EndTripResponse, error) {
+	trip.Confirmation = confirmation
+	if err := s.tripRepo.Update(ctx, trip); err != nil {
+		return nil, err
+	}
+
+	payment, receipt, split := s.settleFare(ctx, trip, ride)
+
+	return &EndTripResponse{
+		Trip:    trip,
+		Payment: payment,
+		Receipt: receipt,
+		Split:   split,
+	}, nil
+}
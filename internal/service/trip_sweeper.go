@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMaxTripDuration   = 6 * time.Hour   // Used when the configured maximum is not positive
+	defaultTripSweepInterval = 5 * time.Minute // Used when the configured interval is not positive
+)
+
+// TripSweeper periodically auto-ends trips that have run past the maximum
+// trip duration, a safeguard against drivers forgetting to end a trip, and
+// confirms trips their riders left unanswered past the confirmation window.
+type TripSweeper struct {
+	tripService *TripService
+	maxDuration time.Duration
+	interval    time.Duration
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewTripSweeper creates a TripSweeper and starts its background loop. Call
+// Close on shutdown to stop it.
+func NewTripSweeper(tripService *TripService, maxDuration time.Duration, interval time.Duration) *TripSweeper {
+	if maxDuration <= 0 {
+		maxDuration = defaultMaxTripDuration
+	}
+	if interval <= 0 {
+		interval = defaultTripSweepInterval
+	}
+
+	s := &TripSweeper{
+		tripService: tripService,
+		maxDuration: maxDuration,
+		interval:    interval,
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// Close stops the background loop, waiting for a sweep in progress.
+func (s *TripSweeper) Close() {
+	s.closeOnce.Do(func() { close(s.stop) })
+	<-s.done
+}
+
+// run sweeps for overdue and unconfirmed trips on every interval.
+func (s *TripSweeper) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if ended, err := s.tripService.AutoEndOverdue(context.Background(), time.Now(), s.maxDuration); err != nil {
+				log.Printf("[TRIP] Failed to sweep overdue trips: %v", err)
+			} else if ended > 0 {
+				log.Printf("[TRIP] Auto-ended %d overdue trips", ended)
+			}
+			if confirmed, err := s.tripService.ConfirmLapsed(context.Background(), time.Now()); err != nil {
+				log.Printf("[TRIP] Failed to sweep unconfirmed trips: %v", err)
+			} else if confirmed > 0 {
+				log.Printf("[TRIP] Confirmed %d trips past the confirmation window", confirmed)
+			}
+		case <-s.stop:
+			return
+		}
+	}
+}
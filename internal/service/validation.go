@@ -0,0 +1,49 @@
+package service
+
+import "strings"
+
+// FieldError is a problem with one field of a request.
+type FieldError struct {
+	Field string // The field's JSON name, e.g. pickup_lat
+	Err   error
+}
+
+// ValidationError lists every problem found in a request, so that a client
+// can fix them all at once rather than one per attempt. errors.Is matches
+// any of the field errors.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+// Add records err against field. A nil err is ignored, so validators'
+// results can be added unchecked.
+func (e *ValidationError) Add(field string, err error) {
+	if err != nil {
+		e.Fields = append(e.Fields, FieldError{Field: field, Err: err})
+	}
+}
+
+// Err returns e if any problem was recorded, otherwise nil.
+func (e *ValidationError) Err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e *ValidationError) Error() string {
+	problems := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		problems[i] = f.Field + ": " + f.Err.Error()
+	}
+	return "invalid request: " + strings.Join(problems, "; ")
+}
+
+// Unwrap returns the field errors.
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Fields))
+	for i, f := range e.Fields {
+		errs[i] = f.Err
+	}
+	return errs
+}
@@ -0,0 +1,173 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/redis"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// ACCESSIBILITY MATCHING
+// ──────────────────────────────────────────────
+
+// seedAccessibilityDrivers places ONLINE drivers "near", without a
+// wheelchair-accessible vehicle, and "far", with one, close to every ride's
+// pickup.
+func seedAccessibilityDrivers(env *testEnv) {
+	env.drivers.AddDriver(&domain.Driver{ID: "near", Status: domain.DriverStatusOnline, Tier: domain.DriverTierBasic})
+	env.drivers.AddDriver(&domain.Driver{
+		ID: "far", Status: domain.DriverStatusOnline, Tier: domain.DriverTierBasic,
+		Capabilities: []string{domain.CapabilityWAV},
+	})
+	env.locations.SetLocations([]redis.DriverLocation{
+		{DriverID: "near", Lat: 12.971, Lng: 77.59},
+		{DriverID: "far", Lat: 12.975, Lng: 77.59},
+	})
+}
+
+func addAccessibilityRide(env *testEnv, id string, capabilities []string, createdAt time.Time) {
+	env.rides.AddRide(&domain.Ride{
+		ID: id, RiderID: "rider-" + id, PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 13.0, DestinationLng: 77.6,
+		Status: domain.RideStatusRequested, Version: 1, CreatedAt: createdAt,
+		Capabilities: capabilities, Priority: domain.NeedsAccessibility(capabilities),
+	})
+}
+
+func TestAccessibility_WAVRideSkipsNearerDriverWithoutWAV(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	seedAccessibilityDrivers(env)
+	addAccessibilityRide(env, "ride-1", []string{domain.CapabilityWAV}, time.Now())
+
+	matcher := service.NewMatchingService(env.matchingDeps())
+	result, err := matcher.Match(context.Background(), service.MatchRequest{RideID: "ride-1", Lat: 12.97, Lng: 77.59, RadiusKm: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.DriverID != "far" {
+		t.Fatalf("expected the wheelchair-accessible driver matched, got %s", result.DriverID)
+	}
+
+	attempts, _ := env.attempts.ListByRide(context.Background(), "ride-1")
+	if len(attempts) != 1 || attempts[0].SkippedCapability != 1 {
+		t.Fatalf("expected one attempt skipping one driver for capability, got %+v", attempts)
+	}
+}
+
+func TestAccessibility_RideWithoutRequirementsMatchesNearest(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	seedAccessibilityDrivers(env)
+	addAccessibilityRide(env, "ride-1", nil, time.Now())
+
+	matcher := service.NewMatchingService(env.matchingDeps())
+	result, err := matcher.Match(context.Background(), service.MatchRequest{RideID: "ride-1", Lat: 12.97, Lng: 77.59, RadiusKm: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.DriverID != "near" {
+		t.Fatalf("expected the nearest driver matched, got %s", result.DriverID)
+	}
+}
+
+func TestAccessibility_PriorityRidesRetriedFirst(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	seedAccessibilityDrivers(env)
+	now := time.Now()
+	addAccessibilityRide(env, "standard", nil, now.Add(-10*time.Minute))
+	addAccessibilityRide(env, "wav", []string{domain.CapabilityWAV}, now.Add(-time.Minute))
+
+	queue, _ := env.rides.ListRequested(context.Background(), 10)
+	if len(queue) != 2 || queue[0].ID != "wav" {
+		t.Fatalf("expected the priority ride queued first, got %v", rideIDs(queue))
+	}
+
+	matcher := &recordingMatcher{MatchingServiceInterface: service.NewMatchingService(env.matchingDeps())}
+	rideService := service.NewRideService(env.rideDeps(matcher))
+
+	matched, err := rideService.RetryUnmatched(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(matcher.rideIDs) != 2 || matcher.rideIDs[0] != "wav" || matcher.rideIDs[1] != "standard" {
+		t.Fatalf("expected the priority ride retried first, got %v", matcher.rideIDs)
+	}
+	if matched != 2 {
+		t.Fatalf("expected both rides matched, got %d", matched)
+	}
+	if got := matcher.driverIDs["wav"]; got != "far" {
+		t.Errorf("expected the priority ride to get the wheelchair-accessible driver, got %q", got)
+	}
+	if got := matcher.driverIDs["standard"]; got != "near" {
+		t.Errorf("expected the standard ride to get the nearest driver, got %q", got)
+	}
+}
+
+func TestAccessibility_CreateRideMarksWAVRidesPriority(t *testing.T) {
+	t.Parallel()
+
+	rides := NewMockRideRepository()
+	rideService := service.NewRideService(service.RideServiceDeps{RideRepo: rides, MatchingService: NewMockMatchingServiceForTest()})
+
+	capabilities, err := service.ValidateCapabilities([]string{" wav", "WAV"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result, err := rideService.CreateRide(context.Background(), service.CreateRideRequest{
+		RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 13.0, DestinationLng: 77.6,
+		Capabilities: capabilities,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stored := rides.GetRide(result.Ride.ID)
+	if !stored.Priority || len(stored.Capabilities) != 1 || stored.Capabilities[0] != domain.CapabilityWAV {
+		t.Fatalf("expected a priority ride requiring WAV once, got priority=%v capabilities=%v", stored.Priority, stored.Capabilities)
+	}
+}
+
+func TestAccessibility_UnknownCapabilityRejected(t *testing.T) {
+	t.Parallel()
+
+	if _, err := service.ValidateCapabilities([]string{"hovercraft"}); !errors.Is(err, service.ErrInvalidCapability) {
+		t.Fatalf("expected ErrInvalidCapability, got %v", err)
+	}
+}
+
+// recordingMatcher records the rides it is asked to match, in order, and
+// the driver each was matched to.
+type recordingMatcher struct {
+	service.MatchingServiceInterface
+	rideIDs   []string
+	driverIDs map[string]string
+}
+
+func (m *recordingMatcher) Match(ctx context.Context, req service.MatchRequest) (*service.MatchResult, error) {
+	m.rideIDs = append(m.rideIDs, req.RideID)
+	result, err := m.MatchingServiceInterface.Match(ctx, req)
+	if err == nil {
+		if m.driverIDs == nil {
+			m.driverIDs = make(map[string]string)
+		}
+		m.driverIDs[req.RideID] = result.DriverID
+	}
+	return result, err
+}
+
+func rideIDs(rides []*domain.Ride) []string {
+	ids := make([]string, 0, len(rides))
+	for _, r := range rides {
+		ids = append(ids, r.ID)
+	}
+	return ids
+}
@@ -0,0 +1,177 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/middleware"
+	"ride/internal/redis"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// ASSIGNED DRIVER DETAILS
+// ──────────────────────────────────────────────
+
+// seedAssignedDriver seeds ride-assigned (driver-1, about 1.1 km from the
+// pickup) and ride-open, which is still waiting for a driver, and returns a
+// matcher that knows driver-1.
+func seedAssignedDriver(env *testEnv) *MockMatchingServiceForTest {
+	env.rides.AddRide(&domain.Ride{
+		ID: "ride-assigned", RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59,
+		Status: domain.RideStatusAssigned, AssignedDriverID: "driver-1", SurgeMultiplier: 1,
+	})
+	env.rides.AddRide(&domain.Ride{
+		ID: "ride-open", RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59,
+		Status: domain.RideStatusRequested, SurgeMultiplier: 1,
+	})
+	env.locations.AddDriverLocation(redis.DriverLocation{DriverID: "driver-1", Lat: 12.98, Lng: 77.59})
+
+	matching := NewMockMatchingServiceForTest()
+	matching.AddDriver(&domain.Driver{
+		ID: "driver-1", Name: "Ravi", Phone: "+919999999999", Status: domain.DriverStatusOnTrip,
+		Tier: domain.DriverTierPremium, VehiclePlate: "KA01AB1234",
+	})
+	return matching
+}
+
+func assignedDriverRouter(env *testEnv, matching service.MatchingServiceInterface) *gin.Engine {
+	rideService := service.NewRideService(env.rideDeps(matching))
+	etaService := service.NewETAService(env.rides, env.locations, nil, nil, 0)
+	rideHandler := handler.NewRideHandler(rideService, etaService, env.rides, nil)
+
+	router := newTestRouter()
+	router.Use(middleware.IdentityMiddleware(testAdminToken))
+	router.POST("/v1/rides", rideHandler.CreateRide)
+	router.GET("/v1/rides/:id", rideHandler.GetRide)
+	return router
+}
+
+func getAssignedRide(t *testing.T, router *gin.Engine, id string) handler.GetRideResponse {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/rides/"+id, nil)
+	req.Header.Set("X-User-ID", "rider-1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp handler.GetRideResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	return resp
+}
+
+func createAssignedRide(t *testing.T, router *gin.Engine) handler.CreateRideResponse {
+	t.Helper()
+
+	body, _ := json.Marshal(handler.CreateRideRequest{RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 12.3, DestinationLng: 76.64})
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/rides", bytes.NewReader(body)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp handler.CreateRideResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	return resp
+}
+
+func TestAssignedDriver_GetRide(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	matching := seedAssignedDriver(env)
+	router := assignedDriverRouter(env, matching)
+
+	driver := getAssignedRide(t, router, "ride-assigned").Driver
+	if driver == nil {
+		t.Fatal("expected driver details for an assigned ride")
+	}
+	if driver.ID != "driver-1" || driver.Name != "Ravi" || driver.Tier != "PREMIUM" || driver.VehiclePlate != "KA01AB1234" {
+		t.Errorf("unexpected driver details: %+v", driver)
+	}
+	if driver.ETASeconds <= 0 || driver.ETAMinutes <= 0 {
+		t.Errorf("expected an ETA from the driver's location, got %+v", driver)
+	}
+
+	if driver := getAssignedRide(t, router, "ride-open").Driver; driver != nil {
+		t.Errorf("expected no driver for a requested ride, got %+v", driver)
+	}
+}
+
+func TestAssignedDriver_CreateRide(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	matching := seedAssignedDriver(env)
+	router := assignedDriverRouter(env, matching)
+
+	if resp := createAssignedRide(t, router); resp.DriverAssigned || resp.Driver != nil {
+		t.Errorf("expected no driver when none was matched, got %+v", resp)
+	}
+
+	matching.SetResult(&service.MatchResult{DriverID: "driver-1", Ride: env.rides.GetRide("ride-assigned")}, nil)
+	resp := createAssignedRide(t, router)
+	if resp.Driver == nil || resp.Driver.Name != "Ravi" || resp.Driver.VehiclePlate != "KA01AB1234" {
+		t.Errorf("expected the matched driver's details, got %+v", resp.Driver)
+	}
+}
+
+func TestAssignedDriver_LookupFailureOmitsDriver(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	matching := seedAssignedDriver(env)
+	router := assignedDriverRouter(env, matching)
+	env.rides.AddRide(&domain.Ride{
+		ID: "ride-unknown-driver", RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59,
+		Status: domain.RideStatusAssigned, AssignedDriverID: "driver-missing", SurgeMultiplier: 1,
+	})
+
+	resp := getAssignedRide(t, router, "ride-unknown-driver")
+	if resp.Driver != nil {
+		t.Errorf("expected no driver details when the driver cannot be loaded, got %+v", resp.Driver)
+	}
+	if resp.AssignedDriverID != "driver-missing" {
+		t.Errorf("expected the assigned driver ID still returned, got %q", resp.AssignedDriverID)
+	}
+}
+
+func TestAssignedDriver_GetRideDriverDistance(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	matching := seedAssignedDriver(env)
+	router := assignedDriverRouter(env, matching)
+	env.rides.AddRide(&domain.Ride{
+		ID: "ride-in-trip", RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 13.0, DestinationLng: 77.59,
+		Status: domain.RideStatusInTrip, AssignedDriverID: "driver-1", SurgeMultiplier: 1,
+	})
+	env.rides.AddRide(&domain.Ride{
+		ID: "ride-no-location", RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59,
+		Status: domain.RideStatusAssigned, AssignedDriverID: "driver-2", SurgeMultiplier: 1,
+	})
+
+	// The driver at (12.98, 77.59) is about 1.11 km from the pickup and
+	// 2.22 km from the in-trip ride's destination.
+	for id, want := range map[string]float64{"ride-assigned": 1.11, "ride-in-trip": 2.22} {
+		got := getAssignedRide(t, router, id).DriverDistanceKm
+		if got == nil || math.Abs(*got-want) > 0.01 {
+			t.Errorf("%s: expected driver distance %.2f km, got %v", id, want, got)
+		}
+	}
+
+	for _, id := range []string{"ride-open", "ride-no-location"} {
+		if got := getAssignedRide(t, router, id).DriverDistanceKm; got != nil {
+			t.Errorf("%s: expected no driver distance, got %.2f", id, *got)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package tests
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/handler"
+	"ride/internal/middleware"
+)
+
+// ──────────────────────────────────────────────
+// REQUEST BODY SIZE LIMITS
+// ──────────────────────────────────────────────
+
+func newBodyLimitRouter(maxBytes int64) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.BodyLimitMiddleware(maxBytes))
+	router.POST("/v1/rides", func(c *gin.Context) {
+		var req handler.CreateRideRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, handler.ErrorResponse{Error: "invalid request body"})
+			return
+		}
+		c.JSON(http.StatusCreated, req)
+	})
+	return router
+}
+
+func TestBodyLimit_OversizedBody_Returns413(t *testing.T) {
+	t.Parallel()
+
+	router := newBodyLimitRouter(64)
+
+	body := `{"rider_id":"` + strings.Repeat("x", 256) + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/v1/rides", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %d", w.Code)
+	}
+}
+
+func TestBodyLimit_UnknownLengthOversizedBody_Returns413(t *testing.T) {
+	t.Parallel()
+
+	router := newBodyLimitRouter(64)
+
+	// Chunked body: ContentLength is unknown, so the limit is enforced while reading.
+	body := `{"rider_id":"` + strings.Repeat("x", 256) + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/v1/rides", bytes.NewBufferString(body))
+	req.ContentLength = -1
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %d", w.Code)
+	}
+}
+
+func TestBodyLimit_BodyWithinLimit_ReachesHandler(t *testing.T) {
+	t.Parallel()
+
+	router := newBodyLimitRouter(1024)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/rides", strings.NewReader(`{"rider_id":"rider-1"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), "rider-1") {
+		t.Errorf("expected handler to see the body, got %s", w.Body.String())
+	}
+}
@@ -0,0 +1,108 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"ride/internal/repository/postgres"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// CROSS-INSTANCE CACHE INVALIDATION
+// ──────────────────────────────────────────────
+
+func TestParseCacheInvalidation(t *testing.T) {
+	t.Parallel()
+
+	if got, ok := postgres.ParseCacheInvalidation("drivers:driver-1"); !ok || got.Table != "drivers" || got.ID != "driver-1" {
+		t.Errorf("expected drivers/driver-1, got %+v, %v", got, ok)
+	}
+	for _, payload := range []string{"", "drivers", "drivers:", ":driver-1"} {
+		if _, ok := postgres.ParseCacheInvalidation(payload); ok {
+			t.Errorf("expected %q rejected", payload)
+		}
+	}
+}
+
+func TestCacheInvalidation_AppliesDriverAndRideWrites(t *testing.T) {
+	t.Parallel()
+
+	invalidations := make(chan postgres.CacheInvalidation, 3)
+	cache := NewMockCacheInvalidator()
+	s := service.NewCacheInvalidationService(invalidations, cache)
+
+	invalidations <- postgres.CacheInvalidation{Table: "drivers", ID: "driver-1"}
+	invalidations <- postgres.CacheInvalidation{Table: "trips", ID: "trip-1"}
+	invalidations <- postgres.CacheInvalidation{Table: "rides", ID: "ride-1"}
+	close(invalidations)
+	s.Wait()
+
+	if got := cache.Invalidated(); !slices.Equal(got, []string{"drivers:driver-1", "rides:ride-1"}) {
+		t.Errorf("expected the driver and ride invalidated and the trip ignored, got %v", got)
+	}
+}
+
+// waitForInvalidation polls cache until it has invalidated want.
+func waitForInvalidation(t *testing.T, cache *MockCacheInvalidator, want string) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if slices.Contains(cache.Invalidated(), want) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("expected %s invalidated, got %v", want, cache.Invalidated())
+}
+
+// TestCacheInvalidation_LivePostgres runs two instances' listeners against
+// the Postgres at TEST_DATABASE_URL, with scripts/schema.sql applied, and
+// checks a driver written through one connection is dropped from both
+// instances' caches.
+func TestCacheInvalidation_LivePostgres(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+
+	caches := []*MockCacheInvalidator{NewMockCacheInvalidator(), NewMockCacheInvalidator()}
+	for _, cache := range caches {
+		listener, err := postgres.NewCacheInvalidationListener(dsn, 10*time.Millisecond, time.Second)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		s := service.NewCacheInvalidationService(listener.Invalidations(), cache)
+		defer func() {
+			_ = listener.Close()
+			s.Wait()
+		}()
+	}
+
+	driverID := uuid.New().String()
+	if _, err := db.ExecContext(ctx, `INSERT INTO drivers (id, name, phone) VALUES ($1, 'Cache Test', $2)`, driverID, driverID[:20]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer func() { _, _ = db.ExecContext(ctx, `DELETE FROM drivers WHERE id = $1`, driverID) }()
+
+	if _, err := db.ExecContext(ctx, `UPDATE drivers SET status = 'ONLINE' WHERE id = $1`, driverID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, cache := range caches {
+		waitForInvalidation(t, cache, "drivers:"+driverID)
+	}
+}
@@ -0,0 +1,376 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// DRIVER INCENTIVE CAMPAIGNS
+// ──────────────────────────────────────────────
+
+// newCampaignService seeds a BASIC driver in Bengaluru and a PREMIUM driver
+// in Mumbai and returns a CampaignService crediting bonuses to earnings.
+func newCampaignService(env *testEnv, earnings *MockEarningsRepository) *service.CampaignService {
+	return newCampaignServiceWithHistory(env, earnings, NewMockLocationHistoryRepository())
+}
+
+// newCampaignServiceWithHistory is newCampaignService measuring online hours
+// from history.
+func newCampaignServiceWithHistory(env *testEnv, earnings *MockEarningsRepository, history *MockLocationHistoryRepository) *service.CampaignService {
+	env.drivers.AddDriver(&domain.Driver{ID: "driver-basic", Status: domain.DriverStatusOnline, Tier: domain.DriverTierBasic, City: "bengaluru"})
+	env.drivers.AddDriver(&domain.Driver{ID: "driver-premium", Status: domain.DriverStatusOnline, Tier: domain.DriverTierPremium, City: "mumbai"})
+	return service.NewCampaignService(NewMockCampaignRepository(), env.drivers, earnings, history, nil, nil)
+}
+
+// addCampaign creates a campaign whose window is the current day.
+func addCampaign(t *testing.T, campaigns *service.CampaignService, name string, criteria domain.CampaignCriteria, target float64, tier domain.DriverTier) *domain.Campaign {
+	t.Helper()
+
+	now := time.Now()
+	campaign, err := campaigns.CreateCampaign(context.Background(), service.CampaignRequest{
+		Name:         name,
+		Criteria:     criteria,
+		Target:       target,
+		RewardAmount: 20,
+		Tier:         tier,
+		StartsAt:     now.Add(-12 * time.Hour),
+		EndsAt:       now.Add(12 * time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("failed to create campaign: %v", err)
+	}
+	return campaign
+}
+
+// endCampaignTrip records an ENDED trip for the driver and runs campaign
+// evaluation.
+func endCampaignTrip(t *testing.T, env *testEnv, campaigns *service.CampaignService, id, driverID string, duration time.Duration) *domain.Trip {
+	t.Helper()
+
+	endedAt := time.Now()
+	trip := &domain.Trip{
+		ID:        id,
+		RideID:    "ride-" + id,
+		DriverID:  driverID,
+		Status:    domain.TripStatusEnded,
+		Fare:      10,
+		StartedAt: endedAt.Add(-duration),
+		EndedAt:   endedAt,
+	}
+	_ = env.trips.Create(context.Background(), trip)
+
+	if err := campaigns.OnTripEnded(context.Background(), trip); err != nil {
+		t.Fatalf("campaign evaluation failed: %v", err)
+	}
+	return trip
+}
+
+func campaignBonuses(earnings *MockEarningsRepository, driverID string) []*domain.EarningsEntry {
+	entries, _ := earnings.ListByDriver(context.Background(), driverID)
+	return entries
+}
+
+func TestCampaign_PartialProgress(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	earnings := NewMockEarningsRepository()
+	campaigns := newCampaignService(env, earnings)
+	trips := addCampaign(t, campaigns, "Ten today", domain.CampaignCriteriaTripCount, 3, "")
+	hours := addCampaign(t, campaigns, "Two hours", domain.CampaignCriteriaTripHours, 2, "")
+
+	endCampaignTrip(t, env, campaigns, "trip-1", "driver-basic", 30*time.Minute)
+	endCampaignTrip(t, env, campaigns, "trip-2", "driver-basic", 30*time.Minute)
+
+	standings, err := campaigns.DriverCampaigns(context.Background(), "driver-basic")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	progress := map[string]*domain.CampaignProgress{}
+	for _, s := range standings {
+		progress[s.Campaign.ID] = s.Progress
+	}
+
+	if p := progress[trips.ID]; p == nil || p.Progress != 2 || p.IsCompleted() {
+		t.Errorf("expected 2 of 3 trips, got %+v", p)
+	}
+	if p := progress[hours.ID]; p == nil || p.Progress < 0.99 || p.Progress > 1.01 || p.IsCompleted() {
+		t.Errorf("expected about 1 of 2 hours, got %+v", p)
+	}
+	if got := campaignBonuses(earnings, "driver-basic"); len(got) != 0 {
+		t.Errorf("expected no bonus yet, got %d", len(got))
+	}
+}
+
+func TestCampaign_CompletionCreditsBonusOnce(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	earnings := NewMockEarningsRepository()
+	campaigns := newCampaignService(env, earnings)
+	campaign := addCampaign(t, campaigns, "Three trips", domain.CampaignCriteriaTripCount, 3, "")
+
+	for _, id := range []string{"trip-1", "trip-2", "trip-3", "trip-4"} {
+		endCampaignTrip(t, env, campaigns, id, "driver-basic", 20*time.Minute)
+	}
+
+	bonuses := campaignBonuses(earnings, "driver-basic")
+	if len(bonuses) != 1 {
+		t.Fatalf("expected exactly one bonus, got %d", len(bonuses))
+	}
+	if bonuses[0].Amount != 20 || bonuses[0].Kind != domain.EarningsKindCampaignBonus {
+		t.Errorf("unexpected bonus entry: %+v", bonuses[0])
+	}
+
+	standings, _ := campaigns.DriverCampaigns(context.Background(), "driver-basic")
+	if len(standings) != 1 || standings[0].Campaign.ID != campaign.ID || !standings[0].Progress.IsCompleted() {
+		t.Errorf("expected completed campaign, got %+v", standings)
+	}
+}
+
+func TestCampaign_TierEligibility(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	earnings := NewMockEarningsRepository()
+	campaigns := newCampaignService(env, earnings)
+	addCampaign(t, campaigns, "Premium only", domain.CampaignCriteriaTripCount, 1, domain.DriverTierPremium)
+
+	endCampaignTrip(t, env, campaigns, "trip-basic", "driver-basic", 20*time.Minute)
+	endCampaignTrip(t, env, campaigns, "trip-premium", "driver-premium", 20*time.Minute)
+
+	if got := campaignBonuses(earnings, "driver-basic"); len(got) != 0 {
+		t.Errorf("basic driver must not earn a premium-only bonus, got %d", len(got))
+	}
+	if got := campaignBonuses(earnings, "driver-premium"); len(got) != 1 {
+		t.Errorf("expected premium driver bonus, got %d", len(got))
+	}
+
+	standings, _ := campaigns.DriverCampaigns(context.Background(), "driver-basic")
+	if len(standings) != 0 {
+		t.Errorf("expected no campaigns listed for basic driver, got %d", len(standings))
+	}
+}
+
+func TestCampaign_CityEligibility(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	earnings := NewMockEarningsRepository()
+	campaigns := newCampaignService(env, earnings)
+
+	now := time.Now()
+	campaign, err := campaigns.CreateCampaign(context.Background(), service.CampaignRequest{
+		Name: "Bengaluru only", Criteria: domain.CampaignCriteriaTripCount, Target: 1, RewardAmount: 20,
+		City: " Bengaluru ", StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("failed to create campaign: %v", err)
+	}
+	if campaign.City != "bengaluru" {
+		t.Errorf("expected normalized city bengaluru, got %q", campaign.City)
+	}
+
+	endCampaignTrip(t, env, campaigns, "trip-basic", "driver-basic", 20*time.Minute)
+	endCampaignTrip(t, env, campaigns, "trip-premium", "driver-premium", 20*time.Minute)
+
+	if got := campaignBonuses(earnings, "driver-basic"); len(got) != 1 {
+		t.Errorf("expected Bengaluru driver bonus, got %d", len(got))
+	}
+	if got := campaignBonuses(earnings, "driver-premium"); len(got) != 0 {
+		t.Errorf("Mumbai driver must not earn a Bengaluru-only bonus, got %d", len(got))
+	}
+
+	standings, _ := campaigns.DriverCampaigns(context.Background(), "driver-premium")
+	if len(standings) != 0 {
+		t.Errorf("expected no campaigns listed for Mumbai driver, got %d", len(standings))
+	}
+}
+
+// addOnlineSlots records one location point per 5-minute slot for the
+// driver, going back from now.
+func addOnlineSlots(history *MockLocationHistoryRepository, driverID string, slots int) {
+	now := time.Now()
+	for i := 1; i <= slots; i++ {
+		history.Add(&domain.LocationPoint{DriverID: driverID, RecordedAt: now.Add(-time.Duration(i) * 5 * time.Minute)})
+	}
+}
+
+func TestCampaign_OnlineHours(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	earnings := NewMockEarningsRepository()
+	history := NewMockLocationHistoryRepository()
+	campaigns := newCampaignServiceWithHistory(env, earnings, history)
+	campaign := addCampaign(t, campaigns, "One hour online", domain.CampaignCriteriaOnlineHours, 1, "")
+
+	addOnlineSlots(history, "driver-basic", 12)
+	addOnlineSlots(history, "driver-premium", 6)
+
+	// Trips ending do not count towards online hours.
+	endCampaignTrip(t, env, campaigns, "trip-premium", "driver-premium", 2*time.Hour)
+
+	standings, err := campaigns.DriverCampaigns(context.Background(), "driver-premium")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(standings) != 1 || standings[0].Progress.Progress != 0.5 || standings[0].Progress.IsCompleted() {
+		t.Errorf("expected half an hour of live progress, got %+v", standings)
+	}
+
+	now := time.Now()
+	for i := 0; i < 2; i++ {
+		completed, err := campaigns.SweepOnlineHours(context.Background(), now.Add(-5*time.Minute), now)
+		if err != nil {
+			t.Fatalf("sweep failed: %v", err)
+		}
+		if want := 1 - i; completed != want {
+			t.Errorf("sweep %d: expected %d completions, got %d", i+1, want, completed)
+		}
+	}
+
+	bonuses := campaignBonuses(earnings, "driver-basic")
+	if len(bonuses) != 1 || bonuses[0].Reference != "campaign:"+campaign.ID+":driver-basic" {
+		t.Fatalf("expected exactly one bonus for the driver online an hour, got %+v", bonuses)
+	}
+	if got := campaignBonuses(earnings, "driver-premium"); len(got) != 0 {
+		t.Errorf("expected no bonus for the driver online half an hour, got %d", len(got))
+	}
+
+	standings, _ = campaigns.DriverCampaigns(context.Background(), "driver-basic")
+	if len(standings) != 1 || standings[0].Progress.Progress != 1 || !standings[0].Progress.IsCompleted() {
+		t.Errorf("expected completed campaign, got %+v", standings)
+	}
+}
+
+func TestCampaign_EndTripRetryIsIdempotent(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	earnings := NewMockEarningsRepository()
+	campaigns := newCampaignService(env, earnings)
+	addCampaign(t, campaigns, "One trip", domain.CampaignCriteriaTripCount, 1, "")
+	addCampaign(t, campaigns, "Two trips", domain.CampaignCriteriaTripCount, 2, "")
+
+	// The first evaluation fails to credit the bonus after counting the trip.
+	earnings.CreditErrors = []error{ErrMockTimeout}
+	trip := &domain.Trip{
+		ID: "trip-1", RideID: "ride-1", DriverID: "driver-basic", Status: domain.TripStatusEnded,
+		StartedAt: time.Now().Add(-20 * time.Minute), EndedAt: time.Now(),
+	}
+	_ = env.trips.Create(context.Background(), trip)
+	if err := campaigns.OnTripEnded(context.Background(), trip); !errors.Is(err, ErrMockTimeout) {
+		t.Fatalf("expected credit failure, got %v", err)
+	}
+	if got := campaignBonuses(earnings, "driver-basic"); len(got) != 0 {
+		t.Fatalf("expected no bonus after failed credit, got %d", len(got))
+	}
+
+	// Retrying EndTrip on the ended trip re-drives the evaluation.
+	tripService := service.NewTripService(service.TripServiceDeps{TripRepo: env.trips, CampaignService: campaigns})
+	for i := 0; i < 3; i++ {
+		if _, err := tripService.EndTrip(context.Background(), service.EndTripRequest{TripID: "trip-1"}); !errors.Is(err, service.ErrTripAlreadyEnded) {
+			t.Fatalf("expected ErrTripAlreadyEnded, got %v", err)
+		}
+	}
+
+	if got := campaignBonuses(earnings, "driver-basic"); len(got) != 1 {
+		t.Fatalf("expected exactly one bonus after retries, got %d", len(got))
+	}
+
+	// The retried trip still counts once towards the two-trip campaign.
+	standings, _ := campaigns.DriverCampaigns(context.Background(), "driver-basic")
+	for _, s := range standings {
+		if s.Campaign.Name == "Two trips" && (s.Progress.Progress != 1 || s.Progress.IsCompleted()) {
+			t.Errorf("expected 1 of 2 trips after retries, got %+v", s.Progress)
+		}
+	}
+}
+
+func TestCampaign_AdminCRUDAndDriverProgress(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	earnings := NewMockEarningsRepository()
+	campaigns := newCampaignService(env, earnings)
+	h := handler.NewCampaignHandler(campaigns)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/v1/admin/campaigns", h.Create)
+	router.GET("/v1/admin/campaigns/:id", h.Get)
+	router.PUT("/v1/admin/campaigns/:id", h.Update)
+	router.DELETE("/v1/admin/campaigns/:id", h.Delete)
+	router.GET("/v1/drivers/:id/campaigns", h.GetDriverCampaigns)
+
+	do := func(method, path string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			_ = json.NewEncoder(&buf).Encode(body)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, path, &buf))
+		return w
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	req := handler.CampaignRequest{
+		Name: "Ten today", Criteria: "TRIP_COUNT", Target: 10, RewardAmount: 20,
+		StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour),
+	}
+
+	bad := req
+	bad.EndsAt = bad.StartsAt
+	if w := do(http.MethodPost, "/v1/admin/campaigns", bad); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for inverted window, got %d", w.Code)
+	}
+
+	w := do(http.MethodPost, "/v1/admin/campaigns", req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var created handler.CampaignResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &created)
+
+	req.Target = 2
+	if w := do(http.MethodPut, "/v1/admin/campaigns/"+created.ID, req); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 on update, got %d", w.Code)
+	}
+
+	endCampaignTrip(t, env, campaigns, "trip-1", "driver-basic", 20*time.Minute)
+
+	w = do(http.MethodGet, "/v1/drivers/driver-basic/campaigns", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var standings []handler.DriverCampaignResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &standings)
+	if len(standings) != 1 || standings[0].Progress != 1 || standings[0].Remaining != 1 || standings[0].Completed {
+		t.Errorf("unexpected driver progress: %+v", standings)
+	}
+
+	if w := do(http.MethodGet, "/v1/drivers/driver-unknown/campaigns", nil); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown driver, got %d", w.Code)
+	}
+
+	if w := do(http.MethodDelete, "/v1/admin/campaigns/"+created.ID, nil); w.Code != http.StatusNoContent {
+		t.Errorf("expected 204 on delete, got %d", w.Code)
+	}
+	if w := do(http.MethodGet, "/v1/admin/campaigns/"+created.ID, nil); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 after delete, got %d", w.Code)
+	}
+}
@@ -0,0 +1,261 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// CARD AUTHORIZATION
+// ──────────────────────────────────────────────
+
+// newAuthTripService returns a trip service holding a 20% buffer on card
+// rides.
+func newAuthTripService(env *testEnv) *service.TripService {
+	deps := env.tripDeps()
+	deps.PaymentService = service.NewPaymentService(env.payments, env.psp, nil, nil, nil, 20)
+	return service.NewTripService(deps)
+}
+
+// addAssignedRide seeds ride-1, about 3.5 km long, assigned to driver-1.
+func addAuthRide(env *testEnv, method domain.PaymentMethod) {
+	env.rides.AddRide(&domain.Ride{
+		ID: "ride-1", RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 13.0, DestinationLng: 77.6,
+		Status: domain.RideStatusAssigned, AssignedDriverID: "driver-1", SurgeMultiplier: 1, PaymentMethod: method, Version: 1,
+	})
+}
+
+// addRunningTrip seeds trip-1, started 20 minutes ago on a CARD ride, with
+// the given amount held on the card.
+func addAuthRunningTrip(t *testing.T, env *testEnv, held float64) {
+	t.Helper()
+
+	env.rides.AddRide(&domain.Ride{
+		ID: "ride-1", RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 13.0, DestinationLng: 77.6,
+		Status: domain.RideStatusInTrip, AssignedDriverID: "driver-1", SurgeMultiplier: 1,
+		PaymentMethod: domain.PaymentMethodCard, Version: 2,
+	})
+	_ = env.trips.Create(context.Background(), &domain.Trip{
+		ID: "trip-1", RideID: "ride-1", DriverID: "driver-1", Status: domain.TripStatusStarted,
+		StartedAt: time.Now().Add(-20 * time.Minute), Version: 1,
+	})
+
+	authID, err := env.psp.Authorize(context.Background(), "", held)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = env.payments.Create(context.Background(), &domain.Payment{
+		ID: "payment-1", TripID: "trip-1", Amount: held, Status: domain.PaymentStatusAuthorized,
+		IdempotencyKey: "payment:trip-1", AuthID: authID,
+	})
+}
+
+// insertedPayments returns the arguments of the payments stored when the
+// trip started.
+func insertedPayments(env *testEnv) [][]any {
+	var inserts [][]any
+	for _, q := range env.rec.Queries() {
+		if strings.Contains(q.Query, "INSERT INTO payments") {
+			args := make([]any, len(q.Args))
+			for i, a := range q.Args {
+				args[i] = a
+			}
+			inserts = append(inserts, args)
+		}
+	}
+	return inserts
+}
+
+func TestCardAuthorization_StartTripHoldsEstimatePlusBuffer(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	tripService := newAuthTripService(env)
+	addAuthRide(env, domain.PaymentMethodCard)
+
+	trip, err := tripService.StartTrip(context.Background(), service.StartTripRequest{RideID: "ride-1", DriverID: "driver-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// About 8.4 minutes at city speed: 2.00 + 8.4 x 0.50 = 6.21, plus 20%.
+	held := env.psp.Holds["auth-1"]
+	if len(env.psp.Holds) != 1 || math.Abs(held-7.45) > 0.05 {
+		t.Fatalf("expected one hold of about 7.45, got %v", env.psp.Holds)
+	}
+
+	inserts := insertedPayments(env)
+	if len(inserts) != 1 {
+		t.Fatalf("expected the authorized payment stored with the trip, got %d inserts", len(inserts))
+	}
+	// trip_id, amount, status ... auth_id
+	if inserts[0][1] != trip.ID || inserts[0][2] != held || inserts[0][3] != string(domain.PaymentStatusAuthorized) || inserts[0][9] != "auth-1" {
+		t.Errorf("expected an AUTHORIZED payment for the trip holding auth-1, got %v", inserts[0])
+	}
+}
+
+func TestCardAuthorization_DeclineBlocksTripStart(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	tripService := newAuthTripService(env)
+	addAuthRide(env, domain.PaymentMethodCard)
+	env.psp.AuthorizeError = &service.PaymentDeclinedError{Reason: "insufficient funds"}
+
+	_, err := tripService.StartTrip(context.Background(), service.StartTripRequest{RideID: "ride-1", DriverID: "driver-1"})
+	if !errors.Is(err, service.ErrPaymentAuthorizationDeclined) || !strings.Contains(err.Error(), "insufficient funds") {
+		t.Fatalf("expected ErrPaymentAuthorizationDeclined with the reason, got %v", err)
+	}
+	if len(env.rec.Queries()) != 0 {
+		t.Errorf("expected no trip started, got %d statements", len(env.rec.Queries()))
+	}
+
+	// The driver's accept is refused with 402 Payment Required.
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/v1/drivers/:id/accept", handler.NewDriverHandler(nil, tripService, nil, "US", nil, nil).AcceptRide)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/drivers/driver-1/accept", strings.NewReader(`{"ride_id":"ride-1"}`)))
+	if w.Code != http.StatusPaymentRequired {
+		t.Errorf("expected 402, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestCardAuthorization_FailedStartVoidsHold(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	tripService := newAuthTripService(env)
+	addAuthRide(env, domain.PaymentMethodCard)
+	env.rec.ExecError = func(query string) error {
+		if strings.Contains(query, "UPDATE drivers") {
+			return errors.New("connection reset")
+		}
+		return nil
+	}
+
+	if _, err := tripService.StartTrip(context.Background(), service.StartTripRequest{RideID: "ride-1", DriverID: "driver-1"}); err == nil {
+		t.Fatal("expected the start to fail")
+	}
+	if len(env.psp.Voided) != 1 || env.psp.Voided[0] != "auth-1" {
+		t.Errorf("expected the hold voided, got %v", env.psp.Voided)
+	}
+}
+
+func TestCardAuthorization_CashRideHoldsNothing(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	tripService := newAuthTripService(env)
+	addAuthRide(env, domain.PaymentMethodCash)
+
+	if _, err := tripService.StartTrip(context.Background(), service.StartTripRequest{RideID: "ride-1", DriverID: "driver-1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(env.psp.Holds) != 0 || len(insertedPayments(env)) != 0 {
+		t.Errorf("expected no authorization for a cash ride, got %v", env.psp.Holds)
+	}
+}
+
+func TestCardAuthorization_EndTripCapturesFinalFare(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	tripService := newAuthTripService(env)
+	addAuthRunningTrip(t, env, 15)
+
+	resp, err := tripService.EndTrip(context.Background(), service.EndTripRequest{TripID: "trip-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 2.00 + 20 min x 0.50 = 12.00, under the 15.00 held.
+	if resp.Payment == nil || resp.Payment.Status != domain.PaymentStatusSuccess || math.Abs(resp.Payment.Amount-12) > 0.1 {
+		t.Fatalf("expected a SUCCESS payment of about 12.00, got %+v", resp.Payment)
+	}
+	if captured, ok := env.psp.Captured["auth-1"]; !ok || captured != resp.Payment.Amount {
+		t.Errorf("expected the fare captured against auth-1, got %v", env.psp.Captured)
+	}
+	if env.psp.ChargeCallCount != 0 || len(env.psp.Voided) != 0 {
+		t.Errorf("expected no separate charge or void, got %d charges and voids %v", env.psp.ChargeCallCount, env.psp.Voided)
+	}
+}
+
+func TestCardAuthorization_FareAboveHoldReauthorizes(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	tripService := newAuthTripService(env)
+	addAuthRunningTrip(t, env, 10)
+
+	resp, err := tripService.EndTrip(context.Background(), service.EndTripRequest{TripID: "trip-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp.Payment == nil || resp.Payment.Status != domain.PaymentStatusSuccess || resp.Payment.AuthID != "auth-2" {
+		t.Fatalf("expected a SUCCESS payment captured against a new hold, got %+v", resp.Payment)
+	}
+	if env.psp.Holds["auth-2"] != resp.Payment.Amount || env.psp.Captured["auth-2"] != resp.Payment.Amount {
+		t.Errorf("expected the full fare re-authorized and captured, got holds %v and captures %v", env.psp.Holds, env.psp.Captured)
+	}
+	if len(env.psp.Voided) != 1 || env.psp.Voided[0] != "auth-1" {
+		t.Errorf("expected the original hold voided, got %v", env.psp.Voided)
+	}
+	if stored := env.payments.GetPaymentByTripID("trip-1"); stored.AuthID != "auth-2" || stored.Amount != resp.Payment.Amount {
+		t.Errorf("expected the new hold and final amount stored, got %+v", stored)
+	}
+}
+
+func TestCardAuthorization_DeclinedReauthorizationFailsPayment(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	tripService := newAuthTripService(env)
+	addAuthRunningTrip(t, env, 10)
+	env.psp.AuthorizeError = &service.PaymentDeclinedError{Reason: "insufficient funds"}
+
+	resp, err := tripService.EndTrip(context.Background(), service.EndTripRequest{TripID: "trip-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Payment == nil || resp.Payment.Status != domain.PaymentStatusFailed || resp.Payment.FailureReason != "insufficient funds" {
+		t.Fatalf("expected a FAILED payment with the decline reason, got %+v", resp.Payment)
+	}
+	if len(env.psp.Captured) != 0 || len(env.psp.Voided) != 1 {
+		t.Errorf("expected nothing captured and the hold released, got captures %v and voids %v", env.psp.Captured, env.psp.Voided)
+	}
+}
+
+func TestCardAuthorization_AbortWithNothingOwedVoidsHold(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	tripService := newAuthTripService(env)
+	addAuthRunningTrip(t, env, 15)
+
+	resp, err := tripService.AbortTrip(context.Background(), service.AbortTripRequest{
+		TripID: "trip-1", AbortedBy: domain.AbortPartyDriver, Reason: "vehicle breakdown",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Payment == nil || resp.Payment.Status != domain.PaymentStatusVoided {
+		t.Fatalf("expected the payment VOIDED, got %+v", resp.Payment)
+	}
+	if len(env.psp.Voided) != 1 || env.psp.Voided[0] != "auth-1" || len(env.psp.Captured) != 0 {
+		t.Errorf("expected auth-1 voided and nothing captured, got voids %v and captures %v", env.psp.Voided, env.psp.Captured)
+	}
+}
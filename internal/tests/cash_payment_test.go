@@ -0,0 +1,184 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/middleware"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// CASH PAYMENT RECONCILIATION
+// ──────────────────────────────────────────────
+
+// seedCashTrips seeds an in-progress trip for each payment method, all
+// driven by driver-1.
+func seedCashTrips(env *testEnv, methods ...domain.PaymentMethod) {
+	for _, method := range methods {
+		id := string(method)
+		env.rides.AddRide(&domain.Ride{
+			ID: "ride-" + id, RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 12.3, DestinationLng: 76.64,
+			Status: domain.RideStatusInTrip, AssignedDriverID: "driver-1", SurgeMultiplier: 1, PaymentMethod: method, Version: 2,
+		})
+		_ = env.trips.Create(context.Background(), &domain.Trip{
+			ID: "trip-" + id, RideID: "ride-" + id, DriverID: "driver-1", Status: domain.TripStatusStarted,
+			StartedAt: time.Now().Add(-20 * time.Minute), Version: 1,
+		})
+	}
+}
+
+func cashRouter(env *testEnv, tripService *service.TripService) *gin.Engine {
+	reportService := service.NewReportService(NewMockReportRepository(env.trips, env.rides, env.payments))
+
+	router := newTestRouter()
+	router.Use(middleware.IdentityMiddleware(testAdminToken))
+	router.POST("/v1/trips/:id/confirm-cash", handler.NewTripHandler(tripService, nil).ConfirmCash)
+	router.GET("/v1/drivers/:id/collections", handler.NewReportHandler(reportService).GetDriverCollections)
+	return router
+}
+
+func endCashTrip(t *testing.T, tripService *service.TripService, tripID string) *domain.Payment {
+	t.Helper()
+
+	resp, err := tripService.EndTrip(context.Background(), service.EndTripRequest{TripID: tripID})
+	if err != nil {
+		t.Fatalf("failed to end %s: %v", tripID, err)
+	}
+	if resp.Payment == nil {
+		t.Fatalf("expected a payment for %s", tripID)
+	}
+	return resp.Payment
+}
+
+// confirmCash has driverID confirm collecting the trip's cash.
+func confirmCash(router *gin.Engine, tripID, driverID string) *httptest.ResponseRecorder {
+	return postAs(router, "/v1/trips/"+tripID+"/confirm-cash", "", driverID)
+}
+
+func cashCollections(t *testing.T, router *gin.Engine) handler.CollectionsReportResponse {
+	t.Helper()
+
+	w := getAs(router, "/v1/drivers/driver-1/collections", "driver-1")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 from collections, got %d", w.Code)
+	}
+	var report handler.CollectionsReportResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &report)
+	return report
+}
+
+func TestCashPayment_EndTripLeavesCashDueWithoutCharge(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	seedCashTrips(env, domain.PaymentMethodCash, domain.PaymentMethodCard)
+	tripService := service.NewTripService(env.tripDeps())
+	router := cashRouter(env, tripService)
+
+	cash := endCashTrip(t, tripService, "trip-CASH")
+	if cash.Status != domain.PaymentStatusCashDue {
+		t.Errorf("expected cash payment CASH_DUE, got %s", cash.Status)
+	}
+	if got := env.psp.ChargeCallCount; got != 0 {
+		t.Errorf("cash must not be charged through the PSP, got %d calls", got)
+	}
+
+	card := endCashTrip(t, tripService, "trip-CARD")
+	if card.Status != domain.PaymentStatusSuccess {
+		t.Errorf("expected card payment SUCCESS, got %s", card.Status)
+	}
+	if got := env.psp.ChargeCallCount; got != 1 {
+		t.Errorf("expected one PSP charge for card, got %d", got)
+	}
+
+	report := cashCollections(t, router)
+	if report.Total.Count != 1 || len(report.Collections) != 1 || report.Collections[0].TripID != "trip-CASH" {
+		t.Errorf("expected only the cash trip outstanding, got %+v", report)
+	}
+	if math.Abs(report.Total.Amount-cash.Amount) > 0.005 {
+		t.Errorf("expected outstanding %.2f, got %.2f", cash.Amount, report.Total.Amount)
+	}
+
+	// Nobody else learns what the driver owes.
+	for _, userID := range []string{"", "driver-2", "rider-1"} {
+		if w := getAs(router, "/v1/drivers/driver-1/collections", userID); w.Code != http.StatusNotFound {
+			t.Errorf("%q: expected 404, got %d", userID, w.Code)
+		}
+	}
+	req := httptest.NewRequest(http.MethodGet, "/v1/drivers/driver-1/collections", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("admin: expected 200, got %d", w.Code)
+	}
+}
+
+func TestCashPayment_DriverConfirmsCollection(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	seedCashTrips(env, domain.PaymentMethodCash)
+	tripService := service.NewTripService(env.tripDeps())
+	router := cashRouter(env, tripService)
+	endCashTrip(t, tripService, "trip-CASH")
+
+	for _, callerID := range []string{"driver-2", "rider-1"} {
+		if w := confirmCash(router, "trip-CASH", callerID); w.Code != http.StatusForbidden {
+			t.Errorf("%s: expected 403, got %d", callerID, w.Code)
+		}
+	}
+	if w := confirmCash(router, "trip-CASH", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("anonymous: expected 400, got %d", w.Code)
+	}
+
+	w := confirmCash(router, "trip-CASH", "driver-1")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var payment handler.PaymentInfo
+	_ = json.Unmarshal(w.Body.Bytes(), &payment)
+	if payment.Status != string(domain.PaymentStatusSuccess) {
+		t.Errorf("expected SUCCESS after confirmation, got %s", payment.Status)
+	}
+
+	// Confirming again is harmless.
+	if w := confirmCash(router, "trip-CASH", "driver-1"); w.Code != http.StatusOK {
+		t.Errorf("expected 200 on repeated confirmation, got %d", w.Code)
+	}
+
+	if report := cashCollections(t, router); report.Total.Count != 0 || len(report.Collections) != 0 {
+		t.Errorf("expected no outstanding collections after confirmation, got %+v", report)
+	}
+	if got := env.psp.ChargeCallCount; got != 0 {
+		t.Errorf("confirmation must not charge the PSP, got %d calls", got)
+	}
+}
+
+func TestCashPayment_ConfirmRejectedForFailedCardPayment(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	seedCashTrips(env, domain.PaymentMethodCard)
+	tripService := service.NewTripService(env.tripDeps())
+	router := cashRouter(env, tripService)
+	env.psp.SetFailure(true, nil)
+
+	if payment := endCashTrip(t, tripService, "trip-CARD"); payment.Status != domain.PaymentStatusFailed {
+		t.Fatalf("expected failed card payment, got %s", payment.Status)
+	}
+
+	if w := confirmCash(router, "trip-CARD", "driver-1"); w.Code != http.StatusConflict {
+		t.Errorf("expected 409 confirming cash on a card payment, got %d", w.Code)
+	}
+}
@@ -0,0 +1,348 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/redis"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// PAYMENT METHOD AND TIER CATALOG
+// ──────────────────────────────────────────────
+
+const tierAuto domain.DriverTier = "AUTO"
+
+// indiaCatalog defaults to UPI and an AUTO tier that is cheaper and surges
+// less than BASIC. It offers no wallets and no PREMIUM tier.
+func indiaCatalog() *domain.Catalog {
+	return &domain.Catalog{
+		PaymentMethods:       []domain.PaymentMethod{domain.PaymentMethodUPI, domain.PaymentMethodCash, domain.PaymentMethodCard},
+		DefaultPaymentMethod: domain.PaymentMethodUPI,
+		Tiers: []domain.TierSpec{
+			{Name: tierAuto, RadiusKm: 3, FareMultiplier: 0.6, MaxSurge: 1.5},
+			{Name: domain.DriverTierBasic},
+		},
+		DefaultTier: tierAuto,
+	}
+}
+
+func TestCatalog_Validate(t *testing.T) {
+	t.Parallel()
+
+	if err := domain.DefaultCatalog().Validate(); err != nil {
+		t.Errorf("expected the default catalog to be valid, got %v", err)
+	}
+	if err := indiaCatalog().Validate(); err != nil {
+		t.Errorf("expected the custom catalog to be valid, got %v", err)
+	}
+
+	testCases := []struct {
+		name   string
+		mutate func(c *domain.Catalog)
+	}{
+		{"default method not offered", func(c *domain.Catalog) { c.DefaultPaymentMethod = domain.PaymentMethodWallet }},
+		{"default tier not offered", func(c *domain.Catalog) { c.DefaultTier = domain.DriverTierPremium }},
+		{"no tiers", func(c *domain.Catalog) { c.Tiers = nil }},
+		{"duplicate tier", func(c *domain.Catalog) { c.Tiers = append(c.Tiers, domain.TierSpec{Name: tierAuto}) }},
+		{"lower-case tier", func(c *domain.Catalog) { c.Tiers[1].Name = "basic" }},
+		{"surge cap below 1", func(c *domain.Catalog) { c.Tiers[0].MaxSurge = 0.5 }},
+		{"negative minimum fare", func(c *domain.Catalog) { c.Tiers[0].MinFare = -1 }},
+	}
+	for _, tc := range testCases {
+		catalog := indiaCatalog()
+		tc.mutate(catalog)
+		if err := catalog.Validate(); !errors.Is(err, domain.ErrInvalidCatalog) {
+			t.Errorf("%s: expected ErrInvalidCatalog, got %v", tc.name, err)
+		}
+	}
+}
+
+func TestCatalog_DriverRegistration(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	driverHandler := handler.NewDriverHandler(nil, nil, NewMockDriverRepository(), "IN", indiaCatalog(), nil)
+	router.POST("/v1/drivers/register", driverHandler.Register)
+	router.GET("/v1/drivers", driverHandler.GetAll)
+
+	testCases := []struct {
+		tier     string
+		wantCode int
+		wantTier string
+	}{
+		{"auto", http.StatusCreated, "AUTO"},
+		{"", http.StatusCreated, "AUTO"},
+		{"BASIC", http.StatusCreated, "BASIC"},
+		{"PREMIUM", http.StatusUnprocessableEntity, ""},
+	}
+
+	for i, tc := range testCases {
+		body, _ := json.Marshal(map[string]string{"name": "Ravi", "phone": "+9198765432" + string(rune('0'+i)) + "0", "tier": tc.tier})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/drivers/register", strings.NewReader(string(body))))
+
+		if w.Code != tc.wantCode {
+			t.Errorf("%q: expected %d, got %d: %s", tc.tier, tc.wantCode, w.Code, w.Body.String())
+			continue
+		}
+		if tc.wantCode != http.StatusCreated {
+			continue
+		}
+		var resp handler.DriverResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		if resp.Tier != tc.wantTier {
+			t.Errorf("%q: expected tier %s, got %s", tc.tier, tc.wantTier, resp.Tier)
+		}
+	}
+
+	// The list filter accepts the catalog's tiers only.
+	for tier, want := range map[string]int{"AUTO": http.StatusOK, "PREMIUM": http.StatusBadRequest} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/drivers?tier="+tier, nil))
+		if w.Code != want {
+			t.Errorf("tier filter %s: expected %d, got %d", tier, want, w.Code)
+		}
+	}
+}
+
+func TestCatalog_RideCreation(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		method     string
+		tier       string
+		wantCode   int
+		wantMethod domain.PaymentMethod
+		wantTier   domain.DriverTier
+	}{
+		{"defaults", "", "", http.StatusCreated, domain.PaymentMethodUPI, tierAuto},
+		{"explicit, any case", "cash", "basic", http.StatusCreated, domain.PaymentMethodCash, domain.DriverTierBasic},
+		{"method not offered", "WALLET", "", http.StatusUnprocessableEntity, "", ""},
+		{"tier not offered", "", "PREMIUM", http.StatusUnprocessableEntity, "", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rides := NewMockRideRepository()
+			matching := NewMockMatchingServiceForTest()
+			rideService := service.NewRideService(service.RideServiceDeps{RideRepo: rides, MatchingService: matching, Catalog: indiaCatalog()})
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.POST("/v1/rides", handler.NewRideHandler(rideService, nil, nil, indiaCatalog()).CreateRide)
+
+			body, _ := json.Marshal(map[string]any{
+				"rider_id": "rider-1", "pickup_lat": 12.97, "pickup_lng": 77.59, "destination_lat": 13.2, "destination_lng": 77.7,
+				"payment_method": tc.method, "tier": tc.tier,
+			})
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/rides", strings.NewReader(string(body))))
+
+			if w.Code != tc.wantCode {
+				t.Fatalf("expected %d, got %d: %s", tc.wantCode, w.Code, w.Body.String())
+			}
+			if tc.wantCode != http.StatusCreated {
+				return
+			}
+			var resp handler.CreateRideResponse
+			_ = json.Unmarshal(w.Body.Bytes(), &resp)
+			ride := rides.GetRide(resp.ID)
+			if ride.PaymentMethod != tc.wantMethod || ride.Tier != tc.wantTier {
+				t.Errorf("expected a %s ride for %s, got %s for %q", tc.wantMethod, tc.wantTier, ride.PaymentMethod, ride.Tier)
+			}
+			if got := matching.LastRequest().Tier; got != tc.wantTier {
+				t.Errorf("expected matching for %s, got %q", tc.wantTier, got)
+			}
+		})
+	}
+}
+
+func TestCatalog_MatchingFiltersByCustomTier(t *testing.T) {
+	t.Parallel()
+
+	db, _ := NewRecordingDB()
+	t.Cleanup(func() { _ = db.Close() })
+
+	drivers := NewMockDriverRepository()
+	drivers.AddDriver(&domain.Driver{ID: "driver-basic", Status: domain.DriverStatusOnline, Tier: domain.DriverTierBasic})
+	drivers.AddDriver(&domain.Driver{ID: "driver-auto", Status: domain.DriverStatusOnline, Tier: tierAuto})
+	locations := NewMockLocationStore()
+	locations.AddDriverLocation(redis.DriverLocation{DriverID: "driver-basic", Lat: 12.97, Lng: 77.59})
+	locations.AddDriverLocation(redis.DriverLocation{DriverID: "driver-auto", Lat: 12.971, Lng: 77.59})
+	rides := NewMockRideRepository()
+	rides.AddRide(&domain.Ride{ID: "ride-1", RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, Status: domain.RideStatusRequested, Version: 1})
+
+	matcher := service.NewMatchingService(service.MatchingServiceDeps{
+		DB:            db,
+		LocationStore: locations,
+		LockStore:     NewMockLockStore(),
+		DriverRepo:    drivers,
+		RideRepo:      rides,
+		TierRadiusKm:  map[domain.DriverTier]float64{tierAuto: 3},
+	})
+
+	result, err := matcher.Match(context.Background(), service.MatchRequest{RideID: "ride-1", Lat: 12.97, Lng: 77.59, Tier: tierAuto})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.DriverID != "driver-auto" {
+		t.Errorf("expected the AUTO driver matched, got %s", result.DriverID)
+	}
+	if locations.LastFindRadiusKm != 3 {
+		t.Errorf("expected the AUTO radius, got %.1f", locations.LastFindRadiusKm)
+	}
+}
+
+func TestCatalog_TierSurgeCapAndFare(t *testing.T) {
+	t.Parallel()
+
+	// One open request and no drivers nearby surges to 2.0x.
+	rides := NewMockRideRepository()
+	rides.AddRide(&domain.Ride{ID: "ride-open", RiderID: "rider-9", PickupLat: 12.97, PickupLng: 77.59, Status: domain.RideStatusRequested})
+	surgeService := service.NewSurgeService(NewMockLocationStore(), rides, nil, 0, 0, nil, nil)
+	rideService := service.NewRideService(service.RideServiceDeps{
+		RideRepo:        rides,
+		MatchingService: NewMockMatchingServiceForTest(),
+		SurgeService:    surgeService,
+		Catalog:         indiaCatalog(),
+	})
+
+	request := func(tier domain.DriverTier) *domain.Ride {
+		resp, err := rideService.CreateRide(context.Background(), service.CreateRideRequest{
+			RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 13.2, DestinationLng: 77.7, Tier: tier,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return resp.Ride
+	}
+	if ride := request(domain.DriverTierBasic); ride.SurgeMultiplier != 2.0 {
+		t.Errorf("expected BASIC to surge to 2.0x, got %.2f", ride.SurgeMultiplier)
+	}
+	auto := request(tierAuto)
+	if auto.SurgeMultiplier != 1.5 {
+		t.Fatalf("expected AUTO surge capped at 1.5x, got %.2f", auto.SurgeMultiplier)
+	}
+
+	// The AUTO fare is 0.6x the metered fare, before surge.
+	db, _ := NewRecordingDB()
+	defer db.Close()
+	auto.Status, auto.AssignedDriverID, auto.Version = domain.RideStatusInTrip, "driver-1", 2
+	tripRepo := NewMockTripRepository()
+	_ = tripRepo.Create(context.Background(), &domain.Trip{
+		ID: "trip-1", RideID: auto.ID, DriverID: "driver-1", Status: domain.TripStatusStarted,
+		StartedAt: time.Now().Add(-20 * time.Minute), Version: 1,
+	})
+	paymentService := service.NewPaymentService(NewMockPaymentRepository(), NewMockPSP(), nil, nil, nil, 0)
+	tripService := service.NewTripService(service.TripServiceDeps{
+		DB:             db,
+		TripRepo:       tripRepo,
+		RideRepo:       rides,
+		DriverRepo:     NewMockDriverRepository(),
+		PaymentService: paymentService,
+		Catalog:        indiaCatalog(),
+	})
+
+	resp, err := tripService.EndTrip(context.Background(), service.EndTripRequest{TripID: "trip-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// ($2 base + 20 min at $0.50) x 0.6 x 1.5.
+	if math.Abs(resp.Trip.Fare-10.8) > 0.05 {
+		t.Errorf("expected an AUTO fare of about 10.80, got %.2f", resp.Trip.Fare)
+	}
+}
+
+func TestCatalog_TierMinimumFare(t *testing.T) {
+	t.Parallel()
+
+	catalog := domain.DefaultCatalog()
+	catalog.Tiers = []domain.TierSpec{
+		{Name: domain.DriverTierBasic, MinFare: 5},
+		{Name: domain.DriverTierPremium, MinFare: 8},
+		{Name: tierAuto, FareMultiplier: 0.6},
+	}
+	fares := service.NewFareSchedule(catalog, 5)
+
+	// A 2-minute ride meters $3.00, under every floor; 30 minutes meters
+	// $17.00, over them.
+	testCases := []struct {
+		tier     domain.DriverTier
+		duration time.Duration
+		want     float64
+	}{
+		{domain.DriverTierBasic, 2 * time.Minute, 5},
+		{domain.DriverTierPremium, 2 * time.Minute, 8},
+		{tierAuto, 2 * time.Minute, 3}, // FARE_MIN scaled by the 0.6 multiplier
+		{"", 2 * time.Minute, 5},       // No tier: FARE_MIN
+		{domain.DriverTierPremium, 30 * time.Minute, 17},
+		{tierAuto, 30 * time.Minute, 10.2},
+	}
+	for _, tc := range testCases {
+		if got := fares.BaseFare(tc.tier, tc.duration); math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("%q for %s: expected %.2f, got %.2f", tc.tier, tc.duration, tc.want, got)
+		}
+	}
+
+	// The trip fare and its receipt agree on each tier's floor.
+	endShortTrip := func(tier domain.DriverTier) (*domain.Trip, *domain.Receipt) {
+		t.Helper()
+
+		db, _ := NewRecordingDB()
+		defer db.Close()
+		rides := NewMockRideRepository()
+		rides.AddRide(&domain.Ride{
+			ID: "ride-1", RiderID: "rider-1", AssignedDriverID: "driver-1", Tier: tier,
+			PaymentMethod: domain.PaymentMethodCash, Status: domain.RideStatusInTrip, Version: 2,
+		})
+		trips := NewMockTripRepository()
+		_ = trips.Create(context.Background(), &domain.Trip{
+			ID: "trip-1", RideID: "ride-1", DriverID: "driver-1", Status: domain.TripStatusStarted,
+			StartedAt: time.Now().Add(-2 * time.Minute), Version: 1,
+		})
+		paymentService := service.NewPaymentService(NewMockPaymentRepository(), NewMockPSP(), nil, nil, nil, 0)
+		receipts := service.NewReceiptService(nil, nil, nil, nil, fares)
+		tripService := service.NewTripService(service.TripServiceDeps{
+			DB:             db,
+			TripRepo:       trips,
+			RideRepo:       rides,
+			DriverRepo:     NewMockDriverRepository(),
+			PaymentService: paymentService,
+			ReceiptService: receipts,
+			MinFare:        5,
+			Catalog:        catalog,
+		})
+
+		resp, err := tripService.EndTrip(context.Background(), service.EndTripRequest{TripID: "trip-1"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return resp.Trip, resp.Receipt
+	}
+
+	for tier, want := range map[domain.DriverTier]float64{domain.DriverTierBasic: 5, domain.DriverTierPremium: 8} {
+		trip, receipt := endShortTrip(tier)
+		if trip.Fare != want {
+			t.Errorf("%s: expected a short trip to charge the %.2f minimum, got %.2f", tier, want, trip.Fare)
+		}
+		if receipt == nil || receipt.BaseFare != want {
+			t.Errorf("%s: expected the receipt's base fare to be the %.2f minimum, got %+v", tier, want, receipt)
+		}
+	}
+}
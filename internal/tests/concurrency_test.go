@@ -351,7 +351,7 @@ func TestMatching_NoNearbyDrivers_RideRemainsRequested(t *testing.T) {
 
 	// Simulate matching: find nearby returns empty
 	ctx := context.Background()
-	nearby, err := locationStore.FindNearbyDrivers(ctx, 12.9716, 77.5946, 5.0)
+	nearby, err := locationStore.FindNearbyDrivers(ctx, 12.9716, 77.5946, 5.0, 0)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -374,7 +374,7 @@ func TestMatching_RedisError_PropagatesError(t *testing.T) {
 	locationStore := NewMockLocationStore()
 	locationStore.FindNearbyDriversError = ErrMockTimeout
 
-	_, err := locationStore.FindNearbyDrivers(ctx, 12.9716, 77.5946, 5.0)
+	_, err := locationStore.FindNearbyDrivers(ctx, 12.9716, 77.5946, 5.0, 0)
 	if err == nil {
 		t.Error("expected error when Redis fails")
 	}
@@ -479,7 +479,7 @@ func TestPerformance_MatchingUsesRedisForLocationLookup(t *testing.T) {
 
 	// Find nearby should use Redis, not SQL
 	ctx := context.Background()
-	nearby, err := locationStore.FindNearbyDrivers(ctx, 12.9716, 77.5946, 5.0)
+	nearby, err := locationStore.FindNearbyDrivers(ctx, 12.9716, 77.5946, 5.0, 0)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -0,0 +1,238 @@
+package tests
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"ride/internal/config"
+)
+
+// ──────────────────────────────────────────────
+// CONFIGURATION VALIDATION
+// ──────────────────────────────────────────────
+
+// These tests set environment variables, so none of them run in parallel.
+
+// validationProblems returns the problems Validate reports for cfg.
+func validationProblems(t *testing.T, cfg *config.Config) []string {
+	t.Helper()
+
+	err := cfg.Validate()
+	if err == nil {
+		return nil
+	}
+	var invalid *config.ValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("expected a *config.ValidationError, got %T: %v", err, err)
+	}
+	return invalid.Problems
+}
+
+// expectProblem fails unless exactly one problem is reported and it names key.
+func expectProblem(t *testing.T, problems []string, key string) {
+	t.Helper()
+
+	if len(problems) != 1 || !strings.HasPrefix(problems[0], key+":") {
+		t.Errorf("expected one %s problem, got %q", key, problems)
+	}
+}
+
+func TestConfigValidation_DefaultsAreValid(t *testing.T) {
+	if problems := validationProblems(t, config.Load()); len(problems) != 0 {
+		t.Errorf("expected the defaults to be valid, got %q", problems)
+	}
+}
+
+func TestConfigValidation_Rules(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(cfg *config.Config)
+		key    string
+	}{
+		{"missing server port", func(c *config.Config) { c.Server.Port = "" }, "SERVER_PORT"},
+		{"server port not a number", func(c *config.Config) { c.Server.Port = "http" }, "SERVER_PORT"},
+		{"server port out of range", func(c *config.Config) { c.Server.Port = "70000" }, "SERVER_PORT"},
+		{"grpc port not a number", func(c *config.Config) { c.Server.GRPCPort = "grpc" }, "GRPC_PORT"},
+		{"grpc port shared with http", func(c *config.Config) { c.Server.GRPCPort = c.Server.Port }, "GRPC_PORT"},
+		{"missing database host", func(c *config.Config) { c.Database.Host = " " }, "DB_HOST"},
+		{"missing database name", func(c *config.Config) { c.Database.DBName = "" }, "DB_NAME"},
+		{"database port zero", func(c *config.Config) { c.Database.Port = "0" }, "DB_PORT"},
+		{"unknown ssl mode", func(c *config.Config) { c.Database.SSLMode = "always" }, "DB_SSLMODE"},
+		{"redis address without port", func(c *config.Config) { c.Redis.Addr = "localhost" }, "REDIS_ADDR"},
+		{"redis address bad port", func(c *config.Config) { c.Redis.Addr = "localhost:redis" }, "REDIS_ADDR"},
+		{"negative redis db", func(c *config.Config) { c.Redis.DB = -1 }, "REDIS_DB"},
+		{"unknown gin mode", func(c *config.Config) { c.Server.GinMode = "prod" }, "GIN_MODE"},
+		{"unknown app env", func(c *config.Config) { c.Server.Env = "prod" }, "APP_ENV"},
+		{"debug mode in production", func(c *config.Config) { c.Server.GinMode = "debug" }, "GIN_MODE"},
+		{"zero read timeout", func(c *config.Config) { c.Server.ReadTimeout = 0 }, "SERVER_READ_TIMEOUT"},
+		{"negative request timeout", func(c *config.Config) { c.Server.RequestTimeout = -time.Second }, "SERVER_REQUEST_TIMEOUT"},
+		{"zero exclusion ttl", func(c *config.Config) { c.Matching.ExclusionTTL = 0 }, "MATCHING_EXCLUSION_TTL"},
+		{"zero quote ttl", func(c *config.Config) { c.Quote.TTL = 0 }, "RIDE_QUOTE_TTL"},
+		{"zero location sweep interval", func(c *config.Config) { c.Redis.LocationSweepInterval = 0 }, "REDIS_LOCATION_SWEEP_INTERVAL"},
+		{"zero max candidates", func(c *config.Config) { c.Matching.MaxCandidates = 0 }, "MATCHING_MAX_CANDIDATES"},
+		{"zero history batch size", func(c *config.Config) { c.History.BatchSize = 0 }, "LOCATION_HISTORY_BATCH_SIZE"},
+		{"fetch limit below max points", func(c *config.Config) { c.OpsMap.FetchLimit = c.OpsMap.MaxPoints - 1 }, "OPS_MAP_FETCH_LIMIT"},
+		{"zero access log sample rate", func(c *config.Config) { c.Server.AccessLogSampleRate = 0 }, "SERVER_ACCESS_LOG_SAMPLE_RATE"},
+		{"negative psp retries", func(c *config.Config) { c.PSP.MaxRetries = -1 }, "PSP_MAX_RETRIES"},
+		{"zero matching radius", func(c *config.Config) { c.Matching.BasicRadiusKm = 0 }, "MATCHING_RADIUS_KM_BASIC"},
+		{"commission above 100", func(c *config.Config) { c.Fare.CommissionPercent = 120 }, "FARE_COMMISSION_PERCENT"},
+		{"fare max below min", func(c *config.Config) { c.Fare.MaxFare = c.Fare.MinFare - 1 }, "FARE_MAX"},
+		{"negative auth buffer", func(c *config.Config) { c.Payment.AuthBufferPercent = -5 }, "PAYMENT_AUTH_BUFFER_PERCENT"},
+		{"smoothing above 1", func(c *config.Config) { c.Surge.Smoothing = 1.5 }, "SURGE_SMOOTHING_FACTOR"},
+		{"zero smoothing", func(c *config.Config) { c.Surge.Smoothing = 0 }, "SURGE_SMOOTHING_FACTOR"},
+		{"unknown abort fare", func(c *config.Config) { c.Trip.DriverAbortFare = "FULL" }, "TRIP_DRIVER_ABORT_FARE"},
+		{"unknown blob store", func(c *config.Config) { c.Attachment.Store = "s3" }, "BLOB_STORE"},
+		{"attachment larger than body limit", func(c *config.Config) {
+			c.Server.MaxBodyBytes = 1 << 20
+			c.Attachment.MaxBytes = 2 << 20
+		}, "ATTACHMENT_MAX_BYTES"},
+		{"unknown floor timezone", func(c *config.Config) { c.Surge.FloorTimezone = "Mars/Olympus" }, "SURGE_FLOOR_TIMEZONE"},
+		{"floor time not a time of day", func(c *config.Config) {
+			c.Surge.Floors = []config.SurgeFloorConfig{{Start: "8am", End: "09:00", Multiplier: 1.2}}
+		}, "SURGE_FLOORS[0]"},
+		{"floor multiplier below 1", func(c *config.Config) {
+			c.Surge.Floors = []config.SurgeFloorConfig{{Start: "08:00", End: "09:00", Multiplier: 0.8}}
+		}, "SURGE_FLOORS[0]"},
+		{"new relic without license key", func(c *config.Config) {
+			c.NewRelic.Enabled = true
+			c.NewRelic.LicenseKey = ""
+		}, "NEW_RELIC_LICENSE_KEY"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Load()
+			tt.modify(cfg)
+			expectProblem(t, validationProblems(t, cfg), tt.key)
+		})
+	}
+}
+
+func TestConfigValidation_GinModeFollowsAppEnv(t *testing.T) {
+	for env, mode := range map[string]string{"development": "debug", "staging": "release", "production": "release"} {
+		t.Setenv("APP_ENV", env)
+		if cfg := config.Load(); cfg.Server.GinMode != mode {
+			t.Errorf("%s: expected gin mode %s, got %s", env, mode, cfg.Server.GinMode)
+		}
+	}
+
+	t.Setenv("GIN_MODE", "test")
+	if cfg := config.Load(); cfg.Server.GinMode != "test" {
+		t.Errorf("expected GIN_MODE to override APP_ENV, got %s", cfg.Server.GinMode)
+	}
+}
+
+func TestConfigValidation_ZeroDisablesOptionalLimits(t *testing.T) {
+	cfg := config.Load()
+	cfg.Server.RequestTimeout = 0
+	cfg.Server.MaxBodyBytes = 0
+	cfg.Database.SlowQueryThreshold = 0
+	cfg.Matching.MaxConcurrentPerArea = 0
+
+	if problems := validationProblems(t, cfg); len(problems) != 0 {
+		t.Errorf("expected 0 to be accepted where it disables a limit, got %q", problems)
+	}
+}
+
+func TestConfigValidation_ReportsEveryProblem(t *testing.T) {
+	t.Setenv("REDIS_DB", "abc")
+	t.Setenv("SERVER_READ_TIMEOUT", "ten seconds")
+	t.Setenv("NEW_RELIC_ENABLED", "true")
+	t.Setenv("NEW_RELIC_LICENSE_KEY", "")
+	t.Setenv("FARE_COMMISSION_PERCENT", "150")
+
+	problems := validationProblems(t, config.Load())
+	for _, key := range []string{"REDIS_DB", "SERVER_READ_TIMEOUT", "NEW_RELIC_LICENSE_KEY", "FARE_COMMISSION_PERCENT"} {
+		found := false
+		for _, p := range problems {
+			if strings.HasPrefix(p, key+":") {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected a %s problem, got %q", key, problems)
+		}
+	}
+}
+
+// writeConfigFile writes content to a file named name in a temporary
+// directory and points CONFIG_FILE at it.
+func writeConfigFile(t *testing.T, name, content string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+}
+
+func TestConfigFile_Precedence(t *testing.T) {
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"config.yaml", "DB_HOST: db.internal\nDB_PORT: 6432\nSERVER_READ_TIMEOUT: 30s\nFARE_COMMISSION_PERCENT: 17.5\n" +
+			"SURGE_FLOORS:\n  - {start: \"07:00\", end: \"09:30\", multiplier: 1.3}\n"},
+		{"config.json", `{"DB_HOST": "db.internal", "DB_PORT": 6432, "SERVER_READ_TIMEOUT": "30s", "FARE_COMMISSION_PERCENT": 17.5,
+			"SURGE_FLOORS": [{"start": "07:00", "end": "09:30", "multiplier": 1.3}]}`},
+	}
+
+	for _, f := range files {
+		t.Run(f.name, func(t *testing.T) {
+			writeConfigFile(t, f.name, f.content)
+			t.Setenv("DB_PORT", "7432")
+
+			cfg := config.Load()
+			if problems := validationProblems(t, cfg); len(problems) != 0 {
+				t.Fatalf("expected a valid configuration, got %q", problems)
+			}
+			// The environment wins over the file ...
+			if cfg.Database.Port != "7432" {
+				t.Errorf("expected DB_PORT from the environment, got %q", cfg.Database.Port)
+			}
+			// ... the file over the defaults ...
+			if cfg.Database.Host != "db.internal" || cfg.Server.ReadTimeout != 30*time.Second || cfg.Fare.CommissionPercent != 17.5 {
+				t.Errorf("expected settings from the file, got host %q, read timeout %s, commission %g",
+					cfg.Database.Host, cfg.Server.ReadTimeout, cfg.Fare.CommissionPercent)
+			}
+			if len(cfg.Surge.Floors) != 1 || cfg.Surge.Floors[0].Start != "07:00" || cfg.Surge.Floors[0].Multiplier != 1.3 {
+				t.Errorf("expected the file's surge floor, got %+v", cfg.Surge.Floors)
+			}
+			// ... and the defaults fill in the rest.
+			if cfg.Database.DBName != "ride_hailing" {
+				t.Errorf("expected the default DB_NAME, got %q", cfg.Database.DBName)
+			}
+		})
+	}
+}
+
+func TestConfigFile_Problems(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		key     string
+	}{
+		{"unknown setting", "config.yaml", "DB_HOST: db.internal\nDB_HOTS: typo\n", "CONFIG_FILE"},
+		{"malformed value", "config.yaml", "REDIS_DB: primary\n", "REDIS_DB"},
+		{"unparsable file", "config.json", `{"DB_HOST": `, "CONFIG_FILE"},
+		{"unsupported format", "config.toml", "DB_HOST = \"db.internal\"\n", "CONFIG_FILE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeConfigFile(t, tt.file, tt.content)
+			expectProblem(t, validationProblems(t, config.Load()), tt.key)
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		t.Setenv("CONFIG_FILE", filepath.Join(t.TempDir(), "absent.yaml"))
+		expectProblem(t, validationProblems(t, config.Load()), "CONFIG_FILE")
+	})
+}
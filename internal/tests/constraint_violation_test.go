@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/repository"
+	"ride/internal/repository/postgres"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// CONSTRAINT VIOLATIONS
+// ──────────────────────────────────────────────
+
+// failInserts makes every INSERT against rec fail with the given Postgres error code.
+func failInserts(rec *RecordingDB, code pq.ErrorCode) {
+	rec.ExecError = func(query string) error {
+		if strings.Contains(query, "INSERT INTO") {
+			return &pq.Error{Code: code, Message: "constraint violated"}
+		}
+		return nil
+	}
+}
+
+func TestConstraintViolation_RepositoryErrors(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+		code pq.ErrorCode
+		want error
+	}{
+		{"unique violation", "23505", repository.ErrDuplicate},
+		{"foreign key violation", "23503", repository.ErrForeignKeyViolation},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			db, rec := NewRecordingDB()
+			t.Cleanup(func() { _ = db.Close() })
+			failInserts(rec, tc.code)
+
+			err := postgres.NewTripRepository(db).Create(context.Background(), &domain.Trip{
+				ID: "trip-1", RideID: "ride-missing", DriverID: "driver-1", Status: domain.TripStatusStarted,
+			})
+			if !errors.Is(err, tc.want) {
+				t.Errorf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+
+	// Other database errors pass through unchanged.
+	db, rec := NewRecordingDB()
+	t.Cleanup(func() { _ = db.Close() })
+	failInserts(rec, "23514")
+	err := postgres.NewPaymentRepository(db).Create(context.Background(), &domain.Payment{ID: "payment-1", TripID: "trip-1"})
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || errors.Is(err, repository.ErrDuplicate) || errors.Is(err, repository.ErrForeignKeyViolation) {
+		t.Errorf("expected the check violation returned as is, got %v", err)
+	}
+}
+
+func TestConstraintViolation_HTTPStatus(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+		code pq.ErrorCode
+		want int
+	}{
+		{"unique violation", "23505", http.StatusConflict},
+		{"foreign key violation", "23503", http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			db, rec := NewRecordingDB()
+			t.Cleanup(func() { _ = db.Close() })
+			failInserts(rec, tc.code)
+
+			paymentService := service.NewPaymentService(postgres.NewPaymentRepository(db), NewMockPSP(), nil, nil, nil, 0)
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.POST("/v1/payments", handler.NewPaymentHandler(paymentService, nil).ProcessPayment)
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/payments", strings.NewReader(`{"trip_id":"trip-missing","amount":12}`))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tc.want {
+				t.Errorf("expected %d, got %d: %s", tc.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
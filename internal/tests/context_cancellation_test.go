@@ -0,0 +1,131 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/domain"
+	"ride/internal/middleware"
+	"ride/internal/redis"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// CONTEXT CANCELLATION
+// ──────────────────────────────────────────────
+
+// cancellingLockStore cancels the match's context while the first driver
+// lock is being taken, and reports that driver as already locked.
+type cancellingLockStore struct {
+	*MockLockStore
+	cancel context.CancelFunc
+}
+
+func (s *cancellingLockStore) AcquireDriverLock(ctx context.Context, driverID string, ttl time.Duration) (bool, error) {
+	atomic.AddInt32(&s.AcquireCallCount, 1)
+	s.cancel()
+	return false, nil
+}
+
+func TestContextCancellation_MatchStopsPromptly(t *testing.T) {
+	t.Parallel()
+
+	db, _ := NewRecordingDB()
+	t.Cleanup(func() { _ = db.Close() })
+
+	drivers := NewMockDriverRepository()
+	locations := NewMockLocationStore()
+	var nearby []redis.DriverLocation
+	for i, id := range []string{"near", "mid", "far"} {
+		drivers.AddDriver(&domain.Driver{ID: id, Status: domain.DriverStatusOnline, Tier: domain.DriverTierBasic})
+		nearby = append(nearby, redis.DriverLocation{DriverID: id, Lat: 12.97 + float64(i)*0.001, Lng: 77.59})
+	}
+	locations.SetLocations(nearby)
+	rides := NewMockRideRepository()
+	rides.AddRide(&domain.Ride{ID: "ride-1", RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, Status: domain.RideStatusRequested, Version: 1})
+	attempts := NewMockMatchAttemptRepository()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	locks := &cancellingLockStore{MockLockStore: NewMockLockStore(), cancel: cancel}
+	matcher := service.NewMatchingService(service.MatchingServiceDeps{
+		DB:            db,
+		LocationStore: locations,
+		LockStore:     locks,
+		DriverRepo:    drivers,
+		RideRepo:      rides,
+		AttemptRepo:   attempts,
+	})
+
+	_, err := matcher.Match(ctx, service.MatchRequest{RideID: "ride-1", Lat: 12.97, Lng: 77.59, RadiusKm: 3})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if got := atomic.LoadInt32(&locks.AcquireCallCount); got != 1 {
+		t.Errorf("expected matching to stop after the first of 3 candidates, got %d lock attempts", got)
+	}
+
+	// The attempt is still recorded, despite the cancelled context.
+	recorded, _ := attempts.ListByRide(context.Background(), "ride-1")
+	if len(recorded) != 1 || recorded[0].Outcome != domain.MatchOutcomeFailed || recorded[0].SkippedLocked != 1 {
+		t.Errorf("expected one FAILED attempt with the locked driver counted, got %+v", recorded)
+	}
+	if ride, _ := rides.GetByID(context.Background(), "ride-1"); ride.Status != domain.RideStatusRequested {
+		t.Errorf("expected the ride left REQUESTED, got %s", ride.Status)
+	}
+}
+
+func TestContextCancellation_PaymentNotStartedForCancelledCaller(t *testing.T) {
+	t.Parallel()
+
+	payments := NewMockPaymentRepository()
+	psp := NewMockPSP()
+	paymentService := service.NewPaymentService(payments, psp, nil, nil, nil, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := paymentService.ProcessPayment(ctx, service.ProcessPaymentRequest{TripID: "trip-1", Amount: 20, Method: domain.PaymentMethodCard})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if got := atomic.LoadInt32(&psp.ChargeCallCount); got != 0 {
+		t.Errorf("expected no charge, got %d", got)
+	}
+
+	// A retry from a live caller charges normally.
+	payment, err := paymentService.ProcessPayment(context.Background(), service.ProcessPaymentRequest{TripID: "trip-1", Amount: 20, Method: domain.PaymentMethodCard})
+	if err != nil || payment.Status != domain.PaymentStatusSuccess {
+		t.Errorf("expected the retry to succeed, got %+v, %v", payment, err)
+	}
+}
+
+func TestContextCancellation_RequestTimeout(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.TimeoutMiddleware(time.Minute, "/streams/:id"))
+	deadline := func(c *gin.Context) {
+		if _, ok := c.Request.Context().Deadline(); ok {
+			c.Status(http.StatusOK)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+	router.GET("/work", deadline)
+	router.GET("/streams/:id", deadline)
+
+	for path, want := range map[string]int{"/work": http.StatusOK, "/streams/1": http.StatusNoContent} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != want {
+			t.Errorf("%s: expected %d, got %d", path, want, w.Code)
+		}
+	}
+}
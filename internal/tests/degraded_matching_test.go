@@ -0,0 +1,239 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+
+	"ride/internal/domain"
+	"ride/internal/redis"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// DEGRADED MATCHING WITHOUT REDIS
+// ──────────────────────────────────────────────
+
+// errRedisDown is what go-redis returns when the server refuses connections.
+var errRedisDown = &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+
+// seedRedisDown sets up ride-1 and two ONLINE drivers near its pickup,
+// driver-1 the closer, with every Redis call failing as if the server were
+// down.
+func seedRedisDown(env *testEnv, locationErr error) {
+	env.drivers.AddDriver(&domain.Driver{ID: "driver-1", Status: domain.DriverStatusOnline, Tier: domain.DriverTierBasic})
+	env.drivers.AddDriver(&domain.Driver{ID: "driver-2", Status: domain.DriverStatusOnline, Tier: domain.DriverTierBasic})
+	env.drivers.SetLastPosition("driver-1", 12.971, 77.591)
+	env.drivers.SetLastPosition("driver-2", 12.98, 77.60)
+	env.locks.AcquireError = errRedisDown
+	env.locations.FindNearbyDriversError = locationErr
+	env.rides.AddRide(&domain.Ride{
+		ID: "ride-1", RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 12.3, DestinationLng: 76.64,
+		Status: domain.RideStatusRequested, Version: 1,
+	})
+}
+
+// newDegradedMatcher returns a matcher over env falling back to the
+// database when Redis is down if fallback is set. flags may be nil.
+func newDegradedMatcher(env *testEnv, fallback bool, flags *service.FeatureFlagService) *service.MatchingService {
+	deps := env.matchingDeps()
+	deps.DegradedFallback = fallback
+	deps.Flags = flags
+	return service.NewMatchingService(deps)
+}
+
+func matchDegraded(matcher *service.MatchingService) (*service.MatchResult, error) {
+	return matcher.Match(context.Background(), service.MatchRequest{RideID: "ride-1", Lat: 12.97, Lng: 77.59, RiderID: "rider-1"})
+}
+
+func degradedAttempt(t *testing.T, env *testEnv) *domain.MatchAttempt {
+	t.Helper()
+
+	attempts, _ := env.attempts.ListByRide(context.Background(), "ride-1")
+	if len(attempts) != 1 {
+		t.Fatalf("expected one attempt, got %d", len(attempts))
+	}
+	return attempts[0]
+}
+
+// driverClaims returns the driver IDs of the recorded conditional driver updates.
+func driverClaims(env *testEnv) []string {
+	var ids []string
+	for _, q := range env.rec.Queries() {
+		if strings.Contains(q.Query, "UPDATE drivers") && strings.Contains(q.Query, "AND status") {
+			ids = append(ids, fmt.Sprint(q.Args[1]))
+		}
+	}
+	return ids
+}
+
+func TestDegradedMatching_MatchesFromDatabaseWhenRedisDown(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	seedRedisDown(env, errRedisDown)
+	matcher := newDegradedMatcher(env, true, nil)
+
+	result, err := matchDegraded(matcher)
+	if err != nil {
+		t.Fatalf("expected a match without Redis, got %v", err)
+	}
+	if result.DriverID != "driver-1" || result.Ride.Status != domain.RideStatusAssigned {
+		t.Errorf("expected ride assigned to driver-1, got %s/%s", result.DriverID, result.Ride.Status)
+	}
+
+	if claims := driverClaims(env); len(claims) != 1 || claims[0] != "driver-1" {
+		t.Errorf("expected one conditional claim of driver-1, got %v", claims)
+	}
+	if got := atomic.LoadInt32(&env.locks.AcquireCallCount); got != 0 {
+		t.Errorf("expected no Redis lock attempts in degraded mode, got %d", got)
+	}
+
+	a := degradedAttempt(t, env)
+	if !a.Degraded || a.Outcome != domain.MatchOutcomeMatched || a.CandidatesFound != 2 {
+		t.Errorf("expected a degraded MATCHED attempt over 2 candidates, got %+v", a)
+	}
+}
+
+func TestDegradedMatching_IgnoresDriversOutsideRadius(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	seedRedisDown(env, errRedisDown)
+	// driver-1 last reported from Mysuru, about 130 km from the pickup;
+	// driver-3, ONLINE too, never reported a position.
+	env.drivers.SetLastPosition("driver-1", 12.30, 76.64)
+	env.drivers.AddDriver(&domain.Driver{ID: "driver-3", Status: domain.DriverStatusOnline, Tier: domain.DriverTierBasic})
+	matcher := newDegradedMatcher(env, true, nil)
+
+	result, err := matchDegraded(matcher)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.DriverID != "driver-2" {
+		t.Errorf("expected the driver within the radius assigned, got %s", result.DriverID)
+	}
+	if claims := driverClaims(env); len(claims) != 1 || claims[0] != "driver-2" {
+		t.Errorf("expected only driver-2 claimed, got %v", claims)
+	}
+	if a := degradedAttempt(t, env); a.CandidatesFound != 1 {
+		t.Errorf("expected one candidate within the radius, got %+v", a)
+	}
+}
+
+func TestDegradedMatching_NoDriverWithinRadius(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	seedRedisDown(env, errRedisDown)
+	env.drivers.SetLastPosition("driver-1", 12.30, 76.64)
+	env.drivers.SetLastPosition("driver-2", 13.20, 77.70)
+	matcher := newDegradedMatcher(env, true, nil)
+
+	if _, err := matchDegraded(matcher); !errors.Is(err, service.ErrNoDriverAvailable) {
+		t.Fatalf("expected no driver available, got %v", err)
+	}
+	if claims := driverClaims(env); len(claims) != 0 {
+		t.Errorf("expected no far-away driver claimed, got %v", claims)
+	}
+}
+
+func TestDegradedMatching_SkipsDriverClaimedConcurrently(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	seedRedisDown(env, errRedisDown)
+	matcher := newDegradedMatcher(env, true, nil)
+
+	// driver-1 was taken by another match between the read and the claim.
+	var claims int32
+	env.rec.RowsAffected = func(query string) int64 {
+		if strings.Contains(query, "UPDATE drivers") && atomic.AddInt32(&claims, 1) == 1 {
+			return 0
+		}
+		return 1
+	}
+
+	result, err := matchDegraded(matcher)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.DriverID != "driver-2" {
+		t.Errorf("expected the next driver assigned, got %s", result.DriverID)
+	}
+	if a := degradedAttempt(t, env); a.SkippedLocked != 1 {
+		t.Errorf("expected the claimed driver counted as locked, got %+v", a)
+	}
+}
+
+func TestDegradedMatching_DisabledFallbackFails(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	seedRedisDown(env, errRedisDown)
+	matcher := newDegradedMatcher(env, false, nil)
+
+	if _, err := matchDegraded(matcher); !errors.Is(err, syscall.ECONNREFUSED) {
+		t.Errorf("expected the Redis error without the fallback, got %v", err)
+	}
+	if a := degradedAttempt(t, env); a.Degraded || a.Outcome != domain.MatchOutcomeFailed {
+		t.Errorf("expected a failed, non-degraded attempt, got %+v", a)
+	}
+}
+
+func TestDegradedMatching_CommandErrorsDoNotDegrade(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	seedRedisDown(env, errors.New("WRONGTYPE Operation against a key holding the wrong kind of value"))
+	matcher := newDegradedMatcher(env, true, nil)
+
+	if _, err := matchDegraded(matcher); err == nil || !strings.Contains(err.Error(), "WRONGTYPE") {
+		t.Errorf("expected the command error, got %v", err)
+	}
+	if claims := driverClaims(env); len(claims) != 0 {
+		t.Errorf("expected no database matching, got claims %v", claims)
+	}
+}
+
+func TestDegradedMatching_SurgeFailsOpen(t *testing.T) {
+	t.Parallel()
+
+	rides := NewMockRideRepository()
+	for i := 0; i < 30; i++ {
+		rides.AddRide(&domain.Ride{ID: fmt.Sprintf("ride-%d", i), PickupLat: 12.97, PickupLng: 77.59, Status: domain.RideStatusRequested})
+	}
+	locations := NewMockLocationStore()
+	locations.FindNearbyDriversError = errRedisDown
+
+	if got := service.NewSurgeService(locations, rides, nil, 0, 0, nil, nil).GetMultiplier(context.Background(), 12.97, 77.59); got != 1.0 {
+		t.Errorf("expected surge 1.0 without Redis, got %.2f", got)
+	}
+}
+
+func TestRedisIsUnavailable(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errRedisDown, true},
+		{fmt.Errorf("find drivers: %w", io.EOF), true},
+		{errors.New("redis: connection pool timeout"), true},
+		{errors.New("WRONGTYPE Operation against a key holding the wrong kind of value"), false},
+	}
+
+	for _, tt := range tests {
+		if got := redis.IsUnavailable(tt.err); got != tt.want {
+			t.Errorf("IsUnavailable(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/redis"
+	"ride/internal/repository"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// DRIVER DESTINATION MODE
+// ──────────────────────────────────────────────
+
+// newDestinationMatcher places ONLINE drivers "near" and "far" close to
+// ride-1's pickup, with the ride heading due north, and returns a matcher
+// that reads driver destinations from destinations.
+func newDestinationMatcher(env *testEnv, destinations *MockDestinationStore) *service.MatchingService {
+	env.rides.AddRide(&domain.Ride{
+		ID: "ride-1", RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 13.10, DestinationLng: 77.59,
+		Status: domain.RideStatusRequested, Version: 1,
+	})
+	for _, id := range []string{"near", "far"} {
+		env.drivers.AddDriver(&domain.Driver{ID: id, Status: domain.DriverStatusOnline, Tier: domain.DriverTierBasic})
+	}
+	env.locations.SetLocations([]redis.DriverLocation{
+		{DriverID: "near", Lat: 12.971, Lng: 77.59},
+		{DriverID: "far", Lat: 12.975, Lng: 77.59},
+	})
+
+	deps := env.matchingDeps()
+	deps.DestinationStore = destinations
+	deps.DestinationAngleDeg = 45
+	return service.NewMatchingService(deps)
+}
+
+func setHeading(destinations *MockDestinationStore, driverID string, lat, lng float64, expiresIn time.Duration) {
+	_ = destinations.SetDestination(context.Background(), driverID, redis.DriverDestination{
+		Lat: lat, Lng: lng, ExpiresAt: time.Now().Add(expiresIn),
+	})
+}
+
+func matchHeadingNorth(t *testing.T, matcher *service.MatchingService) string {
+	t.Helper()
+
+	result, err := matcher.Match(context.Background(), service.MatchRequest{RideID: "ride-1", Lat: 12.97, Lng: 77.59, RadiusKm: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return result.DriverID
+}
+
+func TestDestinationMode_AlignedRideOffered(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	destinations := NewMockDestinationStore()
+	matcher := newDestinationMatcher(env, destinations)
+	setHeading(destinations, "near", 13.2, 77.62, time.Hour) // North, slightly east
+
+	if got := matchHeadingNorth(t, matcher); got != "near" {
+		t.Fatalf("expected the driver heading the ride's way matched, got %s", got)
+	}
+
+	// Matched, so the driver is out of destination mode.
+	if d, _ := destinations.GetDestination(context.Background(), "near"); d != nil {
+		t.Errorf("expected the destination cleared after the match, got %+v", d)
+	}
+}
+
+func TestDestinationMode_OppositeRideSkipped(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	destinations := NewMockDestinationStore()
+	matcher := newDestinationMatcher(env, destinations)
+	setHeading(destinations, "near", 12.8, 77.59, time.Hour) // Due south
+
+	if got := matchHeadingNorth(t, matcher); got != "far" {
+		t.Fatalf("expected the driver heading away skipped for far, got %s", got)
+	}
+
+	attempts, _ := env.attempts.ListByRide(context.Background(), "ride-1")
+	if len(attempts) != 1 || attempts[0].SkippedDirection != 1 {
+		t.Errorf("expected 1 direction skip recorded, got %+v", attempts)
+	}
+	if d, _ := destinations.GetDestination(context.Background(), "near"); d == nil {
+		t.Error("expected the skipped driver to stay in destination mode")
+	}
+}
+
+func TestDestinationMode_ExpiredDestinationIgnored(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	destinations := NewMockDestinationStore()
+	matcher := newDestinationMatcher(env, destinations)
+	setHeading(destinations, "near", 12.8, 77.59, -time.Minute)
+
+	if got := matchHeadingNorth(t, matcher); got != "near" {
+		t.Errorf("expected the expired destination ignored, got %s", got)
+	}
+}
+
+func TestDestinationMode_ReadErrorDoesNotBlockMatch(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	destinations := NewMockDestinationStore()
+	matcher := newDestinationMatcher(env, destinations)
+	setHeading(destinations, "near", 12.8, 77.59, time.Hour)
+	destinations.GetError = errors.New("redis down")
+
+	if got := matchHeadingNorth(t, matcher); got != "near" {
+		t.Errorf("expected the nearest driver matched when destinations are unreadable, got %s", got)
+	}
+}
+
+func TestDestinationMode_SetAndClear(t *testing.T) {
+	t.Parallel()
+
+	drivers, destinations := NewMockDriverRepository(), NewMockDestinationStore()
+	drivers.AddDriver(&domain.Driver{ID: "driver-1", Status: domain.DriverStatusOnline})
+	driverService := service.NewDriverService(NewMockLocationStore(), nil, drivers, nil, nil, nil, destinations, time.Hour, nil)
+	ctx := context.Background()
+
+	d, err := driverService.SetDestination(ctx, "driver-1", 13.2, 77.6)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d.Lat != 13.2 || d.Lng != 77.6 || time.Until(d.ExpiresAt) < 59*time.Minute {
+		t.Errorf("expected the destination to expire in an hour, got %+v", d)
+	}
+	if stored, _ := destinations.GetDestination(ctx, "driver-1"); stored == nil {
+		t.Fatal("expected the destination stored")
+	}
+
+	if err := driverService.ClearDestination(ctx, "driver-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stored, _ := destinations.GetDestination(ctx, "driver-1"); stored != nil {
+		t.Errorf("expected the destination cleared, got %+v", stored)
+	}
+
+	if _, err := driverService.SetDestination(ctx, "driver-1", 95, 77.6); !errors.Is(err, service.ErrInvalidLocation) {
+		t.Errorf("expected ErrInvalidLocation, got %v", err)
+	}
+	if _, err := driverService.SetDestination(ctx, "ghost", 13.2, 77.6); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for an unknown driver, got %v", err)
+	}
+
+	disabled := service.NewDriverService(NewMockLocationStore(), nil, drivers, nil, nil, nil, nil, 0, nil)
+	if _, err := disabled.SetDestination(ctx, "driver-1", 13.2, 77.6); !errors.Is(err, service.ErrDestinationModeUnavailable) {
+		t.Errorf("expected ErrDestinationModeUnavailable, got %v", err)
+	}
+}
@@ -0,0 +1,214 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// DOMAIN STATE MACHINES & INVARIANTS
+// ──────────────────────────────────────────────
+
+var allRideStatuses = []domain.RideStatus{
+	domain.RideStatusRequested,
+	domain.RideStatusAssigned,
+	domain.RideStatusInTrip,
+	domain.RideStatusCompleted,
+	domain.RideStatusCancelled,
+}
+
+var allTripStatuses = []domain.TripStatus{
+	domain.TripStatusStarted,
+	domain.TripStatusPaused,
+	domain.TripStatusEnded,
+	domain.TripStatusAborted,
+}
+
+func TestRide_CanTransitionTo_AllPairs(t *testing.T) {
+	t.Parallel()
+
+	allowed := map[domain.RideStatus]map[domain.RideStatus]bool{
+		domain.RideStatusRequested: {domain.RideStatusAssigned: true, domain.RideStatusCancelled: true},
+		domain.RideStatusAssigned:  {domain.RideStatusInTrip: true, domain.RideStatusCancelled: true},
+		domain.RideStatusInTrip:    {domain.RideStatusCompleted: true, domain.RideStatusCancelled: true},
+		domain.RideStatusCompleted: {},
+		domain.RideStatusCancelled: {},
+	}
+
+	for _, from := range allRideStatuses {
+		for _, to := range allRideStatuses {
+			ride := &domain.Ride{Status: from}
+			want := allowed[from][to]
+			if got := ride.CanTransitionTo(to); got != want {
+				t.Errorf("ride %s -> %s: expected %v, got %v", from, to, want, got)
+			}
+		}
+	}
+
+	unknown := &domain.Ride{Status: "UNKNOWN"}
+	for _, to := range allRideStatuses {
+		if unknown.CanTransitionTo(to) {
+			t.Errorf("unknown status should not transition to %s", to)
+		}
+	}
+}
+
+func TestTrip_CanTransitionTo_AllPairs(t *testing.T) {
+	t.Parallel()
+
+	allowed := map[domain.TripStatus]map[domain.TripStatus]bool{
+		domain.TripStatusStarted: {domain.TripStatusPaused: true, domain.TripStatusEnded: true, domain.TripStatusAborted: true},
+		domain.TripStatusPaused:  {domain.TripStatusStarted: true, domain.TripStatusEnded: true, domain.TripStatusAborted: true},
+		domain.TripStatusEnded:   {},
+		domain.TripStatusAborted: {},
+	}
+
+	for _, from := range allTripStatuses {
+		for _, to := range allTripStatuses {
+			trip := &domain.Trip{Status: from}
+			want := allowed[from][to]
+			if got := trip.CanTransitionTo(to); got != want {
+				t.Errorf("trip %s -> %s: expected %v, got %v", from, to, want, got)
+			}
+		}
+	}
+}
+
+func TestRide_Validate(t *testing.T) {
+	t.Parallel()
+
+	valid := func() *domain.Ride {
+		return &domain.Ride{
+			ID:             "ride-1",
+			RiderID:        "rider-1",
+			PickupLat:      12.9716,
+			PickupLng:      77.5946,
+			DestinationLat: 12.2958,
+			DestinationLng: 76.6394,
+			Status:         domain.RideStatusRequested,
+		}
+	}
+
+	testCases := []struct {
+		name    string
+		mutate  func(r *domain.Ride)
+		wantErr error
+	}{
+		{"valid", func(r *domain.Ride) {}, nil},
+		{"missing rider", func(r *domain.Ride) { r.RiderID = "" }, service.ErrInvalidRiderID},
+		{"bad pickup lat", func(r *domain.Ride) { r.PickupLat = 91 }, service.ErrInvalidPickupLocation},
+		{"bad pickup lng", func(r *domain.Ride) { r.PickupLng = -181 }, service.ErrInvalidPickupLocation},
+		{"bad destination lat", func(r *domain.Ride) { r.DestinationLat = -91 }, service.ErrInvalidDestinationLocation},
+		{"bad destination lng", func(r *domain.Ride) { r.DestinationLng = 181 }, service.ErrInvalidDestinationLocation},
+		{"unknown status", func(r *domain.Ride) { r.Status = "FLYING" }, domain.ErrInvalidRideStatus},
+		{"surge below one", func(r *domain.Ride) { r.SurgeMultiplier = 0.5 }, domain.ErrInvalidSurgeMultiplier},
+		{"assigned without driver", func(r *domain.Ride) { r.Status = domain.RideStatusAssigned }, domain.ErrInvalidDriverID},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			r := valid()
+			tc.mutate(r)
+			if err := r.Validate(); !errors.Is(err, tc.wantErr) {
+				t.Errorf("expected %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestTrip_Validate(t *testing.T) {
+	t.Parallel()
+
+	start := time.Now().Add(-30 * time.Minute)
+	valid := func() *domain.Trip {
+		return &domain.Trip{
+			ID:        "trip-1",
+			RideID:    "ride-1",
+			DriverID:  "driver-1",
+			Status:    domain.TripStatusStarted,
+			StartedAt: start,
+		}
+	}
+
+	testCases := []struct {
+		name    string
+		mutate  func(tr *domain.Trip)
+		wantErr error
+	}{
+		{"valid", func(tr *domain.Trip) {}, nil},
+		{"missing ride", func(tr *domain.Trip) { tr.RideID = "" }, domain.ErrInvalidRideID},
+		{"missing driver", func(tr *domain.Trip) { tr.DriverID = "" }, domain.ErrInvalidDriverID},
+		{"unknown status", func(tr *domain.Trip) { tr.Status = "DONE" }, domain.ErrInvalidTripStatus},
+		{"negative fare", func(tr *domain.Trip) { tr.Fare = -1 }, domain.ErrInvalidFare},
+		{"no start time", func(tr *domain.Trip) { tr.StartedAt = time.Time{} }, domain.ErrInvalidTripTimestamps},
+		{"ended before started", func(tr *domain.Trip) {
+			tr.Status = domain.TripStatusEnded
+			tr.EndedAt = start.Add(-time.Minute)
+		}, domain.ErrInvalidTripTimestamps},
+		{"ended without end time", func(tr *domain.Trip) { tr.Status = domain.TripStatusEnded }, domain.ErrInvalidTripTimestamps},
+		{"paused without pause time", func(tr *domain.Trip) { tr.Status = domain.TripStatusPaused }, domain.ErrInvalidTripTimestamps},
+		{"ended after started", func(tr *domain.Trip) {
+			tr.Status = domain.TripStatusEnded
+			tr.EndedAt = start.Add(time.Minute)
+		}, nil},
+		{"aborted without end time", func(tr *domain.Trip) {
+			tr.Status = domain.TripStatusAborted
+			tr.AbortedBy = domain.AbortPartyRider
+		}, domain.ErrInvalidTripTimestamps},
+		{"aborted without party", func(tr *domain.Trip) {
+			tr.Status = domain.TripStatusAborted
+			tr.EndedAt = start.Add(time.Minute)
+		}, domain.ErrInvalidAbortParty},
+		{"aborted by driver", func(tr *domain.Trip) {
+			tr.Status = domain.TripStatusAborted
+			tr.EndedAt = start.Add(time.Minute)
+			tr.AbortedBy = domain.AbortPartyDriver
+		}, nil},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			tr := valid()
+			tc.mutate(tr)
+			if err := tr.Validate(); !errors.Is(err, tc.wantErr) {
+				t.Errorf("expected %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCancelRide_RejectedTransitionsReturnSentinelErrors(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		status  domain.RideStatus
+		wantErr error
+	}{
+		{domain.RideStatusCancelled, service.ErrRideAlreadyCancelled},
+		{domain.RideStatusInTrip, service.ErrRideCannotBeCancelled},
+		{domain.RideStatusCompleted, service.ErrRideCannotBeCancelled},
+	}
+
+	for _, tc := range testCases {
+		rideRepo := NewMockRideRepository()
+		rideRepo.AddRide(&domain.Ride{
+			ID:               "ride-1",
+			RiderID:          "rider-1",
+			Status:           tc.status,
+			AssignedDriverID: "driver-1",
+		})
+		rideService := service.NewRideService(service.RideServiceDeps{RideRepo: rideRepo, MatchingService: NewMockMatchingServiceForTest()})
+
+		_, err := rideService.CancelRide(context.Background(), service.CancelRideRequest{RideID: "ride-1", CancelledBy: "rider-1"})
+		if !errors.Is(err, tc.wantErr) {
+			t.Errorf("status %s: expected %v, got %v", tc.status, tc.wantErr, err)
+		}
+	}
+}
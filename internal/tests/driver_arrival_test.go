@@ -0,0 +1,154 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"ride/internal/domain"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// DRIVER ARRIVAL DETECTION
+// ──────────────────────────────────────────────
+
+// Points relative to the pickup at (12.97, 77.59).
+var (
+	atPickup      = [2]float64{12.9703, 77.59} // About 33 m away
+	nearPickup    = [2]float64{12.9704, 77.59} // About 44 m away
+	besidePickup  = [2]float64{12.9710, 77.59} // About 110 m away
+	farFromPickup = [2]float64{12.9900, 77.59} // About 2.2 km away
+)
+
+// newArrivalDriverService assigns ride-1, picking up at (12.97, 77.59), to
+// driver-1 and returns a driver service marking arrival after 2 pings within
+// 75 m.
+func newArrivalDriverService(env *testEnv, status domain.RideStatus) *service.DriverService {
+	env.rides.AddRide(&domain.Ride{
+		ID: "ride-1", RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 13.2, DestinationLng: 77.7,
+		Status: status, AssignedDriverID: "driver-1", SurgeMultiplier: 1, Version: 1,
+	})
+	env.drivers.AddDriver(&domain.Driver{ID: "driver-1", Status: domain.DriverStatusOnTrip, Tier: domain.DriverTierBasic})
+
+	deps := env.tripDeps()
+	deps.ArrivalStore = NewMockArrivalStore()
+	deps.ArrivalRadiusKm = 0.075
+	deps.ArrivalPings = 2
+	tripService := service.NewTripService(deps)
+	return service.NewDriverService(env.locations, nil, env.drivers, nil, nil, tripService, nil, 0, nil)
+}
+
+// drive sends each point of the track as a driver location update.
+func drive(t *testing.T, driverService *service.DriverService, track ...[2]float64) {
+	t.Helper()
+
+	for _, p := range track {
+		if err := driverService.UpdateLocation(context.Background(), service.UpdateLocationRequest{
+			DriverID: "driver-1", Lat: p[0], Lng: p[1],
+		}); err != nil {
+			t.Fatalf("location update failed: %v", err)
+		}
+	}
+}
+
+func arrivalRide(env *testEnv) *domain.Ride {
+	ride, _ := env.rides.GetByID(context.Background(), "ride-1")
+	return ride
+}
+
+func arrivalNotifications(env *testEnv) int {
+	events, _ := env.notifications.ListSince(context.Background(), "rider-1", 0, 100)
+	count := 0
+	for _, e := range events {
+		if e.Type == string(service.NotificationDriverArrived) {
+			count++
+		}
+	}
+	return count
+}
+
+func TestDriverArrival_MarkedOnceAfterConsecutivePings(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	driverService := newArrivalDriverService(env, domain.RideStatusAssigned)
+
+	drive(t, driverService, farFromPickup, atPickup)
+	if !arrivalRide(env).ArrivedAt.IsZero() {
+		t.Fatal("expected a single in-fence ping not to mark arrival")
+	}
+
+	drive(t, driverService, nearPickup)
+	ride := arrivalRide(env)
+	if ride.ArrivedAt.IsZero() {
+		t.Fatal("expected arrival marked on the second in-fence ping")
+	}
+	if ride.Status != domain.RideStatusAssigned {
+		t.Errorf("expected the ride to stay ASSIGNED, got %s", ride.Status)
+	}
+	if got := arrivalNotifications(env); got != 1 {
+		t.Errorf("expected 1 arrival notification, got %d", got)
+	}
+
+	// Waiting at the pickup does not announce the driver again.
+	arrivedAt := ride.ArrivedAt
+	drive(t, driverService, atPickup, nearPickup, atPickup)
+	if got := arrivalNotifications(env); got != 1 {
+		t.Errorf("expected still 1 arrival notification, got %d", got)
+	}
+	if !arrivalRide(env).ArrivedAt.Equal(arrivedAt) {
+		t.Errorf("expected arrival time kept at %v, got %v", arrivedAt, arrivalRide(env).ArrivedAt)
+	}
+}
+
+func TestDriverArrival_JitterAtFenceEdgeNotMarked(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	driverService := newArrivalDriverService(env, domain.RideStatusAssigned)
+
+	// GPS jitter around the fence edge never gives two in-fence pings in a row.
+	drive(t, driverService, atPickup, besidePickup, nearPickup, besidePickup, atPickup, besidePickup)
+	if !arrivalRide(env).ArrivedAt.IsZero() || arrivalNotifications(env) != 0 {
+		t.Fatal("expected no arrival while the driver keeps leaving the fence")
+	}
+
+	drive(t, driverService, atPickup, nearPickup)
+	if arrivalRide(env).ArrivedAt.IsZero() || arrivalNotifications(env) != 1 {
+		t.Error("expected arrival once the driver settles inside the fence")
+	}
+}
+
+func TestDriverArrival_ReenteringFenceDoesNotRefire(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	driverService := newArrivalDriverService(env, domain.RideStatusAssigned)
+
+	drive(t, driverService, atPickup, nearPickup)
+	arrivedAt := arrivalRide(env).ArrivedAt
+	if arrivedAt.IsZero() {
+		t.Fatal("expected arrival marked")
+	}
+
+	// The driver circles the block and comes back.
+	drive(t, driverService, besidePickup, farFromPickup, atPickup, nearPickup, atPickup)
+	if got := arrivalNotifications(env); got != 1 {
+		t.Errorf("expected 1 arrival notification, got %d", got)
+	}
+	if !arrivalRide(env).ArrivedAt.Equal(arrivedAt) {
+		t.Errorf("expected arrival time kept at %v, got %v", arrivedAt, arrivalRide(env).ArrivedAt)
+	}
+}
+
+func TestDriverArrival_StartedTripNotMarked(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	driverService := newArrivalDriverService(env, domain.RideStatusInTrip)
+
+	drive(t, driverService, atPickup, nearPickup, atPickup)
+	if !arrivalRide(env).ArrivedAt.IsZero() || arrivalNotifications(env) != 0 {
+		t.Error("expected no arrival for a ride whose trip has started")
+	}
+}
@@ -0,0 +1,137 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/middleware"
+	"ride/internal/redis"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// DRIVER BREAKS
+// ──────────────────────────────────────────────
+
+// newDriverBreakRouter has driver-1 ONLINE at ride-1's pickup and serves
+// its break, resume and location updates.
+func newDriverBreakRouter(env *testEnv) *gin.Engine {
+	env.drivers.AddDriver(&domain.Driver{ID: "driver-1", Status: domain.DriverStatusOnline, Tier: domain.DriverTierBasic})
+	env.locations.AddDriverLocation(redis.DriverLocation{DriverID: "driver-1", Lat: 12.97, Lng: 77.59})
+	env.rides.AddRide(&domain.Ride{ID: "ride-1", RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, Status: domain.RideStatusRequested, Version: 1})
+
+	driverHandler := handler.NewDriverHandler(env.driverService(), nil, env.drivers, "", nil, nil)
+	router := newTestRouter()
+	router.Use(middleware.IdentityMiddleware(""))
+	router.POST("/v1/drivers/:id/break", driverHandler.StartBreak)
+	router.POST("/v1/drivers/:id/resume", driverHandler.EndBreak)
+	router.POST("/v1/drivers/:id/location", driverHandler.UpdateLocation)
+	return router
+}
+
+func driverStatus(t *testing.T, env *testEnv) domain.DriverStatus {
+	t.Helper()
+
+	driver, err := env.drivers.GetByID(context.Background(), "driver-1")
+	if err != nil {
+		t.Fatalf("failed to load driver-1: %v", err)
+	}
+	return driver.Status
+}
+
+func matchAtPickup(matcher *service.MatchingService) error {
+	_, err := matcher.Match(context.Background(), service.MatchRequest{RideID: "ride-1", Lat: 12.97, Lng: 77.59})
+	return err
+}
+
+func TestDriverBreak_NoOffersWhileOnBreak(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	router := newDriverBreakRouter(env)
+	matcher := service.NewMatchingService(env.matchingDeps())
+
+	w := post(router, "/v1/drivers/driver-1/break", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp handler.DriverResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Status != string(domain.DriverStatusBreak) {
+		t.Errorf("expected BREAK in the response, got %q", resp.Status)
+	}
+
+	if err := matchAtPickup(matcher); !errors.Is(err, service.ErrNoDriverAvailable) {
+		t.Errorf("expected no offer during a break, got %v", err)
+	}
+	// Still on the map.
+	if loc, _ := env.locations.GetLocation(context.Background(), "driver-1"); loc == nil {
+		t.Error("expected the driver's location kept during a break")
+	}
+
+	if w := post(router, "/v1/drivers/driver-1/resume", ""); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 on resume, got %d: %s", w.Code, w.Body.String())
+	}
+	if err := matchAtPickup(matcher); err != nil {
+		t.Errorf("expected an offer after resuming, got %v", err)
+	}
+}
+
+func TestDriverBreak_LocationUpdateKeepsBreak(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	router := newDriverBreakRouter(env)
+	post(router, "/v1/drivers/driver-1/break", "")
+
+	if w := postAs(router, "/v1/drivers/driver-1/location", `{"lat":12.98,"lng":77.6}`, "driver-1"); w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", w.Code, w.Body.String())
+	}
+	if got := driverStatus(t, env); got != domain.DriverStatusBreak {
+		t.Errorf("expected the driver still on break, got %s", got)
+	}
+	if loc, _ := env.locations.GetLocation(context.Background(), "driver-1"); loc == nil || loc.Lat != 12.98 {
+		t.Errorf("expected the location updated during the break, got %+v", loc)
+	}
+
+	// An offline driver still comes online by sending a location.
+	_ = env.drivers.UpdateStatus(context.Background(), "driver-1", domain.DriverStatusOffline)
+	postAs(router, "/v1/drivers/driver-1/location", `{"lat":12.98,"lng":77.6}`, "driver-1")
+	if got := driverStatus(t, env); got != domain.DriverStatusOnline {
+		t.Errorf("expected an offline driver set ONLINE, got %s", got)
+	}
+}
+
+func TestDriverBreak_Transitions(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	router := newDriverBreakRouter(env)
+
+	// Both calls are idempotent.
+	if w := post(router, "/v1/drivers/driver-1/resume", ""); w.Code != http.StatusOK {
+		t.Errorf("expected 200 resuming an ONLINE driver, got %d", w.Code)
+	}
+	post(router, "/v1/drivers/driver-1/break", "")
+	if w := post(router, "/v1/drivers/driver-1/break", ""); w.Code != http.StatusOK {
+		t.Errorf("expected 200 starting a break twice, got %d", w.Code)
+	}
+
+	_ = env.drivers.UpdateStatus(context.Background(), "driver-1", domain.DriverStatusOnTrip)
+	if w := post(router, "/v1/drivers/driver-1/break", ""); w.Code != http.StatusConflict {
+		t.Errorf("expected 409 starting a break on a trip, got %d", w.Code)
+	}
+	if w := post(router, "/v1/drivers/driver-1/resume", ""); w.Code != http.StatusConflict {
+		t.Errorf("expected 409 resuming a driver on a trip, got %d", w.Code)
+	}
+	if w := post(router, "/v1/drivers/driver-unknown/break", ""); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown driver, got %d", w.Code)
+	}
+}
@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/middleware"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// DRIVER CURRENT RIDE
+// ──────────────────────────────────────────────
+
+func newDriverCurrentRouter(rides *MockRideRepository, trips *MockTripRepository) *gin.Engine {
+	tripService := service.NewTripService(service.TripServiceDeps{TripRepo: trips, RideRepo: rides, DriverRepo: NewMockDriverRepository()})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.IdentityMiddleware(testAdminToken))
+	router.GET("/v1/drivers/:id/current", handler.NewDriverHandler(nil, tripService, nil, "US", nil, nil).GetCurrent)
+	return router
+}
+
+// getDriverCurrent fetches driverID's current work as that driver.
+func getDriverCurrent(t *testing.T, router *gin.Engine, driverID string) handler.DriverCurrentResponse {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/drivers/"+driverID+"/current", nil)
+	req.Header.Set("X-User-ID", driverID)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp handler.DriverCurrentResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	}
+	return resp
+}
+
+func TestDriverCurrent_AssignedRideIsPendingOffer(t *testing.T) {
+	t.Parallel()
+
+	rides := NewMockRideRepository()
+	rides.AddRide(&domain.Ride{
+		ID: "ride-1", RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 13.0, DestinationLng: 77.6,
+		Status: domain.RideStatusAssigned, AssignedDriverID: "driver-1", PaymentMethod: domain.PaymentMethodCash,
+		AssignedAt: time.Now().Add(-time.Minute),
+	})
+	router := newDriverCurrentRouter(rides, NewMockTripRepository())
+
+	resp := getDriverCurrent(t, router, "driver-1")
+	if resp.Trip != nil {
+		t.Errorf("expected no active trip before the driver accepts, got %+v", resp.Trip)
+	}
+	offer := resp.PendingOffer
+	if offer == nil || offer.RideID != "ride-1" || offer.PickupLat != 12.97 || offer.PickupLng != 77.59 || offer.AssignedAt == "" {
+		t.Fatalf("expected ride-1 as a pending offer with its pickup, got %+v", offer)
+	}
+}
+
+func TestDriverCurrent_StartedTripIsNotPending(t *testing.T) {
+	t.Parallel()
+
+	rides := NewMockRideRepository()
+	rides.AddRide(&domain.Ride{
+		ID: "ride-1", RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 13.0, DestinationLng: 77.6,
+		Status: domain.RideStatusInTrip, AssignedDriverID: "driver-1",
+	})
+	trips := NewMockTripRepository()
+	_ = trips.Create(context.Background(), &domain.Trip{
+		ID: "trip-1", RideID: "ride-1", DriverID: "driver-1", Status: domain.TripStatusStarted, StartedAt: time.Now(),
+	})
+	router := newDriverCurrentRouter(rides, trips)
+
+	resp := getDriverCurrent(t, router, "driver-1")
+	if resp.Trip == nil || resp.Trip.TripID != "trip-1" || resp.PendingOffer != nil {
+		t.Errorf("expected trip-1 active and no pending offer, got %+v", resp)
+	}
+
+	if idle := getDriverCurrent(t, router, "driver-2"); idle.Trip != nil || idle.PendingOffer != nil {
+		t.Errorf("expected nothing for an idle driver, got %+v", idle)
+	}
+}
+
+func TestDriverCurrent_OtherDriversCannotSeeOffer(t *testing.T) {
+	t.Parallel()
+
+	rides := NewMockRideRepository()
+	rides.AddRide(&domain.Ride{
+		ID: "ride-1", RiderID: "rider-1", Status: domain.RideStatusAssigned, AssignedDriverID: "driver-1",
+	})
+	router := newDriverCurrentRouter(rides, NewMockTripRepository())
+
+	if code := readAs(router, "/v1/drivers/driver-1/current", "driver-2", false); code != http.StatusNotFound {
+		t.Errorf("expected 404 for another driver, got %d", code)
+	}
+	if code := readAs(router, "/v1/drivers/driver-1/current", "", true); code != http.StatusOK {
+		t.Errorf("expected admins to see any driver, got %d", code)
+	}
+}
@@ -0,0 +1,274 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/middleware"
+	"ride/internal/repository"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// DRIVER TRIP EARNINGS
+// ──────────────────────────────────────────────
+
+// newEarningsService returns an EarningsService over env taking a 20%
+// commission and crediting drivers in ledger.
+func newEarningsService(env *testEnv, ledger *MockEarningsRepository) *service.EarningsService {
+	return service.NewEarningsService(env.trips, env.rides, ledger, env.paymentService(), 20)
+}
+
+// addEarningsTrip stores driver-1's trip on ride with the given fare and
+// status.
+func addEarningsTrip(env *testEnv, ride *domain.Ride, tripID string, fare float64, status domain.TripStatus) {
+	ride.RiderID, ride.AssignedDriverID = "rider-1", "driver-1"
+	env.rides.AddRide(ride)
+	_ = env.trips.Create(context.Background(), &domain.Trip{
+		ID: tripID, RideID: ride.ID, DriverID: "driver-1", Status: status, Fare: fare,
+		StartedAt: time.Now().Add(-20 * time.Minute), EndedAt: time.Now(), Version: 1,
+	})
+}
+
+// tipTrip has rider-1 tip amount on a trip, failing the test on error.
+func tipTrip(t *testing.T, earningsService *service.EarningsService, tripID string, amount float64) *domain.Payment {
+	t.Helper()
+
+	payment, err := earningsService.TipTrip(context.Background(), "rider-1", tripID, amount)
+	if err != nil {
+		t.Fatalf("unexpected error tipping %s: %v", tripID, err)
+	}
+	return payment
+}
+
+func TestTripEarnings_SurgedTippedCardTrip(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	ledger := NewMockEarningsRepository()
+	earningsService := newEarningsService(env, ledger)
+	addEarningsTrip(env, &domain.Ride{ID: "ride-1", SurgeMultiplier: 1.5, PaymentMethod: domain.PaymentMethodCard}, "trip-1", 30, domain.TripStatusEnded)
+	tipTrip(t, earningsService, "trip-1", 5)
+
+	earnings, err := earningsService.TripEarnings(context.Background(), "driver-1", "trip-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 30 at 1.5x surge is 20 base plus 10 surge; the 20% commission is 6.
+	want := domain.TripEarnings{
+		TripID: "trip-1", DriverID: "driver-1", PaymentMethod: domain.PaymentMethodCard,
+		GrossFare: 30, SurgeMultiplier: 1.5, SurgeAmount: 10, CommissionPercent: 20, Commission: 6,
+		Tip: 5, NetEarnings: 29, Payout: 29,
+	}
+	if *earnings != want {
+		t.Errorf("expected %+v, got %+v", want, *earnings)
+	}
+}
+
+func TestTripEarnings_CashTripOwesCommission(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	ledger := NewMockEarningsRepository()
+	earningsService := newEarningsService(env, ledger)
+	addEarningsTrip(env, &domain.Ride{ID: "ride-1", SurgeMultiplier: 1.0, PaymentMethod: domain.PaymentMethodCash}, "trip-1", 20, domain.TripStatusEnded)
+
+	earnings, err := earningsService.TripEarnings(context.Background(), "driver-1", "trip-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if earnings.NetEarnings != 16 {
+		t.Errorf("expected net 16, got %v", earnings.NetEarnings)
+	}
+	if earnings.CashCollected != 20 {
+		t.Errorf("expected 20 collected in cash, got %v", earnings.CashCollected)
+	}
+	if earnings.Payout != -4 {
+		t.Errorf("expected payout -4 (commission owed), got %v", earnings.Payout)
+	}
+}
+
+func TestTripEarnings_SurchargeFreeOfCommission(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	ledger := NewMockEarningsRepository()
+	earningsService := newEarningsService(env, ledger)
+	addEarningsTrip(env, &domain.Ride{ID: "ride-1", SurchargeAmount: 5, PaymentMethod: domain.PaymentMethodCard}, "trip-1", 25, domain.TripStatusEnded)
+
+	earnings, err := earningsService.TripEarnings(context.Background(), "driver-1", "trip-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if earnings.SurchargeAmount != 5 || earnings.Commission != 4 || earnings.NetEarnings != 21 {
+		t.Errorf("expected surcharge 5, commission 4, net 21, got %+v", *earnings)
+	}
+}
+
+func TestTipTrip_CreditedOnce(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	ledger := NewMockEarningsRepository()
+	earningsService := newEarningsService(env, ledger)
+	addEarningsTrip(env, &domain.Ride{ID: "ride-1", PaymentMethod: domain.PaymentMethodCard}, "trip-1", 20, domain.TripStatusEnded)
+
+	first := tipTrip(t, earningsService, "trip-1", 5)
+	if first.Status != domain.PaymentStatusSuccess || first.Amount != 5 {
+		t.Fatalf("expected a successful 5 tip, got %+v", first)
+	}
+
+	// A repeated tip returns the first one, charged and credited once.
+	if again := tipTrip(t, earningsService, "trip-1", 8); again.ID != first.ID {
+		t.Errorf("expected the repeated tip to return payment %s, got %s", first.ID, again.ID)
+	}
+	if env.psp.ChargeCallCount != 1 {
+		t.Errorf("expected 1 charge, got %d", env.psp.ChargeCallCount)
+	}
+	entries, _ := ledger.ListByDriver(context.Background(), "driver-1")
+	if len(entries) != 1 || entries[0].Kind != domain.EarningsKindTip || entries[0].Amount != 5 {
+		t.Errorf("expected one 5 tip credited to driver-1, got %+v", entries)
+	}
+}
+
+func TestTipTrip_DeclinedNotCredited(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	ledger := NewMockEarningsRepository()
+	earningsService := newEarningsService(env, ledger)
+	addEarningsTrip(env, &domain.Ride{ID: "ride-1", PaymentMethod: domain.PaymentMethodCard}, "trip-1", 20, domain.TripStatusEnded)
+
+	env.psp.SetFailure(true, nil)
+	if payment := tipTrip(t, earningsService, "trip-1", 5); payment.Status != domain.PaymentStatusFailed {
+		t.Fatalf("expected a FAILED tip, got %s", payment.Status)
+	}
+	if _, err := ledger.GetByReference(context.Background(), service.TipReference("trip-1")); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected no tip credited after a decline, got %v", err)
+	}
+
+	// Tipping again retries the charge.
+	env.psp.SetFailure(false, nil)
+	if payment := tipTrip(t, earningsService, "trip-1", 5); payment.Status != domain.PaymentStatusSuccess {
+		t.Fatalf("expected the retried tip to succeed, got %s", payment.Status)
+	}
+	if entry, err := ledger.GetByReference(context.Background(), service.TipReference("trip-1")); err != nil || entry.Amount != 5 {
+		t.Errorf("expected the 5 tip credited, got %+v, %v", entry, err)
+	}
+}
+
+func TestTipTrip_Rejections(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	earningsService := newEarningsService(env, NewMockEarningsRepository())
+	addEarningsTrip(env, &domain.Ride{ID: "ride-1", PaymentMethod: domain.PaymentMethodCard}, "trip-1", 20, domain.TripStatusEnded)
+	addEarningsTrip(env, &domain.Ride{ID: "ride-2", PaymentMethod: domain.PaymentMethodCard}, "trip-2", 0, domain.TripStatusStarted)
+	addEarningsTrip(env, &domain.Ride{ID: "ride-3", PaymentMethod: domain.PaymentMethodCash}, "trip-3", 20, domain.TripStatusEnded)
+
+	for _, tc := range []struct {
+		riderID, tripID string
+		amount          float64
+		want            error
+	}{
+		{"rider-2", "trip-1", 5, repository.ErrNotFound},
+		{"rider-1", "trip-2", 5, service.ErrTripNotEnded},
+		{"rider-1", "trip-3", 5, service.ErrInvalidPaymentMethod},
+		{"rider-1", "trip-1", 0, service.ErrInvalidPaymentAmount},
+	} {
+		if _, err := earningsService.TipTrip(context.Background(), tc.riderID, tc.tripID, tc.amount); !errors.Is(err, tc.want) {
+			t.Errorf("%s tipping %v on %s: expected %v, got %v", tc.riderID, tc.amount, tc.tripID, tc.want, err)
+		}
+	}
+	if env.psp.ChargeCallCount != 0 {
+		t.Errorf("expected no charges, got %d", env.psp.ChargeCallCount)
+	}
+}
+
+func TestTripEarnings_Rejections(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	ledger := NewMockEarningsRepository()
+	earningsService := newEarningsService(env, ledger)
+	addEarningsTrip(env, &domain.Ride{ID: "ride-1", PaymentMethod: domain.PaymentMethodCard}, "trip-1", 20, domain.TripStatusStarted)
+
+	if _, err := earningsService.TripEarnings(context.Background(), "driver-1", "trip-1"); !errors.Is(err, service.ErrTripNotEnded) {
+		t.Errorf("expected ErrTripNotEnded for a trip in progress, got %v", err)
+	}
+	if _, err := earningsService.TripEarnings(context.Background(), "driver-2", "trip-1"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for another driver's trip, got %v", err)
+	}
+}
+
+func TestTripEarnings_HTTP(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	ledger := NewMockEarningsRepository()
+	earningsService := newEarningsService(env, ledger)
+	addEarningsTrip(env, &domain.Ride{ID: "ride-1", SurgeMultiplier: 1.5, PaymentMethod: domain.PaymentMethodCard}, "trip-1", 30, domain.TripStatusEnded)
+	addEarningsTrip(env, &domain.Ride{ID: "ride-2", PaymentMethod: domain.PaymentMethodCard}, "trip-2", 0, domain.TripStatusStarted)
+
+	tripService := service.NewTripService(service.TripServiceDeps{TripRepo: env.trips, RideRepo: env.rides, DriverRepo: NewMockDriverRepository()})
+	earningsHandler := handler.NewEarningsHandler(earningsService, tripService)
+	router := newTestRouter()
+	router.Use(middleware.IdentityMiddleware(testAdminToken))
+	router.GET("/v1/drivers/:id/trips/:tripId/earnings", earningsHandler.GetTripEarnings)
+	router.POST("/v1/trips/:id/tip", earningsHandler.TipTrip)
+
+	if w := postAs(router, "/v1/trips/trip-1/tip", `{"amount": 5}`, "rider-1"); w.Code != http.StatusCreated {
+		t.Fatalf("tip: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := postAs(router, "/v1/trips/trip-1/tip", `{"amount": 5}`, "rider-2"); w.Code != http.StatusNotFound {
+		t.Errorf("another rider's tip: expected 404, got %d", w.Code)
+	}
+
+	w := getAs(router, "/v1/drivers/driver-1/trips/trip-1/earnings", "driver-1")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp handler.TripEarningsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.TripID != "trip-1" || resp.Status != string(domain.TripStatusEnded) {
+		t.Errorf("expected ended trip-1, got %+v", resp.TripResponse)
+	}
+	if resp.Earnings.NetEarnings != 29 || resp.Earnings.Tip != 5 || resp.Earnings.PaymentMethod != "CARD" {
+		t.Errorf("expected net 29 with a 5 tip on CARD, got %+v", resp.Earnings)
+	}
+
+	for _, tc := range []struct {
+		path, userID string
+		want         int
+	}{
+		{"/v1/drivers/driver-1/trips/trip-2/earnings", "driver-1", http.StatusConflict},
+		{"/v1/drivers/driver-2/trips/trip-1/earnings", "driver-2", http.StatusNotFound},
+		{"/v1/drivers/driver-1/trips/trip-1/earnings", "driver-2", http.StatusNotFound},
+		{"/v1/drivers/driver-1/trips/trip-1/earnings", "rider-1", http.StatusNotFound},
+		{"/v1/drivers/driver-1/trips/trip-1/earnings", "", http.StatusNotFound},
+	} {
+		if w := getAs(router, tc.path, tc.userID); w.Code != tc.want {
+			t.Errorf("GET %s as %q: expected %d, got %d", tc.path, tc.userID, tc.want, w.Code)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/drivers/driver-1/trips/trip-1/earnings", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("admin: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,126 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/middleware"
+	"ride/internal/redis"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// LIVE DRIVER PICKUP ETA
+// ──────────────────────────────────────────────
+
+func newETATestService(status domain.RideStatus) (*service.ETAService, *MockLocationStore, *MockRideRepository) {
+	rideRepo := NewMockRideRepository()
+	rideRepo.AddRide(&domain.Ride{
+		ID:               "ride-1",
+		RiderID:          "rider-1",
+		PickupLat:        12.9716,
+		PickupLng:        77.5946,
+		Status:           status,
+		AssignedDriverID: "driver-1",
+	})
+	locationStore := NewMockLocationStore()
+	return service.NewETAService(rideRepo, locationStore, nil, nil, 0), locationStore, rideRepo
+}
+
+func newDriverETARouter(etaService *service.ETAService, rideRepo *MockRideRepository) *gin.Engine {
+	rideService := service.NewRideService(service.RideServiceDeps{RideRepo: rideRepo, MatchingService: NewMockMatchingServiceForTest()})
+	router := newTestRouter()
+	router.Use(middleware.IdentityMiddleware(testAdminToken))
+	router.GET("/v1/rides/:id/driver-eta", handler.NewRideHandler(rideService, etaService, rideRepo, nil).GetDriverETA)
+	return router
+}
+
+func TestDriverETA_DecreasesAsDriverApproaches(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	etaService, locationStore, _ := newETATestService(domain.RideStatusAssigned)
+
+	locationStore.AddDriverLocation(redis.DriverLocation{DriverID: "driver-1", Lat: 13.0200, Lng: 77.6400})
+	far, err := etaService.DriverETA(ctx, "ride-1", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_ = locationStore.UpdateLocation(ctx, "driver-1", 12.9800, 77.6000, 0)
+	near, err := etaService.DriverETA(ctx, "ride-1", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if near.DistanceKm >= far.DistanceKm {
+		t.Errorf("expected distance to shrink, got %.2f -> %.2f km", far.DistanceKm, near.DistanceKm)
+	}
+	if near.ETASeconds >= far.ETASeconds {
+		t.Errorf("expected ETA to decrease, got %ds -> %ds", far.ETASeconds, near.ETASeconds)
+	}
+}
+
+func TestDriverETA_NotApplicableOnceTripStarted(t *testing.T) {
+	t.Parallel()
+
+	etaService, locationStore, rideRepo := newETATestService(domain.RideStatusInTrip)
+	locationStore.AddDriverLocation(redis.DriverLocation{DriverID: "driver-1", Lat: 12.9716, Lng: 77.5946})
+
+	_, err := etaService.DriverETA(context.Background(), "ride-1", false)
+	if !errors.Is(err, service.ErrPickupETANotApplicable) {
+		t.Fatalf("expected ErrPickupETANotApplicable, got %v", err)
+	}
+
+	w := getAs(newDriverETARouter(etaService, rideRepo), "/v1/rides/ride-1/driver-eta", "rider-1")
+	if w.Code != http.StatusConflict {
+		t.Errorf("expected 409, got %d", w.Code)
+	}
+}
+
+func TestDriverETA_MissingDriverLocation(t *testing.T) {
+	t.Parallel()
+
+	etaService, _, _ := newETATestService(domain.RideStatusAssigned)
+
+	_, err := etaService.DriverETA(context.Background(), "ride-1", false)
+	if !errors.Is(err, service.ErrDriverLocationUnavailable) {
+		t.Fatalf("expected ErrDriverLocationUnavailable, got %v", err)
+	}
+}
+
+func TestDriverETA_OnlyRideParticipants(t *testing.T) {
+	t.Parallel()
+
+	etaService, locationStore, rideRepo := newETATestService(domain.RideStatusAssigned)
+	locationStore.AddDriverLocation(redis.DriverLocation{DriverID: "driver-1", Lat: 12.98, Lng: 77.60})
+	router := newDriverETARouter(etaService, rideRepo)
+
+	for _, userID := range []string{"rider-1", "driver-1"} {
+		if w := getAs(router, "/v1/rides/ride-1/driver-eta", userID); w.Code != http.StatusOK {
+			t.Errorf("%s: expected 200, got %d: %s", userID, w.Code, w.Body.String())
+		}
+	}
+
+	// Anyone else learns neither where the driver is nor that the ride exists.
+	for _, userID := range []string{"", "rider-2", "driver-2"} {
+		if w := getAs(router, "/v1/rides/ride-1/driver-eta", userID); w.Code != http.StatusNotFound {
+			t.Errorf("%q: expected 404, got %d: %s", userID, w.Code, w.Body.String())
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/rides/ride-1/driver-eta", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("admin: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+}
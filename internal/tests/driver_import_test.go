@@ -0,0 +1,170 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// DRIVER IMPORT
+// ──────────────────────────────────────────────
+
+// newDriverImportRouter serves the import endpoint over drivers, which
+// already holds a driver registered with +16502530000.
+func newDriverImportRouter(t *testing.T, maxRows int) (*gin.Engine, *MockDriverRepository) {
+	t.Helper()
+
+	drivers := NewMockDriverRepository()
+	drivers.AddDriver(&domain.Driver{ID: "driver-1", Name: "Ravi", Phone: "+16502530000", Status: domain.DriverStatusOffline, Tier: domain.DriverTierBasic})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	importService := service.NewDriverImportService(nil, drivers, "US", nil, maxRows)
+	router.POST("/v1/admin/drivers/import", handler.NewDriverImportHandler(importService).Import)
+	return router, drivers
+}
+
+func postImport(router *gin.Engine, contentType string, body []byte) (*httptest.ResponseRecorder, handler.DriverImportResponse) {
+	req := httptest.NewRequest(http.MethodPost, "/v1/admin/drivers/import", bytes.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var resp handler.DriverImportResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	return w, resp
+}
+
+func TestDriverImport_MixedBatchReportsEachRow(t *testing.T) {
+	t.Parallel()
+
+	router, drivers := newDriverImportRouter(t, 0)
+	body, _ := json.Marshal([]map[string]string{
+		{"name": "Asha", "phone": "650-253-0001", "tier": "BASIC", "vehicle_plate": " ka01ab1234 "},
+		{"name": "Ravi again", "phone": "(650) 253-0000", "tier": "BASIC"},
+	})
+
+	w, resp := postImport(router, "application/json", body)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if resp.Imported != 1 || resp.Failed != 1 || len(resp.Rows) != 2 {
+		t.Fatalf("expected 1 imported and 1 failed, got %+v", resp)
+	}
+
+	imported := resp.Rows[0]
+	if imported.Row != 1 || imported.Status != "IMPORTED" || imported.DriverID == "" || imported.Error != "" {
+		t.Errorf("expected row 1 imported, got %+v", imported)
+	}
+	driver, err := drivers.GetByID(context.Background(), imported.DriverID)
+	if err != nil {
+		t.Fatalf("expected the imported driver stored: %v", err)
+	}
+	if driver.Phone != "+16502530001" || driver.VehiclePlate != "KA01AB1234" || driver.Status != domain.DriverStatusOffline {
+		t.Errorf("expected a registration-normalized OFFLINE driver, got %+v", driver)
+	}
+
+	duplicate := resp.Rows[1]
+	if duplicate.Row != 2 || duplicate.Status != "FAILED" || duplicate.DriverID != "" || duplicate.Error != service.ErrDriverAlreadyRegistered.Error() {
+		t.Errorf("expected row 2 failed as a duplicate phone, got %+v", duplicate)
+	}
+	if all, _ := drivers.GetAll(context.Background()); len(all) != 2 {
+		t.Errorf("expected 2 drivers stored, got %d", len(all))
+	}
+}
+
+func TestDriverImport_RowsValidatedLikeRegistration(t *testing.T) {
+	t.Parallel()
+
+	router, _ := newDriverImportRouter(t, 0)
+	body, _ := json.Marshal([]map[string]string{
+		{"name": "", "phone": "650-253-0002", "tier": "BASIC"},
+		{"name": "Meera", "phone": "12", "tier": "BASIC"},
+		{"name": "Kiran", "phone": "650-253-0003", "tier": "LUXURY"},
+		{"name": "Dev", "phone": "650-253-0004", "tier": "BASIC", "email": "dev@example.com"},
+		{"name": "Dev's twin", "phone": "650-253-0005", "tier": "BASIC", "email": "DEV@example.com"},
+	})
+
+	_, resp := postImport(router, "application/json", body)
+	want := []string{
+		"invalid request: name: " + service.ErrFieldRequired.Error(),
+		"invalid request: phone: " + service.ErrInvalidPhone.Error(),
+		"invalid request: tier: " + service.ErrInvalidTier.Error(),
+		"",
+		service.ErrEmailTaken.Error(),
+	}
+	if len(resp.Rows) != len(want) {
+		t.Fatalf("expected %d rows, got %+v", len(want), resp)
+	}
+	for i, row := range resp.Rows {
+		if row.Error != want[i] {
+			t.Errorf("row %d: expected error %q, got %q", row.Row, want[i], row.Error)
+		}
+	}
+	if resp.Imported != 1 || resp.Failed != 4 {
+		t.Errorf("expected 1 imported and 4 failed, got %d and %d", resp.Imported, resp.Failed)
+	}
+}
+
+func TestDriverImport_CSV(t *testing.T) {
+	t.Parallel()
+
+	csv := "Phone,Name,Tier,Email\n650-253-0006,Asha,BASIC,\n650-253-0000,Ravi,BASIC,\n"
+
+	router, _ := newDriverImportRouter(t, 0)
+	_, resp := postImport(router, "text/csv", []byte(csv))
+	if resp.Imported != 1 || resp.Failed != 1 {
+		t.Errorf("text/csv: expected 1 imported and 1 failed, got %+v", resp)
+	}
+
+	router, _ = newDriverImportRouter(t, 0)
+	var form bytes.Buffer
+	writer := multipart.NewWriter(&form)
+	part, _ := writer.CreateFormFile("file", "drivers.csv")
+	_, _ = part.Write([]byte(csv))
+	_ = writer.Close()
+	_, resp = postImport(router, writer.FormDataContentType(), form.Bytes())
+	if resp.Imported != 1 || resp.Failed != 1 {
+		t.Errorf("multipart: expected 1 imported and 1 failed, got %+v", resp)
+	}
+
+	if w, _ := postImport(router, "text/csv", []byte("first,last\nAsha,Rao\n")); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for CSV without name and phone columns, got %d", w.Code)
+	}
+}
+
+func TestDriverImport_BatchSizeCapped(t *testing.T) {
+	t.Parallel()
+
+	router, drivers := newDriverImportRouter(t, 2)
+	rows := make([]map[string]string, 3)
+	for i := range rows {
+		rows[i] = map[string]string{"name": "Driver", "phone": fmt.Sprintf("650-253-001%d", i), "tier": "BASIC"}
+	}
+	body, _ := json.Marshal(rows)
+
+	w, _ := postImport(router, "application/json", body)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "limit is 2") {
+		t.Errorf("expected 400 naming the cap, got %d: %s", w.Code, w.Body.String())
+	}
+	if all, _ := drivers.GetAll(context.Background()); len(all) != 1 {
+		t.Errorf("expected nothing imported from an oversized batch, got %d drivers", len(all))
+	}
+
+	if w, _ := postImport(router, "application/json", []byte("[]")); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an empty batch, got %d", w.Code)
+	}
+}
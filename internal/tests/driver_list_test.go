@@ -0,0 +1,204 @@
+package tests
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/repository"
+	"ride/internal/repository/postgres"
+)
+
+// ──────────────────────────────────────────────
+// DRIVER SEARCH & FILTERING
+// ──────────────────────────────────────────────
+
+type driverListBody struct {
+	Data   []handler.DriverResponse `json:"data"`
+	Total  int                      `json:"total"`
+	Limit  int                      `json:"limit"`
+	Offset int                      `json:"offset"`
+}
+
+func newDriverListRouter() *gin.Engine {
+	driverRepo := NewMockDriverRepository()
+	driverRepo.AddDriver(&domain.Driver{ID: "d1", Name: "Asha Rao", Phone: "+9198000001", Status: domain.DriverStatusOnline, Tier: domain.DriverTierPremium})
+	driverRepo.AddDriver(&domain.Driver{ID: "d2", Name: "Ravi Kumar", Phone: "+9198000002", Status: domain.DriverStatusOnline, Tier: domain.DriverTierBasic})
+	driverRepo.AddDriver(&domain.Driver{ID: "d3", Name: "Rashmi K", Phone: "+9199000003", Status: domain.DriverStatusOffline, Tier: domain.DriverTierPremium})
+	driverRepo.AddDriver(&domain.Driver{ID: "d4", Name: "Kiran", Phone: "+9198000004", Status: domain.DriverStatusOnline, Tier: domain.DriverTierPremium})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/v1/drivers", handler.NewDriverHandler(nil, nil, driverRepo, "", nil, nil).GetAll)
+	return router
+}
+
+func getDriverList(t *testing.T, router *gin.Engine, query string) (int, driverListBody) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/drivers"+query, nil))
+
+	var body driverListBody
+	if w.Code == http.StatusOK {
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("invalid json: %v", err)
+		}
+	}
+	return w.Code, body
+}
+
+func TestDriverList_CombinedFilters(t *testing.T) {
+	t.Parallel()
+
+	router := newDriverListRouter()
+
+	code, body := getDriverList(t, router, "?status=ONLINE&tier=PREMIUM&phone=%2B9198")
+	if code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if body.Total != 2 || len(body.Data) != 2 {
+		t.Fatalf("expected 2 drivers, got total=%d len=%d", body.Total, len(body.Data))
+	}
+	if body.Data[0].ID != "d1" || body.Data[1].ID != "d4" {
+		t.Errorf("expected d1 and d4, got %+v", body.Data)
+	}
+
+	code, body = getDriverList(t, router, "?name=ra&status=ONLINE")
+	if code != http.StatusOK || body.Total != 3 {
+		t.Errorf("expected 3 ONLINE drivers matching 'ra', got %d / %+v", code, body)
+	}
+}
+
+func TestDriverList_Pagination(t *testing.T) {
+	t.Parallel()
+
+	router := newDriverListRouter()
+
+	code, body := getDriverList(t, router, "?limit=2&offset=2")
+	if code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if body.Total != 4 || len(body.Data) != 2 || body.Limit != 2 || body.Offset != 2 {
+		t.Errorf("unexpected page: %+v", body)
+	}
+	if body.Data[0].ID != "d3" {
+		t.Errorf("expected page to start at d3, got %s", body.Data[0].ID)
+	}
+}
+
+func TestDriverList_InvalidParams(t *testing.T) {
+	t.Parallel()
+
+	router := newDriverListRouter()
+
+	for _, query := range []string{"?status=BUSY", "?tier=GOLD", "?limit=0", "?limit=500", "?offset=-1", "?limit=abc"} {
+		if code, _ := getDriverList(t, router, query); code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, code)
+		}
+	}
+}
+
+func TestDriverRepository_List_BindsFiltersAsParameters(t *testing.T) {
+	t.Parallel()
+
+	db, rec := NewRecordingDB()
+	defer db.Close()
+	rec.Respond = func(query string, _ []driver.Value) ([]string, [][]driver.Value) {
+		if strings.HasPrefix(query, "SELECT COUNT(*)") {
+			return []string{"count"}, [][]driver.Value{{int64(0)}}
+		}
+		return nil, nil
+	}
+
+	malicious := "'; DROP TABLE drivers; --%_"
+	_, total, err := postgres.NewDriverRepository(db).List(context.Background(), repository.DriverFilter{
+		Status:      domain.DriverStatusOnline,
+		Tier:        domain.DriverTierPremium,
+		PhonePrefix: malicious,
+		Name:        "ra",
+		Limit:       10,
+		Offset:      20,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if total != 0 {
+		t.Errorf("expected total 0, got %d", total)
+	}
+
+	queries := rec.Queries()
+	if len(queries) != 2 {
+		t.Fatalf("expected count and page queries, got %d", len(queries))
+	}
+	for _, q := range queries {
+		if strings.Contains(q.Query, "DROP") {
+			t.Errorf("user input leaked into SQL text: %s", q.Query)
+		}
+		if !strings.Contains(q.Query, "phone LIKE $3") || !strings.Contains(q.Query, "name ILIKE $4") {
+			t.Errorf("expected positional placeholders, got: %s", q.Query)
+		}
+	}
+
+	page := queries[1]
+	if !strings.Contains(page.Query, "LIMIT $5 OFFSET $6") {
+		t.Errorf("expected bound limit/offset, got: %s", page.Query)
+	}
+	// Wildcards in the prefix are escaped so they match literally.
+	if got := page.Args[2]; got != `'; DROP TABLE drivers; --\%\_%` {
+		t.Errorf("unexpected phone arg: %v", got)
+	}
+	if got := page.Args[3]; got != "%ra%" {
+		t.Errorf("unexpected name arg: %v", got)
+	}
+	if page.Args[4] != int64(10) || page.Args[5] != int64(20) {
+		t.Errorf("unexpected limit/offset args: %v", page.Args[4:])
+	}
+}
+
+func TestDriverRepository_List_NearFiltersOnLastPosition(t *testing.T) {
+	t.Parallel()
+
+	db, rec := NewRecordingDB()
+	defer db.Close()
+	rec.Respond = func(query string, _ []driver.Value) ([]string, [][]driver.Value) {
+		if strings.HasPrefix(query, "SELECT COUNT(*)") {
+			return []string{"count"}, [][]driver.Value{{int64(0)}}
+		}
+		return nil, nil
+	}
+
+	_, _, err := postgres.NewDriverRepository(db).List(context.Background(), repository.DriverFilter{
+		Status: domain.DriverStatusOnline,
+		Near:   &repository.NearFilter{Lat: 12.97, Lng: 77.59, RadiusKm: 5},
+		Limit:  3,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	queries := rec.Queries()
+	if len(queries) != 2 {
+		t.Fatalf("expected count and page queries, got %d", len(queries))
+	}
+	for _, q := range queries {
+		if !strings.Contains(q.Query, "driver_location_history") || !strings.Contains(q.Query, "<= $3") || !strings.Contains(q.Query, "status = $4") {
+			t.Errorf("expected the last position bound within the radius, got: %s", q.Query)
+		}
+	}
+
+	page := queries[1]
+	if !strings.Contains(page.Query, "ORDER BY (2 * 6371.0") || !strings.Contains(page.Query, "LIMIT $5 OFFSET $6") {
+		t.Errorf("expected nearest first with bound limit/offset, got: %s", page.Query)
+	}
+	if page.Args[0] != 12.97 || page.Args[1] != 77.59 || page.Args[2] != 5.0 {
+		t.Errorf("unexpected position args: %v", page.Args[:3])
+	}
+}
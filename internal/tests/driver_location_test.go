@@ -2,9 +2,17 @@ package tests
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/gin-gonic/gin"
+
 	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/middleware"
 	"ride/internal/service"
 )
 
@@ -27,7 +35,7 @@ func TestDriverLocationUpdate_WritesToRedisOnly(t *testing.T) {
 		Tier:   domain.DriverTierBasic,
 	})
 
-	driverService := service.NewDriverService(locationStore, nil, driverRepo)
+	driverService := service.NewDriverService(locationStore, nil, driverRepo, nil, nil, nil, nil, 0, nil)
 
 	req := service.UpdateLocationRequest{
 		DriverID: "driver-1",
@@ -128,7 +136,7 @@ func TestDriverLocationUpdate_InvalidLatitude_Rejected(t *testing.T) {
 				Status: domain.DriverStatusOffline,
 			})
 
-			driverService := service.NewDriverService(locationStore, nil, driverRepo)
+			driverService := service.NewDriverService(locationStore, nil, driverRepo, nil, nil, nil, nil, 0, nil)
 
 			req := service.UpdateLocationRequest{
 				DriverID: "driver-1",
@@ -152,7 +160,7 @@ func TestDriverLocationUpdate_MissingDriverID_Rejected(t *testing.T) {
 
 	locationStore := NewMockLocationStore()
 	driverRepo := NewMockDriverRepository()
-	driverService := service.NewDriverService(locationStore, nil, driverRepo)
+	driverService := service.NewDriverService(locationStore, nil, driverRepo, nil, nil, nil, nil, 0, nil)
 
 	req := service.UpdateLocationRequest{
 		DriverID: "", // Missing driver ID
@@ -176,7 +184,7 @@ func TestDriverLocationUpdate_HighFrequencyUpdates_NoError(t *testing.T) {
 		Status: domain.DriverStatusOffline,
 	})
 
-	driverService := service.NewDriverService(locationStore, nil, driverRepo)
+	driverService := service.NewDriverService(locationStore, nil, driverRepo, nil, nil, nil, nil, 0, nil)
 
 	// Simulate high-frequency updates (100 updates)
 	for i := 0; i < 100; i++ {
@@ -210,7 +218,7 @@ func TestDriverLocationUpdate_SetsDriverOnline(t *testing.T) {
 		Status: domain.DriverStatusOffline,
 	})
 
-	driverService := service.NewDriverService(locationStore, nil, driverRepo)
+	driverService := service.NewDriverService(locationStore, nil, driverRepo, nil, nil, nil, nil, 0, nil)
 
 	req := service.UpdateLocationRequest{
 		DriverID: "driver-1",
@@ -246,7 +254,7 @@ func TestDriverLocationUpdate_RedisError_PropagatesError(t *testing.T) {
 		Status: domain.DriverStatusOffline,
 	})
 
-	driverService := service.NewDriverService(locationStore, nil, driverRepo)
+	driverService := service.NewDriverService(locationStore, nil, driverRepo, nil, nil, nil, nil, 0, nil)
 
 	req := service.UpdateLocationRequest{
 		DriverID: "driver-1",
@@ -267,7 +275,7 @@ func TestDriverLocationUpdate_UnknownDriver_StillUpdatesRedis(t *testing.T) {
 	driverRepo := NewMockDriverRepository()
 	// Note: No driver added to repo
 
-	driverService := service.NewDriverService(locationStore, nil, driverRepo)
+	driverService := service.NewDriverService(locationStore, nil, driverRepo, nil, nil, nil, nil, 0, nil)
 
 	req := service.UpdateLocationRequest{
 		DriverID: "unknown-driver",
@@ -287,3 +295,50 @@ func TestDriverLocationUpdate_UnknownDriver_StillUpdatesRedis(t *testing.T) {
 		t.Error("expected location to be stored even for unknown driver")
 	}
 }
+
+func TestDriverLocationUpdate_HeadingRoundTripsToNearby(t *testing.T) {
+	t.Parallel()
+
+	driverRepo := NewMockDriverRepository()
+	driverRepo.AddDriver(&domain.Driver{ID: "driver-1", Status: domain.DriverStatusOffline, Tier: domain.DriverTierBasic})
+	driverService := service.NewDriverService(NewMockLocationStore(), nil, driverRepo, nil, nil, nil, nil, 0, nil)
+	h := handler.NewDriverHandler(driverService, nil, driverRepo, "", nil, nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.IdentityMiddleware(""))
+	router.POST("/v1/drivers/:id/location", h.UpdateLocation)
+	router.GET("/v1/drivers/nearby", h.GetNearby)
+
+	post := func(body string) int {
+		req := httptest.NewRequest(http.MethodPost, "/v1/drivers/driver-1/location", strings.NewReader(body))
+		req.Header.Set("X-User-ID", "driver-1")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	for _, body := range []string{`{"lat":12.97,"lng":77.59,"heading":-1}`, `{"lat":12.97,"lng":77.59,"heading":360.5}`} {
+		if code := post(body); code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", body, code)
+		}
+	}
+
+	if code := post(`{"lat":12.97,"lng":77.59,"heading":135.5}`); code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", code)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/drivers/nearby?lat=12.97&lng=77.59&radius_km=2", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	var nearby []handler.NearbyDriverResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &nearby); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	if len(nearby) != 1 || nearby[0].DriverID != "driver-1" || nearby[0].Heading != 135.5 {
+		t.Errorf("expected driver-1 with heading 135.5, got %+v", nearby)
+	}
+}
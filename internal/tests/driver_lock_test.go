@@ -0,0 +1,127 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/middleware"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// ADMIN DRIVER UNLOCK
+// ──────────────────────────────────────────────
+
+const testAdminToken = "admin-secret"
+
+func newDriverLockRouter(t *testing.T, lockStore *MockLockStore) *gin.Engine {
+	t.Helper()
+
+	driverRepo := NewMockDriverRepository()
+	driverRepo.AddDriver(&domain.Driver{ID: "driver-1", Status: domain.DriverStatusOnline, Tier: domain.DriverTierBasic})
+	matcher := service.NewMatchingService(service.MatchingServiceDeps{
+		LocationStore: NewMockLocationStore(),
+		LockStore:     lockStore,
+		DriverRepo:    driverRepo,
+		RideRepo:      NewMockRideRepository(),
+	})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	admin := router.Group("/v1/admin", middleware.AdminAuthMiddleware(testAdminToken))
+	admin.POST("/drivers/:id/unlock", handler.NewDriverLockHandler(matcher).Unlock)
+	return router
+}
+
+func unlockDriver(router *gin.Engine, driverID, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/v1/admin/drivers/"+driverID+"/unlock", nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestDriverLock_AdminUnlockMakesDriverAcquirable(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	lockStore := NewMockLockStore()
+	router := newDriverLockRouter(t, lockStore)
+
+	// A lock wedged by a crashed matcher.
+	if ok, _ := lockStore.AcquireDriverLock(ctx, "driver-1", time.Hour); !ok {
+		t.Fatal("expected to acquire the initial lock")
+	}
+	if ok, _ := lockStore.AcquireDriverLock(ctx, "driver-1", time.Hour); ok {
+		t.Fatal("expected the lock to be held")
+	}
+
+	w := unlockDriver(router, "driver-1", testAdminToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp handler.UnlockDriverResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if !resp.WasLocked {
+		t.Errorf("expected was_locked true, got %+v", resp)
+	}
+
+	if ok, _ := lockStore.AcquireDriverLock(ctx, "driver-1", time.Hour); !ok {
+		t.Error("expected the driver to be acquirable after unlock")
+	}
+	_ = lockStore.ReleaseDriverLock(ctx, "driver-1")
+
+	// Unlocking again reports that no lock was held.
+	w = unlockDriver(router, "driver-1", testAdminToken)
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusOK || resp.WasLocked {
+		t.Errorf("expected 200 with was_locked false, got %d %+v", w.Code, resp)
+	}
+}
+
+func TestDriverLock_UnlockRequiresAdminToken(t *testing.T) {
+	t.Parallel()
+
+	lockStore := NewMockLockStore()
+	router := newDriverLockRouter(t, lockStore)
+	_, _ = lockStore.AcquireDriverLock(context.Background(), "driver-1", time.Hour)
+
+	for _, token := range []string{"", "wrong"} {
+		if w := unlockDriver(router, "driver-1", token); w.Code != http.StatusUnauthorized {
+			t.Errorf("token %q: expected 401, got %d", token, w.Code)
+		}
+	}
+	if locked, _ := lockStore.IsDriverLocked(context.Background(), "driver-1"); !locked {
+		t.Error("expected the lock untouched without admin auth")
+	}
+
+	if w := unlockDriver(router, "driver-unknown", testAdminToken); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown driver, got %d", w.Code)
+	}
+}
+
+func TestAdminAuth_NoConfiguredTokenRejectsAll(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/admin", middleware.AdminAuthMiddleware(""), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+	req.Header.Set("Authorization", "Bearer ")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 without a configured token, got %d", w.Code)
+	}
+}
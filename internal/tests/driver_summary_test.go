@@ -0,0 +1,296 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/middleware"
+	"ride/internal/repository"
+	"ride/internal/repository/postgres"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// DRIVER WEEKLY SUMMARIES AND LEADERBOARD
+// ──────────────────────────────────────────────
+
+// summaryWeek is a Monday.
+var summaryWeek = time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)
+
+// newSummaryService serves bengaluru, sending summaries to env's outbox. In
+// summaryWeek driver-b leads on earnings among the 10-trip drivers, driver-a
+// and driver-d are tied on trips and earnings, and driver-c has fewer trips
+// but the most earnings.
+func newSummaryService(env *testEnv) *service.DriverSummaryService {
+	repo := NewMockDriverSummaryRepository()
+	repo.SetActivity(summaryWeek,
+		domain.DriverWeeklySummary{DriverID: "driver-a", Trips: 10, Earnings: 100, OnlineHours: 20},
+		domain.DriverWeeklySummary{DriverID: "driver-b", Trips: 10, Earnings: 120, OnlineHours: 25},
+		domain.DriverWeeklySummary{DriverID: "driver-c", Trips: 8, Earnings: 200, OnlineHours: 30},
+		domain.DriverWeeklySummary{DriverID: "driver-d", Trips: 10, Earnings: 100, OnlineHours: 18},
+	)
+	return service.NewDriverSummaryService(repo, env.notificationService(), "bengaluru", 20)
+}
+
+// summaryNotifications returns the weekly summaries a driver was sent.
+func summaryNotifications(env *testEnv, driverID string) []*domain.NotificationEvent {
+	events, _ := env.notifications.ListSince(context.Background(), driverID, 0, 100)
+	var sent []*domain.NotificationEvent
+	for _, e := range events {
+		if e.Type == string(service.NotificationWeeklySummary) {
+			sent = append(sent, e)
+		}
+	}
+	return sent
+}
+
+func TestWeekStart(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		at   time.Time
+		want time.Time
+	}{
+		{summaryWeek, summaryWeek},
+		{summaryWeek.Add(3*24*time.Hour + 13*time.Hour), summaryWeek},
+		{summaryWeek.Add(7*24*time.Hour - time.Second), summaryWeek}, // Sunday night
+		{summaryWeek.Add(7 * 24 * time.Hour), summaryWeek.AddDate(0, 0, 7)},
+	} {
+		if got := domain.WeekStart(tc.at); !got.Equal(tc.want) {
+			t.Errorf("WeekStart(%s): expected %s, got %s", tc.at, tc.want, got)
+		}
+	}
+}
+
+func TestParseWeek(t *testing.T) {
+	t.Parallel()
+
+	now := summaryWeek.AddDate(0, 0, 9) // Wednesday of the following week
+	if got, err := service.ParseWeek("", now); err != nil || !got.Equal(summaryWeek) {
+		t.Errorf("expected the last complete week %s, got %s, %v", summaryWeek, got, err)
+	}
+	if got, err := service.ParseWeek("2026-10-08", now); err != nil || !got.Equal(summaryWeek) {
+		t.Errorf("expected a Thursday to mean its week %s, got %s, %v", summaryWeek, got, err)
+	}
+	if _, err := service.ParseWeek("2026-W41", now); !errors.Is(err, service.ErrInvalidWeek) {
+		t.Errorf("expected ErrInvalidWeek, got %v", err)
+	}
+}
+
+func TestGenerateWeek_TieBreaking(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	summaries := newSummaryService(env)
+	if _, err := summaries.GenerateWeek(context.Background(), summaryWeek); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	board, err := summaries.Leaderboard(context.Background(), summaryWeek, "", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Earnings break the tie on trips; a and d, tied on both, share rank 2
+	// and are listed by ID, and the next rank is 4.
+	var got []string
+	for _, s := range board {
+		got = append(got, fmt.Sprintf("%s#%d", s.DriverID, s.Rank))
+	}
+	want := "driver-b#1 driver-a#2 driver-d#2 driver-c#4"
+	if strings.Join(got, " ") != want {
+		t.Errorf("expected %s, got %s", want, strings.Join(got, " "))
+	}
+	if board[0].RankedDrivers != 4 || board[0].City != "bengaluru" {
+		t.Errorf("expected 4 drivers ranked in bengaluru, got %+v", board[0])
+	}
+}
+
+func TestGenerateWeek_RanksWithinDriverCity(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	repo := NewMockDriverSummaryRepository()
+	repo.SetActivity(summaryWeek,
+		domain.DriverWeeklySummary{DriverID: "driver-a", City: "bengaluru", Trips: 10, Earnings: 100},
+		domain.DriverWeeklySummary{DriverID: "driver-b", Trips: 12, Earnings: 90}, // Registered without a city
+		domain.DriverWeeklySummary{DriverID: "driver-m", City: "mysuru", Trips: 3, Earnings: 30},
+		domain.DriverWeeklySummary{DriverID: "driver-n", City: "mysuru", Trips: 5, Earnings: 40},
+	)
+	summaries := service.NewDriverSummaryService(repo, env.notificationService(), "Bengaluru", 20)
+	if _, err := summaries.GenerateWeek(context.Background(), summaryWeek); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for city, want := range map[string]string{
+		"":        "driver-b#1/2 driver-a#2/2",
+		"Mysuru ": "driver-n#1/2 driver-m#2/2",
+		"goa":     "",
+	} {
+		board, err := summaries.Leaderboard(context.Background(), summaryWeek, city, 0)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var got []string
+		for _, s := range board {
+			got = append(got, fmt.Sprintf("%s#%d/%d", s.DriverID, s.Rank, s.RankedDrivers))
+		}
+		if strings.Join(got, " ") != want {
+			t.Errorf("city %q: expected %q, got %q", city, want, strings.Join(got, " "))
+		}
+	}
+}
+
+func TestGenerateWeek_IdempotentRegeneration(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	summaries := newSummaryService(env)
+	first, err := summaries.GenerateWeek(context.Background(), summaryWeek)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := summaries.GenerateWeek(context.Background(), summaryWeek.Add(36*time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(first) != 4 || len(second) != 4 {
+		t.Fatalf("expected 4 summaries each time, got %d and %d", len(first), len(second))
+	}
+	for i := range first {
+		a, b := *first[i], *second[i]
+		a.GeneratedAt, b.GeneratedAt, a.NotifiedAt, b.NotifiedAt = time.Time{}, time.Time{}, time.Time{}, time.Time{}
+		if a != b {
+			t.Errorf("expected regeneration to match, got %+v then %+v", a, b)
+		}
+	}
+
+	for _, driverID := range []string{"driver-a", "driver-b", "driver-c", "driver-d"} {
+		if sent := summaryNotifications(env, driverID); len(sent) != 1 {
+			t.Errorf("expected %s to be notified once, got %d", driverID, len(sent))
+		}
+	}
+	sent := summaryNotifications(env, "driver-c")[0]
+	if !strings.Contains(sent.Message, "8 trips") || !strings.Contains(sent.Message, "#4 in bengaluru") {
+		t.Errorf("unexpected summary message %q", sent.Message)
+	}
+}
+
+func TestDriverSummaryRepository_SQL(t *testing.T) {
+	t.Parallel()
+
+	db, rec := NewRecordingDB()
+	defer db.Close()
+	repo := postgres.NewDriverSummaryRepository(db)
+
+	if _, err := repo.GenerateWeek(context.Background(), summaryWeek, "bengaluru", 20, time.Now()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := repo.Leaderboard(context.Background(), summaryWeek, "bengaluru", 10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	queries := rec.Queries()
+	if len(queries) != 2 {
+		t.Fatalf("expected 2 queries, got %d", len(queries))
+	}
+
+	generate := queries[0]
+	for _, want := range []string{
+		"COALESCE(NULLIF(d.city, ''), $4) AS city",
+		"RANK() OVER (PARTITION BY city ORDER BY trips DESC, earnings DESC)",
+		"COUNT(*) OVER (PARTITION BY city)",
+		"ON CONFLICT (driver_id, week_start) DO UPDATE",
+	} {
+		if !strings.Contains(generate.Query, want) {
+			t.Errorf("expected generation to contain %q", want)
+		}
+	}
+	if strings.Contains(generate.Query, "notified_at =") {
+		t.Error("expected regeneration to keep notified_at")
+	}
+	if !generate.Args[0].(time.Time).Equal(summaryWeek) || !generate.Args[1].(time.Time).Equal(summaryWeek.AddDate(0, 0, 7)) {
+		t.Errorf("expected the week's bounds as arguments, got %v", generate.Args[:2])
+	}
+	if !strings.Contains(queries[1].Query, "ORDER BY rank, driver_id") {
+		t.Error("expected the leaderboard ordered by rank, then driver ID")
+	}
+}
+
+func TestDriverSummary_HTTP(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	summaries := newSummaryService(env)
+	if _, err := summaries.GenerateWeek(context.Background(), summaryWeek); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	router := newTestRouter()
+	router.Use(middleware.IdentityMiddleware(testAdminToken))
+	h := handler.NewDriverSummaryHandler(summaries)
+	router.GET("/v1/drivers/leaderboard", h.GetLeaderboard)
+	router.GET("/v1/drivers/:id/summary", h.GetSummary)
+
+	w := getAs(router, "/v1/drivers/driver-c/summary?week=2026-10-07", "driver-c")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var summary handler.DriverSummaryResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &summary); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if summary.WeekStart != "2026-10-05" || summary.Trips != 8 || summary.Rank != 4 || summary.RankedDrivers != 4 {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/drivers/leaderboard?week=2026-10-05&limit=2", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var board handler.LeaderboardResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &board); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if board.City != "bengaluru" || len(board.Drivers) != 2 || board.Drivers[0].DriverID != "driver-b" {
+		t.Errorf("unexpected leaderboard %+v", board)
+	}
+
+	for _, tc := range []struct {
+		path, userID string
+		want         int
+	}{
+		{"/v1/drivers/driver-x/summary?week=2026-10-05", "driver-x", http.StatusNotFound},
+		{"/v1/drivers/driver-a/summary?week=last-week", "driver-a", http.StatusBadRequest},
+		{"/v1/drivers/driver-c/summary?week=2026-10-05", "driver-a", http.StatusNotFound},
+		{"/v1/drivers/driver-c/summary?week=2026-10-05", "", http.StatusNotFound},
+		{"/v1/drivers/leaderboard?week=2026-10-05&limit=0", "", http.StatusBadRequest},
+		{"/v1/drivers/leaderboard?week=2026-10-05&city=goa", "", http.StatusOK},
+	} {
+		if w := getAs(router, tc.path, tc.userID); w.Code != tc.want {
+			t.Errorf("GET %s as %q: expected %d, got %d", tc.path, tc.userID, tc.want, w.Code)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/drivers/driver-c/summary?week=2026-10-05", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("admin: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if _, err := summaries.Summary(context.Background(), "driver-a", summaryWeek.AddDate(0, 0, 7)); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a week not generated, got %v", err)
+	}
+}
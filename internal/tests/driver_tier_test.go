@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/redis"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// DRIVER TIER CHANGES
+// ──────────────────────────────────────────────
+
+// newDriverTierRouter has BASIC driver-1 ONLINE at the pickup of ride-1,
+// which asks for PREMIUM, and serves admin tier changes.
+func newDriverTierRouter(env *testEnv) *gin.Engine {
+	env.drivers.AddDriver(&domain.Driver{ID: "driver-1", Status: domain.DriverStatusOnline, Tier: domain.DriverTierBasic})
+	env.locations.AddDriverLocation(redis.DriverLocation{DriverID: "driver-1", Lat: 12.97, Lng: 77.59})
+	env.rides.AddRide(&domain.Ride{
+		ID: "ride-1", RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59,
+		Status: domain.RideStatusRequested, Tier: domain.DriverTierPremium, Version: 1,
+	})
+
+	router := newTestRouter()
+	router.POST("/v1/admin/drivers/:id/tier", handler.NewDriverHandler(env.driverService(), nil, env.drivers, "", nil, nil).ChangeTier)
+	return router
+}
+
+func changeTier(router *gin.Engine, driverID, body string) *httptest.ResponseRecorder {
+	return post(router, "/v1/admin/drivers/"+driverID+"/tier", body)
+}
+
+func matchPremium(matcher *service.MatchingService) (*service.MatchResult, error) {
+	return matcher.Match(context.Background(), service.MatchRequest{
+		RideID: "ride-1", Lat: 12.97, Lng: 77.59, Tier: domain.DriverTierPremium,
+	})
+}
+
+func TestDriverTier_UpgradeIsMatchedImmediately(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	router := newDriverTierRouter(env)
+	matcher := service.NewMatchingService(env.matchingDeps())
+
+	if _, err := matchPremium(matcher); !errors.Is(err, service.ErrNoDriverAvailable) {
+		t.Fatalf("expected no premium driver before the upgrade, got %v", err)
+	}
+
+	w := changeTier(router, "driver-1", `{"tier":"premium"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp handler.TierChangeResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.DriverID != "driver-1" || resp.PreviousTier != "BASIC" || resp.Tier != "PREMIUM" || resp.ChangedAt == "" {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+
+	if len(env.drivers.TierChanges) != 1 || env.drivers.TierChanges[0].ChangedAt.IsZero() {
+		t.Errorf("expected the change recorded with a timestamp, got %+v", env.drivers.TierChanges)
+	}
+
+	result, err := matchPremium(matcher)
+	if err != nil {
+		t.Fatalf("expected a premium match after the upgrade, got %v", err)
+	}
+	if result.DriverID != "driver-1" {
+		t.Errorf("expected driver-1 matched, got %s", result.DriverID)
+	}
+}
+
+func TestDriverTier_Rejected(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	router := newDriverTierRouter(env)
+
+	cases := []struct {
+		name     string
+		driverID string
+		body     string
+		want     int
+	}{
+		{"tier not in the catalog", "driver-1", `{"tier":"GOLD"}`, http.StatusBadRequest},
+		{"missing tier", "driver-1", `{}`, http.StatusBadRequest},
+		{"already in the tier", "driver-1", `{"tier":"BASIC"}`, http.StatusConflict},
+		{"unknown driver", "driver-missing", `{"tier":"PREMIUM"}`, http.StatusNotFound},
+	}
+	for _, tc := range cases {
+		if w := changeTier(router, tc.driverID, tc.body); w.Code != tc.want {
+			t.Errorf("%s: expected %d, got %d: %s", tc.name, tc.want, w.Code, w.Body.String())
+		}
+	}
+	if len(env.drivers.TierChanges) != 0 {
+		t.Errorf("expected no changes recorded, got %+v", env.drivers.TierChanges)
+	}
+}
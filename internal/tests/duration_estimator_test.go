@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// TRIP DURATION ESTIMATES
+// ──────────────────────────────────────────────
+
+// seedPastTrip adds an ended trip for a ride between the given points, taking
+// minutes of driving plus paused minutes, ended hoursAgo before now.
+func seedPastTrip(t *testing.T, env *testEnv, id string, pickupLat, pickupLng, destLat, destLng float64, minutes, pausedMinutes int, hoursAgo float64, now time.Time) {
+	t.Helper()
+
+	env.rides.AddRide(&domain.Ride{
+		ID: "ride-" + id, RiderID: "rider-" + id, PickupLat: pickupLat, PickupLng: pickupLng, DestinationLat: destLat, DestinationLng: destLng,
+		Status: domain.RideStatusCompleted, Version: 1,
+	})
+	ended := now.Add(-time.Duration(hoursAgo * float64(time.Hour)))
+	err := env.trips.Create(context.Background(), &domain.Trip{
+		ID: "trip-" + id, RideID: "ride-" + id, DriverID: "driver-" + id, Status: domain.TripStatusEnded,
+		StartedAt: ended.Add(-time.Duration(minutes+pausedMinutes) * time.Minute), EndedAt: ended,
+		TotalPaused: time.Duration(pausedMinutes) * time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// estimatorNow is the time the estimator tests run at.
+var estimatorNow = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func TestEstimator_UsesMedianOfSimilarRecentTrips(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	now := estimatorNow
+	// Five similar trips a few hundred metres apart; the 90-minute outlier
+	// would drag an average, not the median. Paused time is not driving.
+	for i, minutes := range []int{18, 20, 22, 25, 90} {
+		offset := float64(i) * 0.001
+		seedPastTrip(t, env, fmt.Sprintf("similar-%d", i), 12.97+offset, 77.59, 13.05, 77.62-offset, minutes, 5, float64(i+1), now)
+	}
+	// Too old, and to a different destination.
+	seedPastTrip(t, env, "old", 12.97, 77.59, 13.05, 77.62, 200, 0, 24*60, now)
+	seedPastTrip(t, env, "elsewhere", 12.97, 77.59, 12.80, 77.40, 200, 0, 1, now)
+
+	estimator := service.NewEstimatorService(env.trips, 0.5, 30*24*time.Hour, 5, 50, func() time.Time { return now })
+	got := estimator.EstimateDuration(context.Background(), 12.97, 77.59, 13.05, 77.62)
+
+	if got.Duration != 22*time.Minute || got.Trips != 5 {
+		t.Errorf("expected the 22-minute median of 5 trips, got %s from %d", got.Duration, got.Trips)
+	}
+}
+
+func TestEstimator_FallsBackToAverageSpeedWithFewTrips(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	now := estimatorNow
+	for i := 0; i < 4; i++ {
+		seedPastTrip(t, env, fmt.Sprintf("similar-%d", i), 12.97, 77.59, 13.05, 77.62, 60, 0, 1, now)
+	}
+
+	estimator := service.NewEstimatorService(env.trips, 0.5, 30*24*time.Hour, 5, 50, func() time.Time { return now })
+	got := estimator.EstimateDuration(context.Background(), 12.97, 77.59, 13.05, 77.62)
+
+	want := (*service.EstimatorService)(nil).EstimateDuration(context.Background(), 12.97, 77.59, 13.05, 77.62)
+	if got.Trips != 0 || got.Duration != want.Duration || got.Duration == 60*time.Minute {
+		t.Errorf("expected the %s average-speed estimate, got %s from %d trips", want.Duration, got.Duration, got.Trips)
+	}
+}
@@ -0,0 +1,291 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// EMAIL VERIFICATION
+// ──────────────────────────────────────────────
+
+// newEmailVerification seeds rider-1 without an email and rider-2 with a
+// verified one, and returns a verification service over env with a one-hour
+// token TTL and a one-minute resend cooldown.
+func newEmailVerification(env *testEnv, tokens *MockEmailTokenStore) *service.EmailVerificationService {
+	env.users.AddUser(&domain.User{ID: "rider-1", Name: "Asha", Phone: "+911111111111"})
+	env.users.AddUser(&domain.User{ID: "rider-2", Name: "Ravi", Phone: "+912222222222", Email: "ravi@example.com", EmailVerified: true})
+	return service.NewEmailVerificationService(env.users, tokens, env.emails, time.Hour, time.Minute)
+}
+
+func newEmailRouter(env *testEnv, verification *service.EmailVerificationService) *gin.Engine {
+	userHandler := handler.NewUserHandler(env.users, verification, "")
+	router := newTestRouter()
+	router.POST("/v1/users/register", userHandler.Register)
+	router.POST("/v1/users/:id/email", userHandler.RequestEmailVerification)
+	router.GET("/v1/users/verify-email", userHandler.VerifyEmail)
+	return router
+}
+
+func requestVerification(router *gin.Engine, userID, email string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(handler.RequestEmailVerificationRequest{Email: email})
+	return post(router, "/v1/users/"+userID+"/email", string(body))
+}
+
+func verifyEmail(router *gin.Engine, token string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/users/verify-email?token="+token, nil))
+	return w
+}
+
+// lastToken returns the token from the most recent verification email.
+func lastToken(t *testing.T, env *testEnv) string {
+	t.Helper()
+
+	sent := env.emails.Sent()
+	if len(sent) == 0 {
+		t.Fatal("expected a verification email")
+	}
+	body := sent[len(sent)-1].Body
+	const prefix = "verify your email address: "
+	i := strings.Index(body, prefix)
+	if i < 0 {
+		t.Fatalf("no token in email body: %q", body)
+	}
+	return strings.Fields(body[i+len(prefix):])[0]
+}
+
+func loadUser(t *testing.T, env *testEnv, id string) *domain.User {
+	t.Helper()
+
+	user, err := env.users.GetByID(context.Background(), id)
+	if err != nil {
+		t.Fatalf("failed to load %s: %v", id, err)
+	}
+	return user
+}
+
+func TestEmailVerification_TokenVerifiesAddress(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	tokens := NewMockEmailTokenStore()
+	verification := newEmailVerification(env, tokens)
+	router := newEmailRouter(env, verification)
+
+	if w := requestVerification(router, "rider-1", "  Asha@Example.com "); w.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+	sent := env.emails.Sent()
+	if len(sent) != 1 || sent[0].To != "asha@example.com" {
+		t.Fatalf("expected one email to the normalized address, got %+v", sent)
+	}
+
+	// The address is not stored until it is verified.
+	if user := loadUser(t, env, "rider-1"); user.Email != "" {
+		t.Errorf("expected no email before verification, got %q", user.Email)
+	}
+
+	w := verifyEmail(router, lastToken(t, env))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp handler.UserResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Email != "asha@example.com" || !resp.EmailVerified {
+		t.Errorf("expected verified address in response, got %+v", resp)
+	}
+	if user := loadUser(t, env, "rider-1"); user.Email != "asha@example.com" || !user.EmailVerified {
+		t.Errorf("expected verified address stored, got %+v", user)
+	}
+}
+
+func TestEmailVerification_RejectsMalformedAddress(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	tokens := NewMockEmailTokenStore()
+	verification := newEmailVerification(env, tokens)
+	router := newEmailRouter(env, verification)
+
+	for _, email := range []string{"", "asha", "asha@", "Asha <asha@example.com>"} {
+		if w := requestVerification(router, "rider-1", email); w.Code != http.StatusBadRequest {
+			t.Errorf("%q: expected 400, got %d", email, w.Code)
+		}
+	}
+	if got := len(env.emails.Sent()); got != 0 {
+		t.Errorf("expected no emails sent, got %d", got)
+	}
+}
+
+func TestEmailVerification_AddressBelongingToAnotherUser(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	tokens := NewMockEmailTokenStore()
+	verification := newEmailVerification(env, tokens)
+	router := newEmailRouter(env, verification)
+
+	if w := requestVerification(router, "rider-1", "RAVI@example.com"); w.Code != http.StatusConflict {
+		t.Errorf("expected 409 for another user's address, got %d", w.Code)
+	}
+	if w := requestVerification(router, "rider-2", "ravi@example.com"); w.Code != http.StatusConflict {
+		t.Errorf("expected 409 re-verifying a verified address, got %d", w.Code)
+	}
+
+	// Someone else verifies the address while rider-1's token is outstanding.
+	if w := requestVerification(router, "rider-1", "shared@example.com"); w.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", w.Code)
+	}
+	token := lastToken(t, env)
+	_ = env.users.UpdateEmail(context.Background(), "rider-2", "shared@example.com", true)
+
+	if w := verifyEmail(router, token); w.Code != http.StatusConflict {
+		t.Errorf("expected 409 verifying a taken address, got %d", w.Code)
+	}
+}
+
+func TestEmailVerification_ResendRateLimited(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	tokens := NewMockEmailTokenStore()
+	verification := newEmailVerification(env, tokens)
+	router := newEmailRouter(env, verification)
+
+	if w := requestVerification(router, "rider-1", "asha@example.com"); w.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", w.Code)
+	}
+	if w := requestVerification(router, "rider-1", "asha@example.com"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("expected 429 within the cooldown, got %d", w.Code)
+	}
+	if got := len(env.emails.Sent()); got != 1 {
+		t.Errorf("expected one email within the cooldown, got %d", got)
+	}
+
+	tokens.Advance(time.Minute)
+	if w := requestVerification(router, "rider-1", "asha@example.com"); w.Code != http.StatusAccepted {
+		t.Errorf("expected 202 after the cooldown, got %d", w.Code)
+	}
+}
+
+func TestEmailVerification_ExpiredToken(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	tokens := NewMockEmailTokenStore()
+	verification := newEmailVerification(env, tokens)
+	router := newEmailRouter(env, verification)
+	if err := verification.RequestVerification(context.Background(), "rider-1", "asha@example.com"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tokens.Advance(time.Hour)
+
+	if w := verifyEmail(router, lastToken(t, env)); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an expired token, got %d", w.Code)
+	}
+	if user := loadUser(t, env, "rider-1"); user.Email != "" || user.EmailVerified {
+		t.Errorf("expected no address stored, got %+v", user)
+	}
+}
+
+func TestEmailVerification_TokenWorksOnce(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	tokens := NewMockEmailTokenStore()
+	verification := newEmailVerification(env, tokens)
+	router := newEmailRouter(env, verification)
+	if err := verification.RequestVerification(context.Background(), "rider-1", "asha@example.com"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	token := lastToken(t, env)
+
+	if _, err := verification.VerifyEmail(context.Background(), token); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := verification.VerifyEmail(context.Background(), token); !errors.Is(err, service.ErrInvalidVerificationToken) {
+		t.Errorf("expected ErrInvalidVerificationToken on reuse, got %v", err)
+	}
+	if w := verifyEmail(router, ""); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 without a token, got %d", w.Code)
+	}
+}
+
+func TestEmailVerification_RegistrationWithEmail(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	tokens := NewMockEmailTokenStore()
+	verification := newEmailVerification(env, tokens)
+	router := newEmailRouter(env, verification)
+
+	register := func(body string) *httptest.ResponseRecorder {
+		return post(router, "/v1/users/register", body)
+	}
+
+	if w := register(`{"name":"Meera","phone":"+913333333333","email":"not-an-email"}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422 for a malformed email, got %d", w.Code)
+	}
+	if w := register(`{"name":"Meera","phone":"+913333333333","email":"ravi@example.com"}`); w.Code != http.StatusConflict {
+		t.Errorf("expected 409 for a taken email, got %d", w.Code)
+	}
+
+	w := register(`{"name":"Meera","phone":"+913333333333","email":"Meera@Example.com"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp handler.UserResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Email != "meera@example.com" || resp.EmailVerified {
+		t.Errorf("expected an unverified normalized email, got %+v", resp)
+	}
+
+	// Registration without an email is unchanged.
+	if w := register(`{"name":"Kiran","phone":"+914444444444"}`); w.Code != http.StatusCreated {
+		t.Errorf("expected 201 without an email, got %d", w.Code)
+	}
+}
+
+func TestEmailVerification_ReceiptsOnlyToVerifiedAddresses(t *testing.T) {
+	t.Parallel()
+
+	users := NewMockUserRepository()
+	users.AddUser(&domain.User{ID: "rider-unverified", Email: "new@example.com"})
+	users.AddUser(&domain.User{ID: "rider-verified", Email: "known@example.com", EmailVerified: true})
+	users.AddUser(&domain.User{ID: "rider-none"})
+	sender := NewMockEmailSender()
+	receipts := service.NewReceiptService(nil, users, sender, nil, nil)
+
+	endedAt := time.Now()
+	for _, riderID := range []string{"rider-unverified", "rider-verified", "rider-none"} {
+		_, err := receipts.GenerateReceipt(context.Background(), service.GenerateReceiptRequest{
+			Trip: &domain.Trip{ID: "trip-" + riderID, DriverID: "driver-1", Fare: 12, StartedAt: endedAt.Add(-10 * time.Minute), EndedAt: endedAt},
+			Ride: &domain.Ride{ID: "ride-" + riderID, RiderID: riderID, SurgeMultiplier: 1},
+		})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", riderID, err)
+		}
+	}
+
+	sent := sender.Sent()
+	if len(sent) != 1 || sent[0].To != "known@example.com" {
+		t.Fatalf("expected a receipt emailed only to the verified address, got %+v", sent)
+	}
+	if !strings.Contains(sent[0].Body, "trip-rider-verified") {
+		t.Errorf("expected the formatted receipt in the email, got %q", sent[0].Body)
+	}
+}
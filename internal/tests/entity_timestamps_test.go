@@ -0,0 +1,148 @@
+package tests
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/repository/postgres"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// ENTITY TIMESTAMPS
+// ──────────────────────────────────────────────
+
+func TestEntityTimestamps_MockCreateOrdersByCreatedAt(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	payments := NewMockPaymentRepository()
+	first := &domain.Payment{ID: "payment-1", TripID: "trip-1", IdempotencyKey: "key-1"}
+	second := &domain.Payment{ID: "payment-2", TripID: "trip-2", IdempotencyKey: "key-2"}
+	_ = payments.Create(ctx, first)
+	_ = payments.Create(ctx, second)
+
+	if first.CreatedAt.IsZero() || !first.UpdatedAt.Equal(first.CreatedAt) {
+		t.Fatalf("expected CreatedAt set and UpdatedAt equal to it, got %+v", first)
+	}
+	if !second.CreatedAt.After(first.CreatedAt) {
+		t.Errorf("expected later creation to sort later, got %v then %v", first.CreatedAt, second.CreatedAt)
+	}
+
+	_ = payments.UpdateStatus(ctx, "payment-1", domain.PaymentStatusSuccess)
+	stored, _ := payments.GetByID(ctx, "payment-1")
+	if !stored.UpdatedAt.After(stored.CreatedAt) || !stored.CreatedAt.Equal(first.CreatedAt) {
+		t.Errorf("expected only UpdatedAt to move on update, got %+v", stored)
+	}
+}
+
+func TestEntityTimestamps_TripUpdateBumpsUpdatedAt(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	trips := NewMockTripRepository()
+	_ = trips.Create(ctx, &domain.Trip{ID: "trip-1", RideID: "ride-1", DriverID: "driver-1", Status: domain.TripStatusStarted, StartedAt: time.Now(), Version: 1})
+	created := trips.GetTrip("trip-1").CreatedAt
+
+	tripService := service.NewTripService(service.TripServiceDeps{
+		TripRepo:   trips,
+		RideRepo:   NewMockRideRepository(),
+		DriverRepo: NewMockDriverRepository(),
+	})
+	paused, err := tripService.PauseTrip(ctx, service.PauseTripRequest{TripID: "trip-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !paused.CreatedAt.Equal(created) || !paused.UpdatedAt.After(created) {
+		t.Fatalf("expected UpdatedAt to move past CreatedAt %v, got %+v", created, paused)
+	}
+
+	resumed, err := tripService.ResumeTrip(ctx, service.ResumeTripRequest{TripID: "trip-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !resumed.UpdatedAt.After(paused.UpdatedAt) {
+		t.Errorf("expected every update to move UpdatedAt, got %v then %v", paused.UpdatedAt, resumed.UpdatedAt)
+	}
+}
+
+func TestEntityTimestamps_PostgresTripWrites(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db, rec := NewRecordingDB()
+	repo := postgres.NewTripRepository(db)
+
+	trip := &domain.Trip{ID: "trip-1", RideID: "ride-1", DriverID: "driver-1", Status: domain.TripStatusStarted, StartedAt: time.Now()}
+	if err := repo.Create(ctx, trip); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	insert := rec.Queries()[0]
+	if !strings.Contains(insert.Query, "created_at, updated_at") {
+		t.Fatalf("expected the insert to write both timestamps, got %s", insert.Query)
+	}
+	if trip.CreatedAt.IsZero() || insert.Args[15] != trip.CreatedAt || insert.Args[16] != trip.UpdatedAt {
+		t.Errorf("expected the stamped timestamps inserted, got %v", insert.Args[15:])
+	}
+
+	created := trip.CreatedAt
+	for i := 0; i < 2; i++ {
+		before := trip.UpdatedAt
+		trip.Status = domain.TripStatusPaused
+		if err := repo.Update(ctx, trip); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		queries := rec.Queries()
+		update := queries[len(queries)-1]
+		if !strings.Contains(update.Query, "updated_at = $14") {
+			t.Fatalf("expected the update to write updated_at, got %s", update.Query)
+		}
+		if !trip.UpdatedAt.After(before) || update.Args[13] != trip.UpdatedAt {
+			t.Errorf("update %d: expected UpdatedAt to move past %v and be written, got %v", i+1, before, trip.UpdatedAt)
+		}
+		if !trip.CreatedAt.Equal(created) {
+			t.Errorf("update %d: expected CreatedAt unchanged, got %v", i+1, trip.CreatedAt)
+		}
+	}
+}
+
+func TestEntityTimestamps_PostgresStatusUpdatesTouchUpdatedAt(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db, rec := NewRecordingDB()
+
+	_ = postgres.NewDriverRepository(db).UpdateStatus(ctx, "driver-1", domain.DriverStatusOnline)
+	_, _ = postgres.NewDriverRepository(db).TransitionStatus(ctx, "driver-1", domain.DriverStatusOnline, domain.DriverStatusBreak)
+	_ = postgres.NewPaymentRepository(db).UpdateStatus(ctx, "payment-1", domain.PaymentStatusSuccess)
+
+	for _, q := range rec.Queries() {
+		if !strings.Contains(q.Query, "updated_at = NOW()") {
+			t.Errorf("expected updated_at touched, got %s", q.Query)
+		}
+	}
+}
+
+func TestEntityTimestamps_PostgresScansTimestamps(t *testing.T) {
+	t.Parallel()
+
+	created := time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC)
+	updated := created.Add(time.Minute)
+	db, rec := NewRecordingDB()
+	rec.Respond = func(query string, args []driver.Value) ([]string, [][]driver.Value) {
+		return []string{"id", "trip_id", "amount", "status", "idempotency_key", "created_at", "updated_at", "instrument_id", "fee", "failure_reason", "auth_id"},
+			[][]driver.Value{{"payment-1", "trip-1", 12.5, "SUCCESS", "key-1", created, updated, "", 0.0, "", ""}}
+	}
+
+	payment, err := postgres.NewPaymentRepository(db).GetByID(context.Background(), "payment-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !payment.CreatedAt.Equal(created) || !payment.UpdatedAt.Equal(updated) {
+		t.Errorf("expected timestamps scanned, got %+v", payment)
+	}
+}
@@ -0,0 +1,142 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// DOMAIN EVENTS
+// ──────────────────────────────────────────────
+
+// runLifecycle requests a card ride, assigns driver-1, and starts and ends
+// its trip. Matching and the trip's transactional writes are mirrored into
+// the mock repositories by hand.
+func runLifecycle(t *testing.T, env *testEnv) (*domain.Ride, *domain.Trip) {
+	t.Helper()
+	ctx := context.Background()
+
+	matching := NewMockMatchingServiceForTest()
+	matching.SetResult(&service.MatchResult{DriverID: "driver-1"}, nil)
+	rideService := service.NewRideService(env.rideDeps(matching))
+	tripService := service.NewTripService(env.tripDeps())
+
+	if _, err := rideService.CreateRide(ctx, service.CreateRideRequest{
+		RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 13.2, DestinationLng: 77.7,
+		PaymentMethod: domain.PaymentMethodCard,
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ride := env.rides.GetAllRides()[0]
+	ride.Status, ride.AssignedDriverID, ride.AssignedAt = domain.RideStatusAssigned, "driver-1", time.Now()
+
+	trip, err := tripService.StartTrip(ctx, service.StartTripRequest{RideID: ride.ID, DriverID: "driver-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	trip.StartedAt = trip.StartedAt.Add(-10 * time.Minute)
+	_ = env.trips.Create(ctx, trip)
+
+	if _, err := tripService.EndTrip(ctx, service.EndTripRequest{TripID: trip.ID}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return ride, trip
+}
+
+func TestEvents_FullRideLifecycle(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	ride, trip := runLifecycle(t, env)
+
+	want := []domain.EventType{
+		domain.EventRideCreated, domain.EventRideAssigned, domain.EventTripStarted, domain.EventTripEnded, domain.EventPaymentSucceeded,
+	}
+	if got := env.events.Types(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected events %v, got %v", want, got)
+	}
+
+	events := env.events.Events()
+	seen := make(map[string]bool)
+	for _, event := range events {
+		if event.ID == "" || seen[event.ID] || event.OccurredAt.IsZero() {
+			t.Errorf("%s: expected a unique ID and a timestamp, got %q at %v", event.Type, event.ID, event.OccurredAt)
+		}
+		seen[event.ID] = true
+	}
+
+	for i, aggregateID := range []string{ride.ID, ride.ID, trip.ID, trip.ID} {
+		if events[i].AggregateID != aggregateID {
+			t.Errorf("%s: expected aggregate %s, got %s", events[i].Type, aggregateID, events[i].AggregateID)
+		}
+	}
+	if events[1].Data["driver_id"] != "driver-1" {
+		t.Errorf("expected the assigned driver on ride.assigned, got %v", events[1].Data)
+	}
+	ended, paid := events[3], events[4]
+	if ended.Data["ride_id"] != ride.ID || ended.Data["fare"].(float64) <= 0 {
+		t.Errorf("expected the ride and fare on trip.ended, got %v", ended.Data)
+	}
+	if paid.Data["trip_id"] != trip.ID || paid.Data["amount"] != ended.Data["fare"] {
+		t.Errorf("expected the trip's fare on payment.succeeded, got %v", paid.Data)
+	}
+}
+
+func TestEvents_NoAssignmentWithoutDriver(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	rideService := service.NewRideService(env.rideDeps(NewMockMatchingServiceForTest()))
+	_, err := rideService.CreateRide(context.Background(), service.CreateRideRequest{
+		RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 13.2, DestinationLng: 77.7,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := env.events.Types(); !reflect.DeepEqual(got, []domain.EventType{domain.EventRideCreated}) {
+		t.Errorf("expected only ride.created, got %v", got)
+	}
+}
+
+func TestEvents_PublishFailureDoesNotFailTransitions(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	env.events.PublishError = errors.New("broker unavailable")
+
+	runLifecycle(t, env)
+
+	if got := len(env.events.Events()); got != 5 {
+		t.Errorf("expected every event attempted, got %d", got)
+	}
+}
+
+func TestEvents_CashCollectionPublishesPaymentSucceeded(t *testing.T) {
+	t.Parallel()
+
+	events := NewMockEventPublisher()
+	paymentService := service.NewPaymentService(NewMockPaymentRepository(), NewMockPSP(), nil, events, nil, 0)
+	ctx := context.Background()
+
+	if _, err := paymentService.ProcessPayment(ctx, service.ProcessPaymentRequest{TripID: "trip-1", Amount: 12, Method: domain.PaymentMethodCash}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := len(events.Events()); got != 0 {
+		t.Fatalf("expected no event while cash is due, got %d", got)
+	}
+
+	if _, _, err := paymentService.ConfirmCash(ctx, "trip-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := events.Events()
+	if len(got) != 1 || got[0].Type != domain.EventPaymentSucceeded || got[0].Data["payment_method"] != domain.PaymentMethodCash {
+		t.Errorf("expected one cash payment.succeeded, got %+v", got)
+	}
+}
@@ -0,0 +1,242 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/middleware"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// SERVER-SENT NOTIFICATION EVENTS
+// ──────────────────────────────────────────────
+
+type sseFrame struct {
+	ID      string
+	Event   string
+	Data    string
+	Comment string
+}
+
+// newEventsServer serves users' event streams, fed by broker, with the given
+// heartbeat. Notifications sent through the returned service reach them.
+func newEventsServer(t *testing.T, broker *MockNotificationBroker, heartbeat time.Duration) (*httptest.Server, *service.NotificationService) {
+	t.Helper()
+
+	notificationService := service.NewNotificationService(NewMockNotificationRepository(), broker, service.DeepLinks{}, nil, nil, nil, nil)
+	router := newTestRouter()
+	router.Use(middleware.IdentityMiddleware(testAdminToken))
+	router.GET("/v1/users/:id/events", handler.NewEventsHandler(notificationService, heartbeat).StreamUserEvents)
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server, notificationService
+}
+
+// openStream connects to the user's event stream as that user. The
+// subscription is live once response headers arrive.
+func openStream(t *testing.T, ctx context.Context, server *httptest.Server, userID, lastEventID string) *bufio.Reader {
+	t.Helper()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/v1/users/"+userID+"/events", nil)
+	req.Header.Set("X-User-ID", userID)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to open stream: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected text/event-stream, got %q", ct)
+	}
+	return bufio.NewReader(resp.Body)
+}
+
+// readFrame reads one blank-line-terminated SSE frame.
+func readFrame(t *testing.T, r *bufio.Reader) sseFrame {
+	t.Helper()
+
+	var frame sseFrame
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("stream ended early: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			return frame
+		case strings.HasPrefix(line, ": "):
+			frame.Comment = strings.TrimPrefix(line, ": ")
+		case strings.HasPrefix(line, "id: "):
+			frame.ID = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			frame.Event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			frame.Data = strings.TrimPrefix(line, "data: ")
+		default:
+			t.Fatalf("unexpected SSE line %q", line)
+		}
+	}
+}
+
+func tripFor(id string) *domain.Trip {
+	return &domain.Trip{ID: id, StartedAt: time.Now()}
+}
+
+func TestSSE_DeliversLiveEventsWithFraming(t *testing.T) {
+	t.Parallel()
+
+	broker := NewMockNotificationBroker()
+	server, notifications := newEventsServer(t, broker, time.Minute)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream := openStream(t, ctx, server, "rider-1", "")
+
+	_ = notifications.NotifyTripStarted(ctx, tripFor("trip-1"), "rider-1")
+	_ = notifications.NotifyTripStarted(ctx, tripFor("trip-other"), "rider-2") // Someone else's
+
+	frame := readFrame(t, stream)
+	if frame.ID != "1" || frame.Event != "TRIP_STARTED" {
+		t.Fatalf("unexpected frame: %+v", frame)
+	}
+
+	var data handler.NotificationEventData
+	if err := json.Unmarshal([]byte(frame.Data), &data); err != nil {
+		t.Fatalf("invalid data: %v", err)
+	}
+	if data.ID != 1 || data.Type != "TRIP_STARTED" || data.Data["trip_id"] != "trip-1" {
+		t.Errorf("unexpected payload: %+v", data)
+	}
+}
+
+func TestSSE_ReplaysMissedEventsSinceLastEventID(t *testing.T) {
+	t.Parallel()
+
+	broker := NewMockNotificationBroker()
+	server, notifications := newEventsServer(t, broker, time.Minute)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Sent while the client was disconnected.
+	for _, id := range []string{"trip-1", "trip-2", "trip-3"} {
+		_ = notifications.NotifyTripStarted(ctx, tripFor(id), "rider-1")
+	}
+
+	stream := openStream(t, ctx, server, "rider-1", "1")
+	_ = notifications.NotifyTripEnded(ctx, service.TripSummary{TripID: "trip-3", RiderID: "rider-1", Fare: 25})
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		ids = append(ids, readFrame(t, stream).ID)
+	}
+	if strings.Join(ids, ",") != "2,3,4" {
+		t.Errorf("expected replay of 2,3 then live 4, got %v", ids)
+	}
+}
+
+func TestSSE_SendsHeartbeatsAndCleansUpOnDisconnect(t *testing.T) {
+	t.Parallel()
+
+	broker := NewMockNotificationBroker()
+	server, _ := newEventsServer(t, broker, 20*time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+
+	stream := openStream(t, ctx, server, "rider-1", "")
+	if frame := readFrame(t, stream); frame.Comment != "heartbeat" {
+		t.Fatalf("expected heartbeat comment, got %+v", frame)
+	}
+	if n := broker.SubscriberCount("rider-1"); n != 1 {
+		t.Fatalf("expected 1 subscriber, got %d", n)
+	}
+
+	cancel()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for broker.SubscriberCount("rider-1") != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("subscription not released after client disconnect")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSSE_InvalidLastEventID(t *testing.T) {
+	t.Parallel()
+
+	server, _ := newEventsServer(t, NewMockNotificationBroker(), time.Minute)
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/v1/users/rider-1/events", nil)
+	req.Header.Set("X-User-ID", "rider-1")
+	req.Header.Set("Last-Event-ID", "abc")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestSSE_OnlyTheUserOrAnAdminSubscribes(t *testing.T) {
+	t.Parallel()
+
+	broker := NewMockNotificationBroker()
+	server, _ := newEventsServer(t, broker, time.Minute)
+
+	for _, tc := range []struct {
+		stream, userID, authorization string
+	}{
+		{stream: "rider-1"},
+		{stream: "rider-1", userID: "rider-2"},
+		{stream: "rider-1", authorization: "Bearer wrong-token"},
+		{stream: service.OpsRecipientID, userID: service.OpsRecipientID},
+		{stream: service.OpsRecipientID, userID: "driver-1"},
+	} {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/v1/users/"+tc.stream+"/events", nil)
+		req.Header.Set("X-User-ID", tc.userID)
+		req.Header.Set("Authorization", tc.authorization)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("%+v: expected 404, got %d", tc, resp.StatusCode)
+		}
+	}
+	if n := broker.SubscriberCount("rider-1") + broker.SubscriberCount(service.OpsRecipientID); n != 0 {
+		t.Errorf("expected no subscriptions, got %d", n)
+	}
+
+	// Admins watch the ops team's stream.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/v1/users/"+service.OpsRecipientID+"/events", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected 200 for an admin on the ops stream, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,208 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/middleware"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// CSV EXPORTS
+// ──────────────────────────────────────────────
+
+// pacedTripRepository hands out one trip per receive on release after the
+// first, so a test can observe rows reaching the client before the
+// iteration finishes.
+type pacedTripRepository struct {
+	*MockTripRepository
+	release chan struct{}
+}
+
+func (r *pacedTripRepository) ListIterator(ctx context.Context, from, to time.Time, fn func(*domain.Trip) error) error {
+	first := true
+	return r.MockTripRepository.ListIterator(ctx, from, to, func(trip *domain.Trip) error {
+		if !first {
+			select {
+			case <-r.release:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		first = false
+		return fn(trip)
+	})
+}
+
+var exportDay = time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC)
+
+// seedExportTrips creates n ended trips started a minute apart on exportDay.
+func seedExportTrips(trips *MockTripRepository, n int) {
+	for i := 0; i < n; i++ {
+		_ = trips.Create(context.Background(), &domain.Trip{
+			ID: "trip-" + string(rune('a'+i)), RideID: "ride-1", DriverID: "driver-1", Status: domain.TripStatusEnded,
+			Fare: 12.5, StartedAt: exportDay.Add(time.Duration(i) * time.Minute), EndedAt: exportDay.Add(time.Hour), Version: 1,
+		})
+	}
+}
+
+// exportRouter serves the admin export endpoints.
+func exportRouter(exportService *service.ExportService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	exportHandler := handler.NewExportHandler(exportService)
+	admin := router.Group("/v1/admin", middleware.AdminAuthMiddleware(testAdminToken))
+	admin.GET("/export/trips", exportHandler.ExportTrips)
+	admin.GET("/export/rides", exportHandler.ExportRides)
+	return router
+}
+
+func getExport(router *gin.Engine, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestExport_TripsStreamRowsAsTheyAreRead(t *testing.T) {
+	t.Parallel()
+
+	trips := &pacedTripRepository{MockTripRepository: NewMockTripRepository(), release: make(chan struct{})}
+	seedExportTrips(trips.MockTripRepository, 3)
+	server := httptest.NewServer(exportRouter(service.NewExportService(nil, trips, NewMockRideRepository(), 0)))
+	t.Cleanup(server.Close)
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/v1/admin/export/trips?from=2026-03-14&to=2026-03-14&format=csv", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if got := resp.Header.Get("Content-Disposition"); got != `attachment; filename="trips-2026-03-14-to-2026-03-14.csv"` {
+		t.Errorf("unexpected Content-Disposition %q", got)
+	}
+
+	// The header and first row must arrive while the rest are held back.
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+	next := func() string {
+		select {
+		case line := <-lines:
+			return line
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for a streamed row")
+			return ""
+		}
+	}
+
+	if header := next(); !strings.HasPrefix(header, "id,ride_id,driver_id,status,fare,started_at") {
+		t.Fatalf("unexpected header %q", header)
+	}
+	if first := next(); !strings.HasPrefix(first, "trip-a,ride-1,driver-1,ENDED,12.50,2026-03-14T09:30:00Z,2026-03-14T10:30:00Z") {
+		t.Errorf("expected the first trip with ISO timestamps, got %q", first)
+	}
+
+	for _, id := range []string{"trip-b", "trip-c"} {
+		trips.release <- struct{}{}
+		if line := next(); !strings.HasPrefix(line, id+",") {
+			t.Errorf("expected %s next, got %q", id, line)
+		}
+	}
+	if line, ok := <-lines; ok {
+		t.Errorf("expected the stream to end, got %q", line)
+	}
+}
+
+func TestExport_RidesEscapeFieldsWithCommas(t *testing.T) {
+	t.Parallel()
+
+	rides := NewMockRideRepository()
+	reason := `Driver late, rider said "no thanks"`
+	rides.AddRide(&domain.Ride{
+		ID: "ride-1", RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, Status: domain.RideStatusCancelled,
+		SurgeMultiplier: 1.5, PaymentMethod: domain.PaymentMethodCash, SurchargeLabel: "Airport, T2", SurchargeAmount: 7.5,
+		CancelReason: reason, CreatedAt: exportDay, CancelledAt: exportDay.Add(5 * time.Minute),
+	})
+	router := exportRouter(service.NewExportService(nil, NewMockTripRepository(), rides, 0))
+
+	w := getExport(router, "/v1/admin/export/rides?from=2026-03-14&to=2026-03-14")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"Driver late, rider said ""no thanks"""`) {
+		t.Errorf("expected the cancel reason quoted, got %s", w.Body.String())
+	}
+
+	records, err := csv.NewReader(strings.NewReader(w.Body.String())).ReadAll()
+	if err != nil {
+		t.Fatalf("expected valid CSV, got %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected a header and one row, got %v", records)
+	}
+	row := make(map[string]string)
+	for i, column := range records[0] {
+		row[column] = records[1][i]
+	}
+	if row["cancel_reason"] != reason || row["surcharge_label"] != "Airport, T2" || row["cancelled_at"] != "2026-03-14T09:35:00Z" {
+		t.Errorf("expected fields to round-trip, got %v", row)
+	}
+}
+
+func TestExport_RowCapRefusesLargeRanges(t *testing.T) {
+	t.Parallel()
+
+	trips := NewMockTripRepository()
+	seedExportTrips(trips, 3)
+	router := exportRouter(service.NewExportService(nil, trips, NewMockRideRepository(), 2))
+
+	w := getExport(router, "/v1/admin/export/trips?from=2026-03-14&to=2026-03-14")
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "row limit") {
+		t.Errorf("expected 400 naming the row limit, got %d: %s", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Content-Disposition") != "" {
+		t.Error("expected no attachment when the export is refused")
+	}
+
+	// A range with no trips succeeds with just the header row.
+	if w := getExport(router, "/v1/admin/export/trips?from=2026-03-15&to=2026-03-16"); w.Code != http.StatusOK || strings.Count(w.Body.String(), "\n") != 1 {
+		t.Errorf("expected only the header for an empty range, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestExport_InvalidQueries(t *testing.T) {
+	t.Parallel()
+
+	router := exportRouter(service.NewExportService(nil, NewMockTripRepository(), NewMockRideRepository(), 0))
+	for _, query := range []string{
+		"",
+		"?from=2026-03-14",
+		"?from=14/03/2026&to=2026-03-14",
+		"?from=2026-03-15&to=2026-03-14",
+		"?from=2026-03-14&to=2026-03-14&format=json",
+	} {
+		if w := getExport(router, "/v1/admin/export/trips"+query); w.Code != http.StatusBadRequest {
+			t.Errorf("%q: expected 400, got %d", query, w.Code)
+		}
+	}
+}
@@ -0,0 +1,509 @@
+package tests
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/middleware"
+	"ride/internal/repository/postgres"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// FARE ADJUSTMENTS
+// ──────────────────────────────────────────────
+
+// newFareAdjustmentService records a card trip that ended with a fare of
+// 25.00, paid in full, and returns a trip service writing adjustments to
+// env's recording database.
+func newFareAdjustmentService(t *testing.T, env *testEnv) *service.TripService {
+	t.Helper()
+
+	seedAdjustableTrip(env)
+	payFare(t, env, 25, "")
+	return service.NewTripService(fareAdjustmentDeps(env))
+}
+
+// seedAdjustableTrip records rider-1's card trip-1, ended with a fare of
+// 25.00 and not yet paid.
+func seedAdjustableTrip(env *testEnv) {
+	env.rec.Respond = func(query string, _ []driver.Value) ([]string, [][]driver.Value) {
+		if strings.Contains(query, "INSERT INTO fare_adjustments") {
+			return []string{"created_at"}, [][]driver.Value{{time.Now()}}
+		}
+		return nil, nil
+	}
+	env.rides.AddRide(&domain.Ride{
+		ID: "ride-1", RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 12.93, DestinationLng: 77.62,
+		Status: domain.RideStatusCompleted, AssignedDriverID: "driver-1", SurgeMultiplier: 1,
+		PaymentMethod: domain.PaymentMethodCard, InstrumentID: "card-rider-1", Tier: domain.DriverTierBasic, Version: 3,
+	})
+	endedAt := time.Now()
+	_ = env.trips.Create(context.Background(), &domain.Trip{
+		ID: "trip-1", RideID: "ride-1", DriverID: "driver-1", Status: domain.TripStatusEnded, Fare: 25,
+		StartedAt: endedAt.Add(-20 * time.Minute), EndedAt: endedAt, Version: 2,
+	})
+}
+
+// payFare pays amount of trip-1's fare: the fare payment without a key,
+// otherwise a further charge under it, as a split share is.
+func payFare(t *testing.T, env *testEnv, amount float64, key string) *domain.Payment {
+	t.Helper()
+
+	instrumentID := "card-rider-1"
+	if key != "" {
+		instrumentID = "card-" + strings.TrimPrefix(key, "split:")
+	}
+	payment, err := env.paymentService().ProcessPayment(context.Background(), service.ProcessPaymentRequest{
+		TripID: "trip-1", Amount: amount, Method: domain.PaymentMethodCard, InstrumentID: instrumentID, IdempotencyKey: key,
+	})
+	if err != nil {
+		t.Fatalf("failed to pay the fare: %v", err)
+	}
+	return payment
+}
+
+func fareAdjustmentDeps(env *testEnv) service.TripServiceDeps {
+	deps := env.tripDeps()
+	deps.ReceiptService = service.NewReceiptService(nil, nil, nil, nil, nil)
+	deps.FareAdjustments = postgres.NewFareAdjustmentRepository(env.db)
+	return deps
+}
+
+func newFareAdjustmentRouter(tripService *service.TripService) *gin.Engine {
+	router := newTestRouter()
+	admin := router.Group("/v1/admin", middleware.AdminAuthMiddleware(testAdminToken))
+	admin.POST("/trips/:id/adjust-fare", handler.NewTripHandler(tripService, nil).AdjustFare)
+	return router
+}
+
+func adjustFare(router *gin.Engine, body, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/v1/admin/trips/trip-1/adjust-fare", strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// recorded returns the adjustments written to the database, with the
+// payment each was settled by.
+func recordedAdjustments(env *testEnv) []domain.FareAdjustment {
+	var adjustments []domain.FareAdjustment
+	for _, q := range env.rec.Queries() {
+		switch {
+		case strings.Contains(q.Query, "INSERT INTO fare_adjustments"):
+			adjustments = append(adjustments, domain.FareAdjustment{
+				ID: q.Args[0].(string), TripID: q.Args[1].(string), PreviousFare: q.Args[2].(float64),
+				Fare: q.Args[3].(float64), Reason: q.Args[4].(string), PaymentID: q.Args[5].(string),
+			})
+		case strings.Contains(q.Query, "UPDATE fare_adjustments SET payment_id"):
+			for i := range adjustments {
+				if adjustments[i].ID == q.Args[1] {
+					adjustments[i].PaymentID = q.Args[0].(string)
+				}
+			}
+		}
+	}
+	return adjustments
+}
+
+// storedFare returns the fare the adjustment wrote with it, 0 if none.
+func storedFare(env *testEnv) float64 {
+	fare := 0.0
+	for _, q := range env.rec.Queries() {
+		if strings.Contains(q.Query, "UPDATE trips") {
+			fare = q.Args[3].(float64) // fare is $4
+		}
+	}
+	return fare
+}
+
+// assertReconciled checks that the trip's payments still account for its
+// stored fare, in the daily report and the ledger check.
+func assertReconciled(t *testing.T, env *testEnv) {
+	t.Helper()
+
+	env.trips.GetTrip("trip-1").Fare = storedFare(env)
+
+	reports := NewMockReportRepository(env.trips, env.rides, env.payments)
+	report, err := service.NewReportService(reports).DailyReport(context.Background(), time.Now().UTC())
+	if err != nil {
+		t.Fatalf("unexpected report error: %v", err)
+	}
+	if !report.Balanced {
+		t.Errorf("expected a balanced report, got difference %.2f and discrepancies %+v", report.Difference, report.Discrepancies)
+	}
+
+	anomalies, _ := reports.GetLedgerAnomalies(context.Background(), time.Time{}, 0.01)
+	if len(anomalies) != 0 {
+		t.Errorf("expected no ledger anomalies, got %+v", anomalies)
+	}
+}
+
+func TestFareAdjustment_UpwardChargesDifference(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	router := newFareAdjustmentRouter(newFareAdjustmentService(t, env))
+
+	w := adjustFare(router, `{"fare": 31.5, "reason": "toll missing from the fare"}`, testAdminToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp handler.FareAdjustmentResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if resp.Fare != 31.5 || resp.Adjustment.PreviousFare != 25 || resp.Adjustment.Difference != 6.5 {
+		t.Errorf("expected fare 25.00 -> 31.50 (+6.50), got fare %.2f and %+v", resp.Fare, resp.Adjustment)
+	}
+	if resp.Payment == nil || resp.Payment.Amount != 6.5 || resp.Payment.Status != string(domain.PaymentStatusSuccess) {
+		t.Fatalf("expected an extra charge of 6.50, got %+v", resp.Payment)
+	}
+	if got := env.psp.ChargeCallCount; got != 2 {
+		t.Errorf("expected the fare and the difference charged, got %d charges", got)
+	}
+	if len(env.psp.Refunds) != 0 {
+		t.Errorf("expected no refunds, got %v", env.psp.Refunds)
+	}
+
+	if r := resp.Receipt; r == nil || r.TotalFare != 31.5 || r.AdjustedFrom != 25 || r.AdjustmentReason != "toll missing from the fare" {
+		t.Errorf("expected a receipt for 31.50 adjusted from 25.00, got %+v", r)
+	}
+
+	if fare := storedFare(env); fare != 31.5 {
+		t.Errorf("expected the corrected fare stored, got %.2f", fare)
+	}
+
+	recorded := recordedAdjustments(env)
+	if len(recorded) != 1 {
+		t.Fatalf("expected one recorded adjustment, got %d", len(recorded))
+	}
+	if a := recorded[0]; a.PreviousFare != 25 || a.Fare != 31.5 || a.Reason != "toll missing from the fare" || a.PaymentID != resp.Payment.ID {
+		t.Errorf("expected the adjustment recorded with its reason and charge, got %+v", a)
+	}
+
+	assertReconciled(t, env)
+}
+
+func TestFareAdjustment_DownwardRefundsDifference(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	tripService := newFareAdjustmentService(t, env)
+
+	result, err := tripService.AdjustFare(context.Background(), service.AdjustFareRequest{
+		TripID: "trip-1",
+		Fare:   18.25,
+		Reason: "  driver took a longer route  ",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	payment := result.Payment
+	if payment == nil || payment.Amount != 6.75 || payment.Status != domain.PaymentStatusRefunded {
+		t.Fatalf("expected a refund of 6.75, got %+v", payment)
+	}
+	if len(env.psp.Refunds) != 1 || env.psp.Refunds[0] != 6.75 {
+		t.Errorf("expected 6.75 refunded through the PSP, got %v", env.psp.Refunds)
+	}
+	if got := env.psp.ChargeCallCount; got != 1 {
+		t.Errorf("expected no further charge, got %d charges", got)
+	}
+	if stored, _ := env.payments.GetByID(context.Background(), payment.ID); stored == nil || stored.Status != domain.PaymentStatusRefunded {
+		t.Errorf("expected the refund stored as REFUNDED, got %+v", stored)
+	}
+
+	if result.Trip.Fare != 18.25 || result.Adjustment.Difference() != -6.75 {
+		t.Errorf("expected fare 18.25 (-6.75), got %.2f and %+v", result.Trip.Fare, result.Adjustment)
+	}
+	if r := result.Receipt; r == nil || r.TotalFare != 18.25 || r.AdjustedFrom != 25 || r.AdjustmentReason != "driver took a longer route" {
+		t.Errorf("expected a receipt for 18.25 adjusted from 25.00, got %+v", r)
+	}
+	if text := service.NewReceiptService(nil, nil, nil, nil, nil).FormatReceipt(result.Receipt); !strings.Contains(text, "Adjusted from $25.00: driver took a longer route") {
+		t.Errorf("expected the adjustment on the printed receipt, got:\n%s", text)
+	}
+
+	recorded := recordedAdjustments(env)
+	if len(recorded) != 1 || recorded[0].PaymentID != payment.ID || recorded[0].Fare != 18.25 {
+		t.Errorf("expected the adjustment recorded with its refund, got %+v", recorded)
+	}
+
+	assertReconciled(t, env)
+}
+
+func TestFareAdjustment_FailedRefundRecorded(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	tripService := newFareAdjustmentService(t, env)
+	env.psp.RefundError = &service.PaymentDeclinedError{Reason: "card closed"}
+
+	result, err := tripService.AdjustFare(context.Background(), service.AdjustFareRequest{TripID: "trip-1", Fare: 20, Reason: "wrong tier"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p := result.Payment; p == nil || p.Status != domain.PaymentStatusFailed || p.FailureReason != "card closed" {
+		t.Errorf("expected a FAILED refund with the PSP's reason, got %+v", p)
+	}
+	if result.Trip.Fare != 20 {
+		t.Errorf("expected the fare corrected regardless, got %.2f", result.Trip.Fare)
+	}
+	if recorded := recordedAdjustments(env); len(recorded) != 1 || recorded[0].PaymentID != result.Payment.ID {
+		t.Errorf("expected the adjustment recorded with its failed refund, got %+v", recorded)
+	}
+}
+
+func TestFareAdjustment_Rejected(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		prepare func(env *testEnv)
+		req     service.AdjustFareRequest
+		wantErr error
+	}{
+		{
+			name:    "zero fare",
+			req:     service.AdjustFareRequest{TripID: "trip-1", Fare: 0, Reason: "waived"},
+			wantErr: service.ErrInvalidFare,
+		},
+		{
+			name:    "no reason",
+			req:     service.AdjustFareRequest{TripID: "trip-1", Fare: 20, Reason: "   "},
+			wantErr: service.ErrAdjustmentReasonRequired,
+		},
+		{
+			name:    "same fare",
+			req:     service.AdjustFareRequest{TripID: "trip-1", Fare: 25.001, Reason: "recheck"},
+			wantErr: service.ErrFareUnchanged,
+		},
+		{
+			name: "trip in progress",
+			prepare: func(env *testEnv) {
+				trip, _ := env.trips.GetByID(context.Background(), "trip-1")
+				trip.Status = domain.TripStatusStarted
+				_ = env.trips.Update(context.Background(), trip)
+			},
+			req:     service.AdjustFareRequest{TripID: "trip-1", Fare: 20, Reason: "wrong route"},
+			wantErr: service.ErrTripNotEnded,
+		},
+		{
+			name: "fare held for review",
+			prepare: func(env *testEnv) {
+				trip, _ := env.trips.GetByID(context.Background(), "trip-1")
+				trip.NeedsReview = true
+				_ = env.trips.Update(context.Background(), trip)
+			},
+			req:     service.AdjustFareRequest{TripID: "trip-1", Fare: 20, Reason: "wrong route"},
+			wantErr: service.ErrTripUnderReview,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			env := newTestEnv(t)
+			tripService := newFareAdjustmentService(t, env)
+			if tt.prepare != nil {
+				tt.prepare(env)
+			}
+
+			if _, err := tripService.AdjustFare(context.Background(), tt.req); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if got := env.psp.ChargeCallCount; got != 1 || len(env.psp.Refunds) != 0 {
+				t.Errorf("expected no money moved, got %d charges and refunds %v", got, env.psp.Refunds)
+			}
+			if recorded := recordedAdjustments(env); len(recorded) != 0 || storedFare(env) != 0 {
+				t.Errorf("expected nothing recorded, got %+v", recorded)
+			}
+		})
+	}
+}
+
+func TestFareAdjustment_RequiresAdminToken(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	router := newFareAdjustmentRouter(newFareAdjustmentService(t, env))
+
+	for _, token := range []string{"", "wrong"} {
+		if w := adjustFare(router, `{"fare": 30, "reason": "toll"}`, token); w.Code != http.StatusUnauthorized {
+			t.Errorf("token %q: expected 401, got %d", token, w.Code)
+		}
+	}
+	if w := adjustFare(router, `{"fare": 30}`, testAdminToken); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 without a reason, got %d", w.Code)
+	}
+
+	if fare := storedFare(env); fare != 0 || env.psp.ChargeCallCount != 1 {
+		t.Errorf("expected the fare untouched, got %.2f stored after %d charges", fare, env.psp.ChargeCallCount)
+	}
+}
+
+func TestFareAdjustment_DownwardReducesUncollectedFare(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		method domain.PaymentMethod
+		status domain.PaymentStatus
+	}{
+		{"declined card", domain.PaymentMethodCard, domain.PaymentStatusFailed},
+		{"cash not yet collected", domain.PaymentMethodCash, domain.PaymentStatusCashDue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			env := newTestEnv(t)
+			tripService := newFareAdjustmentService(t, env)
+			env.rides.GetRide("ride-1").PaymentMethod = tt.method
+			fare := env.payments.GetPaymentByTripID("trip-1")
+			_ = env.payments.MarkFailed(context.Background(), fare.ID, domain.PaymentFailureDeclined)
+			if tt.status != domain.PaymentStatusFailed {
+				_ = env.payments.UpdateStatus(context.Background(), fare.ID, tt.status)
+			}
+
+			result, err := tripService.AdjustFare(context.Background(), service.AdjustFareRequest{TripID: "trip-1", Fare: 18.25, Reason: "longer route"})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(env.psp.Refunds) != 0 {
+				t.Errorf("expected nothing refunded on a fare never collected, got %v", env.psp.Refunds)
+			}
+			if p := result.Payment; p == nil || p.ID != fare.ID || p.Amount != 18.25 || p.Status != tt.status {
+				t.Errorf("expected the %s fare payment reduced to 18.25, got %+v", tt.status, p)
+			}
+			if stored, _ := env.payments.GetByID(context.Background(), fare.ID); stored.Amount != 18.25 || stored.Status != tt.status {
+				t.Errorf("expected 18.25 still owed, got %.2f %s", stored.Amount, stored.Status)
+			}
+			if got := env.payments.CountPayments(); got != 1 {
+				t.Errorf("expected no refund payment recorded, got %d payments", got)
+			}
+			if recorded := recordedAdjustments(env); len(recorded) != 1 || recorded[0].PaymentID != fare.ID {
+				t.Errorf("expected the adjustment recorded against the fare payment, got %+v", recorded)
+			}
+		})
+	}
+}
+
+func TestFareAdjustment_UnrecordedAdjustmentMovesNoMoney(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	tripService := newFareAdjustmentService(t, env)
+	env.rec.Respond = nil // The audit row cannot be written
+
+	if _, err := tripService.AdjustFare(context.Background(), service.AdjustFareRequest{TripID: "trip-1", Fare: 18.25, Reason: "longer route"}); err == nil {
+		t.Fatal("expected the failed audit write returned")
+	}
+	if got := env.psp.ChargeCallCount; got != 1 || len(env.psp.Refunds) != 0 {
+		t.Errorf("expected no money moved, got %d charges and refunds %v", got, env.psp.Refunds)
+	}
+	if got := env.payments.CountPayments(); got != 1 {
+		t.Errorf("expected no payment for the adjustment, got %d payments", got)
+	}
+}
+
+func TestFareAdjustment_DownwardRefundsSplitFareByShare(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name     string
+		absorbed bool
+		want     map[string]float64 // Refunded, by instrument
+	}{
+		{"each rider paid", false, map[string]float64{"card-rider-1": 10, "card-rider-2": 10}},
+		// rider-1 paid rider-2's share too, so gets it all back.
+		{"share absorbed", true, map[string]float64{"card-rider-1": 20}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			env := newTestEnv(t)
+			seedAdjustableTrip(env)
+			split := &domain.FareSplit{TripID: "trip-1", OwnerID: "rider-1", Shares: []domain.FareShare{
+				{RiderID: "rider-1", Amount: 12.5}, {RiderID: "rider-2", Amount: 12.5, Absorbed: tc.absorbed},
+			}}
+			if tc.absorbed {
+				split.Shares[0].PaymentID = payFare(t, env, 25, "").ID
+			} else {
+				split.Shares[0].PaymentID = payFare(t, env, 12.5, "").ID
+				split.Shares[1].PaymentID = payFare(t, env, 12.5, "split:rider-2").ID
+			}
+			splits := NewMockFareSplitRepository()
+			_ = splits.Save(context.Background(), split)
+			deps := fareAdjustmentDeps(env)
+			deps.FareSplits = splits
+
+			result, err := service.NewTripService(deps).AdjustFare(context.Background(), service.AdjustFareRequest{
+				TripID: "trip-1", Fare: 5, Reason: "wrong route",
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			refunded := env.payments.Refunded("trip-1")
+			if len(refunded) != len(tc.want) {
+				t.Fatalf("expected refunds %v, got %v", tc.want, refunded)
+			}
+			for instrumentID, amount := range tc.want {
+				if refunded[instrumentID] != amount {
+					t.Errorf("expected %.2f refunded to %s, got %v", amount, instrumentID, refunded)
+				}
+			}
+			if p := result.Payment; p == nil || p.InstrumentID != "card-rider-1" || p.Status != domain.PaymentStatusRefunded {
+				t.Errorf("expected the ride's rider's refund recorded on the adjustment, got %+v", p)
+			}
+			assertReconciled(t, env)
+		})
+	}
+}
+
+func TestFareAdjustment_SplitRefundCappedAtCollected(t *testing.T) {
+	t.Parallel()
+
+	// rider-2's share payment was only partly collected; the rest of their
+	// part of the refund goes back to rider-1, who covered it.
+	env := newTestEnv(t)
+	seedAdjustableTrip(env)
+	split := &domain.FareSplit{TripID: "trip-1", OwnerID: "rider-1", Shares: []domain.FareShare{
+		{RiderID: "rider-1", Amount: 12.5, PaymentID: payFare(t, env, 21, "").ID},
+		{RiderID: "rider-2", Amount: 12.5, PaymentID: payFare(t, env, 4, "split:rider-2").ID},
+	}}
+	splits := NewMockFareSplitRepository()
+	_ = splits.Save(context.Background(), split)
+	deps := fareAdjustmentDeps(env)
+	deps.FareSplits = splits
+
+	if _, err := service.NewTripService(deps).AdjustFare(context.Background(), service.AdjustFareRequest{
+		TripID: "trip-1", Fare: 5, Reason: "wrong route",
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	refunded := env.payments.Refunded("trip-1")
+	if refunded["card-rider-2"] != 4 || refunded["card-rider-1"] != 16 {
+		t.Errorf("expected 4.00 to rider-2 and 16.00 to rider-1, got %v", refunded)
+	}
+}
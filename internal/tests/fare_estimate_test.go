@@ -0,0 +1,164 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/middleware"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// ESTIMATED FARE RANGE
+// ──────────────────────────────────────────────
+
+func TestFareEstimate_RecordedAndReturned(t *testing.T) {
+	t.Parallel()
+
+	rideRepo := NewMockRideRepository()
+	rideService := service.NewRideService(service.RideServiceDeps{RideRepo: rideRepo, MatchingService: NewMockMatchingServiceForTest()})
+	rideHandler := handler.NewRideHandler(rideService, nil, rideRepo, nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.IdentityMiddleware(testAdminToken))
+	router.POST("/v1/rides", rideHandler.CreateRide)
+	router.GET("/v1/rides/:id", rideHandler.GetRide)
+
+	body, _ := json.Marshal(handler.CreateRideRequest{RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 12.3, DestinationLng: 76.64})
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/rides", bytes.NewReader(body)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var created handler.CreateRideResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &created)
+
+	if created.EstimatedFareLow <= 0 || created.EstimatedFareHigh <= created.EstimatedFareLow {
+		t.Fatalf("expected a fare range, got %.2f-%.2f", created.EstimatedFareLow, created.EstimatedFareHigh)
+	}
+
+	stored := rideRepo.GetRide(created.ID)
+	if stored.EstimateLow != created.EstimatedFareLow || stored.EstimateHigh != created.EstimatedFareHigh {
+		t.Errorf("expected the range persisted, got %.2f-%.2f", stored.EstimateLow, stored.EstimateHigh)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/rides/"+created.ID, nil)
+	req.Header.Set("X-User-ID", "rider-1")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var got handler.GetRideResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &got)
+	if got.EstimatedFareLow != created.EstimatedFareLow || got.EstimatedFareHigh != created.EstimatedFareHigh {
+		t.Errorf("expected GET to return %.2f-%.2f, got %.2f-%.2f",
+			created.EstimatedFareLow, created.EstimatedFareHigh, got.EstimatedFareLow, got.EstimatedFareHigh)
+	}
+}
+
+func TestFareEstimate_PricedLikeTheFare(t *testing.T) {
+	t.Parallel()
+
+	fares := service.NewFareSchedule(domain.DefaultCatalog(), 0)
+	duration := 30 * time.Minute
+
+	low, high := fares.EstimateRange(domain.DriverTierBasic, duration, 1.0, 0)
+	fare := fares.Fare(domain.DriverTierBasic, duration, 1.0, 0)
+	if fare < low || fare > high {
+		t.Errorf("expected the fare for the estimated duration (%.2f) within %.2f-%.2f", fare, low, high)
+	}
+
+	surgedLow, surgedHigh := fares.EstimateRange(domain.DriverTierBasic, duration, 2.0, 3.5)
+	if math.Abs(surgedLow-(2*low+3.5)) > 0.02 || math.Abs(surgedHigh-(2*high+3.5)) > 0.02 {
+		t.Errorf("expected surge and surcharge applied to the range, got %.2f-%.2f from %.2f-%.2f",
+			surgedLow, surgedHigh, low, high)
+	}
+}
+
+// ──────────────────────────────────────────────
+// ESTIMATE ACCURACY REPORT
+// ──────────────────────────────────────────────
+
+// seedEstimatedTrip adds an ENDED trip whose ride was quoted low-high.
+func seedEstimatedTrip(tripRepo *MockTripRepository, rideRepo *MockRideRepository, id string, low, high, fare float64, endedAt time.Time, needsReview bool) {
+	rideRepo.AddRide(&domain.Ride{
+		ID:           "ride-" + id,
+		RiderID:      "rider-1",
+		Status:       domain.RideStatusCompleted,
+		EstimateLow:  low,
+		EstimateHigh: high,
+	})
+	_ = tripRepo.Create(context.Background(), &domain.Trip{
+		ID:          id,
+		RideID:      "ride-" + id,
+		DriverID:    "driver-1",
+		Status:      domain.TripStatusEnded,
+		Fare:        fare,
+		StartedAt:   endedAt.Add(-20 * time.Minute),
+		EndedAt:     endedAt,
+		NeedsReview: needsReview,
+	})
+}
+
+func TestEstimateAccuracy_Report(t *testing.T) {
+	t.Parallel()
+
+	tripRepo := NewMockTripRepository()
+	rideRepo := NewMockRideRepository()
+	reportService := service.NewReportService(NewMockReportRepository(tripRepo, rideRepo, NewMockPaymentRepository()))
+
+	// Quoted 10-14, so the midpoint is 12.
+	noon := reportDay.Add(12 * time.Hour)
+	seedEstimatedTrip(tripRepo, rideRepo, "trip-exact", 10, 14, 12, noon, false) // 0%
+	seedEstimatedTrip(tripRepo, rideRepo, "trip-low", 10, 14, 10, noon, false)   // 20%
+	seedEstimatedTrip(tripRepo, rideRepo, "trip-over", 10, 14, 15, noon, false)  // 20%
+	seedEstimatedTrip(tripRepo, rideRepo, "trip-under", 10, 14, 8, noon, false)  // 50%
+
+	// Left out: held for review, no estimate, ended the day before.
+	seedEstimatedTrip(tripRepo, rideRepo, "trip-review", 10, 14, 60, noon, true)
+	seedEstimatedTrip(tripRepo, rideRepo, "trip-unquoted", 0, 0, 30, noon, false)
+	seedEstimatedTrip(tripRepo, rideRepo, "trip-yesterday", 10, 14, 40, reportDay.Add(-time.Minute), false)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/v1/admin/reports/estimate-accuracy", handler.NewReportHandler(reportService).GetEstimateAccuracy)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/admin/reports/estimate-accuracy?date=2026-03-14", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp handler.EstimateAccuracyResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+
+	want := handler.EstimateAccuracyResponse{
+		Date:        "2026-03-14",
+		Trips:       4,
+		WithinRange: 2,
+		BelowRange:  1,
+		AboveRange:  1,
+		ErrorP50:    20,
+		ErrorP90:    41,
+		ErrorP99:    49.1,
+	}
+	if resp != want {
+		t.Errorf("expected %+v, got %+v", want, resp)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/admin/reports/estimate-accuracy", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 without a date, got %d", w.Code)
+	}
+}
@@ -0,0 +1,179 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// FARE CAPS AND REVIEW
+// ──────────────────────────────────────────────
+
+const testMaxFare = 100.0
+
+// newFareReviewService adds a card ride in trip with driver-1 and returns a
+// trip service capping fares at testMaxFare.
+func newFareReviewService(env *testEnv) *service.TripService {
+	env.rides.AddRide(&domain.Ride{
+		ID: "ride-1", RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 12.3, DestinationLng: 76.64,
+		Status: domain.RideStatusInTrip, AssignedDriverID: "driver-1", SurgeMultiplier: 1,
+		PaymentMethod: domain.PaymentMethodCard, Version: 2,
+	})
+
+	deps := env.tripDeps()
+	deps.MaxFare = testMaxFare
+	return service.NewTripService(deps)
+}
+
+// startReviewTrip records a trip that started the given duration ago.
+func startReviewTrip(env *testEnv, elapsed time.Duration) {
+	_ = env.trips.Create(context.Background(), &domain.Trip{
+		ID: "trip-1", RideID: "ride-1", DriverID: "driver-1", Status: domain.TripStatusStarted,
+		StartedAt: time.Now().Add(-elapsed), Version: 1,
+	})
+}
+
+// holdReviewTrip records a trip that ended with its fare capped and held for
+// review.
+func holdReviewTrip(env *testEnv) {
+	endedAt := time.Now()
+	_ = env.trips.Create(context.Background(), &domain.Trip{
+		ID: "trip-1", RideID: "ride-1", DriverID: "driver-1", Status: domain.TripStatusEnded,
+		Fare: testMaxFare, NeedsReview: true, UncappedFare: 302,
+		StartedAt: endedAt.Add(-10 * time.Hour), EndedAt: endedAt, Version: 2,
+	})
+}
+
+func opsReviewRequests(env *testEnv) int {
+	events, _ := env.notifications.ListSince(context.Background(), service.OpsRecipientID, 0, 100)
+	count := 0
+	for _, e := range events {
+		if e.Type == string(service.NotificationFareReview) {
+			count++
+		}
+	}
+	return count
+}
+
+func TestFareReview_NormalFareCharged(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	tripService := newFareReviewService(env)
+	startReviewTrip(env, 15*time.Minute)
+
+	resp, err := tripService.EndTrip(context.Background(), service.EndTripRequest{TripID: "trip-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp.Trip.NeedsReview || resp.Trip.Fare >= testMaxFare {
+		t.Errorf("expected an uncapped fare, got %+v", resp.Trip)
+	}
+	if resp.Payment == nil || resp.Payment.Amount != resp.Trip.Fare {
+		t.Errorf("expected payment of %.2f, got %+v", resp.Trip.Fare, resp.Payment)
+	}
+	if got := env.psp.ChargeCallCount; got != 1 {
+		t.Errorf("expected one PSP charge, got %d", got)
+	}
+	if got := opsReviewRequests(env); got != 0 {
+		t.Errorf("expected no review request, got %d", got)
+	}
+}
+
+func TestFareReview_OverCapTripHeldWithoutCharge(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	tripService := newFareReviewService(env)
+	startReviewTrip(env, 10*time.Hour) // Left running overnight: about $302
+
+	resp, err := tripService.EndTrip(context.Background(), service.EndTripRequest{TripID: "trip-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !resp.Trip.NeedsReview || resp.Trip.Fare != testMaxFare || resp.Trip.UncappedFare <= testMaxFare {
+		t.Errorf("expected fare capped at %.2f and held, got %+v", testMaxFare, resp.Trip)
+	}
+	if resp.Payment != nil {
+		t.Errorf("expected no payment for a held fare, got %+v", resp.Payment)
+	}
+	if got := env.psp.ChargeCallCount; got != 0 {
+		t.Errorf("expected no PSP call, got %d", got)
+	}
+	if got := opsReviewRequests(env); got != 1 {
+		t.Errorf("expected ops asked to review once, got %d", got)
+	}
+
+	// The hold is persisted with the trip: needs_review is $11, uncapped_fare $12.
+	var persisted bool
+	for _, q := range env.rec.Queries() {
+		if strings.Contains(q.Query, "UPDATE trips") {
+			persisted = q.Args[3] == testMaxFare && q.Args[10] == true && q.Args[11] == resp.Trip.UncappedFare
+		}
+	}
+	if !persisted {
+		t.Error("expected the capped fare and review flag in the trip update")
+	}
+}
+
+func TestFareReview_ApprovalChargesAdjustedFare(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	tripService := newFareReviewService(env)
+	holdReviewTrip(env)
+
+	router := newTestRouter()
+	router.POST("/v1/admin/trips/:id/approve-fare", handler.NewTripHandler(tripService, nil).ApproveFare)
+
+	approve := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/admin/trips/trip-1/approve-fare", bytes.NewBufferString(body)))
+		return w
+	}
+
+	if w := approve(`{"fare": 0}`); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a zero fare, got %d", w.Code)
+	}
+
+	w := approve(`{"fare": 42.5}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	payment := env.payments.GetPaymentByTripID("trip-1")
+	if payment == nil || payment.Amount != 42.5 || payment.Status != domain.PaymentStatusSuccess {
+		t.Fatalf("expected successful payment of 42.50, got %+v", payment)
+	}
+	if got := env.psp.ChargeCallCount; got != 1 {
+		t.Errorf("expected one PSP charge, got %d", got)
+	}
+
+	trip, _ := env.trips.GetByID(context.Background(), "trip-1")
+	if trip.NeedsReview || trip.Fare != 42.5 {
+		t.Errorf("expected trip released at the approved fare, got %+v", trip)
+	}
+
+	// A second approval finds nothing to review.
+	if w := approve(`{"fare": 42.5}`); w.Code != http.StatusConflict {
+		t.Errorf("expected 409 on repeated approval, got %d", w.Code)
+	}
+	if _, err := tripService.ApproveFare(context.Background(), service.ApproveFareRequest{TripID: "trip-1", Fare: 42.5}); !errors.Is(err, service.ErrTripNotUnderReview) {
+		t.Errorf("expected ErrTripNotUnderReview, got %v", err)
+	}
+	if got := env.psp.ChargeCallCount; got != 1 {
+		t.Errorf("expected no further charges, got %d", got)
+	}
+}
@@ -0,0 +1,364 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/middleware"
+	"ride/internal/repository"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// FARE SPLITS
+// ──────────────────────────────────────────────
+
+func TestSplitAmount_SharesSumToTotal(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		total   float64
+		weights []float64
+		want    []float64
+	}{
+		{"odd cent goes to the first rider", 10.00, []float64{0, 0, 0}, []float64{3.34, 3.33, 3.33}},
+		{"two odd cents", 0.05, []float64{0, 0, 0}, []float64{0.02, 0.02, 0.01}},
+		{"even split", 7.00, []float64{0, 0, 0, 0}, []float64{1.75, 1.75, 1.75, 1.75}},
+		{"largest remainder wins", 10.01, []float64{1, 2}, []float64{3.34, 6.67}},
+		{"largest remainder is not always first", 1.00, []float64{1, 1, 4}, []float64{0.17, 0.16, 0.67}},
+		{"less than a cent each", 0.01, []float64{0, 0}, []float64{0.01, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := domain.SplitAmount(tt.total, tt.weights)
+			var sum float64
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("expected %v, got %v", tt.want, got)
+					break
+				}
+				sum += got[i]
+			}
+			if math.Round(sum*100) != math.Round(tt.total*100) {
+				t.Errorf("expected shares summing to %.2f, got %.2f", tt.total, sum)
+			}
+		})
+	}
+}
+
+// decliningPSP declines charges to one instrument token.
+type decliningPSP struct {
+	*MockPSP
+	declined string
+}
+
+func (p *decliningPSP) Charge(ctx context.Context, token string, amount float64) (bool, error) {
+	if token == p.declined {
+		return false, nil
+	}
+	return p.MockPSP.Charge(ctx, token, amount)
+}
+
+// newFareSplitService has rider-1's card ride in trip-1, started 15 minutes
+// ago. rider-1 to rider-3 have a card on file, tokened "tok-" plus their ID;
+// charges to declinedToken are declined.
+func newFareSplitService(env *testEnv, splits *MockFareSplitRepository, declinedToken string) *service.TripService {
+	env.rides.AddRide(&domain.Ride{
+		ID: "ride-1", RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 12.93, DestinationLng: 77.62,
+		Status: domain.RideStatusInTrip, AssignedDriverID: "driver-1", SurgeMultiplier: 1,
+		PaymentMethod: domain.PaymentMethodCard, InstrumentID: "card-rider-1", Version: 2,
+	})
+	_ = env.trips.Create(context.Background(), &domain.Trip{
+		ID: "trip-1", RideID: "ride-1", DriverID: "driver-1", Status: domain.TripStatusStarted,
+		StartedAt: time.Now().Add(-15 * time.Minute), Version: 1,
+	})
+
+	instruments := NewMockPaymentInstrumentRepository()
+	for _, rider := range []string{"rider-1", "rider-2", "rider-3"} {
+		_ = instruments.Create(context.Background(), &domain.PaymentInstrument{
+			ID: "card-" + rider, UserID: rider, Type: domain.PaymentMethodCard, Token: "tok-" + rider, IsDefault: true,
+		})
+	}
+
+	psp := &decliningPSP{MockPSP: env.psp, declined: declinedToken}
+	deps := env.tripDeps()
+	deps.PaymentService = service.NewPaymentService(env.payments, psp, instruments, env.events, nil, 0)
+	deps.ReceiptService = service.NewReceiptService(deps.NotificationService, nil, nil, nil, nil)
+	deps.FareSplits = splits
+	return service.NewTripService(deps)
+}
+
+func splitFare(t *testing.T, tripService *service.TripService, participants ...service.SplitParticipant) *domain.FareSplit {
+	t.Helper()
+
+	split, err := tripService.SplitFare(context.Background(), service.SplitFareRequest{
+		TripID: "trip-1", RiderID: "rider-1", Participants: participants,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return split
+}
+
+func endSplitTrip(t *testing.T, tripService *service.TripService) *service.EndTripResponse {
+	t.Helper()
+
+	resp, err := tripService.EndTrip(context.Background(), service.EndTripRequest{TripID: "trip-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return resp
+}
+
+// markEnded records trip-1 as ended, as EndTrip does through the database.
+func markEnded(env *testEnv) {
+	trip, _ := env.trips.GetByID(context.Background(), "trip-1")
+	trip.Status, trip.EndedAt = domain.TripStatusEnded, time.Now()
+	_ = env.trips.Update(context.Background(), trip)
+}
+
+// receiptsFor returns how many receipt-ready notifications the rider got.
+func receiptsFor(env *testEnv, riderID string) int {
+	events, _ := env.notifications.ListSince(context.Background(), riderID, 0, 100)
+	count := 0
+	for _, e := range events {
+		if e.Type == string(service.NotificationReceiptReady) {
+			count++
+		}
+	}
+	return count
+}
+
+func riders(ids ...string) []service.SplitParticipant {
+	participants := make([]service.SplitParticipant, len(ids))
+	for i, id := range ids {
+		participants[i] = service.SplitParticipant{RiderID: id}
+	}
+	return participants
+}
+
+func TestFareSplit_EachRiderChargedTheirShare(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	splits := NewMockFareSplitRepository()
+	tripService := newFareSplitService(env, splits, "")
+	splitFare(t, tripService, riders("rider-1", "rider-2", "rider-3")...)
+	resp := endSplitTrip(t, tripService)
+
+	if resp.Split == nil || len(resp.Split.Shares) != 3 {
+		t.Fatalf("expected the split settled, got %+v", resp.Split)
+	}
+	var sum float64
+	for _, share := range resp.Split.Shares {
+		if share.Absorbed || share.PaymentID == "" {
+			t.Errorf("expected %s's share paid, got %+v", share.RiderID, share)
+		}
+		key := "payment:trip-1:split:" + share.RiderID
+		if share.RiderID == "rider-1" {
+			key = "payment:trip-1"
+		}
+		payment, _ := env.payments.GetByIdempotencyKey(context.Background(), key)
+		if payment == nil || payment.ID != share.PaymentID || payment.Amount != share.Amount ||
+			payment.Status != domain.PaymentStatusSuccess || payment.InstrumentID != "card-"+share.RiderID {
+			t.Errorf("expected %s charged %.2f to their card under %s, got %+v", share.RiderID, share.Amount, key, payment)
+		}
+		if diff := share.Amount - resp.Trip.Fare/3; diff > 0.01 || diff < -0.01 {
+			t.Errorf("expected a third of %.2f, got %.2f", resp.Trip.Fare, share.Amount)
+		}
+		sum += share.Amount
+	}
+	if math.Round(sum*100) != math.Round(resp.Trip.Fare*100) {
+		t.Errorf("expected shares summing to the fare %.2f, got %.2f", resp.Trip.Fare, sum)
+	}
+	if env.payments.CountPayments() != 3 {
+		t.Errorf("expected 3 payments, got %d", env.payments.CountPayments())
+	}
+
+	if resp.Payment == nil || resp.Payment.ID != resp.Split.Shares[0].PaymentID {
+		t.Errorf("expected the ride's rider's payment in the response, got %+v", resp.Payment)
+	}
+	if r := resp.Receipt; r == nil || r.RiderID != "rider-1" || r.SplitRiders != 3 ||
+		r.ShareAmount != resp.Split.Shares[0].Amount || r.TotalFare != r.ShareAmount {
+		t.Errorf("expected the ride's rider's receipt for their share, got %+v", r)
+	}
+	for _, rider := range []string{"rider-1", "rider-2", "rider-3"} {
+		if got := receiptsFor(env, rider); got != 1 {
+			t.Errorf("expected a receipt for %s, got %d", rider, got)
+		}
+	}
+
+	stored, _ := splits.GetByTripID(context.Background(), "trip-1")
+	if stored == nil || stored.Shares[1].PaymentID != resp.Split.Shares[1].PaymentID {
+		t.Errorf("expected the settled split stored, got %+v", stored)
+	}
+}
+
+func TestFareSplit_WeightedShares(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	splits := NewMockFareSplitRepository()
+	tripService := newFareSplitService(env, splits, "")
+	splitFare(t, tripService, service.SplitParticipant{RiderID: "rider-1", Share: 1}, service.SplitParticipant{RiderID: "rider-2", Share: 3})
+	resp := endSplitTrip(t, tripService)
+
+	owner, other := resp.Split.Shares[0], resp.Split.Shares[1]
+	if math.Round((owner.Amount+other.Amount)*100) != math.Round(resp.Trip.Fare*100) {
+		t.Errorf("expected shares summing to the fare %.2f, got %+v", resp.Trip.Fare, resp.Split.Shares)
+	}
+	if diff := other.Amount - resp.Trip.Fare*0.75; diff > 0.01 || diff < -0.01 {
+		t.Errorf("expected rider-2 to pay three quarters of %.2f, got %.2f", resp.Trip.Fare, other.Amount)
+	}
+}
+
+func TestFareSplit_FailedShareChargedToOwner(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	splits := NewMockFareSplitRepository()
+	tripService := newFareSplitService(env, splits, "tok-rider-2")
+	splitFare(t, tripService, riders("rider-1", "rider-2", "rider-3")...)
+	resp := endSplitTrip(t, tripService)
+
+	owner, declined, paid := resp.Split.Shares[0], resp.Split.Shares[1], resp.Split.Shares[2]
+	if !declined.Absorbed || owner.Absorbed || paid.Absorbed {
+		t.Fatalf("expected only rider-2's share absorbed, got %+v", resp.Split.Shares)
+	}
+
+	// rider-3 is not held up by rider-2's card.
+	payment, _ := env.payments.GetByID(context.Background(), paid.PaymentID)
+	if payment == nil || payment.Status != domain.PaymentStatusSuccess {
+		t.Errorf("expected rider-3's share paid, got %+v", payment)
+	}
+	payment, _ = env.payments.GetByID(context.Background(), declined.PaymentID)
+	if payment == nil || payment.Status != domain.PaymentStatusFailed {
+		t.Errorf("expected rider-2's payment FAILED, got %+v", payment)
+	}
+
+	want := math.Round((owner.Amount+declined.Amount)*100) / 100
+	if resp.Payment == nil || resp.Payment.Amount != want || resp.Payment.Status != domain.PaymentStatusSuccess {
+		t.Errorf("expected the ride's rider charged %.2f, got %+v", want, resp.Payment)
+	}
+	if r := resp.Receipt; r == nil || r.AbsorbedAmount != declined.Amount || r.TotalFare != want {
+		t.Errorf("expected the ride's rider's receipt to show the covered share, got %+v", r)
+	}
+
+	stored, _ := splits.GetByTripID(context.Background(), "trip-1")
+	if stored == nil || !stored.Share("rider-2").Absorbed {
+		t.Errorf("expected rider-2's share flagged for follow-up, got %+v", stored)
+	}
+}
+
+func TestFareSplit_Rejected(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		riderID      string
+		participants []service.SplitParticipant
+		setup        func(env *testEnv)
+		want         error
+	}{
+		{"not the ride's rider", "rider-2", riders("rider-1", "rider-2"), nil, repository.ErrNotFound},
+		{"ride's rider left out", "rider-1", riders("rider-2", "rider-3"), nil, service.ErrInvalidFareSplit},
+		{"one rider", "rider-1", riders("rider-1"), nil, service.ErrInvalidFareSplit},
+		{"repeated rider", "rider-1", riders("rider-1", "rider-2", "rider-2"), nil, service.ErrInvalidFareSplit},
+		{"blank rider", "rider-1", riders("rider-1", ""), nil, service.ErrInvalidFareSplit},
+		{"weighted and equal shares mixed", "rider-1",
+			[]service.SplitParticipant{{RiderID: "rider-1", Share: 2}, {RiderID: "rider-2"}}, nil, service.ErrInvalidFareSplit},
+		{"negative share", "rider-1",
+			[]service.SplitParticipant{{RiderID: "rider-1", Share: 2}, {RiderID: "rider-2", Share: -1}}, nil, service.ErrInvalidFareSplit},
+		{"rider without a card", "rider-1", riders("rider-1", "rider-4"), nil, service.ErrNoPaymentInstrument},
+		{"cash ride", "rider-1", riders("rider-1", "rider-2"), func(env *testEnv) {
+			ride, _ := env.rides.GetByID(context.Background(), "ride-1")
+			ride.PaymentMethod, ride.InstrumentID = domain.PaymentMethodCash, ""
+			_ = env.rides.Update(context.Background(), ride)
+		}, service.ErrFareSplitNotAllowed},
+		{"trip ended", "rider-1", riders("rider-1", "rider-2"), markEnded, service.ErrTripAlreadyEnded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			splits := NewMockFareSplitRepository()
+			tripService := newFareSplitService(env, splits, "")
+			if tt.setup != nil {
+				tt.setup(env)
+			}
+			_, err := tripService.SplitFare(context.Background(), service.SplitFareRequest{
+				TripID: "trip-1", RiderID: tt.riderID, Participants: tt.participants,
+			})
+			if !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+			if split, _ := splits.GetByTripID(context.Background(), "trip-1"); split != nil {
+				t.Errorf("expected no split stored, got %+v", split)
+			}
+		})
+	}
+}
+
+func TestFareSplit_HTTP(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	splits := NewMockFareSplitRepository()
+	tripService := newFareSplitService(env, splits, "")
+	router := newTestRouter()
+	router.Use(middleware.IdentityMiddleware(testAdminToken))
+	h := handler.NewTripHandler(tripService, nil)
+	router.POST("/v1/trips/:id/split", h.SplitFare)
+	router.POST("/v1/trips/:id/end", h.EndTrip)
+
+	post := func(path, userID, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User-ID", userID)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	body := `{"riders": [{"rider_id": "rider-1"}, {"rider_id": "rider-2"}]}`
+	if w := post("/v1/trips/trip-1/split", "rider-2", body); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for another rider, got %d", w.Code)
+	}
+	if w := post("/v1/trips/trip-1/split", "rider-1", `{"riders": [{"rider_id": "rider-1"}]}`); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a split with one rider, got %d", w.Code)
+	}
+
+	w := post("/v1/trips/trip-1/split", "rider-1", body)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var split handler.FareSplitInfo
+	if err := json.Unmarshal(w.Body.Bytes(), &split); err != nil || split.OwnerID != "rider-1" || len(split.Shares) != 2 {
+		t.Fatalf("expected the split, got %s", w.Body.String())
+	}
+
+	w = post("/v1/trips/trip-1/end", "driver-1", "")
+	var trip handler.TripResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &trip); err != nil || trip.Split == nil || trip.Receipt == nil {
+		t.Fatalf("expected the settled split in the response, got %s", w.Body.String())
+	}
+	if trip.Split.Shares[0].Amount+trip.Split.Shares[1].Amount == 0 || trip.Receipt.SplitRiders != 2 {
+		t.Errorf("expected each share settled, got %s", w.Body.String())
+	}
+
+	markEnded(env)
+	if w := post("/v1/trips/trip-1/split", "rider-1", body); w.Code != http.StatusConflict {
+		t.Errorf("expected 409 once the trip has ended, got %d", w.Code)
+	}
+}
@@ -0,0 +1,415 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	goredis "github.com/redis/go-redis/v9"
+
+	"ride/internal/domain"
+	"ride/internal/faults"
+	"ride/internal/handler"
+	"ride/internal/middleware"
+	"ride/internal/redis"
+	"ride/internal/repository/postgres"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// FAULT INJECTION
+// ──────────────────────────────────────────────
+
+// newFaultedDB returns a RecordingDB whose connections inject injector's
+// Postgres faults.
+func newFaultedDB(t *testing.T, injector *faults.Injector) (*sql.DB, *RecordingDB) {
+	t.Helper()
+
+	unwrapped, rec := NewRecordingDB()
+	_ = unwrapped.Close()
+	db := sql.OpenDB(injector.Connector(rec))
+	t.Cleanup(func() { _ = db.Close() })
+	return db, rec
+}
+
+// newFaultedRedis returns a client with injector's hook and no server behind
+// it, so only commands that a fault fails before dialing are meaningful.
+func newFaultedRedis(t *testing.T, injector *faults.Injector) *goredis.Client {
+	t.Helper()
+
+	client := goredis.NewClient(&goredis.Options{Addr: "127.0.0.1:0", MaxRetries: -1})
+	client.AddHook(injector.RedisHook())
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
+
+// chargeFunc adapts a function to service.PSP. Authorizations go through
+// the function as charges; captures and voids succeed.
+type chargeFunc func(ctx context.Context, token string, amount float64) (bool, error)
+
+func (f chargeFunc) Charge(ctx context.Context, token string, amount float64) (bool, error) {
+	return f(ctx, token, amount)
+}
+
+func (f chargeFunc) Authorize(ctx context.Context, token string, amount float64) (string, error) {
+	if ok, err := f(ctx, token, amount); !ok || err != nil {
+		return "", err
+	}
+	return "auth-1", nil
+}
+
+func (f chargeFunc) Capture(ctx context.Context, authID string, amount float64) error { return nil }
+
+func (f chargeFunc) Void(ctx context.Context, authID string) error { return nil }
+
+func (f chargeFunc) Refund(ctx context.Context, token string, amount float64) error { return nil }
+
+func TestFaultInjector_Set(t *testing.T) {
+	t.Parallel()
+
+	injector := faults.NewInjector()
+	invalid := []struct {
+		operation string
+		fault     faults.Fault
+	}{
+		{"mysql", faults.Fault{ErrorRate: 1}},
+		{"redis.", faults.Fault{ErrorRate: 1}},
+		{"redis", faults.Fault{ErrorRate: 1.5}},
+		{"redis", faults.Fault{ErrorRate: -0.1}},
+		{"postgres", faults.Fault{Latency: -time.Second}},
+	}
+	for _, tc := range invalid {
+		if err := injector.Set(tc.operation, tc.fault); !errors.Is(err, faults.ErrInvalidFault) {
+			t.Errorf("%s %+v: expected ErrInvalidFault, got %v", tc.operation, tc.fault, err)
+		}
+	}
+
+	_ = injector.Set("Redis.GEOADD", faults.Fault{ErrorRate: 1})
+	_ = injector.Set("postgres", faults.Fault{Latency: time.Millisecond})
+	got := injector.Faults()
+	if len(got) != 2 || got[0].Operation != "postgres" || got[1].Operation != "redis.geoadd" {
+		t.Fatalf("expected postgres and redis.geoadd faults, got %+v", got)
+	}
+
+	// The zero fault clears one operation; Clear removes the rest.
+	_ = injector.Set("redis.geoadd", faults.Fault{})
+	if got := injector.Faults(); len(got) != 1 {
+		t.Errorf("expected the geoadd fault cleared, got %+v", got)
+	}
+	injector.Clear()
+	if got := injector.Faults(); len(got) != 0 {
+		t.Errorf("expected no faults after Clear, got %+v", got)
+	}
+}
+
+func TestFaultInjector_Postgres(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	injector := faults.NewInjector()
+	db, rec := newFaultedDB(t, injector)
+
+	// Operation faults win over the store-wide one.
+	_ = injector.Set(faults.OpPostgresExec, faults.Fault{ErrorRate: 1})
+	_ = injector.Set(faults.OpPostgres, faults.Fault{Latency: 30 * time.Millisecond})
+	if _, err := db.ExecContext(ctx, "UPDATE drivers SET status = 'ONLINE'"); !errors.Is(err, faults.ErrInjected) {
+		t.Errorf("expected the exec to fail with ErrInjected, got %v", err)
+	}
+	start := time.Now()
+	if _, err := db.QueryContext(ctx, "SELECT 1"); err != nil {
+		t.Fatalf("expected the query to succeed, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("expected at least 30ms of injected latency, took %v", elapsed)
+	}
+	if n := len(rec.Queries()); n != 1 {
+		t.Errorf("expected only the query to reach the database, got %d statements", n)
+	}
+
+	// Latency gives up with the caller's context.
+	shortCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_ = injector.Set(faults.OpPostgres, faults.Fault{Latency: time.Minute})
+	if _, err := db.QueryContext(shortCtx, "SELECT 1"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the deadline to cut the latency short, got %v", err)
+	}
+
+	// A refused database drops pooled connections and fails to redial.
+	injector.Clear()
+	_ = injector.Set(faults.OpPostgres, faults.Fault{ConnectionRefused: true})
+	if _, err := db.QueryContext(ctx, "SELECT 1"); !errors.Is(err, syscall.ECONNREFUSED) {
+		t.Errorf("expected connection refused, got %v", err)
+	}
+	_, err := db.BeginTx(ctx, nil)
+	if !errors.Is(err, syscall.ECONNREFUSED) {
+		t.Errorf("expected the transaction to be refused, got %v", err)
+	}
+
+	injector.Clear()
+	if _, err := db.ExecContext(ctx, "UPDATE drivers SET status = 'ONLINE'"); err != nil {
+		t.Errorf("expected the database back after Clear, got %v", err)
+	}
+}
+
+func TestFaultInjector_ErrorRate(t *testing.T) {
+	t.Parallel()
+
+	injector := faults.NewInjector()
+	db, _ := newFaultedDB(t, injector)
+	_ = injector.Set(faults.OpPostgresQuery, faults.Fault{ErrorRate: 0.5})
+
+	failed := 0
+	for i := 0; i < 2000; i++ {
+		if _, err := db.QueryContext(context.Background(), "SELECT 1"); err != nil {
+			failed++
+		}
+	}
+	if failed < 800 || failed > 1200 {
+		t.Errorf("expected about half of 2000 queries to fail, %d did", failed)
+	}
+}
+
+func TestFaultInjector_Redis(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	injector := faults.NewInjector()
+	store := redis.NewLocationStore(newFaultedRedis(t, injector), "", nil)
+
+	// GEORADIUS without STORE is sent as its read-only variant.
+	_ = injector.Set("redis.georadius_ro", faults.Fault{Latency: 20 * time.Millisecond, ErrorRate: 1})
+	start := time.Now()
+	_, err := store.FindNearbyDrivers(ctx, 12.97, 77.59, 3, 0)
+	if !errors.Is(err, faults.ErrInjected) || redis.IsUnavailable(err) {
+		t.Errorf("expected an injected command error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("expected at least 20ms of injected latency, took %v", elapsed)
+	}
+
+	// Pipelines and transactions are refused like single commands.
+	_ = injector.Set(faults.OpRedis, faults.Fault{ConnectionRefused: true})
+	err = store.UpdateLocation(ctx, "driver-1", 12.97, 77.59, 0)
+	if !errors.Is(err, syscall.ECONNREFUSED) || !redis.IsUnavailable(err) {
+		t.Errorf("expected the update refused as unavailable, got %v", err)
+	}
+}
+
+func TestFaults_SurgeFailsOpen(t *testing.T) {
+	t.Parallel()
+
+	injector := faults.NewInjector()
+	_ = injector.Set(faults.OpRedis, faults.Fault{ConnectionRefused: true})
+
+	// Open requests with no visible supply would surge if supply were known.
+	rides := NewMockRideRepository()
+	for _, id := range []string{"ride-1", "ride-2", "ride-3"} {
+		rides.AddRide(&domain.Ride{ID: id, PickupLat: 12.97, PickupLng: 77.59, Status: domain.RideStatusRequested})
+	}
+	surge := service.NewSurgeService(redis.NewLocationStore(newFaultedRedis(t, injector), "", nil), rides, nil, 0, 0, nil, nil)
+
+	if got := surge.GetMultiplier(context.Background(), 12.97, 77.59); got != 1.0 {
+		t.Errorf("expected surge to fail open to 1.0, got %.2f", got)
+	}
+}
+
+func TestFaults_RideCreationUnderRedisFaults(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		fault    faults.Fault
+		fallback bool
+		wantCode int
+	}{
+		{"refused, degraded fallback", faults.Fault{ConnectionRefused: true}, true, http.StatusCreated},
+		{"refused, no fallback", faults.Fault{ConnectionRefused: true}, false, http.StatusServiceUnavailable},
+		{"command errors", faults.Fault{ErrorRate: 1}, true, http.StatusInternalServerError},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			injector := faults.NewInjector()
+			_ = injector.Set(faults.OpRedis, tc.fault)
+			client := newFaultedRedis(t, injector)
+			db, _ := NewRecordingDB()
+			t.Cleanup(func() { _ = db.Close() })
+
+			rides := NewMockRideRepository()
+			locations := redis.NewLocationStore(client, "", nil)
+			matcher := service.NewMatchingService(service.MatchingServiceDeps{
+				DB:               db,
+				LocationStore:    locations,
+				LockStore:        redis.NewLockStore(client, ""),
+				CacheStore:       redis.NewCacheStore(client, ""),
+				DriverRepo:       NewMockDriverRepository(),
+				RideRepo:         rides,
+				DegradedFallback: tc.fallback,
+			})
+			rideService := service.NewRideService(service.RideServiceDeps{
+				RideRepo:        rides,
+				MatchingService: matcher,
+				SurgeService:    service.NewSurgeService(locations, rides, nil, 0, 0, nil, nil),
+			})
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.POST("/v1/rides", handler.NewRideHandler(rideService, nil, rides, nil).CreateRide)
+			body := `{"rider_id":"rider-1","pickup_lat":12.97,"pickup_lng":77.59,"destination_lat":13.2,"destination_lng":77.7}`
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/rides", strings.NewReader(body)))
+
+			if w.Code != tc.wantCode {
+				t.Fatalf("expected %d, got %d: %s", tc.wantCode, w.Code, w.Body.String())
+			}
+			if tc.wantCode != http.StatusCreated {
+				return
+			}
+			var resp handler.CreateRideResponse
+			_ = json.Unmarshal(w.Body.Bytes(), &resp)
+			if resp.Status != string(domain.RideStatusRequested) || resp.SurgeMultiplier != 1.0 {
+				t.Errorf("expected an unassigned ride at 1.0x, got %+v", resp)
+			}
+		})
+	}
+}
+
+func TestFaults_LocationUpdateUnderRedisFaults(t *testing.T) {
+	t.Parallel()
+
+	for fault, wantCode := range map[faults.Fault]int{
+		{ConnectionRefused: true}: http.StatusServiceUnavailable,
+		{ErrorRate: 1}:            http.StatusInternalServerError,
+	} {
+		injector := faults.NewInjector()
+		_ = injector.Set(faults.OpRedis, fault)
+		drivers := NewMockDriverRepository()
+		drivers.AddDriver(&domain.Driver{ID: "driver-1", Status: domain.DriverStatusOnline, Tier: domain.DriverTierBasic})
+		driverService := service.NewDriverService(redis.NewLocationStore(newFaultedRedis(t, injector), "", nil), nil, drivers, nil, nil, nil, nil, 0, nil)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.Use(middleware.IdentityMiddleware(""))
+		router.POST("/v1/drivers/:id/location", handler.NewDriverHandler(driverService, nil, drivers, "US", nil, nil).UpdateLocation)
+		req := httptest.NewRequest(http.MethodPost, "/v1/drivers/driver-1/location", strings.NewReader(`{"lat":12.97,"lng":77.59,"heading":90}`))
+		req.Header.Set("X-User-ID", "driver-1")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != wantCode {
+			t.Errorf("%+v: expected %d, got %d: %s", fault, wantCode, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestFaults_PaymentUnderDatabaseOutage(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	// The database drops while the PSP call is failing: the payment still
+	// comes back FAILED rather than erroring or panicking.
+	injector := faults.NewInjector()
+	db, _ := newFaultedDB(t, injector)
+	psp := chargeFunc(func(context.Context, string, float64) (bool, error) {
+		_ = injector.Set(faults.OpPostgres, faults.Fault{ConnectionRefused: true})
+		return false, service.ErrPSPUnavailable
+	})
+	payments := service.NewPaymentService(postgres.NewPaymentRepository(db), psp, nil, nil, nil, 0)
+	payment, err := payments.ProcessPayment(ctx, service.ProcessPaymentRequest{TripID: "trip-1", Amount: 12.5, Method: domain.PaymentMethodCard})
+	if err != nil {
+		t.Fatalf("expected a FAILED payment, got error %v", err)
+	}
+	if payment.Status != domain.PaymentStatusFailed {
+		t.Errorf("expected FAILED, got %s", payment.Status)
+	}
+
+	// A successful charge that cannot be recorded is an error the caller
+	// can retry, mapped to 503 rather than 500.
+	injector = faults.NewInjector()
+	db, _ = newFaultedDB(t, injector)
+	psp = chargeFunc(func(context.Context, string, float64) (bool, error) {
+		_ = injector.Set(faults.OpPostgresExec, faults.Fault{ConnectionRefused: true})
+		return true, nil
+	})
+	payments = service.NewPaymentService(postgres.NewPaymentRepository(db), psp, nil, nil, nil, 0)
+	_, err = payments.ProcessPayment(ctx, service.ProcessPaymentRequest{TripID: "trip-2", Amount: 12.5, Method: domain.PaymentMethodCard})
+	if !errors.Is(err, driver.ErrBadConn) {
+		t.Fatalf("expected the status update to fail on a dropped connection, got %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/v1/payments", handler.NewPaymentHandler(payments, nil).ProcessPayment)
+	for fault, wantCode := range map[faults.Fault]int{
+		{ConnectionRefused: true}: http.StatusServiceUnavailable,
+		{ErrorRate: 1}:            http.StatusInternalServerError,
+	} {
+		injector.Clear()
+		_ = injector.Set(faults.OpPostgres, fault)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/payments",
+			strings.NewReader(`{"trip_id":"trip-3","amount":12.5}`)))
+		if w.Code != wantCode {
+			t.Errorf("%+v: expected %d, got %d: %s", fault, wantCode, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestFaultsHandler(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	faultsHandler := handler.NewFaultsHandler(faults.NewInjector())
+	router.POST("/v1/admin/faults", faultsHandler.Set)
+	router.GET("/v1/admin/faults", faultsHandler.GetAll)
+	router.DELETE("/v1/admin/faults", faultsHandler.Clear)
+
+	serve := func(method, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, "/v1/admin/faults", strings.NewReader(body)))
+		return w
+	}
+
+	for body, wantCode := range map[string]int{
+		`{"operation":"redis.geosearch","latency_ms":250,"error_rate":0.2}`: http.StatusOK,
+		`{"operation":"postgres","connection_refused":true}`:                http.StatusOK,
+		`{"operation":"kafka","error_rate":1}`:                              http.StatusBadRequest,
+		`{"operation":"redis","error_rate":2}`:                              http.StatusBadRequest,
+		`{"latency_ms":100}`:                                                http.StatusBadRequest,
+	} {
+		if w := serve(http.MethodPost, body); w.Code != wantCode {
+			t.Errorf("%s: expected %d, got %d: %s", body, wantCode, w.Code, w.Body.String())
+		}
+	}
+
+	var resp handler.FaultsResponse
+	_ = json.Unmarshal(serve(http.MethodGet, "").Body.Bytes(), &resp)
+	want := []handler.FaultResponse{
+		{Operation: "postgres", ConnectionRefused: true},
+		{Operation: "redis.geosearch", LatencyMs: 250, ErrorRate: 0.2},
+	}
+	if len(resp.Faults) != len(want) || resp.Faults[0] != want[0] || resp.Faults[1] != want[1] {
+		t.Errorf("expected %+v, got %+v", want, resp.Faults)
+	}
+
+	if w := serve(http.MethodDelete, ""); w.Code != http.StatusNoContent {
+		t.Errorf("expected 204 from DELETE, got %d", w.Code)
+	}
+	_ = json.Unmarshal(serve(http.MethodGet, "").Body.Bytes(), &resp)
+	if len(resp.Faults) != 0 {
+		t.Errorf("expected no faults after DELETE, got %+v", resp.Faults)
+	}
+}
@@ -0,0 +1,346 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/middleware"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// FEATURE FLAGS
+// ──────────────────────────────────────────────
+
+// riderIDs returns n distinct rider IDs.
+func riderIDs(n int) []string {
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("rider-%d", i)
+	}
+	return ids
+}
+
+func TestFeatureFlag_PercentageRolloutDistribution(t *testing.T) {
+	t.Parallel()
+
+	riders := riderIDs(20000)
+	for _, percentage := range []int{1, 10, 25, 50, 90} {
+		flag := &domain.FeatureFlag{Name: "rollout", Enabled: true, Percentage: percentage}
+
+		on := 0
+		for _, id := range riders {
+			if flag.IsOnFor(id, "") {
+				on++
+			}
+		}
+
+		// Within 1.5 percentage points of the target share.
+		want := len(riders) * percentage / 100
+		if tolerance := len(riders) * 15 / 1000; on < want-tolerance || on > want+tolerance {
+			t.Errorf("expected about %d of %d riders at %d%%, got %d", want, len(riders), percentage, on)
+		}
+	}
+}
+
+func TestFeatureFlag_BucketsSpreadEvenly(t *testing.T) {
+	t.Parallel()
+
+	flag := &domain.FeatureFlag{Name: "rollout"}
+	counts := make([]int, 100)
+	for _, id := range riderIDs(50000) {
+		counts[flag.Bucket(id)]++
+	}
+
+	// Each bucket expects 500 riders.
+	for bucket, n := range counts {
+		if n < 400 || n > 600 {
+			t.Errorf("expected about 500 riders in bucket %d, got %d", bucket, n)
+		}
+	}
+}
+
+func TestFeatureFlag_DeterministicPerRider(t *testing.T) {
+	t.Parallel()
+
+	riders := riderIDs(2000)
+	at10 := &domain.FeatureFlag{Name: "rollout", Enabled: true, Percentage: 10}
+	at50 := &domain.FeatureFlag{Name: "rollout", Enabled: true, Percentage: 50}
+	again := &domain.FeatureFlag{Name: "rollout", Enabled: true, Percentage: 10}
+	other := &domain.FeatureFlag{Name: "other-rollout", Enabled: true, Percentage: 10}
+
+	differs := false
+	for _, id := range riders {
+		if at10.IsOnFor(id, "") != again.IsOnFor(id, "") {
+			t.Fatalf("expected %s to get the same answer on every evaluation", id)
+		}
+		// Raising the percentage only adds riders.
+		if at10.IsOnFor(id, "") && !at50.IsOnFor(id, "") {
+			t.Fatalf("expected %s, in at 10%%, to stay in at 50%%", id)
+		}
+		if at10.IsOnFor(id, "") != other.IsOnFor(id, "") {
+			differs = true
+		}
+	}
+	if !differs {
+		t.Error("expected different flags to roll out to different riders first")
+	}
+}
+
+func TestFeatureFlag_RulesGateRollout(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		flag   domain.FeatureFlag
+		userID string
+		city   string
+		want   bool
+	}{
+		{"disabled at 100%", domain.FeatureFlag{Percentage: 100}, "rider-1", "", false},
+		{"enabled at 100%", domain.FeatureFlag{Enabled: true, Percentage: 100}, "rider-1", "", true},
+		{"enabled at 0%", domain.FeatureFlag{Enabled: true}, "rider-1", "", false},
+		{"allowed city", domain.FeatureFlag{Enabled: true, Percentage: 100, Cities: []string{"pune", "bengaluru"}}, "rider-1", "bengaluru", true},
+		{"other city", domain.FeatureFlag{Enabled: true, Percentage: 100, Cities: []string{"pune"}}, "rider-1", "bengaluru", false},
+		{"no rider below 100%", domain.FeatureFlag{Enabled: true, Percentage: 99}, "", "", false},
+		{"no rider at 100%", domain.FeatureFlag{Enabled: true, Percentage: 100}, "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.flag.Name = "rollout"
+			if got := tt.flag.IsOnFor(tt.userID, tt.city); got != tt.want {
+				t.Errorf("expected %t, got %t", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFeatureFlagService_UndefinedFlagUsesFallback(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	flags := service.NewFeatureFlagService(NewMockFeatureFlagRepository(), "", 0)
+
+	if !flags.Enabled(ctx, "missing", "rider-1", true) || flags.Enabled(ctx, "missing", "rider-1", false) {
+		t.Error("expected an undefined flag to return the fallback")
+	}
+
+	var none *service.FeatureFlagService
+	if !none.Enabled(ctx, service.FlagRideQuotes, "rider-1", true) {
+		t.Error("expected a nil service to return the fallback")
+	}
+}
+
+func TestFeatureFlagService_CachesUntilTTL(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo := NewMockFeatureFlagRepository()
+	_ = repo.Upsert(ctx, &domain.FeatureFlag{Name: "rollout", Enabled: true, Percentage: 100})
+	flags := service.NewFeatureFlagService(repo, "", 50*time.Millisecond)
+
+	for _, id := range riderIDs(100) {
+		if !flags.Enabled(ctx, "rollout", id, false) {
+			t.Fatalf("expected the flag on for %s", id)
+		}
+	}
+	if got := atomic.LoadInt32(&repo.GetAllCallCount); got != 1 {
+		t.Errorf("expected flags loaded once for 100 evaluations, got %d", got)
+	}
+
+	// Another instance turns the flag off; this one sees it after the TTL.
+	_ = repo.Upsert(ctx, &domain.FeatureFlag{Name: "rollout"})
+	if !flags.Enabled(ctx, "rollout", "rider-1", false) {
+		t.Error("expected the cached flag used before the TTL")
+	}
+	time.Sleep(60 * time.Millisecond)
+	if flags.Enabled(ctx, "rollout", "rider-1", false) {
+		t.Error("expected the flag reloaded after the TTL")
+	}
+}
+
+func TestFeatureFlagService_FailedReloadKeepsCachedFlags(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo := NewMockFeatureFlagRepository()
+	_ = repo.Upsert(ctx, &domain.FeatureFlag{Name: "rollout", Enabled: true, Percentage: 100})
+	flags := service.NewFeatureFlagService(repo, "", 10*time.Millisecond)
+
+	if !flags.Enabled(ctx, "rollout", "rider-1", false) {
+		t.Fatal("expected the flag on")
+	}
+	repo.GetAllError = errors.New("connection refused")
+	time.Sleep(20 * time.Millisecond)
+	if !flags.Enabled(ctx, "rollout", "rider-1", false) {
+		t.Error("expected the cached flag kept while the database is unavailable")
+	}
+}
+
+func TestFeatureFlagService_SetFlagAppliesAtOnce(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	flags := service.NewFeatureFlagService(NewMockFeatureFlagRepository(), "bengaluru", time.Hour)
+
+	if flags.Enabled(ctx, "rollout", "rider-1", false) {
+		t.Fatal("expected the undefined flag off")
+	}
+	if _, err := flags.SetFlag(ctx, "rollout", service.FeatureFlagRequest{Enabled: true, Percentage: 100, Cities: []string{"bengaluru"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !flags.Enabled(ctx, "rollout", "rider-1", false) {
+		t.Error("expected the flag on without waiting for the cache to expire")
+	}
+
+	if _, err := flags.SetFlag(ctx, "rollout", service.FeatureFlagRequest{Enabled: true, Percentage: 100, Cities: []string{"pune"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if flags.Enabled(ctx, "rollout", "rider-1", true) {
+		t.Error("expected the flag off outside its allowed cities")
+	}
+}
+
+func TestFeatureFlagService_SetFlagValidates(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	flags := service.NewFeatureFlagService(NewMockFeatureFlagRepository(), "", 0)
+
+	if _, err := flags.SetFlag(ctx, "", service.FeatureFlagRequest{Enabled: true}); !errors.Is(err, service.ErrInvalidFeatureFlagName) {
+		t.Errorf("expected ErrInvalidFeatureFlagName, got %v", err)
+	}
+	for _, percentage := range []int{-1, 101} {
+		if _, err := flags.SetFlag(ctx, "rollout", service.FeatureFlagRequest{Percentage: percentage}); !errors.Is(err, service.ErrInvalidFeatureFlagPercentage) {
+			t.Errorf("expected ErrInvalidFeatureFlagPercentage for %d, got %v", percentage, err)
+		}
+	}
+}
+
+func TestFeatureFlagHandler_FlipAtRuntime(t *testing.T) {
+	t.Parallel()
+
+	flags := service.NewFeatureFlagService(NewMockFeatureFlagRepository(), "", time.Hour)
+	flagHandler := handler.NewFeatureFlagHandler(flags)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	admin := router.Group("/v1/admin", middleware.AdminAuthMiddleware(testAdminToken))
+	admin.GET("/flags", flagHandler.GetAll)
+	admin.PUT("/flags/:name", flagHandler.Set)
+
+	put := func(token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/v1/admin/flags/"+service.FlagRideQuotes, bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := put("wrong-token", `{"enabled":true,"percentage":100}`); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 without the admin token, got %d", w.Code)
+	}
+	if w := put(testAdminToken, `{"enabled":true,"percentage":150}`); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a percentage over 100, got %d", w.Code)
+	}
+
+	w := put(testAdminToken, `{"enabled":true,"percentage":25,"cities":["pune"]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var flag handler.FeatureFlagResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &flag)
+	if flag.Name != service.FlagRideQuotes || !flag.Enabled || flag.Percentage != 25 || len(flag.Cities) != 1 {
+		t.Errorf("expected the flag's rules echoed back, got %+v", flag)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/admin/flags", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var list []handler.FeatureFlagResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &list)
+	if w.Code != http.StatusOK || len(list) != 1 || list[0].Percentage != 25 {
+		t.Errorf("expected the flag listed, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+// ──────────────────────────────────────────────
+// FEATURE FLAG DECISION POINTS
+// ──────────────────────────────────────────────
+
+// newFlagService returns a flag service with the named flag set to the rules.
+func newFlagService(t *testing.T, name string, req service.FeatureFlagRequest) *service.FeatureFlagService {
+	t.Helper()
+
+	flags := service.NewFeatureFlagService(NewMockFeatureFlagRepository(), "", time.Hour)
+	if _, err := flags.SetFlag(context.Background(), name, req); err != nil {
+		t.Fatalf("failed to set flag: %v", err)
+	}
+	return flags
+}
+
+func TestFeatureFlag_DegradedMatchingRolledOutPerRider(t *testing.T) {
+	t.Parallel()
+
+	// Configured off, but rolled out to every rider.
+	on := newFlagService(t, service.FlagDegradedMatching, service.FeatureFlagRequest{Enabled: true, Percentage: 100})
+	env := newTestEnv(t)
+	seedRedisDown(env, errRedisDown)
+	if _, err := matchDegraded(newDegradedMatcher(env, false, on)); err != nil {
+		t.Errorf("expected the flag to enable the fallback, got %v", err)
+	}
+
+	// Configured on, but the flag has it off.
+	off := newFlagService(t, service.FlagDegradedMatching, service.FeatureFlagRequest{Enabled: false})
+	env = newTestEnv(t)
+	seedRedisDown(env, errRedisDown)
+	if _, err := matchDegraded(newDegradedMatcher(env, true, off)); !errors.Is(err, syscall.ECONNREFUSED) {
+		t.Errorf("expected the Redis error with the flag off, got %v", err)
+	}
+}
+
+func TestFeatureFlag_RideQuotesOffPricesLive(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	rides := NewMockRideRepository()
+	locations := NewMockLocationStore()
+	quotes := service.NewQuoteService(NewMockQuoteStore(), "test-signing-key", 2*time.Minute)
+	surge := service.NewSurgeService(locations, rides, nil, 0, 0, nil, nil)
+	flags := newFlagService(t, service.FlagRideQuotes, service.FeatureFlagRequest{Enabled: false})
+	rideService := service.NewRideService(service.RideServiceDeps{
+		RideRepo:        rides,
+		MatchingService: NewMockMatchingServiceForTest(),
+		SurgeService:    surge,
+		QuoteService:    quotes,
+		Flags:           flags,
+	})
+
+	estimate, err := rideService.EstimateRide(ctx, service.EstimateRideRequest{PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 12.3, DestinationLng: 76.64})
+	if err != nil || estimate.QuoteID == "" {
+		t.Fatalf("expected a quote, got %+v, %v", estimate, err)
+	}
+
+	resp, err := rideService.CreateRide(ctx, service.CreateRideRequest{
+		RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 12.3, DestinationLng: 76.64, QuoteID: estimate.QuoteID,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !resp.QuoteRejected || resp.Ride.QuoteID != "" {
+		t.Errorf("expected the quote not honored with the flag off, got %+v", resp)
+	}
+}
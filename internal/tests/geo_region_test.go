@@ -0,0 +1,108 @@
+package tests
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"ride/internal/redis"
+)
+
+// ──────────────────────────────────────────────
+// REGION-SCOPED GEO INDEXES
+// ──────────────────────────────────────────────
+
+// testGeoRegions are two cities far apart; pickups and drivers between them
+// belong to neither.
+var testGeoRegions = []redis.GeoRegion{
+	{Name: "bengaluru", MinLat: 12.8, MinLng: 77.4, MaxLat: 13.2, MaxLng: 77.8},
+	{Name: "mumbai", MinLat: 18.8, MinLng: 72.7, MaxLat: 19.3, MaxLng: 73.1},
+}
+
+func TestGeoRegion_Contains(t *testing.T) {
+	t.Parallel()
+
+	bengaluru := testGeoRegions[0]
+	if !bengaluru.Contains(12.97, 77.59) || !bengaluru.Contains(12.8, 77.4) {
+		t.Error("expected central Bengaluru and the region's corner to be inside")
+	}
+	if bengaluru.Contains(19.07, 72.87) || bengaluru.Contains(12.79, 77.59) {
+		t.Error("expected Mumbai and a point just south of the region to be outside")
+	}
+}
+
+func TestLocationStore_UpdateLocationUsesDriverRegion(t *testing.T) {
+	t.Parallel()
+
+	client, rec := newKeyRecordingClient(t)
+	store := redis.NewLocationStore(client, "", testGeoRegions)
+
+	_ = store.UpdateLocation(context.Background(), "driver-b", 19.07, 72.87, 0)
+
+	got := strings.Join(rec.Commands(), ", ")
+	for _, want := range []string{
+		"geoadd drivers:locations:mumbai",
+		"zrem drivers:locations,", // Leaves the index for drivers outside every region
+		"zrem drivers:locations:bengaluru",
+		"hset drivers:regions",
+	} {
+		if !strings.Contains(got+",", want) {
+			t.Errorf("expected %q in %s", want, got)
+		}
+	}
+	if strings.Contains(got, "zrem drivers:locations:mumbai") {
+		t.Errorf("expected the driver to stay in the mumbai index, got %s", got)
+	}
+}
+
+func TestLocationStore_PickupNeverMatchesAnotherRegion(t *testing.T) {
+	t.Parallel()
+
+	client, rec := newKeyRecordingClient(t)
+	store := redis.NewLocationStore(client, "", testGeoRegions)
+
+	// A driver in Mumbai, then a pickup in Bengaluru with a radius wide
+	// enough to reach them: only Bengaluru's index is searched.
+	_ = store.UpdateLocation(context.Background(), "driver-b", 19.07, 72.87, 0)
+	rec.Commands()
+	_, _ = store.FindNearbyDrivers(context.Background(), 12.97, 77.59, 2000, 10)
+
+	got := rec.Commands()
+	if len(got) != 1 || got[0] != "georadius_ro drivers:locations:bengaluru" {
+		t.Errorf("expected only the bengaluru index searched, got %v", got)
+	}
+
+	// A pickup outside every region only searches drivers outside every region.
+	_, _ = store.FindNearbyDrivers(context.Background(), 15.5, 75.0, 2000, 10)
+	if got := rec.Commands(); len(got) != 1 || got[0] != "georadius_ro drivers:locations" {
+		t.Errorf("expected only the unregioned index searched, got %v", got)
+	}
+}
+
+func TestLocationStore_NoRegionsSharesOneIndex(t *testing.T) {
+	t.Parallel()
+
+	client, rec := newKeyRecordingClient(t)
+	store := redis.NewLocationStore(client, "", nil)
+
+	_ = store.UpdateLocation(context.Background(), "driver-b", 19.07, 72.87, 0)
+	_, _ = store.FindNearbyDrivers(context.Background(), 12.97, 77.59, 5, 10)
+
+	want := "geoadd drivers:locations, hset drivers:headings, zadd drivers:heartbeats, georadius_ro drivers:locations"
+	if got := strings.Join(rec.Commands(), ", "); got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestLocationStore_FindInBoxSearchesOverlappingRegions(t *testing.T) {
+	t.Parallel()
+
+	client, rec := newKeyRecordingClient(t)
+	store := redis.NewLocationStore(client, "", testGeoRegions)
+
+	_, _ = store.FindInBox(context.Background(), 12.9, 77.5, 13.0, 77.6, 100)
+	want := "geosearch drivers:locations, geosearch drivers:locations:bengaluru"
+	if got := strings.Join(rec.Commands(), ", "); got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
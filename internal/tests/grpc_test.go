@@ -0,0 +1,436 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	gogrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"ride/internal/domain"
+	internalGRPC "ride/internal/grpc"
+	"ride/internal/grpc/ridepb"
+	"ride/internal/handler"
+	"ride/internal/middleware"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// gRPC API CONFORMANCE
+// ──────────────────────────────────────────────
+
+// syncBuffer is a bytes.Buffer safe for the server's goroutines to write.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// newAPIEnv seeds ride-1 (rider-1) assigned to driver-1, and ride-2
+// (rider-2) in trip-2, driven by driver-2 for the last 20 minutes. driver-3
+// is online with no ride.
+func newAPIEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	env := newTestEnv(t)
+	env.rides.AddRide(&domain.Ride{
+		ID: "ride-1", RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 13.0, DestinationLng: 77.6,
+		Status: domain.RideStatusAssigned, AssignedDriverID: "driver-1", SurgeMultiplier: 1, PaymentMethod: domain.PaymentMethodCash, Version: 1,
+	})
+	env.rides.AddRide(&domain.Ride{
+		ID: "ride-2", RiderID: "rider-2", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 13.0, DestinationLng: 77.6,
+		Status: domain.RideStatusInTrip, AssignedDriverID: "driver-2", SurgeMultiplier: 1, PaymentMethod: domain.PaymentMethodCash, Version: 2,
+	})
+	_ = env.trips.Create(context.Background(), &domain.Trip{
+		ID: "trip-2", RideID: "ride-2", DriverID: "driver-2", Status: domain.TripStatusStarted,
+		StartedAt: time.Now().Add(-20 * time.Minute), Version: 1,
+	})
+	env.drivers.AddDriver(&domain.Driver{ID: "driver-1", Status: domain.DriverStatusOnline, Tier: domain.DriverTierBasic})
+	env.drivers.AddDriver(&domain.Driver{ID: "driver-2", Status: domain.DriverStatusOnTrip, Tier: domain.DriverTierBasic})
+	env.drivers.AddDriver(&domain.Driver{ID: "driver-3", Status: domain.DriverStatusOnline, Tier: domain.DriverTierBasic})
+	return env
+}
+
+// newAPIRouter serves env's rides, drivers and trips over REST.
+func newAPIRouter(env *testEnv) *gin.Engine {
+	rideService := service.NewRideService(env.rideDeps(NewMockMatchingServiceForTest()))
+	tripService := service.NewTripService(env.tripDeps())
+	rideHandler := handler.NewRideHandler(rideService, nil, env.rides, nil)
+	driverHandler := handler.NewDriverHandler(env.driverService(), tripService, env.drivers, "", nil, nil)
+
+	router := newTestRouter()
+	router.Use(middleware.IdentityMiddleware(testAdminToken))
+	router.POST("/v1/rides", rideHandler.CreateRide)
+	router.GET("/v1/rides/:id", rideHandler.GetRide)
+	router.POST("/v1/drivers/:id/location", driverHandler.UpdateLocation)
+	router.POST("/v1/drivers/:id/accept", driverHandler.AcceptRide)
+	router.POST("/v1/trips/:id/end", handler.NewTripHandler(tripService, nil).EndTrip)
+	return router
+}
+
+// newAPIClient serves env's rides, drivers and trips over gRPC and returns a
+// client for them.
+func newAPIClient(t *testing.T, env *testEnv) ridepb.RideServiceClient {
+	t.Helper()
+
+	rideService := service.NewRideService(env.rideDeps(NewMockMatchingServiceForTest()))
+	tripService := service.NewTripService(env.tripDeps())
+
+	listener := bufconn.Listen(1 << 20)
+	server := internalGRPC.NewGRPCServer(internalGRPC.NewServer(rideService, env.driverService(), tripService, nil, testAdminToken), nil, io.Discard)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	return newBufconnClient(t, listener)
+}
+
+// newBufconnClient dials a gRPC server listening on listener.
+func newBufconnClient(t *testing.T, listener *bufconn.Listener) ridepb.RideServiceClient {
+	t.Helper()
+
+	conn, err := gogrpc.NewClient("passthrough:///bufnet",
+		gogrpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		gogrpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return ridepb.NewRideServiceClient(conn)
+}
+
+// restRequest issues a JSON request as userID (none when empty) and returns
+// the response.
+func restRequest(router *gin.Engine, method, path, body, userID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if userID != "" {
+		req.Header.Set("X-User-ID", userID)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// asUser returns a context calling as userID.
+func asUser(userID string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "x-user-id", userID)
+}
+
+func TestGRPC_ErrorParityWithREST(t *testing.T) {
+	t.Parallel()
+
+	// Each case is run against a fresh env over both APIs.
+	testCases := []struct {
+		name     string
+		rest     func(router *gin.Engine) int
+		grpc     func(client ridepb.RideServiceClient) error
+		wantHTTP int
+		wantCode codes.Code
+	}{
+		{
+			name: "create ride with bad fields",
+			rest: func(router *gin.Engine) int {
+				return restRequest(router, http.MethodPost, "/v1/rides", `{"pickup_lat": 91, "pickup_lng": 77.59, "destination_lat": 13, "destination_lng": 77.6}`, "").Code
+			},
+			grpc: func(client ridepb.RideServiceClient) error {
+				_, err := client.CreateRide(context.Background(), &ridepb.CreateRideRequest{PickupLat: 91, PickupLng: 77.59, DestinationLat: 13, DestinationLng: 77.6})
+				return err
+			},
+			wantHTTP: http.StatusUnprocessableEntity,
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "read a stranger's ride",
+			rest: func(router *gin.Engine) int {
+				return restRequest(router, http.MethodGet, "/v1/rides/ride-1", "", "rider-2").Code
+			},
+			grpc: func(client ridepb.RideServiceClient) error {
+				_, err := client.GetRide(asUser("rider-2"), &ridepb.GetRideRequest{RideId: "ride-1"})
+				return err
+			},
+			wantHTTP: http.StatusNotFound,
+			wantCode: codes.NotFound,
+		},
+		{
+			name: "read a missing ride",
+			rest: func(router *gin.Engine) int {
+				return restRequest(router, http.MethodGet, "/v1/rides/ride-9", "", "rider-1").Code
+			},
+			grpc: func(client ridepb.RideServiceClient) error {
+				_, err := client.GetRide(asUser("rider-1"), &ridepb.GetRideRequest{RideId: "ride-9"})
+				return err
+			},
+			wantHTTP: http.StatusNotFound,
+			wantCode: codes.NotFound,
+		},
+		{
+			name: "location off the globe",
+			rest: func(router *gin.Engine) int {
+				return restRequest(router, http.MethodPost, "/v1/drivers/driver-1/location", `{"lat": 95, "lng": 77.59}`, "driver-1").Code
+			},
+			grpc: func(client ridepb.RideServiceClient) error {
+				_, err := client.UpdateDriverLocation(asUser("driver-1"), &ridepb.UpdateDriverLocationRequest{DriverId: "driver-1", Lat: 95, Lng: 77.59})
+				return err
+			},
+			wantHTTP: http.StatusBadRequest,
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "location with a bad timestamp",
+			rest: func(router *gin.Engine) int {
+				return restRequest(router, http.MethodPost, "/v1/drivers/driver-1/location", `{"lat": 12.97, "lng": 77.59, "recorded_at": "yesterday"}`, "driver-1").Code
+			},
+			grpc: func(client ridepb.RideServiceClient) error {
+				_, err := client.UpdateDriverLocation(asUser("driver-1"), &ridepb.UpdateDriverLocationRequest{DriverId: "driver-1", Lat: 12.97, Lng: 77.59, RecordedAt: "yesterday"})
+				return err
+			},
+			wantHTTP: http.StatusBadRequest,
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "another driver's location",
+			rest: func(router *gin.Engine) int {
+				return restRequest(router, http.MethodPost, "/v1/drivers/driver-1/location", `{"lat": 12.97, "lng": 77.59}`, "driver-2").Code
+			},
+			grpc: func(client ridepb.RideServiceClient) error {
+				_, err := client.UpdateDriverLocation(asUser("driver-2"), &ridepb.UpdateDriverLocationRequest{DriverId: "driver-1", Lat: 12.97, Lng: 77.59})
+				return err
+			},
+			wantHTTP: http.StatusForbidden,
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name: "accept another driver's ride",
+			rest: func(router *gin.Engine) int {
+				return restRequest(router, http.MethodPost, "/v1/drivers/driver-3/accept", `{"ride_id": "ride-1"}`, "").Code
+			},
+			grpc: func(client ridepb.RideServiceClient) error {
+				_, err := client.AcceptRide(context.Background(), &ridepb.AcceptRideRequest{DriverId: "driver-3", RideId: "ride-1"})
+				return err
+			},
+			wantHTTP: http.StatusForbidden,
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name: "accept during another trip",
+			rest: func(router *gin.Engine) int {
+				return restRequest(router, http.MethodPost, "/v1/drivers/driver-2/accept", `{"ride_id": "ride-1"}`, "").Code
+			},
+			grpc: func(client ridepb.RideServiceClient) error {
+				_, err := client.AcceptRide(context.Background(), &ridepb.AcceptRideRequest{DriverId: "driver-2", RideId: "ride-1"})
+				return err
+			},
+			wantHTTP: http.StatusConflict,
+			wantCode: codes.FailedPrecondition,
+		},
+		{
+			name: "end a missing trip",
+			rest: func(router *gin.Engine) int {
+				return restRequest(router, http.MethodPost, "/v1/trips/trip-9/end", "", "").Code
+			},
+			grpc: func(client ridepb.RideServiceClient) error {
+				_, err := client.EndTrip(context.Background(), &ridepb.EndTripRequest{TripId: "trip-9"})
+				return err
+			},
+			wantHTTP: http.StatusNotFound,
+			wantCode: codes.NotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tc.rest(newAPIRouter(newAPIEnv(t))); got != tc.wantHTTP {
+				t.Errorf("REST: expected %d, got %d", tc.wantHTTP, got)
+			}
+			if got := status.Code(tc.grpc(newAPIClient(t, newAPIEnv(t)))); got != tc.wantCode {
+				t.Errorf("gRPC: expected %s, got %s", tc.wantCode, got)
+			}
+		})
+	}
+}
+
+func TestGRPC_CreateRideValidationListsSameFields(t *testing.T) {
+	t.Parallel()
+
+	env := newAPIEnv(t)
+	router, client := newAPIRouter(env), newAPIClient(t, env)
+
+	w := restRequest(router, http.MethodPost, "/v1/rides", `{"pickup_lat": 91, "pickup_lng": 77.59, "destination_lat": 13, "destination_lng": 200, "tier": "GOLD"}`, "")
+	var body handler.ValidationErrorResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+	var restFields []string
+	for _, field := range body.Fields {
+		restFields = append(restFields, field.Field+": "+field.Error)
+	}
+
+	_, err := client.CreateRide(context.Background(), &ridepb.CreateRideRequest{
+		PickupLat: 91, PickupLng: 77.59, DestinationLat: 13, DestinationLng: 200, Tier: "GOLD",
+	})
+	var grpcFields []string
+	for _, detail := range status.Convert(err).Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, v := range badRequest.GetFieldViolations() {
+				grpcFields = append(grpcFields, v.GetField()+": "+v.GetDescription())
+			}
+		}
+	}
+
+	sort.Strings(restFields)
+	sort.Strings(grpcFields)
+	if len(restFields) != 4 || strings.Join(restFields, "; ") != strings.Join(grpcFields, "; ") {
+		t.Errorf("expected the same four field problems, got REST %v and gRPC %v", restFields, grpcFields)
+	}
+}
+
+func TestGRPC_SuccessParityWithREST(t *testing.T) {
+	t.Parallel()
+
+	t.Run("create ride", func(t *testing.T) {
+		t.Parallel()
+
+		var rest handler.CreateRideResponse
+		w := restRequest(newAPIRouter(newAPIEnv(t)), http.MethodPost, "/v1/rides", `{"rider_id": "rider-3", "pickup_lat": 12.97, "pickup_lng": 77.59, "destination_lat": 13, "destination_lng": 77.6, "payment_method": "cash"}`, "")
+		_ = json.Unmarshal(w.Body.Bytes(), &rest)
+
+		got, err := newAPIClient(t, newAPIEnv(t)).CreateRide(context.Background(), &ridepb.CreateRideRequest{
+			RiderId: "rider-3", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 13, DestinationLng: 77.6, PaymentMethod: "cash",
+		})
+		if err != nil || w.Code != http.StatusCreated {
+			t.Fatalf("expected both to create the ride, got %d and %v", w.Code, err)
+		}
+		ride := got.GetRide()
+		if ride.GetRiderId() != rest.RiderID || ride.GetStatus() != rest.Status || ride.GetPaymentMethod() != rest.PaymentMethod ||
+			got.GetDriverAssigned() != rest.DriverAssigned || ride.GetSurgeMultiplier() != rest.SurgeMultiplier {
+			t.Errorf("expected %+v to match %+v", got, rest)
+		}
+	})
+
+	t.Run("get ride", func(t *testing.T) {
+		t.Parallel()
+
+		env := newAPIEnv(t)
+		router, client := newAPIRouter(env), newAPIClient(t, env)
+		var rest handler.GetRideResponse
+		_ = json.Unmarshal(restRequest(router, http.MethodGet, "/v1/rides/ride-1", "", "driver-1").Body.Bytes(), &rest)
+
+		got, err := client.GetRide(asUser("driver-1"), &ridepb.GetRideRequest{RideId: "ride-1"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.GetId() != rest.ID || got.GetStatus() != rest.Status || got.GetAssignedDriverId() != rest.AssignedDriverID {
+			t.Errorf("expected %+v to match %+v", got, rest)
+		}
+
+		admin := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+testAdminToken)
+		if _, err := client.GetRide(admin, &ridepb.GetRideRequest{RideId: "ride-1"}); err != nil {
+			t.Errorf("expected the admin to read the ride, got %v", err)
+		}
+	})
+
+	t.Run("update location", func(t *testing.T) {
+		t.Parallel()
+
+		if w := restRequest(newAPIRouter(newAPIEnv(t)), http.MethodPost, "/v1/drivers/driver-1/location", `{"lat": 12.97, "lng": 77.59}`, "driver-1"); w.Code != http.StatusNoContent {
+			t.Errorf("REST: expected 204, got %d", w.Code)
+		}
+		if _, err := newAPIClient(t, newAPIEnv(t)).UpdateDriverLocation(asUser("driver-1"), &ridepb.UpdateDriverLocationRequest{DriverId: "driver-1", Lat: 12.97, Lng: 77.59}); err != nil {
+			t.Errorf("gRPC: unexpected error: %v", err)
+		}
+	})
+
+	t.Run("accept ride and end trip", func(t *testing.T) {
+		t.Parallel()
+
+		var accepted handler.AcceptRideResponse
+		_ = json.Unmarshal(restRequest(newAPIRouter(newAPIEnv(t)), http.MethodPost, "/v1/drivers/driver-1/accept", `{"ride_id": "ride-1"}`, "").Body.Bytes(), &accepted)
+		trip, err := newAPIClient(t, newAPIEnv(t)).AcceptRide(context.Background(), &ridepb.AcceptRideRequest{DriverId: "driver-1", RideId: "ride-1"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if trip.GetRideId() != accepted.RideID || trip.GetDriverId() != accepted.DriverID || trip.GetStatus() != accepted.Status {
+			t.Errorf("expected %+v to match %+v", trip, accepted)
+		}
+
+		var ended handler.TripResponse
+		_ = json.Unmarshal(restRequest(newAPIRouter(newAPIEnv(t)), http.MethodPost, "/v1/trips/trip-2/end", "", "").Body.Bytes(), &ended)
+		got, err := newAPIClient(t, newAPIEnv(t)).EndTrip(context.Background(), &ridepb.EndTripRequest{TripId: "trip-2"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// The fares differ by the time between the two calls.
+		if got.GetTrip().GetStatus() != ended.Status || math.Abs(got.GetTrip().GetFare()-ended.Fare) > 0.01 || ended.Fare <= 0 {
+			t.Errorf("expected %+v to match %+v", got.GetTrip(), ended)
+		}
+		if ended.Payment == nil || math.Abs(got.GetPayment().GetAmount()-ended.Payment.Amount) > 0.01 || got.GetPayment().GetStatus() != ended.Payment.Status {
+			t.Errorf("expected payment %+v to match %+v", got.GetPayment(), ended.Payment)
+		}
+	})
+}
+
+// panickingServer panics in GetRide.
+type panickingServer struct {
+	ridepb.UnimplementedRideServiceServer
+}
+
+func (panickingServer) GetRide(context.Context, *ridepb.GetRideRequest) (*ridepb.Ride, error) {
+	panic("boom")
+}
+
+func TestGRPC_InterceptorsRecoverAndLog(t *testing.T) {
+	t.Parallel()
+
+	out := &syncBuffer{}
+	listener := bufconn.Listen(1 << 20)
+	server := internalGRPC.NewGRPCServer(panickingServer{}, nil, out)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+	client := newBufconnClient(t, listener)
+
+	_, err := client.GetRide(context.Background(), &ridepb.GetRideRequest{RideId: "ride-1"})
+	if status.Code(err) != codes.Internal || strings.Contains(status.Convert(err).Message(), "boom") {
+		t.Errorf("expected a bare INTERNAL error, got %v", err)
+	}
+	// The server keeps serving after the panic.
+	if _, err := client.EndTrip(context.Background(), &ridepb.EndTripRequest{TripId: "trip-1"}); status.Code(err) != codes.Unimplemented {
+		t.Errorf("expected UNIMPLEMENTED, got %v", err)
+	}
+
+	logged := out.String()
+	if !strings.Contains(logged, "[PANIC]") || !strings.Contains(logged, "boom") {
+		t.Errorf("expected the panic logged with its value, got %q", logged)
+	}
+	if !strings.Contains(logged, "[GRPC]") || !strings.Contains(logged, "Internal") || !strings.Contains(logged, "/ride.v1.RideService/GetRide") {
+		t.Errorf("expected the call logged with its code and method, got %q", logged)
+	}
+}
@@ -0,0 +1,181 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/repository/postgres"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// LEDGER CONSISTENCY CHECK
+// ──────────────────────────────────────────────
+
+// ledgerSeed is one trip or payment of each anomaly class, next to entries
+// the check must accept: a card payment with its fee on top, a fare held
+// for review and a zero-fare abort, neither of which owes a payment.
+func ledgerSeed(now time.Time) ([]*domain.Trip, []*domain.Payment) {
+	ended := func(id string, fare float64) *domain.Trip {
+		return &domain.Trip{ID: id, RideID: "ledger-ride", DriverID: "ledger-driver", Status: domain.TripStatusEnded,
+			Fare: fare, StartedAt: now.Add(-20 * time.Minute), EndedAt: now, Version: 1}
+	}
+	payment := func(id, tripID string, amount float64, key string) *domain.Payment {
+		return &domain.Payment{ID: id, TripID: tripID, Amount: amount, Status: domain.PaymentStatusSuccess,
+			IdempotencyKey: key, CreatedAt: now}
+	}
+
+	review := ended("ledger-review", 200)
+	review.NeedsReview = true
+	aborted := ended("ledger-aborted", 0)
+	aborted.Status = domain.TripStatusAborted
+	started := ended("ledger-started", 0)
+	started.Status, started.EndedAt = domain.TripStatusStarted, time.Time{}
+	trips := []*domain.Trip{
+		ended("ledger-ok", 20), review, aborted, started,
+		ended("ledger-nopay", 15), ended("ledger-double", 10), ended("ledger-mismatch", 30),
+	}
+
+	card := payment("ledger-p-ok", "ledger-ok", 20.88, "payment:ledger-ok")
+	card.Fee = 0.88
+	payments := []*domain.Payment{
+		card,
+		payment("ledger-p-double-1", "ledger-double", 10, "payment:ledger-double"),
+		payment("ledger-p-double-2", "ledger-double", 10, "payment:ledger-double"),
+		payment("ledger-p-started", "ledger-started", 12, "payment:ledger-started"),
+		payment("ledger-p-orphan", "ledger-missing", 8, "payment:ledger-missing"),
+		payment("ledger-p-mismatch", "ledger-mismatch", 25, "payment:ledger-mismatch"),
+	}
+	return trips, payments
+}
+
+// wantLedgerAnomalies are the anomalies in ledgerSeed, in reporting order.
+var wantLedgerAnomalies = []string{
+	"TRIP_MULTIPLE_PAYMENTS trip=ledger-double payment= key= 2 payments",
+	"TRIP_WITHOUT_PAYMENT trip=ledger-nopay payment= key= ",
+	"PAYMENT_WITHOUT_TRIP trip=ledger-missing payment=ledger-p-orphan key= trip not found",
+	"PAYMENT_WITHOUT_TRIP trip=ledger-started payment=ledger-p-started key= trip status STARTED",
+	"AMOUNT_MISMATCH trip=ledger-mismatch payment=ledger-p-mismatch key= 30.00 vs 25.00",
+	"DUPLICATE_IDEMPOTENCY_KEY trip= payment= key=payment:ledger-double 2 payments",
+}
+
+// describeLedgerAnomalies renders the anomalies that involve seeded rows,
+// ignoring any others already in the ledger.
+func describeLedgerAnomalies(anomalies []domain.LedgerAnomaly) []string {
+	var got []string
+	for _, a := range anomalies {
+		if !strings.Contains(a.TripID+a.PaymentID+a.IdempotencyKey, "ledger-") {
+			continue
+		}
+		detail := a.Detail
+		if a.Kind == domain.LedgerAmountMismatch {
+			detail = fmt.Sprintf("%.2f vs %.2f", a.Fare, a.NetAmount)
+		}
+		got = append(got, fmt.Sprintf("%s trip=%s payment=%s key=%s %s", a.Kind, a.TripID, a.PaymentID, a.IdempotencyKey, detail))
+	}
+	return got
+}
+
+func assertLedgerAnomalies(t *testing.T, got []string) {
+	t.Helper()
+	if strings.Join(got, "\n") != strings.Join(wantLedgerAnomalies, "\n") {
+		t.Errorf("expected anomalies\n%s\ngot\n%s", strings.Join(wantLedgerAnomalies, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestLedgerCheck_ReportsEachAnomaly(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	trips, payments := NewMockTripRepository(), NewMockPaymentRepository()
+	seedTrips, seedPayments := ledgerSeed(time.Now())
+	for _, trip := range seedTrips {
+		_ = trips.Create(ctx, trip)
+	}
+	for _, payment := range seedPayments {
+		_ = payments.Create(ctx, payment)
+	}
+	reportService := service.NewReportService(NewMockReportRepository(trips, NewMockRideRepository(), payments))
+
+	report, err := reportService.LedgerCheck(ctx, time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Consistent {
+		t.Error("expected the ledger reported inconsistent")
+	}
+	assertLedgerAnomalies(t, describeLedgerAnomalies(report.Anomalies))
+
+	// Entries from before the window are not checked.
+	report, err = reportService.LedgerCheck(ctx, time.Now().Add(time.Hour))
+	if err != nil || !report.Consistent || len(report.Anomalies) != 0 {
+		t.Errorf("expected a consistent report for a window with no entries, got %+v, %v", report, err)
+	}
+}
+
+// TestLedgerCheck_LivePostgres seeds every anomaly inside a transaction
+// against the Postgres at TEST_DATABASE_URL, with scripts/schema.sql applied,
+// and rolls it back afterwards. The constraints that would refuse an orphan
+// payment or a duplicate idempotency key are dropped within the transaction.
+func TestLedgerCheck_LivePostgres(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	for _, stmt := range []string{
+		`ALTER TABLE payments DROP CONSTRAINT IF EXISTS payments_trip_id_fkey`,
+		`ALTER TABLE payments DROP CONSTRAINT IF EXISTS payments_idempotency_key_key`,
+	} {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	if err := postgres.NewRideRepositoryWithTx(tx).Create(ctx, &domain.Ride{
+		ID: "ledger-ride", RiderID: "ledger-rider", Status: domain.RideStatusCompleted, SurgeMultiplier: 1,
+	}); err != nil {
+		t.Fatalf("failed to seed ride: %v", err)
+	}
+	if err := postgres.NewDriverRepositoryWithTx(tx).Create(ctx, &domain.Driver{
+		ID: "ledger-driver", Name: "Ledger", Phone: "+15550100199", Status: domain.DriverStatusOffline, Tier: domain.DriverTierBasic,
+	}); err != nil {
+		t.Fatalf("failed to seed driver: %v", err)
+	}
+	seedTrips, seedPayments := ledgerSeed(now)
+	for _, trip := range seedTrips {
+		if err := postgres.NewTripRepositoryWithTx(tx).Create(ctx, trip); err != nil {
+			t.Fatalf("failed to seed trip %s: %v", trip.ID, err)
+		}
+	}
+	for _, payment := range seedPayments {
+		if err := postgres.NewPaymentRepositoryWithTx(tx).Create(ctx, payment); err != nil {
+			t.Fatalf("failed to seed payment %s: %v", payment.ID, err)
+		}
+	}
+
+	anomalies, err := postgres.NewReportRepositoryWithTx(tx).GetLedgerAnomalies(ctx, now.Add(-time.Minute), 0.01)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertLedgerAnomalies(t, describeLedgerAnomalies(anomalies))
+}
@@ -0,0 +1,179 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/middleware"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// DRIVER LOCATION HISTORY
+// ──────────────────────────────────────────────
+
+// newLocationHistoryService writes history in batches of two with an
+// interval long enough that only full batches and Close trigger writes.
+func newLocationHistoryService(t *testing.T, history *MockLocationHistoryRepository) *service.LocationHistoryService {
+	t.Helper()
+
+	historyService := service.NewLocationHistoryService(history, 2, time.Hour, 24*time.Hour)
+	t.Cleanup(historyService.Close)
+	return historyService
+}
+
+// newHistoryDriverService takes location updates from driver-1, recording
+// them in historyService.
+func newHistoryDriverService(env *testEnv, historyService *service.LocationHistoryService) *service.DriverService {
+	env.drivers.AddDriver(&domain.Driver{ID: "driver-1", Status: domain.DriverStatusOffline, Tier: domain.DriverTierBasic})
+	return service.NewDriverService(env.locations, nil, env.drivers, nil, historyService, nil, nil, 0, nil)
+}
+
+func getTrack(t *testing.T, historyService *service.LocationHistoryService, query string) (int, handler.DriverTrackResponse) {
+	t.Helper()
+
+	router := newTestRouter()
+	admin := router.Group("/v1/admin", middleware.AdminAuthMiddleware(testAdminToken))
+	admin.GET("/drivers/:id/track", handler.NewDriverTrackHandler(historyService).GetTrack)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/admin/drivers/driver-1/track"+query, nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var resp handler.DriverTrackResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	return w.Code, resp
+}
+
+func TestLocationHistory_UpdatesAccumulateInOrder(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	history := NewMockLocationHistoryRepository()
+	historyService := newLocationHistoryService(t, history)
+	driverService := newHistoryDriverService(env, historyService)
+
+	lats := []float64{12.970, 12.971, 12.972}
+	for _, lat := range lats {
+		if err := driverService.UpdateLocation(context.Background(), service.UpdateLocationRequest{DriverID: "driver-1", Lat: lat, Lng: 77.59}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// Close flushes the partial batch left after the first full one.
+	historyService.Close()
+	if got := history.Count(); got != len(lats) {
+		t.Fatalf("expected %d history rows, got %d", len(lats), got)
+	}
+	if got := history.Batches(); got != 2 {
+		t.Errorf("expected the points written in 2 batches, got %d", got)
+	}
+
+	code, resp := getTrack(t, historyService, "")
+	if code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if len(resp.Points) != len(lats) {
+		t.Fatalf("expected %d points, got %+v", len(lats), resp.Points)
+	}
+	for i, p := range resp.Points {
+		if p.Lat != lats[i] {
+			t.Errorf("point %d: expected lat %.3f, got %.3f", i, lats[i], p.Lat)
+		}
+	}
+}
+
+func TestLocationHistory_TrackWindowOrderedByTime(t *testing.T) {
+	t.Parallel()
+
+	history := NewMockLocationHistoryRepository()
+	historyService := newLocationHistoryService(t, history)
+	base := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	for _, p := range []*domain.LocationPoint{
+		{DriverID: "driver-1", Lat: 3, RecordedAt: base.Add(30 * time.Minute)},
+		{DriverID: "driver-1", Lat: 1, RecordedAt: base.Add(10 * time.Minute)},
+		{DriverID: "driver-2", Lat: 9, RecordedAt: base.Add(15 * time.Minute)},
+		{DriverID: "driver-1", Lat: 2, RecordedAt: base.Add(20 * time.Minute)},
+		{DriverID: "driver-1", Lat: 4, RecordedAt: base.Add(2 * time.Hour)},
+	} {
+		history.Add(p)
+	}
+
+	code, resp := getTrack(t, historyService, "?from=2026-03-01T09:00:00Z&to=2026-03-01T10:00:00Z")
+	if code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if len(resp.Points) != 3 || resp.Points[0].Lat != 1 || resp.Points[1].Lat != 2 || resp.Points[2].Lat != 3 {
+		t.Errorf("expected driver-1's three points in the window, oldest first, got %+v", resp.Points)
+	}
+
+	for _, query := range []string{"?from=yesterday", "?from=2026-03-01T10:00:00Z&to=2026-03-01T09:00:00Z"} {
+		if code, _ := getTrack(t, historyService, query); code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, code)
+		}
+	}
+}
+
+func TestLocationHistory_PruneAppliesRetention(t *testing.T) {
+	t.Parallel()
+
+	history := NewMockLocationHistoryRepository()
+	historyService := newLocationHistoryService(t, history)
+	history.Add(&domain.LocationPoint{DriverID: "driver-1", RecordedAt: time.Now().Add(-25 * time.Hour)})
+	history.Add(&domain.LocationPoint{DriverID: "driver-1", RecordedAt: time.Now().Add(-time.Hour)})
+
+	removed, err := historyService.Prune(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if removed != 1 || history.Count() != 1 {
+		t.Errorf("expected only the point past retention removed, removed %d, kept %d", removed, history.Count())
+	}
+}
+
+func TestLocationHistory_DuplicateAndLateFixesDoNotExtendPath(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	history := NewMockLocationHistoryRepository()
+	historyService := newLocationHistoryService(t, history)
+	driverService := newHistoryDriverService(env, historyService)
+	base := time.Now().Add(-10 * time.Minute).Truncate(time.Second)
+	path := []*domain.LocationPoint{
+		{Lat: 12.970, Lng: 77.590, RecordedAt: base},
+		{Lat: 12.975, Lng: 77.592, RecordedAt: base.Add(10 * time.Second)},
+		{Lat: 12.980, Lng: 77.595, RecordedAt: base.Add(20 * time.Second)},
+		{Lat: 12.985, Lng: 77.600, RecordedAt: base.Add(30 * time.Second)},
+	}
+
+	// The second fix arrives after the third, and the first and third are
+	// each delivered twice.
+	for _, p := range []*domain.LocationPoint{path[0], path[0], path[2], path[1], path[2], path[3]} {
+		if err := driverService.UpdateLocation(context.Background(), service.UpdateLocationRequest{
+			DriverID: "driver-1", Lat: p.Lat, Lng: p.Lng, RecordedAt: p.RecordedAt,
+		}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	historyService.Close()
+	recorded, err := historyService.Track(context.Background(), "driver-1", base.Add(-time.Minute), time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(recorded) != 3 {
+		t.Fatalf("expected the late fix and duplicates ignored, got %d points", len(recorded))
+	}
+
+	want := domain.PathDistanceKm([]*domain.LocationPoint{path[0], path[2], path[3]})
+	if got := domain.PathDistanceKm(recorded); math.Abs(got-want) > 1e-9 {
+		t.Errorf("expected a path of %.4f km, got %.4f km", want, got)
+	}
+}
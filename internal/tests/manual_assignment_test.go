@@ -0,0 +1,241 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/middleware"
+	"ride/internal/redis"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// MANUAL RIDE ASSIGNMENT
+// ──────────────────────────────────────────────
+
+// seedManualAssignment adds REQUESTED rides ride-1 and ride-2 and ONLINE
+// driver-1, nowhere near either pickup. The database grants driver-1's
+// ONLINE to ON_TRIP claim once, as Postgres would; the returned counter
+// counts the conditional claims it saw.
+func seedManualAssignment(env *testEnv) *atomic.Int32 {
+	claims := &atomic.Int32{}
+	env.rec.RowsAffected = func(query string) int64 {
+		if strings.Contains(query, "UPDATE drivers SET status") && claims.Add(1) > 1 {
+			return 0
+		}
+		return 1
+	}
+
+	env.drivers.AddDriver(&domain.Driver{ID: "driver-1", Status: domain.DriverStatusOnline, Tier: domain.DriverTierBasic})
+	for _, id := range []string{"ride-1", "ride-2"} {
+		env.rides.AddRide(&domain.Ride{
+			ID: id, RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 12.3, DestinationLng: 76.64,
+			Status: domain.RideStatusRequested, Version: 1,
+		})
+	}
+	return claims
+}
+
+func newManualAssignRouter(env *testEnv, exclusions *MockExclusionStore) *gin.Engine {
+	deps := env.matchingDeps()
+	deps.ExclusionStore = exclusions
+	deps.TripRepo = env.trips
+	rideService := service.NewRideService(env.rideDeps(service.NewMatchingService(deps)))
+
+	router := newTestRouter()
+	router.POST("/v1/admin/rides/:id/assign",
+		middleware.AdminAuthMiddleware(testAdminToken), handler.NewRideHandler(rideService, nil, env.rides, nil).AssignDriver)
+	return router
+}
+
+func assignRide(router *gin.Engine, rideID, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/v1/admin/rides/"+rideID+"/assign", bytes.NewBufferString(body))
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestManualAssignment_AssignsChosenDriver(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	claims := seedManualAssignment(env)
+	router := newManualAssignRouter(env, NewMockExclusionStore())
+
+	w := assignRide(router, "ride-1", `{"driver_id":"driver-1"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp handler.AssignRideResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.RideID != "ride-1" || resp.Status != "ASSIGNED" || resp.AssignedDriverID != "driver-1" || resp.AssignedBy != "ADMIN" || resp.AssignedAt == "" {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+
+	// Assigned in one transaction, claiming the driver only if ONLINE.
+	if got := claims.Load(); got != 1 {
+		t.Errorf("expected one conditional driver claim, got %d", got)
+	}
+	updates := rideUpdateArgs(env.rec)
+	if len(updates) != 1 {
+		t.Fatalf("expected one ride update, got %d", len(updates))
+	}
+	// assigned_driver_id is $7, assigned_by $19.
+	if updates[0][6] != "driver-1" || updates[0][18] != "ADMIN" {
+		t.Errorf("expected the manual assignment persisted, got driver %v by %v", updates[0][6], updates[0][18])
+	}
+	if !env.locks.IsLocked("driver-1") {
+		t.Error("expected the driver lock held like a match's")
+	}
+
+	// Without the admin token the route is closed.
+	req := httptest.NewRequest(http.MethodPost, "/v1/admin/rides/ride-2/assign", bytes.NewBufferString(`{"driver_id":"driver-1"}`))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 without the admin token, got %d", w.Code)
+	}
+}
+
+func TestManualAssignment_MatchedRidesRecordedAsMatching(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	seedManualAssignment(env)
+	env.locations.AddDriverLocation(redis.DriverLocation{DriverID: "driver-1", Lat: 12.97, Lng: 77.59})
+	deps := env.matchingDeps()
+	deps.TripRepo = env.trips
+	matcher := service.NewMatchingService(deps)
+
+	result, err := matcher.Match(context.Background(), service.MatchRequest{RideID: "ride-1", Lat: 12.97, Lng: 77.59})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Ride.AssignedBy != domain.RideAssignedByMatching {
+		t.Errorf("expected a matched ride recorded as MATCHING, got %q", result.Ride.AssignedBy)
+	}
+	if updates := rideUpdateArgs(env.rec); len(updates) != 1 || updates[0][18] != "MATCHING" {
+		t.Errorf("expected assigned_by MATCHING persisted, got %v", updates)
+	}
+}
+
+func TestManualAssignment_Rejected(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	seedManualAssignment(env)
+	exclusions := NewMockExclusionStore()
+	router := newManualAssignRouter(env, exclusions)
+	env.rides.AddRide(&domain.Ride{
+		ID: "ride-assigned", RiderID: "rider-2", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 12.3, DestinationLng: 76.64,
+		Status: domain.RideStatusAssigned, AssignedDriverID: "driver-other", Version: 2,
+	})
+	env.drivers.AddDriver(&domain.Driver{ID: "driver-on-trip", Status: domain.DriverStatusOnTrip, Tier: domain.DriverTierBasic})
+	env.drivers.AddDriver(&domain.Driver{ID: "driver-offline", Status: domain.DriverStatusOffline, Tier: domain.DriverTierBasic})
+	env.drivers.AddDriver(&domain.Driver{ID: "driver-stale", Status: domain.DriverStatusOnline, Tier: domain.DriverTierBasic})
+	env.drivers.AddDriver(&domain.Driver{ID: "driver-blocked", Status: domain.DriverStatusOnline, Tier: domain.DriverTierBasic})
+	_ = exclusions.AddExcludedDrivers(context.Background(), "ride-1", []string{"driver-blocked"}, time.Hour)
+	env.rides.AddRide(&domain.Ride{
+		ID: "ride-premium", RiderID: "rider-3", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 12.3, DestinationLng: 76.64,
+		Status: domain.RideStatusRequested, Tier: domain.DriverTierPremium, Version: 1,
+	})
+	env.rides.AddRide(&domain.Ride{
+		ID: "ride-wav", RiderID: "rider-4", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 12.3, DestinationLng: 76.64,
+		Status: domain.RideStatusRequested, Capabilities: []string{domain.CapabilityWAV}, Version: 1,
+	})
+	_ = env.trips.Create(context.Background(), &domain.Trip{
+		ID: "trip-1", RideID: "ride-old", DriverID: "driver-stale", Status: domain.TripStatusStarted,
+		StartedAt: time.Now().Add(-5 * time.Minute), Version: 1,
+	})
+
+	cases := []struct {
+		name   string
+		rideID string
+		body   string
+		want   int
+	}{
+		{"ride already assigned", "ride-assigned", `{"driver_id":"driver-1"}`, http.StatusConflict},
+		{"driver on a trip", "ride-1", `{"driver_id":"driver-on-trip"}`, http.StatusConflict},
+		{"driver offline", "ride-1", `{"driver_id":"driver-offline"}`, http.StatusConflict},
+		{"driver with an active trip", "ride-1", `{"driver_id":"driver-stale"}`, http.StatusConflict},
+		{"driver the rider blocked", "ride-1", `{"driver_id":"driver-blocked"}`, http.StatusConflict},
+		{"driver of another tier", "ride-premium", `{"driver_id":"driver-1"}`, http.StatusConflict},
+		{"non-WAV driver for a wheelchair ride", "ride-wav", `{"driver_id":"driver-1"}`, http.StatusConflict},
+		{"unknown driver", "ride-1", `{"driver_id":"driver-missing"}`, http.StatusNotFound},
+		{"unknown ride", "ride-missing", `{"driver_id":"driver-1"}`, http.StatusNotFound},
+		{"missing driver", "ride-1", `{}`, http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		if w := assignRide(router, tc.rideID, tc.body); w.Code != tc.want {
+			t.Errorf("%s: expected %d, got %d: %s", tc.name, tc.want, w.Code, w.Body.String())
+		}
+	}
+
+	if updates := rideUpdateArgs(env.rec); len(updates) != 0 {
+		t.Errorf("expected no ride assigned, got %d updates", len(updates))
+	}
+	for _, id := range []string{"driver-1", "driver-on-trip", "driver-offline", "driver-stale", "driver-blocked"} {
+		if env.locks.IsLocked(id) {
+			t.Errorf("expected %s's lock released after a rejected assignment", id)
+		}
+	}
+}
+
+func TestManualAssignment_NoSecondActiveTrip(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	seedManualAssignment(env)
+	router := newManualAssignRouter(env, NewMockExclusionStore())
+
+	// Dispatchers assigning the same driver to two rides at once.
+	var wg sync.WaitGroup
+	codes := make([]int, 2)
+	for i, rideID := range []string{"ride-1", "ride-2"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes[i] = assignRide(router, rideID, `{"driver_id":"driver-1"}`).Code
+		}()
+	}
+	wg.Wait()
+
+	ok, conflict := 0, 0
+	for _, code := range codes {
+		switch code {
+		case http.StatusOK:
+			ok++
+		case http.StatusConflict:
+			conflict++
+		}
+	}
+	if ok != 1 || conflict != 1 {
+		t.Fatalf("expected one assignment and one conflict, got %v", codes)
+	}
+
+	// Once the driver lock expires, a stale ONLINE read still cannot assign
+	// the driver again: the database claim fails.
+	env.locks.ClearLocks()
+	for _, rideID := range []string{"ride-1", "ride-2"} {
+		if w := assignRide(router, rideID, `{"driver_id":"driver-1"}`); w.Code != http.StatusConflict {
+			t.Errorf("expected 409 re-assigning the driver to %s, got %d: %s", rideID, w.Code, w.Body.String())
+		}
+	}
+
+	if updates := rideUpdateArgs(env.rec); len(updates) != 1 {
+		t.Errorf("expected the driver assigned to one ride, got %d ride updates", len(updates))
+	}
+}
@@ -0,0 +1,144 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/redis"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// MATCH AREA CONCURRENCY LIMIT
+// ──────────────────────────────────────────────
+
+const areaLimitRides = 6
+
+// newAreaLimitMatcher sets up areaLimitRides REQUESTED rides and as many
+// ONLINE drivers at the same pickup. Every statement the assignment
+// transactions execute goes through exec.
+func newAreaLimitMatcher(t *testing.T, limit int, exec func(query string) error) *service.MatchingService {
+	t.Helper()
+
+	db, rec := NewRecordingDB()
+	t.Cleanup(func() { _ = db.Close() })
+	rec.ExecError = exec
+
+	rides, drivers, locations := NewMockRideRepository(), NewMockDriverRepository(), NewMockLocationStore()
+	var driverLocations []redis.DriverLocation
+	for i := 0; i < areaLimitRides; i++ {
+		rides.AddRide(&domain.Ride{
+			ID: fmt.Sprintf("ride-%d", i), RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 13.0, DestinationLng: 77.62,
+			Status: domain.RideStatusRequested, Version: 1,
+		})
+		id := fmt.Sprintf("driver-%d", i)
+		drivers.AddDriver(&domain.Driver{ID: id, Status: domain.DriverStatusOnline, Tier: domain.DriverTierBasic})
+		driverLocations = append(driverLocations, redis.DriverLocation{DriverID: id, Lat: 12.97 + float64(i)*0.001, Lng: 77.59})
+	}
+	locations.SetLocations(driverLocations)
+
+	return service.NewMatchingService(service.MatchingServiceDeps{
+		DB:                   db,
+		LocationStore:        locations,
+		LockStore:            NewMockLockStore(),
+		DriverRepo:           drivers,
+		RideRepo:             rides,
+		MaxConcurrentPerArea: limit,
+	})
+}
+
+// matchAllAtOnce matches every ride concurrently and returns the most
+// statements that were executing at the same time.
+func matchAllAtOnce(t *testing.T, limit int) int32 {
+	t.Helper()
+
+	var inFlight, peak atomic.Int32
+	matcher := newAreaLimitMatcher(t, limit, func(string) error {
+		n := inFlight.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		inFlight.Add(-1)
+		return nil
+	})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, areaLimitRides)
+	for i := 0; i < areaLimitRides; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := matcher.Match(context.Background(), service.MatchRequest{
+				RideID: fmt.Sprintf("ride-%d", i), Lat: 12.97, Lng: 77.59, RadiusKm: 3,
+			})
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	return peak.Load()
+}
+
+func TestMatchAreaLimit_SerializesMatchesInArea(t *testing.T) {
+	t.Parallel()
+
+	// Every match still succeeds; they just take turns at the database.
+	if peak := matchAllAtOnce(t, 1); peak != 1 {
+		t.Errorf("expected assignment transactions one at a time, got %d at once", peak)
+	}
+}
+
+func TestMatchAreaLimit_UnlimitedRunsConcurrently(t *testing.T) {
+	t.Parallel()
+
+	if peak := matchAllAtOnce(t, 0); peak < 2 {
+		t.Errorf("expected concurrent assignment transactions without a limit, got %d at once", peak)
+	}
+}
+
+func TestMatchAreaLimit_QueuedMatchGivesUpWithContext(t *testing.T) {
+	t.Parallel()
+
+	entered, unblock := make(chan struct{}, areaLimitRides), make(chan struct{})
+	matcher := newAreaLimitMatcher(t, 1, func(string) error {
+		entered <- struct{}{}
+		<-unblock
+		return nil
+	})
+
+	// ride-0 takes the area's only slot and holds it.
+	done := make(chan error, 1)
+	go func() {
+		_, err := matcher.Match(context.Background(), service.MatchRequest{RideID: "ride-0", Lat: 12.97, Lng: 77.59, RadiusKm: 3})
+		done <- err
+	}()
+	<-entered
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := matcher.Match(ctx, service.MatchRequest{RideID: "ride-1", Lat: 12.97, Lng: 77.59, RadiusKm: 3})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the queued match to give up at its deadline, got %v", err)
+	}
+
+	close(unblock)
+	if err := <-done; err != nil {
+		t.Errorf("expected the slot holder to finish, got %v", err)
+	}
+}
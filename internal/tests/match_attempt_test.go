@@ -0,0 +1,212 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/redis"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// MATCH ATTEMPT DIAGNOSTICS
+// ──────────────────────────────────────────────
+
+// scriptCandidates adds REQUESTED ride-1 and places, in order of proximity:
+// an offline driver, a basic driver, a premium driver already locked by
+// another match, a location entry with no driver behind it, and the given
+// available drivers.
+func scriptCandidates(t *testing.T, env *testEnv, available ...string) {
+	t.Helper()
+
+	env.rides.AddRide(&domain.Ride{
+		ID: "ride-1", RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 12.3, DestinationLng: 76.64,
+		Status: domain.RideStatusRequested, Version: 1,
+	})
+	env.drivers.AddDriver(&domain.Driver{ID: "offline", Status: domain.DriverStatusOffline, Tier: domain.DriverTierPremium})
+	env.drivers.AddDriver(&domain.Driver{ID: "basic", Status: domain.DriverStatusOnline, Tier: domain.DriverTierBasic})
+	env.drivers.AddDriver(&domain.Driver{ID: "locked", Status: domain.DriverStatusOnline, Tier: domain.DriverTierPremium})
+	if ok, _ := env.locks.AcquireDriverLock(context.Background(), "locked", time.Minute); !ok {
+		t.Fatal("failed to pre-lock driver")
+	}
+
+	ids := append([]string{"offline", "basic", "locked", "ghost"}, available...)
+	locations := make([]redis.DriverLocation, 0, len(ids))
+	for i, id := range ids {
+		locations = append(locations, redis.DriverLocation{DriverID: id, Lat: 12.97 + float64(i)*0.001, Lng: 77.59})
+	}
+	for _, id := range available {
+		env.drivers.AddDriver(&domain.Driver{ID: id, Status: domain.DriverStatusOnline, Tier: domain.DriverTierPremium})
+	}
+	env.locations.SetLocations(locations)
+}
+
+func matchScripted(matcher *service.MatchingService) (*service.MatchResult, error) {
+	return matcher.Match(context.Background(), service.MatchRequest{
+		RideID: "ride-1", Lat: 12.97, Lng: 77.59, Tier: domain.DriverTierPremium, RadiusKm: 3,
+	})
+}
+
+func assertSkips(t *testing.T, a *domain.MatchAttempt, found, offline, tier, locked, stale int) {
+	t.Helper()
+
+	if a.CandidatesFound != found || a.SkippedOffline != offline || a.SkippedTier != tier ||
+		a.SkippedLocked != locked || a.SkippedStale != stale {
+		t.Errorf("expected found=%d offline=%d tier=%d locked=%d stale=%d, got %+v",
+			found, offline, tier, locked, stale, a)
+	}
+}
+
+func TestMatchAttempt_RecordsSkipsOnSuccess(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	matcher := service.NewMatchingService(env.matchingDeps())
+	scriptCandidates(t, env, "premium")
+
+	result, err := matchScripted(matcher)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	attempts, _ := env.attempts.ListByRide(context.Background(), "ride-1")
+	if len(attempts) != 1 {
+		t.Fatalf("expected one attempt, got %d", len(attempts))
+	}
+	a := attempts[0]
+	assertSkips(t, a, 5, 1, 1, 1, 1)
+	if a.Outcome != domain.MatchOutcomeMatched || a.AssignedDriverID != result.DriverID || a.AssignedDriverID != "premium" {
+		t.Errorf("expected MATCHED to premium, got %s/%s", a.Outcome, a.AssignedDriverID)
+	}
+	if a.RadiusKm != 3 || a.Tier != domain.DriverTierPremium || a.Duration <= 0 {
+		t.Errorf("expected radius, tier and duration recorded, got %+v", a)
+	}
+}
+
+func TestMatchAttempt_RecordedWhenNoDriverAvailable(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	matcher := service.NewMatchingService(env.matchingDeps())
+	scriptCandidates(t, env)
+
+	if _, err := matchScripted(matcher); err != service.ErrNoDriverAvailable {
+		t.Fatalf("expected ErrNoDriverAvailable, got %v", err)
+	}
+
+	attempts, _ := env.attempts.ListByRide(context.Background(), "ride-1")
+	if len(attempts) != 1 {
+		t.Fatalf("expected one attempt, got %d", len(attempts))
+	}
+	assertSkips(t, attempts[0], 4, 1, 1, 1, 1)
+	if attempts[0].Outcome != domain.MatchOutcomeNoDriver || attempts[0].AssignedDriverID != "" {
+		t.Errorf("expected NO_DRIVER, got %+v", attempts[0])
+	}
+}
+
+func TestMatchAttempt_RecordedWhenRideNotMatchable(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	matcher := service.NewMatchingService(env.matchingDeps())
+	scriptCandidates(t, env, "premium")
+	env.rides.AddRide(&domain.Ride{ID: "ride-1", RiderID: "rider-1", Status: domain.RideStatusCancelled, Version: 2})
+
+	// A cancelled ride fails before any search.
+	if _, err := matchScripted(matcher); err != service.ErrRideNotInRequestedState {
+		t.Fatalf("expected ErrRideNotInRequestedState, got %v", err)
+	}
+
+	attempts, _ := env.attempts.ListByRide(context.Background(), "ride-1")
+	if len(attempts) != 1 {
+		t.Fatalf("expected one attempt, got %d", len(attempts))
+	}
+	assertSkips(t, attempts[0], 0, 0, 0, 0, 0)
+	if attempts[0].Outcome != domain.MatchOutcomeFailed || attempts[0].Error == "" {
+		t.Errorf("expected FAILED with error, got %+v", attempts[0])
+	}
+}
+
+func TestMatchAttempt_AdminListing(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	matcher := service.NewMatchingService(env.matchingDeps())
+	scriptCandidates(t, env)
+	_, _ = matchScripted(matcher)
+
+	router := newTestRouter()
+	router.GET("/v1/admin/match-attempts", handler.NewMatchAttemptHandler(matcher).GetAll)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/admin/match-attempts", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 without ride_id, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/admin/match-attempts?ride_id=ride-1", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var attempts []handler.MatchAttemptResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &attempts)
+	if len(attempts) != 1 {
+		t.Fatalf("expected one attempt, got %d", len(attempts))
+	}
+	if a := attempts[0]; a.Outcome != "NO_DRIVER" || a.CandidatesFound != 4 || a.SkippedLocked != 1 || a.SkippedStale != 1 {
+		t.Errorf("unexpected attempt: %+v", a)
+	}
+}
+
+// gatedMatchAttemptRepository holds every write until released.
+type gatedMatchAttemptRepository struct {
+	*MockMatchAttemptRepository
+	release chan struct{}
+}
+
+func (r *gatedMatchAttemptRepository) Create(ctx context.Context, attempt *domain.MatchAttempt) error {
+	<-r.release
+	return r.MockMatchAttemptRepository.Create(ctx, attempt)
+}
+
+func TestMatchAttempt_AsyncWriterDoesNotBlockMatching(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	scriptCandidates(t, env, "premium")
+	gated := &gatedMatchAttemptRepository{MockMatchAttemptRepository: env.attempts, release: make(chan struct{})}
+	writer := service.NewAsyncMatchAttemptWriter(gated)
+	deps := env.matchingDeps()
+	deps.AttemptRepo = writer
+	matcher := service.NewMatchingService(deps)
+
+	// Both matches return while the database write is stuck.
+	if _, err := matchScripted(matcher); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	env.rides.AddRide(&domain.Ride{ID: "ride-1", RiderID: "rider-1", Status: domain.RideStatusRequested, Version: 3})
+	if _, err := matchScripted(matcher); err != service.ErrNoDriverAvailable {
+		t.Fatalf("expected ErrNoDriverAvailable, got %v", err)
+	}
+
+	close(gated.release)
+	writer.Close()
+
+	attempts, _ := env.attempts.ListByRide(context.Background(), "ride-1")
+	if len(attempts) != 2 {
+		t.Fatalf("expected both attempts written on close, got %d", len(attempts))
+	}
+	if a := attempts[0]; a.Outcome != domain.MatchOutcomeMatched || a.AssignedDriverID != "premium" || a.CandidatesFound != 5 || a.RadiusKm != 3 || a.Duration <= 0 {
+		t.Errorf("expected the match recorded, got %+v", a)
+	}
+	if a := attempts[1]; a.Outcome != domain.MatchOutcomeNoDriver || a.AssignedDriverID != "" {
+		t.Errorf("expected the failure recorded, got %+v", a)
+	}
+}
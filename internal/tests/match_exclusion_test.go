@@ -0,0 +1,137 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/redis"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// MATCH EXCLUSIONS
+// ──────────────────────────────────────────────
+
+// newExclusionMatcher places ONLINE drivers "near", "mid" and "far" at
+// increasing distance from a REQUESTED ride-1. Exclusions are remembered in
+// exclusions unless it is nil.
+func newExclusionMatcher(env *testEnv, exclusions *MockExclusionStore) *service.MatchingService {
+	env.rides.AddRide(&domain.Ride{
+		ID: "ride-1", RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 12.3, DestinationLng: 76.64,
+		Status: domain.RideStatusRequested, Version: 1,
+	})
+
+	var locations []redis.DriverLocation
+	for i, id := range []string{"near", "mid", "far"} {
+		env.drivers.AddDriver(&domain.Driver{ID: id, Status: domain.DriverStatusOnline, Tier: domain.DriverTierBasic})
+		locations = append(locations, redis.DriverLocation{DriverID: id, Lat: 12.97 + float64(i)*0.001, Lng: 77.59})
+	}
+	env.locations.SetLocations(locations)
+
+	deps := env.matchingDeps()
+	if exclusions != nil {
+		deps.ExclusionStore = exclusions
+	}
+	deps.ExclusionTTL = time.Hour
+	return service.NewMatchingService(deps)
+}
+
+func matchExcluding(matcher *service.MatchingService, exclude ...string) (*service.MatchResult, error) {
+	return matcher.Match(context.Background(), service.MatchRequest{
+		RideID: "ride-1", Lat: 12.97, Lng: 77.59, RadiusKm: 3, ExcludeDriverIDs: exclude,
+	})
+}
+
+func TestMatchExclusion_RememberedAcrossMatches(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	exclusions := NewMockExclusionStore()
+	matcher := newExclusionMatcher(env, exclusions)
+
+	result, err := matchExcluding(matcher, "near")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.DriverID != "mid" {
+		t.Fatalf("expected the excluded nearest driver skipped for mid, got %s", result.DriverID)
+	}
+
+	// mid cancels and the ride is matched again, excluding only mid: near
+	// stays excluded without being named.
+	result, err = matchExcluding(matcher, "mid")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.DriverID != "far" {
+		t.Errorf("expected near and mid both skipped for far, got %s", result.DriverID)
+	}
+
+	excluded, _ := exclusions.ExcludedDrivers(context.Background(), "ride-1")
+	if len(excluded) != 2 || excluded[0] != "mid" || excluded[1] != "near" {
+		t.Errorf("expected near and mid stored for the ride, got %v", excluded)
+	}
+	if exclusions.LastTTL != time.Hour {
+		t.Errorf("expected the configured TTL, got %v", exclusions.LastTTL)
+	}
+
+	attempts, _ := env.attempts.ListByRide(context.Background(), "ride-1")
+	if len(attempts) != 2 || attempts[0].SkippedExcluded != 1 || attempts[1].SkippedExcluded != 2 {
+		t.Errorf("expected 1 then 2 excluded skips recorded, got %+v", attempts)
+	}
+}
+
+func TestMatchExclusion_HonoredWithoutStore(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	matcher := newExclusionMatcher(env, nil)
+
+	result, err := matchExcluding(matcher, "near")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.DriverID != "mid" {
+		t.Errorf("expected mid, got %s", result.DriverID)
+	}
+	if env.locks.IsLocked("near") {
+		t.Error("expected the excluded driver never locked")
+	}
+
+	// Nothing is remembered without a store.
+	if result, _ := matchExcluding(matcher); result == nil || result.DriverID != "near" {
+		t.Errorf("expected near matched once no longer excluded, got %+v", result)
+	}
+}
+
+func TestMatchExclusion_AllExcluded(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	matcher := newExclusionMatcher(env, nil)
+
+	if _, err := matchExcluding(matcher, "near", "mid", "far"); !errors.Is(err, service.ErrNoDriverAvailable) {
+		t.Errorf("expected ErrNoDriverAvailable, got %v", err)
+	}
+}
+
+func TestMatchExclusion_RideServicePassesExclusions(t *testing.T) {
+	t.Parallel()
+
+	matching := NewMockMatchingServiceForTest()
+	rides := service.NewRideService(service.RideServiceDeps{RideRepo: NewMockRideRepository(), MatchingService: matching})
+
+	_, err := rides.CreateRide(context.Background(), service.CreateRideRequest{
+		RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 12.3, DestinationLng: 76.64,
+		ExcludeDriverIDs: []string{"blocked"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := matching.LastRequest().ExcludeDriverIDs; len(got) != 1 || got[0] != "blocked" {
+		t.Errorf("expected the blocked driver excluded from matching, got %v", got)
+	}
+}
@@ -2,12 +2,15 @@ package tests
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sync"
 	"testing"
 	"time"
 
 	"ride/internal/domain"
 	"ride/internal/redis"
+	"ride/internal/service"
 )
 
 func TestMatchingLogic_FiltersOfflineDrivers(t *testing.T) {
@@ -39,7 +42,7 @@ func TestMatchingLogic_FiltersOfflineDrivers(t *testing.T) {
 	})
 
 	// Simulate matching logic: iterate through nearby drivers and filter by status.
-	nearbyDrivers, err := locationStore.FindNearbyDrivers(ctx, 12.0, 77.0, 5.0)
+	nearbyDrivers, err := locationStore.FindNearbyDrivers(ctx, 12.0, 77.0, 5.0, 0)
 	if err != nil {
 		t.Fatalf("failed to find nearby drivers: %v", err)
 	}
@@ -95,7 +98,7 @@ func TestMatchingLogic_FiltersByTier(t *testing.T) {
 	// Filter for premium tier only.
 	requestedTier := domain.DriverTierPremium
 
-	nearbyDrivers, _ := locationStore.FindNearbyDrivers(ctx, 12.0, 77.0, 5.0)
+	nearbyDrivers, _ := locationStore.FindNearbyDrivers(ctx, 12.0, 77.0, 5.0, 0)
 
 	var matchedDriver *domain.Driver
 	for _, loc := range nearbyDrivers {
@@ -123,7 +126,7 @@ func TestMatchingLogic_NoDriversAvailable(t *testing.T) {
 	locationStore := NewMockLocationStore()
 	// No drivers in location store.
 
-	nearbyDrivers, err := locationStore.FindNearbyDrivers(ctx, 12.0, 77.0, 5.0)
+	nearbyDrivers, err := locationStore.FindNearbyDrivers(ctx, 12.0, 77.0, 5.0, 0)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -253,7 +256,7 @@ func TestMatchingLogic_SkipsLockedDrivers(t *testing.T) {
 	lockStore.AcquireDriverLock(ctx, "driver-1", 10*time.Second)
 
 	// Simulate matching: should skip locked driver and match second.
-	nearbyDrivers, _ := locationStore.FindNearbyDrivers(ctx, 12.0, 77.0, 5.0)
+	nearbyDrivers, _ := locationStore.FindNearbyDrivers(ctx, 12.0, 77.0, 5.0, 0)
 
 	var matchedDriver *domain.Driver
 	for _, loc := range nearbyDrivers {
@@ -302,7 +305,7 @@ func TestMatchingLogic_MatchesClosestDriver(t *testing.T) {
 		{DriverID: "driver-far", Lat: 12.5, Lng: 77.5},   // Farther.
 	})
 
-	nearbyDrivers, _ := locationStore.FindNearbyDrivers(ctx, 12.0, 77.0, 10.0)
+	nearbyDrivers, _ := locationStore.FindNearbyDrivers(ctx, 12.0, 77.0, 10.0, 0)
 
 	var matchedDriver *domain.Driver
 	for _, loc := range nearbyDrivers {
@@ -323,3 +326,115 @@ func TestMatchingLogic_MatchesClosestDriver(t *testing.T) {
 		t.Errorf("expected closest driver (driver-close), got %s", matchedDriver.ID)
 	}
 }
+
+func TestMatching_AttemptsAtMostMaxCandidates(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	driverRepo := NewMockDriverRepository()
+	rideRepo := NewMockRideRepository()
+	locationStore := NewMockLocationStore()
+	lockStore := NewMockLockStore()
+
+	// Every candidate is busy elsewhere, so the matcher would try them all.
+	lockStore.ForceAcquireFailure = true
+
+	locations := make([]redis.DriverLocation, 0, 200)
+	for i := 0; i < 200; i++ {
+		id := fmt.Sprintf("driver-%03d", i)
+		driverRepo.AddDriver(&domain.Driver{ID: id, Status: domain.DriverStatusOnline, Tier: domain.DriverTierBasic})
+		locations = append(locations, redis.DriverLocation{DriverID: id, Lat: 12.97, Lng: 77.59})
+	}
+	locationStore.SetLocations(locations)
+
+	rideRepo.AddRide(&domain.Ride{ID: "ride-1", RiderID: "rider-1", Status: domain.RideStatusRequested})
+
+	const maxCandidates = 7
+	matcher := service.NewMatchingService(service.MatchingServiceDeps{
+		LocationStore: locationStore,
+		LockStore:     lockStore,
+		DriverRepo:    driverRepo,
+		RideRepo:      rideRepo,
+		MaxCandidates: maxCandidates,
+	})
+
+	_, err := matcher.Match(ctx, service.MatchRequest{RideID: "ride-1", Lat: 12.97, Lng: 77.59})
+	if !errors.Is(err, service.ErrMatchingCandidatesExhausted) {
+		t.Fatalf("expected ErrMatchingCandidatesExhausted, got %v", err)
+	}
+
+	if locationStore.LastFindLimit != maxCandidates {
+		t.Errorf("expected GEORADIUS COUNT %d, got %d", maxCandidates, locationStore.LastFindLimit)
+	}
+	if lockStore.AcquireCallCount != maxCandidates {
+		t.Errorf("expected %d attempts, got %d", maxCandidates, lockStore.AcquireCallCount)
+	}
+}
+
+func TestMatching_CandidateCapExhaustedIsDistinctFromNoDriver(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	driverRepo := NewMockDriverRepository()
+	rideRepo := NewMockRideRepository()
+	locationStore := NewMockLocationStore()
+	lockStore := NewMockLockStore()
+	attempts := NewMockMatchAttemptRepository()
+
+	// 100 ONLINE candidates, each already locked by another match.
+	locations := make([]redis.DriverLocation, 0, 100)
+	for i := 0; i < 100; i++ {
+		id := fmt.Sprintf("driver-%03d", i)
+		driverRepo.AddDriver(&domain.Driver{ID: id, Status: domain.DriverStatusOnline, Tier: domain.DriverTierBasic})
+		locations = append(locations, redis.DriverLocation{DriverID: id, Lat: 12.97, Lng: 77.59})
+		_, _ = lockStore.AcquireDriverLock(ctx, id, time.Minute)
+	}
+	locationStore.SetLocations(locations)
+	lockStore.AcquireCallCount = 0
+
+	rideRepo.AddRide(&domain.Ride{ID: "ride-1", RiderID: "rider-1", Status: domain.RideStatusRequested})
+
+	const maxCandidates = 25
+	matcher := service.NewMatchingService(service.MatchingServiceDeps{
+		LocationStore: locationStore,
+		LockStore:     lockStore,
+		DriverRepo:    driverRepo,
+		RideRepo:      rideRepo,
+		AttemptRepo:   attempts,
+		MaxCandidates: maxCandidates,
+	})
+
+	_, err := matcher.Match(ctx, service.MatchRequest{RideID: "ride-1", Lat: 12.97, Lng: 77.59})
+	if !errors.Is(err, service.ErrMatchingCandidatesExhausted) || errors.Is(err, service.ErrNoDriverAvailable) {
+		t.Fatalf("expected ErrMatchingCandidatesExhausted, got %v", err)
+	}
+	if lockStore.AcquireCallCount != maxCandidates {
+		t.Errorf("expected the loop to stop after %d candidates, got %d", maxCandidates, lockStore.AcquireCallCount)
+	}
+
+	// Locks held by other matches are left alone.
+	if lockStore.ReleaseCallCount != 0 {
+		t.Errorf("expected no locks released, got %d", lockStore.ReleaseCallCount)
+	}
+	for i := 0; i < 100; i++ {
+		if locked, _ := lockStore.IsDriverLocked(ctx, fmt.Sprintf("driver-%03d", i)); !locked {
+			t.Fatalf("expected driver-%03d still locked", i)
+		}
+	}
+
+	recorded, _ := attempts.ListByRide(ctx, "ride-1")
+	if len(recorded) != 1 || recorded[0].Outcome != domain.MatchOutcomeCandidatesExhausted || recorded[0].SkippedLocked != maxCandidates {
+		t.Errorf("expected one CANDIDATES_EXHAUSTED attempt, got %+v", recorded)
+	}
+
+	// Fewer locked candidates than the cap means no driver, not exhaustion.
+	rideRepo.AddRide(&domain.Ride{ID: "ride-2", RiderID: "rider-2", Status: domain.RideStatusRequested})
+	locationStore.SetLocations(locations[:10])
+	_, err = matcher.Match(ctx, service.MatchRequest{RideID: "ride-2", Lat: 12.97, Lng: 77.59})
+	if !errors.Is(err, service.ErrNoDriverAvailable) {
+		t.Errorf("expected ErrNoDriverAvailable below the cap, got %v", err)
+	}
+	if recorded, _ := attempts.ListByRide(ctx, "ride-2"); len(recorded) != 1 || recorded[0].Outcome != domain.MatchOutcomeNoDriver {
+		t.Errorf("expected one NO_DRIVER attempt, got %+v", recorded)
+	}
+}
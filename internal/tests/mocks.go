@@ -2,28 +2,64 @@ package tests
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
+	"testing"
 	"time"
 
+	"github.com/gin-gonic/gin"
+
 	"ride/internal/domain"
 	"ride/internal/redis"
 	"ride/internal/repository"
+	"ride/internal/service"
 )
 
+// ──────────────────────────────────────────────
+// MOCK CLOCK
+// ──────────────────────────────────────────────
+
+var mockClock struct {
+	mu   sync.Mutex
+	last time.Time
+}
+
+// mockNow returns strictly increasing timestamps, at the microsecond
+// precision Postgres stores, so tests can assert ordering by CreatedAt and
+// UpdatedAt.
+func mockNow() time.Time {
+	mockClock.mu.Lock()
+	defer mockClock.mu.Unlock()
+	now := time.Now().Truncate(time.Microsecond)
+	if !now.After(mockClock.last) {
+		now = mockClock.last.Add(time.Microsecond)
+	}
+	mockClock.last = now
+	return now
+}
+
 // ──────────────────────────────────────────────
 // MOCK DRIVER REPOSITORY
 // ──────────────────────────────────────────────
 
 // MockDriverRepository is a mock implementation of DriverRepository.
 type MockDriverRepository struct {
-	mu      sync.RWMutex
-	drivers map[string]*domain.Driver
+	mu        sync.RWMutex
+	drivers   map[string]*domain.Driver
+	positions map[string]domain.LocationPoint // Last recorded position, for Near filters
 
 	// Counters for verification
 	CreateCallCount       int32
 	UpdateStatusCallCount int32
+	TierChanges           []domain.DriverTierChange // Recorded by ChangeTier
 
 	// Error injection
 	CreateError       error
@@ -33,10 +69,19 @@ type MockDriverRepository struct {
 // NewMockDriverRepository creates a new mock driver repository.
 func NewMockDriverRepository() *MockDriverRepository {
 	return &MockDriverRepository{
-		drivers: make(map[string]*domain.Driver),
+		drivers:   make(map[string]*domain.Driver),
+		positions: make(map[string]domain.LocationPoint),
 	}
 }
 
+// SetLastPosition records the driver's last position, as the location
+// history would.
+func (m *MockDriverRepository) SetLastPosition(driverID string, lat, lng float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.positions[driverID] = domain.LocationPoint{DriverID: driverID, Lat: lat, Lng: lng}
+}
+
 // AddDriver adds a driver to the mock repository.
 func (m *MockDriverRepository) AddDriver(driver *domain.Driver) {
 	m.mu.Lock()
@@ -51,6 +96,10 @@ func (m *MockDriverRepository) Create(ctx context.Context, driver *domain.Driver
 	}
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	if driver.CreatedAt.IsZero() {
+		driver.CreatedAt = mockNow()
+	}
+	driver.UpdatedAt = driver.CreatedAt
 	m.drivers[driver.ID] = driver
 	return nil
 }
@@ -79,6 +128,18 @@ func (m *MockDriverRepository) GetByPhone(ctx context.Context, phone string) (*d
 	return nil, repository.ErrNotFound
 }
 
+func (m *MockDriverRepository) GetByEmail(ctx context.Context, email string) (*domain.Driver, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, d := range m.drivers {
+		if d.Email == email {
+			copy := *d
+			return &copy, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
 func (m *MockDriverRepository) GetAll(ctx context.Context) ([]*domain.Driver, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -102,9 +163,89 @@ func (m *MockDriverRepository) UpdateStatus(ctx context.Context, id string, stat
 		return repository.ErrNotFound
 	}
 	driver.Status = status
+	driver.UpdatedAt = mockNow()
+	return nil
+}
+
+func (m *MockDriverRepository) TransitionStatus(ctx context.Context, id string, from, to domain.DriverStatus) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	driver, ok := m.drivers[id]
+	if !ok || driver.Status != from {
+		return false, nil
+	}
+	driver.Status = to
+	driver.UpdatedAt = mockNow()
+	return true, nil
+}
+
+func (m *MockDriverRepository) ChangeTier(ctx context.Context, change *domain.DriverTierChange) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	driver, ok := m.drivers[change.DriverID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	change.PreviousTier = driver.Tier
+	change.ChangedAt = mockNow()
+	driver.Tier = change.Tier
+	driver.UpdatedAt = change.ChangedAt
+	m.TierChanges = append(m.TierChanges, *change)
 	return nil
 }
 
+func (m *MockDriverRepository) List(ctx context.Context, filter repository.DriverFilter) ([]*domain.Driver, int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var matched []*domain.Driver
+	for _, d := range m.drivers {
+		if filter.Status != "" && d.Status != filter.Status {
+			continue
+		}
+		if filter.Tier != "" && d.Tier != filter.Tier {
+			continue
+		}
+		if filter.PhonePrefix != "" && !strings.HasPrefix(d.Phone, filter.PhonePrefix) {
+			continue
+		}
+		if filter.Name != "" && !strings.Contains(strings.ToLower(d.Name), strings.ToLower(filter.Name)) {
+			continue
+		}
+		if filter.Near != nil {
+			p, ok := m.positions[d.ID]
+			if !ok || domain.HaversineKm(p.Lat, p.Lng, filter.Near.Lat, filter.Near.Lng) > filter.Near.RadiusKm {
+				continue
+			}
+		}
+		copy := *d
+		matched = append(matched, &copy)
+	}
+	distance := func(d *domain.Driver) float64 {
+		if filter.Near == nil {
+			return 0
+		}
+		p := m.positions[d.ID]
+		return domain.HaversineKm(p.Lat, p.Lng, filter.Near.Lat, filter.Near.Lng)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if di, dj := distance(matched[i]), distance(matched[j]); di != dj {
+			return di < dj
+		}
+		return matched[i].ID < matched[j].ID
+	})
+
+	total := len(matched)
+	if filter.Offset >= total {
+		return nil, total, nil
+	}
+	end := total
+	if filter.Limit > 0 && filter.Offset+filter.Limit < total {
+		end = filter.Offset + filter.Limit
+	}
+	return matched[filter.Offset:end], total, nil
+}
+
 // GetDriver returns driver for test assertions.
 func (m *MockDriverRepository) GetDriver(id string) *domain.Driver {
 	m.mu.RLock()
@@ -128,6 +269,10 @@ type MockRideRepository struct {
 	// Error injection
 	CreateError error
 	UpdateError error
+
+	// BeforeUpdate, if set, runs at the start of Update. Tests use it to
+	// interleave a competing write between a read and its update.
+	BeforeUpdate func(ride *domain.Ride)
 }
 
 // NewMockRideRepository creates a new mock ride repository.
@@ -151,6 +296,10 @@ func (m *MockRideRepository) Create(ctx context.Context, ride *domain.Ride) erro
 	}
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	if ride.CreatedAt.IsZero() {
+		ride.CreatedAt = mockNow()
+	}
+	ride.UpdatedAt = ride.CreatedAt
 	m.rides[ride.ID] = ride
 	return nil
 }
@@ -183,15 +332,129 @@ func (m *MockRideRepository) Update(ctx context.Context, ride *domain.Ride) erro
 	if m.UpdateError != nil {
 		return m.UpdateError
 	}
+	if hook := m.BeforeUpdate; hook != nil {
+		m.BeforeUpdate = nil // Run once so the hook's own writes don't recurse.
+		hook(ride)
+	}
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	if _, ok := m.rides[ride.ID]; !ok {
+	stored, ok := m.rides[ride.ID]
+	if !ok {
 		return repository.ErrNotFound
 	}
-	m.rides[ride.ID] = ride
+	if stored.Version != ride.Version {
+		return repository.ErrVersionConflict
+	}
+	ride.Version++
+	ride.UpdatedAt = mockNow()
+	copy := *ride
+	m.rides[ride.ID] = &copy
+	return nil
+}
+
+func (m *MockRideRepository) StartIfAssigned(ctx context.Context, ride *domain.Ride, driverID string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored, ok := m.rides[ride.ID]
+	if !ok || stored.Status != domain.RideStatusAssigned || stored.AssignedDriverID != driverID {
+		return false, nil
+	}
+	stored.Status = domain.RideStatusInTrip
+	stored.Version++
+	stored.UpdatedAt = mockNow()
+	ride.Status, ride.Version, ride.UpdatedAt = stored.Status, stored.Version, stored.UpdatedAt
+	return true, nil
+}
+
+func (m *MockRideRepository) GetAssignedByDriverID(ctx context.Context, driverID string) (*domain.Ride, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, r := range m.rides {
+		if r.AssignedDriverID == driverID && r.Status == domain.RideStatusAssigned {
+			copy := *r
+			return &copy, nil
+		}
+	}
+	return nil, nil
+}
+
+// ridesInRange returns copies of the rides created in [from, to), oldest
+// first.
+func (m *MockRideRepository) ridesInRange(from, to time.Time) []*domain.Ride {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*domain.Ride
+	for _, r := range m.rides {
+		if !r.CreatedAt.Before(from) && r.CreatedAt.Before(to) {
+			copy := *r
+			result = append(result, &copy)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.Before(result[j].CreatedAt) })
+	return result
+}
+
+func (m *MockRideRepository) CountInRange(ctx context.Context, from, to time.Time) (int, error) {
+	return len(m.ridesInRange(from, to)), nil
+}
+
+func (m *MockRideRepository) ListIterator(ctx context.Context, from, to time.Time, fn func(*domain.Ride) error) error {
+	for _, r := range m.ridesInRange(from, to) {
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+func (m *MockRideRepository) ListOpenInBox(ctx context.Context, box domain.BoundingBox, limit int) ([]*domain.Ride, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*domain.Ride
+	for _, r := range m.rides {
+		open := r.Status == domain.RideStatusRequested || r.Status == domain.RideStatusAssigned
+		if open && box.Contains(r.PickupLat, r.PickupLng) {
+			copy := *r
+			result = append(result, &copy)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.Before(result[j].CreatedAt)
+		}
+		return result[i].ID < result[j].ID
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (m *MockRideRepository) ListRequested(ctx context.Context, limit int) ([]*domain.Ride, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*domain.Ride
+	for _, r := range m.rides {
+		if r.Status == domain.RideStatusRequested {
+			copy := *r
+			result = append(result, &copy)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Priority != result[j].Priority {
+			return result[i].Priority
+		}
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.Before(result[j].CreatedAt)
+		}
+		return result[i].ID < result[j].ID
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
 // GetRide returns the ride by ID (for test assertions).
 func (m *MockRideRepository) GetRide(id string) *domain.Ride {
 	m.mu.RLock()
@@ -233,6 +496,10 @@ type MockTripRepository struct {
 	// Error injection
 	CreateError error
 	UpdateError error
+
+	// Rides, if set, gives CompletedDurations each trip's ride endpoints;
+	// without it no trip matches.
+	Rides *MockRideRepository
 }
 
 // NewMockTripRepository creates a new mock trip repository.
@@ -249,6 +516,10 @@ func (m *MockTripRepository) Create(ctx context.Context, trip *domain.Trip) erro
 	}
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	if trip.CreatedAt.IsZero() {
+		trip.CreatedAt = mockNow()
+	}
+	trip.UpdatedAt = trip.CreatedAt
 	m.trips[trip.ID] = trip
 	return nil
 }
@@ -264,11 +535,22 @@ func (m *MockTripRepository) GetByID(ctx context.Context, id string) (*domain.Tr
 	return &copy, nil
 }
 
+func (m *MockTripRepository) GetAll(ctx context.Context) ([]*domain.Trip, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := make([]*domain.Trip, 0, len(m.trips))
+	for _, t := range m.trips {
+		copy := *t
+		result = append(result, &copy)
+	}
+	return result, nil
+}
+
 func (m *MockTripRepository) GetActiveByDriverID(ctx context.Context, driverID string) (*domain.Trip, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	for _, t := range m.trips {
-		if t.DriverID == driverID && t.Status != domain.TripStatusEnded {
+		if t.DriverID == driverID && t.Status != domain.TripStatusEnded && t.Status != domain.TripStatusAborted {
 			copy := *t
 			return &copy, nil
 		}
@@ -283,7 +565,108 @@ func (m *MockTripRepository) Update(ctx context.Context, trip *domain.Trip) erro
 	}
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	m.trips[trip.ID] = trip
+	if stored, ok := m.trips[trip.ID]; ok && stored.Version != trip.Version {
+		return repository.ErrVersionConflict
+	}
+	trip.Version++
+	trip.UpdatedAt = mockNow()
+	copy := *trip
+	m.trips[trip.ID] = &copy
+	return nil
+}
+
+func (m *MockTripRepository) GetStartedBefore(ctx context.Context, before time.Time, limit int) ([]*domain.Trip, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*domain.Trip
+	for _, t := range m.trips {
+		if t.Status == domain.TripStatusStarted && t.StartedAt.Before(before) {
+			copy := *t
+			result = append(result, &copy)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].StartedAt.Before(result[j].StartedAt) })
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (m *MockTripRepository) GetAwaitingConfirmationBefore(ctx context.Context, before time.Time, limit int) ([]*domain.Trip, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*domain.Trip
+	for _, t := range m.trips {
+		if t.Confirmation == domain.TripConfirmationPending && t.EndedAt.Before(before) {
+			copy := *t
+			result = append(result, &copy)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].EndedAt.Before(result[j].EndedAt) })
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// tripsInRange returns copies of the trips started in [from, to), oldest
+// first.
+func (m *MockTripRepository) tripsInRange(from, to time.Time) []*domain.Trip {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*domain.Trip
+	for _, r := range m.trips {
+		if !r.StartedAt.Before(from) && r.StartedAt.Before(to) {
+			copy := *r
+			result = append(result, &copy)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].StartedAt.Before(result[j].StartedAt) })
+	return result
+}
+
+func (m *MockTripRepository) CountInRange(ctx context.Context, from, to time.Time) (int, error) {
+	return len(m.tripsInRange(from, to)), nil
+}
+
+func (m *MockTripRepository) CompletedDurations(ctx context.Context, pickup, destination domain.BoundingBox, since time.Time, limit int) ([]time.Duration, error) {
+	m.mu.RLock()
+	var ended []*domain.Trip
+	for _, t := range m.trips {
+		if t.Status == domain.TripStatusEnded && !t.AutoEnded && !t.EndedAt.Before(since) {
+			copy := *t
+			ended = append(ended, &copy)
+		}
+	}
+	m.mu.RUnlock()
+	if m.Rides == nil {
+		return nil, nil
+	}
+
+	sort.Slice(ended, func(i, j int) bool { return ended[i].EndedAt.After(ended[j].EndedAt) })
+	var durations []time.Duration
+	for _, t := range ended {
+		ride, err := m.Rides.GetByID(ctx, t.RideID)
+		if err != nil {
+			continue
+		}
+		if !pickup.Contains(ride.PickupLat, ride.PickupLng) || !destination.Contains(ride.DestinationLat, ride.DestinationLng) {
+			continue
+		}
+		durations = append(durations, t.EndedAt.Sub(t.StartedAt)-t.TotalPaused)
+		if len(durations) == limit {
+			break
+		}
+	}
+	return durations, nil
+}
+
+func (m *MockTripRepository) ListIterator(ctx context.Context, from, to time.Time, fn func(*domain.Trip) error) error {
+	for _, r := range m.tripsInRange(from, to) {
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -307,7 +690,7 @@ func (m *MockTripRepository) CountActiveTripsForDriver(driverID string) int {
 	defer m.mu.RUnlock()
 	count := 0
 	for _, t := range m.trips {
-		if t.DriverID == driverID && t.Status != domain.TripStatusEnded {
+		if t.DriverID == driverID && t.Status != domain.TripStatusEnded && t.Status != domain.TripStatusAborted {
 			count++
 		}
 	}
@@ -344,6 +727,10 @@ func (m *MockPaymentRepository) Create(ctx context.Context, payment *domain.Paym
 	}
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	if payment.CreatedAt.IsZero() {
+		payment.CreatedAt = mockNow()
+	}
+	payment.UpdatedAt = payment.CreatedAt
 	m.payments[payment.ID] = payment
 	return nil
 }
@@ -379,6 +766,47 @@ func (m *MockPaymentRepository) UpdateStatus(ctx context.Context, id string, sta
 		return repository.ErrNotFound
 	}
 	payment.Status = status
+	payment.FailureReason = ""
+	payment.UpdatedAt = mockNow()
+	return nil
+}
+
+func (m *MockPaymentRepository) MarkFailed(ctx context.Context, id string, reason string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	payment, ok := m.payments[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	payment.Status = domain.PaymentStatusFailed
+	payment.FailureReason = reason
+	payment.UpdatedAt = mockNow()
+	return nil
+}
+
+func (m *MockPaymentRepository) TransitionStatus(ctx context.Context, id string, from, to domain.PaymentStatus) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	payment, ok := m.payments[id]
+	if !ok || payment.Status != from {
+		return false, nil
+	}
+	payment.Status = to
+	payment.UpdatedAt = mockNow()
+	return true, nil
+}
+
+func (m *MockPaymentRepository) UpdateCharge(ctx context.Context, id string, amount, fee float64, authID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	payment, ok := m.payments[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	payment.Amount = amount
+	payment.Fee = fee
+	payment.AuthID = authID
+	payment.UpdatedAt = mockNow()
 	return nil
 }
 
@@ -389,6 +817,19 @@ func (m *MockPaymentRepository) CountPayments() int {
 	return len(m.payments)
 }
 
+// Refunded returns the amounts refunded for a trip, by instrument.
+func (m *MockPaymentRepository) Refunded(tripID string) map[string]float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	refunded := make(map[string]float64)
+	for _, p := range m.payments {
+		if p.TripID == tripID && p.Status == domain.PaymentStatusRefunded {
+			refunded[p.InstrumentID] += p.Amount
+		}
+	}
+	return refunded
+}
+
 // GetPaymentByTripID returns payment for a trip.
 func (m *MockPaymentRepository) GetPaymentByTripID(tripID string) *domain.Payment {
 	m.mu.RLock()
@@ -402,55 +843,269 @@ func (m *MockPaymentRepository) GetPaymentByTripID(tripID string) *domain.Paymen
 }
 
 // ──────────────────────────────────────────────
-// MOCK LOCATION STORE
+// MOCK PAYMENT INSTRUMENT REPOSITORY
 // ──────────────────────────────────────────────
 
-// MockLocationStore is a mock implementation of LocationStore.
-type MockLocationStore struct {
-	mu        sync.RWMutex
-	locations []redis.DriverLocation
-
-	// Counters
-	UpdateLocationCallCount int32
-
-	// Error injection
-	UpdateLocationError    error
-	FindNearbyDriversError error
+// MockPaymentInstrumentRepository is a mock implementation of PaymentInstrumentRepository.
+type MockPaymentInstrumentRepository struct {
+	mu          sync.RWMutex
+	instruments map[string]*domain.PaymentInstrument
 }
 
-// NewMockLocationStore creates a new mock location store.
-func NewMockLocationStore() *MockLocationStore {
-	return &MockLocationStore{
-		locations: make([]redis.DriverLocation, 0),
+// NewMockPaymentInstrumentRepository creates a new mock payment instrument repository.
+func NewMockPaymentInstrumentRepository() *MockPaymentInstrumentRepository {
+	return &MockPaymentInstrumentRepository{
+		instruments: make(map[string]*domain.PaymentInstrument),
 	}
 }
 
-// AddDriverLocation adds a driver location to the mock store.
-func (m *MockLocationStore) AddDriverLocation(loc redis.DriverLocation) {
+func (m *MockPaymentInstrumentRepository) Create(ctx context.Context, instrument *domain.PaymentInstrument) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	m.locations = append(m.locations, loc)
+	if instrument.CreatedAt.IsZero() {
+		instrument.CreatedAt = mockNow()
+	}
+	instrument.UpdatedAt = instrument.CreatedAt
+	copy := *instrument
+	m.instruments[instrument.ID] = &copy
+	return nil
 }
 
-// SetLocations sets all locations (for test setup).
-func (m *MockLocationStore) SetLocations(locations []redis.DriverLocation) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	m.locations = locations
+func (m *MockPaymentInstrumentRepository) GetByID(ctx context.Context, id string) (*domain.PaymentInstrument, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	instrument, ok := m.instruments[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copy := *instrument
+	return &copy, nil
 }
 
-func (m *MockLocationStore) UpdateLocation(ctx context.Context, driverID string, lat, lng float64) error {
-	atomic.AddInt32(&m.UpdateLocationCallCount, 1)
-	if m.UpdateLocationError != nil {
-		return m.UpdateLocationError
+func (m *MockPaymentInstrumentRepository) ListByUser(ctx context.Context, userID string) ([]*domain.PaymentInstrument, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*domain.PaymentInstrument
+	for _, instrument := range m.instruments {
+		if instrument.UserID == userID {
+			copy := *instrument
+			result = append(result, &copy)
+		}
 	}
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.Before(result[j].CreatedAt) })
+	return result, nil
+}
+
+func (m *MockPaymentInstrumentRepository) GetDefault(ctx context.Context, userID string, instrumentType domain.PaymentMethod) (*domain.PaymentInstrument, error) {
+	instruments, _ := m.ListByUser(ctx, userID)
+	var result *domain.PaymentInstrument
+	for _, instrument := range instruments {
+		if instrument.Type != instrumentType {
+			continue
+		}
+		if instrument.IsDefault {
+			return instrument, nil
+		}
+		result = instrument // Newest wins when none is marked default
+	}
+	return result, nil
+}
+
+func (m *MockPaymentInstrumentRepository) SetDefault(ctx context.Context, userID, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	target, ok := m.instruments[id]
+	if !ok || target.UserID != userID {
+		return repository.ErrNotFound
+	}
+	for _, instrument := range m.instruments {
+		if instrument.UserID == userID && instrument.Type == target.Type {
+			instrument.IsDefault = instrument.ID == id
+			instrument.UpdatedAt = mockNow()
+		}
+	}
+	return nil
+}
+
+func (m *MockPaymentInstrumentRepository) Delete(ctx context.Context, userID, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	instrument, ok := m.instruments[id]
+	if !ok || instrument.UserID != userID {
+		return repository.ErrNotFound
+	}
+	delete(m.instruments, id)
+	return nil
+}
+
+// ──────────────────────────────────────────────
+// MOCK TRIP ATTACHMENT REPOSITORY
+// ──────────────────────────────────────────────
+
+// MockTripAttachmentRepository is an in-memory store of trip attachments.
+type MockTripAttachmentRepository struct {
+	mu          sync.RWMutex
+	attachments map[string]*domain.TripAttachment
+}
+
+// NewMockTripAttachmentRepository creates a new mock trip attachment repository.
+func NewMockTripAttachmentRepository() *MockTripAttachmentRepository {
+	return &MockTripAttachmentRepository{
+		attachments: make(map[string]*domain.TripAttachment),
+	}
+}
+
+func (m *MockTripAttachmentRepository) Create(ctx context.Context, attachment *domain.TripAttachment) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if attachment.CreatedAt.IsZero() {
+		attachment.CreatedAt = mockNow()
+	}
+	copy := *attachment
+	m.attachments[attachment.ID] = &copy
+	return nil
+}
+
+func (m *MockTripAttachmentRepository) GetByID(ctx context.Context, id string) (*domain.TripAttachment, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	attachment, ok := m.attachments[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copy := *attachment
+	return &copy, nil
+}
+
+func (m *MockTripAttachmentRepository) ListByTrip(ctx context.Context, tripID string) ([]*domain.TripAttachment, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*domain.TripAttachment
+	for _, attachment := range m.attachments {
+		if attachment.TripID == tripID {
+			copy := *attachment
+			result = append(result, &copy)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.Before(result[j].CreatedAt) })
+	return result, nil
+}
+
+// ──────────────────────────────────────────────
+// MOCK FARE SPLIT REPOSITORY
+// ──────────────────────────────────────────────
+
+// MockFareSplitRepository is an in-memory store of fare splits.
+type MockFareSplitRepository struct {
+	mu     sync.RWMutex
+	splits map[string]*domain.FareSplit
+}
+
+// NewMockFareSplitRepository creates a new mock fare split repository.
+func NewMockFareSplitRepository() *MockFareSplitRepository {
+	return &MockFareSplitRepository{
+		splits: make(map[string]*domain.FareSplit),
+	}
+}
+
+func (m *MockFareSplitRepository) Save(ctx context.Context, split *domain.FareSplit) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	split.UpdatedAt = mockNow()
+	if existing, ok := m.splits[split.TripID]; ok {
+		split.CreatedAt = existing.CreatedAt
+	} else {
+		split.CreatedAt = split.UpdatedAt
+	}
+	copy := *split
+	copy.Shares = append([]domain.FareShare(nil), split.Shares...)
+	m.splits[split.TripID] = &copy
+	return nil
+}
+
+func (m *MockFareSplitRepository) GetByTripID(ctx context.Context, tripID string) (*domain.FareSplit, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	split, ok := m.splits[tripID]
+	if !ok {
+		return nil, nil
+	}
+	copy := *split
+	copy.Shares = append([]domain.FareShare(nil), split.Shares...)
+	return &copy, nil
+}
+
+// ──────────────────────────────────────────────
+// MOCK LOCATION STORE
+// ──────────────────────────────────────────────
+
+// MockLocationStore is a mock implementation of LocationStore.
+type MockLocationStore struct {
+	mu        sync.RWMutex
+	locations []redis.DriverLocation
+	lastSeen  map[string]time.Time // Latest ping per driver; drivers without one are never stale
+
+	// Counters
+	UpdateLocationCallCount int32
+
+	// Error injection
+	UpdateLocationError    error
+	FindNearbyDriversError error
+
+	// LastFindLimit is the limit passed to the latest FindNearbyDrivers call.
+	LastFindLimit int
+	// LastFindRadiusKm is the radius passed to the latest FindNearbyDrivers call.
+	LastFindRadiusKm float64
+	// LastBoxLimit is the limit passed to the latest FindInBox call.
+	LastBoxLimit int
+
+	// FilterByRadius makes FindNearbyDrivers drop drivers outside the radius.
+	FilterByRadius bool
+}
+
+// NewMockLocationStore creates a new mock location store.
+func NewMockLocationStore() *MockLocationStore {
+	return &MockLocationStore{
+		locations: make([]redis.DriverLocation, 0),
+		lastSeen:  make(map[string]time.Time),
+	}
+}
+
+// SetLastSeen sets the time of a driver's latest location ping.
+func (m *MockLocationStore) SetLastSeen(driverID string, at time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastSeen[driverID] = at
+}
+
+// AddDriverLocation adds a driver location to the mock store.
+func (m *MockLocationStore) AddDriverLocation(loc redis.DriverLocation) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.locations = append(m.locations, loc)
+}
+
+// SetLocations sets all locations (for test setup).
+func (m *MockLocationStore) SetLocations(locations []redis.DriverLocation) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.locations = locations
+}
+
+func (m *MockLocationStore) UpdateLocation(ctx context.Context, driverID string, lat, lng, heading float64) error {
+	atomic.AddInt32(&m.UpdateLocationCallCount, 1)
+	if m.UpdateLocationError != nil {
+		return m.UpdateLocationError
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastSeen[driverID] = time.Now()
 	// Update existing or add new.
 	for i, loc := range m.locations {
 		if loc.DriverID == driverID {
 			m.locations[i].Lat = lat
 			m.locations[i].Lng = lng
+			m.locations[i].Heading = heading
 			return nil
 		}
 	}
@@ -458,25 +1113,70 @@ func (m *MockLocationStore) UpdateLocation(ctx context.Context, driverID string,
 		DriverID: driverID,
 		Lat:      lat,
 		Lng:      lng,
+		Heading:  heading,
 	})
 	return nil
 }
 
-func (m *MockLocationStore) FindNearbyDrivers(ctx context.Context, lat, lng, radiusKm float64) ([]redis.DriverLocation, error) {
+func (m *MockLocationStore) FindNearbyDrivers(ctx context.Context, lat, lng, radiusKm float64, limit int) ([]redis.DriverLocation, error) {
 	if m.FindNearbyDriversError != nil {
 		return nil, m.FindNearbyDriversError
 	}
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-	// Return all locations (mock doesn't do real geo filtering).
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.LastFindLimit = limit
+	m.LastFindRadiusKm = radiusKm
+	if m.FilterByRadius {
+		var result []redis.DriverLocation
+		for _, loc := range m.locations {
+			if domain.HaversineKm(lat, lng, loc.Lat, loc.Lng) <= radiusKm {
+				result = append(result, loc)
+			}
+		}
+		return result, nil
+	}
+	// Return all locations (mock doesn't do real geo filtering by default).
 	result := make([]redis.DriverLocation, len(m.locations))
 	copy(result, m.locations)
 	return result, nil
 }
 
+func (m *MockLocationStore) FindInBox(ctx context.Context, minLat, minLng, maxLat, maxLng float64, limit int) ([]redis.DriverLocation, error) {
+	if m.FindNearbyDriversError != nil {
+		return nil, m.FindNearbyDriversError
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.LastBoxLimit = limit
+	var result []redis.DriverLocation
+	for _, loc := range m.locations {
+		if loc.Lat < minLat || loc.Lat > maxLat || loc.Lng < minLng || loc.Lng > maxLng {
+			continue
+		}
+		if limit > 0 && len(result) == limit {
+			break
+		}
+		result = append(result, loc)
+	}
+	return result, nil
+}
+
+func (m *MockLocationStore) GetLocation(ctx context.Context, driverID string) (*redis.DriverLocation, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, loc := range m.locations {
+		if loc.DriverID == driverID {
+			copy := loc
+			return &copy, nil
+		}
+	}
+	return nil, nil
+}
+
 func (m *MockLocationStore) RemoveLocation(ctx context.Context, driverID string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	delete(m.lastSeen, driverID)
 	for i, loc := range m.locations {
 		if loc.DriverID == driverID {
 			m.locations = append(m.locations[:i], m.locations[i+1:]...)
@@ -486,6 +1186,23 @@ func (m *MockLocationStore) RemoveLocation(ctx context.Context, driverID string)
 	return nil
 }
 
+func (m *MockLocationStore) RemoveStale(ctx context.Context, cutoff time.Time) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var stale []string
+	kept := m.locations[:0]
+	for _, loc := range m.locations {
+		if seen, ok := m.lastSeen[loc.DriverID]; ok && seen.Before(cutoff) {
+			stale = append(stale, loc.DriverID)
+			delete(m.lastSeen, loc.DriverID)
+			continue
+		}
+		kept = append(kept, loc)
+	}
+	m.locations = kept
+	return stale, nil
+}
+
 // HasLocation checks if a driver location exists.
 func (m *MockLocationStore) HasLocation(driverID string) bool {
 	m.mu.RLock()
@@ -555,6 +1272,13 @@ func (m *MockLockStore) ReleaseDriverLock(ctx context.Context, driverID string)
 	return nil
 }
 
+func (m *MockLockStore) IsDriverLocked(ctx context.Context, driverID string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	expiry, exists := m.locks["lock:driver:"+driverID]
+	return exists && time.Now().Before(expiry), nil
+}
+
 // IsLocked checks if a driver is locked (for test assertions).
 func (m *MockLockStore) IsLocked(driverID string) bool {
 	m.mu.Lock()
@@ -584,6 +1308,26 @@ type MockPSP struct {
 
 	// Counters
 	ChargeCallCount int32
+
+	// LastToken and LastAmount are the instrument token and amount of the
+	// most recent charge.
+	LastToken  string
+	LastAmount float64
+
+	// AuthorizeError and CaptureError fail authorizations and captures.
+	AuthorizeError error
+	CaptureError   error
+
+	// Holds are the amounts held by authorization ID. Captured and Voided
+	// record the captures and voids made against them.
+	Holds    map[string]float64
+	Captured map[string]float64
+	Voided   []string
+	nextAuth int
+
+	// RefundError fails refunds. Refunds records the amounts refunded.
+	RefundError error
+	Refunds     []float64
 }
 
 // NewMockPSP creates a new mock PSP.
@@ -591,10 +1335,12 @@ func NewMockPSP() *MockPSP {
 	return &MockPSP{}
 }
 
-func (m *MockPSP) Charge(ctx context.Context, amount float64) (bool, error) {
+func (m *MockPSP) Charge(ctx context.Context, token string, amount float64) (bool, error) {
 	atomic.AddInt32(&m.ChargeCallCount, 1)
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	m.LastToken = token
+	m.LastAmount = amount
 	if m.FailError != nil {
 		return false, m.FailError
 	}
@@ -604,6 +1350,57 @@ func (m *MockPSP) Charge(ctx context.Context, amount float64) (bool, error) {
 	return true, nil
 }
 
+func (m *MockPSP) Authorize(ctx context.Context, token string, amount float64) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.LastToken = token
+	if m.AuthorizeError != nil {
+		return "", m.AuthorizeError
+	}
+	m.nextAuth++
+	authID := fmt.Sprintf("auth-%d", m.nextAuth)
+	if m.Holds == nil {
+		m.Holds = make(map[string]float64)
+	}
+	m.Holds[authID] = amount
+	return authID, nil
+}
+
+func (m *MockPSP) Capture(ctx context.Context, authID string, amount float64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.CaptureError != nil {
+		return m.CaptureError
+	}
+	held, ok := m.Holds[authID]
+	if !ok || amount > held {
+		return &service.PaymentDeclinedError{Reason: "capture exceeds authorization"}
+	}
+	if m.Captured == nil {
+		m.Captured = make(map[string]float64)
+	}
+	m.Captured[authID] = amount
+	return nil
+}
+
+func (m *MockPSP) Void(ctx context.Context, authID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Voided = append(m.Voided, authID)
+	return nil
+}
+
+func (m *MockPSP) Refund(ctx context.Context, token string, amount float64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.LastToken = token
+	if m.RefundError != nil {
+		return m.RefundError
+	}
+	m.Refunds = append(m.Refunds, amount)
+	return nil
+}
+
 // SetFailure configures the PSP to fail.
 func (m *MockPSP) SetFailure(shouldFail bool, err error) {
 	m.mu.Lock()
@@ -613,10 +1410,2036 @@ func (m *MockPSP) SetFailure(shouldFail bool, err error) {
 }
 
 // ──────────────────────────────────────────────
-// HELPER ERRORS
+// MOCK REPORT REPOSITORY
 // ──────────────────────────────────────────────
 
-var (
-	ErrMockDBConstraint = errors.New("mock: unique constraint violation")
-	ErrMockTimeout      = errors.New("mock: operation timeout")
-)
+// MockReportRepository computes reports from the seeded mock repositories,
+// mirroring the SQL in postgres.ReportRepository.
+type MockReportRepository struct {
+	trips    *MockTripRepository
+	rides    *MockRideRepository
+	payments *MockPaymentRepository
+}
+
+// NewMockReportRepository creates a report repository over the given mocks.
+func NewMockReportRepository(trips *MockTripRepository, rides *MockRideRepository, payments *MockPaymentRepository) *MockReportRepository {
+	return &MockReportRepository{trips: trips, rides: rides, payments: payments}
+}
+
+// endedTrips returns trips ended or aborted in [from, to).
+func (m *MockReportRepository) endedTrips(from, to time.Time) []*domain.Trip {
+	m.trips.mu.RLock()
+	defer m.trips.mu.RUnlock()
+	var result []*domain.Trip
+	for _, t := range m.trips.trips {
+		finished := t.Status == domain.TripStatusEnded || t.Status == domain.TripStatusAborted
+		if finished && !t.EndedAt.Before(from) && t.EndedAt.Before(to) {
+			copy := *t
+			result = append(result, &copy)
+		}
+	}
+	return result
+}
+
+// paymentsForTrip returns all payments recorded against a trip.
+func (m *MockReportRepository) paymentsForTrip(tripID string) []*domain.Payment {
+	m.payments.mu.RLock()
+	defer m.payments.mu.RUnlock()
+	var result []*domain.Payment
+	for _, p := range m.payments.payments {
+		if p.TripID == tripID {
+			copy := *p
+			result = append(result, &copy)
+		}
+	}
+	return result
+}
+
+func (m *MockReportRepository) GetDailyTotals(ctx context.Context, from, to time.Time) (*domain.DailyTotals, error) {
+	var totals domain.DailyTotals
+	for _, t := range m.endedTrips(from, to) {
+		totals.TripsEnded++
+		totals.GrossFares += t.Fare
+
+		for _, p := range m.paymentsForTrip(t.ID) {
+			switch p.Status {
+			case domain.PaymentStatusSuccess:
+				totals.SuccessfulPayments.Count++
+				totals.SuccessfulPayments.Amount += p.Amount
+			case domain.PaymentStatusFailed:
+				totals.FailedPayments.Count++
+				totals.FailedPayments.Amount += p.Amount
+			case domain.PaymentStatusPending:
+				totals.PendingPayments.Count++
+				totals.PendingPayments.Amount += p.Amount
+			case domain.PaymentStatusCashDue:
+				totals.CashAwaiting.Count++
+				totals.CashAwaiting.Amount += p.Amount
+			case domain.PaymentStatusRefunded:
+				totals.Refunds.Count++
+				totals.Refunds.Amount += p.Amount
+			}
+		}
+	}
+	return &totals, nil
+}
+
+func (m *MockReportRepository) GetFareDiscrepancies(ctx context.Context, from, to time.Time, tolerance float64) ([]domain.FareDiscrepancy, error) {
+	var result []domain.FareDiscrepancy
+	for _, t := range m.endedTrips(from, to) {
+		accounted := 0.0
+		for _, p := range m.paymentsForTrip(t.ID) {
+			switch p.Status {
+			case domain.PaymentStatusSuccess, domain.PaymentStatusFailed, domain.PaymentStatusPending, domain.PaymentStatusCashDue:
+				accounted += p.Amount
+			case domain.PaymentStatusRefunded:
+				accounted -= p.Amount
+			}
+		}
+		diff := t.Fare - accounted
+		if diff > tolerance || diff < -tolerance {
+			result = append(result, domain.FareDiscrepancy{TripID: t.ID, Fare: t.Fare, AccountedAmount: accounted})
+		}
+	}
+	return result, nil
+}
+
+func (m *MockReportRepository) GetEstimateAccuracy(ctx context.Context, from, to time.Time) (*domain.EstimateAccuracy, error) {
+	var accuracy domain.EstimateAccuracy
+	var samples []float64
+	for _, t := range m.endedTrips(from, to) {
+		if t.Status != domain.TripStatusEnded || t.NeedsReview || t.Fare <= 0 {
+			continue
+		}
+		ride, err := m.rides.GetByID(ctx, t.RideID)
+		if err != nil || ride.EstimateHigh <= 0 {
+			continue
+		}
+
+		accuracy.Trips++
+		switch {
+		case t.Fare < ride.EstimateLow:
+			accuracy.BelowRange++
+		case t.Fare > ride.EstimateHigh:
+			accuracy.AboveRange++
+		default:
+			accuracy.WithinRange++
+		}
+		samples = append(samples, math.Abs((ride.EstimateLow+ride.EstimateHigh)/2-t.Fare)/t.Fare*100)
+	}
+	sort.Float64s(samples)
+
+	// percentileCont interpolates like PostgreSQL's percentile_cont.
+	percentileCont := func(p float64) float64 {
+		if len(samples) == 0 {
+			return 0
+		}
+		pos := p * float64(len(samples)-1)
+		lower := int(math.Floor(pos))
+		upper := int(math.Ceil(pos))
+		return samples[lower] + (samples[upper]-samples[lower])*(pos-float64(lower))
+	}
+	accuracy.ErrorP50 = percentileCont(0.5)
+	accuracy.ErrorP90 = percentileCont(0.9)
+	accuracy.ErrorP99 = percentileCont(0.99)
+	return &accuracy, nil
+}
+
+func (m *MockReportRepository) GetLedgerAnomalies(ctx context.Context, since time.Time, tolerance float64) ([]domain.LedgerAnomaly, error) {
+	m.trips.mu.RLock()
+	trips := make(map[string]domain.Trip, len(m.trips.trips))
+	for id, t := range m.trips.trips {
+		trips[id] = *t
+	}
+	m.trips.mu.RUnlock()
+	m.payments.mu.RLock()
+	var payments []domain.Payment
+	for _, p := range m.payments.payments {
+		payments = append(payments, *p)
+	}
+	m.payments.mu.RUnlock()
+	sort.Slice(payments, func(i, j int) bool { return payments[i].ID < payments[j].ID })
+
+	finished := func(t domain.Trip) bool {
+		return t.Status == domain.TripStatusEnded || t.Status == domain.TripStatusAborted
+	}
+	inWindow := func(at time.Time) bool { return since.IsZero() || !at.Before(since) }
+
+	var tripIDs []string
+	for id, t := range trips {
+		if finished(t) && inWindow(t.EndedAt) {
+			tripIDs = append(tripIDs, id)
+		}
+	}
+	sort.Strings(tripIDs)
+
+	var anomalies []domain.LedgerAnomaly
+	for _, id := range tripIDs {
+		t, count := trips[id], 0
+		for _, p := range payments {
+			if p.TripID == id && p.IdempotencyKey == "payment:"+id {
+				count++
+			}
+		}
+		switch {
+		case count > 1:
+			anomalies = append(anomalies, domain.LedgerAnomaly{Kind: domain.LedgerTripMultiplePayments, TripID: id, Detail: fmt.Sprintf("%d payments", count)})
+		case count == 0 && t.Status == domain.TripStatusEnded && t.Fare > 0 && !t.NeedsReview:
+			anomalies = append(anomalies, domain.LedgerAnomaly{Kind: domain.LedgerTripWithoutPayment, TripID: id})
+		}
+	}
+	for _, p := range payments {
+		if p.Status != domain.PaymentStatusSuccess || !inWindow(p.CreatedAt) {
+			continue
+		}
+		if t, ok := trips[p.TripID]; !ok {
+			anomalies = append(anomalies, domain.LedgerAnomaly{Kind: domain.LedgerPaymentWithoutTrip, PaymentID: p.ID, TripID: p.TripID, Detail: "trip not found"})
+		} else if !finished(t) {
+			anomalies = append(anomalies, domain.LedgerAnomaly{Kind: domain.LedgerPaymentWithoutTrip, PaymentID: p.ID, TripID: p.TripID, Detail: "trip status " + string(t.Status)})
+		}
+	}
+	// Splitting a fare charges its other riders' shares under keys of their
+	// own, as adjusting one does its difference.
+	split := make(map[string]bool)
+	for _, p := range payments {
+		if strings.HasPrefix(p.IdempotencyKey, "payment:"+p.TripID+":split:") ||
+			strings.HasPrefix(p.IdempotencyKey, "payment:"+p.TripID+":adjustment:") {
+			split[p.TripID] = true
+		}
+	}
+	for _, p := range payments {
+		t, ok := trips[p.TripID]
+		if !ok || !finished(t) || !inWindow(t.EndedAt) || p.IdempotencyKey != "payment:"+t.ID || split[t.ID] {
+			continue
+		}
+		if diff := t.Fare - (p.Amount - p.Fee); diff > tolerance || diff < -tolerance {
+			anomalies = append(anomalies, domain.LedgerAnomaly{Kind: domain.LedgerAmountMismatch, PaymentID: p.ID, TripID: t.ID, Fare: t.Fare, NetAmount: p.Amount - p.Fee})
+		}
+	}
+	keys := make(map[string][]domain.Payment)
+	for _, p := range payments {
+		keys[p.IdempotencyKey] = append(keys[p.IdempotencyKey], p)
+	}
+	var duplicated []string
+	for key, shared := range keys {
+		recent := false
+		for _, p := range shared {
+			recent = recent || inWindow(p.CreatedAt)
+		}
+		if len(shared) > 1 && recent {
+			duplicated = append(duplicated, key)
+		}
+	}
+	sort.Strings(duplicated)
+	for _, key := range duplicated {
+		anomalies = append(anomalies, domain.LedgerAnomaly{Kind: domain.LedgerDuplicateIdempotencyKey, IdempotencyKey: key, Detail: fmt.Sprintf("%d payments", len(keys[key]))})
+	}
+	return anomalies, nil
+}
+
+func (m *MockReportRepository) GetOutstandingCollections(ctx context.Context, driverID string) ([]domain.OutstandingCollection, error) {
+	m.trips.mu.RLock()
+	var trips []*domain.Trip
+	for _, t := range m.trips.trips {
+		if t.DriverID == driverID {
+			copy := *t
+			trips = append(trips, &copy)
+		}
+	}
+	m.trips.mu.RUnlock()
+
+	sort.Slice(trips, func(i, j int) bool { return trips[i].EndedAt.Before(trips[j].EndedAt) })
+
+	var result []domain.OutstandingCollection
+	for _, t := range trips {
+		for _, p := range m.paymentsForTrip(t.ID) {
+			if p.Status == domain.PaymentStatusCashDue {
+				result = append(result, domain.OutstandingCollection{PaymentID: p.ID, TripID: t.ID, Amount: p.Amount, EndedAt: t.EndedAt})
+			}
+		}
+	}
+	return result, nil
+}
+
+// ──────────────────────────────────────────────
+// MOCK NOTIFICATION OUTBOX & BROKER
+// ──────────────────────────────────────────────
+
+// MockNotificationRepository is an in-memory notification outbox.
+type MockNotificationRepository struct {
+	mu     sync.RWMutex
+	events []*domain.NotificationEvent
+	nextID int64
+}
+
+// NewMockNotificationRepository creates a new mock notification repository.
+func NewMockNotificationRepository() *MockNotificationRepository {
+	return &MockNotificationRepository{}
+}
+
+func (m *MockNotificationRepository) Append(ctx context.Context, event *domain.NotificationEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nextID++
+	event.ID = m.nextID
+	copy := *event
+	m.events = append(m.events, &copy)
+	return nil
+}
+
+func (m *MockNotificationRepository) ListSince(ctx context.Context, recipientID string, afterID int64, limit int) ([]*domain.NotificationEvent, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*domain.NotificationEvent
+	for _, e := range m.events {
+		if e.RecipientID == recipientID && e.ID > afterID && len(result) < limit {
+			copy := *e
+			result = append(result, &copy)
+		}
+	}
+	return result, nil
+}
+
+// MockNotificationDeliveryRepository is an in-memory queue of notification
+// deliveries.
+type MockNotificationDeliveryRepository struct {
+	mu         sync.Mutex
+	deliveries map[int64]*domain.NotificationDelivery
+	nextID     int64
+}
+
+// NewMockNotificationDeliveryRepository creates a new mock notification delivery repository.
+func NewMockNotificationDeliveryRepository() *MockNotificationDeliveryRepository {
+	return &MockNotificationDeliveryRepository{deliveries: make(map[int64]*domain.NotificationDelivery)}
+}
+
+func (m *MockNotificationDeliveryRepository) Create(ctx context.Context, delivery *domain.NotificationDelivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nextID++
+	delivery.ID = m.nextID
+	delivery.UpdatedAt = delivery.CreatedAt
+	copy := *delivery
+	m.deliveries[delivery.ID] = &copy
+	return nil
+}
+
+func (m *MockNotificationDeliveryRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*domain.NotificationDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var due []*domain.NotificationDelivery
+	for _, d := range m.deliveries {
+		if d.Status == domain.NotificationDeliveryPending && !d.NextAttemptAt.After(now) {
+			due = append(due, d)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].NextAttemptAt.Equal(due[j].NextAttemptAt) {
+			return due[i].NextAttemptAt.Before(due[j].NextAttemptAt)
+		}
+		return due[i].ID < due[j].ID
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	result := make([]*domain.NotificationDelivery, 0, len(due))
+	for _, d := range due {
+		d.NextAttemptAt = now.Add(lease)
+		copy := *d
+		result = append(result, &copy)
+	}
+	return result, nil
+}
+
+func (m *MockNotificationDeliveryRepository) Update(ctx context.Context, delivery *domain.NotificationDelivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.deliveries[delivery.ID]; !ok {
+		return repository.ErrNotFound
+	}
+	copy := *delivery
+	m.deliveries[delivery.ID] = &copy
+	return nil
+}
+
+func (m *MockNotificationDeliveryRepository) ListByStatus(ctx context.Context, status domain.NotificationDeliveryStatus, limit int) ([]*domain.NotificationDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []*domain.NotificationDelivery
+	for _, d := range m.deliveries {
+		if d.Status == status {
+			copy := *d
+			result = append(result, &copy)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].UpdatedAt.Equal(result[j].UpdatedAt) {
+			return result[i].UpdatedAt.After(result[j].UpdatedAt)
+		}
+		return result[i].ID > result[j].ID
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (m *MockNotificationDeliveryRepository) CountByStatus(ctx context.Context, status domain.NotificationDeliveryStatus) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	count := 0
+	for _, d := range m.deliveries {
+		if d.Status == status {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *MockNotificationDeliveryRepository) Requeue(ctx context.Context, id int64, now time.Time) (*domain.NotificationDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	d, ok := m.deliveries[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	if d.Status == domain.NotificationDeliveryDead {
+		d.Status = domain.NotificationDeliveryPending
+		d.Attempts = 0
+		d.NextAttemptAt = now
+		d.UpdatedAt = now
+	}
+	copy := *d
+	return &copy, nil
+}
+
+// Get returns a delivery for assertions.
+func (m *MockNotificationDeliveryRepository) Get(id int64) *domain.NotificationDelivery {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if d, ok := m.deliveries[id]; ok {
+		copy := *d
+		return &copy
+	}
+	return nil
+}
+
+// MockNotificationPreferenceRepository is an in-memory store of notification
+// preferences.
+type MockNotificationPreferenceRepository struct {
+	mu          sync.Mutex
+	preferences map[string]*domain.NotificationPreference // recipientID|type|channel
+
+	ListError error
+}
+
+// NewMockNotificationPreferenceRepository creates a new mock notification preference repository.
+func NewMockNotificationPreferenceRepository() *MockNotificationPreferenceRepository {
+	return &MockNotificationPreferenceRepository{preferences: make(map[string]*domain.NotificationPreference)}
+}
+
+func (m *MockNotificationPreferenceRepository) Upsert(ctx context.Context, preferences []*domain.NotificationPreference) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, p := range preferences {
+		copy := *p
+		m.preferences[p.RecipientID+"|"+p.Type+"|"+string(p.Channel)] = &copy
+	}
+	return nil
+}
+
+func (m *MockNotificationPreferenceRepository) ListByRecipient(ctx context.Context, recipientID string) ([]*domain.NotificationPreference, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.ListError != nil {
+		return nil, m.ListError
+	}
+	var result []*domain.NotificationPreference
+	for _, p := range m.preferences {
+		if p.RecipientID == recipientID {
+			copy := *p
+			result = append(result, &copy)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Type != result[j].Type {
+			return result[i].Type < result[j].Type
+		}
+		return result[i].Channel < result[j].Channel
+	})
+	return result, nil
+}
+
+// MockNotificationBroker is an in-memory pub/sub broker.
+type MockNotificationBroker struct {
+	mu          sync.Mutex
+	subscribers map[string]map[chan []byte]struct{}
+}
+
+// NewMockNotificationBroker creates a new mock notification broker.
+func NewMockNotificationBroker() *MockNotificationBroker {
+	return &MockNotificationBroker{subscribers: make(map[string]map[chan []byte]struct{})}
+}
+
+func (m *MockNotificationBroker) Publish(ctx context.Context, recipientID string, payload []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for ch := range m.subscribers[recipientID] {
+		select {
+		case ch <- payload:
+		default: // Slow subscriber; pub/sub is best-effort.
+		}
+	}
+	return nil
+}
+
+func (m *MockNotificationBroker) Subscribe(ctx context.Context, recipientID string) (<-chan []byte, func(), error) {
+	ch := make(chan []byte, 16)
+	m.mu.Lock()
+	if m.subscribers[recipientID] == nil {
+		m.subscribers[recipientID] = make(map[chan []byte]struct{})
+	}
+	m.subscribers[recipientID][ch] = struct{}{}
+	m.mu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			m.mu.Lock()
+			delete(m.subscribers[recipientID], ch)
+			m.mu.Unlock()
+			close(ch)
+		})
+	}
+	return ch, cancel, nil
+}
+
+// SubscriberCount returns the number of live subscriptions for a recipient.
+func (m *MockNotificationBroker) SubscriberCount(recipientID string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.subscribers[recipientID])
+}
+
+// ──────────────────────────────────────────────
+// MOCK CAMPAIGN & EARNINGS REPOSITORIES
+// ──────────────────────────────────────────────
+
+// MockCampaignRepository is an in-memory campaign store with per-driver progress.
+type MockCampaignRepository struct {
+	mu        sync.RWMutex
+	campaigns map[string]*domain.Campaign
+	progress  map[string]*domain.CampaignProgress // campaignID|driverID
+	credited  map[string]bool                     // campaignID|tripID
+}
+
+// NewMockCampaignRepository creates a new mock campaign repository.
+func NewMockCampaignRepository() *MockCampaignRepository {
+	return &MockCampaignRepository{
+		campaigns: make(map[string]*domain.Campaign),
+		progress:  make(map[string]*domain.CampaignProgress),
+		credited:  make(map[string]bool),
+	}
+}
+
+func (m *MockCampaignRepository) Create(ctx context.Context, campaign *domain.Campaign) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	copy := *campaign
+	m.campaigns[campaign.ID] = &copy
+	return nil
+}
+
+func (m *MockCampaignRepository) GetByID(ctx context.Context, id string) (*domain.Campaign, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	campaign, ok := m.campaigns[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copy := *campaign
+	return &copy, nil
+}
+
+func (m *MockCampaignRepository) GetAll(ctx context.Context) ([]*domain.Campaign, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := make([]*domain.Campaign, 0, len(m.campaigns))
+	for _, c := range m.campaigns {
+		copy := *c
+		result = append(result, &copy)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
+
+func (m *MockCampaignRepository) Update(ctx context.Context, campaign *domain.Campaign) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.campaigns[campaign.ID]; !ok {
+		return repository.ErrNotFound
+	}
+	copy := *campaign
+	m.campaigns[campaign.ID] = &copy
+	return nil
+}
+
+func (m *MockCampaignRepository) Delete(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.campaigns[id]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(m.campaigns, id)
+	for key, p := range m.progress {
+		if p.CampaignID == id {
+			delete(m.progress, key)
+		}
+	}
+	return nil
+}
+
+func (m *MockCampaignRepository) ListActive(ctx context.Context, at time.Time) ([]*domain.Campaign, error) {
+	all, _ := m.GetAll(ctx)
+	var result []*domain.Campaign
+	for _, c := range all {
+		if c.IsActiveAt(at) {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockCampaignRepository) ListOverlapping(ctx context.Context, from, to time.Time) ([]*domain.Campaign, error) {
+	all, _ := m.GetAll(ctx)
+	var result []*domain.Campaign
+	for _, c := range all {
+		if !c.StartsAt.After(to) && c.EndsAt.After(from) {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockCampaignRepository) AddTripProgress(ctx context.Context, campaignID, driverID, tripID string, amount float64) (*domain.CampaignProgress, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := campaignID + "|" + driverID
+	progress, ok := m.progress[key]
+	if !ok {
+		progress = &domain.CampaignProgress{CampaignID: campaignID, DriverID: driverID}
+	}
+
+	applied := !m.credited[campaignID+"|"+tripID]
+	if applied {
+		m.credited[campaignID+"|"+tripID] = true
+		progress.Progress += amount
+		m.progress[key] = progress
+	}
+
+	copy := *progress
+	return &copy, applied, nil
+}
+
+func (m *MockCampaignRepository) SetProgress(ctx context.Context, campaignID, driverID string, progress float64) (*domain.CampaignProgress, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := campaignID + "|" + driverID
+	p, ok := m.progress[key]
+	if !ok {
+		p = &domain.CampaignProgress{CampaignID: campaignID, DriverID: driverID}
+		m.progress[key] = p
+	}
+	p.Progress = max(p.Progress, progress)
+
+	copy := *p
+	return &copy, nil
+}
+
+func (m *MockCampaignRepository) MarkCompleted(ctx context.Context, campaignID, driverID string, at time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	progress, ok := m.progress[campaignID+"|"+driverID]
+	if !ok || progress.IsCompleted() {
+		return false, nil
+	}
+	progress.CompletedAt = at
+	return true, nil
+}
+
+func (m *MockCampaignRepository) GetProgressByDriver(ctx context.Context, driverID string) ([]*domain.CampaignProgress, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*domain.CampaignProgress
+	for _, p := range m.progress {
+		if p.DriverID == driverID {
+			copy := *p
+			result = append(result, &copy)
+		}
+	}
+	return result, nil
+}
+
+// MockEarningsRepository is an in-memory driver earnings ledger.
+type MockEarningsRepository struct {
+	mu      sync.RWMutex
+	entries []*domain.EarningsEntry
+
+	// Error injection: CreditErrors are returned by successive Credit calls.
+	CreditErrors []error
+}
+
+// NewMockEarningsRepository creates a new mock earnings repository.
+func NewMockEarningsRepository() *MockEarningsRepository {
+	return &MockEarningsRepository{}
+}
+
+func (m *MockEarningsRepository) Credit(ctx context.Context, entry *domain.EarningsEntry) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.CreditErrors) > 0 {
+		err := m.CreditErrors[0]
+		m.CreditErrors = m.CreditErrors[1:]
+		if err != nil {
+			return false, err
+		}
+	}
+	for _, e := range m.entries {
+		if e.Reference == entry.Reference {
+			return false, nil
+		}
+	}
+	copy := *entry
+	m.entries = append(m.entries, &copy)
+	return true, nil
+}
+
+func (m *MockEarningsRepository) ListByDriver(ctx context.Context, driverID string) ([]*domain.EarningsEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*domain.EarningsEntry
+	for _, e := range m.entries {
+		if e.DriverID == driverID {
+			copy := *e
+			result = append(result, &copy)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockEarningsRepository) GetByReference(ctx context.Context, reference string) (*domain.EarningsEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, e := range m.entries {
+		if e.Reference == reference {
+			copy := *e
+			return &copy, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+// MockDriverSummaryRepository is an in-memory store of weekly summaries.
+// GenerateWeek ranks the activity set with SetActivity the way the SQL
+// does: within each city, on trips, then earnings, with ties sharing a rank.
+type MockDriverSummaryRepository struct {
+	mu        sync.Mutex
+	activity  map[time.Time][]domain.DriverWeeklySummary
+	summaries map[string]*domain.DriverWeeklySummary // driverID|weekStart
+}
+
+// NewMockDriverSummaryRepository creates a new mock driver summary repository.
+func NewMockDriverSummaryRepository() *MockDriverSummaryRepository {
+	return &MockDriverSummaryRepository{
+		activity:  make(map[time.Time][]domain.DriverWeeklySummary),
+		summaries: make(map[string]*domain.DriverWeeklySummary),
+	}
+}
+
+// SetActivity sets the drivers' city, trips, earnings and online hours that
+// GenerateWeek finds for a week. An empty city is the driver registered
+// without one.
+func (m *MockDriverSummaryRepository) SetActivity(weekStart time.Time, activity ...domain.DriverWeeklySummary) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.activity[weekStart] = activity
+}
+
+func driverSummaryKey(driverID string, weekStart time.Time) string {
+	return driverID + "|" + weekStart.Format(time.RFC3339)
+}
+
+func (m *MockDriverSummaryRepository) GenerateWeek(ctx context.Context, weekStart time.Time, defaultCity string, commissionPercent float64, now time.Time) ([]*domain.DriverWeeklySummary, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	byCity := make(map[string][]domain.DriverWeeklySummary)
+	var cities []string
+	for _, a := range m.activity[weekStart] {
+		if a.City == "" {
+			a.City = defaultCity
+		}
+		if _, ok := byCity[a.City]; !ok {
+			cities = append(cities, a.City)
+		}
+		byCity[a.City] = append(byCity[a.City], a)
+	}
+
+	var result []*domain.DriverWeeklySummary
+	for _, city := range cities {
+		ranked := byCity[city]
+		sort.SliceStable(ranked, func(i, j int) bool {
+			if ranked[i].Trips != ranked[j].Trips {
+				return ranked[i].Trips > ranked[j].Trips
+			}
+			return ranked[i].Earnings > ranked[j].Earnings
+		})
+
+		prevRank := 0
+		for i, a := range ranked {
+			rank := i + 1
+			if i > 0 && a.Trips == ranked[i-1].Trips && a.Earnings == ranked[i-1].Earnings {
+				rank = prevRank
+			}
+			prevRank = rank
+			key := driverSummaryKey(a.DriverID, weekStart)
+			summary := &domain.DriverWeeklySummary{
+				DriverID: a.DriverID, WeekStart: weekStart, City: city, Trips: a.Trips, Earnings: a.Earnings,
+				OnlineHours: a.OnlineHours, Rank: rank, RankedDrivers: len(ranked), GeneratedAt: now,
+			}
+			if existing, ok := m.summaries[key]; ok {
+				summary.NotifiedAt = existing.NotifiedAt
+			}
+			m.summaries[key] = summary
+			copy := *summary
+			result = append(result, &copy)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockDriverSummaryRepository) Get(ctx context.Context, driverID string, weekStart time.Time) (*domain.DriverWeeklySummary, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	summary, ok := m.summaries[driverSummaryKey(driverID, weekStart)]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copy := *summary
+	return &copy, nil
+}
+
+func (m *MockDriverSummaryRepository) Leaderboard(ctx context.Context, weekStart time.Time, city string, limit int) ([]*domain.DriverWeeklySummary, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []*domain.DriverWeeklySummary
+	for _, summary := range m.summaries {
+		if summary.WeekStart.Equal(weekStart) && summary.City == city {
+			copy := *summary
+			result = append(result, &copy)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Rank != result[j].Rank {
+			return result[i].Rank < result[j].Rank
+		}
+		return result[i].DriverID < result[j].DriverID
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (m *MockDriverSummaryRepository) MarkNotified(ctx context.Context, driverID string, weekStart time.Time, at time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	summary, ok := m.summaries[driverSummaryKey(driverID, weekStart)]
+	if !ok || !summary.NotifiedAt.IsZero() {
+		return false, nil
+	}
+	summary.NotifiedAt = at
+	return true, nil
+}
+
+// ──────────────────────────────────────────────
+// MOCK ROUTE DEVIATION STORES
+// ──────────────────────────────────────────────
+
+// MockDeviationAlertRepository is an in-memory route deviation alert store.
+type MockDeviationAlertRepository struct {
+	mu     sync.RWMutex
+	alerts []*domain.RouteDeviationAlert
+}
+
+// NewMockDeviationAlertRepository creates a new mock deviation alert repository.
+func NewMockDeviationAlertRepository() *MockDeviationAlertRepository {
+	return &MockDeviationAlertRepository{}
+}
+
+func (m *MockDeviationAlertRepository) Create(ctx context.Context, alert *domain.RouteDeviationAlert) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	copy := *alert
+	m.alerts = append(m.alerts, &copy)
+	return nil
+}
+
+func (m *MockDeviationAlertRepository) ListByTrip(ctx context.Context, tripID string) ([]*domain.RouteDeviationAlert, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*domain.RouteDeviationAlert
+	for _, a := range m.alerts {
+		if a.TripID == tripID {
+			copy := *a
+			result = append(result, &copy)
+		}
+	}
+	return result, nil
+}
+
+// MockDeviationStore is an in-memory consecutive off-route ping counter.
+type MockDeviationStore struct {
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+// NewMockDeviationStore creates a new mock deviation store.
+func NewMockDeviationStore() *MockDeviationStore {
+	return &MockDeviationStore{counts: make(map[string]int64)}
+}
+
+func (m *MockDeviationStore) IncrementOffRoute(ctx context.Context, tripID string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counts[tripID]++
+	return m.counts[tripID], nil
+}
+
+func (m *MockDeviationStore) ResetOffRoute(ctx context.Context, tripID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.counts, tripID)
+	return nil
+}
+
+// MockArrivalStore is an in-memory consecutive in-fence ping counter.
+type MockArrivalStore struct {
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+// NewMockArrivalStore creates a new mock arrival store.
+func NewMockArrivalStore() *MockArrivalStore {
+	return &MockArrivalStore{counts: make(map[string]int64)}
+}
+
+func (m *MockArrivalStore) IncrementInFence(ctx context.Context, rideID string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counts[rideID]++
+	return m.counts[rideID], nil
+}
+
+func (m *MockArrivalStore) ResetInFence(ctx context.Context, rideID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.counts, rideID)
+	return nil
+}
+
+// MockSurgeStore is an in-memory smoothed surge store. TTLs are recorded
+// but never expire entries.
+type MockSurgeStore struct {
+	mu          sync.Mutex
+	multipliers map[string]float64
+	LastTTL     time.Duration
+
+	// Error injection
+	GetError error
+}
+
+// NewMockSurgeStore creates a new mock surge store.
+func NewMockSurgeStore() *MockSurgeStore {
+	return &MockSurgeStore{multipliers: make(map[string]float64)}
+}
+
+func (m *MockSurgeStore) GetMultiplier(ctx context.Context, cell string) (float64, bool, error) {
+	if m.GetError != nil {
+		return 0, false, m.GetError
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	multiplier, ok := m.multipliers[cell]
+	return multiplier, ok, nil
+}
+
+func (m *MockSurgeStore) SetMultiplier(ctx context.Context, cell string, multiplier float64, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.multipliers[cell] = multiplier
+	m.LastTTL = ttl
+	return nil
+}
+
+// Expire forgets every stored multiplier, as if the TTL had passed.
+func (m *MockSurgeStore) Expire() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.multipliers = make(map[string]float64)
+}
+
+// ──────────────────────────────────────────────
+// MOCK EXCLUSION STORE
+// ──────────────────────────────────────────────
+
+// MockExclusionStore is an in-memory per-ride driver exclusion store. TTLs
+// are recorded but never expire entries.
+type MockExclusionStore struct {
+	mu       sync.Mutex
+	excluded map[string]map[string]bool
+	LastTTL  time.Duration
+}
+
+// NewMockExclusionStore creates a new mock exclusion store.
+func NewMockExclusionStore() *MockExclusionStore {
+	return &MockExclusionStore{excluded: make(map[string]map[string]bool)}
+}
+
+func (m *MockExclusionStore) AddExcludedDrivers(ctx context.Context, rideID string, driverIDs []string, ttl time.Duration) error {
+	if len(driverIDs) == 0 {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.excluded[rideID] == nil {
+		m.excluded[rideID] = make(map[string]bool)
+	}
+	for _, id := range driverIDs {
+		m.excluded[rideID][id] = true
+	}
+	m.LastTTL = ttl
+	return nil
+}
+
+func (m *MockExclusionStore) ExcludedDrivers(ctx context.Context, rideID string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ids := make([]string, 0, len(m.excluded[rideID]))
+	for id := range m.excluded[rideID] {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// ──────────────────────────────────────────────
+// MOCK DESTINATION STORE
+// ──────────────────────────────────────────────
+
+// MockDestinationStore is an in-memory driver destination store. Entries
+// past their ExpiresAt read as missing, like an expired Redis key.
+type MockDestinationStore struct {
+	mu           sync.Mutex
+	destinations map[string]redis.DriverDestination
+	GetError     error
+}
+
+// NewMockDestinationStore creates a new mock destination store.
+func NewMockDestinationStore() *MockDestinationStore {
+	return &MockDestinationStore{destinations: make(map[string]redis.DriverDestination)}
+}
+
+func (m *MockDestinationStore) SetDestination(ctx context.Context, driverID string, d redis.DriverDestination) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.destinations[driverID] = d
+	return nil
+}
+
+func (m *MockDestinationStore) GetDestination(ctx context.Context, driverID string) (*redis.DriverDestination, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.GetError != nil {
+		return nil, m.GetError
+	}
+	d, ok := m.destinations[driverID]
+	if !ok || !time.Now().Before(d.ExpiresAt) {
+		return nil, nil
+	}
+	return &d, nil
+}
+
+func (m *MockDestinationStore) ClearDestination(ctx context.Context, driverID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.destinations, driverID)
+	return nil
+}
+
+// ──────────────────────────────────────────────
+// MOCK LOCATION GUARD STORE
+// ──────────────────────────────────────────────
+
+// MockLocationGuardStore is an in-memory speed guard store.
+type MockLocationGuardStore struct {
+	mu        sync.Mutex
+	fixes     map[string]redis.LocationFix
+	anomalies map[string]int64
+}
+
+// NewMockLocationGuardStore creates a new mock location guard store.
+func NewMockLocationGuardStore() *MockLocationGuardStore {
+	return &MockLocationGuardStore{fixes: make(map[string]redis.LocationFix), anomalies: make(map[string]int64)}
+}
+
+func (m *MockLocationGuardStore) LastFix(ctx context.Context, driverID string) (*redis.LocationFix, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	fix, ok := m.fixes[driverID]
+	if !ok {
+		return nil, nil
+	}
+	return &fix, nil
+}
+
+func (m *MockLocationGuardStore) SetLastFix(ctx context.Context, driverID string, fix redis.LocationFix) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fixes[driverID] = fix
+	return nil
+}
+
+func (m *MockLocationGuardStore) IncrementSpeedAnomalies(ctx context.Context, driverID string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.anomalies[driverID]++
+	return m.anomalies[driverID], nil
+}
+
+func (m *MockLocationGuardStore) SpeedAnomalies(ctx context.Context, driverID string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.anomalies[driverID], nil
+}
+
+func (m *MockLocationGuardStore) ListSpeedAnomalies(ctx context.Context, limit int) ([]redis.SpeedAnomalyCount, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	counts := make([]redis.SpeedAnomalyCount, 0, len(m.anomalies))
+	for driverID, count := range m.anomalies {
+		counts = append(counts, redis.SpeedAnomalyCount{DriverID: driverID, Count: count})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].DriverID < counts[j].DriverID
+	})
+	if limit > 0 && len(counts) > limit {
+		counts = counts[:limit]
+	}
+	return counts, nil
+}
+
+func (m *MockLocationGuardStore) ClearSpeedAnomalies(ctx context.Context, driverID string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, had := m.anomalies[driverID]
+	delete(m.anomalies, driverID)
+	return had, nil
+}
+
+// ──────────────────────────────────────────────
+// MOCK CACHE INVALIDATOR
+// ──────────────────────────────────────────────
+
+// MockCacheInvalidator records invalidated drivers and rides as
+// "<table>:<id>", e.g. "drivers:driver-1".
+type MockCacheInvalidator struct {
+	mu          sync.Mutex
+	invalidated []string
+}
+
+// NewMockCacheInvalidator creates a new mock cache invalidator.
+func NewMockCacheInvalidator() *MockCacheInvalidator {
+	return &MockCacheInvalidator{}
+}
+
+func (m *MockCacheInvalidator) InvalidateDriver(ctx context.Context, driverID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.invalidated = append(m.invalidated, "drivers:"+driverID)
+	return nil
+}
+
+func (m *MockCacheInvalidator) InvalidateRide(ctx context.Context, rideID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.invalidated = append(m.invalidated, "rides:"+rideID)
+	return nil
+}
+
+// Invalidated returns the invalidations so far, in order.
+func (m *MockCacheInvalidator) Invalidated() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.invalidated...)
+}
+
+// ──────────────────────────────────────────────
+// MOCK MATCH ATTEMPT REPOSITORY
+// ──────────────────────────────────────────────
+
+// MockMatchAttemptRepository is an in-memory match attempt store.
+type MockMatchAttemptRepository struct {
+	mu       sync.RWMutex
+	attempts []*domain.MatchAttempt
+}
+
+// NewMockMatchAttemptRepository creates a new mock match attempt repository.
+func NewMockMatchAttemptRepository() *MockMatchAttemptRepository {
+	return &MockMatchAttemptRepository{}
+}
+
+func (m *MockMatchAttemptRepository) Create(ctx context.Context, attempt *domain.MatchAttempt) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	copy := *attempt
+	m.attempts = append(m.attempts, &copy)
+	return nil
+}
+
+func (m *MockMatchAttemptRepository) ListByRide(ctx context.Context, rideID string) ([]*domain.MatchAttempt, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*domain.MatchAttempt
+	for _, a := range m.attempts {
+		if a.RideID == rideID {
+			copy := *a
+			result = append(result, &copy)
+		}
+	}
+	return result, nil
+}
+
+// ──────────────────────────────────────────────
+// MOCK LOCATION HISTORY REPOSITORY
+// ──────────────────────────────────────────────
+
+// MockLocationHistoryRepository is an in-memory location history store.
+type MockLocationHistoryRepository struct {
+	mu      sync.RWMutex
+	points  []*domain.LocationPoint
+	batches int
+}
+
+// NewMockLocationHistoryRepository creates a new mock location history repository.
+func NewMockLocationHistoryRepository() *MockLocationHistoryRepository {
+	return &MockLocationHistoryRepository{}
+}
+
+func (m *MockLocationHistoryRepository) CreateBatch(ctx context.Context, points []*domain.LocationPoint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.batches++
+	for _, p := range points {
+		copy := *p
+		m.points = append(m.points, &copy)
+	}
+	return nil
+}
+
+func (m *MockLocationHistoryRepository) ListByDriver(ctx context.Context, driverID string, from, to time.Time) ([]*domain.LocationPoint, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*domain.LocationPoint
+	for _, p := range m.points {
+		if p.DriverID == driverID && !p.RecordedAt.Before(from) && !p.RecordedAt.After(to) {
+			copy := *p
+			result = append(result, &copy)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].RecordedAt.Before(result[j].RecordedAt) })
+	return result, nil
+}
+
+func (m *MockLocationHistoryRepository) DeleteBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	kept := m.points[:0]
+	for _, p := range m.points {
+		if !p.RecordedAt.Before(cutoff) {
+			kept = append(kept, p)
+		}
+	}
+	removed := int64(len(m.points) - len(kept))
+	m.points = kept
+	return removed, nil
+}
+
+func (m *MockLocationHistoryRepository) OnlineHours(ctx context.Context, driverID string, from, to time.Time) (float64, error) {
+	hours := m.onlineHours(from, to)
+	return hours[driverID], nil
+}
+
+func (m *MockLocationHistoryRepository) ListOnlineHours(ctx context.Context, from, to time.Time, minHours float64) (map[string]float64, error) {
+	hours := m.onlineHours(from, to)
+	for driverID, h := range hours {
+		if h < minHours {
+			delete(hours, driverID)
+		}
+	}
+	return hours, nil
+}
+
+// onlineHours counts each driver's 5-minute slots in [from, to) with at
+// least one point, in hours.
+func (m *MockLocationHistoryRepository) onlineHours(from, to time.Time) map[string]float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	slots := make(map[string]map[int64]bool)
+	for _, p := range m.points {
+		if p.RecordedAt.Before(from) || !p.RecordedAt.Before(to) {
+			continue
+		}
+		if slots[p.DriverID] == nil {
+			slots[p.DriverID] = make(map[int64]bool)
+		}
+		slots[p.DriverID][p.RecordedAt.Unix()/300] = true
+	}
+	hours := make(map[string]float64, len(slots))
+	for driverID, s := range slots {
+		hours[driverID] = float64(len(s)) / 12
+	}
+	return hours
+}
+
+// Add stores a point directly, bypassing batching.
+func (m *MockLocationHistoryRepository) Add(point *domain.LocationPoint) {
+	_ = m.CreateBatch(context.Background(), []*domain.LocationPoint{point})
+}
+
+// Count returns the number of stored points.
+func (m *MockLocationHistoryRepository) Count() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.points)
+}
+
+// Batches returns the number of CreateBatch calls.
+func (m *MockLocationHistoryRepository) Batches() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.batches
+}
+
+// ──────────────────────────────────────────────
+// HELPER ERRORS
+// ──────────────────────────────────────────────
+
+var (
+	ErrMockDBConstraint = errors.New("mock: unique constraint violation")
+	ErrMockTimeout      = errors.New("mock: operation timeout")
+)
+
+// ──────────────────────────────────────────────
+// MOCK USER REPOSITORY
+// ──────────────────────────────────────────────
+
+// MockUserRepository is an in-memory user store.
+type MockUserRepository struct {
+	mu    sync.RWMutex
+	users map[string]*domain.User
+}
+
+// NewMockUserRepository creates a new mock user repository.
+func NewMockUserRepository() *MockUserRepository {
+	return &MockUserRepository{users: make(map[string]*domain.User)}
+}
+
+// AddUser adds a user to the mock repository.
+func (m *MockUserRepository) AddUser(user *domain.User) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	copy := *user
+	m.users[user.ID] = &copy
+}
+
+func (m *MockUserRepository) Create(ctx context.Context, user *domain.User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, u := range m.users {
+		if u.Phone == user.Phone || (user.Email != "" && u.Email == user.Email) {
+			return repository.ErrDuplicate
+		}
+	}
+	copy := *user
+	m.users[user.ID] = &copy
+	return nil
+}
+
+func (m *MockUserRepository) GetByID(ctx context.Context, id string) (*domain.User, error) {
+	return m.find(func(u *domain.User) bool { return u.ID == id })
+}
+
+func (m *MockUserRepository) GetByPhone(ctx context.Context, phone string) (*domain.User, error) {
+	return m.find(func(u *domain.User) bool { return u.Phone == phone })
+}
+
+func (m *MockUserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	return m.find(func(u *domain.User) bool { return u.Email == email })
+}
+
+func (m *MockUserRepository) find(match func(*domain.User) bool) (*domain.User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, u := range m.users {
+		if match(u) {
+			copy := *u
+			return &copy, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (m *MockUserRepository) GetAll(ctx context.Context) ([]*domain.User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := make([]*domain.User, 0, len(m.users))
+	for _, u := range m.users {
+		copy := *u
+		result = append(result, &copy)
+	}
+	return result, nil
+}
+
+func (m *MockUserRepository) UpdateEmail(ctx context.Context, id, email string, verified bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	user, ok := m.users[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	for _, u := range m.users {
+		if u.ID != id && email != "" && u.Email == email {
+			return repository.ErrDuplicate
+		}
+	}
+	user.Email = email
+	user.EmailVerified = verified
+	return nil
+}
+
+// ──────────────────────────────────────────────
+// MOCK EMAIL TOKEN STORE
+// ──────────────────────────────────────────────
+
+// MockEmailTokenStore is an in-memory verification token and resend
+// cooldown store. Entries expire against a clock the test can advance.
+type MockEmailTokenStore struct {
+	mu            sync.Mutex
+	now           time.Time
+	verifications map[string]mockEmailEntry
+	resends       map[string]time.Time // userID -> cooldown end
+}
+
+type mockEmailEntry struct {
+	verification redis.EmailVerification
+	expiresAt    time.Time
+}
+
+// NewMockEmailTokenStore creates a new mock email token store.
+func NewMockEmailTokenStore() *MockEmailTokenStore {
+	return &MockEmailTokenStore{
+		now:           time.Now(),
+		verifications: make(map[string]mockEmailEntry),
+		resends:       make(map[string]time.Time),
+	}
+}
+
+// Advance moves the store's clock forward, expiring tokens and cooldowns.
+func (m *MockEmailTokenStore) Advance(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.now = m.now.Add(d)
+}
+
+func (m *MockEmailTokenStore) SaveVerification(ctx context.Context, token string, v redis.EmailVerification, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.verifications[token] = mockEmailEntry{verification: v, expiresAt: m.now.Add(ttl)}
+	return nil
+}
+
+func (m *MockEmailTokenStore) ConsumeVerification(ctx context.Context, token string) (*redis.EmailVerification, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry, ok := m.verifications[token]
+	delete(m.verifications, token)
+	if !ok || !m.now.Before(entry.expiresAt) {
+		return nil, nil
+	}
+	v := entry.verification
+	return &v, nil
+}
+
+func (m *MockEmailTokenStore) AcquireResendSlot(ctx context.Context, userID string, cooldown time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if until, ok := m.resends[userID]; ok && m.now.Before(until) {
+		return false, nil
+	}
+	m.resends[userID] = m.now.Add(cooldown)
+	return true, nil
+}
+
+// ──────────────────────────────────────────────
+// MOCK NOTIFICATION THROTTLE STORE
+// ──────────────────────────────────────────────
+
+// MockNotificationThrottleStore is an in-memory notification cooldown store.
+// Cooldowns expire against a clock the test can advance.
+type MockNotificationThrottleStore struct {
+	mu    sync.Mutex
+	now   time.Time
+	slots map[string]time.Time // type:recipientID -> cooldown end
+
+	// AcquireError, when set, is returned by AcquireNotifySlot.
+	AcquireError error
+}
+
+// NewMockNotificationThrottleStore creates a new mock notification throttle store.
+func NewMockNotificationThrottleStore() *MockNotificationThrottleStore {
+	return &MockNotificationThrottleStore{now: time.Now(), slots: make(map[string]time.Time)}
+}
+
+// Advance moves the store's clock forward, expiring cooldowns.
+func (m *MockNotificationThrottleStore) Advance(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.now = m.now.Add(d)
+}
+
+func (m *MockNotificationThrottleStore) AcquireNotifySlot(ctx context.Context, recipientID, notificationType string, cooldown time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.AcquireError != nil {
+		return false, m.AcquireError
+	}
+	key := notificationType + ":" + recipientID
+	if until, ok := m.slots[key]; ok && m.now.Before(until) {
+		return false, nil
+	}
+	m.slots[key] = m.now.Add(cooldown)
+	return true, nil
+}
+
+// ──────────────────────────────────────────────
+// MOCK EMAIL SENDER
+// ──────────────────────────────────────────────
+
+// SentEmail is a message captured by MockEmailSender.
+type SentEmail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// MockEmailSender records the messages it is asked to send.
+type MockEmailSender struct {
+	mu   sync.Mutex
+	sent []SentEmail
+}
+
+// NewMockEmailSender creates a new mock email sender.
+func NewMockEmailSender() *MockEmailSender {
+	return &MockEmailSender{}
+}
+
+func (m *MockEmailSender) Send(ctx context.Context, to, subject, body string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, SentEmail{To: to, Subject: subject, Body: body})
+	return nil
+}
+
+// Sent returns the messages sent so far.
+func (m *MockEmailSender) Sent() []SentEmail {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]SentEmail(nil), m.sent...)
+}
+
+// ──────────────────────────────────────────────
+// MOCK QUOTE STORE
+// ──────────────────────────────────────────────
+
+// MockQuoteStore is an in-memory ride quote store. Quotes expire against a
+// clock the test can advance.
+type MockQuoteStore struct {
+	mu     sync.Mutex
+	now    time.Time
+	quotes map[string]mockQuoteEntry
+}
+
+type mockQuoteEntry struct {
+	quote     redis.RideQuote
+	expiresAt time.Time
+}
+
+// NewMockQuoteStore creates a new mock quote store.
+func NewMockQuoteStore() *MockQuoteStore {
+	return &MockQuoteStore{now: time.Now(), quotes: make(map[string]mockQuoteEntry)}
+}
+
+// Advance moves the store's clock forward, expiring quotes.
+func (m *MockQuoteStore) Advance(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.now = m.now.Add(d)
+}
+
+func (m *MockQuoteStore) SaveQuote(ctx context.Context, id string, q redis.RideQuote, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.quotes[id] = mockQuoteEntry{quote: q, expiresAt: m.now.Add(ttl)}
+	return nil
+}
+
+func (m *MockQuoteStore) ConsumeQuote(ctx context.Context, id string) (*redis.RideQuote, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry, ok := m.quotes[id]
+	delete(m.quotes, id)
+	if !ok || !m.now.Before(entry.expiresAt) {
+		return nil, nil
+	}
+	q := entry.quote
+	return &q, nil
+}
+
+// ──────────────────────────────────────────────
+// MOCK RIDE QUEUE STORE
+// ──────────────────────────────────────────────
+
+// MockRideQueueStore is an in-memory per-area ride queue store. Like a Redis
+// sorted set, rides with equal scores are ordered by ID. Recorded matches
+// are kept however old; MatchesSince filters them.
+type MockRideQueueStore struct {
+	mu         sync.Mutex
+	areas      map[string]string               // Ride ID -> area
+	scores     map[string]float64              // Ride ID -> score
+	matches    map[string]map[string]time.Time // Area -> ride ID -> match time
+	LastWindow time.Duration
+}
+
+// NewMockRideQueueStore creates a new mock ride queue store.
+func NewMockRideQueueStore() *MockRideQueueStore {
+	return &MockRideQueueStore{
+		areas:   make(map[string]string),
+		scores:  make(map[string]float64),
+		matches: make(map[string]map[string]time.Time),
+	}
+}
+
+func (m *MockRideQueueStore) Enqueue(ctx context.Context, area, rideID string, score float64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.areas[rideID]; ok {
+		return nil
+	}
+	m.areas[rideID] = area
+	m.scores[rideID] = score
+	return nil
+}
+
+func (m *MockRideQueueStore) Remove(ctx context.Context, rideID string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	area := m.areas[rideID]
+	delete(m.areas, rideID)
+	delete(m.scores, rideID)
+	return area, nil
+}
+
+func (m *MockRideQueueStore) Position(ctx context.Context, rideID string) (string, int64, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	area, ok := m.areas[rideID]
+	if !ok {
+		return "", 0, false, nil
+	}
+	var ahead int64
+	for id, score := range m.scores {
+		if m.areas[id] != area || id == rideID {
+			continue
+		}
+		if score < m.scores[rideID] || (score == m.scores[rideID] && id < rideID) {
+			ahead++
+		}
+	}
+	return area, ahead, true, nil
+}
+
+func (m *MockRideQueueStore) RecordMatch(ctx context.Context, area, rideID string, at time.Time, window time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.matches[area] == nil {
+		m.matches[area] = make(map[string]time.Time)
+	}
+	m.matches[area][rideID] = at
+	m.LastWindow = window
+	return nil
+}
+
+func (m *MockRideQueueStore) MatchesSince(ctx context.Context, area string, since time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var n int64
+	for _, at := range m.matches[area] {
+		if !at.Before(since) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// Queued returns the IDs of the queued rides, sorted.
+func (m *MockRideQueueStore) Queued() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ids := make([]string, 0, len(m.areas))
+	for id := range m.areas {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// ──────────────────────────────────────────────
+// MOCK EVENT PUBLISHER
+// ──────────────────────────────────────────────
+
+// MockEventPublisher records published domain events.
+type MockEventPublisher struct {
+	mu     sync.Mutex
+	events []*domain.Event
+
+	// PublishError, if set, is returned by every Publish after recording.
+	PublishError error
+}
+
+// NewMockEventPublisher creates a new mock event publisher.
+func NewMockEventPublisher() *MockEventPublisher {
+	return &MockEventPublisher{}
+}
+
+func (m *MockEventPublisher) Publish(ctx context.Context, event *domain.Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, event)
+	return m.PublishError
+}
+
+// Events returns the published events in order.
+func (m *MockEventPublisher) Events() []*domain.Event {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*domain.Event(nil), m.events...)
+}
+
+// Types returns the types of the published events in order.
+func (m *MockEventPublisher) Types() []domain.EventType {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	types := make([]domain.EventType, 0, len(m.events))
+	for _, event := range m.events {
+		types = append(types, event.Type)
+	}
+	return types
+}
+
+// ──────────────────────────────────────────────
+// MOCK FEATURE FLAG REPOSITORY
+// ──────────────────────────────────────────────
+
+// MockFeatureFlagRepository is an in-memory feature flag store.
+type MockFeatureFlagRepository struct {
+	mu    sync.Mutex
+	flags map[string]*domain.FeatureFlag
+
+	GetAllCallCount int32
+	GetAllError     error
+}
+
+// NewMockFeatureFlagRepository creates a new mock feature flag repository.
+func NewMockFeatureFlagRepository() *MockFeatureFlagRepository {
+	return &MockFeatureFlagRepository{flags: make(map[string]*domain.FeatureFlag)}
+}
+
+func (m *MockFeatureFlagRepository) Upsert(ctx context.Context, flag *domain.FeatureFlag) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	copy := *flag
+	m.flags[flag.Name] = &copy
+	return nil
+}
+
+func (m *MockFeatureFlagRepository) GetAll(ctx context.Context) ([]*domain.FeatureFlag, error) {
+	atomic.AddInt32(&m.GetAllCallCount, 1)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.GetAllError != nil {
+		return nil, m.GetAllError
+	}
+	flags := make([]*domain.FeatureFlag, 0, len(m.flags))
+	for _, flag := range m.flags {
+		copy := *flag
+		flags = append(flags, &copy)
+	}
+	sort.Slice(flags, func(i, j int) bool { return flags[i].Name < flags[j].Name })
+	return flags, nil
+}
+
+// ──────────────────────────────────────────────
+// MOCK TRACKER KEY REPOSITORY
+// ──────────────────────────────────────────────
+
+// MockTrackerKeyRepository is an in-memory tracker key repository.
+type MockTrackerKeyRepository struct {
+	mu      sync.RWMutex
+	secrets map[string]string // driverID -> encrypted secret
+}
+
+// NewMockTrackerKeyRepository creates a new mock tracker key repository.
+func NewMockTrackerKeyRepository() *MockTrackerKeyRepository {
+	return &MockTrackerKeyRepository{secrets: make(map[string]string)}
+}
+
+func (m *MockTrackerKeyRepository) Set(ctx context.Context, driverID, encryptedSecret string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.secrets[driverID] = encryptedSecret
+	return nil
+}
+
+func (m *MockTrackerKeyRepository) GetSecret(ctx context.Context, driverID string) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	encryptedSecret, ok := m.secrets[driverID]
+	if !ok {
+		return "", repository.ErrNotFound
+	}
+	return encryptedSecret, nil
+}
+
+// ──────────────────────────────────────────────
+// MOCK TRACKER NONCE STORE
+// ──────────────────────────────────────────────
+
+// MockTrackerNonceStore is an in-memory tracker nonce store. Nonces never
+// expire.
+type MockTrackerNonceStore struct {
+	mu     sync.Mutex
+	nonces map[string]time.Duration // driverID:nonce -> TTL it was claimed with
+}
+
+// NewMockTrackerNonceStore creates a new mock tracker nonce store.
+func NewMockTrackerNonceStore() *MockTrackerNonceStore {
+	return &MockTrackerNonceStore{nonces: make(map[string]time.Duration)}
+}
+
+func (m *MockTrackerNonceStore) ClaimNonce(ctx context.Context, driverID, nonce string, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := driverID + ":" + nonce
+	if _, ok := m.nonces[key]; ok {
+		return false, nil
+	}
+	m.nonces[key] = ttl
+	return true, nil
+}
+
+// TTL returns the TTL a nonce was claimed with; 0 if it was not.
+func (m *MockTrackerNonceStore) TTL(driverID, nonce string) time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.nonces[driverID+":"+nonce]
+}
+
+// ──────────────────────────────────────────────
+// MOCK TRIP ETA STORE
+// ──────────────────────────────────────────────
+
+// MockTripETAStore is an in-memory store of paused trips' remaining
+// distances. Frozen values never expire.
+type MockTripETAStore struct {
+	mu     sync.Mutex
+	frozen map[string]float64 // tripID:pausedAt -> remaining km
+}
+
+// NewMockTripETAStore creates a new mock trip ETA store.
+func NewMockTripETAStore() *MockTripETAStore {
+	return &MockTripETAStore{frozen: make(map[string]float64)}
+}
+
+func (m *MockTripETAStore) FrozenRemaining(ctx context.Context, tripID string, pausedAt time.Time) (float64, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	remainingKm, ok := m.frozen[fmt.Sprintf("%s:%d", tripID, pausedAt.UnixNano())]
+	return remainingKm, ok, nil
+}
+
+func (m *MockTripETAStore) FreezeRemaining(ctx context.Context, tripID string, pausedAt time.Time, remainingKm float64, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := fmt.Sprintf("%s:%d", tripID, pausedAt.UnixNano())
+	if _, ok := m.frozen[key]; !ok {
+		m.frozen[key] = remainingKm
+	}
+	return nil
+}
+
+// ──────────────────────────────────────────────
+// TEST ENVIRONMENT
+// ──────────────────────────────────────────────
+
+// testEnv is the shared wiring for service tests: one of each in-memory
+// repository and store the core services are built from, over a
+// RecordingDB. Tests seed the mocks, take the deps of the service under
+// test, set the fields their feature adds, and build it.
+type testEnv struct {
+	db            *sql.DB
+	rec           *RecordingDB
+	rides         *MockRideRepository
+	trips         *MockTripRepository
+	drivers       *MockDriverRepository
+	locations     *MockLocationStore
+	locks         *MockLockStore
+	attempts      *MockMatchAttemptRepository
+	payments      *MockPaymentRepository
+	psp           *MockPSP
+	notifications *MockNotificationRepository
+	users         *MockUserRepository
+	emails        *MockEmailSender
+	events        *MockEventPublisher
+}
+
+// newTestEnv creates a testEnv whose database is closed when the test ends.
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	db, rec := NewRecordingDB()
+	t.Cleanup(func() { _ = db.Close() })
+
+	env := &testEnv{
+		db:            db,
+		rec:           rec,
+		rides:         NewMockRideRepository(),
+		trips:         NewMockTripRepository(),
+		drivers:       NewMockDriverRepository(),
+		locations:     NewMockLocationStore(),
+		locks:         NewMockLockStore(),
+		attempts:      NewMockMatchAttemptRepository(),
+		payments:      NewMockPaymentRepository(),
+		psp:           NewMockPSP(),
+		notifications: NewMockNotificationRepository(),
+		users:         NewMockUserRepository(),
+		emails:        NewMockEmailSender(),
+		events:        NewMockEventPublisher(),
+	}
+	env.trips.Rides = env.rides
+	return env
+}
+
+// paymentService returns a PaymentService charging through the env's PSP.
+func (e *testEnv) paymentService() *service.PaymentService {
+	return service.NewPaymentService(e.payments, e.psp, nil, e.events, nil, 0)
+}
+
+// notificationService returns a NotificationService writing to the env's
+// outbox.
+func (e *testEnv) notificationService() *service.NotificationService {
+	return service.NewNotificationService(e.notifications, nil, service.DeepLinks{}, nil, nil, nil, nil)
+}
+
+// matchingDeps returns MatchingService dependencies backed by the env.
+func (e *testEnv) matchingDeps() service.MatchingServiceDeps {
+	return service.MatchingServiceDeps{
+		DB:            e.db,
+		LocationStore: e.locations,
+		LockStore:     e.locks,
+		DriverRepo:    e.drivers,
+		RideRepo:      e.rides,
+		AttemptRepo:   e.attempts,
+	}
+}
+
+// tripDeps returns TripService dependencies backed by the env. The location
+// store is left unset, so trips start without the pickup geofence check.
+func (e *testEnv) tripDeps() service.TripServiceDeps {
+	return service.TripServiceDeps{
+		DB:                  e.db,
+		TripRepo:            e.trips,
+		RideRepo:            e.rides,
+		DriverRepo:          e.drivers,
+		PaymentService:      e.paymentService(),
+		NotificationService: e.notificationService(),
+		Publisher:           e.events,
+	}
+}
+
+// rideDeps returns RideService dependencies backed by the env that match
+// rides with matcher.
+func (e *testEnv) rideDeps(matcher service.MatchingServiceInterface) service.RideServiceDeps {
+	return service.RideServiceDeps{RideRepo: e.rides, MatchingService: matcher, Publisher: e.events}
+}
+
+// driverService returns a DriverService over the env's drivers and locations.
+func (e *testEnv) driverService() *service.DriverService {
+	return service.NewDriverService(e.locations, nil, e.drivers, nil, nil, nil, nil, 0, nil)
+}
+
+// fakeClock is a settable clock for services that take one.
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.t }
+
+// Advance moves the clock forward by d.
+func (c *fakeClock) Advance(d time.Duration) { c.t = c.t.Add(d) }
+
+// newTestRouter returns an empty gin engine in test mode.
+func newTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	return gin.New()
+}
+
+// post sends a POST with body to router and returns the recorded response.
+func post(router *gin.Engine, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+	return w
+}
+
+// serveAs sends req to router on behalf of userID, anonymously when empty.
+func serveAs(router *gin.Engine, req *http.Request, userID string) *httptest.ResponseRecorder {
+	if userID != "" {
+		req.Header.Set("X-User-ID", userID)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// getAs sends a GET to router on behalf of userID.
+func getAs(router *gin.Engine, path, userID string) *httptest.ResponseRecorder {
+	return serveAs(router, httptest.NewRequest(http.MethodGet, path, nil), userID)
+}
+
+// postAs sends a POST with body to router on behalf of userID.
+func postAs(router *gin.Engine, path, body, userID string) *httptest.ResponseRecorder {
+	return serveAs(router, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)), userID)
+}
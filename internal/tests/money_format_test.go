@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/money"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// CURRENCY AND LOCALE FORMATTING
+// ──────────────────────────────────────────────
+
+func TestMoney_FormatsCurrencyForLocale(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		currency, locale string
+		amount           float64
+		want             string
+	}{
+		{"USD", "en-US", 12.5, "$12.50"},
+		{"USD", "en-US", 1234567.891, "$1,234,567.89"},
+		{"USD", "en-US", -3.2, "-$3.20"},
+		{"EUR", "de-DE", 1234.5, "1.234,50\u00a0€"},
+		{"EUR", "fr-FR", 987654.32, "987\u202f654,32\u00a0€"},
+		{"EUR", "en-GB", 999.999, "€1,000.00"},
+		{"INR", "en-IN", 1040, "₹1,040.00"},
+		{"INR", "en-IN", 10400000.5, "₹1,04,00,000.50"},
+		{"INR", "en-US", 10400000.5, "₹10,400,000.50"},
+		{"JPY", "en-US", 1500.4, "¥1,500"},
+		{"", "", 0, "$0.00"},
+	}
+	for _, tt := range tests {
+		f, err := money.NewFormatter(tt.currency, tt.locale)
+		if err != nil {
+			t.Fatalf("%s/%s: unexpected error: %v", tt.currency, tt.locale, err)
+		}
+		if got := f.Format(tt.amount); got != tt.want {
+			t.Errorf("%s/%s %v: expected %q, got %q", tt.currency, tt.locale, tt.amount, tt.want, got)
+		}
+	}
+
+	var unset *money.Formatter
+	if got := unset.Format(12.5); got != "$12.50" {
+		t.Errorf("expected a nil formatter to format US dollars, got %q", got)
+	}
+}
+
+func TestMoney_RejectsUnsupportedCurrencyAndLocale(t *testing.T) {
+	t.Parallel()
+
+	if _, err := money.NewFormatter("XYZ", "en-US"); err == nil {
+		t.Error("expected an error for an unknown currency")
+	}
+	if _, err := money.NewFormatter("USD", "xx-XX"); err == nil {
+		t.Error("expected an error for an unknown locale")
+	}
+}
+
+func TestMoney_ReceiptsAndNotificationsUseConfiguredCurrency(t *testing.T) {
+	t.Parallel()
+
+	formatter, err := money.NewFormatter("INR", "en-IN")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	notifications := NewMockNotificationRepository()
+	notificationService := service.NewNotificationService(notifications, nil, service.DeepLinks{}, nil, nil, formatter, nil)
+	receiptService := service.NewReceiptService(notificationService, nil, nil, formatter, nil)
+
+	started := time.Now().Add(-40 * time.Minute)
+	receipt, err := receiptService.GenerateReceipt(context.Background(), service.GenerateReceiptRequest{
+		Trip: &domain.Trip{ID: "trip-1", RideID: "ride-1", DriverID: "driver-1", Status: domain.TripStatusEnded, Fare: 1040, StartedAt: started, EndedAt: started.Add(40 * time.Minute)},
+		Ride: &domain.Ride{ID: "ride-1", RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 13.05, DestinationLng: 77.62, SurgeMultiplier: 1},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	text := receiptService.FormatReceipt(receipt)
+	if !strings.Contains(text, "TOTAL:            ₹1,040.00") {
+		t.Errorf("expected the total in rupees, got %s", text)
+	}
+	if strings.Contains(text, "$") {
+		t.Errorf("expected no dollar signs, got %s", text)
+	}
+
+	sent, _ := notifications.ListSince(context.Background(), "rider-1", 0, 100)
+	if len(sent) != 1 || !strings.Contains(sent[0].Message, "₹1,040.00") {
+		t.Errorf("expected the receipt-ready notification in rupees, got %d: %+v", len(sent), sent)
+	}
+}
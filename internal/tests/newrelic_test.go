@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	goredis "github.com/redis/go-redis/v9"
+
+	"ride/internal/app"
+	"ride/internal/config"
+	"ride/internal/middleware"
+)
+
+// ──────────────────────────────────────────────
+// NEW RELIC GRACEFUL DEGRADATION
+// ──────────────────────────────────────────────
+
+// inertNewRelicApp returns a non-nil application that never connects,
+// standing in for an agent that started but cannot report.
+func inertNewRelicApp(t *testing.T) *newrelic.Application {
+	t.Helper()
+	nrApp, err := newrelic.NewApplication(
+		newrelic.ConfigAppName("ride-test"),
+		newrelic.ConfigLicense("0123456789012345678901234567890123456789"),
+		newrelic.ConfigEnabled(false),
+	)
+	if err != nil {
+		t.Fatalf("failed to build inert New Relic app: %v", err)
+	}
+	return nrApp
+}
+
+func TestNewRelic_FailedInitReturnsNil(t *testing.T) {
+	t.Parallel()
+
+	// Licenses must be 40 characters; the agent rejects this one.
+	nrApp := app.NewNewRelicApp(config.NewRelicConfig{AppName: "ride", LicenseKey: "bad-key", Enabled: true})
+	if nrApp != nil {
+		t.Fatal("expected nil app when New Relic fails to initialize")
+	}
+
+	if app.NewNewRelicApp(config.NewRelicConfig{LicenseKey: "0123456789012345678901234567890123456789"}) != nil {
+		t.Error("expected nil app when New Relic is disabled")
+	}
+}
+
+func TestNewRelic_MiddlewareHandlesNilAndInertApp(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+	for name, nrApp := range map[string]*newrelic.Application{"nil": nil, "inert": inertNewRelicApp(t)} {
+		router := gin.New()
+		router.Use(middleware.NewRelicMiddleware(nrApp))
+		router.GET("/ping", func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"status": "ok"})
+		})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ping", nil))
+		if w.Code != http.StatusOK {
+			t.Errorf("%s app: expected 200, got %d", name, w.Code)
+		}
+	}
+}
+
+func TestNewRelic_RedisHookHandlesNilApp(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	for name, nrApp := range map[string]*newrelic.Application{"nil": nil, "inert": inertNewRelicApp(t)} {
+		hook := app.NewRedisHook(nrApp)
+
+		called := false
+		process := hook.ProcessHook(func(ctx context.Context, cmd goredis.Cmder) error {
+			called = true
+			return nil
+		})
+		if err := process(ctx, goredis.NewStatusCmd(ctx, "ping")); err != nil || !called {
+			t.Errorf("%s app: expected command to pass through, called=%v err=%v", name, called, err)
+		}
+
+		called = false
+		pipeline := hook.ProcessPipelineHook(func(ctx context.Context, cmds []goredis.Cmder) error {
+			called = true
+			return nil
+		})
+		if err := pipeline(ctx, []goredis.Cmder{goredis.NewStatusCmd(ctx, "ping")}); err != nil || !called {
+			t.Errorf("%s app: expected pipeline to pass through, called=%v err=%v", name, called, err)
+		}
+	}
+}
+
+func TestNewRelic_NewDatabaseHandlesNilApp(t *testing.T) {
+	t.Parallel()
+
+	// Nothing listens on port 1; NewDatabase must fail cleanly for either driver.
+	cfg := config.DatabaseConfig{Host: "127.0.0.1", Port: "1", User: "u", Password: "p", DBName: "d", SSLMode: "disable"}
+	for name, nrApp := range map[string]*newrelic.Application{"nil": nil, "inert": inertNewRelicApp(t)} {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		db, err := app.NewDatabase(ctx, cfg, nrApp, nil)
+		cancel()
+		if err == nil {
+			db.Close()
+			t.Errorf("%s app: expected connection error", name)
+		}
+	}
+}
@@ -0,0 +1,301 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/middleware"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// NOTIFICATION DISPATCH AND DEAD LETTERS
+// ──────────────────────────────────────────────
+
+// flakyEmailChannel records what it sends and fails while failing is set.
+type flakyEmailChannel struct {
+	mu      sync.Mutex
+	failing bool
+	sent    []string // Recipient IDs
+	tries   int
+}
+
+func (c *flakyEmailChannel) Channel() domain.NotificationChannel {
+	return domain.NotificationChannelEmail
+}
+
+func (c *flakyEmailChannel) Send(ctx context.Context, notification service.Notification) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tries++
+	if c.failing {
+		return errors.New("provider rejected recipient: 400")
+	}
+	c.sent = append(c.sent, notification.RecipientID)
+	return nil
+}
+
+func (c *flakyEmailChannel) setFailing(failing bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failing = failing
+}
+
+func (c *flakyEmailChannel) counts() (tries, sent int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tries, len(c.sent)
+}
+
+// newDispatcher queues env's notifications for email through channel and
+// dispatches them with 3 attempts, a 30s backoff capped at 1m, and an alert
+// at alertThreshold dead deliveries.
+func newDispatcher(env *testEnv, channel *flakyEmailChannel, deliveries *MockNotificationDeliveryRepository, clock *fakeClock, alertThreshold int) (*service.NotificationService, *service.NotificationDispatcher) {
+	channels := []service.NotificationChannelSender{channel}
+	notificationService := service.NewNotificationService(env.notifications, nil, service.DeepLinks{}, nil, channels, nil, deliveries)
+	dispatcher := service.NewNotificationDispatcher(deliveries, channels, notificationService, 3, 30*time.Second, time.Minute, alertThreshold, clock.Now)
+	return notificationService, dispatcher
+}
+
+func newDeadLetterRouter(dispatcher *service.NotificationDispatcher) *gin.Engine {
+	router := newTestRouter()
+	admin := router.Group("/v1/admin", middleware.AdminAuthMiddleware(testAdminToken))
+	h := handler.NewNotificationDeliveryHandler(dispatcher)
+	admin.GET("/notifications/dead", h.ListDead)
+	admin.POST("/notifications/:id/requeue", h.Requeue)
+	return router
+}
+
+// notifyQueued sends the rider a payment notification, which is queued for
+// email due at the wall-clock time, and catches clock up to it.
+func notifyQueued(t *testing.T, notificationService *service.NotificationService, clock *fakeClock, riderID string) {
+	t.Helper()
+
+	if err := notificationService.NotifyPaymentSuccess(context.Background(), &domain.Payment{ID: "payment-" + riderID, Amount: 12.5}, riderID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if now := time.Now(); now.After(clock.Now()) {
+		clock.t = now
+	}
+}
+
+func dispatchDue(t *testing.T, dispatcher *service.NotificationDispatcher) service.DispatchResult {
+	t.Helper()
+
+	result, err := dispatcher.DispatchDue(context.Background(), 100)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return result
+}
+
+// adminRequest issues an admin request and decodes the JSON response into
+// out.
+func adminRequest(t *testing.T, router *gin.Engine, method, path string, out any) int {
+	t.Helper()
+
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if out != nil && w.Code == http.StatusOK {
+		if err := json.Unmarshal(w.Body.Bytes(), out); err != nil {
+			t.Fatalf("unexpected body: %s", w.Body.String())
+		}
+	}
+	return w.Code
+}
+
+func TestNotificationDispatch_QueuedThenSent(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	channel := &flakyEmailChannel{}
+	deliveries := NewMockNotificationDeliveryRepository()
+	clock := &fakeClock{t: time.Now()}
+	notificationService, dispatcher := newDispatcher(env, channel, deliveries, clock, 0)
+	notifyQueued(t, notificationService, clock, "rider-1")
+
+	if _, sent := channel.counts(); sent != 0 {
+		t.Fatal("expected the email queued, not sent inline")
+	}
+	if d := deliveries.Get(1); d == nil || d.Status != domain.NotificationDeliveryPending {
+		t.Fatalf("expected a PENDING delivery, got %+v", d)
+	}
+
+	if result := dispatchDue(t, dispatcher); result.Sent != 1 {
+		t.Fatalf("expected 1 sent, got %+v", result)
+	}
+	if d := deliveries.Get(1); d.Status != domain.NotificationDeliverySent || d.Attempts != 0 {
+		t.Errorf("expected the delivery SENT on its first attempt, got %+v", d)
+	}
+
+	// A sent delivery is not sent again.
+	clock.Advance(time.Hour)
+	if result := dispatchDue(t, dispatcher); result != (service.DispatchResult{}) {
+		t.Errorf("expected nothing left to dispatch, got %+v", result)
+	}
+}
+
+func TestNotificationDispatch_RetriesWithBackoffThenDeadLetters(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	channel := &flakyEmailChannel{}
+	deliveries := NewMockNotificationDeliveryRepository()
+	clock := &fakeClock{t: time.Now()}
+	notificationService, dispatcher := newDispatcher(env, channel, deliveries, clock, 0)
+	channel.setFailing(true)
+	notifyQueued(t, notificationService, clock, "rider-1")
+
+	if result := dispatchDue(t, dispatcher); result.Retried != 1 {
+		t.Fatalf("expected a retry scheduled, got %+v", result)
+	}
+	d := deliveries.Get(1)
+	if d.Status != domain.NotificationDeliveryPending || d.Attempts != 1 || !d.NextAttemptAt.Equal(clock.Now().Add(30*time.Second)) {
+		t.Fatalf("expected a retry in 30s, got %+v", d)
+	}
+
+	// Not due yet.
+	clock.Advance(29 * time.Second)
+	if result := dispatchDue(t, dispatcher); result != (service.DispatchResult{}) {
+		t.Fatalf("expected nothing due before the backoff, got %+v", result)
+	}
+
+	// The second failure doubles the backoff.
+	clock.Advance(time.Second)
+	dispatchDue(t, dispatcher)
+	if d := deliveries.Get(1); d.Attempts != 2 || !d.NextAttemptAt.Equal(clock.Now().Add(time.Minute)) {
+		t.Fatalf("expected a retry in 1m, got %+v", d)
+	}
+
+	// The third is the last.
+	clock.Advance(time.Minute)
+	if result := dispatchDue(t, dispatcher); result.Dead != 1 {
+		t.Fatalf("expected the delivery dead-lettered, got %+v", result)
+	}
+	d = deliveries.Get(1)
+	if d.Status != domain.NotificationDeliveryDead || d.Attempts != 3 || d.LastError != "provider rejected recipient: 400" {
+		t.Errorf("expected a DEAD delivery with its last error, got %+v", d)
+	}
+
+	// Dead deliveries are never retried on their own.
+	clock.Advance(24 * time.Hour)
+	dispatchDue(t, dispatcher)
+	if tries, _ := channel.counts(); tries != 3 {
+		t.Errorf("expected 3 attempts in all, got %d", tries)
+	}
+}
+
+func TestNotificationDispatch_AlertsOpsOnceAtThreshold(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	channel := &flakyEmailChannel{}
+	deliveries := NewMockNotificationDeliveryRepository()
+	clock := &fakeClock{t: time.Now()}
+	notificationService, dispatcher := newDispatcher(env, channel, deliveries, clock, 2)
+	channel.setFailing(true)
+	for _, rider := range []string{"rider-1", "rider-2", "rider-3"} {
+		notifyQueued(t, notificationService, clock, rider)
+	}
+
+	// Every delivery fails three times, one pass per attempt.
+	for i := 0; i < 3; i++ {
+		dispatchDue(t, dispatcher)
+		clock.Advance(time.Minute)
+	}
+	if count, _ := deliveries.CountByStatus(context.Background(), domain.NotificationDeliveryDead); count != 3 {
+		t.Fatalf("expected 3 dead deliveries, got %d", count)
+	}
+
+	alerts, _ := env.notifications.ListSince(context.Background(), service.OpsRecipientID, 0, 100)
+	if len(alerts) != 1 || alerts[0].Type != string(service.NotificationDeadLetters) {
+		t.Fatalf("expected one dead-letter alert, got %+v", alerts)
+	}
+
+	// A fourth dead delivery does not alert again while over the threshold.
+	notifyQueued(t, notificationService, clock, "rider-4")
+	for i := 0; i < 3; i++ {
+		dispatchDue(t, dispatcher)
+		clock.Advance(time.Minute)
+	}
+	if alerts, _ := env.notifications.ListSince(context.Background(), service.OpsRecipientID, 0, 100); len(alerts) != 1 {
+		t.Errorf("expected no repeat alert, got %d", len(alerts))
+	}
+}
+
+func TestNotificationDispatch_RequeuedDeliverySucceeds(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	channel := &flakyEmailChannel{}
+	deliveries := NewMockNotificationDeliveryRepository()
+	clock := &fakeClock{t: time.Now()}
+	notificationService, dispatcher := newDispatcher(env, channel, deliveries, clock, 0)
+	router := newDeadLetterRouter(dispatcher)
+	channel.setFailing(true)
+	notifyQueued(t, notificationService, clock, "rider-1")
+	for i := 0; i < 3; i++ {
+		dispatchDue(t, dispatcher)
+		clock.Advance(time.Minute)
+	}
+
+	var dead []handler.NotificationDeliveryResponse
+	if code := adminRequest(t, router, http.MethodGet, "/v1/admin/notifications/dead", &dead); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if len(dead) != 1 || dead[0].ID != 1 || dead[0].Attempts != 3 || dead[0].LastError == "" {
+		t.Fatalf("expected the dead delivery listed, got %+v", dead)
+	}
+
+	// The provider recovers; an admin requeues the delivery, twice.
+	channel.setFailing(false)
+	for i := 0; i < 2; i++ {
+		var requeued handler.NotificationDeliveryResponse
+		if code := adminRequest(t, router, http.MethodPost, "/v1/admin/notifications/1/requeue", &requeued); code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", code)
+		}
+		if requeued.Status != string(domain.NotificationDeliveryPending) || requeued.Attempts != 0 {
+			t.Errorf("expected the delivery PENDING with fresh attempts, got %+v", requeued)
+		}
+	}
+
+	if result := dispatchDue(t, dispatcher); result.Sent != 1 {
+		t.Fatalf("expected the requeued delivery sent once, got %+v", result)
+	}
+	if _, sent := channel.counts(); sent != 1 {
+		t.Errorf("expected one email, got %d", sent)
+	}
+
+	// Requeuing a sent delivery leaves it sent.
+	var sent handler.NotificationDeliveryResponse
+	adminRequest(t, router, http.MethodPost, "/v1/admin/notifications/1/requeue", &sent)
+	if sent.Status != string(domain.NotificationDeliverySent) {
+		t.Errorf("expected the sent delivery unchanged, got %+v", sent)
+	}
+	clock.Advance(time.Minute)
+	if result := dispatchDue(t, dispatcher); result != (service.DispatchResult{}) {
+		t.Errorf("expected nothing to dispatch, got %+v", result)
+	}
+
+	if code := adminRequest(t, router, http.MethodGet, "/v1/admin/notifications/dead", &dead); code != http.StatusOK || len(dead) != 0 {
+		t.Errorf("expected no dead deliveries, got %d %+v", code, dead)
+	}
+	if code := adminRequest(t, router, http.MethodPost, "/v1/admin/notifications/99/requeue", nil); code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown delivery, got %d", code)
+	}
+	if code := adminRequest(t, router, http.MethodPost, "/v1/admin/notifications/abc/requeue", nil); code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a malformed id, got %d", code)
+	}
+}
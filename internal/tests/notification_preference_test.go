@@ -0,0 +1,243 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/middleware"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// NOTIFICATION PREFERENCES
+// ──────────────────────────────────────────────
+
+// newPreferenceService sends push and email notifications to rider-1 and
+// driver-1, who both have verified email addresses, honouring preferences.
+func newPreferenceService(env *testEnv, preferences *MockNotificationPreferenceRepository) *service.NotificationService {
+	env.users.AddUser(&domain.User{ID: "rider-1", Name: "Rider", Email: "rider@example.com", EmailVerified: true})
+	env.drivers.AddDriver(&domain.Driver{ID: "driver-1", Email: "driver@example.com", EmailVerified: true, Tier: domain.DriverTierBasic})
+
+	email := service.NewEmailNotificationChannel(env.users, env.drivers, env.emails)
+	return service.NewNotificationService(env.notifications, nil, service.DeepLinks{}, preferences,
+		[]service.NotificationChannelSender{email}, nil, nil)
+}
+
+func mute(t *testing.T, notificationService *service.NotificationService, recipientID string, notificationType service.NotificationType, channel domain.NotificationChannel) {
+	t.Helper()
+
+	_, err := notificationService.UpdatePreferences(context.Background(), recipientID, service.NotificationPreferences{
+		notificationType: {channel: false},
+	})
+	if err != nil {
+		t.Fatalf("failed to mute %s over %s: %v", notificationType, channel, err)
+	}
+}
+
+// pushed returns the types pushed to the recipient, oldest first.
+func pushed(env *testEnv, recipientID string) []string {
+	events, _ := env.notifications.ListSince(context.Background(), recipientID, 0, 100)
+	types := make([]string, 0, len(events))
+	for _, e := range events {
+		types = append(types, e.Type)
+	}
+	return types
+}
+
+// emailed returns the subjects emailed to the address, oldest first.
+func emailed(env *testEnv, to string) []string {
+	var subjects []string
+	for _, e := range env.emails.Sent() {
+		if e.To == to {
+			subjects = append(subjects, e.Subject)
+		}
+	}
+	return subjects
+}
+
+func payBoth(notificationService *service.NotificationService) {
+	ctx := context.Background()
+	payment := &domain.Payment{ID: "payment-1", TripID: "trip-1", Amount: 20}
+	_ = notificationService.NotifyPaymentSuccess(ctx, payment, "rider-1")
+	_ = notificationService.NotifyPaymentFailed(ctx, payment, "rider-1")
+}
+
+func TestNotificationPreferences_DefaultSendsEverywhere(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	preferenceRepo := NewMockNotificationPreferenceRepository()
+	notificationService := newPreferenceService(env, preferenceRepo)
+
+	preferences, err := notificationService.GetPreferences(context.Background(), "rider-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for notificationType, channels := range preferences {
+		for _, channel := range domain.NotificationChannels {
+			if !channels[channel] {
+				t.Errorf("expected %s on over %s by default", notificationType, channel)
+			}
+		}
+	}
+	if len(preferences[service.NotificationPaymentSuccess]) != len(domain.NotificationChannels) {
+		t.Errorf("expected every channel listed, got %v", preferences[service.NotificationPaymentSuccess])
+	}
+
+	payBoth(notificationService)
+	if got := pushed(env, "rider-1"); strings.Join(got, ",") != "PAYMENT_SUCCESS,PAYMENT_FAILED" {
+		t.Errorf("expected both payments pushed, got %v", got)
+	}
+	if got := emailed(env, "rider@example.com"); strings.Join(got, ",") != "Payment Successful,Payment Failed" {
+		t.Errorf("expected both payments emailed, got %v", got)
+	}
+}
+
+func TestNotificationPreferences_MutedPushStillEmails(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	preferenceRepo := NewMockNotificationPreferenceRepository()
+	notificationService := newPreferenceService(env, preferenceRepo)
+	mute(t, notificationService, "rider-1", service.NotificationPaymentSuccess, domain.NotificationChannelPush)
+
+	payBoth(notificationService)
+	if got := pushed(env, "rider-1"); strings.Join(got, ",") != "PAYMENT_FAILED" {
+		t.Errorf("expected only the failed payment pushed, got %v", got)
+	}
+	if got := emailed(env, "rider@example.com"); strings.Join(got, ",") != "Payment Successful,Payment Failed" {
+		t.Errorf("expected both payments still emailed, got %v", got)
+	}
+}
+
+func TestNotificationPreferences_MutedEmailStillPushes(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	preferenceRepo := NewMockNotificationPreferenceRepository()
+	notificationService := newPreferenceService(env, preferenceRepo)
+	mute(t, notificationService, "rider-1", service.NotificationPaymentFailed, domain.NotificationChannelEmail)
+
+	payBoth(notificationService)
+	if got := pushed(env, "rider-1"); strings.Join(got, ",") != "PAYMENT_SUCCESS,PAYMENT_FAILED" {
+		t.Errorf("expected both payments pushed, got %v", got)
+	}
+	if got := emailed(env, "rider@example.com"); strings.Join(got, ",") != "Payment Successful" {
+		t.Errorf("expected only the successful payment emailed, got %v", got)
+	}
+}
+
+func TestNotificationPreferences_DriverPreferencesApply(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	preferenceRepo := NewMockNotificationPreferenceRepository()
+	notificationService := newPreferenceService(env, preferenceRepo)
+	mute(t, notificationService, "driver-1", service.NotificationCampaignBonus, domain.NotificationChannelEmail)
+
+	campaign := &domain.Campaign{ID: "campaign-1", Name: "Weekend", RewardAmount: 20}
+	_ = notificationService.NotifyCampaignBonus(context.Background(), campaign, "driver-1")
+	if got := pushed(env, "driver-1"); len(got) != 1 {
+		t.Errorf("expected the bonus pushed to the driver, got %v", got)
+	}
+	if got := emailed(env, "driver@example.com"); len(got) != 0 {
+		t.Errorf("expected no bonus email, got %v", got)
+	}
+
+	// Muting the driver's bonus does not mute the rider's.
+	_ = notificationService.NotifyCampaignBonus(context.Background(), campaign, "rider-1")
+	if got := emailed(env, "rider@example.com"); len(got) != 1 {
+		t.Errorf("expected the rider's bonus emailed, got %v", got)
+	}
+}
+
+func TestNotificationPreferences_UnreadablePreferencesSendEverywhere(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	preferenceRepo := NewMockNotificationPreferenceRepository()
+	notificationService := newPreferenceService(env, preferenceRepo)
+	mute(t, notificationService, "rider-1", service.NotificationPaymentSuccess, domain.NotificationChannelPush)
+	preferenceRepo.ListError = errors.New("connection refused")
+
+	payBoth(notificationService)
+	if got := pushed(env, "rider-1"); len(got) != 2 {
+		t.Errorf("expected both payments pushed while preferences are unreadable, got %v", got)
+	}
+}
+
+func TestNotificationPreferences_UnknownTypeOrChannelRejected(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	preferenceRepo := NewMockNotificationPreferenceRepository()
+	notificationService := newPreferenceService(env, preferenceRepo)
+	ctx := context.Background()
+
+	for _, changes := range []service.NotificationPreferences{
+		{"NOT_A_TYPE": {domain.NotificationChannelPush: false}},
+		{service.NotificationPaymentSuccess: {"PIGEON": false}},
+	} {
+		if _, err := notificationService.UpdatePreferences(ctx, "rider-1", changes); !errors.Is(err, service.ErrInvalidNotificationPreference) {
+			t.Errorf("expected ErrInvalidNotificationPreference for %v, got %v", changes, err)
+		}
+	}
+}
+
+func TestNotificationPreferences_HTTP(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	preferenceRepo := NewMockNotificationPreferenceRepository()
+	notificationService := newPreferenceService(env, preferenceRepo)
+	preferenceHandler := handler.NewNotificationPreferenceHandler(notificationService)
+
+	router := newTestRouter()
+	router.Use(middleware.IdentityMiddleware(testAdminToken))
+	router.GET("/v1/users/:id/notification-preferences", preferenceHandler.Get)
+	router.PUT("/v1/users/:id/notification-preferences", preferenceHandler.Update)
+	router.PUT("/v1/drivers/:id/notification-preferences", preferenceHandler.Update)
+
+	do := func(method, path, userID, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User-ID", userID)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodPut, "/v1/users/rider-1/notification-preferences", "rider-1", `{"preferences":{"PAYMENT_SUCCESS":{"PUSH":false}}}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp handler.NotificationPreferencesResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Preferences["PAYMENT_SUCCESS"]["PUSH"] || !resp.Preferences["PAYMENT_SUCCESS"]["EMAIL"] || !resp.Preferences["PAYMENT_FAILED"]["PUSH"] {
+		t.Errorf("expected only PAYMENT_SUCCESS push off, got %v", resp.Preferences)
+	}
+
+	w = do(http.MethodGet, "/v1/users/rider-1/notification-preferences", "rider-1", "")
+	resp = handler.NotificationPreferencesResponse{}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusOK || resp.Preferences["PAYMENT_SUCCESS"]["PUSH"] {
+		t.Errorf("expected the muted push returned, got %d %s", w.Code, w.Body.String())
+	}
+
+	if w := do(http.MethodGet, "/v1/users/rider-1/notification-preferences", "rider-2", ""); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for another rider's preferences, got %d", w.Code)
+	}
+	if w := do(http.MethodPut, "/v1/users/rider-1/notification-preferences", "rider-1", `{"preferences":{"PAYMENT_SUCCESS":{"SMS":false}}}`); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown channel, got %d", w.Code)
+	}
+	if w := do(http.MethodPut, "/v1/drivers/driver-1/notification-preferences", "driver-1", `{"preferences":{"RIDE_REQUESTED":{"EMAIL":false}}}`); w.Code != http.StatusOK {
+		t.Errorf("expected 200 for the driver's own preferences, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,223 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/redis"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// ADMIN OPS MAP
+// ──────────────────────────────────────────────
+
+// opsMapBox covers central Bangalore.
+var opsMapBox = domain.BoundingBox{MinLat: 12.9, MinLng: 77.5, MaxLat: 13.0, MaxLng: 77.7}
+
+// seedOpsMap adds n online drivers and n requested rides inside opsMapBox,
+// created a minute apart so rides sort by index.
+func seedOpsMap(env *testEnv, n int) {
+	start := time.Now().Add(-time.Hour)
+	for i := 0; i < n; i++ {
+		id := fmt.Sprintf("driver-%02d", i)
+		env.drivers.AddDriver(&domain.Driver{ID: id, Status: domain.DriverStatusOnline, Tier: domain.DriverTierBasic})
+		env.locations.AddDriverLocation(redis.DriverLocation{DriverID: id, Lat: 12.95, Lng: 77.51 + float64(i)*0.001})
+		env.rides.AddRide(&domain.Ride{
+			ID: fmt.Sprintf("ride-%02d", i), RiderID: "rider-1", PickupLat: 12.95, PickupLng: 77.6,
+			Status: domain.RideStatusRequested, CreatedAt: start.Add(time.Duration(i) * time.Minute),
+		})
+	}
+}
+
+func TestOpsMap_FiltersAndEnriches(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	seedOpsMap(env, 2)
+	env.drivers.AddDriver(&domain.Driver{ID: "driver-premium", Status: domain.DriverStatusOnTrip, Tier: domain.DriverTierPremium})
+	env.locations.AddDriverLocation(redis.DriverLocation{DriverID: "driver-premium", Lat: 12.99, Lng: 77.69, Heading: 90})
+	env.drivers.AddDriver(&domain.Driver{ID: "driver-offline", Status: domain.DriverStatusOffline, Tier: domain.DriverTierBasic})
+	env.locations.AddDriverLocation(redis.DriverLocation{DriverID: "driver-offline", Lat: 12.95, Lng: 77.6})
+	env.locations.AddDriverLocation(redis.DriverLocation{DriverID: "driver-deleted", Lat: 12.95, Lng: 77.6})
+	env.drivers.AddDriver(&domain.Driver{ID: "driver-outside", Status: domain.DriverStatusOnline, Tier: domain.DriverTierBasic})
+	env.locations.AddDriverLocation(redis.DriverLocation{DriverID: "driver-outside", Lat: 13.2, Lng: 77.6})
+
+	env.rides.AddRide(&domain.Ride{ID: "ride-assigned", PickupLat: 12.91, PickupLng: 77.55, Status: domain.RideStatusAssigned, Tier: domain.DriverTierPremium})
+	env.rides.AddRide(&domain.Ride{ID: "ride-in-trip", PickupLat: 12.95, PickupLng: 77.6, Status: domain.RideStatusInTrip})
+	env.rides.AddRide(&domain.Ride{ID: "ride-outside", PickupLat: 12.95, PickupLng: 77.8, Status: domain.RideStatusRequested})
+
+	svc := service.NewOpsMapService(env.locations, nil, env.drivers, env.rides, 10, 100)
+	opsMap, err := svc.Map(context.Background(), opsMapBox)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if opsMap.DriversSampled || opsMap.RequestsSampled {
+		t.Error("expected no down-sampling under the cap")
+	}
+	gotDrivers := make(map[string]service.MapDriver)
+	for _, d := range opsMap.Drivers {
+		gotDrivers[d.DriverID] = d
+	}
+	if len(gotDrivers) != 3 {
+		t.Fatalf("expected 3 drivers on the map, got %v", opsMap.Drivers)
+	}
+	premium := gotDrivers["driver-premium"]
+	if premium.Status != domain.DriverStatusOnTrip || premium.Tier != domain.DriverTierPremium || premium.Heading != 90 {
+		t.Errorf("expected the premium driver enriched, got %+v", premium)
+	}
+	for _, id := range []string{"driver-offline", "driver-deleted", "driver-outside"} {
+		if _, ok := gotDrivers[id]; ok {
+			t.Errorf("expected %s left off the map", id)
+		}
+	}
+
+	if opsMap.RequestCount != 3 || len(opsMap.Requests) != 3 {
+		t.Fatalf("expected 3 open requests, got %d: %+v", opsMap.RequestCount, opsMap.Requests)
+	}
+	for _, r := range opsMap.Requests {
+		if r.RideID == "ride-in-trip" || r.RideID == "ride-outside" {
+			t.Errorf("expected %s left off the map", r.RideID)
+		}
+	}
+}
+
+func TestOpsMap_DownSamplesEvenly(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	seedOpsMap(env, 10)
+	svc := service.NewOpsMapService(env.locations, nil, env.drivers, env.rides, 4, 100)
+
+	opsMap, err := svc.Map(context.Background(), opsMapBox)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if opsMap.DriverCount != 10 || !opsMap.DriversSampled || len(opsMap.Drivers) != 4 {
+		t.Fatalf("expected 4 of 10 drivers, sampled; got %d of %d, sampled=%v",
+			len(opsMap.Drivers), opsMap.DriverCount, opsMap.DriversSampled)
+	}
+	if opsMap.RequestCount != 10 || !opsMap.RequestsSampled || len(opsMap.Requests) != 4 {
+		t.Fatalf("expected 4 of 10 requests, sampled; got %d of %d, sampled=%v",
+			len(opsMap.Requests), opsMap.RequestCount, opsMap.RequestsSampled)
+	}
+
+	// Every (10/4)th point, so the sample spans the whole layer, not its head.
+	wantDrivers := []string{"driver-00", "driver-02", "driver-05", "driver-07"}
+	wantRides := []string{"ride-00", "ride-02", "ride-05", "ride-07"}
+	for i := range wantDrivers {
+		if opsMap.Drivers[i].DriverID != wantDrivers[i] {
+			t.Errorf("driver %d: expected %s, got %s", i, wantDrivers[i], opsMap.Drivers[i].DriverID)
+		}
+		if opsMap.Requests[i].RideID != wantRides[i] {
+			t.Errorf("request %d: expected %s, got %s", i, wantRides[i], opsMap.Requests[i].RideID)
+		}
+	}
+}
+
+func TestOpsMap_FetchLimit(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	seedOpsMap(env, 10)
+
+	// The fetch limit bounds what is read; counts report what was read.
+	svc := service.NewOpsMapService(env.locations, nil, env.drivers, env.rides, 2, 6)
+	opsMap, err := svc.Map(context.Background(), opsMapBox)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if env.locations.LastBoxLimit != 6 {
+		t.Errorf("expected the GEO search capped at 6, got %d", env.locations.LastBoxLimit)
+	}
+	if opsMap.DriverCount != 6 || opsMap.RequestCount != 6 || len(opsMap.Drivers) != 2 || len(opsMap.Requests) != 2 {
+		t.Errorf("expected 2 of 6 per layer, got %d of %d drivers and %d of %d requests",
+			len(opsMap.Drivers), opsMap.DriverCount, len(opsMap.Requests), opsMap.RequestCount)
+	}
+
+	// A fetch limit below the point cap is raised to it.
+	svc = service.NewOpsMapService(env.locations, nil, env.drivers, env.rides, 8, 3)
+	if _, err := svc.Map(context.Background(), opsMapBox); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if env.locations.LastBoxLimit != 8 {
+		t.Errorf("expected the fetch limit raised to the cap of 8, got %d", env.locations.LastBoxLimit)
+	}
+}
+
+func TestOpsMap_InvalidBox(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	seedOpsMap(env, 1)
+	svc := service.NewOpsMapService(env.locations, nil, env.drivers, env.rides, 0, 0)
+
+	boxes := []domain.BoundingBox{
+		{MinLat: 13.0, MinLng: 77.5, MaxLat: 12.9, MaxLng: 77.7},    // inverted latitude
+		{MinLat: 12.9, MinLng: 77.7, MaxLat: 13.0, MaxLng: 77.5},    // inverted longitude
+		{MinLat: 12.9, MinLng: 77.5, MaxLat: 91.0, MaxLng: 77.7},    // out of range
+		{MinLat: 12.9, MinLng: 170.0, MaxLat: 13.0, MaxLng: -170.0}, // crosses the antimeridian
+	}
+	for _, box := range boxes {
+		if _, err := svc.Map(context.Background(), box); !errors.Is(err, service.ErrInvalidBoundingBox) {
+			t.Errorf("%+v: expected ErrInvalidBoundingBox, got %v", box, err)
+		}
+	}
+}
+
+func TestOpsMapHandler_GetMap(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	seedOpsMap(env, 3)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/v1/admin/map", handler.NewOpsMapHandler(service.NewOpsMapService(env.locations, nil, env.drivers, env.rides, 2, 0)).GetMap)
+
+	testCases := []struct {
+		query    string
+		wantCode int
+	}{
+		{"min_lat=12.9&min_lng=77.5&max_lat=13.0&max_lng=77.7", http.StatusOK},
+		{"min_lat=12.9&min_lng=77.5&max_lat=13.0", http.StatusBadRequest},
+		{"min_lat=north&min_lng=77.5&max_lat=13.0&max_lng=77.7", http.StatusBadRequest},
+		{"min_lat=13.0&min_lng=77.5&max_lat=12.9&max_lng=77.7", http.StatusBadRequest},
+	}
+	for _, tc := range testCases {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/admin/map?"+tc.query, nil))
+		if w.Code != tc.wantCode {
+			t.Errorf("%s: expected %d, got %d: %s", tc.query, tc.wantCode, w.Code, w.Body.String())
+			continue
+		}
+		if tc.wantCode != http.StatusOK {
+			continue
+		}
+
+		var resp handler.OpsMapResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("invalid response: %v", err)
+		}
+		if len(resp.Drivers) != 2 || resp.DriverCount != 3 || !resp.DriversSampled {
+			t.Errorf("expected 2 of 3 drivers, sampled; got %+v", resp)
+		}
+		if len(resp.Requests) != 2 || resp.RequestCount != 3 || !resp.RequestsSampled {
+			t.Errorf("expected 2 of 3 requests, sampled; got %+v", resp)
+		}
+		if resp.Drivers[0].Status != "ONLINE" || resp.Drivers[0].Tier != "BASIC" || resp.Requests[0].Status != "REQUESTED" {
+			t.Errorf("expected status and tier on every point, got %+v", resp)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/service"
+	"ride/pkg/api"
+	"ride/pkg/rideclient"
+)
+
+// ──────────────────────────────────────────────
+// PAYMENT FAILURE REASONS AND RETRIES
+// ──────────────────────────────────────────────
+
+func TestPaymentFailure_DeclineReasonRecorded(t *testing.T) {
+	t.Parallel()
+
+	payments, psp := NewMockPaymentRepository(), NewMockPSP()
+	psp.SetFailure(false, &service.PaymentDeclinedError{Reason: "insufficient funds"})
+	paymentService := service.NewPaymentService(payments, psp, nil, nil, nil, 0)
+
+	payment, err := paymentService.ProcessPayment(context.Background(), service.ProcessPaymentRequest{
+		TripID: "trip-1", Amount: 20, Method: domain.PaymentMethodCard,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if payment.Status != domain.PaymentStatusFailed || payment.FailureReason != "insufficient funds" || !payment.Declined() {
+		t.Errorf("expected a declined payment with the PSP's reason, got %+v", payment)
+	}
+	if stored := payments.GetPaymentByTripID("trip-1"); stored.FailureReason != "insufficient funds" {
+		t.Errorf("expected the reason stored, got %q", stored.FailureReason)
+	}
+}
+
+func TestPaymentFailure_ReasonsWithoutPSPReason(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	declining, unreachable := NewMockPSP(), NewMockPSP()
+	declining.SetFailure(true, nil)
+	unreachable.SetFailure(false, errors.New("connection refused"))
+
+	payment, _ := service.NewPaymentService(NewMockPaymentRepository(), declining, nil, nil, nil, 0).
+		ProcessPayment(ctx, service.ProcessPaymentRequest{TripID: "trip-1", Amount: 20})
+	if payment.FailureReason != domain.PaymentFailureDeclined || !payment.Declined() {
+		t.Errorf("expected a plain decline, got %+v", payment)
+	}
+
+	payment, _ = service.NewPaymentService(NewMockPaymentRepository(), unreachable, nil, nil, nil, 0).
+		ProcessPayment(ctx, service.ProcessPaymentRequest{TripID: "trip-1", Amount: 20})
+	if payment.FailureReason != domain.PaymentFailureProviderError || payment.Declined() {
+		t.Errorf("expected a provider failure, not a decline, got %+v", payment)
+	}
+}
+
+func TestPaymentFailure_RetryChargesFailedPaymentAgain(t *testing.T) {
+	t.Parallel()
+
+	payments, psp := NewMockPaymentRepository(), NewMockPSP()
+	psp.SetFailure(true, nil)
+	paymentService := service.NewPaymentService(payments, psp, nil, nil, nil, 0)
+	ctx := context.Background()
+	req := service.ProcessPaymentRequest{TripID: "trip-1", Amount: 20}
+
+	failed, _ := paymentService.ProcessPayment(ctx, req)
+
+	psp.SetFailure(false, nil)
+	paid, err := paymentService.ProcessPayment(ctx, req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if paid.ID != failed.ID || paid.Status != domain.PaymentStatusSuccess || paid.FailureReason != "" {
+		t.Errorf("expected the same payment charged successfully, got %+v", paid)
+	}
+
+	// Paid now, so further calls return it without charging.
+	_, _ = paymentService.ProcessPayment(ctx, req)
+	if got := atomic.LoadInt32(&psp.ChargeCallCount); got != 2 || payments.CountPayments() != 1 {
+		t.Errorf("expected 2 charges of 1 payment, got %d charges of %d", got, payments.CountPayments())
+	}
+}
+
+func TestPaymentFailure_DeclinesWithReasonDoNotTripBreaker(t *testing.T) {
+	t.Parallel()
+
+	mock := NewMockPSP()
+	mock.SetFailure(false, &service.PaymentDeclinedError{Reason: "card expired"})
+	psp := service.NewResilientPSP(mock, time.Second, 2, time.Millisecond, 2, time.Minute)
+
+	for i := 0; i < 4; i++ {
+		var declined *service.PaymentDeclinedError
+		if _, err := psp.Charge(context.Background(), "tok", 10); !errors.As(err, &declined) {
+			t.Fatalf("expected the decline passed through, got %v", err)
+		}
+	}
+	if got := atomic.LoadInt32(&mock.ChargeCallCount); got != 4 {
+		t.Errorf("expected every decline to reach the PSP once, got %d calls", got)
+	}
+}
+
+// endDeclinedTrip runs a ride to the end of its trip over HTTP with the PSP
+// declining for insufficient funds.
+func endDeclinedTrip(t *testing.T, env *testEnv, server *httptest.Server) *api.TripResponse {
+	t.Helper()
+
+	ctx := context.Background()
+	rider, driver := clientAs(server, "rider-1"), clientAs(server, "driver-1")
+	env.psp.SetFailure(false, &service.PaymentDeclinedError{Reason: "insufficient funds"})
+
+	created, err := rider.CreateRide(ctx, api.CreateRideRequest{
+		RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 13.0, DestinationLng: 77.62,
+		PaymentMethod: "card",
+	})
+	if err != nil {
+		t.Fatalf("CreateRide: %v", err)
+	}
+	if err := driver.UpdateDriverLocation(ctx, "driver-1", api.UpdateLocationRequest{Lat: 12.9703, Lng: 77.59}); err != nil {
+		t.Fatalf("UpdateDriverLocation: %v", err)
+	}
+	accepted, err := driver.AcceptRide(ctx, "driver-1", api.AcceptRideRequest{RideID: created.ID})
+	if err != nil {
+		t.Fatalf("AcceptRide: %v", err)
+	}
+	_ = env.trips.Create(ctx, &domain.Trip{ID: accepted.TripID, RideID: created.ID, DriverID: "driver-1",
+		Status: domain.TripStatusStarted, StartedAt: time.Now().Add(-15 * time.Minute), Version: 1})
+
+	ended, err := driver.EndTrip(ctx, accepted.TripID)
+	if err != nil {
+		t.Fatalf("EndTrip: %v", err)
+	}
+	return ended
+}
+
+func TestPaymentFailure_DeclineSurfacedToClient(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	server := newContractServer(t, env)
+	ended := endDeclinedTrip(t, env, server)
+
+	// The trip still ended; its payment says why it failed and what to do.
+	if ended.Status != "ENDED" || ended.Payment == nil || ended.Payment.Status != "FAILED" {
+		t.Fatalf("expected an ENDED trip with a FAILED payment, got %+v", ended)
+	}
+	if ended.Payment.FailureReason != "insufficient funds" || !strings.Contains(ended.Payment.RetryHint, "POST /v1/payments") {
+		t.Errorf("expected the decline reason and a retry hint, got %+v", ended.Payment)
+	}
+
+	// Retrying while the card is still declined is a 402 with the reason.
+	_, err := clientAs(server, "rider-1").ProcessPayment(context.Background(), api.ProcessPaymentRequest{TripID: ended.TripID, Amount: ended.Fare})
+	var apiErr *rideclient.Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusPaymentRequired {
+		t.Fatalf("expected a 402, got %v", err)
+	}
+	if apiErr.FailureReason != "insufficient funds" || apiErr.RetryHint == "" {
+		t.Errorf("expected the decline reason and a retry hint, got %+v", apiErr)
+	}
+
+	// Once the card is sorted out, a retry pays.
+	env.psp.SetFailure(false, nil)
+	payment, err := clientAs(server, "rider-1").ProcessPayment(context.Background(), api.ProcessPaymentRequest{TripID: ended.TripID, Amount: ended.Fare})
+	if err != nil {
+		t.Fatalf("ProcessPayment: %v", err)
+	}
+	if payment.ID != ended.Payment.ID || payment.Status != "SUCCESS" || payment.FailureReason != "" || payment.RetryHint != "" {
+		t.Errorf("expected the trip's payment to succeed on retry, got %+v", payment)
+	}
+}
+
+func TestPaymentFailure_ProviderOutageIs503(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	server := newContractServer(t, env)
+	ended := endDeclinedTrip(t, env, server)
+
+	env.psp.SetFailure(false, errors.New("connection refused"))
+	client := rideclient.New(rideclient.Config{BaseURL: server.URL, UserID: "rider-1", MaxRetries: -1})
+	_, err := client.ProcessPayment(context.Background(), api.ProcessPaymentRequest{TripID: ended.TripID, Amount: ended.Fare})
+	var apiErr *rideclient.Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable || apiErr.FailureReason != domain.PaymentFailureProviderError {
+		t.Errorf("expected a 503 for the provider failure, got %v", err)
+	}
+}
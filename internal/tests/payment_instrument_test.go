@@ -0,0 +1,212 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/middleware"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// PAYMENT INSTRUMENTS
+// ──────────────────────────────────────────────
+
+// newInstrumentRideService creates rides paid with instruments from
+// instrumentService.
+func newInstrumentRideService(env *testEnv, instrumentService *service.PaymentInstrumentService) *service.RideService {
+	deps := env.rideDeps(NewMockMatchingServiceForTest())
+	deps.InstrumentService = instrumentService
+	return service.NewRideService(deps)
+}
+
+func addInstrument(t *testing.T, instrumentService *service.PaymentInstrumentService, userID string, method domain.PaymentMethod, token string, isDefault bool) *domain.PaymentInstrument {
+	t.Helper()
+
+	instrument, err := instrumentService.Add(context.Background(), service.AddPaymentInstrumentRequest{
+		UserID: userID, Type: method, Token: token, MaskedDetails: "VISA •••• " + token[len(token)-4:], IsDefault: isDefault,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return instrument
+}
+
+func requestRide(rideService *service.RideService, method domain.PaymentMethod, instrumentID string) (*service.CreateRideResponse, error) {
+	return rideService.CreateRide(context.Background(), service.CreateRideRequest{
+		RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 13.2, DestinationLng: 77.7,
+		PaymentMethod: method, InstrumentID: instrumentID,
+	})
+}
+
+func TestPaymentInstrument_CardRideNeedsInstrument(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	instrumentService := service.NewPaymentInstrumentService(NewMockPaymentInstrumentRepository(), nil)
+	rideService := newInstrumentRideService(env, instrumentService)
+
+	if _, err := requestRide(rideService, domain.PaymentMethodCard, ""); !errors.Is(err, service.ErrNoPaymentInstrument) {
+		t.Fatalf("expected ErrNoPaymentInstrument without a card on file, got %v", err)
+	}
+	if resp, err := requestRide(rideService, domain.PaymentMethodCash, ""); err != nil || resp.Ride.InstrumentID != "" {
+		t.Fatalf("expected a cash ride without an instrument, got %v", err)
+	}
+
+	// A wallet does not pay for a card ride, nor does another rider's card.
+	wallet := addInstrument(t, instrumentService, "rider-1", domain.PaymentMethodWallet, "tok_wallet_1111", false)
+	other := addInstrument(t, instrumentService, "rider-2", domain.PaymentMethodCard, "tok_card_9999", false)
+	if _, err := requestRide(rideService, domain.PaymentMethodCard, wallet.ID); !errors.Is(err, service.ErrNoPaymentInstrument) {
+		t.Errorf("expected a wallet rejected for a card ride, got %v", err)
+	}
+	if _, err := requestRide(rideService, domain.PaymentMethodCard, other.ID); !errors.Is(err, service.ErrNoPaymentInstrument) {
+		t.Errorf("expected another rider's card rejected, got %v", err)
+	}
+
+	card := addInstrument(t, instrumentService, "rider-1", domain.PaymentMethodCard, "tok_card_4242", false)
+	resp, err := requestRide(rideService, domain.PaymentMethodCard, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Ride.InstrumentID != card.ID {
+		t.Errorf("expected the ride charged to %s, got %q", card.ID, resp.Ride.InstrumentID)
+	}
+}
+
+func TestPaymentInstrument_Defaulting(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	instrumentService := service.NewPaymentInstrumentService(NewMockPaymentInstrumentRepository(), nil)
+	rideService := newInstrumentRideService(env, instrumentService)
+	first := addInstrument(t, instrumentService, "rider-1", domain.PaymentMethodCard, "tok_card_1111", false)
+	second := addInstrument(t, instrumentService, "rider-1", domain.PaymentMethodCard, "tok_card_2222", false)
+	upi := addInstrument(t, instrumentService, "rider-1", domain.PaymentMethodUPI, "tok_upi_3333", false)
+
+	if !first.IsDefault || second.IsDefault || !upi.IsDefault {
+		t.Fatalf("expected the first instrument of each type to become default, got %v %v %v", first.IsDefault, second.IsDefault, upi.IsDefault)
+	}
+	if resp, _ := requestRide(rideService, domain.PaymentMethodCard, ""); resp.Ride.InstrumentID != first.ID {
+		t.Errorf("expected the default card charged, got %q", resp.Ride.InstrumentID)
+	}
+	if resp, _ := requestRide(rideService, domain.PaymentMethodCard, second.ID); resp.Ride.InstrumentID != second.ID {
+		t.Errorf("expected the chosen card charged, got %q", resp.Ride.InstrumentID)
+	}
+
+	// Moving the default clears it on the other card only.
+	if _, err := instrumentService.SetDefault(context.Background(), "rider-1", second.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	instruments, _ := instrumentService.List(context.Background(), "rider-1")
+	for _, instrument := range instruments {
+		if want := instrument.ID == second.ID || instrument.ID == upi.ID; instrument.IsDefault != want {
+			t.Errorf("%s: expected is_default %v, got %v", instrument.Token, want, instrument.IsDefault)
+		}
+	}
+
+	// Adding with is_default takes the default over.
+	third := addInstrument(t, instrumentService, "rider-1", domain.PaymentMethodCard, "tok_card_5555", true)
+	if resp, _ := requestRide(rideService, domain.PaymentMethodCard, ""); resp.Ride.InstrumentID != third.ID {
+		t.Errorf("expected the new default charged, got %q", resp.Ride.InstrumentID)
+	}
+}
+
+func TestPaymentInstrument_PaymentRecordsInstrumentCharged(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	instruments := NewMockPaymentInstrumentRepository()
+	card := addInstrument(t, service.NewPaymentInstrumentService(instruments, nil), "rider-1", domain.PaymentMethodCard, "tok_card_4242", false)
+	paymentService := service.NewPaymentService(env.payments, env.psp, instruments, env.events, nil, 0)
+
+	payment, err := paymentService.ProcessPayment(context.Background(), service.ProcessPaymentRequest{
+		TripID: "trip-1", Amount: 25, Method: domain.PaymentMethodCard, InstrumentID: card.ID,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if payment.InstrumentID != card.ID || env.payments.GetPaymentByTripID("trip-1").InstrumentID != card.ID {
+		t.Errorf("expected the payment to record %s, got %q", card.ID, payment.InstrumentID)
+	}
+	if env.psp.LastToken != "tok_card_4242" {
+		t.Errorf("expected the card's token charged, got %q", env.psp.LastToken)
+	}
+
+	cash, _ := paymentService.ProcessPayment(context.Background(), service.ProcessPaymentRequest{
+		TripID: "trip-2", Amount: 25, Method: domain.PaymentMethodCash, InstrumentID: card.ID,
+	})
+	if cash.InstrumentID != "" {
+		t.Errorf("expected no instrument on a cash payment, got %q", cash.InstrumentID)
+	}
+}
+
+func TestPaymentInstrument_HTTP(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	instrumentService := service.NewPaymentInstrumentService(NewMockPaymentInstrumentRepository(), nil)
+	rideService := newInstrumentRideService(env, instrumentService)
+	instrumentHandler := handler.NewPaymentInstrumentHandler(instrumentService)
+
+	router := newTestRouter()
+	router.Use(middleware.IdentityMiddleware(testAdminToken))
+	router.POST("/v1/rides", handler.NewRideHandler(rideService, nil, nil, nil).CreateRide)
+	router.POST("/v1/users/:id/payment-methods", instrumentHandler.Create)
+	router.GET("/v1/users/:id/payment-methods", instrumentHandler.GetAll)
+	router.DELETE("/v1/users/:id/payment-methods/:instrument_id", instrumentHandler.Delete)
+
+	do := func(method, path, userID, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User-ID", userID)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	ride := `{"rider_id":"rider-1","pickup_lat":12.97,"pickup_lng":77.59,"destination_lat":13.2,"destination_lng":77.7,"payment_method":"CARD"}`
+
+	if w := do(http.MethodPost, "/v1/rides", "rider-1", ride); w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 for a card ride without a card, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w := do(http.MethodPost, "/v1/users/rider-1/payment-methods", "rider-1", `{"type":"CARD","token":"tok_card_4242","masked_details":"VISA •••• 4242"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "tok_card_4242") {
+		t.Errorf("expected the token withheld, got %s", w.Body.String())
+	}
+	var created handler.PaymentInstrumentResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &created)
+	if !created.IsDefault {
+		t.Error("expected the first card to be the default")
+	}
+
+	if w := do(http.MethodPost, "/v1/rides", "rider-1", ride); w.Code != http.StatusCreated {
+		t.Errorf("expected 201 once a card is on file, got %d: %s", w.Code, w.Body.String())
+	}
+
+	for _, body := range []string{`{"type":"CASH","token":"x","masked_details":"Cash"}`, `{"type":"CARD","masked_details":"VISA"}`} {
+		if w := do(http.MethodPost, "/v1/users/rider-1/payment-methods", "rider-1", body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", body, w.Code)
+		}
+	}
+
+	// Other users cannot see or remove the card.
+	if w := do(http.MethodGet, "/v1/users/rider-1/payment-methods", "rider-2", ""); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for another user, got %d", w.Code)
+	}
+	if w := do(http.MethodDelete, "/v1/users/rider-2/payment-methods/"+created.ID, "rider-2", ""); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 deleting another user's card, got %d", w.Code)
+	}
+	if w := do(http.MethodDelete, "/v1/users/rider-1/payment-methods/"+created.ID, "rider-1", ""); w.Code != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", w.Code)
+	}
+}
@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/handler"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// PHONE NORMALIZATION
+// ──────────────────────────────────────────────
+
+func newPhoneRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/v1/users/register", handler.NewUserHandler(NewMockUserRepository(), nil, "US").Register)
+	router.POST("/v1/drivers/register", handler.NewDriverHandler(nil, nil, NewMockDriverRepository(), "US", nil, nil).Register)
+	return router
+}
+
+func registerPhone(router *gin.Engine, path, phone string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(map[string]string{"name": "Asha", "phone": phone})
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body)))
+	return w
+}
+
+func TestNormalizePhone_EquivalentFormats(t *testing.T) {
+	t.Parallel()
+
+	for _, phone := range []string{"+1 (650) 253-0000", "6502530000", "650-253-0000", " +16502530000 "} {
+		got, err := service.NormalizePhone(phone, "US")
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", phone, err)
+			continue
+		}
+		if got != "+16502530000" {
+			t.Errorf("%q: expected +16502530000, got %q", phone, got)
+		}
+	}
+
+	// Numbers with a country code keep it regardless of the default region.
+	if got, _ := service.NormalizePhone("+91 98765 43210", "US"); got != "+919876543210" {
+		t.Errorf("expected +919876543210, got %q", got)
+	}
+}
+
+func TestPhoneRegistration_EquivalentNumbersCollide(t *testing.T) {
+	t.Parallel()
+
+	for _, path := range []string{"/v1/users/register", "/v1/drivers/register"} {
+		router := newPhoneRouter(t)
+
+		w := registerPhone(router, path, "+1 (650) 253-0000")
+		if w.Code != http.StatusCreated {
+			t.Fatalf("%s: expected 201, got %d: %s", path, w.Code, w.Body.String())
+		}
+		var created struct {
+			Phone string `json:"phone"`
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &created)
+		if created.Phone != "+16502530000" {
+			t.Errorf("%s: expected the E.164 phone stored, got %q", path, created.Phone)
+		}
+
+		for _, phone := range []string{"6502530000", "650-253-0000"} {
+			if w := registerPhone(router, path, phone); w.Code != http.StatusConflict {
+				t.Errorf("%s %q: expected 409 for the same number, got %d", path, phone, w.Code)
+			}
+		}
+	}
+}
+
+func TestPhoneRegistration_RejectsInvalidNumbers(t *testing.T) {
+	t.Parallel()
+
+	router := newPhoneRouter(t)
+
+	for _, path := range []string{"/v1/users/register", "/v1/drivers/register"} {
+		for _, phone := range []string{"12", "abc", "+1 000 000 0000"} {
+			if w := registerPhone(router, path, phone); w.Code != http.StatusUnprocessableEntity {
+				t.Errorf("%s %q: expected 422, got %d", path, phone, w.Code)
+			}
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"ride/internal/domain"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// PICKUP GEOFENCE ON TRIP START
+// ──────────────────────────────────────────────
+
+const testPickupGeofenceKm = 0.2
+
+// newGeofenceTripService assigns ride-1, picking up at (12.97, 77.59), to
+// driver-1 and checks drivers starting trips against env's locations.
+func newGeofenceTripService(env *testEnv) *service.TripService {
+	env.rides.AddRide(&domain.Ride{
+		ID: "ride-1", RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 12.3, DestinationLng: 76.64,
+		Status: domain.RideStatusAssigned, AssignedDriverID: "driver-1", SurgeMultiplier: 1, Version: 1,
+	})
+
+	deps := env.tripDeps()
+	deps.LocationStore = env.locations
+	deps.PickupGeofenceKm = testPickupGeofenceKm
+	return service.NewTripService(deps)
+}
+
+func startAtPickup(tripService *service.TripService, override bool) (*domain.Trip, error) {
+	return tripService.StartTrip(context.Background(), service.StartTripRequest{
+		RideID: "ride-1", DriverID: "driver-1", Override: override,
+	})
+}
+
+func TestPickupGeofence_DriverAtPickupStartsTrip(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	tripService := newGeofenceTripService(env)
+	_ = env.locations.UpdateLocation(context.Background(), "driver-1", 12.9705, 77.5905, 0) // About 75 m away
+
+	trip, err := startAtPickup(tripService, false)
+	if err != nil {
+		t.Fatalf("expected trip to start at pickup, got %v", err)
+	}
+	if trip.Status != domain.TripStatusStarted {
+		t.Errorf("expected STARTED trip, got %s", trip.Status)
+	}
+}
+
+func TestPickupGeofence_DriverFarAwayRejected(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	tripService := newGeofenceTripService(env)
+	_ = env.locations.UpdateLocation(context.Background(), "driver-1", 12.99, 77.59, 0) // About 2.2 km away
+
+	if _, err := startAtPickup(tripService, false); !errors.Is(err, service.ErrDriverTooFarFromPickup) {
+		t.Errorf("expected ErrDriverTooFarFromPickup, got %v", err)
+	}
+}
+
+func TestPickupGeofence_UnknownLocationRejected(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	tripService := newGeofenceTripService(env)
+
+	if _, err := startAtPickup(tripService, false); !errors.Is(err, service.ErrDriverLocationUnavailable) {
+		t.Errorf("expected ErrDriverLocationUnavailable, got %v", err)
+	}
+}
+
+func TestPickupGeofence_OverrideStartsTripAway(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	tripService := newGeofenceTripService(env)
+	_ = env.locations.UpdateLocation(context.Background(), "driver-1", 12.99, 77.59, 0)
+
+	if _, err := startAtPickup(tripService, true); err != nil {
+		t.Errorf("expected override to start the trip, got %v", err)
+	}
+}
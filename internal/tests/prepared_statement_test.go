@@ -0,0 +1,150 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"ride/internal/domain"
+	"ride/internal/repository"
+	"ride/internal/repository/postgres"
+)
+
+// ──────────────────────────────────────────────
+// PREPARED STATEMENTS
+// ──────────────────────────────────────────────
+
+func TestPreparedStatements_HotQueriesPreparedOnce(t *testing.T) {
+	t.Parallel()
+
+	db, rec := NewRecordingDB()
+	t.Cleanup(func() { _ = db.Close() })
+	ctx := context.Background()
+
+	drivers := postgres.NewDriverRepository(db)
+	rides := postgres.NewRideRepository(db)
+	t.Cleanup(func() { _ = drivers.Close(); _ = rides.Close() })
+
+	prepared := rec.Prepared()
+	if len(prepared) != 3 {
+		t.Fatalf("expected driver GetByID, driver UpdateStatus and ride GetByID prepared, got %q", prepared)
+	}
+
+	for i := 0; i < 5; i++ {
+		if _, err := drivers.GetByID(ctx, "driver-1"); !errors.Is(err, repository.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+		if _, err := rides.GetByID(ctx, "ride-1"); !errors.Is(err, repository.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+		if err := drivers.UpdateStatus(ctx, "driver-1", domain.DriverStatusOnTrip); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	_, _ = drivers.GetByPhone(ctx, "+911234567890")
+
+	if got := rec.Prepared(); len(got) != len(prepared) {
+		t.Errorf("expected statements reused, got %d preparations", len(got))
+	}
+
+	// Prepared executions are still ordinary queries with their arguments.
+	queries := rec.Queries()
+	if len(queries) != 16 {
+		t.Fatalf("expected 16 queries, got %d", len(queries))
+	}
+	if update := queries[2]; !strings.Contains(update.Query, "UPDATE drivers") || update.Args[0] != string(domain.DriverStatusOnTrip) || update.Args[1] != "driver-1" {
+		t.Errorf("unexpected update %+v", update)
+	}
+}
+
+func TestPreparedStatements_TxRepositoriesRunUnprepared(t *testing.T) {
+	t.Parallel()
+
+	db, rec := NewRecordingDB()
+	t.Cleanup(func() { _ = db.Close() })
+	ctx := context.Background()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	drivers := postgres.NewDriverRepositoryWithTx(tx)
+	rides := postgres.NewRideRepositoryWithTx(tx)
+
+	if _, err := drivers.GetByID(ctx, "driver-1"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if err := drivers.UpdateStatus(ctx, "driver-1", domain.DriverStatusOnline); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := rides.GetByID(ctx, "ride-1"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	if got := rec.Prepared(); len(got) != 0 {
+		t.Errorf("expected nothing prepared in a transaction, got %q", got)
+	}
+	if got := len(rec.Queries()); got != 3 {
+		t.Errorf("expected 3 queries, got %d", got)
+	}
+	if err := drivers.Close(); err != nil {
+		t.Errorf("expected Close to be a no-op in a transaction, got %v", err)
+	}
+}
+
+// BenchmarkDriverGetByID compares prepared and unprepared lookups against the
+// Postgres at TEST_DATABASE_URL, with scripts/schema.sql applied. The
+// unprepared case runs in a transaction, where statements are not prepared.
+func BenchmarkDriverGetByID(b *testing.B) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		b.Skip("TEST_DATABASE_URL not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		b.Fatalf("unexpected error: %v", err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+
+	drivers := postgres.NewDriverRepository(db)
+	defer drivers.Close()
+
+	id := uuid.New().String()
+	if err := drivers.Create(ctx, &domain.Driver{ID: id, Name: "Bench", Phone: "+91" + id[:10], Status: domain.DriverStatusOnline, Tier: domain.DriverTierBasic}); err != nil {
+		b.Fatalf("unexpected error: %v", err)
+	}
+	defer func() { _, _ = db.ExecContext(ctx, `DELETE FROM drivers WHERE id = $1`, id) }()
+
+	b.Run("prepared", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := drivers.GetByID(ctx, id); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("unprepared", func(b *testing.B) {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+		defer func() { _ = tx.Rollback() }()
+		txDrivers := postgres.NewDriverRepositoryWithTx(tx)
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := txDrivers.GetByID(ctx, id); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// PAYMENT PROCESSING FEES
+// ──────────────────────────────────────────────
+
+// endTripPaidBy ends a 20 minute trip paid by method, with a 2.9% + $0.30
+// card fee configured, and returns the result and the PSP.
+func endTripPaidBy(t *testing.T, method domain.PaymentMethod) (*service.EndTripResponse, *MockPSP, *service.ReceiptService) {
+	t.Helper()
+
+	db, _ := NewRecordingDB()
+	t.Cleanup(func() { _ = db.Close() })
+
+	rideRepo := NewMockRideRepository()
+	rideRepo.AddRide(&domain.Ride{
+		ID: "ride-1", RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 13.20, DestinationLng: 77.70,
+		Status: domain.RideStatusInTrip, AssignedDriverID: "driver-1", SurgeMultiplier: 1, PaymentMethod: method, Version: 2,
+	})
+	tripRepo := NewMockTripRepository()
+	_ = tripRepo.Create(context.Background(), &domain.Trip{
+		ID: "trip-1", RideID: "ride-1", DriverID: "driver-1", Status: domain.TripStatusStarted,
+		StartedAt: time.Now().Add(-20 * time.Minute), Version: 1,
+	})
+
+	psp := NewMockPSP()
+	fees := map[domain.PaymentMethod]domain.ProcessingFee{
+		domain.PaymentMethodCard: {Percent: 2.9, Flat: 0.30},
+		domain.PaymentMethodCash: {Flat: 1}, // Never applied to cash
+	}
+	receiptService := service.NewReceiptService(nil, nil, nil, nil, nil)
+	paymentService := service.NewPaymentService(NewMockPaymentRepository(), psp, nil, nil, fees, 0)
+	tripService := service.NewTripService(service.TripServiceDeps{
+		DB:             db,
+		TripRepo:       tripRepo,
+		RideRepo:       rideRepo,
+		DriverRepo:     NewMockDriverRepository(),
+		PaymentService: paymentService,
+		ReceiptService: receiptService,
+	})
+
+	resp, err := tripService.EndTrip(context.Background(), service.EndTripRequest{TripID: "trip-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Payment == nil || resp.Receipt == nil {
+		t.Fatalf("expected a payment and a receipt, got %+v", resp)
+	}
+	return resp, psp, receiptService
+}
+
+func TestProcessingFee_CardChargedAndOnReceipt(t *testing.T) {
+	t.Parallel()
+
+	resp, psp, receipts := endTripPaidBy(t, domain.PaymentMethodCard)
+
+	fare := resp.Trip.Fare
+	fee := domain.ProcessingFee{Percent: 2.9, Flat: 0.30}.Amount(fare)
+	if fee <= 0.30 {
+		t.Fatalf("expected a percentage on top of the flat fee, got %.2f", fee)
+	}
+	if resp.Payment.Fee != fee || math.Abs(resp.Payment.Amount-(fare+fee)) > 0.005 {
+		t.Errorf("expected fare %.2f plus fee %.2f, got %+v", fare, fee, resp.Payment)
+	}
+	if psp.LastAmount != resp.Payment.Amount {
+		t.Errorf("expected the fee included in the charge, charged %.2f", psp.LastAmount)
+	}
+
+	receipt := resp.Receipt
+	if receipt.ProcessingFee != fee || receipt.TotalFare != resp.Payment.Amount {
+		t.Errorf("expected the fee line and the charged total on the receipt, got %+v", receipt)
+	}
+	if !strings.Contains(receipts.FormatReceipt(receipt), "Processing fee:") {
+		t.Error("expected the processing fee line in the formatted receipt")
+	}
+}
+
+func TestProcessingFee_NoneForCash(t *testing.T) {
+	t.Parallel()
+
+	resp, psp, receipts := endTripPaidBy(t, domain.PaymentMethodCash)
+
+	if resp.Payment.Fee != 0 || resp.Payment.Amount != resp.Trip.Fare {
+		t.Errorf("expected the bare fare due in cash, got %+v", resp.Payment)
+	}
+	if psp.ChargeCallCount != 0 {
+		t.Errorf("expected cash not charged, got %d charges", psp.ChargeCallCount)
+	}
+	if resp.Receipt.ProcessingFee != 0 || resp.Receipt.TotalFare != resp.Trip.Fare {
+		t.Errorf("expected no fee on the receipt, got %+v", resp.Receipt)
+	}
+	if strings.Contains(receipts.FormatReceipt(resp.Receipt), "Processing fee") {
+		t.Error("expected no processing fee line for cash")
+	}
+}
+
+func TestProcessingFee_Amount(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		fee  domain.ProcessingFee
+		fare float64
+		want float64
+	}{
+		{domain.ProcessingFee{Percent: 2.9, Flat: 0.30}, 20, 0.88},
+		{domain.ProcessingFee{Percent: 1}, 12.34, 0.12},
+		{domain.ProcessingFee{Flat: 0.5}, 99, 0.5},
+	}
+	for _, tc := range testCases {
+		if got := tc.fee.Amount(tc.fare); got != tc.want {
+			t.Errorf("%+v on %.2f: expected %.2f, got %.2f", tc.fee, tc.fare, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/middleware"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// PACKAGE RIDES AND PROOF OF DELIVERY
+// ──────────────────────────────────────────────
+
+// testPNG sniffs as image/png.
+var testPNG = append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 64)...)
+
+const testAttachmentMaxBytes = 1024
+
+// newAttachmentService adds trip-1, driver-1's trip on rider-1's PACKAGE
+// ride, and trip-2, driver-1's trip on a PASSENGER ride, both STARTED, and
+// returns a service storing their attachments in attachmentRepo.
+func newAttachmentService(t *testing.T, env *testEnv, attachmentRepo *MockTripAttachmentRepository) *service.TripAttachmentService {
+	t.Helper()
+
+	for _, ride := range []*domain.Ride{
+		{ID: "ride-1", Type: domain.RideTypePackage},
+		{ID: "ride-2", Type: domain.RideTypePassenger},
+	} {
+		ride.RiderID, ride.AssignedDriverID, ride.Status, ride.PaymentMethod, ride.Version = "rider-1", "driver-1", domain.RideStatusInTrip, domain.PaymentMethodCash, 1
+		ride.PickupLat, ride.PickupLng, ride.DestinationLat, ride.DestinationLng = 12.97, 77.59, 13.0, 77.62
+		env.rides.AddRide(ride)
+		_ = env.trips.Create(context.Background(), &domain.Trip{
+			ID: "trip-" + strings.TrimPrefix(ride.ID, "ride-"), RideID: ride.ID, DriverID: "driver-1",
+			Status: domain.TripStatusStarted, StartedAt: time.Now().Add(-10 * time.Minute), Version: 1,
+		})
+	}
+
+	blobs, err := service.NewFilesystemBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return service.NewTripAttachmentService(env.trips, env.rides, attachmentRepo, blobs, testAttachmentMaxBytes, "test-key", time.Minute)
+}
+
+// newDeliveryRouter serves the trips newAttachmentService adds.
+func newDeliveryRouter(t *testing.T, env *testEnv) *gin.Engine {
+	t.Helper()
+
+	attachmentRepo := NewMockTripAttachmentRepository()
+	attachments := newAttachmentService(t, env, attachmentRepo)
+	deps := env.tripDeps()
+	deps.AttachmentRepo = attachmentRepo
+	tripService := service.NewTripService(deps)
+	rideService := service.NewRideService(env.rideDeps(NewMockMatchingServiceForTest()))
+
+	router := newTestRouter()
+	router.Use(middleware.IdentityMiddleware(testAdminToken))
+	attachmentHandler := handler.NewTripAttachmentHandler(attachments, tripService)
+	router.POST("/v1/rides", handler.NewRideHandler(rideService, nil, env.rides, nil).CreateRide)
+	router.POST("/v1/trips/:id/end", handler.NewTripHandler(tripService, nil).EndTrip)
+	router.POST("/v1/trips/:id/attachments", attachmentHandler.Upload)
+	router.GET("/v1/trips/:id/attachments", attachmentHandler.List)
+	router.GET("/v1/attachments/:id/content", attachmentHandler.Content)
+	return router
+}
+
+// uploadAttachment posts data as the "file" field of a multipart form; a
+// nil data sends the form without it.
+func uploadAttachment(t *testing.T, router *gin.Engine, tripID, userID string, data []byte) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	if data != nil {
+		part, err := form.CreateFormFile("file", "delivery.png")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		_, _ = part.Write(data)
+	} else {
+		_ = form.WriteField("note", "no photo")
+	}
+	_ = form.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/trips/"+tripID+"/attachments", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	return serveAs(router, req, userID)
+}
+
+func endDeliveryTrip(router *gin.Engine, tripID string) *httptest.ResponseRecorder {
+	return serveAs(router, httptest.NewRequest(http.MethodPost, "/v1/trips/"+tripID+"/end", nil), "driver-1")
+}
+
+func TestValidateRideType(t *testing.T) {
+	t.Parallel()
+
+	for input, want := range map[string]domain.RideType{"": domain.RideTypePassenger, "package": domain.RideTypePackage, " PASSENGER ": domain.RideTypePassenger} {
+		if got, err := service.ValidateRideType(input); err != nil || got != want {
+			t.Errorf("ValidateRideType(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	if _, err := service.ValidateRideType("cargo"); !errors.Is(err, service.ErrInvalidRideType) {
+		t.Errorf("expected ErrInvalidRideType, got %v", err)
+	}
+}
+
+func TestProofOfDelivery_CreatePackageRide(t *testing.T) {
+	t.Parallel()
+
+	router := newDeliveryRouter(t, newTestEnv(t))
+	create := func(rideType string) *httptest.ResponseRecorder {
+		body := `{"rider_id":"rider-1","pickup_lat":12.97,"pickup_lng":77.59,"destination_lat":13.0,"destination_lng":77.62,"ride_type":"` + rideType + `"}`
+		req := httptest.NewRequest(http.MethodPost, "/v1/rides", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		return serveAs(router, req, "rider-1")
+	}
+
+	w := create("package")
+	var created handler.CreateRideResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil || w.Code != http.StatusCreated || created.RideType != "PACKAGE" {
+		t.Fatalf("expected a PACKAGE ride, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := create("cargo"); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422 for an unknown ride type, got %d", w.Code)
+	}
+}
+
+func TestProofOfDelivery_EndRequiresAttachment(t *testing.T) {
+	t.Parallel()
+
+	router := newDeliveryRouter(t, newTestEnv(t))
+
+	if w := endDeliveryTrip(router, "trip-1"); w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), "proof of delivery") {
+		t.Fatalf("expected 409 ending a package trip without a photo, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w := uploadAttachment(t, router, "trip-1", "driver-1", testPNG)
+	var attachment handler.TripAttachmentResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &attachment); err != nil || w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if attachment.TripID != "trip-1" || attachment.ContentType != "image/png" || attachment.URL == "" || attachment.ExpiresAt == "" {
+		t.Errorf("expected a signed PNG attachment, got %+v", attachment)
+	}
+
+	if w := endDeliveryTrip(router, "trip-1"); w.Code != http.StatusOK {
+		t.Errorf("expected the trip to end once a photo is attached, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestProofOfDelivery_PassengerRides(t *testing.T) {
+	t.Parallel()
+
+	router := newDeliveryRouter(t, newTestEnv(t))
+
+	if w := uploadAttachment(t, router, "trip-2", "driver-1", testPNG); w.Code != http.StatusConflict {
+		t.Errorf("expected 409 attaching to a passenger trip, got %d", w.Code)
+	}
+	if w := endDeliveryTrip(router, "trip-2"); w.Code != http.StatusOK {
+		t.Errorf("expected a passenger trip to end without a photo, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestProofOfDelivery_UploadRejected(t *testing.T) {
+	t.Parallel()
+
+	router := newDeliveryRouter(t, newTestEnv(t))
+
+	cases := []struct {
+		name   string
+		userID string
+		data   []byte
+		want   int
+	}{
+		{"no file", "driver-1", nil, http.StatusBadRequest},
+		{"not the trip's driver", "driver-2", testPNG, http.StatusForbidden},
+		{"too large", "driver-1", append(append([]byte{}, testPNG...), make([]byte, testAttachmentMaxBytes)...), http.StatusRequestEntityTooLarge},
+		{"not an image", "driver-1", []byte("just some text"), http.StatusUnsupportedMediaType},
+	}
+	for _, tc := range cases {
+		if w := uploadAttachment(t, router, "trip-1", tc.userID, tc.data); w.Code != tc.want {
+			t.Errorf("%s: expected %d, got %d: %s", tc.name, tc.want, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestProofOfDelivery_SignedURLServesPhoto(t *testing.T) {
+	t.Parallel()
+
+	router := newDeliveryRouter(t, newTestEnv(t))
+	if w := uploadAttachment(t, router, "trip-1", "driver-1", testPNG); w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", w.Code)
+	}
+
+	w := getAs(router, "/v1/trips/trip-1/attachments", "rider-1")
+	var listed []handler.TripAttachmentResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &listed); err != nil || len(listed) != 1 {
+		t.Fatalf("expected the rider to see one attachment, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := getAs(router, "/v1/trips/trip-1/attachments", "rider-2"); w.Code != http.StatusNotFound {
+		t.Errorf("expected another rider not to see the attachments, got %d", w.Code)
+	}
+
+	// The signed URL needs no identity.
+	w = getAs(router, listed[0].URL, "")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "image/png" || !bytes.Equal(w.Body.Bytes(), testPNG) {
+		t.Fatalf("expected the photo served, got %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+
+	tampered := strings.Replace(listed[0].URL, "expires=", "expires=9", 1)
+	if w := getAs(router, tampered, ""); w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for an altered URL, got %d", w.Code)
+	}
+}
+
+func TestProofOfDelivery_SignedURLExpires(t *testing.T) {
+	t.Parallel()
+
+	attachments := newAttachmentService(t, newTestEnv(t), NewMockTripAttachmentRepository())
+	ctx := context.Background()
+	attachment, err := attachments.Attach(ctx, service.AttachRequest{TripID: "trip-1", DriverID: "driver-1", Data: testPNG})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	now := time.Now()
+	signed, expiresAt := attachments.SignedURL(attachment, now)
+	parsed, err := url.Parse(signed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expires, signature := parsed.Query().Get("expires"), parsed.Query().Get("signature")
+
+	if _, _, err := attachments.Open(ctx, attachment.ID, expires, signature, now); err != nil {
+		t.Errorf("expected the URL valid before it expires, got %v", err)
+	}
+	if _, _, err := attachments.Open(ctx, attachment.ID, expires, signature, expiresAt); !errors.Is(err, service.ErrInvalidAttachmentURL) {
+		t.Errorf("expected ErrInvalidAttachmentURL once expired, got %v", err)
+	}
+}
+
+func TestProofOfDelivery_EndedTripRejectsAttachments(t *testing.T) {
+	t.Parallel()
+
+	trips, rides := NewMockTripRepository(), NewMockRideRepository()
+	rides.AddRide(&domain.Ride{ID: "ride-1", Type: domain.RideTypePackage})
+	_ = trips.Create(context.Background(), &domain.Trip{ID: "trip-1", RideID: "ride-1", DriverID: "driver-1", Status: domain.TripStatusEnded})
+	blobs, _ := service.NewFilesystemBlobStore(t.TempDir())
+	attachments := service.NewTripAttachmentService(trips, rides, NewMockTripAttachmentRepository(), blobs, 0, "test-key", 0)
+
+	_, err := attachments.Attach(context.Background(), service.AttachRequest{TripID: "trip-1", DriverID: "driver-1", Data: testPNG})
+	if !errors.Is(err, service.ErrTripAlreadyEnded) {
+		t.Errorf("expected ErrTripAlreadyEnded, got %v", err)
+	}
+}
+
+func TestFilesystemBlobStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store, err := service.NewFilesystemBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := store.Put(ctx, "trips/trip-1/photo.png", testPNG); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, err := store.Get(ctx, "trips/trip-1/photo.png"); err != nil || !bytes.Equal(got, testPNG) {
+		t.Errorf("expected the blob read back, got %d bytes, %v", len(got), err)
+	}
+	for _, key := range []string{"../escape.png", "/etc/passwd", ""} {
+		if err := store.Put(ctx, key, testPNG); err == nil {
+			t.Errorf("expected key %q refused", key)
+		}
+	}
+
+	if _, err := service.NewBlobStore("s3", t.TempDir()); err == nil {
+		t.Error("expected an unknown blob store kind refused")
+	}
+}
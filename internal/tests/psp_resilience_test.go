@@ -0,0 +1,160 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// PSP RESILIENCE
+// ──────────────────────────────────────────────
+
+// slowPSP never answers before its context is done.
+type slowPSP struct {
+	calls int32
+}
+
+func (p *slowPSP) Charge(ctx context.Context, token string, amount float64) (bool, error) {
+	atomic.AddInt32(&p.calls, 1)
+	<-ctx.Done()
+	return false, ctx.Err()
+}
+
+func (p *slowPSP) Authorize(ctx context.Context, token string, amount float64) (string, error) {
+	atomic.AddInt32(&p.calls, 1)
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func (p *slowPSP) Capture(ctx context.Context, authID string, amount float64) error {
+	atomic.AddInt32(&p.calls, 1)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (p *slowPSP) Void(ctx context.Context, authID string) error {
+	atomic.AddInt32(&p.calls, 1)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (p *slowPSP) Refund(ctx context.Context, token string, amount float64) error {
+	atomic.AddInt32(&p.calls, 1)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestResilientPSP_TimeoutFailsPaymentWithoutRetry(t *testing.T) {
+	t.Parallel()
+
+	slow := &slowPSP{}
+	psp := service.NewResilientPSP(slow, 20*time.Millisecond, 3, time.Millisecond, 5, time.Minute)
+	payments := NewMockPaymentRepository()
+	paymentService := service.NewPaymentService(payments, psp, nil, nil, nil, 0)
+
+	start := time.Now()
+	payment, err := paymentService.ProcessPayment(context.Background(), service.ProcessPaymentRequest{
+		TripID: "trip-1", Amount: 25, Method: domain.PaymentMethodCard,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the charge bounded by the timeout, took %s", elapsed)
+	}
+	if payment.Status != domain.PaymentStatusFailed || payments.GetPaymentByTripID("trip-1").Status != domain.PaymentStatusFailed {
+		t.Errorf("expected a FAILED payment, got %s", payment.Status)
+	}
+	// The charge may have gone through, so it is not retried.
+	if got := atomic.LoadInt32(&slow.calls); got != 1 {
+		t.Errorf("expected 1 PSP call, got %d", got)
+	}
+}
+
+func TestResilientPSP_RetriesTransientErrors(t *testing.T) {
+	t.Parallel()
+
+	mock := NewMockPSP()
+	mock.SetFailure(false, fmt.Errorf("gateway 502: %w", service.ErrPSPTransient))
+	psp := service.NewResilientPSP(mock, time.Second, 2, time.Millisecond, 10, time.Minute)
+
+	if _, err := psp.Charge(context.Background(), "tok", 10); !errors.Is(err, service.ErrPSPTransient) {
+		t.Fatalf("expected ErrPSPTransient, got %v", err)
+	}
+	if got := atomic.LoadInt32(&mock.ChargeCallCount); got != 3 {
+		t.Errorf("expected 1 call and 2 retries, got %d calls", got)
+	}
+
+	// Other errors are not retried.
+	mock.SetFailure(false, errors.New("invalid token"))
+	_, _ = psp.Charge(context.Background(), "tok", 10)
+	if got := atomic.LoadInt32(&mock.ChargeCallCount); got != 4 {
+		t.Errorf("expected no retry of a permanent error, got %d calls", got)
+	}
+}
+
+func TestResilientPSP_BreakerOpensAndRecovers(t *testing.T) {
+	t.Parallel()
+
+	mock := NewMockPSP()
+	mock.SetFailure(false, errors.New("connection refused"))
+	psp := service.NewResilientPSP(mock, time.Second, 0, time.Millisecond, 3, 50*time.Millisecond)
+	payments := NewMockPaymentRepository()
+	paymentService := service.NewPaymentService(payments, psp, nil, nil, nil, 0)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		_, _ = psp.Charge(ctx, "tok", 10)
+	}
+
+	// Open: the PSP is not called and the payment fails fast.
+	payment, err := paymentService.ProcessPayment(ctx, service.ProcessPaymentRequest{
+		TripID: "trip-1", Amount: 10, Method: domain.PaymentMethodCard,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if payment.Status != domain.PaymentStatusFailed {
+		t.Errorf("expected a FAILED payment while the breaker is open, got %s", payment.Status)
+	}
+	if _, err := psp.Charge(ctx, "tok", 10); !errors.Is(err, service.ErrPSPUnavailable) {
+		t.Errorf("expected ErrPSPUnavailable, got %v", err)
+	}
+	if got := atomic.LoadInt32(&mock.ChargeCallCount); got != 3 {
+		t.Errorf("expected no PSP calls while open, got %d calls", got)
+	}
+
+	// After the cooldown a successful trial charge closes the breaker.
+	mock.SetFailure(false, nil)
+	time.Sleep(60 * time.Millisecond)
+	if ok, err := psp.Charge(ctx, "tok", 10); err != nil || !ok {
+		t.Fatalf("expected the trial charge to succeed, got %v, %v", ok, err)
+	}
+	if ok, err := psp.Charge(ctx, "tok", 10); err != nil || !ok {
+		t.Errorf("expected the breaker closed, got %v, %v", ok, err)
+	}
+}
+
+func TestResilientPSP_DeclinesDoNotTripBreaker(t *testing.T) {
+	t.Parallel()
+
+	mock := NewMockPSP()
+	mock.SetFailure(true, nil)
+	psp := service.NewResilientPSP(mock, time.Second, 0, time.Millisecond, 2, time.Minute)
+
+	for i := 0; i < 5; i++ {
+		if ok, err := psp.Charge(context.Background(), "tok", 10); ok || err != nil {
+			t.Fatalf("expected a decline, got %v, %v", ok, err)
+		}
+	}
+	if got := atomic.LoadInt32(&mock.ChargeCallCount); got != 5 {
+		t.Errorf("expected every decline to reach the PSP, got %d calls", got)
+	}
+}
@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/middleware"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// READ AUTHORIZATION
+// ──────────────────────────────────────────────
+
+// newReadAuthRouter serves ride-1 (rider-1, driven by driver-1), its trip and
+// its payment.
+func newReadAuthRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+
+	rideRepo := NewMockRideRepository()
+	rideRepo.AddRide(&domain.Ride{
+		ID: "ride-1", RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 12.3, DestinationLng: 76.64,
+		Status: domain.RideStatusCompleted, AssignedDriverID: "driver-1", SurgeMultiplier: 1, PaymentMethod: domain.PaymentMethodCard,
+	})
+	tripRepo := NewMockTripRepository()
+	_ = tripRepo.Create(context.Background(), &domain.Trip{ID: "trip-1", RideID: "ride-1", DriverID: "driver-1", Status: domain.TripStatusEnded, Fare: 12})
+	paymentRepo := NewMockPaymentRepository()
+	_ = paymentRepo.Create(context.Background(), &domain.Payment{ID: "payment-1", TripID: "trip-1", Amount: 12, Status: domain.PaymentStatusSuccess})
+
+	rideService := service.NewRideService(service.RideServiceDeps{RideRepo: rideRepo, MatchingService: NewMockMatchingServiceForTest()})
+	tripService := service.NewTripService(service.TripServiceDeps{TripRepo: tripRepo, RideRepo: rideRepo, DriverRepo: NewMockDriverRepository()})
+	paymentService := service.NewPaymentService(paymentRepo, NewMockPSP(), nil, nil, nil, 0)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.IdentityMiddleware(testAdminToken))
+	router.GET("/v1/rides/:id", handler.NewRideHandler(rideService, nil, rideRepo, nil).GetRide)
+	router.GET("/v1/trips/:id", handler.NewTripHandler(tripService, nil).GetTrip)
+	router.GET("/v1/payments/:id", handler.NewPaymentHandler(paymentService, tripService).GetPayment)
+	return router
+}
+
+// readAs issues a GET as a user ID, or as admin when admin is set.
+func readAs(router *gin.Engine, path, userID string, admin bool) int {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if userID != "" {
+		req.Header.Set("X-User-ID", userID)
+	}
+	if admin {
+		req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestReadAuthorization_Personas(t *testing.T) {
+	t.Parallel()
+
+	router := newReadAuthRouter(t)
+
+	personas := []struct {
+		name   string
+		userID string
+		admin  bool
+		want   int
+	}{
+		{"rider on the ride", "rider-1", false, http.StatusOK},
+		{"driver assigned to the ride", "driver-1", false, http.StatusOK},
+		{"admin", "", true, http.StatusOK},
+		{"another rider", "rider-2", false, http.StatusNotFound},
+		{"another driver", "driver-2", false, http.StatusNotFound},
+		{"anonymous", "", false, http.StatusNotFound},
+	}
+
+	for _, path := range []string{"/v1/rides/ride-1", "/v1/trips/trip-1", "/v1/payments/payment-1"} {
+		for _, p := range personas {
+			if got := readAs(router, path, p.userID, p.admin); got != p.want {
+				t.Errorf("%s as %s: expected %d, got %d", path, p.name, p.want, got)
+			}
+		}
+	}
+}
+
+func TestReadAuthorization_WrongAdminTokenIsNotAdmin(t *testing.T) {
+	t.Parallel()
+
+	router := newReadAuthRouter(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/rides/ride-1", nil)
+	req.Header.Set("Authorization", "Bearer wrong")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 with a wrong admin token, got %d", w.Code)
+	}
+}
+
+func TestReadAuthorization_HiddenMatchesMissing(t *testing.T) {
+	t.Parallel()
+
+	router := newReadAuthRouter(t)
+
+	// A stranger cannot tell an existing ride from a missing one.
+	hidden := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/v1/rides/ride-1", nil)
+	req.Header.Set("X-User-ID", "rider-2")
+	router.ServeHTTP(hidden, req)
+
+	missing := httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/v1/rides/ride-missing", nil)
+	req.Header.Set("X-User-ID", "rider-2")
+	router.ServeHTTP(missing, req)
+
+	if hidden.Code != missing.Code || hidden.Body.String() != missing.Body.String() {
+		t.Errorf("expected identical responses, got %d %s vs %d %s",
+			hidden.Code, hidden.Body.String(), missing.Code, missing.Body.String())
+	}
+}
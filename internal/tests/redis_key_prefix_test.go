@@ -0,0 +1,196 @@
+package tests
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	goredis "github.com/redis/go-redis/v9"
+
+	"ride/internal/middleware"
+	"ride/internal/redis"
+)
+
+// ──────────────────────────────────────────────
+// REDIS KEY PREFIX
+// ──────────────────────────────────────────────
+
+// keyRecorder is a go-redis hook that records the key of every command
+// instead of sending it, so stores can be exercised without a server.
+// Reads see an empty keyspace.
+type keyRecorder struct {
+	mu       sync.Mutex
+	keys     []string
+	commands []string // "<command> <key>", e.g. "geoadd drivers:locations"
+}
+
+func (r *keyRecorder) DialHook(next goredis.DialHook) goredis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return nil, goredis.ErrClosed
+	}
+}
+
+func (r *keyRecorder) ProcessHook(next goredis.ProcessHook) goredis.ProcessHook {
+	return func(ctx context.Context, cmd goredis.Cmder) error {
+		r.record(cmd)
+		cmd.SetErr(goredis.Nil)
+		return goredis.Nil
+	}
+}
+
+func (r *keyRecorder) ProcessPipelineHook(next goredis.ProcessPipelineHook) goredis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []goredis.Cmder) error {
+		for _, cmd := range cmds {
+			r.record(cmd)
+		}
+		return nil
+	}
+}
+
+func (r *keyRecorder) record(cmd goredis.Cmder) {
+	args := cmd.Args()
+	if len(args) < 2 {
+		return // MULTI, EXEC
+	}
+	key, _ := args[1].(string)
+	r.mu.Lock()
+	r.keys = append(r.keys, key)
+	r.commands = append(r.commands, cmd.Name()+" "+key)
+	r.mu.Unlock()
+}
+
+func (r *keyRecorder) Keys() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.keys...)
+}
+
+// Commands returns the recorded commands with their keys, and forgets them.
+func (r *keyRecorder) Commands() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	commands := r.commands
+	r.commands = nil
+	return commands
+}
+
+func newKeyRecordingClient(t *testing.T) (*goredis.Client, *keyRecorder) {
+	t.Helper()
+
+	rec := &keyRecorder{}
+	client := goredis.NewClient(&goredis.Options{Addr: "127.0.0.1:0"})
+	client.AddHook(rec)
+	t.Cleanup(func() { _ = client.Close() })
+	return client, rec
+}
+
+// touchAllStores issues one write per key family through stores built with
+// prefix, returning the keys they used.
+func touchAllStores(t *testing.T, prefix string) []string {
+	t.Helper()
+
+	ctx := context.Background()
+	client, rec := newKeyRecordingClient(t)
+
+	_ = redis.NewLocationStore(client, prefix, nil).UpdateLocation(ctx, "driver-1", 12.97, 77.59, 90)
+	_, _ = redis.NewLockStore(client, prefix).AcquireDriverLock(ctx, "driver-1", time.Second)
+	cache := redis.NewCacheStore(client, prefix)
+	_ = cache.SetDriver(ctx, &redis.CachedDriver{ID: "driver-1"})
+	_ = cache.SetRide(ctx, &redis.CachedRide{ID: "ride-1"})
+	_, _ = cache.AcquireRideLock(ctx, "ride-1", time.Second)
+	_ = cache.AddAvailableDriver(ctx, "driver-1")
+	_ = redis.NewNotificationBroker(client, prefix).Publish(ctx, "rider-1", []byte("{}"))
+	_, _ = redis.NewDeviationStore(client, prefix).IncrementOffRoute(ctx, "trip-1")
+	_, _ = redis.NewArrivalStore(client, prefix).IncrementInFence(ctx, "ride-1")
+	_, _ = redis.NewEmailTokenStore(client, prefix).AcquireResendSlot(ctx, "rider-1", time.Minute)
+	_ = redis.NewQuoteStore(client, prefix).SaveQuote(ctx, "quote-1", redis.RideQuote{}, time.Minute)
+	_ = redis.NewSurgeStore(client, prefix).SetMultiplier(ctx, "1297:7759", 1.5, time.Minute)
+	_ = redis.NewExclusionStore(client, prefix).AddExcludedDrivers(ctx, "ride-1", []string{"driver-1"}, time.Minute)
+	_ = redis.NewEmailTokenStore(client, prefix).SaveVerification(ctx, "token-1", redis.EmailVerification{}, time.Minute)
+	_, _ = redis.NewNotificationThrottleStore(client, prefix).AcquireNotifySlot(ctx, "driver-1", "RIDE_REQUESTED", time.Minute)
+	_ = redis.NewDestinationStore(client, prefix).SetDestination(ctx, "driver-1", redis.DriverDestination{ExpiresAt: time.Now().Add(time.Hour)})
+	queue := redis.NewRideQueueStore(client, prefix)
+	_ = queue.Enqueue(ctx, "259:1551", "ride-1", 1)
+	_ = queue.RecordMatch(ctx, "259:1551", "ride-1", time.Now(), time.Minute)
+	guard := redis.NewLocationGuardStore(client, prefix)
+	_ = guard.SetLastFix(ctx, "driver-1", redis.LocationFix{Lat: 12.97, Lng: 77.59, At: time.Now()})
+	_, _ = guard.IncrementSpeedAnomalies(ctx, "driver-1")
+	_ = redis.NewTripETAStore(client, prefix).FreezeRemaining(ctx, "trip-1", time.Unix(1700000000, 0), 4.2, time.Minute)
+
+	keys := rec.Keys()
+	if len(keys) == 0 {
+		t.Fatal("expected the stores to issue commands")
+	}
+	return keys
+}
+
+func TestRedisKeyPrefix_AppliedToEveryStore(t *testing.T) {
+	t.Parallel()
+
+	unprefixed := touchAllStores(t, "")
+	prefixed := touchAllStores(t, "ride:staging:")
+	if len(prefixed) != len(unprefixed) {
+		t.Fatalf("expected the same commands with and without a prefix, got %v and %v", unprefixed, prefixed)
+	}
+	for i, key := range prefixed {
+		if key != "ride:staging:"+unprefixed[i] {
+			t.Errorf("expected %q prefixed, got %q", unprefixed[i], key)
+		}
+	}
+}
+
+func TestRedisKeyPrefix_NamespacesDoNotCollide(t *testing.T) {
+	t.Parallel()
+
+	staging := touchAllStores(t, "staging:")
+	production := touchAllStores(t, "production:")
+	seen := make(map[string]bool, len(staging))
+	for _, key := range staging {
+		seen[key] = true
+	}
+	for _, key := range production {
+		if seen[key] {
+			t.Errorf("key %q used by both namespaces", key)
+		}
+	}
+}
+
+func TestRedisKeyPrefix_IdempotencyKeys(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+	keysFor := func(prefix string) []string {
+		client, rec := newKeyRecordingClient(t)
+		router := gin.New()
+		router.Use(middleware.IdempotencyMiddleware(client, prefix))
+		router.POST("/v1/rides", func(c *gin.Context) {
+			c.JSON(http.StatusCreated, gin.H{"id": "ride-1"})
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/rides", nil)
+		req.Header.Set("Idempotency-Key", "abc")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+		return rec.Keys()
+	}
+
+	for _, key := range keysFor("") {
+		if key != "idempotency:abc" {
+			t.Errorf("expected the unprefixed key unchanged, got %q", key)
+		}
+	}
+	staging, production := keysFor("staging:"), keysFor("production:")
+	if len(staging) == 0 || len(production) == 0 {
+		t.Fatal("expected the middleware to look up and store the response")
+	}
+	for i := range staging {
+		if !strings.HasPrefix(staging[i], "staging:") || staging[i] == production[i] {
+			t.Errorf("expected distinct namespaced keys, got %q and %q", staging[i], production[i])
+		}
+	}
+}
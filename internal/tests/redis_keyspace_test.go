@@ -0,0 +1,182 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	goredis "github.com/redis/go-redis/v9"
+
+	"ride/internal/handler"
+	"ride/internal/redis"
+	"ride/internal/redis/keyspace"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// REDIS KEYSPACE
+// ──────────────────────────────────────────────
+
+func TestRedisKeyspace_NoStoreWritesUnprefixedKey(t *testing.T) {
+	t.Parallel()
+
+	keys := keyspace.New("ride:")
+	for _, key := range touchAllStores(t, "ride:") {
+		if !strings.HasPrefix(key, "ride:") {
+			t.Errorf("expected %q under the app prefix", key)
+			continue
+		}
+		if strings.HasPrefix(key, "ride:notifications:") {
+			continue // A pub/sub channel, not a key
+		}
+		if _, ok := keys.Namespace(key); !ok {
+			t.Errorf("expected %q in a known namespace", key)
+		}
+	}
+}
+
+func TestRedisKeyspace_LocationPingsRecordHeartbeat(t *testing.T) {
+	t.Parallel()
+
+	client, rec := newKeyRecordingClient(t)
+	store := redis.NewLocationStore(client, "ride:", nil)
+
+	_ = store.UpdateLocation(context.Background(), "driver-1", 12.97, 77.59, 0)
+	if got := strings.Join(rec.Commands(), ", "); !strings.Contains(got, "zadd ride:drivers:heartbeats") {
+		t.Errorf("expected the ping recorded in the heartbeat set, got %s", got)
+	}
+
+	_ = store.RemoveLocation(context.Background(), "driver-1")
+	if got := strings.Join(rec.Commands(), ", "); !strings.Contains(got, "zrem ride:drivers:heartbeats") {
+		t.Errorf("expected going offline to drop the heartbeat, got %s", got)
+	}
+}
+
+func TestRedisKeyspace_SweepRemovesDriversWithoutRecentPing(t *testing.T) {
+	t.Parallel()
+
+	locations := NewMockLocationStore()
+	now := time.Now()
+	_ = locations.UpdateLocation(context.Background(), "fresh", 12.97, 77.59, 0)
+	_ = locations.UpdateLocation(context.Background(), "stale", 12.98, 77.59, 0)
+	locations.SetLastSeen("fresh", now.Add(-time.Minute))
+	locations.SetLastSeen("stale", now.Add(-time.Hour))
+
+	driverService := service.NewDriverService(locations, nil, NewMockDriverRepository(), nil, nil, nil, nil, 0, nil)
+	removed, err := driverService.SweepStaleLocations(context.Background(), now, 10*time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if removed != 1 || locations.HasLocation("stale") || !locations.HasLocation("fresh") {
+		t.Errorf("expected only the stale driver removed, got %d removed, stale=%v fresh=%v",
+			removed, locations.HasLocation("stale"), locations.HasLocation("fresh"))
+	}
+}
+
+// scanResponder is a go-redis hook serving SCAN and TTL from a fixed
+// keyspace, keys mapped to their TTL (-1 for none).
+type scanResponder struct {
+	keys    map[string]time.Duration
+	matches []string
+}
+
+func (r *scanResponder) DialHook(next goredis.DialHook) goredis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return nil, goredis.ErrClosed
+	}
+}
+
+func (r *scanResponder) ProcessHook(next goredis.ProcessHook) goredis.ProcessHook {
+	return func(ctx context.Context, cmd goredis.Cmder) error {
+		if scan, ok := cmd.(*goredis.ScanCmd); ok {
+			args := scan.Args()
+			r.matches = append(r.matches, args[3].(string))
+			// Two pages, to exercise the cursor.
+			var page []string
+			var next uint64
+			for key := range r.keys {
+				page = append(page, key)
+			}
+			sort.Strings(page)
+			if args[1].(uint64) == 0 {
+				page, next = page[:len(page)/2], 1
+			} else {
+				page = page[len(page)/2:]
+			}
+			scan.SetVal(page, next)
+		}
+		return nil
+	}
+}
+
+func (r *scanResponder) ProcessPipelineHook(next goredis.ProcessPipelineHook) goredis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []goredis.Cmder) error {
+		for _, cmd := range cmds {
+			if ttl, ok := cmd.(*goredis.DurationCmd); ok {
+				ttl.SetVal(r.keys[ttl.Args()[1].(string)])
+			}
+		}
+		return nil
+	}
+}
+
+func TestRedisKeyspace_AuditCountsKeysPerNamespace(t *testing.T) {
+	t.Parallel()
+
+	responder := &scanResponder{keys: map[string]time.Duration{
+		"ride*:drivers:locations":           -1,
+		"ride*:drivers:locations:bengaluru": -1,
+		"ride*:cache:driver:driver-1":       30 * time.Second,
+		"ride*:cache:driver:driver-2":       -1, // Leaked: cached drivers always expire
+		"ride*:quote:quote-1":               time.Minute,
+		"ride*:legacy:thing":                -1,
+	}}
+	client := goredis.NewClient(&goredis.Options{Addr: "127.0.0.1:0"})
+	client.AddHook(responder)
+	t.Cleanup(func() { _ = client.Close() })
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/v1/admin/redis/keys", handler.NewRedisKeysHandler(redis.NewKeyAuditor(client, "ride*:")).GetKeys)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/admin/redis/keys", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp handler.RedisKeysResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	}
+	if len(responder.matches) != 2 || responder.matches[0] != `ride\*:*` {
+		t.Errorf("expected two SCANs matching the escaped prefix, got %v", responder.matches)
+	}
+	if resp.Scanned != 6 || resp.Unknown != 1 || len(resp.UnknownSample) != 1 || resp.UnknownSample[0] != "ride*:legacy:thing" {
+		t.Errorf("expected 6 keys scanned and the legacy key unknown, got %+v", resp)
+	}
+	if len(resp.Namespaces) != len(keyspace.Namespaces) {
+		t.Fatalf("expected every namespace reported, got %d", len(resp.Namespaces))
+	}
+
+	usage := make(map[string]handler.NamespaceUsageResponse, len(resp.Namespaces))
+	for _, ns := range resp.Namespaces {
+		usage[ns.Namespace] = ns
+	}
+	for name, want := range map[string][2]int64{
+		"drivers:locations": {2, 2},
+		"cache:driver:":     {2, 1},
+		"quote:":            {1, 0},
+		"idempotency:":      {0, 0},
+	} {
+		if got := usage[name]; got.Keys != want[0] || got.WithoutTTL != want[1] || got.Cleanup == "" {
+			t.Errorf("%s: expected %d keys, %d without TTL, got %+v", name, want[0], want[1], got)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// DAILY RECONCILIATION REPORT
+// ──────────────────────────────────────────────
+
+var reportDay = time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC)
+
+// seedReportTrip adds an ENDED trip (and its ride) ending at the given time.
+func seedReportTrip(tripRepo *MockTripRepository, rideRepo *MockRideRepository, id string, fare float64, method domain.PaymentMethod, endedAt time.Time) {
+	rideRepo.AddRide(&domain.Ride{
+		ID:            "ride-" + id,
+		RiderID:       "rider-1",
+		Status:        domain.RideStatusCompleted,
+		PaymentMethod: method,
+	})
+	_ = tripRepo.Create(context.Background(), &domain.Trip{
+		ID:        id,
+		RideID:    "ride-" + id,
+		DriverID:  "driver-1",
+		Status:    domain.TripStatusEnded,
+		Fare:      fare,
+		StartedAt: endedAt.Add(-20 * time.Minute),
+		EndedAt:   endedAt,
+	})
+}
+
+func seedReportPayment(paymentRepo *MockPaymentRepository, tripID string, amount float64, status domain.PaymentStatus) {
+	_ = paymentRepo.Create(context.Background(), &domain.Payment{
+		ID:             "pay-" + tripID + "-" + string(status),
+		TripID:         tripID,
+		Amount:         amount,
+		Status:         status,
+		IdempotencyKey: "payment:" + tripID + ":" + string(status),
+	})
+}
+
+func newSeededReportService(t *testing.T, mismatched bool) *service.ReportService {
+	t.Helper()
+
+	tripRepo := NewMockTripRepository()
+	rideRepo := NewMockRideRepository()
+	paymentRepo := NewMockPaymentRepository()
+
+	noon := reportDay.Add(12 * time.Hour)
+	seedReportTrip(tripRepo, rideRepo, "trip-card", 20.00, domain.PaymentMethodCard, noon)
+	seedReportPayment(paymentRepo, "trip-card", 20.00, domain.PaymentStatusSuccess)
+
+	seedReportTrip(tripRepo, rideRepo, "trip-failed", 12.50, domain.PaymentMethodCard, noon)
+	seedReportPayment(paymentRepo, "trip-failed", 12.50, domain.PaymentStatusFailed)
+
+	seedReportTrip(tripRepo, rideRepo, "trip-cash", 8.25, domain.PaymentMethodCash, noon)
+	seedReportPayment(paymentRepo, "trip-cash", 8.25, domain.PaymentStatusCashDue)
+
+	// Ended the previous day - must not be counted.
+	seedReportTrip(tripRepo, rideRepo, "trip-yesterday", 99.00, domain.PaymentMethodCard, reportDay.Add(-time.Minute))
+	seedReportPayment(paymentRepo, "trip-yesterday", 99.00, domain.PaymentStatusSuccess)
+
+	if mismatched {
+		// Charged less than the fare.
+		seedReportTrip(tripRepo, rideRepo, "trip-short", 15.00, domain.PaymentMethodCard, noon)
+		seedReportPayment(paymentRepo, "trip-short", 10.00, domain.PaymentStatusSuccess)
+	}
+
+	return service.NewReportService(NewMockReportRepository(tripRepo, rideRepo, paymentRepo))
+}
+
+func TestDailyReport_BalancedDay(t *testing.T) {
+	t.Parallel()
+
+	report, err := newSeededReportService(t, false).DailyReport(context.Background(), reportDay)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if report.Totals.TripsEnded != 3 {
+		t.Errorf("expected 3 trips ended, got %d", report.Totals.TripsEnded)
+	}
+	if report.Totals.GrossFares != 40.75 {
+		t.Errorf("expected gross fares 40.75, got %.2f", report.Totals.GrossFares)
+	}
+	if report.Totals.SuccessfulPayments.Amount != 20.00 || report.Totals.FailedPayments.Amount != 12.50 {
+		t.Errorf("unexpected success/failed totals: %+v", report.Totals)
+	}
+	if report.Totals.CashAwaiting.Count != 1 || report.Totals.CashAwaiting.Amount != 8.25 {
+		t.Errorf("expected 8.25 cash awaiting, got %+v", report.Totals.CashAwaiting)
+	}
+	if report.AccountedAmount != 40.75 || report.Difference != 0 {
+		t.Errorf("expected accounted 40.75 and zero difference, got %.2f / %.2f", report.AccountedAmount, report.Difference)
+	}
+	if !report.Balanced {
+		t.Error("expected report to be balanced")
+	}
+	if len(report.Discrepancies) != 0 {
+		t.Errorf("expected no discrepancies, got %v", report.Discrepancies)
+	}
+}
+
+func TestDailyReport_MismatchedRowFlagged(t *testing.T) {
+	t.Parallel()
+
+	report, err := newSeededReportService(t, true).DailyReport(context.Background(), reportDay)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if report.Balanced {
+		t.Error("expected report to be unbalanced")
+	}
+	if report.Difference != 5.00 {
+		t.Errorf("expected difference 5.00, got %.2f", report.Difference)
+	}
+	if len(report.Discrepancies) != 1 || report.Discrepancies[0].TripID != "trip-short" {
+		t.Fatalf("expected trip-short flagged, got %v", report.Discrepancies)
+	}
+	if report.Discrepancies[0].AccountedAmount != 10.00 {
+		t.Errorf("expected accounted 10.00, got %.2f", report.Discrepancies[0].AccountedAmount)
+	}
+}
+
+func TestDailyReport_Handler_CSVAndValidation(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/v1/admin/reports/daily", handler.NewReportHandler(newSeededReportService(t, true)).GetDailyReport)
+
+	// Bad date.
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/admin/reports/daily?date=14-03-2026", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for bad date, got %d", w.Code)
+	}
+
+	// JSON.
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/admin/reports/daily?date=2026-03-14", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var resp handler.DailyReportResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	if resp.Balanced || len(resp.Discrepancies) != 1 {
+		t.Errorf("expected one discrepancy in JSON response, got %+v", resp)
+	}
+
+	// CSV.
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/admin/reports/daily?date=2026-03-14&format=csv", nil))
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("expected text/csv content type, got %q", ct)
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, "trips_ended,4,55.75") {
+		t.Errorf("expected trips_ended row in CSV, got:\n%s", body)
+	}
+	if !strings.Contains(body, "trip-short,15.00,10.00") {
+		t.Errorf("expected discrepancy row in CSV, got:\n%s", body)
+	}
+}
@@ -0,0 +1,220 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/middleware"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// RESPONSE SHAPES (GOLDEN JSON)
+// ──────────────────────────────────────────────
+
+var goldenTime = time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC)
+
+// assertGoldenJSON compares got with the golden document, ignoring whitespace.
+func assertGoldenJSON(t *testing.T, name string, got []byte, golden string) {
+	t.Helper()
+	var want bytes.Buffer
+	if err := json.Compact(&want, []byte(golden)); err != nil {
+		t.Fatalf("%s: invalid golden JSON: %v", name, err)
+	}
+	var have bytes.Buffer
+	if err := json.Compact(&have, got); err != nil {
+		t.Fatalf("%s: invalid response JSON: %v", name, err)
+	}
+	if have.String() != want.String() {
+		t.Errorf("%s:\n got: %s\nwant: %s", name, have.String(), want.String())
+	}
+}
+
+func serveGolden(t *testing.T, router *gin.Engine, path string) []byte {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET %s: expected 200, got %d: %s", path, w.Code, w.Body.String())
+	}
+	return w.Body.Bytes()
+}
+
+func TestGoldenJSON_TripResponses(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	tripRepo := NewMockTripRepository()
+	_ = tripRepo.Create(ctx, &domain.Trip{
+		ID: "trip-done", RideID: "ride-1", DriverID: "driver-1", Status: domain.TripStatusEnded,
+		Fare: 42.5, StartedAt: goldenTime, EndedAt: goldenTime.Add(20 * time.Minute), CreatedAt: goldenTime,
+	})
+	_ = tripRepo.Create(ctx, &domain.Trip{
+		ID: "trip-paused", RideID: "ride-2", DriverID: "driver-2", Status: domain.TripStatusPaused,
+		StartedAt: goldenTime, PausedAt: goldenTime.Add(5 * time.Minute), TotalPaused: 90 * time.Second, CreatedAt: goldenTime,
+	})
+
+	tripService := service.NewTripService(service.TripServiceDeps{TripRepo: tripRepo})
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.IdentityMiddleware(testAdminToken))
+	router.GET("/v1/trips/:id", handler.NewTripHandler(tripService, nil).GetTrip)
+
+	// A completed trip that was never paused still reports total_paused_seconds: 0.
+	assertGoldenJSON(t, "completed trip", serveGolden(t, router, "/v1/trips/trip-done"), `{
+		"trip_id": "trip-done",
+		"ride_id": "ride-1",
+		"driver_id": "driver-1",
+		"status": "ENDED",
+		"fare": 42.5,
+		"started_at": "2026-03-14T09:30:00Z",
+		"ended_at": "2026-03-14T09:50:00Z",
+		"total_paused_seconds": 0,
+		"created_at": "2026-03-14T09:30:00Z",
+		"updated_at": "2026-03-14T09:30:00Z"
+	}`)
+
+	assertGoldenJSON(t, "paused trip", serveGolden(t, router, "/v1/trips/trip-paused"), `{
+		"trip_id": "trip-paused",
+		"ride_id": "ride-2",
+		"driver_id": "driver-2",
+		"status": "PAUSED",
+		"fare": 0,
+		"started_at": "2026-03-14T09:30:00Z",
+		"paused_at": "2026-03-14T09:35:00Z",
+		"total_paused_seconds": 90,
+		"created_at": "2026-03-14T09:30:00Z",
+		"updated_at": "2026-03-14T09:30:00Z"
+	}`)
+}
+
+func TestGoldenJSON_RideResponses(t *testing.T) {
+	t.Parallel()
+
+	rideRepo := NewMockRideRepository()
+	rideRepo.AddRide(&domain.Ride{
+		ID: "ride-open", RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59,
+		DestinationLat: 12.3, DestinationLng: 76.64, Status: domain.RideStatusRequested,
+		SurgeMultiplier: 1, PaymentMethod: domain.PaymentMethodCash,
+	})
+	rideRepo.AddRide(&domain.Ride{
+		ID: "ride-cancelled", RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59,
+		DestinationLat: 12.3, DestinationLng: 76.64, Status: domain.RideStatusCancelled,
+		AssignedDriverID: "driver-1", SurgeMultiplier: 1.5, PaymentMethod: domain.PaymentMethodCard,
+		CancelledAt: goldenTime, CancelReason: "changed plans",
+	})
+
+	rideService := service.NewRideService(service.RideServiceDeps{RideRepo: rideRepo, MatchingService: NewMockMatchingServiceForTest()})
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.IdentityMiddleware(testAdminToken))
+	router.GET("/v1/rides/:id", handler.NewRideHandler(rideService, nil, rideRepo, nil).GetRide)
+
+	// surge_multiplier is always present, even at 1.0; driver and cancellation fields only when set.
+	assertGoldenJSON(t, "open ride", serveGolden(t, router, "/v1/rides/ride-open"), `{
+		"id": "ride-open",
+		"rider_id": "rider-1",
+		"pickup_lat": 12.97,
+		"pickup_lng": 77.59,
+		"destination_lat": 12.3,
+		"destination_lng": 76.64,
+		"status": "REQUESTED",
+		"surge_multiplier": 1,
+		"surge_active": false,
+		"payment_method": "CASH"
+	}`)
+
+	assertGoldenJSON(t, "cancelled ride", serveGolden(t, router, "/v1/rides/ride-cancelled"), `{
+		"id": "ride-cancelled",
+		"rider_id": "rider-1",
+		"pickup_lat": 12.97,
+		"pickup_lng": 77.59,
+		"destination_lat": 12.3,
+		"destination_lng": 76.64,
+		"status": "CANCELLED",
+		"assigned_driver_id": "driver-1",
+		"surge_multiplier": 1.5,
+		"surge_active": true,
+		"payment_method": "CARD",
+		"cancelled_at": "2026-03-14T09:30:00Z",
+		"cancel_reason": "changed plans"
+	}`)
+}
+
+func TestGoldenJSON_EmptyListsAreArrays(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/v1/rides", handler.NewRideHandler(nil, nil, NewMockRideRepository(), nil).GetAll)
+	router.GET("/v1/drivers", handler.NewDriverHandler(nil, nil, NewMockDriverRepository(), "", nil, nil).GetAll)
+
+	assertGoldenJSON(t, "empty rides", serveGolden(t, router, "/v1/rides"), `[]`)
+	assertGoldenJSON(t, "empty drivers", serveGolden(t, router, "/v1/drivers"), `{"data": [], "total": 0, "limit": 50, "offset": 0}`)
+}
+
+func TestGoldenJSON_StaticShapes(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name   string
+		value  any
+		golden string
+	}{
+		{
+			"create ride without driver",
+			handler.CreateRideResponse{ID: "ride-1", RiderID: "rider-1", Status: "REQUESTED", SurgeMultiplier: 1, PaymentMethod: "CASH"},
+			`{"id":"ride-1","rider_id":"rider-1","pickup_lat":0,"pickup_lng":0,"destination_lat":0,"destination_lng":0,
+			  "status":"REQUESTED","driver_assigned":false,"surge_multiplier":1,"surge_active":false,"payment_method":"CASH"}`,
+		},
+		{
+			"accept ride",
+			handler.AcceptRideResponse{TripID: "trip-1", RideID: "ride-1", DriverID: "driver-1", Status: "STARTED", StartedAt: "2026-03-14T09:30:00Z"},
+			`{"trip_id":"trip-1","ride_id":"ride-1","driver_id":"driver-1","status":"STARTED","started_at":"2026-03-14T09:30:00Z"}`,
+		},
+		{
+			"driver",
+			handler.DriverResponse{ID: "driver-1", Name: "Asha", Phone: "+91", Status: "OFFLINE", Tier: "BASIC"},
+			`{"id":"driver-1","name":"Asha","phone":"+91","status":"OFFLINE","tier":"BASIC"}`,
+		},
+		{
+			"user",
+			handler.UserResponse{ID: "user-1", Name: "Ravi", Phone: "+92"},
+			`{"id":"user-1","name":"Ravi","phone":"+92"}`,
+		},
+		{
+			"payment",
+			handler.PaymentResponse{ID: "pay-1", TripID: "trip-1", Amount: 0, Status: "PENDING", IdempotencyKey: "k"},
+			`{"id":"pay-1","trip_id":"trip-1","amount":0,"status":"PENDING","idempotency_key":"k"}`,
+		},
+		{
+			"driver eta",
+			handler.DriverETAResponse{RideID: "ride-1", DriverID: "driver-1"},
+			`{"ride_id":"ride-1","driver_id":"driver-1","driver_lat":0,"driver_lng":0,"distance_km":0,"eta_seconds":0,"eta_minutes":0}`,
+		},
+		{
+			"error",
+			handler.ErrorResponse{Error: "ride not found"},
+			`{"error":"ride not found"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		got, err := json.Marshal(tc.value)
+		if err != nil {
+			t.Fatalf("%s: marshal failed: %v", tc.name, err)
+		}
+		assertGoldenJSON(t, tc.name, got, tc.golden)
+	}
+}
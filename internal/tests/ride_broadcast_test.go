@@ -0,0 +1,146 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/redis"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// RIDE REQUEST BROADCAST
+// ──────────────────────────────────────────────
+
+// newBroadcastRideService places drivers near the pickup, nearest first:
+// driver-1 (matched to every ride), driver-2 on a trip, driver-3 on a break,
+// then driver-4, driver-5 and driver-6 online. Each request is told to the
+// 3 nearest eligible drivers.
+func newBroadcastRideService(env *testEnv, matching *MockMatchingServiceForTest, throttle *MockNotificationThrottleStore) *service.RideService {
+	statuses := []domain.DriverStatus{
+		domain.DriverStatusOnTrip, domain.DriverStatusOnTrip, domain.DriverStatusBreak,
+		domain.DriverStatusOnline, domain.DriverStatusOnline, domain.DriverStatusOnline,
+	}
+	for i, status := range statuses {
+		id := fmt.Sprintf("driver-%d", i+1)
+		env.drivers.AddDriver(&domain.Driver{ID: id, Status: status, Tier: domain.DriverTierBasic})
+		env.locations.AddDriverLocation(redis.DriverLocation{DriverID: id, Lat: 12.97 + float64(i)*0.001, Lng: 77.59})
+	}
+	matching.SetResult(&service.MatchResult{DriverID: "driver-1", Ride: &domain.Ride{ID: "matched"}}, nil)
+
+	deps := env.rideDeps(matching)
+	deps.Broadcast = service.NewRideBroadcastService(env.locations, env.drivers, throttle, env.notificationService(), 3, 5, time.Minute)
+	return service.NewRideService(deps)
+}
+
+func requestBroadcastRide(t *testing.T, rideService *service.RideService) {
+	t.Helper()
+
+	if _, err := rideService.CreateRide(context.Background(), service.CreateRideRequest{
+		RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 13.0, DestinationLng: 77.6,
+	}); err != nil {
+		t.Fatalf("failed to create ride: %v", err)
+	}
+}
+
+// notifiedDrivers returns the drivers holding a ride request notification,
+// and how many each holds.
+func notifiedDrivers(env *testEnv) map[string]int {
+	counts := make(map[string]int)
+	for i := 1; i <= 6; i++ {
+		id := fmt.Sprintf("driver-%d", i)
+		events, _ := env.notifications.ListSince(context.Background(), id, 0, 100)
+		for _, e := range events {
+			if e.Type == string(service.NotificationRideRequested) {
+				counts[id]++
+			}
+		}
+	}
+	return counts
+}
+
+func describeNotified(counts map[string]int) string {
+	var ids []string
+	for id, n := range counts {
+		ids = append(ids, fmt.Sprintf("%s=%d", id, n))
+	}
+	sort.Strings(ids)
+	return strings.Join(ids, ",")
+}
+
+func TestRideBroadcast_NearestEligibleDriversNotified(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	matching, throttle := NewMockMatchingServiceForTest(), NewMockNotificationThrottleStore()
+	rideService := newBroadcastRideService(env, matching, throttle)
+	requestBroadcastRide(t, rideService)
+
+	// driver-1 was assigned and driver-2 is on a trip; driver-6 is beyond the top 3.
+	if got := describeNotified(notifiedDrivers(env)); got != "driver-3=1,driver-4=1,driver-5=1" {
+		t.Errorf("expected driver-3, driver-4 and driver-5 notified once, got %s", got)
+	}
+}
+
+func TestRideBroadcast_CooldownPerDriver(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	matching, throttle := NewMockMatchingServiceForTest(), NewMockNotificationThrottleStore()
+	rideService := newBroadcastRideService(env, matching, throttle)
+	requestBroadcastRide(t, rideService)
+	requestBroadcastRide(t, rideService)
+	if got := describeNotified(notifiedDrivers(env)); got != "driver-3=1,driver-4=1,driver-5=1" {
+		t.Errorf("expected no second notification within the cooldown, got %s", got)
+	}
+
+	// A driver newly among the nearest is told despite the others' cooldown.
+	_ = env.drivers.UpdateStatus(context.Background(), "driver-2", domain.DriverStatusOnline)
+	throttle.Advance(30 * time.Second)
+	requestBroadcastRide(t, rideService)
+	if got := describeNotified(notifiedDrivers(env)); got != "driver-2=1,driver-3=1,driver-4=1,driver-5=1" {
+		t.Errorf("expected only driver-2 newly notified, got %s", got)
+	}
+
+	// Once the cooldown has passed, the nearest drivers are told again.
+	throttle.Advance(31 * time.Second)
+	requestBroadcastRide(t, rideService)
+	if got := describeNotified(notifiedDrivers(env)); got != "driver-2=1,driver-3=2,driver-4=2,driver-5=1" {
+		t.Errorf("expected driver-3 and driver-4 notified again after the cooldown, got %s", got)
+	}
+}
+
+func TestRideBroadcast_UnmatchedRideNotifiesNearest(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	matching, throttle := NewMockMatchingServiceForTest(), NewMockNotificationThrottleStore()
+	rideService := newBroadcastRideService(env, matching, throttle)
+	matching.SetResult(nil, service.ErrNoDriverAvailable)
+	_ = env.drivers.UpdateStatus(context.Background(), "driver-1", domain.DriverStatusOnline)
+
+	requestBroadcastRide(t, rideService)
+	if got := describeNotified(notifiedDrivers(env)); got != "driver-1=1,driver-3=1,driver-4=1" {
+		t.Errorf("expected the 3 nearest eligible drivers notified, got %s", got)
+	}
+}
+
+func TestRideBroadcast_ThrottleUnavailableNotifiesNobody(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	matching, throttle := NewMockMatchingServiceForTest(), NewMockNotificationThrottleStore()
+	rideService := newBroadcastRideService(env, matching, throttle)
+	throttle.AcquireError = errors.New("connection refused")
+
+	requestBroadcastRide(t, rideService)
+	if got := notifiedDrivers(env); len(got) != 0 {
+		t.Errorf("expected no notifications without the cooldown store, got %v", got)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"ride/internal/domain"
+	"ride/internal/repository"
 	"ride/internal/service"
 )
 
@@ -19,7 +20,7 @@ func TestRideCreation_ValidInput_Succeeds(t *testing.T) {
 	rideRepo := NewMockRideRepository()
 	matchingService := NewMockMatchingServiceForTest()
 
-	rideService := service.NewRideService(rideRepo, matchingService, nil, nil)
+	rideService := service.NewRideService(service.RideServiceDeps{RideRepo: rideRepo, MatchingService: matchingService})
 
 	req := service.CreateRideRequest{
 		RiderID:        "rider-1",
@@ -86,7 +87,7 @@ func TestRideCreation_MissingCoordinates_Fails(t *testing.T) {
 
 			rideRepo := NewMockRideRepository()
 			matchingService := NewMockMatchingServiceForTest()
-			rideService := service.NewRideService(rideRepo, matchingService, nil, nil)
+			rideService := service.NewRideService(service.RideServiceDeps{RideRepo: rideRepo, MatchingService: matchingService})
 
 			_, err := rideService.CreateRide(context.Background(), tc.req)
 			if tc.wantErr && err == nil {
@@ -104,7 +105,7 @@ func TestRideCreation_MissingRiderID_Fails(t *testing.T) {
 
 	rideRepo := NewMockRideRepository()
 	matchingService := NewMockMatchingServiceForTest()
-	rideService := service.NewRideService(rideRepo, matchingService, nil, nil)
+	rideService := service.NewRideService(service.RideServiceDeps{RideRepo: rideRepo, MatchingService: matchingService})
 
 	req := service.CreateRideRequest{
 		RiderID:        "", // Missing rider ID
@@ -203,7 +204,7 @@ func TestRideCreation_InvalidCoordinates_Rejected(t *testing.T) {
 
 			rideRepo := NewMockRideRepository()
 			matchingService := NewMockMatchingServiceForTest()
-			rideService := service.NewRideService(rideRepo, matchingService, nil, nil)
+			rideService := service.NewRideService(service.RideServiceDeps{RideRepo: rideRepo, MatchingService: matchingService})
 
 			_, err := rideService.CreateRide(context.Background(), tc.req)
 			if tc.wantErr && err == nil {
@@ -218,7 +219,7 @@ func TestRideCreation_AlwaysInRequestedState(t *testing.T) {
 
 	rideRepo := NewMockRideRepository()
 	matchingService := NewMockMatchingServiceForTest()
-	rideService := service.NewRideService(rideRepo, matchingService, nil, nil)
+	rideService := service.NewRideService(service.RideServiceDeps{RideRepo: rideRepo, MatchingService: matchingService})
 
 	req := service.CreateRideRequest{
 		RiderID:        "rider-1",
@@ -253,7 +254,7 @@ func TestRideCreation_PersistsAllFields(t *testing.T) {
 
 	rideRepo := NewMockRideRepository()
 	matchingService := NewMockMatchingServiceForTest()
-	rideService := service.NewRideService(rideRepo, matchingService, nil, nil)
+	rideService := service.NewRideService(service.RideServiceDeps{RideRepo: rideRepo, MatchingService: matchingService})
 
 	req := service.CreateRideRequest{
 		RiderID:        "rider-123",
@@ -296,7 +297,7 @@ func TestRideCreation_MultipleRidesAreDistinct(t *testing.T) {
 
 	rideRepo := NewMockRideRepository()
 	matchingService := NewMockMatchingServiceForTest()
-	rideService := service.NewRideService(rideRepo, matchingService, nil, nil)
+	rideService := service.NewRideService(service.RideServiceDeps{RideRepo: rideRepo, MatchingService: matchingService})
 
 	req := service.CreateRideRequest{
 		RiderID:        "rider-1",
@@ -334,7 +335,7 @@ func TestRideCreation_RepoCreateIsCalled(t *testing.T) {
 
 	rideRepo := NewMockRideRepository()
 	matchingService := NewMockMatchingServiceForTest()
-	rideService := service.NewRideService(rideRepo, matchingService, nil, nil)
+	rideService := service.NewRideService(service.RideServiceDeps{RideRepo: rideRepo, MatchingService: matchingService})
 
 	req := service.CreateRideRequest{
 		RiderID:        "rider-1",
@@ -360,10 +361,12 @@ func TestRideCreation_RepoCreateIsCalled(t *testing.T) {
 
 // MockMatchingServiceForTest implements MatchingServiceInterface for testing.
 type MockMatchingServiceForTest struct {
-	mu        sync.Mutex
-	callCount int
-	result    *service.MatchResult
-	err       error
+	mu          sync.Mutex
+	callCount   int
+	lastRequest service.MatchRequest
+	result      *service.MatchResult
+	err         error
+	drivers     map[string]*domain.Driver
 }
 
 // NewMockMatchingServiceForTest creates a new mock matching service.
@@ -379,12 +382,41 @@ func (m *MockMatchingServiceForTest) Match(ctx context.Context, req service.Matc
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.callCount++
+	m.lastRequest = req
 	if m.err != nil {
 		return nil, m.err
 	}
 	return m.result, nil
 }
 
+func (m *MockMatchingServiceForTest) AssignDriver(ctx context.Context, rideID, driverID string) (*service.MatchResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.result, nil
+}
+
+func (m *MockMatchingServiceForTest) GetDriver(ctx context.Context, driverID string) (*domain.Driver, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if driver, ok := m.drivers[driverID]; ok {
+		return driver, nil
+	}
+	return nil, repository.ErrNotFound
+}
+
+// AddDriver makes a driver available to GetDriver.
+func (m *MockMatchingServiceForTest) AddDriver(driver *domain.Driver) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.drivers == nil {
+		m.drivers = make(map[string]*domain.Driver)
+	}
+	m.drivers[driver.ID] = driver
+}
+
 func (m *MockMatchingServiceForTest) SetResult(result *service.MatchResult, err error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -397,3 +429,10 @@ func (m *MockMatchingServiceForTest) CallCount() int {
 	defer m.mu.Unlock()
 	return m.callCount
 }
+
+// LastRequest returns the most recent match request.
+func (m *MockMatchingServiceForTest) LastRequest() service.MatchRequest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lastRequest
+}
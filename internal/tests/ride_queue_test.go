@@ -0,0 +1,228 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/middleware"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// RIDE QUEUE POSITION
+// ──────────────────────────────────────────────
+
+// queueMatcher finds a driver only for the rides marked matchable, assigning
+// them in the ride repository as the real matcher would.
+type queueMatcher struct {
+	rides *MockRideRepository
+
+	mu        sync.Mutex
+	matchable map[string]bool
+}
+
+func (m *queueMatcher) allow(rideID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.matchable[rideID] = true
+}
+
+func (m *queueMatcher) Match(ctx context.Context, req service.MatchRequest) (*service.MatchResult, error) {
+	m.mu.Lock()
+	ok := m.matchable[req.RideID]
+	m.mu.Unlock()
+	if !ok {
+		return nil, service.ErrNoDriverAvailable
+	}
+
+	ride, err := m.rides.GetByID(ctx, req.RideID)
+	if err != nil {
+		return nil, err
+	}
+	if ride.Status != domain.RideStatusRequested {
+		return nil, service.ErrRideNotInRequestedState
+	}
+	ride.Status = domain.RideStatusAssigned
+	ride.AssignedDriverID = "driver-" + req.RideID
+	if err := m.rides.Update(ctx, ride); err != nil {
+		return nil, err
+	}
+	return &service.MatchResult{Ride: ride, DriverID: ride.AssignedDriverID}, nil
+}
+
+// AssignDriver is not used by the queue tests.
+func (m *queueMatcher) AssignDriver(ctx context.Context, rideID, driverID string) (*service.MatchResult, error) {
+	return nil, service.ErrNoDriverAvailable
+}
+
+func (m *queueMatcher) GetDriver(ctx context.Context, driverID string) (*domain.Driver, error) {
+	return &domain.Driver{ID: driverID}, nil
+}
+
+// queueBase is when the first waiting ride in a queue test was created.
+var queueBase = time.Now().Add(-10 * time.Minute)
+
+func newQueueRideService(env *testEnv, matcher *queueMatcher, store *MockRideQueueStore) *service.RideService {
+	deps := env.rideDeps(matcher)
+	deps.Queue = service.NewRideQueueService(store, 15*time.Minute, nil)
+	return service.NewRideService(deps)
+}
+
+// addWaitingRide seeds a REQUESTED ride at the shared pickup, created
+// minutes after queueBase.
+func addWaitingRide(env *testEnv, id string, minutes int, priority bool) {
+	env.rides.AddRide(&domain.Ride{
+		ID: id, RiderID: "rider-" + id, PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 13.0, DestinationLng: 77.6,
+		Status: domain.RideStatusRequested, PaymentMethod: domain.PaymentMethodCash, Priority: priority,
+		CreatedAt: queueBase.Add(time.Duration(minutes) * time.Minute), Version: 1,
+	})
+}
+
+// retryUnmatched runs one background rematch pass.
+func retryUnmatched(t *testing.T, rideService *service.RideService) int {
+	t.Helper()
+
+	matched, err := rideService.RetryUnmatched(context.Background(), 50)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return matched
+}
+
+// getQueuedRide fetches a ride as its rider.
+func getQueuedRide(t *testing.T, rideService *service.RideService, id string) handler.GetRideResponse {
+	t.Helper()
+
+	router := newTestRouter()
+	router.Use(middleware.IdentityMiddleware(testAdminToken))
+	router.GET("/v1/rides/:id", handler.NewRideHandler(rideService, nil, nil, nil).GetRide)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/rides/"+id, nil)
+	req.Header.Set("X-User-ID", "rider-"+id)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp handler.GetRideResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	}
+	return resp
+}
+
+// expectPosition fails unless the ride is at position, with the given
+// estimated wait in seconds, 0 meaning none.
+func expectPosition(t *testing.T, rideService *service.RideService, id string, position, waitSeconds int) {
+	t.Helper()
+
+	resp := getQueuedRide(t, rideService, id)
+	if resp.QueuePosition == nil || *resp.QueuePosition != position {
+		t.Errorf("%s: expected queue position %d, got %v", id, position, resp.QueuePosition)
+	}
+	switch {
+	case waitSeconds == 0 && resp.EstimatedWaitSeconds != nil:
+		t.Errorf("%s: expected no estimated wait without recent matches, got %d", id, *resp.EstimatedWaitSeconds)
+	case waitSeconds != 0 && (resp.EstimatedWaitSeconds == nil || *resp.EstimatedWaitSeconds != waitSeconds):
+		t.Errorf("%s: expected an estimated wait of %ds, got %v", id, waitSeconds, resp.EstimatedWaitSeconds)
+	}
+}
+
+func TestRideQueue_PositionsShiftAsEarlierRidesAreMatched(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	matcher := &queueMatcher{rides: env.rides, matchable: make(map[string]bool)}
+	store := NewMockRideQueueStore()
+	rideService := newQueueRideService(env, matcher, store)
+	addWaitingRide(env, "ride-1", 0, false)
+	addWaitingRide(env, "ride-2", 1, false)
+	addWaitingRide(env, "ride-3", 2, false)
+
+	if matched := retryUnmatched(t, rideService); matched != 0 {
+		t.Fatalf("expected no driver for any ride, got %d matched", matched)
+	}
+	expectPosition(t, rideService, "ride-1", 1, 0)
+	expectPosition(t, rideService, "ride-2", 2, 0)
+	expectPosition(t, rideService, "ride-3", 3, 0)
+
+	// A driver frees up for the first ride.
+	matcher.allow("ride-1")
+	if matched := retryUnmatched(t, rideService); matched != 1 {
+		t.Fatalf("expected ride-1 matched, got %d matched", matched)
+	}
+
+	if resp := getQueuedRide(t, rideService, "ride-1"); resp.Status != string(domain.RideStatusAssigned) || resp.QueuePosition != nil || resp.EstimatedWaitSeconds != nil {
+		t.Errorf("expected the matched ride out of the queue, got %+v", resp)
+	}
+	// One match in the 15-minute window: 15 minutes per place.
+	expectPosition(t, rideService, "ride-2", 1, 900)
+	expectPosition(t, rideService, "ride-3", 2, 1800)
+}
+
+func TestRideQueue_CancelledRideLeavesQueue(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	matcher := &queueMatcher{rides: env.rides, matchable: make(map[string]bool)}
+	store := NewMockRideQueueStore()
+	rideService := newQueueRideService(env, matcher, store)
+	addWaitingRide(env, "ride-1", 0, false)
+	addWaitingRide(env, "ride-2", 1, false)
+	addWaitingRide(env, "ride-3", 2, false)
+	retryUnmatched(t, rideService)
+
+	if _, err := rideService.CancelRide(context.Background(), service.CancelRideRequest{RideID: "ride-1", CancelledBy: "rider-ride-1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectPosition(t, rideService, "ride-2", 1, 0)
+	expectPosition(t, rideService, "ride-3", 2, 0)
+	if queued := store.Queued(); len(queued) != 2 {
+		t.Errorf("expected the cancelled ride dequeued, got %v", queued)
+	}
+}
+
+func TestRideQueue_PriorityRidesQueueFirst(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	matcher := &queueMatcher{rides: env.rides, matchable: make(map[string]bool)}
+	store := NewMockRideQueueStore()
+	rideService := newQueueRideService(env, matcher, store)
+	addWaitingRide(env, "ride-1", 0, false)
+	addWaitingRide(env, "ride-2", 1, true)
+	retryUnmatched(t, rideService)
+
+	expectPosition(t, rideService, "ride-2", 1, 0)
+	expectPosition(t, rideService, "ride-1", 2, 0)
+}
+
+func TestRideQueue_UnmatchedRequestIsQueued(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	matcher := &queueMatcher{rides: env.rides, matchable: make(map[string]bool)}
+	store := NewMockRideQueueStore()
+	rideService := newQueueRideService(env, matcher, store)
+	resp, err := rideService.CreateRide(context.Background(), service.CreateRideRequest{
+		RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 13.0, DestinationLng: 77.6,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.DriverAssigned {
+		t.Fatal("expected no driver")
+	}
+	if queued := store.Queued(); len(queued) != 1 || queued[0] != resp.Ride.ID {
+		t.Errorf("expected the new ride queued, got %v", queued)
+	}
+}
@@ -0,0 +1,178 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/redis"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// RIDE QUOTES
+// ──────────────────────────────────────────────
+
+// newQuoteRideService sets up one open request and five drivers near the
+// pickup, so surge starts at 1.0x; surgeUp removes the drivers, taking it to
+// 2.0x.
+func newQuoteRideService(env *testEnv, quotes *MockQuoteStore) *service.RideService {
+	env.rides.AddRide(&domain.Ride{ID: "ride-open", RiderID: "rider-9", PickupLat: 12.97, PickupLng: 77.59, Status: domain.RideStatusRequested})
+	for _, id := range []string{"driver-1", "driver-2", "driver-3", "driver-4", "driver-5"} {
+		env.locations.AddDriverLocation(redis.DriverLocation{DriverID: id, Lat: 12.97, Lng: 77.59})
+	}
+
+	deps := env.rideDeps(NewMockMatchingServiceForTest())
+	deps.SurgeService = service.NewSurgeService(env.locations, env.rides, nil, 0, 0, nil, nil)
+	deps.QuoteService = service.NewQuoteService(quotes, "test-signing-key", 2*time.Minute)
+	return service.NewRideService(deps)
+}
+
+func newQuoteRouter(env *testEnv, rideService *service.RideService) *gin.Engine {
+	rideHandler := handler.NewRideHandler(rideService, nil, env.rides, nil)
+	router := newTestRouter()
+	router.POST("/v1/rides/estimate", rideHandler.EstimateRide)
+	router.POST("/v1/rides", rideHandler.CreateRide)
+	return router
+}
+
+func surgeUp(env *testEnv) {
+	env.locations.SetLocations(nil)
+}
+
+func estimateQuote(t *testing.T, router *gin.Engine) handler.EstimateRideResponse {
+	t.Helper()
+
+	body, _ := json.Marshal(handler.EstimateRideRequest{PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 12.3, DestinationLng: 76.64})
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/rides/estimate", bytes.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 from estimate, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp handler.EstimateRideResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	return resp
+}
+
+func createQuotedRide(t *testing.T, router *gin.Engine, pickupLat float64, quoteID string) handler.CreateRideResponse {
+	t.Helper()
+
+	body, _ := json.Marshal(handler.CreateRideRequest{
+		RiderID: "rider-1", PickupLat: pickupLat, PickupLng: 77.59, DestinationLat: 12.3, DestinationLng: 76.64, QuoteID: quoteID,
+	})
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/rides", bytes.NewReader(body)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201 from create, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp handler.CreateRideResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	return resp
+}
+
+func TestRideQuote_HonoredWithinTTL(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	quotes := NewMockQuoteStore()
+	rideService := newQuoteRideService(env, quotes)
+	router := newQuoteRouter(env, rideService)
+
+	quote := estimateQuote(t, router)
+	if quote.QuoteID == "" || quote.SurgeMultiplier != 1.0 || quote.ExpiresAt == "" {
+		t.Fatalf("expected a 1.0x quote with an expiry, got %+v", quote)
+	}
+
+	surgeUp(env)
+	quotes.Advance(time.Minute)
+
+	ride := createQuotedRide(t, router, 12.97, quote.QuoteID)
+	if ride.SurgeMultiplier != 1.0 || ride.QuoteRejected {
+		t.Errorf("expected the quoted 1.0x surge honored, got %+v", ride)
+	}
+	if stored := env.rides.GetRide(ride.ID); stored.QuoteID != quote.QuoteID {
+		t.Errorf("expected the quote ID recorded on the ride, got %q", stored.QuoteID)
+	}
+
+	// A quote prices one ride only.
+	again := createQuotedRide(t, router, 12.97, quote.QuoteID)
+	if again.SurgeMultiplier != 2.0 || !again.QuoteRejected {
+		t.Errorf("expected a reused quote priced live, got %+v", again)
+	}
+}
+
+func TestRideQuote_IgnoredAfterExpiry(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	quotes := NewMockQuoteStore()
+	rideService := newQuoteRideService(env, quotes)
+	router := newQuoteRouter(env, rideService)
+	quote := estimateQuote(t, router)
+
+	surgeUp(env)
+	quotes.Advance(2 * time.Minute)
+
+	ride := createQuotedRide(t, router, 12.97, quote.QuoteID)
+	if ride.SurgeMultiplier != 2.0 || !ride.QuoteRejected {
+		t.Errorf("expected an expired quote priced live and flagged, got %+v", ride)
+	}
+	if stored := env.rides.GetRide(ride.ID); stored.QuoteID != "" {
+		t.Errorf("expected no quote ID on a live-priced ride, got %q", stored.QuoteID)
+	}
+}
+
+func TestRideQuote_RejectedForDifferentCoordinates(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	quotes := NewMockQuoteStore()
+	rideService := newQuoteRideService(env, quotes)
+	router := newQuoteRouter(env, rideService)
+	quote := estimateQuote(t, router)
+	surgeUp(env)
+
+	// About 1 km north of the quoted pickup.
+	ride := createQuotedRide(t, router, 12.98, quote.QuoteID)
+	if ride.SurgeMultiplier != 2.0 || !ride.QuoteRejected {
+		t.Errorf("expected a quote for other coordinates rejected, got %+v", ride)
+	}
+}
+
+func TestRideQuote_TamperedIDRejected(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	quotes := NewMockQuoteStore()
+	rideService := newQuoteRideService(env, quotes)
+	router := newQuoteRouter(env, rideService)
+	quote := estimateQuote(t, router)
+	surgeUp(env)
+
+	id, _, _ := strings.Cut(quote.QuoteID, ".")
+	for _, tampered := range []string{id, id + ".deadbeef", "made-up." + strings.Repeat("0", 64)} {
+		resp, err := rideService.CreateRide(context.Background(), service.CreateRideRequest{
+			RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 12.3, DestinationLng: 76.64, QuoteID: tampered,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.SurgeMultiplier != 2.0 || !resp.QuoteRejected {
+			t.Errorf("%q: expected a tampered quote rejected, got surge %.2f", tampered, resp.SurgeMultiplier)
+		}
+	}
+
+	// The genuine quote is still intact after the tampered attempts.
+	if ride := createQuotedRide(t, router, 12.97, quote.QuoteID); ride.SurgeMultiplier != 1.0 || ride.QuoteRejected {
+		t.Errorf("expected the genuine quote honored, got %+v", ride)
+	}
+}
@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/middleware"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// RIDE REBOOKING
+// ──────────────────────────────────────────────
+
+// newRebookRouter serves rebooking over rides, which holds ride-1 of
+// rider-1 in status, quoted at 2.0x surge.
+func newRebookRouter(t *testing.T, status domain.RideStatus) (*gin.Engine, *MockRideRepository, *MockMatchingServiceForTest) {
+	t.Helper()
+
+	rides := NewMockRideRepository()
+	rides.AddRide(&domain.Ride{
+		ID: "ride-1", RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 13.2, DestinationLng: 77.7,
+		Status: status, Tier: domain.DriverTierPremium, PaymentMethod: domain.PaymentMethodCash,
+		SurgeMultiplier: 2.0, QuoteID: "quote-1", Version: 1,
+	})
+	matching := NewMockMatchingServiceForTest()
+	rideService := service.NewRideService(service.RideServiceDeps{RideRepo: rides, MatchingService: matching})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.IdentityMiddleware(testAdminToken))
+	router.POST("/v1/rides/:id/rebook", handler.NewRideHandler(rideService, nil, rides, nil).RebookRide)
+	return router, rides, matching
+}
+
+func postRebook(router *gin.Engine, rideID, userID string) (*httptest.ResponseRecorder, handler.CreateRideResponse) {
+	req := httptest.NewRequest(http.MethodPost, "/v1/rides/"+rideID+"/rebook", nil)
+	if userID != "" {
+		req.Header.Set("X-User-ID", userID)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var resp handler.CreateRideResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	return w, resp
+}
+
+func TestRideRebook_CopiesTripWithFreshPricing(t *testing.T) {
+	t.Parallel()
+
+	for _, status := range []domain.RideStatus{domain.RideStatusCancelled, domain.RideStatusCompleted} {
+		router, rides, matching := newRebookRouter(t, status)
+
+		w, resp := postRebook(router, "ride-1", "rider-1")
+		if w.Code != http.StatusCreated {
+			t.Fatalf("%s: expected 201, got %d: %s", status, w.Code, w.Body.String())
+		}
+		if resp.ID == "ride-1" || resp.RebookedFrom != "ride-1" || resp.RiderID != "rider-1" {
+			t.Errorf("%s: expected a new ride linked to ride-1, got %+v", status, resp)
+		}
+		if resp.PickupLat != 12.97 || resp.PickupLng != 77.59 || resp.DestinationLat != 13.2 || resp.DestinationLng != 77.7 || resp.PaymentMethod != "CASH" {
+			t.Errorf("%s: expected the original trip and payment method, got %+v", status, resp)
+		}
+		if resp.QuoteID != "" || resp.SurgeMultiplier != 1.0 {
+			t.Errorf("%s: expected live pricing without the old quote, got quote %q at %.2fx", status, resp.QuoteID, resp.SurgeMultiplier)
+		}
+		if got := matching.LastRequest().Tier; got != domain.DriverTierPremium {
+			t.Errorf("%s: expected matching for PREMIUM, got %q", status, got)
+		}
+
+		stored, err := rides.GetByID(context.Background(), resp.ID)
+		if err != nil || stored.RebookedFrom != "ride-1" || stored.Tier != domain.DriverTierPremium {
+			t.Errorf("%s: expected the new ride stored with its origin, got %+v, %v", status, stored, err)
+		}
+	}
+}
+
+func TestRideRebook_Rejected(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		status   domain.RideStatus
+		rideID   string
+		userID   string
+		wantCode int
+	}{
+		{"requested ride", domain.RideStatusRequested, "ride-1", "rider-1", http.StatusConflict},
+		{"ride in trip", domain.RideStatusInTrip, "ride-1", "rider-1", http.StatusConflict},
+		{"another rider's ride", domain.RideStatusCancelled, "ride-1", "rider-2", http.StatusNotFound},
+		{"no caller", domain.RideStatusCancelled, "ride-1", "", http.StatusNotFound},
+		{"unknown ride", domain.RideStatusCancelled, "ride-2", "rider-1", http.StatusNotFound},
+	}
+	for _, tc := range testCases {
+		router, _, matching := newRebookRouter(t, tc.status)
+		if w, _ := postRebook(router, tc.rideID, tc.userID); w.Code != tc.wantCode {
+			t.Errorf("%s: expected %d, got %d: %s", tc.name, tc.wantCode, w.Code, w.Body.String())
+		}
+		if matching.CallCount() != 0 {
+			t.Errorf("%s: expected no new ride matched", tc.name)
+		}
+	}
+}
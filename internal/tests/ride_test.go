@@ -11,7 +11,7 @@ import (
 func TestRideCreation_ValidatesRiderID(t *testing.T) {
 	rideRepo := NewMockRideRepository()
 	mockMatching := NewMockMatchingServiceForTest()
-	rideService := service.NewRideService(rideRepo, mockMatching, nil, nil)
+	rideService := service.NewRideService(service.RideServiceDeps{RideRepo: rideRepo, MatchingService: mockMatching})
 
 	_, err := rideService.CreateRide(context.Background(), service.CreateRideRequest{
 		RiderID:        "", // Empty rider ID.
@@ -29,7 +29,7 @@ func TestRideCreation_ValidatesRiderID(t *testing.T) {
 func TestRideCreation_ValidatesPickupLatitude(t *testing.T) {
 	rideRepo := NewMockRideRepository()
 	mockMatching := NewMockMatchingServiceForTest()
-	rideService := service.NewRideService(rideRepo, mockMatching, nil, nil)
+	rideService := service.NewRideService(service.RideServiceDeps{RideRepo: rideRepo, MatchingService: mockMatching})
 
 	testCases := []struct {
 		name string
@@ -59,7 +59,7 @@ func TestRideCreation_ValidatesPickupLatitude(t *testing.T) {
 func TestRideCreation_ValidatesPickupLongitude(t *testing.T) {
 	rideRepo := NewMockRideRepository()
 	mockMatching := NewMockMatchingServiceForTest()
-	rideService := service.NewRideService(rideRepo, mockMatching, nil, nil)
+	rideService := service.NewRideService(service.RideServiceDeps{RideRepo: rideRepo, MatchingService: mockMatching})
 
 	testCases := []struct {
 		name string
@@ -89,7 +89,7 @@ func TestRideCreation_ValidatesPickupLongitude(t *testing.T) {
 func TestRideCreation_ValidatesDestinationLatitude(t *testing.T) {
 	rideRepo := NewMockRideRepository()
 	mockMatching := NewMockMatchingServiceForTest()
-	rideService := service.NewRideService(rideRepo, mockMatching, nil, nil)
+	rideService := service.NewRideService(service.RideServiceDeps{RideRepo: rideRepo, MatchingService: mockMatching})
 
 	_, err := rideService.CreateRide(context.Background(), service.CreateRideRequest{
 		RiderID:        "rider-1",
@@ -107,7 +107,7 @@ func TestRideCreation_ValidatesDestinationLatitude(t *testing.T) {
 func TestRideCreation_ValidatesDestinationLongitude(t *testing.T) {
 	rideRepo := NewMockRideRepository()
 	mockMatching := NewMockMatchingServiceForTest()
-	rideService := service.NewRideService(rideRepo, mockMatching, nil, nil)
+	rideService := service.NewRideService(service.RideServiceDeps{RideRepo: rideRepo, MatchingService: mockMatching})
 
 	_, err := rideService.CreateRide(context.Background(), service.CreateRideRequest{
 		RiderID:        "rider-1",
@@ -200,7 +200,7 @@ func TestRideCreation_DirectRepo_PersistsAllFields(t *testing.T) {
 func TestGetRideStatus_ReturnsExistingRide(t *testing.T) {
 	rideRepo := NewMockRideRepository()
 	mockMatching := NewMockMatchingServiceForTest()
-	rideService := service.NewRideService(rideRepo, mockMatching, nil, nil)
+	rideService := service.NewRideService(service.RideServiceDeps{RideRepo: rideRepo, MatchingService: mockMatching})
 	ctx := context.Background()
 
 	// Add a ride directly to the repo.
@@ -232,7 +232,7 @@ func TestGetRideStatus_ReturnsExistingRide(t *testing.T) {
 func TestGetRideStatus_ReturnsErrorForEmptyID(t *testing.T) {
 	rideRepo := NewMockRideRepository()
 	mockMatching := NewMockMatchingServiceForTest()
-	rideService := service.NewRideService(rideRepo, mockMatching, nil, nil)
+	rideService := service.NewRideService(service.RideServiceDeps{RideRepo: rideRepo, MatchingService: mockMatching})
 
 	_, err := rideService.GetRideStatus(context.Background(), "")
 
@@ -244,7 +244,7 @@ func TestGetRideStatus_ReturnsErrorForEmptyID(t *testing.T) {
 func TestGetRideStatus_ReturnsNotFoundForNonexistentRide(t *testing.T) {
 	rideRepo := NewMockRideRepository()
 	mockMatching := NewMockMatchingServiceForTest()
-	rideService := service.NewRideService(rideRepo, mockMatching, nil, nil)
+	rideService := service.NewRideService(service.RideServiceDeps{RideRepo: rideRepo, MatchingService: mockMatching})
 
 	_, err := rideService.GetRideStatus(context.Background(), "nonexistent")
 
@@ -0,0 +1,209 @@
+package tests
+
+import (
+	"context"
+	"database/sql/driver"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/middleware"
+	"ride/internal/redis"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// RIDE LIFECYCLE TIMESTAMPS
+// ──────────────────────────────────────────────
+
+// rideUpdateArgs returns the arguments of the recorded UPDATE rides statements.
+func rideUpdateArgs(rec *RecordingDB) [][]driver.Value {
+	var args [][]driver.Value
+	for _, q := range rec.Queries() {
+		if strings.Contains(q.Query, "UPDATE rides") {
+			args = append(args, q.Args)
+		}
+	}
+	return args
+}
+
+// maxPlaceholder returns the highest $N placeholder in a query. Postgres
+// rejects a statement given more arguments than it references.
+func maxPlaceholder(query string) int {
+	highest := 0
+	for _, m := range regexp.MustCompile(`\$(\d+)`).FindAllStringSubmatch(query, -1) {
+		if n, _ := strconv.Atoi(m[1]); n > highest {
+			highest = n
+		}
+	}
+	return highest
+}
+
+func TestRideTimestamps_RequestedAtSetOnCreate(t *testing.T) {
+	t.Parallel()
+
+	rideRepo := NewMockRideRepository()
+	rideService := service.NewRideService(service.RideServiceDeps{RideRepo: rideRepo, MatchingService: NewMockMatchingServiceForTest()})
+
+	before := time.Now()
+	resp, err := rideService.CreateRide(context.Background(), service.CreateRideRequest{
+		RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 12.3, DestinationLng: 76.64,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stored := rideRepo.GetRide(resp.Ride.ID)
+	if stored.RequestedAt.Before(before) || !stored.RequestedAt.Equal(stored.CreatedAt) {
+		t.Errorf("expected requested_at at creation, got %v (created %v)", stored.RequestedAt, stored.CreatedAt)
+	}
+	if !stored.AssignedAt.IsZero() || !stored.CompletedAt.IsZero() {
+		t.Errorf("expected later timestamps unset, got assigned=%v completed=%v", stored.AssignedAt, stored.CompletedAt)
+	}
+}
+
+func TestRideTimestamps_AssignedAtSetOnMatch(t *testing.T) {
+	t.Parallel()
+
+	db, rec := NewRecordingDB()
+	defer db.Close()
+
+	driverRepo := NewMockDriverRepository()
+	driverRepo.AddDriver(&domain.Driver{ID: "driver-1", Status: domain.DriverStatusOnline, Tier: domain.DriverTierBasic})
+	locationStore := NewMockLocationStore()
+	locationStore.SetLocations([]redis.DriverLocation{{DriverID: "driver-1", Lat: 12.97, Lng: 77.59}})
+	rideRepo := NewMockRideRepository()
+	requestedAt := time.Now().Add(-time.Minute)
+	rideRepo.AddRide(&domain.Ride{
+		ID: "ride-1", RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 12.3, DestinationLng: 76.64,
+		Status: domain.RideStatusRequested, RequestedAt: requestedAt, CreatedAt: requestedAt, Version: 1,
+	})
+
+	matcher := service.NewMatchingService(service.MatchingServiceDeps{
+		DB:            db,
+		LocationStore: locationStore,
+		LockStore:     NewMockLockStore(),
+		DriverRepo:    driverRepo,
+		RideRepo:      rideRepo,
+	})
+
+	before := time.Now()
+	result, err := matcher.Match(context.Background(), service.MatchRequest{RideID: "ride-1", Lat: 12.97, Lng: 77.59})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Ride.AssignedAt.Before(before) {
+		t.Errorf("expected assigned_at at match time, got %v", result.Ride.AssignedAt)
+	}
+	if !result.Ride.RequestedAt.Equal(requestedAt) {
+		t.Errorf("requested_at must not change on assignment, got %v", result.Ride.RequestedAt)
+	}
+
+	updates := rideUpdateArgs(rec)
+	if len(updates) != 1 {
+		t.Fatalf("expected one ride update, got %d", len(updates))
+	}
+	// assigned_at is $14, completed_at is $15.
+	if assignedAt, ok := updates[0][13].(time.Time); !ok || !assignedAt.Equal(result.Ride.AssignedAt) {
+		t.Errorf("expected assigned_at persisted, got %v", updates[0][13])
+	}
+	if updates[0][14] != nil {
+		t.Errorf("expected completed_at NULL on assignment, got %v", updates[0][14])
+	}
+	for _, q := range rec.Queries() {
+		if strings.Contains(q.Query, "UPDATE rides") && maxPlaceholder(q.Query) != len(q.Args) {
+			t.Errorf("expected the update to reference all %d arguments, got up to $%d", len(q.Args), maxPlaceholder(q.Query))
+		}
+	}
+}
+
+func TestRideTimestamps_CompletedAtSetOnEndTrip(t *testing.T) {
+	t.Parallel()
+
+	db, rec := NewRecordingDB()
+	defer db.Close()
+
+	startedAt := time.Now().Add(-15 * time.Minute)
+	assignedAt := startedAt.Add(-5 * time.Minute)
+
+	rideRepo := NewMockRideRepository()
+	rideRepo.AddRide(&domain.Ride{
+		ID: "ride-1", RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 12.3, DestinationLng: 76.64,
+		Status: domain.RideStatusInTrip, AssignedDriverID: "driver-1", SurgeMultiplier: 1,
+		RequestedAt: assignedAt.Add(-time.Minute), AssignedAt: assignedAt, Version: 2,
+	})
+	tripRepo := NewMockTripRepository()
+	_ = tripRepo.Create(context.Background(), &domain.Trip{
+		ID: "trip-1", RideID: "ride-1", DriverID: "driver-1", Status: domain.TripStatusStarted, StartedAt: startedAt, Version: 1,
+	})
+	paymentService := service.NewPaymentService(NewMockPaymentRepository(), NewMockPSP(), nil, nil, nil, 0)
+
+	tripService := service.NewTripService(service.TripServiceDeps{
+		DB:             db,
+		TripRepo:       tripRepo,
+		RideRepo:       rideRepo,
+		DriverRepo:     NewMockDriverRepository(),
+		PaymentService: paymentService,
+	})
+	resp, err := tripService.EndTrip(context.Background(), service.EndTripRequest{TripID: "trip-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !resp.Trip.StartedAt.Equal(startedAt) {
+		t.Errorf("trip started_at must mark the start of movement, got %v", resp.Trip.StartedAt)
+	}
+
+	updates := rideUpdateArgs(rec)
+	if len(updates) != 1 {
+		t.Fatalf("expected one ride update, got %d", len(updates))
+	}
+	if got, ok := updates[0][13].(time.Time); !ok || !got.Equal(assignedAt) {
+		t.Errorf("expected assigned_at preserved, got %v", updates[0][13])
+	}
+	if got, ok := updates[0][14].(time.Time); !ok || !got.Equal(resp.Trip.EndedAt) {
+		t.Errorf("expected completed_at = trip end %v, got %v", resp.Trip.EndedAt, updates[0][14])
+	}
+}
+
+func TestRideTimestamps_ExposedInRideResponse(t *testing.T) {
+	t.Parallel()
+
+	rideRepo := NewMockRideRepository()
+	rideRepo.AddRide(&domain.Ride{
+		ID: "ride-1", RiderID: "rider-1", Status: domain.RideStatusCompleted, SurgeMultiplier: 1,
+		PaymentMethod: domain.PaymentMethodCash,
+		RequestedAt:   goldenTime,
+		AssignedAt:    goldenTime.Add(time.Minute),
+		CompletedAt:   goldenTime.Add(30 * time.Minute),
+	})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.IdentityMiddleware(testAdminToken))
+	rideService := service.NewRideService(service.RideServiceDeps{RideRepo: rideRepo, MatchingService: NewMockMatchingServiceForTest()})
+	router.GET("/v1/rides/:id", handler.NewRideHandler(rideService, nil, rideRepo, nil).GetRide)
+
+	assertGoldenJSON(t, "completed ride", serveGolden(t, router, "/v1/rides/ride-1"), `{
+		"id": "ride-1",
+		"rider_id": "rider-1",
+		"pickup_lat": 0,
+		"pickup_lng": 0,
+		"destination_lat": 0,
+		"destination_lng": 0,
+		"status": "COMPLETED",
+		"surge_multiplier": 1,
+		"surge_active": false,
+		"payment_method": "CASH",
+		"requested_at": "2026-03-14T09:30:00Z",
+		"assigned_at": "2026-03-14T09:31:00Z",
+		"completed_at": "2026-03-14T10:00:00Z"
+	}`)
+}
@@ -0,0 +1,264 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	goredis "github.com/redis/go-redis/v9"
+
+	"ride/internal/app"
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/middleware"
+	"ride/internal/service"
+	"ride/pkg/api"
+	"ride/pkg/rideclient"
+)
+
+// ──────────────────────────────────────────────
+// RIDE CLIENT CONTRACT
+// ──────────────────────────────────────────────
+
+// contractMatcher assigns every ride to driver-1 in the mock repository.
+type contractMatcher struct {
+	rides   *MockRideRepository
+	drivers *MockDriverRepository
+}
+
+func (m *contractMatcher) Match(ctx context.Context, req service.MatchRequest) (*service.MatchResult, error) {
+	ride, err := m.rides.GetByID(ctx, req.RideID)
+	if err != nil {
+		return nil, err
+	}
+	ride.Status, ride.AssignedDriverID, ride.AssignedAt = domain.RideStatusAssigned, "driver-1", time.Now()
+	m.rides.AddRide(ride)
+	return &service.MatchResult{DriverID: "driver-1", Ride: ride}, nil
+}
+
+// AssignDriver is not part of the client contract.
+func (m *contractMatcher) AssignDriver(ctx context.Context, rideID, driverID string) (*service.MatchResult, error) {
+	return nil, service.ErrNoDriverAvailable
+}
+
+func (m *contractMatcher) GetDriver(ctx context.Context, driverID string) (*domain.Driver, error) {
+	return m.drivers.GetByID(ctx, driverID)
+}
+
+// newContractServer serves the full router with real handlers and services
+// over env's mock repositories. driver-1 is ONLINE and gets every ride.
+func newContractServer(t *testing.T, env *testEnv) *httptest.Server {
+	t.Helper()
+
+	env.drivers.AddDriver(&domain.Driver{ID: "driver-1", Name: "Asha", Status: domain.DriverStatusOnline, Tier: domain.DriverTierBasic})
+
+	rideService := service.NewRideService(env.rideDeps(&contractMatcher{rides: env.rides, drivers: env.drivers}))
+	deps := env.tripDeps()
+	deps.LocationStore = env.locations
+	tripService := service.NewTripService(deps)
+	driverService := service.NewDriverService(env.locations, nil, env.drivers, nil, nil, tripService, nil, 0, nil)
+	etaService := service.NewETAService(env.rides, env.locations, nil, nil, 0)
+
+	// Redis is unreachable, so Idempotency-Key headers pass straight through.
+	redisClient := goredis.NewClient(&goredis.Options{Addr: "127.0.0.1:0", MaxRetries: -1})
+	t.Cleanup(func() { _ = redisClient.Close() })
+
+	router, err := app.NewRouter(app.RouterDeps{
+		RideHandler:    handler.NewRideHandler(rideService, etaService, env.rides, nil),
+		DriverHandler:  handler.NewDriverHandler(driverService, tripService, env.drivers, "", nil, nil),
+		TripHandler:    handler.NewTripHandler(tripService, nil),
+		PaymentHandler: handler.NewPaymentHandler(deps.PaymentService, tripService),
+		RedisClient:    redisClient,
+		AdminToken:     testAdminToken,
+		GinMode:        gin.TestMode,
+		AccessLog:      middleware.AccessLogConfig{Output: io.Discard},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func clientAs(server *httptest.Server, userID string) *rideclient.Client {
+	return rideclient.New(rideclient.Config{BaseURL: server.URL, UserID: userID})
+}
+
+func TestRideClient_FullRideLifecycle(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	server := newContractServer(t, env)
+	ctx := context.Background()
+	rider, driver := clientAs(server, "rider-1"), clientAs(server, "driver-1")
+
+	created, err := rider.CreateRide(ctx, api.CreateRideRequest{
+		RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 13.0, DestinationLng: 77.62,
+		PaymentMethod: "card",
+	})
+	if err != nil {
+		t.Fatalf("CreateRide: %v", err)
+	}
+	if created.ID == "" || !created.DriverAssigned || created.AssignedDriverID != "driver-1" || created.PaymentMethod != "CARD" {
+		t.Fatalf("expected a CARD ride assigned to driver-1, got %+v", created)
+	}
+	if created.Driver == nil || created.Driver.Name != "Asha" {
+		t.Errorf("expected the assigned driver's details, got %+v", created.Driver)
+	}
+
+	if err := driver.UpdateDriverLocation(ctx, "driver-1", api.UpdateLocationRequest{Lat: 12.9703, Lng: 77.59, Heading: 90}); err != nil {
+		t.Fatalf("UpdateDriverLocation: %v", err)
+	}
+
+	ride, err := rider.GetRide(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("GetRide: %v", err)
+	}
+	if ride.ID != created.ID || ride.Status != "ASSIGNED" || ride.AssignedAt == "" || ride.DriverDistanceKm == nil {
+		t.Errorf("expected the ASSIGNED ride with the driver's distance, got %+v", ride)
+	}
+
+	accepted, err := driver.AcceptRide(ctx, "driver-1", api.AcceptRideRequest{RideID: created.ID})
+	if err != nil {
+		t.Fatalf("AcceptRide: %v", err)
+	}
+	if accepted.TripID == "" || accepted.RideID != created.ID || accepted.DriverID != "driver-1" || accepted.Status != "STARTED" {
+		t.Fatalf("expected a STARTED trip for the ride, got %+v", accepted)
+	}
+
+	// The trip was written in a transaction the mock repository does not see.
+	_ = env.trips.Create(ctx, &domain.Trip{ID: accepted.TripID, RideID: created.ID, DriverID: "driver-1",
+		Status: domain.TripStatusStarted, StartedAt: time.Now().Add(-15 * time.Minute), Version: 1})
+
+	ended, err := driver.EndTrip(ctx, accepted.TripID)
+	if err != nil {
+		t.Fatalf("EndTrip: %v", err)
+	}
+	if ended.Status != "ENDED" || ended.Fare <= 0 || ended.Payment == nil || ended.Payment.Status != "SUCCESS" {
+		t.Fatalf("expected an ENDED trip with a successful payment, got %+v", ended)
+	}
+
+	payment, err := rider.ProcessPayment(ctx, api.ProcessPaymentRequest{TripID: accepted.TripID, Amount: ended.Fare},
+		rideclient.WithIdempotencyKey("pay-"+accepted.TripID))
+	if err != nil {
+		t.Fatalf("ProcessPayment: %v", err)
+	}
+	if payment.ID != ended.Payment.ID || payment.TripID != accepted.TripID || payment.IdempotencyKey == "" {
+		t.Errorf("expected the trip's existing payment returned, got %+v", payment)
+	}
+}
+
+func TestRideClient_ErrorsCarryStatusAndMessage(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	server := newContractServer(t, env)
+	ctx := context.Background()
+
+	_, err := clientAs(server, "rider-1").GetRide(ctx, "no-such-ride")
+	var apiErr *rideclient.Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound || apiErr.Message == "" {
+		t.Errorf("expected a 404 error with a message, got %v", err)
+	}
+
+	_, err = clientAs(server, "rider-1").ProcessPayment(ctx, api.ProcessPaymentRequest{TripID: "trip-1"})
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest || apiErr.Message != "amount must be positive" {
+		t.Errorf("expected a 400 error, got %v", err)
+	}
+}
+
+// newFlakyServer answers 503 to the first failures requests, then 200 with
+// body, recording how many requests arrived and the last Idempotency-Key.
+func newFlakyServer(t *testing.T, failures int32, body string) (*httptest.Server, *atomic.Int32, *atomic.Value) {
+	t.Helper()
+
+	var calls atomic.Int32
+	var key atomic.Value
+	key.Store("")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key.Store(r.Header.Get("Idempotency-Key"))
+		if calls.Add(1) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"error":"service temporarily unavailable"}`))
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls, &key
+}
+
+func TestRideClient_RetriesIdempotentCallsOn5xx(t *testing.T) {
+	t.Parallel()
+
+	server, calls, _ := newFlakyServer(t, 2, `{"id":"ride-1","status":"REQUESTED"}`)
+	client := rideclient.New(rideclient.Config{BaseURL: server.URL, RetryBackoff: time.Millisecond})
+
+	ride, err := client.GetRide(context.Background(), "ride-1")
+	if err != nil || ride.ID != "ride-1" {
+		t.Fatalf("expected the ride after retrying, got %+v, %v", ride, err)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("expected 3 attempts, got %d", got)
+	}
+}
+
+func TestRideClient_NonIdempotentCallsNotRetried(t *testing.T) {
+	t.Parallel()
+
+	server, calls, _ := newFlakyServer(t, 1, `{"trip_id":"trip-1"}`)
+	client := rideclient.New(rideclient.Config{BaseURL: server.URL, RetryBackoff: time.Millisecond})
+
+	_, err := client.EndTrip(context.Background(), "trip-1")
+	var apiErr *rideclient.Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected the 503 returned, got %v", err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("expected 1 attempt, got %d", got)
+	}
+}
+
+func TestRideClient_IdempotencyKeyMakesCallRetryable(t *testing.T) {
+	t.Parallel()
+
+	server, calls, key := newFlakyServer(t, 1, `{"trip_id":"trip-1","status":"ENDED"}`)
+	client := rideclient.New(rideclient.Config{BaseURL: server.URL, RetryBackoff: time.Millisecond})
+
+	trip, err := client.EndTrip(context.Background(), "trip-1", rideclient.WithIdempotencyKey("end-trip-1"))
+	if err != nil || trip.Status != "ENDED" {
+		t.Fatalf("expected the trip after retrying, got %+v, %v", trip, err)
+	}
+	if calls.Load() != 2 || key.Load() != "end-trip-1" {
+		t.Errorf("expected 2 attempts with the key, got %d with %q", calls.Load(), key.Load())
+	}
+}
+
+func TestRideClient_PerCallTimeout(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	t.Cleanup(server.Close)
+	client := rideclient.New(rideclient.Config{BaseURL: server.URL})
+
+	start := time.Now()
+	_, err := client.GetRide(context.Background(), "ride-1", rideclient.WithTimeout(20*time.Millisecond))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("expected the call cut off near 20ms, took %v", elapsed)
+	}
+}
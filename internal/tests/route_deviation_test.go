@@ -0,0 +1,168 @@
+package tests
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"ride/internal/domain"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// TRIP ROUTE DEVIATION ALERTS
+// ──────────────────────────────────────────────
+
+// newDeviationDriverService sets up an in-progress trip heading due north
+// from (12.90, 77.60) to (13.00, 77.60), alerting after 3 pings over 1 km
+// off route.
+func newDeviationDriverService(env *testEnv, alertRepo *MockDeviationAlertRepository) *service.DriverService {
+	env.rides.AddRide(&domain.Ride{
+		ID: "ride-1", RiderID: "rider-1", PickupLat: 12.90, PickupLng: 77.60, DestinationLat: 13.00, DestinationLng: 77.60,
+		Status: domain.RideStatusInTrip, AssignedDriverID: "driver-1",
+	})
+	_ = env.trips.Create(context.Background(), &domain.Trip{
+		ID: "trip-1", RideID: "ride-1", DriverID: "driver-1", Status: domain.TripStatusStarted,
+	})
+	env.drivers.AddDriver(&domain.Driver{ID: "driver-1", Status: domain.DriverStatusOnTrip, Tier: domain.DriverTierBasic})
+
+	deviation := service.NewDeviationService(env.trips, env.rides, alertRepo, NewMockDeviationStore(), env.notificationService(), 1.0, 3)
+	return service.NewDriverService(env.locations, nil, env.drivers, deviation, nil, nil, nil, 0, nil)
+}
+
+func tripAlerts(alertRepo *MockDeviationAlertRepository) []*domain.RouteDeviationAlert {
+	alerts, _ := alertRepo.ListByTrip(context.Background(), "trip-1")
+	return alerts
+}
+
+func deviationNotifications(env *testEnv, recipientID string) int {
+	events, _ := env.notifications.ListSince(context.Background(), recipientID, 0, 100)
+	count := 0
+	for _, e := range events {
+		if e.Type == string(service.NotificationRouteDeviation) {
+			count++
+		}
+	}
+	return count
+}
+
+// About 0.1 km east of the route line.
+var onRouteTrack = [][2]float64{
+	{12.91, 77.600}, {12.92, 77.601}, {12.93, 77.599}, {12.94, 77.601}, {12.95, 77.600},
+}
+
+// About 3.3 km east of the route line.
+var offRouteTrack = [][2]float64{
+	{12.95, 77.63}, {12.96, 77.63}, {12.97, 77.63},
+}
+
+func TestRouteDeviation_OnRouteTrackRaisesNoAlert(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	alertRepo := NewMockDeviationAlertRepository()
+	driverService := newDeviationDriverService(env, alertRepo)
+	drive(t, driverService, onRouteTrack...)
+
+	if got := tripAlerts(alertRepo); len(got) != 0 {
+		t.Errorf("expected no alerts on route, got %d", len(got))
+	}
+}
+
+func TestRouteDeviation_OffRouteTrackAlertsRiderAndOps(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	alertRepo := NewMockDeviationAlertRepository()
+	driverService := newDeviationDriverService(env, alertRepo)
+	drive(t, driverService, onRouteTrack...)
+	drive(t, driverService, offRouteTrack...)
+
+	alerts := tripAlerts(alertRepo)
+	if len(alerts) != 1 {
+		t.Fatalf("expected one alert, got %d", len(alerts))
+	}
+	alert := alerts[0]
+	if alert.RideID != "ride-1" || alert.DriverID != "driver-1" || alert.ConsecutivePings != 3 {
+		t.Errorf("unexpected alert: %+v", alert)
+	}
+	if alert.Lat != 12.97 || alert.DistanceKm < 3 || alert.DistanceKm > 3.5 {
+		t.Errorf("expected alert at the third off-route ping about 3.3 km out, got %+v", alert)
+	}
+
+	if got := deviationNotifications(env, "rider-1"); got != 1 {
+		t.Errorf("expected rider notified once, got %d", got)
+	}
+	if got := deviationNotifications(env, service.OpsRecipientID); got != 1 {
+		t.Errorf("expected ops notified once, got %d", got)
+	}
+}
+
+func TestRouteDeviation_ConsecutivePingDebounce(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	alertRepo := NewMockDeviationAlertRepository()
+	driverService := newDeviationDriverService(env, alertRepo)
+
+	// Two off-route pings, then back on route: the run resets.
+	drive(t, driverService, offRouteTrack[:2]...)
+	drive(t, driverService, onRouteTrack[:1]...)
+	drive(t, driverService, offRouteTrack[:2]...)
+	if got := tripAlerts(alertRepo); len(got) != 0 {
+		t.Fatalf("expected no alert for interrupted runs, got %d", len(got))
+	}
+
+	// The third consecutive off-route ping alerts; staying off route does not alert again.
+	drive(t, driverService, offRouteTrack...)
+	if got := tripAlerts(alertRepo); len(got) != 1 {
+		t.Fatalf("expected one alert for a sustained deviation, got %d", len(got))
+	}
+
+	// Returning to the route and leaving again starts a new run.
+	drive(t, driverService, onRouteTrack[:1]...)
+	drive(t, driverService, offRouteTrack...)
+	if got := tripAlerts(alertRepo); len(got) != 2 {
+		t.Errorf("expected a second alert for a new deviation, got %d", len(got))
+	}
+}
+
+func TestRouteDeviation_NoActiveTripIsIgnored(t *testing.T) {
+	t.Parallel()
+
+	deviation := service.NewDeviationService(NewMockTripRepository(), NewMockRideRepository(),
+		NewMockDeviationAlertRepository(), NewMockDeviationStore(), nil, 1.0, 1)
+
+	alert, err := deviation.CheckLocation(context.Background(), "driver-idle", 12.97, 77.63)
+	if err != nil || alert != nil {
+		t.Errorf("expected no alert without an active trip, got %+v, %v", alert, err)
+	}
+}
+
+func TestDistanceToSegmentKm(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		lat, lng float64
+		want     float64
+	}{
+		{"on the segment", 12.95, 77.60, 0},
+		{"beside the midpoint", 12.95, 77.63, domain.HaversineKm(12.95, 77.60, 12.95, 77.63)},
+		{"beyond the destination", 13.05, 77.60, domain.HaversineKm(13.00, 77.60, 13.05, 77.60)},
+		{"before the pickup", 12.85, 77.60, domain.HaversineKm(12.90, 77.60, 12.85, 77.60)},
+	}
+
+	for _, tt := range tests {
+		got := domain.DistanceToSegmentKm(tt.lat, tt.lng, 12.90, 77.60, 13.00, 77.60)
+		if math.Abs(got-tt.want) > 0.01 {
+			t.Errorf("%s: expected %.3f km, got %.3f km", tt.name, tt.want, got)
+		}
+	}
+
+	// A degenerate segment measures to its single point.
+	if got, want := domain.DistanceToSegmentKm(12.95, 77.63, 12.95, 77.60, 12.95, 77.60),
+		domain.HaversineKm(12.95, 77.60, 12.95, 77.63); math.Abs(got-want) > 0.01 {
+		t.Errorf("degenerate segment: expected %.3f km, got %.3f km", want, got)
+	}
+}
@@ -0,0 +1,178 @@
+package tests
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/app"
+	"ride/internal/middleware"
+)
+
+// ──────────────────────────────────────────────
+// ROUTER: TRUSTED PROXIES, ACCESS LOG AND RECOVERY
+// ──────────────────────────────────────────────
+
+// newLoggedRouter builds the full router with no handlers wired; only /health
+// and unmatched routes are served.
+func newLoggedRouter(t *testing.T, trustedProxies, skipPaths []string, sampleRate int) (*gin.Engine, *bytes.Buffer) {
+	t.Helper()
+
+	var logs bytes.Buffer
+	router, err := app.NewRouter(app.RouterDeps{
+		TrustedProxies: trustedProxies,
+		AccessLog:      middleware.AccessLogConfig{SkipPaths: skipPaths, SampleRate: sampleRate, Output: &logs},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return router, &logs
+}
+
+// getFrom issues a GET from 192.0.2.1 (httptest's default remote address) with an
+// optional X-Forwarded-For header.
+func getFrom(router *gin.Engine, path, forwardedFor string) int {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestRouter_ForwardedForIgnoredFromUntrustedPeer(t *testing.T) {
+	t.Parallel()
+
+	router, logs := newLoggedRouter(t, nil, nil, 1)
+
+	if code := getFrom(router, "/health", "203.0.113.7"); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if !strings.Contains(logs.String(), "192.0.2.1") || strings.Contains(logs.String(), "203.0.113.7") {
+		t.Errorf("expected the peer address logged, got %q", logs.String())
+	}
+}
+
+func TestRouter_ForwardedForHonoredFromTrustedProxy(t *testing.T) {
+	t.Parallel()
+
+	router, logs := newLoggedRouter(t, []string{"192.0.2.0/24"}, nil, 1)
+
+	if code := getFrom(router, "/health", "203.0.113.7"); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if !strings.Contains(logs.String(), "203.0.113.7") {
+		t.Errorf("expected the forwarded client address logged, got %q", logs.String())
+	}
+}
+
+func TestRouter_InvalidTrustedProxyRejected(t *testing.T) {
+	t.Parallel()
+
+	if _, err := app.NewRouter(app.RouterDeps{TrustedProxies: []string{"not-a-cidr"}}); err == nil {
+		t.Error("expected an error for an invalid trusted proxy")
+	}
+	if _, err := app.NewRouter(app.RouterDeps{GinMode: "verbose"}); err == nil {
+		t.Error("expected an error for an invalid gin mode")
+	}
+}
+
+func TestAccessLog_SkipsConfiguredPaths(t *testing.T) {
+	t.Parallel()
+
+	router, logs := newLoggedRouter(t, nil, []string{"/health", "/metrics"}, 1)
+
+	getFrom(router, "/health", "")
+	getFrom(router, "/metrics", "")
+	if logs.Len() != 0 {
+		t.Errorf("expected skipped paths not logged, got %q", logs.String())
+	}
+
+	getFrom(router, "/v1/unknown", "")
+	if !strings.Contains(logs.String(), `"/v1/unknown"`) {
+		t.Errorf("expected other paths logged, got %q", logs.String())
+	}
+}
+
+func TestAccessLog_SamplesSuccessesButLogsErrors(t *testing.T) {
+	t.Parallel()
+
+	router, logs := newLoggedRouter(t, nil, nil, 3)
+
+	for i := 0; i < 6; i++ {
+		getFrom(router, "/health", "")
+	}
+	if got := strings.Count(logs.String(), `"/health"`); got != 2 {
+		t.Errorf("expected 2 of 6 successes logged at 1 in 3, got %d", got)
+	}
+
+	for i := 0; i < 3; i++ {
+		getFrom(router, "/v1/unknown", "")
+	}
+	if got := strings.Count(logs.String(), `"/v1/unknown"`); got != 3 {
+		t.Errorf("expected every error logged, got %d", got)
+	}
+}
+
+func TestRouter_PanicReturnsJSONWithRequestID(t *testing.T) {
+	t.Parallel()
+
+	router, logs := newLoggedRouter(t, nil, nil, 1)
+	router.GET("/v1/panic", func(c *gin.Context) {
+		panic("db password is hunter2")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/panic", nil)
+	req.Header.Set(middleware.RequestIDHeader, "req-42")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError || !strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+		t.Fatalf("expected a JSON 500, got %d %q: %s", w.Code, w.Header().Get("Content-Type"), w.Body.String())
+	}
+	if body := w.Body.String(); body != `{"error":"internal server error","request_id":"req-42"}` {
+		t.Errorf("expected a sanitized envelope, got %s", body)
+	}
+	if w.Header().Get(middleware.RequestIDHeader) != "req-42" {
+		t.Errorf("expected the request ID echoed, got %q", w.Header().Get(middleware.RequestIDHeader))
+	}
+
+	// The panic and its stack are logged, and so is the 500.
+	out := logs.String()
+	for _, want := range []string{"[PANIC]", "req-42", "hunter2", "| 500 |"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in the logs, got %q", want, out)
+		}
+	}
+}
+
+func TestRouter_RequestIDGeneratedWhenMissingOrUnsafe(t *testing.T) {
+	t.Parallel()
+
+	router, logs := newLoggedRouter(t, nil, nil, 1)
+
+	for _, sent := range []string{"", "bad id\nINJECTED", strings.Repeat("a", 65)} {
+		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		if sent != "" {
+			req.Header.Set(middleware.RequestIDHeader, sent)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		id := w.Header().Get(middleware.RequestIDHeader)
+		if id == "" || id == sent {
+			t.Errorf("expected a generated request ID for %q, got %q", sent, id)
+		}
+		if !strings.Contains(logs.String(), id) {
+			t.Errorf("expected the request ID %q in the access log, got %q", id, logs.String())
+		}
+	}
+	if strings.Contains(logs.String(), "INJECTED") {
+		t.Errorf("expected an unsafe request ID never logged, got %q", logs.String())
+	}
+}
@@ -0,0 +1,157 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"ride/internal/app"
+	"ride/internal/repository/postgres"
+)
+
+// ──────────────────────────────────────────────
+// STARTUP SCHEMA CHECK
+// ──────────────────────────────────────────────
+
+var (
+	createTablePattern = regexp.MustCompile(`(?s)CREATE TABLE IF NOT EXISTS (\w+) \((.*?)\n\);`)
+	addColumnPattern   = regexp.MustCompile(`ALTER TABLE (\w+) ADD COLUMN IF NOT EXISTS (\w+) (\w+)`)
+	columnLinePattern  = regexp.MustCompile(`^\s*(\w+) (\w+)`)
+)
+
+// sqlDataTypes maps the column types scripts/schema.sql uses to the
+// data_type information_schema reports for them.
+var sqlDataTypes = map[string]string{
+	"VARCHAR":   "character varying",
+	"TEXT":      "text",
+	"INTEGER":   "integer",
+	"BIGINT":    "bigint",
+	"BIGSERIAL": "bigint",
+	"DOUBLE":    "double precision",
+	"BOOLEAN":   "boolean",
+	"TIMESTAMP": "timestamp without time zone",
+	"JSONB":     "jsonb",
+}
+
+// schemaColumns returns information_schema rows for the tables and columns
+// scripts/schema.sql creates, as a database it was applied to would.
+func schemaColumns(t *testing.T) [][]driver.Value {
+	t.Helper()
+	script, err := os.ReadFile("../../scripts/schema.sql")
+	if err != nil {
+		t.Fatalf("failed to read schema.sql: %v", err)
+	}
+
+	var rows [][]driver.Value
+	add := func(table, column, sqlType string) {
+		dataType, ok := sqlDataTypes[sqlType]
+		if !ok {
+			t.Fatalf("schema.sql: unmapped type %s for %s.%s", sqlType, table, column)
+		}
+		rows = append(rows, []driver.Value{table, column, dataType})
+	}
+	for _, m := range createTablePattern.FindAllStringSubmatch(string(script), -1) {
+		for _, line := range strings.Split(m[2], "\n") {
+			col := columnLinePattern.FindStringSubmatch(line)
+			if col == nil || col[1] == "CONSTRAINT" || col[1] == "PRIMARY" {
+				continue
+			}
+			add(m[1], col[1], col[2])
+		}
+	}
+	for _, m := range addColumnPattern.FindAllStringSubmatch(string(script), -1) {
+		add(m[1], m[2], m[3])
+	}
+	return rows
+}
+
+// schemaCheckDB answers the schema check's query with rows.
+func schemaCheckDB(rows [][]driver.Value) *sql.DB {
+	db, rec := NewRecordingDB()
+	rec.Respond = func(query string, _ []driver.Value) ([]string, [][]driver.Value) {
+		if !strings.Contains(query, "information_schema.columns") {
+			return nil, nil
+		}
+		return []string{"table_name", "column_name", "data_type"}, append([][]driver.Value(nil), rows...)
+	}
+	return db
+}
+
+func TestSchemaCheck_PassesForSchemaScript(t *testing.T) {
+	t.Parallel()
+
+	db := schemaCheckDB(schemaColumns(t))
+	if err := app.SchemaCheck(context.Background(), db, postgres.ExpectedSchema()); err != nil {
+		t.Fatalf("expected scripts/schema.sql to satisfy the repositories, got %v", err)
+	}
+}
+
+func TestSchemaCheck_CatchesDriftWithPreciseMessage(t *testing.T) {
+	t.Parallel()
+
+	var dropped, retyped [][]driver.Value
+	for _, row := range schemaColumns(t) {
+		table, column := row[0].(string), row[1].(string)
+		if table == "payments" && column == "fee" {
+			retyped = append(retyped, []driver.Value{table, column, "text"})
+			continue
+		}
+		retyped = append(retyped, row)
+		if table != "match_attempts" {
+			dropped = append(dropped, row)
+		}
+	}
+	err := app.SchemaCheck(context.Background(), schemaCheckDB(dropped), postgres.ExpectedSchema())
+	if err == nil {
+		t.Fatal("expected a dropped column and table to fail the check")
+	}
+	for _, want := range []string{"missing column payments.fee (float)", "missing table match_attempts"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %q", want, err.Error())
+		}
+	}
+
+	err = app.SchemaCheck(context.Background(), schemaCheckDB(retyped), postgres.ExpectedSchema())
+	if err == nil || !strings.Contains(err.Error(), "column payments.fee is text, expected float") {
+		t.Errorf("expected the incompatible fee column reported, got %v", err)
+	}
+}
+
+// TestSchemaCheck_LivePostgres drops a column inside a transaction against
+// the Postgres at TEST_DATABASE_URL, with scripts/schema.sql applied, and
+// rolls it back afterwards.
+func TestSchemaCheck_LivePostgres(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+
+	if err := app.SchemaCheck(ctx, db, postgres.ExpectedSchema()); err != nil {
+		t.Fatalf("expected the applied schema to pass, got %v", err)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	if _, err := tx.ExecContext(ctx, `ALTER TABLE trips DROP COLUMN pause_reason`); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err = app.SchemaCheck(ctx, tx, postgres.ExpectedSchema())
+	if err == nil || !strings.Contains(err.Error(), "missing column trips.pause_reason (text)") {
+		t.Errorf("expected the dropped column reported, got %v", err)
+	}
+}
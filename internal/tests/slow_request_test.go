@@ -0,0 +1,66 @@
+package tests
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/middleware"
+)
+
+// ──────────────────────────────────────────────
+// SLOW REQUEST LOG
+// ──────────────────────────────────────────────
+
+func newSlowRequestRouter(threshold time.Duration, out *bytes.Buffer) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.SlowRequestMiddleware(threshold, out))
+	router.GET("/v1/rides/:id", func(c *gin.Context) {
+		if c.Query("slow") != "" {
+			time.Sleep(50 * time.Millisecond)
+		}
+		c.Status(http.StatusAccepted)
+	})
+	return router
+}
+
+func TestSlowRequest_LogsRouteAndStatus(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	router := newSlowRequestRouter(10*time.Millisecond, &out)
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/rides/ride-1", nil))
+	if out.Len() != 0 {
+		t.Fatalf("expected no log for a fast request, got %q", out.String())
+	}
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/rides/ride-1?slow=1", nil))
+	line := out.String()
+	for _, want := range []string{"[SLOW]", "| 202 |", "GET     /v1/rides/:id", "over 10ms"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("expected %q in slow request log %q", want, line)
+		}
+	}
+	if strings.Contains(line, "ride-1") {
+		t.Errorf("expected the route pattern rather than the path, got %q", line)
+	}
+}
+
+func TestSlowRequest_ZeroThresholdDisables(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	router := newSlowRequestRouter(0, &out)
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/rides/ride-1?slow=1", nil))
+	if out.Len() != 0 {
+		t.Errorf("expected no log with the threshold disabled, got %q", out.String())
+	}
+}
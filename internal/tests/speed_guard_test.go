@@ -0,0 +1,282 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/middleware"
+	"ride/internal/redis"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// LOCATION SPEED GUARD
+// ──────────────────────────────────────────────
+
+// kmPerDegreeLat converts a northward move in degrees to kilometres.
+var kmPerDegreeLat = domain.HaversineKm(12.0, 77.59, 13.0, 77.59)
+
+// speedGuardStart is when speed guard tests start their clocks.
+var speedGuardStart = time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+
+// newGuardedDriverService takes location updates from ONLINE driver-1
+// through guard. Its cache store talks to a recording client, so tests can
+// see the available-set writes.
+func newGuardedDriverService(t *testing.T, env *testEnv, guard *service.LocationGuardService) (*service.DriverService, *keyRecorder) {
+	t.Helper()
+
+	client, rec := newKeyRecordingClient(t)
+	env.drivers.AddDriver(&domain.Driver{ID: "driver-1", Status: domain.DriverStatusOnline, Tier: domain.DriverTierBasic})
+	return service.NewDriverService(env.locations, redis.NewCacheStore(client, "ride:"), env.drivers, nil, nil, nil, nil, 0, guard), rec
+}
+
+func updateLat(driverService *service.DriverService, lat float64) error {
+	return driverService.UpdateLocation(context.Background(), service.UpdateLocationRequest{DriverID: "driver-1", Lat: lat, Lng: 77.59})
+}
+
+func TestSpeedGuard_SpeedMath(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		northKm    float64
+		elapsed    time.Duration
+		wantReject bool
+	}{
+		{"city driving", 1, time.Minute, false},                          // 60 km/h
+		{"motorway", 3, time.Minute, false},                              // 180 km/h
+		{"just under the limit", 10, 3*time.Minute + time.Second, false}, // ~199 km/h
+		{"just over the limit", 10, 3*time.Minute - time.Second, true},   // ~201 km/h
+		{"teleport", 40, time.Minute, true},                              // 2400 km/h
+		{"instant jump", 2, 0, true},
+		{"GPS jitter", 0.3, time.Second, false}, // 1080 km/h, but too short a move to measure
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			env := newTestEnv(t)
+			clock := &fakeClock{t: speedGuardStart}
+			store := NewMockLocationGuardStore()
+			guard := service.NewLocationGuardService(store, 200, 5, clock.Now)
+			driverService, _ := newGuardedDriverService(t, env, guard)
+			if err := updateLat(driverService, 12.9); err != nil {
+				t.Fatalf("first update: unexpected error: %v", err)
+			}
+			clock.Advance(tc.elapsed)
+
+			err := updateLat(driverService, 12.9+tc.northKm/kmPerDegreeLat)
+			if tc.wantReject && !errors.Is(err, service.ErrImplausibleSpeed) {
+				t.Errorf("expected ErrImplausibleSpeed, got %v", err)
+			}
+			if !tc.wantReject && err != nil {
+				t.Errorf("expected the update accepted, got %v", err)
+			}
+		})
+	}
+
+	if got := domain.SpeedKmh(50, 15*time.Minute); math.Abs(got-200) > 1e-9 {
+		t.Errorf("expected 50 km in 15m to be 200 km/h, got %f", got)
+	}
+}
+
+func TestSpeedGuard_RejectedUpdateKeepsPositionAndStatus(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	clock := &fakeClock{t: speedGuardStart}
+	store := NewMockLocationGuardStore()
+	guard := service.NewLocationGuardService(store, 200, 5, clock.Now)
+	driverService, _ := newGuardedDriverService(t, env, guard)
+	if err := updateLat(driverService, 12.9); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 50 km north ten seconds later.
+	far := 12.9 + 50/kmPerDegreeLat
+	clock.Advance(10 * time.Second)
+	if err := updateLat(driverService, far); !errors.Is(err, service.ErrImplausibleSpeed) {
+		t.Fatalf("expected ErrImplausibleSpeed, got %v", err)
+	}
+
+	loc, _ := env.locations.GetLocation(context.Background(), "driver-1")
+	if loc == nil || loc.Lat != 12.9 {
+		t.Errorf("expected the driver left at their last position, got %+v", loc)
+	}
+	if driver, _ := env.drivers.GetByID(context.Background(), "driver-1"); driver.Status != domain.DriverStatusOnline {
+		t.Errorf("expected the driver to stay ONLINE, got %s", driver.Status)
+	}
+	if count, _ := store.SpeedAnomalies(context.Background(), "driver-1"); count != 1 {
+		t.Errorf("expected one anomaly counted, got %d", count)
+	}
+
+	// Measured from the last accepted fix, the same point is reachable
+	// once enough time has passed.
+	clock.Advance(30 * time.Minute)
+	if err := updateLat(driverService, far); err != nil {
+		t.Errorf("expected the update accepted half an hour later, got %v", err)
+	}
+}
+
+func TestSpeedGuard_SmallStepsStillAddUp(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	clock := &fakeClock{t: speedGuardStart}
+	store := NewMockLocationGuardStore()
+	guard := service.NewLocationGuardService(store, 200, 5, clock.Now)
+	driverService, _ := newGuardedDriverService(t, env, guard)
+	if err := updateLat(driverService, 12.9); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 0.4 km a second: each step is under the jitter threshold, but the
+	// second one puts the driver 0.8 km from the last measured fix.
+	lat := 12.9
+	var err error
+	for i := 0; i < 2 && err == nil; i++ {
+		clock.Advance(time.Second)
+		lat += 0.4 / kmPerDegreeLat
+		err = updateLat(driverService, lat)
+	}
+	if !errors.Is(err, service.ErrImplausibleSpeed) {
+		t.Errorf("expected creeping at 1440 km/h to be rejected, got %v", err)
+	}
+}
+
+func TestSpeedGuard_RepeatedAnomaliesHoldDriverForReview(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	clock := &fakeClock{t: speedGuardStart}
+	store := NewMockLocationGuardStore()
+	guard := service.NewLocationGuardService(store, 200, 2, clock.Now)
+	driverService, rec := newGuardedDriverService(t, env, guard)
+	if err := updateLat(driverService, 12.9); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.Join(rec.Commands(), ", "); !strings.Contains(got, "sadd ride:drivers:available") {
+		t.Fatalf("expected the driver made available, got %s", got)
+	}
+
+	far := 12.9 + 50/kmPerDegreeLat
+	clock.Advance(10 * time.Second)
+	_ = updateLat(driverService, far)
+	if got := strings.Join(rec.Commands(), ", "); strings.Contains(got, "srem ride:drivers:available") {
+		t.Errorf("expected one anomaly not to hold the driver, got %s", got)
+	}
+
+	clock.Advance(10 * time.Second)
+	_ = updateLat(driverService, far)
+	if got := strings.Join(rec.Commands(), ", "); !strings.Contains(got, "srem ride:drivers:available") {
+		t.Errorf("expected the second anomaly to remove the driver from the available set, got %s", got)
+	}
+
+	// A plausible update while held keeps them out.
+	clock.Advance(10 * time.Second)
+	if err := updateLat(driverService, 12.9); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.Join(rec.Commands(), ", "); strings.Contains(got, "sadd ride:drivers:available") || !strings.Contains(got, "srem ride:drivers:available") {
+		t.Errorf("expected a held driver kept out of the available set, got %s", got)
+	}
+
+	if _, err := guard.ClearReview(context.Background(), "driver-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	clock.Advance(10 * time.Second)
+	if err := updateLat(driverService, 12.9); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.Join(rec.Commands(), ", "); !strings.Contains(got, "sadd ride:drivers:available") {
+		t.Errorf("expected a cleared driver made available again, got %s", got)
+	}
+}
+
+func TestSpeedGuard_AdminReportAndClear(t *testing.T) {
+	t.Parallel()
+
+	store := NewMockLocationGuardStore()
+	guard := service.NewLocationGuardService(store, 200, 2, nil)
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		_, _ = store.IncrementSpeedAnomalies(ctx, "driver-1")
+	}
+	_, _ = store.IncrementSpeedAnomalies(ctx, "driver-2")
+
+	router := newTestRouter()
+	admin := router.Group("/v1/admin", middleware.AdminAuthMiddleware(testAdminToken))
+	h := handler.NewSpeedGuardHandler(guard)
+	admin.GET("/reports/speed-anomalies", h.GetAnomalies)
+	admin.POST("/drivers/:id/clear-speed-review", h.ClearReview)
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+testAdminToken)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve(http.MethodGet, "/v1/admin/reports/speed-anomalies")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var report []handler.SpeedAnomalyResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &report)
+	want := []handler.SpeedAnomalyResponse{
+		{DriverID: "driver-1", Rejected: 2, HeldForReview: true},
+		{DriverID: "driver-2", Rejected: 1, HeldForReview: false},
+	}
+	if len(report) != len(want) || report[0] != want[0] || report[1] != want[1] {
+		t.Errorf("expected %+v, got %+v", want, report)
+	}
+
+	w = serve(http.MethodPost, "/v1/admin/drivers/driver-1/clear-speed-review")
+	var cleared handler.ClearSpeedReviewResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &cleared)
+	if w.Code != http.StatusOK || !cleared.HadAnomalies {
+		t.Errorf("expected 200 clearing a driver with anomalies, got %d: %s", w.Code, w.Body.String())
+	}
+	if count, _ := store.SpeedAnomalies(ctx, "driver-1"); count != 0 {
+		t.Errorf("expected the driver's anomalies cleared, got %d", count)
+	}
+}
+
+func TestSpeedGuard_HTTPRejectionIs422(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	clock := &fakeClock{t: speedGuardStart}
+	store := NewMockLocationGuardStore()
+	guard := service.NewLocationGuardService(store, 200, 5, clock.Now)
+	driverService, _ := newGuardedDriverService(t, env, guard)
+	if err := updateLat(driverService, 12.9); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	clock.Advance(10 * time.Second)
+
+	router := newTestRouter()
+	router.Use(middleware.IdentityMiddleware(""))
+	router.POST("/v1/drivers/:id/location", handler.NewDriverHandler(driverService, nil, env.drivers, "", nil, nil).UpdateLocation)
+
+	body := `{"lat": 13.4, "lng": 77.59}`
+	req := httptest.NewRequest(http.MethodPost, "/v1/drivers/driver-1/location", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-ID", "driver-1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,162 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sync"
+)
+
+// ──────────────────────────────────────────────
+// RECORDING SQL DRIVER
+// ──────────────────────────────────────────────
+
+// RecordedQuery is a statement executed against a RecordingDB.
+type RecordedQuery struct {
+	Query string
+	Args  []driver.Value
+}
+
+// RecordingDB is a database/sql backend that records every statement and
+// answers queries with canned rows. It lets tests exercise the postgres
+// repositories' SQL construction without a live database.
+type RecordingDB struct {
+	mu       sync.Mutex
+	queries  []RecordedQuery
+	prepared []string
+
+	// Respond returns the columns and rows for a query. Nil means no rows.
+	Respond func(query string, args []driver.Value) ([]string, [][]driver.Value)
+
+	// RowsAffected returns the affected row count for an Exec. Nil means 1.
+	RowsAffected func(query string) int64
+
+	// ExecError returns the error an Exec fails with. Nil means none.
+	ExecError func(query string) error
+}
+
+// NewRecordingDB returns a *sql.DB backed by a new RecordingDB.
+func NewRecordingDB() (*sql.DB, *RecordingDB) {
+	rec := &RecordingDB{}
+	return sql.OpenDB(rec), rec
+}
+
+// Queries returns the statements recorded so far.
+func (r *RecordingDB) Queries() []RecordedQuery {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]RecordedQuery(nil), r.queries...)
+}
+
+// Prepared returns the statements prepared so far, once per preparation.
+func (r *RecordingDB) Prepared() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.prepared...)
+}
+
+func (r *RecordingDB) record(query string, args []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for i, a := range args {
+		values[i] = a.Value
+	}
+	r.mu.Lock()
+	r.queries = append(r.queries, RecordedQuery{Query: query, Args: values})
+	r.mu.Unlock()
+	return values
+}
+
+// Connect implements driver.Connector.
+func (r *RecordingDB) Connect(context.Context) (driver.Conn, error) {
+	return &recordingConn{db: r}, nil
+}
+
+// Driver implements driver.Connector.
+func (r *RecordingDB) Driver() driver.Driver { return recordingDriver{db: r} }
+
+type recordingDriver struct{ db *RecordingDB }
+
+func (d recordingDriver) Open(string) (driver.Conn, error) { return &recordingConn{db: d.db}, nil }
+
+type recordingConn struct{ db *RecordingDB }
+
+func (c *recordingConn) Close() error              { return nil }
+func (c *recordingConn) Begin() (driver.Tx, error) { return recordingTx{}, nil }
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	c.db.mu.Lock()
+	c.db.prepared = append(c.db.prepared, query)
+	c.db.mu.Unlock()
+	return &recordingStmt{conn: c, query: query}, nil
+}
+
+func (c *recordingConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.db.record(query, args)
+	if c.db.ExecError != nil {
+		if err := c.db.ExecError(query); err != nil {
+			return nil, err
+		}
+	}
+	if c.db.RowsAffected != nil {
+		return driver.RowsAffected(c.db.RowsAffected(query)), nil
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c *recordingConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	values := c.db.record(query, args)
+	rows := &recordingRows{}
+	if c.db.Respond != nil {
+		rows.columns, rows.data = c.db.Respond(query, values)
+	}
+	return rows, nil
+}
+
+// recordingStmt runs a prepared statement through its connection, so
+// executions are recorded and answered like direct queries.
+type recordingStmt struct {
+	conn  *recordingConn
+	query string
+}
+
+func (s *recordingStmt) Close() error  { return nil }
+func (s *recordingStmt) NumInput() int { return -1 }
+
+func (s *recordingStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, driver.ErrSkip
+}
+
+func (s *recordingStmt) Query([]driver.Value) (driver.Rows, error) {
+	return nil, driver.ErrSkip
+}
+
+func (s *recordingStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return s.conn.ExecContext(ctx, s.query, args)
+}
+
+func (s *recordingStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.conn.QueryContext(ctx, s.query, args)
+}
+
+type recordingTx struct{}
+
+func (recordingTx) Commit() error   { return nil }
+func (recordingTx) Rollback() error { return nil }
+
+type recordingRows struct {
+	columns []string
+	data    [][]driver.Value
+}
+
+func (r *recordingRows) Columns() []string { return r.columns }
+func (r *recordingRows) Close() error      { return nil }
+
+func (r *recordingRows) Next(dest []driver.Value) error {
+	if len(r.data) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.data[0])
+	r.data = r.data[1:]
+	return nil
+}
@@ -0,0 +1,154 @@
+package tests
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+
+	"ride/internal/app"
+	"ride/internal/config"
+	"ride/internal/domain"
+	"ride/internal/repository/postgres"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// POSTGRES STATEMENT TIMEOUT
+// ──────────────────────────────────────────────
+
+func TestStatementTimeout_SetOnConnections(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.DatabaseConfig{Host: "db", Port: "5432", User: "u", Password: "p", DBName: "d", SSLMode: "disable"}
+
+	cases := []struct {
+		timeout time.Duration
+		want    string // Expected parameter; empty means none
+	}{
+		{0, ""},
+		{1500 * time.Millisecond, " statement_timeout=1500"},
+		{30 * time.Second, " statement_timeout=30000"},
+		{500 * time.Microsecond, " statement_timeout=1"}, // Never rounded down to 0, which disables it
+	}
+	for _, tc := range cases {
+		cfg.StatementTimeout = tc.timeout
+		dsn := app.DatabaseDSN(cfg)
+		if tc.want == "" {
+			if strings.Contains(dsn, "statement_timeout") {
+				t.Errorf("%s: expected no statement timeout, got %q", tc.timeout, dsn)
+			}
+			continue
+		}
+		if !strings.HasSuffix(dsn, tc.want) {
+			t.Errorf("%s: expected %q in %q", tc.timeout, tc.want, dsn)
+		}
+	}
+}
+
+func TestStatementTimeout_OnByDefault(t *testing.T) {
+	cfg := config.Load()
+	if cfg.Database.StatementTimeout != 30*time.Second {
+		t.Errorf("expected a 30s statement timeout by default, got %s", cfg.Database.StatementTimeout)
+	}
+	if dsn := app.DatabaseDSN(cfg.Database); !strings.HasSuffix(dsn, " statement_timeout=30000") {
+		t.Errorf("expected the statement timeout on default connections, got %q", dsn)
+	}
+}
+
+// The streaming CSV exports can outlast the timeout, so they lift it for
+// their own transaction before reading.
+func TestStatementTimeout_LiftedForExports(t *testing.T) {
+	t.Parallel()
+
+	db, rec := NewRecordingDB()
+	defer db.Close()
+	rec.Respond = func(query string, _ []driver.Value) ([]string, [][]driver.Value) {
+		if strings.Contains(query, "COUNT(*)") {
+			return []string{"count"}, [][]driver.Value{{int64(0)}}
+		}
+		return nil, nil
+	}
+
+	exports := service.NewExportService(db, postgres.NewTripRepository(db), postgres.NewRideRepository(db), 0)
+	day := time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC)
+	if err := exports.ExportTrips(context.Background(), day, day, func(*domain.Trip) error { return nil }); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := exports.ExportRides(context.Background(), day, day, func(*domain.Ride) error { return nil }); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var statements []string
+	for _, q := range rec.Queries() {
+		statements = append(statements, strings.Fields(q.Query)[0]+" "+strings.Fields(q.Query)[1])
+	}
+	want := []string{"SET LOCAL", "SELECT COUNT(*)", "SELECT id,", "SET LOCAL", "SELECT COUNT(*)", "SELECT id,"}
+	if strings.Join(statements, "|") != strings.Join(want, "|") {
+		t.Errorf("expected each export to lift the timeout before reading, got %v", statements)
+	}
+}
+
+// TestStatementTimeout_LivePostgres connects to the Postgres at
+// TEST_DATABASE_URL, given as a postgres:// URL, and checks a deliberately
+// slow query is cancelled by the server at the configured timeout.
+func TestStatementTimeout_LivePostgres(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	u, err := url.Parse(dsn)
+	if err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") {
+		t.Skip("TEST_DATABASE_URL is not a postgres:// URL")
+	}
+
+	cfg := config.DatabaseConfig{
+		Host:             u.Hostname(),
+		Port:             u.Port(),
+		User:             u.User.Username(),
+		DBName:           strings.TrimPrefix(u.Path, "/"),
+		SSLMode:          u.Query().Get("sslmode"),
+		StatementTimeout: 200 * time.Millisecond,
+	}
+	cfg.Password, _ = u.User.Password()
+	if cfg.Port == "" {
+		cfg.Port = "5432"
+	}
+	if cfg.SSLMode == "" {
+		cfg.SSLMode = "require"
+	}
+
+	ctx := context.Background()
+	db, err := app.NewDatabase(ctx, cfg, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer db.Close()
+
+	start := time.Now()
+	_, err = db.ExecContext(ctx, `SELECT pg_sleep(5)`)
+	elapsed := time.Since(start)
+
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || pqErr.Code != "57014" {
+		t.Fatalf("expected the query cancelled by the statement timeout, got %v", err)
+	}
+	if elapsed > 2*time.Second {
+		t.Errorf("expected the query cancelled after about 200ms, took %s", elapsed)
+	}
+
+	// The connection went back to the pool usable.
+	var timeout string
+	if err := db.QueryRowContext(ctx, `SHOW statement_timeout`).Scan(&timeout); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if timeout != "200ms" {
+		t.Errorf("expected statement_timeout 200ms, got %q", timeout)
+	}
+}
@@ -0,0 +1,133 @@
+package tests
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// ZONE SURCHARGES
+// ──────────────────────────────────────────────
+
+// Bounding box around the airport, north of the city centre at (12.97, 77.59).
+var airportZone = domain.SurchargeZone{
+	Label: "Airport fee", Amount: 7.5, MinLat: 13.18, MinLng: 77.68, MaxLat: 13.22, MaxLng: 77.72,
+}
+
+func createSurchargedRide(t *testing.T, zones []domain.SurchargeZone, destLat, destLng float64) *domain.Ride {
+	t.Helper()
+
+	rideRepo := NewMockRideRepository()
+	rideService := service.NewRideService(service.RideServiceDeps{
+		RideRepo:         rideRepo,
+		MatchingService:  NewMockMatchingServiceForTest(),
+		SurchargeService: service.NewSurchargeService(zones),
+	})
+
+	resp, err := rideService.CreateRide(context.Background(), service.CreateRideRequest{
+		RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: destLat, DestinationLng: destLng,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return rideRepo.GetRide(resp.Ride.ID)
+}
+
+func TestSurcharge_RideIntoAirportZone(t *testing.T) {
+	t.Parallel()
+
+	ride := createSurchargedRide(t, []domain.SurchargeZone{airportZone}, 13.20, 77.70)
+
+	if ride.SurchargeLabel != "Airport fee" || ride.SurchargeAmount != 7.5 {
+		t.Errorf("expected the airport fee on the ride, got %q %.2f", ride.SurchargeLabel, ride.SurchargeAmount)
+	}
+}
+
+func TestSurcharge_RideOutsideZones(t *testing.T) {
+	t.Parallel()
+
+	ride := createSurchargedRide(t, []domain.SurchargeZone{airportZone}, 12.30, 76.64)
+
+	if ride.SurchargeLabel != "" || ride.SurchargeAmount != 0 {
+		t.Errorf("expected no surcharge, got %q %.2f", ride.SurchargeLabel, ride.SurchargeAmount)
+	}
+}
+
+func TestSurcharge_EachZoneAppliesOnce(t *testing.T) {
+	t.Parallel()
+
+	toll := domain.SurchargeZone{Label: "Expressway toll", Amount: 2, MinLat: 12.96, MinLng: 77.58, MaxLat: 12.98, MaxLng: 77.60}
+	free := domain.SurchargeZone{Label: "Promo zone", Amount: 0, MinLat: 12.96, MinLng: 77.58, MaxLat: 12.98, MaxLng: 77.60}
+	surcharges := service.NewSurchargeService([]domain.SurchargeZone{airportZone, toll, free})
+
+	// Pickup and destination both inside the airport zone: charged once.
+	ride := &domain.Ride{PickupLat: 13.19, PickupLng: 77.69, DestinationLat: 13.21, DestinationLng: 77.71}
+	surcharges.Apply(ride)
+	if ride.SurchargeAmount != 7.5 {
+		t.Errorf("expected a single airport fee, got %.2f", ride.SurchargeAmount)
+	}
+
+	// Pickup in the toll zone, destination at the airport: both apply.
+	ride = &domain.Ride{PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 13.20, DestinationLng: 77.70}
+	surcharges.Apply(ride)
+	if ride.SurchargeAmount != 9.5 || ride.SurchargeLabel != "Airport fee, Expressway toll" {
+		t.Errorf("expected both surcharges, got %q %.2f", ride.SurchargeLabel, ride.SurchargeAmount)
+	}
+}
+
+func TestSurcharge_ChargedAndOnReceipt(t *testing.T) {
+	t.Parallel()
+
+	db, _ := NewRecordingDB()
+	defer db.Close()
+
+	rideRepo := NewMockRideRepository()
+	rideRepo.AddRide(&domain.Ride{
+		ID: "ride-1", RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 13.20, DestinationLng: 77.70,
+		Status: domain.RideStatusInTrip, AssignedDriverID: "driver-1", SurgeMultiplier: 1, PaymentMethod: domain.PaymentMethodCard,
+		SurchargeLabel: "Airport fee", SurchargeAmount: 7.5, Version: 2,
+	})
+	tripRepo := NewMockTripRepository()
+	_ = tripRepo.Create(context.Background(), &domain.Trip{
+		ID: "trip-1", RideID: "ride-1", DriverID: "driver-1", Status: domain.TripStatusStarted,
+		StartedAt: time.Now().Add(-20 * time.Minute), Version: 1,
+	})
+
+	receiptService := service.NewReceiptService(nil, nil, nil, nil, nil)
+	paymentService := service.NewPaymentService(NewMockPaymentRepository(), NewMockPSP(), nil, nil, nil, 0)
+	tripService := service.NewTripService(service.TripServiceDeps{
+		DB:             db,
+		TripRepo:       tripRepo,
+		RideRepo:       rideRepo,
+		DriverRepo:     NewMockDriverRepository(),
+		PaymentService: paymentService,
+		ReceiptService: receiptService,
+	})
+
+	resp, err := tripService.EndTrip(context.Background(), service.EndTripRequest{TripID: "trip-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// $2 base + 20 min at $0.50, plus the airport fee.
+	if math.Abs(resp.Trip.Fare-19.5) > 0.05 {
+		t.Errorf("expected fare of about 19.50 including the surcharge, got %.2f", resp.Trip.Fare)
+	}
+	if resp.Payment == nil || resp.Payment.Amount != resp.Trip.Fare {
+		t.Errorf("expected the surcharge in the charged total, got %+v", resp.Payment)
+	}
+
+	receipt := resp.Receipt
+	if receipt == nil || receipt.SurchargeLabel != "Airport fee" || receipt.SurchargeAmount != 7.5 {
+		t.Fatalf("expected an airport fee line item, got %+v", receipt)
+	}
+	if !strings.Contains(receiptService.FormatReceipt(receipt), "Airport fee:  $7.50") {
+		t.Error("expected the surcharge line in the formatted receipt")
+	}
+}
@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// SCHEDULED SURGE FLOOR
+// ──────────────────────────────────────────────
+
+// at returns today's date at hh:mm in loc.
+func at(hour, minute int, loc *time.Location) time.Time {
+	return time.Date(2026, 10, 15, hour, minute, 0, 0, loc)
+}
+
+func TestSurgeFloor_AppliesOnlyDuringWindow(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	locations, rides, _ := newSurgeSpike() // One driver, no demand: 1.0x
+	clock := &fakeClock{}
+	morningPeak := []domain.SurgeFloor{{Start: 8 * time.Hour, End: 9 * time.Hour, Multiplier: 1.3}}
+	surge := service.NewSurgeService(locations, rides, NewMockSurgeStore(), 0.5, 0, morningPeak, clock.Now)
+
+	testCases := []struct {
+		name string
+		now  time.Time
+		want float64
+	}{
+		{"before the window", at(7, 59, time.UTC), 1.0},
+		{"window opens", at(8, 0, time.UTC), 1.3},
+		{"inside the window", at(8, 30, time.UTC), 1.3},
+		{"window closes", at(9, 0, time.UTC), 1.0},
+		{"evening", at(18, 0, time.UTC), 1.0},
+	}
+	for _, tc := range testCases {
+		clock.t = tc.now
+		if got := surge.GetMultiplier(ctx, 12.97, 77.59); got != tc.want {
+			t.Errorf("%s: expected %.2fx, got %.3f", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestSurgeFloor_HigherDemandSurgeWins(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	locations, rides, addRequests := newSurgeSpike()
+	addRequests(3) // 2.0x from demand
+	clock := &fakeClock{t: at(8, 30, time.UTC)}
+	surge := service.NewSurgeService(locations, rides, nil, 0, 0,
+		[]domain.SurgeFloor{{Start: 8 * time.Hour, End: 9 * time.Hour, Multiplier: 1.3}}, clock.Now)
+
+	if got := surge.GetMultiplier(ctx, 12.97, 77.59); got != 2.0 {
+		t.Errorf("expected the demand-based 2.0x over the 1.3x floor, got %.3f", got)
+	}
+}
+
+func TestSurgeFloor_DoesNotLingerInSmoothing(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	locations, rides, _ := newSurgeSpike()
+	clock := &fakeClock{t: at(8, 59, time.UTC)}
+	surge := service.NewSurgeService(locations, rides, NewMockSurgeStore(), 0.5, 0,
+		[]domain.SurgeFloor{{Start: 8 * time.Hour, End: 9 * time.Hour, Multiplier: 1.5}}, clock.Now)
+
+	_ = surge.GetMultiplier(ctx, 12.97, 77.59)
+	clock.t = at(9, 1, time.UTC)
+	if got := surge.GetMultiplier(ctx, 12.97, 77.59); got != 1.0 {
+		t.Errorf("expected surge back to 1.0x as soon as the window closes, got %.3f", got)
+	}
+}
+
+func TestSurgeFloor_WindowsPastMidnightAndTimeZones(t *testing.T) {
+	t.Parallel()
+
+	lateNight := domain.SurgeFloor{Start: 23 * time.Hour, End: 2 * time.Hour, Multiplier: 1.2}
+	if !lateNight.Covers(at(23, 30, time.UTC)) || !lateNight.Covers(at(1, 0, time.UTC)) {
+		t.Error("expected the window to cover both sides of midnight")
+	}
+	if lateNight.Covers(at(2, 0, time.UTC)) || lateNight.Covers(at(12, 0, time.UTC)) {
+		t.Error("expected the window closed at 02:00 and midday")
+	}
+
+	// Windows are read in the clock's zone: 02:45 UTC is 08:15 in IST.
+	ist := time.FixedZone("IST", 5*3600+1800)
+	morningPeak := domain.SurgeFloor{Start: 8 * time.Hour, End: 9 * time.Hour, Multiplier: 1.3}
+	if !morningPeak.Covers(at(2, 45, time.UTC).In(ist)) {
+		t.Error("expected 08:15 IST inside the morning peak")
+	}
+}
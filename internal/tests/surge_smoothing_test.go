@@ -0,0 +1,145 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/redis"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// SURGE SMOOTHING
+// ──────────────────────────────────────────────
+
+// newSurgeSpike returns one nearby driver and no demand, plus a func that
+// adds n open requests at the pickup, enough for 2.0x from n = 2.
+func newSurgeSpike() (*MockLocationStore, *MockRideRepository, func(n int)) {
+	locations := NewMockLocationStore()
+	locations.AddDriverLocation(redis.DriverLocation{DriverID: "driver-1", Lat: 12.97, Lng: 77.59})
+	rides := NewMockRideRepository()
+	added := 0
+	addRequests := func(n int) {
+		for i := 0; i < n; i++ {
+			added++
+			rides.AddRide(&domain.Ride{
+				ID: fmt.Sprintf("ride-%d", added), RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59,
+				Status: domain.RideStatusRequested,
+			})
+		}
+	}
+	return locations, rides, addRequests
+}
+
+func TestSurgeSmoothing_RampsUpAfterDemandSpike(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	locations, rides, addRequests := newSurgeSpike()
+	store := NewMockSurgeStore()
+	surge := service.NewSurgeService(locations, rides, store, 0.5, 5*time.Minute, nil, nil)
+
+	if got := surge.GetMultiplier(ctx, 12.97, 77.59); got != 1.0 {
+		t.Fatalf("expected no surge before the spike, got %.3f", got)
+	}
+
+	addRequests(3)
+	var readings []float64
+	for i := 0; i < 8; i++ {
+		readings = append(readings, surge.GetMultiplier(ctx, 12.97, 77.59))
+	}
+
+	// Half way to 2.0x each time: 1.5, 1.75, 1.875, ... snapping to 2.0.
+	if math.Abs(readings[0]-1.5) > 1e-9 || math.Abs(readings[1]-1.75) > 1e-9 {
+		t.Errorf("expected 1.5x then 1.75x, got %v", readings[:2])
+	}
+	for i := 1; i < len(readings); i++ {
+		if readings[i] < readings[i-1] {
+			t.Errorf("expected surge to rise monotonically, got %v", readings)
+			break
+		}
+	}
+	if last := readings[len(readings)-1]; last != 2.0 {
+		t.Errorf("expected surge to settle at exactly 2.0x, got %v", readings)
+	}
+	if store.LastTTL != 5*time.Minute {
+		t.Errorf("expected the configured TTL, got %v", store.LastTTL)
+	}
+
+	// Once the cell is forgotten, the next spike ramps from 1.0x again.
+	store.Expire()
+	if got := surge.GetMultiplier(ctx, 12.97, 77.59); math.Abs(got-1.5) > 1e-9 {
+		t.Errorf("expected 1.5x after the TTL, got %.3f", got)
+	}
+}
+
+func TestSurgeSmoothing_RampsDownAfterDemandClears(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	locations, rides, addRequests := newSurgeSpike()
+	store := NewMockSurgeStore()
+	surge := service.NewSurgeService(locations, rides, store, 0.5, 0, nil, nil)
+
+	addRequests(3)
+	for i := 0; i < 10; i++ {
+		surge.GetMultiplier(ctx, 12.97, 77.59)
+	}
+	for _, ride := range rides.GetAllRides() {
+		ride.Status = domain.RideStatusCancelled
+		_ = rides.Update(ctx, ride)
+	}
+
+	if got := surge.GetMultiplier(ctx, 12.97, 77.59); math.Abs(got-1.5) > 1e-9 {
+		t.Errorf("expected surge to ease to 1.5x, got %.3f", got)
+	}
+}
+
+func TestSurgeSmoothing_CellsAreIndependent(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	locations, rides, addRequests := newSurgeSpike()
+	surge := service.NewSurgeService(locations, rides, NewMockSurgeStore(), 0.5, 0, nil, nil)
+
+	addRequests(3)
+	for i := 0; i < 10; i++ {
+		surge.GetMultiplier(ctx, 12.97, 77.59)
+	}
+
+	// A point about 2km away sees the same demand but its own history.
+	if got := surge.GetMultiplier(ctx, 12.99, 77.59); math.Abs(got-1.5) > 1e-9 {
+		t.Errorf("expected the neighbouring cell to start ramping at 1.5x, got %.3f", got)
+	}
+}
+
+func TestSurgeSmoothing_Disabled(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	failing := NewMockSurgeStore()
+	failing.GetError = errors.New("redis down")
+
+	testCases := []struct {
+		name      string
+		store     redis.SurgeStoreInterface
+		smoothing float64
+	}{
+		{"no store", nil, 0.5},
+		{"factor of 1", NewMockSurgeStore(), 1},
+		{"store unavailable", failing, 0.5},
+	}
+	for _, tc := range testCases {
+		locations, rides, addRequests := newSurgeSpike()
+		addRequests(3)
+		surge := service.NewSurgeService(locations, rides, tc.store, tc.smoothing, 0, nil, nil)
+		if got := surge.GetMultiplier(ctx, 12.97, 77.59); got != 2.0 {
+			t.Errorf("%s: expected an immediate 2.0x, got %.3f", tc.name, got)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"ride/internal/domain"
+	"ride/internal/redis"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// PER-TIER SEARCH RADIUS
+// ──────────────────────────────────────────────
+
+// newTierRadiusMatcher places a single ONLINE driver of the given tier about
+// 7 km north of ride-1's pickup, with a 3 km BASIC and 10 km PREMIUM radius.
+func newTierRadiusMatcher(t *testing.T, tier domain.DriverTier) (*service.MatchingService, *MockLocationStore) {
+	t.Helper()
+
+	db, _ := NewRecordingDB()
+	t.Cleanup(func() { _ = db.Close() })
+
+	drivers := NewMockDriverRepository()
+	drivers.AddDriver(&domain.Driver{ID: "driver-far", Status: domain.DriverStatusOnline, Tier: tier})
+	locations := NewMockLocationStore()
+	locations.FilterByRadius = true
+	locations.AddDriverLocation(redis.DriverLocation{DriverID: "driver-far", Lat: 12.97 + 0.063, Lng: 77.59})
+	rides := NewMockRideRepository()
+	rides.AddRide(&domain.Ride{ID: "ride-1", RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, Status: domain.RideStatusRequested, Version: 1})
+
+	matcher := service.NewMatchingService(service.MatchingServiceDeps{
+		DB:            db,
+		LocationStore: locations,
+		LockStore:     NewMockLockStore(),
+		DriverRepo:    drivers,
+		RideRepo:      rides,
+		TierRadiusKm:  map[domain.DriverTier]float64{domain.DriverTierBasic: 3, domain.DriverTierPremium: 10},
+	})
+	return matcher, locations
+}
+
+func TestTierRadius_PremiumSearchesWider(t *testing.T) {
+	t.Parallel()
+
+	matcher, locations := newTierRadiusMatcher(t, domain.DriverTierPremium)
+
+	result, err := matcher.Match(context.Background(), service.MatchRequest{RideID: "ride-1", Lat: 12.97, Lng: 77.59, Tier: domain.DriverTierPremium})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.DriverID != "driver-far" {
+		t.Errorf("expected driver-far matched, got %s", result.DriverID)
+	}
+	if locations.LastFindRadiusKm != 10 {
+		t.Errorf("expected the 10 km premium radius, got %.1f", locations.LastFindRadiusKm)
+	}
+}
+
+func TestTierRadius_BasicMissesDriverOutsideItsRadius(t *testing.T) {
+	t.Parallel()
+
+	matcher, locations := newTierRadiusMatcher(t, domain.DriverTierBasic)
+
+	_, err := matcher.Match(context.Background(), service.MatchRequest{RideID: "ride-1", Lat: 12.97, Lng: 77.59, Tier: domain.DriverTierBasic})
+	if !errors.Is(err, service.ErrNoDriverAvailable) {
+		t.Fatalf("expected ErrNoDriverAvailable, got %v", err)
+	}
+	if locations.LastFindRadiusKm != 3 {
+		t.Errorf("expected the 3 km basic radius, got %.1f", locations.LastFindRadiusKm)
+	}
+}
+
+func TestTierRadius_ExplicitRadiusAndUntieredRequests(t *testing.T) {
+	t.Parallel()
+
+	matcher, locations := newTierRadiusMatcher(t, domain.DriverTierBasic)
+
+	// An explicit radius wins over the tier default.
+	result, err := matcher.Match(context.Background(), service.MatchRequest{RideID: "ride-1", Lat: 12.97, Lng: 77.59, Tier: domain.DriverTierBasic, RadiusKm: 8})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.DriverID != "driver-far" || locations.LastFindRadiusKm != 8 {
+		t.Errorf("expected driver-far within the explicit 8 km radius, got %s at %.1f km", result.DriverID, locations.LastFindRadiusKm)
+	}
+
+	// Requests without a tier keep the 5 km default.
+	_, _ = matcher.Match(context.Background(), service.MatchRequest{RideID: "ride-1", Lat: 12.97, Lng: 77.59})
+	if locations.LastFindRadiusKm != 5 {
+		t.Errorf("expected the 5 km default radius, got %.1f", locations.LastFindRadiusKm)
+	}
+}
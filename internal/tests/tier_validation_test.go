@@ -0,0 +1,148 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// TIER VALIDATION
+// ──────────────────────────────────────────────
+
+// catalogDefaultingTo returns the built-in catalog with defaultTier as its
+// default, or unchanged when defaultTier is empty.
+func catalogDefaultingTo(defaultTier domain.DriverTier) *domain.Catalog {
+	catalog := domain.DefaultCatalog()
+	if defaultTier != "" {
+		catalog.DefaultTier = defaultTier
+	}
+	return catalog
+}
+
+func TestValidateTier(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		tier        string
+		defaultTier domain.DriverTier
+		want        domain.DriverTier
+		wantErr     bool
+	}{
+		{"BASIC", "", domain.DriverTierBasic, false},
+		{"premium", "", domain.DriverTierPremium, false},
+		{" Premium ", "", domain.DriverTierPremium, false},
+		{"", "", domain.DriverTierBasic, false},
+		{"", domain.DriverTierPremium, domain.DriverTierPremium, false},
+		{"basic", domain.DriverTierPremium, domain.DriverTierBasic, false},
+		{"premiuim", "", "", true},
+		{"GOLD", domain.DriverTierBasic, "", true},
+		{"", "GOLD", "", true},
+	}
+
+	for _, tc := range testCases {
+		got, err := service.ValidateTier(tc.tier, catalogDefaultingTo(tc.defaultTier))
+		if tc.wantErr {
+			if !errors.Is(err, service.ErrInvalidTier) {
+				t.Errorf("%q (default %q): expected ErrInvalidTier, got %v", tc.tier, tc.defaultTier, err)
+			}
+			continue
+		}
+		if err != nil || got != tc.want {
+			t.Errorf("%q (default %q): expected %s, got %s (%v)", tc.tier, tc.defaultTier, tc.want, got, err)
+		}
+	}
+}
+
+func TestTierValidation_RideRequests(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		tier        string
+		defaultTier domain.DriverTier
+		wantCode    int
+		wantTier    domain.DriverTier
+	}{
+		{"explicit tier any case", "premium", "", http.StatusCreated, domain.DriverTierPremium},
+		{"empty tier defaults to BASIC", "", "", http.StatusCreated, domain.DriverTierBasic},
+		{"empty tier uses configured default", "", domain.DriverTierPremium, http.StatusCreated, domain.DriverTierPremium},
+		{"typo rejected", "premiuim", "", http.StatusUnprocessableEntity, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			matching := NewMockMatchingServiceForTest()
+			rideService := service.NewRideService(service.RideServiceDeps{RideRepo: NewMockRideRepository(), MatchingService: matching})
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.POST("/v1/rides", handler.NewRideHandler(rideService, nil, nil, catalogDefaultingTo(tc.defaultTier)).CreateRide)
+
+			body := `{"rider_id":"rider-1","pickup_lat":12.97,"pickup_lng":77.59,"destination_lat":13.2,"destination_lng":77.7,"tier":"` + tc.tier + `"}`
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/rides", strings.NewReader(body)))
+
+			if w.Code != tc.wantCode {
+				t.Fatalf("expected %d, got %d: %s", tc.wantCode, w.Code, w.Body.String())
+			}
+			if tc.wantCode != http.StatusCreated {
+				if matching.CallCount() != 0 {
+					t.Error("expected no matching for an invalid tier")
+				}
+				return
+			}
+			if got := matching.LastRequest().Tier; got != tc.wantTier {
+				t.Errorf("expected matching for %s, got %q", tc.wantTier, got)
+			}
+		})
+	}
+}
+
+func TestTierValidation_DriverRegistration(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		tier     string
+		wantCode int
+		wantTier string
+	}{
+		{"PREMIUM", http.StatusCreated, "PREMIUM"},
+		{"premium", http.StatusCreated, "PREMIUM"},
+		{"", http.StatusCreated, "BASIC"},
+		{"premiuim", http.StatusUnprocessableEntity, ""},
+	}
+
+	for i, tc := range testCases {
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.POST("/v1/drivers/register", handler.NewDriverHandler(nil, nil, NewMockDriverRepository(), "IN", nil, nil).Register)
+
+		body, _ := json.Marshal(map[string]string{"name": "Asha", "phone": "+9198765432" + string(rune('0'+i)) + "0", "tier": tc.tier})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/drivers/register", strings.NewReader(string(body))))
+
+		if w.Code != tc.wantCode {
+			t.Errorf("%q: expected %d, got %d: %s", tc.tier, tc.wantCode, w.Code, w.Body.String())
+			continue
+		}
+		if tc.wantCode != http.StatusCreated {
+			continue
+		}
+		var resp handler.DriverResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		if resp.Tier != tc.wantTier {
+			t.Errorf("%q: expected tier %s, got %s", tc.tier, tc.wantTier, resp.Tier)
+		}
+	}
+}
@@ -0,0 +1,338 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/middleware"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// SIGNED TRACKER LOCATION UPDATES
+// ──────────────────────────────────────────────
+
+const (
+	trackerMaxSkew       = 5 * time.Minute
+	trackerEncryptionKey = "test-tracker-encryption-key"
+)
+
+// trackerNow is the time tracker signatures are checked against.
+var trackerNow = time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+
+func newTrackerRouter(env *testEnv, keys *MockTrackerKeyRepository, nonces *MockTrackerNonceStore) *gin.Engine {
+	trackerKeys := service.NewTrackerKeyService(keys, env.drivers, trackerEncryptionKey)
+	driverHandler := handler.NewDriverHandler(env.driverService(), nil, env.drivers, "IN", nil, trackerKeys)
+
+	router := newTestRouter()
+	router.Use(middleware.IdentityMiddleware(testAdminToken))
+	router.POST("/v1/drivers/register", driverHandler.Register)
+	router.POST("/v1/drivers/:id/location", middleware.TrackerAuthMiddleware(middleware.TrackerAuthConfig{
+		Keys:    trackerKeys,
+		Nonces:  nonces,
+		MaxSkew: trackerMaxSkew,
+		Now:     func() time.Time { return trackerNow },
+	}), driverHandler.UpdateLocation)
+	router.POST("/v1/admin/drivers/:id/tracker-key",
+		middleware.AdminAuthMiddleware(testAdminToken), handler.NewTrackerKeyHandler(trackerKeys).Rotate)
+	return router
+}
+
+// registerTracker registers a driver and returns its ID and tracker secret.
+func registerTracker(t *testing.T, router *gin.Engine, phone string) (string, string) {
+	t.Helper()
+
+	body, _ := json.Marshal(handler.RegisterDriverRequest{Name: "Tracker", Phone: phone, Tier: "BASIC"})
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/drivers/register", bytes.NewReader(body)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp handler.RegisterDriverResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.TrackerSecret == "" {
+		t.Fatal("expected a tracker secret at registration")
+	}
+	return resp.ID, resp.TrackerSecret
+}
+
+// postLocation sends a location update signed with secret at the given
+// time.
+func postLocation(router *gin.Engine, driverID, secret, nonce string, at time.Time) *httptest.ResponseRecorder {
+	return postSignedLocation(router, driverID, service.HashTrackerSecret(secret), nonce, at)
+}
+
+// postSignedLocation sends a location update signed with signingKey as is.
+func postSignedLocation(router *gin.Engine, driverID, signingKey, nonce string, at time.Time) *httptest.ResponseRecorder {
+	body := []byte(`{"lat":12.9716,"lng":77.5946}`)
+	req := httptest.NewRequest(http.MethodPost, "/v1/drivers/"+driverID+"/location", bytes.NewReader(body))
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	req.Header.Set(middleware.TrackerTimestampHeader, timestamp)
+	req.Header.Set(middleware.TrackerNonceHeader, nonce)
+	req.Header.Set(middleware.TrackerSignatureHeader, middleware.TrackerSignature(signingKey, timestamp, nonce, body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// postAppLocation sends an unsigned location update as the app does, on
+// behalf of userID.
+func postAppLocation(router *gin.Engine, driverID, userID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/v1/drivers/"+driverID+"/location", strings.NewReader(`{"lat":12.9716,"lng":77.5946}`))
+	req.Header.Set("X-User-ID", userID)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestTrackerAuth_ValidSignature(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	keys, nonces := NewMockTrackerKeyRepository(), NewMockTrackerNonceStore()
+	router := newTrackerRouter(env, keys, nonces)
+	driverID, secret := registerTracker(t, router, "+919876543210")
+
+	// Clocks a little apart are tolerated.
+	if w := postLocation(router, driverID, secret, "nonce-1", trackerNow.Add(-time.Minute)); w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", w.Code, w.Body.String())
+	}
+	if !env.locations.HasLocation(driverID) {
+		t.Error("expected the tracker's location stored")
+	}
+	if ttl := nonces.TTL(driverID, "nonce-1"); ttl != 2*trackerMaxSkew {
+		t.Errorf("expected the nonce kept for %s, got %s", 2*trackerMaxSkew, ttl)
+	}
+}
+
+func TestTrackerAuth_ExpiredTimestamp(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	keys, nonces := NewMockTrackerKeyRepository(), NewMockTrackerNonceStore()
+	router := newTrackerRouter(env, keys, nonces)
+	driverID, secret := registerTracker(t, router, "+919876543210")
+
+	for _, at := range []time.Time{trackerNow.Add(-trackerMaxSkew - time.Second), trackerNow.Add(trackerMaxSkew + time.Second)} {
+		if w := postLocation(router, driverID, secret, "nonce-"+at.String(), at); w.Code != http.StatusUnauthorized {
+			t.Errorf("expected 401 for a request signed at %s, got %d", at, w.Code)
+		}
+	}
+	if env.locations.HasLocation(driverID) {
+		t.Error("expected no location stored from expired requests")
+	}
+}
+
+func TestTrackerAuth_ReplayedNonce(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	keys, nonces := NewMockTrackerKeyRepository(), NewMockTrackerNonceStore()
+	router := newTrackerRouter(env, keys, nonces)
+	driverID, secret := registerTracker(t, router, "+919876543210")
+
+	if w := postLocation(router, driverID, secret, "nonce-1", trackerNow); w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := postLocation(router, driverID, secret, "nonce-1", trackerNow); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for a replayed nonce, got %d", w.Code)
+	}
+	if got := env.locations.UpdateLocationCallCount; got != 1 {
+		t.Errorf("expected one location update, got %d", got)
+	}
+}
+
+func TestTrackerAuth_WrongKey(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	keys, nonces := NewMockTrackerKeyRepository(), NewMockTrackerNonceStore()
+	router := newTrackerRouter(env, keys, nonces)
+	driverID, _ := registerTracker(t, router, "+919876543210")
+	_, otherSecret := registerTracker(t, router, "+919876543211")
+
+	if w := postLocation(router, driverID, otherSecret, "nonce-1", trackerNow); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for another driver's key, got %d", w.Code)
+	}
+	if w := postLocation(router, "driver-without-key", otherSecret, "nonce-2", trackerNow); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for a driver without a key, got %d", w.Code)
+	}
+	if nonces.TTL(driverID, "nonce-1") != 0 {
+		t.Error("expected a rejected signature not to use up its nonce")
+	}
+}
+
+func TestTrackerAuth_TamperedBody(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	keys, nonces := NewMockTrackerKeyRepository(), NewMockTrackerNonceStore()
+	router := newTrackerRouter(env, keys, nonces)
+	driverID, secret := registerTracker(t, router, "+919876543210")
+
+	timestamp := strconv.FormatInt(trackerNow.Unix(), 10)
+	signature := middleware.TrackerSignature(service.HashTrackerSecret(secret), timestamp, "nonce-1", []byte(`{"lat":12.9716,"lng":77.5946}`))
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/drivers/"+driverID+"/location", bytes.NewReader([]byte(`{"lat":13.5,"lng":77.5946}`)))
+	req.Header.Set(middleware.TrackerTimestampHeader, timestamp)
+	req.Header.Set(middleware.TrackerNonceHeader, "nonce-1")
+	req.Header.Set(middleware.TrackerSignatureHeader, signature)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for a body other than the one signed, got %d", w.Code)
+	}
+}
+
+func TestTrackerAuth_UnsignedRejectedOnceKeyIssued(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	keys, nonces := NewMockTrackerKeyRepository(), NewMockTrackerNonceStore()
+	router := newTrackerRouter(env, keys, nonces)
+	driverID, _ := registerTracker(t, router, "+919876543210")
+
+	// Claiming to be the driver is not enough once they have a key.
+	if w := postAppLocation(router, driverID, driverID); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for an unsigned update, got %d: %s", w.Code, w.Body.String())
+	}
+	if env.locations.HasLocation(driverID) {
+		t.Error("expected no location stored")
+	}
+}
+
+func TestTrackerAuth_UnsignedWithoutKeyOnlyFromDriver(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	env.drivers.AddDriver(&domain.Driver{ID: "driver-1", Status: domain.DriverStatusOffline, Tier: domain.DriverTierBasic})
+	router := newTrackerRouter(env, NewMockTrackerKeyRepository(), NewMockTrackerNonceStore())
+
+	for _, userID := range []string{"", "driver-2", "rider-1"} {
+		if w := postAppLocation(router, "driver-1", userID); w.Code != http.StatusForbidden {
+			t.Errorf("caller %q: expected 403, got %d: %s", userID, w.Code, w.Body.String())
+		}
+	}
+	if env.locations.HasLocation("driver-1") {
+		t.Fatal("expected no location stored for other callers")
+	}
+
+	if w := postAppLocation(router, "driver-1", "driver-1"); w.Code != http.StatusNoContent {
+		t.Errorf("expected the app's unsigned update accepted, got %d: %s", w.Code, w.Body.String())
+	}
+	if !env.locations.HasLocation("driver-1") {
+		t.Error("expected the driver's location stored")
+	}
+}
+
+func TestTrackerAuth_Rotation(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	keys, nonces := NewMockTrackerKeyRepository(), NewMockTrackerNonceStore()
+	router := newTrackerRouter(env, keys, nonces)
+	driverID, oldSecret := registerTracker(t, router, "+919876543210")
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/admin/drivers/"+driverID+"/tracker-key", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 without the admin token, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/admin/drivers/"+driverID+"/tracker-key", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp handler.TrackerKeyResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.DriverID != driverID || resp.TrackerSecret == "" || resp.TrackerSecret == oldSecret {
+		t.Fatalf("expected a new secret, got %+v", resp)
+	}
+
+	if w := postLocation(router, driverID, oldSecret, "nonce-1", trackerNow); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for the rotated-out key, got %d", w.Code)
+	}
+	if w := postLocation(router, driverID, resp.TrackerSecret, "nonce-2", trackerNow); w.Code != http.StatusNoContent {
+		t.Errorf("expected 204 for the new key, got %d: %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/admin/drivers/driver-missing/tracker-key", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown driver, got %d", w.Code)
+	}
+}
+
+func TestTrackerAuth_StoredSecretCannotSign(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	keys, nonces := NewMockTrackerKeyRepository(), NewMockTrackerNonceStore()
+	router := newTrackerRouter(env, keys, nonces)
+	driverID, secret := registerTracker(t, router, "+919876543210")
+	otherID, _ := registerTracker(t, router, "+919876543211")
+
+	stored, err := keys.GetSecret(context.Background(), driverID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(stored, secret) || strings.Contains(stored, service.HashTrackerSecret(secret)) {
+		t.Fatalf("expected neither the secret nor its signing key stored, got %q", stored)
+	}
+
+	// Whoever reads the table cannot sign with what is in it.
+	for i, key := range []string{stored, service.HashTrackerSecret(stored)} {
+		if w := postSignedLocation(router, driverID, key, "nonce-"+strconv.Itoa(i), trackerNow); w.Code != http.StatusUnauthorized {
+			t.Errorf("expected 401 signing with the stored value, got %d", w.Code)
+		}
+	}
+
+	// Nor move it to another driver's row and sign for them.
+	_ = keys.Set(context.Background(), otherID, stored)
+	if w := postLocation(router, otherID, secret, "nonce-2", trackerNow); w.Code == http.StatusNoContent {
+		t.Error("expected a secret copied to another driver not to authenticate them")
+	}
+	if env.locations.HasLocation(driverID) || env.locations.HasLocation(otherID) {
+		t.Error("expected no location stored")
+	}
+}
+
+func TestTrackerAuth_UnavailableWithoutEncryptionKey(t *testing.T) {
+	t.Parallel()
+
+	driverRepo := NewMockDriverRepository()
+	driverRepo.AddDriver(&domain.Driver{ID: "driver-1", Status: domain.DriverStatusOnline, Tier: domain.DriverTierBasic})
+	trackerKeys := service.NewTrackerKeyService(NewMockTrackerKeyRepository(), driverRepo, "")
+
+	if _, err := trackerKeys.Issue(context.Background(), "driver-1"); !errors.Is(err, service.ErrTrackerKeysUnavailable) {
+		t.Errorf("expected ErrTrackerKeysUnavailable issuing a secret, got %v", err)
+	}
+	if key, err := trackerKeys.SigningKey(context.Background(), "driver-1"); key != "" || err != nil {
+		t.Errorf("expected no key for a driver never issued one, got %q, %v", key, err)
+	}
+
+	keys := NewMockTrackerKeyRepository()
+	_ = keys.Set(context.Background(), "driver-1", "sealed-elsewhere")
+	trackerKeys = service.NewTrackerKeyService(keys, driverRepo, "")
+	if _, err := trackerKeys.SigningKey(context.Background(), "driver-1"); !errors.Is(err, service.ErrTrackerKeysUnavailable) {
+		t.Errorf("expected ErrTrackerKeysUnavailable verifying, got %v", err)
+	}
+}
@@ -0,0 +1,199 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// TRIP ABORTS
+// ──────────────────────────────────────────────
+
+// newAbortTripService adds trip-1, started 20 minutes ago on a 1.5x surge
+// ride with a 7.50 airport fee, under the given driver abort policy.
+func newAbortTripService(env *testEnv, driverAbortFare service.AbortFarePolicy) *service.TripService {
+	env.rides.AddRide(&domain.Ride{
+		ID: "ride-1", RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 13.20, DestinationLng: 77.70,
+		Status: domain.RideStatusInTrip, AssignedDriverID: "driver-1", SurgeMultiplier: 1.5,
+		PaymentMethod: domain.PaymentMethodCard, SurchargeLabel: "Airport fee", SurchargeAmount: 7.5, Version: 2,
+	})
+	_ = env.trips.Create(context.Background(), &domain.Trip{
+		ID: "trip-1", RideID: "ride-1", DriverID: "driver-1", Status: domain.TripStatusStarted,
+		StartedAt: time.Now().Add(-20 * time.Minute), Version: 1,
+	})
+
+	deps := env.tripDeps()
+	deps.ReceiptService = service.NewReceiptService(nil, nil, nil, nil, nil)
+	deps.DriverAbortFare = driverAbortFare
+	return service.NewTripService(deps)
+}
+
+func abortTrip(t *testing.T, tripService *service.TripService, party domain.AbortParty) *service.EndTripResponse {
+	t.Helper()
+
+	resp, err := tripService.AbortTrip(context.Background(), service.AbortTripRequest{
+		TripID: "trip-1", AbortedBy: party, Reason: "vehicle breakdown",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return resp
+}
+
+// assertRideCancelledAndDriverOnline checks the transaction cancelled the
+// ride with the abort reason and put the driver back ONLINE.
+func assertRideCancelledAndDriverOnline(t *testing.T, env *testEnv) {
+	t.Helper()
+
+	updates := rideUpdateArgs(env.rec)
+	if len(updates) != 1 || updates[0][5] != string(domain.RideStatusCancelled) || updates[0][10] != "vehicle breakdown" {
+		t.Errorf("expected the ride cancelled with the abort reason, got %v", updates)
+	}
+
+	online := false
+	for _, q := range env.rec.Queries() {
+		if strings.Contains(q.Query, "UPDATE drivers") && q.Args[0] == string(domain.DriverStatusOnline) {
+			online = true
+		}
+	}
+	if !online {
+		t.Error("expected the driver reset to ONLINE")
+	}
+}
+
+func TestTripAbort_RiderAbortChargesElapsedFare(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	tripService := newAbortTripService(env, service.AbortFareNone)
+	resp := abortTrip(t, tripService, domain.AbortPartyRider)
+
+	// 2.00 + 20 min x 0.50 = 12.00, with 1.5x surge and no airport fee.
+	if resp.Trip.Status != domain.TripStatusAborted || resp.Trip.AbortedBy != domain.AbortPartyRider {
+		t.Errorf("expected the trip aborted by the rider, got %+v", resp.Trip)
+	}
+	if math.Abs(resp.Trip.Fare-18) > 0.1 {
+		t.Errorf("expected a prorated fare of about 18.00, got %.2f", resp.Trip.Fare)
+	}
+	if resp.Payment == nil || resp.Payment.Amount != resp.Trip.Fare || env.psp.ChargeCallCount != 1 {
+		t.Errorf("expected one charge of the prorated fare, got %+v", resp.Payment)
+	}
+	if resp.Receipt == nil || resp.Receipt.AbortedBy != domain.AbortPartyRider || resp.Receipt.SurchargeAmount != 0 {
+		t.Errorf("expected a receipt for the abort without the surcharge, got %+v", resp.Receipt)
+	}
+	assertRideCancelledAndDriverOnline(t, env)
+}
+
+func TestTripAbort_DriverAbortNoChargePolicy(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	tripService := newAbortTripService(env, service.AbortFareNone)
+	resp := abortTrip(t, tripService, domain.AbortPartyDriver)
+
+	if resp.Trip.Status != domain.TripStatusAborted || resp.Trip.Fare != 0 {
+		t.Errorf("expected an aborted trip with no fare, got %+v", resp.Trip)
+	}
+	if resp.Payment != nil || env.psp.ChargeCallCount != 0 {
+		t.Errorf("expected no payment, got %+v", resp.Payment)
+	}
+	if got := env.payments.CountPayments(); got != 0 {
+		t.Errorf("expected no payment recorded, got %d", got)
+	}
+
+	receipt := resp.Receipt
+	if receipt == nil || receipt.TotalFare != 0 || receipt.BaseFare != 0 || receipt.PaymentStatus != "" {
+		t.Fatalf("expected a zero receipt with nothing pending, got %+v", receipt)
+	}
+	text := service.NewReceiptService(nil, nil, nil, nil, nil).FormatReceipt(receipt)
+	if !strings.Contains(text, "Aborted by driver: vehicle breakdown") || !strings.Contains(text, "Status: No charge") {
+		t.Errorf("expected the receipt to show the abort and no charge, got %s", text)
+	}
+	assertRideCancelledAndDriverOnline(t, env)
+}
+
+func TestTripAbort_DriverAbortElapsedFarePolicy(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	tripService := newAbortTripService(env, service.AbortFareElapsed)
+	resp := abortTrip(t, tripService, domain.AbortPartyDriver)
+
+	if math.Abs(resp.Trip.Fare-18) > 0.1 {
+		t.Errorf("expected the elapsed-time fare, got %.2f", resp.Trip.Fare)
+	}
+	if resp.Payment == nil || env.psp.ChargeCallCount != 1 {
+		t.Errorf("expected the driver abort charged under the ELAPSED policy, got %+v", resp.Payment)
+	}
+	if resp.Receipt == nil || resp.Receipt.AbortedBy != domain.AbortPartyDriver {
+		t.Errorf("expected the receipt to record the driver abort, got %+v", resp.Receipt)
+	}
+}
+
+func TestTripAbort_PausedTripExcludesPausedTime(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	tripService := newAbortTripService(env, service.AbortFareNone)
+	trip := env.trips.GetTrip("trip-1")
+	trip.Status = domain.TripStatusPaused
+	trip.PausedAt = time.Now().Add(-10 * time.Minute)
+	trip.PauseReason = domain.PauseReasonOther
+
+	resp := abortTrip(t, tripService, domain.AbortPartyRider)
+
+	// Only the 10 minutes before the pause are charged: 2.00 + 5.00, x1.5.
+	if math.Abs(resp.Trip.Fare-10.5) > 0.1 {
+		t.Errorf("expected paused time excluded from the fare, got %.2f", resp.Trip.Fare)
+	}
+	if !resp.Trip.PausedAt.IsZero() || resp.Trip.PauseReason != "" {
+		t.Errorf("expected the pause cleared, got %+v", resp.Trip)
+	}
+}
+
+func TestTripAbort_HTTPValidation(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	tripService := newAbortTripService(env, service.AbortFareNone)
+	router := newTestRouter()
+	router.POST("/v1/trips/:id/abort", handler.NewTripHandler(tripService, nil).AbortTrip)
+
+	for _, body := range []string{
+		`{"aborted_by":"DISPATCH","reason":"vehicle breakdown"}`,
+		`{"aborted_by":"RIDER","reason":"  "}`,
+		`{"aborted_by":"RIDER"}`,
+	} {
+		if w := post(router, "/v1/trips/trip-1/abort", body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", body, w.Code)
+		}
+	}
+
+	w := post(router, "/v1/trips/trip-1/abort", `{"aborted_by":"DRIVER","reason":"vehicle breakdown"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp handler.TripResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Status != "ABORTED" || resp.AbortedBy != "DRIVER" || resp.AbortReason != "vehicle breakdown" || resp.Payment != nil {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+
+	// The mock trip is not updated by the transaction; end it to check the
+	// terminal-state guard.
+	trip := env.trips.GetTrip("trip-1")
+	trip.Status = domain.TripStatusEnded
+	trip.EndedAt = time.Now()
+	if w := post(router, "/v1/trips/trip-1/abort", `{"aborted_by":"RIDER","reason":"changed my mind"}`); w.Code != http.StatusConflict {
+		t.Errorf("expected 409 aborting an ended trip, got %d", w.Code)
+	}
+}
@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"context"
+	"database/sql/driver"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// TRIP AUTO-END SAFEGUARD
+// ──────────────────────────────────────────────
+
+const testMaxTripDuration = 6 * time.Hour
+
+// autoEndStart is when auto-end tests' trips start; sweeps pass a clock
+// relative to it.
+var autoEndStart = time.Date(2026, 3, 14, 8, 0, 0, 0, time.UTC)
+
+// newAutoEndTripService adds card trip-1, started at autoEndStart.
+func newAutoEndTripService(env *testEnv) *service.TripService {
+	env.rides.AddRide(&domain.Ride{
+		ID: "ride-1", RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 13.20, DestinationLng: 77.70,
+		Status: domain.RideStatusInTrip, AssignedDriverID: "driver-1", SurgeMultiplier: 1,
+		PaymentMethod: domain.PaymentMethodCard, Version: 2,
+	})
+	_ = env.trips.Create(context.Background(), &domain.Trip{
+		ID: "trip-1", RideID: "ride-1", DriverID: "driver-1", Status: domain.TripStatusStarted,
+		StartedAt: autoEndStart, Version: 1,
+	})
+	return service.NewTripService(env.tripDeps())
+}
+
+// sweepOverdue runs one auto-end pass with the clock at autoEndStart plus
+// elapsed.
+func sweepOverdue(t *testing.T, tripService *service.TripService, elapsed time.Duration) int {
+	t.Helper()
+
+	ended, err := tripService.AutoEndOverdue(context.Background(), autoEndStart.Add(elapsed), testMaxTripDuration)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return ended
+}
+
+func autoEndedNotifications(env *testEnv, recipientID string) int {
+	events, _ := env.notifications.ListSince(context.Background(), recipientID, 0, 100)
+	count := 0
+	for _, e := range events {
+		if e.Type == string(service.NotificationTripAutoEnded) {
+			count++
+		}
+	}
+	return count
+}
+
+func TestTripAutoEnd_OverdueTripEndedWithCappedFare(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	tripService := newAutoEndTripService(env)
+
+	if ended := sweepOverdue(t, tripService, 5*time.Hour); ended != 0 || len(env.rec.Queries()) != 0 {
+		t.Fatalf("expected a trip within the maximum left alone, ended %d", ended)
+	}
+
+	if ended := sweepOverdue(t, tripService, 9*time.Hour); ended != 1 {
+		t.Fatalf("expected the overdue trip auto-ended, ended %d", ended)
+	}
+
+	var update []driver.Value
+	var driverOnline bool
+	for _, q := range env.rec.Queries() {
+		if strings.Contains(q.Query, "UPDATE trips") {
+			update = q.Args
+		}
+		if strings.Contains(q.Query, "UPDATE drivers") && q.Args[0] == string(domain.DriverStatusOnline) {
+			driverOnline = true
+		}
+	}
+	if update == nil {
+		t.Fatal("expected the trip updated")
+	}
+
+	// Charged for 6 hours, not 9: 2.00 + 360 min x 0.50.
+	fare, _ := update[3].(float64)
+	if update[2] != string(domain.TripStatusEnded) || math.Abs(fare-182) > 0.01 || update[16] != true {
+		t.Errorf("expected the trip ENDED, auto-ended, with a fare of 182.00, got %v", update)
+	}
+	if ended, _ := update[5].(time.Time); !ended.Equal(autoEndStart.Add(9 * time.Hour)) {
+		t.Errorf("expected the trip ended at the sweep time, got %v", update[5])
+	}
+	if !driverOnline {
+		t.Error("expected the driver reset to ONLINE")
+	}
+	if updates := rideUpdateArgs(env.rec); len(updates) != 1 || updates[0][5] != string(domain.RideStatusCompleted) {
+		t.Errorf("expected the ride completed, got %v", updates)
+	}
+
+	if got := env.payments.CountPayments(); got != 1 {
+		t.Errorf("expected the capped fare charged, got %d payments", got)
+	}
+	if autoEndedNotifications(env, "rider-1") != 1 || autoEndedNotifications(env, "driver-1") != 1 {
+		t.Error("expected both the rider and the driver notified")
+	}
+}
+
+func TestTripAutoEnd_OnlyStartedTripsSwept(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	tripService := newAutoEndTripService(env)
+	trip := env.trips.GetTrip("trip-1")
+	trip.Status = domain.TripStatusPaused
+	trip.PausedAt = autoEndStart.Add(time.Hour)
+
+	if ended := sweepOverdue(t, tripService, 9*time.Hour); ended != 0 || env.payments.CountPayments() != 0 {
+		t.Errorf("expected a paused trip left for the driver to resume or end, ended %d", ended)
+	}
+}
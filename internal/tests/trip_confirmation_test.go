@@ -0,0 +1,275 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/middleware"
+	"ride/internal/repository"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// RIDER TRIP CONFIRMATION
+// ──────────────────────────────────────────────
+
+// newConfirmationTripService returns a trip service holding ended card
+// trips' fares for an hour for the rider to confirm.
+func newConfirmationTripService(env *testEnv) *service.TripService {
+	deps := env.tripDeps()
+	deps.PaymentService = env.paymentService()
+	deps.NotificationService = env.notificationService()
+	deps.ConfirmationWindow = time.Hour
+	return service.NewTripService(deps)
+}
+
+// addAwaitingTrip seeds rider-1's card trip, ended at endedAt with a 12.00
+// fare waiting for the rider, and its fare held on the card.
+func addAwaitingTrip(t *testing.T, env *testEnv, tripID string, endedAt time.Time) {
+	t.Helper()
+
+	rideID := "ride-" + tripID
+	env.rides.AddRide(&domain.Ride{
+		ID: rideID, RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 13.0, DestinationLng: 77.6,
+		Status: domain.RideStatusCompleted, AssignedDriverID: "driver-1", SurgeMultiplier: 1,
+		PaymentMethod: domain.PaymentMethodCard, CompletedAt: endedAt, Version: 3,
+	})
+	_ = env.trips.Create(context.Background(), &domain.Trip{
+		ID: tripID, RideID: rideID, DriverID: "driver-1", Status: domain.TripStatusEnded, Fare: 12,
+		StartedAt: endedAt.Add(-20 * time.Minute), EndedAt: endedAt, Confirmation: domain.TripConfirmationPending, Version: 2,
+	})
+
+	authID, err := env.psp.Authorize(context.Background(), "", 15)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = env.payments.Create(context.Background(), &domain.Payment{
+		ID: "payment-" + tripID, TripID: tripID, Amount: 15, Status: domain.PaymentStatusAuthorized,
+		IdempotencyKey: "payment:" + tripID, AuthID: authID,
+	})
+}
+
+// farePaymentStatus returns the status of a trip's fare payment.
+func farePaymentStatus(env *testEnv, tripID string) domain.PaymentStatus {
+	payment, _ := env.payments.GetByIdempotencyKey(context.Background(), "payment:"+tripID)
+	if payment == nil {
+		return ""
+	}
+	return payment.Status
+}
+
+// disputeNotifications counts the dispute notifications sent to ops.
+func disputeNotifications(env *testEnv) int {
+	events, _ := env.notifications.ListSince(context.Background(), service.OpsRecipientID, 0, 100)
+	count := 0
+	for _, e := range events {
+		if e.Type == string(service.NotificationTripDisputed) {
+			count++
+		}
+	}
+	return count
+}
+
+func TestTripConfirmation_EndTripHoldsCardFare(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	tripService := newConfirmationTripService(env)
+	addAuthRunningTrip(t, env, 15)
+	seedCashTrips(env, domain.PaymentMethodCash)
+
+	resp, err := tripService.EndTrip(context.Background(), service.EndTripRequest{TripID: "trip-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Trip.Confirmation != domain.TripConfirmationPending {
+		t.Errorf("expected the trip to wait for the rider, got %q", resp.Trip.Confirmation)
+	}
+	if resp.Payment == nil || resp.Payment.Status != domain.PaymentStatusAuthorized || resp.Receipt != nil {
+		t.Errorf("expected the AUTHORIZED hold and no receipt yet, got %+v and %+v", resp.Payment, resp.Receipt)
+	}
+	if len(env.psp.Captured) != 0 {
+		t.Errorf("expected nothing captured before the rider confirms, got %v", env.psp.Captured)
+	}
+
+	// Cash is collected by the driver; there is nothing to hold.
+	cash, err := tripService.EndTrip(context.Background(), service.EndTripRequest{TripID: "trip-CASH"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cash.Trip.Confirmation != "" || cash.Payment == nil || cash.Payment.Status != domain.PaymentStatusCashDue {
+		t.Errorf("expected a cash trip settled without waiting, got %q and %+v", cash.Trip.Confirmation, cash.Payment)
+	}
+}
+
+func TestTripConfirmation_ConfirmCaptures(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	tripService := newConfirmationTripService(env)
+	addAwaitingTrip(t, env, "trip-1", time.Now())
+
+	if _, err := tripService.ConfirmTrip(context.Background(), service.TripConfirmationRequest{TripID: "trip-1", RiderID: "rider-2"}); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for another rider, got %v", err)
+	}
+
+	resp, err := tripService.ConfirmTrip(context.Background(), service.TripConfirmationRequest{TripID: "trip-1", RiderID: "rider-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Payment == nil || resp.Payment.Status != domain.PaymentStatusSuccess || env.psp.Captured["auth-1"] != 12 {
+		t.Fatalf("expected 12.00 captured against the hold, got %+v and %v", resp.Payment, env.psp.Captured)
+	}
+	if trip, _ := env.trips.GetByID(context.Background(), "trip-1"); trip.Confirmation != domain.TripConfirmationConfirmed {
+		t.Errorf("expected the trip CONFIRMED, got %q", trip.Confirmation)
+	}
+
+	if _, err := tripService.ConfirmTrip(context.Background(), service.TripConfirmationRequest{TripID: "trip-1", RiderID: "rider-1"}); !errors.Is(err, service.ErrTripNotAwaitingConfirmation) {
+		t.Errorf("expected ErrTripNotAwaitingConfirmation confirming twice, got %v", err)
+	}
+}
+
+func TestTripConfirmation_DisputeHoldsPayment(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	tripService := newConfirmationTripService(env)
+	addAwaitingTrip(t, env, "trip-1", time.Now())
+
+	if _, err := tripService.DisputeTrip(context.Background(), service.TripConfirmationRequest{TripID: "trip-1", RiderID: "rider-1", Reason: " "}); !errors.Is(err, service.ErrDisputeReasonRequired) {
+		t.Errorf("expected ErrDisputeReasonRequired, got %v", err)
+	}
+
+	trip, err := tripService.DisputeTrip(context.Background(), service.TripConfirmationRequest{TripID: "trip-1", RiderID: "rider-1", Reason: "driver never arrived"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if trip.Confirmation != domain.TripConfirmationDisputed || trip.DisputeReason != "driver never arrived" {
+		t.Errorf("expected the trip DISPUTED with the reason, got %q %q", trip.Confirmation, trip.DisputeReason)
+	}
+	if status := farePaymentStatus(env, "trip-1"); status != domain.PaymentStatusAuthorized || len(env.psp.Captured) != 0 {
+		t.Errorf("expected the payment still only authorized, got %s and captures %v", status, env.psp.Captured)
+	}
+	if n := disputeNotifications(env); n != 1 {
+		t.Errorf("expected ops notified of the dispute once, got %d", n)
+	}
+
+	// A disputed trip waits for an admin, not the rider.
+	if _, err := tripService.ConfirmTrip(context.Background(), service.TripConfirmationRequest{TripID: "trip-1", RiderID: "rider-1"}); !errors.Is(err, service.ErrTripNotAwaitingConfirmation) {
+		t.Errorf("expected ErrTripNotAwaitingConfirmation confirming a disputed trip, got %v", err)
+	}
+	if n, _ := tripService.ConfirmLapsed(context.Background(), time.Now().Add(2*time.Hour)); n != 0 {
+		t.Errorf("expected a disputed trip left for the admin, got %d confirmed", n)
+	}
+}
+
+func TestTripConfirmation_ResolveDispute(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	tripService := newConfirmationTripService(env)
+	addAwaitingTrip(t, env, "trip-1", time.Now())
+	addAwaitingTrip(t, env, "trip-2", time.Now())
+
+	if _, err := tripService.ResolveDispute(context.Background(), service.ResolveDisputeRequest{TripID: "trip-1", Outcome: service.DisputeOutcomeCharge}); !errors.Is(err, service.ErrTripNotDisputed) {
+		t.Errorf("expected ErrTripNotDisputed for an undisputed trip, got %v", err)
+	}
+	for _, tripID := range []string{"trip-1", "trip-2"} {
+		if _, err := tripService.DisputeTrip(context.Background(), service.TripConfirmationRequest{TripID: tripID, RiderID: "rider-1", Reason: "wrong route"}); err != nil {
+			t.Fatalf("unexpected error disputing %s: %v", tripID, err)
+		}
+	}
+	if _, err := tripService.ResolveDispute(context.Background(), service.ResolveDisputeRequest{TripID: "trip-1", Outcome: "REFUND"}); !errors.Is(err, service.ErrInvalidDisputeOutcome) {
+		t.Errorf("expected ErrInvalidDisputeOutcome, got %v", err)
+	}
+
+	charged, err := tripService.ResolveDispute(context.Background(), service.ResolveDisputeRequest{TripID: "trip-1", Outcome: service.DisputeOutcomeCharge})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if charged.Trip.Confirmation != domain.TripConfirmationCharged || charged.Payment.Status != domain.PaymentStatusSuccess || env.psp.Captured["auth-1"] != 12 {
+		t.Errorf("expected trip-1 CHARGED with 12.00 captured, got %q, %+v and %v", charged.Trip.Confirmation, charged.Payment, env.psp.Captured)
+	}
+
+	voided, err := tripService.ResolveDispute(context.Background(), service.ResolveDisputeRequest{TripID: "trip-2", Outcome: service.DisputeOutcomeVoid})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if voided.Trip.Confirmation != domain.TripConfirmationVoided || voided.Trip.Fare != 0 || voided.Payment.Status != domain.PaymentStatusVoided {
+		t.Errorf("expected trip-2 VOIDED with no fare, got %q, %v and %+v", voided.Trip.Confirmation, voided.Trip.Fare, voided.Payment)
+	}
+	if len(env.psp.Voided) != 1 || env.psp.Voided[0] != "auth-2" {
+		t.Errorf("expected trip-2's hold released, got %v", env.psp.Voided)
+	}
+}
+
+func TestTripConfirmation_LapsedTripsConfirmed(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	tripService := newConfirmationTripService(env)
+	now := time.Now()
+	addAwaitingTrip(t, env, "trip-old", now.Add(-2*time.Hour))
+	addAwaitingTrip(t, env, "trip-new", now.Add(-10*time.Minute))
+
+	confirmed, err := tripService.ConfirmLapsed(context.Background(), now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if confirmed != 1 {
+		t.Fatalf("expected 1 lapsed trip confirmed, got %d", confirmed)
+	}
+	if status := farePaymentStatus(env, "trip-old"); status != domain.PaymentStatusSuccess {
+		t.Errorf("expected the lapsed trip's fare captured, got %s", status)
+	}
+	if status := farePaymentStatus(env, "trip-new"); status != domain.PaymentStatusAuthorized {
+		t.Errorf("expected the trip within the window still held, got %s", status)
+	}
+}
+
+func TestTripConfirmation_HTTP(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	tripService := newConfirmationTripService(env)
+	addAwaitingTrip(t, env, "trip-1", time.Now())
+
+	h := handler.NewTripHandler(tripService, nil)
+	router := newTestRouter()
+	router.Use(middleware.IdentityMiddleware(testAdminToken))
+	router.POST("/v1/trips/:id/confirm", h.ConfirmTrip)
+	router.POST("/v1/trips/:id/dispute", h.DisputeTrip)
+	router.POST("/v1/admin/trips/:id/resolve-dispute", h.ResolveDispute)
+
+	if w := postAs(router, "/v1/trips/trip-1/dispute", `{"reason": "never picked up"}`, "rider-2"); w.Code != http.StatusNotFound {
+		t.Errorf("another rider: expected 404, got %d", w.Code)
+	}
+	w := postAs(router, "/v1/trips/trip-1/dispute", `{"reason": "never picked up"}`, "rider-1")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var disputed handler.TripResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &disputed)
+	if disputed.Confirmation != string(domain.TripConfirmationDisputed) || disputed.DisputeReason != "never picked up" {
+		t.Errorf("expected a DISPUTED trip with the reason, got %+v", disputed)
+	}
+	if w := postAs(router, "/v1/trips/trip-1/confirm", "", "rider-1"); w.Code != http.StatusConflict {
+		t.Errorf("confirming a disputed trip: expected 409, got %d", w.Code)
+	}
+
+	w = postAs(router, "/v1/admin/trips/trip-1/resolve-dispute", `{"outcome": "CHARGE"}`, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resolved handler.TripResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resolved)
+	if resolved.Confirmation != string(domain.TripConfirmationCharged) || resolved.Payment == nil || resolved.Payment.Status != string(domain.PaymentStatusSuccess) {
+		t.Errorf("expected a CHARGED trip with a SUCCESS payment, got %+v", resolved)
+	}
+}
@@ -2,6 +2,9 @@ package tests
 
 import (
 	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -38,7 +41,7 @@ func TestTrip_CreatedOnlyAfterDriverAcceptsRide(t *testing.T) {
 	}
 	driverRepo.AddDriver(driver)
 
-	paymentService := service.NewPaymentService(paymentRepo, psp)
+	paymentService := service.NewPaymentService(paymentRepo, psp, nil, nil, nil, 0)
 
 	// We can't use the real TripService here as it requires *sql.DB
 	// But we can test the trip repo operations directly
@@ -290,7 +293,7 @@ func TestPayment_CreatedOnTripEnd(t *testing.T) {
 	paymentRepo := NewMockPaymentRepository()
 	psp := NewMockPSP()
 
-	paymentService := service.NewPaymentService(paymentRepo, psp)
+	paymentService := service.NewPaymentService(paymentRepo, psp, nil, nil, nil, 0)
 
 	req := service.ProcessPaymentRequest{
 		TripID: "trip-1",
@@ -325,7 +328,7 @@ func TestPayment_DuplicateWithSameIdempotencyKey_DoesNotCreateDuplicate(t *testi
 	paymentRepo := NewMockPaymentRepository()
 	psp := NewMockPSP()
 
-	paymentService := service.NewPaymentService(paymentRepo, psp)
+	paymentService := service.NewPaymentService(paymentRepo, psp, nil, nil, nil, 0)
 
 	req := service.ProcessPaymentRequest{
 		TripID: "trip-1",
@@ -355,6 +358,72 @@ func TestPayment_DuplicateWithSameIdempotencyKey_DoesNotCreateDuplicate(t *testi
 	}
 }
 
+func TestPayment_ExplicitIdempotencyKey_ChargesSeparatelyFromFare(t *testing.T) {
+	t.Parallel()
+
+	paymentRepo := NewMockPaymentRepository()
+	psp := NewMockPSP()
+
+	paymentService := service.NewPaymentService(paymentRepo, psp, nil, nil, nil, 0)
+
+	fare, err := paymentService.ProcessPayment(context.Background(), service.ProcessPaymentRequest{
+		TripID: "trip-1",
+		Amount: 15.0,
+	})
+	if err != nil {
+		t.Fatalf("fare payment failed: %v", err)
+	}
+
+	// A tip for the same trip is its own charge.
+	tipReq := service.ProcessPaymentRequest{
+		TripID:         "trip-1",
+		Amount:         3.0,
+		IdempotencyKey: "tip-1",
+	}
+	tip, err := paymentService.ProcessPayment(context.Background(), tipReq)
+	if err != nil {
+		t.Fatalf("tip payment failed: %v", err)
+	}
+	if tip.ID == fare.ID {
+		t.Fatal("expected the tip to be a separate payment")
+	}
+
+	// Retrying the tip with its key returns it without charging again.
+	retried, err := paymentService.ProcessPayment(context.Background(), tipReq)
+	if err != nil {
+		t.Fatalf("tip retry failed: %v", err)
+	}
+	if retried.ID != tip.ID {
+		t.Error("expected the same payment for a repeated key")
+	}
+
+	if paymentRepo.CountPayments() != 2 {
+		t.Errorf("expected 2 payments, got %d", paymentRepo.CountPayments())
+	}
+	if atomic.LoadInt32(&psp.ChargeCallCount) != 2 {
+		t.Errorf("expected 2 charges, got %d", psp.ChargeCallCount)
+	}
+}
+
+func TestPayment_OverlongIdempotencyKey_Rejected(t *testing.T) {
+	t.Parallel()
+
+	paymentRepo := NewMockPaymentRepository()
+	paymentService := service.NewPaymentService(paymentRepo, NewMockPSP(), nil, nil, nil, 0)
+
+	_, err := paymentService.ProcessPayment(context.Background(), service.ProcessPaymentRequest{
+		TripID:         "trip-1",
+		Amount:         3.0,
+		IdempotencyKey: strings.Repeat("k", 129),
+	})
+	if !errors.Is(err, service.ErrInvalidIdempotencyKey) {
+		t.Errorf("expected ErrInvalidIdempotencyKey, got %v", err)
+	}
+	if paymentRepo.CountPayments() != 0 {
+		t.Errorf("expected no payment, got %d", paymentRepo.CountPayments())
+	}
+}
+
 func TestPayment_PSPFailure_PaymentStatusFailed(t *testing.T) {
 	t.Parallel()
 
@@ -362,7 +431,7 @@ func TestPayment_PSPFailure_PaymentStatusFailed(t *testing.T) {
 	psp := NewMockPSP()
 	psp.ShouldFail = true // Configure PSP to fail
 
-	paymentService := service.NewPaymentService(paymentRepo, psp)
+	paymentService := service.NewPaymentService(paymentRepo, psp, nil, nil, nil, 0)
 
 	req := service.ProcessPaymentRequest{
 		TripID: "trip-1",
@@ -386,7 +455,7 @@ func TestPayment_RetryIsSafe(t *testing.T) {
 	paymentRepo := NewMockPaymentRepository()
 	psp := NewMockPSP()
 
-	paymentService := service.NewPaymentService(paymentRepo, psp)
+	paymentService := service.NewPaymentService(paymentRepo, psp, nil, nil, nil, 0)
 
 	req := service.ProcessPaymentRequest{
 		TripID: "trip-1",
@@ -424,7 +493,7 @@ func TestPayment_InvalidAmount_Rejected(t *testing.T) {
 	paymentRepo := NewMockPaymentRepository()
 	psp := NewMockPSP()
 
-	paymentService := service.NewPaymentService(paymentRepo, psp)
+	paymentService := service.NewPaymentService(paymentRepo, psp, nil, nil, nil, 0)
 
 	testCases := []struct {
 		name   string
@@ -458,7 +527,7 @@ func TestPayment_MissingTripID_Rejected(t *testing.T) {
 	paymentRepo := NewMockPaymentRepository()
 	psp := NewMockPSP()
 
-	paymentService := service.NewPaymentService(paymentRepo, psp)
+	paymentService := service.NewPaymentService(paymentRepo, psp, nil, nil, nil, 0)
 
 	req := service.ProcessPaymentRequest{
 		TripID: "", // Missing trip ID
@@ -478,7 +547,7 @@ func TestPayment_PSPError_PaymentStillCreated(t *testing.T) {
 	psp := NewMockPSP()
 	psp.SetFailure(false, ErrMockTimeout)
 
-	paymentService := service.NewPaymentService(paymentRepo, psp)
+	paymentService := service.NewPaymentService(paymentRepo, psp, nil, nil, nil, 0)
 
 	req := service.ProcessPaymentRequest{
 		TripID: "trip-1",
@@ -0,0 +1,120 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// TRIP PAUSE REASONS
+// ──────────────────────────────────────────────
+
+// seedPauseReasonTrip starts trip-1 on ride-1 for rider-1 and returns a
+// router serving pause and resume.
+func seedPauseReasonTrip(env *testEnv) *gin.Engine {
+	env.rides.AddRide(&domain.Ride{ID: "ride-1", RiderID: "rider-1", Status: domain.RideStatusInTrip, AssignedDriverID: "driver-1", Version: 2})
+	_ = env.trips.Create(context.Background(), &domain.Trip{
+		ID: "trip-1", RideID: "ride-1", DriverID: "driver-1", Status: domain.TripStatusStarted,
+		StartedAt: time.Now().Add(-10 * time.Minute), Version: 1,
+	})
+
+	tripHandler := handler.NewTripHandler(service.NewTripService(env.tripDeps()), nil)
+	router := newTestRouter()
+	router.POST("/v1/trips/:id/pause", tripHandler.PauseTrip)
+	router.POST("/v1/trips/:id/resume", tripHandler.ResumeTrip)
+	return router
+}
+
+// lastPaused returns the rider's most recent TRIP_PAUSED notification.
+func lastPaused(t *testing.T, env *testEnv) *domain.NotificationEvent {
+	t.Helper()
+
+	events, _ := env.notifications.ListSince(context.Background(), "rider-1", 0, 100)
+	for i := len(events) - 1; i >= 0; i-- {
+		if events[i].Type == string(service.NotificationTripPaused) {
+			return events[i]
+		}
+	}
+	t.Fatal("expected a TRIP_PAUSED notification")
+	return nil
+}
+
+func TestPauseReason_InResponseAndNotification(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	router := seedPauseReasonTrip(env)
+
+	w := post(router, "/v1/trips/trip-1/pause", `{"reason":"TRAFFIC"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp handler.TripResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Status != string(domain.TripStatusPaused) || resp.PauseReason != "TRAFFIC" {
+		t.Errorf("expected a paused trip with reason TRAFFIC, got %+v", resp)
+	}
+
+	event := lastPaused(t, env)
+	if !strings.Contains(event.Message, "traffic") {
+		t.Errorf("expected the reason in the message, got %q", event.Message)
+	}
+	if event.Data["reason"] != domain.PauseReasonTraffic {
+		t.Errorf("expected reason TRAFFIC in the payload, got %v", event.Data["reason"])
+	}
+
+	// Resuming clears the reason.
+	w = post(router, "/v1/trips/trip-1/resume", "")
+	var resumed handler.TripResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resumed)
+	if w.Code != http.StatusOK || resumed.PauseReason != "" {
+		t.Errorf("expected the reason cleared on resume, got %d %+v", w.Code, resumed)
+	}
+	if trip, _ := env.trips.GetByID(context.Background(), "trip-1"); trip.PauseReason != "" {
+		t.Errorf("expected no stored reason after resume, got %q", trip.PauseReason)
+	}
+}
+
+func TestPauseReason_Optional(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	router := seedPauseReasonTrip(env)
+
+	w := post(router, "/v1/trips/trip-1/pause", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 without a body, got %d: %s", w.Code, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "pause_reason") {
+		t.Errorf("expected no pause_reason, got %s", w.Body.String())
+	}
+	if msg := lastPaused(t, env).Message; msg != "Your trip has been paused by the driver." {
+		t.Errorf("expected the generic message, got %q", msg)
+	}
+}
+
+func TestPauseReason_UnknownRejected(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	router := seedPauseReasonTrip(env)
+
+	for _, body := range []string{`{"reason":"NAP"}`, `{"reason":"traffic"}`} {
+		if w := post(router, "/v1/trips/trip-1/pause", body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", body, w.Code)
+		}
+	}
+	if trip, _ := env.trips.GetByID(context.Background(), "trip-1"); trip.Status != domain.TripStatusStarted {
+		t.Errorf("expected the trip still started, got %s", trip.Status)
+	}
+}
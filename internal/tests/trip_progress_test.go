@@ -0,0 +1,161 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/domain"
+	"ride/internal/handler"
+	"ride/internal/middleware"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// TRIP PROGRESS AND ETA TO DESTINATION
+// ──────────────────────────────────────────────
+
+// Ride-1 runs due north from pickup to destination, about 11.1 km.
+const (
+	progressPickupLat      = 12.90
+	progressDestinationLat = 13.00
+	progressLng            = 77.60
+)
+
+// seedTripProgress starts trip-1 on ride-1 for rider-1, with driver-1 not
+// yet located, and returns a router serving the trip and pause/resume.
+func seedTripProgress(env *testEnv) *gin.Engine {
+	env.rides.AddRide(&domain.Ride{
+		ID: "ride-1", RiderID: "rider-1", Status: domain.RideStatusInTrip, AssignedDriverID: "driver-1", Version: 2,
+		PickupLat: progressPickupLat, PickupLng: progressLng, DestinationLat: progressDestinationLat, DestinationLng: progressLng,
+	})
+	_ = env.trips.Create(context.Background(), &domain.Trip{
+		ID: "trip-1", RideID: "ride-1", DriverID: "driver-1", Status: domain.TripStatusStarted,
+		StartedAt: time.Now().Add(-10 * time.Minute), Version: 1,
+	})
+
+	etaService := service.NewETAService(env.rides, env.locations, nil, NewMockTripETAStore(), 30)
+	tripHandler := handler.NewTripHandler(service.NewTripService(env.tripDeps()), etaService)
+
+	router := newTestRouter()
+	router.Use(middleware.IdentityMiddleware(testAdminToken))
+	router.GET("/v1/trips/:id", tripHandler.GetTrip)
+	router.POST("/v1/trips/:id/pause", tripHandler.PauseTrip)
+	router.POST("/v1/trips/:id/resume", tripHandler.ResumeTrip)
+	return router
+}
+
+// moveTo reports driver-1 at lat on the ride's line.
+func moveTo(env *testEnv, lat float64) {
+	_ = env.locations.UpdateLocation(context.Background(), "driver-1", lat, progressLng, 0)
+}
+
+// tripProgress fetches trip-1 as rider-1 and returns its progress, nil if
+// absent.
+func tripProgress(t *testing.T, router *gin.Engine) *handler.TripETAInfo {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/trips/trip-1", nil)
+	req.Header.Set("X-User-ID", "rider-1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp handler.TripResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	return resp.Progress
+}
+
+func TestTripProgress_MonotonicAlongTrack(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	router := seedTripProgress(env)
+
+	var last *handler.TripETAInfo
+	for step := 0; step <= 10; step++ {
+		moveTo(env, progressPickupLat+float64(step)*(progressDestinationLat-progressPickupLat)/10)
+		got := tripProgress(t, router)
+		if got == nil {
+			t.Fatalf("step %d: expected progress for a located driver", step)
+		}
+		if got.Paused {
+			t.Errorf("step %d: expected a started trip not flagged paused", step)
+		}
+		if last != nil && (got.ProgressPercent < last.ProgressPercent || got.RemainingKm > last.RemainingKm || got.ETASeconds > last.ETASeconds) {
+			t.Errorf("step %d: expected progress to only advance, went from %+v to %+v", step, *last, *got)
+		}
+		last = got
+	}
+
+	if last.ProgressPercent != 100 || last.RemainingKm != 0 || last.ETASeconds != 0 {
+		t.Errorf("expected the trip complete at the destination, got %+v", *last)
+	}
+
+	// Halfway, 5.56 km out at 30 km/h is 668 seconds.
+	moveTo(env, (progressPickupLat+progressDestinationLat)/2)
+	if got := tripProgress(t, router); got.ProgressPercent != 50 || got.RemainingKm != 5.56 || got.ETASeconds != 668 || got.ETAMinutes != 12 {
+		t.Errorf("unexpected progress halfway: %+v", *got)
+	}
+}
+
+func TestTripProgress_FrozenWhilePaused(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	router := seedTripProgress(env)
+	moveTo(env, 12.95)
+	live := tripProgress(t, router)
+
+	if w := post(router, "/v1/trips/trip-1/pause", ""); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	paused := tripProgress(t, router)
+	if paused == nil || !paused.Paused {
+		t.Fatalf("expected progress flagged paused, got %+v", paused)
+	}
+
+	// A detour to refuel during the pause leaves the ETA where it was.
+	moveTo(env, 12.92)
+	if got := tripProgress(t, router); *got != *paused {
+		t.Errorf("expected the ETA frozen at %+v, got %+v", *paused, *got)
+	}
+	if paused.RemainingKm != live.RemainingKm || paused.ETASeconds != live.ETASeconds {
+		t.Errorf("expected the ETA frozen at the pause (%+v), got %+v", *live, *paused)
+	}
+
+	if w := post(router, "/v1/trips/trip-1/resume", ""); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	resumed := tripProgress(t, router)
+	if resumed.Paused || resumed.RemainingKm <= paused.RemainingKm {
+		t.Errorf("expected the ETA live again from the detour, got %+v", *resumed)
+	}
+}
+
+func TestTripProgress_OmittedWithoutLocation(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	router := seedTripProgress(env)
+	if got := tripProgress(t, router); got != nil {
+		t.Errorf("expected no progress without a driver location, got %+v", *got)
+	}
+
+	moveTo(env, 12.95)
+	trip := env.trips.GetTrip("trip-1")
+	trip.Status = domain.TripStatusEnded
+	trip.EndedAt = time.Now()
+	_ = env.trips.Update(context.Background(), trip)
+	if got := tripProgress(t, router); got != nil {
+		t.Errorf("expected no progress for an ended trip, got %+v", *got)
+	}
+}
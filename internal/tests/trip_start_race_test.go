@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"ride/internal/domain"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// CONCURRENT TRIP START
+// ──────────────────────────────────────────────
+
+// newStartRaceService returns a trip service for ride-1, assigned to
+// driver-1, whose database lets only the first conditional start of the
+// ride through, as Postgres does once the row has left ASSIGNED.
+func newStartRaceService(t *testing.T) (*service.TripService, *RecordingDB) {
+	t.Helper()
+
+	db, rec := NewRecordingDB()
+	t.Cleanup(func() { _ = db.Close() })
+
+	var mu sync.Mutex
+	claimed := false
+	rec.RowsAffected = func(query string) int64 {
+		if !strings.Contains(query, "UPDATE rides") {
+			return 1
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if claimed {
+			return 0
+		}
+		claimed = true
+		return 1
+	}
+
+	rideRepo := NewMockRideRepository()
+	rideRepo.AddRide(&domain.Ride{
+		ID: "ride-1", RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 12.3, DestinationLng: 76.64,
+		Status: domain.RideStatusAssigned, AssignedDriverID: "driver-1", SurgeMultiplier: 1, Version: 1,
+	})
+
+	tripService := service.NewTripService(service.TripServiceDeps{
+		DB:         db,
+		TripRepo:   NewMockTripRepository(),
+		RideRepo:   rideRepo,
+		DriverRepo: NewMockDriverRepository(),
+	})
+	return tripService, rec
+}
+
+func TestStartTrip_ConcurrentAcceptsOneWins(t *testing.T) {
+	t.Parallel()
+
+	tripService, rec := newStartRaceService(t)
+
+	const accepts = 2
+	errs := make([]error, accepts)
+	var wg sync.WaitGroup
+	for i := range accepts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = tripService.StartTrip(context.Background(), service.StartTripRequest{
+				RideID: "ride-1", DriverID: "driver-1",
+			})
+		}()
+	}
+	wg.Wait()
+
+	succeeded, lost := 0, 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case errors.Is(err, service.ErrRideNotAssigned):
+			lost++
+		default:
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if succeeded != 1 || lost != 1 {
+		t.Errorf("expected 1 accept to win and 1 to get ErrRideNotAssigned, got %d and %d", succeeded, lost)
+	}
+
+	// Both accepts guarded the start on the ride still being assigned to them.
+	guarded := 0
+	for _, q := range rec.Queries() {
+		if strings.Contains(q.Query, "UPDATE rides") {
+			if !strings.Contains(q.Query, "status = $4 AND assigned_driver_id = $5") {
+				t.Errorf("expected a conditional start, got %q", q.Query)
+			}
+			if q.Args[3] != string(domain.RideStatusAssigned) || q.Args[4] != "driver-1" {
+				t.Errorf("expected the start conditioned on ASSIGNED to driver-1, got %v", q.Args)
+			}
+			guarded++
+		}
+	}
+	if guarded != accepts {
+		t.Errorf("expected %d conditional starts, got %d", accepts, guarded)
+	}
+}
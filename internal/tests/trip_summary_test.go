@@ -0,0 +1,138 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// TRIP COMPLETION SUMMARY
+// ──────────────────────────────────────────────
+
+// newSummaryTripService starts trip-1 on ride-1 (rider-1, 1.5x surge, card)
+// and ends trips with custom deep links. maxFare caps the fare; a short trip
+// stays under it.
+func newSummaryTripService(env *testEnv, maxFare float64) *service.TripService {
+	env.rides.AddRide(&domain.Ride{
+		ID: "ride-1", RiderID: "rider-1", PickupLat: 12.97, PickupLng: 77.59, DestinationLat: 12.3, DestinationLng: 76.64,
+		Status: domain.RideStatusInTrip, AssignedDriverID: "driver-1", SurgeMultiplier: 1.5,
+		PaymentMethod: domain.PaymentMethodCard, Version: 2,
+	})
+	_ = env.trips.Create(context.Background(), &domain.Trip{
+		ID: "trip-1", RideID: "ride-1", DriverID: "driver-1", Status: domain.TripStatusStarted,
+		StartedAt: time.Now().Add(-20 * time.Minute), TotalPaused: 5 * time.Minute, Version: 1,
+	})
+
+	notificationService := service.NewNotificationService(env.notifications, nil, service.DeepLinks{
+		Receipt:    "https://app.example/receipts/{receipt_id}",
+		RateDriver: "https://app.example/trips/{trip_id}/rate",
+	}, nil, nil, nil, nil)
+	deps := env.tripDeps()
+	deps.NotificationService = notificationService
+	deps.ReceiptService = service.NewReceiptService(notificationService, nil, nil, nil, nil)
+	deps.MaxFare = maxFare
+	return service.NewTripService(deps)
+}
+
+// tripEnded returns the rider's TRIP_ENDED payload.
+func tripEnded(t *testing.T, env *testEnv) map[string]any {
+	t.Helper()
+
+	events, _ := env.notifications.ListSince(context.Background(), "rider-1", 0, 100)
+	for _, e := range events {
+		if e.Type == string(service.NotificationTripEnded) {
+			return e.Data
+		}
+	}
+	t.Fatal("expected a TRIP_ENDED notification")
+	return nil
+}
+
+func TestTripSummary_FullPayload(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	tripService := newSummaryTripService(env, 0)
+
+	resp, err := tripService.EndTrip(context.Background(), service.EndTripRequest{TripID: "trip-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Payment == nil || resp.Receipt == nil {
+		t.Fatalf("expected a payment and receipt, got %+v", resp)
+	}
+
+	data := tripEnded(t, env)
+	if data["trip_id"] != "trip-1" || data["ride_id"] != "ride-1" || data["driver_id"] != "driver-1" {
+		t.Errorf("expected trip, ride and driver IDs, got %v", data)
+	}
+	if data["fare"] != resp.Trip.Fare || data["surge_multiplier"] != 1.5 {
+		t.Errorf("expected fare %.2f at 1.5x, got %v and %v", resp.Trip.Fare, data["fare"], data["surge_multiplier"])
+	}
+	// Twenty minutes less five paused.
+	if got, _ := data["duration_seconds"].(int64); got < 899 || got > 901 {
+		t.Errorf("expected about 900 seconds, got %v", data["duration_seconds"])
+	}
+	if got, _ := data["distance_km"].(float64); got < 80 || got > 130 {
+		t.Errorf("expected the pickup-destination distance, got %v", data["distance_km"])
+	}
+	if data["payment_method"] != domain.PaymentMethodCard || data["payment_status"] != domain.PaymentStatusSuccess {
+		t.Errorf("expected a successful card payment, got %v / %v", data["payment_method"], data["payment_status"])
+	}
+	if data["receipt_id"] != resp.Receipt.ID || data["receipt_url"] != "https://app.example/receipts/"+resp.Receipt.ID {
+		t.Errorf("expected a receipt deep link, got %v / %v", data["receipt_id"], data["receipt_url"])
+	}
+	if data["rate_driver_url"] != "https://app.example/trips/trip-1/rate" {
+		t.Errorf("expected a rating deep link, got %v", data["rate_driver_url"])
+	}
+}
+
+func TestTripSummary_HeldFareHasNoPaymentOrReceipt(t *testing.T) {
+	t.Parallel()
+
+	// A $1 cap holds every fare for review, so nothing is charged.
+	env := newTestEnv(t)
+	tripService := newSummaryTripService(env, 1)
+
+	resp, err := tripService.EndTrip(context.Background(), service.EndTripRequest{TripID: "trip-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Payment != nil || resp.Receipt != nil {
+		t.Fatalf("expected no payment or receipt, got %+v", resp)
+	}
+
+	data := tripEnded(t, env)
+	for _, key := range []string{"payment_status", "receipt_id", "receipt_url"} {
+		if _, ok := data[key]; ok {
+			t.Errorf("expected no %s without a payment or receipt, got %v", key, data[key])
+		}
+	}
+	if data["fare"] != 1.0 || data["rate_driver_url"] != "https://app.example/trips/trip-1/rate" {
+		t.Errorf("expected the capped fare and rating link, got %v", data)
+	}
+}
+
+func TestTripSummary_DefaultDeepLinks(t *testing.T) {
+	t.Parallel()
+
+	notifications := NewMockNotificationRepository()
+	notificationService := service.NewNotificationService(notifications, nil, service.DeepLinks{}, nil, nil, nil, nil)
+
+	_ = notificationService.NotifyTripEnded(context.Background(), service.TripSummary{TripID: "trip-9", RiderID: "rider-9", ReceiptID: "receipt-9"})
+
+	events, _ := notifications.ListSince(context.Background(), "rider-9", 0, 10)
+	if len(events) != 1 {
+		t.Fatalf("expected one notification, got %d", len(events))
+	}
+	if got := events[0].Data["receipt_url"]; got != "ride://receipts/receipt-9" {
+		t.Errorf("expected the default receipt link, got %v", got)
+	}
+	if got := events[0].Data["rate_driver_url"]; got != "ride://trips/trip-9/rate" {
+		t.Errorf("expected the default rating link, got %v", got)
+	}
+}
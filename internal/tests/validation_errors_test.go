@@ -0,0 +1,164 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"ride/internal/handler"
+	"ride/internal/service"
+	"ride/pkg/api"
+	"ride/pkg/rideclient"
+)
+
+// ──────────────────────────────────────────────
+// STRUCTURED VALIDATION ERRORS
+// ──────────────────────────────────────────────
+
+func newValidationRouter(t *testing.T) (*gin.Engine, *MockMatchingServiceForTest) {
+	t.Helper()
+
+	matching := NewMockMatchingServiceForTest()
+	rideService := service.NewRideService(service.RideServiceDeps{RideRepo: NewMockRideRepository(), MatchingService: matching})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/v1/rides", handler.NewRideHandler(rideService, nil, nil, nil).CreateRide)
+	router.POST("/v1/users/register", handler.NewUserHandler(NewMockUserRepository(), nil, "US").Register)
+	router.POST("/v1/drivers/register", handler.NewDriverHandler(nil, nil, NewMockDriverRepository(), "US", nil, nil).Register)
+	return router, matching
+}
+
+// postValidation posts body to path and returns the response's field
+// errors by field, failing unless it is a 422.
+func postValidation(t *testing.T, router *gin.Engine, path, body string) map[string]string {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("%s: expected 422, got %d: %s", path, w.Code, w.Body.String())
+	}
+
+	var resp handler.ValidationErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("%s: unexpected body: %s", path, w.Body.String())
+	}
+	fields := make(map[string]string, len(resp.Fields))
+	for _, f := range resp.Fields {
+		fields[f.Field] = f.Error
+	}
+	return fields
+}
+
+func TestValidationErrors_RideCreationReportsEveryField(t *testing.T) {
+	t.Parallel()
+
+	router, matching := newValidationRouter(t)
+	fields := postValidation(t, router, "/v1/rides", `{
+		"pickup_lat": 95, "pickup_lng": 77.59, "destination_lat": 13.0, "destination_lng": 181,
+		"payment_method": "BARTER", "tier": "LUXURY", "required_capabilities": ["hovercraft"]
+	}`)
+
+	want := map[string]string{
+		"rider_id":              service.ErrFieldRequired.Error(),
+		"pickup_lat":            service.ErrInvalidPickupLocation.Error(),
+		"destination_lng":       service.ErrInvalidDestinationLocation.Error(),
+		"payment_method":        service.ErrInvalidPaymentMethod.Error(),
+		"tier":                  service.ErrInvalidTier.Error(),
+		"required_capabilities": service.ErrInvalidCapability.Error(),
+	}
+	if len(fields) != len(want) {
+		t.Errorf("expected %d field errors, got %v", len(want), fields)
+	}
+	for field, message := range want {
+		if fields[field] != message {
+			t.Errorf("%s: expected %q, got %q", field, message, fields[field])
+		}
+	}
+	if matching.CallCount() != 0 {
+		t.Error("expected no matching for an invalid request")
+	}
+}
+
+func TestValidationErrors_RegistrationReportsEveryField(t *testing.T) {
+	t.Parallel()
+
+	router, _ := newValidationRouter(t)
+
+	fields := postValidation(t, router, "/v1/users/register", `{"phone": "12", "email": "not-an-email"}`)
+	if len(fields) != 3 || fields["name"] == "" || fields["phone"] != service.ErrInvalidPhone.Error() || fields["email"] != service.ErrInvalidEmail.Error() {
+		t.Errorf("user: expected name, phone and email errors, got %v", fields)
+	}
+
+	fields = postValidation(t, router, "/v1/drivers/register", `{"name": "Asha", "tier": "LUXURY", "email": "asha@", "capabilities": ["WAV", "jetpack"]}`)
+	want := map[string]string{
+		"phone":        service.ErrFieldRequired.Error(),
+		"tier":         service.ErrInvalidTier.Error(),
+		"email":        service.ErrInvalidEmail.Error(),
+		"capabilities": service.ErrInvalidCapability.Error(),
+	}
+	if len(fields) != len(want) {
+		t.Errorf("driver: expected %d field errors, got %v", len(want), fields)
+	}
+	for field, message := range want {
+		if fields[field] != message {
+			t.Errorf("driver %s: expected %q, got %q", field, message, fields[field])
+		}
+	}
+}
+
+func TestValidationErrors_MalformedBodyStillBadRequest(t *testing.T) {
+	t.Parallel()
+
+	router, _ := newValidationRouter(t)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/rides", strings.NewReader(`{"rider_id":`)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a body that is not JSON, got %d", w.Code)
+	}
+}
+
+func TestValidationErrors_ErrorsIsMatchesEachField(t *testing.T) {
+	t.Parallel()
+
+	var problems service.ValidationError
+	if problems.Err() != nil {
+		t.Fatal("expected no error before any problem is added")
+	}
+	problems.Add("tier", nil)
+	problems.Add("tier", service.ErrInvalidTier)
+	problems.Add("phone", service.ErrInvalidPhone)
+
+	err := problems.Err()
+	if !errors.Is(err, service.ErrInvalidTier) || !errors.Is(err, service.ErrInvalidPhone) {
+		t.Errorf("expected errors.Is to match each field error, got %v", err)
+	}
+	if got := err.Error(); got != "invalid request: tier: invalid tier; phone: invalid phone number" {
+		t.Errorf("unexpected message %q", got)
+	}
+}
+
+func TestValidationErrors_ClientExposesFields(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	server := newContractServer(t, env)
+	_, err := clientAs(server, "rider-1").CreateRide(context.Background(), api.CreateRideRequest{
+		RiderID: "rider-1", PickupLat: -91, PickupLng: 77.59, DestinationLat: 13.0, DestinationLng: 77.6, Tier: "LUXURY",
+	})
+
+	var apiErr *rideclient.Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("expected a 422 client error, got %v", err)
+	}
+	if len(apiErr.Fields) != 2 || apiErr.Fields[0].Field != "pickup_lat" || apiErr.Fields[1].Field != "tier" {
+		t.Errorf("expected pickup_lat and tier field errors, got %+v", apiErr.Fields)
+	}
+}
@@ -0,0 +1,177 @@
+package tests
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"ride/internal/domain"
+	"ride/internal/repository"
+	"ride/internal/repository/postgres"
+	"ride/internal/service"
+)
+
+// ──────────────────────────────────────────────
+// OPTIMISTIC CONCURRENCY
+// ──────────────────────────────────────────────
+
+func newVersionedRide() *domain.Ride {
+	return &domain.Ride{
+		ID:             "ride-1",
+		RiderID:        "rider-1",
+		PickupLat:      12.9716,
+		PickupLng:      77.5946,
+		DestinationLat: 12.2958,
+		DestinationLng: 76.6394,
+		Status:         domain.RideStatusRequested,
+		Version:        1,
+	}
+}
+
+func TestVersionConflict_InterleavedCancelAndAssign(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	rideRepo := NewMockRideRepository()
+	rideRepo.AddRide(newVersionedRide())
+
+	// Both flows read the same version.
+	cancelView, _ := rideRepo.GetByID(ctx, "ride-1")
+	assignView, _ := rideRepo.GetByID(ctx, "ride-1")
+
+	assignView.Status = domain.RideStatusAssigned
+	assignView.AssignedDriverID = "driver-1"
+	if err := rideRepo.Update(ctx, assignView); err != nil {
+		t.Fatalf("assign should win: %v", err)
+	}
+
+	cancelView.Status = domain.RideStatusCancelled
+	cancelView.CancelledAt = time.Now()
+	if err := rideRepo.Update(ctx, cancelView); !errors.Is(err, repository.ErrVersionConflict) {
+		t.Fatalf("expected stale cancel to get ErrVersionConflict, got %v", err)
+	}
+
+	stored, _ := rideRepo.GetByID(ctx, "ride-1")
+	if stored.Status != domain.RideStatusAssigned || stored.AssignedDriverID != "driver-1" {
+		t.Errorf("assignment was clobbered: %+v", stored)
+	}
+	if stored.Version != 2 {
+		t.Errorf("expected version 2, got %d", stored.Version)
+	}
+}
+
+func TestVersionConflict_CancelReloadsAfterConcurrentAssign(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	rideRepo := NewMockRideRepository()
+	rideRepo.AddRide(newVersionedRide())
+
+	// An assignment lands between CancelRide's read and its write.
+	rideRepo.BeforeUpdate = func(*domain.Ride) {
+		assigned, _ := rideRepo.GetByID(ctx, "ride-1")
+		assigned.Status = domain.RideStatusAssigned
+		assigned.AssignedDriverID = "driver-1"
+		if err := rideRepo.Update(ctx, assigned); err != nil {
+			t.Errorf("concurrent assign failed: %v", err)
+		}
+	}
+
+	rideService := service.NewRideService(service.RideServiceDeps{RideRepo: rideRepo, MatchingService: NewMockMatchingServiceForTest()})
+	ride, err := rideService.CancelRide(ctx, service.CancelRideRequest{RideID: "ride-1", CancelledBy: "rider-1"})
+	if err != nil {
+		t.Fatalf("expected cancel to succeed after reload, got %v", err)
+	}
+
+	// The retry saw the assignment, so the driver is not silently dropped.
+	if ride.Status != domain.RideStatusCancelled || ride.AssignedDriverID != "driver-1" {
+		t.Errorf("expected cancelled ride that still records driver-1, got %+v", ride)
+	}
+	if ride.Version != 3 {
+		t.Errorf("expected version 3 after assign and cancel, got %d", ride.Version)
+	}
+}
+
+func TestVersionConflict_TripPauseSurfacesConflict(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	tripRepo := NewMockTripRepository()
+	_ = tripRepo.Create(ctx, &domain.Trip{
+		ID:        "trip-1",
+		RideID:    "ride-1",
+		DriverID:  "driver-1",
+		Status:    domain.TripStatusStarted,
+		StartedAt: time.Now().Add(-10 * time.Minute),
+		Version:   1,
+	})
+
+	stale, _ := tripRepo.GetByID(ctx, "trip-1")
+	fresh, _ := tripRepo.GetByID(ctx, "trip-1")
+
+	fresh.Status = domain.TripStatusPaused
+	fresh.PausedAt = time.Now()
+	if err := tripRepo.Update(ctx, fresh); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stale.Status = domain.TripStatusEnded
+	stale.EndedAt = time.Now()
+	if err := tripRepo.Update(ctx, stale); !errors.Is(err, repository.ErrVersionConflict) {
+		t.Fatalf("expected ErrVersionConflict, got %v", err)
+	}
+}
+
+func TestRideRepository_Update_UsesVersionPredicate(t *testing.T) {
+	t.Parallel()
+
+	db, rec := NewRecordingDB()
+	defer db.Close()
+
+	ride := newVersionedRide()
+	if err := postgres.NewRideRepository(db).Update(context.Background(), ride); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ride.Version != 2 {
+		t.Errorf("expected version bump to 2, got %d", ride.Version)
+	}
+
+	update := rec.Queries()[0]
+	if !strings.Contains(update.Query, "AND version = $13") || !strings.Contains(update.Query, "version = version + 1") {
+		t.Errorf("expected versioned update, got: %s", update.Query)
+	}
+	if update.Args[12] != int64(1) {
+		t.Errorf("expected version arg 1, got %v", update.Args[12])
+	}
+}
+
+func TestRideRepository_Update_ZeroRows(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		exists  bool
+		wantErr error
+	}{
+		{true, repository.ErrVersionConflict},
+		{false, repository.ErrNotFound},
+	} {
+		db, rec := NewRecordingDB()
+		rec.RowsAffected = func(string) int64 { return 0 }
+		rec.Respond = func(query string, _ []driver.Value) ([]string, [][]driver.Value) {
+			return []string{"exists"}, [][]driver.Value{{tc.exists}}
+		}
+
+		ride := newVersionedRide()
+		err := postgres.NewRideRepository(db).Update(context.Background(), ride)
+		if !errors.Is(err, tc.wantErr) {
+			t.Errorf("exists=%v: expected %v, got %v", tc.exists, tc.wantErr, err)
+		}
+		if ride.Version != 1 {
+			t.Errorf("exists=%v: version must not change on failure, got %d", tc.exists, ride.Version)
+		}
+		db.Close()
+	}
+}
@@ -0,0 +1,21 @@
+// Package api holds the HTTP request and response bodies shared by the
+// service's handlers and the rideclient package, so the two cannot drift.
+package api
+
+// ErrorResponse represents an error response.
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// ValidationErrorResponse is the 422 response for a request with invalid
+// fields. Every problem found is listed, not just the first.
+type ValidationErrorResponse struct {
+	Error  string       `json:"error"`
+	Fields []FieldError `json:"fields"`
+}
+
+// FieldError is a problem with one request field.
+type FieldError struct {
+	Field string `json:"field"` // JSON name, e.g. pickup_lat
+	Error string `json:"error"`
+}
@@ -0,0 +1,28 @@
+package api
+
+// UpdateLocationRequest is the HTTP request body for updating driver location.
+type UpdateLocationRequest struct {
+	Lat     float64 `json:"lat"`
+	Lng     float64 `json:"lng"`
+	Heading float64 `json:"heading"` // Optional; degrees clockwise from north
+
+	// RecordedAt is when the device took the fix, as an RFC 3339 timestamp.
+	// Optional; without it the update is stamped when it arrives. Fixes
+	// that arrive late or twice are then kept out of the driver's path.
+	RecordedAt string `json:"recorded_at,omitempty"`
+}
+
+// AcceptRideRequest is the HTTP request body for accepting a ride.
+type AcceptRideRequest struct {
+	RideID           string `json:"ride_id"`
+	OverrideGeofence bool   `json:"override_geofence"` // Start away from the pickup point
+}
+
+// AcceptRideResponse is the HTTP response for accepting a ride.
+type AcceptRideResponse struct {
+	TripID    string `json:"trip_id"`
+	RideID    string `json:"ride_id"`
+	DriverID  string `json:"driver_id"`
+	Status    string `json:"status"`
+	StartedAt string `json:"started_at"`
+}
@@ -0,0 +1,36 @@
+package api
+
+// ProcessPaymentRequest is the HTTP request body for processing a payment.
+type ProcessPaymentRequest struct {
+	TripID string  `json:"trip_id"`
+	Amount float64 `json:"amount"`
+
+	// IdempotencyKey identifies a charge besides the trip's fare, such as a
+	// tip; omit it for the fare itself.
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+}
+
+// PaymentResponse is the HTTP response for payment operations.
+type PaymentResponse struct {
+	ID             string  `json:"id"`
+	TripID         string  `json:"trip_id"`
+	Amount         float64 `json:"amount"`
+	Fee            float64 `json:"fee,omitempty"`
+	Status         string  `json:"status"`
+	IdempotencyKey string  `json:"idempotency_key"`
+	InstrumentID   string  `json:"instrument_id,omitempty"`
+	FailureReason  string  `json:"failure_reason,omitempty"`
+	RetryHint      string  `json:"retry_hint,omitempty"` // How to recover a FAILED payment
+	CreatedAt      string  `json:"created_at,omitempty"`
+	UpdatedAt      string  `json:"updated_at,omitempty"`
+}
+
+// PaymentFailedResponse is the HTTP response when processing a payment
+// leaves it FAILED: 402 when it was declined, 503 when the payment provider
+// could not be reached.
+type PaymentFailedResponse struct {
+	Error         string          `json:"error"`
+	FailureReason string          `json:"failure_reason"`
+	RetryHint     string          `json:"retry_hint"`
+	Payment       PaymentResponse `json:"payment"`
+}
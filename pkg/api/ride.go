@@ -0,0 +1,100 @@
+package api
+
+// CreateRideRequest is the HTTP request body for creating a ride.
+type CreateRideRequest struct {
+	RiderID        string  `json:"rider_id"`
+	PickupLat      float64 `json:"pickup_lat"`
+	PickupLng      float64 `json:"pickup_lng"`
+	DestinationLat float64 `json:"destination_lat"`
+	DestinationLng float64 `json:"destination_lng"`
+	Tier           string  `json:"tier,omitempty"`           // A catalog tier, any case; defaults to the catalog's default
+	PaymentMethod  string  `json:"payment_method,omitempty"` // A catalog method, any case; defaults to the catalog's default
+	QuoteID        string  `json:"quote_id,omitempty"`       // From POST /v1/rides/estimate
+	RideType       string  `json:"ride_type,omitempty"`      // PASSENGER or PACKAGE, any case; defaults to PASSENGER
+
+	// RequiredCapabilities are vehicle capabilities the driver must have,
+	// any case, e.g. WAV for a wheelchair-accessible vehicle. Rides with an
+	// accessibility need are matched with priority.
+	RequiredCapabilities []string `json:"required_capabilities,omitempty"`
+
+	PaymentInstrumentID string   `json:"payment_instrument_id,omitempty"` // Defaults to the rider's default for payment_method
+	ExcludeDriverIDs    []string `json:"exclude_driver_ids,omitempty"`    // Drivers never to match, e.g. ones the rider blocked
+}
+
+// CreateRideResponse is the HTTP response for creating a ride.
+type CreateRideResponse struct {
+	ID                string                  `json:"id"`
+	RiderID           string                  `json:"rider_id"`
+	PickupLat         float64                 `json:"pickup_lat"`
+	PickupLng         float64                 `json:"pickup_lng"`
+	DestinationLat    float64                 `json:"destination_lat"`
+	DestinationLng    float64                 `json:"destination_lng"`
+	Status            string                  `json:"status"`
+	AssignedDriverID  string                  `json:"assigned_driver_id,omitempty"`
+	DriverAssigned    bool                    `json:"driver_assigned"`
+	SurgeMultiplier   float64                 `json:"surge_multiplier"`
+	SurgeActive       bool                    `json:"surge_active"`
+	SurchargeLabel    string                  `json:"surcharge_label,omitempty"`
+	SurchargeAmount   float64                 `json:"surcharge_amount,omitempty"`
+	EstimatedFareLow  float64                 `json:"estimated_fare_low,omitempty"` // Fare range expected when the ride was requested, surge and surcharge included
+	EstimatedFareHigh float64                 `json:"estimated_fare_high,omitempty"`
+	PaymentMethod     string                  `json:"payment_method"`
+	RideType          string                  `json:"ride_type,omitempty"`
+	Capabilities      []string                `json:"required_capabilities,omitempty"`
+	Priority          bool                    `json:"priority,omitempty"` // Matched before other waiting rides
+	QuoteID           string                  `json:"quote_id,omitempty"`
+	QuoteRejected     bool                    `json:"quote_rejected,omitempty"` // The quote was expired or invalid; surge was priced live
+	RebookedFrom      string                  `json:"rebooked_from,omitempty"`  // The ride this one repeats
+	Driver            *AssignedDriverResponse `json:"driver,omitempty"`
+}
+
+// GetRideResponse is the HTTP response for getting a ride.
+type GetRideResponse struct {
+	ID                string                  `json:"id"`
+	RiderID           string                  `json:"rider_id"`
+	PickupLat         float64                 `json:"pickup_lat"`
+	PickupLng         float64                 `json:"pickup_lng"`
+	DestinationLat    float64                 `json:"destination_lat"`
+	DestinationLng    float64                 `json:"destination_lng"`
+	Status            string                  `json:"status"`
+	AssignedDriverID  string                  `json:"assigned_driver_id,omitempty"`
+	SurgeMultiplier   float64                 `json:"surge_multiplier"`
+	SurgeActive       bool                    `json:"surge_active"`
+	SurchargeLabel    string                  `json:"surcharge_label,omitempty"`
+	SurchargeAmount   float64                 `json:"surcharge_amount,omitempty"`
+	EstimatedFareLow  float64                 `json:"estimated_fare_low,omitempty"` // Fare range expected when the ride was requested, surge and surcharge included
+	EstimatedFareHigh float64                 `json:"estimated_fare_high,omitempty"`
+	PaymentMethod     string                  `json:"payment_method"`
+	RideType          string                  `json:"ride_type,omitempty"`
+	Capabilities      []string                `json:"required_capabilities,omitempty"`
+	Priority          bool                    `json:"priority,omitempty"` // Matched before other waiting rides
+	QuoteID           string                  `json:"quote_id,omitempty"`
+	RebookedFrom      string                  `json:"rebooked_from,omitempty"`
+	CreatedAt         string                  `json:"created_at,omitempty"`
+	UpdatedAt         string                  `json:"updated_at,omitempty"`
+	RequestedAt       string                  `json:"requested_at,omitempty"`
+	AssignedAt        string                  `json:"assigned_at,omitempty"`
+	ArrivedAt         string                  `json:"arrived_at,omitempty"`
+	CompletedAt       string                  `json:"completed_at,omitempty"`
+	CancelledAt       string                  `json:"cancelled_at,omitempty"`
+	CancelReason      string                  `json:"cancel_reason,omitempty"`
+	Driver            *AssignedDriverResponse `json:"driver,omitempty"`
+	DriverDistanceKm  *float64                `json:"driver_distance_km,omitempty"` // To pickup while ASSIGNED, to destination while IN_TRIP
+
+	// While REQUESTED with no driver found yet: the ride's place among the
+	// rides waiting in its area, 1 being next, and the wait estimated from
+	// the area's recent matches, absent when there were none.
+	QueuePosition        *int `json:"queue_position,omitempty"`
+	EstimatedWaitSeconds *int `json:"estimated_wait_seconds,omitempty"`
+}
+
+// AssignedDriverResponse describes the assigned driver to the rider. The ETA
+// is present when the driver's location is known.
+type AssignedDriverResponse struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Tier         string `json:"tier"`
+	VehiclePlate string `json:"vehicle_plate,omitempty"`
+	ETASeconds   int    `json:"eta_seconds,omitempty"`
+	ETAMinutes   int    `json:"eta_minutes,omitempty"`
+}
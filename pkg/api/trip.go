@@ -0,0 +1,88 @@
+package api
+
+// TripResponse is the HTTP response for trip operations.
+type TripResponse struct {
+	TripID        string         `json:"trip_id"`
+	RideID        string         `json:"ride_id"`
+	DriverID      string         `json:"driver_id"`
+	Status        string         `json:"status"`
+	Fare          float64        `json:"fare"`
+	StartedAt     string         `json:"started_at"`
+	EndedAt       string         `json:"ended_at,omitempty"`
+	PausedAt      string         `json:"paused_at,omitempty"`
+	PauseReason   string         `json:"pause_reason,omitempty"`
+	TotalPaused   int64          `json:"total_paused_seconds"`
+	NeedsReview   bool           `json:"needs_review,omitempty"`
+	UncappedFare  float64        `json:"uncapped_fare,omitempty"` // Computed fare of a trip held for review
+	AbortedBy     string         `json:"aborted_by,omitempty"`
+	AbortReason   string         `json:"abort_reason,omitempty"`
+	AutoEnded     bool           `json:"auto_ended,omitempty"`     // Ended by the max-duration safeguard
+	Confirmation  string         `json:"confirmation,omitempty"`   // PENDING while the fare is held for the rider; CONFIRMED, DISPUTED, CHARGED or VOIDED after
+	DisputeReason string         `json:"dispute_reason,omitempty"` // The rider's reason, once disputed
+	Payment       *PaymentInfo   `json:"payment,omitempty"`
+	Receipt       *ReceiptInfo   `json:"receipt,omitempty"`
+	Split         *FareSplitInfo `json:"split,omitempty"`    // Each rider's share when the fare was split
+	Progress      *TripETAInfo   `json:"progress,omitempty"` // Live ETA to the destination while the trip is in progress
+	CreatedAt     string         `json:"created_at,omitempty"`
+	UpdatedAt     string         `json:"updated_at,omitempty"`
+}
+
+// PaymentInfo contains payment details in the response.
+type PaymentInfo struct {
+	ID            string  `json:"id"`
+	Amount        float64 `json:"amount"`
+	Fee           float64 `json:"fee,omitempty"`
+	Status        string  `json:"status"`
+	FailureReason string  `json:"failure_reason,omitempty"`
+	RetryHint     string  `json:"retry_hint,omitempty"` // How to recover a FAILED payment
+}
+
+// ReceiptInfo contains receipt details in the response.
+type ReceiptInfo struct {
+	ID               string  `json:"id"`
+	BaseFare         float64 `json:"base_fare"`
+	SurgeMultiplier  float64 `json:"surge_multiplier"`
+	SurgeAmount      float64 `json:"surge_amount"`
+	SurchargeLabel   string  `json:"surcharge_label,omitempty"`
+	SurchargeAmount  float64 `json:"surcharge_amount,omitempty"`
+	ProcessingFee    float64 `json:"processing_fee,omitempty"`
+	TotalFare        float64 `json:"total_fare"`
+	PaymentMethod    string  `json:"payment_method"`
+	PaymentStatus    string  `json:"payment_status"`
+	DurationMinutes  float64 `json:"duration_minutes"`
+	DistanceKm       float64 `json:"distance_km"`
+	SplitRiders      int     `json:"split_riders,omitempty"`    // Riders the fare was split between
+	ShareAmount      float64 `json:"share_amount,omitempty"`    // This rider's share of a split fare
+	AbsorbedAmount   float64 `json:"absorbed_amount,omitempty"` // Others' failed shares this rider paid
+	ShareAbsorbed    bool    `json:"share_absorbed,omitempty"`  // This rider's share failed and the ride's rider paid it
+	AdjustedFrom     float64 `json:"adjusted_from,omitempty"`   // The fare before an admin corrected it
+	AdjustmentReason string  `json:"adjustment_reason,omitempty"`
+}
+
+// TripETAInfo is how far an in-progress trip is from its destination, by
+// the driver's latest location. While the trip is PAUSED the figures are
+// frozen at the pause and Paused is set.
+type TripETAInfo struct {
+	RemainingKm     float64 `json:"remaining_km"`
+	ETASeconds      int     `json:"eta_seconds"`
+	ETAMinutes      int     `json:"eta_minutes"`
+	ProgressPercent int     `json:"progress_pct"` // Share of the pickup-to-destination distance covered
+	Paused          bool    `json:"paused,omitempty"`
+}
+
+// FareSplitInfo is a trip's fare split between riders.
+type FareSplitInfo struct {
+	TripID  string          `json:"trip_id"`
+	OwnerID string          `json:"owner_id"`
+	Shares  []FareShareInfo `json:"shares"`
+}
+
+// FareShareInfo is one rider's share of a split fare. Amount and PaymentID
+// are set once the trip has ended.
+type FareShareInfo struct {
+	RiderID   string  `json:"rider_id"`
+	Share     float64 `json:"share,omitempty"` // Relative weight; absent when split equally
+	Amount    float64 `json:"amount,omitempty"`
+	PaymentID string  `json:"payment_id,omitempty"`
+	Absorbed  bool    `json:"absorbed,omitempty"` // Failed to pay and charged to the ride's rider; needs follow-up
+}
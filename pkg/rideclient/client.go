@@ -0,0 +1,261 @@
+// Package rideclient is a typed HTTP client for the ride service. Request and
+// response bodies are the ones the service's handlers use, from pkg/api.
+package rideclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"ride/pkg/api"
+)
+
+const (
+	defaultTimeout      = 10 * time.Second
+	defaultMaxRetries   = 2
+	defaultRetryBackoff = 200 * time.Millisecond
+)
+
+// Config configures a Client. Zero values use the defaults: a 10s timeout
+// per call and 2 retries, 200ms apart, of idempotent calls that get a 5xx.
+type Config struct {
+	BaseURL      string       // e.g. http://ride.internal:8080
+	HTTPClient   *http.Client // Defaults to a new http.Client
+	UserID       string       // Sent as X-User-ID: the rider or driver calls are made for
+	AdminToken   string       // Sent as a bearer token when set
+	Timeout      time.Duration
+	MaxRetries   int // Negative disables retries
+	RetryBackoff time.Duration
+}
+
+// Client calls the ride service's HTTP API.
+type Client struct {
+	baseURL      string
+	httpClient   *http.Client
+	userID       string
+	adminToken   string
+	timeout      time.Duration
+	maxRetries   int
+	retryBackoff time.Duration
+}
+
+// New creates a new Client.
+func New(cfg Config) *Client {
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{}
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = defaultMaxRetries
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = defaultRetryBackoff
+	}
+	return &Client{
+		baseURL:      strings.TrimRight(cfg.BaseURL, "/"),
+		httpClient:   cfg.HTTPClient,
+		userID:       cfg.UserID,
+		adminToken:   cfg.AdminToken,
+		timeout:      cfg.Timeout,
+		maxRetries:   cfg.MaxRetries,
+		retryBackoff: cfg.RetryBackoff,
+	}
+}
+
+// Error is a non-2xx response from the service.
+type Error struct {
+	StatusCode int
+	Message    string // The response's error field
+
+	// FailureReason and RetryHint are set when a payment failed: 402 for a
+	// decline, 503 when the payment provider could not be reached.
+	FailureReason string
+	RetryHint     string
+
+	// Fields lists every invalid request field on a 422.
+	Fields []api.FieldError
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("ride service: %d %s", e.StatusCode, e.Message)
+}
+
+// CallOption adjusts a single call.
+type CallOption func(*callOptions)
+
+type callOptions struct {
+	idempotencyKey string
+	timeout        time.Duration
+}
+
+// WithIdempotencyKey sends the key as Idempotency-Key, so the service
+// replays its first response to any repeat. Calls with a key are retried
+// on 5xx like idempotent ones.
+func WithIdempotencyKey(key string) CallOption {
+	return func(o *callOptions) { o.idempotencyKey = key }
+}
+
+// WithTimeout bounds the call, including retries, overriding Config.Timeout.
+func WithTimeout(d time.Duration) CallOption {
+	return func(o *callOptions) { o.timeout = d }
+}
+
+// CreateRide requests a ride. POST /v1/rides
+func (c *Client) CreateRide(ctx context.Context, req api.CreateRideRequest, opts ...CallOption) (*api.CreateRideResponse, error) {
+	var resp api.CreateRideResponse
+	if err := c.do(ctx, http.MethodPost, "/v1/rides", req, &resp, false, opts); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetRide returns a ride's status. GET /v1/rides/:id
+func (c *Client) GetRide(ctx context.Context, rideID string, opts ...CallOption) (*api.GetRideResponse, error) {
+	var resp api.GetRideResponse
+	if err := c.do(ctx, http.MethodGet, "/v1/rides/"+url.PathEscape(rideID), nil, &resp, true, opts); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// UpdateDriverLocation reports a driver's position. Repeating it sets the
+// same position, so it is retried. POST /v1/drivers/:id/location
+func (c *Client) UpdateDriverLocation(ctx context.Context, driverID string, req api.UpdateLocationRequest, opts ...CallOption) error {
+	return c.do(ctx, http.MethodPost, "/v1/drivers/"+url.PathEscape(driverID)+"/location", req, nil, true, opts)
+}
+
+// AcceptRide has the driver accept a ride and start its trip.
+// POST /v1/drivers/:id/accept
+func (c *Client) AcceptRide(ctx context.Context, driverID string, req api.AcceptRideRequest, opts ...CallOption) (*api.AcceptRideResponse, error) {
+	var resp api.AcceptRideResponse
+	if err := c.do(ctx, http.MethodPost, "/v1/drivers/"+url.PathEscape(driverID)+"/accept", req, &resp, false, opts); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// EndTrip ends a trip and settles its fare. POST /v1/trips/:id/end
+func (c *Client) EndTrip(ctx context.Context, tripID string, opts ...CallOption) (*api.TripResponse, error) {
+	var resp api.TripResponse
+	if err := c.do(ctx, http.MethodPost, "/v1/trips/"+url.PathEscape(tripID)+"/end", nil, &resp, false, opts); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ProcessPayment charges for a trip, or charges a FAILED payment again. A
+// payment that fails returns an *Error with its FailureReason and RetryHint.
+// POST /v1/payments
+func (c *Client) ProcessPayment(ctx context.Context, req api.ProcessPaymentRequest, opts ...CallOption) (*api.PaymentResponse, error) {
+	var resp api.PaymentResponse
+	if err := c.do(ctx, http.MethodPost, "/v1/payments", req, &resp, false, opts); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// do sends the request, retrying 5xx responses of idempotent calls, and
+// decodes a 2xx body into out when out is non-nil.
+func (c *Client) do(ctx context.Context, method, path string, body, out any, idempotent bool, opts []CallOption) error {
+	o := callOptions{timeout: c.timeout}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	ctx, cancel := context.WithTimeout(ctx, o.timeout)
+	defer cancel()
+
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	retries := 0
+	if idempotent || o.idempotencyKey != "" {
+		retries = c.maxRetries
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := c.send(ctx, method, path, payload, out, o.idempotencyKey)
+		apiErr, ok := err.(*Error)
+		if !ok || apiErr.StatusCode < 500 || attempt == retries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(c.retryBackoff):
+		}
+	}
+}
+
+// send makes one attempt at the request.
+func (c *Client) send(ctx context.Context, method, path string, payload []byte, out any, idempotencyKey string) error {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.userID != "" {
+		req.Header.Set("X-User-ID", c.userID)
+	}
+	if c.adminToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.adminToken)
+	}
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		// PaymentFailedResponse and ValidationErrorResponse are each an
+		// ErrorResponse with more fields.
+		data, _ := io.ReadAll(resp.Body)
+		var errResp api.PaymentFailedResponse
+		_ = json.Unmarshal(data, &errResp)
+		if errResp.Error == "" {
+			errResp.Error = http.StatusText(resp.StatusCode)
+		}
+		var validationResp api.ValidationErrorResponse
+		if resp.StatusCode == http.StatusUnprocessableEntity {
+			_ = json.Unmarshal(data, &validationResp)
+		}
+		return &Error{
+			StatusCode:    resp.StatusCode,
+			Message:       errResp.Error,
+			FailureReason: errResp.FailureReason,
+			RetryHint:     errResp.RetryHint,
+			Fields:        validationResp.Fields,
+		}
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -5,6 +5,8 @@ CREATE TABLE IF NOT EXISTS users (
     id VARCHAR(36) PRIMARY KEY,
     name VARCHAR(100) NOT NULL,
     phone VARCHAR(20) NOT NULL UNIQUE,
+    email VARCHAR(254) NOT NULL DEFAULT '',
+    email_verified BOOLEAN NOT NULL DEFAULT FALSE,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
@@ -15,6 +17,8 @@ CREATE TABLE IF NOT EXISTS drivers (
     phone VARCHAR(20) NOT NULL UNIQUE,
     status VARCHAR(20) NOT NULL DEFAULT 'OFFLINE',
     tier VARCHAR(20) NOT NULL DEFAULT 'BASIC',
+    email VARCHAR(254) NOT NULL DEFAULT '',
+    email_verified BOOLEAN NOT NULL DEFAULT FALSE,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     CONSTRAINT drivers_status_check CHECK (status IN ('ONLINE', 'OFFLINE', 'ON_TRIP')),
     CONSTRAINT drivers_tier_check CHECK (tier IN ('BASIC', 'PREMIUM'))
@@ -35,6 +39,13 @@ CREATE TABLE IF NOT EXISTS rides (
     cancelled_at TIMESTAMP,
     cancel_reason TEXT,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    requested_at TIMESTAMP,
+    assigned_at TIMESTAMP,
+    completed_at TIMESTAMP,
+    version INTEGER NOT NULL DEFAULT 1,
+    surcharge_label VARCHAR(255) NOT NULL DEFAULT '',
+    surcharge_amount DOUBLE PRECISION NOT NULL DEFAULT 0,
+    quote_id VARCHAR(255) NOT NULL DEFAULT '',
     CONSTRAINT rides_status_check CHECK (status IN ('REQUESTED', 'ASSIGNED', 'IN_TRIP', 'COMPLETED', 'CANCELLED')),
     CONSTRAINT rides_surge_check CHECK (surge_multiplier >= 1.0 AND surge_multiplier <= 5.0),
     CONSTRAINT rides_payment_method_check CHECK (payment_method IN ('CASH', 'CARD', 'WALLET', 'UPI'))
@@ -51,6 +62,9 @@ CREATE TABLE IF NOT EXISTS trips (
     ended_at TIMESTAMP,
     paused_at TIMESTAMP,
     total_paused_seconds INTEGER DEFAULT 0,
+    version INTEGER NOT NULL DEFAULT 1,
+    needs_review BOOLEAN NOT NULL DEFAULT FALSE,
+    uncapped_fare DOUBLE PRECISION NOT NULL DEFAULT 0,
     CONSTRAINT trips_status_check CHECK (status IN ('STARTED', 'PAUSED', 'ENDED'))
 );
 
@@ -91,7 +105,106 @@ CREATE TABLE IF NOT EXISTS payments (
     status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
     idempotency_key VARCHAR(255) UNIQUE NOT NULL,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    CONSTRAINT payments_status_check CHECK (status IN ('PENDING', 'SUCCESS', 'FAILED'))
+    CONSTRAINT payments_status_check CHECK (status IN ('PENDING', 'SUCCESS', 'FAILED', 'REFUNDED', 'CASH_DUE'))
+);
+
+-- Notification outbox: every notification sent, used to replay missed
+-- server-sent events by Last-Event-ID
+CREATE TABLE IF NOT EXISTS notification_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    recipient_id VARCHAR(36) NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    title TEXT NOT NULL,
+    message TEXT NOT NULL,
+    data JSONB,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Driver incentive campaigns ("complete 10 trips today, earn a $20 bonus")
+CREATE TABLE IF NOT EXISTS campaigns (
+    id VARCHAR(36) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    criteria VARCHAR(20) NOT NULL,
+    target DOUBLE PRECISION NOT NULL,
+    reward_amount DOUBLE PRECISION NOT NULL,
+    tier VARCHAR(20),
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT campaigns_criteria_check CHECK (criteria IN ('TRIP_COUNT', 'TRIP_HOURS')),
+    CONSTRAINT campaigns_tier_check CHECK (tier IN ('BASIC', 'PREMIUM')),
+    CONSTRAINT campaigns_window_check CHECK (ends_at > starts_at)
+);
+
+-- Per-driver campaign progress; completed_at is set once, when the bonus is credited
+CREATE TABLE IF NOT EXISTS campaign_progress (
+    campaign_id VARCHAR(36) NOT NULL REFERENCES campaigns(id) ON DELETE CASCADE,
+    driver_id VARCHAR(36) NOT NULL REFERENCES drivers(id),
+    progress DOUBLE PRECISION NOT NULL DEFAULT 0,
+    completed_at TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (campaign_id, driver_id)
+);
+
+-- Trips already counted towards a campaign, so a retried EndTrip counts once
+CREATE TABLE IF NOT EXISTS campaign_trip_credits (
+    campaign_id VARCHAR(36) NOT NULL REFERENCES campaigns(id) ON DELETE CASCADE,
+    trip_id VARCHAR(36) NOT NULL REFERENCES trips(id),
+    driver_id VARCHAR(36) NOT NULL REFERENCES drivers(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (campaign_id, trip_id)
+);
+
+-- Driver earnings ledger; reference makes each credit idempotent
+CREATE TABLE IF NOT EXISTS driver_earnings (
+    id VARCHAR(36) PRIMARY KEY,
+    driver_id VARCHAR(36) NOT NULL REFERENCES drivers(id),
+    kind VARCHAR(30) NOT NULL,
+    amount DOUBLE PRECISION NOT NULL,
+    reference VARCHAR(200) NOT NULL UNIQUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Route deviation alerts raised while a trip is in progress
+CREATE TABLE IF NOT EXISTS route_deviation_alerts (
+    id VARCHAR(36) PRIMARY KEY,
+    trip_id VARCHAR(36) NOT NULL REFERENCES trips(id),
+    ride_id VARCHAR(36) NOT NULL REFERENCES rides(id),
+    driver_id VARCHAR(36) NOT NULL REFERENCES drivers(id),
+    lat DOUBLE PRECISION NOT NULL,
+    lng DOUBLE PRECISION NOT NULL,
+    distance_km DOUBLE PRECISION NOT NULL,
+    consecutive_pings INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Matching diagnostics: one row per Match call, successful or not
+CREATE TABLE IF NOT EXISTS match_attempts (
+    id VARCHAR(36) PRIMARY KEY,
+    ride_id VARCHAR(36) NOT NULL,
+    tier VARCHAR(20) NOT NULL DEFAULT '',
+    radius_km DOUBLE PRECISION NOT NULL,
+    candidates_found INTEGER NOT NULL DEFAULT 0,
+    skipped_offline INTEGER NOT NULL DEFAULT 0,
+    skipped_tier INTEGER NOT NULL DEFAULT 0,
+    skipped_locked INTEGER NOT NULL DEFAULT 0,
+    skipped_stale INTEGER NOT NULL DEFAULT 0,
+    outcome VARCHAR(20) NOT NULL,
+    assigned_driver_id VARCHAR(36) NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Driver location history: append-only positions for replay and analytics,
+-- pruned after LOCATION_HISTORY_RETENTION
+CREATE TABLE IF NOT EXISTS driver_location_history (
+    id BIGSERIAL PRIMARY KEY,
+    driver_id VARCHAR(36) NOT NULL,
+    lat DOUBLE PRECISION NOT NULL,
+    lng DOUBLE PRECISION NOT NULL,
+    heading DOUBLE PRECISION NOT NULL DEFAULT 0,
+    recorded_at TIMESTAMP NOT NULL
 );
 
 -- ============================================
@@ -109,6 +222,8 @@ CREATE INDEX IF NOT EXISTS idx_drivers_status_tier ON drivers(status, tier);
 -- Partial index for ONLINE drivers only (smaller, faster for matching)
 CREATE INDEX IF NOT EXISTS idx_drivers_online ON drivers(id) WHERE status = 'ONLINE';
 CREATE INDEX IF NOT EXISTS idx_drivers_online_tier ON drivers(id, tier) WHERE status = 'ONLINE';
+-- Prefix search on phone (LIKE 'prefix%') without a full scan
+CREATE INDEX IF NOT EXISTS idx_drivers_phone_prefix ON drivers(phone varchar_pattern_ops);
 
 -- Rides indexes
 CREATE INDEX IF NOT EXISTS idx_rides_status ON rides(status);
@@ -130,6 +245,8 @@ CREATE INDEX IF NOT EXISTS idx_trips_status ON trips(status);
 CREATE INDEX IF NOT EXISTS idx_trips_driver_status ON trips(driver_id, status);
 -- Partial index for active trips only
 CREATE INDEX IF NOT EXISTS idx_trips_active ON trips(driver_id) WHERE status != 'ENDED';
+-- Recently ended trips, for duration estimates from history
+CREATE INDEX IF NOT EXISTS idx_trips_ended ON trips(ended_at DESC) WHERE status = 'ENDED';
 
 -- Payments indexes
 CREATE INDEX IF NOT EXISTS idx_payments_trip ON payments(trip_id);
@@ -142,9 +259,513 @@ CREATE INDEX IF NOT EXISTS idx_receipts_rider ON receipts(rider_id);
 CREATE INDEX IF NOT EXISTS idx_receipts_driver ON receipts(driver_id);
 CREATE INDEX IF NOT EXISTS idx_receipts_created ON receipts(created_at DESC);
 
+-- Notification outbox indexes
+-- Replay scans one recipient's events after a given ID
+CREATE INDEX IF NOT EXISTS idx_notification_outbox_recipient ON notification_outbox(recipient_id, id);
+
+-- Campaign indexes
+-- Active campaign lookup when a trip ends
+CREATE INDEX IF NOT EXISTS idx_campaigns_window ON campaigns(starts_at, ends_at);
+CREATE INDEX IF NOT EXISTS idx_campaign_progress_driver ON campaign_progress(driver_id);
+
+-- Driver earnings indexes
+CREATE INDEX IF NOT EXISTS idx_driver_earnings_driver ON driver_earnings(driver_id, created_at);
+
+-- Route deviation alert indexes
+CREATE INDEX IF NOT EXISTS idx_route_deviation_alerts_trip ON route_deviation_alerts(trip_id, created_at);
+
+-- Match attempt indexes
+CREATE INDEX IF NOT EXISTS idx_match_attempts_ride ON match_attempts(ride_id, created_at);
+
+-- Location history indexes (track queries and retention pruning)
+CREATE INDEX IF NOT EXISTS idx_driver_location_history_driver ON driver_location_history(driver_id, recorded_at);
+CREATE INDEX IF NOT EXISTS idx_driver_location_history_recorded ON driver_location_history(recorded_at);
+
+-- Trips held for fare review
+CREATE INDEX IF NOT EXISTS idx_trips_needs_review ON trips(ended_at) WHERE needs_review;
+
 -- ============================================
 -- VERSION COLUMN FOR OPTIMISTIC LOCKING
 -- ============================================
--- Add version column to rides for optimistic locking (run as ALTER if table exists)
--- ALTER TABLE rides ADD COLUMN IF NOT EXISTS version INTEGER DEFAULT 1;
--- ALTER TABLE drivers ADD COLUMN IF NOT EXISTS version INTEGER DEFAULT 1;
\ No newline at end of file
+-- Rides and trips are updated with "WHERE id = $n AND version = $m" and
+-- version + 1; a zero-row update is reported as a version conflict.
+-- Existing databases pick up the column here.
+ALTER TABLE rides ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE trips ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+-- ALTER TABLE drivers ADD COLUMN IF NOT EXISTS version INTEGER DEFAULT 1;
+
+-- ============================================
+-- RIDE LIFECYCLE TIMESTAMPS
+-- ============================================
+-- requested_at, assigned_at and completed_at are set at their transitions;
+-- the trip's started_at marks when the vehicle actually moved off.
+-- Existing rides take requested_at from created_at.
+ALTER TABLE rides ADD COLUMN IF NOT EXISTS requested_at TIMESTAMP;
+ALTER TABLE rides ADD COLUMN IF NOT EXISTS assigned_at TIMESTAMP;
+ALTER TABLE rides ADD COLUMN IF NOT EXISTS completed_at TIMESTAMP;
+UPDATE rides SET requested_at = created_at WHERE requested_at IS NULL;
+
+-- ============================================
+-- FARE REVIEW
+-- ============================================
+-- Fares above the maximum cap are clamped and held with needs_review until
+-- an admin approves them; uncapped_fare keeps the computed amount.
+ALTER TABLE trips ADD COLUMN IF NOT EXISTS needs_review BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE trips ADD COLUMN IF NOT EXISTS uncapped_fare DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+-- ============================================
+-- CASH COLLECTION
+-- ============================================
+-- Cash payments start as CASH_DUE and move to SUCCESS once the driver
+-- confirms collecting the cash.
+ALTER TABLE payments DROP CONSTRAINT IF EXISTS payments_status_check;
+ALTER TABLE payments ADD CONSTRAINT payments_status_check
+    CHECK (status IN ('PENDING', 'SUCCESS', 'FAILED', 'REFUNDED', 'CASH_DUE'));
+
+-- ============================================
+-- DEGRADED MATCHING
+-- ============================================
+-- Marks match attempts that ran from the database because Redis was down.
+ALTER TABLE match_attempts ADD COLUMN IF NOT EXISTS degraded BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- ============================================
+-- ZONE SURCHARGES
+-- ============================================
+-- Rides picking up or dropping off in a surcharge zone (e.g. an airport)
+-- carry a flat surcharge that is added to the fare and shown on the receipt.
+ALTER TABLE rides ADD COLUMN IF NOT EXISTS surcharge_label VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE rides ADD COLUMN IF NOT EXISTS surcharge_amount DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+-- ============================================
+-- EMAIL ADDRESSES
+-- ============================================
+-- Optional email addresses, unique when present; only verified ones
+-- receive email.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email VARCHAR(254) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE drivers ADD COLUMN IF NOT EXISTS email VARCHAR(254) NOT NULL DEFAULT '';
+ALTER TABLE drivers ADD COLUMN IF NOT EXISTS email_verified BOOLEAN NOT NULL DEFAULT FALSE;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users(email) WHERE email <> '';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_drivers_email ON drivers(email) WHERE email <> '';
+
+-- ============================================
+-- RIDE QUOTES
+-- ============================================
+-- The signed quote whose surge priced the ride, kept for auditing; empty
+-- when the ride was priced live.
+ALTER TABLE rides ADD COLUMN IF NOT EXISTS quote_id VARCHAR(255) NOT NULL DEFAULT '';
+
+-- ============================================
+-- TRIP PAUSE REASONS
+-- ============================================
+-- Why the driver paused the trip (TRAFFIC, FUEL, RIDER_REQUEST, OTHER);
+-- empty when no reason was given or the trip is not paused.
+ALTER TABLE trips ADD COLUMN IF NOT EXISTS pause_reason VARCHAR(20) NOT NULL DEFAULT '';
+
+-- ============================================
+-- DRIVER BREAKS
+-- ============================================
+-- Drivers on a break keep their map position but receive no offers.
+ALTER TABLE drivers DROP CONSTRAINT IF EXISTS drivers_status_check;
+ALTER TABLE drivers ADD CONSTRAINT drivers_status_check CHECK (status IN ('ONLINE', 'OFFLINE', 'ON_TRIP', 'BREAK'));
+
+-- ============================================
+-- DRIVER VEHICLE PLATES
+-- ============================================
+-- Shown to the rider once the driver is assigned; empty when not provided.
+ALTER TABLE drivers ADD COLUMN IF NOT EXISTS vehicle_plate VARCHAR(20) NOT NULL DEFAULT '';
+
+-- ============================================
+-- ENTITY TIMESTAMPS
+-- ============================================
+-- created_at is set on insert and updated_at on every write, for sorting
+-- and auditing.
+ALTER TABLE trips ADD COLUMN IF NOT EXISTS created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE drivers ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE rides ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE trips ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+UPDATE drivers SET created_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;
+UPDATE rides SET created_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;
+UPDATE payments SET created_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;
+
+-- ============================================
+-- TRIP ABORTS
+-- ============================================
+-- Trips ended before the destination, e.g. after a breakdown. aborted_by is
+-- RIDER or DRIVER; both columns are empty for other trips. An aborted trip
+-- no longer counts as the driver's active trip.
+ALTER TABLE trips DROP CONSTRAINT IF EXISTS trips_status_check;
+ALTER TABLE trips ADD CONSTRAINT trips_status_check CHECK (status IN ('STARTED', 'PAUSED', 'ENDED', 'ABORTED'));
+ALTER TABLE trips ADD COLUMN IF NOT EXISTS aborted_by VARCHAR(10) NOT NULL DEFAULT '';
+ALTER TABLE trips ADD COLUMN IF NOT EXISTS abort_reason TEXT NOT NULL DEFAULT '';
+DROP INDEX IF EXISTS idx_trips_active_driver;
+CREATE UNIQUE INDEX idx_trips_active_driver
+ON trips (driver_id)
+WHERE status NOT IN ('ENDED', 'ABORTED');
+
+-- ============================================
+-- TRIP AUTO-END
+-- ============================================
+-- Set on trips the safeguard sweeper ended after they ran past
+-- TRIP_MAX_DURATION; their fare covers only that duration.
+ALTER TABLE trips ADD COLUMN IF NOT EXISTS auto_ended BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- ============================================
+-- PAYMENT INSTRUMENTS
+-- ============================================
+-- Cards, wallets and UPI accounts users keep on file. token is the PSP's
+-- token and is never returned by the API. A non-CASH ride is charged to the
+-- instrument recorded on it; instrument_id is empty for cash and for rides
+-- and payments made before instruments existed.
+CREATE TABLE IF NOT EXISTS payment_instruments (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    token TEXT NOT NULL,
+    masked_details VARCHAR(100) NOT NULL,
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT payment_instruments_type_check CHECK (type IN ('CARD', 'WALLET', 'UPI'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_instruments_user ON payment_instruments (user_id, created_at);
+
+-- At most one default per user and type.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payment_instruments_default
+ON payment_instruments (user_id, type)
+WHERE is_default;
+
+ALTER TABLE rides ADD COLUMN IF NOT EXISTS instrument_id VARCHAR(36) NOT NULL DEFAULT '';
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS instrument_id VARCHAR(36) NOT NULL DEFAULT '';
+
+-- ============================================
+-- PAYMENT METHOD AND TIER CATALOG
+-- ============================================
+-- Payment methods and tiers are configured per market (CATALOG_FILE) and
+-- validated by the application, so the columns no longer pin them. A ride
+-- records its requested tier for tier-specific fares; empty means any tier
+-- or a ride requested before tiers were recorded.
+ALTER TABLE drivers DROP CONSTRAINT IF EXISTS drivers_tier_check;
+ALTER TABLE rides DROP CONSTRAINT IF EXISTS rides_payment_method_check;
+ALTER TABLE campaigns DROP CONSTRAINT IF EXISTS campaigns_tier_check;
+ALTER TABLE payment_instruments DROP CONSTRAINT IF EXISTS payment_instruments_type_check;
+ALTER TABLE payment_instruments ADD CONSTRAINT payment_instruments_type_check CHECK (type <> 'CASH');
+
+ALTER TABLE rides ADD COLUMN IF NOT EXISTS tier VARCHAR(20) NOT NULL DEFAULT '';
+
+-- ============================================
+-- ADMIN OPS MAP
+-- ============================================
+-- Open requests inside a bounding box, by pickup.
+CREATE INDEX IF NOT EXISTS idx_rides_open_pickup
+ON rides (pickup_lat, pickup_lng)
+WHERE status IN ('REQUESTED', 'ASSIGNED');
+
+-- ============================================
+-- MATCH EXCLUSIONS
+-- ============================================
+-- Candidates passed over because the ride excludes them, e.g. a driver the
+-- rider blocked or one who cancelled the ride before.
+ALTER TABLE match_attempts ADD COLUMN IF NOT EXISTS skipped_excluded INTEGER NOT NULL DEFAULT 0;
+
+-- ============================================
+-- PAYMENT PROCESSING FEES
+-- ============================================
+-- The processor fee passed on for the payment method (PAYMENT_FEES),
+-- already included in amount; 0 for cash and fee-free methods.
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS fee DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+-- ============================================
+-- RIDE REBOOKING
+-- ============================================
+-- The cancelled or completed ride a rider repeated with one tap; empty for
+-- rides requested from scratch.
+ALTER TABLE rides ADD COLUMN IF NOT EXISTS rebooked_from VARCHAR(36) NOT NULL DEFAULT '';
+
+-- ============================================
+-- DRIVER ARRIVAL
+-- ============================================
+-- When the assigned driver's pings first stayed within TRIP_ARRIVAL_RADIUS_KM
+-- of the pickup point; NULL until then.
+ALTER TABLE rides ADD COLUMN IF NOT EXISTS arrived_at TIMESTAMP;
+
+-- ============================================
+-- FEATURE FLAGS
+-- ============================================
+-- Gradual rollouts, flipped at runtime from /v1/admin/flags. A flag is on
+-- for a user when enabled, the deployment's city (FEATURE_FLAGS_CITY) is in
+-- cities or cities is empty, and the user's hash bucket is below percentage.
+CREATE TABLE IF NOT EXISTS feature_flags (
+    name VARCHAR(100) PRIMARY KEY,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    percentage INTEGER NOT NULL DEFAULT 0,
+    cities JSONB NOT NULL DEFAULT '[]',
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT feature_flags_percentage_check CHECK (percentage BETWEEN 0 AND 100)
+);
+
+-- ============================================
+-- NOTIFICATION PREFERENCES
+-- ============================================
+-- Notification types a rider or driver turned on or off per channel (PUSH,
+-- EMAIL). Types and channels without a row are on.
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    recipient_id VARCHAR(36) NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    enabled BOOLEAN NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (recipient_id, type, channel)
+);
+
+-- ============================================
+-- DRIVER DESTINATION MODE
+-- ============================================
+-- Candidates passed over because they are heading to a destination and the
+-- ride goes another way.
+ALTER TABLE match_attempts ADD COLUMN IF NOT EXISTS skipped_direction INTEGER NOT NULL DEFAULT 0;
+
+-- ============================================
+-- CACHE INVALIDATION
+-- ============================================
+-- Every instance LISTENs on cache_invalidation and drops its cached copy of
+-- the driver or ride named in the "<table>:<id>" payload.
+CREATE OR REPLACE FUNCTION notify_cache_invalidation() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        PERFORM pg_notify('cache_invalidation', TG_TABLE_NAME || ':' || OLD.id);
+    ELSE
+        PERFORM pg_notify('cache_invalidation', TG_TABLE_NAME || ':' || NEW.id);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS drivers_cache_invalidation ON drivers;
+CREATE TRIGGER drivers_cache_invalidation
+    AFTER UPDATE OR DELETE ON drivers
+    FOR EACH ROW EXECUTE FUNCTION notify_cache_invalidation();
+
+DROP TRIGGER IF EXISTS rides_cache_invalidation ON rides;
+CREATE TRIGGER rides_cache_invalidation
+    AFTER UPDATE OR DELETE ON rides
+    FOR EACH ROW EXECUTE FUNCTION notify_cache_invalidation();
+
+-- ============================================
+-- PAYMENT FAILURE REASONS
+-- ============================================
+-- Why a FAILED payment failed, shown to the rider with a retry hint: the
+-- PSP's decline reason, or that the provider could not be reached.
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS failure_reason VARCHAR(255) NOT NULL DEFAULT '';
+
+-- ============================================
+-- PACKAGE DELIVERY
+-- ============================================
+-- PASSENGER rides carry the rider; PACKAGE rides carry a parcel and need a
+-- proof-of-delivery photo before the trip can end.
+ALTER TABLE rides ADD COLUMN IF NOT EXISTS ride_type VARCHAR(20) NOT NULL DEFAULT 'PASSENGER';
+
+-- Photos drivers attach to trips. The image is in the blob store
+-- (BLOB_STORE) under blob_key; the rider fetches it through a signed,
+-- expiring URL.
+CREATE TABLE IF NOT EXISTS trip_attachments (
+    id VARCHAR(36) PRIMARY KEY,
+    trip_id VARCHAR(36) NOT NULL REFERENCES trips(id),
+    uploaded_by VARCHAR(36) NOT NULL,
+    blob_key VARCHAR(255) NOT NULL,
+    content_type VARCHAR(50) NOT NULL,
+    size_bytes INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_trip_attachments_trip ON trip_attachments (trip_id, created_at);
+
+-- ============================================
+-- DRIVER WEEKLY SUMMARIES
+-- ============================================
+-- Each driver's trips, net earnings and estimated online hours for a week
+-- (Monday to Monday UTC), ranked within the city. Regenerating a week
+-- replaces its rows; notified_at records that the driver was sent theirs.
+CREATE TABLE IF NOT EXISTS driver_weekly_summaries (
+    driver_id VARCHAR(36) NOT NULL REFERENCES drivers(id),
+    week_start TIMESTAMP NOT NULL,
+    city VARCHAR(100) NOT NULL DEFAULT '',
+    trips INTEGER NOT NULL DEFAULT 0,
+    earnings DOUBLE PRECISION NOT NULL DEFAULT 0,
+    online_hours DOUBLE PRECISION NOT NULL DEFAULT 0,
+    rank INTEGER NOT NULL,
+    ranked_drivers INTEGER NOT NULL,
+    generated_at TIMESTAMP NOT NULL,
+    notified_at TIMESTAMP,
+    PRIMARY KEY (driver_id, week_start)
+);
+
+CREATE INDEX IF NOT EXISTS idx_driver_weekly_summaries_leaderboard ON driver_weekly_summaries (week_start, city, rank);
+-- Weekly aggregation scans ended trips and ledger credits by time
+CREATE INDEX IF NOT EXISTS idx_trips_ended_at ON trips (ended_at) WHERE status = 'ENDED';
+CREATE INDEX IF NOT EXISTS idx_driver_earnings_created ON driver_earnings (created_at);
+
+-- ============================================
+-- ACCESSIBILITY MATCHING
+-- ============================================
+-- Drivers declare vehicle capabilities (e.g. WAV, wheelchair-accessible);
+-- rides may require some, and are only matched to drivers having all of
+-- them. Rides with an accessibility need are re-matched before others.
+ALTER TABLE drivers ADD COLUMN IF NOT EXISTS capabilities JSONB NOT NULL DEFAULT '[]';
+ALTER TABLE rides ADD COLUMN IF NOT EXISTS capabilities JSONB NOT NULL DEFAULT '[]';
+ALTER TABLE rides ADD COLUMN IF NOT EXISTS priority BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE match_attempts ADD COLUMN IF NOT EXISTS skipped_capability INTEGER NOT NULL DEFAULT 0;
+
+-- The re-matcher's queue: waiting rides, priority first, oldest first
+CREATE INDEX IF NOT EXISTS idx_rides_rematch_queue ON rides (priority DESC, created_at, id) WHERE status = 'REQUESTED';
+
+-- ============================================
+-- CARD AUTHORIZATION
+-- ============================================
+-- CARD rides hold the estimated fare when the trip starts (AUTHORIZED) and
+-- capture the final fare when it ends; a hold released without charging,
+-- e.g. for an aborted trip, is VOIDED.
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS auth_id VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE payments DROP CONSTRAINT IF EXISTS payments_status_check;
+ALTER TABLE payments ADD CONSTRAINT payments_status_check
+    CHECK (status IN ('PENDING', 'SUCCESS', 'FAILED', 'REFUNDED', 'CASH_DUE', 'AUTHORIZED', 'VOIDED'));
+
+-- ============================================
+-- NOTIFICATION DELIVERIES
+-- ============================================
+-- Notifications queued for a channel besides push (EMAIL), retried with
+-- exponential backoff. After the maximum attempts a delivery is DEAD until an
+-- admin requeues it.
+CREATE TABLE IF NOT EXISTS notification_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    channel VARCHAR(20) NOT NULL,
+    recipient_id VARCHAR(36) NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    title TEXT NOT NULL,
+    message TEXT NOT NULL,
+    data JSONB,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT notification_deliveries_status_check CHECK (status IN ('PENDING', 'SENT', 'DEAD'))
+);
+
+-- The dispatcher's queue, and the admin dead-letter list
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_due ON notification_deliveries (next_attempt_at, id) WHERE status = 'PENDING';
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_dead ON notification_deliveries (updated_at DESC) WHERE status = 'DEAD';
+
+-- ============================================
+-- FARE SPLITS
+-- ============================================
+-- Riders travelling together split a trip's fare between their accounts.
+-- shares holds each rider's weight and, once the trip ends, their amount,
+-- payment and whether the ride's rider (owner_id) absorbed it after it
+-- failed to pay.
+CREATE TABLE IF NOT EXISTS trip_fare_splits (
+    trip_id VARCHAR(36) PRIMARY KEY REFERENCES trips(id),
+    owner_id VARCHAR(36) NOT NULL,
+    shares JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- ============================================
+-- FARE ADJUSTMENTS
+-- ============================================
+-- Audit trail of admin corrections to ended trips' fares. payment_id is the
+-- extra charge or refund that settled the difference, empty if it could not
+-- be made.
+CREATE TABLE IF NOT EXISTS fare_adjustments (
+    id VARCHAR(36) PRIMARY KEY,
+    trip_id VARCHAR(36) NOT NULL REFERENCES trips(id),
+    previous_fare DOUBLE PRECISION NOT NULL,
+    fare DOUBLE PRECISION NOT NULL,
+    reason TEXT NOT NULL,
+    payment_id VARCHAR(36) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_fare_adjustments_trip ON fare_adjustments (trip_id, created_at);
+
+-- ============================================
+-- FARE ESTIMATES
+-- ============================================
+-- The fare range quoted when a ride is requested, kept so support can compare
+-- it with the final fare and the estimate-accuracy report can calibrate it.
+-- 0 on rides requested before estimates were recorded.
+ALTER TABLE rides ADD COLUMN IF NOT EXISTS estimate_low DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE rides ADD COLUMN IF NOT EXISTS estimate_high DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+-- ============================================
+-- DRIVER TRACKER KEYS
+-- ============================================
+-- Keys that embedded location trackers sign their updates with, one per
+-- driver. key_hash is the SHA-256 of the secret issued at registration or
+-- rotation; the secret itself is never stored.
+CREATE TABLE IF NOT EXISTS driver_tracker_keys (
+    driver_id VARCHAR(36) PRIMARY KEY REFERENCES drivers(id),
+    key_hash VARCHAR(64) NOT NULL,
+    rotated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- ============================================
+-- DRIVER TIER CHANGES
+-- ============================================
+-- Audit trail of admins moving drivers between tiers.
+CREATE TABLE IF NOT EXISTS driver_tier_changes (
+    id VARCHAR(36) PRIMARY KEY,
+    driver_id VARCHAR(36) NOT NULL REFERENCES drivers(id),
+    previous_tier VARCHAR(20) NOT NULL,
+    tier VARCHAR(20) NOT NULL,
+    changed_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_driver_tier_changes_driver ON driver_tier_changes (driver_id, changed_at);
+
+-- ============================================
+-- RIDE ASSIGNMENT SOURCE
+-- ============================================
+-- How each ride's driver was chosen: MATCHING, or ADMIN when a dispatcher
+-- assigned the driver by hand. Empty on rides not yet assigned and on rides
+-- assigned before it was recorded.
+ALTER TABLE rides ADD COLUMN IF NOT EXISTS assigned_by VARCHAR(20) NOT NULL DEFAULT '';
+
+-- ============================================
+-- ENCRYPTED TRACKER SECRETS
+-- ============================================
+-- key_hash was the key trackers sign with, so anyone able to read it could
+-- sign for the driver. Secrets are now stored encrypted under
+-- TRACKER_KEY_ENCRYPTION_KEY; keys issued before that are dropped, and those
+-- drivers' trackers need a new secret.
+ALTER TABLE driver_tracker_keys ADD COLUMN IF NOT EXISTS encrypted_secret TEXT NOT NULL DEFAULT '';
+DELETE FROM driver_tracker_keys WHERE encrypted_secret = '';
+ALTER TABLE driver_tracker_keys DROP COLUMN IF EXISTS key_hash;
+
+-- ============================================
+-- RIDER TRIP CONFIRMATION
+-- ============================================
+-- With TRIP_CONFIRMATION_WINDOW set, an ended CARD trip keeps its payment
+-- authorized while confirmation is PENDING, until the rider confirms it or
+-- the window lapses (CONFIRMED). A DISPUTED trip stays on hold until an admin
+-- charges it (CHARGED) or releases the hold (VOIDED). Empty on trips that did
+-- not wait for the rider.
+ALTER TABLE trips ADD COLUMN IF NOT EXISTS confirmation VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE trips ADD COLUMN IF NOT EXISTS dispute_reason TEXT NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_trips_confirmation_pending ON trips (ended_at) WHERE confirmation = 'PENDING';
+
+-- ============================================
+-- CAMPAIGN CITIES AND ONLINE HOURS
+-- ============================================
+-- A driver's city is set at registration, lowercased; empty when not
+-- provided. A campaign with a city is open only to drivers in it; empty
+-- means every city. ONLINE_HOURS campaigns count hours online from
+-- driver_location_history rather than trips.
+ALTER TABLE drivers ADD COLUMN IF NOT EXISTS city VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS city VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE campaigns DROP CONSTRAINT IF EXISTS campaigns_criteria_check;
+ALTER TABLE campaigns ADD CONSTRAINT campaigns_criteria_check CHECK (criteria IN ('TRIP_COUNT', 'TRIP_HOURS', 'ONLINE_HOURS'));